@@ -24,6 +24,80 @@ func main() {
 	fs.StringVar(&cfg.Endpoint, "endpoint", cfg.Endpoint, "Endpoint path for HTTP transport")
 	fs.BoolVar(&cfg.Stateless, "stateless", cfg.Stateless, "Run in stateless mode (no session tracking)")
 	fs.BoolVar(&cfg.Preload, "preload", cfg.Preload, "Download all documentation bundles at startup")
+	fs.BoolVar(&cfg.Write, "allow-write", cfg.Write, "Enable the write_script tool for saving scripts inside the roots")
+	fs.Func("root", "Directory tools may read scripts and data files from (repeatable)", func(v string) error {
+		cfg.Roots = append(cfg.Roots, v)
+		return nil
+	})
+	fs.Func("redact-header", "Header name to mask in captured HTTP traffic (repeatable)", func(v string) error {
+		cfg.RedactHeaders = append(cfg.RedactHeaders, v)
+		return nil
+	})
+	fs.Func("redact-pattern", "Regular expression to mask in captured HTTP traffic (repeatable)", func(v string) error {
+		cfg.RedactPatterns = append(cfg.RedactPatterns, v)
+		return nil
+	})
+	fs.StringVar(&cfg.SecretsFile, "secrets-file", cfg.SecretsFile,
+		"File of NAME=VALUE secrets that tools can reference by name")
+	fs.StringVar(&cfg.RemoteImports, "remote-imports", cfg.RemoteImports,
+		"Whether scripts may import remote modules: allow or deny")
+	fs.Func("import-host", "Host remote modules may be imported from (repeatable)", func(v string) error {
+		cfg.ImportHosts = append(cfg.ImportHosts, v)
+		return nil
+	})
+	fs.Func("allow-target", "Host scripts may send requests to (repeatable)", func(v string) error {
+		cfg.AllowTargets = append(cfg.AllowTargets, v)
+		return nil
+	})
+	fs.Func("deny-target", "Host scripts may never send requests to (repeatable)", func(v string) error {
+		cfg.DenyTargets = append(cfg.DenyTargets, v)
+		return nil
+	})
+	fs.StringVar(&cfg.JSLibDir, "jslib-dir", cfg.JSLibDir, "Offline jslib mirror directory served to inline scripts")
+	fs.BoolVar(&cfg.VendorJSLib, "vendor-jslib", cfg.VendorJSLib,
+		"Download the common jslib modules into -jslib-dir and exit")
+	fs.BoolVar(&cfg.CheckDocLinks, "check-doc-links", cfg.CheckDocLinks,
+		"Check the links of every documentation version and exit")
+	fs.Func("webhook", "URL notified when background or scheduled runs end (repeatable)", func(v string) error {
+		cfg.Webhooks = append(cfg.Webhooks, v)
+		return nil
+	})
+	fs.StringVar(&cfg.SLOFile, "slo-file", cfg.SLOFile, "JSON file of SLOs runs can be checked against")
+	fs.StringVar(&cfg.ObserveFile, "observe-file", cfg.ObserveFile,
+		"JSON file of Prometheus or Loki queries whose results over each run are attached to it")
+	fs.StringVar(&cfg.AuditLog, "audit-log", cfg.AuditLog,
+		"JSON Lines file every command the server spawns is appended to")
+	fs.StringVar(&cfg.WorkerAddr, "worker-addr", cfg.WorkerAddr,
+		"Serve the runs of a coordinator on this address instead of MCP, authenticated with MCP_K6_WORKER_TOKEN")
+	fs.Func("worker", "URL of a worker run_on_workers splits runs across (repeatable)", func(v string) error {
+		cfg.Workers = append(cfg.Workers, v)
+		return nil
+	})
+	fs.Func("remote-host", "Host run_remote may run tests on over SSH, as host, *.domain or user@host (repeatable)",
+		func(v string) error {
+			cfg.RemoteHosts = append(cfg.RemoteHosts, v)
+			return nil
+		})
+	fs.StringVar(&cfg.RemoteKeyDir, "remote-key-dir", cfg.RemoteKeyDir,
+		"Directory of the private keys run_remote may log in with, by file name")
+	fs.StringVar(&cfg.RemoteK6, "remote-k6", cfg.RemoteK6,
+		"k6 executable run_remote runs on the remote hosts (default: k6 in their PATH)")
+	fs.IntVar(&cfg.ConfirmVUs, "confirm-vus", cfg.ConfirmVUs,
+		"Require confirmation for runs starting more than this many VUs (0 disables)")
+	fs.DurationVar(&cfg.ConfirmDuration, "confirm-duration", cfg.ConfirmDuration,
+		"Require confirmation for runs lasting longer than this (0 disables)")
+	fs.StringVar(&cfg.VerifyToken, "verify-token", cfg.VerifyToken,
+		"Token target hosts must publish before receiving load above -verify-above-vus")
+	fs.IntVar(&cfg.VerifyAboveVUs, "verify-above-vus", cfg.VerifyAboveVUs,
+		"VUs a run may start without verifying the ownership of its target hosts")
+	fs.IntVar(&cfg.MaxResponseBytes, "max-response-bytes", cfg.MaxResponseBytes,
+		"Truncate tool responses larger than this many bytes (0 disables)")
+	fs.Func("tool-response-bytes", "Response limit of one tool as tool=bytes (repeatable)", func(v string) error {
+		cfg.ToolResponseBytes = append(cfg.ToolResponseBytes, v)
+		return nil
+	})
+	fs.IntVar(&cfg.DocsCacheSize, "docs-cache-size", cfg.DocsCacheSize,
+		"Documentation tool responses kept in memory for repeated lookups (0 disables)")
 
 	//nolint:forbidigo // main must parse CLI arguments from os.Args.
 	if err := fs.Parse(os.Args[1:]); err != nil {
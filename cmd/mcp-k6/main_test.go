@@ -49,6 +49,26 @@ func TestRunSucceedsWithStubbedK6(t *testing.T) {
 	assert.Equal(t, 0, code, "run should succeed when k6 is available")
 }
 
+func TestRunFailsWithInvalidRedactPattern(t *testing.T) {
+	cfg := mcpserver.DefaultConfig()
+	cfg.RedactPatterns = []string{"("}
+
+	var stderr bytes.Buffer
+	code := mcpserver.Run(context.Background(), newTestLogger(), &stderr, cfg)
+	assert.NotEqual(t, 0, code)
+	assert.Contains(t, stderr.String(), "invalid redaction pattern")
+}
+
+func TestRunFailsWithMissingSecretsFile(t *testing.T) {
+	cfg := mcpserver.DefaultConfig()
+	cfg.SecretsFile = filepath.Join(t.TempDir(), "missing.env")
+
+	var stderr bytes.Buffer
+	code := mcpserver.Run(context.Background(), newTestLogger(), &stderr, cfg)
+	assert.NotEqual(t, 0, code)
+	assert.Contains(t, stderr.String(), "invalid secrets configuration")
+}
+
 func newTestLogger() *slog.Logger {
 	return slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{Level: slog.LevelDebug}))
 }
@@ -71,3 +91,163 @@ func createK6Stub(t *testing.T, dir string) {
 		t.Fatalf("failed to write k6 stub: %v", err)
 	}
 }
+
+func TestRunFailsWithInvalidImportPolicy(t *testing.T) {
+	cfg := mcpserver.DefaultConfig()
+	cfg.RemoteImports = "block"
+
+	var stderr bytes.Buffer
+	code := mcpserver.Run(context.Background(), newTestLogger(), &stderr, cfg)
+	assert.NotEqual(t, 0, code)
+	assert.Contains(t, stderr.String(), "invalid import policy")
+}
+
+func TestRunFailsWithMissingJSLibMirror(t *testing.T) {
+	cfg := mcpserver.DefaultConfig()
+	cfg.JSLibDir = filepath.Join(t.TempDir(), "missing")
+
+	var stderr bytes.Buffer
+	code := mcpserver.Run(context.Background(), newTestLogger(), &stderr, cfg)
+	assert.NotEqual(t, 0, code)
+	assert.Contains(t, stderr.String(), "invalid jslib mirror")
+}
+
+func TestRunFailsWithInvalidWebhook(t *testing.T) {
+	cfg := mcpserver.DefaultConfig()
+	cfg.Webhooks = []string{"hooks.example.com/token"}
+
+	var stderr bytes.Buffer
+	code := mcpserver.Run(context.Background(), newTestLogger(), &stderr, cfg)
+	assert.NotEqual(t, 0, code)
+	assert.Contains(t, stderr.String(), "invalid webhook configuration")
+	assert.NotContains(t, stderr.String(), "token")
+}
+
+func TestVendorJSLibRequiresDirectory(t *testing.T) {
+	cfg := mcpserver.DefaultConfig()
+	cfg.VendorJSLib = true
+
+	var stderr bytes.Buffer
+	code := mcpserver.Run(context.Background(), newTestLogger(), &stderr, cfg)
+	assert.NotEqual(t, 0, code)
+	assert.Contains(t, stderr.String(), "-jslib-dir")
+}
+
+func TestRunFailsWithInvalidSLOFile(t *testing.T) {
+	cfg := mcpserver.DefaultConfig()
+	cfg.SLOFile = filepath.Join(t.TempDir(), "slos.json")
+	content := `[{"name": "latency", "kind": "latency", "objective": 99}]`
+	//nolint:forbidigo // Test needs an SLO file on disk.
+	if err := os.WriteFile(cfg.SLOFile, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write SLO file: %v", err)
+	}
+
+	var stderr bytes.Buffer
+	code := mcpserver.Run(context.Background(), newTestLogger(), &stderr, cfg)
+	assert.NotEqual(t, 0, code)
+	assert.Contains(t, stderr.String(), "invalid SLO configuration")
+}
+
+func TestRunFailsWithInvalidObserveFile(t *testing.T) {
+	cfg := mcpserver.DefaultConfig()
+	cfg.ObserveFile = filepath.Join(t.TempDir(), "observe.json")
+	content := `{"datasources": [{"name": "prometheus", "url": "http://localhost:9090"}], "queries": []}`
+	//nolint:forbidigo // Test needs an observe file on disk.
+	if err := os.WriteFile(cfg.ObserveFile, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write observe file: %v", err)
+	}
+
+	var stderr bytes.Buffer
+	code := mcpserver.Run(context.Background(), newTestLogger(), &stderr, cfg)
+	assert.NotEqual(t, 0, code)
+	assert.Contains(t, stderr.String(), "invalid observe configuration")
+}
+
+func TestRunFailsWithInvalidTelemetry(t *testing.T) {
+	t.Setenv("MCP_K6_TELEMETRY_URL", "https://prometheus.grafana.net/api/v1/push/influx/write")
+	t.Setenv("MCP_K6_TELEMETRY_USER", "123456")
+	cfg := mcpserver.DefaultConfig()
+
+	var stderr bytes.Buffer
+	code := mcpserver.Run(context.Background(), newTestLogger(), &stderr, cfg)
+	assert.NotEqual(t, 0, code)
+	assert.Contains(t, stderr.String(), "invalid telemetry configuration")
+}
+
+func TestRunFailsWithInvalidAnnotations(t *testing.T) {
+	t.Setenv("MCP_K6_GRAFANA_URL", "https://example.grafana.net")
+	cfg := mcpserver.DefaultConfig()
+
+	var stderr bytes.Buffer
+	code := mcpserver.Run(context.Background(), newTestLogger(), &stderr, cfg)
+	assert.NotEqual(t, 0, code)
+	assert.Contains(t, stderr.String(), "invalid annotation configuration")
+}
+
+func TestRunFailsWithInvalidWorkers(t *testing.T) {
+	t.Setenv("MCP_K6_WORKER_TOKEN", "")
+	cfg := mcpserver.DefaultConfig()
+	cfg.Workers = []string{"http://worker-1:6566"}
+
+	var stderr bytes.Buffer
+	code := mcpserver.Run(context.Background(), newTestLogger(), &stderr, cfg)
+	assert.NotEqual(t, 0, code)
+	assert.Contains(t, stderr.String(), "invalid worker configuration")
+
+	cfg = mcpserver.DefaultConfig()
+	cfg.WorkerAddr = "127.0.0.1:0"
+	stderr.Reset()
+	code = mcpserver.Run(context.Background(), newTestLogger(), &stderr, cfg)
+	assert.NotEqual(t, 0, code)
+	assert.Contains(t, stderr.String(), "invalid worker configuration")
+}
+
+func TestRunFailsWithInvalidAuditLog(t *testing.T) {
+	cfg := mcpserver.DefaultConfig()
+	cfg.AuditLog = filepath.Join(t.TempDir(), "missing", "audit.jsonl")
+
+	var stderr bytes.Buffer
+	code := mcpserver.Run(context.Background(), newTestLogger(), &stderr, cfg)
+	assert.NotEqual(t, 0, code)
+	assert.Contains(t, stderr.String(), "invalid audit log configuration")
+}
+
+func TestRunFailsWithInvalidConfirmationPolicy(t *testing.T) {
+	cfg := mcpserver.DefaultConfig()
+	cfg.ConfirmVUs = -1
+
+	var stderr bytes.Buffer
+	code := mcpserver.Run(context.Background(), newTestLogger(), &stderr, cfg)
+	assert.NotEqual(t, 0, code)
+	assert.Contains(t, stderr.String(), "invalid confirmation policy")
+}
+
+func TestRunFailsWithInvalidTargetPolicy(t *testing.T) {
+	cfg := mcpserver.DefaultConfig()
+	cfg.DenyTargets = []string{"https://prod.example.com/"}
+
+	var stderr bytes.Buffer
+	code := mcpserver.Run(context.Background(), newTestLogger(), &stderr, cfg)
+	assert.NotEqual(t, 0, code)
+	assert.Contains(t, stderr.String(), "invalid target policy")
+}
+
+func TestRunFailsWithInvalidOwnershipVerification(t *testing.T) {
+	cfg := mcpserver.DefaultConfig()
+	cfg.VerifyToken = "short"
+
+	var stderr bytes.Buffer
+	code := mcpserver.Run(context.Background(), newTestLogger(), &stderr, cfg)
+	assert.NotEqual(t, 0, code)
+	assert.Contains(t, stderr.String(), "invalid ownership verification")
+}
+
+func TestRunFailsWithInvalidResponseLimits(t *testing.T) {
+	cfg := mcpserver.DefaultConfig()
+	cfg.ToolResponseBytes = []string{"get_documentation"}
+
+	var stderr bytes.Buffer
+	code := mcpserver.Run(context.Background(), newTestLogger(), &stderr, cfg)
+	assert.NotEqual(t, 0, code)
+	assert.Contains(t, stderr.String(), "invalid response limits")
+}
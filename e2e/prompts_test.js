@@ -7,6 +7,8 @@ function testPromptDiscovery(client) {
   expect(prompts.length).toBeGreaterThanOrEqual(2);
   expect(promptNames).toContain("generate_script");
   expect(promptNames).toContain("convert_playwright_script");
+  expect(promptNames).toContain("convert_cypress");
+  expect(promptNames).toContain("convert_selenium");
 }
 
 function testGenerateScriptPrompt(client) {
@@ -30,6 +32,28 @@ function testConvertPlaywrightScriptPrompt(client) {
   expect(result.messages[0].content.text.length).toBeGreaterThan(0);
 }
 
+function testConvertCypressPrompt(client) {
+  const result = client.getPrompt({
+    name: "convert_cypress",
+    arguments: {
+      cypress_script: 'describe("home", () => { it("loads", () => cy.visit("/")); });',
+    },
+  });
+  expect(result.messages.length).toBeGreaterThan(0);
+  expect(result.messages[0].content.text).toContain("cy.visit");
+}
+
+function testConvertSeleniumPrompt(client) {
+  const result = client.getPrompt({
+    name: "convert_selenium",
+    arguments: {
+      selenium_script: 'driver.get("https://quickpizza.grafana.com")',
+    },
+  });
+  expect(result.messages.length).toBeGreaterThan(0);
+  expect(result.messages[0].content.text).toContain("driver.get");
+}
+
 export default function () {
   const client = new mcp.StdioClient({
     path: __ENV.MCP_K6_BIN || "./mcp-k6",
@@ -38,4 +62,6 @@ export default function () {
   testPromptDiscovery(client);
   testGenerateScriptPrompt(client);
   testConvertPlaywrightScriptPrompt(client);
+  testConvertCypressPrompt(client);
+  testConvertSeleniumPrompt(client);
 }
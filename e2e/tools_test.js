@@ -23,13 +23,61 @@ function testPing(client) {
 function testToolDiscovery(client) {
   const tools = client.listAllTools().tools;
   const toolNames = tools.map((t) => t.name);
-  expect(tools).toHaveLength(6);
+  expect(tools).toHaveLength(31);
   expect(toolNames).toContain("info");
+  expect(toolNames).toContain("server_stats");
   expect(toolNames).toContain("validate_script");
   expect(toolNames).toContain("run_script");
+  expect(toolNames).toContain("plan_run");
+  expect(toolNames).toContain("get_run");
+  expect(toolNames).toContain("list_runs");
+  expect(toolNames).toContain("stop_run");
+  expect(toolNames).toContain("pause_run");
+  expect(toolNames).toContain("resume_run");
+  expect(toolNames).toContain("scale_run");
+  expect(toolNames).toContain("get_run_samples");
+  expect(toolNames).toContain("analyze_run");
+  expect(toolNames).toContain("find_capacity");
+  expect(toolNames).toContain("run_suite");
+  expect(toolNames).toContain("schedule_run");
+  expect(toolNames).toContain("run_distributed");
+  expect(toolNames).toContain("run_on_workers");
+  expect(toolNames).toContain("run_remote");
+  expect(toolNames).toContain("list_schedules");
+  expect(toolNames).toContain("cancel_schedule");
+  expect(toolNames).toContain("define_slo");
+  expect(toolNames).toContain("list_slos");
+  expect(toolNames).toContain("delete_slo");
+  expect(toolNames).toContain("get_error_budget");
   expect(toolNames).toContain("list_sections");
   expect(toolNames).toContain("get_documentation");
   expect(toolNames).toContain("search_terraform");
+  expect(toolNames).toContain("convert_recording");
+  expect(toolNames).toContain("convert_playwright");
+  expect(toolNames).toContain("generate_contract_tests");
+  expect(toolNames).toContain("generate_graphql_script");
+  expect(toolNames).toContain("generate_kafka_script");
+  expect(toolNames).toContain("generate_sql_test");
+  expect(toolNames).toContain("generate_mqtt_script");
+  expect(toolNames).toContain("generate_streaming_script");
+  expect(toolNames).toContain("generate_disruptor_script");
+  expect(toolNames).toContain("generate_auth_snippet");
+  expect(toolNames).toContain("generate_session_snippet");
+  expect(toolNames).toContain("analyze_correlation");
+  expect(toolNames).toContain("analyze_script");
+  expect(toolNames).toContain("check_compatibility");
+  expect(toolNames).toContain("whats_new");
+  expect(toolNames).toContain("migrate_script");
+  expect(toolNames).toContain("list_endpoints");
+  expect(toolNames).toContain("openapi_coverage");
+  expect(toolNames).toContain("explain_options");
+  expect(toolNames).toContain("build_scenario");
+  expect(toolNames).toContain("model_pacing");
+  expect(toolNames).toContain("build_workload_mix");
+  expect(toolNames).toContain("scaffold_typescript_project");
+  expect(toolNames).toContain("checks_to_thresholds");
+  expect(toolNames).toContain("diff_scripts");
+  expect(toolNames).toContain("apply_patch");
 }
 
 function testInfoTool(client) {
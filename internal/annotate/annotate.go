@@ -0,0 +1,205 @@
+// Package annotate marks the runs of the server on Grafana dashboards, with
+// a region annotation spanning from the start of each run to its end.
+package annotate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Environment variables configuring the annotations.
+const (
+	// EnvURL is the URL of the Grafana instance, such as
+	// https://example.grafana.net.
+	EnvURL = "MCP_K6_GRAFANA_URL"
+	// EnvToken is a service account token allowed to write annotations.
+	EnvToken = "MCP_K6_GRAFANA_TOKEN"
+	// EnvDashboard is the UID of the dashboard to annotate (default: an
+	// organization annotation, shown by dashboards querying its tags).
+	EnvDashboard = "MCP_K6_GRAFANA_DASHBOARD_UID"
+	// EnvTags are extra comma-separated tags of the annotations.
+	EnvTags = "MCP_K6_GRAFANA_TAGS"
+)
+
+// timeout bounds each annotation request.
+const timeout = 10 * time.Second
+
+// ErrInvalid is returned for annotation variables that cannot be used.
+var ErrInvalid = errors.New("invalid Grafana annotation environment")
+
+// Annotator posts the annotations of runs. A nil *Annotator posts nothing.
+type Annotator struct {
+	url       *url.URL
+	token     string
+	dashboard string
+	tags      []string
+	client    *http.Client
+}
+
+// Run describes an annotated run.
+type Run struct {
+	// ID is the ID of the run, empty for runs the caller waits for.
+	ID string
+	// Script is the file name of the script, or "inline".
+	Script string
+}
+
+// Mark is the annotation of a run in progress, ended with End. A nil *Mark
+// ends nothing.
+type Mark struct {
+	a     *Annotator
+	run   Run
+	id    int64
+	start time.Time
+}
+
+// annotation is the body of the Grafana annotations API.
+type annotation struct {
+	DashboardUID string   `json:"dashboardUID,omitempty"`
+	Time         int64    `json:"time,omitempty"`
+	TimeEnd      int64    `json:"timeEnd,omitempty"`
+	Tags         []string `json:"tags"`
+	Text         string   `json:"text"`
+}
+
+// New returns an annotator configured from the MCP_K6_GRAFANA_* variables
+// of environ, or nil when no Grafana URL is set.
+func New(environ []string) (*Annotator, error) {
+	env := make(map[string]string)
+	for _, kv := range environ {
+		if k, v, ok := strings.Cut(kv, "="); ok && strings.HasPrefix(k, "MCP_K6_GRAFANA_") {
+			env[k] = v
+		}
+	}
+	if env[EnvURL] == "" {
+		return nil, nil
+	}
+	u, err := url.Parse(strings.TrimRight(env[EnvURL], "/"))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("%w: %s must be an absolute http or https URL", ErrInvalid, EnvURL)
+	}
+	if env[EnvToken] == "" {
+		return nil, fmt.Errorf("%w: %s is required with %s", ErrInvalid, EnvToken, EnvURL)
+	}
+
+	a := &Annotator{
+		url:       u,
+		token:     env[EnvToken],
+		dashboard: env[EnvDashboard],
+		tags:      []string{"k6", "mcp-k6"},
+		client:    &http.Client{Timeout: timeout},
+	}
+	for _, tag := range strings.Split(env[EnvTags], ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			a.tags = append(a.tags, tag)
+		}
+	}
+	return a, nil
+}
+
+// Host returns the Grafana host annotations are posted to, for logging.
+func (a *Annotator) Host() string {
+	if a == nil {
+		return ""
+	}
+	return a.url.Host
+}
+
+// Start posts the annotation of run, starting now. The returned mark is
+// ended with End, also when the post failed, as End then posts the whole
+// region.
+func (a *Annotator) Start(ctx context.Context, run Run) (*Mark, error) {
+	if a == nil {
+		return nil, nil
+	}
+	m := &Mark{a: a, run: run, start: time.Now()}
+	body := annotation{
+		DashboardUID: a.dashboard,
+		Time:         m.start.UnixMilli(),
+		Tags:         a.runTags(run),
+		Text:         describe(run) + " started",
+	}
+	var created struct {
+		ID int64 `json:"id"`
+	}
+	if err := a.send(ctx, http.MethodPost, "/api/annotations", body, &created); err != nil {
+		return m, err
+	}
+	m.id = created.ID
+	return m, nil
+}
+
+// End extends the annotation of the run to now, recording its outcome, such
+// as "passed".
+func (m *Mark) End(ctx context.Context, outcome string) error {
+	if m == nil {
+		return nil
+	}
+	body := annotation{
+		Time:    m.start.UnixMilli(),
+		TimeEnd: time.Now().UnixMilli(),
+		Tags:    append(m.a.runTags(m.run), "outcome:"+outcome),
+		Text:    describe(m.run) + ": " + outcome,
+	}
+	if m.id == 0 {
+		body.DashboardUID = m.a.dashboard
+		return m.a.send(ctx, http.MethodPost, "/api/annotations", body, nil)
+	}
+	return m.a.send(ctx, http.MethodPatch, "/api/annotations/"+strconv.FormatInt(m.id, 10), body, nil)
+}
+
+// runTags returns the tags of the annotation of run.
+func (a *Annotator) runTags(run Run) []string {
+	tags := append([]string(nil), a.tags...)
+	if run.ID != "" {
+		tags = append(tags, "run:"+run.ID)
+	}
+	return append(tags, "script:"+run.Script)
+}
+
+// describe returns the text naming run in its annotation.
+func describe(run Run) string {
+	if run.ID == "" {
+		return "k6 run of " + run.Script
+	}
+	return "k6 run " + run.ID + " of " + run.Script
+}
+
+func (a *Annotator) send(ctx context.Context, method, path string, body annotation, out any) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("encoding annotation: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, a.url.String()+path, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("grafana %s: %w", a.url.Host, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+a.token)
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("grafana %s: %w", a.url.Host, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode >= http.StatusBadRequest {
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+		return fmt.Errorf("grafana %s: %s", a.url.Host, resp.Status)
+	}
+	if out == nil {
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+		return nil
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 64*1024)).Decode(out); err != nil {
+		return fmt.Errorf("grafana %s: unexpected response: %w", a.url.Host, err)
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package annotate
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	a, err := New([]string{"PATH=/bin"})
+	require.NoError(t, err)
+	assert.Nil(t, a)
+
+	tests := map[string]struct {
+		env []string
+		err string
+	}{
+		"url":   {[]string{EnvURL + "=grafana.example.com", EnvToken + "=glsa"}, "absolute http or https URL"},
+		"token": {[]string{EnvURL + "=https://grafana.example.com"}, EnvToken + " is required"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			_, err := New(tt.env)
+			require.ErrorIs(t, err, ErrInvalid)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+
+	a, err = New([]string{EnvURL + "=https://grafana.example.com/", EnvToken + "=glsa", EnvTags + "=team:shop, ,ci"})
+	require.NoError(t, err)
+	assert.Equal(t, "grafana.example.com", a.Host())
+	assert.Equal(t, []string{"k6", "mcp-k6", "team:shop", "ci"}, a.tags)
+}
+
+// request is an annotation request received by the test server.
+type request struct {
+	method string
+	path   string
+	body   annotation
+}
+
+func TestStartEnd(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu       sync.Mutex
+		requests []request
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer glsa_token", r.Header.Get("Authorization"))
+		var body annotation
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		mu.Lock()
+		requests = append(requests, request{r.Method, r.URL.Path, body})
+		mu.Unlock()
+		_, _ = w.Write([]byte(`{"id": 42, "message": "Annotation added"}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	a, err := New([]string{EnvURL + "=" + srv.URL, EnvToken + "=glsa_token", EnvDashboard + "=shop"})
+	require.NoError(t, err)
+	m, err := a.Start(context.Background(), Run{ID: "run-1", Script: "checkout.js"})
+	require.NoError(t, err)
+	require.NoError(t, m.End(context.Background(), "passed"))
+
+	require.Len(t, requests, 2)
+	assert.Equal(t, http.MethodPost, requests[0].method)
+	assert.Equal(t, "/api/annotations", requests[0].path)
+	assert.Equal(t, "shop", requests[0].body.DashboardUID)
+	assert.Equal(t, "k6 run run-1 of checkout.js started", requests[0].body.Text)
+	assert.Equal(t, []string{"k6", "mcp-k6", "run:run-1", "script:checkout.js"}, requests[0].body.Tags)
+	assert.Equal(t, http.MethodPatch, requests[1].method)
+	assert.Equal(t, "/api/annotations/42", requests[1].path)
+	assert.Equal(t, "k6 run run-1 of checkout.js: passed", requests[1].body.Text)
+	assert.Equal(t, requests[0].body.Time, requests[1].body.Time)
+	assert.GreaterOrEqual(t, requests[1].body.TimeEnd, requests[1].body.Time)
+	assert.Contains(t, requests[1].body.Tags, "outcome:passed")
+}
+
+func TestEndPostsRegionAfterFailedStart(t *testing.T) {
+	t.Parallel()
+
+	var posts []annotation
+	fail := true
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fail {
+			fail = false
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var body annotation
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, http.MethodPost, r.Method)
+		posts = append(posts, body)
+		_, _ = w.Write([]byte(`{"id": 7}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	a, err := New([]string{EnvURL + "=" + srv.URL, EnvToken + "=glsa_token"})
+	require.NoError(t, err)
+	m, err := a.Start(context.Background(), Run{Script: "inline"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "401 Unauthorized")
+	require.NoError(t, m.End(context.Background(), "thresholds_failed"))
+
+	require.Len(t, posts, 1)
+	assert.Equal(t, "k6 run of inline: thresholds_failed", posts[0].Text)
+	assert.NotZero(t, posts[0].TimeEnd)
+
+	var none *Annotator
+	m, err = none.Start(context.Background(), Run{})
+	require.NoError(t, err)
+	require.NoError(t, m.End(context.Background(), "passed"))
+}
@@ -0,0 +1,296 @@
+// Package anomaly flags latency spikes, error bursts and throughput plateaus
+// in the downsampled time series of a run, so they can be matched with
+// deploys or the load at which the system under test saturated.
+package anomaly
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/grafana/mcp-k6/internal/samples"
+)
+
+// Kinds of anomalies.
+const (
+	LatencySpike      = "latency_spike"
+	ErrorBurst        = "error_burst"
+	ThroughputPlateau = "throughput_plateau"
+)
+
+// Metrics of the series Detect reads, besides the latency metric.
+const (
+	errorsMetric   = "http_req_failed"
+	requestsMetric = "http_reqs"
+	vusMetric      = "vus"
+)
+
+const (
+	// DefaultLatencyMetric is the metric latency spikes are looked for in.
+	DefaultLatencyMetric = "http_req_duration"
+	// minBuckets is the number of buckets with samples a baseline needs.
+	minBuckets = 5
+	// spikeFactor is how many times the baseline latency a spike reaches.
+	spikeFactor = 2
+	// minBurstRate is the error rate below which no burst is flagged.
+	minBurstRate = 0.05
+	// burstFactor is how many times the error rate of the run a burst
+	// reaches.
+	burstFactor = 2
+	// plateauBuckets is the number of buckets throughput must stay flat for.
+	plateauBuckets = 3
+	// plateauTolerance is how much throughput varies on a plateau.
+	plateauTolerance = 0.1
+	// plateauVUGrowth is how much VUs grow over a plateau.
+	plateauVUGrowth = 0.2
+)
+
+// Anomaly is a period of the run in which a series departs from its
+// baseline.
+type Anomaly struct {
+	Kind   string `json:"kind"`
+	Metric string `json:"metric"`
+	// Start and End bound the buckets of the anomaly.
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+	// From and To are Start and End since the start of the run, as accepted
+	// by get_run_samples.
+	From string `json:"from"`
+	To   string `json:"to"`
+	// Peak is the worst value of the anomaly: the highest bucket average
+	// latency in milliseconds or error rate, or the average requests per
+	// second of a plateau.
+	Peak float64 `json:"peak"`
+	// Baseline is the value Peak departs from: the median bucket latency,
+	// the error rate of the run, or the requests per second the plateau
+	// started at.
+	Baseline float64 `json:"baseline"`
+	Message  string  `json:"message"`
+}
+
+// Options configures Detect.
+type Options struct {
+	// LatencyMetric is the trend metric latency spikes are looked for in;
+	// empty for DefaultLatencyMetric.
+	LatencyMetric string
+}
+
+// Metrics returns the metrics Detect reads, for selecting their samples.
+func (o Options) Metrics() []string {
+	return []string{o.latencyMetric(), errorsMetric, requestsMetric, vusMetric}
+}
+
+func (o Options) latencyMetric() string {
+	if o.LatencyMetric == "" {
+		return DefaultLatencyMetric
+	}
+	return o.LatencyMetric
+}
+
+// series indexes the buckets of a metric by their position from the start
+// of the series.
+type series map[int]samples.Bucket
+
+// Detect returns the anomalies of the series of res, selected with buckets,
+// ordered by start time. Each detector needs minBuckets buckets with samples.
+func Detect(res *samples.Result, opts Options) []Anomaly {
+	if res.Width <= 0 {
+		return []Anomaly{}
+	}
+	d := detector{run: res.Start, width: res.Width, series: make(map[string]series)}
+	for _, a := range res.Aggregates {
+		if len(a.Series) > 0 && (d.start.IsZero() || a.Series[0].Time.Before(d.start)) {
+			d.start = a.Series[0].Time
+		}
+	}
+	for _, a := range res.Aggregates {
+		if a.Group != "" {
+			continue
+		}
+		s := make(series, len(a.Series))
+		for _, b := range a.Series {
+			s[int(b.Time.Sub(d.start)/d.width)] = b
+		}
+		d.series[a.Metric] = s
+	}
+
+	anomalies := []Anomaly{}
+	anomalies = append(anomalies, d.latencySpikes(opts.latencyMetric())...)
+	anomalies = append(anomalies, d.errorBursts()...)
+	anomalies = append(anomalies, d.throughputPlateaus()...)
+	sort.SliceStable(anomalies, func(i, j int) bool { return anomalies[i].Start.Before(anomalies[j].Start) })
+	return anomalies
+}
+
+type detector struct {
+	// run is the start of the run, start the start of the first bucket.
+	run, start time.Time
+	width      time.Duration
+	series     map[string]series
+}
+
+// latencySpikes flags the buckets whose average latency is spikeFactor
+// times the median of the bucket averages.
+func (d *detector) latencySpikes(metric string) []Anomaly {
+	s := d.series[metric]
+	if len(s) < minBuckets {
+		return nil
+	}
+	avgs := make([]float64, 0, len(s))
+	for _, b := range s {
+		avgs = append(avgs, b.Avg)
+	}
+	baseline := median(avgs)
+	if baseline <= 0 {
+		return nil
+	}
+	var anomalies []Anomaly
+	for _, sp := range spans(s, func(b samples.Bucket) bool { return b.Avg > spikeFactor*baseline }) {
+		anomalies = append(anomalies, d.anomaly(LatencySpike, metric, sp, baseline, fmt.Sprintf(
+			"%s averaged up to %s ms, %sx the median of %s ms", metric, format(sp.peak),
+			format(sp.peak/baseline), format(baseline))))
+	}
+	return anomalies
+}
+
+// errorBursts flags the buckets whose rate of failed requests is at least
+// minBurstRate and burstFactor times the rate of the run.
+func (d *detector) errorBursts() []Anomaly {
+	s := d.series[errorsMetric]
+	if len(s) < minBuckets {
+		return nil
+	}
+	var failed float64
+	var count int
+	for _, b := range s {
+		failed += b.Sum
+		count += b.Count
+	}
+	baseline := round(failed / float64(count))
+	var anomalies []Anomaly
+	for _, sp := range spans(s, func(b samples.Bucket) bool {
+		return b.Avg >= minBurstRate && b.Avg >= burstFactor*baseline
+	}) {
+		anomalies = append(anomalies, d.anomaly(ErrorBurst, errorsMetric, sp, baseline, fmt.Sprintf(
+			"up to %s%% of requests failed, against %s%% over the run", format(sp.peak*100), format(baseline*100))))
+	}
+	return anomalies
+}
+
+// throughputPlateaus flags the runs of at least plateauBuckets buckets whose
+// requests per second stay within plateauTolerance of the first while the
+// VUs grow by plateauVUGrowth: the load grows but the throughput does not.
+func (d *detector) throughputPlateaus() []Anomaly {
+	reqs, vus := d.series[requestsMetric], d.series[vusMetric]
+	if len(reqs) < minBuckets || len(vus) < minBuckets {
+		return nil
+	}
+	last := 0
+	for k := range reqs {
+		last = max(last, k)
+	}
+	rate := func(k int) (float64, bool) {
+		b, ok := reqs[k]
+		return b.Sum / d.width.Seconds(), ok
+	}
+
+	var anomalies []Anomaly
+	for i := 0; i <= last; {
+		level, ok := rate(i)
+		if !ok || level == 0 {
+			i++
+			continue
+		}
+		j, sum := i, level
+		for ; j+1 <= last; j++ {
+			next, ok := rate(j + 1)
+			if !ok || math.Abs(next-level) > plateauTolerance*level {
+				break
+			}
+			sum += next
+		}
+		from, fromOK := vus[i]
+		to, toOK := vus[j]
+		if j-i+1 < plateauBuckets || !fromOK || !toOK || from.Max <= 0 || to.Max < (1+plateauVUGrowth)*from.Max {
+			i++
+			continue
+		}
+		avg := round(sum / float64(j-i+1))
+		anomalies = append(anomalies, d.anomaly(ThroughputPlateau, requestsMetric, span{i, j, avg}, round(level),
+			fmt.Sprintf("throughput stayed at about %s req/s while VUs grew from %s to %s; "+
+				"the system under test likely saturated", format(avg), format(from.Max), format(to.Max))))
+		i = j + 1
+	}
+	return anomalies
+}
+
+// span is a run of consecutive buckets, with the highest average of its
+// buckets as peak.
+type span struct {
+	first, last int
+	peak        float64
+}
+
+// spans returns the runs of consecutive buckets of s matching flagged.
+// Buckets without samples end a span.
+func spans(s series, flagged func(samples.Bucket) bool) []span {
+	keys := make([]int, 0, len(s))
+	for k := range s {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+
+	var spans []span
+	var cur *span
+	for _, k := range keys {
+		b := s[k]
+		if !flagged(b) {
+			cur = nil
+			continue
+		}
+		if cur != nil && k == cur.last+1 {
+			cur.last, cur.peak = k, max(cur.peak, b.Avg)
+			continue
+		}
+		spans = append(spans, span{first: k, last: k, peak: b.Avg})
+		cur = &spans[len(spans)-1]
+	}
+	return spans
+}
+
+func (d *detector) anomaly(kind, metric string, sp span, baseline float64, message string) Anomaly {
+	start := d.start.Add(time.Duration(sp.first) * d.width)
+	end := d.start.Add(time.Duration(sp.last+1) * d.width)
+	return Anomaly{
+		Kind:     kind,
+		Metric:   metric,
+		Start:    start,
+		End:      end,
+		From:     start.Sub(d.run).Round(time.Second).String(),
+		To:       end.Sub(d.run).Round(time.Second).String(),
+		Peak:     round(sp.peak),
+		Baseline: baseline,
+		Message:  message,
+	}
+}
+
+func median(values []float64) float64 {
+	sort.Float64s(values)
+	n := len(values)
+	if n%2 == 1 {
+		return round(values[n/2])
+	}
+	return round((values[n/2-1] + values[n/2]) / 2)
+}
+
+// round rounds v to 4 decimals.
+func round(v float64) float64 {
+	return math.Round(v*10000) / 10000
+}
+
+// format formats v with up to 2 decimals.
+func format(v float64) string {
+	return strconv.FormatFloat(math.Round(v*100)/100, 'f', -1, 64)
+}
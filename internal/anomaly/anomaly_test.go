@@ -0,0 +1,91 @@
+package anomaly
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grafana/mcp-k6/internal/samples"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// run writes the samples of a 10-minute ramp from 1 to 20 VUs, whose
+// throughput stops growing at 5m, with a latency spike at 2m and an error
+// burst at 7m.
+func run() string {
+	var b strings.Builder
+	start := time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)
+	point := func(metric string, i int, value float64) {
+		fmt.Fprintf(&b, `{"metric":%q,"type":"Point","data":{"time":%q,"value":%v}}`+"\n",
+			metric, start.Add(time.Duration(i)*time.Second).Format(time.RFC3339), value)
+	}
+	for i := range 600 {
+		vus := 1 + i/30
+		point("vus", i, float64(vus))
+		point("http_reqs", i, float64(min(vus, 11)*10))
+		latency, failed := 100.0, 0.0
+		if i >= 120 && i < 150 {
+			latency = 1000
+		}
+		if i >= 420 && i < 450 {
+			failed = 1
+		}
+		point("http_req_duration", i, latency)
+		point("http_req_failed", i, failed)
+	}
+	return b.String()
+}
+
+func TestDetect(t *testing.T) {
+	t.Parallel()
+
+	opts := Options{}
+	res, err := samples.Select(strings.NewReader(run()), samples.FormatJSON,
+		samples.Query{Metrics: opts.Metrics(), Buckets: 20})
+	require.NoError(t, err)
+
+	anomalies := Detect(res, opts)
+	require.Len(t, anomalies, 3)
+
+	spike := anomalies[0]
+	assert.Equal(t, LatencySpike, spike.Kind)
+	assert.Equal(t, "http_req_duration", spike.Metric)
+	assert.Equal(t, "2m0s", spike.From)
+	assert.Equal(t, "2m30s", spike.To)
+	assert.InDelta(t, 1000, spike.Peak, 0)
+	assert.InDelta(t, 100, spike.Baseline, 0)
+	assert.Equal(t, "http_req_duration averaged up to 1000 ms, 10x the median of 100 ms", spike.Message)
+
+	plateau := anomalies[1]
+	assert.Equal(t, ThroughputPlateau, plateau.Kind)
+	assert.Equal(t, "5m0s", plateau.From)
+	assert.Equal(t, "9m59s", plateau.To)
+	assert.Contains(t, plateau.Message, "about 110.18 req/s while VUs grew from 11 to 20")
+
+	burst := anomalies[2]
+	assert.Equal(t, ErrorBurst, burst.Kind)
+	assert.Equal(t, "6m59s", burst.From)
+	assert.InDelta(t, 1, burst.Peak, 0)
+	assert.InDelta(t, 0.05, burst.Baseline, 0)
+	assert.Equal(t, "up to 100% of requests failed, against 5% over the run", burst.Message)
+}
+
+func TestDetectSteadyRun(t *testing.T) {
+	t.Parallel()
+
+	var b strings.Builder
+	for i := range 100 {
+		fmt.Fprintf(&b, `{"metric":"http_req_duration","type":"Point","data":{"time":%q,"value":%d}}`+"\n",
+			time.Date(2026, 1, 2, 10, 0, i, 0, time.UTC).Format(time.RFC3339), 100+i%10)
+	}
+	res, err := samples.Select(strings.NewReader(b.String()), samples.FormatJSON, samples.Query{Buckets: 10})
+	require.NoError(t, err)
+	assert.Empty(t, Detect(res, Options{}))
+
+	// Without buckets there is no series to look at
+	res, err = samples.Select(strings.NewReader(b.String()), samples.FormatJSON, samples.Query{})
+	require.NoError(t, err)
+	assert.Empty(t, Detect(res, Options{}))
+}
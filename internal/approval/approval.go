@@ -0,0 +1,171 @@
+// Package approval gates high-load runs behind an explicit confirmation.
+// A run above the configured limits is not started; instead the caller gets
+// a one-time token, bound to the exact request, that it must pass back to
+// go ahead, after checking with the user.
+package approval
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// TokenTTL is how long a confirmation token can be redeemed.
+	TokenTTL = 10 * time.Minute
+	// maxPending bounds the tokens waiting to be redeemed.
+	maxPending = 100
+)
+
+var (
+	// ErrInvalid is returned for limits that cannot be used.
+	ErrInvalid = errors.New("invalid confirmation policy")
+	// ErrToken is returned for tokens that do not confirm the request.
+	ErrToken = errors.New("invalid confirmation_token")
+)
+
+// Load is the planned load of a run.
+type Load struct {
+	VUs      int
+	Duration time.Duration
+	// Unknown is set when the load cannot be read statically.
+	Unknown bool
+}
+
+// Gate holds the limits above which runs need confirmation, and the tokens
+// issued for them. A nil *Gate lets every run through.
+type Gate struct {
+	maxVUs      int
+	maxDuration time.Duration
+
+	mu      sync.Mutex
+	pending map[string]pending
+
+	// now reads the clock; replaced in tests.
+	now func() time.Time
+}
+
+type pending struct {
+	fingerprint string
+	expires     time.Time
+}
+
+// New returns a gate for runs above maxVUs or lasting longer than
+// maxDuration; zero disables a limit. It returns nil when both are zero.
+func New(maxVUs int, maxDuration time.Duration) (*Gate, error) {
+	if maxVUs < 0 {
+		return nil, fmt.Errorf("%w: VU limit %d is negative", ErrInvalid, maxVUs)
+	}
+	if maxDuration < 0 {
+		return nil, fmt.Errorf("%w: duration limit %v is negative", ErrInvalid, maxDuration)
+	}
+	if maxVUs == 0 && maxDuration == 0 {
+		return nil, nil
+	}
+	return &Gate{
+		maxVUs:      maxVUs,
+		maxDuration: maxDuration,
+		pending:     make(map[string]pending),
+		now:         time.Now,
+	}, nil
+}
+
+// String describes the limits, such as "more than 20 VUs or longer than 2m0s".
+func (g *Gate) String() string {
+	var limits []string
+	if g.maxVUs > 0 {
+		limits = append(limits, fmt.Sprintf("more than %d VUs", g.maxVUs))
+	}
+	if g.maxDuration > 0 {
+		limits = append(limits, "longer than "+g.maxDuration.String())
+	}
+	return strings.Join(limits, " or ")
+}
+
+// Check returns why load needs confirmation, or nothing if it does not.
+func (g *Gate) Check(load Load) []string {
+	if g == nil {
+		return nil
+	}
+	var reasons []string
+	if g.maxVUs > 0 && load.VUs > g.maxVUs {
+		reasons = append(reasons, fmt.Sprintf("the run starts up to %d VUs (limit %d)", load.VUs, g.maxVUs))
+	}
+	if g.maxDuration > 0 && load.Duration > g.maxDuration {
+		reasons = append(reasons, fmt.Sprintf("the run lasts %v (limit %v)", load.Duration, g.maxDuration))
+	}
+	if load.Unknown {
+		reasons = append(reasons, "the load of the run cannot be read statically")
+	}
+	return reasons
+}
+
+// Issue returns a token confirming the request with fingerprint, and when
+// it expires.
+func (g *Gate) Issue(fingerprint string) (string, time.Time) {
+	buf := make([]byte, 16)
+	_, _ = rand.Read(buf)
+	token := hex.EncodeToString(buf)
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	now := g.now()
+	g.expire(now)
+	if len(g.pending) >= maxPending {
+		g.dropOldest()
+	}
+	expires := now.Add(TokenTTL)
+	g.pending[token] = pending{fingerprint: fingerprint, expires: expires}
+	return token, expires
+}
+
+// Redeem consumes token, which must have been issued for the request with
+// fingerprint. A token is used up by the first attempt, even a failed one.
+func (g *Gate) Redeem(token, fingerprint string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	p, ok := g.pending[token]
+	delete(g.pending, token)
+	switch {
+	case !ok:
+		return fmt.Errorf("%w: unknown or already used; call again without it for a new one", ErrToken)
+	case g.now().After(p.expires):
+		return fmt.Errorf("%w: expired after %v; call again without it for a new one", ErrToken, TokenTTL)
+	case p.fingerprint != fingerprint:
+		return fmt.Errorf("%w: it was issued for different parameters; "+
+			"call again without it to confirm these", ErrToken)
+	}
+	return nil
+}
+
+func (g *Gate) expire(now time.Time) {
+	for token, p := range g.pending {
+		if now.After(p.expires) {
+			delete(g.pending, token)
+		}
+	}
+}
+
+func (g *Gate) dropOldest() {
+	var oldest string
+	for token, p := range g.pending {
+		if oldest == "" || p.expires.Before(g.pending[oldest].expires) {
+			oldest = token
+		}
+	}
+	delete(g.pending, oldest)
+}
+
+// Fingerprint hashes the parts of a request into the value a token is bound to.
+func Fingerprint(parts ...string) string {
+	h := sha256.New()
+	for _, part := range parts {
+		_, _ = fmt.Fprintf(h, "%d:%s", len(part), part)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,96 @@
+package approval
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	g, err := New(0, 0)
+	require.NoError(t, err)
+	assert.Nil(t, g)
+	assert.Empty(t, g.Check(Load{VUs: 1000, Duration: time.Hour}))
+
+	_, err = New(-1, 0)
+	require.ErrorIs(t, err, ErrInvalid)
+	_, err = New(0, -time.Second)
+	require.ErrorIs(t, err, ErrInvalid)
+
+	g, err = New(20, 2*time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, "more than 20 VUs or longer than 2m0s", g.String())
+}
+
+func TestCheck(t *testing.T) {
+	t.Parallel()
+
+	g, err := New(20, 2*time.Minute)
+	require.NoError(t, err)
+	assert.Empty(t, g.Check(Load{VUs: 20, Duration: 2 * time.Minute}))
+	assert.Equal(t, []string{
+		"the run starts up to 30 VUs (limit 20)",
+		"the run lasts 5m0s (limit 2m0s)",
+	}, g.Check(Load{VUs: 30, Duration: 5 * time.Minute}))
+	assert.Equal(t, []string{"the load of the run cannot be read statically"}, g.Check(Load{VUs: 1, Unknown: true}))
+
+	g, err = New(0, time.Minute)
+	require.NoError(t, err)
+	assert.Empty(t, g.Check(Load{VUs: 1000}))
+}
+
+func TestRedeem(t *testing.T) {
+	t.Parallel()
+
+	g, err := New(10, 0)
+	require.NoError(t, err)
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	g.now = func() time.Time { return now }
+
+	a := Fingerprint("run_script", `{"vus":40}`, "script")
+	b := Fingerprint("run_script", `{"vus":45}`, "script")
+	assert.NotEqual(t, a, b)
+	assert.NotEqual(t, Fingerprint("ab", "c"), Fingerprint("a", "bc"))
+
+	token, expires := g.Issue(a)
+	assert.Len(t, token, 32)
+	assert.Equal(t, now.Add(TokenTTL), expires)
+	require.NoError(t, g.Redeem(token, a))
+
+	err = g.Redeem(token, a)
+	require.ErrorIs(t, err, ErrToken)
+	assert.Contains(t, err.Error(), "already used")
+
+	token, _ = g.Issue(a)
+	err = g.Redeem(token, b)
+	require.ErrorIs(t, err, ErrToken)
+	assert.Contains(t, err.Error(), "different parameters")
+	require.ErrorIs(t, g.Redeem(token, a), ErrToken, "a failed attempt uses the token up")
+
+	token, _ = g.Issue(a)
+	now = now.Add(TokenTTL + time.Second)
+	err = g.Redeem(token, a)
+	require.ErrorIs(t, err, ErrToken)
+	assert.Contains(t, err.Error(), "expired")
+}
+
+func TestIssueBounded(t *testing.T) {
+	t.Parallel()
+
+	g, err := New(10, 0)
+	require.NoError(t, err)
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	g.now = func() time.Time { return now }
+
+	first, _ := g.Issue("f")
+	for range maxPending {
+		now = now.Add(time.Second)
+		g.Issue("f")
+	}
+	assert.Len(t, g.pending, maxPending)
+	require.ErrorIs(t, g.Redeem(first, "f"), ErrToken)
+}
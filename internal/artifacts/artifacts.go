@@ -0,0 +1,76 @@
+// Package artifacts keeps the files runs leave behind, such as their raw
+// samples, in a directory per run, so later tool calls can read them.
+package artifacts
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+)
+
+// DefaultMaxRuns bounds the runs whose artifacts are kept; the directories
+// of older runs are removed.
+const DefaultMaxRuns = 20
+
+// Store hands out the artifact directories of runs.
+type Store struct {
+	mu sync.Mutex
+	// root holds the artifact directories, created on first use when empty.
+	root  string
+	max   int
+	seq   int
+	dirs  map[string]string
+	order []string
+}
+
+// New returns a store keeping the artifacts of the last maxRuns runs in
+// root, or in a new temporary directory when root is empty.
+func New(root string, maxRuns int) *Store {
+	return &Store{root: root, max: maxRuns, dirs: make(map[string]string)}
+}
+
+// Create creates the artifact directory of a new run, and returns its ID.
+func (s *Store) Create() (string, string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.root == "" {
+		//nolint:forbidigo // The artifact directories live under a private temporary directory
+		root, err := os.MkdirTemp("", "mcp-k6-artifacts-")
+		if err != nil {
+			return "", "", fmt.Errorf("failed to create the artifact directory: %w", err)
+		}
+		s.root = root
+	}
+
+	s.seq++
+	id := "artifact-" + strconv.Itoa(s.seq)
+	dir := filepath.Join(s.root, id)
+	//nolint:forbidigo // Per-run artifact directory, readable by the server only
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", "", fmt.Errorf("failed to create the artifact directory: %w", err)
+	}
+	s.dirs[id] = dir
+	s.order = append(s.order, id)
+	for len(s.order) > s.max {
+		oldest := s.order[0]
+		s.order = s.order[1:]
+		//nolint:forbidigo // Removing the artifacts of old runs
+		_ = os.RemoveAll(s.dirs[oldest])
+		delete(s.dirs, oldest)
+	}
+	return id, dir, nil
+}
+
+// Dir returns the artifact directory of the run with the given ID.
+func (s *Store) Dir(id string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	dir, ok := s.dirs[id]
+	if !ok {
+		return "", fmt.Errorf("unknown artifact_id %q; the artifacts of the last %d runs are kept", id, s.max)
+	}
+	return dir, nil
+}
@@ -0,0 +1,37 @@
+package artifacts
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	s := New(root, 2)
+
+	first, dir, err := s.Create()
+	require.NoError(t, err)
+	assert.Equal(t, "artifact-1", first)
+	assert.Equal(t, filepath.Join(root, first), dir)
+	require.DirExists(t, dir)
+
+	got, err := s.Dir(first)
+	require.NoError(t, err)
+	assert.Equal(t, dir, got)
+
+	// Only the last 2 runs keep their artifacts
+	_, _, err = s.Create()
+	require.NoError(t, err)
+	_, _, err = s.Create()
+	require.NoError(t, err)
+	_, err = s.Dir(first)
+	require.ErrorContains(t, err, `unknown artifact_id "artifact-1"`)
+	_, err = os.Stat(dir)
+	assert.True(t, os.IsNotExist(err))
+}
@@ -0,0 +1,153 @@
+// Package audit records every subprocess the server spawns, with its
+// arguments, initiating session and tool, and exit status, into an
+// append-only JSON Lines file for security reviews of agent-executed
+// commands.
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/mcp-k6/internal/logging"
+)
+
+// ErrInvalid is returned for audit log files that cannot be opened.
+var ErrInvalid = errors.New("invalid audit log")
+
+type contextKey struct{}
+
+// Caller identifies who initiated a command.
+type Caller struct {
+	// Session is the ID of the MCP client session, if any.
+	Session string
+	// Tool is the name of the tool whose call spawned the command.
+	Tool string
+}
+
+// Entry is one line of the audit log.
+type Entry struct {
+	Time       string   `json:"time"`
+	Session    string   `json:"session,omitempty"`
+	Tool       string   `json:"tool,omitempty"`
+	Command    string   `json:"command"`
+	Args       []string `json:"args"`
+	Dir        string   `json:"dir,omitempty"`
+	ExitCode   int      `json:"exit_code"`
+	Error      string   `json:"error,omitempty"`
+	DurationMS int64    `json:"duration_ms"`
+}
+
+// Log appends entries to an audit log file. A nil *Log records nothing.
+type Log struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// Open opens path for appending, creating it if needed.
+func Open(path string) (*Log, error) {
+	// #nosec G304 -- the audit log path is set by the server operator
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalid, err)
+	}
+	return &Log{file: file}, nil
+}
+
+// Close closes the file.
+func (l *Log) Close() error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.file.Close()
+}
+
+// Write appends e as one line.
+func (l *Log) Write(e Entry) error {
+	if l == nil {
+		return nil
+	}
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, err = l.file.Write(append(line, '\n'))
+	return err
+}
+
+// ContextWithLog returns a copy of ctx carrying l and the caller of the
+// commands spawned with it.
+func ContextWithLog(ctx context.Context, l *Log, caller Caller) context.Context {
+	return context.WithValue(ctx, contextKey{}, &scope{log: l, caller: caller})
+}
+
+type scope struct {
+	log    *Log
+	caller Caller
+}
+
+// Command records cmd, which ran from start and returned err, in the log of
+// ctx, if any. The values of --env flags are redacted. Failing to write the
+// entry is logged, not returned, so auditing never fails a tool call.
+func Command(ctx context.Context, cmd *exec.Cmd, start time.Time, err error) {
+	s, _ := ctx.Value(contextKey{}).(*scope)
+	if s == nil || s.log == nil {
+		return
+	}
+
+	e := Entry{
+		Time:       start.UTC().Format(time.RFC3339Nano),
+		Session:    s.caller.Session,
+		Tool:       s.caller.Tool,
+		Command:    cmd.Path,
+		Args:       []string{},
+		Dir:        cmd.Dir,
+		ExitCode:   -1,
+		DurationMS: time.Since(start).Milliseconds(),
+	}
+	if len(cmd.Args) > 1 {
+		e.Args = RedactArgs(cmd.Args[1:])
+	}
+	if cmd.ProcessState != nil {
+		e.ExitCode = cmd.ProcessState.ExitCode()
+	}
+	if err != nil {
+		e.Error = err.Error()
+	}
+
+	if werr := s.log.Write(e); werr != nil {
+		logging.LoggerFromContext(ctx).WarnContext(ctx, "Failed to write audit log entry",
+			slog.String("command", e.Command), slog.String("error", werr.Error()))
+	}
+}
+
+// RedactArgs returns a copy of args with the values of --env flags replaced,
+// keeping the variable names, so secrets from an env file are not recorded.
+func RedactArgs(args []string) []string {
+	out := make([]string, len(args))
+	copy(out, args)
+	for i := 1; i < len(out); i++ {
+		if out[i-1] == "--env" || out[i-1] == "-e" {
+			name, _, _ := strings.Cut(out[i], "=")
+			out[i] = name + "=<redacted>"
+		}
+	}
+	for i, arg := range out {
+		if name, ok := strings.CutPrefix(arg, "--env="); ok {
+			name, _, _ = strings.Cut(name, "=")
+			out[i] = "--env=" + name + "=<redacted>"
+		}
+	}
+	return out
+}
@@ -0,0 +1,96 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readEntries(t *testing.T, path string) []Entry {
+	t.Helper()
+	data, err := os.ReadFile(path) //nolint:forbidigo // Test reads the audit log back.
+	require.NoError(t, err)
+	var entries []Entry
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var e Entry
+		require.NoError(t, json.Unmarshal([]byte(line), &e))
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+func TestCommand(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	l, err := Open(path)
+	require.NoError(t, err)
+	ctx := ContextWithLog(context.Background(), l, Caller{Session: "s-1", Tool: "run_script"})
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", "exit 3", "--env", "TOKEN=abc")
+	cmd.Dir = t.TempDir()
+	start := time.Now()
+	Command(ctx, cmd, start, cmd.Run())
+
+	missing := exec.CommandContext(ctx, filepath.Join(t.TempDir(), "missing"))
+	Command(ctx, missing, time.Now(), missing.Run())
+
+	// Commands outside an audited call are not recorded
+	Command(context.Background(), exec.CommandContext(ctx, "true"), time.Now(), nil)
+	require.NoError(t, l.Close())
+
+	// Reopening appends
+	l, err = Open(path)
+	require.NoError(t, err)
+	ok := exec.CommandContext(ctx, "true")
+	Command(ContextWithLog(context.Background(), l, Caller{Tool: "info"}), ok, time.Now(), ok.Run())
+	require.NoError(t, l.Close())
+
+	entries := readEntries(t, path)
+	require.Len(t, entries, 3)
+	assert.Equal(t, "s-1", entries[0].Session)
+	assert.Equal(t, "run_script", entries[0].Tool)
+	assert.Equal(t, cmd.Path, entries[0].Command)
+	assert.Equal(t, []string{"-c", "exit 3", "--env", "TOKEN=<redacted>"}, entries[0].Args)
+	assert.Equal(t, cmd.Dir, entries[0].Dir)
+	assert.Equal(t, 3, entries[0].ExitCode)
+	assert.Contains(t, entries[0].Error, "exit status 3")
+	assert.Equal(t, start.UTC().Format(time.RFC3339Nano), entries[0].Time)
+
+	assert.Equal(t, -1, entries[1].ExitCode)
+	assert.Equal(t, []string{}, entries[1].Args)
+	assert.NotEmpty(t, entries[1].Error)
+
+	assert.Equal(t, "info", entries[2].Tool)
+	assert.Equal(t, 0, entries[2].ExitCode)
+	assert.Empty(t, entries[2].Error)
+}
+
+func TestOpen(t *testing.T) {
+	t.Parallel()
+
+	_, err := Open(filepath.Join(t.TempDir(), "missing", "audit.jsonl"))
+	require.ErrorIs(t, err, ErrInvalid)
+
+	var l *Log
+	require.NoError(t, l.Write(Entry{}))
+	require.NoError(t, l.Close())
+}
+
+func TestRedactArgs(t *testing.T) {
+	t.Parallel()
+
+	args := []string{"run", "--env", "A=1", "-e", "B", "--env=C=3", "--vus", "10", "script.js"}
+	assert.Equal(t, []string{
+		"run", "--env", "A=<redacted>", "-e", "B=<redacted>", "--env=C=<redacted>", "--vus", "10", "script.js",
+	}, RedactArgs(args))
+	assert.Equal(t, "A=1", args[2])
+}
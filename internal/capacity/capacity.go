@@ -0,0 +1,284 @@
+// Package capacity estimates the load a system under test sustains from the
+// samples of a run whose load ramps up: the load of the last period of the
+// run before its error rate or latency first degraded.
+package capacity
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/grafana/mcp-k6/internal/samples"
+)
+
+// Metrics of the samples Read reads.
+const (
+	durationMetric   = "http_req_duration"
+	failedMetric     = "http_req_failed"
+	requestsMetric   = "http_reqs"
+	iterationsMetric = "iterations"
+	vusMetric        = "vus"
+)
+
+const (
+	// Windows is the number of periods of equal duration a run is split
+	// into.
+	Windows = 30
+	// minRequests is the number of requests a period needs to be judged.
+	minRequests = 10
+	// DefaultErrorRate is the share of failed requests degrading a run
+	// without criteria.
+	DefaultErrorRate = 0.01
+	// DefaultLatencyFactor is how many times the p95 latency of the first
+	// judged period degrades a run without criteria.
+	DefaultLatencyFactor = 2
+)
+
+// Criterion is a condition every period of the run must meet.
+type Criterion struct {
+	Name string
+	// Latency criteria bound a percentile of http_req_duration, the others
+	// the rate of http_req_failed.
+	Latency bool
+	// Percentile is the percentile of latency criteria, such as 95.
+	Percentile float64
+	// Limit is the latency in milliseconds, or the failed rate, the
+	// criterion allows.
+	Limit float64
+	// Factor, when set instead of Limit, bounds the latency to this many
+	// times its value in the first judged period.
+	Factor float64
+	// Tags select the requests the criterion applies to.
+	Tags map[string]string
+}
+
+// DefaultCriteria returns the criteria of runs without SLOs: at most
+// DefaultErrorRate failed requests, and a p95 latency within
+// DefaultLatencyFactor times the one at the lowest load.
+func DefaultCriteria() []Criterion {
+	return []Criterion{
+		{Name: "error_rate", Limit: DefaultErrorRate},
+		{Name: "latency", Latency: true, Percentile: 95, Factor: DefaultLatencyFactor},
+	}
+}
+
+// Load is the load applied in a period of the run.
+type Load struct {
+	// At is the end of the period, since the start of the run.
+	At  string  `json:"at"`
+	VUs float64 `json:"vus"`
+	// RequestRate and IterationRate are per second.
+	RequestRate   float64 `json:"request_rate"`
+	IterationRate float64 `json:"iteration_rate"`
+}
+
+// Estimate is the capacity of the system under test estimated from a run.
+type Estimate struct {
+	// Degraded reports whether a criterion failed during the run; if not,
+	// Capacity is the period with the highest request rate, a lower bound.
+	Degraded bool `json:"degraded"`
+	// Capacity is the load of the last period meeting every criterion
+	// before the first that did not, nil when the run degraded from the
+	// start.
+	Capacity *Load `json:"capacity,omitempty"`
+	// DegradedAt is the load of the first period failing a criterion.
+	DegradedAt *Load `json:"degraded_at,omitempty"`
+	// Criterion names the failed criterion, with its observed value and
+	// limit.
+	Criterion string  `json:"criterion,omitempty"`
+	Observed  float64 `json:"observed,omitempty"`
+	Limit     float64 `json:"limit,omitempty"`
+	Message   string  `json:"message"`
+}
+
+// window holds the samples of a period of the run.
+type window struct {
+	vus        float64
+	requests   float64
+	iterations float64
+	// durations and failed hold the samples of each criterion.
+	durations [][]float64
+	failed    [][]float64
+}
+
+// Read reads the JSON output of a run from r and returns its capacity
+// against criteria, or nil when the run has too few requests to tell.
+func Read(r io.Reader, criteria []Criterion) (*Estimate, error) {
+	var all []samples.Sample
+	err := samples.Read(r, samples.FormatJSON, func(s samples.Sample) bool {
+		switch s.Metric {
+		case durationMetric, failedMetric, requestsMetric, iterationsMetric, vusMetric:
+			all = append(all, s)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(all) == 0 {
+		return nil, nil //nolint:nilnil // A run without samples has no capacity to estimate
+	}
+
+	start, end := all[0].Time, all[0].Time
+	for _, s := range all {
+		if s.Time.Before(start) {
+			start = s.Time
+		}
+		if s.Time.After(end) {
+			end = s.Time
+		}
+	}
+	width := end.Sub(start)/Windows + 1
+	windows := make([]window, Windows)
+	for i := range windows {
+		windows[i].durations = make([][]float64, len(criteria))
+		windows[i].failed = make([][]float64, len(criteria))
+	}
+	for _, s := range all {
+		w := &windows[min(int(s.Time.Sub(start)/width), Windows-1)]
+		switch s.Metric {
+		case vusMetric:
+			w.vus = max(w.vus, s.Value)
+		case requestsMetric:
+			w.requests += s.Value
+		case iterationsMetric:
+			w.iterations += s.Value
+		}
+		for i, c := range criteria {
+			if !matches(s.Tags, c.Tags) {
+				continue
+			}
+			if c.Latency && s.Metric == durationMetric {
+				w.durations[i] = append(w.durations[i], s.Value)
+			}
+			if !c.Latency && s.Metric == failedMetric {
+				w.failed[i] = append(w.failed[i], s.Value)
+			}
+		}
+	}
+
+	load := func(k int) *Load {
+		w := windows[k]
+		return &Load{
+			At:            (time.Duration(k+1) * width).Round(time.Second).String(),
+			VUs:           w.vus,
+			RequestRate:   round(w.requests / width.Seconds()),
+			IterationRate: round(w.iterations / width.Seconds()),
+		}
+	}
+	limits := make([]float64, len(criteria))
+	for i, c := range criteria {
+		limits[i] = c.Limit
+	}
+	var last, peak *Load
+	for k := range windows {
+		judged := false
+		for i, c := range criteria {
+			observed, ok := windows[k].observe(i, c)
+			if !ok {
+				continue
+			}
+			judged = true
+			if c.Factor > 0 && limits[i] == 0 {
+				limits[i] = round(c.Factor * observed)
+				continue
+			}
+			if observed > limits[i] {
+				return degraded(last, load(k), c, observed, limits[i]), nil
+			}
+		}
+		if judged {
+			last = load(k)
+			if peak == nil || last.RequestRate > peak.RequestRate {
+				peak = last
+			}
+		}
+	}
+	if peak == nil {
+		return nil, nil //nolint:nilnil // Too few requests in every period to judge
+	}
+	return &Estimate{
+		Capacity: peak,
+		Message: fmt.Sprintf("every criterion held up to %s VUs and %s requests/s; the capacity is higher "+
+			"than the run reached", format(peak.VUs), format(peak.RequestRate)),
+	}, nil
+}
+
+// observe returns the value of criterion i in the window, and whether the
+// window has enough requests to judge it.
+func (w window) observe(i int, c Criterion) (float64, bool) {
+	if c.Latency {
+		if len(w.durations[i]) < minRequests {
+			return 0, false
+		}
+		return percentile(w.durations[i], c.Percentile), true
+	}
+	if len(w.failed[i]) < minRequests {
+		return 0, false
+	}
+	var failed float64
+	for _, v := range w.failed[i] {
+		failed += v
+	}
+	return round(failed / float64(len(w.failed[i]))), true
+}
+
+func degraded(last, at *Load, c Criterion, observed, limit float64) *Estimate {
+	e := &Estimate{
+		Degraded:   true,
+		Capacity:   last,
+		DegradedAt: at,
+		Criterion:  c.Name,
+		Observed:   observed,
+		Limit:      limit,
+	}
+	what := fmt.Sprintf("the failed rate reached %s, over %s", format(observed), format(limit))
+	if c.Latency {
+		what = fmt.Sprintf("p(%s) latency reached %s ms, over %s ms", format(c.Percentile), format(observed),
+			format(limit))
+	}
+	if last == nil {
+		e.Message = fmt.Sprintf("%s failed from the start of the run at %s VUs: %s; "+
+			"the capacity is below the lowest load of the run", c.Name, format(at.VUs), what)
+		return e
+	}
+	e.Message = fmt.Sprintf("%s first failed at %s with %s VUs and %s requests/s: %s; "+
+		"the system sustained %s VUs and %s requests/s", c.Name, at.At, format(at.VUs), format(at.RequestRate),
+		what, format(last.VUs), format(last.RequestRate))
+	return e
+}
+
+func matches(tags, want map[string]string) bool {
+	for name, value := range want {
+		if tags[name] != value {
+			return false
+		}
+	}
+	return true
+}
+
+// percentile returns the p-th percentile of values, interpolating between
+// ranks like k6.
+func percentile(values []float64, p float64) float64 {
+	sort.Float64s(values)
+	rank := p / 100 * float64(len(values)-1)
+	lower := int(rank)
+	v := values[lower]
+	if lower+1 < len(values) {
+		v += (values[lower+1] - values[lower]) * (rank - float64(lower))
+	}
+	return round(v)
+}
+
+// round rounds v to 4 decimals.
+func round(v float64) float64 {
+	return math.Round(v*10000) / 10000
+}
+
+// format formats v with up to 2 decimals.
+func format(v float64) string {
+	return strconv.FormatFloat(math.Round(v*100)/100, 'f', -1, 64)
+}
@@ -0,0 +1,78 @@
+package capacity
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ramp writes the JSON output of a 5-minute ramp from 1 to 30 VUs sending 2
+// requests a second, whose latency goes from 100 ms to 400 ms past 20 VUs.
+func ramp() string {
+	var b strings.Builder
+	start := time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)
+	point := func(metric string, i int, value float64, tags string) {
+		fmt.Fprintf(&b, `{"metric":%q,"type":"Point","data":{"time":%q,"value":%v,"tags":{%s}}}`+"\n",
+			metric, start.Add(time.Duration(i)*time.Second).Format(time.RFC3339), value, tags)
+	}
+	for i := range 300 {
+		vus := 1 + i/10
+		point("vus", i, float64(vus), "")
+		latency := 100.0
+		if vus > 20 {
+			latency = 400
+		}
+		for _, name := range []string{"home", "login"} {
+			tags := fmt.Sprintf(`"name":%q`, name)
+			point("http_reqs", i, 1, tags)
+			point("http_req_duration", i, latency, tags)
+			point("http_req_failed", i, 0, tags)
+		}
+		point("iterations", i, 1, "")
+	}
+	return b.String()
+}
+
+func TestRead(t *testing.T) {
+	t.Parallel()
+
+	e, err := Read(strings.NewReader(ramp()), DefaultCriteria())
+	require.NoError(t, err)
+	require.NotNil(t, e)
+	assert.True(t, e.Degraded)
+	assert.Equal(t, "latency", e.Criterion)
+	assert.InDelta(t, 400, e.Observed, 0)
+	assert.InDelta(t, 200, e.Limit, 0)
+	require.NotNil(t, e.Capacity)
+	assert.InDelta(t, 20, e.Capacity.VUs, 0)
+	assert.Equal(t, "3m19s", e.Capacity.At)
+	assert.InDelta(t, 2.0067, e.Capacity.RequestRate, 0)
+	assert.InDelta(t, 21, e.DegradedAt.VUs, 0)
+	assert.Equal(t, "latency first failed at 3m29s with 21 VUs and 2.01 requests/s: p(95) latency reached 400 ms, "+
+		"over 200 ms; the system sustained 20 VUs and 2.01 requests/s", e.Message)
+
+	// An SLO on the errors of one endpoint holds for the whole run
+	e, err = Read(strings.NewReader(ramp()), []Criterion{{Name: "login-errors", Limit: 0.01,
+		Tags: map[string]string{"name": "login"}}})
+	require.NoError(t, err)
+	require.NotNil(t, e)
+	assert.False(t, e.Degraded)
+	assert.Contains(t, e.Message, "every criterion held up to")
+
+	// A latency SLO failing from the start
+	e, err = Read(strings.NewReader(ramp()), []Criterion{{Name: "fast", Latency: true, Percentile: 99, Limit: 50}})
+	require.NoError(t, err)
+	require.NotNil(t, e)
+	assert.True(t, e.Degraded)
+	assert.Nil(t, e.Capacity)
+	assert.Contains(t, e.Message, "the capacity is below the lowest load of the run")
+
+	e, err = Read(strings.NewReader(`{"metric":"vus","type":"Point","data":{"time":"2026-01-02T10:00:00Z","value":1}}`),
+		DefaultCriteria())
+	require.NoError(t, err)
+	assert.Nil(t, e)
+}
@@ -0,0 +1,311 @@
+// Package compat tells the k6 version a script needs: the oldest
+// documentation version documenting each module, API symbol, option and
+// executor the script uses.
+package compat
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/grafana/mcp-k6/internal/scriptinfo"
+	"github.com/grafana/mcp-k6/internal/symbols"
+	"github.com/grafana/xk6-docs/docs"
+)
+
+// Kinds of Feature.
+const (
+	KindModule   = "module"
+	KindSymbol   = "symbol"
+	KindOption   = "option"
+	KindExecutor = "executor"
+)
+
+// optionsSlug ends the slug of the reference of the k6 options, with a
+// heading per option.
+const optionsSlug = "k6-options/reference"
+
+//nolint:gochecknoglobals // Compiled once, read-only.
+var (
+	// reHeading matches the headings of the options reference.
+	reHeading = regexp.MustCompile(`(?m)^##\s+(.+?)\s*$`)
+	// reVersion matches a documentation version, "v1.4.x", or a k6
+	// version, "1.3.0" or "v0.57.0".
+	reVersion = regexp.MustCompile(`^v?(\d+)\.(\d+)`)
+)
+
+// Feature is a k6 module, API symbol, option or executor a script uses.
+type Feature struct {
+	Kind string `json:"kind"`
+	// Name is the module path, the qualified symbol such as
+	// "http.asyncRequest", the option or the executor.
+	Name string `json:"name"`
+	Line int    `json:"line,omitempty"`
+	// Since is the oldest documentation version documenting the feature,
+	// such as "v0.52.x", and Slug its section there.
+	Since string `json:"since,omitempty"`
+	Slug  string `json:"slug,omitempty"`
+}
+
+// Report is the k6 version a script needs.
+type Report struct {
+	// Required is the newest Since of the features, empty when the script
+	// uses none. Features documented since the oldest version may be older.
+	Required string `json:"required_version,omitempty"`
+	// Oldest and Latest bound the documentation versions searched.
+	Oldest string `json:"oldest_version"`
+	Latest string `json:"latest_version"`
+	// Features are documented by the latest version, in source order.
+	Features []Feature `json:"features"`
+	// Undocumented holds the modules and executors the latest version does
+	// not document: removed, renamed, misspelled or from extensions.
+	Undocumented []Feature `json:"undocumented,omitempty"`
+}
+
+// Catalog is the part of *docs.Catalog a Checker reads.
+type Catalog interface {
+	Versions() []string
+	Index(ctx context.Context, version string) (*docs.Index, error)
+	Read(ctx context.Context, version, slug string) ([]byte, error)
+}
+
+// Checker finds what documentation versions document, loading each version
+// once.
+type Checker struct {
+	catalog Catalog
+
+	mu       sync.Mutex
+	versions map[string]*version
+}
+
+// version holds what a documentation version documents.
+type version struct {
+	symbols *symbols.Index
+	// executors and options map executor and normalized option names to
+	// the section documenting them.
+	executors map[string]string
+	options   map[string]string
+}
+
+// NewChecker returns a Checker reading catalog.
+func NewChecker(catalog Catalog) *Checker {
+	return &Checker{catalog: catalog, versions: make(map[string]*version)}
+}
+
+// Check returns the features script uses and the oldest documentation
+// version of each. Features are assumed to stay documented once added, so
+// each is looked up in a few versions only.
+func (c *Checker) Check(ctx context.Context, script string) (*Report, error) {
+	versions := c.catalog.Versions()
+	if len(versions) == 0 {
+		return nil, errors.New("no documentation version is available")
+	}
+	// Versions are sorted latest first
+	oldest, latest := versions[len(versions)-1], versions[0]
+	report := &Report{Oldest: oldest, Latest: latest, Features: []Feature{}}
+
+	latestDocs, err := c.version(ctx, latest)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range Uses(script) {
+		if _, ok := latestDocs.documents(f); !ok {
+			if f.Kind == KindModule || f.Kind == KindExecutor {
+				report.Undocumented = append(report.Undocumented, f)
+			}
+			continue
+		}
+		if f, err = c.since(ctx, versions, f); err != nil {
+			return nil, err
+		}
+		report.Features = append(report.Features, f)
+		if report.Required == "" || Compare(f.Since, report.Required) > 0 {
+			report.Required = f.Since
+		}
+	}
+	return report, nil
+}
+
+// since sets the oldest version of versions, latest first, documenting f,
+// found by bisection.
+func (c *Checker) since(ctx context.Context, versions []string, f Feature) (Feature, error) {
+	// versions[lo] documents f, the versions older than versions[hi] do not
+	lo, hi := 0, len(versions)-1
+	for lo < hi {
+		mid := (lo + hi + 1) / 2
+		v, err := c.version(ctx, versions[mid])
+		if err != nil {
+			return f, err
+		}
+		if _, ok := v.documents(f); ok {
+			lo = mid
+		} else {
+			hi = mid - 1
+		}
+	}
+	v, err := c.version(ctx, versions[lo])
+	if err != nil {
+		return f, err
+	}
+	f.Since = versions[lo]
+	f.Slug, _ = v.documents(f)
+	return f, nil
+}
+
+func (c *Checker) version(ctx context.Context, name string) (*version, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if v, ok := c.versions[name]; ok {
+		return v, nil
+	}
+
+	idx, err := c.catalog.Index(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("loading documentation %s: %w", name, err)
+	}
+	v := &version{
+		symbols:   symbols.Build(idx, "", nil),
+		executors: make(map[string]string),
+		options:   make(map[string]string),
+	}
+	for i := range idx.Sections {
+		sec := &idx.Sections[i]
+		if parent, executor, ok := strings.Cut(sec.Slug, "/executors/"); ok && parent != "" && executor != "" {
+			v.executors[executor] = sec.Slug
+		}
+		if strings.HasSuffix(sec.Slug, optionsSlug) {
+			// An options reference that cannot be read leaves the options
+			// undocumented
+			content, _ := c.catalog.Read(ctx, name, sec.Slug)
+			for _, m := range reHeading.FindAllStringSubmatch(string(content), -1) {
+				v.options[normalize(m[1])] = sec.Slug
+			}
+		}
+	}
+	c.versions[name] = v
+	return v, nil
+}
+
+// documents returns the section documenting f.
+func (v *version) documents(f Feature) (string, bool) {
+	switch f.Kind {
+	case KindModule, KindSymbol:
+		for _, e := range v.symbols.Lookup(f.Name) {
+			if strings.EqualFold(e.Symbol, f.Name) {
+				return e.Slug, true
+			}
+		}
+		return "", false
+	case KindExecutor:
+		slug, ok := v.executors[f.Name]
+		return slug, ok
+	default:
+		slug, ok := v.options[normalize(f.Name)]
+		return slug, ok
+	}
+}
+
+// Uses returns the built-in modules the script imports, the symbols of
+// those modules it uses, its options and executors, in source order.
+func Uses(script string) []Feature {
+	info := scriptinfo.Analyze(script)
+	var features []Feature
+	seen := make(map[string]bool)
+	add := func(f Feature) {
+		if key := f.Kind + " " + f.Name; !seen[key] {
+			seen[key] = true
+			features = append(features, f)
+		}
+	}
+
+	for _, imp := range info.Imports {
+		if imp.Kind != "builtin" && imp.Kind != "experimental" {
+			continue
+		}
+		add(Feature{Kind: KindModule, Name: imp.Module, Line: imp.Line})
+		// Names are looked up both as named exports, such as check, and
+		// as the module object of default imports, such as http
+		for _, name := range imp.Names {
+			add(Feature{Kind: KindSymbol, Name: qualify(imp.Module, name), Line: imp.Line})
+		}
+		for _, m := range scriptinfo.Members(script, imp.Names) {
+			add(Feature{Kind: KindSymbol, Name: qualify(imp.Module, m.Name), Line: m.Line})
+		}
+	}
+
+	if info.Options != nil {
+		for _, p := range info.RawOptions() {
+			add(Feature{Kind: KindOption, Name: p.Key, Line: info.Options.Line})
+		}
+		for _, sc := range info.Scenarios {
+			if sc.Executor != "" {
+				add(Feature{Kind: KindExecutor, Name: sc.Executor, Line: info.Options.Line})
+			}
+		}
+	}
+
+	sort.SliceStable(features, func(i, j int) bool { return features[i].Line < features[j].Line })
+	return features
+}
+
+// qualify returns the name the symbol index gives a symbol of module: names
+// of the k6 module are bare, others are qualified by the last part of the
+// module path, such as http.get for get of k6/http.
+func qualify(module, name string) string {
+	if module == "k6" {
+		return name
+	}
+	return module[strings.LastIndexByte(module, '/')+1:] + "." + name
+}
+
+// normalize returns an option name or heading lowercase without spaces and
+// punctuation, so "Summary trend stats" matches summaryTrendStats.
+func normalize(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// Compare compares the major and minor parts of the versions a and b,
+// documentation versions or k6 versions, returning -1, 0 or 1. Unparsable
+// versions sort first.
+func Compare(a, b string) int {
+	am, an := parse(a)
+	bm, bn := parse(b)
+	switch {
+	case am != bm:
+		return sign(am - bm)
+	default:
+		return sign(an - bn)
+	}
+}
+
+func parse(v string) (int, int) {
+	m := reVersion.FindStringSubmatch(strings.TrimSpace(v))
+	if m == nil {
+		return -1, -1
+	}
+	major, _ := strconv.Atoi(m[1])
+	minor, _ := strconv.Atoi(m[2])
+	return major, minor
+}
+
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	default:
+		return 0
+	}
+}
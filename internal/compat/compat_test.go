@@ -0,0 +1,121 @@
+package compat
+
+import (
+	"encoding/json"
+	"testing"
+	"testing/fstest"
+
+	"github.com/grafana/xk6-docs/docs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const script = `import http from 'k6/http';
+import { sleep } from 'k6';
+import { browser } from 'k6/browser';
+import { chromium } from 'k6/experimental/browser';
+
+export const options = {
+  vus: 2,
+  scenarios: { api: { executor: 'constant-vus', duration: '1m' } },
+};
+
+export default async function () {
+  await http.asyncRequest('GET', 'https://test.k6.io');
+  const page = await browser.newPage();
+  sleep(1);
+}
+`
+
+// testCatalog returns a catalog of versions whose sections add, version by
+// version, the modules and symbols of the test script.
+func testCatalog(t *testing.T) *docs.Catalog {
+	t.Helper()
+
+	base := []docs.Section{
+		{Slug: "javascript-api/k6", Title: "k6"},
+		{Slug: "javascript-api/k6/sleep", Title: "sleep( t )"},
+		{Slug: "javascript-api/k6-http", Title: "k6/http"},
+		{Slug: "javascript-api/k6-http/get", Title: "get( url, [params] )"},
+		{Slug: "using-k6/scenarios/executors/constant-vus", Title: "Constant VUs"},
+		{Slug: "using-k6/k6-options/reference", RelPath: "using-k6/k6-options/reference.md", Title: "Options reference"},
+	}
+	browser := []docs.Section{
+		{Slug: "javascript-api/k6-browser", Title: "k6/browser"},
+		{Slug: "javascript-api/k6-browser/newpage", Title: "newPage()"},
+	}
+	asyncRequest := docs.Section{Slug: "javascript-api/k6-http/asyncrequest", Title: "asyncRequest( method, url )"}
+	experimental := docs.Section{Slug: "javascript-api/k6-experimental/browser", Title: "k6/experimental/browser"}
+
+	fsys := fstest.MapFS{}
+	for version, sections := range map[string][]docs.Section{
+		"v0.47.x": append([]docs.Section{experimental}, base...),
+		"v0.52.x": append(append([]docs.Section{experimental}, base...), browser...),
+		"v1.0.x":  append(append([]docs.Section{asyncRequest}, base...), browser...),
+		"v1.4.x":  append(append([]docs.Section{asyncRequest}, base...), browser...),
+	} {
+		index, err := json.Marshal(docs.Index{Version: version, Sections: sections})
+		require.NoError(t, err)
+		fsys[version+"/sections.json"] = &fstest.MapFile{Data: index}
+		fsys[version+"/markdown/using-k6/k6-options/reference.md"] = &fstest.MapFile{
+			Data: []byte("# Options reference\n\n## VUs\n\nThe number of VUs.\n\n## Scenarios\n\nScenarios.\n"),
+		}
+	}
+	return docs.NewCatalog(docs.WithFS(fsys))
+}
+
+func TestCheck(t *testing.T) {
+	t.Parallel()
+
+	report, err := NewChecker(testCatalog(t)).Check(t.Context(), script)
+	require.NoError(t, err)
+	assert.Equal(t, "v1.0.x", report.Required)
+	assert.Equal(t, "v0.47.x", report.Oldest)
+	assert.Equal(t, "v1.4.x", report.Latest)
+
+	since := make(map[string]string, len(report.Features))
+	for _, f := range report.Features {
+		since[f.Kind+" "+f.Name] = f.Since
+	}
+	assert.Equal(t, map[string]string{
+		"module k6/http":           "v0.47.x",
+		"module k6":                "v0.47.x",
+		"symbol sleep":             "v0.47.x",
+		"module k6/browser":        "v0.52.x",
+		"option vus":               "v0.47.x",
+		"option scenarios":         "v0.47.x",
+		"executor constant-vus":    "v0.47.x",
+		"symbol http.asyncRequest": "v1.0.x",
+		"symbol browser.newPage":   "v0.52.x",
+	}, since)
+	assert.Equal(t, []Feature{{Kind: KindModule, Name: "k6/experimental/browser", Line: 4}}, report.Undocumented)
+}
+
+func TestUses(t *testing.T) {
+	t.Parallel()
+
+	features := Uses(`import { check } from 'k6';
+import exec from 'k6/execution';
+import { Client } from 'k6/x/sql';
+
+export default function () {
+  check(exec.vu.idInTest, {});
+}
+`)
+	assert.Equal(t, []Feature{
+		{Kind: KindModule, Name: "k6", Line: 1},
+		{Kind: KindSymbol, Name: "check", Line: 1},
+		{Kind: KindModule, Name: "k6/execution", Line: 2},
+		{Kind: KindSymbol, Name: "execution.exec", Line: 2},
+		{Kind: KindSymbol, Name: "execution.vu", Line: 6},
+	}, features)
+}
+
+func TestCompare(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, -1, Compare("1.3.0", "v1.4.x"))
+	assert.Equal(t, 0, Compare("v1.4.2", "v1.4.x"))
+	assert.Equal(t, 1, Compare("v1.0.x", "v0.57.x"))
+	assert.Equal(t, -1, Compare("devel", "v0.47.x"))
+}
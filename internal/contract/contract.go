@@ -0,0 +1,196 @@
+// Package contract infers the shape of JSON responses from examples, for k6
+// checks asserting that responses keep the shape they were recorded with.
+package contract
+
+import (
+	"encoding/json"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// maxDepth bounds the nesting of inferred shapes; deeper values are "any".
+const maxDepth = 8
+
+// Types of values.
+const (
+	TypeString  = "string"
+	TypeNumber  = "number"
+	TypeBoolean = "boolean"
+	TypeNull    = "null"
+	TypeArray   = "array"
+	TypeObject  = "object"
+	TypeAny     = "any"
+)
+
+// Shape is the inferred shape of JSON values.
+type Shape struct {
+	// Type is the type of the values, or the types of all samples joined by
+	// "|" when they differ, such as "null|string".
+	Type string `json:"type"`
+	// Fields are the fields of objects.
+	Fields map[string]*Shape `json:"fields,omitempty"`
+	// Optional is set on the fields missing in some samples.
+	Optional bool `json:"optional,omitempty"`
+	// Items is the shape of the elements of arrays, nil when every sample
+	// was empty.
+	Items *Shape `json:"items,omitempty"`
+}
+
+// Infer returns the shape of the JSON document data.
+func Infer(data []byte) (*Shape, error) {
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+	return infer(v, 0), nil
+}
+
+func infer(v any, depth int) *Shape {
+	if depth >= maxDepth {
+		return &Shape{Type: TypeAny}
+	}
+	switch v := v.(type) {
+	case string:
+		return &Shape{Type: TypeString}
+	case float64:
+		return &Shape{Type: TypeNumber}
+	case bool:
+		return &Shape{Type: TypeBoolean}
+	case []any:
+		s := &Shape{Type: TypeArray}
+		for _, item := range v {
+			s.Items = Merge(s.Items, infer(item, depth+1))
+		}
+		return s
+	case map[string]any:
+		s := &Shape{Type: TypeObject, Fields: make(map[string]*Shape, len(v))}
+		for k, field := range v {
+			s.Fields[k] = infer(field, depth+1)
+		}
+		return s
+	default:
+		return &Shape{Type: TypeNull}
+	}
+}
+
+// Merge returns the shape of the values of a and b. Either may be nil.
+func Merge(a, b *Shape) *Shape {
+	switch {
+	case a == nil:
+		return b
+	case b == nil:
+		return a
+	case a.Type == TypeAny || b.Type == TypeAny:
+		return &Shape{Type: TypeAny, Optional: a.Optional || b.Optional}
+	case a.Type != b.Type:
+		types := append(strings.Split(a.Type, "|"), strings.Split(b.Type, "|")...)
+		sort.Strings(types)
+		return &Shape{Type: strings.Join(slices.Compact(types), "|"), Optional: a.Optional || b.Optional}
+	}
+
+	s := &Shape{Type: a.Type, Optional: a.Optional || b.Optional, Items: Merge(a.Items, b.Items)}
+	if a.Type == TypeObject {
+		s.Fields = make(map[string]*Shape, len(a.Fields))
+		for k, fa := range a.Fields {
+			fb, ok := b.Fields[k]
+			f := Merge(fa, fb)
+			if !ok {
+				f = optional(f)
+			}
+			s.Fields[k] = f
+		}
+		for k, fb := range b.Fields {
+			if _, ok := a.Fields[k]; !ok {
+				s.Fields[k] = optional(fb)
+			}
+		}
+	}
+	return s
+}
+
+func optional(s *Shape) *Shape {
+	c := *s
+	c.Optional = true
+	return &c
+}
+
+// JS returns s as the JavaScript literal Validator reads: a type name, an
+// array of the shape of the elements, or an object of the shapes of the
+// fields whose optional fields end with "?".
+func (s *Shape) JS() string {
+	switch {
+	case s.Type == TypeArray && s.Items == nil:
+		return "[]"
+	case s.Type == TypeArray:
+		return "[" + s.Items.JS() + "]"
+	case s.Type == TypeObject && len(s.Fields) == 0:
+		return "{}"
+	case s.Type == TypeObject:
+		names := make([]string, 0, len(s.Fields))
+		for k := range s.Fields {
+			names = append(names, k)
+		}
+		sort.Strings(names)
+		fields := make([]string, 0, len(names))
+		for _, k := range names {
+			f := s.Fields[k]
+			if f.Optional {
+				k += "?"
+			}
+			fields = append(fields, jsKey(k)+": "+f.JS())
+		}
+		return "{ " + strings.Join(fields, ", ") + " }"
+	default:
+		return "'" + s.Type + "'"
+	}
+}
+
+// jsKey returns k as an object key, quoted unless it is an identifier.
+func jsKey(k string) string {
+	ident := k != ""
+	for i, c := range k {
+		if c != '_' && c != '$' && (c < 'a' || c > 'z') && (c < 'A' || c > 'Z') && (i == 0 || c < '0' || c > '9') {
+			ident = false
+		}
+	}
+	if ident {
+		return k
+	}
+	data, _ := json.Marshal(k)
+	return string(data)
+}
+
+// Validator is the JavaScript source of hasShape(res, shape), which reports
+// whether the JSON body of a k6 response has a shape as JS returns it.
+const Validator = `// hasShape reports whether the JSON body of res has the recorded shape: a type
+// name ('string', 'null|number'), [shape] for arrays, or an object of field
+// shapes whose optional fields end with '?'.
+function hasShape(res, shape) {
+  let body;
+  try {
+    body = res.json();
+  } catch (e) {
+    return false;
+  }
+  return matchesShape(body, shape);
+}
+
+function matchesShape(value, shape) {
+  const type = value === null ? 'null' : Array.isArray(value) ? 'array' : typeof value;
+  if (typeof shape === 'string') {
+    return shape === 'any' || shape.split('|').includes(type);
+  }
+  if (Array.isArray(shape)) {
+    return type === 'array' && (shape.length === 0 || value.every((v) => matchesShape(v, shape[0])));
+  }
+  if (type !== 'object') {
+    return false;
+  }
+  return Object.entries(shape).every(([key, field]) => {
+    const optional = key.endsWith('?');
+    const name = optional ? key.slice(0, -1) : key;
+    return name in value ? matchesShape(value[name], field) : optional;
+  });
+}
+`
@@ -0,0 +1,42 @@
+package contract
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInfer(t *testing.T) {
+	t.Parallel()
+
+	a, err := Infer([]byte(`{"id": 1, "name": "margherita", "tags": ["vegetarian"], "chef": null, "price-eur": 9.5}`))
+	require.NoError(t, err)
+	b, err := Infer([]byte(`{"id": 2, "name": "diavola", "tags": [], "chef": "Luigi", "spicy": true}`))
+	require.NoError(t, err)
+
+	s := Merge(a, b)
+	assert.Equal(t, TypeObject, s.Type)
+	assert.Equal(t, "null|string", s.Fields["chef"].Type)
+	assert.True(t, s.Fields["spicy"].Optional)
+	assert.False(t, s.Fields["id"].Optional)
+	assert.Equal(t,
+		`{ chef: 'null|string', id: 'number', name: 'string', "price-eur?": 'number', "spicy?": 'boolean', `+
+			`tags: ['string'] }`,
+		s.JS())
+
+	_, err = Infer([]byte("<html>"))
+	assert.Error(t, err)
+}
+
+func TestShapeJS(t *testing.T) {
+	t.Parallel()
+
+	s, err := Infer([]byte(`[[], {}, 1]`))
+	require.NoError(t, err)
+	assert.Equal(t, "['array|number|object']", s.JS())
+
+	s, err = Infer([]byte(`[]`))
+	require.NoError(t, err)
+	assert.Equal(t, "[]", s.JS())
+}
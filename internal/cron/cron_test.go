@@ -0,0 +1,65 @@
+package cron
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNext(t *testing.T) {
+	t.Parallel()
+
+	// A Wednesday
+	from := time.Date(2026, time.March, 11, 10, 7, 30, 0, time.UTC)
+	tests := map[string]struct {
+		expr string
+		want time.Time
+	}{
+		"every minute":    {"* * * * *", time.Date(2026, 3, 11, 10, 8, 0, 0, time.UTC)},
+		"every 15 min":    {"*/15 * * * *", time.Date(2026, 3, 11, 10, 15, 0, 0, time.UTC)},
+		"hourly":          {"@hourly", time.Date(2026, 3, 11, 11, 0, 0, 0, time.UTC)},
+		"daily at 02:30":  {"30 2 * * *", time.Date(2026, 3, 12, 2, 30, 0, 0, time.UTC)},
+		"weekdays":        {"0 9 * * mon-fri", time.Date(2026, 3, 12, 9, 0, 0, 0, time.UTC)},
+		"sunday as 7":     {"0 0 * * 7", time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC)},
+		"monthly":         {"@monthly", time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)},
+		"named month":     {"0 0 1 jan *", time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)},
+		"list and range":  {"0 8,12-13 * * *", time.Date(2026, 3, 11, 12, 0, 0, 0, time.UTC)},
+		"day or weekday":  {"0 0 20 * fri", time.Date(2026, 3, 13, 0, 0, 0, 0, time.UTC)},
+		"leap day":        {"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		"stepped range":   {"0-30/10 10 * * *", time.Date(2026, 3, 11, 10, 10, 0, 0, time.UTC)},
+		"stepped from 5":  {"5/20 * * * *", time.Date(2026, 3, 11, 10, 25, 0, 0, time.UTC)},
+		"never fires":     {"0 0 30 2 *", time.Time{}},
+		"padded and case": {"  @DAILY ", time.Date(2026, 3, 12, 0, 0, 0, 0, time.UTC)},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			s, err := Parse(tt.expr)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, s.Next(from))
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	t.Parallel()
+
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"10-5 * * * *",
+		"* * * foo *",
+		"@every 5m",
+	} {
+		_, err := Parse(expr)
+		require.ErrorIs(t, err, ErrInvalid, expr)
+	}
+}
@@ -0,0 +1,172 @@
+// Package diagnostics locates the errors k6 reports, such as syntax errors,
+// exceptions and the esbuild errors of TypeScript scripts, by file, line and
+// column. k6 applies the source maps of TypeScript and bundled scripts to the
+// locations it prints, so they point at the sources.
+package diagnostics
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Diagnostic is an error located in a script.
+type Diagnostic struct {
+	File   string `json:"file"`
+	Line   int    `json:"line"`
+	Column int    `json:"column,omitempty"`
+	// Kind is the JavaScript error type, such as "SyntaxError", when known.
+	Kind    string `json:"kind,omitempty"`
+	Message string `json:"message"`
+	// CodeFrame shows the lines around the location, when the source is known.
+	CodeFrame string `json:"code_frame,omitempty"`
+}
+
+// Location returns the file:line:column of d.
+func (d Diagnostic) Location() string {
+	if d.Column == 0 {
+		return fmt.Sprintf("%s:%d", d.File, d.Line)
+	}
+	return fmt.Sprintf("%s:%d:%d", d.File, d.Line, d.Column)
+}
+
+// codeFrameContext is the number of lines a code frame shows around the
+// located one.
+const codeFrameContext = 2
+
+//nolint:gochecknoglobals // Compiled once, read-only.
+var (
+	// reParse matches the parse errors of k6, for JavaScript and for the
+	// esbuild errors of TypeScript: "SyntaxError: file:///t.js: Line 3:10
+	// Unexpected token (and 1 more errors)".
+	reParse = regexp.MustCompile(
+		`(?m)(?:(\w*Error): )?(\S+?): Line (\d+):(\d+) (.+?)(?: \(and \d+ more errors?\))?$`)
+	// reEsbuild matches the errors of the esbuild CLI, whose columns start
+	// at 0: "✘ [ERROR] Expected ";"" then "    src/test.ts:3:6:".
+	reEsbuild = regexp.MustCompile(`(?:✘|X) \[ERROR\] ([^\n]+)\n\s*\n\s+(\S+?):(\d+):(\d+):`)
+	// reException matches an uncaught exception: "ReferenceError: foo is not defined".
+	reException = regexp.MustCompile(`(?m)^(?:Uncaught \(in promise\) )?(\w*Error): (.+)$`)
+	// reFrame matches the stack frames of a script location, with or without
+	// the function: "at default (file:///t.js:5:3(4))" or "at file:///t.js:5:3(4)".
+	reFrame = regexp.MustCompile(`(?m)^\s*(?:running )?at (?:[^\n(]*\()?(\S+?):(\d+):(\d+)(?:\(\d+\))?\)?\s*$`)
+)
+
+// Parse returns the diagnostics of k6 output, in order and without
+// duplicates. Lines logged as JSON, with --log-format=json, are read by
+// their message.
+func Parse(output string) []Diagnostic {
+	var out []Diagnostic
+	seen := make(map[Diagnostic]bool)
+	add := func(d Diagnostic) {
+		d.File = filePath(d.File)
+		d.Message = strings.TrimSpace(d.Message)
+		if !seen[d] {
+			seen[d] = true
+			out = append(out, d)
+		}
+	}
+	for _, msg := range messages(output) {
+		for _, m := range reParse.FindAllStringSubmatch(msg, -1) {
+			add(Diagnostic{File: m[2], Line: atoi(m[3]), Column: atoi(m[4]), Kind: m[1], Message: m[5]})
+		}
+		for _, m := range reEsbuild.FindAllStringSubmatch(msg, -1) {
+			add(Diagnostic{File: m[2], Line: atoi(m[3]), Column: atoi(m[4]) + 1, Kind: "SyntaxError", Message: m[1]})
+		}
+		if reParse.MatchString(msg) {
+			continue
+		}
+		if m := reException.FindStringSubmatchIndex(msg); m != nil {
+			// The first frame of a script is where the exception was thrown
+			if frame := reFrame.FindStringSubmatch(msg[m[1]:]); frame != nil {
+				add(Diagnostic{
+					File: frame[1], Line: atoi(frame[2]), Column: atoi(frame[3]),
+					Kind: msg[m[2]:m[3]], Message: msg[m[4]:m[5]],
+				})
+			}
+		}
+	}
+	return out
+}
+
+// CodeFrame returns the lines of source around line, marking it and, when
+// column is known, the column:
+//
+//	  2 | import http from 'k6/http';
+//	> 3 | const x = ;
+//	    |           ^
+func CodeFrame(source string, line, column int) string {
+	lines := strings.Split(source, "\n")
+	if line < 1 || line > len(lines) {
+		return ""
+	}
+	first, last := max(1, line-codeFrameContext), min(len(lines), line+codeFrameContext)
+	width := len(strconv.Itoa(last))
+
+	var b strings.Builder
+	for n := first; n <= last; n++ {
+		marker := " "
+		if n == line {
+			marker = ">"
+		}
+		text := strings.TrimRight(lines[n-1], "\r")
+		b.WriteString(strings.TrimRight(fmt.Sprintf("%s %*d | %s", marker, width, n, text), " "))
+		b.WriteByte('\n')
+		if n == line && column > 0 && column <= len(text)+1 {
+			// Tabs keep their width, so the caret lines up in a terminal
+			pad := strings.Map(func(r rune) rune {
+				if r == '\t' {
+					return r
+				}
+				return ' '
+			}, text[:column-1])
+			fmt.Fprintf(&b, "  %*s | %s^\n", width, "", pad)
+		}
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// messages returns the messages of output: the message of each JSON log
+// line, and the other lines together.
+func messages(output string) []string {
+	var out []string
+	var plain []string
+	for line := range strings.SplitSeq(output, "\n") {
+		var entry struct {
+			Msg   string `json:"msg"`
+			Error string `json:"error"`
+		}
+		if strings.HasPrefix(line, "{") && json.Unmarshal([]byte(line), &entry) == nil {
+			out = append(out, entry.Msg)
+			if entry.Error != "" {
+				out = append(out, entry.Error)
+			}
+			continue
+		}
+		plain = append(plain, line)
+	}
+	if text := strings.Join(plain, "\n"); strings.TrimSpace(text) != "" {
+		out = append(out, text)
+	}
+	return out
+}
+
+// filePath returns the path of a file URL, as k6 prints local files.
+func filePath(file string) string {
+	file = strings.TrimSuffix(file, ":")
+	rest, ok := strings.CutPrefix(file, "file://")
+	if !ok {
+		return file
+	}
+	if unescaped, err := url.PathUnescape(rest); err == nil {
+		return unescaped
+	}
+	return rest
+}
+
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
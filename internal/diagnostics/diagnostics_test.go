@@ -0,0 +1,87 @@
+package diagnostics
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name   string
+		output string
+		want   []Diagnostic
+	}{
+		{
+			name: "parse error logged as JSON",
+			output: `{"level":"error","msg":"SyntaxError: file:///tmp/k6-run-1.js: Line 3:11 ` +
+				`Unexpected token ; (and 1 more errors)","time":"2025-01-01T00:00:00Z"}` + "\n",
+			want: []Diagnostic{{
+				File: "/tmp/k6-run-1.js", Line: 3, Column: 11, Kind: "SyntaxError", Message: "Unexpected token ;",
+			}},
+		},
+		{
+			name: "TypeScript error",
+			output: `{"level":"error","msg":"file:///work/My%20Tests/test.ts: Line 4:9 ` +
+				`Expected \";\" but found \"foo\""}`,
+			want: []Diagnostic{{
+				File: "/work/My Tests/test.ts", Line: 4, Column: 9, Message: `Expected ";" but found "foo"`,
+			}},
+		},
+		{
+			name: "exception with a stack",
+			output: `{"level":"error","msg":"Uncaught (in promise) ReferenceError: foo is not defined\n` +
+				`\tat reflect.methodValueCall (native)\n\tat default (file:///work/test.js:5:3(4))\n` +
+				`\tat file:///work/test.js:9:1(12)\n","source":"stacktrace"}`,
+			want: []Diagnostic{{
+				File: "/work/test.js", Line: 5, Column: 3, Kind: "ReferenceError", Message: "foo is not defined",
+			}},
+		},
+		{
+			name:   "esbuild CLI",
+			output: "✘ [ERROR] Expected \";\" but found \"foo\"\n\n    src/test.ts:3:6:\n      3 │ const x foo = 1;\n",
+			want: []Diagnostic{{
+				File: "src/test.ts", Line: 3, Column: 7, Kind: "SyntaxError", Message: `Expected ";" but found "foo"`,
+			}},
+		},
+		{
+			name:   "duplicates",
+			output: "TypeError: x is null\n\tat file:///t.js:2:4(1)\n" + `{"msg":"TypeError: x is null\n\tat file:///t.js:2:4(1)"}`,
+			want:   []Diagnostic{{File: "/t.js", Line: 2, Column: 4, Kind: "TypeError", Message: "x is null"}},
+		},
+		{
+			name:   "no location",
+			output: `{"level":"error","msg":"GoError: the body is null"}` + "\nthresholds on metrics 'checks' have been crossed",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tc.want, Parse(tc.output))
+		})
+	}
+}
+
+func TestCodeFrame(t *testing.T) {
+	t.Parallel()
+
+	source := "import http from 'k6/http';\n\nexport default function () {\n\tconst x = ;\n}\n"
+	assert.Equal(t, ""+
+		"  2 |\n"+
+		"  3 | export default function () {\n"+
+		"> 4 | \tconst x = ;\n"+
+		"    | \t          ^\n"+
+		"  5 | }\n"+
+		"  6 |", CodeFrame(source, 4, 12))
+	assert.Equal(t, "> 1 | import http from 'k6/http';\n  2 |\n  3 | export default function () {",
+		CodeFrame(source, 1, 0))
+	assert.Empty(t, CodeFrame(source, 9, 1))
+}
+
+func TestLocation(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "test.js:3:7", Diagnostic{File: "test.js", Line: 3, Column: 7}.Location())
+	assert.Equal(t, "test.js:3", Diagnostic{File: "test.js", Line: 3}.Location())
+}
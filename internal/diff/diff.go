@@ -0,0 +1,234 @@
+// Package diff computes line-based unified diffs between script versions and
+// applies unified diff patches back onto a script.
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Kind is the type of a single line edit.
+type Kind int
+
+const (
+	// Equal marks a line present in both versions.
+	Equal Kind = iota
+	// Delete marks a line only present in the old version.
+	Delete
+	// Insert marks a line only present in the new version.
+	Insert
+)
+
+// Edit is a single line of an edit script. Line keeps its trailing newline,
+// if any, so that a missing newline at the end of a file is a visible change.
+type Edit struct {
+	Kind Kind
+	Line string
+}
+
+// Stats summarizes a diff.
+type Stats struct {
+	Added   int `json:"added"`
+	Removed int `json:"removed"`
+	Hunks   int `json:"hunks"`
+}
+
+// DefaultContext is the number of unchanged lines shown around each change.
+const DefaultContext = 3
+
+// splitLines splits s into lines, keeping the line terminators.
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// Lines returns the shortest edit script transforming a into b.
+func Lines(a, b []string) []Edit {
+	// Common prefix and suffix don't need the O(ND) search.
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	edits := make([]Edit, 0, len(a)+len(b))
+	for _, l := range a[:prefix] {
+		edits = append(edits, Edit{Kind: Equal, Line: l})
+	}
+	edits = append(edits, myers(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, l := range a[len(a)-suffix:] {
+		edits = append(edits, Edit{Kind: Equal, Line: l})
+	}
+	return edits
+}
+
+// myers implements the Myers O(ND) difference algorithm. For every edit
+// distance d it stores the furthest reaching x for diagonals -d-1..d+1, which
+// is all the backtracking step needs.
+func myers(a, b []string) []Edit {
+	n, m := len(a), len(b)
+	if n == 0 && m == 0 {
+		return nil
+	}
+
+	maxD := n + m
+	offset := maxD + 1
+	v := make([]int, 2*maxD+3)
+	var trace [][]int
+
+	var finalD int
+search:
+	for d := 0; d <= maxD; d++ {
+		snapshot := make([]int, 2*d+3)
+		copy(snapshot, v[offset-d-1:offset+d+2])
+		trace = append(trace, snapshot)
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				finalD = d
+				break search
+			}
+		}
+	}
+
+	get := func(d, k int) int { return trace[d][k+d+1] }
+
+	var reversed []Edit
+	x, y := n, m
+	for d := finalD; d >= 0; d-- {
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && get(d, k-1) < get(d, k+1)) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := get(d, prevK)
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			reversed = append(reversed, Edit{Kind: Equal, Line: a[x-1]})
+			x--
+			y--
+		}
+		if d == 0 {
+			break
+		}
+		if x == prevX {
+			reversed = append(reversed, Edit{Kind: Insert, Line: b[y-1]})
+			y--
+		} else {
+			reversed = append(reversed, Edit{Kind: Delete, Line: a[x-1]})
+			x--
+		}
+	}
+
+	edits := make([]Edit, len(reversed))
+	for i, e := range reversed {
+		edits[len(reversed)-1-i] = e
+	}
+	return edits
+}
+
+// Unified returns a unified diff between from and to with the given number of
+// context lines. An empty string is returned when both versions are equal.
+func Unified(fromName, toName, from, to string, context int) (string, Stats) {
+	if context < 0 {
+		context = DefaultContext
+	}
+	edits := Lines(splitLines(from), splitLines(to))
+
+	var changes []int
+	var stats Stats
+	for i, e := range edits {
+		switch e.Kind {
+		case Delete:
+			stats.Removed++
+			changes = append(changes, i)
+		case Insert:
+			stats.Added++
+			changes = append(changes, i)
+		case Equal:
+		}
+	}
+	if len(changes) == 0 {
+		return "", stats
+	}
+
+	// oldLine[i] and newLine[i] are the 0-based line numbers before edit i.
+	oldLine := make([]int, len(edits)+1)
+	newLine := make([]int, len(edits)+1)
+	for i, e := range edits {
+		oldLine[i+1], newLine[i+1] = oldLine[i], newLine[i]
+		if e.Kind != Insert {
+			oldLine[i+1]++
+		}
+		if e.Kind != Delete {
+			newLine[i+1]++
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", fromName, toName)
+
+	for start := 0; start < len(changes); {
+		end := start
+		for end+1 < len(changes) && changes[end+1]-changes[end] <= 2*context+1 {
+			end++
+		}
+		lo := max(changes[start]-context, 0)
+		hi := min(changes[end]+context+1, len(edits))
+		start = end + 1
+		stats.Hunks++
+
+		oldCount, newCount := oldLine[hi]-oldLine[lo], newLine[hi]-newLine[lo]
+		fmt.Fprintf(&b, "@@ -%s +%s @@\n", hunkRange(oldLine[lo], oldCount), hunkRange(newLine[lo], newCount))
+		for _, e := range edits[lo:hi] {
+			prefix := " "
+			switch e.Kind {
+			case Delete:
+				prefix = "-"
+			case Insert:
+				prefix = "+"
+			case Equal:
+			}
+			b.WriteString(prefix)
+			b.WriteString(e.Line)
+			if !strings.HasSuffix(e.Line, "\n") {
+				b.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+	}
+
+	return b.String(), stats
+}
+
+// hunkRange formats a hunk range. Empty ranges point at the line before the
+// change, as in GNU diff.
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
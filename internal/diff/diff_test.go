@@ -0,0 +1,128 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const baseScript = `import http from 'k6/http';
+import { sleep } from 'k6';
+
+export const options = {
+  vus: 1,
+  duration: '30s',
+};
+
+export default function () {
+  http.get('https://test.k6.io');
+  sleep(1);
+}
+`
+
+func TestUnified(t *testing.T) {
+	t.Parallel()
+
+	modified := strings.Replace(baseScript, "vus: 1,", "vus: 10,", 1)
+	got, stats := Unified("a/script.js", "b/script.js", baseScript, modified, DefaultContext)
+
+	want := `--- a/script.js
++++ b/script.js
+@@ -2,7 +2,7 @@
+ import { sleep } from 'k6';
+ 
+ export const options = {
+-  vus: 1,
++  vus: 10,
+   duration: '30s',
+ };
+ 
+`
+	assert.Equal(t, want, got)
+	assert.Equal(t, Stats{Added: 1, Removed: 1, Hunks: 1}, stats)
+
+	same, stats := Unified("a", "b", baseScript, baseScript, DefaultContext)
+	assert.Empty(t, same)
+	assert.Zero(t, stats.Hunks)
+}
+
+func TestRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		from, to string
+	}{
+		"change":            {baseScript, strings.Replace(baseScript, "sleep(1);", "sleep(2);", 1)},
+		"insert at start":   {baseScript, "// smoke test\n" + baseScript},
+		"append":            {baseScript, baseScript + "\nexport function teardown() {}\n"},
+		"delete everything": {baseScript, ""},
+		"from empty":        {"", baseScript},
+		"separate hunks": {
+			baseScript,
+			strings.NewReplacer("import http", "import http2", "sleep(1);", "sleep(3);").Replace(baseScript),
+		},
+		"drop trailing newline": {baseScript, strings.TrimSuffix(baseScript, "\n")},
+		"add trailing newline":  {"a\nb", "a\nb\n"},
+		"shuffle":               {"a\nb\nc\nd\ne\n", "e\nc\nb\nf\na\n"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			patch, _ := Unified("a", "b", tt.from, tt.to, DefaultContext)
+			got, applied, err := Apply(tt.from, patch)
+			require.NoError(t, err, patch)
+			assert.Equal(t, tt.to, got, patch)
+			for _, a := range applied {
+				assert.Zero(t, a.Offset)
+				assert.False(t, a.Fuzzy)
+			}
+		})
+	}
+}
+
+func TestApplyWithOffset(t *testing.T) {
+	t.Parallel()
+
+	modified := strings.Replace(baseScript, "sleep(1);", "sleep(5);", 1)
+	patch, _ := Unified("a", "b", baseScript, modified, 1)
+
+	// The script gained two lines at the top after the patch was made.
+	drifted := "// header\n// more\n" + baseScript
+	got, applied, err := Apply(drifted, patch)
+	require.NoError(t, err)
+	assert.Equal(t, "// header\n// more\n"+modified, got)
+	require.Len(t, applied, 1)
+	assert.Equal(t, 2, applied[0].Offset)
+}
+
+func TestApplyFuzzyWhitespace(t *testing.T) {
+	t.Parallel()
+
+	patch := `@@ -1,3 +1,3 @@
+ export default function () {
+-  sleep(1);
++  sleep(2);
+ }
+`
+	got, applied, err := Apply("export default function () {\n\tsleep(1);\n}\n", patch)
+	require.NoError(t, err)
+	assert.Equal(t, "export default function () {\n  sleep(2);\n}\n", got)
+	assert.True(t, applied[0].Fuzzy)
+}
+
+func TestApplyErrors(t *testing.T) {
+	t.Parallel()
+
+	_, _, err := Apply(baseScript, "not a patch")
+	require.ErrorIs(t, err, ErrNoHunks)
+
+	_, _, err = Apply(baseScript, "@@ -1,1 +1,1 @@\n-missing line\n+replacement\n")
+	require.ErrorIs(t, err, ErrConflict)
+
+	_, _, err = Apply(baseScript, "@@ -1,2 +1,2 @@\n-only one line\n")
+	require.Error(t, err)
+}
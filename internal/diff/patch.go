@@ -0,0 +1,193 @@
+package diff
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrNoHunks is returned when a patch contains no hunks.
+	ErrNoHunks = errors.New("patch contains no hunks")
+	// ErrConflict is returned when a hunk's context does not match the script.
+	ErrConflict = errors.New("hunk does not apply")
+)
+
+// maxFuzzOffset bounds how far from its recorded position a hunk is searched for.
+const maxFuzzOffset = 200
+
+//nolint:gochecknoglobals // Compiled once and reused.
+var hunkHeaderRe = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@`)
+
+// Hunk is a parsed unified diff hunk. Old and New hold the lines the hunk
+// expects and produces, with their line terminators.
+type Hunk struct {
+	OldStart int
+	OldLines int
+	NewStart int
+	NewLines int
+	Old      []string
+	New      []string
+}
+
+// Applied describes where a hunk was applied.
+type Applied struct {
+	Hunk int `json:"hunk"`
+	// Line is the 1-based line in the original script where the hunk matched.
+	Line int `json:"line"`
+	// Offset is the distance from the line recorded in the hunk header.
+	Offset int `json:"offset"`
+	// Fuzzy is true when the context only matched after ignoring whitespace.
+	Fuzzy bool `json:"fuzzy,omitempty"`
+}
+
+// Parse extracts the hunks of a unified diff. File headers and other
+// preamble lines are ignored.
+func Parse(patch string) ([]Hunk, error) {
+	lines := strings.Split(strings.ReplaceAll(patch, "\r\n", "\n"), "\n")
+	var hunks []Hunk
+
+	for i := 0; i < len(lines); i++ {
+		m := hunkHeaderRe.FindStringSubmatch(lines[i])
+		if m == nil {
+			continue
+		}
+		h := Hunk{
+			OldStart: atoi(m[1], 0),
+			OldLines: atoi(m[2], 1),
+			NewStart: atoi(m[3], 0),
+			NewLines: atoi(m[4], 1),
+		}
+
+		oldSeen, newSeen := 0, 0
+		var lastKind byte
+		for i+1 < len(lines) && (oldSeen < h.OldLines || newSeen < h.NewLines || strings.HasPrefix(lines[i+1], `\`)) {
+			i++
+			line := lines[i]
+			if line == "" {
+				// Some editors strip the single space of empty context lines.
+				line = " "
+			}
+			switch line[0] {
+			case ' ':
+				h.Old = append(h.Old, line[1:]+"\n")
+				h.New = append(h.New, line[1:]+"\n")
+				oldSeen++
+				newSeen++
+			case '-':
+				h.Old = append(h.Old, line[1:]+"\n")
+				oldSeen++
+			case '+':
+				h.New = append(h.New, line[1:]+"\n")
+				newSeen++
+			case '\\':
+				// "\ No newline at end of file" refers to the previous line.
+				if lastKind == ' ' || lastKind == '-' {
+					h.Old[len(h.Old)-1] = strings.TrimSuffix(h.Old[len(h.Old)-1], "\n")
+				}
+				if lastKind == ' ' || lastKind == '+' {
+					h.New[len(h.New)-1] = strings.TrimSuffix(h.New[len(h.New)-1], "\n")
+				}
+			default:
+				return nil, fmt.Errorf("hunk %d: unexpected line %q", len(hunks)+1, line)
+			}
+			lastKind = line[0]
+		}
+		if oldSeen != h.OldLines || newSeen != h.NewLines {
+			return nil, fmt.Errorf("hunk %d: header expects -%d +%d lines but body has -%d +%d",
+				len(hunks)+1, h.OldLines, h.NewLines, oldSeen, newSeen)
+		}
+		hunks = append(hunks, h)
+	}
+
+	if len(hunks) == 0 {
+		return nil, ErrNoHunks
+	}
+	return hunks, nil
+}
+
+func atoi(s string, fallback int) int {
+	if s == "" {
+		return fallback
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return fallback
+	}
+	return n
+}
+
+// Apply applies a unified diff to original. Hunks are located at their
+// recorded line first and then searched for nearby, so patches made against
+// a slightly different version still apply. If the exact context cannot be
+// found, a whitespace-insensitive match is attempted before giving up.
+func Apply(original, patch string) (string, []Applied, error) {
+	hunks, err := Parse(patch)
+	if err != nil {
+		return "", nil, err
+	}
+
+	src := splitLines(original)
+	out := make([]string, 0, len(src))
+	applied := make([]Applied, 0, len(hunks))
+	pos, delta := 0, 0
+
+	for i, h := range hunks {
+		recorded := h.OldStart - 1
+		if h.OldLines == 0 {
+			recorded = h.OldStart
+		}
+		expected := max(recorded+delta, pos)
+
+		at, fuzzy, ok := locate(src, h.Old, expected, pos)
+		if !ok {
+			return "", nil, fmt.Errorf("%w: hunk %d (expected near line %d)", ErrConflict, i+1, expected+1)
+		}
+
+		out = append(out, src[pos:at]...)
+		out = append(out, h.New...)
+		pos = at + len(h.Old)
+		delta = at - recorded
+		applied = append(applied, Applied{Hunk: i + 1, Line: at + 1, Offset: delta, Fuzzy: fuzzy})
+	}
+	out = append(out, src[pos:]...)
+
+	return strings.Join(out, ""), applied, nil
+}
+
+// locate finds the position of want in src, searching outwards from
+// expected but never before minPos.
+func locate(src, want []string, expected, minPos int) (int, bool, bool) {
+	for _, fuzzy := range []bool{false, true} {
+		for off := 0; off <= maxFuzzOffset; off++ {
+			candidates := []int{expected - off, expected + off}
+			if off == 0 {
+				candidates = candidates[:1]
+			}
+			for _, at := range candidates {
+				if at >= minPos && at+len(want) <= len(src) && matchAt(src, want, at, fuzzy) {
+					return at, fuzzy, true
+				}
+			}
+		}
+	}
+	return 0, false, false
+}
+
+func matchAt(src, want []string, at int, fuzzy bool) bool {
+	for j, w := range want {
+		got := src[at+j]
+		if fuzzy {
+			if strings.Join(strings.Fields(got), " ") != strings.Join(strings.Fields(w), " ") {
+				return false
+			}
+			continue
+		}
+		if got != w {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,210 @@
+// Package doclinks checks the links between documentation pages, relrefs and
+// aliases included, so that get_documentation does not point models at pages
+// that do not exist.
+package doclinks
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/grafana/xk6-docs/docs"
+)
+
+// Kinds of Problem.
+const (
+	// KindLink is a markdown link to a page missing from its version.
+	KindLink = "link"
+	// KindRelref is a relref shortcode to a page missing from its version.
+	KindRelref = "relref"
+	// KindAlias is an alias another section or alias already takes, which
+	// lookups never resolve to its section.
+	KindAlias = "alias"
+	// KindChild is a child slug missing from the index.
+	KindChild = "child"
+	// KindRead is a page whose content cannot be read.
+	KindRead = "read"
+)
+
+//nolint:gochecknoglobals // Compiled once, read-only.
+var (
+	reRelref = regexp.MustCompile(`\{\{<\s*relref\s+"([^"]+)"\s*>\}\}`)
+	// reLink matches the target of markdown links and images, up to an
+	// optional title
+	reLink  = regexp.MustCompile(`\]\(\s*([^)\s]+)(?:\s+"[^"]*")?\s*\)`)
+	reFence = regexp.MustCompile("(?ms)^\\s*(```|~~~).*?^\\s*(```|~~~)")
+)
+
+// Problem is a broken reference found in a documentation version.
+type Problem struct {
+	Version string `json:"version"`
+	// Slug is the section holding the reference.
+	Slug   string `json:"slug"`
+	Kind   string `json:"kind"`
+	Target string `json:"target"`
+}
+
+func (p Problem) String() string {
+	return fmt.Sprintf("%s %s: %s %s", p.Version, p.Slug, p.Kind, p.Target)
+}
+
+// Catalog is the part of *docs.Catalog Check reads.
+type Catalog interface {
+	Index(ctx context.Context, version string) (*docs.Index, error)
+	Read(ctx context.Context, version, slug string) ([]byte, error)
+}
+
+// Check returns the problems of every version of catalog, in the order of
+// versions and sections.
+func Check(ctx context.Context, catalog Catalog, versions []string) ([]Problem, error) {
+	var problems []Problem
+	for _, version := range versions {
+		idx, err := catalog.Index(ctx, version)
+		if err != nil {
+			return nil, fmt.Errorf("loading documentation %s: %w", version, err)
+		}
+		problems = append(problems, CheckIndex(idx, func(slug string) ([]byte, error) {
+			return catalog.Read(ctx, idx.Version, slug)
+		})...)
+	}
+	return problems, nil
+}
+
+// CheckIndex returns the problems of the sections of idx, reading their
+// content with read.
+func CheckIndex(idx *docs.Index, read func(slug string) ([]byte, error)) []Problem {
+	var problems []Problem
+	report := func(sec *docs.Section, kind, target string) {
+		problems = append(problems, Problem{Version: idx.Version, Slug: sec.Slug, Kind: kind, Target: target})
+	}
+
+	slugs := make(map[string]bool, len(idx.Sections))
+	for i := range idx.Sections {
+		sec := &idx.Sections[i]
+		slugs[strings.ToLower(sec.Slug)] = true
+	}
+	aliases := make(map[string]bool)
+	for i := range idx.Sections {
+		sec := &idx.Sections[i]
+		for _, alias := range sec.Aliases {
+			key := strings.ToLower(alias)
+			if slugs[key] || aliases[key] {
+				report(sec, KindAlias, alias)
+			}
+			aliases[key] = true
+		}
+		for _, child := range sec.Children {
+			if _, ok := idx.Lookup(child); !ok {
+				report(sec, KindChild, child)
+			}
+		}
+
+		content, err := read(sec.Slug)
+		if err != nil {
+			report(sec, KindRead, sec.RelPath)
+			continue
+		}
+		for _, ref := range references(idx.Version, sec, string(content)) {
+			if _, ok := idx.Lookup(ref.slug); !ok {
+				report(sec, ref.kind, ref.target)
+			}
+		}
+	}
+	return problems
+}
+
+type reference struct {
+	kind   string
+	target string
+	slug   string
+}
+
+// references returns the references of content to pages of version, skipping
+// code blocks, external links and links to other versions.
+func references(version string, sec *docs.Section, content string) []reference {
+	content = reFence.ReplaceAllString(content, "")
+
+	var refs []reference
+	for _, m := range reRelref.FindAllStringSubmatch(content, -1) {
+		if slug, ok := relrefSlug(sec, m[1]); ok {
+			refs = append(refs, reference{kind: KindRelref, target: m[1], slug: slug})
+		}
+	}
+	content = reRelref.ReplaceAllString(content, "")
+	for _, m := range reLink.FindAllStringSubmatch(content, -1) {
+		if slug, ok := linkSlug(version, sec, m[1]); ok {
+			refs = append(refs, reference{kind: KindLink, target: m[1], slug: slug})
+		}
+	}
+	return refs
+}
+
+// linkSlug returns the slug a markdown link of sec points to, and false for
+// links that are not to a page of version.
+func linkSlug(version string, sec *docs.Section, target string) (string, bool) {
+	u, err := url.Parse(target)
+	if err != nil || u.Opaque != "" || u.Path == "" || isAsset(u.Path) {
+		return "", false
+	}
+	switch {
+	case u.Scheme != "" || u.Host != "":
+		if u.Hostname() != "grafana.com" && u.Hostname() != "www.grafana.com" {
+			return "", false
+		}
+	case !strings.HasPrefix(u.Path, "/"):
+		// Pages are served as directories, so links are relative to the page
+		return pageSlug(path.Join("/docs/k6", version, sec.Slug, u.Path), version)
+	}
+	return pageSlug(u.Path, version)
+}
+
+// pageSlug returns the slug of the page of version served at p.
+func pageSlug(p, version string) (string, bool) {
+	rest, ok := strings.CutPrefix(p, "/docs/k6/")
+	if !ok {
+		return "", false
+	}
+	linked, slug, _ := strings.Cut(rest, "/")
+	if linked != version && linked != "<K6_VERSION>" && linked != "latest" {
+		return "", false
+	}
+	return cleanSlug(slug)
+}
+
+// relrefSlug returns the slug a relref of sec points to: relative refs are
+// resolved from the file of sec, absolute ones from the content root.
+func relrefSlug(sec *docs.Section, target string) (string, bool) {
+	target, _, _ = strings.Cut(target, "#")
+	if target == "" {
+		return "", false
+	}
+	if strings.HasPrefix(target, "/") {
+		if strings.HasPrefix(target, "/docs/k6/") {
+			_, target, _ = strings.Cut(strings.TrimPrefix(target, "/docs/k6/"), "/")
+		}
+		return cleanSlug(target)
+	}
+	return cleanSlug(path.Join(path.Dir(sec.RelPath), target))
+}
+
+// cleanSlug turns the path of a page or its file into its slug. The root page
+// has none and is not checked.
+func cleanSlug(p string) (string, bool) {
+	p = strings.TrimSuffix(path.Clean("/"+p), ".md")
+	if base := path.Base(p); base == "_index" || base == "index" {
+		p = path.Dir(p)
+	}
+	p = strings.Trim(p, "/")
+	return p, p != ""
+}
+
+func isAsset(p string) bool {
+	switch strings.ToLower(path.Ext(p)) {
+	case ".png", ".jpg", ".jpeg", ".gif", ".svg", ".webp", ".zip", ".json", ".js", ".yaml", ".yml":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,77 @@
+package doclinks
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"testing/fstest"
+
+	"github.com/grafana/xk6-docs/docs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheck(t *testing.T) {
+	t.Parallel()
+
+	sections := []docs.Section{
+		{
+			Slug:     "using-k6/scenarios",
+			RelPath:  "using-k6/scenarios/_index.md",
+			IsIndex:  true,
+			Children: []string{"using-k6/scenarios/executors", "using-k6/scenarios/missing"},
+			Aliases:  []string{"scenarios"},
+		},
+		{Slug: "using-k6/scenarios/executors", RelPath: "using-k6/scenarios/executors.md"},
+		{Slug: "using-k6/thresholds", RelPath: "using-k6/thresholds.md", Aliases: []string{"scenarios"}},
+	}
+	index, err := json.Marshal(docs.Index{Version: "v1.4.x", Sections: sections})
+	require.NoError(t, err)
+
+	fsys := fstest.MapFS{
+		"v1.4.x/sections.json": {Data: index},
+		"v1.4.x/markdown/using-k6/scenarios/_index.md": {Data: []byte(
+			"See [executors](executors/), [thresholds](/docs/k6/<K6_VERSION>/using-k6/thresholds#syntax),\n" +
+				"[arrival rate](https://grafana.com/docs/k6/latest/using-k6/scenarios/arrival-rate/) and\n" +
+				"[an older page](/docs/k6/v0.50.x/removed/).\n" +
+				"![diagram](/media/docs/k6/scenarios.png) [elsewhere](https://k6.io/missing/) [top](#top)\n" +
+				"```javascript\n" +
+				"const link = '[not a link](/docs/k6/latest/in/code/)';\n" +
+				"```\n")},
+		"v1.4.x/markdown/using-k6/scenarios/executors.md": {Data: []byte(
+			"Back to [scenarios]({{< relref \"./_index.md\" >}}) and [thresholds]({{< relref \"../thresholds\" >}}),\n" +
+				"not [ramping]({{< relref \"./ramping-vus\" >}}).\n")},
+	}
+
+	problems, err := Check(context.Background(), docs.NewCatalog(docs.WithFS(fsys)), []string{"v1.4.x"})
+	require.NoError(t, err)
+	assert.Equal(t, []Problem{
+		{Version: "v1.4.x", Slug: "using-k6/scenarios", Kind: KindChild, Target: "using-k6/scenarios/missing"},
+		{
+			Version: "v1.4.x", Slug: "using-k6/scenarios", Kind: KindLink,
+			Target: "https://grafana.com/docs/k6/latest/using-k6/scenarios/arrival-rate/",
+		},
+		{Version: "v1.4.x", Slug: "using-k6/scenarios/executors", Kind: KindRelref, Target: "./ramping-vus"},
+		{Version: "v1.4.x", Slug: "using-k6/thresholds", Kind: KindAlias, Target: "scenarios"},
+		{Version: "v1.4.x", Slug: "using-k6/thresholds", Kind: KindRead, Target: "using-k6/thresholds.md"},
+	}, problems)
+
+	_, err = Check(context.Background(), docs.NewCatalog(docs.WithFS(fsys)), []string{"v9.9.x"})
+	require.Error(t, err)
+}
+
+func TestCleanSlug(t *testing.T) {
+	t.Parallel()
+
+	for in, want := range map[string]string{
+		"using-k6/scenarios/_index.md": "using-k6/scenarios",
+		"using-k6/scenarios/":          "using-k6/scenarios",
+		"using-k6/../javascript-api":   "javascript-api",
+		"examples/reindex":             "examples/reindex",
+		"_index.md":                    "",
+	} {
+		got, ok := cleanSlug(in)
+		assert.Equal(t, want, got, in)
+		assert.Equal(t, want != "", ok, in)
+	}
+}
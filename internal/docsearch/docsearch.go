@@ -0,0 +1,173 @@
+// Package docsearch ranks the documentation sections matching a free-text
+// query, favoring the JavaScript API reference for code-like queries and the
+// guides for conceptual ones.
+package docsearch
+
+import (
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/grafana/xk6-docs/docs"
+)
+
+// Kind classifies a query.
+type Kind string
+
+const (
+	// Code queries name API symbols, modules or options, such as "http.post",
+	// "k6/http" or "setResponseCallback".
+	Code Kind = "code"
+	// Concept queries ask about k6 in prose, such as "how do I ramp up users".
+	Concept Kind = "concept"
+)
+
+// boosted are the top-level sections favored for each kind of query.
+//
+//nolint:gochecknoglobals // Read-only lookup table.
+var boosted = map[Kind][]string{
+	Code:    {"javascript-api"},
+	Concept: {"using-k6", "testing-guides"},
+}
+
+//nolint:gochecknoglobals // Compiled once, read-only.
+var (
+	// reDotted matches dotted or called identifiers, such as "http.post",
+	// "scenarios.executor" or "check(".
+	reDotted = regexp.MustCompile(`[A-Za-z_$][\w$]*(?:\.[A-Za-z_$][\w$]+|\()`)
+	// reCamel matches camelCase and PascalCase names, such as
+	// "setResponseCallback" or "SharedArray".
+	reCamel = regexp.MustCompile(`\b[A-Za-z][a-z0-9]+[A-Z]\w*`)
+	// reModule matches k6 module paths, such as "k6/http".
+	reModule = regexp.MustCompile(`\bk6/[a-z]`)
+)
+
+// optionKeys are the k6 options a one-word query may name.
+//
+//nolint:gochecknoglobals // Read-only lookup table.
+var optionKeys = map[string]bool{
+	"thresholds": true, "scenarios": true, "stages": true, "executor": true, "vus": true,
+	"duration": true, "iterations": true, "tags": true, "summarytrendstats": true,
+}
+
+// stopWords are left out of the terms of a query.
+//
+//nolint:gochecknoglobals // Read-only lookup table.
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "can": true, "do": true, "does": true, "for": true,
+	"how": true, "i": true, "in": true, "is": true, "it": true, "k6": true, "my": true, "of": true,
+	"on": true, "or": true, "should": true, "the": true, "to": true, "what": true, "when": true,
+	"why": true, "with": true,
+}
+
+// Classify tells whether query names code or asks about a concept. isSymbol
+// reports the API symbols of the docs, such as "check" or "sleep"; it may be
+// nil.
+func Classify(query string, isSymbol func(string) bool) Kind {
+	query = strings.TrimSpace(query)
+	if reDotted.MatchString(query) || reCamel.MatchString(query) || reModule.MatchString(query) {
+		return Code
+	}
+	if strings.ContainsAny(query, " \t") {
+		return Concept
+	}
+	if optionKeys[strings.ToLower(query)] || (isSymbol != nil && isSymbol(query)) {
+		return Code
+	}
+	return Concept
+}
+
+// Result is a section matching a query.
+type Result struct {
+	*docs.Section
+	// Score adds up how well the title, slug and description of the section
+	// match each term of the query.
+	Score int
+	// Boosted is set for the sections of the categories favored for the kind
+	// of query, ranked first among those of the same score.
+	Boosted bool
+}
+
+// Search returns the sections of idx matching the terms of query, best
+// first. The text score ranks first, so an exact title match wins across
+// categories; the sections favored for kind break its ties, then the order
+// of the index.
+func Search(idx *docs.Index, query string, kind Kind) []Result {
+	scores := make(map[*docs.Section]int)
+	var order []*docs.Section
+	match := func(term string, bonus int) {
+		for _, sec := range idx.Search(term, nil) {
+			if _, ok := scores[sec]; !ok {
+				order = append(order, sec)
+			}
+			scores[sec] += score(sec, term) + bonus
+		}
+	}
+	// The whole query, matched as is, ranks above its terms matched apart
+	if terms := Terms(query); len(terms) > 1 {
+		match(strings.TrimSpace(query), 2)
+		for _, term := range terms {
+			match(term, 0)
+		}
+	} else {
+		match(strings.TrimSpace(query), 0)
+	}
+
+	results := make([]Result, len(order))
+	for i, sec := range order {
+		results[i] = Result{Section: sec, Score: scores[sec], Boosted: favored(sec, kind)}
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Boosted && !results[j].Boosted
+	})
+	return results
+}
+
+// Terms returns the words of query, lowercased, without stop words.
+func Terms(query string) []string {
+	var terms []string
+	for _, word := range strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return r == ' ' || r == '\t' || r == ',' || r == '?' || r == '!' || r == '"' || r == '\''
+	}) {
+		if !stopWords[word] {
+			terms = append(terms, word)
+		}
+	}
+	return terms
+}
+
+// Boosted returns the top-level sections favored for kind.
+func Boosted(kind Kind) []string {
+	return boosted[kind]
+}
+
+// score weighs where term appears in sec: its whole title, in its title,
+// slug or description.
+func score(sec *docs.Section, term string) int {
+	title, lower := strings.ToLower(sec.Title), strings.ToLower(term)
+	switch {
+	case title == lower || normalize(title) == normalize(lower):
+		return 4
+	case strings.Contains(title, lower) || strings.Contains(normalize(title), normalize(lower)):
+		return 3
+	case strings.Contains(normalize(sec.Slug), normalize(lower)):
+		return 2
+	default:
+		return 1
+	}
+}
+
+// favored reports whether sec is in a top-level section favored for kind.
+func favored(sec *docs.Section, kind Kind) bool {
+	top, _, _ := strings.Cut(sec.Slug, "/")
+	return slices.Contains(boosted[kind], top)
+}
+
+// normalize strips separators and lowercases s, as Index.Search matches.
+func normalize(s string) string {
+	return strings.ToLower(strings.NewReplacer("-", "", " ", "", "/", "").Replace(s))
+}
@@ -0,0 +1,87 @@
+package docsearch
+
+import (
+	"testing"
+
+	"github.com/grafana/xk6-docs/docs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassify(t *testing.T) {
+	t.Parallel()
+
+	isSymbol := func(name string) bool { return name == "check" || name == "sleep" }
+	for query, want := range map[string]Kind{
+		"http.post":                    Code,
+		"check(":                       Code,
+		"setResponseCallback":          Code,
+		"SharedArray":                  Code,
+		"import from k6/http":          Code,
+		"thresholds":                   Code,
+		"scenarios.executor":           Code,
+		"check":                        Code,
+		"how do I ramp up users":       Concept,
+		"what is a VU":                 Concept,
+		"check the status of requests": Concept,
+		"e.g. load testing":            Concept,
+		"spike":                        Concept,
+	} {
+		assert.Equal(t, want, Classify(query, isSymbol), query)
+	}
+	assert.Equal(t, Concept, Classify("check", nil))
+}
+
+func TestSearch(t *testing.T) {
+	t.Parallel()
+
+	idx := &docs.Index{Sections: []docs.Section{
+		{Slug: "using-k6/thresholds", Title: "Thresholds", Description: "Pass/fail criteria for your test metrics."},
+		{
+			Slug: "javascript-api/k6-http/post", Title: "post( url, [body], [params] )",
+			Description: "Send an HTTP POST request.",
+		},
+		{Slug: "using-k6/http-requests", Title: "HTTP Requests", Description: "How to send a POST request."},
+		{Slug: "javascript-api/k6-execution", Title: "k6/execution", Description: "Ramp up information of the test."},
+		{Slug: "testing-guides/ramp-up", Title: "Ramp up users", Description: "Ramping the VUs of a test."},
+		{Slug: "javascript-api/k6/thresholds", Title: "Thresholds", Description: "Thresholds options."},
+	}}
+	slugs := func(results []Result) []string {
+		out := make([]string, len(results))
+		for i, r := range results {
+			out[i] = r.Slug
+		}
+		return out
+	}
+
+	// Equal matches rank the sections favored for the kind first
+	assert.Equal(t, []string{"javascript-api/k6-http/post", "using-k6/http-requests"},
+		slugs(Search(idx, "send", Code)))
+	assert.Equal(t, []string{"using-k6/http-requests", "javascript-api/k6-http/post"},
+		slugs(Search(idx, "send", Concept)))
+
+	// Equal titles too
+	results := Search(idx, "thresholds", Code)
+	assert.Equal(t, "javascript-api/k6/thresholds", results[0].Slug)
+	assert.True(t, results[0].Boosted)
+	results = Search(idx, "thresholds", Concept)
+	assert.Equal(t, "using-k6/thresholds", results[0].Slug)
+
+	// An exact title match wins across categories
+	results = Search(idx, "ramp up users", Code)
+	assert.Equal(t, "testing-guides/ramp-up", results[0].Slug)
+	assert.False(t, results[0].Boosted)
+
+	// The whole question ranks above sections matching some of its words
+	results = Search(idx, "how do I ramp up users", Concept)
+	assert.Equal(t, []string{"testing-guides/ramp-up", "javascript-api/k6-execution"}, slugs(results))
+	assert.Greater(t, results[0].Score, results[1].Score)
+
+	assert.Empty(t, Search(idx, "websockets", Concept))
+}
+
+func TestTerms(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, []string{"ramp", "up", "users"}, Terms("How do I ramp up users?"))
+	assert.Empty(t, Terms("what is the"))
+}
@@ -0,0 +1,36 @@
+// Package fuzzy matches misspelled names, such as documentation slugs, API
+// symbols, module paths and option names, to the names they were meant to be.
+package fuzzy
+
+// Distance returns the Levenshtein edit distance between a and b, in bytes.
+func Distance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+// Closest returns the candidate nearest to s, at most maxDistance edits
+// away. Ties go to the first candidate.
+func Closest(candidates []string, s string, maxDistance int) (string, bool) {
+	best, bestDistance := "", maxDistance+1
+	for _, c := range candidates {
+		if d := Distance(s, c); d < bestDistance {
+			best, bestDistance = c, d
+		}
+	}
+	return best, best != ""
+}
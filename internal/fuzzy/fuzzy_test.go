@@ -0,0 +1,28 @@
+package fuzzy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDistance(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, 0, Distance("k6/http", "k6/http"))
+	assert.Equal(t, 1, Distance("k6/htpp", "k6/http"))
+	assert.Equal(t, 2, Distance("k6/htlm", "k6/html"))
+	assert.Equal(t, 3, Distance("", "vus"))
+}
+
+func TestClosest(t *testing.T) {
+	t.Parallel()
+
+	candidates := []string{"k6/http", "k6/html", "k6/ws"}
+	got, ok := Closest(candidates, "k6/htm", 2)
+	assert.True(t, ok)
+	assert.Equal(t, "k6/html", got)
+
+	_, ok = Closest(candidates, "k6/grpc", 2)
+	assert.False(t, ok)
+}
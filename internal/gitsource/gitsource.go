@@ -0,0 +1,254 @@
+// Package gitsource checks scripts out of Git repositories, so that a run
+// executes the commit that was reviewed rather than a copy of it. Each
+// checkout fetches a single commit, without its history, into a temporary
+// directory removed once the run ends.
+package gitsource
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/grafana/mcp-k6/internal/audit"
+)
+
+const (
+	// Timeout bounds the fetch and checkout of a commit.
+	Timeout = 2 * time.Minute
+	// protocols are the transports git may use for the repositories of
+	// callers: neither local paths nor ext:: and fd:: helpers.
+	protocols = "https:ssh"
+)
+
+var (
+	// ErrNoGit is returned when git is not on the PATH.
+	ErrNoGit = errors.New("git not found in PATH: install git to run scripts from a repository")
+	// ErrInvalid is returned for repositories, refs and paths that cannot be
+	// checked out.
+	ErrInvalid = errors.New("invalid git source")
+)
+
+var (
+	// scpRe matches the scp-like syntax of ssh repositories, such as
+	// git@github.com:grafana/k6.git.
+	//
+	//nolint:gochecknoglobals // Compiled once, read-only.
+	scpRe = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9._-]*@[A-Za-z0-9][A-Za-z0-9.-]*:[A-Za-z0-9_./~-]+$`)
+	// refRe matches branch and tag names and commit SHAs, none of which
+	// starts with a dash git would read as an option.
+	//
+	//nolint:gochecknoglobals // Compiled once, read-only.
+	refRe = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9._/-]*$`)
+	// commitRe matches full commit SHAs, of SHA-1 and SHA-256 repositories.
+	//
+	//nolint:gochecknoglobals // Compiled once, read-only.
+	commitRe = regexp.MustCompile(`^([0-9a-f]{40}|[0-9a-f]{64})$`)
+)
+
+// Source names a script in a repository.
+type Source struct {
+	Repo string `json:"repo"`
+	// Ref is the commit SHA, branch or tag to check out.
+	Ref string `json:"ref"`
+	// Path is the script, relative to the root of the repository.
+	Path string `json:"path"`
+	// Commit is the SHA Ref resolved to, set once checked out.
+	Commit string `json:"commit,omitempty"`
+}
+
+// Pinned reports whether Ref is a commit SHA rather than a branch or tag,
+// which may move between the review and the run.
+func (s Source) Pinned() bool {
+	return commitRe.MatchString(s.Ref)
+}
+
+// String returns the path of s in its repository at its commit, or ref
+// until checked out.
+func (s Source) String() string {
+	at := s.Commit
+	if at == "" {
+		at = s.Ref
+	}
+	return s.Repo + "@" + at + ":" + s.Path
+}
+
+// Validate checks that the repository is an https or ssh URL without a
+// password, that the ref is a plain name or SHA, and that the path stays
+// inside the repository.
+func (s Source) Validate() error {
+	if err := validateRepo(s.Repo); err != nil {
+		return err
+	}
+	return s.validateCheckout()
+}
+
+// validateCheckout checks the ref and path of s.
+func (s Source) validateCheckout() error {
+	switch {
+	case !refRe.MatchString(s.Ref) || strings.Contains(s.Ref, ".."):
+		return fmt.Errorf("%w: ref %q is not a branch, tag or commit SHA", ErrInvalid, s.Ref)
+	case s.Path == "" || path.IsAbs(s.Path) || strings.HasPrefix(s.Path, "\\"):
+		return fmt.Errorf("%w: path %q must be relative to the root of the repository", ErrInvalid, s.Path)
+	case path.Clean(s.Path) == ".." || strings.HasPrefix(path.Clean(s.Path), "../"):
+		return fmt.Errorf("%w: path %q leaves the repository", ErrInvalid, s.Path)
+	}
+	return nil
+}
+
+// validateRepo checks that repo is an https or ssh repository git can
+// fetch without prompting and without a password on its command line.
+func validateRepo(repo string) error {
+	if scpRe.MatchString(repo) {
+		return nil
+	}
+	u, err := url.Parse(repo)
+	if err != nil || u.Host == "" || strings.HasPrefix(u.Host, "-") {
+		return fmt.Errorf("%w: repository %q is not an https or ssh URL", ErrInvalid, repo)
+	}
+	switch {
+	case u.Scheme != "https" && u.Scheme != "ssh":
+		return fmt.Errorf("%w: repository %q is not an https or ssh URL", ErrInvalid, repo)
+	case hasPassword(u):
+		return fmt.Errorf("%w: repository URL holds a password; configure a git credential helper "+
+			"or an ssh key instead", ErrInvalid)
+	}
+	return nil
+}
+
+// hasPassword reports whether u carries a password, or an access token in
+// place of its user name.
+func hasPassword(u *url.URL) bool {
+	if u.User == nil {
+		return false
+	}
+	_, set := u.User.Password()
+	return set || u.Scheme == "https"
+}
+
+// Checkout is a commit checked out into a temporary directory.
+type Checkout struct {
+	Source
+	// Dir is the root of the working tree.
+	Dir string
+}
+
+// Clone checks src out into a new temporary directory, after validating
+// it, and resolves its ref to a commit.
+func Clone(ctx context.Context, src Source) (*Checkout, error) {
+	if err := src.Validate(); err != nil {
+		return nil, err
+	}
+	return clone(ctx, src, protocols)
+}
+
+// clone checks src out, over the git transports of allowed.
+func clone(ctx context.Context, src Source, allowed string) (*Checkout, error) {
+	gitPath, err := exec.LookPath("git")
+	if err != nil {
+		return nil, ErrNoGit
+	}
+	ctx, cancel := context.WithTimeout(ctx, Timeout)
+	defer cancel()
+
+	//nolint:forbidigo // The working tree of the checkout.
+	dir, err := os.MkdirTemp("", "k6-git-*")
+	if err != nil {
+		return nil, fmt.Errorf("creating the checkout directory: %w", err)
+	}
+	co := &Checkout{Source: src, Dir: dir}
+	g := &git{path: gitPath, dir: dir, allowed: allowed}
+	if err := g.checkout(ctx, src); err != nil {
+		_ = co.Remove()
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("checking out %s at %s: %w after %s", src.Repo, src.Ref, ctx.Err(), Timeout)
+		}
+		return nil, err
+	}
+	out, err := g.run(ctx, "rev-parse", "HEAD")
+	if err != nil {
+		_ = co.Remove()
+		return nil, err
+	}
+	co.Commit = strings.TrimSpace(out)
+	if src.Pinned() && co.Commit != src.Ref {
+		_ = co.Remove()
+		return nil, fmt.Errorf("%w: %s checked out commit %s instead of %s", ErrInvalid, src.Repo, co.Commit, src.Ref)
+	}
+	return co, nil
+}
+
+// Remove removes the working tree of c. A nil checkout has nothing to
+// remove.
+func (c *Checkout) Remove() error {
+	if c == nil {
+		return nil
+	}
+	//nolint:forbidigo // Cleanup of the checkout directory.
+	return os.RemoveAll(c.Dir)
+}
+
+// git runs git in the directory of a checkout.
+type git struct {
+	path    string
+	dir     string
+	allowed string
+}
+
+// checkout fetches the ref of src alone into the empty directory of g and
+// checks it out. Servers that refuse to serve commits by SHA get the SHA
+// looked up in their branches and tags instead.
+func (g *git) checkout(ctx context.Context, src Source) error {
+	if _, err := g.run(ctx, "init", "--quiet"); err != nil {
+		return err
+	}
+	_, err := g.run(ctx, "fetch", "--quiet", "--depth=1", "--no-tags", src.Repo, src.Ref)
+	if err != nil && !src.Pinned() {
+		return err
+	}
+	if err != nil {
+		if _, err := g.run(ctx, "fetch", "--quiet", "--no-tags", src.Repo,
+			"+refs/heads/*:refs/remotes/origin/*", "+refs/tags/*:refs/tags/*"); err != nil {
+			return err
+		}
+		_, err = g.run(ctx, "checkout", "--quiet", "--detach", src.Ref+"^{commit}")
+		return err
+	}
+	_, err = g.run(ctx, "checkout", "--quiet", "--detach", "FETCH_HEAD")
+	return err
+}
+
+// run runs git with args in the directory of g, returning its output. Git
+// fails instead of prompting for credentials.
+func (g *git) run(ctx context.Context, args ...string) (string, error) {
+	sub := args[0]
+	args = append([]string{"-C", g.dir, "-c", "advice.detachedHead=false"}, args...)
+	cmd := exec.CommandContext(ctx, g.path, args...) // #nosec G204 -- source checked by Validate
+	cmd.Env = append(cmd.Environ(), "GIT_TERMINAL_PROMPT=0", "GIT_ALLOW_PROTOCOL="+g.allowed)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	start := time.Now()
+	err := cmd.Run()
+	audit.Command(ctx, cmd, start, err)
+	if err != nil {
+		if msg := lastLine(stderr.String()); msg != "" {
+			return "", fmt.Errorf("git %s: %s", sub, msg)
+		}
+		return "", fmt.Errorf("git %s: %w", sub, err)
+	}
+	return stdout.String(), nil
+}
+
+// lastLine returns the last non-empty line of s, which holds the error of
+// git.
+func lastLine(s string) string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
@@ -0,0 +1,289 @@
+package graphql
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// maxSampleDepth bounds the nesting of sample input objects.
+const maxSampleDepth = 4
+
+// ErrSubscription is returned for subscriptions, which run over WebSockets
+// rather than HTTP requests.
+var ErrSubscription = errors.New("subscriptions are not supported: they run over WebSockets, not HTTP requests")
+
+// Operation is a GraphQL operation to load test.
+type Operation struct {
+	// Name is the operation name, empty for anonymous documents.
+	Name string `json:"name"`
+	// Type is "query" or "mutation".
+	Type string `json:"type"`
+	// Field is the root field the operation was built on, empty for given
+	// documents.
+	Field    string `json:"field,omitempty"`
+	Document string `json:"document"`
+	// Variables are sample values for the variables of the operation.
+	Variables map[string]any `json:"variables,omitempty"`
+}
+
+// FieldOperation builds an operation on the field of the root type of op
+// ("query" or "mutation"). Required arguments become variables, and the
+// selection set picks the scalar fields of the result, nesting objects down to
+// depth levels. Fields with required arguments are left out of it.
+func (s *Schema) FieldOperation(op, field string, depth int) (*Operation, error) {
+	if op == "subscription" {
+		return nil, ErrSubscription
+	}
+	root := s.Root(op)
+	if root == nil {
+		return nil, fmt.Errorf("schema declares no %s type", op)
+	}
+	f, ok := root.field(field)
+	if !ok {
+		return nil, fmt.Errorf("%s has no field %q", root.Name, field)
+	}
+
+	o := &Operation{Name: strings.ToUpper(field[:1]) + field[1:], Type: op, Field: field}
+	var defs, args []string
+	for _, arg := range f.Args {
+		if !arg.Required() {
+			continue
+		}
+		defs = append(defs, fmt.Sprintf("$%s: %s", arg.Name, arg.Type))
+		args = append(args, fmt.Sprintf("%s: $%s", arg.Name, arg.Name))
+		if o.Variables == nil {
+			o.Variables = make(map[string]any)
+		}
+		o.Variables[arg.Name] = s.Sample(arg.Type)
+	}
+
+	var b strings.Builder
+	b.WriteString(op + " " + o.Name)
+	if len(defs) > 0 {
+		b.WriteString("(" + strings.Join(defs, ", ") + ")")
+	}
+	b.WriteString(" {\n  " + field)
+	if len(args) > 0 {
+		b.WriteString("(" + strings.Join(args, ", ") + ")")
+	}
+	if lines := s.selection(NamedType(f.Type), max(depth, 1)); len(lines) > 0 {
+		b.WriteString(" {\n")
+		for _, line := range lines {
+			b.WriteString("    " + line + "\n")
+		}
+		b.WriteString("  }")
+	}
+	b.WriteString("\n}")
+	o.Document = b.String()
+	return o, nil
+}
+
+func (t *Type) field(name string) (Field, bool) {
+	for _, f := range t.Fields {
+		if f.Name == name {
+			return f, true
+		}
+	}
+	return Field{}, false
+}
+
+// selection returns the lines of the selection set of the type name, without
+// its braces, or nil for leaf types.
+func (s *Schema) selection(name string, depth int) []string {
+	t := s.Types[name]
+	if t == nil {
+		return nil
+	}
+	switch t.Kind {
+	case KindUnion:
+		return []string{"__typename"}
+	case KindObject, KindInterface:
+	default:
+		return nil
+	}
+
+	var lines []string
+	for _, f := range t.Fields {
+		if strings.HasPrefix(f.Name, "__") || hasRequiredArgs(f) {
+			continue
+		}
+		ft := s.Types[NamedType(f.Type)]
+		switch {
+		case ft == nil:
+		case ft.Kind == KindScalar || ft.Kind == KindEnum:
+			lines = append(lines, f.Name)
+		case depth > 1:
+			sub := s.selection(ft.Name, depth-1)
+			if len(sub) == 0 {
+				continue
+			}
+			lines = append(lines, f.Name+" {")
+			for _, line := range sub {
+				lines = append(lines, "  "+line)
+			}
+			lines = append(lines, "}")
+		}
+	}
+	if len(lines) == 0 {
+		return []string{"__typename"}
+	}
+	return lines
+}
+
+func hasRequiredArgs(f Field) bool {
+	for _, arg := range f.Args {
+		if arg.Required() {
+			return true
+		}
+	}
+	return false
+}
+
+//nolint:gochecknoglobals // Compiled once, read-only.
+var (
+	// reOperationHeader matches the operation type, name and variable
+	// definitions of a document.
+	reOperationHeader = regexp.MustCompile(`^(query|mutation|subscription)\b\s*([_A-Za-z]\w*)?\s*(\([^)]*\))?`)
+	// reVariable matches a variable definition, and the = of its default.
+	reVariable = regexp.MustCompile(`\$([_A-Za-z]\w*)\s*:\s*([^=@$),]+)(=)?`)
+)
+
+// Operation returns the operation ref selects: an operation document, a root
+// field such as "pizza" (looked up in the query type, then the mutation type),
+// or a field of a root type, such as "Mutation.createPizza" or
+// "mutation.createPizza". Operations on fields select depth levels of fields.
+func (s *Schema) Operation(ref string, depth int) (*Operation, error) {
+	ref = strings.TrimSpace(ref)
+	if strings.Contains(ref, "{") {
+		return s.DocumentOperation(ref)
+	}
+	if root, field, ok := strings.Cut(ref, "."); ok {
+		for _, op := range []string{"query", "mutation", "subscription"} {
+			if t := s.Root(op); strings.EqualFold(root, op) || (t != nil && root == t.Name) {
+				return s.FieldOperation(op, field, depth)
+			}
+		}
+		return nil, fmt.Errorf("%q is not a root type of the schema", root)
+	}
+	if m := s.Root("mutation"); m != nil {
+		if _, ok := s.Root("query").field(ref); !ok {
+			if _, ok := m.field(ref); ok {
+				return s.FieldOperation("mutation", ref, depth)
+			}
+		}
+	}
+	return s.FieldOperation("query", ref, depth)
+}
+
+// DocumentOperation returns the operation of a GraphQL document, with sample
+// values for its variables without defaults. Documents of several operations
+// are not supported.
+func (s *Schema) DocumentOperation(document string) (*Operation, error) {
+	document = strings.TrimSpace(document)
+	header := document[headerStart(document):]
+	o := &Operation{Type: "query", Document: document}
+	if strings.HasPrefix(header, "{") {
+		return o, nil
+	}
+	m := reOperationHeader.FindStringSubmatch(header)
+	if m == nil {
+		return nil, errors.New("invalid operation: expected 'query', 'mutation' or '{'")
+	}
+	if m[1] == "subscription" {
+		return nil, ErrSubscription
+	}
+	o.Type, o.Name = m[1], m[2]
+	for _, v := range reVariable.FindAllStringSubmatch(m[3], -1) {
+		if v[3] != "" {
+			continue
+		}
+		if o.Variables == nil {
+			o.Variables = make(map[string]any)
+		}
+		o.Variables[v[1]] = s.Sample(strings.Join(strings.Fields(v[2]), ""))
+	}
+	return o, nil
+}
+
+// Rename sets the name of the operation, in its document too.
+func (o *Operation) Rename(name string) {
+	start := headerStart(o.Document)
+	header := o.Document[start:]
+	switch {
+	case o.Name != "":
+		i := len(o.Type) + strings.Index(header[len(o.Type):], o.Name)
+		header = header[:i] + name + header[i+len(o.Name):]
+	case strings.HasPrefix(header, "{"):
+		header = o.Type + " " + name + " " + header
+	default:
+		header = o.Type + " " + name + strings.TrimPrefix(header, o.Type)
+	}
+	o.Document = o.Document[:start] + header
+	o.Name = name
+}
+
+// headerStart returns the offset of the operation header of document, after
+// the comments and blank lines before it.
+func headerStart(document string) int {
+	i := 0
+	for i < len(document) {
+		switch document[i] {
+		case ' ', '\t', '\r', '\n', ',':
+			i++
+		case '#':
+			for i < len(document) && document[i] != '\n' {
+				i++
+			}
+		default:
+			return i
+		}
+	}
+	return i
+}
+
+// Sample returns a placeholder value of the type reference t: "1" for IDs,
+// the first value of enums, an object of the required fields of input
+// objects, and "" for custom scalars.
+func (s *Schema) Sample(t string) any {
+	return s.sample(strings.TrimSpace(t), 0)
+}
+
+func (s *Schema) sample(t string, depth int) any {
+	if inner := strings.TrimSuffix(t, "!"); strings.HasPrefix(inner, "[") && strings.HasSuffix(inner, "]") {
+		return []any{s.sample(inner[1:len(inner)-1], depth)}
+	}
+	name := NamedType(t)
+	switch name {
+	case "ID":
+		return "1"
+	case "String":
+		return "example"
+	case "Int":
+		return 1
+	case "Float":
+		return 1.5
+	case "Boolean":
+		return true
+	}
+
+	typ := s.Types[name]
+	switch {
+	case typ == nil:
+		return ""
+	case typ.Kind == KindEnum && len(typ.EnumValues) > 0:
+		return typ.EnumValues[0]
+	case typ.Kind == KindInputObject:
+		obj := make(map[string]any)
+		if depth < maxSampleDepth {
+			for _, f := range typ.Fields {
+				if f.Required() {
+					obj[f.Name] = s.sample(f.Type, depth+1)
+				}
+			}
+		}
+		return obj
+	}
+	return ""
+}
@@ -0,0 +1,119 @@
+package graphql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFieldOperation(t *testing.T) {
+	t.Parallel()
+
+	s, err := Parse([]byte(pizzaSDL))
+	require.NoError(t, err)
+
+	o, err := s.FieldOperation("query", "pizza", 2)
+	require.NoError(t, err)
+	assert.Equal(t, "Pizza", o.Name)
+	assert.Equal(t, `query Pizza($id: ID!) {
+  pizza(id: $id) {
+    id
+    name
+    dough
+    ingredients {
+      name
+      calories
+    }
+    createdAt
+  }
+}`, o.Document)
+	assert.Equal(t, map[string]any{"id": "1"}, o.Variables)
+
+	o, err = s.FieldOperation("query", "search", 2)
+	require.NoError(t, err)
+	assert.Contains(t, o.Document, "search(text: $text) {\n    __typename\n  }")
+
+	o, err = s.FieldOperation("query", "version", 2)
+	require.NoError(t, err)
+	assert.Equal(t, "query Version {\n  version\n}", o.Document)
+	assert.Nil(t, o.Variables)
+
+	o, err = s.FieldOperation("mutation", "createPizza", 1)
+	require.NoError(t, err)
+	assert.Contains(t, o.Document, "mutation CreatePizza($input: PizzaInput!) {\n  createPizza(input: $input) {\n    id\n")
+	assert.NotContains(t, o.Document, "ingredients")
+	assert.Equal(t, map[string]any{"input": map[string]any{"name": "example", "dough": "THIN"}}, o.Variables)
+
+	_, err = s.FieldOperation("query", "menu", 2)
+	require.ErrorContains(t, err, `RootQuery has no field "menu"`)
+	_, err = s.FieldOperation("subscription", "orders", 2)
+	require.ErrorIs(t, err, ErrSubscription)
+}
+
+func TestDocumentOperation(t *testing.T) {
+	t.Parallel()
+
+	s, err := Parse([]byte(pizzaSDL))
+	require.NoError(t, err)
+
+	o, err := s.DocumentOperation(`# Menu page.
+query Menu($limit: Int, $ids: [ID!]!, $dough: Dough = THIN) @cached {
+  pizzas(limit: $limit) { name }
+}`)
+	require.NoError(t, err)
+	assert.Equal(t, "Menu", o.Name)
+	assert.Equal(t, "query", o.Type)
+	assert.Equal(t, map[string]any{"limit": 1, "ids": []any{"1"}}, o.Variables)
+
+	o, err = s.DocumentOperation("{ version }")
+	require.NoError(t, err)
+	assert.Empty(t, o.Name)
+	assert.Equal(t, "query", o.Type)
+
+	_, err = s.DocumentOperation("subscription Orders { orders }")
+	require.ErrorIs(t, err, ErrSubscription)
+	_, err = s.DocumentOperation("fragment F on Pizza { id }")
+	require.Error(t, err)
+}
+
+func TestOperation(t *testing.T) {
+	t.Parallel()
+
+	s, err := Parse([]byte(pizzaSDL))
+	require.NoError(t, err)
+
+	for ref, want := range map[string]string{
+		"pizzas":                   "query",
+		"createPizza":              "mutation",
+		"RootMutation.createPizza": "mutation",
+		"query.version":            "query",
+		"mutation { createPizza(input: {name: \"a\", dough: THIN}) { id } }": "mutation",
+	} {
+		o, err := s.Operation(ref, 2)
+		require.NoError(t, err, ref)
+		assert.Equal(t, want, o.Type, ref)
+	}
+
+	_, err = s.Operation("Pizza.name", 2)
+	require.ErrorContains(t, err, "not a root type")
+}
+
+func TestRename(t *testing.T) {
+	t.Parallel()
+
+	s, err := Parse([]byte(pizzaSDL))
+	require.NoError(t, err)
+
+	for document, want := range map[string]string{
+		"# Version.\n{ version }":                             "# Version.\nquery Menu { version }",
+		"query($limit: Int) { pizzas(limit: $limit) { id } }": "query Menu($limit: Int) { pizzas(limit: $limit) { id } }",
+		"query Q { version }":                                 "query Menu { version }",
+	} {
+		o, err := s.DocumentOperation(document)
+		require.NoError(t, err, document)
+		o.Rename("Menu")
+		assert.Equal(t, "Menu", o.Name)
+		assert.Equal(t, want, o.Document)
+	}
+}
@@ -0,0 +1,498 @@
+// Package graphql reads GraphQL schemas, from SDL or introspection results,
+// and builds operations on their root fields for load tests.
+package graphql
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Kinds of types, named as in introspection results.
+const (
+	KindScalar      = "SCALAR"
+	KindObject      = "OBJECT"
+	KindInterface   = "INTERFACE"
+	KindUnion       = "UNION"
+	KindEnum        = "ENUM"
+	KindInputObject = "INPUT_OBJECT"
+)
+
+// ErrNoQuery is returned when a schema has no query type.
+var ErrNoQuery = errors.New("schema declares no query type")
+
+// Schema is the subset of a GraphQL schema needed to write operations.
+type Schema struct {
+	// Query, Mutation and Subscription are the names of the root types; only
+	// Query is required.
+	Query        string
+	Mutation     string
+	Subscription string
+	Types        map[string]*Type
+}
+
+// Type is a named type of a schema.
+type Type struct {
+	Name string
+	Kind string
+	// Fields are the fields of objects and interfaces, and the input fields of
+	// input objects.
+	Fields     []Field
+	EnumValues []string
+}
+
+// Field is a field, an argument or an input field.
+type Field struct {
+	Name string
+	// Type is the type reference in GraphQL notation, such as "[Pizza!]!".
+	Type string
+	Args []Field
+	// HasDefault is set on arguments and input fields with a default value.
+	HasDefault bool
+}
+
+// Required reports whether a value must be given for f: its type is non-null
+// and it has no default.
+func (f Field) Required() bool {
+	return strings.HasSuffix(f.Type, "!") && !f.HasDefault
+}
+
+// NamedType returns the named type of the type reference t, without its list
+// and non-null wrappers.
+func NamedType(t string) string {
+	return strings.Trim(t, "[]! ")
+}
+
+// builtinScalars are the scalars every schema has without declaring them.
+//
+//nolint:gochecknoglobals // Lookup table.
+var builtinScalars = []string{"String", "Int", "Float", "Boolean", "ID"}
+
+// Parse decodes a schema from SDL, or from the JSON result of an
+// introspection query, with or without its "data" envelope.
+func Parse(data []byte) (*Schema, error) {
+	var s *Schema
+	var err error
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' && json.Valid(trimmed) {
+		s, err = parseIntrospection(trimmed)
+	} else {
+		s, err = parseSDL(string(data))
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range builtinScalars {
+		if _, ok := s.Types[name]; !ok {
+			s.Types[name] = &Type{Name: name, Kind: KindScalar}
+		}
+	}
+	if s.Types[s.Query] == nil {
+		return nil, ErrNoQuery
+	}
+	return s, nil
+}
+
+// Root returns the root type of the operation type op ("query", "mutation"
+// or "subscription"), or nil.
+func (s *Schema) Root(op string) *Type {
+	switch op {
+	case "query":
+		return s.Types[s.Query]
+	case "mutation":
+		return s.Types[s.Mutation]
+	case "subscription":
+		return s.Types[s.Subscription]
+	}
+	return nil
+}
+
+type introspectionType struct {
+	Kind        string               `json:"kind"`
+	Name        string               `json:"name"`
+	Fields      []introspectionField `json:"fields"`
+	InputFields []introspectionField `json:"inputFields"`
+	EnumValues  []struct {
+		Name string `json:"name"`
+	} `json:"enumValues"`
+}
+
+type introspectionField struct {
+	Name         string               `json:"name"`
+	Type         *introspectionRef    `json:"type"`
+	Args         []introspectionField `json:"args"`
+	DefaultValue *string              `json:"defaultValue"`
+}
+
+type introspectionRef struct {
+	Kind   string            `json:"kind"`
+	Name   string            `json:"name"`
+	OfType *introspectionRef `json:"ofType"`
+}
+
+func (r *introspectionRef) String() string {
+	switch {
+	case r == nil:
+		return ""
+	case r.Kind == "NON_NULL":
+		return r.OfType.String() + "!"
+	case r.Kind == "LIST":
+		return "[" + r.OfType.String() + "]"
+	}
+	return r.Name
+}
+
+func parseIntrospection(data []byte) (*Schema, error) {
+	type rootRef struct{ Name string }
+	type schemaDoc struct {
+		QueryType        *rootRef            `json:"queryType"`
+		MutationType     *rootRef            `json:"mutationType"`
+		SubscriptionType *rootRef            `json:"subscriptionType"`
+		Types            []introspectionType `json:"types"`
+	}
+	var doc struct {
+		Schema *schemaDoc `json:"__schema"`
+		Data   struct {
+			Schema *schemaDoc `json:"__schema"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("invalid introspection result: %w", err)
+	}
+	sd := doc.Schema
+	if sd == nil {
+		sd = doc.Data.Schema
+	}
+	if sd == nil {
+		return nil, errors.New("invalid introspection result: missing '__schema'")
+	}
+
+	s := &Schema{Types: make(map[string]*Type, len(sd.Types))}
+	if sd.QueryType != nil {
+		s.Query = sd.QueryType.Name
+	}
+	if sd.MutationType != nil {
+		s.Mutation = sd.MutationType.Name
+	}
+	if sd.SubscriptionType != nil {
+		s.Subscription = sd.SubscriptionType.Name
+	}
+	for _, it := range sd.Types {
+		t := &Type{Name: it.Name, Kind: it.Kind}
+		fields := it.Fields
+		if it.Kind == KindInputObject {
+			fields = it.InputFields
+		}
+		t.Fields = introspectionFields(fields)
+		for _, v := range it.EnumValues {
+			t.EnumValues = append(t.EnumValues, v.Name)
+		}
+		s.Types[t.Name] = t
+	}
+	return s, nil
+}
+
+func introspectionFields(fields []introspectionField) []Field {
+	out := make([]Field, 0, len(fields))
+	for _, f := range fields {
+		out = append(out, Field{
+			Name:       f.Name,
+			Type:       f.Type.String(),
+			Args:       introspectionFields(f.Args),
+			HasDefault: f.DefaultValue != nil,
+		})
+	}
+	return out
+}
+
+// sdlKinds maps the SDL definition keywords to the kinds they declare.
+//
+//nolint:gochecknoglobals // Lookup table.
+var sdlKinds = map[string]string{
+	"scalar":    KindScalar,
+	"type":      KindObject,
+	"interface": KindInterface,
+	"union":     KindUnion,
+	"enum":      KindEnum,
+	"input":     KindInputObject,
+}
+
+func parseSDL(source string) (*Schema, error) {
+	p := &sdlParser{tokens: tokenize(strings.TrimPrefix(source, "\ufeff"))}
+	s := &Schema{Types: make(map[string]*Type)}
+	schemaDeclared := false
+	for !p.done() {
+		keyword := p.next()
+		if keyword == "extend" {
+			keyword = p.next()
+		}
+		switch {
+		case keyword == "schema":
+			schemaDeclared = true
+			p.directives()
+			if !p.accept("{") {
+				return nil, p.errorf("expected '{' after 'schema'")
+			}
+			for !p.done() && !p.accept("}") {
+				op := p.next()
+				p.accept(":")
+				name := p.next()
+				switch op {
+				case "query":
+					s.Query = name
+				case "mutation":
+					s.Mutation = name
+				case "subscription":
+					s.Subscription = name
+				}
+			}
+		case keyword == "directive":
+			p.directiveDefinition()
+		case sdlKinds[keyword] != "":
+			if err := p.typeDefinition(s, sdlKinds[keyword]); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, p.errorf("unexpected %q", keyword)
+		}
+	}
+	if !schemaDeclared {
+		s.Query, s.Mutation, s.Subscription = "Query", "Mutation", "Subscription"
+	}
+	return s, nil
+}
+
+// sdlParser reads the definitions of an SDL document. Descriptions are
+// dropped by the tokenizer.
+type sdlParser struct {
+	tokens []token
+	pos    int
+}
+
+type token struct {
+	text string
+	line int
+}
+
+func (p *sdlParser) done() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *sdlParser) peek() string {
+	if p.done() {
+		return ""
+	}
+	return p.tokens[p.pos].text
+}
+
+func (p *sdlParser) next() string {
+	t := p.peek()
+	p.pos++
+	return t
+}
+
+func (p *sdlParser) accept(text string) bool {
+	if p.peek() != text {
+		return false
+	}
+	p.pos++
+	return true
+}
+
+func (p *sdlParser) errorf(format string, args ...any) error {
+	line := 0
+	if n := min(p.pos, len(p.tokens)) - 1; n >= 0 {
+		line = p.tokens[n].line
+	}
+	return fmt.Errorf("invalid SDL line %d: %s", line, fmt.Sprintf(format, args...))
+}
+
+// skipBalanced skips from an opening bracket to its closing bracket.
+func (p *sdlParser) skipBalanced() {
+	depth := 0
+	for !p.done() {
+		switch p.next() {
+		case "(", "[", "{":
+			depth++
+		case ")", "]", "}":
+			depth--
+		}
+		if depth <= 0 {
+			return
+		}
+	}
+}
+
+// directives skips the directives applied at the current position.
+func (p *sdlParser) directives() {
+	for p.accept("@") {
+		p.next()
+		if p.peek() == "(" {
+			p.skipBalanced()
+		}
+	}
+}
+
+// directiveDefinition skips "directive @name(args) repeatable on A | B".
+func (p *sdlParser) directiveDefinition() {
+	p.accept("@")
+	p.next()
+	if p.peek() == "(" {
+		p.skipBalanced()
+	}
+	p.accept("repeatable")
+	if p.accept("on") {
+		p.accept("|")
+		p.next()
+		for p.accept("|") {
+			p.next()
+		}
+	}
+}
+
+func (p *sdlParser) typeDefinition(s *Schema, kind string) error {
+	name := p.next()
+	t := s.Types[name]
+	if t == nil {
+		t = &Type{Name: name, Kind: kind}
+		s.Types[name] = t
+	}
+	if p.accept("implements") {
+		p.accept("&")
+		p.next()
+		for p.accept("&") {
+			p.next()
+		}
+	}
+	p.directives()
+
+	switch kind {
+	case KindUnion:
+		if p.accept("=") {
+			p.accept("|")
+			p.next()
+			for p.accept("|") {
+				p.next()
+			}
+		}
+	case KindEnum:
+		if p.accept("{") {
+			for !p.done() && !p.accept("}") {
+				t.EnumValues = append(t.EnumValues, p.next())
+				p.directives()
+			}
+		}
+	case KindObject, KindInterface, KindInputObject:
+		if p.accept("{") {
+			for !p.done() && !p.accept("}") {
+				f, err := p.field()
+				if err != nil {
+					return err
+				}
+				t.Fields = append(t.Fields, f)
+			}
+		}
+	}
+	return nil
+}
+
+// field reads a field, argument or input field definition.
+func (p *sdlParser) field() (Field, error) {
+	f := Field{Name: p.next()}
+	if p.accept("(") {
+		for !p.done() && !p.accept(")") {
+			arg, err := p.field()
+			if err != nil {
+				return f, err
+			}
+			f.Args = append(f.Args, arg)
+		}
+	}
+	if !p.accept(":") {
+		return f, p.errorf("expected ':' after %q", f.Name)
+	}
+	f.Type = p.typeRef()
+	if p.accept("=") {
+		f.HasDefault = true
+		if v := p.peek(); v == "[" || v == "{" {
+			p.skipBalanced()
+		} else {
+			p.next()
+		}
+	}
+	p.directives()
+	return f, nil
+}
+
+// typeRef reads a type reference such as [Pizza!]!.
+func (p *sdlParser) typeRef() string {
+	if p.accept("[") {
+		inner := p.typeRef()
+		p.accept("]")
+		ref := "[" + inner + "]"
+		if p.accept("!") {
+			ref += "!"
+		}
+		return ref
+	}
+	ref := p.next()
+	if p.accept("!") {
+		ref += "!"
+	}
+	return ref
+}
+
+// tokenize splits SDL source into names, values and punctuators, dropping
+// comments, commas and descriptions.
+func tokenize(source string) []token {
+	var tokens []token
+	line := 1
+	for i := 0; i < len(source); {
+		c := source[i]
+		switch {
+		case c == '\n':
+			line++
+			i++
+		case c == ' ' || c == '\t' || c == '\r' || c == ',':
+			i++
+		case c == '#':
+			for i < len(source) && source[i] != '\n' {
+				i++
+			}
+		case strings.HasPrefix(source[i:], `"""`):
+			end := strings.Index(source[i+3:], `"""`)
+			if end < 0 {
+				end = len(source) - i - 3
+			}
+			line += strings.Count(source[i:i+3+end], "\n")
+			i += 6 + end
+		case c == '"':
+			j := i + 1
+			for j < len(source) && source[j] != '"' && source[j] != '\n' {
+				if source[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			// A string is a description unless it is a default value.
+			if len(tokens) > 0 && tokens[len(tokens)-1].text == "=" {
+				tokens = append(tokens, token{text: source[i:min(j+1, len(source))], line: line})
+			}
+			i = j + 1
+		case strings.ContainsRune("!()[]{}:=@|&", rune(c)):
+			tokens = append(tokens, token{text: string(c), line: line})
+			i++
+		case strings.HasPrefix(source[i:], "..."):
+			i += 3
+		default:
+			j := i
+			for j < len(source) && !strings.ContainsRune(" \t\r\n,#\"!()[]{}:=@|&", rune(source[j])) {
+				j++
+			}
+			tokens = append(tokens, token{text: source[i:j], line: line})
+			i = j
+		}
+	}
+	return tokens
+}
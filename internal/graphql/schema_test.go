@@ -0,0 +1,124 @@
+package graphql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const pizzaSDL = `
+"""
+The QuickPizza API.
+"""
+schema @link(url: "https://specs.apollo.dev/federation/v2.0") {
+  query: RootQuery
+  mutation: RootMutation
+}
+
+directive @auth(role: String = "user") repeatable on FIELD_DEFINITION | OBJECT
+
+scalar DateTime
+
+enum Dough { THIN THICK }
+
+interface Node { id: ID! }
+
+union SearchResult = Pizza | Ingredient
+
+type Pizza implements Node @key(fields: "id") {
+  id: ID!
+  "The name shown on the menu."
+  name: String!
+  dough: Dough
+  ingredients(first: Int = 10): [Ingredient!]!
+  reviews(after: String!): [String]
+  createdAt: DateTime
+}
+
+type Ingredient { name: String! calories: Int }
+
+input PizzaInput {
+  name: String!
+  dough: Dough!
+  tags: [String!]! = []
+  extra: String
+}
+
+type RootQuery {
+  # Look up a pizza.
+  pizza(id: ID!): Pizza @auth
+  pizzas(limit: Int): [Pizza!]!
+  search(text: String!): [SearchResult!]!
+}
+
+type RootMutation {
+  createPizza(input: PizzaInput!, dryRun: Boolean! = false): Pizza
+}
+
+extend type RootQuery {
+  version: String
+}
+`
+
+func TestParseSDL(t *testing.T) {
+	t.Parallel()
+
+	s, err := Parse([]byte(pizzaSDL))
+	require.NoError(t, err)
+	assert.Equal(t, "RootQuery", s.Query)
+	assert.Equal(t, "RootMutation", s.Mutation)
+	assert.Equal(t, KindScalar, s.Types["Int"].Kind)
+	assert.Equal(t, []string{"THIN", "THICK"}, s.Types["Dough"].EnumValues)
+	assert.Len(t, s.Types["RootQuery"].Fields, 4)
+
+	pizza := s.Types["Pizza"]
+	require.Len(t, pizza.Fields, 6)
+	assert.Equal(t, "[Ingredient!]!", pizza.Fields[3].Type)
+	assert.True(t, pizza.Fields[3].Args[0].HasDefault)
+
+	input := s.Types["PizzaInput"]
+	assert.Equal(t, KindInputObject, input.Kind)
+	assert.True(t, input.Fields[1].Required())
+	assert.False(t, input.Fields[2].Required())
+}
+
+func TestParseIntrospection(t *testing.T) {
+	t.Parallel()
+
+	s, err := Parse([]byte(`{"data": {"__schema": {
+  "queryType": {"name": "Query"},
+  "mutationType": null,
+  "types": [
+    {"kind": "OBJECT", "name": "Query", "fields": [
+      {"name": "pizza", "args": [
+        {"name": "id", "type": {"kind": "NON_NULL", "ofType": {"kind": "SCALAR", "name": "ID"}}, "defaultValue": null}
+      ], "type": {"kind": "OBJECT", "name": "Pizza"}}
+    ]},
+    {"kind": "OBJECT", "name": "Pizza", "fields": [
+      {"name": "tags", "args": [], "type": {"kind": "NON_NULL", "ofType":
+        {"kind": "LIST", "ofType": {"kind": "SCALAR", "name": "String"}}}}
+    ]},
+    {"kind": "ENUM", "name": "Dough", "enumValues": [{"name": "THIN"}]}
+  ]
+}}}`))
+	require.NoError(t, err)
+	assert.Equal(t, "Query", s.Query)
+	assert.Empty(t, s.Mutation)
+	assert.True(t, s.Types["Query"].Fields[0].Args[0].Required())
+	assert.Equal(t, "[String]!", s.Types["Pizza"].Fields[0].Type)
+	assert.Equal(t, []string{"THIN"}, s.Types["Dough"].EnumValues)
+}
+
+func TestParseErrors(t *testing.T) {
+	t.Parallel()
+
+	_, err := Parse([]byte("type Pizza { id: ID! }"))
+	require.ErrorIs(t, err, ErrNoQuery)
+
+	_, err = Parse([]byte("type Query { pizza ID }"))
+	require.ErrorContains(t, err, "line 1")
+
+	_, err = Parse([]byte(`{"data": null}`))
+	require.ErrorContains(t, err, "__schema")
+}
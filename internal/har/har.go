@@ -0,0 +1,148 @@
+// Package har parses HTTP Archive (HAR) recordings such as the ones exported by
+// k6 Studio and the Grafana k6 browser recorder.
+package har
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Archive is the top-level HAR document.
+type Archive struct {
+	Log Log `json:"log"`
+}
+
+// Log holds the recorded pages and entries.
+type Log struct {
+	Version string  `json:"version"`
+	Creator Creator `json:"creator"`
+	Pages   []Page  `json:"pages"`
+	Entries []Entry `json:"entries"`
+}
+
+// Creator identifies the application that produced the recording.
+type Creator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// Page is a top-level navigation captured during the recording.
+type Page struct {
+	ID              string `json:"id"`
+	Title           string `json:"title"`
+	StartedDateTime string `json:"startedDateTime"`
+}
+
+// Entry is a single request/response pair.
+type Entry struct {
+	PageRef         string   `json:"pageref,omitempty"`
+	StartedDateTime string   `json:"startedDateTime"`
+	Time            float64  `json:"time"`
+	Request         Request  `json:"request"`
+	Response        Response `json:"response"`
+}
+
+// Request is the recorded HTTP request.
+type Request struct {
+	Method   string      `json:"method"`
+	URL      string      `json:"url"`
+	Headers  []NameValue `json:"headers"`
+	Cookies  []NameValue `json:"cookies,omitempty"`
+	PostData *PostData   `json:"postData,omitempty"`
+}
+
+// Response is the recorded HTTP response.
+type Response struct {
+	Status  int         `json:"status"`
+	Headers []NameValue `json:"headers"`
+	Cookies []NameValue `json:"cookies,omitempty"`
+	Content Content     `json:"content"`
+}
+
+// NameValue is a header, cookie, query or form parameter.
+type NameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// PostData is the body of a recorded request.
+type PostData struct {
+	MimeType string      `json:"mimeType"`
+	Text     string      `json:"text"`
+	Params   []NameValue `json:"params,omitempty"`
+}
+
+// Content is the body of a recorded response.
+type Content struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+	Encoding string `json:"encoding,omitempty"`
+}
+
+// ErrNoEntries is returned when a recording contains no requests.
+var ErrNoEntries = errors.New("recording contains no entries")
+
+// Parse decodes a HAR document and checks that it contains at least one entry.
+func Parse(data []byte) (*Archive, error) {
+	var archive Archive
+	if err := json.Unmarshal(data, &archive); err != nil {
+		return nil, fmt.Errorf("invalid HAR document: %w", err)
+	}
+	if len(archive.Log.Entries) == 0 {
+		return nil, ErrNoEntries
+	}
+	for i, e := range archive.Log.Entries {
+		if e.Request.Method == "" || e.Request.URL == "" {
+			return nil, fmt.Errorf("entry %d is missing a request method or URL", i)
+		}
+	}
+	return &archive, nil
+}
+
+// Header returns the first value of the named header (case-insensitive).
+func Header(headers []NameValue, name string) (string, bool) {
+	for _, h := range headers {
+		if strings.EqualFold(h.Name, name) {
+			return h.Value, true
+		}
+	}
+	return "", false
+}
+
+// Host returns the host (with port, if any) of the entry's request URL.
+func (e Entry) Host() string {
+	u, err := url.Parse(e.Request.URL)
+	if err != nil {
+		return ""
+	}
+	return u.Host
+}
+
+// Started returns the parsed start time of the entry. The zero time is
+// returned when the timestamp is missing or malformed.
+func (e Entry) Started() time.Time {
+	t, err := time.Parse(time.RFC3339Nano, e.StartedDateTime)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// PageTitle returns the title of the page with the given ID, falling back to
+// the ID itself when no title was recorded.
+func (l Log) PageTitle(id string) string {
+	for _, p := range l.Pages {
+		if p.ID == id {
+			if p.Title != "" {
+				return p.Title
+			}
+			return p.ID
+		}
+	}
+	return id
+}
@@ -0,0 +1,129 @@
+// Package httpdebug extracts the request and response dumps k6 prints to
+// stdout when it runs with --http-debug.
+package httpdebug
+
+import (
+	"strings"
+)
+
+// MaxBodySize bounds how much of each message body is kept.
+const MaxBodySize = 4 * 1024
+
+// Message is a single dumped HTTP request or response.
+type Message struct {
+	// StartLine is the request line ("GET /path HTTP/1.1") or the status
+	// line ("HTTP/1.1 200 OK").
+	StartLine string   `json:"start_line"`
+	Headers   []Header `json:"headers,omitempty"`
+	Body      string   `json:"body,omitempty"`
+	// Truncated is set when the body was longer than MaxBodySize.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// Header is a single header line of a message.
+type Header struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// Get returns the first value of the named header (case-insensitive).
+func (m *Message) Get(name string) (string, bool) {
+	for _, h := range m.Headers {
+		if strings.EqualFold(h.Name, name) {
+			return h.Value, true
+		}
+	}
+	return "", false
+}
+
+// Exchange pairs a request with the response k6 received for it.
+type Exchange struct {
+	Request  *Message `json:"request"`
+	Response *Message `json:"response,omitempty"`
+}
+
+const (
+	requestMarker  = "Request:"
+	responseMarker = "Response:"
+)
+
+// Parse returns the exchanges dumped in output, in order, along with the
+// output with the dumps removed.
+func Parse(output string) ([]Exchange, string) {
+	lines := strings.Split(output, "\n")
+	var exchanges []Exchange
+	var rest []string
+
+	for i := 0; i < len(lines); {
+		marker := strings.TrimSpace(lines[i])
+		if marker != requestMarker && marker != responseMarker {
+			rest = append(rest, lines[i])
+			i++
+			continue
+		}
+
+		var msg *Message
+		msg, i = parseMessage(lines, i+1)
+		switch {
+		case marker == requestMarker:
+			exchanges = append(exchanges, Exchange{Request: msg})
+		case len(exchanges) > 0 && exchanges[len(exchanges)-1].Response == nil:
+			exchanges[len(exchanges)-1].Response = msg
+		default:
+			// A response without a dumped request, e.g. the output was cut.
+			exchanges = append(exchanges, Exchange{Request: &Message{}, Response: msg})
+		}
+	}
+	return exchanges, strings.Join(rest, "\n")
+}
+
+// parseMessage reads a dump starting at line i: the start line, headers up
+// to the first blank line, then the body up to the next blank line or marker.
+func parseMessage(lines []string, i int) (*Message, int) {
+	msg := &Message{}
+	if i < len(lines) {
+		msg.StartLine = trimCR(lines[i])
+		i++
+	}
+
+	for ; i < len(lines); i++ {
+		line := trimCR(lines[i])
+		if line == "" {
+			i++
+			break
+		}
+		if isMarker(line) {
+			return msg, i
+		}
+		name, value, _ := strings.Cut(line, ":")
+		msg.Headers = append(msg.Headers, Header{Name: name, Value: strings.TrimSpace(value)})
+	}
+
+	var body []string
+	for ; i < len(lines); i++ {
+		line := trimCR(lines[i])
+		if line == "" || isMarker(line) {
+			break
+		}
+		body = append(body, line)
+	}
+	if i < len(lines) && trimCR(lines[i]) == "" {
+		i++
+	}
+
+	msg.Body = strings.Join(body, "\n")
+	if len(msg.Body) > MaxBodySize {
+		msg.Body = msg.Body[:MaxBodySize]
+		msg.Truncated = true
+	}
+	return msg, i
+}
+
+func isMarker(line string) bool {
+	line = strings.TrimSpace(line)
+	return line == requestMarker || line == responseMarker
+}
+
+func trimCR(line string) string {
+	return strings.TrimSuffix(line, "\r")
+}
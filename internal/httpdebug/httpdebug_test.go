@@ -0,0 +1,74 @@
+package httpdebug
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const debugOutput = "\n         /\\      Grafana   /‾‾/\n" +
+	"Request:\n" +
+	"POST /login HTTP/1.1\r\n" +
+	"Host: test.k6.io\r\n" +
+	"User-Agent: Grafana k6/1.0.0\r\n" +
+	"Content-Type: application/json\r\n" +
+	"\r\n" +
+	"{\"user\":\"admin\"}\n" +
+	"Response:\n" +
+	"HTTP/1.1 302 Found\r\n" +
+	"Location: /home\r\n" +
+	"Content-Length: 0\r\n" +
+	"\r\n" +
+	"\n" +
+	"Request:\n" +
+	"GET /home HTTP/1.1\r\n" +
+	"Host: test.k6.io\r\n" +
+	"\r\n" +
+	"\n" +
+	"Response:\n" +
+	"HTTP/1.1 200 OK\r\n" +
+	"Content-Type: text/html\r\n" +
+	"\r\n" +
+	"<html>ok</html>\n" +
+	"\n" +
+	"     http_reqs......: 2\n"
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	exchanges, rest := Parse(debugOutput)
+
+	require.Len(t, exchanges, 2)
+	first := exchanges[0]
+	assert.Equal(t, "POST /login HTTP/1.1", first.Request.StartLine)
+	host, ok := first.Request.Get("host")
+	assert.True(t, ok)
+	assert.Equal(t, "test.k6.io", host)
+	assert.Equal(t, `{"user":"admin"}`, first.Request.Body)
+	require.NotNil(t, first.Response)
+	assert.Equal(t, "HTTP/1.1 302 Found", first.Response.StartLine)
+	assert.Empty(t, first.Response.Body)
+
+	second := exchanges[1]
+	assert.Equal(t, "GET /home HTTP/1.1", second.Request.StartLine)
+	assert.Empty(t, second.Request.Body)
+	assert.Equal(t, "<html>ok</html>", second.Response.Body)
+
+	assert.Contains(t, rest, "Grafana")
+	assert.Contains(t, rest, "http_reqs")
+	assert.NotContains(t, rest, "Request:")
+	assert.NotContains(t, rest, "test.k6.io")
+}
+
+func TestParseTruncatesBodies(t *testing.T) {
+	t.Parallel()
+
+	output := "Request:\nPOST / HTTP/1.1\r\n\r\n" + strings.Repeat("x", MaxBodySize+10) + "\n"
+	exchanges, _ := Parse(output)
+	require.Len(t, exchanges, 1)
+	assert.Len(t, exchanges[0].Request.Body, MaxBodySize)
+	assert.True(t, exchanges[0].Request.Truncated)
+	assert.Nil(t, exchanges[0].Response)
+}
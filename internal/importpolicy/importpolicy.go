@@ -0,0 +1,148 @@
+// Package importpolicy decides which remote modules k6 scripts may import,
+// for air-gapped environments and supply-chain policies.
+package importpolicy
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Modes of a Policy.
+const (
+	// Allow permits remote imports, from the allowed hosts when any are set.
+	Allow = "allow"
+	// Deny rejects every remote import.
+	Deny = "deny"
+)
+
+// ErrNotAllowed is returned for a remote import the policy rejects.
+var ErrNotAllowed = errors.New("remote import not allowed")
+
+// Policy controls the remote (http:// and https://) modules a script may
+// import. Local, built-in and extension modules are always allowed. A nil
+// *Policy allows every remote import.
+type Policy struct {
+	deny bool
+	// hosts limits remote imports to matching hosts when non-empty. Entries
+	// are "host", "host:port" or "*.domain" for subdomains.
+	hosts []string
+}
+
+// New returns a policy for mode ("allow", "deny" or empty for allow) that
+// limits remote imports to hosts when any are given.
+func New(mode string, hosts []string) (*Policy, error) {
+	p := &Policy{}
+	switch mode {
+	case "", Allow:
+	case Deny:
+		p.deny = true
+	default:
+		return nil, fmt.Errorf("remote import mode must be %q or %q, got %q", Allow, Deny, mode)
+	}
+	for _, h := range hosts {
+		h = strings.ToLower(strings.TrimSpace(h))
+		if h == "" || strings.ContainsAny(h, "/@ ") || strings.Contains(strings.TrimPrefix(h, "*."), "*") {
+			return nil, fmt.Errorf("invalid import host %q: use host, host:port or *.domain", h)
+		}
+		p.hosts = append(p.hosts, h)
+	}
+	return p, nil
+}
+
+// Restrict returns the policy of a single request, which may deny remote
+// imports or narrow the allowed hosts but not widen them.
+func (p *Policy) Restrict(mode string, hosts []string) (*Policy, error) {
+	r, err := New(mode, hosts)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return r, nil
+	}
+	if p.deny {
+		r.deny = true
+	}
+	if len(r.hosts) == 0 {
+		r.hosts = p.hosts
+		return r, nil
+	}
+	for _, h := range r.hosts {
+		if len(p.hosts) > 0 && !matchAny(p.hosts, strings.TrimPrefix(h, "*.")) {
+			return nil, fmt.Errorf("import host %q is not allowed by the server policy", h)
+		}
+	}
+	return r, nil
+}
+
+// Check returns an error wrapping ErrNotAllowed when module is a remote
+// module the policy rejects.
+func (p *Policy) Check(module string) error {
+	if p == nil || !IsRemote(module) {
+		return nil
+	}
+	if p.deny {
+		return fmt.Errorf("%w: remote imports are disabled", ErrNotAllowed)
+	}
+	if len(p.hosts) == 0 {
+		return nil
+	}
+	u, err := url.Parse(module)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrNotAllowed, err)
+	}
+	if !matchAny(p.hosts, strings.ToLower(u.Host)) {
+		return fmt.Errorf("%w: %s is not in the allowed hosts (%s)", ErrNotAllowed, u.Hostname(),
+			strings.Join(p.hosts, ", "))
+	}
+	return nil
+}
+
+// Restricted reports whether the policy rejects any remote import.
+func (p *Policy) Restricted() bool {
+	return p != nil && (p.deny || len(p.hosts) > 0)
+}
+
+// String describes the policy for humans.
+func (p *Policy) String() string {
+	switch {
+	case p == nil || !p.Restricted():
+		return "remote imports are allowed"
+	case p.deny:
+		return "remote imports are disabled"
+	default:
+		return "remote imports are limited to " + strings.Join(p.hosts, ", ")
+	}
+}
+
+// IsRemote reports whether module is loaded over the network.
+func IsRemote(module string) bool {
+	m := strings.ToLower(module)
+	return strings.HasPrefix(m, "https://") || strings.HasPrefix(m, "http://")
+}
+
+// matchAny reports whether host ("name" or "name:port") matches one of the
+// patterns. Patterns without a port match any port.
+func matchAny(patterns []string, host string) bool {
+	name := host
+	if i := strings.LastIndexByte(host, ':'); i >= 0 && !strings.Contains(host[i:], "]") {
+		name = host[:i]
+	}
+	for _, pattern := range patterns {
+		target := name
+		if strings.Contains(strings.TrimPrefix(pattern, "*."), ":") {
+			target = host
+		}
+		if suffix, ok := strings.CutPrefix(pattern, "*."); ok {
+			if strings.HasSuffix(target, "."+suffix) {
+				return true
+			}
+			continue
+		}
+		if target == pattern {
+			return true
+		}
+	}
+	return false
+}
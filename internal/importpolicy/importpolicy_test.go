@@ -0,0 +1,74 @@
+package importpolicy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicyCheck(t *testing.T) {
+	t.Parallel()
+
+	var open *Policy
+	require.NoError(t, open.Check("https://jslib.k6.io/k6-utils/1.4.0/index.js"))
+
+	deny, err := New(Deny, nil)
+	require.NoError(t, err)
+	require.ErrorIs(t, deny.Check("https://jslib.k6.io/k6-utils/1.4.0/index.js"), ErrNotAllowed)
+	require.NoError(t, deny.Check("k6/http"))
+	require.NoError(t, deny.Check("./lib/helpers.js"))
+
+	hosts, err := New(Allow, []string{"jslib.k6.io", "*.corp.example", "mirror.local:8443"})
+	require.NoError(t, err)
+	require.NoError(t, hosts.Check("https://jslib.k6.io/k6-utils/1.4.0/index.js"))
+	require.NoError(t, hosts.Check("https://js.corp.example/lib.js"))
+	require.NoError(t, hosts.Check("https://mirror.local:8443/lib.js"))
+	require.ErrorIs(t, hosts.Check("https://corp.example/lib.js"), ErrNotAllowed)
+	require.ErrorIs(t, hosts.Check("https://mirror.local/lib.js"), ErrNotAllowed)
+	require.ErrorIs(t, hosts.Check("http://cdn.jsdelivr.net/npm/lodash"), ErrNotAllowed)
+
+	assert.Equal(t, "remote imports are limited to jslib.k6.io, *.corp.example, mirror.local:8443", hosts.String())
+	assert.Equal(t, "remote imports are disabled", deny.String())
+	assert.Equal(t, "remote imports are allowed", open.String())
+}
+
+func TestNewRejectsInvalidConfiguration(t *testing.T) {
+	t.Parallel()
+
+	_, err := New("block", nil)
+	require.Error(t, err)
+	for _, host := range []string{"https://jslib.k6.io", "", "a.*.example", "user@host"} {
+		_, err = New(Allow, []string{host})
+		require.Error(t, err, host)
+	}
+}
+
+func TestRestrict(t *testing.T) {
+	t.Parallel()
+
+	server, err := New(Allow, []string{"*.corp.example", "jslib.k6.io"})
+	require.NoError(t, err)
+
+	p, err := server.Restrict("", []string{"jslib.k6.io"})
+	require.NoError(t, err)
+	require.ErrorIs(t, p.Check("https://js.corp.example/lib.js"), ErrNotAllowed)
+	require.NoError(t, p.Check("https://jslib.k6.io/lib.js"))
+
+	p, err = server.Restrict("", []string{"js.corp.example"})
+	require.NoError(t, err)
+	require.NoError(t, p.Check("https://js.corp.example/lib.js"))
+
+	_, err = server.Restrict("", []string{"cdn.jsdelivr.net"})
+	require.Error(t, err)
+
+	p, err = server.Restrict(Deny, nil)
+	require.NoError(t, err)
+	require.ErrorIs(t, p.Check("https://jslib.k6.io/lib.js"), ErrNotAllowed)
+
+	serverDeny, err := New(Deny, nil)
+	require.NoError(t, err)
+	p, err = serverDeny.Restrict(Allow, nil)
+	require.NoError(t, err)
+	require.ErrorIs(t, p.Check("https://jslib.k6.io/lib.js"), ErrNotAllowed)
+}
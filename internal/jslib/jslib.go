@@ -0,0 +1,250 @@
+// Package jslib vendors k6 jslib modules into a local directory and serves
+// them to k6 from a loopback HTTP server, so scripts importing jslib run
+// without internet access.
+package jslib
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/grafana/mcp-k6/internal/scriptinfo"
+)
+
+// Origin is where k6 scripts import jslib modules from.
+const Origin = "https://jslib.k6.io"
+
+// maxModuleSize bounds the size of a vendored module in bytes (10MB).
+const maxModuleSize = 10 * 1024 * 1024
+
+// Common lists the jslib modules vendored by default.
+//
+//nolint:gochecknoglobals // Read-only lookup table.
+var Common = []string{
+	"k6-utils/1.4.0/index.js",
+	"k6-summary/0.1.0/index.js",
+	"papaparse/5.1.1/index.js",
+	"url/1.0.0/index.js",
+	"httpx/0.1.0/index.js",
+	"k6chaijs/4.3.4.3/index.js",
+	"formdata/0.0.2/index.js",
+	"ajv/6.12.5/index.js",
+}
+
+// ErrInvalidModule is returned for module paths outside the jslib layout.
+var ErrInvalidModule = errors.New("invalid jslib module path")
+
+// Vendor downloads modules from origin into dir, along with the jslib
+// modules they import, and returns the paths it wrote. Modules already in
+// dir are kept.
+func Vendor(ctx context.Context, origin, dir string, modules []string) ([]string, error) {
+	client := &http.Client{Timeout: time.Minute}
+	queue := append([]string{}, modules...)
+	seen := make(map[string]bool, len(queue))
+	var written []string
+	for len(queue) > 0 {
+		module := queue[0]
+		queue = queue[1:]
+		if seen[module] {
+			continue
+		}
+		seen[module] = true
+
+		target, err := modulePath(dir, module)
+		if err != nil {
+			return written, err
+		}
+		//nolint:forbidigo // Reading the vendored mirror.
+		data, err := os.ReadFile(target) // #nosec G304 -- path is confined to the mirror directory
+		if err != nil {
+			if data, err = fetch(ctx, client, origin+"/"+module); err != nil {
+				return written, err
+			}
+			if err := writeModule(target, data); err != nil {
+				return written, err
+			}
+			written = append(written, module)
+		}
+		queue = append(queue, imports(module, string(data))...)
+	}
+	return written, nil
+}
+
+// imports returns the jslib modules imported by the module at p.
+func imports(p, source string) []string {
+	var modules []string
+	for _, imp := range scriptinfo.Analyze(source).Imports {
+		switch {
+		case strings.HasPrefix(imp.Module, Origin+"/"):
+			modules = append(modules, strings.TrimPrefix(imp.Module, Origin+"/"))
+		case strings.HasPrefix(imp.Module, "./"), strings.HasPrefix(imp.Module, "../"):
+			if resolved := path.Join(path.Dir(p), imp.Module); !strings.HasPrefix(resolved, "../") {
+				modules = append(modules, resolved)
+			}
+		}
+	}
+	return modules
+}
+
+func fetch(ctx context.Context, client *http.Client, u string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("downloading %s: %w", u, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("downloading %s: %s", u, resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxModuleSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("downloading %s: %w", u, err)
+	}
+	if len(data) > maxModuleSize {
+		return nil, fmt.Errorf("downloading %s: module exceeds %d bytes", u, maxModuleSize)
+	}
+	return data, nil
+}
+
+func writeModule(target string, data []byte) error {
+	const dirMode, fileMode = 0o755, 0o644
+	//nolint:forbidigo // Writing the vendored mirror.
+	if err := os.MkdirAll(filepath.Dir(target), dirMode); err != nil {
+		return fmt.Errorf("creating mirror directory: %w", err)
+	}
+	//nolint:forbidigo // Writing the vendored mirror.
+	if err := os.WriteFile(target, data, fileMode); err != nil {
+		return fmt.Errorf("writing %s: %w", target, err)
+	}
+	return nil
+}
+
+// modulePath returns the file of module in the mirror at dir.
+func modulePath(dir, module string) (string, error) {
+	clean := path.Clean(strings.TrimPrefix(module, "/"))
+	if clean == "." || !filepath.IsLocal(filepath.FromSlash(clean)) {
+		return "", fmt.Errorf("%w: %q", ErrInvalidModule, module)
+	}
+	return filepath.Join(dir, filepath.FromSlash(clean)), nil
+}
+
+// Mirror serves a vendored jslib directory on a loopback address. A nil
+// *Mirror leaves scripts unchanged.
+type Mirror struct {
+	dir    string
+	base   string
+	server *http.Server
+}
+
+// Serve starts serving the mirror at dir on 127.0.0.1.
+func Serve(dir string) (*Mirror, error) {
+	//nolint:forbidigo // Checking the configured mirror directory.
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("jslib mirror: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("jslib mirror: %s is not a directory", dir)
+	}
+
+	var lc net.ListenConfig
+	ln, err := lc.Listen(context.Background(), "tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("jslib mirror: %w", err)
+	}
+	m := &Mirror{dir: dir, base: "http://" + ln.Addr().String()}
+	m.server = &http.Server{Handler: m, ReadHeaderTimeout: 10 * time.Second}
+	go func() { _ = m.server.Serve(ln) }()
+	return m, nil
+}
+
+// URL returns the base URL the mirror is served at.
+func (m *Mirror) URL() string {
+	if m == nil {
+		return ""
+	}
+	return m.base
+}
+
+// Close stops serving the mirror.
+func (m *Mirror) Close() error {
+	if m == nil {
+		return nil
+	}
+	return m.server.Close()
+}
+
+// Has reports whether the mirror holds module, given as a path or a jslib URL.
+func (m *Mirror) Has(module string) bool {
+	if m == nil {
+		return false
+	}
+	target, err := modulePath(m.dir, strings.TrimPrefix(module, Origin+"/"))
+	if err != nil {
+		return false
+	}
+	//nolint:forbidigo // Reading the vendored mirror.
+	info, err := os.Stat(target)
+	return err == nil && !info.IsDir()
+}
+
+// Rewrite points the jslib imports of source that the mirror holds to the
+// mirror, and returns the jslib modules it does not hold.
+func (m *Mirror) Rewrite(source string) (string, []string) {
+	if m == nil || !strings.Contains(source, Origin+"/") {
+		return source, nil
+	}
+	var missing []string
+	for _, imp := range scriptinfo.Analyze(source).Imports {
+		if !strings.HasPrefix(imp.Module, Origin+"/") {
+			continue
+		}
+		if !m.Has(imp.Module) {
+			missing = append(missing, imp.Module)
+			continue
+		}
+		mirrored := m.base + "/" + strings.TrimPrefix(imp.Module, Origin+"/")
+		source = strings.ReplaceAll(source, "'"+imp.Module+"'", "'"+mirrored+"'")
+		source = strings.ReplaceAll(source, `"`+imp.Module+`"`, `"`+mirrored+`"`)
+	}
+	return source, missing
+}
+
+// ServeHTTP serves a vendored module with its absolute jslib imports
+// pointed to the mirror.
+func (m *Mirror) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	module, err := url.PathUnescape(r.URL.Path)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	target, err := modulePath(m.dir, module)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	//nolint:forbidigo // Serving the vendored mirror.
+	data, err := os.ReadFile(target) // #nosec G304 -- path is confined to the mirror directory
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "application/javascript")
+	_, _ = io.WriteString(w, strings.ReplaceAll(string(data), Origin+"/", m.base+"/"))
+}
@@ -0,0 +1,104 @@
+package jslib
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVendor(t *testing.T) {
+	t.Parallel()
+
+	modules := map[string]string{
+		"/k6-utils/1.4.0/index.js":  "import { a } from './lib/a.js';\nexport const b = 1;\n",
+		"/k6-utils/1.4.0/lib/a.js":  "import { c } from 'https://jslib.k6.io/url/1.0.0/index.js';\nexport const a = 1;\n",
+		"/url/1.0.0/index.js":       "export const c = 1;\n",
+		"/papaparse/5.1.1/index.js": "export default {};\n",
+	}
+	requests := 0
+	origin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		body, ok := modules[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = io.WriteString(w, body)
+	}))
+	t.Cleanup(origin.Close)
+
+	dir := t.TempDir()
+	written, err := Vendor(context.Background(), origin.URL, dir, []string{"k6-utils/1.4.0/index.js"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"k6-utils/1.4.0/index.js", "k6-utils/1.4.0/lib/a.js", "url/1.0.0/index.js"}, written)
+	assert.FileExists(t, filepath.Join(dir, "url", "1.0.0", "index.js"))
+
+	// Vendored modules are not downloaded again
+	requests = 0
+	written, err = Vendor(context.Background(), origin.URL, dir, []string{"k6-utils/1.4.0/index.js"})
+	require.NoError(t, err)
+	assert.Empty(t, written)
+	assert.Equal(t, 0, requests)
+
+	_, err = Vendor(context.Background(), origin.URL, dir, []string{"missing/1.0.0/index.js"})
+	require.Error(t, err)
+	_, err = Vendor(context.Background(), origin.URL, dir, []string{"../escape.js"})
+	require.ErrorIs(t, err, ErrInvalidModule)
+}
+
+func TestMirror(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	require.NoError(t, writeModule(filepath.Join(dir, "k6-utils", "1.4.0", "index.js"),
+		[]byte("import { c } from 'https://jslib.k6.io/url/1.0.0/index.js';\n")))
+
+	m, err := Serve(dir)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = m.Close() })
+
+	script := `import { randomItem } from 'https://jslib.k6.io/k6-utils/1.4.0/index.js';
+import papa from "https://jslib.k6.io/papaparse/5.1.1/index.js";
+`
+	rewritten, missing := m.Rewrite(script)
+	assert.Contains(t, rewritten, "from '"+m.URL()+"/k6-utils/1.4.0/index.js'")
+	assert.Contains(t, rewritten, `from "https://jslib.k6.io/papaparse/5.1.1/index.js"`)
+	assert.Equal(t, []string{"https://jslib.k6.io/papaparse/5.1.1/index.js"}, missing)
+
+	resp, err := http.Get(m.URL() + "/k6-utils/1.4.0/index.js")
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "import { c } from '"+m.URL()+"/url/1.0.0/index.js';\n", string(body))
+
+	resp, err = http.Get(m.URL() + "/../../etc/passwd")
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	var unset *Mirror
+	out, missing := unset.Rewrite(script)
+	assert.Equal(t, script, out)
+	assert.Empty(t, missing)
+}
+
+func TestServeRequiresDirectory(t *testing.T) {
+	t.Parallel()
+
+	_, err := Serve(filepath.Join(t.TempDir(), "missing"))
+	require.Error(t, err)
+
+	file := filepath.Join(t.TempDir(), "file")
+	require.NoError(t, os.WriteFile(file, nil, 0o600))
+	_, err = Serve(file)
+	require.Error(t, err)
+}
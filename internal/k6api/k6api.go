@@ -0,0 +1,234 @@
+// Package k6api is a client for the REST API k6 serves while a test runs
+// (k6 run --address), used to watch and steer the test from the outside.
+package k6api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// maxResponseSize bounds the size of API responses in bytes.
+const maxResponseSize = 4 * 1024 * 1024
+
+// ErrUnavailable is returned when the API does not answer, usually because
+// k6 has not started serving it yet or the test has ended.
+var ErrUnavailable = errors.New("k6 REST API unavailable")
+
+// executionStatuses names the execution statuses k6 reports, by value.
+//
+//nolint:gochecknoglobals // Read-only lookup table.
+var executionStatuses = []string{
+	"created",
+	"init_vus",
+	"init_executors",
+	"init_done",
+	"paused_before_run",
+	"started",
+	"setup",
+	"running",
+	"teardown",
+	"ended",
+	"interrupted",
+}
+
+// Status is the execution status of a running test.
+type Status struct {
+	// Execution is the k6 execution stage, such as "running" or "teardown".
+	Execution string `json:"execution"`
+	Paused    bool   `json:"paused"`
+	VUs       int64  `json:"vus"`
+	VUsMax    int64  `json:"vus_max"`
+	Stopped   bool   `json:"stopped"`
+	Running   bool   `json:"running"`
+	// Tainted is true once a threshold has failed.
+	Tainted bool `json:"tainted"`
+}
+
+// StatusUpdate changes the status of a running test. Nil fields are left
+// unchanged.
+type StatusUpdate struct {
+	Paused  *bool  `json:"paused,omitempty"`
+	VUs     *int64 `json:"vus,omitempty"`
+	VUsMax  *int64 `json:"vus-max,omitempty"`
+	Stopped *bool  `json:"stopped,omitempty"`
+}
+
+// Metric is the current value of a metric of a running test.
+type Metric struct {
+	Name string `json:"name"`
+	// Type is the k6 metric type: counter, gauge, rate or trend.
+	Type string `json:"type"`
+	// Contains is "default", "time" or "data".
+	Contains string `json:"contains,omitempty"`
+	// Sample holds the aggregated values, such as count and rate for
+	// counters or avg and p(95) for trends.
+	Sample map[string]float64 `json:"sample"`
+}
+
+// Client talks to the REST API of one k6 process.
+type Client struct {
+	base string
+	http *http.Client
+}
+
+// New returns a client for the API listening on addr ("host:port").
+func New(addr string) *Client {
+	return &Client{base: "http://" + addr, http: &http.Client{Timeout: 5 * time.Second}}
+}
+
+// FreeAddress returns a loopback address with a port no one listens on, for
+// passing to k6 run --address.
+func FreeAddress(ctx context.Context) (string, error) {
+	var lc net.ListenConfig
+	ln, err := lc.Listen(ctx, "tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", fmt.Errorf("finding a free port: %w", err)
+	}
+	addr := ln.Addr().String()
+	if err := ln.Close(); err != nil {
+		return "", fmt.Errorf("finding a free port: %w", err)
+	}
+	return addr, nil
+}
+
+// statusAttributes is the JSON:API representation of Status.
+type statusAttributes struct {
+	Status  int   `json:"status"`
+	Paused  bool  `json:"paused"`
+	VUs     int64 `json:"vus"`
+	VUsMax  int64 `json:"vus-max"`
+	Stopped bool  `json:"stopped"`
+	Running bool  `json:"running"`
+	Tainted bool  `json:"tainted"`
+}
+
+func (a statusAttributes) status() *Status {
+	execution := fmt.Sprintf("status_%d", a.Status)
+	if a.Status >= 0 && a.Status < len(executionStatuses) {
+		execution = executionStatuses[a.Status]
+	}
+	return &Status{
+		Execution: execution,
+		Paused:    a.Paused,
+		VUs:       a.VUs,
+		VUsMax:    a.VUsMax,
+		Stopped:   a.Stopped,
+		Running:   a.Running,
+		Tainted:   a.Tainted,
+	}
+}
+
+type envelope[T any] struct {
+	Data T `json:"data"`
+}
+
+type resource[T any] struct {
+	Type       string `json:"type"`
+	ID         string `json:"id"`
+	Attributes T      `json:"attributes"`
+}
+
+// Status returns the execution status of the test.
+func (c *Client) Status(ctx context.Context) (*Status, error) {
+	var resp envelope[resource[statusAttributes]]
+	if err := c.do(ctx, http.MethodGet, "/v1/status", nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Data.Attributes.status(), nil
+}
+
+// UpdateStatus applies update and returns the resulting status.
+func (c *Client) UpdateStatus(ctx context.Context, update StatusUpdate) (*Status, error) {
+	body := envelope[resource[StatusUpdate]]{Data: resource[StatusUpdate]{
+		Type: "status", ID: "default", Attributes: update,
+	}}
+	var resp envelope[resource[statusAttributes]]
+	if err := c.do(ctx, http.MethodPatch, "/v1/status", body, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Data.Attributes.status(), nil
+}
+
+// Metrics returns the current values of the test's metrics, sorted by name.
+func (c *Client) Metrics(ctx context.Context) ([]Metric, error) {
+	var resp envelope[[]resource[struct {
+		Type     string             `json:"type"`
+		Contains string             `json:"contains"`
+		Sample   map[string]float64 `json:"sample"`
+	}]]
+	if err := c.do(ctx, http.MethodGet, "/v1/metrics", nil, &resp); err != nil {
+		return nil, err
+	}
+	metrics := make([]Metric, 0, len(resp.Data))
+	for _, r := range resp.Data {
+		metrics = append(metrics, Metric{
+			Name:     r.ID,
+			Type:     r.Attributes.Type,
+			Contains: r.Attributes.Contains,
+			Sample:   r.Attributes.Sample,
+		})
+	}
+	sort.Slice(metrics, func(i, j int) bool { return metrics[i].Name < metrics[j].Name })
+	return metrics, nil
+}
+
+func (c *Client) do(ctx context.Context, method, path string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.base+path, body)
+	if err != nil {
+		return err
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrUnavailable, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return fmt.Errorf("reading k6 REST API response: %w", err)
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("k6 REST API %s %s: %s: %s", method, path, resp.Status, apiError(data))
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("decoding k6 REST API response: %w", err)
+	}
+	return nil
+}
+
+// apiError extracts the error detail of a JSON:API error response.
+func apiError(data []byte) string {
+	var resp struct {
+		Errors []struct {
+			Title  string `json:"title"`
+			Detail string `json:"detail"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(data, &resp); err == nil && len(resp.Errors) > 0 {
+		if resp.Errors[0].Detail != "" {
+			return resp.Errors[0].Detail
+		}
+		return resp.Errors[0].Title
+	}
+	return string(bytes.TrimSpace(data))
+}
@@ -0,0 +1,91 @@
+package k6api
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	return New(strings.TrimPrefix(srv.URL, "http://"))
+}
+
+func TestStatus(t *testing.T) {
+	t.Parallel()
+
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/status", r.URL.Path)
+		_, _ = io.WriteString(w, `{"data":{"type":"status","id":"default","attributes":`+
+			`{"status":7,"paused":false,"vus":5,"vus-max":10,"stopped":false,"running":true,"tainted":true}}}`)
+	})
+
+	status, err := c.Status(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, &Status{Execution: "running", VUs: 5, VUsMax: 10, Running: true, Tainted: true}, status)
+}
+
+func TestUpdateStatus(t *testing.T) {
+	t.Parallel()
+
+	var body map[string]any
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPatch, r.Method)
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		_, _ = io.WriteString(w, `{"data":{"type":"status","id":"default","attributes":`+
+			`{"status":7,"paused":true,"vus":5,"vus-max":10,"running":true}}}`)
+	})
+
+	paused := true
+	status, err := c.UpdateStatus(context.Background(), StatusUpdate{Paused: &paused})
+	require.NoError(t, err)
+	assert.True(t, status.Paused)
+	assert.Equal(t, map[string]any{
+		"data": map[string]any{"type": "status", "id": "default", "attributes": map[string]any{"paused": true}},
+	}, body)
+}
+
+func TestMetrics(t *testing.T) {
+	t.Parallel()
+
+	c := newTestClient(t, func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = io.WriteString(w, `{"data":[`+
+			`{"type":"metrics","id":"vus","attributes":{"type":"gauge","contains":"default","sample":{"value":5}}},`+
+			`{"type":"metrics","id":"http_req_duration","attributes":{"type":"trend","contains":"time",`+
+			`"sample":{"avg":12.5,"p(95)":30}}}]}`)
+	})
+
+	metrics, err := c.Metrics(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []Metric{
+		{Name: "http_req_duration", Type: "trend", Contains: "time", Sample: map[string]float64{"avg": 12.5, "p(95)": 30}},
+		{Name: "vus", Type: "gauge", Contains: "default", Sample: map[string]float64{"value": 5}},
+	}, metrics)
+}
+
+func TestAPIErrors(t *testing.T) {
+	t.Parallel()
+
+	c := newTestClient(t, func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = io.WriteString(w, `{"errors":[{"title":"Couldn't update status","detail":"can't exceed vus-max"}]}`)
+	})
+	vus := int64(100)
+	_, err := c.UpdateStatus(context.Background(), StatusUpdate{VUs: &vus})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "can't exceed vus-max")
+
+	addr, err := FreeAddress(context.Background())
+	require.NoError(t, err)
+	_, err = New(addr).Status(context.Background())
+	require.ErrorIs(t, err, ErrUnavailable)
+}
@@ -65,6 +65,35 @@ func TestInfoVersion(t *testing.T) {
 	}
 }
 
+func TestInfoExtensions(t *testing.T) {
+	dir := t.TempDir()
+	createStub(t, dir, extensionsStubContent())
+	t.Setenv("PATH", dir)
+	if runtime.GOOS == "windows" {
+		t.Setenv("PATHEXT", ".COM;.EXE;.BAT;.CMD")
+	}
+
+	info, err := k6env.Locate(context.Background())
+	if err != nil {
+		t.Fatalf("Locate returned error: %v", err)
+	}
+	extensions, err := info.Extensions(context.Background())
+	if err != nil {
+		t.Fatalf("Extensions returned error: %v", err)
+	}
+	if len(extensions) != 2 {
+		t.Fatalf("Extensions = %+v, want 2 extensions", extensions)
+	}
+	kafka := extensions[0]
+	if kafka.Module != "github.com/mostafa/xk6-kafka" || kafka.Version != "v0.26.0" ||
+		len(kafka.Imports) != 1 || kafka.Imports[0] != "k6/x/kafka" {
+		t.Fatalf("Extensions[0] = %+v, want xk6-kafka providing k6/x/kafka", kafka)
+	}
+	if output := extensions[1]; len(output.Imports) != 0 {
+		t.Fatalf("Extensions[1] = %+v, want an output extension without imports", output)
+	}
+}
+
 func createStub(t *testing.T, dir, content string) string {
 	t.Helper()
 	var filename string
@@ -103,3 +132,14 @@ func versionStubContent() string {
 
 	return "#!/bin/sh\nif [ \"$1\" = \"version\" ]; then\n  echo \"k6 v0.0.0-test\"\n  exit 0\nfi\necho \"unexpected args\" 1>&2\nexit 1\n"
 }
+
+func extensionsStubContent() string {
+	if runtime.GOOS == "windows" {
+		return "@echo off\necho k6 v1.3.0 (go1.25.1, windows/amd64)\necho Extensions:\n" +
+			"echo   github.com/mostafa/xk6-kafka v0.26.0, k6/x/kafka [js]\n" +
+			"echo   github.com/grafana/xk6-output-timescaledb v0.3.0, timescaledb [output]\n"
+	}
+	return "#!/bin/sh\necho 'k6 v1.3.0 (go1.25.1, linux/amd64)'\necho 'Extensions:'\n" +
+		"echo '  github.com/mostafa/xk6-kafka v0.26.0, k6/x/kafka [js]'\n" +
+		"echo '  github.com/grafana/xk6-output-timescaledb v0.3.0, timescaledb [output]'\n"
+}
@@ -7,6 +7,9 @@ import (
 	"os/exec"
 	"regexp"
 	"strings"
+	"time"
+
+	"github.com/grafana/mcp-k6/internal/audit"
 )
 
 // IsLoggedIn checks whether the k6 executable has an active k6 Cloud login.
@@ -17,7 +20,9 @@ func (i Info) IsLoggedIn(ctx context.Context) (bool, error) {
 
 	// #nosec G204 -- i.Path is obtained from Locate and points to a trusted executable
 	cmd := exec.CommandContext(ctx, i.Path, "cloud", "login", "--show")
+	start := time.Now()
 	output, err := cmd.Output()
+	audit.Command(ctx, cmd, start, err)
 	if err != nil {
 		return false, fmt.Errorf("failed to check k6 cloud login status: %w", err)
 	}
@@ -7,25 +7,20 @@ import (
 	"os/exec"
 	"regexp"
 	"strings"
+	"time"
+
+	"github.com/grafana/mcp-k6/internal/audit"
 )
 
 // Version executes "k6 version" using the resolved executable path.
 // It returns only the semantic version (e.g., "1.3.0"). If no semver is found,
 // the raw trimmed output is returned.
 func (i Info) Version(ctx context.Context) (string, error) {
-	if i.Path == "" {
-		return "", errors.New("k6 executable path is empty")
-	}
-
-	// #nosec G204 -- i.Path is obtained from Locate and points to a trusted executable
-	cmd := exec.CommandContext(ctx, i.Path, "version")
-	output, err := cmd.Output()
+	raw, err := i.versionOutput(ctx)
 	if err != nil {
-		return "", fmt.Errorf("failed to get k6 version: %w", err)
+		return "", err
 	}
 
-	raw := strings.TrimSpace(string(output))
-
 	// Extract semantic version (e.g., 1.3.0) from outputs like:
 	// "k6 v1.3.0 (commit/devel, go1.25.1, darwin/arm64)" or "k6 v0.0.0-test"
 	// We prefer strict semver (MAJOR.MINOR.PATCH). If not found, fall back to the raw string.
@@ -37,3 +32,63 @@ func (i Info) Version(ctx context.Context) (string, error) {
 
 	return raw, nil
 }
+
+// Extension is a k6 extension built into the executable.
+type Extension struct {
+	// Module is the Go module of the extension, e.g. github.com/mostafa/xk6-kafka.
+	Module  string `json:"module"`
+	Version string `json:"version,omitempty"`
+	// Imports are the JavaScript modules the extension provides, e.g. k6/x/kafka.
+	Imports []string `json:"imports,omitempty"`
+}
+
+// Extensions executes "k6 version" and returns the extensions it lists, none
+// for a k6 built without extensions.
+func (i Info) Extensions(ctx context.Context) ([]Extension, error) {
+	raw, err := i.versionOutput(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Binaries built with xk6 list their extensions after the version:
+	//   Extensions:
+	//     github.com/mostafa/xk6-kafka v0.26.0, k6/x/kafka [js]
+	//     github.com/grafana/xk6-output-timescaledb v0.3.0, timescaledb [output]
+	var extensions []Extension
+	_, list, _ := strings.Cut(raw, "Extensions:")
+	for _, line := range strings.Split(list, "\n") {
+		parts := strings.Split(strings.TrimSpace(line), ", ")
+		fields := strings.Fields(parts[0])
+		if len(fields) == 0 {
+			continue
+		}
+		ext := Extension{Module: fields[0]}
+		if len(fields) > 1 {
+			ext.Version = fields[1]
+		}
+		for _, provided := range parts[1:] {
+			if name, ok := strings.CutSuffix(provided, " [js]"); ok {
+				ext.Imports = append(ext.Imports, name)
+			}
+		}
+		extensions = append(extensions, ext)
+	}
+	return extensions, nil
+}
+
+// versionOutput returns the trimmed output of "k6 version".
+func (i Info) versionOutput(ctx context.Context) (string, error) {
+	if i.Path == "" {
+		return "", errors.New("k6 executable path is empty")
+	}
+
+	// #nosec G204 -- i.Path is obtained from Locate and points to a trusted executable
+	cmd := exec.CommandContext(ctx, i.Path, "version")
+	start := time.Now()
+	output, err := cmd.Output()
+	audit.Command(ctx, cmd, start, err)
+	if err != nil {
+		return "", fmt.Errorf("failed to get k6 version: %w", err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
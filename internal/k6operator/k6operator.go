@@ -0,0 +1,326 @@
+// Package k6operator runs k6 tests on Kubernetes with the k6-operator. It
+// renders the TestRun resource of a test and the ConfigMap of its script,
+// applies them with kubectl, and follows the runner pods the operator starts
+// until the test ends.
+package k6operator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/grafana/mcp-k6/internal/audit"
+	"gopkg.in/yaml.v3"
+)
+
+// Stages of a TestRun, as reported in its status.
+const (
+	StageFinished = "finished"
+	StageError    = "error"
+)
+
+const (
+	// DefaultImage is the k6 image of the runners.
+	DefaultImage = "grafana/k6:latest"
+	// maxNameLength leaves room for the suffixes of the jobs and pods the
+	// operator names after the TestRun.
+	maxNameLength = 50
+	// logTail is the number of log lines read from a runner, enough for the
+	// end-of-test summary.
+	logTail = 500
+)
+
+// ErrNoKubectl is returned when kubectl is not on the PATH.
+var ErrNoKubectl = errors.New("kubectl not found in PATH: install it from https://kubernetes.io/docs/tasks/tools/")
+
+// nameRe matches the DNS labels Kubernetes accepts as resource names.
+//
+//nolint:gochecknoglobals // Compiled once, read-only.
+var nameRe = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+
+// Spec describes a distributed test.
+type Spec struct {
+	// Name of the TestRun and of the ConfigMap of its files.
+	Name      string
+	Namespace string
+	// Files are the modules of the test by file name; Entry is the one run.
+	Files map[string]string
+	Entry string
+	// Parallelism is the number of runners the load is split across.
+	Parallelism int
+	// Arguments are the k6 run flags of every runner.
+	Arguments []string
+	Image     string
+	// Env is set in the environment of the runners, where the script reads
+	// it from __ENV.
+	Env map[string]string
+}
+
+// CheckName returns an error when name cannot name a TestRun.
+func CheckName(name string) error {
+	if len(name) > maxNameLength || !nameRe.MatchString(name) {
+		return fmt.Errorf("invalid name %q: use up to %d lowercase letters, digits and dashes, "+
+			"starting and ending with a letter or digit", name, maxNameLength)
+	}
+	return nil
+}
+
+// Manifests renders the ConfigMap and the TestRun of spec as a YAML stream.
+func Manifests(spec Spec) (string, error) {
+	labels := map[string]string{"app.kubernetes.io/managed-by": "mcp-k6"}
+	configMap := map[string]any{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata":   map[string]any{"name": spec.Name, "namespace": spec.Namespace, "labels": labels},
+		"data":       spec.Files,
+	}
+
+	image := spec.Image
+	if image == "" {
+		image = DefaultImage
+	}
+	runner := map[string]any{"image": image}
+	if len(spec.Env) > 0 {
+		keys := make([]string, 0, len(spec.Env))
+		for k := range spec.Env {
+			keys = append(keys, k)
+		}
+		slices.Sort(keys)
+		env := make([]map[string]string, 0, len(keys))
+		for _, k := range keys {
+			env = append(env, map[string]string{"name": k, "value": spec.Env[k]})
+		}
+		runner["env"] = env
+	}
+	testRun := map[string]any{
+		"apiVersion": "k6.io/v1alpha1",
+		"kind":       "TestRun",
+		"metadata":   map[string]any{"name": spec.Name, "namespace": spec.Namespace, "labels": labels},
+		"spec": map[string]any{
+			"parallelism": spec.Parallelism,
+			"script": map[string]any{
+				"configMap": map[string]any{"name": spec.Name, "file": spec.Entry},
+			},
+			"arguments": strings.Join(spec.Arguments, " "),
+			"runner":    runner,
+		},
+	}
+
+	var b bytes.Buffer
+	enc := yaml.NewEncoder(&b)
+	enc.SetIndent(2)
+	for _, doc := range []any{configMap, testRun} {
+		if err := enc.Encode(doc); err != nil {
+			return "", fmt.Errorf("rendering manifests: %w", err)
+		}
+	}
+	if err := enc.Close(); err != nil {
+		return "", fmt.Errorf("rendering manifests: %w", err)
+	}
+	return b.String(), nil
+}
+
+// Pod is a runner pod of a TestRun.
+type Pod struct {
+	Name string `json:"name"`
+	// Phase is the phase of the pod, such as "Running" or "Succeeded".
+	Phase string `json:"phase"`
+	// ExitCode is the exit code of k6 once it ended: 99 when thresholds
+	// failed.
+	ExitCode *int `json:"exit_code,omitempty"`
+	// Reason tells why a container is waiting or was terminated, such as
+	// "ImagePullBackOff".
+	Reason string `json:"reason,omitempty"`
+}
+
+// Done reports whether k6 ended in the pod.
+func (p Pod) Done() bool {
+	return p.Phase == "Succeeded" || p.Phase == "Failed"
+}
+
+// Status is the state of a TestRun and its runners.
+type Status struct {
+	Stage string `json:"stage"`
+	Pods  []Pod  `json:"pods"`
+}
+
+// Kubectl runs kubectl against a cluster.
+type Kubectl struct {
+	path       string
+	kubeconfig string
+	context    string
+	namespace  string
+}
+
+// NewKubectl returns a kubectl using the kubeconfig file and context, or
+// the defaults of kubectl when empty, in namespace.
+func NewKubectl(kubeconfig, kubeContext, namespace string) (*Kubectl, error) {
+	path, err := exec.LookPath("kubectl")
+	if err != nil {
+		return nil, ErrNoKubectl
+	}
+	return &Kubectl{path: path, kubeconfig: kubeconfig, context: kubeContext, namespace: namespace}, nil
+}
+
+// Apply creates or updates the resources of manifests.
+func (k *Kubectl) Apply(ctx context.Context, manifests string) error {
+	_, err := k.run(ctx, []byte(manifests), "apply", "-f", "-")
+	return err
+}
+
+// Status returns the stage of the TestRun name and its runner pods.
+func (k *Kubectl) Status(ctx context.Context, name string) (Status, error) {
+	out, err := k.run(ctx, nil, "get", "testrun", name, "-o", "jsonpath={.status.stage}")
+	if err != nil {
+		return Status{}, err
+	}
+	status := Status{Stage: strings.TrimSpace(string(out))}
+	if out, err = k.run(ctx, nil, "get", "pods", "-l", "k6_cr="+name+",runner=true", "-o", "json"); err != nil {
+		return status, err
+	}
+	status.Pods, err = parsePods(out)
+	return status, err
+}
+
+// Logs returns the last lines of the log of pod.
+func (k *Kubectl) Logs(ctx context.Context, pod string) (string, error) {
+	out, err := k.run(ctx, nil, "logs", pod, fmt.Sprintf("--tail=%d", logTail))
+	return string(out), err
+}
+
+// Delete deletes the TestRun name, which stops its runners, and the
+// ConfigMap of its files.
+func (k *Kubectl) Delete(ctx context.Context, name string) error {
+	_, err := k.run(ctx, nil, "delete", "testrun/"+name, "configmap/"+name, "--ignore-not-found")
+	return err
+}
+
+// Follow polls the status of the TestRun name every interval, passing each
+// change to changed, until the TestRun finishes or fails, or all of its
+// parallelism runners are done.
+func (k *Kubectl) Follow(
+	ctx context.Context, name string, parallelism int, interval time.Duration, changed func(Status),
+) (Status, error) {
+	var last string
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		status, err := k.Status(ctx, name)
+		if err != nil {
+			return status, err
+		}
+		if key := statusKey(status); key != last {
+			last = key
+			changed(status)
+		}
+		done := 0
+		for _, p := range status.Pods {
+			if p.Done() {
+				done++
+			}
+		}
+		if status.Stage == StageFinished || status.Stage == StageError || (parallelism > 0 && done >= parallelism) {
+			return status, nil
+		}
+		select {
+		case <-ctx.Done():
+			return status, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// statusKey identifies the state of status, to report changes only.
+func statusKey(s Status) string {
+	parts := []string{s.Stage}
+	for _, p := range s.Pods {
+		parts = append(parts, p.Name+"="+p.Phase+"/"+p.Reason)
+	}
+	return strings.Join(parts, ",")
+}
+
+// run runs kubectl with args in the cluster, namespace and context of k,
+// returning its output.
+func (k *Kubectl) run(ctx context.Context, stdin []byte, args ...string) ([]byte, error) {
+	var flags []string
+	if k.kubeconfig != "" {
+		flags = append(flags, "--kubeconfig", k.kubeconfig)
+	}
+	if k.context != "" {
+		flags = append(flags, "--context", k.context)
+	}
+	if k.namespace != "" {
+		flags = append(flags, "--namespace", k.namespace)
+	}
+	cmd := exec.CommandContext(ctx, k.path, append(flags, args...)...) // #nosec G204
+	if stdin != nil {
+		cmd.Stdin = bytes.NewReader(stdin)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	start := time.Now()
+	err := cmd.Run()
+	audit.Command(ctx, cmd, start, err)
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("kubectl %s: %s", args[0], msg)
+		}
+		return nil, fmt.Errorf("kubectl %s: %w", args[0], err)
+	}
+	return stdout.Bytes(), nil
+}
+
+// podList is the part of a kubectl pod list read by parsePods.
+type podList struct {
+	Items []struct {
+		Metadata struct {
+			Name string `json:"name"`
+		} `json:"metadata"`
+		Status struct {
+			Phase             string `json:"phase"`
+			ContainerStatuses []struct {
+				State struct {
+					Waiting *struct {
+						Reason string `json:"reason"`
+					} `json:"waiting"`
+					Terminated *struct {
+						ExitCode int    `json:"exitCode"`
+						Reason   string `json:"reason"`
+					} `json:"terminated"`
+				} `json:"state"`
+			} `json:"containerStatuses"`
+		} `json:"status"`
+	} `json:"items"`
+}
+
+// parsePods reads the pods of a kubectl pod list, sorted by name.
+func parsePods(data []byte) ([]Pod, error) {
+	var list podList
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("reading pods: %w", err)
+	}
+	pods := make([]Pod, 0, len(list.Items))
+	for _, item := range list.Items {
+		p := Pod{Name: item.Metadata.Name, Phase: item.Status.Phase}
+		for _, c := range item.Status.ContainerStatuses {
+			switch {
+			case c.State.Terminated != nil:
+				code := c.State.Terminated.ExitCode
+				p.ExitCode = &code
+				p.Reason = c.State.Terminated.Reason
+			case c.State.Waiting != nil:
+				p.Reason = c.State.Waiting.Reason
+			}
+		}
+		pods = append(pods, p)
+	}
+	slices.SortFunc(pods, func(a, b Pod) int { return strings.Compare(a.Name, b.Name) })
+	return pods, nil
+}
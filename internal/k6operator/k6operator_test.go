@@ -0,0 +1,156 @@
+package k6operator
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestManifests(t *testing.T) {
+	t.Parallel()
+
+	manifests, err := Manifests(Spec{
+		Name:        "checkout",
+		Namespace:   "load",
+		Files:       map[string]string{"entry.js": "import './script.js';", "script.js": "export default function () {}"},
+		Entry:       "entry.js",
+		Parallelism: 4,
+		Arguments:   []string{"--vus", "400", "--duration", "10m"},
+		Env:         map[string]string{"BASE_URL": "https://shop.example.com", "API_KEY": "abc"},
+	})
+	require.NoError(t, err)
+
+	dec := yaml.NewDecoder(strings.NewReader(manifests))
+	var configMap, testRun map[string]any
+	require.NoError(t, dec.Decode(&configMap))
+	require.NoError(t, dec.Decode(&testRun))
+
+	assert.Equal(t, "ConfigMap", configMap["kind"])
+	assert.Equal(t, "export default function () {}", configMap["data"].(map[string]any)["script.js"])
+	assert.Equal(t, "k6.io/v1alpha1", testRun["apiVersion"])
+	assert.Equal(t, map[string]any{"name": "checkout", "namespace": "load",
+		"labels": map[string]any{"app.kubernetes.io/managed-by": "mcp-k6"}}, testRun["metadata"])
+	spec := testRun["spec"].(map[string]any)
+	assert.Equal(t, 4, spec["parallelism"])
+	assert.Equal(t, "--vus 400 --duration 10m", spec["arguments"])
+	assert.Equal(t, map[string]any{"configMap": map[string]any{"name": "checkout", "file": "entry.js"}}, spec["script"])
+	runner := spec["runner"].(map[string]any)
+	assert.Equal(t, DefaultImage, runner["image"])
+	assert.Equal(t, []any{
+		map[string]any{"name": "API_KEY", "value": "abc"},
+		map[string]any{"name": "BASE_URL", "value": "https://shop.example.com"},
+	}, runner["env"])
+}
+
+func TestCheckName(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, CheckName("mcp-k6-a1b2c3"))
+	for _, name := range []string{"", "Checkout", "-checkout", "checkout-", "check_out", strings.Repeat("a", 51)} {
+		assert.Error(t, CheckName(name), name)
+	}
+}
+
+func TestParsePods(t *testing.T) {
+	t.Parallel()
+
+	pods, err := parsePods([]byte(`{"items": [
+		{"metadata": {"name": "checkout-2-x"}, "status": {"phase": "Pending",
+			"containerStatuses": [{"state": {"waiting": {"reason": "ImagePullBackOff"}}}]}},
+		{"metadata": {"name": "checkout-1-x"}, "status": {"phase": "Failed",
+			"containerStatuses": [{"state": {"terminated": {"exitCode": 99, "reason": "Error"}}}]}}
+	]}`))
+	require.NoError(t, err)
+	require.Len(t, pods, 2)
+	assert.Equal(t, "checkout-1-x", pods[0].Name)
+	require.NotNil(t, pods[0].ExitCode)
+	assert.Equal(t, 99, *pods[0].ExitCode)
+	assert.True(t, pods[0].Done())
+	assert.Equal(t, Pod{Name: "checkout-2-x", Phase: "Pending", Reason: "ImagePullBackOff"}, pods[1])
+	assert.False(t, pods[1].Done())
+}
+
+// stubKubectl writes a kubectl reporting the TestRun as started on its first
+// status check and finished on the next, recording its arguments in calls.
+func stubKubectl(t *testing.T) (*Kubectl, string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("the kubectl stub is a shell script")
+	}
+	dir := t.TempDir()
+	calls := filepath.Join(dir, "calls")
+	script := `#!/bin/sh
+echo "$*" >> ` + calls + `
+case "$*" in
+*"get testrun"*)
+  if [ -f ` + dir + `/started ]; then echo finished; else touch ` + dir + `/started; echo started; fi ;;
+*"get pods"*)
+  if [ -f ` + dir + `/done ]; then phase=Succeeded; state='"terminated": {"exitCode": 0, "reason": "Completed"}'
+  else touch ` + dir + `/done; phase=Running; state='"running": {}'; fi
+  echo "{\"items\": [{\"metadata\": {\"name\": \"checkout-1-abc\"}, \"status\": {\"phase\": \"$phase\",
+    \"containerStatuses\": [{\"state\": {$state}}]}}]}" ;;
+*logs*) echo "running"; echo "mcp-k6-summary: {}" ;;
+*apply*) cat > ` + dir + `/applied ;;
+*delete*) echo "deleted" ;;
+*) echo "unexpected $*" >&2; exit 1 ;;
+esac
+`
+	path := filepath.Join(dir, "kubectl")
+	//nolint:forbidigo // Writing the kubectl stub
+	// #nosec G306 -- Stub executable must be runnable during tests
+	require.NoError(t, os.WriteFile(path, []byte(script), 0o700))
+	return &Kubectl{path: path, kubeconfig: "/tmp/kubeconfig", context: "test", namespace: "load"}, dir
+}
+
+func TestKubectl(t *testing.T) {
+	t.Parallel()
+
+	k, dir := stubKubectl(t)
+	ctx := context.Background()
+	require.NoError(t, k.Apply(ctx, "kind: TestRun\n"))
+
+	var changes []Status
+	status, err := k.Follow(ctx, "checkout", 1, time.Millisecond, func(s Status) { changes = append(changes, s) })
+	require.NoError(t, err)
+	assert.Equal(t, StageFinished, status.Stage)
+	require.Len(t, changes, 2)
+	assert.Equal(t, "started", changes[0].Stage)
+	assert.Equal(t, "Running", changes[0].Pods[0].Phase)
+	assert.Equal(t, "Succeeded", changes[1].Pods[0].Phase)
+
+	logs, err := k.Logs(ctx, "checkout-1-abc")
+	require.NoError(t, err)
+	assert.Contains(t, logs, "mcp-k6-summary: {}")
+	require.NoError(t, k.Delete(ctx, "checkout"))
+
+	//nolint:forbidigo // Reading the files written by the stub
+	applied, err := os.ReadFile(filepath.Join(dir, "applied"))
+	require.NoError(t, err)
+	assert.Equal(t, "kind: TestRun\n", string(applied))
+	//nolint:forbidigo // Reading the files written by the stub
+	calls, err := os.ReadFile(filepath.Join(dir, "calls"))
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(calls)), "\n")
+	assert.Equal(t, "--kubeconfig /tmp/kubeconfig --context test --namespace load apply -f -", lines[0])
+	assert.Contains(t, lines, "--kubeconfig /tmp/kubeconfig --context test --namespace load "+
+		"get pods -l k6_cr=checkout,runner=true -o json")
+	assert.Equal(t, "--kubeconfig /tmp/kubeconfig --context test --namespace load "+
+		"delete testrun/checkout configmap/checkout --ignore-not-found", lines[len(lines)-1])
+}
+
+func TestKubectlError(t *testing.T) {
+	t.Parallel()
+
+	k, _ := stubKubectl(t)
+	_, err := k.run(context.Background(), nil, "version")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "kubectl version: unexpected")
+}
@@ -0,0 +1,334 @@
+// Package k6opts reproduces how k6 consolidates options from its defaults,
+// the script, K6_* environment variables and command line flags, so the
+// effective configuration of a run can be explained before it starts.
+package k6opts
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/grafana/mcp-k6/internal/scriptinfo"
+)
+
+// Source identifies the configuration layer an option value comes from.
+type Source string
+
+// Configuration layers, from lowest to highest precedence.
+const (
+	SourceDefault Source = "default"
+	SourceScript  Source = "script"
+	SourceEnv     Source = "env"
+	SourceCLI     Source = "cli"
+)
+
+// Layer holds the option values set by one source, keyed by k6 option name.
+// Values are rendered the way they would be written on the command line,
+// e.g. stages as "30s:10,1m:0".
+type Layer map[string]string
+
+// Override records a lower-precedence value that lost to the effective one.
+type Override struct {
+	Source Source `json:"source"`
+	Value  string `json:"value"`
+}
+
+// Option is the effective value of a single option.
+type Option struct {
+	Name       string     `json:"name"`
+	Value      string     `json:"value"`
+	Source     Source     `json:"source"`
+	Env        string     `json:"env,omitempty"`
+	Flag       string     `json:"flag,omitempty"`
+	Overridden []Override `json:"overridden,omitempty"`
+}
+
+// Execution describes the scenarios k6 will run.
+type Execution struct {
+	// Source is the layer the execution settings come from.
+	Source Source `json:"source"`
+	// Executor is set when k6 derives a single "default" scenario from the
+	// vus, duration, iterations and stages shortcuts.
+	Executor    string `json:"executor,omitempty"`
+	Description string `json:"description"`
+}
+
+// Result is the consolidated configuration.
+type Result struct {
+	Options   []Option  `json:"options"`
+	Execution Execution `json:"execution"`
+	Warnings  []string  `json:"warnings,omitempty"`
+}
+
+// Lookup returns the effective option with the given name.
+func (r *Result) Lookup(name string) (Option, bool) {
+	for _, o := range r.Options {
+		if o.Name == name {
+			return o, true
+		}
+	}
+	return Option{}, false
+}
+
+// executionKeys are the options k6 treats as one unit: setting any of them
+// in a layer discards all of them from the layers below.
+//
+//nolint:gochecknoglobals // Read-only lookup table.
+var executionKeys = []string{"duration", "iterations", "stages", "scenarios"}
+
+// FromScript returns the options exported by the script.
+func FromScript(info *scriptinfo.Info) Layer {
+	layer := Layer{}
+	for _, p := range info.RawOptions() {
+		switch p.Key {
+		case "stages":
+			stages := make([]string, 0, len(info.Options.Stages))
+			for _, st := range info.Options.Stages {
+				stages = append(stages, st.Duration+":"+st.Target)
+			}
+			layer[p.Key] = strings.Join(stages, ",")
+		case "scenarios":
+			names := make([]string, 0, len(info.Scenarios))
+			for _, sc := range info.Scenarios {
+				names = append(names, fmt.Sprintf("%s (%s)", sc.Name, sc.Executor))
+			}
+			layer[p.Key] = strings.Join(names, ", ")
+		case "thresholds":
+			metrics := make([]string, 0, len(info.Thresholds))
+			for _, th := range info.Thresholds {
+				metrics = append(metrics, th.Metric)
+			}
+			layer[p.Key] = strings.Join(metrics, ", ")
+		default:
+			layer[p.Key] = scriptinfo.StringValue(p.Value)
+		}
+	}
+	return layer
+}
+
+// FromEnv returns the options set by K6_* variables of env. Variables that do
+// not configure an option are ignored.
+func FromEnv(env map[string]string) Layer {
+	layer := Layer{}
+	for _, d := range definitions {
+		if d.env == "" {
+			continue
+		}
+		if v, ok := env[d.env]; ok {
+			layer[d.name] = v
+		}
+	}
+	return layer
+}
+
+// FromFlags returns the options set by k6 run command line arguments.
+// Arguments that are not options, such as --out or the script path, are
+// skipped; unknown flags are reported as warnings.
+func FromFlags(args []string) (Layer, []string) {
+	layer := Layer{}
+	var warnings []string
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if !strings.HasPrefix(arg, "-") || arg == "-" {
+			continue
+		}
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		long := strings.HasPrefix(arg, "--")
+
+		d, ok := lookupFlag(name, long)
+		if !ok {
+			known, takesValue := otherFlag(name)
+			if !known {
+				warnings = append(warnings, fmt.Sprintf("unknown flag %s is not a k6 run flag", arg))
+			} else if takesValue && !hasValue {
+				i++
+			}
+			continue
+		}
+
+		if !hasValue {
+			switch {
+			case d.boolean && (i+1 >= len(args) || !isBool(args[i+1])):
+				value = "true"
+			case i+1 < len(args):
+				i++
+				value = args[i]
+			default:
+				warnings = append(warnings, fmt.Sprintf("flag %s is missing a value", arg))
+				continue
+			}
+		}
+
+		if d.repeatable && layer[d.name] != "" {
+			layer[d.name] += "," + value
+		} else {
+			layer[d.name] = value
+		}
+	}
+	return layer, warnings
+}
+
+// Resolve consolidates the layers with k6's precedence: defaults < script <
+// env < cli. Nil layers are skipped.
+func Resolve(script, env, cli Layer) *Result {
+	type entry struct {
+		value      string
+		source     Source
+		overridden []Override
+	}
+	effective := make(map[string]*entry)
+	for _, d := range definitions {
+		if d.def != "" {
+			effective[d.name] = &entry{value: d.def, source: SourceDefault}
+		}
+	}
+
+	var warnings []string
+	executionSource := SourceDefault
+
+	layers := []struct {
+		source Source
+		values Layer
+	}{{SourceScript, script}, {SourceEnv, env}, {SourceCLI, cli}}
+
+	for _, layer := range layers {
+		if len(layer.values) == 0 {
+			continue
+		}
+
+		if setsExecution(layer.values) {
+			var dropped []string
+			for _, key := range executionKeys {
+				if e, ok := effective[key]; ok && e.source != SourceDefault && layer.values[key] == "" {
+					dropped = append(dropped, fmt.Sprintf("%s=%s (%s)", key, e.value, e.source))
+					delete(effective, key)
+				}
+			}
+			if len(dropped) > 0 {
+				warnings = append(warnings, fmt.Sprintf(
+					"%s sets %s, which replaces all execution settings of lower layers; dropped %s",
+					layer.source, strings.Join(executionKeysIn(layer.values), " and "), strings.Join(dropped, ", ")))
+			}
+			executionSource = layer.source
+		}
+
+		for _, name := range sortedNames(layer.values) {
+			value := layer.values[name]
+			if e, ok := effective[name]; ok {
+				e.overridden = append(e.overridden, Override{Source: e.source, Value: e.value})
+				e.value, e.source = value, layer.source
+				continue
+			}
+			effective[name] = &entry{value: value, source: layer.source}
+		}
+	}
+
+	result := &Result{}
+	for _, name := range sortedNames(effective) {
+		e := effective[name]
+		// Defaults nobody touched are noise, except vus which every executor uses.
+		if e.source == SourceDefault && name != "vus" {
+			continue
+		}
+		opt := Option{Name: name, Value: e.value, Source: e.source, Overridden: e.overridden}
+		if d, ok := lookupName(name); ok {
+			opt.Env = d.env
+			if d.flag != "" {
+				opt.Flag = "--" + d.flag
+			}
+		}
+		result.Options = append(result.Options, opt)
+	}
+
+	value := func(name string) string {
+		if e, ok := effective[name]; ok {
+			return e.value
+		}
+		return ""
+	}
+	result.Execution, warnings = deriveExecution(value, effective["vus"].source, executionSource, warnings)
+	result.Warnings = warnings
+	return result
+}
+
+// deriveExecution mirrors k6's derivation of the default scenario from the
+// shortcut options and the validation of conflicting settings.
+func deriveExecution(
+	value func(string) string,
+	vusSource, source Source,
+	warnings []string,
+) (Execution, []string) {
+	vus := value("vus")
+	duration, iterations, stages, scenarios := value("duration"), value("iterations"), value("stages"), value("scenarios")
+
+	switch {
+	case scenarios != "":
+		shortcuts := executionKeysIn(Layer{"duration": duration, "iterations": iterations, "stages": stages})
+		if len(shortcuts) > 0 {
+			warnings = append(warnings, fmt.Sprintf(
+				"k6 will refuse to start: %s and scenarios are set in the same layer", strings.Join(shortcuts, " and ")))
+		}
+		if vusSource != SourceDefault {
+			warnings = append(warnings, fmt.Sprintf(
+				"vus=%s (%s) is ignored: it only applies to the duration, iterations and stages shortcuts, "+
+					"not to scenarios", vus, vusSource))
+		}
+		return Execution{Source: source, Description: "Scenarios: " + scenarios}, warnings
+	case stages != "" && (duration != "" || iterations != ""):
+		warnings = append(warnings, "k6 will refuse to start: stages cannot be combined with duration or iterations")
+		return Execution{Source: source, Executor: "ramping-vus", Description: "Invalid: conflicting shortcuts"}, warnings
+	case stages != "":
+		n := strings.Count(stages, ",") + 1
+		return Execution{
+			Source:      source,
+			Executor:    "ramping-vus",
+			Description: fmt.Sprintf("Ramps through %d stage(s) (%s), starting from %s VU(s)", n, stages, vus),
+		}, warnings
+	case iterations != "":
+		desc := fmt.Sprintf("%s VU(s) share %s iteration(s)", vus, iterations)
+		if duration != "" {
+			desc += ", stopping after at most " + duration
+		}
+		return Execution{Source: source, Executor: "shared-iterations", Description: desc}, warnings
+	case duration != "":
+		return Execution{
+			Source:      source,
+			Executor:    "constant-vus",
+			Description: fmt.Sprintf("%s VU(s) loop for %s", vus, duration),
+		}, warnings
+	default:
+		return Execution{
+			Source:      SourceDefault,
+			Executor:    "per-vu-iterations",
+			Description: fmt.Sprintf("No execution options: each of %s VU(s) runs a single iteration", vus),
+		}, warnings
+	}
+}
+
+func setsExecution(layer Layer) bool {
+	return len(executionKeysIn(layer)) > 0
+}
+
+func executionKeysIn(layer Layer) []string {
+	var keys []string
+	for _, key := range executionKeys {
+		if layer[key] != "" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+func isBool(s string) bool {
+	return s == "true" || s == "false"
+}
+
+func sortedNames[V any](m map[string]V) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
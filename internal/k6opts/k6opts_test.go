@@ -0,0 +1,99 @@
+package k6opts
+
+import (
+	"testing"
+
+	"github.com/grafana/mcp-k6/internal/scriptinfo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromFlags(t *testing.T) {
+	t.Parallel()
+
+	layer, warnings := FromFlags([]string{
+		"run", "--vus", "10", "-d=30s", "--stage", "10s:5", "--stage=20s:0",
+		"--out", "json=out.json", "-e", "FOO=bar", "--insecure-skip-tls-verify",
+		"--tag", "env=prod", "--quiet", "--bogus", "script.js",
+	})
+
+	assert.Equal(t, Layer{
+		"vus":                   "10",
+		"duration":              "30s",
+		"stages":                "10s:5,20s:0",
+		"insecureSkipTLSVerify": "true",
+		"tags":                  "env=prod",
+	}, layer)
+	assert.Equal(t, []string{"unknown flag --bogus is not a k6 run flag"}, warnings)
+}
+
+func TestResolvePrecedence(t *testing.T) {
+	t.Parallel()
+
+	script := Layer{"vus": "5", "duration": "1m", "userAgent": "script-agent"}
+	env := FromEnv(map[string]string{"K6_VUS": "8", "K6_USER_AGENT": "env-agent", "PATH": "/bin"})
+	cli := Layer{"vus": "10"}
+
+	res := Resolve(script, env, cli)
+
+	vus, ok := res.Lookup("vus")
+	require.True(t, ok)
+	assert.Equal(t, Option{
+		Name: "vus", Value: "10", Source: SourceCLI, Env: "K6_VUS", Flag: "--vus",
+		Overridden: []Override{
+			{Source: SourceDefault, Value: "1"},
+			{Source: SourceScript, Value: "5"},
+			{Source: SourceEnv, Value: "8"},
+		},
+	}, vus)
+
+	ua, _ := res.Lookup("userAgent")
+	assert.Equal(t, "env-agent", ua.Value)
+	_, ok = res.Lookup("batch")
+	assert.False(t, ok, "untouched defaults are omitted")
+
+	assert.Equal(t, Execution{
+		Source: SourceScript, Executor: "constant-vus", Description: "10 VU(s) loop for 1m",
+	}, res.Execution)
+	assert.Empty(t, res.Warnings)
+}
+
+func TestResolveShortcutReplacesScenarios(t *testing.T) {
+	t.Parallel()
+
+	info := scriptinfo.Analyze(`export const options = {
+  scenarios: { api: { executor: 'constant-arrival-rate', rate: 10 } },
+  thresholds: { http_req_duration: ['p(95)<500'] },
+};
+export default function () {}`)
+	script := FromScript(info)
+	assert.Equal(t, "api (constant-arrival-rate)", script["scenarios"])
+	assert.Equal(t, "http_req_duration", script["thresholds"])
+
+	res := Resolve(script, nil, Layer{"vus": "2", "iterations": "4"})
+	_, ok := res.Lookup("scenarios")
+	assert.False(t, ok)
+	_, ok = res.Lookup("thresholds")
+	assert.True(t, ok, "thresholds are not execution settings")
+	assert.Equal(t, "shared-iterations", res.Execution.Executor)
+	assert.Equal(t, SourceCLI, res.Execution.Source)
+	require.Len(t, res.Warnings, 1)
+	assert.Contains(t, res.Warnings[0], "dropped scenarios=api (constant-arrival-rate) (script)")
+}
+
+func TestResolveWarnings(t *testing.T) {
+	t.Parallel()
+
+	res := Resolve(Layer{"scenarios": "api (constant-vus)"}, nil, Layer{"vus": "3"})
+	assert.Equal(t, "Scenarios: api (constant-vus)", res.Execution.Description)
+	require.Len(t, res.Warnings, 1)
+	assert.Contains(t, res.Warnings[0], "vus=3 (cli) is ignored")
+
+	res = Resolve(Layer{"stages": "10s:1", "duration": "1m"}, nil, nil)
+	require.Len(t, res.Warnings, 1)
+	assert.Contains(t, res.Warnings[0], "k6 will refuse to start")
+
+	res = Resolve(nil, nil, nil)
+	assert.Equal(t, "per-vu-iterations", res.Execution.Executor)
+	assert.Equal(t, []Option{{Name: "vus", Value: "1", Source: SourceDefault, Env: "K6_VUS", Flag: "--vus"}}, res.Options)
+}
@@ -0,0 +1,97 @@
+package k6opts
+
+// definition describes how an option is spelled in each layer.
+type definition struct {
+	name  string // option name in the script's options object
+	env   string // K6_* environment variable, if any
+	flag  string // long command line flag, if any
+	short string // single-letter flag, if any
+	def   string // default value shown when nothing overrides it
+	// boolean flags may be given without a value.
+	boolean bool
+	// repeatable flags accumulate into a comma-separated list.
+	repeatable bool
+}
+
+// definitions lists the options k6 run accepts from more than one layer.
+// Script-only options such as scenarios and thresholds are handled as they
+// come.
+//
+//nolint:gochecknoglobals // Read-only lookup table.
+var definitions = []definition{
+	{name: "vus", env: "K6_VUS", flag: "vus", short: "u", def: "1"},
+	{name: "duration", env: "K6_DURATION", flag: "duration", short: "d"},
+	{name: "iterations", env: "K6_ITERATIONS", flag: "iterations", short: "i"},
+	{name: "stages", env: "K6_STAGES", flag: "stage", short: "s", repeatable: true},
+	{name: "paused", env: "K6_PAUSED", flag: "paused", short: "p", boolean: true},
+	{name: "noSetup", env: "K6_NO_SETUP", flag: "no-setup", boolean: true},
+	{name: "noTeardown", env: "K6_NO_TEARDOWN", flag: "no-teardown", boolean: true},
+	{name: "noThresholds", env: "K6_NO_THRESHOLDS", flag: "no-thresholds", boolean: true},
+	{name: "noSummary", env: "K6_NO_SUMMARY", flag: "no-summary", boolean: true},
+	{name: "setupTimeout", env: "K6_SETUP_TIMEOUT", def: "60s"},
+	{name: "teardownTimeout", env: "K6_TEARDOWN_TIMEOUT", def: "60s"},
+	{name: "minIterationDuration", env: "K6_MIN_ITERATION_DURATION", flag: "min-iteration-duration"},
+	{name: "rps", env: "K6_RPS", flag: "rps"},
+	{name: "batch", env: "K6_BATCH", flag: "batch", def: "20"},
+	{name: "batchPerHost", env: "K6_BATCH_PER_HOST", flag: "batch-per-host", def: "6"},
+	{name: "maxRedirects", env: "K6_MAX_REDIRECTS", flag: "max-redirects", def: "10"},
+	{name: "userAgent", env: "K6_USER_AGENT", flag: "user-agent"},
+	{name: "httpDebug", env: "K6_HTTP_DEBUG", flag: "http-debug"},
+	{
+		name: "insecureSkipTLSVerify", env: "K6_INSECURE_SKIP_TLS_VERIFY", flag: "insecure-skip-tls-verify",
+		boolean: true,
+	},
+	{name: "noConnectionReuse", env: "K6_NO_CONNECTION_REUSE", flag: "no-connection-reuse", boolean: true},
+	{name: "noVUConnectionReuse", env: "K6_NO_VU_CONNECTION_REUSE", flag: "no-vu-connection-reuse", boolean: true},
+	{
+		name: "discardResponseBodies", env: "K6_DISCARD_RESPONSE_BODIES", flag: "discard-response-bodies",
+		boolean: true,
+	},
+	{name: "throw", env: "K6_THROW", flag: "throw", short: "w", boolean: true},
+	{name: "blacklistIPs", env: "K6_BLACKLIST_IPS", flag: "blacklist-ip", repeatable: true},
+	{name: "blockHostnames", env: "K6_BLOCK_HOSTNAMES", flag: "block-hostnames"},
+	{name: "dns", env: "K6_DNS", flag: "dns"},
+	{name: "localIPs", env: "K6_LOCAL_IPS", flag: "local-ips"},
+	{name: "summaryTrendStats", env: "K6_SUMMARY_TREND_STATS", flag: "summary-trend-stats"},
+	{name: "summaryTimeUnit", env: "K6_SUMMARY_TIME_UNIT", flag: "summary-time-unit"},
+	{name: "systemTags", env: "K6_SYSTEM_TAGS", flag: "system-tags"},
+	{name: "tags", flag: "tag", repeatable: true},
+}
+
+// otherFlags are the k6 run flags that do not set an option, mapped to
+// whether they take a value, so FromFlags knows to skip their argument.
+//
+//nolint:gochecknoglobals // Read-only lookup table.
+var otherFlags = map[string]bool{
+	"out": true, "o": true, "env": true, "e": true, "config": true, "c": true,
+	"address": true, "a": true, "summary-export": true, "log-output": true,
+	"log-format": true, "console-output": true, "compatibility-mode": true,
+	"secret-source": true, "linger": false, "quiet": false, "q": false,
+	"verbose": false, "v": false, "no-color": false, "include-system-env-vars": false,
+	"no-usage-report": false, "summary-mode": true, "local-execution": false,
+}
+
+func lookupFlag(name string, long bool) (definition, bool) {
+	for _, d := range definitions {
+		if (long && d.flag == name) || (!long && d.short != "" && d.short == name) {
+			return d, true
+		}
+	}
+	return definition{}, false
+}
+
+func lookupName(name string) (definition, bool) {
+	for _, d := range definitions {
+		if d.name == name {
+			return d, true
+		}
+	}
+	return definition{}, false
+}
+
+// otherFlag reports whether name is a k6 run flag that does not set an
+// option and whether it consumes the next argument.
+func otherFlag(name string) (known, takesValue bool) {
+	takesValue, known = otherFlags[name]
+	return known, takesValue
+}
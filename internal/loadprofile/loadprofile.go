@@ -0,0 +1,293 @@
+// Package loadprofile renders the planned load of a k6 run, VUs or arrival
+// rate over time, as a plain-text chart.
+package loadprofile
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/mcp-k6/internal/k6opts"
+	"github.com/grafana/mcp-k6/internal/scriptinfo"
+)
+
+// Chart dimensions, in characters.
+const (
+	Width  = 60
+	Height = 8
+)
+
+// Point is the planned load at an offset from the scenario start. Load
+// changes linearly between points.
+type Point struct {
+	At    time.Duration
+	Value float64
+}
+
+// Profile is the planned load of one scenario.
+type Profile struct {
+	Name     string
+	Executor string
+	// Unit is "VUs" or, for arrival-rate executors, "iterations/<timeUnit>".
+	Unit string
+	// Start is the scenario's startTime.
+	Start  time.Duration
+	Points []Point
+	// Note replaces the chart when the load has no fixed time axis or
+	// cannot be read statically.
+	Note string
+}
+
+// end returns the offset from the test start at which the profile ends.
+func (p Profile) end() time.Duration {
+	if len(p.Points) == 0 {
+		return p.Start
+	}
+	return p.Start + p.Points[len(p.Points)-1].At
+}
+
+// at returns the load at offset t from the test start.
+func (p Profile) at(t time.Duration) float64 {
+	t -= p.Start
+	if len(p.Points) == 0 || t < 0 || t > p.Points[len(p.Points)-1].At {
+		return 0
+	}
+	for i := 1; i < len(p.Points); i++ {
+		a, b := p.Points[i-1], p.Points[i]
+		if t > b.At {
+			continue
+		}
+		if b.At == a.At {
+			return b.Value
+		}
+		return a.Value + (b.Value-a.Value)*float64(t-a.At)/float64(b.At-a.At)
+	}
+	return p.Points[0].Value
+}
+
+// FromResult returns the profiles of the execution k6 derives for res,
+// reading scenario definitions from info when the script's scenarios are
+// in effect.
+func FromResult(res *k6opts.Result, info *scriptinfo.Info) []Profile {
+	if res.Execution.Executor == "" {
+		if info == nil || len(info.Scenarios) == 0 {
+			return []Profile{{Name: "scenarios", Note: res.Execution.Description}}
+		}
+		profiles := make([]Profile, 0, len(info.Scenarios))
+		for _, sc := range info.Scenarios {
+			profiles = append(profiles, fromScenario(sc))
+		}
+		return profiles
+	}
+
+	value := func(name string) string {
+		if o, ok := res.Lookup(name); ok {
+			return o.Value
+		}
+		return ""
+	}
+	p := Profile{Name: "default", Executor: res.Execution.Executor, Unit: "VUs"}
+	vus, err := number(value("vus"))
+	if err != nil {
+		p.Note = res.Execution.Description
+		return []Profile{p}
+	}
+
+	switch res.Execution.Executor {
+	case "constant-vus":
+		p.Points, err = constant(vus, value("duration"))
+	case "ramping-vus":
+		p.Points, err = ramp(vus, shortcutStages(value("stages")))
+	}
+	if err != nil || p.Points == nil {
+		p.Note = res.Execution.Description
+	}
+	return []Profile{p}
+}
+
+// fromScenario returns the profile of a scenario of the script.
+func fromScenario(sc scriptinfo.Scenario) Profile {
+	p := Profile{Name: sc.Name, Executor: sc.Executor, Unit: "VUs"}
+	setting := func(key, def string) string {
+		if v, ok := sc.Settings[key]; ok {
+			return v
+		}
+		return def
+	}
+
+	var err error
+	if sc.StartTime != "" {
+		if p.Start, err = time.ParseDuration(sc.StartTime); err != nil {
+			p.Note = fmt.Sprintf("startTime %q cannot be read statically", sc.StartTime)
+			return p
+		}
+	}
+
+	var start float64
+	switch sc.Executor {
+	case "constant-vus", "externally-controlled":
+		if start, err = number(setting("vus", "1")); err == nil {
+			p.Points, err = constant(start, setting("duration", ""))
+		}
+	case "ramping-vus":
+		if start, err = number(setting("startVUs", "1")); err == nil {
+			p.Points, err = ramp(start, sc.Stages)
+		}
+	case "constant-arrival-rate":
+		p.Unit = "iterations/" + setting("timeUnit", "1s")
+		if start, err = number(setting("rate", "")); err == nil {
+			p.Points, err = constant(start, setting("duration", ""))
+		}
+	case "ramping-arrival-rate":
+		p.Unit = "iterations/" + setting("timeUnit", "1s")
+		if start, err = number(setting("startRate", "0")); err == nil {
+			p.Points, err = ramp(start, sc.Stages)
+		}
+	case "shared-iterations", "per-vu-iterations":
+		p.Note = fmt.Sprintf("%s VU(s) run %s iteration(s); the duration depends on iteration time",
+			setting("vus", "1"), setting("iterations", "1"))
+		return p
+	default:
+		p.Note = fmt.Sprintf("executor %q cannot be charted", sc.Executor)
+		return p
+	}
+	if err != nil {
+		p.Points = nil
+		p.Note = "the load shape cannot be read statically: " + err.Error()
+	}
+	return p
+}
+
+func constant(value float64, duration string) ([]Point, error) {
+	d, err := time.ParseDuration(duration)
+	if err != nil {
+		return nil, fmt.Errorf("duration %q is not a literal duration", duration)
+	}
+	return []Point{{0, value}, {d, value}}, nil
+}
+
+func ramp(start float64, stages []scriptinfo.Stage) ([]Point, error) {
+	if len(stages) == 0 {
+		return nil, errors.New("no stages")
+	}
+	points := []Point{{0, start}}
+	var at time.Duration
+	for i, st := range stages {
+		d, err := time.ParseDuration(st.Duration)
+		if err != nil {
+			return nil, fmt.Errorf("stage %d duration %q is not a literal duration", i+1, st.Duration)
+		}
+		target, err := number(st.Target)
+		if err != nil {
+			return nil, fmt.Errorf("stage %d target %q is not a number", i+1, st.Target)
+		}
+		at += d
+		points = append(points, Point{at, target})
+	}
+	return points, nil
+}
+
+// shortcutStages parses the "30s:10,1m:20" form of the stages option.
+func shortcutStages(value string) []scriptinfo.Stage {
+	var stages []scriptinfo.Stage
+	for _, part := range strings.Split(value, ",") {
+		d, t, _ := strings.Cut(strings.TrimSpace(part), ":")
+		stages = append(stages, scriptinfo.Stage{Duration: d, Target: t})
+	}
+	return stages
+}
+
+func number(s string) (float64, error) {
+	v, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil || v < 0 {
+		return 0, fmt.Errorf("%q is not a literal number", s)
+	}
+	return v, nil
+}
+
+// Render draws the profiles as text charts sharing one time axis, so
+// scenarios that start later line up with the ones before them.
+func Render(profiles []Profile) []string {
+	var total time.Duration
+	for _, p := range profiles {
+		total = max(total, p.end())
+	}
+
+	var lines []string
+	for i, p := range profiles {
+		if i > 0 {
+			lines = append(lines, "")
+		}
+		header := p.Name
+		if p.Executor != "" {
+			header += " (" + p.Executor + ")"
+		}
+		if p.Note != "" || len(p.Points) == 0 || total == 0 {
+			note := p.Note
+			if note == "" {
+				note = "no load over time to chart"
+			}
+			lines = append(lines, header+": "+note)
+			continue
+		}
+		if p.Start > 0 {
+			header += ", starts at " + FormatDuration(p.Start)
+		}
+		lines = append(lines, header+", "+p.Unit+":")
+		lines = append(lines, chart(p, total)...)
+	}
+	return lines
+}
+
+// chart draws one profile as an area chart with a y axis labelled with the
+// peak load and an x axis spanning total.
+func chart(p Profile, total time.Duration) []string {
+	peak := 0.0
+	for _, pt := range p.Points {
+		peak = math.Max(peak, pt.Value)
+	}
+	values := make([]float64, Width)
+	for c := range values {
+		values[c] = p.at(time.Duration(float64(total) * float64(c) / float64(Width-1)))
+	}
+
+	peakLabel := strconv.FormatFloat(peak, 'f', -1, 64)
+	pad := len(peakLabel)
+	lines := make([]string, 0, Height+2)
+	for row := Height; row >= 1; row-- {
+		label := strings.Repeat(" ", pad)
+		if row == Height {
+			label = peakLabel
+		}
+		var b strings.Builder
+		for _, v := range values {
+			if peak > 0 && v*Height/peak >= float64(row)-0.5 {
+				b.WriteByte('#')
+			} else {
+				b.WriteByte(' ')
+			}
+		}
+		lines = append(lines, label+" |"+strings.TrimRight(b.String(), " "))
+	}
+	lines = append(lines, fmt.Sprintf("%*s +%s", pad, "0", strings.Repeat("-", Width)))
+
+	end := FormatDuration(total)
+	gap := max(1, Width-len("0s")-len(end))
+	lines = append(lines, strings.Repeat(" ", pad+2)+"0s"+strings.Repeat(" ", gap)+end)
+	return lines
+}
+
+// FormatDuration formats d without trailing zero units: 2m instead of 2m0s.
+func FormatDuration(d time.Duration) string {
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
@@ -0,0 +1,117 @@
+package loadprofile
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grafana/mcp-k6/internal/k6opts"
+	"github.com/grafana/mcp-k6/internal/scriptinfo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderRampingVUs(t *testing.T) {
+	t.Parallel()
+
+	res := k6opts.Resolve(k6opts.Layer{"stages": "1m:10,1m:10,1m:0"}, nil, nil)
+	profiles := FromResult(res, nil)
+	require.Len(t, profiles, 1)
+	assert.Equal(t, []Point{{0, 1}, {time.Minute, 10}, {2 * time.Minute, 10}, {3 * time.Minute, 0}},
+		profiles[0].Points)
+
+	lines := Render(profiles)
+	require.Len(t, lines, Height+3)
+	assert.Equal(t, "default (ramping-vus), VUs:", lines[0])
+	assert.True(t, strings.HasPrefix(lines[1], "10 |"))
+	assert.Equal(t, " 0 +"+strings.Repeat("-", Width), lines[Height+1])
+	assert.True(t, strings.HasSuffix(lines[Height+2], "3m"))
+
+	// The plateau is at the peak, the ramps are not.
+	top := lines[1][len("10 |"):]
+	assert.Equal(t, strings.Repeat("#", len(strings.TrimSpace(top))), strings.TrimSpace(top))
+	assert.Greater(t, len(top)-len(strings.TrimLeft(top, " ")), 10)
+}
+
+func TestRenderScenarios(t *testing.T) {
+	t.Parallel()
+
+	info := scriptinfo.Analyze(`
+export const options = {
+  scenarios: {
+    browse: { executor: 'constant-vus', vus: 5, duration: '30s' },
+    spike: {
+      executor: 'ramping-arrival-rate', startTime: '30s', timeUnit: '1s', preAllocatedVUs: 10,
+      stages: [{ duration: '10s', target: 100 }, { duration: '10s', target: 0 }],
+    },
+    setup: { executor: 'shared-iterations', vus: 2, iterations: 20 },
+  },
+};
+export default function () {}
+`)
+	res := k6opts.Resolve(k6opts.FromScript(info), nil, nil)
+	lines := Render(FromResult(res, info))
+	out := strings.Join(lines, "\n")
+
+	assert.Contains(t, out, "browse (constant-vus), VUs:")
+	assert.Contains(t, out, "spike (ramping-arrival-rate), starts at 30s, iterations/1s:")
+	assert.Contains(t, out, "setup (shared-iterations): 2 VU(s) run 20 iteration(s)")
+	assert.Contains(t, out, "100 |")
+
+	// Both charts share the 50s axis: browse ends before spike starts.
+	browseTop := lines[1]
+	assert.Less(t, len(strings.TrimRight(browseTop, " ")), len("5 |")+Width*2/3)
+}
+
+func TestRenderNonLiteral(t *testing.T) {
+	t.Parallel()
+
+	info := scriptinfo.Analyze(`
+export const options = {
+  scenarios: { load: { executor: 'constant-vus', vus: __ENV.VUS, duration: '1m' } },
+};
+export default function () {}
+`)
+	lines := Render(FromResult(k6opts.Resolve(k6opts.FromScript(info), nil, nil), info))
+	require.Len(t, lines, 1)
+	assert.Contains(t, lines[0], "cannot be read statically")
+}
+
+func TestFormatDuration(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "2m", FormatDuration(2*time.Minute))
+	assert.Equal(t, "1h", FormatDuration(time.Hour))
+	assert.Equal(t, "1m30s", FormatDuration(90*time.Second))
+}
+
+func TestPeakOf(t *testing.T) {
+	t.Parallel()
+
+	res := k6opts.Resolve(k6opts.Layer{"stages": "1m:10,2m:40,1m:0"}, nil, nil)
+	assert.Equal(t, Peak{VUs: 40, Duration: 4 * time.Minute}, PeakOf(res, nil))
+
+	info := scriptinfo.Analyze(`
+export const options = {
+  scenarios: {
+    browse: { executor: 'constant-vus', vus: 5, duration: '1m' },
+    ramp: { executor: 'ramping-vus', startTime: '30s', stages: [{ duration: '1m', target: 20 }] },
+    spike: { executor: 'constant-arrival-rate', rate: 100, duration: '2m', preAllocatedVUs: 10, maxVUs: 30 },
+    setup: { executor: 'shared-iterations', vus: 2, iterations: 20 },
+  },
+};
+export default function () {}
+`)
+	res = k6opts.Resolve(k6opts.FromScript(info), nil, nil)
+	// browse ends at 1m, before ramp peaks at 20 VUs; spike and setup count
+	// with all the VUs they may start.
+	assert.Equal(t, Peak{VUs: 20 + 30 + 2, Duration: 2 * time.Minute}, PeakOf(res, info))
+
+	info = scriptinfo.Analyze(`
+export const options = {
+  scenarios: { load: { executor: 'constant-vus', vus: __ENV.VUS, duration: '1m' } },
+};
+export default function () {}
+`)
+	assert.True(t, PeakOf(k6opts.Resolve(k6opts.FromScript(info), nil, nil), info).Unknown)
+}
@@ -0,0 +1,98 @@
+package loadprofile
+
+import (
+	"math"
+	"time"
+
+	"github.com/grafana/mcp-k6/internal/k6opts"
+	"github.com/grafana/mcp-k6/internal/scriptinfo"
+)
+
+// Peak is the most load an execution applies.
+type Peak struct {
+	// VUs is the most VUs active at once. Arrival-rate and iteration-based
+	// scenarios count with every VU they may start, for the whole test.
+	VUs int
+	// Duration is when the last timed scenario ends. Iteration-based
+	// scenarios last as long as their iterations take and do not count.
+	Duration time.Duration
+	// Unknown is set when the load of a scenario cannot be read statically,
+	// so VUs and Duration may fall short.
+	Unknown bool
+}
+
+// PeakOf returns the peak load of the execution k6 derives for res,
+// reading scenario definitions from info when the script's scenarios are
+// in effect.
+func PeakOf(res *k6opts.Result, info *scriptinfo.Info) Peak {
+	profiles := FromResult(res, info)
+	var peak Peak
+	var charted []Profile
+	fixed := 0.0
+
+	for i, p := range profiles {
+		if end := p.end(); len(p.Points) > 0 && end > peak.Duration {
+			peak.Duration = end
+		}
+		if p.Unit == "VUs" && len(p.Points) > 0 {
+			charted = append(charted, p)
+			continue
+		}
+
+		var vus float64
+		var err error
+		switch {
+		case res.Execution.Executor != "":
+			vus, err = number(optionValue(res, "vus"))
+		case info != nil && i < len(info.Scenarios):
+			vus, err = scenarioVUs(info.Scenarios[i])
+		default:
+			peak.Unknown = true
+		}
+		if err != nil {
+			peak.Unknown = true
+		}
+		fixed += vus
+	}
+
+	top := 0.0
+	for _, p := range charted {
+		for _, pt := range p.Points {
+			t := p.Start + pt.At
+			sum := 0.0
+			for _, q := range charted {
+				sum += q.at(t)
+			}
+			top = math.Max(top, sum)
+		}
+	}
+	peak.VUs = int(math.Ceil(top + fixed))
+	return peak
+}
+
+// scenarioVUs returns the VUs a scenario without a VU chart may start.
+func scenarioVUs(sc scriptinfo.Scenario) (float64, error) {
+	setting := func(keys ...string) string {
+		for _, key := range keys {
+			if v, ok := sc.Settings[key]; ok {
+				return v
+			}
+		}
+		return "1"
+	}
+	switch sc.Executor {
+	case "constant-arrival-rate", "ramping-arrival-rate":
+		return number(setting("maxVUs", "preAllocatedVUs"))
+	case "externally-controlled":
+		return number(setting("maxVUs", "vus"))
+	default:
+		return number(setting("vus"))
+	}
+}
+
+func optionValue(res *k6opts.Result, name string) string {
+	if o, ok := res.Lookup(name); ok {
+		return o.Value
+	}
+	return ""
+}
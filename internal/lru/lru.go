@@ -0,0 +1,88 @@
+// Package lru provides a fixed-size cache evicting the least recently used
+// entries.
+package lru
+
+import (
+	"container/list"
+	"sync"
+)
+
+// Cache maps keys to values, keeping the size most recently used ones. It is
+// safe for concurrent use. A nil Cache holds nothing.
+type Cache[K comparable, V any] struct {
+	mu    sync.Mutex
+	size  int
+	order *list.List // of *entry[K, V], most recently used first
+	items map[K]*list.Element
+}
+
+type entry[K comparable, V any] struct {
+	key   K
+	value V
+}
+
+// New returns a cache of size entries, or nil when size is not positive.
+func New[K comparable, V any](size int) *Cache[K, V] {
+	if size <= 0 {
+		return nil
+	}
+	return &Cache[K, V]{size: size, order: list.New(), items: make(map[K]*list.Element, size)}
+}
+
+// Get returns the value of key, and marks it as the most recently used.
+func (c *Cache[K, V]) Get(key K) (V, bool) {
+	var zero V
+	if c == nil {
+		return zero, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.items[key]
+	if !ok {
+		return zero, false
+	}
+	c.order.MoveToFront(el)
+	return el.Value.(*entry[K, V]).value, true
+}
+
+// Add sets the value of key, evicting the least recently used entry when
+// the cache is full.
+func (c *Cache[K, V]) Add(key K, value V) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.items[key]; ok {
+		el.Value.(*entry[K, V]).value = value
+		c.order.MoveToFront(el)
+		return
+	}
+	c.items[key] = c.order.PushFront(&entry[K, V]{key: key, value: value})
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*entry[K, V]).key)
+	}
+}
+
+// Purge removes every entry of the cache.
+func (c *Cache[K, V]) Purge() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.order.Init()
+	clear(c.items)
+}
+
+// Len returns the number of entries of the cache.
+func (c *Cache[K, V]) Len() int {
+	if c == nil {
+		return 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
@@ -0,0 +1,71 @@
+package lru
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache(t *testing.T) {
+	t.Parallel()
+
+	c := New[string, int](2)
+	c.Add("a", 1)
+	c.Add("b", 2)
+	v, ok := c.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, 1, v)
+
+	// b is now the least recently used
+	c.Add("c", 3)
+	_, ok = c.Get("b")
+	assert.False(t, ok)
+	assert.Equal(t, 2, c.Len())
+
+	c.Add("a", 10)
+	v, _ = c.Get("a")
+	assert.Equal(t, 10, v, "adding a key again replaces its value")
+	assert.Equal(t, 2, c.Len())
+
+	c.Purge()
+	_, ok = c.Get("a")
+	assert.False(t, ok)
+	assert.Zero(t, c.Len())
+	c.Add("d", 4)
+	assert.Equal(t, 1, c.Len(), "a purged cache is reused")
+}
+
+func TestNilCache(t *testing.T) {
+	t.Parallel()
+
+	c := New[string, int](0)
+	assert.Nil(t, c)
+	c.Add("a", 1)
+	c.Purge()
+	_, ok := c.Get("a")
+	assert.False(t, ok)
+	assert.Zero(t, c.Len())
+}
+
+func TestCacheConcurrent(t *testing.T) {
+	t.Parallel()
+
+	c := New[string, int](10)
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range 100 {
+				key := fmt.Sprint((i + j) % 20)
+				if _, ok := c.Get(key); !ok {
+					c.Add(key, j)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 10, c.Len())
+}
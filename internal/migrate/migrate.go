@@ -0,0 +1,162 @@
+// Package migrate rewrites the deprecated patterns of k6 scripts, such as
+// imports of graduated experimental modules, to their equivalent in a target
+// k6 version. Patterns without a safe rewrite are reported as notes.
+package migrate
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/grafana/mcp-k6/internal/diff"
+	"github.com/grafana/mcp-k6/internal/releasenotes"
+	"github.com/grafana/mcp-k6/internal/scriptinfo"
+)
+
+// patchName names the script in the headers of patches.
+const patchName = "script.js"
+
+// rule rewrites the imports of a module k6 replaced.
+type rule struct {
+	module string
+	// to is the replacement module, empty when the import is removed or
+	// must be rewritten by hand.
+	to string
+	// since is the first release providing the replacement.
+	since releasenotes.Version
+	// note tells what else to change after the rewrite.
+	note string
+}
+
+//nolint:gochecknoglobals // Read-only lookup tables.
+var (
+	rules = []rule{
+		{module: "k6/experimental/grpc", to: "k6/net/grpc", since: releasenotes.Version{0, 49, 0}},
+		{module: "k6/experimental/timers", to: "k6/timers", since: releasenotes.Version{0, 50, 0}},
+		{
+			module: "k6/experimental/browser", to: "k6/browser", since: releasenotes.Version{0, 52, 0},
+			note: "k6/browser methods return promises: await browser.newPage(), page.goto() and locator actions " +
+				"in async functions",
+		},
+		{module: "k6/experimental/websockets", to: "k6/websockets", since: releasenotes.Version{1, 0, 0}},
+		{
+			module: "k6/experimental/webcrypto", since: releasenotes.Version{1, 0, 0},
+			note: "crypto is a global object: remove the import and use crypto.subtle directly",
+		},
+		{
+			module: "k6/experimental/tracing", since: releasenotes.Version{1, 0, 0},
+			note: "k6/experimental/tracing was removed: instrument requests with the " +
+				"https://jslib.k6.io/http-instrumentation-tempo/1.0.0/index.js jslib instead",
+		},
+	}
+	// loadimpactSince is the first release reading options.cloud, which
+	// replaces options.ext.loadimpact.
+	loadimpactSince = releasenotes.Version{0, 48, 0}
+	reLoadimpact    = regexp.MustCompile(`(^|[\s,{])['"]?loadimpact['"]?\s*:`)
+	// reCryptoImport matches a whole line importing the global crypto.
+	reCryptoImport = regexp.MustCompile(
+		`^\s*import\s*\{\s*crypto\s*\}\s*from\s*['"]k6/experimental/webcrypto['"]\s*;?\s*$`)
+)
+
+// Change is a deprecated pattern of a script.
+type Change struct {
+	Line int    `json:"line"`
+	From string `json:"from"`
+	// To is the replacement, empty when the pattern is removed.
+	To string `json:"to,omitempty"`
+	// Since is the first release providing the replacement.
+	Since string `json:"since"`
+	Note  string `json:"note,omitempty"`
+}
+
+// Result is the migration of a script.
+type Result struct {
+	Script string `json:"script"`
+	// Patch is a unified diff from the original script, empty when nothing
+	// was rewritten.
+	Patch string `json:"patch,omitempty"`
+	// Changes are the rewritten patterns.
+	Changes []Change `json:"changes"`
+	// Manual are the patterns to rewrite by hand.
+	Manual []Change `json:"manual,omitempty"`
+	// Pending are the patterns whose replacement is newer than the target.
+	Pending []Change `json:"pending,omitempty"`
+}
+
+// Migrate rewrites the deprecated patterns of script whose replacement is in
+// target. The zero target migrates to the latest release.
+func Migrate(script string, target releasenotes.Version) *Result {
+	res := &Result{Script: script, Changes: []Change{}}
+	info := scriptinfo.Analyze(script)
+	lines := strings.SplitAfter(script, "\n")
+	applies := func(since releasenotes.Version) bool {
+		return target == (releasenotes.Version{}) || target.Compare(since) >= 0
+	}
+
+	for _, imp := range info.Imports {
+		r, ok := ruleFor(imp.Module)
+		if !ok || imp.Line < 1 || imp.Line > len(lines) {
+			continue
+		}
+		c := Change{Line: imp.Line, From: r.module, To: r.to, Since: r.since.String(), Note: r.note}
+		switch {
+		case !applies(r.since):
+			res.Pending = append(res.Pending, c)
+		case r.to != "":
+			re := regexp.MustCompile(`(['"])` + regexp.QuoteMeta(r.module) + `(['"])`)
+			lines[imp.Line-1] = re.ReplaceAllString(lines[imp.Line-1], "${1}"+r.to+"${2}")
+			res.Changes = append(res.Changes, c)
+		case reCryptoImport.MatchString(lines[imp.Line-1]):
+			lines[imp.Line-1] = ""
+			c.Note = "crypto is a global object"
+			res.Changes = append(res.Changes, c)
+		default:
+			res.Manual = append(res.Manual, c)
+		}
+	}
+
+	if info.Options != nil {
+		for n := max(info.Options.Line, 1); n <= len(lines); n++ {
+			if !reLoadimpact.MatchString(lines[n-1]) {
+				continue
+			}
+			c := Change{
+				Line: n, From: "options.ext.loadimpact", To: "options.cloud", Since: loadimpactSince.String(),
+				Note: "move the fields of options.ext.loadimpact to options.cloud",
+			}
+			if applies(loadimpactSince) {
+				res.Manual = append(res.Manual, c)
+			} else {
+				res.Pending = append(res.Pending, c)
+			}
+			break
+		}
+	}
+
+	res.Script = strings.Join(lines, "")
+	res.Patch, _ = diff.Unified("a/"+patchName, "b/"+patchName, script, res.Script, diff.DefaultContext)
+	return res
+}
+
+func ruleFor(module string) (rule, bool) {
+	for _, r := range rules {
+		if r.module == module {
+			return r, true
+		}
+	}
+	return rule{}, false
+}
+
+// Describe returns a sentence telling what c changes.
+func (c Change) Describe() string {
+	var s string
+	if c.To != "" {
+		s = fmt.Sprintf("line %d: %s becomes %s in %s", c.Line, c.From, c.To, c.Since)
+	} else {
+		s = fmt.Sprintf("line %d: %s is replaced in %s", c.Line, c.From, c.Since)
+	}
+	if c.Note != "" {
+		s += "; " + c.Note
+	}
+	return s
+}
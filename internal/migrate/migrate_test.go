@@ -0,0 +1,67 @@
+package migrate
+
+import (
+	"testing"
+
+	"github.com/grafana/mcp-k6/internal/releasenotes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const script = `import { browser } from 'k6/experimental/browser';
+import { crypto } from "k6/experimental/webcrypto";
+import ws from 'k6/experimental/websockets';
+import { instrumentHTTP } from 'k6/experimental/tracing';
+
+export const options = {
+  ext: {
+    loadimpact: { projectID: 1 },
+  },
+};
+
+export default function () {}
+`
+
+func TestMigrate(t *testing.T) {
+	t.Parallel()
+
+	res := Migrate(script, releasenotes.Version{})
+	assert.Equal(t, `import { browser } from 'k6/browser';
+import ws from 'k6/websockets';
+import { instrumentHTTP } from 'k6/experimental/tracing';
+
+export const options = {
+  ext: {
+    loadimpact: { projectID: 1 },
+  },
+};
+
+export default function () {}
+`, res.Script)
+	assert.Contains(t, res.Patch, "-import { browser } from 'k6/experimental/browser';\n")
+	assert.Contains(t, res.Patch, "+import { browser } from 'k6/browser';\n")
+	require.Len(t, res.Changes, 3)
+	assert.Equal(t, Change{
+		Line: 1, From: "k6/experimental/browser", To: "k6/browser", Since: "v0.52.0", Note: rules[2].note,
+	}, res.Changes[0])
+	assert.Equal(t, "k6/experimental/webcrypto", res.Changes[1].From)
+	require.Len(t, res.Manual, 2)
+	assert.Equal(t, 4, res.Manual[0].Line)
+	assert.Equal(t, "options.ext.loadimpact", res.Manual[1].From)
+	assert.Equal(t, 8, res.Manual[1].Line)
+	assert.Empty(t, res.Pending)
+}
+
+func TestMigrateTarget(t *testing.T) {
+	t.Parallel()
+
+	res := Migrate(script, releasenotes.Version{0, 57, 0})
+	require.Len(t, res.Changes, 1)
+	assert.Equal(t, "k6/browser", res.Changes[0].To)
+	require.Len(t, res.Pending, 3)
+	assert.Equal(t, "line 3: k6/experimental/websockets becomes k6/websockets in v1.0.0", res.Pending[1].Describe())
+
+	res = Migrate("import http from 'k6/http';\n", releasenotes.Version{})
+	assert.Empty(t, res.Patch)
+	assert.Empty(t, res.Changes)
+}
@@ -0,0 +1,449 @@
+// Package observe queries the Prometheus and Loki datasources configured on
+// the server for the server-side metrics of the time window a run covered,
+// such as CPU usage or the rate of error logs, so they can be lined up with
+// the results of the run.
+package observe
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Datasource types.
+const (
+	// Prometheus is any datasource serving the Prometheus HTTP API, such as
+	// Mimir, Thanos or a Grafana datasource proxy.
+	Prometheus = "prometheus"
+	// Loki runs LogQL metric queries, such as the rate of error logs.
+	Loki = "loki"
+)
+
+const (
+	// DefaultStep is the resolution of the queries by default.
+	DefaultStep = 15 * time.Second
+	// DefaultMargin widens the run window on both sides by default, to
+	// show the baseline before the load and the recovery after it.
+	DefaultMargin = 30 * time.Second
+	// MaxQueries bounds the queries of a configuration.
+	MaxQueries = 20
+	// maxSeries bounds the series kept per query.
+	maxSeries = 10
+	// maxPoints bounds the points kept per series.
+	maxPoints = 60
+	// timeout bounds each query.
+	timeout = 15 * time.Second
+	// maxBody bounds the responses read from datasources.
+	maxBody = 4 << 20
+)
+
+// ErrInvalid is returned for configurations that cannot be used.
+var ErrInvalid = errors.New("invalid observe configuration")
+
+// Config is the observe configuration, read from a JSON file.
+type Config struct {
+	Datasources []Datasource `json:"datasources"`
+	Queries     []Query      `json:"queries"`
+	// Step is the resolution of the queries, such as "15s".
+	Step string `json:"step,omitempty"`
+	// Margin widens the run window on both sides, such as "30s".
+	Margin string `json:"margin,omitempty"`
+}
+
+// Datasource is a Prometheus or Loki HTTP API. Grafana datasources are
+// reached through their proxy URL, such as
+// https://grafana.example.com/api/datasources/proxy/uid/<uid>.
+type Datasource struct {
+	Name string `json:"name"`
+	// Type is Prometheus (default) or Loki.
+	Type string `json:"type,omitempty"`
+	URL  string `json:"url"`
+	// User and TokenEnv authenticate requests: with a user, the token is
+	// the basic auth password, otherwise a bearer token.
+	User string `json:"user,omitempty"`
+	// TokenEnv names the environment variable holding the token, which is
+	// never written in the file.
+	TokenEnv string `json:"token_env,omitempty"`
+}
+
+// Query is a PromQL or LogQL metric query run over the window of a run.
+type Query struct {
+	Name string `json:"name"`
+	// Datasource names the datasource (default: the first one).
+	Datasource string `json:"datasource,omitempty"`
+	Query      string `json:"query"`
+	// Unit describes the values, such as "cores" or "errors/s".
+	Unit string `json:"unit,omitempty"`
+}
+
+// Observer runs the configured queries. A nil *Observer observes nothing.
+type Observer struct {
+	datasources map[string]datasource
+	queries     []Query
+	step        time.Duration
+	margin      time.Duration
+	client      *http.Client
+}
+
+// datasource is a configured datasource with its resolved credentials.
+type datasource struct {
+	Datasource
+	url   *url.URL
+	token string
+}
+
+// Report holds the series the queries returned for a run.
+type Report struct {
+	// Start and End are the queried window, the run widened by the margin.
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+	// RunStart is the time offsets of the series are relative to.
+	RunStart time.Time `json:"run_start"`
+	Step     string    `json:"step"`
+	Series   []Series  `json:"series"`
+	// Errors lists the queries that failed or returned too many series.
+	Errors []string `json:"errors,omitempty"`
+}
+
+// Series is a time series returned by a query, with the statistics of its
+// values.
+type Series struct {
+	Query      string            `json:"query"`
+	Datasource string            `json:"datasource"`
+	Labels     map[string]string `json:"labels,omitempty"`
+	Unit       string            `json:"unit,omitempty"`
+	Min        float64           `json:"min"`
+	Max        float64           `json:"max"`
+	Mean       float64           `json:"mean"`
+	// Baseline is the mean before the run started, and During the mean
+	// while it ran, to tell the effect of the load; Baseline is nil without
+	// points before the run.
+	Baseline *float64 `json:"baseline,omitempty"`
+	During   *float64 `json:"during,omitempty"`
+	// PeakAt is when Max was reached, in seconds from the start of the run.
+	PeakAt float64 `json:"peak_at"`
+	// Points are [seconds from the start of the run, value] pairs, evenly
+	// thinned out when there are more than maxPoints.
+	Points [][2]float64 `json:"points"`
+}
+
+// Load returns an observer for the configuration in file, or nil when file
+// is empty. Tokens are looked up in environ.
+func Load(file string, environ []string) (*Observer, error) {
+	if file == "" {
+		return nil, nil
+	}
+	//nolint:forbidigo // The observe file is operator configuration, outside any workspace root.
+	data, err := os.ReadFile(file) // #nosec G304 -- path comes from server configuration
+	if err != nil {
+		return nil, fmt.Errorf("reading observe file: %w", err)
+	}
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parsing observe file: %w", err)
+	}
+	return New(cfg, environ)
+}
+
+// New returns an observer running the queries of cfg. Tokens are looked up
+// in environ.
+func New(cfg Config, environ []string) (*Observer, error) {
+	env := make(map[string]string)
+	for _, kv := range environ {
+		if k, v, ok := strings.Cut(kv, "="); ok {
+			env[k] = v
+		}
+	}
+
+	o := &Observer{
+		datasources: make(map[string]datasource, len(cfg.Datasources)),
+		step:        DefaultStep,
+		margin:      DefaultMargin,
+		client:      &http.Client{Timeout: timeout},
+	}
+	var err error
+	if cfg.Step != "" {
+		if o.step, err = time.ParseDuration(cfg.Step); err != nil || o.step < time.Second {
+			return nil, fmt.Errorf("%w: step must be a duration of at least 1s, got %q", ErrInvalid, cfg.Step)
+		}
+	}
+	if cfg.Margin != "" {
+		if o.margin, err = time.ParseDuration(cfg.Margin); err != nil || o.margin < 0 {
+			return nil, fmt.Errorf("%w: margin must be a positive duration, got %q", ErrInvalid, cfg.Margin)
+		}
+	}
+
+	if len(cfg.Datasources) == 0 {
+		return nil, fmt.Errorf("%w: no datasources", ErrInvalid)
+	}
+	for _, d := range cfg.Datasources {
+		if d.Type == "" {
+			d.Type = Prometheus
+		}
+		u, err := url.Parse(strings.TrimRight(d.URL, "/"))
+		switch {
+		case d.Name == "":
+			return nil, fmt.Errorf("%w: a datasource has no name", ErrInvalid)
+		case o.datasources[d.Name].url != nil:
+			return nil, fmt.Errorf("%w: datasource %s is defined twice", ErrInvalid, d.Name)
+		case d.Type != Prometheus && d.Type != Loki:
+			return nil, fmt.Errorf("%w: datasource %s: type must be %s or %s", ErrInvalid, d.Name, Prometheus, Loki)
+		case err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "":
+			return nil, fmt.Errorf("%w: datasource %s: url must be an absolute http or https URL", ErrInvalid, d.Name)
+		case u.User != nil:
+			return nil, fmt.Errorf("%w: datasource %s: set credentials with user and token_env, not in the url",
+				ErrInvalid, d.Name)
+		}
+		ds := datasource{Datasource: d, url: u}
+		if d.TokenEnv != "" {
+			if ds.token = env[d.TokenEnv]; ds.token == "" {
+				return nil, fmt.Errorf("%w: datasource %s: %s is not set", ErrInvalid, d.Name, d.TokenEnv)
+			}
+		}
+		o.datasources[d.Name] = ds
+	}
+
+	switch {
+	case len(cfg.Queries) == 0:
+		return nil, fmt.Errorf("%w: no queries", ErrInvalid)
+	case len(cfg.Queries) > MaxQueries:
+		return nil, fmt.Errorf("%w: %d queries (max %d)", ErrInvalid, len(cfg.Queries), MaxQueries)
+	}
+	var names []string
+	for _, q := range cfg.Queries {
+		if q.Datasource == "" {
+			q.Datasource = cfg.Datasources[0].Name
+		}
+		switch {
+		case q.Name == "":
+			return nil, fmt.Errorf("%w: a query has no name", ErrInvalid)
+		case slices.Contains(names, q.Name):
+			return nil, fmt.Errorf("%w: query %s is defined twice", ErrInvalid, q.Name)
+		case strings.TrimSpace(q.Query) == "":
+			return nil, fmt.Errorf("%w: query %s is empty", ErrInvalid, q.Name)
+		case o.datasources[q.Datasource].url == nil:
+			return nil, fmt.Errorf("%w: query %s: unknown datasource %q", ErrInvalid, q.Name, q.Datasource)
+		}
+		names = append(names, q.Name)
+		o.queries = append(o.queries, q)
+	}
+	return o, nil
+}
+
+// Queries returns the names of the queries, for logging.
+func (o *Observer) Queries() []string {
+	if o == nil {
+		return nil
+	}
+	names := make([]string, 0, len(o.queries))
+	for _, q := range o.queries {
+		names = append(names, q.Name)
+	}
+	return names
+}
+
+// Observe runs every query over the window of a run that started at start
+// and ended at end, widened by the margin. Failed queries are reported in
+// the Errors of the report, which is nil for a nil observer.
+func (o *Observer) Observe(ctx context.Context, start, end time.Time) *Report {
+	if o == nil {
+		return nil
+	}
+	report := &Report{
+		Start:    start.Add(-o.margin).UTC().Truncate(time.Second),
+		End:      end.Add(o.margin).UTC().Truncate(time.Second),
+		RunStart: start.UTC(),
+		Step:     o.step.String(),
+	}
+
+	results := make([][]Series, len(o.queries))
+	errs := make([]error, len(o.queries))
+	var wg sync.WaitGroup
+	for i, q := range o.queries {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], errs[i] = o.query(ctx, q, report.Start, report.End)
+		}()
+	}
+	wg.Wait()
+
+	for i, q := range o.queries {
+		if errs[i] != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("%s: %v", q.Name, errs[i]))
+			continue
+		}
+		series := results[i]
+		if len(series) > maxSeries {
+			report.Errors = append(report.Errors, fmt.Sprintf(
+				"%s: %d series, only the %d with the highest peaks are kept; aggregate the query with sum or avg",
+				q.Name, len(series), maxSeries))
+			sort.SliceStable(series, func(a, b int) bool { return series[a].Max > series[b].Max })
+			series = series[:maxSeries]
+		}
+		for j := range series {
+			summarize(&series[j], start, end)
+		}
+		report.Series = append(report.Series, series...)
+	}
+	return report
+}
+
+// queryResponse is the body of a query_range response, the same for
+// Prometheus and Loki metric queries.
+type queryResponse struct {
+	Status    string `json:"status"`
+	Error     string `json:"error"`
+	ErrorType string `json:"errorType"`
+	Data      struct {
+		ResultType string `json:"resultType"`
+		Result     []struct {
+			Metric map[string]string `json:"metric"`
+			Values [][2]any          `json:"values"`
+		} `json:"result"`
+	} `json:"data"`
+}
+
+// query runs q over [start, end] and returns its series, with their raw
+// points in Points as [unix seconds, value].
+func (o *Observer) query(ctx context.Context, q Query, start, end time.Time) ([]Series, error) {
+	ds := o.datasources[q.Datasource]
+	path := "/api/v1/query_range"
+	if ds.Type == Loki {
+		path = "/loki/api/v1/query_range"
+	}
+	params := url.Values{
+		"query": {q.Query},
+		"start": {start.Format(time.RFC3339)},
+		"end":   {end.Format(time.RFC3339)},
+		"step":  {strconv.FormatFloat(o.step.Seconds(), 'f', -1, 64)},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ds.url.String()+path+"?"+params.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", ds.Name, err)
+	}
+	switch {
+	case ds.User != "":
+		req.SetBasicAuth(ds.User, ds.token)
+	case ds.token != "":
+		req.Header.Set("Authorization", "Bearer "+ds.token)
+	}
+	resp, err := o.client.Do(req)
+	if err != nil {
+		// Errors from the client quote the full URL, query included
+		var uerr *url.Error
+		if errors.As(err, &uerr) {
+			err = uerr.Err
+		}
+		return nil, fmt.Errorf("%s: %w", ds.Name, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxBody))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", ds.Name, err)
+	}
+
+	var qr queryResponse
+	if err := json.Unmarshal(body, &qr); err != nil {
+		if resp.StatusCode >= http.StatusBadRequest {
+			return nil, fmt.Errorf("%s: %s", ds.Name, resp.Status)
+		}
+		return nil, fmt.Errorf("%s: unexpected response: %w", ds.Name, err)
+	}
+	if qr.Status != "success" {
+		msg := qr.Error
+		if msg == "" {
+			msg = resp.Status
+		}
+		return nil, fmt.Errorf("%s: %s", ds.Name, msg)
+	}
+	if qr.Data.ResultType != "matrix" {
+		return nil, fmt.Errorf("%s: want a range of values, got a %s: use a metric query", ds.Name, qr.Data.ResultType)
+	}
+
+	series := make([]Series, 0, len(qr.Data.Result))
+	for _, r := range qr.Data.Result {
+		s := Series{Query: q.Name, Datasource: ds.Name, Labels: r.Metric, Unit: q.Unit}
+		for _, v := range r.Values {
+			ts, ok := v[0].(float64)
+			raw, isString := v[1].(string)
+			if !ok || !isString {
+				continue
+			}
+			value, err := strconv.ParseFloat(raw, 64)
+			if err != nil || math.IsNaN(value) || math.IsInf(value, 0) {
+				continue
+			}
+			s.Points = append(s.Points, [2]float64{ts, value})
+		}
+		series = append(series, s)
+	}
+	return series, nil
+}
+
+// summarize sets the statistics of s and makes its points relative to the
+// start of the run, thinning them out to maxPoints.
+func summarize(s *Series, start, end time.Time) {
+	if len(s.Points) == 0 {
+		return
+	}
+	runStart := float64(start.UnixMilli()) / 1000
+	runEnd := float64(end.UnixMilli()) / 1000
+
+	s.Min, s.Max = math.Inf(1), math.Inf(-1)
+	var sum, before, during float64
+	var nBefore, nDuring int
+	for i, p := range s.Points {
+		value := p[1]
+		sum += value
+		s.Min = min(s.Min, value)
+		if value > s.Max {
+			s.Max = value
+			s.PeakAt = round(p[0] - runStart)
+		}
+		switch {
+		case p[0] < runStart:
+			before += value
+			nBefore++
+		case p[0] <= runEnd:
+			during += value
+			nDuring++
+		}
+		s.Points[i] = [2]float64{round(p[0] - runStart), round(value)}
+	}
+	s.Mean = round(sum / float64(len(s.Points)))
+	s.Min, s.Max = round(s.Min), round(s.Max)
+	if nBefore > 0 {
+		baseline := round(before / float64(nBefore))
+		s.Baseline = &baseline
+	}
+	if nDuring > 0 {
+		mean := round(during / float64(nDuring))
+		s.During = &mean
+	}
+
+	if n := len(s.Points); n > maxPoints {
+		thinned := make([][2]float64, 0, maxPoints)
+		for i := range maxPoints {
+			thinned = append(thinned, s.Points[i*(n-1)/(maxPoints-1)])
+		}
+		s.Points = thinned
+	}
+}
+
+// round rounds f to 3 decimals.
+func round(f float64) float64 {
+	return math.Round(f*1000) / 1000
+}
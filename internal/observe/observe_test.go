@@ -0,0 +1,143 @@
+package observe
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestObserve(t *testing.T) {
+	t.Parallel()
+
+	start := time.Unix(1_700_000_000, 0)
+	end := start.Add(60 * time.Second)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/query_range":
+			assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+			assert.Equal(t, start.Add(-30*time.Second).UTC().Format(time.RFC3339), r.URL.Query().Get("start"))
+			assert.Equal(t, "15", r.URL.Query().Get("step"))
+			// CPU idles at 0.2 cores before the run and reaches 1.6 during it.
+			var values []string
+			for i, v := range []string{"0.2", "0.2", "0.8", "1.6", "1.2", "NaN"} {
+				values = append(values, fmt.Sprintf(`[%d, %q]`, start.Unix()+int64(i*15-30), v))
+			}
+			_, _ = fmt.Fprintf(w, `{"status": "success", "data": {"resultType": "matrix", "result": [`+
+				`{"metric": {"job": "api"}, "values": [%s]}]}}`, strings.Join(values, ","))
+		case "/loki/api/v1/query_range":
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"status": "error", "error": "parse error at line 1"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	o, err := New(Config{
+		Datasources: []Datasource{
+			{Name: "prometheus", URL: srv.URL + "/", TokenEnv: "PROM_TOKEN"},
+			{Name: "loki", Type: Loki, URL: srv.URL},
+		},
+		Queries: []Query{
+			{Name: "cpu", Query: `sum(rate(process_cpu_seconds_total[1m]))`, Unit: "cores"},
+			{Name: "error_logs", Datasource: "loki", Query: `sum(rate({app="api"} |= "error" [1m]))`},
+		},
+	}, []string{"PROM_TOKEN=secret"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"cpu", "error_logs"}, o.Queries())
+
+	report := o.Observe(context.Background(), start, end)
+	require.NotNil(t, report)
+	assert.Equal(t, "15s", report.Step)
+	require.Len(t, report.Series, 1)
+	s := report.Series[0]
+	assert.Equal(t, "cpu", s.Query)
+	assert.Equal(t, map[string]string{"job": "api"}, s.Labels)
+	assert.Equal(t, "cores", s.Unit)
+	assert.InDelta(t, 0.2, s.Min, 0.001)
+	assert.InDelta(t, 1.6, s.Max, 0.001)
+	assert.InDelta(t, 15, s.PeakAt, 0.001)
+	require.NotNil(t, s.Baseline)
+	assert.InDelta(t, 0.2, *s.Baseline, 0.001)
+	require.NotNil(t, s.During)
+	assert.InDelta(t, 1.2, *s.During, 0.001)
+	assert.Len(t, s.Points, 5)
+	assert.Equal(t, [2]float64{-30, 0.2}, s.Points[0])
+
+	require.Len(t, report.Errors, 1)
+	assert.Contains(t, report.Errors[0], "error_logs: loki: parse error at line 1")
+}
+
+func TestObserveThinsPoints(t *testing.T) {
+	t.Parallel()
+
+	s := Series{}
+	start := time.Unix(1_000, 0)
+	for i := range 200 {
+		s.Points = append(s.Points, [2]float64{float64(1_000 + i), float64(i)})
+	}
+	summarize(&s, start, start.Add(time.Minute))
+	assert.Len(t, s.Points, maxPoints)
+	assert.Equal(t, [2]float64{0, 0}, s.Points[0])
+	assert.Equal(t, [2]float64{199, 199}, s.Points[maxPoints-1])
+	assert.InDelta(t, 99.5, s.Mean, 0.001)
+	assert.Nil(t, s.Baseline)
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	o, err := Load("", nil)
+	require.NoError(t, err)
+	assert.Nil(t, o)
+	assert.Nil(t, o.Observe(context.Background(), time.Now(), time.Now()))
+
+	prometheus := []Datasource{{Name: "prometheus", URL: "http://localhost:9090"}}
+	cpu := []Query{{Name: "cpu", Query: "up"}}
+	tests := []struct {
+		name string
+		cfg  Config
+		env  []string
+		want string
+	}{
+		{"no datasources", Config{Queries: cpu}, nil, "no datasources"},
+		{"no queries", Config{Datasources: prometheus}, nil, "no queries"},
+		{
+			"relative url",
+			Config{Datasources: []Datasource{{Name: "p", URL: "localhost:9090"}}, Queries: cpu},
+			nil, "absolute http or https URL",
+		},
+		{
+			"credentials in url",
+			Config{Datasources: []Datasource{{Name: "p", URL: "https://u:p@prom.example.com"}}, Queries: cpu},
+			nil, "not in the url",
+		},
+		{
+			"unset token",
+			Config{Datasources: []Datasource{{Name: "p", URL: "http://prom", TokenEnv: "TOKEN"}}, Queries: cpu},
+			[]string{"OTHER=1"}, "TOKEN is not set",
+		},
+		{
+			"unknown datasource",
+			Config{Datasources: prometheus, Queries: []Query{{Name: "cpu", Datasource: "mimir", Query: "up"}}},
+			nil, `unknown datasource "mimir"`,
+		},
+		{"short step", Config{Datasources: prometheus, Queries: cpu, Step: "100ms"}, nil, "step"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := New(tt.cfg, tt.env)
+			require.ErrorIs(t, err, ErrInvalid)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
@@ -0,0 +1,195 @@
+// Package openapi reads the operations declared by OpenAPI 3 and Swagger 2
+// documents and matches request paths against them.
+package openapi
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ErrNoOperations is returned when a document declares no operations.
+var ErrNoOperations = errors.New("spec declares no operations")
+
+// methods are the path item keys that declare operations, in reporting order.
+//
+//nolint:gochecknoglobals // Lookup table.
+var methods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// Spec is the subset of an API description needed for coverage reports.
+type Spec struct {
+	Title   string
+	Version string
+	// BasePaths are the path prefixes of the declared servers, such as "/v1".
+	BasePaths []string
+	// Hosts are the hosts of the declared servers.
+	Hosts      []string
+	Operations []Operation
+}
+
+// Operation is a single method and path template pair.
+type Operation struct {
+	Method      string   `json:"method"`
+	Path        string   `json:"path"`
+	OperationID string   `json:"operation_id,omitempty"`
+	Summary     string   `json:"summary,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	Deprecated  bool     `json:"deprecated,omitempty"`
+}
+
+type document struct {
+	OpenAPI string `yaml:"openapi"`
+	Swagger string `yaml:"swagger"`
+	Info    struct {
+		Title   string `yaml:"title"`
+		Version string `yaml:"version"`
+	} `yaml:"info"`
+	Servers []struct {
+		URL string `yaml:"url"`
+	} `yaml:"servers"`
+	Host     string                          `yaml:"host"`
+	BasePath string                          `yaml:"basePath"`
+	Paths    map[string]map[string]yaml.Node `yaml:"paths"`
+}
+
+type operationDoc struct {
+	OperationID string   `yaml:"operationId"`
+	Summary     string   `yaml:"summary"`
+	Tags        []string `yaml:"tags"`
+	Deprecated  bool     `yaml:"deprecated"`
+}
+
+// Parse decodes a JSON or YAML OpenAPI 3 or Swagger 2 document.
+func Parse(data []byte) (*Spec, error) {
+	var doc document
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("invalid OpenAPI document: %w", err)
+	}
+	if doc.OpenAPI == "" && doc.Swagger == "" {
+		return nil, errors.New("invalid OpenAPI document: missing 'openapi' or 'swagger' version field")
+	}
+
+	spec := &Spec{Title: doc.Info.Title, Version: doc.Info.Version}
+	for _, server := range doc.Servers {
+		spec.addServer(server.URL)
+	}
+	if doc.Host != "" {
+		spec.Hosts = append(spec.Hosts, doc.Host)
+	}
+	if doc.BasePath != "" && doc.BasePath != "/" {
+		spec.BasePaths = append(spec.BasePaths, strings.TrimSuffix(doc.BasePath, "/"))
+	}
+
+	paths := make([]string, 0, len(doc.Paths))
+	for p := range doc.Paths {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	for _, p := range paths {
+		item := doc.Paths[p]
+		for _, method := range methods {
+			node, ok := item[method]
+			if !ok {
+				continue
+			}
+			var op operationDoc
+			if err := node.Decode(&op); err != nil {
+				return nil, fmt.Errorf("invalid operation %s %s: %w", strings.ToUpper(method), p, err)
+			}
+			spec.Operations = append(spec.Operations, Operation{
+				Method:      strings.ToUpper(method),
+				Path:        p,
+				OperationID: op.OperationID,
+				Summary:     op.Summary,
+				Tags:        op.Tags,
+				Deprecated:  op.Deprecated,
+			})
+		}
+	}
+	if len(spec.Operations) == 0 {
+		return nil, ErrNoOperations
+	}
+	return spec, nil
+}
+
+func (s *Spec) addServer(raw string) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return
+	}
+	if u.Host != "" {
+		s.Hosts = append(s.Hosts, u.Host)
+	}
+	if base := strings.TrimSuffix(u.Path, "/"); base != "" {
+		s.BasePaths = append(s.BasePaths, base)
+	}
+}
+
+// Match returns the index of the operation matching method and path, or -1.
+// Path segments written as {placeholder} on either side match any segment,
+// so normalized request patterns such as /users/{id} match /users/{userId}.
+// Server base paths are stripped from path before matching. When several
+// operations match, the most specific one wins.
+func (s *Spec) Match(method, path string) int {
+	method = strings.ToUpper(method)
+	candidates := []string{path}
+	for _, base := range s.BasePaths {
+		if rest, ok := strings.CutPrefix(path, base); ok && (rest == "" || rest[0] == '/') {
+			candidates = append(candidates, rest)
+		}
+	}
+
+	best, bestScore := -1, -1
+	for i, op := range s.Operations {
+		if op.Method != method {
+			continue
+		}
+		for _, candidate := range candidates {
+			if score, ok := matchPath(op.Path, candidate); ok && score > bestScore {
+				best, bestScore = i, score
+			}
+		}
+	}
+	return best
+}
+
+// matchPath compares a path template with a request path segment by segment
+// and scores the match: equal literals count most, then template parameters,
+// then request placeholders matched against a literal template segment.
+func matchPath(template, path string) (int, bool) {
+	want := splitPath(template)
+	got := splitPath(path)
+	if len(want) != len(got) {
+		return 0, false
+	}
+	score := 0
+	for i := range want {
+		switch {
+		case isPlaceholder(want[i]):
+			score++
+		case isPlaceholder(got[i]):
+		case want[i] == got[i]:
+			score += 2
+		default:
+			return 0, false
+		}
+	}
+	return score, true
+}
+
+func splitPath(p string) []string {
+	p = strings.Trim(p, "/")
+	if p == "" {
+		return nil
+	}
+	return strings.Split(p, "/")
+}
+
+func isPlaceholder(segment string) bool {
+	return strings.Contains(segment, "{")
+}
@@ -0,0 +1,97 @@
+package openapi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const petstore = `openapi: 3.0.3
+info:
+  title: Petstore
+  version: 1.0.0
+servers:
+  - url: https://api.example.com/v1
+paths:
+  /pets:
+    parameters:
+      - name: limit
+        in: query
+    get:
+      operationId: listPets
+      tags: [pets]
+    post:
+      operationId: createPet
+  /pets/{petId}:
+    get:
+      operationId: showPet
+  /pets/mine:
+    get:
+      operationId: myPets
+      deprecated: true
+`
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	spec, err := Parse([]byte(petstore))
+	require.NoError(t, err)
+
+	assert.Equal(t, "Petstore", spec.Title)
+	assert.Equal(t, []string{"api.example.com"}, spec.Hosts)
+	assert.Equal(t, []string{"/v1"}, spec.BasePaths)
+	assert.Equal(t, []Operation{
+		{Method: "GET", Path: "/pets", OperationID: "listPets", Tags: []string{"pets"}},
+		{Method: "POST", Path: "/pets", OperationID: "createPet"},
+		{Method: "GET", Path: "/pets/mine", OperationID: "myPets", Deprecated: true},
+		{Method: "GET", Path: "/pets/{petId}", OperationID: "showPet"},
+	}, spec.Operations)
+}
+
+func TestParseSwagger2JSON(t *testing.T) {
+	t.Parallel()
+
+	spec, err := Parse([]byte(`{"swagger": "2.0", "host": "api.example.com", "basePath": "/api",
+		"paths": {"/users/{id}": {"delete": {"operationId": "deleteUser"}}}}`))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/api"}, spec.BasePaths)
+	assert.Equal(t, "DELETE", spec.Operations[0].Method)
+}
+
+func TestParseErrors(t *testing.T) {
+	t.Parallel()
+
+	_, err := Parse([]byte("not: [valid"))
+	require.Error(t, err)
+
+	_, err = Parse([]byte("title: no version field\n"))
+	require.Error(t, err)
+
+	_, err = Parse([]byte("openapi: 3.1.0\npaths: {}\n"))
+	require.ErrorIs(t, err, ErrNoOperations)
+}
+
+func TestMatch(t *testing.T) {
+	t.Parallel()
+
+	spec, err := Parse([]byte(petstore))
+	require.NoError(t, err)
+
+	tests := []struct {
+		method, path string
+		want         int
+	}{
+		{"GET", "/v1/pets", 0},
+		{"get", "/pets/", 0},
+		{"POST", "/v1/pets", 1},
+		{"GET", "/v1/pets/{id}", 3},
+		{"GET", "/v1/pets/mine", 2},
+		{"GET", "/v1/pets/{id}/toys", -1},
+		{"DELETE", "/v1/pets", -1},
+		{"GET", "/v10/pets", -1},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, spec.Match(tt.method, tt.path), tt.method+" "+tt.path)
+	}
+}
@@ -0,0 +1,185 @@
+// Package ownership checks that the hosts a script sends load to publish a
+// verification token, so users of a shared server prove they own, or have
+// the consent of the owner of, what they test.
+package ownership
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// RecordPrefix is prepended to the host name for the DNS TXT record.
+	RecordPrefix = "_k6-verify."
+	// WellKnownPath is the HTTP path the token can be served at.
+	WellKnownPath = "/.well-known/k6-verify.txt"
+
+	// minTokenLength keeps tokens hard to guess.
+	minTokenLength = 16
+	// cacheTTL is how long a verified host stays verified.
+	cacheTTL = time.Hour
+	// timeout bounds each DNS or HTTP lookup.
+	timeout = 5 * time.Second
+	// maxBody bounds the well-known and robots.txt documents read.
+	maxBody = 64 << 10
+)
+
+var (
+	// ErrInvalid is returned for verification settings that cannot be used.
+	ErrInvalid = errors.New("invalid ownership verification")
+	// ErrUnverified is returned for a host that does not publish the token.
+	ErrUnverified = errors.New("target ownership not verified")
+)
+
+// Verifier checks that target hosts publish the server's token, as a DNS
+// TXT record, at the well-known path or in robots.txt. A nil *Verifier
+// verifies nothing.
+type Verifier struct {
+	token    string
+	aboveVUs int
+
+	lookupTXT func(ctx context.Context, name string) ([]string, error)
+	client    *http.Client
+	now       func() time.Time
+
+	mu       sync.Mutex
+	verified map[string]time.Time
+}
+
+// New returns a verifier for runs above aboveVUs VUs, checking for token.
+// It returns nil when token is empty.
+func New(token string, aboveVUs int) (*Verifier, error) {
+	if token == "" {
+		return nil, nil
+	}
+	if len(token) < minTokenLength || strings.ContainsAny(token, " \t\r\n=") {
+		return nil, fmt.Errorf("%w: the token must be at least %d characters, without spaces or '='",
+			ErrInvalid, minTokenLength)
+	}
+	if aboveVUs < 0 {
+		return nil, fmt.Errorf("%w: VU limit %d is negative", ErrInvalid, aboveVUs)
+	}
+	return &Verifier{
+		token:     token,
+		aboveVUs:  aboveVUs,
+		lookupTXT: net.DefaultResolver.LookupTXT,
+		client:    &http.Client{Timeout: timeout},
+		now:       time.Now,
+		verified:  make(map[string]time.Time),
+	}, nil
+}
+
+// Applies reports whether a run starting up to vus VUs needs its targets
+// verified.
+func (v *Verifier) Applies(vus int) bool {
+	return v != nil && vus > v.aboveVUs
+}
+
+// AboveVUs returns the VUs a run may start without verification.
+func (v *Verifier) AboveVUs() int {
+	return v.aboveVUs
+}
+
+// Verify returns an error wrapping ErrUnverified, explaining how to publish
+// the token, unless host ("name" or "name:port") publishes it. scheme is
+// the one the script uses for host: http or ws targets are checked over
+// http, others over https.
+func (v *Verifier) Verify(ctx context.Context, scheme, host string) error {
+	if v == nil {
+		return nil
+	}
+	host = strings.ToLower(host)
+	v.mu.Lock()
+	expires, ok := v.verified[host]
+	v.mu.Unlock()
+	if ok && v.now().Before(expires) {
+		return nil
+	}
+
+	if !v.published(ctx, scheme, host) {
+		return fmt.Errorf("%w: %s. %s", ErrUnverified, host, v.Instructions(scheme, host))
+	}
+	v.mu.Lock()
+	v.verified[host] = v.now().Add(cacheTTL)
+	v.mu.Unlock()
+	return nil
+}
+
+// Instructions tells how to publish the token for host.
+func (v *Verifier) Instructions(scheme, host string) string {
+	name := hostname(host)
+	base := httpScheme(scheme) + "://" + host
+	return fmt.Sprintf("To prove you may load test it, publish %q as a DNS TXT record of %s, "+
+		"or as a line of %s%s or %s/robots.txt", v.record(), RecordPrefix+name, base, WellKnownPath, base)
+}
+
+func (v *Verifier) record() string {
+	return "k6-verify=" + v.token
+}
+
+func (v *Verifier) published(ctx context.Context, scheme, host string) bool {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	name := hostname(host)
+	if net.ParseIP(name) == nil {
+		if records, err := v.lookupTXT(ctx, RecordPrefix+name); err == nil {
+			for _, r := range records {
+				if strings.TrimSpace(r) == v.record() {
+					return true
+				}
+			}
+		}
+	}
+	base := httpScheme(scheme) + "://" + host
+	return v.served(ctx, base+WellKnownPath) || v.served(ctx, base+"/robots.txt")
+}
+
+// served reports whether the document at url has a line holding the record,
+// possibly as a comment as robots.txt requires.
+func (v *Verifier) served(ctx context.Context, url string) bool {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return false
+	}
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return false
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return false
+	}
+	scanner := bufio.NewScanner(io.LimitReader(resp.Body, maxBody))
+	for scanner.Scan() {
+		line := strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(scanner.Text()), "#"))
+		if line == v.record() {
+			return true
+		}
+	}
+	return false
+}
+
+func hostname(host string) string {
+	if name, _, err := net.SplitHostPort(host); err == nil {
+		return name
+	}
+	return strings.Trim(host, "[]")
+}
+
+func httpScheme(scheme string) string {
+	switch strings.ToLower(scheme) {
+	case "http", "ws":
+		return "http"
+	default:
+		return "https"
+	}
+}
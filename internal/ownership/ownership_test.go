@@ -0,0 +1,120 @@
+package ownership
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testToken = "0123456789abcdef"
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	v, err := New("", 1)
+	require.NoError(t, err)
+	assert.Nil(t, v)
+	assert.False(t, v.Applies(100))
+	require.NoError(t, v.Verify(context.Background(), "https", "example.com"))
+
+	for _, token := range []string{"short", "0123456789 abcdef", "k6-verify=0123456789abcdef"} {
+		_, err = New(token, 1)
+		require.ErrorIs(t, err, ErrInvalid, token)
+	}
+	_, err = New(testToken, -1)
+	require.ErrorIs(t, err, ErrInvalid)
+
+	v, err = New(testToken, 1)
+	require.NoError(t, err)
+	assert.False(t, v.Applies(1))
+	assert.True(t, v.Applies(2))
+}
+
+func TestVerifyServedToken(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name, path, body string
+		verified         bool
+	}{
+		{name: "well-known", path: WellKnownPath, body: "k6-verify=" + testToken + "\n", verified: true},
+		{name: "robots.txt", path: "/robots.txt", body: "User-agent: *\n# k6-verify=" + testToken + "\n", verified: true},
+		{name: "other token", path: WellKnownPath, body: "k6-verify=fedcba9876543210\n"},
+		{name: "missing", path: "/elsewhere"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != tc.path {
+					http.NotFound(w, r)
+					return
+				}
+				_, _ = w.Write([]byte(tc.body))
+			}))
+			t.Cleanup(srv.Close)
+
+			v, err := New(testToken, 1)
+			require.NoError(t, err)
+			v.lookupTXT = func(context.Context, string) ([]string, error) {
+				t.Error("IP hosts have no TXT records to look up")
+				return nil, nil
+			}
+			host := strings.TrimPrefix(srv.URL, "http://")
+			err = v.Verify(context.Background(), "http", host)
+			if tc.verified {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, ErrUnverified)
+			assert.Contains(t, err.Error(), "k6-verify="+testToken)
+			assert.Contains(t, err.Error(), srv.URL+WellKnownPath)
+		})
+	}
+}
+
+func TestVerifyDNSRecordAndCache(t *testing.T) {
+	t.Parallel()
+
+	v, err := New(testToken, 1)
+	require.NoError(t, err)
+	v.client = &http.Client{Transport: roundTripFunc(func(*http.Request) (*http.Response, error) {
+		return nil, errors.New("offline")
+	})}
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	v.now = func() time.Time { return now }
+
+	var lookups []string
+	records := []string{"v=spf1 -all", "k6-verify=" + testToken}
+	v.lookupTXT = func(_ context.Context, name string) ([]string, error) {
+		lookups = append(lookups, name)
+		return records, nil
+	}
+
+	require.NoError(t, v.Verify(context.Background(), "https", "API.example.com:8443"))
+	assert.Equal(t, []string{"_k6-verify.api.example.com"}, lookups)
+
+	// Verified hosts are cached until cacheTTL passes
+	records = nil
+	require.NoError(t, v.Verify(context.Background(), "https", "api.example.com:8443"))
+	assert.Len(t, lookups, 1)
+
+	now = now.Add(cacheTTL + time.Minute)
+	err = v.Verify(context.Background(), "https", "api.example.com:8443")
+	require.ErrorIs(t, err, ErrUnverified)
+	assert.Contains(t, err.Error(), "DNS TXT record of _k6-verify.api.example.com")
+	assert.Len(t, lookups, 2)
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
@@ -0,0 +1,279 @@
+// Package playwright parses Playwright test files into their tests, hooks and
+// statements, for converting them to k6 browser scripts. Only the common
+// layout is recognized: test(), test.describe(), test.beforeEach(),
+// test.afterEach() and test.step() calls whose header fits on one line.
+package playwright
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Module is the module Playwright tests import test and expect from.
+const Module = "@playwright/test"
+
+// Test modes.
+const (
+	ModeOnly  = "only"
+	ModeSkip  = "skip"
+	ModeFixme = "fixme"
+)
+
+// ErrNoTests is returned for sources without a test() call.
+var ErrNoTests = errors.New("no Playwright test() call found")
+
+//nolint:gochecknoglobals // Compiled once, read-only.
+var (
+	// reTest matches the header of a test: test('title', async ({ page }) => {
+	// or an empty test on one line.
+	reTest = regexp.MustCompile(`^test(?:\.(only|skip|fixme))?\(\s*['"` + "`" + `](.+?)['"` + "`" + `]\s*,` +
+		`(?:\s*\{[^{}]*\}\s*,)?\s*async\s*(?:function\s*)?\(\s*(?:\{([^}]*)\})?[^)]*\)\s*(?:=>\s*)?\{\s*` +
+		`(\}\s*\)\s*;?\s*)?$`)
+	// reHook matches the header of a beforeEach or afterEach hook.
+	reHook = regexp.MustCompile(`^test\.(beforeEach|afterEach)\(\s*async\s*(?:function\s*)?` +
+		`\(\s*(?:\{([^}]*)\})?[^)]*\)\s*(?:=>\s*)?\{\s*$`)
+	// reDescribe matches the header of a describe block.
+	reDescribe = regexp.MustCompile(`^test\.describe(?:\.(only|skip|fixme|serial|parallel))?\(\s*['"` + "`" +
+		`](.+?)['"` + "`" + `]\s*,\s*(?:async\s*)?(?:\(\s*\)\s*=>|function\s*\(\s*\))\s*\{\s*$`)
+	// reStep matches the header of a step in a test.
+	reStep = regexp.MustCompile(`^(?:await\s+)?test\.step\(\s*['"` + "`" + `](.+?)['"` + "`" +
+		`]\s*,\s*async\s*(?:\(\s*\)\s*=>|function\s*\(\s*\))\s*\{\s*$`)
+	// reClose matches the end of a describe block: "});".
+	reClose = regexp.MustCompile(`^\}\s*\)\s*;?\s*$`)
+)
+
+// Statement is a statement of a source, whose lines after the first are
+// indented relative to it.
+type Statement struct {
+	Line int    `json:"line"`
+	Code string `json:"code"`
+}
+
+// Test is a test of a source.
+type Test struct {
+	// Title is the title of the test, after the titles of its describe
+	// blocks.
+	Title string
+	Line  int
+	// Fixtures are the fixtures the test destructures, such as "page".
+	Fixtures []string
+	// Mode is "", ModeOnly, ModeSkip or ModeFixme, inherited from the
+	// describe blocks.
+	Mode string
+	// Before and After are the statements of the beforeEach and afterEach
+	// hooks applying to the test.
+	Before, After []Statement
+	Body          []Statement
+	scope         string
+}
+
+// Suite is a parsed Playwright test file.
+type Suite struct {
+	// Imports are the imports of other modules than Module.
+	Imports []Statement
+	// Other are the top-level statements besides imports, tests and hooks,
+	// such as helper functions.
+	Other []Statement
+	Tests []Test
+}
+
+type hook struct {
+	kind  string
+	scope string
+	body  []Statement
+}
+
+type describe struct {
+	title, mode, scope string
+}
+
+// Parse parses the Playwright test file source.
+func Parse(source string) (*Suite, error) {
+	lines := strings.Split(source, "\n")
+	suite := &Suite{}
+	var (
+		hooks  []hook
+		frames []describe
+		blocks int
+	)
+	scope := func() string {
+		if len(frames) == 0 {
+			return ""
+		}
+		return frames[len(frames)-1].scope
+	}
+
+	for i := 0; i < len(lines); {
+		line := strings.TrimSpace(lines[i])
+		if m := reTest.FindStringSubmatch(line); m != nil {
+			t := Test{Line: i + 1, Mode: m[1], Fixtures: fixtures(m[3]), scope: scope()}
+			var titles []string
+			for _, f := range frames {
+				titles = append(titles, f.title)
+				if t.Mode == "" {
+					t.Mode = f.mode
+				}
+			}
+			t.Title = strings.Join(append(titles, m[2]), " > ")
+			if m[4] != "" {
+				i++
+			} else {
+				t.Body, i = block(lines, i+1)
+			}
+			suite.Tests = append(suite.Tests, t)
+			continue
+		}
+		if m := reHook.FindStringSubmatch(line); m != nil {
+			h := hook{kind: m[1], scope: scope()}
+			h.body, i = block(lines, i+1)
+			hooks = append(hooks, h)
+			continue
+		}
+		switch m := reDescribe.FindStringSubmatch(line); {
+		case m != nil:
+			blocks++
+			mode := m[1]
+			if mode != ModeOnly && mode != ModeSkip && mode != ModeFixme {
+				mode = ""
+			}
+			frames = append(frames, describe{title: m[2], mode: mode, scope: scope() + strconv.Itoa(blocks) + "/"})
+			i++
+		case reClose.MatchString(line) && len(frames) > 0:
+			frames = frames[:len(frames)-1]
+			i++
+		case line == "" || strings.HasPrefix(line, "//"):
+			i++
+		default:
+			var s Statement
+			s, i = statement(lines, i)
+			switch {
+			case !strings.HasPrefix(line, "import "):
+				suite.Other = append(suite.Other, s)
+			case !strings.Contains(s.Code, `'`+Module+`'`) && !strings.Contains(s.Code, `"`+Module+`"`):
+				suite.Imports = append(suite.Imports, s)
+			}
+		}
+	}
+	if len(suite.Tests) == 0 {
+		return nil, ErrNoTests
+	}
+
+	for i := range suite.Tests {
+		t := &suite.Tests[i]
+		for _, h := range hooks {
+			if !strings.HasPrefix(t.scope, h.scope) {
+				continue
+			}
+			if h.kind == "beforeEach" {
+				t.Before = append(t.Before, h.body...)
+			} else {
+				t.After = append(t.After, h.body...)
+			}
+		}
+	}
+	return suite, nil
+}
+
+// block returns the statements of the block starting at line i, up to the
+// line closing it, and the index of the line after it. Steps are flattened
+// into their statements, after a comment with their title.
+func block(lines []string, i int) ([]Statement, int) {
+	var stmts []Statement
+	for i < len(lines) {
+		line := strings.TrimSpace(lines[i])
+		switch {
+		case line == "":
+			i++
+		case strings.HasPrefix(line, "}"):
+			return stmts, i + 1
+		case reStep.MatchString(line):
+			stmts = append(stmts, Statement{Line: i + 1, Code: "// " + reStep.FindStringSubmatch(line)[1]})
+			var inner []Statement
+			inner, i = block(lines, i+1)
+			stmts = append(stmts, inner...)
+		default:
+			var s Statement
+			s, i = statement(lines, i)
+			stmts = append(stmts, s)
+		}
+	}
+	return stmts, i
+}
+
+// statement returns the statement starting at line i and the index of the
+// line after it. A statement ends on the line balancing its brackets, unless
+// the next line continues a method chain.
+func statement(lines []string, i int) (Statement, int) {
+	first := lines[i]
+	indent := first[:len(first)-len(strings.TrimLeft(first, " \t"))]
+	s := Statement{Line: i + 1}
+	var sc scanner
+	var code []string
+	for ; i < len(lines); i++ {
+		line := strings.TrimPrefix(strings.TrimRight(lines[i], " \t\r"), indent)
+		code = append(code, line)
+		sc.scan(line)
+		if sc.depth > 0 || sc.quote != 0 {
+			continue
+		}
+		if i+1 < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i+1]), ".") {
+			continue
+		}
+		i++
+		break
+	}
+	s.Code = strings.TrimSpace(strings.Join(code, "\n"))
+	return s, i
+}
+
+// scanner tracks the brackets and strings of lines of JavaScript.
+type scanner struct {
+	depth int
+	// quote is the quote of the string the last line ends in, only ever a
+	// template literal since other strings end with their line.
+	quote byte
+}
+
+func (s *scanner) scan(line string) {
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		if s.quote != 0 {
+			switch c {
+			case '\\':
+				i++
+			case s.quote:
+				s.quote = 0
+			}
+			continue
+		}
+		switch c {
+		case '\'', '"', '`':
+			s.quote = c
+		case '/':
+			if i+1 < len(line) && line[i+1] == '/' {
+				return
+			}
+		case '(', '[', '{':
+			s.depth++
+		case ')', ']', '}':
+			s.depth--
+		}
+	}
+	if s.quote != '`' {
+		s.quote = 0
+	}
+}
+
+// fixtures returns the names destructured in "page, context: ctx".
+func fixtures(list string) []string {
+	var names []string
+	for _, f := range strings.Split(list, ",") {
+		name, _, _ := strings.Cut(f, ":")
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
@@ -0,0 +1,83 @@
+package playwright
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const source = `import { test, expect } from '@playwright/test';
+import { LoginPage } from './pages/login';
+
+const BASE_URL = 'https://quickpizza.grafana.com';
+
+test.beforeEach(async ({ page }) => {
+  await page.goto(BASE_URL);
+});
+
+test('has title', async ({ page }) => {
+  await expect(page).toHaveTitle(/QuickPizza/);
+});
+
+test.describe('pizza', () => {
+  test.afterEach(async ({ page }) => {
+    await page.screenshot({ path: 'pizza.png' });
+  });
+
+  test.skip('is delicious', async ({ page, context }) => {
+    await context.clearCookies();
+  });
+
+  test('recommends a pizza', { tag: '@fast' }, async ({ page }) => {
+    await test.step('ask', async () => {
+      await page
+        .getByRole('button', { name: 'Pizza, Please!' })
+        .click();
+    });
+    const text = await page.locator('#recommendations').textContent();
+    expect(text).toContain('Our recommendation');
+  });
+});
+`
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	suite, err := Parse(source)
+	require.NoError(t, err)
+	assert.Equal(t, []Statement{{Line: 2, Code: "import { LoginPage } from './pages/login';"}}, suite.Imports)
+	assert.Equal(t, []Statement{{Line: 4, Code: "const BASE_URL = 'https://quickpizza.grafana.com';"}}, suite.Other)
+	require.Len(t, suite.Tests, 3)
+
+	first := suite.Tests[0]
+	assert.Equal(t, "has title", first.Title)
+	assert.Equal(t, 10, first.Line)
+	assert.Equal(t, []string{"page"}, first.Fixtures)
+	assert.Equal(t, []Statement{{Line: 7, Code: "await page.goto(BASE_URL);"}}, first.Before)
+	assert.Empty(t, first.After)
+	assert.Equal(t, []Statement{{Line: 11, Code: "await expect(page).toHaveTitle(/QuickPizza/);"}}, first.Body)
+
+	skipped := suite.Tests[1]
+	assert.Equal(t, "pizza > is delicious", skipped.Title)
+	assert.Equal(t, ModeSkip, skipped.Mode)
+	assert.Equal(t, []string{"page", "context"}, skipped.Fixtures)
+
+	last := suite.Tests[2]
+	assert.Empty(t, last.Mode)
+	assert.Len(t, last.Before, 1)
+	assert.Equal(t, []Statement{{Line: 16, Code: "await page.screenshot({ path: 'pizza.png' });"}}, last.After)
+	assert.Equal(t, []Statement{
+		{Line: 24, Code: "// ask"},
+		{Line: 25, Code: "await page\n  .getByRole('button', { name: 'Pizza, Please!' })\n  .click();"},
+		{Line: 29, Code: "const text = await page.locator('#recommendations').textContent();"},
+		{Line: 30, Code: "expect(text).toContain('Our recommendation');"},
+	}, last.Body)
+}
+
+func TestParseNoTests(t *testing.T) {
+	t.Parallel()
+
+	_, err := Parse("import { chromium } from 'playwright';\n")
+	assert.ErrorIs(t, err, ErrNoTests)
+}
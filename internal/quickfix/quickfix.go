@@ -0,0 +1,286 @@
+// Package quickfix suggests edits repairing the common problems of k6
+// scripts: unknown modules, a missing default export, await outside async
+// functions and options fields k6 ignores. Edits are unified diffs, as
+// apply_patch takes them.
+package quickfix
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/grafana/mcp-k6/internal/diagnostics"
+	"github.com/grafana/mcp-k6/internal/diff"
+	"github.com/grafana/mcp-k6/internal/fuzzy"
+	"github.com/grafana/mcp-k6/internal/scriptinfo"
+)
+
+// Kinds of fixes.
+const (
+	KindUnknownModule  = "unknown_module"
+	KindMissingDefault = "missing_default_export"
+	KindAwait          = "await_outside_async"
+	KindUnknownOption  = "unknown_option"
+)
+
+// patchName names the script in the headers of patches.
+const patchName = "script.js"
+
+// Fix is a suggested repair of a script.
+type Fix struct {
+	Kind        string `json:"kind"`
+	Line        int    `json:"line,omitempty"`
+	Description string `json:"description"`
+	// Patch is a unified diff of the script, empty when no edit can be
+	// suggested safely and the description says what to change.
+	Patch string `json:"patch,omitempty"`
+}
+
+//nolint:gochecknoglobals // Read-only lookup tables.
+var (
+	// modules are the modules k6 provides.
+	modules = []string{
+		"k6", "k6/http", "k6/metrics", "k6/crypto", "k6/encoding", "k6/html", "k6/ws", "k6/websockets",
+		"k6/net/grpc", "k6/data", "k6/execution", "k6/timers", "k6/browser", "k6/secrets",
+		"k6/experimental/redis", "k6/experimental/streams", "k6/experimental/fs", "k6/experimental/csv",
+	}
+	// movedModules maps modules k6 no longer provides to their replacement.
+	movedModules = map[string]string{
+		"k6/experimental/browser":    "k6/browser",
+		"k6/experimental/websockets": "k6/websockets",
+		"k6/experimental/timers":     "k6/timers",
+		"k6/experimental/grpc":       "k6/net/grpc",
+		"k6/grpc":                    "k6/net/grpc",
+	}
+	// options are the fields of the options object k6 reads.
+	options = []string{
+		"batch", "batchPerHost", "blacklistIPs", "blockHostnames", "cloud", "discardResponseBodies", "dns",
+		"duration", "executionSegment", "executionSegmentSequence", "ext", "hosts", "httpDebug",
+		"insecureSkipTLSVerify", "iterations", "localIPs", "maxRedirects", "minIterationDuration",
+		"noConnectionReuse", "noCookiesReset", "noSetup", "noSummary", "noTeardown", "noThresholds",
+		"noVUConnectionReuse", "paused", "rps", "scenarios", "setupTimeout", "stages", "summaryTimeUnit",
+		"summaryTrendStats", "systemTags", "tags", "teardownTimeout", "thresholds", "throw", "tlsAuth",
+		"tlsCipherSuites", "tlsVersion", "userAgent", "vus",
+	}
+)
+
+//nolint:gochecknoglobals // Compiled once, read-only.
+var (
+	reUnknownModule = regexp.MustCompile(`unknown module: ([\w$@./:-]+)`)
+	reNoDefault     = regexp.MustCompile(`function 'default' not found in exports|no exported functions in script`)
+	reAwait         = regexp.MustCompile(`\bawait\b`)
+	// Headers of the functions an await may be in, ending at their body.
+	reFunction = regexp.MustCompile(`\bfunction\b\s*\*?\s*[\w$]*\s*\([^()]*\)\s*$`)
+	reArrow    = regexp.MustCompile(`(?:\([^()]*\)|[\w$]+)\s*=>\s*$`)
+	reMethod   = regexp.MustCompile(`(?:^|[\s,{])([\w$]+)\s*\([^()]*\)\s*$`)
+	reAsync    = regexp.MustCompile(`\basync\s*$`)
+	// Keywords whose blocks look like methods: "if (x) {".
+	controlKeywords = []string{"if", "for", "while", "switch", "catch", "with"}
+)
+
+// Suggest returns the fixes of script, for the problems k6 reported in output
+// and the diagnostics located in the script, and for the options fields k6
+// ignores, which it does not report.
+func Suggest(script, output string, located []diagnostics.Diagnostic) []Fix {
+	info := scriptinfo.Analyze(script)
+	var fixes []Fix
+	seen := make(map[string]bool)
+	for _, m := range reUnknownModule.FindAllStringSubmatch(output, -1) {
+		if module := strings.TrimRight(m[1], ".:"); !seen[module] {
+			seen[module] = true
+			fixes = append(fixes, unknownModule(script, info, module))
+		}
+	}
+	if reNoDefault.MatchString(output) && !slices.Contains(info.Lifecycle, "default") {
+		fixes = append(fixes, missingDefault(script, info))
+	}
+	for _, d := range located {
+		if fix, ok := awaitOutsideAsync(script, d); ok && !seen[fix.Patch] {
+			seen[fix.Patch] = true
+			fixes = append(fixes, fix)
+		}
+	}
+	return append(fixes, unknownOptions(script, info)...)
+}
+
+func unknownModule(script string, info *scriptinfo.Info, module string) Fix {
+	fix := Fix{Kind: KindUnknownModule}
+	for _, imp := range info.Imports {
+		if imp.Module == module {
+			fix.Line = imp.Line
+			break
+		}
+	}
+
+	replacement, moved := movedModules[module]
+	switch {
+	case moved:
+		fix.Description = fmt.Sprintf("%s is now %s", module, replacement)
+	case strings.HasPrefix(module, "k6/x/"):
+		fix.Description = fmt.Sprintf(
+			"%s is provided by a k6 extension: run the script with a k6 binary built with it using xk6", module)
+		return fix
+	default:
+		var ok bool
+		if replacement, ok = fuzzy.Closest(modules, module, 3); !ok {
+			fix.Description = fmt.Sprintf("k6 provides no module %s; import a local file or a URL instead", module)
+			return fix
+		}
+		fix.Description = fmt.Sprintf("k6 provides no module %s; did you mean %s?", module, replacement)
+	}
+	if fix.Line > 0 {
+		fix.Patch = replaceLine(script, fix.Line, func(line string) string {
+			return strings.NewReplacer("'"+module+"'", "'"+replacement+"'", `"`+module+`"`, `"`+replacement+`"`).
+				Replace(line)
+		})
+	}
+	return fix
+}
+
+// missingDefault exports the only function of a script without scenarios as
+// the default, as its author meant it, or else adds a default function.
+func missingDefault(script string, info *scriptinfo.Info) Fix {
+	fix := Fix{Kind: KindMissingDefault}
+	var candidates []string
+	for _, name := range info.Exports {
+		if !slices.Contains(info.Lifecycle, name) {
+			candidates = append(candidates, name)
+		}
+	}
+	if len(candidates) == 1 && len(info.Scenarios) == 0 {
+		name := regexp.QuoteMeta(candidates[0])
+		re := regexp.MustCompile(`(?m)^([ \t]*export\s+)((?:async\s+)?function\s*` + name + `\s*\()`)
+		if loc := re.FindStringIndex(script); loc != nil {
+			fix.Line = strings.Count(script[:loc[0]], "\n") + 1
+			fix.Description = fmt.Sprintf("k6 runs the default export of a script: export %s as the default", candidates[0])
+			fix.Patch = unified(script, re.ReplaceAllString(script, "${1}default ${2}"))
+			return fix
+		}
+	}
+	fix.Description = "k6 runs the default export of a script: add the code each VU iteration runs as the default function"
+	stub := "\nexport default function () {\n  // The code each VU runs in a loop\n}\n"
+	fix.Patch = unified(script, strings.TrimRight(script, "\n")+"\n"+stub)
+	return fix
+}
+
+// awaitOutsideAsync makes the function an await of a syntax error is in
+// async.
+func awaitOutsideAsync(script string, d diagnostics.Diagnostic) (Fix, bool) {
+	lines := strings.SplitAfter(script, "\n")
+	if d.Line < 1 || d.Line > len(lines) || !reAwait.MatchString(lines[d.Line-1]) ||
+		d.Kind != "SyntaxError" && !strings.Contains(d.Message, "await") {
+		return Fix{}, false
+	}
+	end := len(strings.Join(lines[:d.Line-1], ""))
+	if d.Column > 0 {
+		end += min(d.Column-1, len(lines[d.Line-1]))
+	} else {
+		end += len(lines[d.Line-1])
+	}
+
+	at, ok := enclosingFunction(script[:end])
+	if ok && reAsync.MatchString(script[:at]) {
+		// The function is async already, so the error is about something else
+		return Fix{}, false
+	}
+	if !ok {
+		return Fix{
+			Kind: KindAwait,
+			Line: d.Line,
+			Description: "await is only allowed in async functions: move the code into an async function, " +
+				"such as export default async function or async setup",
+		}, true
+	}
+	return Fix{
+		Kind:        KindAwait,
+		Line:        strings.Count(script[:at], "\n") + 1,
+		Description: "await is only allowed in async functions: make the enclosing function async",
+		Patch:       unified(script, script[:at]+"async "+script[at:]),
+	}, true
+}
+
+// enclosingFunction returns the offset to insert "async " at to make the
+// innermost function around the end of src async.
+func enclosingFunction(src string) (int, bool) {
+	depth := 0
+	for i := len(src) - 1; i >= 0; i-- {
+		switch src[i] {
+		case '}':
+			depth++
+		case '{':
+			if depth > 0 {
+				depth--
+				continue
+			}
+			header := src[:i]
+			if loc := reFunction.FindStringIndex(header); loc != nil {
+				return loc[0], true
+			}
+			if loc := reArrow.FindStringIndex(header); loc != nil {
+				return loc[0], true
+			}
+			if m := reMethod.FindStringSubmatchIndex(header); m != nil &&
+				!slices.Contains(controlKeywords, header[m[2]:m[3]]) {
+				return m[2], true
+			}
+		}
+	}
+	return 0, false
+}
+
+// unknownOptions renames the options fields k6 ignores that are close to the
+// name of one it reads.
+func unknownOptions(script string, info *scriptinfo.Info) []Fix {
+	if info.Options == nil {
+		return nil
+	}
+	var fixes []Fix
+	for _, key := range info.Options.OtherKeys {
+		if slices.Contains(options, key) {
+			continue
+		}
+		name, ok := "", false
+		for _, o := range options {
+			if strings.EqualFold(o, key) {
+				name, ok = o, true
+			}
+		}
+		if !ok {
+			name, ok = fuzzy.Closest(options, key, max(2, len(key)/4))
+		}
+		if !ok {
+			continue
+		}
+
+		fix := Fix{
+			Kind:        KindUnknownOption,
+			Description: fmt.Sprintf("k6 ignores the options field %q; did you mean %q?", key, name),
+		}
+		re := regexp.MustCompile(`(^|[\s,{])(['"]?)` + regexp.QuoteMeta(key) + `(['"]?\s*:)`)
+		lines := strings.SplitAfter(script, "\n")
+		for n := info.Options.Line; n <= len(lines); n++ {
+			if re.MatchString(lines[n-1]) {
+				fix.Line = n
+				fix.Patch = replaceLine(script, n, func(line string) string {
+					return re.ReplaceAllString(line, "${1}${2}"+name+"${3}")
+				})
+				break
+			}
+		}
+		fixes = append(fixes, fix)
+	}
+	return fixes
+}
+
+// replaceLine returns a patch of script replacing line n with edit of it.
+func replaceLine(script string, n int, edit func(string) string) string {
+	lines := strings.SplitAfter(script, "\n")
+	lines[n-1] = edit(lines[n-1])
+	return unified(script, strings.Join(lines, ""))
+}
+
+func unified(from, to string) string {
+	patch, _ := diff.Unified("a/"+patchName, "b/"+patchName, from, to, diff.DefaultContext)
+	return patch
+}
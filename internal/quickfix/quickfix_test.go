@@ -0,0 +1,114 @@
+package quickfix
+
+import (
+	"testing"
+
+	"github.com/grafana/mcp-k6/internal/diagnostics"
+	"github.com/grafana/mcp-k6/internal/diff"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// apply returns script with the patch of fix applied.
+func apply(t *testing.T, script string, fix Fix) string {
+	t.Helper()
+	require.NotEmpty(t, fix.Patch, fix.Description)
+	patched, _, err := diff.Apply(script, fix.Patch)
+	require.NoError(t, err)
+	return patched
+}
+
+func TestSuggestUnknownModule(t *testing.T) {
+	t.Parallel()
+
+	script := "import http from 'k6/htpp';\nimport { browser } from \"k6/experimental/browser\";\n" +
+		"import sql from 'k6/x/sql';\n\nexport default function () {}\n"
+	output := `{"level":"error","msg":"GoError: unknown module: k6/htpp\n\tat file:///t.js:1:1(0)"}` + "\n" +
+		"unknown module: k6/experimental/browser\nunknown module: k6/x/sql\nunknown module: k6/htpp"
+
+	fixes := Suggest(script, output, nil)
+	require.Len(t, fixes, 3)
+	assert.Equal(t, KindUnknownModule, fixes[0].Kind)
+	assert.Equal(t, 1, fixes[0].Line)
+	assert.Contains(t, fixes[0].Description, "did you mean k6/http?")
+	assert.Contains(t, apply(t, script, fixes[0]), "import http from 'k6/http';\n")
+	assert.Contains(t, apply(t, script, fixes[1]), `import { browser } from "k6/browser";`)
+	assert.Equal(t, 3, fixes[2].Line)
+	assert.Contains(t, fixes[2].Description, "xk6")
+	assert.Empty(t, fixes[2].Patch)
+}
+
+func TestSuggestMissingDefault(t *testing.T) {
+	t.Parallel()
+
+	output := "function 'default' not found in exports"
+
+	// The only exported function becomes the default
+	script := "import http from 'k6/http';\n\nexport async function main() {\n  http.get('https://test.k6.io');\n}\n"
+	fixes := Suggest(script, output, nil)
+	require.Len(t, fixes, 1)
+	assert.Equal(t, KindMissingDefault, fixes[0].Kind)
+	assert.Equal(t, 3, fixes[0].Line)
+	assert.Contains(t, apply(t, script, fixes[0]), "export default async function main() {")
+
+	// Otherwise a default function is added
+	script = "export function setup() {}\nexport function a() {}\nexport function b() {}\n"
+	fixes = Suggest(script, output, nil)
+	require.Len(t, fixes, 1)
+	assert.Contains(t, apply(t, script, fixes[0]), "export function b() {}\n\nexport default function () {\n")
+
+	// Scripts with a default function are not changed
+	assert.Empty(t, Suggest("export default () => {};\n", output, nil))
+}
+
+func TestSuggestAwait(t *testing.T) {
+	t.Parallel()
+
+	script := "import http from 'k6/http';\n\nexport default function () {\n  if (true) {\n" +
+		"    const res = await http.asyncRequest('GET', 'https://test.k6.io');\n  }\n}\n\n" +
+		"export function teardown() {\n  const f = (x) => {\n    await x;\n  };\n}\n\n" +
+		"export async function setup() {\n  await 1;\n}\n"
+	located := []diagnostics.Diagnostic{
+		{Line: 5, Column: 17, Kind: "SyntaxError", Message: "Unexpected identifier"},
+		{Line: 11, Column: 5, Kind: "SyntaxError", Message: "Unexpected identifier"},
+		// Already in an async function
+		{Line: 16, Column: 3, Kind: "SyntaxError", Message: "Unexpected number"},
+		// Not an await
+		{Line: 1, Column: 1, Kind: "SyntaxError", Message: "Unexpected token"},
+	}
+
+	fixes := Suggest(script, "", located)
+	require.Len(t, fixes, 2)
+	assert.Equal(t, KindAwait, fixes[0].Kind)
+	assert.Equal(t, 3, fixes[0].Line)
+	assert.Contains(t, apply(t, script, fixes[0]), "export default async function () {\n")
+	assert.Equal(t, 10, fixes[1].Line)
+	assert.Contains(t, apply(t, script, fixes[1]), "const f = async (x) => {\n")
+
+	// A top-level await gets a description only
+	fixes = Suggest("const data = await fetchData();\n", "", []diagnostics.Diagnostic{
+		{Line: 1, Column: 14, Kind: "SyntaxError", Message: "Unexpected identifier"},
+	})
+	require.Len(t, fixes, 1)
+	assert.Empty(t, fixes[0].Patch)
+	assert.Contains(t, fixes[0].Description, "async function")
+}
+
+func TestSuggestUnknownOptions(t *testing.T) {
+	t.Parallel()
+
+	script := "export const options = {\n  VUs: 10,\n  duration: '1m',\n  threshold: {\n" +
+		"    http_req_failed: ['rate<0.01'],\n  },\n  'userAgnt': 'k6',\n  somethingElse: true,\n};\n" +
+		"\nexport default function () {}\n"
+
+	fixes := Suggest(script, "", nil)
+	require.Len(t, fixes, 3)
+	for _, fix := range fixes {
+		assert.Equal(t, KindUnknownOption, fix.Kind)
+	}
+	assert.Equal(t, 2, fixes[0].Line)
+	assert.Contains(t, apply(t, script, fixes[0]), "  vus: 10,\n")
+	assert.Contains(t, fixes[1].Description, `did you mean "thresholds"?`)
+	assert.Contains(t, apply(t, script, fixes[1]), "  thresholds: {\n")
+	assert.Contains(t, apply(t, script, fixes[2]), "  'userAgent': 'k6',\n")
+}
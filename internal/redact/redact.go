@@ -0,0 +1,151 @@
+// Package redact masks credentials in captured HTTP traffic and other text
+// before it is returned to the model.
+package redact
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Mask replaces every redacted value.
+const Mask = "[REDACTED]"
+
+// defaultHeaders are always redacted, in canonical form.
+//
+//nolint:gochecknoglobals // Read-only lookup table.
+var defaultHeaders = []string{
+	"Authorization",
+	"Proxy-Authorization",
+	"Cookie",
+	"Set-Cookie",
+	"X-Api-Key",
+	"X-Auth-Token",
+	"X-Csrf-Token",
+	"X-Xsrf-Token",
+}
+
+// defaultPatterns mask credential-looking fields in JSON bodies, form bodies
+// and query strings. The first capture group is the part that is masked.
+//
+//nolint:gochecknoglobals // Read-only lookup table.
+var defaultPatterns = []string{
+	`(?i)"(?:password|passwd|secret|client_secret|token|access_token|refresh_token|id_token|api_?key)"\s*:\s*"([^"]*)"`,
+	`(?i)(?:^|[?&\s])(?:password|passwd|secret|client_secret|token|access_token|refresh_token|id_token|api_?key)=([^&\s]*)`,
+}
+
+// Redactor masks sensitive headers and text matching a set of patterns.
+// A nil *Redactor behaves like Default().
+type Redactor struct {
+	headers  map[string]bool
+	patterns []*regexp.Regexp
+}
+
+// New returns a Redactor that masks the default headers and patterns plus the
+// given header names and regular expressions. When a pattern has a capture
+// group only the first group is masked, otherwise the whole match is.
+func New(headers, patterns []string) (*Redactor, error) {
+	r := &Redactor{headers: make(map[string]bool)}
+	for _, h := range append(append([]string{}, defaultHeaders...), headers...) {
+		r.headers[http.CanonicalHeaderKey(strings.TrimSpace(h))] = true
+	}
+	for _, p := range append(append([]string{}, defaultPatterns...), patterns...) {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction pattern %q: %w", p, err)
+		}
+		r.patterns = append(r.patterns, re)
+	}
+	return r, nil
+}
+
+// Default returns a Redactor with only the built-in rules.
+func Default() *Redactor {
+	return defaultRedactor()
+}
+
+//nolint:gochecknoglobals // Built once on first use.
+var defaultRedactor = sync.OnceValue(func() *Redactor {
+	r, err := New(nil, nil)
+	if err != nil {
+		panic(err) // the built-in patterns are constants
+	}
+	return r
+})
+
+// Header returns the value to show for the named header. Authorization
+// schemes and cookie names are kept so the traffic stays readable.
+func (r *Redactor) Header(name, value string) string {
+	if r == nil {
+		r = Default()
+	}
+	canonical := http.CanonicalHeaderKey(strings.TrimSpace(name))
+	if !r.headers[canonical] {
+		return r.String(value)
+	}
+
+	switch canonical {
+	case "Authorization", "Proxy-Authorization":
+		if scheme, _, ok := strings.Cut(value, " "); ok {
+			return scheme + " " + Mask
+		}
+	case "Cookie":
+		cookies := strings.Split(value, ";")
+		for i, c := range cookies {
+			cookies[i] = maskCookie(c)
+		}
+		return strings.Join(cookies, ";")
+	case "Set-Cookie":
+		cookie, attributes, ok := strings.Cut(value, ";")
+		if ok {
+			return maskCookie(cookie) + ";" + attributes
+		}
+		return maskCookie(cookie)
+	}
+	return Mask
+}
+
+// String masks every pattern match in s.
+func (r *Redactor) String(s string) string {
+	if r == nil {
+		r = Default()
+	}
+	for _, re := range r.patterns {
+		if re.NumSubexp() == 0 {
+			s = re.ReplaceAllString(s, Mask)
+			continue
+		}
+		s = replaceGroup(re, s)
+	}
+	return s
+}
+
+// replaceGroup masks the first capture group of each match of re in s.
+func replaceGroup(re *regexp.Regexp, s string) string {
+	var b strings.Builder
+	last := 0
+	for _, m := range re.FindAllStringSubmatchIndex(s, -1) {
+		start, end := m[2], m[3]
+		if start < 0 || start == end {
+			continue
+		}
+		b.WriteString(s[last:start])
+		b.WriteString(Mask)
+		last = end
+	}
+	if last == 0 {
+		return s
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
+
+func maskCookie(cookie string) string {
+	name, _, ok := strings.Cut(cookie, "=")
+	if !ok {
+		return cookie
+	}
+	return name + "=" + Mask
+}
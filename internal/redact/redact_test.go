@@ -0,0 +1,62 @@
+package redact
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHeader(t *testing.T) {
+	t.Parallel()
+
+	r, err := New([]string{"x-tenant-id"}, nil)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name, value, want string
+	}{
+		{"Authorization", "Bearer abc.def", "Bearer [REDACTED]"},
+		{"authorization", "opaque", "[REDACTED]"},
+		{"Cookie", "session=abc; theme=dark", "session=[REDACTED]; theme=[REDACTED]"},
+		{"Set-Cookie", "session=abc; Path=/; HttpOnly", "session=[REDACTED]; Path=/; HttpOnly"},
+		{"X-Tenant-Id", "acme", "[REDACTED]"},
+		{"Content-Type", "application/json", "application/json"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, r.Header(tt.name, tt.value), tt.name)
+	}
+}
+
+func TestString(t *testing.T) {
+	t.Parallel()
+
+	r, err := New(nil, []string{`card=\d+`, `ssn: (\d{3}-\d{2}-\d{4})`})
+	require.NoError(t, err)
+
+	assert.Equal(t,
+		`{"user":"admin","password":"[REDACTED]","access_token": "[REDACTED]"}`,
+		r.String(`{"user":"admin","password":"hunter2","access_token": "xyz"}`))
+	assert.Equal(t,
+		"GET /callback?code=1&token=[REDACTED] HTTP/1.1",
+		r.String("GET /callback?code=1&token=s3cr3t HTTP/1.1"))
+	assert.Equal(t, "username=bob&password=[REDACTED]", r.String("username=bob&password=pw"))
+	assert.Equal(t, "pay [REDACTED] now", r.String("pay card=4111111111111111 now"))
+	assert.Equal(t, "ssn: [REDACTED]", r.String("ssn: 123-45-6789"))
+	assert.Equal(t, "nothing to hide", r.String("nothing to hide"))
+}
+
+func TestNilRedactorUsesDefaults(t *testing.T) {
+	t.Parallel()
+
+	var r *Redactor
+	assert.Equal(t, "Basic [REDACTED]", r.Header("Authorization", "Basic dXNlcjpwYXNz"))
+	assert.Equal(t, `{"token":"[REDACTED]"}`, r.String(`{"token":"abc"}`))
+}
+
+func TestNewRejectsInvalidPatterns(t *testing.T) {
+	t.Parallel()
+
+	_, err := New(nil, []string{"("})
+	require.Error(t, err)
+}
@@ -0,0 +1,179 @@
+// Package releasenotes reads the k6 release notes of the documentation and
+// sorts their entries into new features, breaking changes and deprecations.
+package releasenotes
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/xk6-docs/docs"
+)
+
+// sectionPrefix is the slug segment of the release notes.
+const sectionPrefix = "release-notes/"
+
+// maxEntry bounds the length of an entry.
+const maxEntry = 200
+
+//nolint:gochecknoglobals // Compiled once, read-only.
+var (
+	// reVersion matches a release as written in slugs and titles: "v1-4-0",
+	// "v1.4.0" or "1.4.0".
+	reVersion = regexp.MustCompile(`v?(\d+)[.-](\d+)[.-](\d+)`)
+	// reHeading matches a markdown heading and its level.
+	reHeading = regexp.MustCompile(`^(#{1,4})\s+(.+?)\s*#*\s*$`)
+	// reBullet matches a top-level list item.
+	reBullet = regexp.MustCompile(`^[-*]\s+(.+)$`)
+	// reLink matches a markdown link, whose text is kept.
+	reLink = regexp.MustCompile(`\[([^\]]*)\]\([^)]*\)`)
+)
+
+// Version is a k6 release: major, minor and patch.
+type Version [3]int
+
+// ParseVersion parses the first release in s, such as "v1.4.0", "1.4.0" or
+// the "v1-4-0" of a slug.
+func ParseVersion(s string) (Version, bool) {
+	m := reVersion.FindStringSubmatch(s)
+	if m == nil {
+		return Version{}, false
+	}
+	var v Version
+	for i := range v {
+		v[i], _ = strconv.Atoi(m[i+1])
+	}
+	return v, true
+}
+
+func (v Version) String() string {
+	return fmt.Sprintf("v%d.%d.%d", v[0], v[1], v[2])
+}
+
+// Compare returns -1, 0 or 1 as v is older than, the same as or newer than
+// o.
+func (v Version) Compare(o Version) int {
+	for i := range v {
+		switch {
+		case v[i] < o[i]:
+			return -1
+		case v[i] > o[i]:
+			return 1
+		}
+	}
+	return 0
+}
+
+// Release holds the entries of the release notes of a version.
+type Release struct {
+	Version string `json:"version"`
+	// Slug is the section of the release notes, for get_documentation.
+	Slug            string   `json:"slug"`
+	Features        []string `json:"features,omitempty"`
+	BreakingChanges []string `json:"breaking_changes,omitempty"`
+	Deprecations    []string `json:"deprecations,omitempty"`
+}
+
+// Note is a release notes section of a documentation index.
+type Note struct {
+	Version Version
+	Slug    string
+}
+
+// Notes returns the release notes sections of idx, oldest first.
+func Notes(idx *docs.Index) []Note {
+	var notes []Note
+	for i := range idx.Sections {
+		sec := &idx.Sections[i]
+		at := strings.Index(sec.Slug, sectionPrefix)
+		if at < 0 || sec.IsIndex {
+			continue
+		}
+		v, ok := ParseVersion(sec.Slug[at+len(sectionPrefix):])
+		if !ok {
+			if v, ok = ParseVersion(sec.Title); !ok {
+				continue
+			}
+		}
+		notes = append(notes, Note{Version: v, Slug: sec.Slug})
+	}
+	sort.Slice(notes, func(i, j int) bool { return notes[i].Version.Compare(notes[j].Version) < 0 })
+	return notes
+}
+
+// Kinds of entries.
+const (
+	feature     = "feature"
+	breaking    = "breaking"
+	deprecation = "deprecation"
+)
+
+// Parse sorts the entries of the release notes content of note. Entries are
+// the "###" headings under a "## New features", "## Breaking changes" or
+// "## Deprecations" heading, or its list items when it has no such heading.
+func Parse(note Note, content string) Release {
+	r := Release{Version: note.Version.String(), Slug: note.Slug}
+	kind, inEntry := "", false
+	add := func(entry string) {
+		entry = clean(entry)
+		switch kind {
+		case feature:
+			r.Features = append(r.Features, entry)
+		case breaking:
+			r.BreakingChanges = append(r.BreakingChanges, entry)
+		case deprecation:
+			r.Deprecations = append(r.Deprecations, entry)
+		}
+	}
+
+	for _, line := range strings.Split(content, "\n") {
+		if m := reHeading.FindStringSubmatch(line); m != nil {
+			level, title := len(m[1]), m[2]
+			switch {
+			case level == 2:
+				kind, inEntry = kindOf(title), false
+			case level == 3 && kindOf(title) != "":
+				// "### Deprecations" under "## Roadmap"
+				kind, inEntry = kindOf(title), false
+			case level == 3 && kind != "":
+				add(title)
+				inEntry = true
+			}
+			continue
+		}
+		if m := reBullet.FindStringSubmatch(line); m != nil && kind != "" && !inEntry {
+			add(m[1])
+		}
+	}
+	return r
+}
+
+func kindOf(heading string) string {
+	h := strings.ToLower(heading)
+	switch {
+	case strings.Contains(h, "breaking"):
+		return breaking
+	case strings.Contains(h, "deprecat"):
+		return deprecation
+	case strings.Contains(h, "new feature"), h == "features":
+		return feature
+	default:
+		return ""
+	}
+}
+
+// clean returns an entry on one line without link targets, bounded to
+// maxEntry bytes.
+func clean(entry string) string {
+	entry = strings.TrimSpace(reLink.ReplaceAllString(entry, "$1"))
+	if len(entry) > maxEntry {
+		cut := strings.LastIndexByte(entry[:maxEntry], ' ')
+		if cut <= 0 {
+			cut = maxEntry
+		}
+		entry = entry[:cut] + "…"
+	}
+	return entry
+}
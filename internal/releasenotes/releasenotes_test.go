@@ -0,0 +1,82 @@
+package releasenotes
+
+import (
+	"testing"
+
+	"github.com/grafana/xk6-docs/docs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const notes = `# Version 1.2.0 release notes
+
+k6 v1.2.0 is here 🎉!
+
+## Breaking changes
+
+- [#4886](https://github.com/grafana/k6/pull/4886) removes the ` + "`k6/experimental/tracing`" + ` module.
+
+## New features
+
+### Secret sources [#4514](https://github.com/grafana/k6/pull/4514)
+
+Scripts read secrets with ` + "`k6/secrets`" + `:
+
+- from files
+- from environment variables
+
+### Automatic extension resolution
+
+## Bug fixes
+
+- [#4900](https://github.com/grafana/k6/pull/4900) fixes a panic.
+
+## Roadmap
+
+### Deprecations
+
+- The ` + "`externally-controlled`" + ` executor will be removed in v2.0.0.
+`
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	r := Parse(Note{Version: Version{1, 2, 0}, Slug: "release-notes/v1-2-0"}, notes)
+	assert.Equal(t, Release{
+		Version:         "v1.2.0",
+		Slug:            "release-notes/v1-2-0",
+		Features:        []string{"Secret sources #4514", "Automatic extension resolution"},
+		BreakingChanges: []string{"#4886 removes the `k6/experimental/tracing` module."},
+		Deprecations:    []string{"The `externally-controlled` executor will be removed in v2.0.0."},
+	}, r)
+}
+
+func TestNotes(t *testing.T) {
+	t.Parallel()
+
+	idx := &docs.Index{Sections: []docs.Section{
+		{Slug: "release-notes", Title: "Release notes", IsIndex: true},
+		{Slug: "release-notes/v1-2-0", Title: "Version 1.2.0 release notes"},
+		{Slug: "release-notes/v0-57-0", Title: "Version 0.57.0 release notes"},
+		{Slug: "release-notes/latest", Title: "Version 1.10.1 release notes"},
+		{Slug: "javascript-api/k6-http", Title: "k6/http"},
+	}}
+	assert.Equal(t, []Note{
+		{Version: Version{0, 57, 0}, Slug: "release-notes/v0-57-0"},
+		{Version: Version{1, 2, 0}, Slug: "release-notes/v1-2-0"},
+		{Version: Version{1, 10, 1}, Slug: "release-notes/latest"},
+	}, Notes(idx))
+}
+
+func TestParseVersion(t *testing.T) {
+	t.Parallel()
+
+	v, ok := ParseVersion("k6 v1.3.0 (commit/devel, go1.25.1, darwin/arm64)")
+	require.True(t, ok)
+	assert.Equal(t, "v1.3.0", v.String())
+	assert.Equal(t, -1, v.Compare(Version{1, 10, 0}))
+	assert.Equal(t, 1, v.Compare(Version{0, 57, 2}))
+
+	_, ok = ParseVersion("devel")
+	assert.False(t, ok)
+}
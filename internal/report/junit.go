@@ -0,0 +1,126 @@
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// JUnit suite names. Test cases are classed "thresholds.<metric>" and
+// "checks.<group>" so CI report viewers group them by metric and group.
+const (
+	runSuite       = "run"
+	thresholdSuite = "thresholds"
+	checkSuite     = "checks"
+)
+
+type junitSuites struct {
+	XMLName  xml.Name     `xml:"testsuites"`
+	Name     string       `xml:"name,attr"`
+	Tests    int          `xml:"tests,attr"`
+	Failures int          `xml:"failures,attr"`
+	Errors   int          `xml:"errors,attr"`
+	Time     string       `xml:"time,attr"`
+	Suites   []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Errors   int         `xml:"errors,attr"`
+	Time     string      `xml:"time,attr"`
+	Cases    []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	ClassName string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitProblem `xml:"failure,omitempty"`
+	Error     *junitProblem `xml:"error,omitempty"`
+}
+
+type junitProblem struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+}
+
+// JUnit renders the report as a JUnit XML document: a "run" suite telling
+// whether k6 completed, a "thresholds" suite with a test case per threshold
+// expression and a "checks" suite with a test case per check.
+func (r Report) JUnit() ([]byte, error) {
+	seconds := formatSeconds(r.Duration)
+	run := junitCase{ClassName: runSuite, Name: "k6 run completed", Time: seconds}
+	if r.Error != "" {
+		run.Error = &junitProblem{Message: r.Error, Type: "run"}
+	}
+
+	thresholds := make([]junitCase, 0, len(r.Thresholds))
+	for _, th := range r.Thresholds {
+		c := junitCase{ClassName: thresholdSuite + "." + th.Metric, Name: th.Expression, Time: "0"}
+		if c.Name == "" {
+			c.Name = th.Metric
+		}
+		if !th.Passed {
+			msg := "threshold crossed"
+			if th.Value != "" {
+				msg += ": " + th.Value
+			}
+			c.Failure = &junitProblem{Message: msg, Type: "threshold"}
+		}
+		thresholds = append(thresholds, c)
+	}
+
+	checks := make([]junitCase, 0, len(r.Checks))
+	for _, check := range r.Checks {
+		c := junitCase{ClassName: checkSuite, Name: check.Name, Time: "0"}
+		if check.Group != "" {
+			c.ClassName += "." + check.Group
+		}
+		if !check.Passed {
+			msg := "check failed"
+			if total := check.Passes + check.Fails; total > 0 {
+				msg = fmt.Sprintf("%d of %d failed", check.Fails, total)
+			}
+			c.Failure = &junitProblem{Message: msg, Type: "check"}
+		}
+		checks = append(checks, c)
+	}
+
+	doc := junitSuites{Name: "k6 " + strings.TrimSpace(r.Name), Time: seconds}
+	for _, s := range []junitSuite{
+		{Name: runSuite, Time: seconds, Cases: []junitCase{run}},
+		{Name: thresholdSuite, Time: "0", Cases: thresholds},
+		{Name: checkSuite, Time: "0", Cases: checks},
+	} {
+		if len(s.Cases) == 0 {
+			continue
+		}
+		for _, c := range s.Cases {
+			s.Tests++
+			if c.Failure != nil {
+				s.Failures++
+			}
+			if c.Error != nil {
+				s.Errors++
+			}
+		}
+		doc.Tests += s.Tests
+		doc.Failures += s.Failures
+		doc.Errors += s.Errors
+		doc.Suites = append(doc.Suites, s)
+	}
+
+	out, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode JUnit report: %w", err)
+	}
+	return append([]byte(xml.Header), append(out, '\n')...), nil
+}
+
+// formatSeconds formats d as the decimal seconds JUnit time attributes use.
+func formatSeconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
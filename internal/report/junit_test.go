@@ -0,0 +1,66 @@
+package report
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/mcp-k6/internal/summary"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJUnit(t *testing.T) {
+	t.Parallel()
+
+	r := Report{
+		Name:     "run-3 (api.js)",
+		Duration: 31500 * time.Millisecond,
+		Thresholds: []summary.Threshold{
+			{Metric: "http_req_duration", Expression: "p(95)<500", Value: "p(95)=512.3ms"},
+			{Metric: "http_req_failed", Expression: "rate<0.01", Value: "rate=0.00%", Passed: true},
+		},
+		Checks: []summary.Check{
+			{Name: "status is 200", Passed: true},
+			{Name: "order created", Group: "checkout", Passes: 8, Fails: 2},
+		},
+	}
+	out, err := r.JUnit()
+	require.NoError(t, err)
+
+	assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites name="k6 run-3 (api.js)" tests="5" failures="2" errors="0" time="31.500">
+  <testsuite name="run" tests="1" failures="0" errors="0" time="31.500">
+    <testcase classname="run" name="k6 run completed" time="31.500"></testcase>
+  </testsuite>
+  <testsuite name="thresholds" tests="2" failures="1" errors="0" time="0">
+    <testcase classname="thresholds.http_req_duration" name="p(95)&lt;500" time="0">
+      <failure message="threshold crossed: p(95)=512.3ms" type="threshold"></failure>
+    </testcase>
+    <testcase classname="thresholds.http_req_failed" name="rate&lt;0.01" time="0"></testcase>
+  </testsuite>
+  <testsuite name="checks" tests="2" failures="1" errors="0" time="0">
+    <testcase classname="checks" name="status is 200" time="0"></testcase>
+    <testcase classname="checks.checkout" name="order created" time="0">
+      <failure message="2 of 10 failed" type="check"></failure>
+    </testcase>
+  </testsuite>
+</testsuites>
+`, string(out))
+}
+
+func TestJUnitRunError(t *testing.T) {
+	t.Parallel()
+
+	r := Report{
+		Name:       "run-1",
+		Error:      "k6 test failed with exit code 107",
+		Thresholds: []summary.Threshold{{Metric: "checks"}},
+	}
+	out, err := r.JUnit()
+	require.NoError(t, err)
+
+	assert.Contains(t, string(out), `tests="2" failures="1" errors="1"`)
+	assert.Contains(t, string(out), `<error message="k6 test failed with exit code 107" type="run"></error>`)
+	assert.Contains(t, string(out), `<testcase classname="thresholds.checks" name="checks" time="0">`)
+	assert.NotContains(t, string(out), `name="checks" tests=`)
+}
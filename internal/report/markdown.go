@@ -0,0 +1,220 @@
+package report
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/mcp-k6/internal/summary"
+)
+
+// keyMetric is a metric value of the Markdown metrics table.
+type keyMetric struct {
+	// Names are the metric's names, newest k6 version first.
+	Names []string
+	Value string
+	Label string
+}
+
+// keyMetrics are the rows of the Markdown metrics table, when the run
+// reported them.
+//
+//nolint:gochecknoglobals // Read-only lookup table.
+var keyMetrics = []keyMetric{
+	{Names: []string{"http_req_duration"}, Value: "avg", Label: "http_req_duration avg"},
+	{Names: []string{"http_req_duration"}, Value: "p(95)", Label: "http_req_duration p(95)"},
+	{Names: []string{"http_req_failed"}, Value: "value", Label: "http_req_failed"},
+	{Names: []string{"http_reqs"}, Value: "rate", Label: "http_reqs"},
+	{Names: []string{"ws_connecting"}, Value: "avg", Label: "ws_connecting avg"},
+	{Names: []string{"ws_session_duration"}, Value: "avg", Label: "ws_session_duration avg"},
+	{Names: []string{"ws_msgs_received"}, Value: "rate", Label: "ws_msgs_received"},
+	{Names: []string{"grpc_req_duration"}, Value: "avg", Label: "grpc_req_duration avg"},
+	{Names: []string{"grpc_req_duration"}, Value: "p(95)", Label: "grpc_req_duration p(95)"},
+	{Names: []string{"iteration_duration"}, Value: "avg", Label: "iteration_duration avg"},
+	{Names: []string{"iterations"}, Value: "value", Label: "iterations"},
+	{Names: []string{"checks_succeeded", "checks"}, Value: "value", Label: "checks"},
+	{Names: []string{"vus_max"}, Value: "value", Label: "vus_max"},
+}
+
+// Markdown renders the report as a short Markdown summary for a
+// pull-request comment: the outcome, a table of key metrics, the thresholds
+// and the failed checks. With a baseline, the metrics table compares each
+// value to the baseline's.
+func (r Report) Markdown(baseline *Report) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "### k6 %s: %s\n\n", strings.TrimSpace(r.Name), r.outcome())
+	fmt.Fprintf(&b, "Ran for %s", r.Duration.Round(100*time.Millisecond))
+	if baseline != nil {
+		fmt.Fprintf(&b, ", compared to %s", strings.TrimSpace(baseline.Name))
+	}
+	b.WriteString(".\n")
+	if r.Error != "" {
+		fmt.Fprintf(&b, "\n> %s\n", strings.ReplaceAll(r.Error, "\n", "\n> "))
+	}
+
+	r.writeMetrics(&b, baseline)
+	r.writeThresholds(&b)
+	r.writeChecks(&b)
+	return b.String()
+}
+
+func (r Report) outcome() string {
+	failed := 0
+	for _, th := range r.Thresholds {
+		if !th.Passed {
+			failed++
+		}
+	}
+	switch {
+	case r.Error != "":
+		return "✗ did not complete"
+	case failed > 0:
+		return fmt.Sprintf("✗ %d of %d thresholds failed", failed, len(r.Thresholds))
+	case len(r.Thresholds) > 0:
+		return "✓ thresholds passed"
+	default:
+		return "✓ completed"
+	}
+}
+
+func (r Report) writeMetrics(b *strings.Builder, baseline *Report) {
+	var rows []string
+	for _, km := range keyMetrics {
+		value, ok := km.lookup(r.Metrics)
+		if !ok {
+			continue
+		}
+		if baseline == nil {
+			rows = append(rows, fmt.Sprintf("| %s | %s |", km.Label, value))
+			continue
+		}
+		base, ok := km.lookup(baseline.Metrics)
+		if !ok {
+			rows = append(rows, fmt.Sprintf("| %s | %s | – | – |", km.Label, value))
+			continue
+		}
+		rows = append(rows, fmt.Sprintf("| %s | %s | %s | %s |", km.Label, value, base, delta(value, base)))
+	}
+	if len(rows) == 0 {
+		return
+	}
+
+	b.WriteString("\n| Metric | Value |")
+	if baseline != nil {
+		b.WriteString(" Baseline | Change |\n|---|---:|---:|---:|\n")
+	} else {
+		b.WriteString("\n|---|---:|\n")
+	}
+	for _, row := range rows {
+		b.WriteString(row + "\n")
+	}
+}
+
+func (r Report) writeThresholds(b *strings.Builder) {
+	if len(r.Thresholds) == 0 {
+		return
+	}
+	b.WriteString("\n| | Threshold | Value |\n|---|---|---|\n")
+	for _, th := range r.Thresholds {
+		mark := "✓"
+		if !th.Passed {
+			mark = "✗"
+		}
+		name := strings.TrimSpace(th.Metric + " `" + th.Expression + "`")
+		if th.Expression == "" {
+			name = th.Metric
+		}
+		fmt.Fprintf(b, "| %s | %s | %s |\n", mark, escapeCell(name), escapeCell(th.Value))
+	}
+}
+
+func (r Report) writeChecks(b *strings.Builder) {
+	if len(r.Checks) == 0 {
+		return
+	}
+	var failed []summary.Check
+	for _, check := range r.Checks {
+		if !check.Passed {
+			failed = append(failed, check)
+		}
+	}
+	fmt.Fprintf(b, "\n%d of %d checks passed", len(r.Checks)-len(failed), len(r.Checks))
+	if len(failed) == 0 {
+		b.WriteString(".\n")
+		return
+	}
+	b.WriteString(". Failed:\n")
+	for _, check := range failed {
+		name := check.Name
+		if check.Group != "" {
+			name = check.Group + " › " + name
+		}
+		fmt.Fprintf(b, "- ✗ %s", name)
+		if total := check.Passes + check.Fails; total > 0 {
+			fmt.Fprintf(b, " (%d of %d failed)", check.Fails, total)
+		}
+		b.WriteString("\n")
+	}
+}
+
+// lookup returns the value of the key metric in metrics.
+func (km keyMetric) lookup(metrics []summary.Metric) (string, bool) {
+	for _, name := range km.Names {
+		for _, m := range metrics {
+			if m.Name != name {
+				continue
+			}
+			v, ok := m.Values[km.Value]
+			return v, ok
+		}
+	}
+	return "", false
+}
+
+// delta describes the change from base to value: in percentage points for
+// rates printed as percentages, otherwise relative to base.
+func delta(value, base string) string {
+	v, vPercent, ok := quantity(value)
+	bv, bPercent, bOK := quantity(base)
+	if !ok || !bOK || vPercent != bPercent {
+		return "–"
+	}
+	if vPercent {
+		return fmt.Sprintf("%+.2f pp", v-bv)
+	}
+	if bv == 0 {
+		if v == 0 {
+			return "0%"
+		}
+		return "–"
+	}
+	change := (v - bv) / bv * 100
+	if math.Abs(change) < 0.05 {
+		return "0%"
+	}
+	return fmt.Sprintf("%+.1f%%", change)
+}
+
+// quantity parses a summary value such as "3.1ms", "1m2s", "1.9/s" or
+// "0.50%", reporting whether it is a percentage. Durations are in seconds.
+func quantity(s string) (float64, bool, bool) {
+	if p, ok := strings.CutSuffix(s, "%"); ok {
+		v, err := strconv.ParseFloat(p, 64)
+		return v, true, err == nil
+	}
+	s = strings.TrimSuffix(s, "/s")
+	if v, err := strconv.ParseFloat(s, 64); err == nil {
+		return v, false, true
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return d.Seconds(), false, true
+	}
+	return 0, false, false
+}
+
+// escapeCell keeps text from breaking a Markdown table row.
+func escapeCell(s string) string {
+	return strings.NewReplacer("|", `\|`, "\n", " ").Replace(s)
+}
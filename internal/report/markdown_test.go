@@ -0,0 +1,97 @@
+package report
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/mcp-k6/internal/summary"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarkdown(t *testing.T) {
+	t.Parallel()
+
+	r := Report{
+		Name:     "run-3 (api.js)",
+		Duration: 31460 * time.Millisecond,
+		Thresholds: []summary.Threshold{
+			{Metric: "http_req_duration", Expression: "p(95)<500", Value: "p(95)=512.3ms"},
+			{Metric: "http_req_failed", Expression: "rate<0.01", Value: "rate=0.50%", Passed: true},
+		},
+		Checks: []summary.Check{
+			{Name: "status is 200", Passed: true},
+			{Name: "order created", Group: "checkout", Passes: 8, Fails: 2},
+		},
+		Metrics: []summary.Metric{
+			{Name: "http_req_duration", Values: map[string]string{"avg": "210ms", "p(95)": "512.3ms"}},
+			{Name: "http_req_failed", Values: map[string]string{"value": "0.50%"}},
+			{Name: "http_reqs", Values: map[string]string{"value": "400", "rate": "12.7/s"}},
+		},
+	}
+	baseline := &Report{
+		Name: "run-2 (api.js)",
+		Metrics: []summary.Metric{
+			{Name: "http_req_duration", Values: map[string]string{"avg": "200ms", "p(95)": "1.1s"}},
+			{Name: "http_req_failed", Values: map[string]string{"value": "0.25%"}},
+		},
+	}
+
+	assert.Equal(t, "### k6 run-3 (api.js): ✗ 1 of 2 thresholds failed\n\n"+
+		"Ran for 31.5s, compared to run-2 (api.js).\n\n"+
+		"| Metric | Value | Baseline | Change |\n|---|---:|---:|---:|\n"+
+		"| http_req_duration avg | 210ms | 200ms | +5.0% |\n"+
+		"| http_req_duration p(95) | 512.3ms | 1.1s | -53.4% |\n"+
+		"| http_req_failed | 0.50% | 0.25% | +0.25 pp |\n"+
+		"| http_reqs | 12.7/s | – | – |\n\n"+
+		"| | Threshold | Value |\n|---|---|---|\n"+
+		"| ✗ | http_req_duration `p(95)<500` | p(95)=512.3ms |\n"+
+		"| ✓ | http_req_failed `rate<0.01` | rate=0.50% |\n\n"+
+		"1 of 2 checks passed. Failed:\n"+
+		"- ✗ checkout › order created (2 of 10 failed)\n",
+		r.Markdown(baseline))
+
+	assert.Equal(t, "### k6 run-1: ✗ did not complete\n\nRan for 0s.\n\n> script error | line 3\n",
+		Report{Name: "run-1", Error: "script error | line 3"}.Markdown(nil))
+}
+
+func TestMarkdownMetricsWithoutBaseline(t *testing.T) {
+	t.Parallel()
+
+	r := Report{
+		Name: "run-1",
+		Metrics: []summary.Metric{
+			{Name: "checks", Values: map[string]string{"value": "100.00%"}},
+			{Name: "iterations", Values: map[string]string{"value": "10", "rate": "1/s"}},
+		},
+		Checks: []summary.Check{{Name: "ok", Passed: true}},
+	}
+	assert.Equal(t, "### k6 run-1: ✓ completed\n\nRan for 0s.\n\n"+
+		"| Metric | Value |\n|---|---:|\n| iterations | 10 |\n| checks | 100.00% |\n\n"+
+		"1 of 1 checks passed.\n", r.Markdown(nil))
+}
+
+func TestMarkdownProtocolMetrics(t *testing.T) {
+	t.Parallel()
+
+	r := Report{
+		Name: "run-1",
+		Metrics: []summary.Metric{
+			{Name: "grpc_req_duration", Values: map[string]string{"avg": "8ms", "p(95)": "15ms"}},
+			{Name: "ws_connecting", Values: map[string]string{"avg": "12.5ms"}},
+			{Name: "ws_msgs_received", Values: map[string]string{"value": "300", "rate": "30/s"}},
+		},
+	}
+	assert.Contains(t, r.Markdown(nil), "| ws_connecting avg | 12.5ms |\n| ws_msgs_received | 30/s |\n"+
+		"| grpc_req_duration avg | 8ms |\n| grpc_req_duration p(95) | 15ms |\n")
+}
+
+func TestDelta(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "+100.0%", delta("2s", "1000ms"))
+	assert.Equal(t, "-50.0%", delta("500µs", "1ms"))
+	assert.Equal(t, "0%", delta("1.9/s", "1.9/s"))
+	assert.Equal(t, "-1.00 pp", delta("98.00%", "99.00%"))
+	assert.Equal(t, "–", delta("1.2 kB", "1 kB"))
+	assert.Equal(t, "–", delta("3", "0"))
+}
@@ -0,0 +1,23 @@
+// Package report renders the outcome of a k6 run in formats meant for
+// other tools, such as JUnit XML for CI test reports and Markdown for
+// pull-request comments.
+package report
+
+import (
+	"time"
+
+	"github.com/grafana/mcp-k6/internal/summary"
+)
+
+// Report is the outcome of one k6 run.
+type Report struct {
+	// Name identifies the run, such as "run-3 (api.js)".
+	Name     string
+	Duration time.Duration
+	// Error is set when the run did not complete, as opposed to completing
+	// with failed thresholds.
+	Error      string
+	Thresholds []summary.Threshold
+	Checks     []summary.Check
+	Metrics    []summary.Metric
+}
@@ -0,0 +1,195 @@
+// Package requestlog reads the HTTP requests of a k6 run from its JSON
+// output (k6 run --out json), keeping the slowest and the failed ones with
+// their tags and timings.
+package requestlog
+
+import (
+	"io"
+	"maps"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/grafana/mcp-k6/internal/samples"
+)
+
+// SystemTags are the tags k6 is asked to record when requests are captured:
+// its defaults, plus the VU and iteration of each request.
+const SystemTags = "proto,subproto,status,method,url,name,group,check,error,error_code," +
+	"tls_version,scenario,service,expected_response,vu,iter"
+
+// Timings breaks down the duration of a request, in milliseconds.
+type Timings struct {
+	// Duration is the time from sending the request to receiving the
+	// response: sending, waiting and receiving.
+	Duration       float64 `json:"duration"`
+	Blocked        float64 `json:"blocked"`
+	Connecting     float64 `json:"connecting"`
+	TLSHandshaking float64 `json:"tls_handshaking"`
+	Sending        float64 `json:"sending"`
+	Waiting        float64 `json:"waiting"`
+	Receiving      float64 `json:"receiving"`
+}
+
+// Request is an HTTP request of a run.
+type Request struct {
+	Time   time.Time `json:"time"`
+	Method string    `json:"method,omitempty"`
+	URL    string    `json:"url"`
+	// Name is the name tag of the request, when it differs from its URL.
+	Name      string `json:"name,omitempty"`
+	Status    int    `json:"status"`
+	Failed    bool   `json:"failed"`
+	Error     string `json:"error,omitempty"`
+	ErrorCode string `json:"error_code,omitempty"`
+	Scenario  string `json:"scenario,omitempty"`
+	Group     string `json:"group,omitempty"`
+	VU        int    `json:"vu"`
+	Iteration int    `json:"iteration"`
+	// Tags holds the other tags of the request, such as those set by the
+	// script.
+	Tags    map[string]string `json:"tags,omitempty"`
+	Timings Timings           `json:"timings"`
+}
+
+// Summary is the selection of the requests of a run.
+type Summary struct {
+	Total       int `json:"total"`
+	FailedTotal int `json:"failed_total"`
+	// Slowest holds the slowest requests by duration, slowest first.
+	Slowest []Request `json:"slowest"`
+	// Failed holds the failed requests in order, up to the limit Read was
+	// given.
+	Failed []Request `json:"failed"`
+}
+
+// timing returns the field of t a metric of a request sets.
+func (t *Timings) timing(metric string) *float64 {
+	switch metric {
+	case "http_req_duration":
+		return &t.Duration
+	case "http_req_blocked":
+		return &t.Blocked
+	case "http_req_connecting":
+		return &t.Connecting
+	case "http_req_tls_handshaking":
+		return &t.TLSHandshaking
+	case "http_req_sending":
+		return &t.Sending
+	case "http_req_waiting":
+		return &t.Waiting
+	case "http_req_receiving":
+		return &t.Receiving
+	}
+	return nil
+}
+
+// isRequestMetric reports whether k6 records metric for each HTTP request.
+func isRequestMetric(metric string) bool {
+	return metric == "http_req_failed" || metric == "http_reqs" || new(Timings).timing(metric) != nil
+}
+
+// Read returns the slowest requests of the JSON output r, and up to
+// maxFailed of its failed requests. k6 writes the samples of a request
+// together, with the same time and tags, so requests are read one at a time
+// whatever the size of the output.
+func Read(r io.Reader, slowest, maxFailed int) (*Summary, error) {
+	s := &Summary{Slowest: []Request{}, Failed: []Request{}}
+	var cur *Request
+	var curTags map[string]string
+	seen := make(map[string]bool)
+	flush := func() {
+		if cur != nil {
+			s.add(*cur, slowest, maxFailed)
+			cur = nil
+		}
+	}
+
+	err := samples.ReadJSON(r, nil, func(smp samples.Sample) bool {
+		if !isRequestMetric(smp.Metric) {
+			return true
+		}
+		if cur == nil || seen[smp.Metric] || !cur.Time.Equal(smp.Time) || !maps.Equal(curTags, smp.Tags) {
+			flush()
+			cur, curTags = newRequest(smp.Time, smp.Tags), smp.Tags
+			clear(seen)
+		}
+		seen[smp.Metric] = true
+		switch smp.Metric {
+		case "http_req_failed":
+			cur.Failed = smp.Value != 0
+		case "http_reqs":
+		default:
+			*cur.Timings.timing(smp.Metric) = smp.Value
+		}
+		return true
+	})
+	flush()
+	return s, err
+}
+
+func (s *Summary) add(req Request, slowest, maxFailed int) {
+	s.Total++
+	if req.Failed {
+		s.FailedTotal++
+		if len(s.Failed) < maxFailed {
+			s.Failed = append(s.Failed, req)
+		}
+	}
+	if slowest <= 0 {
+		return
+	}
+	i := sort.Search(len(s.Slowest), func(i int) bool { return s.Slowest[i].Timings.Duration < req.Timings.Duration })
+	if i >= slowest {
+		return
+	}
+	s.Slowest = append(s.Slowest, Request{})
+	copy(s.Slowest[i+1:], s.Slowest[i:])
+	s.Slowest[i] = req
+	if len(s.Slowest) > slowest {
+		s.Slowest = s.Slowest[:slowest]
+	}
+}
+
+// newRequest returns a request with the fields its tags set. The failed
+// state defaults to the expected_response tag, for k6 versions without
+// http_req_failed.
+func newRequest(at time.Time, tags map[string]string) *Request {
+	req := &Request{Time: at, Tags: make(map[string]string)}
+	for name, value := range tags {
+		switch name {
+		case "method":
+			req.Method = value
+		case "url":
+			req.URL = value
+		case "name":
+			req.Name = value
+		case "status":
+			req.Status, _ = strconv.Atoi(value)
+		case "error":
+			req.Error = value
+		case "error_code":
+			req.ErrorCode = value
+		case "scenario":
+			req.Scenario = value
+		case "group":
+			req.Group = value
+		case "vu":
+			req.VU, _ = strconv.Atoi(value)
+		case "iter":
+			req.Iteration, _ = strconv.Atoi(value)
+		case "expected_response":
+			req.Failed = value == "false"
+		case "proto", "subproto", "tls_version", "service", "check":
+		default:
+			req.Tags[name] = value
+		}
+	}
+	if req.Name == req.URL {
+		req.Name = ""
+	}
+	if len(req.Tags) == 0 {
+		req.Tags = nil
+	}
+	return req
+}
@@ -0,0 +1,93 @@
+package requestlog
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// request returns the JSON output k6 writes for a request.
+func request(at, url, status string, vu, iter int, duration float64, failed bool, extraTags string) string {
+	tags := fmt.Sprintf(`{"method":"GET","url":%q,"name":%q,"status":%q,"scenario":"default","group":"",`+
+		`"expected_response":"%t","vu":"%d","iter":"%d"%s}`, url, url, status, !failed, vu, iter, extraTags)
+	point := func(metric string, value float64) string {
+		return fmt.Sprintf(`{"metric":%q,"type":"Point","data":{"time":%q,"value":%v,"tags":%s}}`,
+			metric, at, value, tags)
+	}
+	failedValue := 0.0
+	if failed {
+		failedValue = 1
+	}
+	return strings.Join([]string{
+		point("http_reqs", 1),
+		point("http_req_duration", duration),
+		point("http_req_blocked", 1.5),
+		point("http_req_connecting", 0.5),
+		point("http_req_tls_handshaking", 0),
+		point("http_req_sending", 0.1),
+		point("http_req_waiting", duration-0.3),
+		point("http_req_receiving", 0.2),
+		point("http_req_failed", failedValue),
+	}, "\n")
+}
+
+func TestRead(t *testing.T) {
+	t.Parallel()
+
+	at := "2026-01-02T10:00:00.123456Z"
+	output := strings.Join([]string{
+		`{"type":"Metric","data":{"name":"http_reqs","type":"counter"},"metric":"http_reqs"}`,
+		request(at, "https://test.k6.io/", "200", 1, 0, 120, false, ""),
+		`{"metric":"iterations","type":"Point","data":{"time":"2026-01-02T10:00:01Z","value":1,"tags":{}}}`,
+		// Two requests of the same VU at the same time are told apart
+		request(at, "https://test.k6.io/", "200", 1, 1, 80, false, ""),
+		request(at, "https://test.k6.io/", "200", 1, 1, 300, false, ""),
+		request("2026-01-02T10:00:02Z", "https://test.k6.io/missing", "404", 2, 0, 40, true, `,"page":"missing"`),
+		"not json",
+	}, "\n")
+
+	s, err := Read(strings.NewReader(output), 2, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 4, s.Total)
+	assert.Equal(t, 1, s.FailedTotal)
+
+	require.Len(t, s.Slowest, 2)
+	assert.InDelta(t, 300, s.Slowest[0].Timings.Duration, 0)
+	assert.InDelta(t, 120, s.Slowest[1].Timings.Duration, 0)
+	assert.Equal(t, 1, s.Slowest[0].Iteration)
+	assert.InDelta(t, 299.7, s.Slowest[0].Timings.Waiting, 1e-9)
+	assert.InDelta(t, 1.5, s.Slowest[0].Timings.Blocked, 0)
+
+	require.Len(t, s.Failed, 1)
+	failed := s.Failed[0]
+	assert.Equal(t, "GET", failed.Method)
+	assert.Equal(t, "https://test.k6.io/missing", failed.URL)
+	assert.Empty(t, failed.Name)
+	assert.Equal(t, 404, failed.Status)
+	assert.Equal(t, 2, failed.VU)
+	assert.Equal(t, "default", failed.Scenario)
+	assert.Equal(t, map[string]string{"page": "missing"}, failed.Tags)
+}
+
+func TestReadLimits(t *testing.T) {
+	t.Parallel()
+
+	var lines []string
+	for i := range 5 {
+		lines = append(lines, request("2026-01-02T10:00:00Z", "https://test.k6.io/", "0", 1, i, float64(i), true,
+			`,"error":"dial: i/o timeout","error_code":"1211"`))
+	}
+
+	s, err := Read(strings.NewReader(strings.Join(lines, "\n")), 0, 3)
+	require.NoError(t, err)
+	assert.Equal(t, 5, s.Total)
+	assert.Equal(t, 5, s.FailedTotal)
+	assert.Empty(t, s.Slowest)
+	require.Len(t, s.Failed, 3)
+	assert.Equal(t, "1211", s.Failed[0].ErrorCode)
+	assert.Equal(t, "dial: i/o timeout", s.Failed[0].Error)
+	assert.Equal(t, 2, s.Failed[2].Iteration)
+}
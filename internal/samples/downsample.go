@@ -0,0 +1,59 @@
+package samples
+
+import (
+	"time"
+)
+
+// Bucket summarizes the samples of a time series in a period of time.
+type Bucket struct {
+	// Time is the start of the period.
+	Time  time.Time `json:"time"`
+	Count int       `json:"count"`
+	Sum   float64   `json:"sum"`
+	Min   float64   `json:"min"`
+	Max   float64   `json:"max"`
+	Avg   float64   `json:"avg"`
+}
+
+// downsample sets the series of each aggregate: n buckets of equal duration
+// spanning the selected samples of all of them, so series line up. Buckets
+// without samples are left out; the min and max of each bucket keep the
+// spikes an average would hide. It returns the duration of the buckets.
+func downsample(aggregates map[[2]string]*Aggregate, n int) time.Duration {
+	var start, end time.Time
+	for _, a := range aggregates {
+		if start.IsZero() || a.First.Before(start) {
+			start = a.First
+		}
+		if a.Last.After(end) {
+			end = a.Last
+		}
+	}
+	// The last sample falls in the last bucket
+	width := end.Sub(start)/time.Duration(n) + 1
+
+	for _, a := range aggregates {
+		buckets := make([]*Bucket, n)
+		for i, t := range a.times {
+			k := min(int(t.Sub(start)/width), n-1)
+			v := a.values[i]
+			b := buckets[k]
+			if b == nil {
+				b = &Bucket{Time: start.Add(time.Duration(k) * width), Min: v, Max: v}
+				buckets[k] = b
+			}
+			b.Count++
+			b.Sum += v
+			b.Min = min(b.Min, v)
+			b.Max = max(b.Max, v)
+		}
+		a.Series = []Bucket{}
+		for _, b := range buckets {
+			if b != nil {
+				b.Avg = round(b.Sum / float64(b.Count))
+				a.Series = append(a.Series, *b)
+			}
+		}
+	}
+	return width
+}
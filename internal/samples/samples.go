@@ -0,0 +1,333 @@
+// Package samples reads the raw metric samples k6 writes with --out json or
+// --out csv, and filters and aggregates them by metric, tag and time range,
+// downsampling their time series to fit in a tool response.
+package samples
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"net/url"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Formats of sample files.
+const (
+	FormatJSON = "json"
+	FormatCSV  = "csv"
+)
+
+// Formats are the sample file formats k6 can write, and Read can read.
+//
+//nolint:gochecknoglobals // Read-only lookup table.
+var Formats = []string{FormatJSON, FormatCSV}
+
+// maxLineSize bounds the lines of the JSON output, which hold one sample
+// each.
+const maxLineSize = 1024 * 1024
+
+// Sample is a metric sample of a run.
+type Sample struct {
+	Metric string            `json:"metric"`
+	Time   time.Time         `json:"time"`
+	Value  float64           `json:"value"`
+	Tags   map[string]string `json:"tags,omitempty"`
+}
+
+// Metric is the definition of a metric, which k6 writes to the JSON output
+// before its first sample.
+type Metric struct {
+	Name string
+	// Type is "counter", "gauge", "rate" or "trend".
+	Type string
+	// Contains is "time" for metrics holding durations.
+	Contains string
+}
+
+// errStop stops Read early.
+var errStop = errors.New("stop")
+
+// Read calls fn with each sample of r, written by k6 in format, until fn
+// returns false.
+func Read(r io.Reader, format string, fn func(Sample) bool) error {
+	switch format {
+	case FormatJSON:
+		return ReadJSON(r, nil, fn)
+	case FormatCSV:
+		if err := readCSV(r, fn); !errors.Is(err, errStop) {
+			return err
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown sample format %q; use %s", format, strings.Join(Formats, " or "))
+	}
+}
+
+// ReadJSON calls metric, when not nil, with each metric definition of the
+// JSON output r, and fn with each of its samples, until fn returns false.
+func ReadJSON(r io.Reader, metric func(Metric), fn func(Sample) bool) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+	for scanner.Scan() {
+		var line struct {
+			Type   string `json:"type"`
+			Metric string `json:"metric"`
+			Data   struct {
+				Name     string            `json:"name"`
+				Type     string            `json:"type"`
+				Contains string            `json:"contains"`
+				Time     time.Time         `json:"time"`
+				Value    float64           `json:"value"`
+				Tags     map[string]string `json:"tags"`
+			} `json:"data"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			continue
+		}
+		switch {
+		case line.Type == "Metric" && metric != nil:
+			metric(Metric{Name: line.Data.Name, Type: line.Data.Type, Contains: line.Data.Contains})
+		case line.Type == "Point":
+			if !fn(Sample{Metric: line.Metric, Time: line.Data.Time, Value: line.Data.Value, Tags: line.Data.Tags}) {
+				return nil
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read the JSON samples: %w", err)
+	}
+	return nil
+}
+
+// readCSV reads the CSV output of k6: a header naming the metric_name,
+// timestamp and metric_value columns and a column per system tag, with the
+// other tags URL-encoded in extra_tags.
+func readCSV(r io.Reader, fn func(Sample) bool) error {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	header, err := cr.Read()
+	if errors.Is(err, io.EOF) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read the CSV samples: %w", err)
+	}
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read the CSV samples: %w", err)
+		}
+		s, ok := csvSample(header, record)
+		if ok && !fn(s) {
+			return errStop
+		}
+	}
+}
+
+func csvSample(header, record []string) (Sample, bool) {
+	s := Sample{Tags: make(map[string]string)}
+	for i, column := range header {
+		if i >= len(record) || record[i] == "" {
+			continue
+		}
+		value := record[i]
+		switch column {
+		case "metric_name":
+			s.Metric = value
+		case "timestamp":
+			s.Time = csvTime(value)
+		case "metric_value":
+			s.Value, _ = strconv.ParseFloat(value, 64)
+		case "metadata":
+		case "extra_tags":
+			extra, _ := url.ParseQuery(value)
+			for name := range extra {
+				s.Tags[name] = extra.Get(name)
+			}
+		default:
+			s.Tags[column] = value
+		}
+	}
+	if len(s.Tags) == 0 {
+		s.Tags = nil
+	}
+	return s, s.Metric != ""
+}
+
+// csvTime parses the timestamps of the CSV output, in Unix seconds or, with
+// timeFormat, micro seconds or RFC 3339.
+func csvTime(value string) time.Time {
+	if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return t
+	}
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	// Unix micro seconds have more than 10 digits until year 2286
+	if len(value) > 12 {
+		return time.UnixMicro(n).UTC()
+	}
+	return time.Unix(n, 0).UTC()
+}
+
+// Query selects samples.
+type Query struct {
+	// Metrics selects samples of these metrics; empty for all.
+	Metrics []string
+	// Tags selects samples with these tag values.
+	Tags map[string]string
+	// From and To select samples in this range of time since the first
+	// sample of the run; zero To for the end of the run.
+	From, To time.Duration
+	// GroupBy aggregates the selected samples by the value of this tag, in
+	// addition to their metric.
+	GroupBy string
+	// Limit bounds the selected samples returned, the rest being only
+	// aggregated.
+	Limit int
+	// Buckets downsamples the selected samples of each aggregate into this
+	// many buckets of equal duration; zero for none.
+	Buckets int
+}
+
+// Aggregate summarizes the selected samples of a metric.
+type Aggregate struct {
+	Metric string `json:"metric"`
+	// Group is the value of the GroupBy tag of the samples.
+	Group string    `json:"group,omitempty"`
+	Count int       `json:"count"`
+	Sum   float64   `json:"sum"`
+	Min   float64   `json:"min"`
+	Max   float64   `json:"max"`
+	Avg   float64   `json:"avg"`
+	P95   float64   `json:"p95"`
+	First time.Time `json:"first"`
+	Last  time.Time `json:"last"`
+	// Series is the downsampled time series of the samples, with Buckets.
+	Series []Bucket `json:"series,omitempty"`
+
+	values []float64
+	times  []time.Time
+}
+
+// Result holds the samples a query selected.
+type Result struct {
+	// Start is the time of the first sample of the run, which the time
+	// range of the query is relative to.
+	Start   time.Time `json:"start"`
+	Matched int       `json:"matched"`
+	// Samples holds the first Limit selected samples.
+	Samples    []Sample    `json:"samples"`
+	Aggregates []Aggregate `json:"aggregates"`
+	// Width is the duration of the buckets of the series, with Buckets.
+	Width time.Duration `json:"-"`
+}
+
+// Select runs q on the samples of r, written by k6 in format.
+func Select(r io.Reader, format string, q Query) (*Result, error) {
+	res := &Result{Samples: []Sample{}, Aggregates: []Aggregate{}}
+	aggregates := make(map[[2]string]*Aggregate)
+	err := Read(r, format, func(s Sample) bool {
+		if res.Start.IsZero() || s.Time.Before(res.Start) {
+			res.Start = s.Time
+		}
+		if !q.matches(s, res.Start) {
+			return true
+		}
+		res.Matched++
+		if len(res.Samples) < q.Limit {
+			res.Samples = append(res.Samples, s)
+		}
+		key := [2]string{s.Metric, ""}
+		if q.GroupBy != "" {
+			key[1] = s.Tags[q.GroupBy]
+		}
+		a, ok := aggregates[key]
+		if !ok {
+			a = &Aggregate{Metric: key[0], Group: key[1], Min: s.Value, Max: s.Value, First: s.Time}
+			aggregates[key] = a
+		}
+		a.add(s, q.Buckets > 0)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	if q.Buckets > 0 {
+		res.Width = downsample(aggregates, q.Buckets)
+	}
+
+	keys := slices.Collect(maps.Keys(aggregates))
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i][0] != keys[j][0] {
+			return keys[i][0] < keys[j][0]
+		}
+		return keys[i][1] < keys[j][1]
+	})
+	for _, key := range keys {
+		res.Aggregates = append(res.Aggregates, aggregates[key].finish())
+	}
+	return res, nil
+}
+
+func (q Query) matches(s Sample, start time.Time) bool {
+	if len(q.Metrics) > 0 && !slices.Contains(q.Metrics, s.Metric) {
+		return false
+	}
+	for name, value := range q.Tags {
+		if s.Tags[name] != value {
+			return false
+		}
+	}
+	offset := s.Time.Sub(start)
+	return offset >= q.From && (q.To == 0 || offset <= q.To)
+}
+
+func (a *Aggregate) add(s Sample, keepTimes bool) {
+	a.Count++
+	a.Sum += s.Value
+	a.Min = min(a.Min, s.Value)
+	a.Max = max(a.Max, s.Value)
+	if s.Time.Before(a.First) {
+		a.First = s.Time
+	}
+	if s.Time.After(a.Last) {
+		a.Last = s.Time
+	}
+	a.values = append(a.values, s.Value)
+	if keepTimes {
+		a.times = append(a.times, s.Time)
+	}
+}
+
+func (a *Aggregate) finish() Aggregate {
+	a.Avg = round(a.Sum / float64(a.Count))
+	sort.Float64s(a.values)
+	rank := 0.95 * float64(len(a.values)-1)
+	lower := int(rank)
+	a.P95 = a.values[lower]
+	if lower+1 < len(a.values) {
+		a.P95 += (a.values[lower+1] - a.values[lower]) * (rank - float64(lower))
+	}
+	a.P95 = round(a.P95)
+	a.values, a.times = nil, nil
+	return *a
+}
+
+// round rounds v to 4 decimals.
+func round(v float64) float64 {
+	return math.Round(v*10000) / 10000
+}
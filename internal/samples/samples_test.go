@@ -0,0 +1,156 @@
+package samples
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const jsonSamples = `{"type":"Metric","data":{"name":"http_reqs","type":"counter"},"metric":"http_reqs"}
+{"metric":"http_req_duration","type":"Point","data":{"time":"2026-01-02T10:00:00Z","value":100,"tags":{"name":"home","status":"200"}}}
+{"metric":"http_reqs","type":"Point","data":{"time":"2026-01-02T10:00:00Z","value":1,"tags":{"name":"home","status":"200"}}}
+{"metric":"http_req_duration","type":"Point","data":{"time":"2026-01-02T10:00:30Z","value":300,"tags":{"name":"login","status":"500"}}}
+{"metric":"http_req_duration","type":"Point","data":{"time":"2026-01-02T10:01:00Z","value":200,"tags":{"name":"home","status":"200"}}}
+{"metric":"vus","type":"Point","data":{"time":"2026-01-02T10:01:00Z","value":5,"tags":{}}}
+`
+
+const csvSamples = `metric_name,timestamp,metric_value,check,error,error_code,expected_response,group,method,name,proto,scenario,service,status,subproto,tls_version,url,extra_tags,metadata
+http_req_duration,1767348000,100,,,,true,,GET,home,HTTP/1.1,default,,200,,,https://test.k6.io/,,
+http_req_duration,1767348030,300,,,,false,,POST,login,HTTP/1.1,default,,500,,,https://test.k6.io/login,env=staging&team=web,
+vus,1767348060,5,,,,,,,,,,,,,,,,
+`
+
+func TestRead(t *testing.T) {
+	t.Parallel()
+
+	var got []Sample
+	require.NoError(t, Read(strings.NewReader(csvSamples), FormatCSV, func(s Sample) bool {
+		got = append(got, s)
+		return true
+	}))
+	require.Len(t, got, 3)
+	assert.Equal(t, Sample{
+		Metric: "http_req_duration",
+		Time:   time.Date(2026, 1, 2, 10, 0, 30, 0, time.UTC),
+		Value:  300,
+		Tags: map[string]string{
+			"expected_response": "false", "method": "POST", "name": "login", "proto": "HTTP/1.1",
+			"scenario": "default", "status": "500", "url": "https://test.k6.io/login", "env": "staging", "team": "web",
+		},
+	}, got[1])
+	assert.Nil(t, got[2].Tags)
+
+	// fn stops the reading
+	n := 0
+	require.NoError(t, Read(strings.NewReader(jsonSamples), FormatJSON, func(Sample) bool {
+		n++
+		return false
+	}))
+	assert.Equal(t, 1, n)
+
+	require.Error(t, Read(strings.NewReader(""), "xml", func(Sample) bool { return true }))
+}
+
+func TestReadJSON(t *testing.T) {
+	t.Parallel()
+
+	var metrics []Metric
+	n := 0
+	require.NoError(t, ReadJSON(strings.NewReader(jsonSamples), func(m Metric) {
+		metrics = append(metrics, m)
+	}, func(Sample) bool {
+		n++
+		return true
+	}))
+	assert.Equal(t, []Metric{{Name: "http_reqs", Type: "counter"}}, metrics)
+	assert.Equal(t, 5, n)
+
+	long := `{"type":"Point","data":{"tags":{"url":"` + strings.Repeat("a", maxLineSize) + `"}}}`
+	require.Error(t, ReadJSON(strings.NewReader(long), nil, func(Sample) bool { return true }))
+}
+
+func TestSelect(t *testing.T) {
+	t.Parallel()
+
+	res, err := Select(strings.NewReader(jsonSamples), FormatJSON, Query{
+		Metrics: []string{"http_req_duration"},
+		GroupBy: "name",
+		Limit:   2,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC), res.Start)
+	assert.Equal(t, 3, res.Matched)
+	require.Len(t, res.Samples, 2)
+	require.Len(t, res.Aggregates, 2)
+	home := res.Aggregates[0]
+	assert.Equal(t, "home", home.Group)
+	assert.Equal(t, 2, home.Count)
+	assert.InDelta(t, 150, home.Avg, 0)
+	assert.InDelta(t, 195, home.P95, 1e-9)
+	assert.Equal(t, time.Date(2026, 1, 2, 10, 1, 0, 0, time.UTC), home.Last)
+	assert.Equal(t, "login", res.Aggregates[1].Group)
+
+	// Tags and time ranges narrow the selection
+	res, err = Select(strings.NewReader(jsonSamples), FormatJSON, Query{
+		Tags: map[string]string{"status": "200"},
+		From: 10 * time.Second,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, res.Matched)
+	assert.Empty(t, res.Samples)
+	assert.InDelta(t, 200, res.Aggregates[0].Max, 0)
+
+	res, err = Select(strings.NewReader(csvSamples), FormatCSV, Query{To: 30 * time.Second, Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, 2, res.Matched)
+	assert.Equal(t, []Aggregate{{
+		Metric: "http_req_duration", Count: 2, Sum: 400, Min: 100, Max: 300, Avg: 200, P95: 290,
+		First: time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC), Last: time.Date(2026, 1, 2, 10, 0, 30, 0, time.UTC),
+	}}, res.Aggregates)
+}
+
+func TestSelectBuckets(t *testing.T) {
+	t.Parallel()
+
+	// A 10-minute run with a sample per second and a spike at 5m
+	var b strings.Builder
+	start := time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)
+	for i := range 600 {
+		value := 100.0
+		if i == 300 {
+			value = 5000
+		}
+		fmt.Fprintf(&b, `{"metric":"http_req_duration","type":"Point","data":{"time":%q,"value":%v}}`+"\n",
+			start.Add(time.Duration(i)*time.Second).Format(time.RFC3339), value)
+		if i%60 == 0 {
+			fmt.Fprintf(&b, `{"metric":"vus","type":"Point","data":{"time":%q,"value":%d}}`+"\n",
+				start.Add(time.Duration(i)*time.Second).Format(time.RFC3339), i/60)
+		}
+	}
+
+	res, err := Select(strings.NewReader(b.String()), FormatJSON, Query{Buckets: 10})
+	require.NoError(t, err)
+	require.Len(t, res.Aggregates, 2)
+	series := res.Aggregates[0].Series
+	require.Len(t, series, 10)
+	assert.Equal(t, start, series[0].Time)
+	assert.Equal(t, 60, series[0].Count)
+	assert.InDelta(t, 100, series[0].Max, 0)
+	assert.InDelta(t, 5000, series[5].Max, 0)
+	assert.InDelta(t, 100, series[5].Min, 0)
+	assert.InDelta(t, 181.6667, series[5].Avg, 0)
+
+	// Series share the bucket bounds
+	vus := res.Aggregates[1].Series
+	require.Len(t, vus, 10)
+	assert.Equal(t, series[9].Time, vus[9].Time)
+	assert.InDelta(t, 9, vus[9].Max, 0)
+
+	res, err = Select(strings.NewReader(b.String()), FormatJSON, Query{})
+	require.NoError(t, err)
+	assert.Nil(t, res.Aggregates[0].Series)
+}
@@ -0,0 +1,411 @@
+// Package scenario builds k6 scenario definitions that satisfy the
+// requirements of each executor.
+package scenario
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Executors lists the executors Build supports, in k6 documentation order.
+//
+//nolint:gochecknoglobals // Read-only lookup table.
+var Executors = []string{
+	"shared-iterations",
+	"per-vu-iterations",
+	"constant-vus",
+	"ramping-vus",
+	"constant-arrival-rate",
+	"ramping-arrival-rate",
+}
+
+// Parameter names, as exposed to tool callers.
+const (
+	paramVUs              = "vus"
+	paramIterations       = "iterations"
+	paramDuration         = "duration"
+	paramMaxDuration      = "max_duration"
+	paramRate             = "rate"
+	paramTimeUnit         = "time_unit"
+	paramPreAllocatedVUs  = "pre_allocated_vus"
+	paramMaxVUs           = "max_vus"
+	paramStartVUs         = "start_vus"
+	paramStartRate        = "start_rate"
+	paramStages           = "stages"
+	paramGracefulRampDown = "graceful_ramp_down"
+)
+
+// executorParams lists, per executor, the shape parameters it accepts and
+// which of them are required. start_time, graceful_stop and exec apply to
+// every executor.
+//
+//nolint:gochecknoglobals // Read-only lookup table.
+var executorParams = map[string]struct {
+	allowed  []string
+	required []string
+}{
+	"shared-iterations": {
+		allowed: []string{paramVUs, paramIterations, paramMaxDuration},
+	},
+	"per-vu-iterations": {
+		allowed: []string{paramVUs, paramIterations, paramMaxDuration},
+	},
+	"constant-vus": {
+		allowed:  []string{paramVUs, paramDuration},
+		required: []string{paramDuration},
+	},
+	"ramping-vus": {
+		allowed:  []string{paramStartVUs, paramStages, paramGracefulRampDown},
+		required: []string{paramStages},
+	},
+	"constant-arrival-rate": {
+		allowed:  []string{paramRate, paramTimeUnit, paramDuration, paramPreAllocatedVUs, paramMaxVUs},
+		required: []string{paramRate, paramDuration, paramPreAllocatedVUs},
+	},
+	"ramping-arrival-rate": {
+		allowed:  []string{paramStartRate, paramTimeUnit, paramStages, paramPreAllocatedVUs, paramMaxVUs},
+		required: []string{paramStages, paramPreAllocatedVUs},
+	},
+}
+
+//nolint:gochecknoglobals // Compiled once and reused.
+var (
+	nameRe = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+	execRe = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+)
+
+// ErrUnknownExecutor is returned for executors Build does not support.
+var ErrUnknownExecutor = errors.New("unknown executor")
+
+// Stage is one step of a ramping executor.
+type Stage struct {
+	Duration string `json:"duration"`
+	Target   int    `json:"target"`
+}
+
+// Params holds the shape of a scenario. Nil and empty fields are unset.
+type Params struct {
+	VUs              *int
+	Iterations       *int
+	Duration         string
+	MaxDuration      string
+	Rate             *int
+	TimeUnit         string
+	PreAllocatedVUs  *int
+	MaxVUs           *int
+	StartVUs         *int
+	StartRate        *int
+	Stages           []Stage
+	GracefulRampDown string
+
+	StartTime    string
+	GracefulStop string
+	Exec         string
+}
+
+// set returns the names of the shape parameters that are set.
+func (p Params) set() []string {
+	var names []string
+	add := func(name string, ok bool) {
+		if ok {
+			names = append(names, name)
+		}
+	}
+	add(paramVUs, p.VUs != nil)
+	add(paramIterations, p.Iterations != nil)
+	add(paramDuration, p.Duration != "")
+	add(paramMaxDuration, p.MaxDuration != "")
+	add(paramRate, p.Rate != nil)
+	add(paramTimeUnit, p.TimeUnit != "")
+	add(paramPreAllocatedVUs, p.PreAllocatedVUs != nil)
+	add(paramMaxVUs, p.MaxVUs != nil)
+	add(paramStartVUs, p.StartVUs != nil)
+	add(paramStartRate, p.StartRate != nil)
+	add(paramStages, p.Stages != nil)
+	add(paramGracefulRampDown, p.GracefulRampDown != "")
+	return names
+}
+
+// Scenario is a k6 scenario definition, serialized with k6's option names.
+type Scenario struct {
+	Executor         string  `json:"executor"`
+	StartTime        string  `json:"startTime,omitempty"`
+	GracefulStop     string  `json:"gracefulStop,omitempty"`
+	Exec             string  `json:"exec,omitempty"`
+	VUs              int     `json:"vus,omitempty"`
+	Iterations       int     `json:"iterations,omitempty"`
+	Duration         string  `json:"duration,omitempty"`
+	MaxDuration      string  `json:"maxDuration,omitempty"`
+	Rate             int     `json:"rate,omitempty"`
+	TimeUnit         string  `json:"timeUnit,omitempty"`
+	PreAllocatedVUs  int     `json:"preAllocatedVUs,omitempty"`
+	MaxVUs           int     `json:"maxVUs,omitempty"`
+	StartVUs         *int    `json:"startVUs,omitempty"`
+	StartRate        int     `json:"startRate,omitempty"`
+	Stages           []Stage `json:"stages,omitempty"`
+	GracefulRampDown string  `json:"gracefulRampDown,omitempty"`
+}
+
+// PeakVUs returns the largest number of VUs the scenario may use.
+func (s Scenario) PeakVUs() int {
+	switch s.Executor {
+	case "ramping-vus":
+		peak := 0
+		if s.StartVUs != nil {
+			peak = *s.StartVUs
+		}
+		for _, st := range s.Stages {
+			peak = max(peak, st.Target)
+		}
+		return peak
+	case "constant-arrival-rate", "ramping-arrival-rate":
+		return max(s.PreAllocatedVUs, s.MaxVUs)
+	default:
+		return s.VUs
+	}
+}
+
+// ValidateName checks that name can be used as a scenario key.
+func ValidateName(name string) error {
+	if !nameRe.MatchString(name) {
+		return fmt.Errorf("invalid scenario name %q: use letters, digits, '-' and '_'", name)
+	}
+	return nil
+}
+
+// Build returns the scenario for executor with the given shape. It rejects
+// parameters the executor does not accept, fills in only what k6 requires,
+// and returns warnings for valid but likely unintended shapes.
+func Build(executor string, p Params) (Scenario, []string, error) {
+	spec, ok := executorParams[executor]
+	if !ok {
+		return Scenario{}, nil, fmt.Errorf("%w %q (supported: %s)", ErrUnknownExecutor, executor,
+			strings.Join(Executors, ", "))
+	}
+
+	set := p.set()
+	for _, name := range set {
+		if !slices.Contains(spec.allowed, name) {
+			return Scenario{}, nil, fmt.Errorf("%s does not accept %s (accepted: %s)",
+				executor, name, strings.Join(spec.allowed, ", "))
+		}
+	}
+	var missing []string
+	for _, name := range spec.required {
+		if !slices.Contains(set, name) {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return Scenario{}, nil, fmt.Errorf("%s requires %s", executor, strings.Join(missing, ", "))
+	}
+
+	if err := validateCommon(p); err != nil {
+		return Scenario{}, nil, err
+	}
+
+	s := Scenario{
+		Executor:     executor,
+		StartTime:    p.StartTime,
+		GracefulStop: p.GracefulStop,
+		Exec:         p.Exec,
+	}
+	var warnings []string
+	var err error
+	switch executor {
+	case "shared-iterations", "per-vu-iterations":
+		warnings, err = buildIterations(&s, p)
+	case "constant-vus":
+		err = buildConstantVUs(&s, p)
+	case "ramping-vus":
+		warnings, err = buildRampingVUs(&s, p)
+	default:
+		warnings, err = buildArrivalRate(&s, p)
+	}
+	if err != nil {
+		return Scenario{}, nil, err
+	}
+	return s, warnings, nil
+}
+
+func buildIterations(s *Scenario, p Params) ([]string, error) {
+	s.VUs, s.Iterations = 1, 1
+	if p.VUs != nil {
+		s.VUs = *p.VUs
+	}
+	if p.Iterations != nil {
+		s.Iterations = *p.Iterations
+	}
+	if err := positive(paramVUs, s.VUs); err != nil {
+		return nil, err
+	}
+	if err := positive(paramIterations, s.Iterations); err != nil {
+		return nil, err
+	}
+	if err := durationParam(paramMaxDuration, p.MaxDuration, false); err != nil {
+		return nil, err
+	}
+	s.MaxDuration = p.MaxDuration
+
+	if s.Executor == "shared-iterations" && s.Iterations < s.VUs {
+		return nil, fmt.Errorf("shared-iterations needs iterations (%d) >= vus (%d)", s.Iterations, s.VUs)
+	}
+	var warnings []string
+	if s.Executor == "per-vu-iterations" && s.VUs > 1 {
+		warnings = append(warnings, fmt.Sprintf(
+			"per-vu-iterations runs %d iterations per VU, %d in total; use shared-iterations for %d in total",
+			s.Iterations, s.Iterations*s.VUs, s.Iterations))
+	}
+	if p.MaxDuration == "" {
+		warnings = append(warnings, "maxDuration defaults to 10m; iterations still running then are interrupted")
+	}
+	return warnings, nil
+}
+
+func buildConstantVUs(s *Scenario, p Params) error {
+	s.VUs = 1
+	if p.VUs != nil {
+		s.VUs = *p.VUs
+	}
+	if err := positive(paramVUs, s.VUs); err != nil {
+		return err
+	}
+	if err := durationParam(paramDuration, p.Duration, true); err != nil {
+		return err
+	}
+	s.Duration = p.Duration
+	return nil
+}
+
+func buildRampingVUs(s *Scenario, p Params) ([]string, error) {
+	if p.StartVUs != nil {
+		if *p.StartVUs < 0 {
+			return nil, fmt.Errorf("%s cannot be negative", paramStartVUs)
+		}
+		s.StartVUs = p.StartVUs
+	}
+	if err := validateStages(p.Stages); err != nil {
+		return nil, err
+	}
+	if err := durationParam(paramGracefulRampDown, p.GracefulRampDown, false); err != nil {
+		return nil, err
+	}
+	s.Stages = p.Stages
+	s.GracefulRampDown = p.GracefulRampDown
+
+	var warnings []string
+	if s.PeakVUs() == 0 {
+		warnings = append(warnings, "no stage targets more than 0 VUs, so no iteration will run")
+	}
+	if last := p.Stages[len(p.Stages)-1]; last.Target > 0 {
+		warnings = append(warnings, fmt.Sprintf(
+			"the last stage ends at %d VUs; add a stage ramping down to 0 for a graceful finish", last.Target))
+	}
+	return warnings, nil
+}
+
+func buildArrivalRate(s *Scenario, p Params) ([]string, error) {
+	if err := durationParam(paramTimeUnit, p.TimeUnit, false); err != nil {
+		return nil, err
+	}
+	s.TimeUnit = p.TimeUnit
+	s.PreAllocatedVUs = *p.PreAllocatedVUs
+	if err := positive(paramPreAllocatedVUs, s.PreAllocatedVUs); err != nil {
+		return nil, err
+	}
+	if p.MaxVUs != nil {
+		s.MaxVUs = *p.MaxVUs
+		if s.MaxVUs < s.PreAllocatedVUs {
+			return nil, fmt.Errorf("%s (%d) cannot be lower than %s (%d)",
+				paramMaxVUs, s.MaxVUs, paramPreAllocatedVUs, s.PreAllocatedVUs)
+		}
+	}
+
+	if s.Executor == "constant-arrival-rate" {
+		s.Rate = *p.Rate
+		if err := positive(paramRate, s.Rate); err != nil {
+			return nil, err
+		}
+		if err := durationParam(paramDuration, p.Duration, true); err != nil {
+			return nil, err
+		}
+		s.Duration = p.Duration
+	} else {
+		if p.StartRate != nil {
+			if *p.StartRate < 0 {
+				return nil, fmt.Errorf("%s cannot be negative", paramStartRate)
+			}
+			s.StartRate = *p.StartRate
+		}
+		if err := validateStages(p.Stages); err != nil {
+			return nil, err
+		}
+		s.Stages = p.Stages
+	}
+
+	var warnings []string
+	if p.MaxVUs == nil {
+		warnings = append(warnings, fmt.Sprintf(
+			"without max_vus k6 never grows beyond %d VUs and drops iterations when responses slow down",
+			s.PreAllocatedVUs))
+	}
+	return warnings, nil
+}
+
+func validateCommon(p Params) error {
+	if err := durationParam("start_time", p.StartTime, false); err != nil {
+		return err
+	}
+	if err := durationParam("graceful_stop", p.GracefulStop, false); err != nil {
+		return err
+	}
+	if p.Exec != "" && !execRe.MatchString(p.Exec) {
+		return fmt.Errorf("exec must name an exported function, got %q", p.Exec)
+	}
+	return nil
+}
+
+func validateStages(stages []Stage) error {
+	if len(stages) == 0 {
+		return fmt.Errorf("%s cannot be empty", paramStages)
+	}
+	for i, st := range stages {
+		if err := durationParam(fmt.Sprintf("stages[%d].duration", i), st.Duration, false); err != nil {
+			return err
+		}
+		if st.Duration == "" {
+			return fmt.Errorf("stages[%d].duration is required", i)
+		}
+		if st.Target < 0 {
+			return fmt.Errorf("stages[%d].target cannot be negative", i)
+		}
+	}
+	return nil
+}
+
+// durationParam checks that value is a k6 duration such as "30s" or "1m30s".
+// Positive requires a non-zero duration.
+func durationParam(name, value string, positive bool) error {
+	if value == "" {
+		return nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return fmt.Errorf("%s must be a duration like '30s' or '1m30s', got %q", name, value)
+	}
+	if d < 0 || (positive && d == 0) {
+		return fmt.Errorf("%s must be positive, got %q", name, value)
+	}
+	return nil
+}
+
+func positive(name string, value int) error {
+	if value < 1 {
+		return fmt.Errorf("%s must be at least 1, got %d", name, value)
+	}
+	return nil
+}
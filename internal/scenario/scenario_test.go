@@ -0,0 +1,97 @@
+package scenario
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func intp(v int) *int { return &v }
+
+func TestBuildConstantArrivalRate(t *testing.T) {
+	t.Parallel()
+
+	s, warnings, err := Build("constant-arrival-rate", Params{
+		Rate:            intp(50),
+		TimeUnit:        "1s",
+		Duration:        "2m",
+		PreAllocatedVUs: intp(20),
+		MaxVUs:          intp(100),
+	})
+	require.NoError(t, err)
+	assert.Empty(t, warnings)
+	assert.Equal(t, 100, s.PeakVUs())
+
+	data, err := json.Marshal(s)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"executor":"constant-arrival-rate","rate":50,"timeUnit":"1s","duration":"2m",
+		"preAllocatedVUs":20,"maxVUs":100}`, string(data))
+}
+
+func TestBuildRampingVUs(t *testing.T) {
+	t.Parallel()
+
+	s, warnings, err := Build("ramping-vus", Params{
+		StartVUs: intp(0),
+		Stages:   []Stage{{Duration: "30s", Target: 10}, {Duration: "1m", Target: 10}},
+	})
+	require.NoError(t, err)
+	require.Len(t, warnings, 1)
+	assert.Contains(t, warnings[0], "last stage ends at 10 VUs")
+
+	data, err := json.Marshal(s)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"executor":"ramping-vus","startVUs":0,
+		"stages":[{"duration":"30s","target":10},{"duration":"1m","target":10}]}`, string(data))
+}
+
+func TestBuildIterationsDefaults(t *testing.T) {
+	t.Parallel()
+
+	s, _, err := Build("shared-iterations", Params{MaxDuration: "1m"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, s.VUs)
+	assert.Equal(t, 1, s.Iterations)
+}
+
+func TestBuildErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		executor string
+		params   Params
+		message  string
+	}{
+		"unknown executor": {"externally-controlled", Params{}, "unknown executor"},
+		"foreign parameter": {
+			"constant-vus", Params{Duration: "1m", Rate: intp(10)},
+			"constant-vus does not accept rate",
+		},
+		"missing parameters": {
+			"constant-arrival-rate", Params{Rate: intp(10)},
+			"requires duration, pre_allocated_vus",
+		},
+		"max below preallocated": {
+			"ramping-arrival-rate",
+			Params{Stages: []Stage{{Duration: "1m", Target: 10}}, PreAllocatedVUs: intp(10), MaxVUs: intp(5)},
+			"max_vus (5) cannot be lower than pre_allocated_vus (10)",
+		},
+		"fewer iterations than vus": {
+			"shared-iterations", Params{VUs: intp(10), Iterations: intp(5)},
+			"iterations (5) >= vus (10)",
+		},
+		"bad duration": {"constant-vus", Params{Duration: "10"}, "duration must be a duration"},
+		"empty stages": {"ramping-vus", Params{Stages: []Stage{}}, "stages cannot be empty"},
+		"bad exec":     {"constant-vus", Params{Duration: "1m", Exec: "my-func"}, "exec must name"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			_, _, err := Build(tt.executor, tt.params)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.message)
+		})
+	}
+}
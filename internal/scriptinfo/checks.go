@@ -0,0 +1,95 @@
+package scriptinfo
+
+import "strings"
+
+// Check is one named assertion passed to check().
+type Check struct {
+	Name string `json:"name"`
+	// Group is the k6 group tag of the enclosing group() calls, such as
+	// "::login::submit", or empty outside groups.
+	Group string `json:"group,omitempty"`
+	// Tags holds the literal tags from the third argument of check().
+	Tags map[string]string `json:"tags,omitempty"`
+	// Dynamic is true when the name is computed at runtime and Name holds
+	// its source text.
+	Dynamic bool `json:"dynamic,omitempty"`
+	Line    int  `json:"line"`
+}
+
+// groupSpan is the source range of a group() callback.
+type groupSpan struct {
+	name       string
+	start, end int
+}
+
+// Checks returns the named assertions of every check() call in the script,
+// in source order.
+func Checks(script string) []Check {
+	src := maskComments(script)
+	lines := newLineIndex(src)
+
+	var spans []groupSpan
+	for _, m := range groupRe.FindAllStringSubmatchIndex(src, -1) {
+		open := m[0] + strings.IndexByte(src[m[0]:m[1]], '(')
+		name := submatch(src, m, 1) + submatch(src, m, 2) + submatch(src, m, 3)
+		spans = append(spans, groupSpan{name: name, start: open, end: skipBalanced(src, open)})
+	}
+
+	var checks []Check
+	for _, m := range checkRe.FindAllStringIndex(src, -1) {
+		args := parseArgs(src, m[1]-1)
+		if len(args) < 2 {
+			continue
+		}
+		sets, ok := args[1].(Object)
+		if !ok {
+			continue
+		}
+		group := enclosingGroup(spans, m[0])
+		tags := literalTags(argAt(args, 2))
+		for _, p := range sets {
+			c := Check{Name: p.Key, Group: group, Tags: tags, Line: lines.line(m[0])}
+			if strings.HasPrefix(p.Key, "[") {
+				c.Dynamic = true
+			}
+			checks = append(checks, c)
+		}
+	}
+	return checks
+}
+
+// submatch returns the n-th capture group of a FindStringSubmatchIndex match.
+func submatch(src string, m []int, n int) string {
+	if m[2*n] < 0 {
+		return ""
+	}
+	return src[m[2*n]:m[2*n+1]]
+}
+
+// enclosingGroup returns the k6 group tag for a call at offset.
+func enclosingGroup(spans []groupSpan, offset int) string {
+	var path strings.Builder
+	for _, s := range spans {
+		if s.start < offset && offset < s.end {
+			path.WriteString("::" + s.name)
+		}
+	}
+	return path.String()
+}
+
+func literalTags(v any) map[string]string {
+	obj, ok := v.(Object)
+	if !ok {
+		return nil
+	}
+	tags := make(map[string]string, len(obj))
+	for _, p := range obj {
+		if s, ok := p.Value.(string); ok && !strings.HasPrefix(p.Key, "[") {
+			tags[p.Key] = s
+		}
+	}
+	if len(tags) == 0 {
+		return nil
+	}
+	return tags
+}
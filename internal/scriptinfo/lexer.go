@@ -0,0 +1,371 @@
+package scriptinfo
+
+import (
+	"strconv"
+	"strings"
+)
+
+// maskComments returns src with comments replaced by spaces. Newlines are
+// kept and string, template and regular expression literals are left intact,
+// so offsets in the result map one-to-one to the original source.
+func maskComments(src string) string {
+	out := []byte(src)
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == '/' && i+1 < len(src) && src[i+1] == '/':
+			for i < len(src) && src[i] != '\n' {
+				out[i] = ' '
+				i++
+			}
+		case c == '/' && i+1 < len(src) && src[i+1] == '*':
+			end := strings.Index(src[i+2:], "*/")
+			stop := len(src)
+			if end >= 0 {
+				stop = i + 2 + end + 2
+			}
+			for ; i < stop; i++ {
+				if src[i] != '\n' {
+					out[i] = ' '
+				}
+			}
+		case c == '\'' || c == '"' || c == '`':
+			i = skipString(src, i)
+		case c == '/' && regexAllowed(src, i):
+			i = skipRegex(src, i)
+		default:
+			i++
+		}
+	}
+	return string(out)
+}
+
+// skipString returns the offset just past the string literal starting at i.
+func skipString(src string, i int) int {
+	quote := src[i]
+	for j := i + 1; j < len(src); j++ {
+		switch src[j] {
+		case '\\':
+			j++
+		case quote:
+			return j + 1
+		case '\n':
+			if quote != '`' {
+				return j
+			}
+		case '$':
+			if quote == '`' && j+1 < len(src) && src[j+1] == '{' {
+				j = skipBalanced(src, j+1) - 1
+			}
+		}
+	}
+	return len(src)
+}
+
+// skipRegex returns the offset just past the regular expression literal at i.
+func skipRegex(src string, i int) int {
+	inClass := false
+	for j := i + 1; j < len(src); j++ {
+		switch src[j] {
+		case '\\':
+			j++
+		case '[':
+			inClass = true
+		case ']':
+			inClass = false
+		case '/':
+			if !inClass {
+				j++
+				for j < len(src) && isIdentChar(src[j]) {
+					j++
+				}
+				return j
+			}
+		case '\n':
+			return j
+		}
+	}
+	return len(src)
+}
+
+// regexAllowed reports whether a '/' at offset i starts a regular expression
+// rather than a division, based on the previous significant character.
+func regexAllowed(src string, i int) bool {
+	j := i - 1
+	for j >= 0 && (src[j] == ' ' || src[j] == '\t' || src[j] == '\n' || src[j] == '\r') {
+		j--
+	}
+	if j < 0 {
+		return true
+	}
+	return strings.IndexByte("(,=:[!&|?{};+-*%<>~^", src[j]) >= 0 ||
+		strings.HasSuffix(src[:j+1], "return") || strings.HasSuffix(src[:j+1], "typeof")
+}
+
+// skipBalanced returns the offset just past the bracket that closes the one
+// at offset i, skipping over nested brackets and literals.
+func skipBalanced(src string, i int) int {
+	depth := 0
+	for j := i; j < len(src); {
+		switch c := src[j]; c {
+		case '(', '[', '{':
+			depth++
+			j++
+		case ')', ']', '}':
+			depth--
+			j++
+			if depth == 0 {
+				return j
+			}
+		case '\'', '"', '`':
+			j = skipString(src, j)
+		case '/':
+			if regexAllowed(src, j) {
+				j = skipRegex(src, j)
+			} else {
+				j++
+			}
+		default:
+			j++
+		}
+	}
+	return len(src)
+}
+
+func isIdentChar(c byte) bool {
+	return c == '_' || c == '$' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+func skipSpace(src string, i int) int {
+	for i < len(src) && strings.IndexByte(" \t\r\n", src[i]) >= 0 {
+		i++
+	}
+	return i
+}
+
+// Property is a key/value pair of a parsed object literal.
+type Property struct {
+	Key   string
+	Value any
+}
+
+// Object is a parsed object literal with its properties in source order.
+type Object []Property
+
+// Get returns the value of the named property.
+func (o Object) Get(key string) (any, bool) {
+	for _, p := range o {
+		if p.Key == key {
+			return p.Value, true
+		}
+	}
+	return nil, false
+}
+
+// Raw is an expression the literal parser does not evaluate, such as a
+// variable reference or a function call. It holds the source text.
+type Raw string
+
+// parseValue parses the JavaScript literal starting at offset i. Objects
+// become Object, arrays []any, strings string, numbers float64, booleans bool,
+// and anything else Raw. It returns the value and the offset after it.
+func parseValue(src string, i int) (any, int) {
+	i = skipSpace(src, i)
+	if i >= len(src) {
+		return Raw(""), i
+	}
+
+	switch c := src[i]; {
+	case c == '{':
+		return parseObject(src, i)
+	case c == '[':
+		return parseArray(src, i)
+	case c == '\'' || c == '"' || c == '`':
+		end := skipString(src, i)
+		if next := skipSpace(src, end); next < len(src) && !isTerminator(src[next]) {
+			raw, rawEnd := parseRaw(src, i)
+			return raw, rawEnd
+		}
+		literal := src[i:end]
+		if c == '`' {
+			if strings.Contains(literal, "${") {
+				return Raw(literal), end
+			}
+			return strings.Trim(literal, "`"), end
+		}
+		if unquoted, ok := unquoteJS(literal); ok {
+			return unquoted, end
+		}
+		return Raw(literal), end
+	default:
+		raw, end := parseRaw(src, i)
+		text := string(raw)
+		if n, err := strconv.ParseFloat(strings.ReplaceAll(text, "_", ""), 64); err == nil {
+			return n, end
+		}
+		switch text {
+		case "true":
+			return true, end
+		case "false":
+			return false, end
+		}
+		return Raw(text), end
+	}
+}
+
+func isTerminator(c byte) bool {
+	return c == ',' || c == '}' || c == ']' || c == ')' || c == ';'
+}
+
+// parseRaw consumes an expression up to the next top-level terminator.
+func parseRaw(src string, i int) (Raw, int) {
+	start := i
+	for i < len(src) {
+		switch c := src[i]; {
+		case c == '(' || c == '[' || c == '{':
+			i = skipBalanced(src, i)
+		case c == '\'' || c == '"' || c == '`':
+			i = skipString(src, i)
+		case isTerminator(c):
+			return Raw(strings.TrimSpace(src[start:i])), i
+		case c == '\n':
+			// A newline ends the expression unless the next line continues it.
+			next := skipSpace(src, i)
+			if next < len(src) && strings.IndexByte(".+-*/?:&|", src[next]) < 0 {
+				return Raw(strings.TrimSpace(src[start:i])), i
+			}
+			i = next
+		default:
+			i++
+		}
+	}
+	return Raw(strings.TrimSpace(src[start:])), i
+}
+
+func parseObject(src string, i int) (any, int) {
+	obj := Object{}
+	i++ // '{'
+	for {
+		i = skipSpace(src, i)
+		if i >= len(src) {
+			return obj, i
+		}
+		if src[i] == '}' {
+			return obj, i + 1
+		}
+		if src[i] == ',' {
+			i++
+			continue
+		}
+		if strings.HasPrefix(src[i:], "...") {
+			_, i = parseRaw(src, i)
+			continue
+		}
+
+		key, next := parseKey(src, i)
+		if next == i {
+			// Not something we understand; skip the character and resync.
+			i++
+			continue
+		}
+		i = skipSpace(src, next)
+
+		switch {
+		case i < len(src) && src[i] == ':':
+			var v any
+			v, i = parseValue(src, i+1)
+			obj = append(obj, Property{Key: key, Value: v})
+		case i < len(src) && src[i] == '(':
+			// Method shorthand: key() { ... }
+			i = skipBalanced(src, i)
+			i = skipSpace(src, i)
+			if i < len(src) && src[i] == '{' {
+				i = skipBalanced(src, i)
+			}
+			obj = append(obj, Property{Key: key, Value: Raw("function")})
+		default:
+			// Shorthand property: { key }
+			obj = append(obj, Property{Key: key, Value: Raw(key)})
+		}
+	}
+}
+
+func parseKey(src string, i int) (string, int) {
+	switch c := src[i]; {
+	case c == '\'' || c == '"':
+		end := skipString(src, i)
+		key, _ := unquoteJS(src[i:end])
+		return key, end
+	case c == '[':
+		end := skipBalanced(src, i)
+		return src[i:end], end
+	default:
+		j := i
+		for j < len(src) && (isIdentChar(src[j]) || src[j] == '.') {
+			j++
+		}
+		return src[i:j], j
+	}
+}
+
+func parseArray(src string, i int) (any, int) {
+	var arr []any
+	i++ // '['
+	for {
+		i = skipSpace(src, i)
+		if i >= len(src) {
+			return arr, i
+		}
+		switch src[i] {
+		case ']':
+			return arr, i + 1
+		case ',':
+			i++
+			continue
+		}
+		var v any
+		before := i
+		v, i = parseValue(src, i)
+		if i == before {
+			i++
+		}
+		arr = append(arr, v)
+	}
+}
+
+// unquoteJS decodes a single or double quoted JavaScript string literal.
+func unquoteJS(literal string) (string, bool) {
+	if len(literal) < 2 {
+		return "", false
+	}
+	body := literal[1 : len(literal)-1]
+	if literal[0] == '\'' {
+		body = strings.ReplaceAll(body, `\'`, `'`)
+		body = strings.ReplaceAll(body, `"`, `\"`)
+	}
+	s, err := strconv.Unquote(`"` + body + `"`)
+	if err != nil {
+		return body, true
+	}
+	return s, true
+}
+
+// StringValue renders a parsed value as a short string for reporting.
+func StringValue(v any) string {
+	switch t := v.(type) {
+	case string:
+		return t
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(t)
+	case Raw:
+		return string(t)
+	case Object:
+		return "{…}"
+	case []any:
+		return "[…]"
+	default:
+		return ""
+	}
+}
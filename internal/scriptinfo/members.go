@@ -0,0 +1,59 @@
+package scriptinfo
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Member is a property of an identifier the script reads or calls, such as
+// "get" in http.get(url).
+type Member struct {
+	Object string `json:"object"`
+	Name   string `json:"name"`
+	Line   int    `json:"line"`
+}
+
+//nolint:gochecknoglobals // Compiled once and reused.
+var memberRe = regexp.MustCompile(`(?:^|[^\w$.])([A-Za-z_$][\w$]*)\s*\.\s*([A-Za-z_$][\w$]*)`)
+
+// Members returns the properties of objects the script uses, each at its
+// first use, in source order.
+func Members(script string, objects []string) []Member {
+	want := make(map[string]bool, len(objects))
+	for _, o := range objects {
+		want[o] = true
+	}
+	src := maskComments(script)
+	lines := newLineIndex(src)
+
+	var members []Member
+	seen := make(map[string]bool)
+	for _, m := range memberRe.FindAllStringSubmatchIndex(src, -1) {
+		object, name := src[m[2]:m[3]], src[m[4]:m[5]]
+		key := object + "." + name
+		if !want[object] || seen[key] || inString(src, m[2]) {
+			continue
+		}
+		seen[key] = true
+		members = append(members, Member{Object: object, Name: name, Line: lines.line(m[2])})
+	}
+	return members
+}
+
+// inString reports whether offset is inside a string or template literal of
+// the line, such as "http.get" in console.log('http.get').
+func inString(src string, offset int) bool {
+	start := strings.LastIndexByte(src[:offset], '\n') + 1
+	var quote byte
+	for i := start; i < offset; i++ {
+		switch c := src[i]; {
+		case quote == 0 && (c == '\'' || c == '"' || c == '`'):
+			quote = c
+		case quote != 0 && c == '\\':
+			i++
+		case c == quote:
+			quote = 0
+		}
+	}
+	return quote != 0
+}
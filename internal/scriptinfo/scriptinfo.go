@@ -0,0 +1,609 @@
+// Package scriptinfo statically analyzes k6 scripts: imports, options,
+// scenarios, thresholds, requested endpoints and referenced data files.
+//
+// The analysis is lexical. It understands literals well enough to read
+// options objects and request arguments, and reports anything it cannot
+// evaluate (variables, function calls) as raw source text.
+package scriptinfo
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Import is a module imported by the script.
+type Import struct {
+	Module string   `json:"module"`
+	Names  []string `json:"names,omitempty"`
+	// Kind is one of "builtin", "experimental", "extension", "jslib", "remote", "local" or "other".
+	Kind string `json:"kind"`
+	Line int    `json:"line"`
+}
+
+// Stage is a ramping step of a scenario or of the top-level options.
+type Stage struct {
+	Duration string `json:"duration"`
+	Target   string `json:"target"`
+}
+
+// Scenario is a named entry of options.scenarios.
+type Scenario struct {
+	Name      string            `json:"name"`
+	Executor  string            `json:"executor"`
+	Exec      string            `json:"exec,omitempty"`
+	StartTime string            `json:"start_time,omitempty"`
+	Stages    []Stage           `json:"stages,omitempty"`
+	Settings  map[string]string `json:"settings,omitempty"`
+}
+
+// Threshold is the set of criteria configured for a metric.
+type Threshold struct {
+	Metric      string   `json:"metric"`
+	Expressions []string `json:"expressions"`
+	AbortOnFail bool     `json:"abort_on_fail,omitempty"`
+}
+
+// Endpoint is a request issued by the script.
+type Endpoint struct {
+	Protocol string `json:"protocol"` // "http", "websocket", "grpc", "browser"
+	Method   string `json:"method,omitempty"`
+	URL      string `json:"url"`
+	// Dynamic is true when the URL is built at runtime (template literal,
+	// concatenation or variable) and URL holds its source text.
+	Dynamic bool `json:"dynamic,omitempty"`
+	// Tags holds the literal request tags from the params argument.
+	Tags map[string]string `json:"tags,omitempty"`
+	// Checked is true when the response is passed to check().
+	Checked bool `json:"checked"`
+	Line    int  `json:"line"`
+}
+
+// DataFile is a file the script reads with open().
+type DataFile struct {
+	Path    string `json:"path"`
+	Binary  bool   `json:"binary,omitempty"`
+	Dynamic bool   `json:"dynamic,omitempty"`
+	Line    int    `json:"line"`
+}
+
+// Options holds the top-level load settings from the exported options.
+type Options struct {
+	VUs        string   `json:"vus,omitempty"`
+	Duration   string   `json:"duration,omitempty"`
+	Iterations string   `json:"iterations,omitempty"`
+	Stages     []Stage  `json:"stages,omitempty"`
+	OtherKeys  []string `json:"other_keys,omitempty"`
+	Line       int      `json:"line"`
+}
+
+// Info is the static summary of a script.
+type Info struct {
+	Imports    []Import    `json:"imports"`
+	Protocols  []string    `json:"protocols"`
+	Lifecycle  []string    `json:"lifecycle"`
+	Exports    []string    `json:"exported_functions"`
+	Options    *Options    `json:"options,omitempty"`
+	Scenarios  []Scenario  `json:"scenarios,omitempty"`
+	Thresholds []Threshold `json:"thresholds,omitempty"`
+	Endpoints  []Endpoint  `json:"endpoints"`
+	DataFiles  []DataFile  `json:"data_files,omitempty"`
+	EnvVars    []string    `json:"env_vars,omitempty"`
+	Groups     []string    `json:"groups,omitempty"`
+	Checks     int         `json:"checks"`
+
+	// options is the parsed options object, kept for other analyzers.
+	options Object
+}
+
+// RawOptions returns the parsed options object, or nil if the script does
+// not export a literal options object.
+func (i *Info) RawOptions() Object {
+	return i.options
+}
+
+//nolint:gochecknoglobals // Compiled once and reused.
+var (
+	importRe = regexp.MustCompile(
+		`(?m)^[ \t]*import\s+(?:([\w$*{}\s,]+?)\s+from\s+)?['"]([^'"]+)['"]`)
+	requireRe  = regexp.MustCompile(`(?:const|let|var)\s+([\w${}\s,]+?)\s*=\s*require\(\s*['"]([^'"]+)['"]\s*\)`)
+	exportFnRe = regexp.MustCompile(
+		`export\s+(?:(default)\s+)?(?:async\s+)?function\s*\*?\s*([\w$]*)|export\s+(?:const|let|var)\s+([\w$]+)\s*=\s*(?:async\s+)?(?:function|\()`)
+	exportDefaultRe = regexp.MustCompile(`export\s+default\s`)
+	optionsRe       = regexp.MustCompile(`export\s+(?:const|let|var)\s+options\s*=`)
+	httpCallRe      = regexp.MustCompile(
+		`\b(?:http|session)\.(get|post|put|patch|del|head|options|request|asyncRequest|batch)\s*\(`)
+	wsCallRe      = regexp.MustCompile(`\bnew\s+WebSocket\s*\(|\bws\.connect\s*\(`)
+	grpcCallRe    = regexp.MustCompile(`\b\w+\.connect\s*\(`)
+	browserCallRe = regexp.MustCompile(`\bpage\.goto\s*\(`)
+	openCallRe    = regexp.MustCompile(`(?:^|[^\w$.])(?:fs\.)?open\s*\(`)
+	envRe         = regexp.MustCompile(`__ENV\.([A-Za-z_][\w]*)|__ENV\[\s*['"]([^'"]+)['"]\s*\]`)
+	groupRe       = regexp.MustCompile("\\bgroup\\s*\\(\\s*(?:'([^']*)'|\"([^\"]*)\"|`([^`]*)`)")
+	checkRe       = regexp.MustCompile(`\bcheck\s*\(`)
+)
+
+// protocolModules maps imported modules to the protocol they provide.
+//
+//nolint:gochecknoglobals // Read-only lookup table.
+var protocolModules = map[string]string{
+	"k6/http":                    "http",
+	"k6/ws":                      "websocket",
+	"k6/websockets":              "websocket",
+	"k6/experimental/websockets": "websocket",
+	"k6/net/grpc":                "grpc",
+	"k6/browser":                 "browser",
+	"k6/experimental/browser":    "browser",
+	"k6/experimental/redis":      "redis",
+	"k6/experimental/streams":    "streams",
+}
+
+// lifecycleFunctions are the exports k6 calls itself.
+//
+//nolint:gochecknoglobals // Read-only lookup table.
+var lifecycleFunctions = map[string]bool{
+	"setup": true, "default": true, "teardown": true, "handleSummary": true,
+}
+
+// Analyze statically summarizes the script.
+func Analyze(script string) *Info {
+	src := maskComments(script)
+	lines := newLineIndex(src)
+	info := &Info{}
+
+	analyzeImports(src, lines, info)
+	analyzeExports(src, info)
+	analyzeOptions(src, lines, info)
+	analyzeEndpoints(src, lines, info)
+	analyzeDataFiles(src, lines, info)
+
+	envVars := make(map[string]bool)
+	for _, m := range envRe.FindAllStringSubmatch(src, -1) {
+		envVars[m[1]+m[2]] = true
+	}
+	info.EnvVars = sortedKeys(envVars)
+
+	seenGroups := make(map[string]bool)
+	for _, m := range groupRe.FindAllStringSubmatch(src, -1) {
+		name := m[1] + m[2] + m[3]
+		if !seenGroups[name] {
+			seenGroups[name] = true
+			info.Groups = append(info.Groups, name)
+		}
+	}
+	info.Checks = len(checkRe.FindAllStringIndex(src, -1))
+
+	return info
+}
+
+func analyzeImports(src string, lines lineIndex, info *Info) {
+	protocols := make(map[string]bool)
+	add := func(names, module string, offset int) {
+		imp := Import{Module: module, Kind: importKind(module), Line: lines.line(offset)}
+		for _, n := range strings.FieldsFunc(names, func(r rune) bool {
+			return r == ',' || r == '{' || r == '}' || r == ' ' || r == '\n' || r == '\t' || r == '\r'
+		}) {
+			if n != "as" && n != "*" {
+				imp.Names = append(imp.Names, n)
+			}
+		}
+		info.Imports = append(info.Imports, imp)
+		if p, ok := protocolModules[module]; ok {
+			protocols[p] = true
+		}
+	}
+
+	for _, m := range importRe.FindAllStringSubmatchIndex(src, -1) {
+		names := ""
+		if m[2] >= 0 {
+			names = src[m[2]:m[3]]
+		}
+		add(names, src[m[4]:m[5]], m[0])
+	}
+	for _, m := range requireRe.FindAllStringSubmatchIndex(src, -1) {
+		add(src[m[2]:m[3]], src[m[4]:m[5]], m[0])
+	}
+
+	info.Protocols = sortedKeys(protocols)
+}
+
+// importKind classifies a module specifier.
+func importKind(module string) string {
+	switch {
+	case strings.HasPrefix(module, "k6/x/"):
+		return "extension"
+	case strings.HasPrefix(module, "k6/experimental/"):
+		return "experimental"
+	case module == "k6" || strings.HasPrefix(module, "k6/"):
+		return "builtin"
+	case strings.HasPrefix(module, "https://jslib.k6.io/"):
+		return "jslib"
+	case strings.HasPrefix(module, "http://") || strings.HasPrefix(module, "https://"):
+		return "remote"
+	case strings.HasPrefix(module, "./") || strings.HasPrefix(module, "../") || strings.HasPrefix(module, "/") ||
+		strings.HasPrefix(module, "file://"):
+		return "local"
+	default:
+		return "other"
+	}
+}
+
+func analyzeExports(src string, info *Info) {
+	seen := make(map[string]bool)
+	for _, m := range exportFnRe.FindAllStringSubmatch(src, -1) {
+		name := m[2]
+		switch {
+		case m[1] == "default":
+			name = "default"
+		case m[3] != "":
+			name = m[3]
+		}
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		info.Exports = append(info.Exports, name)
+		if lifecycleFunctions[name] {
+			info.Lifecycle = append(info.Lifecycle, name)
+		}
+	}
+	// export default () => {} is not matched above.
+	if !seen["default"] && exportDefaultRe.MatchString(src) {
+		info.Exports = append(info.Exports, "default")
+		info.Lifecycle = append(info.Lifecycle, "default")
+	}
+}
+
+func analyzeOptions(src string, lines lineIndex, info *Info) {
+	loc := optionsRe.FindStringIndex(src)
+	if loc == nil {
+		return
+	}
+	value, _ := parseValue(src, loc[1])
+	obj, ok := value.(Object)
+	if !ok {
+		return
+	}
+	info.options = obj
+
+	opts := &Options{Line: lines.line(loc[0])}
+	for _, p := range obj {
+		switch p.Key {
+		case "vus":
+			opts.VUs = StringValue(p.Value)
+		case "duration":
+			opts.Duration = StringValue(p.Value)
+		case "iterations":
+			opts.Iterations = StringValue(p.Value)
+		case "stages":
+			opts.Stages = parseStages(p.Value)
+		case "scenarios":
+			info.Scenarios = parseScenarios(p.Value)
+		case "thresholds":
+			info.Thresholds = parseThresholds(p.Value)
+		default:
+			opts.OtherKeys = append(opts.OtherKeys, p.Key)
+		}
+	}
+	info.Options = opts
+}
+
+func parseStages(v any) []Stage {
+	arr, ok := v.([]any)
+	if !ok {
+		return nil
+	}
+	stages := make([]Stage, 0, len(arr))
+	for _, item := range arr {
+		obj, ok := item.(Object)
+		if !ok {
+			continue
+		}
+		var s Stage
+		if d, ok := obj.Get("duration"); ok {
+			s.Duration = StringValue(d)
+		}
+		if t, ok := obj.Get("target"); ok {
+			s.Target = StringValue(t)
+		}
+		stages = append(stages, s)
+	}
+	return stages
+}
+
+func parseScenarios(v any) []Scenario {
+	obj, ok := v.(Object)
+	if !ok {
+		return nil
+	}
+	scenarios := make([]Scenario, 0, len(obj))
+	for _, p := range obj {
+		sc := Scenario{Name: p.Key}
+		body, ok := p.Value.(Object)
+		if !ok {
+			scenarios = append(scenarios, sc)
+			continue
+		}
+		for _, field := range body {
+			switch field.Key {
+			case "executor":
+				sc.Executor = StringValue(field.Value)
+			case "exec":
+				sc.Exec = StringValue(field.Value)
+			case "startTime":
+				sc.StartTime = StringValue(field.Value)
+			case "stages":
+				sc.Stages = parseStages(field.Value)
+			default:
+				if sc.Settings == nil {
+					sc.Settings = make(map[string]string)
+				}
+				sc.Settings[field.Key] = StringValue(field.Value)
+			}
+		}
+		scenarios = append(scenarios, sc)
+	}
+	return scenarios
+}
+
+func parseThresholds(v any) []Threshold {
+	obj, ok := v.(Object)
+	if !ok {
+		return nil
+	}
+	thresholds := make([]Threshold, 0, len(obj))
+	for _, p := range obj {
+		th := Threshold{Metric: p.Key}
+		items, isList := p.Value.([]any)
+		if !isList {
+			items = []any{p.Value}
+		}
+		for _, item := range items {
+			if o, ok := item.(Object); ok {
+				if expr, ok := o.Get("threshold"); ok {
+					th.Expressions = append(th.Expressions, StringValue(expr))
+				}
+				if abort, ok := o.Get("abortOnFail"); ok && abort == true {
+					th.AbortOnFail = true
+				}
+				continue
+			}
+			th.Expressions = append(th.Expressions, StringValue(item))
+		}
+		thresholds = append(thresholds, th)
+	}
+	return thresholds
+}
+
+func analyzeEndpoints(src string, lines lineIndex, info *Info) {
+	for _, m := range httpCallRe.FindAllStringSubmatchIndex(src, -1) {
+		method := src[m[2]:m[3]]
+		args := parseArgs(src, m[1]-1)
+		line := lines.line(m[0])
+		checked := isChecked(src, m[0], skipBalanced(src, m[1]-1))
+
+		var endpoints []Endpoint
+		switch method {
+		case "batch":
+			if len(args) > 0 {
+				endpoints = batchEndpoints(args[0], line)
+			}
+		case "request", "asyncRequest":
+			if len(args) >= 2 {
+				endpoints = append(endpoints,
+					newEndpoint("http", strings.ToUpper(StringValue(args[0])), args[1], argAt(args, 3), line))
+			}
+		default:
+			if len(args) >= 1 {
+				verb := strings.ToUpper(method)
+				if verb == "DEL" {
+					verb = "DELETE"
+				}
+				paramsIndex := 2
+				if verb == "GET" || verb == "HEAD" {
+					paramsIndex = 1
+				}
+				endpoints = append(endpoints, newEndpoint("http", verb, args[0], argAt(args, paramsIndex), line))
+			}
+		}
+		for i := range endpoints {
+			endpoints[i].Checked = checked
+		}
+		info.Endpoints = append(info.Endpoints, endpoints...)
+	}
+
+	for _, call := range []struct {
+		re       *regexp.Regexp
+		protocol string
+	}{
+		{wsCallRe, "websocket"},
+		{browserCallRe, "browser"},
+	} {
+		for _, m := range call.re.FindAllStringIndex(src, -1) {
+			if args := parseArgs(src, m[1]-1); len(args) > 0 {
+				info.Endpoints = append(info.Endpoints, newEndpoint(call.protocol, "", args[0], nil, lines.line(m[0])))
+			}
+		}
+	}
+
+	if containsString(info.Protocols, "grpc") {
+		for _, m := range grpcCallRe.FindAllStringIndex(src, -1) {
+			if strings.HasPrefix(src[m[0]:], "ws.") {
+				continue
+			}
+			if args := parseArgs(src, m[1]-1); len(args) > 0 {
+				info.Endpoints = append(info.Endpoints, newEndpoint("grpc", "", args[0], nil, lines.line(m[0])))
+			}
+		}
+	}
+
+	sort.SliceStable(info.Endpoints, func(i, j int) bool { return info.Endpoints[i].Line < info.Endpoints[j].Line })
+}
+
+func batchEndpoints(v any, line int) []Endpoint {
+	var items []any
+	switch t := v.(type) {
+	case []any:
+		items = t
+	case Object:
+		for _, p := range t {
+			items = append(items, p.Value)
+		}
+	default:
+		return nil
+	}
+
+	var endpoints []Endpoint
+	for _, item := range items {
+		switch t := item.(type) {
+		case []any:
+			if len(t) >= 2 {
+				endpoints = append(endpoints, newEndpoint("http", strings.ToUpper(StringValue(t[0])), t[1], argAt(t, 3), line))
+			}
+		case Object:
+			method := "GET"
+			if m, ok := t.Get("method"); ok {
+				method = strings.ToUpper(StringValue(m))
+			}
+			if u, ok := t.Get("url"); ok {
+				params, _ := t.Get("params")
+				endpoints = append(endpoints, newEndpoint("http", method, u, params, line))
+			}
+		default:
+			endpoints = append(endpoints, newEndpoint("http", "GET", t, nil, line))
+		}
+	}
+	return endpoints
+}
+
+func newEndpoint(protocol, method string, target, params any, line int) Endpoint {
+	url, literal := target.(string)
+	if !literal {
+		url = StringValue(target)
+	}
+	e := Endpoint{Protocol: protocol, Method: method, URL: url, Dynamic: !literal, Line: line}
+	if obj, ok := params.(Object); ok {
+		if tags, ok := obj.Get("tags"); ok {
+			if tagObj, ok := tags.(Object); ok {
+				e.Tags = make(map[string]string, len(tagObj))
+				for _, p := range tagObj {
+					e.Tags[p.Key] = StringValue(p.Value)
+				}
+			}
+		}
+	}
+	return e
+}
+
+func argAt(args []any, i int) any {
+	if i < len(args) {
+		return args[i]
+	}
+	return nil
+}
+
+//nolint:gochecknoglobals // Compiled once and reused.
+var (
+	assignedRe    = regexp.MustCompile(`([\w$]+)\s*=\s*(?:await)?$`)
+	checkPrefixRe = regexp.MustCompile(`\bcheck\(\s*$`)
+)
+
+// isChecked reports whether the response of the call spanning [start, end)
+// is passed to check(), either directly or through the variable it is
+// assigned to, before that variable is reassigned.
+func isChecked(src string, start, end int) bool {
+	lineStart := strings.LastIndexByte(src[:start], '\n') + 1
+	before := strings.TrimRight(src[lineStart:start], " \t")
+	if checkPrefixRe.MatchString(before) {
+		return true
+	}
+
+	m := assignedRe.FindStringSubmatch(before)
+	if m == nil {
+		return false
+	}
+	rest := src[end:]
+	name := regexp.QuoteMeta(m[1])
+	checkAt := regexp.MustCompile(`\bcheck\(\s*` + name + `\b`).FindStringIndex(rest)
+	if checkAt == nil {
+		return false
+	}
+	reassignAt := regexp.MustCompile(`\b` + name + `\s*=[^=]`).FindStringIndex(rest)
+	return reassignAt == nil || checkAt[0] < reassignAt[0]
+}
+
+func analyzeDataFiles(src string, lines lineIndex, info *Info) {
+	for _, m := range openCallRe.FindAllStringIndex(src, -1) {
+		args := parseArgs(src, m[1]-1)
+		if len(args) == 0 {
+			continue
+		}
+		path, literal := args[0].(string)
+		if !literal {
+			path = StringValue(args[0])
+		}
+		df := DataFile{Path: path, Dynamic: !literal, Line: lines.line(m[0])}
+		if len(args) > 1 && StringValue(args[1]) == "b" {
+			df.Binary = true
+		}
+		info.DataFiles = append(info.DataFiles, df)
+	}
+}
+
+// parseArgs parses the call arguments starting at the '(' at offset i.
+func parseArgs(src string, i int) []any {
+	end := skipBalanced(src, i)
+	inner := src[:end-1]
+	var args []any
+	pos := i + 1
+	for {
+		pos = skipSpace(inner, pos)
+		if pos >= len(inner) {
+			return args
+		}
+		if inner[pos] == ',' {
+			pos++
+			continue
+		}
+		v, next := parseValue(inner, pos)
+		if next <= pos {
+			return args
+		}
+		args = append(args, v)
+		pos = next
+	}
+}
+
+// lineIndex maps byte offsets to 1-based line numbers.
+type lineIndex []int
+
+func newLineIndex(src string) lineIndex {
+	idx := lineIndex{0}
+	for i := 0; i < len(src); i++ {
+		if src[i] == '\n' {
+			idx = append(idx, i+1)
+		}
+	}
+	return idx
+}
+
+func (l lineIndex) line(offset int) int {
+	return sort.Search(len(l), func(i int) bool { return l[i] > offset })
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,214 @@
+package scriptinfo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testScript = `import http from 'k6/http';
+import { check, group, sleep } from 'k6';
+import { SharedArray } from 'k6/data';
+import { randomItem } from 'https://jslib.k6.io/k6-utils/1.4.0/index.js';
+
+// http.get('https://commented.example.com');
+const users = new SharedArray('users', () => JSON.parse(open('./data/users.json')));
+const BASE_URL = __ENV.BASE_URL || 'https://test.k6.io';
+
+export const options = {
+  scenarios: {
+    browse: {
+      executor: 'ramping-vus',
+      startVUs: 0,
+      stages: [
+        { duration: '1m', target: 20 },
+        { duration: '30s', target: 0 },
+      ],
+      exec: 'browse',
+    },
+    api: {
+      executor: 'constant-arrival-rate',
+      rate: 50,
+      timeUnit: '1s',
+      duration: '2m',
+      preAllocatedVUs: 10,
+      startTime: '1m30s',
+    },
+  },
+  thresholds: {
+    http_req_duration: ['p(95)<500', 'p(99)<1500'],
+    'http_req_failed{scenario:api}': [{ threshold: 'rate<0.01', abortOnFail: true }],
+  },
+  insecureSkipTLSVerify: true,
+};
+
+export function setup() {
+  return { token: __ENV["API_TOKEN"] };
+}
+
+export function browse() {
+  group('home page', function () {
+    const res = http.get('https://test.k6.io/');
+    check(res, { 'status is 200': (r) => r.status === 200 });
+  });
+  sleep(1);
+}
+
+export default function (data) {
+  const user = randomItem(users);
+  http.post(` + "`${BASE_URL}/login`" + `, JSON.stringify(user), { headers: { 'Content-Type': 'application/json' }, tags: { name: 'login' } });
+  http.batch([
+    ['GET', 'https://test.k6.io/contacts.php'],
+    { method: 'PUT', url: BASE_URL + '/items/1' },
+  ]);
+  http.request('DELETE', 'https://test.k6.io/items/1');
+}
+`
+
+func TestAnalyze(t *testing.T) {
+	t.Parallel()
+
+	info := Analyze(testScript)
+
+	require.Len(t, info.Imports, 4)
+	assert.Equal(t, Import{Module: "k6/http", Names: []string{"http"}, Kind: "builtin", Line: 1}, info.Imports[0])
+	assert.Equal(t, []string{"check", "group", "sleep"}, info.Imports[1].Names)
+	assert.Equal(t, "jslib", info.Imports[3].Kind)
+	assert.Equal(t, []string{"http"}, info.Protocols)
+
+	assert.Equal(t, []string{"setup", "browse", "default"}, info.Exports)
+	assert.Equal(t, []string{"setup", "default"}, info.Lifecycle)
+
+	require.NotNil(t, info.Options)
+	assert.Equal(t, []string{"insecureSkipTLSVerify"}, info.Options.OtherKeys)
+
+	require.Len(t, info.Scenarios, 2)
+	assert.Equal(t, Scenario{
+		Name:     "browse",
+		Executor: "ramping-vus",
+		Exec:     "browse",
+		Stages:   []Stage{{Duration: "1m", Target: "20"}, {Duration: "30s", Target: "0"}},
+		Settings: map[string]string{"startVUs": "0"},
+	}, info.Scenarios[0])
+	assert.Equal(t, "1m30s", info.Scenarios[1].StartTime)
+	assert.Equal(t, "50", info.Scenarios[1].Settings["rate"])
+
+	assert.Equal(t, []Threshold{
+		{Metric: "http_req_duration", Expressions: []string{"p(95)<500", "p(99)<1500"}},
+		{Metric: "http_req_failed{scenario:api}", Expressions: []string{"rate<0.01"}, AbortOnFail: true},
+	}, info.Thresholds)
+
+	assert.Equal(t, []Endpoint{
+		{Protocol: "http", Method: "GET", URL: "https://test.k6.io/", Checked: true, Line: 43},
+		{Protocol: "http", Method: "POST", URL: "`${BASE_URL}/login`", Dynamic: true, Tags: map[string]string{"name": "login"}, Line: 51},
+		{Protocol: "http", Method: "GET", URL: "https://test.k6.io/contacts.php", Line: 52},
+		{Protocol: "http", Method: "PUT", URL: "BASE_URL + '/items/1'", Dynamic: true, Line: 52},
+		{Protocol: "http", Method: "DELETE", URL: "https://test.k6.io/items/1", Line: 56},
+	}, info.Endpoints)
+
+	assert.Equal(t, []DataFile{{Path: "./data/users.json", Line: 7}}, info.DataFiles)
+	assert.Equal(t, []string{"API_TOKEN", "BASE_URL"}, info.EnvVars)
+	assert.Equal(t, []string{"home page"}, info.Groups)
+	assert.Equal(t, 1, info.Checks)
+}
+
+func TestAnalyzeTopLevelOptions(t *testing.T) {
+	t.Parallel()
+
+	info := Analyze(`import ws from 'k6/ws';
+export let options = { vus: 10, duration: '30s', stages: [{ duration: '10s', target: 5 }] };
+export default () => {
+  ws.connect('wss://echo.websocket.org', null, function (socket) {});
+};`)
+
+	require.NotNil(t, info.Options)
+	assert.Equal(t, "10", info.Options.VUs)
+	assert.Equal(t, "30s", info.Options.Duration)
+	assert.Equal(t, []Stage{{Duration: "10s", Target: "5"}}, info.Options.Stages)
+	assert.Equal(t, []string{"default"}, info.Lifecycle)
+	assert.Equal(t, []string{"websocket"}, info.Protocols)
+	require.Len(t, info.Endpoints, 1)
+	assert.Equal(t, "wss://echo.websocket.org", info.Endpoints[0].URL)
+}
+
+func TestIsChecked(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]bool{
+		"const res = http.get('u');\ncheck(res, {});":                     true,
+		"check(http.get('u'), {});":                                       true,
+		"let res = await http.asyncRequest('GET', 'u');\ncheck(res, {});": true,
+		"http.get('u');\ncheck(other, {});":                               false,
+		"let res = http.get('u');\nres = http.get('v');\ncheck(res, {});": false,
+	}
+	for src, want := range tests {
+		info := Analyze("import http from 'k6/http';\n" + src)
+		require.NotEmpty(t, info.Endpoints, src)
+		assert.Equal(t, want, info.Endpoints[0].Checked, src)
+	}
+}
+
+func TestMaskComments(t *testing.T) {
+	t.Parallel()
+
+	src := "const a = 'http://x'; // comment\nconst re = /\\/\\//g; /* block\n */ const b = 1;"
+	masked := maskComments(src)
+	assert.Len(t, masked, len(src))
+	assert.Contains(t, masked, "'http://x'")
+	assert.Contains(t, masked, `/\/\//g`)
+	assert.NotContains(t, masked, "comment")
+	assert.NotContains(t, masked, "block")
+	assert.Contains(t, masked, "const b = 1;")
+}
+
+func TestChecks(t *testing.T) {
+	t.Parallel()
+
+	checks := Checks(`import http from 'k6/http';
+import { check, group } from 'k6';
+
+export default function () {
+  group('login', function () {
+    const res = http.post('https://test.k6.io/login');
+    check(res, { 'status is 200': (r) => r.status === 200 });
+    group("profile", () => {
+      check(res, {
+        'has token': (r) => r.json('token') !== '',
+        [` + "`status ${expected}`" + `]: (r) => r.status === expected,
+      }, { kind: 'auth' });
+    });
+  });
+  // check(res, { 'commented out': () => true });
+  check(http.get('https://test.k6.io'), { 'home ok': (r) => r.status === 200 });
+}
+`)
+	require.Len(t, checks, 4)
+	assert.Equal(t, Check{Name: "status is 200", Group: "::login", Line: 7}, checks[0])
+	assert.Equal(t, Check{Name: "has token", Group: "::login::profile", Tags: map[string]string{"kind": "auth"}, Line: 9},
+		checks[1])
+	assert.True(t, checks[2].Dynamic)
+	assert.Equal(t, Check{Name: "home ok", Line: 16}, checks[3])
+}
+
+func TestMembers(t *testing.T) {
+	t.Parallel()
+
+	members := Members(`import http from 'k6/http';
+import { browser } from 'k6/browser';
+
+export default async function () {
+  // http.del('commented out');
+  const res = http.get('https://test.k6.io');
+  http.get('https://test.k6.io/again');
+  console.log('http.batch is not called');
+  const page = await browser.newPage();
+  await page.goto(res.url);
+  other.http.post('u');
+}
+`, []string{"http", "browser"})
+	assert.Equal(t, []Member{
+		{Object: "http", Name: "get", Line: 6},
+		{Object: "browser", Name: "newPage", Line: 9},
+	}, members)
+}
@@ -0,0 +1,66 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+)
+
+// scrubHandler removes secret values from log records before passing them on.
+type scrubHandler struct {
+	next     slog.Handler
+	registry *Registry
+}
+
+// Handler wraps next so that secret values never reach the log output.
+func (r *Registry) Handler(next slog.Handler) slog.Handler {
+	if r.Empty() {
+		return next
+	}
+	return &scrubHandler{next: next, registry: r}
+}
+
+func (h *scrubHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *scrubHandler) Handle(ctx context.Context, record slog.Record) error {
+	scrubbed := slog.NewRecord(record.Time, record.Level, h.registry.Scrub(record.Message), record.PC)
+	record.Attrs(func(a slog.Attr) bool {
+		scrubbed.AddAttrs(h.scrubAttr(a))
+		return true
+	})
+	return h.next.Handle(ctx, scrubbed)
+}
+
+func (h *scrubHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	scrubbed := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		scrubbed[i] = h.scrubAttr(a)
+	}
+	return &scrubHandler{next: h.next.WithAttrs(scrubbed), registry: h.registry}
+}
+
+func (h *scrubHandler) WithGroup(name string) slog.Handler {
+	return &scrubHandler{next: h.next.WithGroup(name), registry: h.registry}
+}
+
+func (h *scrubHandler) scrubAttr(a slog.Attr) slog.Attr {
+	v := a.Value.Resolve()
+	switch v.Kind() {
+	case slog.KindString:
+		return slog.String(a.Key, h.registry.Scrub(v.String()))
+	case slog.KindGroup:
+		group := v.Group()
+		attrs := make([]any, len(group))
+		for i, ga := range group {
+			attrs[i] = h.scrubAttr(ga)
+		}
+		return slog.Group(a.Key, attrs...)
+	case slog.KindAny:
+		if text := fmt.Sprint(v.Any()); h.registry.Scrub(text) != text {
+			return slog.String(a.Key, h.registry.Scrub(text))
+		}
+	}
+	return slog.Attr{Key: a.Key, Value: v}
+}
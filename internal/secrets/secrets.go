@@ -0,0 +1,171 @@
+// Package secrets holds named secrets configured on the server. Tools refer
+// to secrets by name; their values are injected into k6 at run time and
+// scrubbed from everything the server logs or returns.
+package secrets
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/grafana/mcp-k6/internal/workspace"
+)
+
+// EnvPrefix marks server environment variables that define secrets:
+// MCP_K6_SECRET_API_TOKEN defines the secret API_TOKEN.
+const EnvPrefix = "MCP_K6_SECRET_"
+
+// minLength is the shortest value that can be scrubbed without masking
+// unrelated text.
+const minLength = 4
+
+// ErrUnknownSecret is returned when a tool call names a secret that is not configured.
+var ErrUnknownSecret = errors.New("unknown secret")
+
+//nolint:gochecknoglobals // Compiled once and reused.
+var nameRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Registry maps secret names to values. A nil *Registry holds no secrets.
+type Registry struct {
+	values map[string]string
+	// forms lists the ways a value may appear in output, longest first:
+	// as is, and escaped as in a JSON string.
+	forms map[string][]string
+	// order lists the names by decreasing value length, so that a secret
+	// containing another one is scrubbed first.
+	order []string
+}
+
+// New returns a Registry holding values.
+func New(values map[string]string) (*Registry, error) {
+	r := &Registry{values: make(map[string]string, len(values)), forms: make(map[string][]string, len(values))}
+	for name, value := range values {
+		if !nameRe.MatchString(name) {
+			return nil, fmt.Errorf("invalid secret name %q: use letters, digits and underscores", name)
+		}
+		if len(value) < minLength {
+			return nil, fmt.Errorf("secret %s is shorter than %d characters and cannot be scrubbed reliably",
+				name, minLength)
+		}
+		r.values[name] = value
+		r.forms[name] = forms(value)
+		r.order = append(r.order, name)
+	}
+	sort.Slice(r.order, func(i, j int) bool {
+		a, b := r.values[r.order[i]], r.values[r.order[j]]
+		if len(a) != len(b) {
+			return len(a) > len(b)
+		}
+		return r.order[i] < r.order[j]
+	})
+	return r, nil
+}
+
+// Load builds a Registry from MCP_K6_SECRET_* variables of environ and, when
+// file is not empty, a .env file of NAME=VALUE lines. File entries win.
+func Load(environ []string, file string) (*Registry, error) {
+	values := make(map[string]string)
+	for _, kv := range environ {
+		key, value, ok := strings.Cut(kv, "=")
+		if name, found := strings.CutPrefix(key, EnvPrefix); ok && found && name != "" {
+			values[name] = value
+		}
+	}
+
+	if file != "" {
+		//nolint:forbidigo // The secrets file is operator configuration, outside any workspace root.
+		data, err := os.ReadFile(file) // #nosec G304 -- path comes from server configuration
+		if err != nil {
+			return nil, fmt.Errorf("reading secrets file: %w", err)
+		}
+		parsed, err := workspace.ParseEnv(data)
+		if err != nil {
+			return nil, fmt.Errorf("parsing secrets file: %w", err)
+		}
+		for name, value := range parsed {
+			values[name] = value
+		}
+	}
+
+	return New(values)
+}
+
+// Names returns the configured secret names, sorted.
+func (r *Registry) Names() []string {
+	if r == nil {
+		return nil
+	}
+	names := append([]string{}, r.order...)
+	sort.Strings(names)
+	return names
+}
+
+// Resolve returns the values of the named secrets.
+func (r *Registry) Resolve(names []string) (map[string]string, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+	values := make(map[string]string, len(names))
+	for _, name := range names {
+		value, ok := "", false
+		if r != nil {
+			value, ok = r.values[name]
+		}
+		if !ok {
+			return nil, fmt.Errorf("%w %q (configured: %s)", ErrUnknownSecret, name, r.describe())
+		}
+		values[name] = value
+	}
+	return values, nil
+}
+
+func (r *Registry) describe() string {
+	if names := r.Names(); len(names) > 0 {
+		return strings.Join(names, ", ")
+	}
+	return "none"
+}
+
+// Scrub replaces every secret value in s with a [SECRET:NAME] marker.
+func (r *Registry) Scrub(s string) string {
+	if r == nil {
+		return s
+	}
+	for _, name := range r.order {
+		for _, form := range r.forms[name] {
+			if strings.Contains(s, form) {
+				s = strings.ReplaceAll(s, form, "[SECRET:"+name+"]")
+			}
+		}
+	}
+	return s
+}
+
+// forms returns value and the distinct forms encoding/json escapes it to in
+// a string, with and without HTML escaping, longest first.
+func forms(value string) []string {
+	out := []string{value}
+	for _, escapeHTML := range []bool{true, false} {
+		var b strings.Builder
+		enc := json.NewEncoder(&b)
+		enc.SetEscapeHTML(escapeHTML)
+		_ = enc.Encode(value)
+		escaped := strings.TrimSuffix(b.String(), "\n")
+		escaped = escaped[1 : len(escaped)-1]
+		if !slices.Contains(out, escaped) {
+			out = append(out, escaped)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return len(out[i]) > len(out[j]) })
+	return out
+}
+
+// Empty reports whether the registry holds no secrets.
+func (r *Registry) Empty() bool {
+	return r == nil || len(r.values) == 0
+}
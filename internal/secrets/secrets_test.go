@@ -0,0 +1,85 @@
+package secrets
+
+import (
+	"bytes"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad(t *testing.T) {
+	t.Parallel()
+
+	file := filepath.Join(t.TempDir(), "secrets.env")
+	require.NoError(t, os.WriteFile(file, []byte("API_TOKEN=from-file-token\nDB_PASSWORD='p@ss word'\n"), 0o600))
+
+	r, err := Load([]string{
+		"PATH=/bin",
+		"MCP_K6_SECRET_API_TOKEN=from-env-token",
+		"MCP_K6_SECRET_CLIENT_SECRET=abcd1234",
+		"MCP_K6_SECRET_=ignored",
+	}, file)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"API_TOKEN", "CLIENT_SECRET", "DB_PASSWORD"}, r.Names())
+	values, err := r.Resolve([]string{"API_TOKEN"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"API_TOKEN": "from-file-token"}, values)
+
+	_, err = r.Resolve([]string{"NOPE"})
+	require.ErrorIs(t, err, ErrUnknownSecret)
+	assert.Contains(t, err.Error(), "API_TOKEN, CLIENT_SECRET, DB_PASSWORD")
+}
+
+func TestNewRejectsShortValues(t *testing.T) {
+	t.Parallel()
+
+	_, err := New(map[string]string{"PIN": "123"})
+	require.Error(t, err)
+	_, err = New(map[string]string{"bad-name": "value1234"})
+	require.Error(t, err)
+}
+
+func TestScrub(t *testing.T) {
+	t.Parallel()
+
+	r, err := New(map[string]string{"SHORT": "abcd", "LONG": "xxabcdxx"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "token [SECRET:LONG] and [SECRET:SHORT]", r.Scrub("token xxabcdxx and abcd"))
+	assert.Equal(t, "clean", r.Scrub("clean"))
+
+	// Values escaped in JSON strings are scrubbed too
+	r, err = New(map[string]string{"QUOTED": `p"w<d>&`})
+	require.NoError(t, err)
+	assert.Equal(t, `{"a":"[SECRET:QUOTED]"}`, r.Scrub(`{"a":"p\"w\u003cd\u003e\u0026"}`))
+	assert.Equal(t, `{"a":"[SECRET:QUOTED]"}`, r.Scrub(`{"a":"p\"w<d>&"}`))
+
+	var nilRegistry *Registry
+	assert.Equal(t, "abcd", nilRegistry.Scrub("abcd"))
+	assert.True(t, nilRegistry.Empty())
+}
+
+func TestHandler(t *testing.T) {
+	t.Parallel()
+
+	r, err := New(map[string]string{"TOKEN": "s3cr3t-value"})
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	logger := slog.New(r.Handler(slog.NewTextHandler(&buf, nil))).With(slog.String("preset", "s3cr3t-value"))
+	logger.Info("calling with s3cr3t-value",
+		slog.String("header", "Bearer s3cr3t-value"),
+		slog.Group("request", slog.String("body", "token=s3cr3t-value")),
+		slog.Any("args", []string{"--env", "TOKEN=s3cr3t-value"}),
+		slog.Int("count", 1))
+
+	out := buf.String()
+	assert.NotContains(t, out, "s3cr3t-value")
+	assert.Contains(t, out, "[SECRET:TOKEN]")
+	assert.Contains(t, out, "count=1")
+}
@@ -0,0 +1,135 @@
+package slo
+
+import (
+	"math"
+	"time"
+)
+
+// Error budget risks.
+const (
+	// RiskOK means the budget holds and is not trending worse.
+	RiskOK = "ok"
+	// RiskAtRisk means most of the budget is spent, or it is spent faster
+	// in recent runs than in earlier ones.
+	RiskAtRisk = "at_risk"
+	// RiskExhausted means the runs spent more than the whole budget.
+	RiskExhausted = "exhausted"
+)
+
+// Trends of a series of runs.
+const (
+	Improving = "improving"
+	Stable    = "stable"
+	Worsening = "worsening"
+)
+
+const (
+	// atRiskConsumed is the budget consumption, in percent, from which the
+	// budget is at risk.
+	atRiskConsumed = 75
+	// trendChange is the relative change between the older and the newer
+	// half of the runs that makes a trend.
+	trendChange = 0.2
+)
+
+// Observation is the verdict of one run against an SLO.
+type Observation struct {
+	RunID     string    `json:"run_id"`
+	Schedule  string    `json:"schedule_id,omitempty"`
+	StartedAt time.Time `json:"started_at"`
+	Verdict
+}
+
+// Budget is the error budget of an SLO across a series of runs.
+type Budget struct {
+	Runs     int `json:"runs"`
+	Met      int `json:"met"`
+	Breached int `json:"breached"`
+	// Consumed is the share of the error budget the runs spent, in
+	// percent: the average burn rate of the runs with one, each run
+	// counting equally. Latency objectives have none.
+	Consumed  *float64 `json:"consumed_percent,omitempty"`
+	Remaining *float64 `json:"remaining_percent,omitempty"`
+	// Trend compares the newer half of the runs with the older half, on
+	// burn rate when known and on the share of breaching runs otherwise.
+	Trend string `json:"trend,omitempty"`
+	Risk  string `json:"risk"`
+}
+
+// ErrorBudget tracks the error budget across observations, oldest first.
+// Observations without data are left out.
+func ErrorBudget(observations []Observation) Budget {
+	var b Budget
+	var burns, breaches []float64
+	for _, o := range observations {
+		if o.Verdict.Verdict == NoData {
+			continue
+		}
+		b.Runs++
+		breach := 0.0
+		if o.Verdict.Verdict == Met {
+			b.Met++
+		} else {
+			b.Breached++
+			breach = 1
+		}
+		breaches = append(breaches, breach)
+		if o.BurnRate != nil {
+			burns = append(burns, *o.BurnRate)
+		}
+	}
+	if b.Runs == 0 {
+		b.Risk = RiskOK
+		return b
+	}
+
+	series := breaches
+	if len(burns) == b.Runs {
+		consumed := round(mean(burns) * 100)
+		remaining := math.Max(0, round(100-consumed))
+		b.Consumed, b.Remaining = &consumed, &remaining
+		series = burns
+	}
+	b.Trend = trend(series)
+
+	switch {
+	case b.Consumed != nil && *b.Consumed >= 100:
+		b.Risk = RiskExhausted
+	case b.Consumed != nil && *b.Consumed >= atRiskConsumed,
+		b.Trend == Worsening,
+		b.Consumed == nil && breaches[len(breaches)-1] > 0:
+		b.Risk = RiskAtRisk
+	default:
+		b.Risk = RiskOK
+	}
+	return b
+}
+
+// trend compares the mean of the newer half of values with the older half.
+func trend(values []float64) string {
+	if len(values) < 2 {
+		return ""
+	}
+	half := len(values) / 2
+	older, newer := mean(values[:half]), mean(values[len(values)-half:])
+	switch {
+	case newer > older && (older == 0 || (newer-older)/older > trendChange):
+		return Worsening
+	case newer < older && (older-newer)/older > trendChange:
+		return Improving
+	default:
+		return Stable
+	}
+}
+
+func mean(values []float64) float64 {
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+func round(v float64) float64 {
+	return math.Round(v*10) / 10
+}
@@ -0,0 +1,64 @@
+package slo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func observe(verdicts ...Verdict) []Observation {
+	observations := make([]Observation, 0, len(verdicts))
+	for _, v := range verdicts {
+		observations = append(observations, Observation{Verdict: v})
+	}
+	return observations
+}
+
+func burnVerdict(burn float64) Verdict {
+	v := Verdict{Verdict: Met, BurnRate: &burn}
+	if burn > 1 {
+		v.Verdict = Breached
+	}
+	return v
+}
+
+func TestErrorBudget(t *testing.T) {
+	t.Parallel()
+
+	b := ErrorBudget(observe(burnVerdict(0.2), burnVerdict(0.4), burnVerdict(0.6), burnVerdict(1.2)))
+	assert.Equal(t, 4, b.Runs)
+	assert.Equal(t, 3, b.Met)
+	assert.Equal(t, 1, b.Breached)
+	require.NotNil(t, b.Consumed)
+	assert.InDelta(t, 60.0, *b.Consumed, 0)
+	assert.InDelta(t, 40.0, *b.Remaining, 0)
+	assert.Equal(t, Worsening, b.Trend)
+	assert.Equal(t, RiskAtRisk, b.Risk)
+
+	b = ErrorBudget(observe(burnVerdict(0.5), burnVerdict(0.4), Verdict{Verdict: NoData}, burnVerdict(0.45)))
+	assert.Equal(t, 3, b.Runs)
+	assert.Equal(t, Stable, b.Trend)
+	assert.Equal(t, RiskOK, b.Risk)
+
+	b = ErrorBudget(observe(burnVerdict(3), burnVerdict(0.5)))
+	assert.InDelta(t, 175.0, *b.Consumed, 0)
+	assert.InDelta(t, 0.0, *b.Remaining, 0)
+	assert.Equal(t, Improving, b.Trend)
+	assert.Equal(t, RiskExhausted, b.Risk)
+}
+
+func TestErrorBudgetLatency(t *testing.T) {
+	t.Parallel()
+
+	b := ErrorBudget(observe(Verdict{Verdict: Met}, Verdict{Verdict: Met}, Verdict{Verdict: Breached}))
+	assert.Equal(t, 3, b.Runs)
+	assert.Nil(t, b.Consumed)
+	assert.Equal(t, Worsening, b.Trend)
+	assert.Equal(t, RiskAtRisk, b.Risk)
+
+	b = ErrorBudget(nil)
+	assert.Equal(t, 0, b.Runs)
+	assert.Equal(t, RiskOK, b.Risk)
+	assert.Empty(t, b.Trend)
+}
@@ -0,0 +1,227 @@
+// Package slo defines service level objectives for the requests of a k6
+// test, maps them to k6 thresholds, judges run results against them and
+// tracks their error budget across runs.
+package slo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Kind is what an SLO measures.
+type Kind string
+
+const (
+	// Latency objectives bound the share of requests slower than a duration.
+	Latency Kind = "latency"
+	// ErrorRate objectives bound the share of failed requests.
+	ErrorRate Kind = "error_rate"
+)
+
+// MaxSLOs is the maximum number of SLOs a registry holds.
+const MaxSLOs = 50
+
+var (
+	// ErrInvalid is returned for SLO definitions that cannot be applied.
+	ErrInvalid = errors.New("invalid SLO")
+	// ErrUnknown is returned for SLO names that are not defined.
+	ErrUnknown = errors.New("unknown SLO")
+)
+
+//nolint:gochecknoglobals // Compiled once and reused.
+var nameRe = regexp.MustCompile(`^[A-Za-z0-9][\w.-]{0,63}$`)
+
+// SLO is a service level objective: the percentage of requests, of an
+// endpoint or of the whole test, that must be good. A request is good when
+// it did not fail for error-rate objectives, and when it took less than
+// Latency for latency objectives.
+type SLO struct {
+	Name string `json:"name"`
+	Kind Kind   `json:"kind"`
+	// Objective is the percentage of good requests, such as 99.5.
+	Objective float64 `json:"objective"`
+	// Latency is the duration good requests stay under, such as "300ms".
+	Latency string `json:"latency,omitempty"`
+	// Tags select the requests of an endpoint, such as {"name": "checkout"};
+	// without tags the objective applies to every request.
+	Tags        map[string]string `json:"tags,omitempty"`
+	Description string            `json:"description,omitempty"`
+}
+
+// Validate checks that the SLO can be turned into a k6 threshold.
+func (s SLO) Validate() error {
+	if !nameRe.MatchString(s.Name) {
+		return fmt.Errorf("%w: name %q must be 1-64 letters, digits, '.', '_' or '-'", ErrInvalid, s.Name)
+	}
+	if !(s.Objective > 0 && s.Objective < 100) {
+		return fmt.Errorf("%w %s: objective must be a percentage between 0 and 100 (exclusive), got %v",
+			ErrInvalid, s.Name, s.Objective)
+	}
+	switch s.Kind {
+	case Latency:
+		if d, err := time.ParseDuration(s.Latency); err != nil || d <= 0 {
+			return fmt.Errorf("%w %s: latency must be a duration like '300ms', got %q", ErrInvalid, s.Name, s.Latency)
+		}
+	case ErrorRate:
+		if s.Latency != "" {
+			return fmt.Errorf("%w %s: latency only applies to latency objectives", ErrInvalid, s.Name)
+		}
+	default:
+		return fmt.Errorf("%w %s: kind must be %q or %q, got %q", ErrInvalid, s.Name, Latency, ErrorRate, s.Kind)
+	}
+	for key, value := range s.Tags {
+		if key == "" || value == "" || strings.ContainsAny(key+value, "{}:,") {
+			return fmt.Errorf("%w %s: tag %q=%q must be non-empty without '{', '}', ':' or ','",
+				ErrInvalid, s.Name, key, value)
+		}
+	}
+	return nil
+}
+
+// Metric returns the k6 metric the SLO is measured on, narrowed to its
+// tags, such as "http_req_duration{name:checkout}".
+func (s SLO) Metric() string {
+	metric := "http_req_failed"
+	if s.Kind == Latency {
+		metric = "http_req_duration"
+	}
+	if len(s.Tags) == 0 {
+		return metric
+	}
+	keys := make([]string, 0, len(s.Tags))
+	for key := range s.Tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	tags := make([]string, 0, len(keys))
+	for _, key := range keys {
+		tags = append(tags, key+":"+s.Tags[key])
+	}
+	return metric + "{" + strings.Join(tags, ",") + "}"
+}
+
+// Expression returns the k6 threshold expression that holds while the SLO
+// is met, such as "p(99)<300" or "rate<=0.005".
+func (s SLO) Expression() string {
+	limit := strconv.FormatFloat(s.Limit(), 'f', -1, 64)
+	if s.Kind == Latency {
+		return "p(" + strconv.FormatFloat(s.Objective, 'f', -1, 64) + ")<" + limit
+	}
+	return "rate<=" + limit
+}
+
+// Limit returns the bound of the threshold of the SLO: the latency in
+// milliseconds, or the share of requests allowed to fail.
+func (s SLO) Limit() float64 {
+	if s.Kind == Latency {
+		d, _ := time.ParseDuration(s.Latency)
+		return float64(d) / float64(time.Millisecond)
+	}
+	return s.budget()
+}
+
+// budget returns the share of requests allowed to be bad, such as 0.005
+// for a 99.5% objective.
+func (s SLO) budget() float64 {
+	// Round off the float error of 100 - 99.9
+	return math.Round((100-s.Objective)*1e6) / 1e8
+}
+
+// Load reads a JSON array of SLOs from the file at path.
+func Load(path string) ([]SLO, error) {
+	//nolint:forbidigo // The SLO file is operator configuration, outside any workspace root.
+	data, err := os.ReadFile(path) // #nosec G304 -- path comes from server configuration
+	if err != nil {
+		return nil, fmt.Errorf("reading SLO file: %w", err)
+	}
+	var slos []SLO
+	if err := json.Unmarshal(data, &slos); err != nil {
+		return nil, fmt.Errorf("parsing SLO file: want a JSON array of SLOs: %w", err)
+	}
+	return slos, nil
+}
+
+// Registry holds the SLOs known to the server, by name. It is safe for
+// concurrent use.
+type Registry struct {
+	mu   sync.Mutex
+	slos map[string]SLO
+}
+
+// NewRegistry returns a registry holding slos.
+func NewRegistry(slos []SLO) (*Registry, error) {
+	r := &Registry{slos: make(map[string]SLO, len(slos))}
+	for _, s := range slos {
+		if _, ok := r.slos[s.Name]; ok {
+			return nil, fmt.Errorf("%w: %s is defined twice", ErrInvalid, s.Name)
+		}
+		if _, err := r.Define(s); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// Define adds s, or replaces the SLO of the same name, and reports whether
+// it replaced one.
+func (r *Registry) Define(s SLO) (bool, error) {
+	if err := s.Validate(); err != nil {
+		return false, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, replaced := r.slos[s.Name]
+	if !replaced && len(r.slos) >= MaxSLOs {
+		return false, fmt.Errorf("%d SLOs are defined (limit %d); delete one first", len(r.slos), MaxSLOs)
+	}
+	r.slos[s.Name] = s
+	return replaced, nil
+}
+
+// Delete removes the SLO called name.
+func (r *Registry) Delete(name string) (SLO, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s, ok := r.slos[name]
+	if !ok {
+		return SLO{}, fmt.Errorf("%w %q", ErrUnknown, name)
+	}
+	delete(r.slos, name)
+	return s, nil
+}
+
+// Get returns the SLOs called names, in that order.
+func (r *Registry) Get(names ...string) ([]SLO, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	slos := make([]SLO, 0, len(names))
+	for _, name := range names {
+		s, ok := r.slos[name]
+		if !ok {
+			return nil, fmt.Errorf("%w %q; list_slos shows the defined SLOs", ErrUnknown, name)
+		}
+		slos = append(slos, s)
+	}
+	return slos, nil
+}
+
+// List returns every SLO, by name.
+func (r *Registry) List() []SLO {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	slos := make([]SLO, 0, len(r.slos))
+	for _, s := range r.slos {
+		slos = append(slos, s)
+	}
+	sort.Slice(slos, func(i, j int) bool { return slos[i].Name < slos[j].Name })
+	return slos
+}
@@ -0,0 +1,99 @@
+package slo
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestThreshold(t *testing.T) {
+	t.Parallel()
+
+	latency := SLO{Name: "checkout-latency", Kind: Latency, Objective: 99, Latency: "1.5s",
+		Tags: map[string]string{"name": "checkout", "method": "POST"}}
+	require.NoError(t, latency.Validate())
+	assert.Equal(t, "http_req_duration{method:POST,name:checkout}", latency.Metric())
+	assert.Equal(t, "p(99)<1500", latency.Expression())
+
+	errors := SLO{Name: "errors", Kind: ErrorRate, Objective: 99.9}
+	require.NoError(t, errors.Validate())
+	assert.Equal(t, "http_req_failed", errors.Metric())
+	assert.Equal(t, "rate<=0.001", errors.Expression())
+}
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		slo SLO
+		err string
+	}{
+		"name":      {SLO{Name: "a b", Kind: ErrorRate, Objective: 99}, "name"},
+		"objective": {SLO{Name: "a", Kind: ErrorRate, Objective: 100}, "objective"},
+		"kind":      {SLO{Name: "a", Kind: "throughput", Objective: 99}, "kind"},
+		"latency":   {SLO{Name: "a", Kind: Latency, Objective: 99, Latency: "fast"}, "latency must be"},
+		"stray latency": {
+			SLO{Name: "a", Kind: ErrorRate, Objective: 99, Latency: "1s"}, "only applies to latency",
+		},
+		"tag": {SLO{Name: "a", Kind: ErrorRate, Objective: 99, Tags: map[string]string{"name": "a,b"}}, "tag"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			err := tt.slo.Validate()
+			require.ErrorIs(t, err, ErrInvalid)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	t.Parallel()
+
+	r, err := NewRegistry([]SLO{{Name: "errors", Kind: ErrorRate, Objective: 99}})
+	require.NoError(t, err)
+
+	replaced, err := r.Define(SLO{Name: "latency", Kind: Latency, Objective: 95, Latency: "300ms"})
+	require.NoError(t, err)
+	assert.False(t, replaced)
+	replaced, err = r.Define(SLO{Name: "errors", Kind: ErrorRate, Objective: 99.5})
+	require.NoError(t, err)
+	assert.True(t, replaced)
+
+	slos, err := r.Get("latency", "errors")
+	require.NoError(t, err)
+	assert.Equal(t, "latency", slos[0].Name)
+	assert.InDelta(t, 99.5, slos[1].Objective, 0)
+	_, err = r.Get("nope")
+	require.ErrorIs(t, err, ErrUnknown)
+
+	_, err = r.Delete("latency")
+	require.NoError(t, err)
+	require.Len(t, r.List(), 1)
+	_, err = r.Delete("latency")
+	require.ErrorIs(t, err, ErrUnknown)
+
+	_, err = NewRegistry([]SLO{{Name: "a", Kind: ErrorRate, Objective: 99}, {Name: "a", Kind: ErrorRate, Objective: 9}})
+	require.ErrorIs(t, err, ErrInvalid)
+}
+
+func TestLoad(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "slos.json")
+	require.NoError(t, os.WriteFile(path, []byte(`[
+		{"name": "checkout-latency", "kind": "latency", "objective": 99, "latency": "500ms", "tags": {"name": "checkout"}}
+	]`), 0o600))
+	slos, err := Load(path)
+	require.NoError(t, err)
+	require.Len(t, slos, 1)
+	assert.Equal(t, "checkout", slos[0].Tags["name"])
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"name": "x"}`), 0o600))
+	_, err = Load(path)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "want a JSON array")
+}
@@ -0,0 +1,111 @@
+package slo
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/mcp-k6/internal/summary"
+)
+
+// FastBurnRate is the burn rate from which a breach is reported as a
+// fast burn: at 14.4 times the allowed rate, 2% of a 30-day error budget
+// is spent in an hour, the usual threshold for paging.
+const FastBurnRate = 14.4
+
+// Verdicts of an SLO.
+const (
+	Met      = "met"
+	Breached = "breached"
+	FastBurn = "fast_burn"
+	// NoData is the verdict when the summary has no result for the SLO,
+	// for example because the run did not complete.
+	NoData = "no_data"
+)
+
+// Verdict is how a run did against one SLO.
+type Verdict struct {
+	SLO       string  `json:"slo"`
+	Kind      Kind    `json:"kind"`
+	Objective float64 `json:"objective"`
+	// Threshold is the k6 threshold the SLO was checked with.
+	Threshold string `json:"threshold"`
+	// Observed is the value k6 printed for the threshold, such as "rate=0.80%".
+	Observed string `json:"observed,omitempty"`
+	// BurnRate is the share of failed requests relative to the error
+	// budget: 1 spends exactly the budget, 2 twice as fast. Latency
+	// objectives have none, as the summary does not tell the share of slow
+	// requests.
+	BurnRate *float64 `json:"burn_rate,omitempty"`
+	Verdict  string   `json:"verdict"`
+}
+
+// Evaluate judges the threshold results of a run summary against slos.
+func Evaluate(slos []SLO, thresholds []summary.Threshold) []Verdict {
+	verdicts := make([]Verdict, 0, len(slos))
+	for _, s := range slos {
+		metric, expr := s.Metric(), s.Expression()
+		v := Verdict{
+			SLO:       s.Name,
+			Kind:      s.Kind,
+			Objective: s.Objective,
+			Threshold: metric + ": " + expr,
+			Verdict:   NoData,
+		}
+		th, ok := findThreshold(thresholds, metric, expr)
+		if !ok {
+			verdicts = append(verdicts, v)
+			continue
+		}
+		v.Observed = th.Value
+		v.Verdict = Met
+		if !th.Passed {
+			v.Verdict = Breached
+		}
+		if rate, ok := observedRate(th.Value); ok && s.Kind == ErrorRate {
+			burn := math.Round(rate/s.budget()*100) / 100
+			v.BurnRate = &burn
+			switch {
+			case burn >= FastBurnRate:
+				v.Verdict = FastBurn
+			case burn > 1:
+				v.Verdict = Breached
+			}
+		}
+		verdicts = append(verdicts, v)
+	}
+	return verdicts
+}
+
+// findThreshold returns the result of the threshold expr on metric. The
+// pre-1.0 summary only marks the metric, which then stands for it.
+func findThreshold(thresholds []summary.Threshold, metric, expr string) (summary.Threshold, bool) {
+	metric = strings.ReplaceAll(metric, " ", "")
+	for _, th := range thresholds {
+		if strings.ReplaceAll(th.Metric, " ", "") != metric {
+			continue
+		}
+		if th.Expression == expr || th.Expression == "" {
+			return th, true
+		}
+	}
+	return summary.Threshold{}, false
+}
+
+// observedRate parses the rate k6 printed for a rate threshold, such as
+// "rate=0.80%", as a share.
+func observedRate(value string) (float64, bool) {
+	p, ok := strings.CutPrefix(value, "rate=")
+	if !ok {
+		return 0, false
+	}
+	p, ok = strings.CutSuffix(p, "%")
+	if !ok {
+		return 0, false
+	}
+	rate, err := strconv.ParseFloat(p, 64)
+	if err != nil {
+		return 0, false
+	}
+	return rate / 100, true
+}
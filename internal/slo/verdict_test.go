@@ -0,0 +1,57 @@
+package slo
+
+import (
+	"testing"
+
+	"github.com/grafana/mcp-k6/internal/summary"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvaluate(t *testing.T) {
+	t.Parallel()
+
+	slos := []SLO{
+		{Name: "errors", Kind: ErrorRate, Objective: 99.5},
+		{Name: "checkout-errors", Kind: ErrorRate, Objective: 99.9, Tags: map[string]string{"name": "checkout"}},
+		{Name: "search-errors", Kind: ErrorRate, Objective: 99, Tags: map[string]string{"name": "search"}},
+		{Name: "latency", Kind: Latency, Objective: 95, Latency: "500ms"},
+		{Name: "login-latency", Kind: Latency, Objective: 99, Latency: "1s", Tags: map[string]string{"name": "login"}},
+	}
+	thresholds := []summary.Threshold{
+		{Metric: "http_req_failed", Expression: "rate<=0.005", Value: "rate=0.25%", Passed: true},
+		{Metric: "http_req_failed{name:checkout}", Expression: "rate<=0.001", Value: "rate=2.00%"},
+		{Metric: "http_req_failed{name:search}", Expression: "rate<=0.01", Value: "rate=1.50%"},
+		{Metric: "http_req_duration", Expression: "p(95)<500", Value: "p(95)=612ms"},
+	}
+
+	verdicts := Evaluate(slos, thresholds)
+	require.Len(t, verdicts, 5)
+
+	assert.Equal(t, Met, verdicts[0].Verdict)
+	assert.Equal(t, "http_req_failed: rate<=0.005", verdicts[0].Threshold)
+	require.NotNil(t, verdicts[0].BurnRate)
+	assert.InDelta(t, 0.5, *verdicts[0].BurnRate, 0)
+
+	assert.Equal(t, FastBurn, verdicts[1].Verdict)
+	assert.InDelta(t, 20.0, *verdicts[1].BurnRate, 0)
+
+	assert.Equal(t, Breached, verdicts[2].Verdict)
+	assert.InDelta(t, 1.5, *verdicts[2].BurnRate, 0)
+
+	assert.Equal(t, Breached, verdicts[3].Verdict)
+	assert.Equal(t, "p(95)=612ms", verdicts[3].Observed)
+	assert.Nil(t, verdicts[3].BurnRate)
+
+	assert.Equal(t, NoData, verdicts[4].Verdict)
+}
+
+func TestEvaluateLegacySummary(t *testing.T) {
+	t.Parallel()
+
+	verdicts := Evaluate([]SLO{{Name: "errors", Kind: ErrorRate, Objective: 99}},
+		[]summary.Threshold{{Metric: "http_req_failed", Passed: true}})
+	require.Len(t, verdicts, 1)
+	assert.Equal(t, Met, verdicts[0].Verdict)
+	assert.Nil(t, verdicts[0].BurnRate)
+}
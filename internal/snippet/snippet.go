@@ -0,0 +1,191 @@
+// Package snippet extracts the sentences of a documentation page around the
+// mention of a term, so a search hit can answer a question without the page.
+package snippet
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// DefaultContext is the number of sentences kept on each side of the
+	// sentence mentioning the term.
+	DefaultContext = 2
+
+	// MaxLength bounds the length of a snippet, in bytes.
+	MaxLength = 600
+)
+
+// abbreviations end with a period that does not end a sentence.
+//
+//nolint:gochecknoglobals // Read-only lookup table.
+var abbreviations = []string{"e.g.", "i.e.", "etc.", "vs.", "approx."}
+
+// Find returns the sentences of text, prose in markdown, around the first
+// mention of the first of terms it mentions, up to context sentences on each
+// side within the paragraph, with the mentions of the term in bold. When
+// text mentions none of terms, it returns its first sentences. Headings,
+// code blocks, tables and HTML are left out. It returns "" when text holds
+// no prose.
+func Find(text string, terms []string, context int) string {
+	paragraphs := prose(text)
+	for _, term := range terms {
+		term = strings.TrimSpace(term)
+		if term == "" {
+			continue
+		}
+		for _, p := range paragraphs {
+			sentences := split(p)
+			for i, s := range sentences {
+				if mentions(s, term) {
+					lo, hi := max(0, i-context), min(len(sentences), i+context+1)
+					return shorten(highlight(strings.Join(sentences[lo:hi], " "), term))
+				}
+			}
+		}
+	}
+	if len(paragraphs) == 0 {
+		return ""
+	}
+	sentences := split(paragraphs[0])
+	return shorten(strings.Join(sentences[:min(len(sentences), context+1)], " "))
+}
+
+// prose returns the paragraphs of text that are prose, each on one line.
+func prose(text string) []string {
+	var paragraphs []string
+	var lines []string
+	flush := func() {
+		if len(lines) > 0 {
+			paragraphs = append(paragraphs, strings.Join(lines, " "))
+			lines = nil
+		}
+	}
+	inCode := false
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "```") || strings.HasPrefix(line, "~~~") {
+			inCode = !inCode
+			flush()
+			continue
+		}
+		if inCode {
+			continue
+		}
+		line = strings.TrimSpace(strings.TrimLeft(line, ">"))
+		switch {
+		case line == "":
+			flush()
+		case strings.ContainsAny(line[:1], "#|<"):
+			flush()
+		default:
+			lines = append(lines, strings.Join(strings.Fields(line), " "))
+		}
+	}
+	flush()
+	return paragraphs
+}
+
+// split splits a paragraph into sentences, at a period, question or
+// exclamation mark followed by a space and a capital, digit or code.
+func split(p string) []string {
+	var sentences []string
+	start := 0
+	for i := 0; i < len(p)-2; i++ {
+		if !strings.ContainsRune(".!?", rune(p[i])) || p[i+1] != ' ' {
+			continue
+		}
+		next, _ := utf8.DecodeRuneInString(p[i+2:])
+		if !unicode.IsUpper(next) && !unicode.IsDigit(next) && next != '`' {
+			continue
+		}
+		if p[i] == '.' && abbreviated(p[start:i+1]) {
+			continue
+		}
+		sentences = append(sentences, p[start:i+1])
+		start = i + 2
+	}
+	return append(sentences, p[start:])
+}
+
+// abbreviated reports whether sentence ends with an abbreviation.
+func abbreviated(sentence string) bool {
+	lower := strings.ToLower(sentence)
+	for _, a := range abbreviations {
+		if strings.HasSuffix(lower, " "+a) || lower == a {
+			return true
+		}
+	}
+	return false
+}
+
+// mentions reports whether s mentions term as a whole word, ignoring case.
+func mentions(s, term string) bool {
+	return len(matches(s, term)) > 0
+}
+
+// matches returns the byte ranges of the whole-word mentions of term in s,
+// plurals included, ignoring case.
+func matches(s, term string) [][2]int {
+	lower, t := strings.ToLower(s), strings.ToLower(term)
+	if len(lower) != len(s) {
+		// Case folding changed the length: offsets would not map back
+		return nil
+	}
+	var ranges [][2]int
+	for from := 0; from <= len(lower)-len(t); {
+		i := strings.Index(lower[from:], t)
+		if i < 0 {
+			break
+		}
+		i += from
+		end := i + len(t)
+		if end < len(lower) && lower[end] == 's' && boundary(lower, end+1) {
+			end++
+		}
+		if boundary(lower, i-1) && boundary(lower, end) {
+			ranges = append(ranges, [2]int{i, end})
+		}
+		from = end
+	}
+	return ranges
+}
+
+// boundary reports whether the byte of s at i, if any, does not go on a
+// word.
+func boundary(s string, i int) bool {
+	if i < 0 || i >= len(s) {
+		return true
+	}
+	c := s[i]
+	return c != '_' && ('a' > c || c > 'z') && ('0' > c || c > '9')
+}
+
+// highlight puts the mentions of term in s in bold.
+func highlight(s, term string) string {
+	var b strings.Builder
+	last := 0
+	for _, r := range matches(s, term) {
+		b.WriteString(s[last:r[0]])
+		b.WriteString("**" + s[r[0]:r[1]] + "**")
+		last = r[1]
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
+
+// shorten cuts s to MaxLength at a word boundary.
+func shorten(s string) string {
+	if len(s) <= MaxLength {
+		return s
+	}
+	cut := strings.LastIndexByte(s[:MaxLength], ' ')
+	if cut <= 0 {
+		cut = MaxLength
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+	}
+	return s[:cut] + "…"
+}
@@ -0,0 +1,63 @@
+package snippet
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const page = `# get( url, [params] )
+
+Make a GET request. The request is synchronous, e.g. it blocks the VU. It returns a Response.
+Use ` + "`http.get`" + ` for reads. Params set the headers. Timeouts default to 60s. Retries are not made.
+
+| Parameter | Type |
+| --- | --- |
+| url | string |
+
+` + "```javascript\nhttp.get('https://test.k6.io');\n```\n"
+
+func TestFind(t *testing.T) {
+	t.Parallel()
+
+	// "http.get" goes first: it is the qualified name
+	got := Find(page, []string{"http.get", "get"}, 2)
+	assert.Equal(t, "The request is synchronous, e.g. it blocks the VU. It returns a Response. Use `**http.get**` "+
+		"for reads. Params set the headers. Timeouts default to 60s.", got,
+		"two sentences on each side, within the paragraph")
+
+	got = Find(page, []string{"get"}, 1)
+	assert.Equal(t, "Make a **GET** request. The request is synchronous, e.g. it blocks the VU.", got)
+
+	got = Find(page, []string{"params"}, 0)
+	assert.Equal(t, "**Params** set the headers.", got, "headings, tables and code are left out")
+}
+
+func TestFindWithoutMention(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "Make a GET request. The request is synchronous, e.g. it blocks the VU.",
+		Find(page, []string{"post"}, 1), "the first sentences stand in")
+	assert.Empty(t, Find("# Title\n\n```js\nget();\n```\n", []string{"get"}, 2))
+	assert.Equal(t, "Sleep suspends the VU.", Find("> Sleep suspends the VU.", []string{"sleeping"}, 2))
+}
+
+func TestFindWholeWords(t *testing.T) {
+	t.Parallel()
+
+	got := Find("Retargeting is forgotten. The target of a request is its URL.", []string{"target"}, 0)
+	assert.Equal(t, "The **target** of a request is its URL.", got)
+	got = Find("Thresholds fail the test. Checks do not.", []string{"check"}, 0)
+	assert.Equal(t, "**Checks** do not.", got, "plurals are mentions")
+}
+
+func TestFindShortens(t *testing.T) {
+	t.Parallel()
+
+	long := "The check " + strings.Repeat("passes again ", 100) + "forever."
+	got := Find(long, []string{"check"}, 2)
+	assert.LessOrEqual(t, len(got), MaxLength+len("…"))
+	assert.True(t, strings.HasSuffix(got, "…"))
+	assert.True(t, strings.HasPrefix(got, "The **check** passes"))
+}
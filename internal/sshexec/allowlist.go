@@ -0,0 +1,138 @@
+package sshexec
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// ErrNotAllowed is returned for a target the allowlist rejects.
+var ErrNotAllowed = errors.New("remote host not allowed")
+
+// Allowlist holds the hosts the operator lets the server run tests on, the
+// directory of the private keys it may log in with, and the k6 executable it
+// runs there. A nil *Allowlist allows no host.
+type Allowlist struct {
+	// entries hold "host", "*.domain", "user@host" or "user@*.domain"
+	// patterns.
+	entries []entry
+	keyDir  string
+	k6      string
+}
+
+type entry struct {
+	user, host string
+}
+
+// DefaultK6 is the k6 executable run on the hosts, looked up in their PATH,
+// when the operator sets none.
+const DefaultK6 = "k6"
+
+// NewAllowlist returns an allowlist of hosts, and of the keys in keyDir when
+// it is not empty, running k6 on them (default: DefaultK6). It returns nil
+// when hosts is empty.
+func NewAllowlist(hosts []string, keyDir, k6 string) (*Allowlist, error) {
+	k6 = strings.TrimSpace(k6)
+	if len(hosts) == 0 {
+		switch {
+		case keyDir != "":
+			return nil, errors.New("a remote key directory needs remote hosts")
+		case k6 != "":
+			return nil, errors.New("a remote k6 executable needs remote hosts")
+		}
+		return nil, nil
+	}
+	if k6 == "" {
+		k6 = DefaultK6
+	}
+	a := &Allowlist{k6: k6}
+	for _, h := range hosts {
+		h = strings.ToLower(strings.TrimSpace(h))
+		user, host, ok := strings.Cut(h, "@")
+		if !ok {
+			user, host = "", h
+		}
+		name := strings.TrimPrefix(host, "*.")
+		if !hostRe.MatchString(name) || strings.Contains(name, "*") || (ok && !userRe.MatchString(user)) {
+			return nil, fmt.Errorf("invalid remote host %q: use host, *.domain, user@host or user@*.domain", h)
+		}
+		a.entries = append(a.entries, entry{user: user, host: host})
+	}
+	if keyDir != "" {
+		dir, err := filepath.Abs(keyDir)
+		if err != nil {
+			return nil, fmt.Errorf("invalid remote key directory: %w", err)
+		}
+		a.keyDir = dir
+	}
+	return a, nil
+}
+
+// Hosts returns the host patterns of the allowlist.
+func (a *Allowlist) Hosts() []string {
+	if a == nil {
+		return nil
+	}
+	hosts := make([]string, len(a.entries))
+	for i, e := range a.entries {
+		hosts[i] = e.host
+		if e.user != "" {
+			hosts[i] = e.user + "@" + e.host
+		}
+	}
+	return hosts
+}
+
+// K6 returns the k6 executable to run on the hosts.
+func (a *Allowlist) K6() string {
+	if a == nil {
+		return DefaultK6
+	}
+	return a.k6
+}
+
+// Resolve returns target once checked against the allowlist, with its key
+// read as the name of a file of the key directory. An entry with a user only
+// allows logging in as that user.
+func (a *Allowlist) Resolve(target Target) (Target, error) {
+	if a == nil {
+		return target, fmt.Errorf("%w: no remote hosts are configured", ErrNotAllowed)
+	}
+	if !a.allows(target) {
+		return target, fmt.Errorf("%w: %s is not in the remote hosts of the server (%s)",
+			ErrNotAllowed, target.Host, strings.Join(a.Hosts(), ", "))
+	}
+	if target.Key == "" {
+		return target, nil
+	}
+	switch {
+	case a.keyDir == "":
+		return target, fmt.Errorf("%w: the server has no key directory; log in with the ssh agent or configuration",
+			ErrNotAllowed)
+	case target.Key != filepath.Base(target.Key) || strings.HasPrefix(target.Key, "."):
+		return target, fmt.Errorf("invalid key %q: want the name of a key file of the server's key directory",
+			target.Key)
+	}
+	target.Key = filepath.Join(a.keyDir, target.Key)
+	return target, nil
+}
+
+func (a *Allowlist) allows(target Target) bool {
+	host, user := strings.ToLower(target.Host), strings.ToLower(target.User)
+	for _, e := range a.entries {
+		if e.user != "" && e.user != user {
+			continue
+		}
+		if suffix, ok := strings.CutPrefix(e.host, "*."); ok {
+			if strings.HasSuffix(host, "."+suffix) {
+				return true
+			}
+			continue
+		}
+		if host == e.host {
+			return true
+		}
+	}
+	return false
+}
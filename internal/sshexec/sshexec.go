@@ -0,0 +1,235 @@
+// Package sshexec runs k6 on remote hosts over SSH, with the ssh client of
+// the server. The files of a test are staged in a temporary directory of the
+// host, which k6 runs in and which is removed once its outputs are read.
+package sshexec
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/mcp-k6/internal/audit"
+)
+
+const (
+	// connectTimeout bounds the connection to the host.
+	connectTimeout = 10 * time.Second
+	// exitSSH is the exit code of ssh itself failing, such as when the host
+	// cannot be reached or rejects the key.
+	exitSSH = 255
+)
+
+// ErrNoSSH is returned when ssh is not on the PATH.
+var ErrNoSSH = errors.New("ssh not found in PATH: install the OpenSSH client")
+
+var (
+	// hostRe matches host names and IPv4 or IPv6 addresses, none of which
+	// starts with a dash ssh would read as an option.
+	//
+	//nolint:gochecknoglobals // Compiled once, read-only.
+	hostRe = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9._:-]*$`)
+	// userRe matches POSIX user names.
+	//
+	//nolint:gochecknoglobals // Compiled once, read-only.
+	userRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9._-]*$`)
+	// dirRe matches the directories mktemp creates.
+	//
+	//nolint:gochecknoglobals // Compiled once, read-only.
+	dirRe = regexp.MustCompile(`^/[A-Za-z0-9._/-]+$`)
+	// fileRe matches the names of staged files.
+	//
+	//nolint:gochecknoglobals // Compiled once, read-only.
+	fileRe = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+)
+
+// Target is a remote host and how to log in to it.
+type Target struct {
+	Host string
+	// User defaults to that of the ssh configuration, or the local user.
+	User string
+	// Port defaults to 22, or that of the ssh configuration, when 0.
+	Port int
+	// Key is the private key file to log in with; the keys of the ssh agent
+	// and configuration are used when empty.
+	Key string
+}
+
+// Output is the output of a command on the host.
+type Output struct {
+	Stdout   string
+	Stderr   string
+	ExitCode int
+}
+
+// Client runs commands on a remote host.
+type Client struct {
+	path   string
+	target Target
+}
+
+// New returns a client of target, after checking its fields.
+func New(target Target) (*Client, error) {
+	switch {
+	case !hostRe.MatchString(target.Host):
+		return nil, fmt.Errorf("invalid host %q: want a host name or an IP address", target.Host)
+	case target.User != "" && !userRe.MatchString(target.User):
+		return nil, fmt.Errorf("invalid user %q", target.User)
+	case target.Port < 0 || target.Port > 65535:
+		return nil, fmt.Errorf("invalid port %d", target.Port)
+	case strings.HasPrefix(target.Key, "-"):
+		return nil, fmt.Errorf("invalid key file %q", target.Key)
+	}
+	path, err := exec.LookPath("ssh")
+	if err != nil {
+		return nil, ErrNoSSH
+	}
+	return &Client{path: path, target: target}, nil
+}
+
+// Stage creates a temporary directory on the host holding files, by file
+// name, and returns its path.
+func (c *Client) Stage(ctx context.Context, files map[string]string) (string, error) {
+	out, err := c.Exec(ctx, nil, "mktemp", "-d")
+	if err != nil {
+		return "", err
+	}
+	dir := strings.TrimSpace(out.Stdout)
+	if out.ExitCode != 0 || !dirRe.MatchString(dir) {
+		return "", fmt.Errorf("ssh %s: creating a temporary directory failed: %s",
+			c.target.Host, lastLine(out.Stderr, dir))
+	}
+	for name, content := range files {
+		if !fileRe.MatchString(name) {
+			return dir, fmt.Errorf("invalid file name %q", name)
+		}
+		out, err := c.Exec(ctx, []byte(content), "cat", ">", dir+"/"+name)
+		if err != nil {
+			return dir, err
+		}
+		if out.ExitCode != 0 {
+			return dir, fmt.Errorf("ssh %s: writing %s failed: %s", c.target.Host, name, lastLine(out.Stderr, ""))
+		}
+	}
+	return dir, nil
+}
+
+// Run runs args in dir on the host and returns their output, whatever their
+// exit code.
+func (c *Client) Run(ctx context.Context, dir string, args ...string) (Output, error) {
+	return c.Exec(ctx, nil, append([]string{"cd", dir, "&&"}, args...)...)
+}
+
+// ReadFile returns the content of the file name of dir on the host.
+func (c *Client) ReadFile(ctx context.Context, dir, name string) ([]byte, error) {
+	out, err := c.Exec(ctx, nil, "cat", dir+"/"+name)
+	if err != nil {
+		return nil, err
+	}
+	if out.ExitCode != 0 {
+		return nil, fmt.Errorf("ssh %s: reading %s failed: %s", c.target.Host, name, lastLine(out.Stderr, ""))
+	}
+	return []byte(out.Stdout), nil
+}
+
+// Remove removes the staged directory dir from the host.
+func (c *Client) Remove(ctx context.Context, dir string) error {
+	if !dirRe.MatchString(dir) {
+		return fmt.Errorf("invalid directory %q", dir)
+	}
+	out, err := c.Exec(ctx, nil, "rm", "-rf", dir)
+	if err == nil && out.ExitCode != 0 {
+		err = fmt.Errorf("ssh %s: removing %s failed: %s", c.target.Host, dir, lastLine(out.Stderr, ""))
+	}
+	return err
+}
+
+// Exec runs the command words on the host with stdin, quoting each word for
+// the remote shell but the operators ">" and "&&". It returns an error when
+// ssh itself failed, and otherwise the output of the command.
+func (c *Client) Exec(ctx context.Context, stdin []byte, words ...string) (Output, error) {
+	quoted := make([]string, len(words))
+	for i, w := range words {
+		if w == ">" || w == "&&" {
+			quoted[i] = w
+		} else {
+			quoted[i] = Quote(w)
+		}
+	}
+
+	args := append(c.flags(), "--", c.target.Host, strings.Join(quoted, " "))
+	cmd := exec.CommandContext(ctx, c.path, args...) // #nosec G204 -- target checked by New, command quoted
+	if stdin != nil {
+		cmd.Stdin = bytes.NewReader(stdin)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	start := time.Now()
+	err := cmd.Run()
+	audit.Command(ctx, cmd, start, err)
+
+	out := Output{Stdout: stdout.String(), Stderr: stderr.String()}
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+	case errors.As(err, &exitErr) && exitErr.ExitCode() != exitSSH:
+		out.ExitCode = exitErr.ExitCode()
+	case ctx.Err() != nil:
+		return out, ctx.Err()
+	default:
+		return out, fmt.Errorf("ssh %s: %s", c.target.Host, lastLine(out.Stderr, err.Error()))
+	}
+	return out, nil
+}
+
+// flags returns the ssh options of the target. Batch mode fails instead of
+// prompting for passwords or unknown host keys.
+func (c *Client) flags() []string {
+	flags := []string{
+		"-o", "BatchMode=yes",
+		"-o", "ConnectTimeout=" + strconv.Itoa(int(connectTimeout.Seconds())),
+	}
+	if c.target.User != "" {
+		flags = append(flags, "-l", c.target.User)
+	}
+	if c.target.Port != 0 {
+		flags = append(flags, "-p", strconv.Itoa(c.target.Port))
+	}
+	if c.target.Key != "" {
+		flags = append(flags, "-i", c.target.Key, "-o", "IdentitiesOnly=yes")
+	}
+	return flags
+}
+
+// Quote quotes s as one word of a POSIX shell command.
+func Quote(s string) string {
+	if s != "" && strings.IndexFunc(s, unsafeRune) < 0 {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// unsafeRune reports whether r has to be quoted in a shell word.
+func unsafeRune(r rune) bool {
+	switch {
+	case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		return false
+	default:
+		return !strings.ContainsRune("-_./=:,+@%", r)
+	}
+}
+
+// lastLine returns the last non-empty line of s, which holds the error of
+// ssh and most commands, or fallback when there is none.
+func lastLine(s, fallback string) string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	if line := strings.TrimSpace(lines[len(lines)-1]); line != "" {
+		return line
+	}
+	return fallback
+}
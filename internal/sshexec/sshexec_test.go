@@ -0,0 +1,160 @@
+package sshexec
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	for name, target := range map[string]Target{
+		"host":   {Host: "-oProxyCommand=x"},
+		"empty":  {},
+		"user":   {Host: "loadgen", User: "k6 user"},
+		"port":   {Host: "loadgen", Port: 70000},
+		"option": {Host: "loadgen", Key: "-F/tmp/config"},
+	} {
+		_, err := New(target)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestQuote(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "--vus", Quote("--vus"))
+	assert.Equal(t, "BASE_URL=https://shop.example.com/", Quote("BASE_URL=https://shop.example.com/"))
+	assert.Equal(t, "''", Quote(""))
+	assert.Equal(t, `'a b'`, Quote("a b"))
+	assert.Equal(t, `'it'\''s; rm -rf /'`, Quote("it's; rm -rf /"))
+}
+
+// stubSSH returns a client of an ssh stub running the remote command in a
+// local shell, recording its arguments in the calls file of the returned
+// directory.
+func stubSSH(t *testing.T) (*Client, string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("the ssh stub is a shell script")
+	}
+	dir := t.TempDir()
+	script := `#!/bin/sh
+echo "$*" >> ` + dir + `/calls
+while [ "$1" != "--" ]; do shift; done
+shift
+if [ "$1" = "unreachable" ]; then echo "ssh: connect to host unreachable port 22: Connection refused" >&2; exit 255; fi
+shift
+exec sh -c "$1"
+`
+	path := filepath.Join(dir, "ssh")
+	//nolint:forbidigo // Writing the ssh stub
+	// #nosec G306 -- Stub executable must be runnable during tests
+	require.NoError(t, os.WriteFile(path, []byte(script), 0o700))
+	return &Client{path: path, target: Target{Host: "loadgen", User: "k6", Port: 2222, Key: "/keys/id"}}, dir
+}
+
+func TestClient(t *testing.T) {
+	t.Parallel()
+
+	c, stub := stubSSH(t)
+	ctx := context.Background()
+	dir, err := c.Stage(ctx, map[string]string{"script.js": "export default function () {}\n"})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = c.Remove(ctx, dir) })
+
+	out, err := c.Run(ctx, dir, "sh", "-c", "cat script.js > copy.js; echo 'it'\\''s done'; exit 99")
+	require.NoError(t, err)
+	assert.Equal(t, 99, out.ExitCode)
+	assert.Equal(t, "it's done\n", out.Stdout)
+
+	data, err := c.ReadFile(ctx, dir, "copy.js")
+	require.NoError(t, err)
+	assert.Equal(t, "export default function () {}\n", string(data))
+	_, err = c.ReadFile(ctx, dir, "missing.json")
+	require.Error(t, err)
+
+	require.NoError(t, c.Remove(ctx, dir))
+	//nolint:forbidigo // Checking the stub removed the directory
+	_, err = os.Stat(dir)
+	assert.True(t, os.IsNotExist(err))
+
+	//nolint:forbidigo // Reading the calls recorded by the stub
+	calls, err := os.ReadFile(filepath.Join(stub, "calls"))
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(calls)), "\n")
+	assert.Equal(t, "-o BatchMode=yes -o ConnectTimeout=10 -l k6 -p 2222 -i /keys/id -o IdentitiesOnly=yes "+
+		"-- loadgen mktemp -d", lines[0])
+	assert.Equal(t, "-o BatchMode=yes -o ConnectTimeout=10 -l k6 -p 2222 -i /keys/id -o IdentitiesOnly=yes "+
+		"-- loadgen rm -rf "+dir, lines[len(lines)-1])
+}
+
+func TestClientUnreachable(t *testing.T) {
+	t.Parallel()
+
+	c, _ := stubSSH(t)
+	c.target.Host = "unreachable"
+	_, err := c.Stage(context.Background(), nil)
+	require.Error(t, err)
+	assert.Equal(t, "ssh unreachable: ssh: connect to host unreachable port 22: Connection refused", err.Error())
+}
+
+func TestAllowlist(t *testing.T) {
+	t.Parallel()
+
+	none, err := NewAllowlist(nil, "", "")
+	require.NoError(t, err)
+	assert.Nil(t, none)
+	_, err = none.Resolve(Target{Host: "loadgen"})
+	require.ErrorIs(t, err, ErrNotAllowed)
+
+	keys := t.TempDir()
+	a, err := NewAllowlist([]string{"loadgen-1", "*.dc.example.com", "k6@bastion"}, keys, "/opt/k6/bin/k6")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"loadgen-1", "*.dc.example.com", "k6@bastion"}, a.Hosts())
+	assert.Equal(t, "/opt/k6/bin/k6", a.K6())
+
+	target, err := a.Resolve(Target{Host: "LoadGen-1", Key: "loadgen"})
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(keys, "loadgen"), target.Key)
+	_, err = a.Resolve(Target{Host: "lg.dc.example.com", User: "root", Port: 2222})
+	require.NoError(t, err)
+	_, err = a.Resolve(Target{Host: "k6", User: "bastion"})
+	require.ErrorIs(t, err, ErrNotAllowed)
+
+	for name, target := range map[string]Target{
+		"other host": {Host: "db.example.com"},
+		"domain":     {Host: "dc.example.com"},
+		"other user": {Host: "bastion", User: "root"},
+		"any user":   {Host: "bastion"},
+		"key path":   {Host: "loadgen-1", Key: "/home/k6/.ssh/id_ed25519"},
+		"key escape": {Host: "loadgen-1", Key: "../id_ed25519"},
+		"hidden key": {Host: "loadgen-1", Key: ".ssh"},
+	} {
+		_, err := a.Resolve(target)
+		assert.Error(t, err, name)
+	}
+
+	// Without a key directory, only the ssh agent and configuration log in
+	noKeys, err := NewAllowlist([]string{"loadgen-1"}, "", " ")
+	require.NoError(t, err)
+	assert.Equal(t, DefaultK6, noKeys.K6())
+	_, err = noKeys.Resolve(Target{Host: "loadgen-1", Key: "loadgen"})
+	require.ErrorIs(t, err, ErrNotAllowed)
+
+	for _, hosts := range [][]string{{"-oProxyCommand=x"}, {"*.*.example.com"}, {"bad user@loadgen"}, {""}} {
+		_, err := NewAllowlist(hosts, "", "")
+		assert.Error(t, err, hosts)
+	}
+	_, err = NewAllowlist(nil, keys, "")
+	require.Error(t, err)
+	_, err = NewAllowlist(nil, "", "/opt/k6/bin/k6")
+	require.Error(t, err)
+}
@@ -0,0 +1,294 @@
+// Package suite reads test suite manifests: YAML files listing the k6
+// scripts of a suite with their options, the thresholds they share, the
+// scripts each depends on, and whether they run one after the other or side
+// by side. A setup script may run first, the data its setup() returns
+// reaching the other scripts as environment variables.
+//
+//	name: checkout
+//	parallel: false
+//	fail_fast: true
+//	setup:
+//	  path: tests/seed.js
+//	thresholds:
+//	  http_req_failed: rate<0.01
+//	scripts:
+//	  - path: tests/login.js
+//	    vus: 5
+//	    duration: 30s
+//	  - path: tests/cart.js
+//	    iterations: 100
+//	    depends_on: [login]
+//	    thresholds:
+//	      http_req_duration: ["p(95)<500"]
+package suite
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// MaxScripts is the maximum number of scripts of a suite.
+const MaxScripts = 20
+
+// ErrInvalid is returned for manifests that cannot be run.
+var ErrInvalid = errors.New("invalid suite manifest")
+
+// envNameRe matches the names setup data is passed to scripts under.
+//
+//nolint:gochecknoglobals // Compiled once, read-only.
+var envNameRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Expressions are the threshold expressions of a metric, written in the
+// manifest as one string or a list of them.
+type Expressions []string
+
+// UnmarshalYAML reads a single expression or a list of them.
+func (e *Expressions) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*e = Expressions{node.Value}
+		return nil
+	}
+	var list []string
+	if err := node.Decode(&list); err != nil {
+		return errors.New("thresholds must map metrics to an expression or a list of expressions")
+	}
+	*e = list
+	return nil
+}
+
+// Manifest is a test suite.
+type Manifest struct {
+	Name string `yaml:"name"`
+	// Parallel runs the scripts side by side instead of in order.
+	Parallel bool `yaml:"parallel"`
+	// FailFast skips the scripts after the first that fails, in
+	// sequential suites.
+	FailFast bool `yaml:"fail_fast"`
+	// Thresholds apply to every script; those of a script replace them for
+	// the same metric.
+	Thresholds map[string]Expressions `yaml:"thresholds"`
+	// Env is passed to every script; that of a script adds to it.
+	Env map[string]string `yaml:"env"`
+	// Setup runs before the scripts, which do not run unless it passes.
+	Setup   *Setup   `yaml:"setup"`
+	Scripts []Script `yaml:"scripts"`
+}
+
+// Setup is the script a suite runs first, once, with one VU. The top-level
+// fields of the object its setup() returns are passed to every script as
+// environment variables.
+type Setup struct {
+	Path string            `yaml:"path"`
+	Env  map[string]string `yaml:"env"`
+}
+
+// Script is a script of a suite and its options.
+type Script struct {
+	// Name defaults to the file name of Path.
+	Name string `yaml:"name"`
+	// Path is relative to the manifest file, or to the workspace roots for
+	// inline manifests.
+	Path       string                 `yaml:"path"`
+	VUs        int                    `yaml:"vus"`
+	Duration   string                 `yaml:"duration"`
+	Iterations int                    `yaml:"iterations"`
+	Env        map[string]string      `yaml:"env"`
+	Thresholds map[string]Expressions `yaml:"thresholds"`
+	// DependsOn names the scripts that must pass before this one runs.
+	DependsOn []string `yaml:"depends_on"`
+}
+
+// Parse reads and checks a manifest. Scripts without a name are named after
+// their file.
+func Parse(data []byte) (*Manifest, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	var m Manifest
+	if err := dec.Decode(&m); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalid, strings.TrimPrefix(err.Error(), "yaml: "))
+	}
+
+	switch {
+	case len(m.Scripts) == 0:
+		return nil, fmt.Errorf("%w: list the scripts of the suite under scripts", ErrInvalid)
+	case len(m.Scripts) > MaxScripts:
+		return nil, fmt.Errorf("%w: %d scripts, the limit is %d", ErrInvalid, len(m.Scripts), MaxScripts)
+	}
+	names := make(map[string]bool, len(m.Scripts))
+	for i := range m.Scripts {
+		s := &m.Scripts[i]
+		if err := s.validate(); err != nil {
+			return nil, fmt.Errorf("%w: script %d: %w", ErrInvalid, i+1, err)
+		}
+		if s.Name == "" {
+			s.Name = strings.TrimSuffix(path.Base(s.Path), path.Ext(s.Path))
+		}
+		if names[s.Name] {
+			return nil, fmt.Errorf("%w: script name %q is used twice; set distinct names", ErrInvalid, s.Name)
+		}
+		names[s.Name] = true
+	}
+	if m.Setup != nil && strings.TrimSpace(m.Setup.Path) == "" {
+		return nil, fmt.Errorf("%w: setup: path is required", ErrInvalid)
+	}
+	if err := m.checkDependencies(names); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// checkDependencies checks that the scripts depend on other scripts of the
+// suite, without cycles.
+func (m *Manifest) checkDependencies(names map[string]bool) error {
+	for _, s := range m.Scripts {
+		for _, dep := range s.DependsOn {
+			switch {
+			case dep == s.Name:
+				return fmt.Errorf("%w: script %s depends on itself", ErrInvalid, s.Name)
+			case !names[dep]:
+				return fmt.Errorf("%w: script %s depends on %q, which is not a script of the suite",
+					ErrInvalid, s.Name, dep)
+			}
+		}
+	}
+	if order := m.Order(); len(order) < len(m.Scripts) {
+		var cycle []string
+		ordered := make(map[int]bool, len(order))
+		for _, i := range order {
+			ordered[i] = true
+		}
+		for i, s := range m.Scripts {
+			if !ordered[i] {
+				cycle = append(cycle, s.Name)
+			}
+		}
+		return fmt.Errorf("%w: the dependencies of %s form a cycle", ErrInvalid, strings.Join(cycle, ", "))
+	}
+	return nil
+}
+
+// Order returns the indexes of the scripts in an order that runs each after
+// those it depends on, keeping the order of the manifest otherwise. Scripts
+// on a dependency cycle are left out.
+func (m *Manifest) Order() []int {
+	index := make(map[string]int, len(m.Scripts))
+	for i, s := range m.Scripts {
+		index[s.Name] = i
+	}
+	waiting := make([]int, len(m.Scripts))
+	dependents := make([][]int, len(m.Scripts))
+	for i, s := range m.Scripts {
+		for _, dep := range s.DependsOn {
+			if j, ok := index[dep]; ok {
+				waiting[i]++
+				dependents[j] = append(dependents[j], i)
+			}
+		}
+	}
+	var ready, order []int
+	for i := range m.Scripts {
+		if waiting[i] == 0 {
+			ready = append(ready, i)
+		}
+	}
+	for len(ready) > 0 {
+		sort.Ints(ready)
+		i := ready[0]
+		ready = ready[1:]
+		order = append(order, i)
+		for _, d := range dependents[i] {
+			if waiting[d]--; waiting[d] == 0 {
+				ready = append(ready, d)
+			}
+		}
+	}
+	return order
+}
+
+// Outputs returns the environment variables of the data a setup script
+// returned: its top-level fields, strings as they are and other values as
+// JSON.
+func Outputs(data json.RawMessage) (map[string]string, error) {
+	var fields map[string]json.RawMessage
+	if len(data) == 0 {
+		return map[string]string{}, nil
+	}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, errors.New("setup() must return an object, whose fields are passed to the scripts")
+	}
+	env := make(map[string]string, len(fields))
+	for name, value := range fields {
+		if !envNameRe.MatchString(name) {
+			return nil, fmt.Errorf("setup() returned field %q, which is not a valid environment variable name", name)
+		}
+		var s string
+		if json.Unmarshal(value, &s) == nil {
+			env[name] = s
+		} else {
+			env[name] = string(value)
+		}
+	}
+	return env, nil
+}
+
+// validate checks the options of s that do not depend on the limits of
+// the server.
+func (s *Script) validate() error {
+	switch {
+	case strings.TrimSpace(s.Path) == "":
+		return errors.New("path is required")
+	case s.VUs < 0:
+		return errors.New("vus cannot be negative")
+	case s.Iterations < 0:
+		return errors.New("iterations cannot be negative")
+	case s.Iterations > 0 && s.Duration != "":
+		return errors.New("set either duration or iterations")
+	}
+	if s.Duration != "" {
+		if d, err := time.ParseDuration(s.Duration); err != nil || d <= 0 {
+			return fmt.Errorf("duration must be a duration like '30s', got %q", s.Duration)
+		}
+	}
+	return nil
+}
+
+// ScriptThresholds returns the thresholds of s: those of the suite,
+// replaced by those of s for the same metric.
+func (m *Manifest) ScriptThresholds(s Script) map[string][]string {
+	if len(m.Thresholds) == 0 && len(s.Thresholds) == 0 {
+		return nil
+	}
+	merged := make(map[string][]string, len(m.Thresholds)+len(s.Thresholds))
+	for metric, exprs := range m.Thresholds {
+		merged[metric] = exprs
+	}
+	for metric, exprs := range s.Thresholds {
+		merged[metric] = exprs
+	}
+	return merged
+}
+
+// ScriptEnv returns the variables of s: base, such as those of an env
+// file, then those of the suite, the outputs of the setup script and those
+// of s, each replacing the former for the same name.
+func (m *Manifest) ScriptEnv(s Script, base, outputs map[string]string) map[string]string {
+	env := make(map[string]string, len(base)+len(m.Env)+len(outputs)+len(s.Env))
+	for _, vars := range []map[string]string{base, m.Env, outputs, s.Env} {
+		for k, v := range vars {
+			env[k] = v
+		}
+	}
+	if len(env) == 0 {
+		return nil
+	}
+	return env
+}
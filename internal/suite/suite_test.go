@@ -0,0 +1,153 @@
+package suite
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testManifest = `
+name: checkout
+fail_fast: true
+thresholds:
+  http_req_failed: rate<0.01
+  http_req_duration: ["p(95)<800"]
+env:
+  BASE_URL: https://staging.example.com
+scripts:
+  - path: tests/login.js
+    vus: 5
+    duration: 30s
+  - name: cart
+    path: tests/cart.js
+    iterations: 100
+    env:
+      BASE_URL: https://cart.staging.example.com
+    thresholds:
+      http_req_duration: ["p(95)<500", "p(99)<900"]
+`
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	m, err := Parse([]byte(testManifest))
+	require.NoError(t, err)
+	assert.Equal(t, "checkout", m.Name)
+	assert.True(t, m.FailFast)
+	assert.False(t, m.Parallel)
+	require.Len(t, m.Scripts, 2)
+	assert.Equal(t, "login", m.Scripts[0].Name)
+	assert.Equal(t, 5, m.Scripts[0].VUs)
+	assert.Equal(t, "cart", m.Scripts[1].Name)
+
+	assert.Equal(t, map[string][]string{
+		"http_req_failed":   {"rate<0.01"},
+		"http_req_duration": {"p(95)<800"},
+	}, m.ScriptThresholds(m.Scripts[0]))
+	assert.Equal(t, map[string][]string{
+		"http_req_failed":   {"rate<0.01"},
+		"http_req_duration": {"p(95)<500", "p(99)<900"},
+	}, m.ScriptThresholds(m.Scripts[1]))
+
+	base := map[string]string{"TOKEN": "t", "BASE_URL": "http://localhost"}
+	assert.Equal(t, map[string]string{"TOKEN": "t", "BASE_URL": "https://staging.example.com"},
+		m.ScriptEnv(m.Scripts[0], base, nil))
+	assert.Equal(t, "https://cart.staging.example.com", m.ScriptEnv(m.Scripts[1], base, nil)["BASE_URL"])
+
+	outputs := map[string]string{"TOKEN": "from-setup", "BASE_URL": "https://seeded.example.com"}
+	env := m.ScriptEnv(m.Scripts[1], base, outputs)
+	assert.Equal(t, "from-setup", env["TOKEN"])
+	assert.Equal(t, "https://cart.staging.example.com", env["BASE_URL"], "the script's own env wins")
+}
+
+func TestParseNoShared(t *testing.T) {
+	t.Parallel()
+
+	m, err := Parse([]byte("scripts:\n  - path: a.js\n"))
+	require.NoError(t, err)
+	assert.Nil(t, m.ScriptThresholds(m.Scripts[0]))
+	assert.Nil(t, m.ScriptEnv(m.Scripts[0], nil, nil))
+	assert.Nil(t, m.Setup)
+	assert.Equal(t, []int{0}, m.Order())
+}
+
+func TestParseDependencies(t *testing.T) {
+	t.Parallel()
+
+	m, err := Parse([]byte(`
+setup:
+  path: tests/seed.js
+  env:
+    USERS: "10"
+scripts:
+  - path: checkout.js
+    depends_on: [cart, login]
+  - path: cart.js
+    depends_on: [login]
+  - path: login.js
+  - path: browse.js
+`))
+	require.NoError(t, err)
+	assert.Equal(t, &Setup{Path: "tests/seed.js", Env: map[string]string{"USERS": "10"}}, m.Setup)
+	assert.Equal(t, []int{2, 1, 0, 3}, m.Order(), "login, cart, checkout, browse")
+}
+
+func TestOutputs(t *testing.T) {
+	t.Parallel()
+
+	env, err := Outputs([]byte(`{"TOKEN":"abc","ORDER_ID":42,"ok":true,"user":{"id":7}}`))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"TOKEN": "abc", "ORDER_ID": "42", "ok": "true", "user": `{"id":7}`}, env)
+
+	env, err = Outputs(nil)
+	require.NoError(t, err)
+	assert.Empty(t, env)
+	env, err = Outputs([]byte("null"))
+	require.NoError(t, err)
+	assert.Empty(t, env)
+
+	_, err = Outputs([]byte(`["abc"]`))
+	require.ErrorContains(t, err, "must return an object")
+	_, err = Outputs([]byte(`{"api-token":"abc"}`))
+	require.ErrorContains(t, err, "not a valid environment variable name")
+}
+
+func TestParseErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		manifest string
+		err      string
+	}{
+		"empty":      {"name: x\n", "list the scripts"},
+		"unknown":    {"scripts:\n  - path: a.js\n    vu: 5\n", "field vu not found"},
+		"path":       {"scripts:\n  - vus: 5\n", "path is required"},
+		"both":       {"scripts:\n  - path: a.js\n    duration: 1m\n    iterations: 5\n", "either duration"},
+		"duration":   {"scripts:\n  - path: a.js\n    duration: soon\n", "duration like"},
+		"negative":   {"scripts:\n  - path: a.js\n    vus: -1\n", "vus cannot be negative"},
+		"duplicate":  {"scripts:\n  - path: a/smoke.js\n  - path: b/smoke.js\n", `"smoke" is used twice`},
+		"thresholds": {"thresholds:\n  http_req_failed: {rate: 1}\nscripts:\n  - path: a.js\n", "expression"},
+		"yaml":       {"scripts: [", "did not find expected"},
+		"setup":      {"setup:\n  env: {A: b}\nscripts:\n  - path: a.js\n", "setup: path is required"},
+		"self":       {"scripts:\n  - path: a.js\n    depends_on: [a]\n", "depends on itself"},
+		"missing":    {"scripts:\n  - path: a.js\n    depends_on: [b]\n", `depends on "b"`},
+		"cycle": {
+			"scripts:\n  - path: a.js\n    depends_on: [b]\n  - path: b.js\n    depends_on: [a]\n  - path: c.js\n",
+			"dependencies of a, b form a cycle",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			_, err := Parse([]byte(tt.manifest))
+			require.ErrorIs(t, err, ErrInvalid)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+
+	many := "scripts:\n" + strings.Repeat("  - path: a.js\n", MaxScripts+1)
+	_, err := Parse([]byte(many))
+	require.ErrorIs(t, err, ErrInvalid)
+}
@@ -0,0 +1,107 @@
+package summary
+
+import (
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Types of metrics.
+const (
+	TypeCounter = "counter"
+	TypeGauge   = "gauge"
+	TypeRate    = "rate"
+	TypeTrend   = "trend"
+)
+
+//nolint:gochecknoglobals // Read-only lookup tables.
+var (
+	// builtinMetrics are the metrics k6 records itself.
+	builtinMetrics = []string{
+		"checks", "data_received", "data_sent", "dropped_iterations", "group_duration", "iteration_duration",
+		"iterations", "vus", "vus_max",
+	}
+	// builtinPrefixes start the names of the metrics of the k6 protocols and
+	// the browser.
+	builtinPrefixes = []string{"http_req", "ws_", "grpc_", "browser_"}
+)
+
+// CustomMetric is a metric defined by the script, with its values as Values
+// of the end-of-test summary: "count" and "rate" of counters, "value",
+// "min" and "max" of gauges, "value" (the share of non-zero samples),
+// "passes" and "fails" of rates, and the summaryTrendStats of trends, such
+// as "avg" and "p(95)". Trends added with isTime are in milliseconds.
+type CustomMetric struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+	// Tags holds the tags of a submetric, such as one a threshold set on
+	// my_trend{endpoint:login}.
+	Tags   map[string]string  `json:"tags,omitempty"`
+	Values map[string]float64 `json:"values"`
+}
+
+// CustomMetrics returns the custom metrics of the exported summary, and
+// their submetrics, sorted by name.
+func (e *Export) CustomMetrics() []CustomMetric {
+	keys := slices.Collect(maps.Keys(e.Metrics))
+	sort.Strings(keys)
+
+	var metrics []CustomMetric
+	for _, key := range keys {
+		name, tags := splitSubmetric(key)
+		if isBuiltin(name) {
+			continue
+		}
+		values := e.values(key)
+		metrics = append(metrics, CustomMetric{Name: name, Type: metricType(values), Tags: tags, Values: values})
+	}
+	return metrics
+}
+
+func isBuiltin(name string) bool {
+	if slices.Contains(builtinMetrics, name) {
+		return true
+	}
+	for _, prefix := range builtinPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// metricType tells the type of a metric from the values k6 exports for it.
+func metricType(values map[string]float64) string {
+	has := func(name string) bool {
+		_, ok := values[name]
+		return ok
+	}
+	switch {
+	case has("passes") || has("fails"):
+		return TypeRate
+	case has("count"):
+		return TypeCounter
+	case has("value"):
+		return TypeGauge
+	default:
+		return TypeTrend
+	}
+}
+
+// splitSubmetric splits "name{tag:value,...}" into the name of the metric
+// and the tags of the submetric.
+func splitSubmetric(key string) (string, map[string]string) {
+	name, rest, ok := strings.Cut(key, "{")
+	if !ok {
+		return key, nil
+	}
+	tags := make(map[string]string)
+	for _, tag := range strings.Split(strings.TrimSuffix(rest, "}"), ",") {
+		k, v, _ := strings.Cut(tag, ":")
+		if k = strings.TrimSpace(k); k != "" {
+			tags[k] = strings.TrimSpace(v)
+		}
+	}
+	return name, tags
+}
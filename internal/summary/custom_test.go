@@ -0,0 +1,42 @@
+package summary
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCustomMetrics(t *testing.T) {
+	t.Parallel()
+
+	e := readExport(t, `{"metrics": {
+    "iterations": {"count": 20, "rate": 1.9},
+    "http_req_duration{expected_response:true}": {"avg": 1, "med": 1, "max": 2},
+    "ws_sessions": {"count": 2, "rate": 0.2},
+    "orders": {"count": 12, "rate": 1.2},
+    "queue_depth": {"value": 3, "min": 0, "max": 9},
+    "login_ok": {"passes": 9, "fails": 1, "value": 0.9, "thresholds": {"rate>0.95": {"ok": false}}},
+    "checkout_time": {"avg": 210.5, "min": 100, "med": 200, "max": 400, "p(90)": 300, "p(95)": 350},
+    "checkout_time{step:pay, region:eu}": {"avg": 300, "p(95)": 390}
+  }}`)
+
+	metrics := e.CustomMetrics()
+	require.Len(t, metrics, 5)
+	assert.Equal(t, []CustomMetric{
+		{Name: "checkout_time", Type: TypeTrend, Values: map[string]float64{
+			"avg": 210.5, "min": 100, "med": 200, "max": 400, "p(90)": 300, "p(95)": 350,
+		}},
+		{Name: "checkout_time", Type: TypeTrend, Tags: map[string]string{"step": "pay", "region": "eu"},
+			Values: map[string]float64{"avg": 300, "p(95)": 390}},
+		{Name: "login_ok", Type: TypeRate, Values: map[string]float64{"passes": 9, "fails": 1, "value": 0.9}},
+		{Name: "orders", Type: TypeCounter, Values: map[string]float64{"count": 12, "rate": 1.2}},
+		{Name: "queue_depth", Type: TypeGauge, Values: map[string]float64{"value": 3, "min": 0, "max": 9}},
+	}, metrics)
+
+	assert.Empty(t, readExport(t, `{"metrics": {"vus": {"value": 1, "min": 1, "max": 1}}}`).CustomMetrics())
+
+	_, err := ReadExport(strings.NewReader(""))
+	require.Error(t, err)
+}
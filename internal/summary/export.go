@@ -0,0 +1,125 @@
+package summary
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+// Export is the end-of-test summary k6 writes with --summary-export.
+type Export struct {
+	// Metrics maps the metrics and submetrics of the run to their values,
+	// and to the outcome of their thresholds.
+	Metrics map[string]map[string]json.RawMessage `json:"metrics"`
+	// SetupData is what the setup() of the script returned, in summaries
+	// written by the handleSummary of an entry module.
+	SetupData json.RawMessage `json:"setup_data,omitempty"`
+}
+
+// ReadExport reads the summary k6 exported to r.
+func ReadExport(r io.Reader) (*Export, error) {
+	var e Export
+	if err := json.NewDecoder(r).Decode(&e); err != nil {
+		return nil, fmt.Errorf("failed to read the summary export: %w", err)
+	}
+	return &e, nil
+}
+
+// values returns the numeric values of a metric, leaving out its thresholds.
+func (e *Export) values(metric string) map[string]float64 {
+	values := make(map[string]float64)
+	for name, raw := range e.Metrics[metric] {
+		var v float64
+		if err := json.Unmarshal(raw, &v); err == nil {
+			values[name] = v
+		}
+	}
+	return values
+}
+
+// Values returns the numeric values of each metric and submetric of the
+// exported summary, leaving out their thresholds.
+func (e *Export) Values() map[string]map[string]float64 {
+	values := make(map[string]map[string]float64, len(e.Metrics))
+	for metric := range e.Metrics {
+		values[metric] = e.values(metric)
+	}
+	return values
+}
+
+// statRe matches the statistic a threshold expression bounds: "p(95)" in
+// "p(95)<500".
+//
+//nolint:gochecknoglobals // Compiled once and reused.
+var statRe = regexp.MustCompile(`^\s*([a-z]+(?:\(\d+(?:\.\d+)?\))?)`)
+
+// Thresholds returns the outcome of the thresholds of the exported summary,
+// sorted by metric and expression, with the observed value of the statistic
+// each bounds. The export marks crossed thresholds true.
+func (e *Export) Thresholds() []Threshold {
+	var thresholds []Threshold
+	for metric, fields := range e.Metrics {
+		raw, ok := fields["thresholds"]
+		if !ok {
+			continue
+		}
+		var crossed map[string]bool
+		if err := json.Unmarshal(raw, &crossed); err != nil {
+			continue
+		}
+		values := e.values(metric)
+		kind, contains := e.text(metric, "type"), e.text(metric, "contains")
+		for expr, failed := range crossed {
+			th := Threshold{Metric: metric, Expression: expr, Passed: !failed}
+			if m := statRe.FindStringSubmatch(expr); m != nil {
+				th.Value = observed(m[1], values, kind, contains)
+			}
+			thresholds = append(thresholds, th)
+		}
+	}
+	sort.Slice(thresholds, func(i, j int) bool {
+		if thresholds[i].Metric != thresholds[j].Metric {
+			return thresholds[i].Metric < thresholds[j].Metric
+		}
+		return thresholds[i].Expression < thresholds[j].Expression
+	})
+	return thresholds
+}
+
+// text returns a string field of a metric, such as the "type" and
+// "contains" the summary handler of the server exports.
+func (e *Export) text(metric, field string) string {
+	var s string
+	_ = json.Unmarshal(e.Metrics[metric][field], &s)
+	return s
+}
+
+// observed formats the value of stat like the k6 summary: rates of rate
+// metrics in percent, times in milliseconds. It is empty when the export
+// lacks the statistic.
+func observed(stat string, values map[string]float64, kind, contains string) string {
+	key := stat
+	if stat == "rate" && (kind == TypeRate || kind == "") {
+		if _, ok := values["value"]; ok {
+			key = "value"
+		}
+	}
+	v, ok := values[key]
+	if !ok {
+		return ""
+	}
+	s := strconv.FormatFloat(math.Round(v*100)/100, 'f', -1, 64)
+	switch {
+	case key == "value" && stat == "rate":
+		s = strconv.FormatFloat(v*100, 'f', 2, 64) + "%"
+	case stat == "rate":
+		s += "/s"
+	case contains == "time" && stat != "count":
+		s += "ms"
+	}
+	return stat + "=" + s
+}
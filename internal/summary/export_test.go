@@ -0,0 +1,28 @@
+package summary
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExportThresholds(t *testing.T) {
+	t.Parallel()
+
+	e := readExport(t, `{
+  "metrics": {
+    "http_req_duration": {"avg": 120.5, "p(95)": 512.25, "type": "trend", "contains": "time",
+      "thresholds": {"p(95)<500": true, "avg<200": false}},
+    "http_req_failed": {"value": 0.008, "passes": 2, "fails": 248, "thresholds": {"rate<0.01": false}},
+    "http_reqs": {"count": 250, "rate": 24.9, "type": "counter", "thresholds": {"rate>10": false}},
+    "checks": {"value": 1, "passes": 250, "fails": 0}
+  }
+}`)
+	assert.Equal(t, []Threshold{
+		{Metric: "http_req_duration", Expression: "avg<200", Value: "avg=120.5ms", Passed: true},
+		{Metric: "http_req_duration", Expression: "p(95)<500", Value: "p(95)=512.25ms"},
+		{Metric: "http_req_failed", Expression: "rate<0.01", Value: "rate=0.80%", Passed: true},
+		{Metric: "http_reqs", Expression: "rate>10", Value: "rate=24.9/s", Passed: true},
+	}, e.Thresholds())
+	assert.Empty(t, readExport(t, networkExport).Thresholds())
+}
@@ -0,0 +1,62 @@
+package summary
+
+import "strings"
+
+// Format is the layout of the end-of-test summary k6 printed.
+type Format string
+
+// Formats of the end-of-test summary. The k6 version alone does not tell
+// them apart: k6 1.x prints the legacy summary with --summary-mode=legacy.
+const (
+	// FormatLegacy is the summary of k6 0.x: metric lines marked when the
+	// metric has thresholds, checks, and groups headed by their name.
+	FormatLegacy Format = "legacy"
+	// FormatV1 is the summary k6 1.x prints by default, in THRESHOLDS,
+	// TOTAL RESULTS, SCENARIO and GROUP sections, with the observed value
+	// of each threshold expression.
+	FormatV1 Format = "v1"
+)
+
+// totalTitle heads the aggregated results of the k6 1.x summary.
+const totalTitle = "TOTAL RESULTS"
+
+// Summary is the end-of-test summary of a run.
+type Summary struct {
+	Format     Format      `json:"format"`
+	Thresholds []Threshold `json:"thresholds,omitempty"`
+	Checks     []Check     `json:"checks,omitempty"`
+	Metrics    []Metric    `json:"metrics,omitempty"`
+}
+
+// DetectFormat returns the format of the end-of-test summary in output,
+// or "" when output holds no summary.
+func DetectFormat(output string) Format {
+	legacy := false
+	for _, line := range strings.Split(output, "\n") {
+		if m := sectionRe.FindStringSubmatch(line); m != nil && (m[1] == thresholdsTitle || m[1] == totalTitle) {
+			return FormatV1
+		}
+		if !legacy && metricRe.MatchString(line) {
+			legacy = true
+		}
+	}
+	if legacy {
+		return FormatLegacy
+	}
+	return ""
+}
+
+// Parse returns the end-of-test summary in output, read according to its
+// format, or nil when output holds none.
+func Parse(output string) *Summary {
+	format := DetectFormat(output)
+	if format == "" {
+		return nil
+	}
+	return &Summary{
+		Format:     format,
+		Thresholds: thresholds(output, format),
+		Checks:     checks(output, format),
+		Metrics:    Metrics(output),
+	}
+}
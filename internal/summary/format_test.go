@@ -0,0 +1,84 @@
+package summary
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFixtures(t *testing.T) {
+	t.Parallel()
+
+	checks := []Check{
+		{Name: "status is 200", Passed: true},
+		{Name: "has body", Passes: 19, Fails: 1},
+		{Name: "order created", Group: "checkout", Passed: true},
+	}
+	legacyThresholds := []Threshold{
+		{Metric: "http_req_duration", Passed: true},
+		{Metric: "http_req_failed", Passed: false},
+	}
+	v1Thresholds := []Threshold{
+		{Metric: "http_req_duration", Expression: "p(95)<500", Value: "p(95)=125.89ms", Passed: true},
+		{Metric: "http_req_failed", Expression: "rate<0.01", Value: "rate=5.00%", Passed: false},
+	}
+	duration := map[string]string{
+		"avg": "115.86ms", "min": "109.2ms", "med": "114.36ms", "max": "132.2ms", "p(90)": "121.74ms", "p(95)": "125.89ms",
+	}
+
+	tests := []struct {
+		file       string
+		format     Format
+		thresholds []Threshold
+		checks     string
+	}{
+		{file: "k6-v0.49.0.txt", format: FormatLegacy, thresholds: legacyThresholds, checks: "checks"},
+		{file: "k6-v0.57.0.txt", format: FormatLegacy, thresholds: legacyThresholds, checks: "checks"},
+		{file: "k6-v1.0.0.txt", format: FormatV1, thresholds: v1Thresholds, checks: "checks_succeeded"},
+		{file: "k6-v1.3.0-full.txt", format: FormatV1, thresholds: v1Thresholds, checks: "checks_succeeded"},
+		{file: "k6-v1.3.0-legacy.txt", format: FormatLegacy, thresholds: legacyThresholds, checks: "checks"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			t.Parallel()
+
+			output, err := os.ReadFile(filepath.Join("testdata", tt.file))
+			require.NoError(t, err)
+			s := Parse(string(output))
+			require.NotNil(t, s)
+			assert.Equal(t, tt.format, s.Format)
+			assert.Equal(t, tt.thresholds, s.Thresholds)
+			assert.Equal(t, checks, s.Checks)
+
+			metrics := make(map[string]map[string]string, len(s.Metrics))
+			for _, m := range s.Metrics {
+				metrics[m.Name] = m.Values
+			}
+			assert.Equal(t, duration, metrics["http_req_duration"])
+			assert.Equal(t, map[string]string{"value": "40", "rate": "3.905068/s"}, metrics["http_reqs"])
+			assert.Equal(t, "98.33%", metrics[tt.checks]["value"])
+		})
+	}
+}
+
+func TestDetectFormat(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, FormatV1, DetectFormat(abortedOutput))
+	assert.Equal(t, FormatLegacy, DetectFormat("     iterations.....................: 7      0.69/s"))
+	assert.Equal(t, Format(""), DetectFormat("running (0m01.0s), 1/1 VUs, 0 complete and 0 interrupted iterations"))
+	assert.Nil(t, Parse("level=error msg=\"could not initialize\""))
+
+	// Legacy groups are headed by their name, even in capitals
+	legacy := `
+     █ API
+
+       ✓ listed
+
+     checks.........................: 100.00% ✓ 1        ✗ 0
+`
+	assert.Equal(t, []Check{{Name: "listed", Group: "API", Passed: true}}, Checks(legacy))
+}
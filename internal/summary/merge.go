@@ -0,0 +1,129 @@
+package summary
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+)
+
+// Merge combines the summaries exported by the instances of a distributed
+// test, each of which ran a segment of its load, into the summary of the
+// whole test. Counts, per-second rates and the passes and fails of rate
+// metrics add up, as do the VUs of every instance; minimums and maximums are
+// kept, and the values of other gauges averaged. The samples behind trends
+// are gone, so the average of a trend is the mean of the averages of the
+// instances, and its median and percentiles the highest of theirs, an upper
+// bound of the value over all samples. A threshold is crossed when it was on
+// any instance, as each evaluated it on its own segment. Merge returns nil
+// when there are no exports.
+func Merge(exports []*Export) *Export {
+	var parts []*Export
+	for _, e := range exports {
+		if e != nil {
+			parts = append(parts, e)
+		}
+	}
+	if len(parts) == 0 {
+		return nil
+	}
+
+	merged := &Export{Metrics: make(map[string]map[string]json.RawMessage)}
+	for _, e := range parts {
+		for name := range e.Metrics {
+			if merged.Metrics[name] != nil {
+				continue
+			}
+			merged.Metrics[name] = mergeMetric(name, parts)
+		}
+	}
+	return merged
+}
+
+// mergeMetric merges the fields of the metric name of parts.
+func mergeMetric(name string, parts []*Export) map[string]json.RawMessage {
+	fields := make(map[string]json.RawMessage)
+	stats := make(map[string][]float64)
+	crossed := make(map[string]bool)
+	for _, e := range parts {
+		metric, ok := e.Metrics[name]
+		if !ok {
+			continue
+		}
+		for field, raw := range metric {
+			var v float64
+			switch {
+			case field == "thresholds":
+				var th map[string]bool
+				if err := json.Unmarshal(raw, &th); err == nil {
+					for expr, failed := range th {
+						crossed[expr] = crossed[expr] || failed
+					}
+				}
+			case json.Unmarshal(raw, &v) == nil:
+				stats[field] = append(stats[field], v)
+			default:
+				// Text fields, such as the type and contents of the metric.
+				if _, ok := fields[field]; !ok {
+					fields[field] = raw
+				}
+			}
+		}
+	}
+
+	base, _, _ := strings.Cut(name, "{")
+	for field, values := range stats {
+		fields[field] = numberJSON(mergeStat(base, field, values))
+	}
+	// The share of passes is that of the summed passes and fails.
+	if passes, fails := sum(stats["passes"]), sum(stats["fails"]); len(stats["passes"]) > 0 && passes+fails > 0 {
+		fields["value"] = numberJSON(passes / (passes + fails))
+	}
+	if len(crossed) > 0 {
+		data, _ := json.Marshal(crossed)
+		fields["thresholds"] = data
+	}
+	return fields
+}
+
+// mergeStat merges the values of the statistic field of the metric name
+// across instances.
+func mergeStat(name, field string, values []float64) float64 {
+	switch {
+	case field == "count" || field == "rate" || field == "passes" || field == "fails":
+		return sum(values)
+	case field == "min":
+		return extreme(values, math.Min)
+	case field == "value" && (name == "vus" || name == "vus_max"):
+		// Every instance runs its own VUs.
+		return sum(values)
+	case field == "avg" || field == "value":
+		return sum(values) / float64(len(values))
+	default:
+		// max, med and percentiles.
+		return extreme(values, math.Max)
+	}
+}
+
+func sum(values []float64) float64 {
+	var total float64
+	for _, v := range values {
+		total += v
+	}
+	return total
+}
+
+func extreme(values []float64, pick func(a, b float64) float64) float64 {
+	result := values[0]
+	for _, v := range values[1:] {
+		result = pick(result, v)
+	}
+	return result
+}
+
+func numberJSON(v float64) json.RawMessage {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return json.RawMessage("0")
+	}
+	return data
+}
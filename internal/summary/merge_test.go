@@ -0,0 +1,48 @@
+package summary
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMerge(t *testing.T) {
+	t.Parallel()
+
+	assert.Nil(t, Merge(nil))
+	assert.Nil(t, Merge([]*Export{nil}))
+
+	merged := Merge([]*Export{
+		readExport(t, `{"metrics": {
+  "http_req_duration": {"avg": 100, "min": 20, "med": 90, "max": 400, "p(95)": 300, "type": "trend",
+    "contains": "time", "thresholds": {"p(95)<500": false}},
+  "http_req_failed": {"value": 0.01, "passes": 1, "fails": 99, "type": "rate", "thresholds": {"rate<0.05": false}},
+  "http_reqs": {"count": 100, "rate": 10, "type": "counter"},
+  "vus_max": {"value": 50, "min": 50, "max": 50, "type": "gauge"}
+}}`),
+		nil,
+		readExport(t, `{"metrics": {
+  "http_req_duration": {"avg": 200, "min": 30, "med": 150, "max": 900, "p(95)": 600, "type": "trend",
+    "contains": "time", "thresholds": {"p(95)<500": true}},
+  "http_req_failed": {"value": 0.09, "passes": 9, "fails": 91, "type": "rate", "thresholds": {"rate<0.05": true}},
+  "http_reqs": {"count": 100, "rate": 10, "type": "counter"},
+  "vus_max": {"value": 50, "min": 50, "max": 50, "type": "gauge"},
+  "orders": {"count": 3, "rate": 0.3, "type": "counter"}
+}}`),
+	})
+	require.NotNil(t, merged)
+
+	values := merged.Values()
+	assert.Equal(t, map[string]float64{"avg": 150, "min": 20, "med": 150, "max": 900, "p(95)": 600},
+		values["http_req_duration"])
+	assert.Equal(t, map[string]float64{"value": 0.05, "passes": 10, "fails": 190}, values["http_req_failed"])
+	assert.Equal(t, map[string]float64{"count": 200, "rate": 20}, values["http_reqs"])
+	assert.Equal(t, map[string]float64{"value": 100, "min": 50, "max": 50}, values["vus_max"])
+	assert.Equal(t, map[string]float64{"count": 3, "rate": 0.3}, values["orders"])
+
+	assert.Equal(t, []Threshold{
+		{Metric: "http_req_duration", Expression: "p(95)<500", Value: "p(95)=600ms"},
+		{Metric: "http_req_failed", Expression: "rate<0.05", Value: "rate=5.00%"},
+	}, merged.Thresholds())
+}
@@ -0,0 +1,76 @@
+package summary
+
+// Bottlenecks of the HTTP requests of a run.
+const (
+	// BottleneckConnection is the time spent getting a connection: waiting
+	// for one, connecting and the TLS handshake.
+	BottleneckConnection = "connection"
+	// BottleneckServer is the time spent waiting for the server to respond.
+	BottleneckServer = "server"
+	// BottleneckTransfer is the time spent sending requests and receiving
+	// responses.
+	BottleneckTransfer = "transfer"
+)
+
+// Timing holds the statistics of an HTTP timing metric, in milliseconds.
+type Timing struct {
+	Avg float64 `json:"avg"`
+	Med float64 `json:"med"`
+	P90 float64 `json:"p90"`
+	P95 float64 `json:"p95"`
+	Max float64 `json:"max"`
+}
+
+// Network breaks the time of the HTTP requests of a run down into its
+// phases, telling network-layer delays from server latency. Duration is the
+// http_req_duration k6 reports, which leaves out the time to get a
+// connection: Blocked, Connecting and TLSHandshaking.
+type Network struct {
+	Duration       Timing `json:"duration"`
+	Blocked        Timing `json:"blocked"`
+	Connecting     Timing `json:"connecting"`
+	TLSHandshaking Timing `json:"tls_handshaking"`
+	Sending        Timing `json:"sending"`
+	Waiting        Timing `json:"waiting"`
+	Receiving      Timing `json:"receiving"`
+	// Bottleneck is the phase requests spend the most time in on average, one
+	// of the Bottleneck constants.
+	Bottleneck string `json:"bottleneck"`
+}
+
+// Network returns the HTTP timings of the exported summary, or nil when the
+// run made no HTTP requests.
+func (e *Export) Network() *Network {
+	if !e.has("http_req_duration") {
+		return nil
+	}
+
+	n := &Network{
+		Duration:       e.timing("http_req_duration"),
+		Blocked:        e.timing("http_req_blocked"),
+		Connecting:     e.timing("http_req_connecting"),
+		TLSHandshaking: e.timing("http_req_tls_handshaking"),
+		Sending:        e.timing("http_req_sending"),
+		Waiting:        e.timing("http_req_waiting"),
+		Receiving:      e.timing("http_req_receiving"),
+	}
+	n.Bottleneck = BottleneckServer
+	connection := n.Blocked.Avg + n.Connecting.Avg + n.TLSHandshaking.Avg
+	transfer := n.Sending.Avg + n.Receiving.Avg
+	switch {
+	case connection > n.Waiting.Avg && connection >= transfer:
+		n.Bottleneck = BottleneckConnection
+	case transfer > n.Waiting.Avg:
+		n.Bottleneck = BottleneckTransfer
+	}
+	return n
+}
+
+// timing returns the statistics of a trend metric. Statistics left out of
+// summaryTrendStats are zero.
+func (e *Export) timing(metric string) Timing {
+	values := e.values(metric)
+	return Timing{
+		Avg: values["avg"], Med: values["med"], P90: values["p(90)"], P95: values["p(95)"], Max: values["max"],
+	}
+}
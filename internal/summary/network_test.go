@@ -0,0 +1,51 @@
+package summary
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const networkExport = `{
+  "root_group": {"name": "", "checks": {}},
+  "metrics": {
+    "http_reqs": {"count": 20, "rate": 1.9},
+    "http_req_duration": {"avg": 120.5, "min": 80, "med": 110, "max": 300, "p(90)": 200, "p(95)": 250},
+    "http_req_blocked": {"avg": 150.2, "min": 0, "med": 0, "max": 900, "p(90)": 600, "p(95)": 800},
+    "http_req_connecting": {"avg": 40.1, "min": 0, "med": 0, "max": 200, "p(90)": 100, "p(95)": 150},
+    "http_req_tls_handshaking": {"avg": 100, "min": 0, "med": 0, "max": 600, "p(90)": 400, "p(95)": 500},
+    "http_req_sending": {"avg": 0.1, "min": 0, "med": 0.1, "max": 0.5, "p(90)": 0.2, "p(95)": 0.3},
+    "http_req_waiting": {"avg": 120, "min": 79, "med": 109, "max": 299, "p(90)": 199, "p(95)": 249},
+    "http_req_receiving": {"avg": 0.4, "min": 0.1, "med": 0.3, "max": 2, "p(90)": 0.8, "p(95)": 1}
+  }
+}`
+
+func TestNetwork(t *testing.T) {
+	t.Parallel()
+
+	n := readExport(t, networkExport).Network()
+	require.NotNil(t, n)
+	assert.Equal(t, Timing{Avg: 120.5, Med: 110, P90: 200, P95: 250, Max: 300}, n.Duration)
+	assert.InDelta(t, 800, n.Blocked.P95, 0)
+	assert.InDelta(t, 40.1, n.Connecting.Avg, 0)
+	assert.InDelta(t, 500, n.TLSHandshaking.P95, 0)
+	assert.Equal(t, BottleneckConnection, n.Bottleneck)
+
+	// Reused connections leave the server as the bottleneck
+	fast := strings.NewReplacer(`"avg": 150.2`, `"avg": 0.1`, `"avg": 40.1`, `"avg": 0`, `"avg": 100,`, `"avg": 0,`).
+		Replace(networkExport)
+	n = readExport(t, fast).Network()
+	assert.Equal(t, BottleneckServer, n.Bottleneck)
+
+	// Without HTTP requests there is nothing to break down
+	assert.Nil(t, readExport(t, `{"metrics": {"iterations": {"count": 1, "rate": 1}}}`).Network())
+}
+
+func readExport(t *testing.T, s string) *Export {
+	t.Helper()
+	e, err := ReadExport(strings.NewReader(s))
+	require.NoError(t, err)
+	return e
+}
@@ -0,0 +1,80 @@
+package summary
+
+// Counter holds the total and per second rate of a counter metric.
+type Counter struct {
+	Count float64 `json:"count"`
+	Rate  float64 `json:"rate"`
+}
+
+// WebSocket summarizes the WebSocket metrics of k6/ws and k6/websockets.
+// Timings are in milliseconds.
+type WebSocket struct {
+	Sessions         Counter `json:"sessions"`
+	Connecting       Timing  `json:"connecting"`
+	SessionDuration  Timing  `json:"session_duration"`
+	MessagesSent     Counter `json:"msgs_sent"`
+	MessagesReceived Counter `json:"msgs_received"`
+	// Ping is only recorded by scripts that ping.
+	Ping *Timing `json:"ping,omitempty"`
+}
+
+// GRPC summarizes the gRPC metrics of k6/net/grpc. Streams are only
+// recorded by scripts using streams.
+type GRPC struct {
+	Duration         Timing   `json:"duration"`
+	Streams          *Counter `json:"streams,omitempty"`
+	MessagesSent     *Counter `json:"stream_msgs_sent,omitempty"`
+	MessagesReceived *Counter `json:"stream_msgs_received,omitempty"`
+}
+
+// Protocols groups the metrics of the non-HTTP protocols a run used.
+type Protocols struct {
+	WebSocket *WebSocket `json:"websocket,omitempty"`
+	GRPC      *GRPC      `json:"grpc,omitempty"`
+}
+
+// Protocols returns the WebSocket and gRPC metrics of the exported summary,
+// or nil when the run used neither.
+func (e *Export) Protocols() *Protocols {
+	var p Protocols
+	if e.has("ws_sessions") || e.has("ws_connecting") {
+		p.WebSocket = &WebSocket{
+			Sessions:         e.counter("ws_sessions"),
+			Connecting:       e.timing("ws_connecting"),
+			SessionDuration:  e.timing("ws_session_duration"),
+			MessagesSent:     e.counter("ws_msgs_sent"),
+			MessagesReceived: e.counter("ws_msgs_received"),
+		}
+		if e.has("ws_ping") {
+			ping := e.timing("ws_ping")
+			p.WebSocket.Ping = &ping
+		}
+	}
+	if e.has("grpc_req_duration") || e.has("grpc_streams") {
+		p.GRPC = &GRPC{Duration: e.timing("grpc_req_duration")}
+		for metric, c := range map[string]**Counter{
+			"grpc_streams":               &p.GRPC.Streams,
+			"grpc_streams_msgs_sent":     &p.GRPC.MessagesSent,
+			"grpc_streams_msgs_received": &p.GRPC.MessagesReceived,
+		} {
+			if e.has(metric) {
+				counter := e.counter(metric)
+				*c = &counter
+			}
+		}
+	}
+	if p.WebSocket == nil && p.GRPC == nil {
+		return nil
+	}
+	return &p
+}
+
+func (e *Export) has(metric string) bool {
+	_, ok := e.Metrics[metric]
+	return ok
+}
+
+func (e *Export) counter(metric string) Counter {
+	values := e.values(metric)
+	return Counter{Count: values["count"], Rate: values["rate"]}
+}
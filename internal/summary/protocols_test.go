@@ -0,0 +1,44 @@
+package summary
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProtocols(t *testing.T) {
+	t.Parallel()
+
+	p := readExport(t, `{"metrics": {
+    "ws_sessions": {"count": 10, "rate": 1},
+    "ws_connecting": {"avg": 12.5, "med": 11, "max": 30, "p(90)": 20, "p(95)": 25},
+    "ws_session_duration": {"avg": 5000, "med": 5000, "max": 5100, "p(90)": 5050, "p(95)": 5080},
+    "ws_msgs_sent": {"count": 100, "rate": 10},
+    "ws_msgs_received": {"count": 300, "rate": 30},
+    "grpc_req_duration": {"avg": 8, "med": 7, "max": 40, "p(90)": 12, "p(95)": 15},
+    "iterations": {"count": 10, "rate": 1}
+  }}`).Protocols()
+	require.NotNil(t, p)
+
+	require.NotNil(t, p.WebSocket)
+	assert.Equal(t, Counter{Count: 10, Rate: 1}, p.WebSocket.Sessions)
+	assert.Equal(t, Timing{Avg: 12.5, Med: 11, P90: 20, P95: 25, Max: 30}, p.WebSocket.Connecting)
+	assert.InDelta(t, 5080, p.WebSocket.SessionDuration.P95, 0)
+	assert.Equal(t, Counter{Count: 300, Rate: 30}, p.WebSocket.MessagesReceived)
+	assert.Nil(t, p.WebSocket.Ping)
+
+	require.NotNil(t, p.GRPC)
+	assert.InDelta(t, 15, p.GRPC.Duration.P95, 0)
+	assert.Nil(t, p.GRPC.Streams)
+
+	p = readExport(t, `{"metrics": {"grpc_streams": {"count": 2, "rate": 0.2},
+    "grpc_streams_msgs_received": {"count": 50, "rate": 5}}}`).Protocols()
+	require.NotNil(t, p)
+	assert.Nil(t, p.WebSocket)
+	assert.Equal(t, &Counter{Count: 2, Rate: 0.2}, p.GRPC.Streams)
+	assert.Equal(t, &Counter{Count: 50, Rate: 5}, p.GRPC.MessagesReceived)
+	assert.Nil(t, p.GRPC.MessagesSent)
+
+	assert.Nil(t, readExport(t, networkExport).Protocols())
+}
@@ -0,0 +1,249 @@
+// Package summary extracts threshold and check results and early-exit
+// details from the console output of k6 run.
+package summary
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Threshold is the outcome of one threshold expression.
+type Threshold struct {
+	Metric     string `json:"metric"`
+	Expression string `json:"expression,omitempty"`
+	// Value is the observed value k6 printed next to the expression, such
+	// as "p(95)=512.3ms".
+	Value  string `json:"value,omitempty"`
+	Passed bool   `json:"passed"`
+}
+
+//nolint:gochecknoglobals // Compiled once and reused.
+var (
+	// abortRe matches the error k6 logs when an abortOnFail threshold stops the test.
+	abortRe = regexp.MustCompile(`thresholds on metrics '([^']*)' were crossed; at least one has abortOnFail enabled`)
+	// runningRe matches the progress line k6 prints while the test runs.
+	runningRe = regexp.MustCompile(`running \((?:(\d+)h)?(\d+)m(\d+(?:\.\d+)?)s\)`)
+	// thresholdRe matches a threshold result of the end-of-test summary:
+	//   ✗ 'p(95)<500' p(95)=512.3ms
+	thresholdRe = regexp.MustCompile(`^\s*([✓✗])\s+'([^']+)'\s*(.*)$`)
+	// legacyMetricRe matches a metric line of the pre-1.0 summary, where
+	// the mark reports the metric's thresholds as a whole:
+	//   ✗ http_req_duration..............: avg=...
+	legacyMetricRe = regexp.MustCompile(`^\s*([✓✗])\s+([\w{}:.,=\- ]+?)\.{2,}:`)
+	// metricHeaderRe matches the metric a group of threshold results belongs to.
+	metricHeaderRe = regexp.MustCompile(`^\s*([A-Za-z_][\w]*(?:\{[^}]*\})?)\s*$`)
+	// checkRe matches a check result of the end-of-test summary:
+	//   ✗ status is 200
+	checkRe = regexp.MustCompile(`^\s*([✓✗])\s+(.+?)\s*$`)
+	// checkCountsRe matches the pass and fail counts under a failed check:
+	//   ↳  90% — ✓ 9 / ✗ 1
+	checkCountsRe = regexp.MustCompile(`↳\s+\d+(?:\.\d+)?%\s+—\s+✓\s+(\d+)\s+/\s+✗\s+(\d+)`)
+	// sectionRe matches a section or group header of the summary:
+	//   █ TOTAL RESULTS
+	sectionRe = regexp.MustCompile(`^\s*█\s+(.+?)\s*$`)
+	// metricRe matches a metric line of the end-of-test summary:
+	//   http_req_duration..............: avg=3.1ms min=1ms med=2ms max=10ms p(90)=5ms p(95)=6ms
+	metricRe = regexp.MustCompile(`^\s*(?:[✓✗]\s+)?([A-Za-z_][\w]*(?:\{[^}]*\})?)\.{2,}:\s*(.*)$`)
+)
+
+//nolint:gochecknoglobals // Read-only lookup table.
+var sizeUnits = map[string]bool{
+	"B": true, "kB": true, "MB": true, "GB": true, "TB": true,
+	"B/s": true, "kB/s": true, "MB/s": true, "GB/s": true, "TB/s": true,
+}
+
+// Metric is a metric line of the end-of-test summary. Values holds the
+// named statistics of trends, such as "avg" and "p(95)", and the "value"
+// and per second "rate" of the other metrics, as printed, e.g. "3.1ms".
+type Metric struct {
+	Name   string            `json:"name"`
+	Values map[string]string `json:"values"`
+}
+
+// Check is the outcome of one check() of the script.
+type Check struct {
+	Name  string `json:"name"`
+	Group string `json:"group,omitempty"`
+	// Passes and Fails are only known for checks that failed at least once.
+	Passes int  `json:"passes,omitempty"`
+	Fails  int  `json:"fails,omitempty"`
+	Passed bool `json:"passed"`
+}
+
+// thresholdsTitle heads the thresholds section of the k6 1.x summary.
+const thresholdsTitle = "THRESHOLDS"
+
+// AbortedMetrics returns the metrics named by k6 when an abortOnFail
+// threshold stopped the test, and whether such an abort happened.
+func AbortedMetrics(output string) ([]string, bool) {
+	m := abortRe.FindStringSubmatch(output)
+	if m == nil {
+		return nil, false
+	}
+	var metrics []string
+	for _, name := range strings.Split(m[1], ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			metrics = append(metrics, name)
+		}
+	}
+	return metrics, true
+}
+
+// Elapsed returns the test time of the last progress line in output.
+func Elapsed(output string) (time.Duration, bool) {
+	all := runningRe.FindAllStringSubmatch(output, -1)
+	if len(all) == 0 {
+		return 0, false
+	}
+	m := all[len(all)-1]
+	hours, _ := strconv.Atoi(m[1])
+	minutes, _ := strconv.Atoi(m[2])
+	seconds, _ := strconv.ParseFloat(m[3], 64)
+	d := time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute +
+		time.Duration(seconds*float64(time.Second))
+	return d.Round(100 * time.Millisecond), true
+}
+
+// Thresholds returns the threshold results of the end-of-test summary.
+// The k6 1.x summary lists each expression under its metric; the legacy
+// one only marks the metric, leaving Expression empty.
+func Thresholds(output string) []Threshold {
+	return thresholds(output, DetectFormat(output))
+}
+
+func thresholds(output string, format Format) []Threshold {
+	var results []Threshold
+	lines := strings.Split(output, "\n")
+	if format == FormatLegacy {
+		for _, line := range lines {
+			if m := legacyMetricRe.FindStringSubmatch(line); m != nil {
+				results = append(results, Threshold{Metric: strings.TrimSpace(m[2]), Passed: m[1] == "✓"})
+			}
+		}
+		return results
+	}
+
+	inSection, metric := false, ""
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(line), "█"))
+		switch {
+		case trimmed == thresholdsTitle:
+			inSection, metric = true, ""
+			continue
+		case !inSection:
+			continue
+		case trimmed == "":
+			continue
+		case strings.HasPrefix(strings.TrimSpace(line), "█"):
+			// The next section of the summary.
+			inSection = false
+			continue
+		}
+		if m := thresholdRe.FindStringSubmatch(line); m != nil && metric != "" {
+			results = append(results, Threshold{
+				Metric:     metric,
+				Expression: m[2],
+				Value:      strings.TrimSpace(m[3]),
+				Passed:     m[1] == "✓",
+			})
+			continue
+		}
+		if m := metricHeaderRe.FindStringSubmatch(line); m != nil {
+			metric = m[1]
+		}
+	}
+	return results
+}
+
+// Checks returns the check results of the end-of-test summary, with the
+// group each was made in. Groups are headed "█ GROUP: name" in the k6 1.x
+// summary and "█ name" in the legacy one.
+func Checks(output string) []Check {
+	return checks(output, DetectFormat(output))
+}
+
+func checks(output string, format Format) []Check {
+	var checks []Check
+	skip, group := false, ""
+	for _, line := range strings.Split(output, "\n") {
+		if m := sectionRe.FindStringSubmatch(line); m != nil {
+			header := m[1]
+			// The checks of each scenario repeat those of the total
+			skip = format != FormatLegacy && (header == thresholdsTitle || strings.HasPrefix(header, "SCENARIO: "))
+			switch {
+			case format == FormatLegacy:
+				group = header
+			case strings.HasPrefix(header, "GROUP: "):
+				group = strings.TrimPrefix(header, "GROUP: ")
+			default:
+				// TOTAL RESULTS, SCENARIO: ... and other 1.x sections
+				group = ""
+			}
+			continue
+		}
+		if skip {
+			continue
+		}
+		if m := checkCountsRe.FindStringSubmatch(line); m != nil && len(checks) > 0 {
+			last := &checks[len(checks)-1]
+			last.Passes, _ = strconv.Atoi(m[1])
+			last.Fails, _ = strconv.Atoi(m[2])
+			continue
+		}
+		m := checkRe.FindStringSubmatch(line)
+		if m == nil || legacyMetricRe.MatchString(line) || strings.HasPrefix(m[2], "'") {
+			continue
+		}
+		checks = append(checks, Check{Name: m[2], Group: group, Passed: m[1] == "✓"})
+	}
+	return checks
+}
+
+// Metrics returns the metrics of the end-of-test summary, in the order k6
+// printed them. Metrics printed again per scenario or group keep their
+// first, total, values.
+func Metrics(output string) []Metric {
+	var metrics []Metric
+	seen := make(map[string]bool)
+	for _, line := range strings.Split(output, "\n") {
+		m := metricRe.FindStringSubmatch(line)
+		if m == nil || seen[m[1]] {
+			continue
+		}
+		seen[m[1]] = true
+		metrics = append(metrics, Metric{Name: m[1], Values: metricValues(m[2])})
+	}
+	return metrics
+}
+
+// metricValues splits the values of a metric line. Named values are kept
+// by name; of the others, the first is the value and one ending in "/s"
+// the rate, so "20 1.9/s" and "0.00% 0 out of 20" both parse.
+func metricValues(s string) map[string]string {
+	var tokens []string
+	for _, tok := range strings.Fields(s) {
+		if n := len(tokens); n > 0 && sizeUnits[tok] {
+			// Sizes are printed with a space: "1.2 kB"
+			tokens[n-1] += " " + tok
+			continue
+		}
+		tokens = append(tokens, tok)
+	}
+
+	values := make(map[string]string)
+	for i, tok := range tokens {
+		if key, value, ok := strings.Cut(tok, "="); ok {
+			values[key] = value
+			continue
+		}
+		switch {
+		case strings.HasSuffix(tok, "/s"):
+			values["rate"] = tok
+		case i == 0:
+			values["value"] = tok
+		}
+	}
+	return values
+}
@@ -0,0 +1,170 @@
+package summary
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const abortedOutput = `
+running (0m01.0s), 10/10 VUs, 12 complete and 0 interrupted iterations
+default   [   3% ] 10 VUs  0m01.0s/30s
+running (0m12.4s), 10/10 VUs, 130 complete and 10 interrupted iterations
+default ✗ [  41% ] 10 VUs  0m12.4s/30s
+
+  █ THRESHOLDS
+
+    checks{check:status is 200}
+    ✓ 'rate==1' rate=100.00%
+
+    http_req_duration
+    ✗ 'p(95)<200' p(95)=512.3ms
+    ✓ 'avg<1000' avg=201.2ms
+
+
+  █ TOTAL RESULTS
+
+    checks_total.......: 130    10.48/s
+time="2026-10-14T10:00:12Z" level=error msg="thresholds on metrics 'http_req_duration, http_req_failed' were crossed; at least one has abortOnFail enabled, stopping test prematurely"
+`
+
+func TestAbortedMetrics(t *testing.T) {
+	t.Parallel()
+
+	metrics, ok := AbortedMetrics(abortedOutput)
+	require.True(t, ok)
+	assert.Equal(t, []string{"http_req_duration", "http_req_failed"}, metrics)
+
+	_, ok = AbortedMetrics("level=error msg=\"thresholds on metrics 'checks' have been crossed\"")
+	assert.False(t, ok)
+}
+
+func TestElapsed(t *testing.T) {
+	t.Parallel()
+
+	d, ok := Elapsed(abortedOutput)
+	require.True(t, ok)
+	assert.Equal(t, 12400*time.Millisecond, d)
+
+	d, ok = Elapsed("running (1h02m03.0s), 0/5 VUs")
+	require.True(t, ok)
+	assert.Equal(t, time.Hour+2*time.Minute+3*time.Second, d)
+
+	_, ok = Elapsed("no progress")
+	assert.False(t, ok)
+}
+
+func TestThresholds(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, []Threshold{
+		{Metric: "checks{check:status is 200}", Expression: "rate==1", Value: "rate=100.00%", Passed: true},
+		{Metric: "http_req_duration", Expression: "p(95)<200", Value: "p(95)=512.3ms", Passed: false},
+		{Metric: "http_req_duration", Expression: "avg<1000", Value: "avg=201.2ms", Passed: true},
+	}, Thresholds(abortedOutput))
+}
+
+func TestThresholdsLegacySummary(t *testing.T) {
+	t.Parallel()
+
+	output := `
+     ✓ status is 200
+
+     checks.........................: 100.00% ✓ 10       ✗ 0
+   ✗ http_req_duration..............: avg=512ms min=400ms med=500ms max=700ms p(90)=650ms p(95)=690ms
+     http_reqs......................: 10      1.2/s
+`
+	assert.Equal(t, []Threshold{{Metric: "http_req_duration", Passed: false}}, Thresholds(output))
+}
+
+func TestChecks(t *testing.T) {
+	t.Parallel()
+
+	output := `
+  █ THRESHOLDS
+
+    checks
+    ✗ 'rate==1' rate=90.00%
+
+
+  █ TOTAL RESULTS
+
+    checks_total.......................: 20      1.9/s
+    checks_succeeded...................: 90.00% 18 out of 20
+    checks_failed......................: 10.00% 2 out of 20
+
+    ✓ status is 200
+    ✗ has items
+      ↳  80% — ✓ 8 / ✗ 2
+
+  █ GROUP: checkout
+
+    ✓ order created
+`
+	assert.Equal(t, []Check{
+		{Name: "status is 200", Passed: true},
+		{Name: "has items", Passes: 8, Fails: 2},
+		{Name: "order created", Group: "checkout", Passed: true},
+	}, Checks(output))
+
+	legacy := `
+     █ login
+
+       ✓ logged in
+
+     ✗ status is 200
+      ↳  75% — ✓ 3 / ✗ 1
+
+     checks.........................: 85.71% ✓ 6        ✗ 1
+   ✓ http_req_duration..............: avg=3.1ms
+`
+	assert.Equal(t, []Check{
+		{Name: "logged in", Group: "login", Passed: true},
+		{Name: "status is 200", Group: "login", Passes: 3, Fails: 1},
+	}, Checks(legacy))
+}
+
+func TestMetrics(t *testing.T) {
+	t.Parallel()
+
+	output := `
+  █ TOTAL RESULTS
+
+    checks_succeeded...................: 90.00% 18 out of 20
+
+    HTTP
+    http_req_duration..................: avg=3.1ms min=1ms med=2ms max=10ms p(90)=5ms p(95)=6.2ms
+      { expected_response:true }.......: avg=3ms   min=1ms med=2ms max=9ms  p(90)=5ms p(95)=6ms
+    http_req_failed....................: 0.00%  0 out of 20
+    http_reqs..........................: 20     1.9/s
+
+    NETWORK
+    data_received......................: 1.2 kB 120 B/s
+
+  █ GROUP: checkout
+
+    http_req_duration..................: avg=9ms min=1ms med=2ms max=10ms p(90)=5ms p(95)=12ms
+`
+	assert.Equal(t, []Metric{
+		{Name: "checks_succeeded", Values: map[string]string{"value": "90.00%"}},
+		{Name: "http_req_duration", Values: map[string]string{
+			"avg": "3.1ms", "min": "1ms", "med": "2ms", "max": "10ms", "p(90)": "5ms", "p(95)": "6.2ms",
+		}},
+		{Name: "http_req_failed", Values: map[string]string{"value": "0.00%"}},
+		{Name: "http_reqs", Values: map[string]string{"value": "20", "rate": "1.9/s"}},
+		{Name: "data_received", Values: map[string]string{"value": "1.2 kB", "rate": "120 B/s"}},
+	}, Metrics(output))
+
+	legacy := `
+   ✗ checks.........................: 85.71% ✓ 6        ✗ 1
+     iterations.....................: 7      0.69/s
+     vus............................: 1      min=1      max=1
+`
+	assert.Equal(t, []Metric{
+		{Name: "checks", Values: map[string]string{"value": "85.71%"}},
+		{Name: "iterations", Values: map[string]string{"value": "7", "rate": "0.69/s"}},
+		{Name: "vus", Values: map[string]string{"value": "1", "min": "1", "max": "1"}},
+	}, Metrics(legacy))
+}
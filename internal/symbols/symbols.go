@@ -0,0 +1,169 @@
+// Package symbols indexes the k6 JavaScript API symbols and glossary terms of
+// a documentation version, such as http.get, check or SharedArray, by the
+// sections documenting them.
+package symbols
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/grafana/xk6-docs/docs"
+)
+
+// apiPrefix is the slug of the JavaScript API reference.
+const apiPrefix = "javascript-api/"
+
+//nolint:gochecknoglobals // Compiled once, read-only.
+var (
+	// reName matches the symbol opening a section title, such as "get" in
+	// "get( url, [params] )" or "Response.json" in "Response.json( [selector] )".
+	reName = regexp.MustCompile(`^[A-Za-z_$][\w$]*(?:\.[A-Za-z_$][\w$]*)*`)
+	// reTerm matches the headings of the glossary.
+	reTerm = regexp.MustCompile(`(?m)^#{2,3}\s+(.+?)\s*$`)
+)
+
+// Entry is a symbol or term and where it is documented.
+type Entry struct {
+	// Symbol is the qualified name, such as "http.get", or the term.
+	Symbol string `json:"symbol"`
+	// Module is the import path of the module of an API symbol, such as
+	// "k6/http".
+	Module string `json:"module,omitempty"`
+	Slug   string `json:"slug"`
+	Title  string `json:"title"`
+	// Anchor locates a glossary term in its section.
+	Anchor string `json:"anchor,omitempty"`
+}
+
+// Index maps the symbols of a documentation version to their entries.
+type Index struct {
+	// exact holds entries by lowercase qualified name, module path or term.
+	exact map[string][]Entry
+	// bare holds entries by lowercase unqualified name, such as "get".
+	bare    map[string][]Entry
+	symbols []string
+}
+
+// Build indexes the API sections of idx, and the "## Term" headings of the
+// glossary page of slug glossarySlug when glossary holds its content.
+func Build(idx *docs.Index, glossarySlug string, glossary []byte) *Index {
+	x := &Index{exact: make(map[string][]Entry), bare: make(map[string][]Entry)}
+	for i := range idx.Sections {
+		sec := &idx.Sections[i]
+		rest, ok := strings.CutPrefix(sec.Slug, apiPrefix)
+		if !ok {
+			continue
+		}
+		segments := strings.Split(rest, "/")
+		module := modulePath(segments[0])
+		if len(segments) == 1 {
+			x.add(module, "", Entry{Symbol: module, Module: module, Slug: sec.Slug, Title: sec.Title})
+			continue
+		}
+		name := reName.FindString(strings.TrimSpace(sec.Title))
+		if name == "" {
+			continue
+		}
+		x.add(qualify(idx, module, segments, name), lastPart(name),
+			Entry{Module: module, Slug: sec.Slug, Title: sec.Title})
+	}
+
+	for _, m := range reTerm.FindAllStringSubmatch(string(glossary), -1) {
+		term := strings.Trim(m[1], " *`")
+		e := Entry{Symbol: term, Slug: glossarySlug, Title: term, Anchor: anchor(term)}
+		x.add(term, "", e)
+		// "Virtual user (VU)" is also found as "Virtual user" and "VU"
+		if inner, ok := strings.CutSuffix(term, ")"); ok {
+			if name, abbr, ok := strings.Cut(inner, " ("); ok {
+				x.add(name, "", e)
+				x.add(abbr, "", e)
+			}
+		}
+	}
+
+	for symbol := range x.exact {
+		x.symbols = append(x.symbols, symbol)
+	}
+	sort.Strings(x.symbols)
+	return x
+}
+
+// Lookup returns the entries of symbol: those of its qualified name, such as
+// "http.get", or else those named like it in any module, such as every "get"
+// for "get" or "http.get" when no module documents it. Lookups ignore case and
+// trailing parentheses.
+func (x *Index) Lookup(symbol string) []Entry {
+	key := strings.ToLower(strings.TrimSuffix(strings.TrimSpace(symbol), "()"))
+	if entries, ok := x.exact[key]; ok {
+		return entries
+	}
+	return x.bare[lastPart(key)]
+}
+
+// Symbols returns the lowercase qualified names, module paths and terms of x
+// in order.
+func (x *Index) Symbols() []string {
+	return x.symbols
+}
+
+func (x *Index) add(symbol, bare string, e Entry) {
+	if e.Symbol == "" {
+		e.Symbol = symbol
+	}
+	key := strings.ToLower(symbol)
+	x.exact[key] = append(x.exact[key], e)
+	if bare = strings.ToLower(bare); bare != "" && bare != key {
+		x.bare[bare] = append(x.bare[bare], e)
+	}
+}
+
+// modulePath returns the import path of the module documented under a
+// javascript-api segment: "k6-http" documents k6/http, "jslib" the jslib
+// modules.
+func modulePath(segment string) string {
+	if rest, ok := strings.CutPrefix(segment, "k6-"); ok {
+		return "k6/" + strings.ReplaceAll(rest, "-", "/")
+	}
+	return segment
+}
+
+// qualify returns the name of a symbol documented under segments: names with
+// a dot are qualified already, others are qualified by the symbol of their
+// parent section, or by the module for its direct children. Names of the k6
+// module, such as check, are used bare, as scripts import them.
+func qualify(idx *docs.Index, module string, segments []string, name string) string {
+	if strings.Contains(name, ".") {
+		return name
+	}
+	if len(segments) > 2 {
+		parentSlug := apiPrefix + strings.Join(segments[:len(segments)-1], "/")
+		if parent, ok := idx.Lookup(parentSlug); ok {
+			if qualifier := reName.FindString(strings.TrimSpace(parent.Title)); qualifier != "" {
+				return lastPart(qualifier) + "." + name
+			}
+		}
+	}
+	if module == "k6" {
+		return name
+	}
+	return lastPart(strings.ReplaceAll(module, "/", ".")) + "." + name
+}
+
+func lastPart(name string) string {
+	return name[strings.LastIndexByte(name, '.')+1:]
+}
+
+// anchor returns the heading ID docs sites give a term.
+func anchor(term string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(term) {
+		switch {
+		case r == ' ' || r == '-':
+			b.WriteByte('-')
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
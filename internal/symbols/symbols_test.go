@@ -0,0 +1,67 @@
+package symbols
+
+import (
+	"testing"
+
+	"github.com/grafana/xk6-docs/docs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuild(t *testing.T) {
+	t.Parallel()
+
+	idx := &docs.Index{Version: "v1.4.x", Sections: []docs.Section{
+		{Slug: "javascript-api", Title: "JavaScript API"},
+		{Slug: "javascript-api/k6", Title: "k6"},
+		{Slug: "javascript-api/k6/check", Title: "check( val, sets, [tags] )"},
+		{Slug: "javascript-api/k6-http", Title: "k6/http"},
+		{Slug: "javascript-api/k6-http/get", Title: "get( url, [params] )"},
+		{Slug: "javascript-api/k6-http/response", Title: "Response"},
+		{Slug: "javascript-api/k6-http/response/response-json", Title: "Response.json( [selector] )"},
+		{Slug: "javascript-api/k6-data/sharedarray", Title: "SharedArray"},
+		{Slug: "javascript-api/k6-browser/browser", Title: "browser"},
+		{Slug: "javascript-api/k6-browser/browser/newpage", Title: "newPage([options])"},
+		{Slug: "javascript-api/k6-browser/page", Title: "Page"},
+		{Slug: "javascript-api/k6-browser/page/goto", Title: "goto(url, [options])"},
+		{Slug: "javascript-api/k6-net-grpc/client/client-invoke", Title: "Client.invoke(url, request)"},
+		{Slug: "javascript-api/k6-ws/connect", Title: "connect( url, params, callback )"},
+		{Slug: "javascript-api/k6-experimental/websockets/connect", Title: "connect( url )"},
+		{Slug: "using-k6/scenarios", Title: "Scenarios"},
+		{Slug: "misc/glossary", Title: "Glossary"},
+	}}
+	glossary := []byte("# Glossary\n\n## Virtual user\n\nA VU.\n\n### Iteration\n\nOne run.\n\n## Requests per second (RPS)\n")
+	x := Build(idx, "misc/glossary", glossary)
+
+	for _, tc := range []struct{ lookup, symbol, module, slug string }{
+		{"check", "check", "k6", "javascript-api/k6/check"},
+		{"http.get", "http.get", "k6/http", "javascript-api/k6-http/get"},
+		{"HTTP.GET()", "http.get", "k6/http", "javascript-api/k6-http/get"},
+		{"response.json", "Response.json", "k6/http", "javascript-api/k6-http/response/response-json"},
+		{"SharedArray", "data.SharedArray", "k6/data", "javascript-api/k6-data/sharedarray"},
+		{"browser.newPage", "browser.newPage", "k6/browser", "javascript-api/k6-browser/browser/newpage"},
+		{"page.goto", "Page.goto", "k6/browser", "javascript-api/k6-browser/page/goto"},
+		{"goto", "Page.goto", "k6/browser", "javascript-api/k6-browser/page/goto"},
+		{"client.invoke", "Client.invoke", "k6/net/grpc", "javascript-api/k6-net-grpc/client/client-invoke"},
+		{"k6/http", "k6/http", "k6/http", "javascript-api/k6-http"},
+	} {
+		got := x.Lookup(tc.lookup)
+		require.Len(t, got, 1, tc.lookup)
+		assert.Equal(t, tc.symbol, got[0].Symbol, tc.lookup)
+		assert.Equal(t, tc.module, got[0].Module, tc.lookup)
+		assert.Equal(t, tc.slug, got[0].Slug, tc.lookup)
+	}
+
+	assert.Equal(t, []Entry{
+		{Symbol: "Virtual user", Slug: "misc/glossary", Title: "Virtual user", Anchor: "virtual-user"},
+	}, x.Lookup("virtual user"))
+	assert.Equal(t, "iteration", x.Lookup("Iteration")[0].Anchor)
+	assert.Equal(t, "requests-per-second-rps", x.Lookup("rps")[0].Anchor)
+	assert.Equal(t, "Requests per second (RPS)", x.Lookup("requests per second")[0].Symbol)
+	// Names in several modules match them all
+	assert.Len(t, x.Lookup("connect"), 2)
+
+	assert.Empty(t, x.Lookup("scenarios"))
+	assert.Empty(t, x.Lookup("http.post"))
+	assert.Contains(t, x.Symbols(), "http.get")
+}
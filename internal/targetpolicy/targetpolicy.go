@@ -0,0 +1,206 @@
+// Package targetpolicy decides which hosts k6 scripts may send load to, so
+// agents cannot point a test at production or third-party systems.
+package targetpolicy
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var (
+	// ErrDenied is returned for a target host the policy rejects.
+	ErrDenied = errors.New("target host not allowed")
+	// ErrUnresolved is returned for a target whose host cannot be read
+	// statically while the policy only allows listed hosts.
+	ErrUnresolved = errors.New("target host cannot be read statically")
+)
+
+// Policy controls the hosts a script may send requests to. Denied hosts
+// are always rejected; when allowed hosts are set, any other host is too.
+// A nil *Policy allows every host.
+type Policy struct {
+	// allow and deny hold "host", "host:port" or "*.domain" patterns.
+	allow []string
+	deny  []string
+}
+
+// New returns a policy allowing only the allow hosts, when any are given,
+// and rejecting the deny hosts. It returns nil when both are empty.
+func New(allow, deny []string) (*Policy, error) {
+	if len(allow) == 0 && len(deny) == 0 {
+		return nil, nil
+	}
+	p := &Policy{}
+	var err error
+	if p.allow, err = patterns(allow); err != nil {
+		return nil, err
+	}
+	if p.deny, err = patterns(deny); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func patterns(hosts []string) ([]string, error) {
+	out := make([]string, 0, len(hosts))
+	for _, h := range hosts {
+		h = strings.ToLower(strings.TrimSpace(h))
+		if h == "" || strings.ContainsAny(h, "/@ ") || strings.Contains(strings.TrimPrefix(h, "*."), "*") {
+			return nil, fmt.Errorf("invalid target host %q: use host, host:port or *.domain", h)
+		}
+		out = append(out, h)
+	}
+	return out, nil
+}
+
+// Restricted reports whether the policy rejects any host.
+func (p *Policy) Restricted() bool {
+	return p != nil && (len(p.allow) > 0 || len(p.deny) > 0)
+}
+
+// String describes the policy for humans.
+func (p *Policy) String() string {
+	if !p.Restricted() {
+		return "any target host is allowed"
+	}
+	var parts []string
+	if len(p.allow) > 0 {
+		parts = append(parts, "targets are limited to "+strings.Join(p.allow, ", "))
+	}
+	if len(p.deny) > 0 {
+		parts = append(parts, "targets "+strings.Join(p.deny, ", ")+" are denied")
+	}
+	return strings.Join(parts, "; ")
+}
+
+// Check returns an error wrapping ErrDenied when the policy rejects host
+// ("name" or "name:port"), or ErrUnresolved for an empty host while only
+// listed hosts are allowed.
+func (p *Policy) Check(host string) error {
+	if !p.Restricted() {
+		return nil
+	}
+	host = strings.ToLower(host)
+	if host == "" {
+		if len(p.allow) > 0 {
+			return fmt.Errorf("%w: use a literal URL or an __ENV variable set by env_file", ErrUnresolved)
+		}
+		return nil
+	}
+	if matchAny(p.deny, host) {
+		return fmt.Errorf("%w: %s is denied by the target policy", ErrDenied, host)
+	}
+	if len(p.allow) > 0 && !matchAny(p.allow, host) {
+		return fmt.Errorf("%w: %s is not in the allowed targets (%s)", ErrDenied, host, strings.Join(p.allow, ", "))
+	}
+	return nil
+}
+
+// CheckDenied returns an error wrapping ErrDenied when host is in the deny
+// list, for hosts the server connects to without sending them load.
+func (p *Policy) CheckDenied(host string) error {
+	if p != nil && matchAny(p.deny, strings.ToLower(host)) {
+		return fmt.Errorf("%w: %s is denied by the target policy", ErrDenied, host)
+	}
+	return nil
+}
+
+// dynamicURLRe matches the literal scheme and host at the start of a URL
+// built at runtime, such as `https://api.example.com/${path}`, and what
+// follows the host.
+//
+//nolint:gochecknoglobals // Compiled once and reused.
+var (
+	dynamicURLRe = regexp.MustCompile("^[`'\"]?((?:https?|wss?)://[^/'\"`$?#\\s]+)(.*)$")
+	envURLRe     = regexp.MustCompile("^`?(?:\\$\\{)?__ENV\\.(\\w+)")
+)
+
+// Host returns the host of a request target as scriptinfo reports it: a
+// literal URL, or the source text of a dynamic one. The host of a dynamic
+// URL is read from its literal prefix, when a path, query or the end of the
+// URL follows it, or from env when the URL starts with an __ENV variable.
+// ok is false when the host cannot be read statically.
+func Host(target string, dynamic bool, env map[string]string) (host string, ok bool) {
+	_, host, ok = Origin(target, dynamic, env)
+	return host, ok
+}
+
+// Origin returns the scheme and host of a request target, read as Host
+// does. The scheme of gRPC targets, which have none, is "grpc".
+func Origin(target string, dynamic bool, env map[string]string) (scheme, host string, ok bool) {
+	if !dynamic {
+		return parseOrigin(target)
+	}
+	if m := dynamicURLRe.FindStringSubmatch(target); m != nil {
+		// The host may go on in an expression, as in https://${tenant}.example.com
+		// or 'https://api.' + domain
+		switch rest := m[2]; {
+		case rest == "", strings.ContainsAny(rest[:1], "/?#"):
+			return parseOrigin(m[1])
+		case len(rest) == 1 && strings.ContainsAny(rest, "`'\""):
+			return parseOrigin(m[1])
+		}
+		return "", "", false
+	}
+	if m := envURLRe.FindStringSubmatch(target); m != nil {
+		if value, ok := env[m[1]]; ok {
+			return parseOrigin(value)
+		}
+	}
+	return "", "", false
+}
+
+// EnvVar returns the __ENV variable a dynamic request target starts with,
+// which Host reads its host from.
+func EnvVar(target string, dynamic bool) (string, bool) {
+	if !dynamic {
+		return "", false
+	}
+	if m := envURLRe.FindStringSubmatch(target); m != nil {
+		return m[1], true
+	}
+	return "", false
+}
+
+func parseOrigin(raw string) (string, string, bool) {
+	if !strings.Contains(raw, "://") {
+		// gRPC targets are host:port
+		raw = "grpc://" + raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return "", "", false
+	}
+	return strings.ToLower(u.Scheme), strings.ToLower(u.Host), true
+}
+
+// matchAny reports whether host ("name" or "name:port") matches one of the
+// patterns. Patterns without a port match any port.
+func matchAny(patterns []string, host string) bool {
+	name, port := host, ""
+	if i := strings.LastIndexByte(host, ':'); i >= 0 && !strings.Contains(host[i:], "]") {
+		name, port = host[:i], host[i:]
+	}
+	// The fully qualified "api.example.com." resolves as "api.example.com"
+	name = strings.TrimSuffix(name, ".")
+	host = name + port
+	for _, pattern := range patterns {
+		target := name
+		if strings.Contains(strings.TrimPrefix(pattern, "*."), ":") {
+			target = host
+		}
+		if suffix, ok := strings.CutPrefix(pattern, "*."); ok {
+			if strings.HasSuffix(target, "."+suffix) {
+				return true
+			}
+			continue
+		}
+		if target == pattern {
+			return true
+		}
+	}
+	return false
+}
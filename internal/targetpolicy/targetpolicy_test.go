@@ -0,0 +1,97 @@
+package targetpolicy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicyCheck(t *testing.T) {
+	t.Parallel()
+
+	open, err := New(nil, nil)
+	require.NoError(t, err)
+	assert.Nil(t, open)
+	require.NoError(t, open.Check("prod.example.com"))
+	assert.Equal(t, "any target host is allowed", open.String())
+
+	deny, err := New(nil, []string{"*.prod.example.com", "Payments.Example.com"})
+	require.NoError(t, err)
+	require.ErrorIs(t, deny.Check("api.prod.example.com"), ErrDenied)
+	require.ErrorIs(t, deny.Check("payments.example.com:443"), ErrDenied)
+	require.NoError(t, deny.Check("api.staging.example.com"))
+	// Fully qualified names, with a trailing dot, are the same hosts
+	host, ok := Host("https://payments.example.com./charge", false, nil)
+	require.True(t, ok)
+	require.ErrorIs(t, deny.Check(host), ErrDenied)
+	require.ErrorIs(t, deny.Check("api.prod.example.com.:8443"), ErrDenied)
+	require.NoError(t, deny.Check(""), "unknown hosts pass a deny list")
+	assert.Equal(t, "targets *.prod.example.com, payments.example.com are denied", deny.String())
+
+	allow, err := New([]string{"*.staging.example.com", "localhost:3000"}, []string{"db.staging.example.com"})
+	require.NoError(t, err)
+	require.NoError(t, allow.Check("api.staging.example.com"))
+	require.NoError(t, allow.Check("localhost:3000"))
+	require.ErrorIs(t, allow.Check("localhost:8080"), ErrDenied)
+	require.ErrorIs(t, allow.Check("db.staging.example.com"), ErrDenied)
+	err = allow.Check("test.k6.io")
+	require.ErrorIs(t, err, ErrDenied)
+	assert.Contains(t, err.Error(), "not in the allowed targets")
+	require.ErrorIs(t, allow.Check(""), ErrUnresolved)
+
+	// Hosts that receive no load are only held to the deny list
+	require.NoError(t, allow.CheckDenied("test.k6.io"))
+	require.ErrorIs(t, allow.CheckDenied("db.staging.example.com"), ErrDenied)
+	require.ErrorIs(t, allow.CheckDenied("DB.staging.example.com."), ErrDenied)
+	require.NoError(t, allow.Check("localhost.:3000"))
+	require.NoError(t, open.CheckDenied("db.staging.example.com"))
+
+	_, err = New([]string{"https://example.com/"}, nil)
+	require.Error(t, err)
+	_, err = New(nil, []string{"*.*.example.com"})
+	require.Error(t, err)
+}
+
+func TestHost(t *testing.T) {
+	t.Parallel()
+
+	env := map[string]string{"BASE_URL": "https://api.staging.example.com"}
+	tests := []struct {
+		target  string
+		dynamic bool
+		host    string
+	}{
+		{"https://test.k6.io/contacts.php", false, "test.k6.io"},
+		{"wss://echo.example.com:8443/ws", false, "echo.example.com:8443"},
+		{"grpc.example.com:443", false, "grpc.example.com:443"},
+		{"`https://api.example.com/users/${id}`", true, "api.example.com"},
+		{"'https://api.example.com' + '/users'", true, ""},
+		{"`https://api.example.com`", true, "api.example.com"},
+		{"`https://${tenant}.example.com/`", true, ""},
+		{"'https://api.' + domain", true, ""},
+		{"`${__ENV.BASE_URL}/users`", true, "api.staging.example.com"},
+		{"__ENV.BASE_URL + '/users'", true, "api.staging.example.com"},
+		{"`${__ENV.OTHER}/users`", true, ""},
+		{"url", true, ""},
+	}
+	for _, tt := range tests {
+		host, ok := Host(tt.target, tt.dynamic, env)
+		assert.Equal(t, tt.host, host, tt.target)
+		assert.Equal(t, tt.host != "", ok, tt.target)
+	}
+}
+
+func TestEnvVar(t *testing.T) {
+	t.Parallel()
+
+	name, ok := EnvVar("`${__ENV.BASE_URL}/users`", true)
+	assert.True(t, ok)
+	assert.Equal(t, "BASE_URL", name)
+	name, _ = EnvVar("__ENV.HOST + '/users'", true)
+	assert.Equal(t, "HOST", name)
+	_, ok = EnvVar("`https://api.example.com/${__ENV.PATH}`", true)
+	assert.False(t, ok)
+	_, ok = EnvVar("__ENV.BASE_URL", false)
+	assert.False(t, ok, "literal targets read no variable")
+}
@@ -0,0 +1,290 @@
+// Package telemetry counts the server's own usage, tool calls and k6 runs,
+// and pushes the counters to a Grafana Cloud Prometheus endpoint in the
+// Influx line protocol it accepts.
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Environment variables configuring the push.
+const (
+	// EnvURL is the Influx push URL of the Prometheus endpoint, such as
+	// https://prometheus-prod-01-eu-west-0.grafana.net/api/v1/push/influx/write.
+	EnvURL = "MCP_K6_TELEMETRY_URL"
+	// EnvUser is the basic auth user, the instance ID of the endpoint.
+	EnvUser = "MCP_K6_TELEMETRY_USER"
+	// EnvToken is the basic auth password, an access policy token with the
+	// metrics:write scope.
+	EnvToken = "MCP_K6_TELEMETRY_TOKEN"
+	// EnvInterval is how often to push, such as "30s" (default: 1m).
+	EnvInterval = "MCP_K6_TELEMETRY_INTERVAL"
+	// EnvInstance labels the server's metrics (default: the host name).
+	EnvInstance = "MCP_K6_TELEMETRY_INSTANCE"
+)
+
+const (
+	// DefaultInterval is how often counters are pushed by default.
+	DefaultInterval = time.Minute
+	// minInterval bounds how often counters can be pushed.
+	minInterval = 10 * time.Second
+	// timeout bounds each push request.
+	timeout = 10 * time.Second
+)
+
+// ErrInvalid is returned for telemetry variables that cannot be used.
+var ErrInvalid = errors.New("invalid telemetry environment")
+
+type contextKey struct{}
+
+// key identifies a counter series.
+type key struct {
+	measurement string
+	label       string
+	value       string
+	outcome     string
+}
+
+// counter is a cumulative count and duration sum.
+type counter struct {
+	count   int64
+	seconds float64
+}
+
+// Recorder counts tool calls and runs and pushes the counters. A nil
+// *Recorder records nothing.
+type Recorder struct {
+	url      *url.URL
+	user     string
+	token    string
+	interval time.Duration
+	instance string
+	version  string
+	client   *http.Client
+
+	mu       sync.Mutex
+	counters map[key]*counter
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// New returns a recorder configured from the MCP_K6_TELEMETRY_* variables
+// of environ, or nil when no push URL is set. version labels the metrics.
+func New(environ []string, version string) (*Recorder, error) {
+	env := make(map[string]string)
+	for _, kv := range environ {
+		if k, v, ok := strings.Cut(kv, "="); ok && strings.HasPrefix(k, "MCP_K6_TELEMETRY_") {
+			env[k] = v
+		}
+	}
+	if env[EnvURL] == "" {
+		return nil, nil
+	}
+	u, err := url.Parse(env[EnvURL])
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("%w: %s must be an absolute http or https URL", ErrInvalid, EnvURL)
+	}
+
+	r := &Recorder{
+		url:      u,
+		user:     env[EnvUser],
+		token:    env[EnvToken],
+		interval: DefaultInterval,
+		instance: env[EnvInstance],
+		version:  version,
+		client:   &http.Client{Timeout: timeout},
+		counters: make(map[key]*counter),
+	}
+	if (r.user == "") != (r.token == "") {
+		return nil, fmt.Errorf("%w: set both %s and %s, or neither", ErrInvalid, EnvUser, EnvToken)
+	}
+	if v := env[EnvInterval]; v != "" {
+		if r.interval, err = time.ParseDuration(v); err != nil || r.interval < minInterval {
+			return nil, fmt.Errorf("%w: %s must be a duration of at least %v, got %q",
+				ErrInvalid, EnvInterval, minInterval, v)
+		}
+	}
+	if r.instance == "" {
+		//nolint:forbidigo // The host name labels the server's own metrics.
+		if r.instance, err = os.Hostname(); err != nil {
+			r.instance = "unknown"
+		}
+	}
+	return r, nil
+}
+
+// Host returns the host counters are pushed to, for logging without the
+// credentials.
+func (r *Recorder) Host() string {
+	if r == nil {
+		return ""
+	}
+	return r.url.Host
+}
+
+// ContextWithRecorder returns a copy of ctx carrying r.
+func ContextWithRecorder(ctx context.Context, r *Recorder) context.Context {
+	return context.WithValue(ctx, contextKey{}, r)
+}
+
+// FromContext returns the recorder of ctx, or nil.
+func FromContext(ctx context.Context) *Recorder {
+	r, _ := ctx.Value(contextKey{}).(*Recorder)
+	return r
+}
+
+// ToolCall counts a call of tool that took d. Failed calls returned an
+// error or an error result.
+func (r *Recorder) ToolCall(tool string, failed bool, d time.Duration) {
+	r.add(key{measurement: "mcp_k6_tool", label: "tool", value: tool, outcome: outcome(failed)}, d)
+}
+
+// Run counts a k6 run that took d, by outcome: "passed", "thresholds_failed"
+// or "error".
+func (r *Recorder) Run(result string, d time.Duration) {
+	r.add(key{measurement: "mcp_k6_run", outcome: result}, d)
+}
+
+func (r *Recorder) add(k key, d time.Duration) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	c, ok := r.counters[k]
+	if !ok {
+		c = &counter{}
+		r.counters[k] = c
+	}
+	c.count++
+	c.seconds += d.Seconds()
+}
+
+func outcome(failed bool) string {
+	if failed {
+		return "error"
+	}
+	return "ok"
+}
+
+// Start pushes the counters every interval until Close, logging failed
+// pushes to logger.
+func (r *Recorder) Start(logger *slog.Logger) {
+	if r == nil {
+		return
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+	r.stop, r.done = make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(r.done)
+		ticker := time.NewTicker(r.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-r.stop:
+				return
+			case <-ticker.C:
+				if err := r.Push(context.Background()); err != nil {
+					logger.Warn("Telemetry push failed", slog.String("error", err.Error()))
+				}
+			}
+		}
+	}()
+}
+
+// Close stops the pushes started with Start and pushes the counters a last
+// time.
+func (r *Recorder) Close(ctx context.Context) error {
+	if r == nil {
+		return nil
+	}
+	if r.stop != nil {
+		close(r.stop)
+		<-r.done
+	}
+	return r.Push(ctx)
+}
+
+// Push sends the current counters. Counters are cumulative, so a failed
+// push loses nothing but resolution.
+func (r *Recorder) Push(ctx context.Context) error {
+	if r == nil {
+		return nil
+	}
+	body := r.lines(time.Now())
+	if len(body) == 0 {
+		return nil
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.url.String(), bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("telemetry %s: %w", r.url.Host, err)
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	if r.user != "" {
+		req.SetBasicAuth(r.user, r.token)
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		var uerr *url.Error
+		if errors.As(err, &uerr) {
+			err = uerr.Err
+		}
+		return fmt.Errorf("telemetry %s: %w", r.url.Host, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("telemetry %s: %s", r.url.Host, resp.Status)
+	}
+	return nil
+}
+
+// lines renders the counters in the Influx line protocol, one line per
+// series, sorted. Grafana Cloud names the metrics after the measurement and
+// field, such as mcp_k6_tool_calls_total.
+func (r *Recorder) lines(now time.Time) []byte {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	lines := make([]string, 0, len(r.counters))
+	for k, c := range r.counters {
+		tags := "instance=" + escape(r.instance) + ",outcome=" + escape(k.outcome)
+		if k.label != "" {
+			tags += "," + k.label + "=" + escape(k.value)
+		}
+		tags += ",version=" + escape(r.version)
+		field := "calls"
+		if k.measurement == "mcp_k6_run" {
+			field = "runs"
+		}
+		lines = append(lines, fmt.Sprintf("%s,%s %s_total=%d,duration_seconds_sum=%g %d",
+			k.measurement, tags, field, c.count, c.seconds, now.UnixNano()))
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+	sort.Strings(lines)
+	return []byte(strings.Join(lines, "\n") + "\n")
+}
+
+// escape escapes a tag value for the line protocol.
+func escape(s string) string {
+	if s == "" {
+		return "unknown"
+	}
+	return strings.NewReplacer(",", `\,`, "=", `\=`, " ", `\ `).Replace(s)
+}
@@ -0,0 +1,113 @@
+package telemetry
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	r, err := New([]string{"PATH=/bin"}, "v1.0.0")
+	require.NoError(t, err)
+	assert.Nil(t, r)
+
+	tests := map[string]struct {
+		env []string
+		err string
+	}{
+		"url":      {[]string{EnvURL + "=grafana.net/push"}, "absolute http or https URL"},
+		"token":    {[]string{EnvURL + "=https://grafana.net/push", EnvUser + "=123"}, "set both"},
+		"interval": {[]string{EnvURL + "=https://grafana.net/push", EnvInterval + "=1s"}, "at least 10s"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			_, err := New(tt.env, "v1.0.0")
+			require.ErrorIs(t, err, ErrInvalid)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+
+	r, err = New([]string{EnvURL + "=https://grafana.net/push", EnvInstance + "=ci"}, "v1.0.0")
+	require.NoError(t, err)
+	assert.Equal(t, "grafana.net", r.Host())
+	assert.Equal(t, DefaultInterval, r.interval)
+}
+
+func TestPush(t *testing.T) {
+	t.Parallel()
+
+	bodies := make(chan string, 2)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		user, token, ok := req.BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, "123456", user)
+		assert.Equal(t, "glc_token", token)
+		body, _ := io.ReadAll(req.Body)
+		bodies <- string(body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(srv.Close)
+
+	r, err := New([]string{
+		EnvURL + "=" + srv.URL, EnvUser + "=123456", EnvToken + "=glc_token", EnvInstance + "=mcp 1",
+	}, "v1.2.0")
+	require.NoError(t, err)
+
+	// Nothing recorded, nothing pushed
+	require.NoError(t, r.Push(context.Background()))
+
+	r.ToolCall("run_script", false, 2*time.Second)
+	r.ToolCall("run_script", false, time.Second)
+	r.ToolCall("validate_script", true, 500*time.Millisecond)
+	r.Run("passed", 30*time.Second)
+	r.Start(nil)
+	require.NoError(t, r.Close(context.Background()))
+
+	lines := strings.Split(strings.TrimSuffix(<-bodies, "\n"), "\n")
+	require.Len(t, lines, 3)
+	tags := `instance=mcp\\ 1,outcome=`
+	assert.Regexp(t, `^mcp_k6_run,`+tags+`passed,version=v1.2.0 runs_total=1,duration_seconds_sum=30 \d+$`, lines[0])
+	assert.Regexp(t, `^mcp_k6_tool,`+tags+`error,tool=validate_script,version=v1.2.0 `+
+		`calls_total=1,duration_seconds_sum=0.5 \d+$`, lines[1])
+	assert.Regexp(t, `^mcp_k6_tool,`+tags+`ok,tool=run_script,version=v1.2.0 `+
+		`calls_total=2,duration_seconds_sum=3 \d+$`, lines[2])
+}
+
+func TestPushFailure(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	t.Cleanup(srv.Close)
+
+	r, err := New([]string{EnvURL + "=" + srv.URL + "/push?token=secret"}, "dev")
+	require.NoError(t, err)
+	r.Run("error", time.Second)
+	err = r.Push(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "401")
+	assert.NotContains(t, err.Error(), "secret")
+}
+
+func TestNilRecorder(t *testing.T) {
+	t.Parallel()
+
+	var r *Recorder
+	r.ToolCall("run_script", false, time.Second)
+	r.Run("passed", time.Second)
+	r.Start(nil)
+	require.NoError(t, r.Close(context.Background()))
+	assert.Nil(t, FromContext(context.Background()))
+	assert.Equal(t, r, FromContext(ContextWithRecorder(context.Background(), r)))
+}
@@ -0,0 +1,201 @@
+// Package truncate keeps tool responses within a size budget, so a large
+// payload such as a documentation page or k6 output cannot fill the context
+// of a small model.
+package truncate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// MinLimit is the smallest response limit, leaving room for the shape
+	// of a result around its shortened values.
+	MinLimit = 1024
+	// minKeep is the length strings are never shortened below.
+	minKeep = 128
+	// maxPasses bounds the strings shortened to fit a value in its limit.
+	maxPasses = 64
+)
+
+// ErrInvalid is returned for response limits that cannot be used.
+var ErrInvalid = errors.New("invalid response limit")
+
+// Limits holds the maximum size of the responses of each tool, in bytes. A
+// nil *Limits sets none.
+type Limits struct {
+	def   int
+	tools map[string]int
+}
+
+// New returns limits of def bytes per response, overridden for the tools of
+// the perTool entries, "tool=bytes". 0 means no limit. It returns nil when no
+// limit is set.
+func New(def int, perTool []string) (*Limits, error) {
+	if def == 0 && len(perTool) == 0 {
+		return nil, nil
+	}
+	if err := check(def); err != nil {
+		return nil, err
+	}
+	l := &Limits{def: def, tools: make(map[string]int, len(perTool))}
+	for _, entry := range perTool {
+		tool, value, ok := strings.Cut(entry, "=")
+		tool = strings.TrimSpace(tool)
+		n, err := strconv.Atoi(strings.TrimSpace(value))
+		if !ok || tool == "" || err != nil {
+			return nil, fmt.Errorf("%w: %q is not tool=bytes", ErrInvalid, entry)
+		}
+		if err := check(n); err != nil {
+			return nil, err
+		}
+		l.tools[tool] = n
+	}
+	return l, nil
+}
+
+func check(n int) error {
+	if n != 0 && n < MinLimit {
+		return fmt.Errorf("%w: %d bytes is below the minimum of %d (0 disables the limit)", ErrInvalid, n, MinLimit)
+	}
+	return nil
+}
+
+// For returns the response limit of tool, 0 when it has none.
+func (l *Limits) For(tool string) int {
+	if l == nil {
+		return 0
+	}
+	if n, ok := l.tools[tool]; ok {
+		return n
+	}
+	return l.def
+}
+
+// Cut is a string value shortened by Value.
+type Cut struct {
+	// Path locates the value, such as "result.stdout" or "tree[2].title".
+	Path string
+	// Kept is the number of bytes of the original value kept.
+	Kept int
+	// Total is the length of the original value.
+	Total int
+}
+
+// Value shortens the longest strings of v, a value decoded from JSON, until
+// its indented encoding fits in limit bytes, keeping its shape. It returns
+// the strings it shortened, longest first, and whether v fits: values made
+// of many short strings or numbers cannot be shortened enough.
+func Value(v any, limit int) (any, []Cut, bool) {
+	cuts := make(map[string]*Cut)
+	for range maxPasses {
+		size := encodedSize(v)
+		if size <= limit {
+			break
+		}
+		leaf := longest(v, cuts)
+		// A bare string is left for Text to cut
+		if leaf == nil || leaf.set == nil || len(leaf.value) <= minKeep {
+			break
+		}
+		path := leaf.path
+		cut, ok := cuts[path]
+		if !ok {
+			cut = &Cut{Path: path, Total: len(leaf.value)}
+			cuts[path] = cut
+		} else {
+			// Drop the marker of the previous pass
+			leaf.value = leaf.value[:cut.Kept]
+		}
+		// JSON escaping makes strings longer encoded than in memory, so the
+		// next pass measures again
+		keep := max(minKeep, len(leaf.value)-(size-limit)-len(marker(cut.Total)))
+		keep = runeStart(leaf.value, min(keep, len(leaf.value)))
+		cut.Kept = keep
+		leaf.set(leaf.value[:keep] + marker(cut.Total-keep))
+	}
+
+	out := make([]Cut, 0, len(cuts))
+	for _, c := range cuts {
+		out = append(out, *c)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Total > out[j].Total })
+	return v, out, encodedSize(v) <= limit
+}
+
+// Text cuts s to at most limit bytes, at the last line break of its final
+// tenth when there is one, and otherwise at a character boundary.
+func Text(s string, limit int) string {
+	if len(s) <= limit {
+		return s
+	}
+	cut := runeStart(s, limit)
+	if i := strings.LastIndexByte(s[:cut], '\n'); i >= 0 && i >= cut-cut/10 {
+		cut = i
+	}
+	return s[:cut]
+}
+
+func marker(n int) string {
+	return fmt.Sprintf("… [%d bytes truncated]", n)
+}
+
+func encodedSize(v any) int {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return 0
+	}
+	return len(data)
+}
+
+// runeStart returns the largest index up to i that starts a character of s.
+func runeStart(s string, i int) int {
+	for i > 0 && i < len(s) && !utf8.RuneStart(s[i]) {
+		i--
+	}
+	return i
+}
+
+// leaf is a string value inside a decoded JSON value.
+type leaf struct {
+	path  string
+	value string
+	set   func(string)
+}
+
+// longest returns the longest string in v that can still be shortened, nil
+// when there is none.
+func longest(v any, cuts map[string]*Cut) *leaf {
+	var best *leaf
+	var walk func(path string, v any, set func(string))
+	walk = func(path string, v any, set func(string)) {
+		switch v := v.(type) {
+		case string:
+			if cut, ok := cuts[path]; ok && cut.Kept <= minKeep {
+				return
+			}
+			if best == nil || len(v) > len(best.value) {
+				best = &leaf{path: path, value: v, set: set}
+			}
+		case map[string]any:
+			for k, child := range v {
+				childPath := k
+				if path != "" {
+					childPath = path + "." + k
+				}
+				walk(childPath, child, func(s string) { v[k] = s })
+			}
+		case []any:
+			for i, child := range v {
+				walk(fmt.Sprintf("%s[%d]", path, i), child, func(s string) { v[i] = s })
+			}
+		}
+	}
+	walk("", v, nil)
+	return best
+}
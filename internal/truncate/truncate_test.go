@@ -0,0 +1,78 @@
+package truncate
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	l, err := New(0, nil)
+	require.NoError(t, err)
+	assert.Nil(t, l)
+	assert.Equal(t, 0, l.For("run_script"))
+
+	l, err = New(4096, []string{"get_documentation=20000", " list_sections = 0 "})
+	require.NoError(t, err)
+	assert.Equal(t, 4096, l.For("run_script"))
+	assert.Equal(t, 20000, l.For("get_documentation"))
+	assert.Equal(t, 0, l.For("list_sections"))
+
+	for _, entry := range []string{"get_documentation", "=2048", "get_documentation=big", "get_documentation=100"} {
+		_, err = New(0, []string{entry})
+		require.ErrorIs(t, err, ErrInvalid, entry)
+	}
+	_, err = New(10, nil)
+	require.ErrorIs(t, err, ErrInvalid)
+}
+
+func TestValue(t *testing.T) {
+	t.Parallel()
+
+	var v any
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"section": {"slug": "using-k6/scenarios", "title": "Scenarios"},
+		"content": "`+strings.Repeat("Scenarios répartissent la charge.\\n", 500)+`",
+		"versions": ["v1.4.x", "v1.3.x"]
+	}`), &v))
+
+	out, cuts, fits := Value(v, 2048)
+	require.True(t, fits)
+	data, err := json.MarshalIndent(out, "", "  ")
+	require.NoError(t, err)
+	assert.LessOrEqual(t, len(data), 2048)
+
+	require.Len(t, cuts, 1)
+	assert.Equal(t, "content", cuts[0].Path)
+	assert.Equal(t, 35*500, cuts[0].Total)
+	doc := out.(map[string]any)
+	content := doc["content"].(string)
+	assert.True(t, strings.HasPrefix(content, "Scenarios répartissent"))
+	assert.Contains(t, content, "bytes truncated]")
+	assert.Equal(t, "Scenarios", doc["section"].(map[string]any)["title"])
+	assert.Len(t, doc["versions"], 2)
+
+	// Many short values cannot be shortened enough
+	items := make([]any, 500)
+	for i := range items {
+		items[i] = float64(i)
+	}
+	_, cuts, fits = Value(map[string]any{"items": items}, MinLimit)
+	assert.False(t, fits)
+	assert.Empty(t, cuts)
+}
+
+func TestText(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "short", Text("short", 10))
+	long := strings.Repeat("x", 95) + "\n" + strings.Repeat("y", 10)
+	assert.Equal(t, strings.Repeat("x", 95), Text(long, 100), "cut at a line break near the limit")
+	assert.Equal(t, "line one\nlin", Text("line one\nline two", 12))
+	assert.Equal(t, "ab", Text("abé", 3), "characters are not split")
+}
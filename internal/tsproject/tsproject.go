@@ -0,0 +1,300 @@
+// Package tsproject generates the files of a TypeScript workspace for k6
+// tests: package.json with @types/k6, tsconfig.json, a sample test and the
+// build setup of the chosen bundler.
+package tsproject
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Bundlers, as accepted by Generate.
+const (
+	// BundlerEsbuild bundles each test of src into dist with esbuild.
+	BundlerEsbuild = "esbuild"
+	// BundlerWebpack bundles with webpack and ts-loader, like the k6
+	// TypeScript template.
+	BundlerWebpack = "webpack"
+	// BundlerNone runs the tests with the TypeScript support of k6 itself,
+	// keeping tsc for type checking only.
+	BundlerNone = "none"
+)
+
+// Bundlers lists the bundlers Generate supports, the default first.
+//
+//nolint:gochecknoglobals // Read-only lookup table.
+var Bundlers = []string{BundlerEsbuild, BundlerWebpack, BundlerNone}
+
+// Versions of the development dependencies of generated projects.
+const (
+	DefaultTypesVersion = "^1.0.0"
+	typescriptVersion   = "^5.6.0"
+	esbuildVersion      = "^0.24.0"
+	webpackVersion      = "^5.95.0"
+	webpackCLIVersion   = "^5.1.4"
+	tsLoaderVersion     = "^9.5.1"
+)
+
+// DefaultURL is the system under test of the sample test.
+const DefaultURL = "https://test.k6.io"
+
+//nolint:gochecknoglobals // Compiled once, read-only.
+var (
+	// reName matches the npm package names Generate accepts.
+	reName = regexp.MustCompile(`^(?:@[a-z0-9][a-z0-9._~-]*/)?[a-z0-9][a-z0-9._~-]*$`)
+	// reVersion matches the npm version ranges accepted for @types/k6.
+	reVersion = regexp.MustCompile(`^[\^~]?\d+(?:\.(?:\d+|x))*$|^latest$`)
+)
+
+// Options configures a generated project.
+type Options struct {
+	// Name is the package name (default "k6-tests").
+	Name string
+	// Bundler is one of Bundlers (default esbuild).
+	Bundler string
+	// TypesVersion is the @types/k6 version range (default DefaultTypesVersion).
+	TypesVersion string
+	// URL is the system under test of the sample test (default DefaultURL).
+	URL string
+}
+
+// File is a file of a generated project.
+type File struct {
+	// Path is relative to the project directory, with forward slashes.
+	Path    string `json:"path"`
+	Content string `json:"content"`
+}
+
+// Project is a generated project.
+type Project struct {
+	Files []File `json:"files"`
+	// Build bundles the tests, empty when k6 runs them directly.
+	Build string `json:"build,omitempty"`
+	// Run runs the sample test.
+	Run string `json:"run"`
+}
+
+// Generate returns the files of a project configured by opts.
+func Generate(opts Options) (*Project, error) {
+	opts, err := withDefaults(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Project{Run: "npm test"}
+	pkg, err := packageJSON(opts)
+	if err != nil {
+		return nil, err
+	}
+	p.Files = append(p.Files,
+		File{Path: "package.json", Content: pkg},
+		File{Path: "tsconfig.json", Content: tsconfig(opts.Bundler)},
+		File{Path: "src/test.ts", Content: sampleTest(opts.URL)},
+	)
+	if opts.Bundler == BundlerWebpack {
+		p.Files = append(p.Files, File{Path: "webpack.config.js", Content: webpackConfig})
+	}
+	p.Files = append(p.Files, File{Path: ".gitignore", Content: "node_modules/\ndist/\n"})
+	if opts.Bundler != BundlerNone {
+		p.Build = "npm run build"
+	}
+	return p, nil
+}
+
+// testPath returns the path, relative to the project, of the script k6 runs
+// for the test src/<name>.ts.
+func testPath(bundler, name string) string {
+	if bundler == BundlerNone {
+		return path.Join("src", name+".ts")
+	}
+	return path.Join("dist", name+".js")
+}
+
+func withDefaults(opts Options) (Options, error) {
+	if opts.Name == "" {
+		opts.Name = "k6-tests"
+	}
+	if !reName.MatchString(opts.Name) || len(opts.Name) > 214 {
+		return opts, fmt.Errorf("invalid package name %q: use lowercase letters, digits, '-', '.' or '_'", opts.Name)
+	}
+	if opts.Bundler == "" {
+		opts.Bundler = BundlerEsbuild
+	}
+	if !slices.Contains(Bundlers, opts.Bundler) {
+		return opts, fmt.Errorf("unknown bundler %q, expected one of: %s", opts.Bundler, strings.Join(Bundlers, ", "))
+	}
+	if opts.TypesVersion == "" {
+		opts.TypesVersion = DefaultTypesVersion
+	}
+	if !reVersion.MatchString(opts.TypesVersion) {
+		return opts, fmt.Errorf("invalid @types/k6 version %q, expected a version such as '^1.0.0'", opts.TypesVersion)
+	}
+	if opts.URL == "" {
+		opts.URL = DefaultURL
+	}
+	if !strings.HasPrefix(opts.URL, "http://") && !strings.HasPrefix(opts.URL, "https://") {
+		return opts, fmt.Errorf("invalid URL %q, expected an http or https URL", opts.URL)
+	}
+	return opts, nil
+}
+
+// packageJSONFile keeps the usual key order of package.json files, which a
+// map would sort.
+type packageJSONFile struct {
+	Name            string            `json:"name"`
+	Version         string            `json:"version"`
+	Private         bool              `json:"private"`
+	Type            string            `json:"type,omitempty"`
+	Scripts         map[string]string `json:"scripts"`
+	DevDependencies map[string]string `json:"devDependencies"`
+}
+
+func packageJSON(opts Options) (string, error) {
+	pkg := packageJSONFile{
+		Name:    opts.Name,
+		Version: "1.0.0",
+		Private: true,
+		DevDependencies: map[string]string{
+			"@types/k6":  opts.TypesVersion,
+			"typescript": typescriptVersion,
+		},
+	}
+	switch opts.Bundler {
+	case BundlerEsbuild:
+		pkg.Type = "module"
+		pkg.Scripts = map[string]string{
+			"build": "esbuild src/*.ts --bundle --outdir=dist --format=esm --target=es2020" +
+				" --external:k6 '--external:k6/*' '--external:https://*'",
+			"typecheck": "tsc",
+			"test":      "npm run build && k6 run " + testPath(opts.Bundler, "test"),
+		}
+		pkg.DevDependencies["esbuild"] = esbuildVersion
+	case BundlerWebpack:
+		pkg.Scripts = map[string]string{
+			"build":     "webpack",
+			"typecheck": "tsc --noEmit",
+			"test":      "npm run build && k6 run " + testPath(opts.Bundler, "test"),
+		}
+		pkg.DevDependencies["webpack"] = webpackVersion
+		pkg.DevDependencies["webpack-cli"] = webpackCLIVersion
+		pkg.DevDependencies["ts-loader"] = tsLoaderVersion
+	case BundlerNone:
+		pkg.Type = "module"
+		pkg.Scripts = map[string]string{
+			"typecheck": "tsc",
+			"test":      "k6 run " + testPath(opts.Bundler, "test"),
+		}
+	}
+
+	data, err := marshal(pkg, "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal package.json: %w", err)
+	}
+	return data, nil
+}
+
+// marshal encodes v as JSON without escaping "&", "<" and ">", which scripts
+// and URLs hold.
+func marshal(v any, indent string) (string, error) {
+	var b strings.Builder
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", indent)
+	if err := enc.Encode(v); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// tsconfig targets the ECMAScript version k6 runs, without the DOM library,
+// so browser globals that k6 lacks do not type check. ts-loader needs tsc to
+// emit; otherwise tsc only checks types.
+func tsconfig(bundler string) string {
+	noEmit := `
+    "noEmit": true,`
+	if bundler == BundlerWebpack {
+		noEmit = ""
+	}
+	return `{
+  "compilerOptions": {
+    "target": "ES2020",
+    "module": "ES2020",
+    "moduleResolution": "bundler",
+    "lib": ["ES2020"],
+    "types": ["k6"],
+    "strict": true,` + noEmit + `
+    "esModuleInterop": true,
+    "skipLibCheck": true,
+    "forceConsistentCasingInFileNames": true
+  },
+  "include": ["src"]
+}
+`
+}
+
+func sampleTest(url string) string {
+	// A JSON string is a valid TypeScript string literal
+	literal, _ := marshal(url, "")
+	return `import { check, sleep } from 'k6';
+import http from 'k6/http';
+import { Options } from 'k6/options';
+
+export const options: Options = {
+  vus: 1,
+  duration: '10s',
+  thresholds: {
+    http_req_failed: ['rate<0.01'],
+    http_req_duration: ['p(95)<500'],
+  },
+};
+
+export default function (): void {
+  const res = http.get(` + strings.TrimSpace(literal) + `);
+  check(res, {
+    'status is 200': (r) => r.status === 200,
+  });
+  sleep(1);
+}
+`
+}
+
+// webpackConfig bundles every test of src into dist as CommonJS, the format
+// of the k6 TypeScript template, leaving k6 modules and remote modules to k6.
+// Minification is off so stack traces point at readable code.
+const webpackConfig = `const fs = require('fs');
+const path = require('path');
+
+const entries = Object.fromEntries(
+  fs
+    .readdirSync(path.join(__dirname, 'src'))
+    .filter((file) => file.endsWith('.ts'))
+    .map((file) => [path.basename(file, '.ts'), path.join(__dirname, 'src', file)]),
+);
+
+module.exports = {
+  mode: 'production',
+  entry: entries,
+  output: {
+    path: path.join(__dirname, 'dist'),
+    libraryTarget: 'commonjs',
+    filename: '[name].js',
+    clean: true,
+  },
+  resolve: {
+    extensions: ['.ts', '.js'],
+  },
+  module: {
+    rules: [{ test: /\.ts$/, use: 'ts-loader', exclude: /node_modules/ }],
+  },
+  target: 'web',
+  externals: /^(k6|https?:\/\/)(\/.*)?/,
+  devtool: 'source-map',
+  optimization: {
+    minimize: false,
+  },
+};
+`
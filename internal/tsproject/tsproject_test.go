@@ -0,0 +1,87 @@
+package tsproject
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func files(p *Project) map[string]string {
+	out := make(map[string]string, len(p.Files))
+	for _, f := range p.Files {
+		out[f.Path] = f.Content
+	}
+	return out
+}
+
+func TestGenerateEsbuild(t *testing.T) {
+	t.Parallel()
+
+	p, err := Generate(Options{URL: "https://example.com/?a=1&b=2"})
+	require.NoError(t, err)
+	assert.Equal(t, "npm run build", p.Build)
+	assert.Equal(t, "npm test", p.Run)
+
+	fs := files(p)
+	assert.NotContains(t, fs, "webpack.config.js")
+	assert.Contains(t, fs["src/test.ts"], `http.get("https://example.com/?a=1&b=2");`)
+	assert.Contains(t, fs["tsconfig.json"], `"noEmit": true`)
+
+	var pkg struct {
+		Name            string            `json:"name"`
+		Type            string            `json:"type"`
+		Scripts         map[string]string `json:"scripts"`
+		DevDependencies map[string]string `json:"devDependencies"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(fs["package.json"]), &pkg))
+	assert.Equal(t, "k6-tests", pkg.Name)
+	assert.Equal(t, "module", pkg.Type)
+	assert.Equal(t, "npm run build && k6 run dist/test.js", pkg.Scripts["test"])
+	assert.Contains(t, pkg.Scripts["build"], "--external:k6")
+	assert.Equal(t, DefaultTypesVersion, pkg.DevDependencies["@types/k6"])
+	assert.Contains(t, pkg.DevDependencies, "esbuild")
+
+	var tsconfig map[string]any
+	require.NoError(t, json.Unmarshal([]byte(fs["tsconfig.json"]), &tsconfig))
+}
+
+func TestGenerateWebpack(t *testing.T) {
+	t.Parallel()
+
+	p, err := Generate(Options{Name: "@acme/load-tests", Bundler: BundlerWebpack, TypesVersion: "~0.54.0"})
+	require.NoError(t, err)
+
+	fs := files(p)
+	assert.Contains(t, fs["webpack.config.js"], "libraryTarget: 'commonjs'")
+	assert.NotContains(t, fs["tsconfig.json"], "noEmit")
+	assert.Contains(t, fs["package.json"], `"name": "@acme/load-tests"`)
+	assert.Contains(t, fs["package.json"], `"@types/k6": "~0.54.0"`)
+	assert.Contains(t, fs["package.json"], `"ts-loader"`)
+	assert.NotContains(t, fs["package.json"], `"type"`)
+}
+
+func TestGenerateNone(t *testing.T) {
+	t.Parallel()
+
+	p, err := Generate(Options{Bundler: BundlerNone})
+	require.NoError(t, err)
+	assert.Empty(t, p.Build)
+	assert.Contains(t, files(p)["package.json"], `"test": "k6 run src/test.ts"`)
+	assert.NotContains(t, files(p)["package.json"], "esbuild")
+}
+
+func TestGenerateRejectsInvalidOptions(t *testing.T) {
+	t.Parallel()
+
+	for _, opts := range []Options{
+		{Name: "My Tests"},
+		{Bundler: "rollup"},
+		{TypesVersion: "1.0.0; rm -rf /"},
+		{URL: "test.k6.io"},
+	} {
+		_, err := Generate(opts)
+		assert.Error(t, err, "%+v", opts)
+	}
+}
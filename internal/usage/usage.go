@@ -0,0 +1,204 @@
+// Package usage keeps the server's tool call statistics since startup, by
+// tool and by MCP session, in memory for the server_stats tool. Unlike
+// telemetry, it is always on and never leaves the server.
+package usage
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MaxSessions bounds the sessions kept: once reached, the session seen
+// least recently is forgotten for a new one.
+const MaxSessions = 100
+
+// counter counts the calls of a tool.
+type counter struct {
+	calls  int64
+	errors int64
+	total  time.Duration
+	max    time.Duration
+}
+
+func (c *counter) add(failed bool, d time.Duration) {
+	c.calls++
+	if failed {
+		c.errors++
+	}
+	c.total += d
+	c.max = max(c.max, d)
+}
+
+// session counts the calls of a session.
+type session struct {
+	first, last time.Time
+	tools       map[string]*counter
+}
+
+// Stats counts tool calls. A nil *Stats counts nothing.
+type Stats struct {
+	now   func() time.Time
+	since time.Time
+
+	mu       sync.Mutex
+	tools    map[string]*counter
+	sessions map[string]*session
+}
+
+// New returns empty statistics started now.
+func New() *Stats {
+	return newStats(time.Now)
+}
+
+func newStats(now func() time.Time) *Stats {
+	return &Stats{
+		now:      now,
+		since:    now(),
+		tools:    make(map[string]*counter),
+		sessions: make(map[string]*session),
+	}
+}
+
+// Record counts a call of tool by session that took d. Failed calls
+// returned an error or an error result. Calls without a session, such as
+// those of the stdio transport before initialization, count for the tools
+// only.
+func (s *Stats) Record(sessionID, tool string, failed bool, d time.Duration) {
+	if s == nil {
+		return
+	}
+	now := s.now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	counterOf(s.tools, tool).add(failed, d)
+	if sessionID == "" {
+		return
+	}
+	ss, ok := s.sessions[sessionID]
+	if !ok {
+		if len(s.sessions) >= MaxSessions {
+			s.forgetOldest()
+		}
+		ss = &session{first: now, tools: make(map[string]*counter)}
+		s.sessions[sessionID] = ss
+	}
+	ss.last = now
+	counterOf(ss.tools, tool).add(failed, d)
+}
+
+func counterOf(counters map[string]*counter, tool string) *counter {
+	c, ok := counters[tool]
+	if !ok {
+		c = &counter{}
+		counters[tool] = c
+	}
+	return c
+}
+
+// forgetOldest drops the session seen least recently.
+func (s *Stats) forgetOldest() {
+	var oldest string
+	for id, ss := range s.sessions {
+		if oldest == "" || ss.last.Before(s.sessions[oldest].last) {
+			oldest = id
+		}
+	}
+	delete(s.sessions, oldest)
+}
+
+// Tool is the statistics of a tool.
+type Tool struct {
+	Name   string
+	Calls  int64
+	Errors int64
+	// Total and Max are the summed and longest durations of the calls.
+	Total time.Duration
+	Max   time.Duration
+}
+
+// Average returns the average duration of the calls.
+func (t Tool) Average() time.Duration {
+	if t.Calls == 0 {
+		return 0
+	}
+	return t.Total / time.Duration(t.Calls)
+}
+
+// Session is the statistics of a session.
+type Session struct {
+	// ID is a digest of the session ID, which identifies the session
+	// without handing its ID to other clients.
+	ID string
+	// Current is the session the snapshot was taken for.
+	Current     bool
+	First, Last time.Time
+	Calls       int64
+	Errors      int64
+	Tools       []Tool
+}
+
+// Snapshot is the statistics at a point in time.
+type Snapshot struct {
+	Since    time.Time
+	Uptime   time.Duration
+	Calls    int64
+	Errors   int64
+	Tools    []Tool
+	Sessions []Session
+}
+
+// Snapshot returns the statistics so far, with the session of currentID
+// marked. Tools are sorted by calls, sessions by last call, most recent
+// first.
+func (s *Stats) Snapshot(currentID string) Snapshot {
+	if s == nil {
+		return Snapshot{}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	snap := Snapshot{Since: s.since, Uptime: s.now().Sub(s.since), Tools: toolsOf(s.tools)}
+	for _, t := range snap.Tools {
+		snap.Calls += t.Calls
+		snap.Errors += t.Errors
+	}
+	for id, ss := range s.sessions {
+		session := Session{ID: digest(id), Current: id == currentID, First: ss.first, Last: ss.last,
+			Tools: toolsOf(ss.tools)}
+		for _, t := range session.Tools {
+			session.Calls += t.Calls
+			session.Errors += t.Errors
+		}
+		snap.Sessions = append(snap.Sessions, session)
+	}
+	sort.Slice(snap.Sessions, func(i, j int) bool {
+		a, b := snap.Sessions[i], snap.Sessions[j]
+		if !a.Last.Equal(b.Last) {
+			return a.Last.After(b.Last)
+		}
+		return a.ID < b.ID
+	})
+	return snap
+}
+
+func toolsOf(counters map[string]*counter) []Tool {
+	tools := make([]Tool, 0, len(counters))
+	for name, c := range counters {
+		tools = append(tools, Tool{Name: name, Calls: c.calls, Errors: c.errors, Total: c.total, Max: c.max})
+	}
+	sort.Slice(tools, func(i, j int) bool {
+		if tools[i].Calls != tools[j].Calls {
+			return tools[i].Calls > tools[j].Calls
+		}
+		return tools[i].Name < tools[j].Name
+	})
+	return tools
+}
+
+// digest returns the digest a session ID is reported under.
+func digest(sessionID string) string {
+	sum := sha256.Sum256([]byte(sessionID))
+	return hex.EncodeToString(sum[:6])
+}
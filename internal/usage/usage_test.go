@@ -0,0 +1,79 @@
+package usage
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testClock returns a clock starting at start that moves a second on each
+// reading.
+func testClock(start time.Time) func() time.Time {
+	now := start
+	return func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	}
+}
+
+func TestStats(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	s := newStats(testClock(start))
+	s.Record("a", "run_script", false, 2*time.Second)
+	s.Record("a", "run_script", true, 4*time.Second)
+	s.Record("b", "validate_script", false, 100*time.Millisecond)
+	s.Record("", "info", false, time.Millisecond)
+
+	snap := s.Snapshot("a")
+	assert.Equal(t, start.Add(time.Second), snap.Since)
+	assert.Equal(t, 5*time.Second, snap.Uptime)
+	assert.Equal(t, int64(4), snap.Calls)
+	assert.Equal(t, int64(1), snap.Errors)
+	require.Len(t, snap.Tools, 3)
+	run := snap.Tools[0]
+	assert.Equal(t, "run_script", run.Name)
+	assert.Equal(t, int64(1), run.Errors)
+	assert.Equal(t, 3*time.Second, run.Average())
+	assert.Equal(t, 4*time.Second, run.Max)
+	assert.Equal(t, []string{"info", "validate_script"}, []string{snap.Tools[1].Name, snap.Tools[2].Name})
+
+	require.Len(t, snap.Sessions, 2)
+	b, a := snap.Sessions[0], snap.Sessions[1]
+	assert.False(t, b.Current)
+	assert.True(t, a.Current)
+	assert.Equal(t, int64(2), a.Calls)
+	assert.Equal(t, int64(1), a.Errors)
+	assert.Equal(t, start.Add(2*time.Second), a.First)
+	assert.Equal(t, start.Add(3*time.Second), a.Last)
+	assert.Len(t, a.Tools, 1)
+	assert.Equal(t, digest("a"), a.ID)
+	assert.NotEqual(t, "a", a.ID, "session IDs are not handed out")
+}
+
+func TestStatsForgetsOldSessions(t *testing.T) {
+	t.Parallel()
+
+	s := newStats(testClock(time.Now()))
+	for i := range MaxSessions + 1 {
+		s.Record(fmt.Sprint(i), "info", false, 0)
+	}
+	snap := s.Snapshot("")
+	assert.Len(t, snap.Sessions, MaxSessions)
+	assert.Equal(t, int64(MaxSessions+1), snap.Calls, "tools keep counting")
+	for _, session := range snap.Sessions {
+		assert.NotEqual(t, digest("0"), session.ID)
+	}
+}
+
+func TestNilStats(t *testing.T) {
+	t.Parallel()
+
+	var s *Stats
+	s.Record("a", "info", false, time.Second)
+	assert.Empty(t, s.Snapshot("a").Tools)
+}
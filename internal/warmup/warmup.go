@@ -0,0 +1,308 @@
+// Package warmup recomputes the metrics and thresholds of a k6 run from its
+// JSON output (k6 run --out json) without the samples of its first seconds,
+// so cold caches, connection setup and JIT warm-up do not skew them.
+package warmup
+
+import (
+	"io"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/mcp-k6/internal/samples"
+	"github.com/grafana/mcp-k6/internal/summary"
+)
+
+// exprRe matches a threshold expression: "p(95)<500", "rate<=0.01".
+//
+//nolint:gochecknoglobals // Compiled once and reused.
+var exprRe = regexp.MustCompile(`^\s*(avg|min|max|med|count|rate|value|p\(\d+(?:\.\d+)?\))\s*` +
+	`(<=|>=|===|==|!=|<|>)\s*(-?\d+(?:\.\d+)?)\s*$`)
+
+// Metric is a metric or submetric of the run without the warm-up, with the
+// values k6 exports for its type: "count" and "rate" of counters, "value",
+// "min" and "max" of gauges, "value" (the share of non-zero samples),
+// "passes" and "fails" of rates, and "avg", "min", "med", "max", "p(90)",
+// "p(95)" and the percentiles of thresholds of trends. Time trends are in
+// milliseconds.
+type Metric struct {
+	Name   string             `json:"name"`
+	Type   string             `json:"type"`
+	Values map[string]float64 `json:"values"`
+}
+
+// Result holds the metrics and thresholds of the run after the warm-up.
+type Result struct {
+	// Discarded is the warm-up left out, since the first sample of the run.
+	Discarded        string `json:"discarded"`
+	DiscardedSamples int    `json:"discarded_samples"`
+	// Metrics are sorted by name.
+	Metrics []Metric `json:"metrics"`
+	// Thresholds are the thresholds of the run evaluated on Metrics, with
+	// the observed Value formatted like k6; thresholds whose expression
+	// the summary did not print, or cannot be evaluated, are left out.
+	Thresholds []summary.Threshold `json:"thresholds,omitempty"`
+}
+
+// definition is the type of a metric, and whether it holds times.
+type definition struct {
+	kind string
+	time bool
+}
+
+// Read returns the metrics of the JSON output r without the samples of the
+// first discard of the run, and the result of thresholds on them.
+func Read(r io.Reader, discard time.Duration, thresholds []summary.Threshold) (*Result, error) {
+	defs := make(map[string]definition)
+	var points []samples.Sample
+	var start time.Time
+
+	err := samples.ReadJSON(r, func(m samples.Metric) {
+		defs[m.Name] = definition{kind: m.Type, time: m.Contains == "time"}
+	}, func(p samples.Sample) bool {
+		points = append(points, p)
+		if start.IsZero() || p.Time.Before(start) {
+			start = p.Time
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	res := &Result{Discarded: discard.String(), Metrics: []Metric{}}
+	cutoff := start.Add(discard)
+	series := make(map[string]*aggregate)
+	submetrics := submetricsOf(thresholds)
+	var end time.Time
+	for _, p := range points {
+		if p.Time.Before(cutoff) {
+			res.DiscardedSamples++
+			continue
+		}
+		if p.Time.After(end) {
+			end = p.Time
+		}
+		add(series, p.Metric, p.Value)
+		for _, sub := range submetrics[p.Metric] {
+			if matches(p.Tags, sub.tags) {
+				add(series, sub.name, p.Value)
+			}
+		}
+	}
+	seconds := end.Sub(cutoff).Seconds()
+	percentiles := percentilesOf(thresholds)
+
+	names := make([]string, 0, len(series))
+	for name := range series {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	values := make(map[string]map[string]float64, len(names))
+	for _, name := range names {
+		def := defs[parent(name)]
+		m := Metric{Name: name, Type: def.kind, Values: series[name].stats(def.kind, seconds, percentiles[name])}
+		values[name] = m.Values
+		res.Metrics = append(res.Metrics, m)
+	}
+
+	for _, th := range thresholds {
+		v, ok := values[strings.ReplaceAll(th.Metric, " ", "")]
+		if !ok {
+			continue
+		}
+		if evaluated, ok := evaluate(th, v, defs[parent(th.Metric)]); ok {
+			res.Thresholds = append(res.Thresholds, evaluated)
+		}
+	}
+	return res, nil
+}
+
+// submetric is a metric narrowed to tags by a threshold, such as
+// "http_req_duration{name:checkout}".
+type submetric struct {
+	name string
+	tags map[string]string
+}
+
+// submetricsOf returns the submetrics of thresholds by their metric.
+func submetricsOf(thresholds []summary.Threshold) map[string][]submetric {
+	subs := make(map[string][]submetric)
+	seen := make(map[string]bool)
+	for _, th := range thresholds {
+		name := strings.ReplaceAll(th.Metric, " ", "")
+		metric, rest, ok := strings.Cut(name, "{")
+		if !ok || seen[name] {
+			continue
+		}
+		seen[name] = true
+		tags := make(map[string]string)
+		for _, tag := range strings.Split(strings.TrimSuffix(rest, "}"), ",") {
+			if k, v, ok := strings.Cut(tag, ":"); ok {
+				tags[k] = v
+			}
+		}
+		subs[metric] = append(subs[metric], submetric{name: name, tags: tags})
+	}
+	return subs
+}
+
+// percentilesOf returns the percentiles thresholds use, by metric.
+func percentilesOf(thresholds []summary.Threshold) map[string][]string {
+	percentiles := make(map[string][]string)
+	for _, th := range thresholds {
+		if m := exprRe.FindStringSubmatch(th.Expression); m != nil && strings.HasPrefix(m[1], "p(") {
+			name := strings.ReplaceAll(th.Metric, " ", "")
+			percentiles[name] = append(percentiles[name], m[1])
+		}
+	}
+	return percentiles
+}
+
+func parent(name string) string {
+	metric, _, _ := strings.Cut(name, "{")
+	return strings.TrimSpace(metric)
+}
+
+func matches(tags, want map[string]string) bool {
+	for name, value := range want {
+		if tags[name] != value {
+			return false
+		}
+	}
+	return true
+}
+
+// aggregate holds the samples of a metric after the warm-up.
+type aggregate struct {
+	samples []float64
+	last    float64
+}
+
+func add(series map[string]*aggregate, name string, v float64) {
+	a, ok := series[name]
+	if !ok {
+		a = &aggregate{}
+		series[name] = a
+	}
+	a.samples = append(a.samples, v)
+	a.last = v
+}
+
+// stats returns the values k6 exports for a metric of type kind, over a
+// run of the given seconds, with the given percentiles of trends.
+func (a *aggregate) stats(kind string, seconds float64, percentiles []string) map[string]float64 {
+	var sum, nonZero float64
+	for _, v := range a.samples {
+		sum += v
+		if v != 0 {
+			nonZero++
+		}
+	}
+	n := float64(len(a.samples))
+	sorted := append([]float64(nil), a.samples...)
+	sort.Float64s(sorted)
+	switch kind {
+	case summary.TypeCounter:
+		values := map[string]float64{"count": round(sum)}
+		if seconds > 0 {
+			values["rate"] = round(sum / seconds)
+		}
+		return values
+	case summary.TypeGauge:
+		return map[string]float64{"value": a.last, "min": sorted[0], "max": sorted[len(sorted)-1]}
+	case summary.TypeRate:
+		return map[string]float64{"value": round(nonZero / n), "passes": nonZero, "fails": n - nonZero}
+	default:
+		values := map[string]float64{
+			"avg":   round(sum / n),
+			"min":   sorted[0],
+			"med":   percentile(sorted, 50),
+			"max":   sorted[len(sorted)-1],
+			"p(90)": percentile(sorted, 90),
+			"p(95)": percentile(sorted, 95),
+		}
+		for _, stat := range percentiles {
+			p, _ := strconv.ParseFloat(strings.TrimSuffix(strings.TrimPrefix(stat, "p("), ")"), 64)
+			values[stat] = percentile(sorted, p)
+		}
+		return values
+	}
+}
+
+// evaluate evaluates the expression of a threshold on the values of its
+// metric.
+func evaluate(th summary.Threshold, values map[string]float64, def definition) (summary.Threshold, bool) {
+	m := exprRe.FindStringSubmatch(th.Expression)
+	if m == nil {
+		return summary.Threshold{}, false
+	}
+	stat, op := m[1], m[2]
+	limit, _ := strconv.ParseFloat(m[3], 64)
+	key := stat
+	if def.kind == summary.TypeRate && stat == "rate" {
+		key = "value"
+	}
+	observed, ok := values[key]
+	if !ok {
+		return summary.Threshold{}, false
+	}
+
+	var passed bool
+	switch op {
+	case "<":
+		passed = observed < limit
+	case "<=":
+		passed = observed <= limit
+	case ">":
+		passed = observed > limit
+	case ">=":
+		passed = observed >= limit
+	case "==", "===":
+		passed = observed == limit
+	case "!=":
+		passed = observed != limit
+	}
+	return summary.Threshold{
+		Metric:     th.Metric,
+		Expression: th.Expression,
+		Value:      stat + "=" + formatValue(stat, observed, def),
+		Passed:     passed,
+	}, true
+}
+
+// formatValue formats an observed value like the k6 summary: rates of rate
+// metrics in percent, times in milliseconds.
+func formatValue(stat string, v float64, def definition) string {
+	s := strconv.FormatFloat(math.Round(v*100)/100, 'f', -1, 64)
+	switch {
+	case def.kind == summary.TypeRate && stat == "rate":
+		return strconv.FormatFloat(math.Round(v*10000)/100, 'f', 2, 64) + "%"
+	case def.kind == summary.TypeCounter && stat == "rate":
+		return s + "/s"
+	case def.time && stat != "count":
+		return s + "ms"
+	default:
+		return s
+	}
+}
+
+// percentile returns the p-th percentile of sorted values, interpolating
+// between ranks like k6.
+func percentile(sorted []float64, p float64) float64 {
+	rank := p / 100 * float64(len(sorted)-1)
+	lower := int(rank)
+	v := sorted[lower]
+	if lower+1 < len(sorted) {
+		v += (sorted[lower+1] - sorted[lower]) * (rank - float64(lower))
+	}
+	return round(v)
+}
+
+// round rounds v to 4 decimals.
+func round(v float64) float64 {
+	return math.Round(v*10000) / 10000
+}
@@ -0,0 +1,82 @@
+package warmup
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grafana/mcp-k6/internal/summary"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// output writes the JSON output of a minute of requests, slow and failing
+// during the first 10 seconds.
+func output() string {
+	var b strings.Builder
+	b.WriteString(`{"type":"Metric","data":{"name":"http_req_duration","type":"trend","contains":"time"},` +
+		`"metric":"http_req_duration"}` + "\n")
+	b.WriteString(`{"type":"Metric","data":{"name":"http_req_failed","type":"rate","contains":"default"},` +
+		`"metric":"http_req_failed"}` + "\n")
+	b.WriteString(`{"type":"Metric","data":{"name":"http_reqs","type":"counter","contains":"default"},` +
+		`"metric":"http_reqs"}` + "\n")
+	start := time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)
+	for i := range 60 {
+		latency, failed := 100, 0
+		if i < 10 {
+			latency, failed = 1000, 1
+		}
+		at := start.Add(time.Duration(i) * time.Second).Format(time.RFC3339)
+		for _, name := range []string{"home", "cart"} {
+			fmt.Fprintf(&b, `{"type":"Point","metric":"http_req_duration","data":{"time":%q,"value":%d,`+
+				`"tags":{"name":%q}}}`+"\n", at, latency+4, name)
+			fmt.Fprintf(&b, `{"type":"Point","metric":"http_req_failed","data":{"time":%q,"value":%d,`+
+				`"tags":{"name":%q}}}`+"\n", at, failed, name)
+			fmt.Fprintf(&b, `{"type":"Point","metric":"http_reqs","data":{"time":%q,"value":1,`+
+				`"tags":{"name":%q}}}`+"\n", at, name)
+		}
+	}
+	return b.String()
+}
+
+func TestRead(t *testing.T) {
+	t.Parallel()
+
+	thresholds := []summary.Threshold{
+		{Metric: "http_req_duration", Expression: "p(95)<500", Value: "p(95)=1004ms"},
+		{Metric: "http_req_failed", Expression: "rate<0.01", Value: "rate=16.66%"},
+		{Metric: "http_reqs", Expression: "count>200"},
+		{Metric: "http_req_duration{name:home}", Expression: "p(99)<110"},
+		{Metric: "http_req_duration", Expression: "avg < 100"},
+		{Metric: "checks"},
+	}
+	res, err := Read(strings.NewReader(output()), 10*time.Second, thresholds)
+	require.NoError(t, err)
+	assert.Equal(t, "10s", res.Discarded)
+	assert.Equal(t, 60, res.DiscardedSamples)
+
+	require.Len(t, res.Metrics, 4)
+	assert.Equal(t, "http_req_duration", res.Metrics[0].Name)
+	assert.Equal(t, summary.TypeTrend, res.Metrics[0].Type)
+	assert.InDelta(t, 104, res.Metrics[0].Values["max"], 0)
+	assert.Equal(t, "http_req_duration{name:home}", res.Metrics[1].Name)
+	assert.InDelta(t, 104, res.Metrics[1].Values["p(99)"], 0)
+	assert.InDelta(t, 0, res.Metrics[2].Values["value"], 0)
+	assert.InDelta(t, 100, res.Metrics[3].Values["count"], 0)
+	assert.InDelta(t, 2.0408, res.Metrics[3].Values["rate"], 0)
+
+	assert.Equal(t, []summary.Threshold{
+		{Metric: "http_req_duration", Expression: "p(95)<500", Value: "p(95)=104ms", Passed: true},
+		{Metric: "http_req_failed", Expression: "rate<0.01", Value: "rate=0.00%", Passed: true},
+		{Metric: "http_reqs", Expression: "count>200", Value: "count=100"},
+		{Metric: "http_req_duration{name:home}", Expression: "p(99)<110", Value: "p(99)=104ms", Passed: true},
+		{Metric: "http_req_duration", Expression: "avg < 100", Value: "avg=104ms"},
+	}, res.Thresholds)
+
+	// Without a warm-up, the slow start fails the latency threshold
+	res, err = Read(strings.NewReader(output()), 0, thresholds[:1])
+	require.NoError(t, err)
+	assert.Zero(t, res.DiscardedSamples)
+	assert.False(t, res.Thresholds[0].Passed)
+}
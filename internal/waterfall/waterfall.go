@@ -0,0 +1,180 @@
+// Package waterfall builds the tree of the group() durations of a k6 run
+// from its JSON output (k6 run --out json), mirroring the nesting of the
+// groups of the script so multi-step journeys can be read step by step.
+package waterfall
+
+import (
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/grafana/mcp-k6/internal/samples"
+)
+
+// pathSeparator separates the names of nested groups in the group tag:
+// "::login::submit".
+const pathSeparator = "::"
+
+// Group is a group of the script with the statistics of its durations, in
+// milliseconds.
+type Group struct {
+	Name string `json:"name"`
+	// Path is the group tag of the group: the names of the groups it is
+	// nested in and its own, each prefixed with "::".
+	Path  string  `json:"path"`
+	Count int     `json:"count"`
+	Avg   float64 `json:"avg"`
+	Min   float64 `json:"min"`
+	Med   float64 `json:"med"`
+	P95   float64 `json:"p95"`
+	Max   float64 `json:"max"`
+	// Share is the part of the average duration of the enclosing group, or
+	// of the iteration for top-level groups, the group takes.
+	Share float64 `json:"share,omitempty"`
+	// Groups are the groups nested in the group, in the order they start.
+	Groups []*Group `json:"groups,omitempty"`
+
+	durations []float64
+	start     time.Time
+}
+
+// Waterfall is the tree of the groups of a run.
+type Waterfall struct {
+	// Iteration is the average duration of the iterations of the run, in
+	// milliseconds.
+	Iteration float64 `json:"iteration_avg"`
+	// Groups are the top-level groups, in the order they start.
+	Groups []*Group `json:"groups"`
+}
+
+// Read returns the waterfall of the JSON output r, or nil when the run went
+// through no group.
+func Read(r io.Reader) (*Waterfall, error) {
+	groups := make(map[string]*Group)
+	var iterations []float64
+
+	err := samples.ReadJSON(r, nil, func(smp samples.Sample) bool {
+		path := smp.Tags["group"]
+		switch {
+		case smp.Metric == "iteration_duration" && path == "":
+			iterations = append(iterations, smp.Value)
+		case smp.Metric == "group_duration" && path != "":
+			g := group(groups, path)
+			g.durations = append(g.durations, smp.Value)
+			start := smp.Time.Add(-time.Duration(smp.Value * float64(time.Millisecond)))
+			if g.start.IsZero() || start.Before(g.start) {
+				g.start = start
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(groups) == 0 {
+		return nil, nil //nolint:nilnil // No groups to chart.
+	}
+
+	w := &Waterfall{Iteration: stats(iterations).Avg}
+	for _, g := range groups {
+		g.setStats()
+	}
+	// Link the groups from the deepest, so enclosing groups only start once
+	// their own nested groups did
+	paths := make([]string, 0, len(groups))
+	for path := range groups {
+		paths = append(paths, path)
+	}
+	sort.Slice(paths, func(i, j int) bool {
+		return strings.Count(paths[i], pathSeparator) > strings.Count(paths[j], pathSeparator)
+	})
+	for _, path := range paths {
+		g := groups[path]
+		parent, ok := groups[parentPath(path)]
+		if !ok {
+			w.Groups = append(w.Groups, g)
+			continue
+		}
+		parent.Groups = append(parent.Groups, g)
+		if parent.start.IsZero() || g.start.Before(parent.start) {
+			parent.start = g.start
+		}
+	}
+	for _, g := range groups {
+		orderAndShare(g.Groups, g.Avg)
+	}
+	orderAndShare(w.Groups, w.Iteration)
+	return w, nil
+}
+
+// group returns the group of path, adding it and the groups it is nested in
+// to groups. Enclosing groups get samples of their own once they end, so a
+// run stopped in a group leaves them without.
+func group(groups map[string]*Group, path string) *Group {
+	if g, ok := groups[path]; ok {
+		return g
+	}
+	g := &Group{Name: path[strings.LastIndex(path, pathSeparator)+len(pathSeparator):], Path: path}
+	groups[path] = g
+	if parent := parentPath(path); parent != "" {
+		group(groups, parent)
+	}
+	return g
+}
+
+// parentPath returns the path of the group path is nested in, or "" for
+// top-level groups.
+func parentPath(path string) string {
+	return path[:max(strings.LastIndex(path, pathSeparator), 0)]
+}
+
+func (g *Group) setStats() {
+	s := stats(g.durations)
+	g.Count, g.Avg, g.Min, g.Med, g.P95, g.Max = len(g.durations), s.Avg, s.Min, s.Med, s.P95, s.Max
+}
+
+// orderAndShare orders groups by start and sets their share of total.
+func orderAndShare(groups []*Group, total float64) {
+	sort.SliceStable(groups, func(i, j int) bool { return groups[i].start.Before(groups[j].start) })
+	for _, g := range groups {
+		if total > 0 && g.Count > 0 {
+			g.Share = math.Round(g.Avg/total*1000) / 1000
+		}
+	}
+}
+
+type summary struct {
+	Avg, Min, Med, P95, Max float64
+}
+
+// stats summarizes values, with the percentiles k6 reports: interpolated
+// between the closest ranks.
+func stats(values []float64) summary {
+	if len(values) == 0 {
+		return summary{}
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	sum := 0.0
+	for _, v := range sorted {
+		sum += v
+	}
+	return summary{
+		Avg: sum / float64(len(sorted)),
+		Min: sorted[0],
+		Med: percentile(sorted, 0.5),
+		P95: percentile(sorted, 0.95),
+		Max: sorted[len(sorted)-1],
+	}
+}
+
+func percentile(sorted []float64, p float64) float64 {
+	rank := p * float64(len(sorted)-1)
+	lower := int(rank)
+	if lower+1 >= len(sorted) {
+		return sorted[lower]
+	}
+	return sorted[lower] + (sorted[lower+1]-sorted[lower])*(rank-float64(lower))
+}
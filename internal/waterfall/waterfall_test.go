@@ -0,0 +1,78 @@
+package waterfall
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// point returns a sample of the JSON output ending at second s of the run.
+func point(metric, group string, s, value float64) string {
+	return fmt.Sprintf(`{"metric":%q,"type":"Point","data":{"time":"2026-01-02T10:00:%06.3fZ","value":%v,`+
+		`"tags":{"group":%q,"scenario":"default"}}}`, metric, s, value, group)
+}
+
+func TestRead(t *testing.T) {
+	t.Parallel()
+
+	var lines []string
+	// Two iterations of: login (form, submit), browse, then checkout
+	for i, offset := range []float64{0, 10} {
+		slow := float64(i) * 100
+		lines = append(lines,
+			point("http_req_duration", "::login::form", offset+0.1, 90),
+			point("group_duration", "::login::form", offset+0.1, 100),
+			point("group_duration", "::login::submit", offset+0.4, 300+slow),
+			point("group_duration", "::login", offset+0.4, 400+slow),
+			point("group_duration", "::browse", offset+0.6, 200),
+			point("group_duration", "::checkout", offset+1.0, 400),
+			point("iteration_duration", "", offset+1.0, 1000+slow),
+			point("iteration_duration", "::setup", offset, 5),
+		)
+	}
+	lines = append(lines, `{"type":"Metric","data":{"name":"group_duration"},"metric":"group_duration"}`, "")
+
+	w, err := Read(strings.NewReader(strings.Join(lines, "\n")))
+	require.NoError(t, err)
+	require.NotNil(t, w)
+	assert.InDelta(t, 1050, w.Iteration, 0)
+
+	require.Len(t, w.Groups, 3)
+	login := w.Groups[0]
+	assert.Equal(t, "login", login.Name)
+	assert.Equal(t, "::login", login.Path)
+	assert.Equal(t, 2, login.Count)
+	assert.InDelta(t, 450, login.Avg, 0)
+	assert.InDelta(t, 400, login.Min, 0)
+	assert.InDelta(t, 500, login.Max, 0)
+	assert.InDelta(t, 495, login.P95, 1e-9)
+	assert.InDelta(t, 0.429, login.Share, 0)
+	assert.Equal(t, []string{"browse", "checkout"}, []string{w.Groups[1].Name, w.Groups[2].Name})
+
+	require.Len(t, login.Groups, 2)
+	assert.Equal(t, "form", login.Groups[0].Name)
+	assert.Equal(t, "submit", login.Groups[1].Name)
+	assert.InDelta(t, 350, login.Groups[1].Avg, 0)
+	assert.InDelta(t, 0.778, login.Groups[1].Share, 0)
+}
+
+func TestReadUnfinishedGroups(t *testing.T) {
+	t.Parallel()
+
+	// The run stopped in the middle of the outer group
+	w, err := Read(strings.NewReader(point("group_duration", "::journey::step", 1, 50)))
+	require.NoError(t, err)
+	require.Len(t, w.Groups, 1)
+	assert.Equal(t, "journey", w.Groups[0].Name)
+	assert.Zero(t, w.Groups[0].Count)
+	require.Len(t, w.Groups[0].Groups, 1)
+	assert.Zero(t, w.Groups[0].Groups[0].Share)
+
+	// Without groups there is no waterfall
+	w, err = Read(strings.NewReader(point("iteration_duration", "", 1, 50)))
+	require.NoError(t, err)
+	assert.Nil(t, w)
+}
@@ -0,0 +1,99 @@
+// Package webhook posts JSON notifications to the URLs configured on the
+// server, such as Slack incoming webhooks.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// timeout bounds each notification request.
+const timeout = 10 * time.Second
+
+// ErrInvalidURL is returned for webhook URLs that are not absolute http(s) URLs.
+var ErrInvalidURL = errors.New("invalid webhook URL")
+
+// Notifier posts notifications to a fixed set of URLs. A nil *Notifier
+// sends nothing.
+type Notifier struct {
+	urls   []*url.URL
+	client *http.Client
+}
+
+// New returns a notifier for urls, or nil when there are none.
+func New(urls []string) (*Notifier, error) {
+	if len(urls) == 0 {
+		return nil, nil
+	}
+	n := &Notifier{client: &http.Client{Timeout: timeout}}
+	for _, raw := range urls {
+		u, err := url.Parse(raw)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			// The URL itself may hold a token, as Slack webhooks do
+			return nil, fmt.Errorf("%w: want an absolute http or https URL", ErrInvalidURL)
+		}
+		n.urls = append(n.urls, u)
+	}
+	return n, nil
+}
+
+// Hosts returns the hosts notifications go to, for logging without the
+// tokens webhook URLs often carry in their path.
+func (n *Notifier) Hosts() []string {
+	if n == nil {
+		return nil
+	}
+	hosts := make([]string, 0, len(n.urls))
+	for _, u := range n.urls {
+		hosts = append(hosts, u.Host)
+	}
+	return hosts
+}
+
+// Notify posts payload as JSON to every URL and returns the failures.
+func (n *Notifier) Notify(ctx context.Context, payload any) error {
+	if n == nil {
+		return nil
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("encoding webhook payload: %w", err)
+	}
+	var errs []error
+	for _, u := range n.urls {
+		if err := n.post(ctx, u, body); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (n *Notifier) post(ctx context.Context, u *url.URL, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("webhook %s: %w", u.Host, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := n.client.Do(req)
+	if err != nil {
+		// Errors from the client quote the full URL
+		var uerr *url.Error
+		if errors.As(err, &uerr) {
+			err = uerr.Err
+		}
+		return fmt.Errorf("webhook %s: %w", u.Host, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("webhook %s: %s", u.Host, resp.Status)
+	}
+	return nil
+}
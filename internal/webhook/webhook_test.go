@@ -0,0 +1,56 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotify(t *testing.T) {
+	t.Parallel()
+
+	var got map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(srv.Close)
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	t.Cleanup(failing.Close)
+
+	n, err := New([]string{srv.URL + "/services/T000/B000/token", failing.URL + "/hook"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{strings.TrimPrefix(srv.URL, "http://"), strings.TrimPrefix(failing.URL, "http://")},
+		n.Hosts())
+
+	err = n.Notify(context.Background(), map[string]any{"text": "done"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "403 Forbidden")
+	assert.NotContains(t, err.Error(), "/hook")
+	assert.Equal(t, map[string]any{"text": "done"}, got)
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	n, err := New(nil)
+	require.NoError(t, err)
+	assert.Nil(t, n)
+	require.NoError(t, n.Notify(context.Background(), "ignored"))
+
+	for _, raw := range []string{"hooks.slack.com/services/x", "ftp://example.com/hook", "https://", "://bad"} {
+		_, err := New([]string{raw})
+		require.ErrorIs(t, err, ErrInvalidURL, raw)
+		assert.NotContains(t, err.Error(), raw)
+	}
+}
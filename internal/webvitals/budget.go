@@ -0,0 +1,88 @@
+package webvitals
+
+import (
+	"io"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/grafana/mcp-k6/internal/samples"
+)
+
+// Budget is a performance budget of a browser test, in the spirit of
+// Lighthouse budgets. Bytes and Requests bound what each iteration loads,
+// Vitals the 75th percentile of the web vitals of all pages. Zero Bytes and
+// Requests are not checked.
+type Budget struct {
+	Bytes    float64            `json:"bytes,omitempty"`
+	Requests float64            `json:"requests,omitempty"`
+	Vitals   map[string]float64 `json:"vitals,omitempty"`
+}
+
+// Violation is a budget a run went over.
+type Violation struct {
+	// Budget is "bytes", "requests" or the name of a web vital.
+	Budget string  `json:"budget"`
+	Limit  float64 `json:"limit"`
+	Actual float64 `json:"actual"`
+}
+
+// BudgetResult is the outcome of a performance budget.
+type BudgetResult struct {
+	Iterations int `json:"iterations"`
+	// Bytes and Requests are the averages per iteration.
+	Bytes    float64 `json:"bytes"`
+	Requests float64 `json:"requests"`
+	// Vitals holds the 75th percentile of each web vital recorded.
+	Vitals     map[string]float64 `json:"vitals,omitempty"`
+	Violations []Violation        `json:"violations"`
+	Passed     bool               `json:"passed"`
+}
+
+// CheckBudget checks the browser samples of the JSON output r against b.
+func CheckBudget(r io.Reader, b Budget) (*BudgetResult, error) {
+	res := &BudgetResult{Vitals: make(map[string]float64), Violations: []Violation{}}
+	var bytes, requests float64
+	vitals := make(map[string][]float64)
+
+	err := samples.ReadJSON(r, nil, func(smp samples.Sample) bool {
+		switch smp.Metric {
+		case "iterations":
+			res.Iterations++
+		case "browser_data_received":
+			bytes += smp.Value
+		case "browser_http_req_duration":
+			requests++
+		default:
+			if name, ok := strings.CutPrefix(smp.Metric, metricPrefix); ok && slices.Contains(Names, name) {
+				vitals[name] = append(vitals[name], smp.Value)
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// An interrupted run still loaded its pages
+	per := float64(max(res.Iterations, 1))
+	res.Bytes = math.Round(bytes / per)
+	res.Requests = math.Round(requests/per*10) / 10
+	if b.Bytes > 0 && res.Bytes > b.Bytes {
+		res.Violations = append(res.Violations, Violation{Budget: "bytes", Limit: b.Bytes, Actual: res.Bytes})
+	}
+	if b.Requests > 0 && res.Requests > b.Requests {
+		res.Violations = append(res.Violations, Violation{Budget: "requests", Limit: b.Requests, Actual: res.Requests})
+	}
+	for _, name := range Names {
+		if len(vitals[name]) == 0 {
+			continue
+		}
+		res.Vitals[name] = p75(vitals[name])
+		if limit, ok := b.Vitals[name]; ok && res.Vitals[name] > limit {
+			res.Violations = append(res.Violations, Violation{Budget: name, Limit: limit, Actual: res.Vitals[name]})
+		}
+	}
+	res.Passed = len(res.Violations) == 0
+	return res, nil
+}
@@ -0,0 +1,51 @@
+package webvitals
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckBudget(t *testing.T) {
+	t.Parallel()
+
+	page := "https://shop.test/"
+	var lines []string
+	for range 2 {
+		lines = append(lines,
+			point("browser_http_req_duration", page, 120),
+			point("browser_http_req_duration", page+"app.js", 80),
+			point("browser_http_req_duration", page+"logo.png", 40),
+			point("browser_data_received", page, 20000),
+			point("browser_data_received", page+"app.js", 900000),
+			point("browser_web_vital_lcp", page, 2600),
+			point("browser_web_vital_cls", page, 0.02),
+			point("iterations", "", 1),
+		)
+	}
+	output := strings.Join(lines, "\n")
+
+	res, err := CheckBudget(strings.NewReader(output), Budget{
+		Bytes:    500000,
+		Requests: 5,
+		Vitals:   map[string]float64{"lcp": 2500, "cls": 0.1, "inp": 200},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, res.Iterations)
+	assert.InDelta(t, 920000, res.Bytes, 0)
+	assert.InDelta(t, 3, res.Requests, 0)
+	assert.Equal(t, map[string]float64{"lcp": 2600, "cls": 0.02}, res.Vitals)
+	assert.False(t, res.Passed)
+	assert.Equal(t, []Violation{
+		{Budget: "bytes", Limit: 500000, Actual: 920000},
+		{Budget: "lcp", Limit: 2500, Actual: 2600},
+	}, res.Violations)
+
+	// Unset bytes and requests are not checked
+	res, err = CheckBudget(strings.NewReader(output), Budget{Vitals: map[string]float64{"cls": 0.1}})
+	require.NoError(t, err)
+	assert.True(t, res.Passed)
+	assert.Empty(t, res.Violations)
+}
@@ -0,0 +1,117 @@
+// Package webvitals reads the Core Web Vitals the k6 browser module records
+// for each page from the JSON output of a run (k6 run --out json), and checks
+// them, and the bytes and requests the run loaded, against budgets.
+package webvitals
+
+import (
+	"io"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/grafana/mcp-k6/internal/samples"
+)
+
+// metricPrefix starts the names of the web vital metrics of the browser
+// module: browser_web_vital_lcp.
+const metricPrefix = "browser_web_vital_"
+
+// percentile is the share of page loads a vital must be within budget for,
+// as in the Core Web Vitals assessment.
+const percentile = 0.75
+
+// Names are the web vitals, in the order they are reported.
+//
+//nolint:gochecknoglobals // Read-only lookup table.
+var Names = []string{"ttfb", "fcp", "lcp", "fid", "inp", "cls"}
+
+// DefaultBudgets returns the thresholds of a good rating of each web vital:
+// milliseconds, and a unitless score for cls.
+func DefaultBudgets() map[string]float64 {
+	return map[string]float64{
+		"ttfb": 800,
+		"fcp":  1800,
+		"lcp":  2500,
+		"fid":  100,
+		"inp":  200,
+		"cls":  0.1,
+	}
+}
+
+// Vital is the 75th percentile of a web vital of a page, checked against its
+// budget.
+type Vital struct {
+	Name   string  `json:"name"`
+	P75    float64 `json:"p75"`
+	Count  int     `json:"count"`
+	Budget float64 `json:"budget"`
+	Passed bool    `json:"passed"`
+}
+
+// Page holds the web vitals of a page.
+type Page struct {
+	URL    string  `json:"url"`
+	Vitals []Vital `json:"vitals"`
+	Passed bool    `json:"passed"`
+}
+
+// Read returns the web vitals of each page of the JSON output r, in the
+// order the pages were first loaded, checked against budgets. Vitals
+// without a budget in budgets get their default budget. Read returns no
+// pages for runs without browser samples.
+func Read(r io.Reader, budgets map[string]float64) ([]Page, error) {
+	values := make(map[string]map[string][]float64)
+	var urls []string
+
+	err := samples.ReadJSON(r, nil, func(smp samples.Sample) bool {
+		name, ok := strings.CutPrefix(smp.Metric, metricPrefix)
+		if !ok || !slices.Contains(Names, name) {
+			return true
+		}
+		url := smp.Tags["url"]
+		if values[url] == nil {
+			values[url] = make(map[string][]float64)
+			urls = append(urls, url)
+		}
+		values[url][name] = append(values[url][name], smp.Value)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	limits := DefaultBudgets()
+	for name, budget := range budgets {
+		limits[name] = budget
+	}
+	pages := make([]Page, 0, len(urls))
+	for _, url := range urls {
+		page := Page{URL: url, Passed: true}
+		for _, name := range Names {
+			samples := values[url][name]
+			if len(samples) == 0 {
+				continue
+			}
+			v := Vital{Name: name, P75: p75(samples), Count: len(samples), Budget: limits[name]}
+			v.Passed = v.P75 <= v.Budget
+			page.Passed = page.Passed && v.Passed
+			page.Vitals = append(page.Vitals, v)
+		}
+		pages = append(pages, page)
+	}
+	return pages, nil
+}
+
+// p75 returns the 75th percentile of values, interpolated between the
+// closest ranks as k6 does, rounded to 4 decimals.
+func p75(values []float64) float64 {
+	sort.Float64s(values)
+	rank := percentile * float64(len(values)-1)
+	lower := int(rank)
+	v := values[lower]
+	if lower+1 < len(values) {
+		v += (values[lower+1] - values[lower]) * (rank - float64(lower))
+	}
+	return math.Round(v*10000) / 10000
+}
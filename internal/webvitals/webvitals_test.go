@@ -0,0 +1,54 @@
+package webvitals
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func point(metric, url string, value float64) string {
+	return fmt.Sprintf(`{"metric":%q,"type":"Point","data":{"time":"2026-01-02T10:00:00Z","value":%v,`+
+		`"tags":{"url":%q,"rating":"good"}}}`, metric, value, url)
+}
+
+func TestRead(t *testing.T) {
+	t.Parallel()
+
+	home, cart := "https://shop.test/", "https://shop.test/cart"
+	output := strings.Join([]string{
+		point("browser_web_vital_ttfb", home, 300),
+		point("browser_web_vital_lcp", home, 1000),
+		point("browser_web_vital_lcp", home, 2000),
+		point("browser_web_vital_lcp", home, 3000),
+		point("browser_web_vital_cls", home, 0.05),
+		point("browser_web_vital_lcp", cart, 3200),
+		point("browser_web_vital_cls", cart, 0.3),
+		point("browser_data_received", home, 1024),
+		point("http_req_duration", home, 10),
+	}, "\n")
+
+	pages, err := Read(strings.NewReader(output), nil)
+	require.NoError(t, err)
+	require.Len(t, pages, 2)
+	assert.Equal(t, Page{URL: home, Passed: true, Vitals: []Vital{
+		{Name: "ttfb", P75: 300, Count: 1, Budget: 800, Passed: true},
+		{Name: "lcp", P75: 2500, Count: 3, Budget: 2500, Passed: true},
+		{Name: "cls", P75: 0.05, Count: 1, Budget: 0.1, Passed: true},
+	}}, pages[0])
+	assert.False(t, pages[1].Passed)
+
+	// Budgets replace the defaults they name
+	pages, err = Read(strings.NewReader(output), map[string]float64{"lcp": 2000, "cls": 0.5})
+	require.NoError(t, err)
+	assert.False(t, pages[0].Passed)
+	assert.False(t, pages[0].Vitals[1].Passed)
+	assert.InDelta(t, 800, pages[0].Vitals[0].Budget, 0)
+	assert.True(t, pages[1].Vitals[1].Passed)
+
+	pages, err = Read(strings.NewReader(point("http_req_duration", home, 10)), nil)
+	require.NoError(t, err)
+	assert.Empty(t, pages)
+}
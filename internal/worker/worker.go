@@ -0,0 +1,321 @@
+// Package worker splits runs across mcp-k6 instances on several machines,
+// without Kubernetes. A worker instance serves the runs a coordinator posts
+// it over HTTP, authenticated with a token shared by both; the coordinator
+// posts each of its workers a share of the VUs of a run and merges the
+// summaries they return.
+package worker
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/mcp-k6/internal/summary"
+)
+
+// EnvToken is the environment variable holding the token coordinators and
+// workers authenticate with.
+const EnvToken = "MCP_K6_WORKER_TOKEN"
+
+const (
+	statusPath = "/v1/status"
+	runsPath   = "/v1/runs"
+	// minTokenLength rejects tokens short enough to guess.
+	minTokenLength = 16
+	// statusTimeout bounds each status request.
+	statusTimeout = 10 * time.Second
+	// maxRunBytes bounds the body of a run, its script included.
+	maxRunBytes = 10 << 20
+	// maxResultBytes bounds the body of a result, its summary included.
+	maxResultBytes = 10 << 20
+)
+
+var (
+	// ErrInvalid is returned for worker settings that cannot be used.
+	ErrInvalid = errors.New("invalid worker configuration")
+	// ErrBusy is returned by workers already running a test.
+	ErrBusy = errors.New("busy with another run")
+)
+
+// Run is the share of a run a coordinator posts a worker.
+type Run struct {
+	Script     string            `json:"script"`
+	VUs        int               `json:"vus"`
+	Duration   string            `json:"duration,omitempty"`
+	Iterations int               `json:"iterations,omitempty"`
+	Env        map[string]string `json:"env,omitempty"`
+}
+
+// Result is the outcome of a run on a worker.
+type Result struct {
+	Success  bool   `json:"success"`
+	ExitCode int    `json:"exit_code"`
+	Error    string `json:"error,omitempty"`
+	Duration string `json:"duration,omitempty"`
+	// Export is the end-of-test summary of the run, nil when k6 exported
+	// none.
+	Export *summary.Export `json:"export,omitempty"`
+}
+
+// Status describes a worker.
+type Status struct {
+	Version string `json:"version"`
+	// Busy is set while the worker runs a test.
+	Busy bool `json:"busy"`
+}
+
+// RunFunc runs the share of a run on a worker. Its errors reject the run
+// and are returned to the coordinator.
+type RunFunc func(ctx context.Context, run Run) (*Result, error)
+
+// errorBody is the body of failed requests.
+type errorBody struct {
+	Error string `json:"error"`
+}
+
+// token returns the worker token of environ.
+func token(environ []string) (string, error) {
+	var value string
+	for _, kv := range environ {
+		if k, v, ok := strings.Cut(kv, "="); ok && k == EnvToken {
+			value = strings.TrimSpace(v)
+		}
+	}
+	if len(value) < minTokenLength {
+		return "", fmt.Errorf("%w: set %s to a token of at least %d characters shared by the coordinator "+
+			"and its workers", ErrInvalid, EnvToken, minTokenLength)
+	}
+	return value, nil
+}
+
+// handler serves the worker protocol, one run at a time.
+type handler struct {
+	token   string
+	version string
+	run     RunFunc
+	mu      sync.Mutex
+	busy    bool
+}
+
+// NewHandler returns the HTTP handler of a worker running the runs posted by
+// coordinators holding the token of environ with run.
+func NewHandler(environ []string, version string, run RunFunc) (http.Handler, error) {
+	t, err := token(environ)
+	if err != nil {
+		return nil, err
+	}
+	h := &handler{token: t, version: version, run: run}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+statusPath, h.status)
+	mux.HandleFunc("POST "+runsPath, h.runs)
+	return h.authenticate(mux), nil
+}
+
+// authenticate rejects the requests not bearing the token of h.
+func (h *handler) authenticate(next http.Handler) http.Handler {
+	want := []byte("Bearer " + h.token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), want) != 1 {
+			writeJSON(w, http.StatusUnauthorized, errorBody{Error: "invalid worker token"})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (h *handler) status(w http.ResponseWriter, _ *http.Request) {
+	h.mu.Lock()
+	busy := h.busy
+	h.mu.Unlock()
+	writeJSON(w, http.StatusOK, Status{Version: h.version, Busy: busy})
+}
+
+func (h *handler) runs(w http.ResponseWriter, r *http.Request) {
+	var run Run
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRunBytes)).Decode(&run); err != nil {
+		writeJSON(w, http.StatusBadRequest, errorBody{Error: "invalid run: " + err.Error()})
+		return
+	}
+	h.mu.Lock()
+	if h.busy {
+		h.mu.Unlock()
+		writeJSON(w, http.StatusConflict, errorBody{Error: ErrBusy.Error()})
+		return
+	}
+	h.busy = true
+	h.mu.Unlock()
+	defer func() {
+		h.mu.Lock()
+		h.busy = false
+		h.mu.Unlock()
+	}()
+
+	// The run stops when the coordinator goes away
+	result, err := h.run(r.Context(), run)
+	if err != nil {
+		writeJSON(w, http.StatusUnprocessableEntity, errorBody{Error: err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// Pool is the set of workers of a coordinator. A nil *Pool has no workers.
+type Pool struct {
+	workers []*Worker
+}
+
+// Worker is a worker of a pool.
+type Worker struct {
+	url    *url.URL
+	token  string
+	client *http.Client
+}
+
+// New returns the pool of the workers at urls, authenticating with the
+// token of environ, or nil when there are no urls.
+func New(urls []string, environ []string) (*Pool, error) {
+	if len(urls) == 0 {
+		return nil, nil
+	}
+	t, err := token(environ)
+	if err != nil {
+		return nil, err
+	}
+	p := &Pool{}
+	seen := make(map[string]bool)
+	for _, raw := range urls {
+		u, err := url.Parse(strings.TrimSpace(raw))
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("%w: worker %q is not an absolute http or https URL", ErrInvalid, raw)
+		}
+		u.Path = strings.TrimSuffix(u.Path, "/")
+		if seen[u.String()] {
+			return nil, fmt.Errorf("%w: worker %s is listed twice", ErrInvalid, u.Host)
+		}
+		seen[u.String()] = true
+		p.workers = append(p.workers, &Worker{url: u, token: t, client: &http.Client{}})
+	}
+	return p, nil
+}
+
+// Workers returns the workers of p, in the order they were configured.
+func (p *Pool) Workers() []*Worker {
+	if p == nil {
+		return nil
+	}
+	return p.workers
+}
+
+// Hosts returns the hosts of the workers of p, for logging.
+func (p *Pool) Hosts() []string {
+	hosts := make([]string, 0, len(p.Workers()))
+	for _, w := range p.Workers() {
+		hosts = append(hosts, w.Host())
+	}
+	return hosts
+}
+
+// Host returns the host of w.
+func (w *Worker) Host() string {
+	return w.url.Host
+}
+
+// Encrypted reports whether w is reached over https.
+func (w *Worker) Encrypted() bool {
+	return w.url.Scheme == "https"
+}
+
+// Status returns the status of w.
+func (w *Worker) Status(ctx context.Context) (Status, error) {
+	ctx, cancel := context.WithTimeout(ctx, statusTimeout)
+	defer cancel()
+	var status Status
+	err := w.do(ctx, http.MethodGet, statusPath, nil, &status)
+	return status, err
+}
+
+// Run runs run on w and waits for its result.
+func (w *Worker) Run(ctx context.Context, run Run) (*Result, error) {
+	body, err := json.Marshal(run)
+	if err != nil {
+		return nil, fmt.Errorf("worker %s: encoding run: %w", w.Host(), err)
+	}
+	var result Result
+	if err := w.do(ctx, http.MethodPost, runsPath, body, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (w *Worker) do(ctx context.Context, method, path string, body []byte, out any) error {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, w.url.String()+path, reader)
+	if err != nil {
+		return fmt.Errorf("worker %s: %w", w.Host(), err)
+	}
+	req.Header.Set("Authorization", "Bearer "+w.token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := w.client.Do(req)
+	if err != nil {
+		var uerr *url.Error
+		if errors.As(err, &uerr) {
+			err = uerr.Err
+		}
+		return fmt.Errorf("worker %s: %w", w.Host(), err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResultBytes))
+	if err != nil {
+		return fmt.Errorf("worker %s: %w", w.Host(), err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		var e errorBody
+		if json.Unmarshal(data, &e) != nil || e.Error == "" {
+			e.Error = resp.Status
+		}
+		if resp.StatusCode == http.StatusConflict {
+			return fmt.Errorf("worker %s: %w", w.Host(), ErrBusy)
+		}
+		return fmt.Errorf("worker %s: %s", w.Host(), e.Error)
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("worker %s: reading response: %w", w.Host(), err)
+	}
+	return nil
+}
+
+// Split divides n into parts shares that differ by at most one, the larger
+// first.
+func Split(n, parts int) []int {
+	if parts <= 0 {
+		return nil
+	}
+	shares := make([]int, parts)
+	for i := range shares {
+		shares[i] = n / parts
+		if i < n%parts {
+			shares[i]++
+		}
+	}
+	return shares
+}
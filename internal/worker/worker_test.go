@@ -0,0 +1,162 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grafana/mcp-k6/internal/summary"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testToken = "0123456789abcdef"
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	p, err := New(nil, nil)
+	require.NoError(t, err)
+	assert.Nil(t, p)
+	assert.Empty(t, p.Workers())
+
+	env := []string{EnvToken + "=" + testToken}
+	tests := map[string]struct {
+		urls []string
+		env  []string
+		err  string
+	}{
+		"token":     {[]string{"http://a:6566"}, nil, EnvToken},
+		"short":     {[]string{"http://a:6566"}, []string{EnvToken + "=short"}, "at least 16 characters"},
+		"url":       {[]string{"a:6566"}, env, "absolute http or https URL"},
+		"duplicate": {[]string{"http://a:6566", "http://a:6566/"}, env, "listed twice"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			_, err := New(tt.urls, tt.env)
+			require.ErrorIs(t, err, ErrInvalid)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+
+	p, err = New([]string{"http://a:6566/", "https://b.example.com"}, env)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a:6566", "b.example.com"}, p.Hosts())
+	assert.False(t, p.Workers()[0].Encrypted())
+	assert.True(t, p.Workers()[1].Encrypted())
+}
+
+func TestNewHandlerRequiresToken(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewHandler(nil, "v1", nil)
+	require.ErrorIs(t, err, ErrInvalid)
+}
+
+// testWorker returns a worker of a server running runs with run.
+func testWorker(t *testing.T, token string, run RunFunc) *Worker {
+	t.Helper()
+	h, err := NewHandler([]string{EnvToken + "=" + testToken}, "v1.2.3", run)
+	require.NoError(t, err)
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+	p, err := New([]string{srv.URL}, []string{EnvToken + "=" + token})
+	require.NoError(t, err)
+	return p.Workers()[0]
+}
+
+func TestWorkerRun(t *testing.T) {
+	t.Parallel()
+
+	w := testWorker(t, testToken, func(_ context.Context, run Run) (*Result, error) {
+		assert.Equal(t, Run{Script: "export default function () {}", VUs: 3, Duration: "10s"}, run)
+		return &Result{
+			Success: true,
+			Export: &summary.Export{Metrics: map[string]map[string]json.RawMessage{
+				"iterations": {"count": json.RawMessage("12")},
+			}},
+		}, nil
+	})
+
+	status, err := w.Status(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, Status{Version: "v1.2.3"}, status)
+
+	result, err := w.Run(context.Background(), Run{Script: "export default function () {}", VUs: 3, Duration: "10s"})
+	require.NoError(t, err)
+	assert.True(t, result.Success)
+	assert.Equal(t, map[string]float64{"count": 12}, result.Export.Values()["iterations"])
+}
+
+func TestWorkerRejectsRuns(t *testing.T) {
+	t.Parallel()
+
+	w := testWorker(t, testToken, func(context.Context, Run) (*Result, error) {
+		return nil, errors.New("vus cannot exceed 50")
+	})
+	_, err := w.Run(context.Background(), Run{VUs: 60})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "vus cannot exceed 50")
+
+	w = testWorker(t, "fedcba9876543210", nil)
+	_, err = w.Status(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid worker token")
+}
+
+func TestWorkerBusy(t *testing.T) {
+	t.Parallel()
+
+	started, release := make(chan struct{}), make(chan struct{})
+	w := testWorker(t, testToken, func(context.Context, Run) (*Result, error) {
+		close(started)
+		<-release
+		return &Result{Success: true}, nil
+	})
+
+	done := make(chan error)
+	go func() {
+		_, err := w.Run(context.Background(), Run{VUs: 1})
+		done <- err
+	}()
+	<-started
+
+	status, err := w.Status(context.Background())
+	require.NoError(t, err)
+	assert.True(t, status.Busy)
+	_, err = w.Run(context.Background(), Run{VUs: 1})
+	require.ErrorIs(t, err, ErrBusy)
+
+	close(release)
+	require.NoError(t, <-done)
+}
+
+func TestSplit(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, []int{4, 3, 3}, Split(10, 3))
+	assert.Equal(t, []int{1, 1}, Split(2, 2))
+	assert.Equal(t, []int{0, 0}, Split(0, 2))
+	assert.Nil(t, Split(5, 0))
+}
+
+func TestHandlerRejectsInvalidRuns(t *testing.T) {
+	t.Parallel()
+
+	h, err := NewHandler([]string{EnvToken + "=" + testToken}, "v1", nil)
+	require.NoError(t, err)
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, srv.URL+runsPath, nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	resp, err := srv.Client().Do(req)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
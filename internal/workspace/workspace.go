@@ -0,0 +1,264 @@
+// Package workspace resolves file paths against the directories an MCP client
+// has granted the server access to (MCP roots), plus any roots configured on
+// the command line.
+package workspace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+var (
+	// ErrNoRoots is returned when neither the client nor the configuration granted any directory.
+	ErrNoRoots = errors.New(
+		"no workspace roots available; the client must expose roots or the server must be started with --root",
+	)
+	// ErrOutsideRoots is returned when a path resolves outside every workspace root.
+	ErrOutsideRoots = errors.New("path is outside the workspace roots")
+	// ErrFileTooLarge is returned when a file exceeds the requested size limit.
+	ErrFileTooLarge = errors.New("file exceeds the maximum allowed size")
+	// ErrFileExists is returned by WriteFile when the target exists and overwriting was not requested.
+	ErrFileExists = errors.New("file already exists")
+)
+
+// RootsLister requests the list of roots from the connected client.
+// *server.MCPServer satisfies this interface.
+type RootsLister interface {
+	RequestRoots(ctx context.Context, request mcp.ListRootsRequest) (*mcp.ListRootsResult, error)
+}
+
+// Workspace resolves and reads files inside the granted roots.
+type Workspace struct {
+	lister RootsLister
+	static []string
+}
+
+// New returns a Workspace that combines the client's roots (via lister, which
+// may be nil) with the given statically configured directories.
+func New(lister RootsLister, roots ...string) *Workspace {
+	static := make([]string, 0, len(roots))
+	for _, r := range roots {
+		if r = strings.TrimSpace(r); r == "" {
+			continue
+		}
+		if abs, err := filepath.Abs(r); err == nil {
+			static = append(static, abs)
+		}
+	}
+	return &Workspace{lister: lister, static: static}
+}
+
+// Roots returns the absolute directories currently available. Client roots are
+// requested on every call because clients may change them during a session.
+// A client that does not support roots is not an error.
+func (w *Workspace) Roots(ctx context.Context) []string {
+	roots := append([]string(nil), w.static...)
+	if w.lister != nil {
+		if res, err := w.lister.RequestRoots(ctx, mcp.ListRootsRequest{}); err == nil && res != nil {
+			for _, r := range res.Roots {
+				if p, ok := pathFromURI(r.URI); ok {
+					roots = append(roots, p)
+				}
+			}
+		}
+	}
+
+	seen := make(map[string]bool, len(roots))
+	unique := roots[:0]
+	for _, r := range roots {
+		if !seen[r] {
+			seen[r] = true
+			unique = append(unique, r)
+		}
+	}
+	return unique
+}
+
+// Resolve returns the absolute, symlink-free form of p and checks that it lies
+// inside one of the workspace roots. Relative paths are tried against each root
+// in order; the first one that exists wins, otherwise the first root is used.
+// The target itself does not need to exist.
+func (w *Workspace) Resolve(ctx context.Context, p string) (string, error) {
+	if strings.TrimSpace(p) == "" {
+		return "", errors.New("path cannot be empty")
+	}
+	roots := w.Roots(ctx)
+	if len(roots) == 0 {
+		return "", ErrNoRoots
+	}
+
+	candidates := []string{p}
+	if !filepath.IsAbs(p) {
+		candidates = candidates[:0]
+		for _, r := range roots {
+			candidates = append(candidates, filepath.Join(r, p))
+		}
+	}
+
+	chosen := candidates[0]
+	for _, c := range candidates {
+		//nolint:forbidigo // Checking for existing files inside the granted roots.
+		if _, err := os.Stat(c); err == nil {
+			chosen = c
+			break
+		}
+	}
+
+	resolved, err := evalExisting(filepath.Clean(chosen))
+	if err != nil {
+		return "", err
+	}
+	for _, r := range roots {
+		root, err := evalExisting(r)
+		if err != nil {
+			continue
+		}
+		if within(root, resolved) {
+			return resolved, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %s", ErrOutsideRoots, p)
+}
+
+// ReadFile resolves p inside the workspace and returns its content together
+// with the resolved path. Files larger than maxSize bytes are rejected.
+func (w *Workspace) ReadFile(ctx context.Context, p string, maxSize int64) ([]byte, string, error) {
+	resolved, err := w.Resolve(ctx, p)
+	if err != nil {
+		return nil, "", err
+	}
+
+	//nolint:forbidigo // Reading files the client granted access to.
+	f, err := os.Open(resolved) // #nosec G304 -- path is confined to the workspace roots
+	if err != nil {
+		return nil, "", fmt.Errorf("opening %s: %w", p, err)
+	}
+	defer func() { _ = f.Close() }()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, "", fmt.Errorf("reading %s: %w", p, err)
+	}
+	if info.IsDir() {
+		return nil, "", fmt.Errorf("%s is a directory", p)
+	}
+	if info.Size() > maxSize {
+		return nil, "", fmt.Errorf("%w: %s is %d bytes (limit %d)", ErrFileTooLarge, p, info.Size(), maxSize)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(f, maxSize+1))
+	if err != nil {
+		return nil, "", fmt.Errorf("reading %s: %w", p, err)
+	}
+	if int64(len(data)) > maxSize {
+		return nil, "", fmt.Errorf("%w: %s (limit %d)", ErrFileTooLarge, p, maxSize)
+	}
+	return data, resolved, nil
+}
+
+// pathFromURI converts a file:// root URI to a local path.
+func pathFromURI(uri string) (string, bool) {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" || u.Path == "" {
+		return "", false
+	}
+	return filepath.Clean(filepath.FromSlash(u.Path)), true
+}
+
+// evalExisting resolves symlinks in the longest existing prefix of p so that
+// paths to files that do not exist yet can still be checked against the roots.
+func evalExisting(p string) (string, error) {
+	var missing []string
+	current := p
+	for {
+		resolved, err := filepath.EvalSymlinks(current)
+		if err == nil {
+			for i := len(missing) - 1; i >= 0; i-- {
+				resolved = filepath.Join(resolved, missing[i])
+			}
+			return resolved, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return "", fmt.Errorf("resolving %s: %w", p, err)
+		}
+		parent := filepath.Dir(current)
+		if parent == current {
+			return p, nil
+		}
+		missing = append(missing, filepath.Base(current))
+		current = parent
+	}
+}
+
+func within(root, p string) bool {
+	rel, err := filepath.Rel(root, p)
+	if err != nil {
+		return false
+	}
+	return rel == "." || (rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)))
+}
+
+// WriteFile resolves p inside the workspace and writes data to it, creating
+// missing parent directories. The content is written to a temporary file in
+// the target directory and renamed into place so readers never observe a
+// partially written file. It reports whether the file was newly created.
+func (w *Workspace) WriteFile(ctx context.Context, p string, data []byte, overwrite bool) (string, bool, error) {
+	resolved, err := w.Resolve(ctx, p)
+	if err != nil {
+		return "", false, err
+	}
+
+	created := true
+	//nolint:forbidigo // Checking the write target inside the granted roots.
+	if info, err := os.Stat(resolved); err == nil {
+		if info.IsDir() {
+			return "", false, fmt.Errorf("%s is a directory", p)
+		}
+		if !overwrite {
+			return "", false, fmt.Errorf("%w: %s", ErrFileExists, p)
+		}
+		created = false
+	}
+
+	const dirMode, fileMode = 0o750, 0o644
+	dir := filepath.Dir(resolved)
+	//nolint:forbidigo // Creating directories inside the granted roots.
+	if err := os.MkdirAll(dir, dirMode); err != nil {
+		return "", false, fmt.Errorf("creating directory for %s: %w", p, err)
+	}
+
+	//nolint:forbidigo // Staging the write next to the target for an atomic rename.
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(resolved)+".*.tmp")
+	if err != nil {
+		return "", false, fmt.Errorf("writing %s: %w", p, err)
+	}
+	tmpName := tmp.Name()
+	//nolint:forbidigo // Removing the staged file if the rename did not happen.
+	defer func() { _ = os.Remove(tmpName) }()
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return "", false, fmt.Errorf("writing %s: %w", p, err)
+	}
+	if err := tmp.Chmod(fileMode); err != nil {
+		_ = tmp.Close()
+		return "", false, fmt.Errorf("writing %s: %w", p, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return "", false, fmt.Errorf("writing %s: %w", p, err)
+	}
+	//nolint:forbidigo // Moving the staged file into place.
+	if err := os.Rename(tmpName, resolved); err != nil {
+		return "", false, fmt.Errorf("writing %s: %w", p, err)
+	}
+	return resolved, created, nil
+}
@@ -0,0 +1,177 @@
+package workspace
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type staticLister struct {
+	roots []mcp.Root
+	err   error
+}
+
+func (l staticLister) RequestRoots(context.Context, mcp.ListRootsRequest) (*mcp.ListRootsResult, error) {
+	if l.err != nil {
+		return nil, l.err
+	}
+	return &mcp.ListRootsResult{Roots: l.roots}, nil
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o750))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+}
+
+func TestWorkspaceRoots(t *testing.T) {
+	t.Parallel()
+
+	static := t.TempDir()
+	client := t.TempDir()
+
+	ws := New(staticLister{roots: []mcp.Root{
+		{URI: "file://" + filepath.ToSlash(client)},
+		{URI: "file://" + filepath.ToSlash(static)},
+		{URI: "https://example.com/not-a-file-root"},
+	}}, static)
+	assert.Equal(t, []string{static, client}, ws.Roots(context.Background()))
+
+	unsupported := New(staticLister{err: errors.New("session does not support roots")}, static)
+	assert.Equal(t, []string{static}, unsupported.Roots(context.Background()))
+}
+
+func TestWorkspaceResolve(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	outside := t.TempDir()
+	writeFile(t, filepath.Join(root, "scripts", "test.js"), "export default function () {}")
+	writeFile(t, filepath.Join(outside, "secret.txt"), "secret")
+	require.NoError(t, os.Symlink(outside, filepath.Join(root, "escape")))
+
+	ws := New(nil, root)
+	ctx := context.Background()
+
+	resolvedRoot, err := filepath.EvalSymlinks(root)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		path    string
+		want    string
+		wantErr error
+	}{
+		{name: "relative", path: "scripts/test.js", want: filepath.Join(resolvedRoot, "scripts", "test.js")},
+		{name: "absolute", path: filepath.Join(root, "scripts", "test.js"), want: filepath.Join(resolvedRoot, "scripts", "test.js")},
+		{name: "missing file inside root", path: "new/script.js", want: filepath.Join(resolvedRoot, "new", "script.js")},
+		{name: "parent traversal", path: "../secret.txt", wantErr: ErrOutsideRoots},
+		{name: "absolute outside", path: filepath.Join(outside, "secret.txt"), wantErr: ErrOutsideRoots},
+		{name: "symlink escape", path: "escape/secret.txt", wantErr: ErrOutsideRoots},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := ws.Resolve(ctx, tt.path)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestWorkspaceResolveWithoutRoots(t *testing.T) {
+	t.Parallel()
+
+	_, err := New(nil).Resolve(context.Background(), "test.js")
+	require.ErrorIs(t, err, ErrNoRoots)
+}
+
+func TestWorkspaceReadFile(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "data.csv"), "user,password\nalice,secret\n")
+	ws := New(nil, root)
+
+	data, resolved, err := ws.ReadFile(context.Background(), "data.csv", 1024)
+	require.NoError(t, err)
+	assert.Equal(t, "user,password\nalice,secret\n", string(data))
+	assert.Equal(t, "data.csv", filepath.Base(resolved))
+
+	_, _, err = ws.ReadFile(context.Background(), "data.csv", 4)
+	require.ErrorIs(t, err, ErrFileTooLarge)
+
+	_, _, err = ws.ReadFile(context.Background(), ".", 1024)
+	require.Error(t, err)
+}
+
+func TestParseEnv(t *testing.T) {
+	t.Parallel()
+
+	env, err := ParseEnv([]byte(`
+# comment
+BASE_URL=https://test.k6.io
+export API_KEY="abc\n123"
+RAW='$not $expanded'
+EMPTY=
+TRAILING=value # comment
+`))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"BASE_URL": "https://test.k6.io",
+		"API_KEY":  "abc\n123",
+		"RAW":      "$not $expanded",
+		"EMPTY":    "",
+		"TRAILING": "value",
+	}, env)
+
+	_, err = ParseEnv([]byte("not a pair"))
+	require.Error(t, err)
+
+	_, err = ParseEnv([]byte("1BAD=value"))
+	require.Error(t, err)
+}
+
+func TestWorkspaceWriteFile(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	ws := New(nil, root)
+	ctx := context.Background()
+
+	resolved, created, err := ws.WriteFile(ctx, "tests/load.js", []byte("v1"), false)
+	require.NoError(t, err)
+	assert.True(t, created)
+	data, err := os.ReadFile(resolved)
+	require.NoError(t, err)
+	assert.Equal(t, "v1", string(data))
+
+	_, _, err = ws.WriteFile(ctx, "tests/load.js", []byte("v2"), false)
+	require.ErrorIs(t, err, ErrFileExists)
+
+	_, created, err = ws.WriteFile(ctx, "tests/load.js", []byte("v2"), true)
+	require.NoError(t, err)
+	assert.False(t, created)
+	data, err = os.ReadFile(resolved)
+	require.NoError(t, err)
+	assert.Equal(t, "v2", string(data))
+
+	entries, err := os.ReadDir(filepath.Dir(resolved))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	_, _, err = ws.WriteFile(ctx, "../outside.js", []byte("x"), false)
+	require.ErrorIs(t, err, ErrOutsideRoots)
+}
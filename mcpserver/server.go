@@ -7,12 +7,36 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"net/http"
 	"os"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/mark3labs/mcp-go/server"
 
+	"github.com/grafana/mcp-k6/internal/annotate"
+	"github.com/grafana/mcp-k6/internal/approval"
+	"github.com/grafana/mcp-k6/internal/audit"
 	"github.com/grafana/mcp-k6/internal/buildinfo"
+	"github.com/grafana/mcp-k6/internal/doclinks"
+	"github.com/grafana/mcp-k6/internal/importpolicy"
+	"github.com/grafana/mcp-k6/internal/jslib"
 	"github.com/grafana/mcp-k6/internal/k6env"
+	"github.com/grafana/mcp-k6/internal/logging"
+	"github.com/grafana/mcp-k6/internal/observe"
+	"github.com/grafana/mcp-k6/internal/ownership"
+	"github.com/grafana/mcp-k6/internal/redact"
+	"github.com/grafana/mcp-k6/internal/secrets"
+	"github.com/grafana/mcp-k6/internal/slo"
+	"github.com/grafana/mcp-k6/internal/sshexec"
+	"github.com/grafana/mcp-k6/internal/targetpolicy"
+	"github.com/grafana/mcp-k6/internal/telemetry"
+	"github.com/grafana/mcp-k6/internal/truncate"
+	"github.com/grafana/mcp-k6/internal/usage"
+	"github.com/grafana/mcp-k6/internal/webhook"
+	"github.com/grafana/mcp-k6/internal/worker"
+	"github.com/grafana/mcp-k6/internal/workspace"
 	"github.com/grafana/mcp-k6/prompts"
 	"github.com/grafana/mcp-k6/resources"
 	"github.com/grafana/mcp-k6/tools"
@@ -26,15 +50,50 @@ for searching for k6 Cloud-related Terraform resources in the Grafana Terraform
 Use the provided resources for understanding the k6 script authoring best practices.
 List the resources at least once before trying to access one of them.
 Use the provided prompts as a good starting point for authoring complex k6 scripts.
+When the client shares workspace roots, pass script_path (and env_file) instead of inlining
+script content so that data files and local modules resolve next to the script.
 `
 
 // Config holds the MCP server configuration.
 type Config struct {
-	Transport string // "stdio" or "http" (default: "stdio")
-	Addr      string // HTTP listen address (default: ":8080")
-	Endpoint  string // HTTP endpoint path (default: "/mcp")
-	Stateless bool   // Stateless mode for HTTP
-	Preload   bool   // Download all doc bundles at startup
+	Transport string   // "stdio" or "http" (default: "stdio")
+	Addr      string   // HTTP listen address (default: ":8080")
+	Endpoint  string   // HTTP endpoint path (default: "/mcp")
+	Stateless bool     // Stateless mode for HTTP
+	Preload   bool     // Download all doc bundles at startup
+	Roots     []string // Directories tools may read from, in addition to client-provided roots
+	Write     bool     // Enable the write_script tool
+
+	RedactHeaders  []string // Extra header names masked in captured HTTP traffic
+	RedactPatterns []string // Extra regular expressions masked in captured HTTP traffic
+	SecretsFile    string   // .env file of secrets, in addition to MCP_K6_SECRET_* variables
+	RemoteImports  string   // "allow" or "deny" remote module imports (default: "allow")
+	ImportHosts    []string // Hosts remote modules may be imported from; empty allows any host
+	AllowTargets   []string // Hosts scripts may send requests to; empty allows any host
+	DenyTargets    []string // Hosts scripts may never send requests to
+	JSLibDir       string   // Offline jslib mirror served to inline scripts
+	VendorJSLib    bool     // Download the common jslib modules into JSLibDir and exit
+	CheckDocLinks  bool     // Check the links of every documentation version and exit
+	Webhooks       []string // URLs notified with a JSON payload when background or scheduled runs end
+	SLOFile        string   // JSON file of SLOs defined at startup
+	ObserveFile    string   // JSON file of the datasources and queries observed over each run
+	AuditLog       string   // JSON Lines file every spawned command is appended to
+	WorkerAddr     string   // Serve the runs of coordinators on this address instead of MCP; empty disables
+	Workers        []string // URLs of the workers run_on_workers splits runs across
+	RemoteHosts    []string // Hosts run_remote may run tests on over SSH; empty disables run_remote
+	RemoteKeyDir   string   // Directory of the private keys run_remote may log in with
+	RemoteK6       string   // k6 executable run_remote runs on the hosts (default: k6 in their PATH)
+
+	ConfirmVUs      int           // Runs above this many VUs need confirmation; 0 disables
+	ConfirmDuration time.Duration // Runs longer than this need confirmation; 0 disables
+
+	VerifyToken    string // Token target hosts must publish before load above VerifyAboveVUs; empty disables
+	VerifyAboveVUs int    // VUs a run may start without verifying target ownership (default: 1)
+
+	MaxResponseBytes  int      // Tool responses are truncated to this many bytes; 0 disables
+	ToolResponseBytes []string // "tool=bytes" limits overriding MaxResponseBytes for one tool
+
+	DocsCacheSize int // Documentation tool responses kept in memory; 0 disables
 }
 
 // DefaultConfig returns a Config with default values.
@@ -43,6 +102,9 @@ func DefaultConfig() Config {
 		Transport: "stdio",
 		Addr:      ":8080",
 		Endpoint:  "/mcp",
+
+		VerifyAboveVUs: 1,
+		DocsCacheSize:  tools.DefaultDocsCacheSize,
 	}
 }
 
@@ -89,6 +151,13 @@ func Run(ctx context.Context, logger *slog.Logger, stderr io.Writer, cfg Config,
 		return 1
 	}
 
+	if cfg.VendorJSLib {
+		return vendorJSLib(ctx, logger, stderr, cfg.JSLibDir)
+	}
+	if cfg.CheckDocLinks {
+		return checkDocLinks(ctx, logger, stderr, docs.NewCatalog())
+	}
+
 	logger.Info("Starting k6 MCP server",
 		slog.String("version", buildinfo.Version),
 		slog.String("commit", buildinfo.Commit),
@@ -96,6 +165,177 @@ func Run(ctx context.Context, logger *slog.Logger, stderr io.Writer, cfg Config,
 		slog.Bool("resource_capabilities", true),
 	)
 
+	rd, err := redact.New(cfg.RedactHeaders, cfg.RedactPatterns)
+	if err != nil {
+		logger.Error("Invalid redaction configuration", slog.String("error", err.Error()))
+		_, _ = fmt.Fprintf(stderr, "invalid redaction configuration: %v\n", err)
+		return 1
+	}
+
+	//nolint:forbidigo // Secrets are read from the server's own environment.
+	reg, err := secrets.Load(os.Environ(), cfg.SecretsFile)
+	if err != nil {
+		logger.Error("Invalid secrets configuration", slog.String("error", err.Error()))
+		_, _ = fmt.Fprintf(stderr, "invalid secrets configuration: %v\n", err)
+		return 1
+	}
+	if !reg.Empty() {
+		logger = slog.New(reg.Handler(logger.Handler()))
+		logging.SetDefault(slog.New(reg.Handler(logging.Default().Handler())))
+		logger.Info("Loaded secrets", slog.Int("count", len(reg.Names())))
+	}
+
+	ip, err := importpolicy.New(cfg.RemoteImports, cfg.ImportHosts)
+	if err != nil {
+		logger.Error("Invalid import policy", slog.String("error", err.Error()))
+		_, _ = fmt.Fprintf(stderr, "invalid import policy: %v\n", err)
+		return 1
+	}
+	if ip.Restricted() {
+		logger.Info("Import policy configured", slog.String("policy", ip.String()))
+	}
+
+	tp, err := targetpolicy.New(cfg.AllowTargets, cfg.DenyTargets)
+	if err != nil {
+		logger.Error("Invalid target policy", slog.String("error", err.Error()))
+		_, _ = fmt.Fprintf(stderr, "invalid target policy: %v\n", err)
+		return 1
+	}
+	if tp.Restricted() {
+		logger.Info("Target policy configured", slog.String("policy", tp.String()))
+	}
+
+	notifier, err := webhook.New(cfg.Webhooks)
+	if err != nil {
+		logger.Error("Invalid webhook configuration", slog.String("error", err.Error()))
+		_, _ = fmt.Fprintf(stderr, "invalid webhook configuration: %v\n", err)
+		return 1
+	}
+	if len(cfg.Webhooks) > 0 {
+		logger.Info("Run notifications configured", slog.Any("hosts", notifier.Hosts()))
+	}
+
+	//nolint:forbidigo // Datasource tokens are read from the server's own environment.
+	observer, err := observe.Load(cfg.ObserveFile, os.Environ())
+	if err != nil {
+		logger.Error("Invalid observe configuration", slog.String("error", err.Error()))
+		_, _ = fmt.Fprintf(stderr, "invalid observe configuration: %v\n", err)
+		return 1
+	}
+	if observer != nil {
+		logger.Info("Run observations configured", slog.Any("queries", observer.Queries()))
+	}
+
+	//nolint:forbidigo // Annotations are configured from the server's own environment.
+	annotator, err := annotate.New(os.Environ())
+	if err != nil {
+		logger.Error("Invalid annotation configuration", slog.String("error", err.Error()))
+		_, _ = fmt.Fprintf(stderr, "invalid annotation configuration: %v\n", err)
+		return 1
+	}
+	if annotator != nil {
+		logger.Info("Run annotations configured", slog.String("host", annotator.Host()))
+	}
+
+	//nolint:forbidigo // Telemetry is configured from the server's own environment.
+	rec, err := telemetry.New(os.Environ(), buildinfo.Version)
+	if err != nil {
+		logger.Error("Invalid telemetry configuration", slog.String("error", err.Error()))
+		_, _ = fmt.Fprintf(stderr, "invalid telemetry configuration: %v\n", err)
+		return 1
+	}
+
+	objectives, err := loadSLOs(cfg.SLOFile)
+	if err != nil {
+		logger.Error("Invalid SLO configuration", slog.String("error", err.Error()))
+		_, _ = fmt.Fprintf(stderr, "invalid SLO configuration: %v\n", err)
+		return 1
+	}
+	if cfg.SLOFile != "" {
+		logger.Info("Loaded SLOs", slog.Int("count", len(objectives.List())))
+	}
+
+	gate, err := approval.New(cfg.ConfirmVUs, cfg.ConfirmDuration)
+	if err != nil {
+		logger.Error("Invalid confirmation policy", slog.String("error", err.Error()))
+		_, _ = fmt.Fprintf(stderr, "invalid confirmation policy: %v\n", err)
+		return 1
+	}
+	if gate != nil {
+		logger.Info("Run confirmation configured", slog.String("policy", gate.String()))
+	}
+
+	ov, err := ownership.New(cfg.VerifyToken, cfg.VerifyAboveVUs)
+	if err != nil {
+		logger.Error("Invalid ownership verification", slog.String("error", err.Error()))
+		_, _ = fmt.Fprintf(stderr, "invalid ownership verification: %v\n", err)
+		return 1
+	}
+	if ov != nil {
+		logger.Info("Target ownership verification configured", slog.Int("above_vus", ov.AboveVUs()))
+	}
+
+	//nolint:forbidigo // The worker token is read from the server's own environment.
+	pool, err := worker.New(cfg.Workers, os.Environ())
+	if err != nil {
+		logger.Error("Invalid worker configuration", slog.String("error", err.Error()))
+		_, _ = fmt.Fprintf(stderr, "invalid worker configuration: %v\n", err)
+		return 1
+	}
+	if pool != nil {
+		logger.Info("Workers registered", slog.Any("hosts", pool.Hosts()))
+	}
+
+	remotes, err := sshexec.NewAllowlist(cfg.RemoteHosts, cfg.RemoteKeyDir, cfg.RemoteK6)
+	if err != nil {
+		logger.Error("Invalid remote hosts", slog.String("error", err.Error()))
+		_, _ = fmt.Fprintf(stderr, "invalid remote hosts: %v\n", err)
+		return 1
+	}
+	if remotes != nil {
+		logger.Info("Remote hosts configured", slog.Any("hosts", remotes.Hosts()), slog.String("k6", remotes.K6()))
+	}
+
+	limits, err := truncate.New(cfg.MaxResponseBytes, cfg.ToolResponseBytes)
+	if err != nil {
+		logger.Error("Invalid response limits", slog.String("error", err.Error()))
+		_, _ = fmt.Fprintf(stderr, "invalid response limits: %v\n", err)
+		return 1
+	}
+
+	var auditLog *audit.Log
+	if cfg.AuditLog != "" {
+		if auditLog, err = audit.Open(cfg.AuditLog); err != nil {
+			logger.Error("Invalid audit log configuration", slog.String("error", err.Error()))
+			_, _ = fmt.Fprintf(stderr, "invalid audit log configuration: %v\n", err)
+			return 1
+		}
+		defer func() { _ = auditLog.Close() }()
+		logger.Info("Auditing spawned commands", slog.String("file", cfg.AuditLog))
+	}
+
+	var mirror *jslib.Mirror
+	if cfg.JSLibDir != "" {
+		if mirror, err = jslib.Serve(cfg.JSLibDir); err != nil {
+			logger.Error("Invalid jslib mirror", slog.String("error", err.Error()))
+			_, _ = fmt.Fprintf(stderr, "invalid jslib mirror: %v\n", err)
+			return 1
+		}
+		defer func() { _ = mirror.Close() }()
+		logger.Info("Serving offline jslib mirror", slog.String("url", mirror.URL()))
+	}
+
+	var workerHandler http.Handler
+	if cfg.WorkerAddr != "" {
+		run := workerRunFunc(logger, tools.WorkerRunFunc(ip, tp, mirror), auditLog)
+		//nolint:forbidigo // The worker token is read from the server's own environment.
+		if workerHandler, err = worker.NewHandler(os.Environ(), buildinfo.Version, run); err != nil {
+			logger.Error("Invalid worker configuration", slog.String("error", err.Error()))
+			_, _ = fmt.Fprintf(stderr, "invalid worker configuration: %v\n", err)
+			return 1
+		}
+	}
+
 	k6Info, err := k6env.Locate(ctx)
 	if err != nil {
 		return handleK6LookupError(logger, stderr, err)
@@ -103,13 +343,29 @@ func Run(ctx context.Context, logger *slog.Logger, stderr io.Writer, cfg Config,
 
 	logger.Info("Detected k6 executable", slog.String("path", k6Info.Path))
 
+	if workerHandler != nil {
+		return serveWorker(ctx, logger, stderr, cfg.WorkerAddr, workerHandler)
+	}
+
 	catalog := docs.NewCatalog()
 
 	if cfg.Preload {
 		preloadBundles(ctx, logger, catalog)
 	}
 
-	s := createServer(catalog)
+	if rec != nil {
+		logger.Info("Pushing telemetry", slog.String("host", rec.Host()))
+		rec.Start(logger)
+		defer closeTelemetry(logger, rec)
+	}
+
+	runs := tools.NewRuns(notifier, observer, annotator)
+	defer runs.Close()
+	schedules := tools.NewSchedules(runs)
+	defer schedules.Close()
+
+	s := createServer(catalog, cfg, rd, reg, ip, tp, mirror, runs, schedules, objectives, rec, usage.New(), auditLog,
+		gate, ov, limits, pool, remotes)
 
 	if cfg.Transport == "http" {
 		return r.serveHTTP(logger, stderr, s, cfg)
@@ -150,45 +406,285 @@ func (r *runner) serveHTTP(logger *slog.Logger, stderr io.Writer, s *server.MCPS
 	return 0
 }
 
-func createServer(catalog *docs.Catalog) *server.MCPServer {
+// serveWorker serves the runs coordinators post on addr with handler, in
+// place of the MCP server, until ctx is done.
+func serveWorker(ctx context.Context, logger *slog.Logger, stderr io.Writer, addr string, handler http.Handler) int {
+	srv := &http.Server{Addr: addr, Handler: handler, ReadHeaderTimeout: 10 * time.Second}
+	stop := context.AfterFunc(ctx, func() { _ = srv.Shutdown(context.Background()) })
+	defer stop()
+
+	logger.Info("Serving runs as a worker", slog.String("addr", addr))
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		logger.Error("Worker error", slog.String("error", err.Error()))
+		_, _ = fmt.Fprintf(stderr, "worker exited with error: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// workerRunFunc logs the runs of a worker, and records the commands they
+// spawn in auditLog.
+func workerRunFunc(logger *slog.Logger, run worker.RunFunc, auditLog *audit.Log) worker.RunFunc {
+	return func(ctx context.Context, r worker.Run) (*worker.Result, error) {
+		ctx = logging.ContextWithLogger(ctx, logger)
+		if auditLog != nil {
+			ctx = audit.ContextWithLog(ctx, auditLog, audit.Caller{Tool: "worker"})
+		}
+		logger.Info("Worker run started",
+			slog.Int("vus", r.VUs),
+			slog.String("duration", r.Duration),
+			slog.Int("iterations", r.Iterations))
+		result, err := run(ctx, r)
+		if err != nil {
+			logger.Warn("Worker run rejected", slog.String("error", err.Error()))
+			return nil, err
+		}
+		logger.Info("Worker run ended", slog.Bool("success", result.Success), slog.String("duration", result.Duration))
+		return result, nil
+	}
+}
+
+func createServer(
+	catalog *docs.Catalog,
+	cfg Config,
+	rd *redact.Redactor,
+	reg *secrets.Registry,
+	ip *importpolicy.Policy,
+	tp *targetpolicy.Policy,
+	mirror *jslib.Mirror,
+	runs *tools.Runs,
+	schedules *tools.Schedules,
+	objectives *slo.Registry,
+	rec *telemetry.Recorder,
+	stats *usage.Stats,
+	auditLog *audit.Log,
+	gate *approval.Gate,
+	ov *ownership.Verifier,
+	limits *truncate.Limits,
+	pool *worker.Pool,
+	remotes *sshexec.Allowlist,
+) *server.MCPServer {
+	serverInstructions := instructions
+	if names := reg.Names(); len(names) > 0 {
+		serverInstructions += "Secrets available to run_script and plan_run by name: " +
+			strings.Join(names, ", ") + ".\n"
+	}
+	if ip.Restricted() {
+		serverInstructions += "Import policy: " + ip.String() +
+			"; prefer local modules over remote imports.\n"
+	}
+	if ov != nil {
+		serverInstructions += fmt.Sprintf("Runs above %d VUs must target hosts publishing the ownership token; "+
+			"a rejected run explains how to publish it.\n", ov.AboveVUs())
+	}
+	if tp.Restricted() {
+		serverInstructions += "Target policy: " + tp.String() + "; scripts sending requests elsewhere are rejected.\n"
+	}
+
+	calls := tools.NewCancellations()
+	hooks := &server.Hooks{}
+	hooks.AddBeforeCallTool(calls.BeforeCallTool)
+
 	s := server.NewMCPServer(
 		"k6",
 		buildinfo.Version,
 		server.WithResourceCapabilities(true, true),
 		server.WithLogging(),
 		server.WithRecovery(),
-		server.WithInstructions(instructions),
+		server.WithInstructions(serverInstructions),
+		server.WithRoots(),
+		server.WithHooks(hooks),
+		server.WithToolHandlerMiddleware(tools.CancelOnRequest(calls)),
+		// Truncate outside of ScrubSecrets, which could no longer match a
+		// secret cut in half
+		server.WithToolHandlerMiddleware(tools.TruncateResponses(limits)),
+		server.WithToolHandlerMiddleware(tools.ScrubSecrets(reg)),
+		server.WithToolHandlerMiddleware(tools.RecordTelemetry(rec)),
+		server.WithToolHandlerMiddleware(tools.RecordUsage(stats)),
+		server.WithToolHandlerMiddleware(tools.AuditCommands(auditLog)),
+		// Cache inside every other middleware, so calls served from cache
+		// are still counted, masked and truncated
+		server.WithToolHandlerMiddleware(tools.CacheDocs(tools.NewDocsCache(cfg.DocsCacheSize, catalog))),
 	)
+	s.AddNotificationHandler(tools.CancelledNotification, calls.HandleCancelled)
+
+	ws := workspace.New(s, cfg.Roots...)
 
 	tools.RegisterInfoTool(s)
-	tools.RegisterValidateTool(s)
-	tools.RegisterRunTool(s)
+	tools.RegisterServerStatsTool(s, stats)
+	tools.RegisterValidateTool(s, ws, ip, tp, mirror)
+	tools.RegisterRunTool(s, ws, rd, reg, ip, tp, mirror, runs, objectives, gate, ov)
+	tools.RegisterRunControlTools(s, ws, runs, gate, ov)
+	tools.RegisterGetRunSamplesTool(s, runs)
+	tools.RegisterAnalyzeRunTool(s, runs)
+	tools.RegisterFindCapacityTool(s, ws, rd, reg, ip, tp, mirror, ov, gate)
+	tools.RegisterRunSuiteTool(s, ws, rd, ip, tp, mirror, gate, ov)
+	tools.RegisterScheduleTools(s, ws, rd, reg, ip, tp, mirror, schedules, objectives, gate, ov)
+	tools.RegisterRunDistributedTool(s, ws, ip, tp, gate, ov)
+	tools.RegisterRunOnWorkersTool(s, ws, ip, tp, gate, ov, pool)
+	tools.RegisterRunRemoteTool(s, ws, ip, tp, gate, ov, remotes)
+	tools.RegisterSLOTools(s, objectives, runs)
+	tools.RegisterPlanRunTool(s, ws, reg, ip, tp)
 	tools.RegisterSearchTerraformTool(s)
 	tools.RegisterListSectionsTool(s, catalog)
 	tools.RegisterGetDocumentationTool(s, catalog)
+	tools.RegisterGetCategoryTool(s, catalog)
+	tools.RegisterLookupSymbolTool(s, catalog)
+	tools.RegisterSearchDocsTool(s, catalog)
+	tools.RegisterCheckCompatibilityTool(s, ws, catalog)
+	tools.RegisterWhatsNewTool(s, catalog)
+	tools.RegisterMigrateScriptTool(s, ws)
+	tools.RegisterConvertRecordingTool(s)
+	tools.RegisterConvertPlaywrightTool(s, ws)
+	tools.RegisterGenerateContractTestsTool(s)
+	tools.RegisterGenerateGraphQLScriptTool(s, ws)
+	tools.RegisterGenerateKafkaScriptTool(s)
+	tools.RegisterGenerateSQLTestTool(s)
+	tools.RegisterGenerateMQTTScriptTool(s)
+	tools.RegisterGenerateStreamingScriptTool(s)
+	tools.RegisterGenerateDisruptorScriptTool(s)
+	tools.RegisterGenerateAuthSnippetTool(s)
+	tools.RegisterGenerateSessionSnippetTool(s)
+	tools.RegisterAnalyzeCorrelationTool(s)
+	tools.RegisterAnalyzeScriptTool(s, ws)
+	tools.RegisterListEndpointsTool(s, ws)
+	tools.RegisterOpenAPICoverageTool(s, ws)
+	tools.RegisterExplainOptionsTool(s, ws)
+	tools.RegisterBuildScenarioTool(s)
+	tools.RegisterModelPacingTool(s)
+	tools.RegisterBuildWorkloadMixTool(s)
+	tools.RegisterScaffoldTypeScriptTool(s)
+	tools.RegisterChecksToThresholdsTool(s, ws)
+	tools.RegisterDiffScriptsTool(s)
+	tools.RegisterApplyPatchTool(s, ws, cfg.Write)
+	if cfg.Write {
+		tools.RegisterWriteScriptTool(s, ws)
+	}
 
 	resources.RegisterBestPracticesResource(s)
 
 	prompts.RegisterGenerateScriptPrompt(s)
 	prompts.RegisterConvertPlaywrightScriptPrompt(s)
+	prompts.RegisterConvertCypressPrompt(s)
+	prompts.RegisterConvertSeleniumPrompt(s)
 
 	return s
 }
 
+// closeTelemetry pushes the counters a last time, as the server exits.
+func closeTelemetry(logger *slog.Logger, rec *telemetry.Recorder) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := rec.Close(ctx); err != nil {
+		logger.Warn("Final telemetry push failed", slog.String("error", err.Error()))
+	}
+}
+
+// loadSLOs returns a registry holding the SLOs of file, if set.
+func loadSLOs(file string) (*slo.Registry, error) {
+	var slos []slo.SLO
+	if file != "" {
+		var err error
+		if slos, err = slo.Load(file); err != nil {
+			return nil, err
+		}
+	}
+	return slo.NewRegistry(slos)
+}
+
+// vendorJSLib downloads the common jslib modules into dir, for serving
+// them later with JSLibDir on machines without internet access.
+func vendorJSLib(ctx context.Context, logger *slog.Logger, stderr io.Writer, dir string) int {
+	if dir == "" {
+		_, _ = fmt.Fprintln(stderr, "vendoring jslib requires a mirror directory (-jslib-dir)")
+		return 1
+	}
+	logger.Info("Vendoring jslib modules", slog.String("dir", dir), slog.Int("modules", len(jslib.Common)))
+	written, err := jslib.Vendor(ctx, jslib.Origin, dir, jslib.Common)
+	if err != nil {
+		logger.Error("Failed to vendor jslib modules", slog.String("error", err.Error()))
+		_, _ = fmt.Fprintf(stderr, "vendoring jslib failed: %v\n", err)
+		return 1
+	}
+	logger.Info("Vendored jslib modules", slog.Int("downloaded", len(written)))
+	return 0
+}
+
+// preloadWorkers is the number of doc versions preloadBundles downloads and
+// indexes at once.
+const preloadWorkers = 4
+
 // preloadBundles downloads and indexes every known doc version so that
-// tool calls don't pay the download cost on first request.
+// tool calls don't pay the download cost on first request. Versions are
+// indexed concurrently, logging progress as each one completes.
 func preloadBundles(ctx context.Context, logger *slog.Logger, catalog *docs.Catalog) {
 	versions := catalog.Versions()
 	logger.Info("Preloading documentation bundles", slog.Int("versions", len(versions)))
+	start := time.Now()
+
+	jobs := make(chan string)
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		done   int
+		failed int
+	)
+	for range min(preloadWorkers, len(versions)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for v := range jobs {
+				_, err := catalog.Index(ctx, v)
+				mu.Lock()
+				done++
+				progress := []any{slog.String("version", v), slog.Int("done", done), slog.Int("total", len(versions))}
+				if err != nil {
+					failed++
+					logger.Warn("Failed to preload bundle", append(progress, slog.String("error", err.Error()))...)
+				} else {
+					logger.Info("Preloaded bundle", progress...)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
 	for _, v := range versions {
-		if _, err := catalog.Index(ctx, v); err != nil {
-			logger.Warn("Failed to preload bundle",
-				slog.String("version", v),
-				slog.String("error", err.Error()))
-			continue
-		}
-		logger.Info("Preloaded bundle", slog.String("version", v))
+		jobs <- v
+	}
+	close(jobs)
+	wg.Wait()
+
+	logger.Info("Preloaded documentation bundles",
+		slog.Int("versions", len(versions)-failed),
+		slog.Int("failed", failed),
+		slog.Duration("duration", time.Since(start)))
+}
+
+// checkDocLinks reports the links, relrefs and aliases of every doc version
+// that lead nowhere, one per line, and fails when there are any, so docs
+// regressions are caught before get_documentation serves them.
+func checkDocLinks(ctx context.Context, logger *slog.Logger, stderr io.Writer, catalog *docs.Catalog) int {
+	versions := catalog.Versions()
+	if len(versions) == 0 {
+		_, _ = fmt.Fprintln(stderr, "checking documentation links failed: no documentation versions available")
+		return 1
+	}
+	logger.Info("Checking documentation links", slog.Int("versions", len(versions)))
+	problems, err := doclinks.Check(ctx, catalog, versions)
+	if err != nil {
+		logger.Error("Failed to check documentation links", slog.String("error", err.Error()))
+		_, _ = fmt.Fprintf(stderr, "checking documentation links failed: %v\n", err)
+		return 1
 	}
+	for _, p := range problems {
+		_, _ = fmt.Fprintln(stderr, p)
+	}
+	if len(problems) > 0 {
+		_, _ = fmt.Fprintf(stderr, "%d broken documentation references\n", len(problems))
+		return 1
+	}
+	logger.Info("Documentation links are valid", slog.Int("versions", len(versions)))
+	return 0
 }
 
 func handleK6LookupError(logger *slog.Logger, stderr io.Writer, err error) int {
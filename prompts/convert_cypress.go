@@ -0,0 +1,68 @@
+package prompts
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/grafana/mcp-k6/internal/logging"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// ConvertCypressPrompt is the MCP prompt definition for Cypress to k6 conversion.
+//
+//nolint:gochecknoglobals // Shared prompt definition registered at startup.
+var ConvertCypressPrompt = mcp.NewPrompt(
+	"convert_cypress",
+	mcp.WithPromptDescription("Convert a Cypress test to its equivalent k6 script leveraging the browser module."),
+	mcp.WithArgument(
+		"cypress_script",
+		mcp.ArgumentDescription("The Cypress spec to convert (JavaScript or TypeScript) "+
+			"into a k6 browser script. Accepts raw text or a file path."),
+	),
+)
+
+// RegisterConvertCypressPrompt registers the convert_cypress prompt with the MCP server.
+func RegisterConvertCypressPrompt(s *server.MCPServer) {
+	s.AddPrompt(ConvertCypressPrompt, withPromptLogger("convert_cypress", convertCypress))
+}
+
+// convertCypress handles prompt requests to convert Cypress tests to k6/browser scripts.
+func convertCypress(
+	ctx context.Context,
+	request mcp.GetPromptRequest,
+) (*mcp.GetPromptResult, error) {
+	logger := logging.LoggerFromContext(ctx)
+	logger.DebugContext(ctx, "Starting cypress test conversion prompt")
+
+	cypressScript, err := extractScriptArgument(ctx, request, "cypress_script", "Cypress")
+	if err != nil {
+		return nil, err
+	}
+
+	templateContent, err := promptFiles.ReadFile("convert_cypress.md")
+	if err != nil {
+		logger.ErrorContext(ctx, "Failed to read embedded prompt template",
+			slog.String("error", err.Error()))
+		return nil, fmt.Errorf("failed to read embedded prompt template: %w", err)
+	}
+
+	promptText := strings.Replace(string(templateContent), "{{.CypressScript}}", cypressScript, 1)
+
+	result := mcp.NewGetPromptResult(
+		"A Cypress test converted to a k6 script",
+		[]mcp.PromptMessage{
+			mcp.NewPromptMessage(
+				mcp.RoleAssistant,
+				mcp.NewTextContent(promptText),
+			),
+		},
+	)
+
+	logger.InfoContext(ctx, "Cypress test conversion prompt completed successfully",
+		slog.Int("prompt_length", len(promptText)))
+
+	return result, nil
+}
@@ -0,0 +1,69 @@
+package prompts
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/grafana/mcp-k6/internal/logging"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// ConvertSeleniumPrompt is the MCP prompt definition for Selenium to k6 conversion.
+//
+//nolint:gochecknoglobals // Shared prompt definition registered at startup.
+var ConvertSeleniumPrompt = mcp.NewPrompt(
+	"convert_selenium",
+	mcp.WithPromptDescription("Convert a Selenium WebDriver script to its equivalent k6 script "+
+		"leveraging the browser module."),
+	mcp.WithArgument(
+		"selenium_script",
+		mcp.ArgumentDescription("The Selenium script to convert (Java, Python or JavaScript) "+
+			"into a k6 browser script. Accepts raw text or a file path."),
+	),
+)
+
+// RegisterConvertSeleniumPrompt registers the convert_selenium prompt with the MCP server.
+func RegisterConvertSeleniumPrompt(s *server.MCPServer) {
+	s.AddPrompt(ConvertSeleniumPrompt, withPromptLogger("convert_selenium", convertSelenium))
+}
+
+// convertSelenium handles prompt requests to convert Selenium scripts to k6/browser scripts.
+func convertSelenium(
+	ctx context.Context,
+	request mcp.GetPromptRequest,
+) (*mcp.GetPromptResult, error) {
+	logger := logging.LoggerFromContext(ctx)
+	logger.DebugContext(ctx, "Starting selenium script conversion prompt")
+
+	seleniumScript, err := extractScriptArgument(ctx, request, "selenium_script", "Selenium")
+	if err != nil {
+		return nil, err
+	}
+
+	templateContent, err := promptFiles.ReadFile("convert_selenium.md")
+	if err != nil {
+		logger.ErrorContext(ctx, "Failed to read embedded prompt template",
+			slog.String("error", err.Error()))
+		return nil, fmt.Errorf("failed to read embedded prompt template: %w", err)
+	}
+
+	promptText := strings.Replace(string(templateContent), "{{.SeleniumScript}}", seleniumScript, 1)
+
+	result := mcp.NewGetPromptResult(
+		"A Selenium script converted to a k6 script",
+		[]mcp.PromptMessage{
+			mcp.NewPromptMessage(
+				mcp.RoleAssistant,
+				mcp.NewTextContent(promptText),
+			),
+		},
+	)
+
+	logger.InfoContext(ctx, "Selenium script conversion prompt completed successfully",
+		slog.Int("prompt_length", len(promptText)))
+
+	return result, nil
+}
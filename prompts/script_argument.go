@@ -0,0 +1,192 @@
+package prompts
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/grafana/mcp-k6/internal/logging"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// extractScriptArgument returns the script of the named prompt argument,
+// given as raw text or as a file path, for the conversion prompts of the
+// given framework ("Playwright", "Cypress", "Selenium").
+func extractScriptArgument(
+	ctx context.Context,
+	request mcp.GetPromptRequest,
+	argument, framework string,
+) (string, error) {
+	logger := logging.LoggerFromContext(ctx)
+
+	script, exists := request.Params.Arguments[argument]
+	if !exists {
+		logger.WarnContext(ctx, "Missing required parameter", slog.String("argument", argument))
+		return "", fmt.Errorf(
+			"missing required parameter '%s'. "+
+				"Provide the script text directly or reference a file path prefixed with '@'", argument,
+		)
+	}
+
+	if strings.TrimSpace(script) == "" {
+		logger.WarnContext(ctx, "Empty script parameter", slog.String("argument", argument))
+		return "", fmt.Errorf(
+			"'%s' parameter cannot be empty. "+
+				"Provide the script text directly or reference a file path prefixed with '@'", argument,
+		)
+	}
+
+	resolvedScript, err := resolveScriptArgument(ctx, script, framework)
+	if err != nil {
+		logger.WarnContext(ctx, "Failed to resolve script argument",
+			slog.String("argument", argument),
+			slog.String("error", err.Error()))
+		return "", err
+	}
+
+	if strings.TrimSpace(resolvedScript) == "" {
+		return "", fmt.Errorf("resolved %s script content is empty", framework)
+	}
+
+	return resolvedScript, nil
+}
+
+func resolveScriptArgument(ctx context.Context, value, framework string) (string, error) {
+	trimmed := strings.TrimSpace(value)
+	if trimmed == "" {
+		return "", nil
+	}
+
+	if strings.HasPrefix(trimmed, "@") {
+		path := strings.TrimSpace(strings.TrimPrefix(trimmed, "@"))
+		if path == "" {
+			return "", fmt.Errorf("file reference prefixed with '@' must include a path")
+		}
+		return readScriptFromFile(ctx, path, framework)
+	}
+
+	if !strings.ContainsAny(trimmed, "\r\n") {
+		script, ok, err := tryReadScriptFromFile(ctx, trimmed)
+		if err != nil {
+			return "", err
+		}
+		if ok {
+			return script, nil
+		}
+	}
+
+	return value, nil
+}
+
+//nolint:forbidigo // Controlled file access required for prompt inputs.
+func readScriptFromFile(ctx context.Context, path, framework string) (string, error) {
+	normalizedPath, err := normalizeFilePath(path)
+	if err != nil {
+		return "", fmt.Errorf("invalid file path %q: %w", path, err)
+	}
+
+	// Resolve to absolute path and ensure it doesn't escape working directory
+	absPath, err := filepath.Abs(normalizedPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve absolute path for %q: %w", normalizedPath, err)
+	}
+
+	// Get current working directory
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("failed to get current working directory: %w", err)
+	}
+
+	// Ensure the resolved path is within allowed directory, to prevent path traversal attacks
+	if !strings.HasPrefix(absPath, cwd+string(filepath.Separator)) && absPath != cwd {
+		return "", fmt.Errorf("file path must be within current working directory")
+	}
+
+	// #nosec G304 -- normalizedPath is sanitized before file access.
+	data, err := os.ReadFile(normalizedPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s script file %q: %w", framework, normalizedPath, err)
+	}
+
+	logger := logging.LoggerFromContext(ctx)
+	logger.DebugContext(ctx, "Loaded script from file",
+		slog.String("path", normalizedPath),
+		slog.Int("bytes", len(data)))
+
+	return string(data), nil
+}
+
+//nolint:forbidigo
+func tryReadScriptFromFile(ctx context.Context, candidate string) (string, bool, error) {
+	normalizedPath, err := normalizeFilePath(candidate)
+	if err != nil {
+		return "", false, fmt.Errorf("invalid candidate path %q: %w", candidate, err)
+	}
+
+	info, err := os.Stat(normalizedPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("failed to inspect candidate file %q: %w", normalizedPath, err)
+	}
+
+	if info.IsDir() {
+		return "", false, nil
+	}
+
+	// #nosec G304 -- normalizedPath is sanitized before file access.
+	data, err := os.ReadFile(normalizedPath)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to read candidate script file %q: %w", normalizedPath, err)
+	}
+
+	logger := logging.LoggerFromContext(ctx)
+	logger.DebugContext(ctx, "Loaded script from implicit file reference",
+		slog.String("path", normalizedPath),
+		slog.Int("bytes", len(data)))
+
+	return string(data), true, nil
+}
+
+func normalizeFilePath(path string) (string, error) {
+	trimmed := strings.TrimSpace(path)
+	trimmed = strings.Trim(trimmed, "\"'")
+
+	if trimmed == "" {
+		return "", fmt.Errorf("file path cannot be empty")
+	}
+
+	if strings.HasPrefix(trimmed, "~") {
+		home, err := resolveHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("unable to resolve home directory: %w", err)
+		}
+
+		trimmed = filepath.Join(home, strings.TrimPrefix(trimmed, "~"))
+	}
+
+	return filepath.Clean(trimmed), nil
+}
+
+//nolint:forbidigo // HOME resolution relies on environment variables.
+func resolveHomeDir() (string, error) {
+	if home := os.Getenv("HOME"); home != "" {
+		return home, nil
+	}
+
+	if userProfile := os.Getenv("USERPROFILE"); userProfile != "" {
+		return userProfile, nil
+	}
+
+	drive := os.Getenv("HOMEDRIVE")
+	path := os.Getenv("HOMEPATH")
+	if drive != "" && path != "" {
+		return filepath.Join(drive, path), nil
+	}
+
+	return "", fmt.Errorf("home directory not set in environment")
+}
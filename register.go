@@ -40,6 +40,61 @@ documentation browsing, and guided script generation.`,
 	cmd.Flags().StringVar(&cfg.Endpoint, "endpoint", cfg.Endpoint, "Endpoint path for HTTP transport")
 	cmd.Flags().BoolVar(&cfg.Stateless, "stateless", cfg.Stateless, "Run in stateless mode (no session tracking)")
 	cmd.Flags().BoolVar(&cfg.Preload, "preload", cfg.Preload, "Download all documentation bundles at startup")
+	cmd.Flags().BoolVar(&cfg.Write, "allow-write", cfg.Write,
+		"Enable the write_script tool for saving scripts inside the roots")
+	cmd.Flags().StringArrayVar(&cfg.Roots, "root", cfg.Roots,
+		"Directory tools may read scripts and data files from (repeatable)")
+	cmd.Flags().StringArrayVar(&cfg.RedactHeaders, "redact-header", cfg.RedactHeaders,
+		"Header name to mask in captured HTTP traffic (repeatable)")
+	cmd.Flags().StringArrayVar(&cfg.RedactPatterns, "redact-pattern", cfg.RedactPatterns,
+		"Regular expression to mask in captured HTTP traffic (repeatable)")
+	cmd.Flags().StringVar(&cfg.SecretsFile, "secrets-file", cfg.SecretsFile,
+		"File of NAME=VALUE secrets that tools can reference by name")
+	cmd.Flags().StringVar(&cfg.RemoteImports, "remote-imports", cfg.RemoteImports,
+		"Whether scripts may import remote modules: allow or deny")
+	cmd.Flags().StringArrayVar(&cfg.ImportHosts, "import-host", cfg.ImportHosts,
+		"Host remote modules may be imported from (repeatable)")
+	cmd.Flags().StringArrayVar(&cfg.AllowTargets, "allow-target", cfg.AllowTargets,
+		"Host scripts may send requests to (repeatable)")
+	cmd.Flags().StringArrayVar(&cfg.DenyTargets, "deny-target", cfg.DenyTargets,
+		"Host scripts may never send requests to (repeatable)")
+	cmd.Flags().StringVar(&cfg.JSLibDir, "jslib-dir", cfg.JSLibDir,
+		"Offline jslib mirror directory served to inline scripts")
+	cmd.Flags().BoolVar(&cfg.VendorJSLib, "vendor-jslib", cfg.VendorJSLib,
+		"Download the common jslib modules into --jslib-dir and exit")
+	cmd.Flags().BoolVar(&cfg.CheckDocLinks, "check-doc-links", cfg.CheckDocLinks,
+		"Check the links of every documentation version and exit")
+	cmd.Flags().StringArrayVar(&cfg.Webhooks, "webhook", cfg.Webhooks,
+		"URL notified when background or scheduled runs end (repeatable)")
+	cmd.Flags().StringVar(&cfg.SLOFile, "slo-file", cfg.SLOFile, "JSON file of SLOs runs can be checked against")
+	cmd.Flags().StringVar(&cfg.ObserveFile, "observe-file", cfg.ObserveFile,
+		"JSON file of Prometheus or Loki queries whose results over each run are attached to it")
+	cmd.Flags().StringVar(&cfg.AuditLog, "audit-log", cfg.AuditLog,
+		"JSON Lines file every command the server spawns is appended to")
+	cmd.Flags().StringVar(&cfg.WorkerAddr, "worker-addr", cfg.WorkerAddr,
+		"Serve the runs of a coordinator on this address instead of MCP, authenticated with MCP_K6_WORKER_TOKEN")
+	cmd.Flags().StringArrayVar(&cfg.Workers, "worker", cfg.Workers,
+		"URL of a worker run_on_workers splits runs across (repeatable)")
+	cmd.Flags().StringArrayVar(&cfg.RemoteHosts, "remote-host", cfg.RemoteHosts,
+		"Host run_remote may run tests on over SSH, as host, *.domain or user@host (repeatable)")
+	cmd.Flags().StringVar(&cfg.RemoteKeyDir, "remote-key-dir", cfg.RemoteKeyDir,
+		"Directory of the private keys run_remote may log in with, by file name")
+	cmd.Flags().StringVar(&cfg.RemoteK6, "remote-k6", cfg.RemoteK6,
+		"k6 executable run_remote runs on the remote hosts (default: k6 in their PATH)")
+	cmd.Flags().IntVar(&cfg.ConfirmVUs, "confirm-vus", cfg.ConfirmVUs,
+		"Require confirmation for runs starting more than this many VUs (0 disables)")
+	cmd.Flags().DurationVar(&cfg.ConfirmDuration, "confirm-duration", cfg.ConfirmDuration,
+		"Require confirmation for runs lasting longer than this (0 disables)")
+	cmd.Flags().StringVar(&cfg.VerifyToken, "verify-token", cfg.VerifyToken,
+		"Token target hosts must publish before receiving load above --verify-above-vus")
+	cmd.Flags().IntVar(&cfg.VerifyAboveVUs, "verify-above-vus", cfg.VerifyAboveVUs,
+		"VUs a run may start without verifying the ownership of its target hosts")
+	cmd.Flags().IntVar(&cfg.MaxResponseBytes, "max-response-bytes", cfg.MaxResponseBytes,
+		"Truncate tool responses larger than this many bytes (0 disables)")
+	cmd.Flags().StringArrayVar(&cfg.ToolResponseBytes, "tool-response-bytes", cfg.ToolResponseBytes,
+		"Response limit of one tool as tool=bytes (repeatable)")
+	cmd.Flags().IntVar(&cfg.DocsCacheSize, "docs-cache-size", cfg.DocsCacheSize,
+		"Documentation tool responses kept in memory for repeated lookups (0 disables)")
 
 	return cmd
 }
@@ -0,0 +1,521 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/mcp-k6/internal/har"
+	"github.com/grafana/mcp-k6/internal/logging"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// AnalyzeCorrelationTool exposes a tool for finding dynamic values that need correlation.
+//
+//nolint:gochecknoglobals // Shared tool definition registered at startup.
+var AnalyzeCorrelationTool = mcp.NewTool(
+	"analyze_correlation",
+	readOnlyTool(),
+	mcp.WithDescription(
+		"Find dynamic values (session IDs, CSRF tokens, auth headers) in a recorded k6 script or HAR recording "+
+			"and suggest k6 code to extract them from earlier responses and inject them into later requests. "+
+			"With a HAR recording, values are traced from the response that produced them to the requests that reuse them. "+
+			"With a script, hard-coded values that look dynamic are flagged.",
+	),
+	mcp.WithString(
+		"script",
+		mcp.Description("The recorded k6 script to inspect. Provide either script or recording."),
+	),
+	mcp.WithString(
+		"recording",
+		mcp.Description("The HAR 1.2 recording to inspect. Provide either script or recording."),
+	),
+)
+
+// RegisterAnalyzeCorrelationTool registers the analyze_correlation tool with the MCP server.
+func RegisterAnalyzeCorrelationTool(s *server.MCPServer) {
+	s.AddTool(AnalyzeCorrelationTool, withToolLogger("analyze_correlation", analyzeCorrelation))
+}
+
+const (
+	// minCorrelatedValueLength filters out short values (ids like "1", "ok")
+	// that would produce false positives when searched in later requests.
+	minCorrelatedValueLength = 8
+	// maxCorrelationCandidates bounds the response size.
+	maxCorrelationCandidates = 50
+)
+
+// correlationSource describes where a dynamic value first appeared.
+type correlationSource struct {
+	Entry    int    `json:"entry"`
+	URL      string `json:"url"`
+	Kind     string `json:"kind"` // "json", "html_input", "html_meta", "header", "cookie"
+	Selector string `json:"selector"`
+}
+
+// correlationUse describes a later request that reuses a dynamic value.
+type correlationUse struct {
+	Entry    int    `json:"entry,omitempty"`
+	Line     int    `json:"line,omitempty"`
+	URL      string `json:"url,omitempty"`
+	Location string `json:"location"` // "url", "header:<name>", "body", "literal"
+}
+
+// correlationCandidate is a dynamic value with suggested extraction and injection code.
+type correlationCandidate struct {
+	Name           string             `json:"name"`
+	ValuePreview   string             `json:"value_preview"`
+	Reason         string             `json:"reason"`
+	Source         *correlationSource `json:"source,omitempty"`
+	UsedIn         []correlationUse   `json:"used_in"`
+	ExtractCode    string             `json:"extract_code"`
+	InjectHint     string             `json:"inject_hint"`
+	HandledByJar   bool               `json:"handled_by_cookie_jar,omitempty"`
+	NeedsJSLibrary string             `json:"needs_jslib,omitempty"`
+}
+
+// correlationResponse is the JSON structure returned by the tool.
+type correlationResponse struct {
+	Input      string                 `json:"input"` // "recording" or "script"
+	Candidates []correlationCandidate `json:"candidates"`
+	Count      int                    `json:"count"`
+	Truncated  bool                   `json:"truncated,omitempty"`
+	NextSteps  []string               `json:"next_steps"`
+}
+
+func analyzeCorrelation(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger := logging.LoggerFromContext(ctx)
+	logger.DebugContext(ctx, "Starting correlation analysis")
+
+	script := request.GetString("script", "")
+	recording := request.GetString("recording", "")
+
+	var resp correlationResponse
+	switch {
+	case recording != "":
+		archive, err := har.Parse([]byte(recording))
+		if err != nil {
+			logger.WarnContext(ctx, "Failed to parse recording", slog.String("error", err.Error()))
+			return mcp.NewToolResultError("Failed to parse recording: " + err.Error()), nil
+		}
+		resp.Input = "recording"
+		resp.Candidates = correlateRecording(archive)
+	case script != "":
+		resp.Input = "script"
+		resp.Candidates = correlateScript(script)
+	default:
+		return mcp.NewToolResultError("Provide either 'script' or 'recording' to analyze"), nil
+	}
+
+	if len(resp.Candidates) > maxCorrelationCandidates {
+		resp.Candidates = resp.Candidates[:maxCorrelationCandidates]
+		resp.Truncated = true
+	}
+	resp.Count = len(resp.Candidates)
+	resp.NextSteps = correlationNextSteps(resp)
+
+	logger.InfoContext(ctx, "Correlation analysis completed",
+		slog.String("input", resp.Input),
+		slog.Int("candidates", resp.Count))
+
+	return marshalResponse(ctx, logger, resp)
+}
+
+func correlationNextSteps(resp correlationResponse) []string {
+	if resp.Count == 0 {
+		return []string{
+			"No dynamic values were detected; the script may replay as recorded",
+			"Use validate_script to confirm the script still works against the target",
+		}
+	}
+	steps := []string{
+		"Add each extract_code snippet right after the request that produces the value",
+		"Replace the recorded literal in every used_in location with the extracted variable",
+		"Use validate_script to confirm the correlated script passes",
+	}
+	for _, c := range resp.Candidates {
+		name := strings.ToLower(c.Name)
+		if strings.Contains(name, "csrf") || strings.Contains(name, "xsrf") || strings.Contains(name, "session") {
+			return append(steps, "The values include a login session: generate_session_snippet writes a login() "+
+				"handling the CSRF token and the cookie jar, to replace the recorded login requests")
+		}
+	}
+	return steps
+}
+
+// dynamicValue is a value observed in a response, keyed by its literal text.
+type dynamicValue struct {
+	name   string
+	value  string
+	source correlationSource
+	code   string
+	jar    bool
+}
+
+//nolint:gochecknoglobals // Compiled once and reused by the analyzers.
+var (
+	hiddenInputRe = regexp.MustCompile(`(?is)<input[^>]*type=["']?hidden["']?[^>]*>`)
+	htmlAttrRe    = regexp.MustCompile(`(?is)\b(name|value|content)\s*=\s*["']([^"']*)["']`)
+	metaTagRe     = regexp.MustCompile(`(?is)<meta[^>]*>`)
+	jwtRe         = regexp.MustCompile(`eyJ[A-Za-z0-9_-]{8,}\.[A-Za-z0-9_-]{8,}\.[A-Za-z0-9_-]{8,}`)
+	uuidRe        = regexp.MustCompile(`(?i)\b[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}\b`)
+	longHexRe     = regexp.MustCompile(`(?i)\b[0-9a-f]{24,}\b`)
+	bearerRe      = regexp.MustCompile(`(?i)\bBearer\s+([A-Za-z0-9._~+/=-]{16,})`)
+	tokenParamRe  = regexp.MustCompile(
+		`(?i)\b(csrf[_-]?token|_csrf|xsrf[_-]?token|_xsrf|authenticity_token|__viewstate|__eventvalidation|` +
+			`session[_-]?id|sessionid|sid|nonce|state|access_token|id_token|refresh_token|token)` +
+			`["']?\s*[=:]\s*["']?([A-Za-z0-9._~+/%-]{8,})`,
+	)
+)
+
+// correlateRecording traces values produced by responses into later requests.
+func correlateRecording(archive *har.Archive) []correlationCandidate {
+	var candidates []correlationCandidate
+	seen := make(map[string]bool)
+
+	for i, entry := range archive.Log.Entries {
+		for _, dv := range responseValues(i, entry) {
+			if seen[dv.value] {
+				continue
+			}
+			uses := findValueUses(archive.Log.Entries[i+1:], i+1, dv.value)
+			if len(uses) == 0 {
+				continue
+			}
+			seen[dv.value] = true
+			src := dv.source
+			candidates = append(candidates, correlationCandidate{
+				Name:         dv.name,
+				ValuePreview: previewValue(dv.value),
+				Reason:       fmt.Sprintf("value returned by entry %d is sent again in %d later request(s)", i, len(uses)),
+				Source:       &src,
+				UsedIn:       uses,
+				ExtractCode:  dv.code,
+				InjectHint:   injectHint(dv.name, uses),
+				HandledByJar: dv.jar && usesOnlyCookies(uses),
+			})
+		}
+	}
+
+	return candidates
+}
+
+// responseValues collects candidate dynamic values from a single response.
+func responseValues(index int, entry har.Entry) []dynamicValue {
+	var values []dynamicValue
+	src := func(kind, selector string) correlationSource {
+		return correlationSource{Entry: index, URL: entry.Request.URL, Kind: kind, Selector: selector}
+	}
+	variable := func(name string) string {
+		return jsIdentifier(name)
+	}
+
+	for _, c := range entry.Response.Cookies {
+		if len(c.Value) >= minCorrelatedValueLength {
+			values = append(values, dynamicValue{
+				name: c.Name, value: c.Value, source: src("cookie", c.Name), jar: true,
+				code: fmt.Sprintf("const %s = res.cookies[%s][0].value;", variable(c.Name), jsString(c.Name)),
+			})
+		}
+	}
+
+	for _, h := range entry.Response.Headers {
+		lower := strings.ToLower(h.Name)
+		if lower == "set-cookie" || len(h.Value) < minCorrelatedValueLength {
+			continue
+		}
+		if strings.Contains(lower, "token") || strings.Contains(lower, "csrf") ||
+			strings.Contains(lower, "session") || lower == "location" {
+			values = append(values, dynamicValue{
+				name: h.Name, value: h.Value, source: src("header", h.Name),
+				code: fmt.Sprintf("const %s = res.headers[%s];", variable(h.Name), jsString(canonicalHeader(h.Name))),
+			})
+		}
+	}
+
+	body := entry.Response.Content.Text
+	if body == "" || entry.Response.Content.Encoding == "base64" {
+		return values
+	}
+
+	mime := strings.ToLower(entry.Response.Content.MimeType)
+	switch {
+	case strings.Contains(mime, "json"):
+		var doc any
+		if err := json.Unmarshal([]byte(body), &doc); err == nil {
+			walkJSONStrings(doc, "", func(path, value string) {
+				if len(value) < minCorrelatedValueLength {
+					return
+				}
+				name := path
+				if idx := strings.LastIndex(path, "."); idx >= 0 {
+					name = path[idx+1:]
+				}
+				values = append(values, dynamicValue{
+					name: name, value: value, source: src("json", path),
+					code: fmt.Sprintf("const %s = res.json(%s);", variable(name), jsString(path)),
+				})
+			})
+		}
+	case strings.Contains(mime, "html"):
+		values = append(values, htmlValues(body, src, variable)...)
+	}
+
+	return values
+}
+
+// htmlValues extracts hidden form fields and meta tag tokens from an HTML body.
+func htmlValues(
+	body string,
+	src func(kind, selector string) correlationSource,
+	variable func(string) string,
+) []dynamicValue {
+	var values []dynamicValue
+	for _, tag := range hiddenInputRe.FindAllString(body, -1) {
+		attrs := htmlAttributes(tag)
+		name, value := attrs["name"], attrs["value"]
+		if name == "" || len(value) < minCorrelatedValueLength {
+			continue
+		}
+		selector := fmt.Sprintf(`input[name="%s"]`, name)
+		values = append(values, dynamicValue{
+			name: name, value: value, source: src("html_input", selector),
+			code: fmt.Sprintf("const %s = res.html().find(%s).first().attr('value');", variable(name), jsString(selector)),
+		})
+	}
+	for _, tag := range metaTagRe.FindAllString(body, -1) {
+		attrs := htmlAttributes(tag)
+		name, content := attrs["name"], attrs["content"]
+		if name == "" || len(content) < minCorrelatedValueLength {
+			continue
+		}
+		selector := fmt.Sprintf(`meta[name="%s"]`, name)
+		values = append(values, dynamicValue{
+			name: name, value: content, source: src("html_meta", selector),
+			code: fmt.Sprintf("const %s = res.html().find(%s).first().attr('content');",
+				variable(name), jsString(selector)),
+		})
+	}
+	return values
+}
+
+func htmlAttributes(tag string) map[string]string {
+	attrs := make(map[string]string)
+	for _, m := range htmlAttrRe.FindAllStringSubmatch(tag, -1) {
+		attrs[strings.ToLower(m[1])] = m[2]
+	}
+	return attrs
+}
+
+// walkJSONStrings calls fn for every string leaf of doc with its k6 JSON
+// selector path (gjson syntax, as accepted by Response.json()).
+func walkJSONStrings(doc any, prefix string, fn func(path, value string)) {
+	join := func(key string) string {
+		if prefix == "" {
+			return key
+		}
+		return prefix + "." + key
+	}
+	switch v := doc.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			walkJSONStrings(v[k], join(k), fn)
+		}
+	case []any:
+		for i, item := range v {
+			walkJSONStrings(item, join(strconv.Itoa(i)), fn)
+		}
+	case string:
+		fn(prefix, v)
+	}
+}
+
+// findValueUses searches requests for value (raw or URL-encoded).
+func findValueUses(entries []har.Entry, offset int, value string) []correlationUse {
+	encoded := url.QueryEscape(value)
+	contains := func(s string) bool {
+		return strings.Contains(s, value) || (encoded != value && strings.Contains(s, encoded))
+	}
+
+	var uses []correlationUse
+	for i, e := range entries {
+		index := offset + i
+		if contains(e.Request.URL) {
+			uses = append(uses, correlationUse{Entry: index, URL: e.Request.URL, Location: "url"})
+		}
+		for _, h := range e.Request.Headers {
+			if contains(h.Value) {
+				uses = append(uses, correlationUse{Entry: index, URL: e.Request.URL, Location: "header:" + h.Name})
+			}
+		}
+		if e.Request.PostData != nil {
+			body := e.Request.PostData.Text
+			for _, p := range e.Request.PostData.Params {
+				body += "&" + p.Name + "=" + p.Value
+			}
+			if contains(body) {
+				uses = append(uses, correlationUse{Entry: index, URL: e.Request.URL, Location: "body"})
+			}
+		}
+	}
+	return uses
+}
+
+func usesOnlyCookies(uses []correlationUse) bool {
+	for _, u := range uses {
+		if !strings.EqualFold(u.Location, "header:cookie") {
+			return false
+		}
+	}
+	return true
+}
+
+// correlateScript flags hard-coded literals in a script that look dynamic.
+func correlateScript(script string) []correlationCandidate {
+	var candidates []correlationCandidate
+	seen := make(map[string]int) // index in candidates, by value
+
+	add := func(name, value, reason, code string, line int) {
+		if i, ok := seen[value]; ok {
+			candidates[i].UsedIn = append(candidates[i].UsedIn, correlationUse{Line: line, Location: "literal"})
+			return
+		}
+		candidates = append(candidates, correlationCandidate{
+			Name:         name,
+			ValuePreview: previewValue(value),
+			Reason:       reason,
+			UsedIn:       []correlationUse{{Line: line, Location: "literal"}},
+			ExtractCode:  code,
+		})
+		seen[value] = len(candidates) - 1
+	}
+
+	for i, line := range strings.Split(script, "\n") {
+		lineNum := i + 1
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "//") || strings.HasPrefix(trimmed, "import ") {
+			continue
+		}
+
+		for _, m := range bearerRe.FindAllStringSubmatch(line, -1) {
+			add("access_token", m[1], "hard-coded bearer token in Authorization header",
+				"const access_token = res.json('access_token'); // from the login/token response", lineNum)
+		}
+		for _, m := range jwtRe.FindAllString(line, -1) {
+			add("jwt", m, "hard-coded JWT; it will expire and be rejected on replay",
+				"const jwt = res.json('token'); // adjust the selector to the login response", lineNum)
+		}
+		for _, m := range tokenParamRe.FindAllStringSubmatch(line, -1) {
+			name := strings.ToLower(m[1])
+			add(name, m[2], fmt.Sprintf("recorded %s value is usually issued per session", name),
+				suggestedExtraction(name), lineNum)
+		}
+		for _, m := range uuidRe.FindAllString(line, -1) {
+			add("id", m, "hard-coded UUID may identify a resource created during the recording",
+				"const id = res.json('id'); // from the response that created the resource", lineNum)
+		}
+		for _, m := range longHexRe.FindAllString(line, -1) {
+			add("token", m, "long hexadecimal literal looks like a generated token or session ID",
+				"const token = findBetween(res.body, 'token\":\"', '\"');", lineNum)
+		}
+	}
+
+	for i := range candidates {
+		c := &candidates[i]
+		c.InjectHint = injectHint(c.Name, c.UsedIn)
+		if strings.Contains(c.ExtractCode, "findBetween") {
+			c.NeedsJSLibrary = "import { findBetween } from 'https://jslib.k6.io/k6-utils/1.4.0/index.js';"
+		}
+	}
+	return candidates
+}
+
+func suggestedExtraction(name string) string {
+	variable := jsIdentifier(name)
+	switch {
+	case strings.Contains(name, "csrf") || strings.Contains(name, "xsrf") ||
+		strings.Contains(name, "authenticity") || strings.HasPrefix(name, "__"):
+		return fmt.Sprintf("const %s = res.html().find('input[name=\"%s\"]').first().attr('value');", variable, name)
+	case strings.Contains(name, "session") || name == "sid":
+		return fmt.Sprintf("const %s = res.cookies['%s'][0].value; // or rely on the k6 cookie jar", variable, name)
+	default:
+		return fmt.Sprintf("const %s = res.json('%s');", variable, name)
+	}
+}
+
+func injectHint(name string, uses []correlationUse) string {
+	variable := jsIdentifier(name)
+	locations := make([]string, 0, len(uses))
+	for _, u := range uses {
+		switch {
+		case u.Line > 0:
+			locations = append(locations, fmt.Sprintf("line %d", u.Line))
+		default:
+			locations = append(locations, fmt.Sprintf("entry %d (%s)", u.Entry, u.Location))
+		}
+	}
+	return fmt.Sprintf("Replace the recorded value with ${%s} at %s", variable, strings.Join(removeDuplicates(locations), ", "))
+}
+
+// previewValue masks a value so tokens are not echoed back in full.
+func previewValue(value string) string {
+	const visible = 4
+	if len(value) <= visible*2 {
+		return strings.Repeat("*", len(value))
+	}
+	return fmt.Sprintf("%s…%s (%d chars)", value[:visible], value[len(value)-visible:], len(value))
+}
+
+// jsIdentifier converts an arbitrary name into a valid JavaScript identifier
+// in camelCase (e.g. "X-CSRF-Token" becomes "xCSRFToken").
+func jsIdentifier(name string) string {
+	var b strings.Builder
+	upper := false
+	for _, r := range name {
+		isAlnum := r == '_' || r == '$' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')
+		if !isAlnum {
+			upper = b.Len() > 0
+			continue
+		}
+		switch {
+		case upper:
+			b.WriteString(strings.ToUpper(string(r)))
+			upper = false
+		case b.Len() == 0:
+			b.WriteString(strings.ToLower(string(r)))
+		default:
+			b.WriteRune(r)
+		}
+	}
+	id := b.String()
+	if id == "" {
+		return "value"
+	}
+	if id[0] >= '0' && id[0] <= '9' {
+		return "v" + id
+	}
+	return id
+}
+
+// canonicalHeader returns the header name as k6 exposes it in res.headers.
+func canonicalHeader(name string) string {
+	parts := strings.Split(strings.ToLower(name), "-")
+	for i, p := range parts {
+		if p != "" {
+			parts[i] = strings.ToUpper(p[:1]) + p[1:]
+		}
+	}
+	return strings.Join(parts, "-")
+}
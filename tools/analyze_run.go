@@ -0,0 +1,112 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/grafana/mcp-k6/internal/anomaly"
+	"github.com/grafana/mcp-k6/internal/logging"
+	"github.com/grafana/mcp-k6/internal/samples"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// DefaultAnalyzeBuckets is the number of buckets analyze_run splits a run
+// into by default.
+const DefaultAnalyzeBuckets = 60
+
+// AnalyzeRunTool exposes a tool for finding anomalies in the samples of a
+// run.
+//
+//nolint:gochecknoglobals // Shared tool definition registered at startup.
+var AnalyzeRunTool = mcp.NewTool(
+	"analyze_run",
+	readOnlyTool(),
+	mcp.WithDescription(
+		"Flag latency spikes, error bursts and throughput plateaus in the time series of a run_script call "+
+			"with output kept, each with its time range, peak and baseline, to correlate them with deploys "+
+			"or the load at which the system under test saturated.",
+	),
+	mcp.WithString(
+		"artifact_id",
+		mcp.Required(),
+		mcp.Description("The artifact_id of the run, from the artifacts of its run_script result."),
+	),
+	mcp.WithNumber(
+		"buckets",
+		mcp.Description(fmt.Sprintf(
+			"Optional: the number of periods of equal duration the run is split into; more buckets find "+
+				"shorter anomalies (default: %d, max: %d).", DefaultAnalyzeBuckets, MaxBuckets)),
+	),
+	mcp.WithString(
+		"latency_metric",
+		mcp.Description(fmt.Sprintf(
+			"Optional: the trend metric to look for latency spikes in, such as a custom trend (default: %s).",
+			anomaly.DefaultLatencyMetric)),
+	),
+)
+
+// RegisterAnalyzeRunTool registers the analyze_run tool, reading the
+// artifacts of runs.
+func RegisterAnalyzeRunTool(s *server.MCPServer, runs *Runs) {
+	s.AddTool(AnalyzeRunTool, withToolLogger("analyze_run", newAnalyzeRunHandlerFunc(runs)))
+}
+
+type analyzeRunResponse struct {
+	ArtifactID string    `json:"artifact_id"`
+	Start      time.Time `json:"start"`
+	// BucketWidth is the duration of the periods the run is split into.
+	BucketWidth string            `json:"bucket_width"`
+	Anomalies   []anomaly.Anomaly `json:"anomalies"`
+	NextSteps   []string          `json:"next_steps,omitempty"`
+}
+
+func newAnalyzeRunHandlerFunc(runs *Runs) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		logger := logging.LoggerFromContext(ctx)
+		id, err := request.RequireString("artifact_id")
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		buckets := request.GetInt("buckets", DefaultAnalyzeBuckets)
+		if buckets < 1 || buckets > MaxBuckets {
+			return mcp.NewToolResultError(fmt.Sprintf("buckets must be between 1 and %d", MaxBuckets)), nil
+		}
+		opts := anomaly.Options{LatencyMetric: request.GetString("latency_metric", "")}
+
+		result, _, err := selectRunSamples(runs, id, samples.Query{Metrics: opts.Metrics(), Buckets: buckets})
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		resp := analyzeRunResponse{
+			ArtifactID:  id,
+			Start:       result.Start,
+			BucketWidth: result.Width.Round(time.Millisecond).String(),
+			Anomalies:   anomaly.Detect(result, opts),
+		}
+		resp.NextSteps = analyzeRunNextSteps(resp.Anomalies, result.Matched)
+		return structuredResponse(ctx, logger, resp)
+	}
+}
+
+func analyzeRunNextSteps(anomalies []anomaly.Anomaly, matched int) []string {
+	if matched == 0 {
+		return []string{"The run kept no HTTP or VU samples; check latency_metric, or analyze a run that sends requests"}
+	}
+	if len(anomalies) == 0 {
+		return []string{"No anomaly found; raise buckets to look for shorter ones"}
+	}
+	steps := []string{
+		"Call get_run_samples with the from and to of an anomaly, and group_by 'name' or 'status', " +
+			"to find the requests behind it",
+	}
+	for _, a := range anomalies {
+		if a.Kind == anomaly.ThroughputPlateau {
+			steps = append(steps, fmt.Sprintf("Throughput stopped growing with the load at %s; "+
+				"confirm the capacity of the system under test with find_capacity", a.From))
+			break
+		}
+	}
+	return steps
+}
@@ -0,0 +1,64 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grafana/mcp-k6/internal/anomaly"
+	"github.com/grafana/mcp-k6/internal/artifacts"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalyzeRun(t *testing.T) {
+	t.Parallel()
+
+	runs := NewRuns(nil, nil, nil)
+	runs.artifacts = artifacts.New(t.TempDir(), artifacts.DefaultMaxRuns)
+	t.Cleanup(runs.Close)
+
+	options := &RunOptions{Output: "json", Artifacts: runs.Artifacts()}
+	require.NoError(t, createSamplesFile(options))
+	// A minute of requests with errors from 30s to 40s
+	var b strings.Builder
+	for i := range 60 {
+		failed := 0
+		if i >= 30 && i < 40 {
+			failed = 1
+		}
+		fmt.Fprintf(&b, `{"metric":"http_req_failed","type":"Point","data":{"time":%q,"value":%d}}`+"\n",
+			time.Date(2026, 1, 2, 10, 0, i, 0, time.UTC).Format(time.RFC3339), failed)
+	}
+	require.NoError(t, os.WriteFile(options.SamplesFile, []byte(b.String()), 0o600))
+
+	handler := newAnalyzeRunHandlerFunc(runs)
+	result, err := handler(context.Background(), newCallRequest(map[string]any{
+		"artifact_id": options.ArtifactID,
+		"buckets":     6.0,
+	}))
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	var resp analyzeRunResponse
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &resp))
+	assert.Equal(t, "9.833s", resp.BucketWidth)
+	require.Len(t, resp.Anomalies, 1)
+	assert.Equal(t, anomaly.ErrorBurst, resp.Anomalies[0].Kind)
+	assert.Equal(t, "30s", resp.Anomalies[0].From)
+	assert.Contains(t, resp.NextSteps[0], "get_run_samples")
+
+	for _, args := range []map[string]any{
+		{"artifact_id": "artifact-9"},
+		{"artifact_id": options.ArtifactID, "buckets": 0.0},
+		{"artifact_id": options.ArtifactID, "buckets": 1000.0},
+	} {
+		result, err = handler(context.Background(), newCallRequest(args))
+		require.NoError(t, err)
+		assert.True(t, result.IsError, args)
+	}
+}
@@ -0,0 +1,133 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/grafana/mcp-k6/internal/logging"
+	"github.com/grafana/mcp-k6/internal/scriptinfo"
+	"github.com/grafana/mcp-k6/internal/workspace"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// AnalyzeScriptTool exposes a tool for statically summarizing a k6 script.
+//
+//nolint:gochecknoglobals // Shared tool definition registered at startup.
+var AnalyzeScriptTool = mcp.NewTool(
+	"analyze_script",
+	readOnlyTool(),
+	mcp.WithDescription(
+		"Statically summarize what a k6 script does without running it: imported modules, "+
+			"lifecycle functions, scenarios and executors, stages, thresholds, endpoints requested, "+
+			"data files opened and environment variables read. "+
+			"Use it to understand an existing test suite before modifying it.",
+	),
+	mcp.WithString(
+		"script",
+		mcp.Description("The k6 script content to analyze. Provide either script or script_path."),
+	),
+	mcp.WithString(
+		"script_path",
+		mcp.Description(scriptPathDescription),
+	),
+)
+
+// RegisterAnalyzeScriptTool registers the analyze_script tool with the MCP server.
+func RegisterAnalyzeScriptTool(s *server.MCPServer, ws *workspace.Workspace) {
+	s.AddTool(AnalyzeScriptTool, withToolLogger("analyze_script", newAnalyzeScriptHandlerFunc(ws)))
+}
+
+// analyzeScriptResponse is the JSON structure returned by the tool.
+type analyzeScriptResponse struct {
+	Overview []string `json:"overview"`
+	*scriptinfo.Info
+}
+
+// newAnalyzeScriptHandlerFunc returns an MCP tool handler bound to a workspace.
+func newAnalyzeScriptHandlerFunc(ws *workspace.Workspace) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		logger := logging.LoggerFromContext(ctx)
+
+		script, _, err := readScriptArgument(ctx, ws, request)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		info := scriptinfo.Analyze(script)
+
+		logger.InfoContext(ctx, "Script analyzed",
+			slog.Int("imports", len(info.Imports)),
+			slog.Int("scenarios", len(info.Scenarios)),
+			slog.Int("endpoints", len(info.Endpoints)))
+
+		return marshalResponse(ctx, logger, analyzeScriptResponse{
+			Overview: scriptOverview(info),
+			Info:     info,
+		})
+	}
+}
+
+// scriptOverview renders the analysis as a few human-readable sentences.
+func scriptOverview(info *scriptinfo.Info) []string {
+	var overview []string
+
+	if len(info.Protocols) > 0 {
+		overview = append(overview, "Protocols: "+strings.Join(info.Protocols, ", "))
+	}
+
+	switch {
+	case len(info.Scenarios) > 0:
+		parts := make([]string, 0, len(info.Scenarios))
+		for _, sc := range info.Scenarios {
+			executor := sc.Executor
+			if executor == "" {
+				executor = "default executor"
+			}
+			parts = append(parts, fmt.Sprintf("%s (%s)", sc.Name, executor))
+		}
+		overview = append(overview, fmt.Sprintf("%d scenario(s): %s", len(info.Scenarios), strings.Join(parts, ", ")))
+	case info.Options != nil && len(info.Options.Stages) > 0:
+		overview = append(overview, fmt.Sprintf("Ramps through %d stage(s)", len(info.Options.Stages)))
+	case info.Options != nil && (info.Options.VUs != "" || info.Options.Duration != "" || info.Options.Iterations != ""):
+		overview = append(overview, fmt.Sprintf("Load: vus=%s duration=%s iterations=%s",
+			valueOr(info.Options.VUs, "1"), valueOr(info.Options.Duration, "-"), valueOr(info.Options.Iterations, "-")))
+	default:
+		overview = append(overview, "No load options in the script; k6 defaults to 1 VU and 1 iteration unless overridden")
+	}
+
+	if len(info.Thresholds) > 0 {
+		metrics := make([]string, 0, len(info.Thresholds))
+		for _, th := range info.Thresholds {
+			metrics = append(metrics, th.Metric)
+		}
+		overview = append(overview, "Thresholds on: "+strings.Join(metrics, ", "))
+	} else {
+		overview = append(overview, "No thresholds: the test cannot fail on performance criteria")
+	}
+
+	overview = append(overview, fmt.Sprintf("%d request call(s), %d check(s), %d group(s)",
+		len(info.Endpoints), info.Checks, len(info.Groups)))
+
+	if len(info.DataFiles) > 0 {
+		files := make([]string, 0, len(info.DataFiles))
+		for _, f := range info.DataFiles {
+			files = append(files, f.Path)
+		}
+		overview = append(overview, "Data files: "+strings.Join(files, ", "))
+	}
+	if len(info.EnvVars) > 0 {
+		overview = append(overview, "Reads environment variables: "+strings.Join(info.EnvVars, ", "))
+	}
+
+	return overview
+}
+
+func valueOr(v, fallback string) string {
+	if v == "" {
+		return fallback
+	}
+	return v
+}
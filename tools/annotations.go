@@ -0,0 +1,44 @@
+package tools
+
+import (
+	"context"
+	"log/slog"
+	"path/filepath"
+
+	"github.com/grafana/mcp-k6/internal/annotate"
+	"github.com/grafana/mcp-k6/internal/logging"
+)
+
+// annotateRun starts the Grafana annotation of the run of options and
+// returns the function ending it with the outcome of the result. Previews
+// apply no load and are not annotated. Failed annotations are logged and do
+// not fail the run.
+func annotateRun(ctx context.Context, options *RunOptions) func(result *RunResult) {
+	if options == nil || options.Annotator == nil || options.Preview {
+		return func(*RunResult) {}
+	}
+	logger := logging.LoggerFromContext(ctx)
+	run := annotate.Run{ID: options.RunID, Script: "inline"}
+	if options.ScriptPath != "" {
+		run.Script = filepath.Base(options.ScriptPath)
+	}
+
+	mark, err := options.Annotator.Start(ctx, run)
+	if err != nil {
+		logger.WarnContext(ctx, "Run annotation failed",
+			slog.String("run_id", run.ID),
+			slog.String("error", err.Error()))
+	}
+	return func(result *RunResult) {
+		outcome := "error"
+		if result != nil {
+			outcome = runOutcome(result)
+		}
+		// A stopped run still ends its annotation
+		if err := mark.End(context.WithoutCancel(ctx), outcome); err != nil {
+			logger.WarnContext(ctx, "Run annotation failed",
+				slog.String("run_id", run.ID),
+				slog.String("error", err.Error()))
+		}
+	}
+}
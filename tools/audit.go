@@ -0,0 +1,27 @@
+package tools
+
+import (
+	"context"
+
+	"github.com/grafana/mcp-k6/internal/audit"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// AuditCommands returns a middleware that passes log on in the context,
+// with the calling session and tool, so every command a call spawns is
+// recorded, including the background and scheduled runs that outlive it.
+func AuditCommands(log *audit.Log) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		if log == nil {
+			return next
+		}
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			caller := audit.Caller{Tool: request.Params.Name}
+			if session := server.ClientSessionFromContext(ctx); session != nil {
+				caller.Session = session.SessionID()
+			}
+			return next(audit.ContextWithLog(ctx, log, caller), request)
+		}
+	}
+}
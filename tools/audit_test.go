@@ -0,0 +1,39 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/grafana/mcp-k6/internal/audit"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditCommands(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	log, err := audit.Open(path)
+	require.NoError(t, err)
+
+	handler := AuditCommands(log)(func(ctx context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		cmd := exec.CommandContext(ctx, "true")
+		audit.Command(ctx, cmd, time.Now(), cmd.Run())
+		return mcp.NewToolResultText("ok"), nil
+	})
+	req := mcp.CallToolRequest{}
+	req.Params.Name = "terraform"
+	_, err = handler(context.Background(), req)
+	require.NoError(t, err)
+	require.NoError(t, log.Close())
+
+	data, err := os.ReadFile(path) //nolint:forbidigo // Test reads the audit log back.
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"tool":"terraform"`)
+	assert.Contains(t, string(data), `"exit_code":0`)
+}
@@ -0,0 +1,564 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/grafana/mcp-k6/internal/logging"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// GenerateAuthSnippetTool exposes a tool for writing the authentication code
+// of scripts.
+//
+//nolint:gochecknoglobals // Shared tool definition registered at startup.
+var GenerateAuthSnippetTool = mcp.NewTool(
+	"generate_auth_snippet",
+	readOnlyTool(),
+	mcp.WithDescription(
+		"Generate k6 authentication code for a common flow: OAuth2/OIDC client credentials, password or "+
+			"refresh token grants, whose token is requested once in setup() and shared with the VUs, or AWS "+
+			"Signature V4 request signing with the k6-jslib-aws library. Credentials are never passed to the "+
+			"tool: 'credentials' only names the environment variables or k6/secrets secrets the script reads "+
+			"them from, so no secret is written in the script.",
+	),
+	mcp.WithString(
+		"flow",
+		mcp.Required(),
+		mcp.Description("'client_credentials', 'password', 'refresh_token' or 'aws_sigv4'."),
+		mcp.Enum(authClientCredentials, authPassword, authRefreshToken, authSigV4),
+	),
+	mcp.WithString(
+		"token_url",
+		mcp.Description("OAuth2 flows: the token endpoint, e.g. https://auth.example.com/oauth/token. "+
+			"Provide either token_url or issuer; the script reads it from TOKEN_URL first."),
+	),
+	mcp.WithString(
+		"issuer",
+		mcp.Description("OAuth2 flows: the OIDC issuer, e.g. https://auth.example.com/realms/shop, whose "+
+			"token endpoint setup() takes from /.well-known/openid-configuration."),
+	),
+	mcp.WithString(
+		"client_auth",
+		mcp.Description("OAuth2 flows: how the client authenticates, 'body' (client_secret_post, default) or "+
+			"'basic' (client_secret_basic)."),
+		mcp.Enum(authBody, authBasic),
+	),
+	mcp.WithString(
+		"scope",
+		mcp.Description("Optional, OAuth2 flows: the scope to request, e.g. 'openid orders:read'."),
+	),
+	mcp.WithString(
+		"audience",
+		mcp.Description("Optional, OAuth2 flows: the audience to request, for providers such as Auth0."),
+	),
+	mcp.WithString(
+		"credential_source",
+		mcp.Description("Where the script reads credentials: 'env' (default), environment variables passed "+
+			"with env_file or the secrets parameter of run_script; or 'secrets', the k6/secrets module with "+
+			"the secret_sources parameter."),
+		mcp.Enum(credentialEnv, credentialSecrets),
+	),
+	mcp.WithObject(
+		"credentials",
+		mcp.Description("Optional: names of the variables or secrets holding each credential, e.g. "+
+			"{\"client_id\": \"SHOP_CLIENT_ID\"}. Roles are client_id, client_secret, username, password, "+
+			"refresh_token, access_key_id, secret_access_key and session_token; client_secret is optional for "+
+			"the password and refresh_token flows, and session_token for aws_sigv4, which are only read when named."),
+		mcp.AdditionalProperties(map[string]any{"type": "string"}),
+	),
+	mcp.WithString(
+		"api_url",
+		mcp.Description("Optional: the URL of the sample authenticated request (default: https://api.example.com/)."),
+	),
+	mcp.WithString(
+		"aws_service",
+		mcp.Description("aws_sigv4: the signing name of the service, e.g. 'execute-api', 's3' or 'lambda'."),
+	),
+	mcp.WithString(
+		"aws_region",
+		mcp.Description("Optional, aws_sigv4: the region, e.g. 'eu-west-1' (default: AWS_REGION)."),
+	),
+)
+
+// Flows, client authentication methods and credential sources of
+// generate_auth_snippet.
+const (
+	authClientCredentials = "client_credentials"
+	authPassword          = "password"
+	authRefreshToken      = "refresh_token"
+	authSigV4             = "aws_sigv4"
+
+	authBody  = "body"
+	authBasic = "basic"
+
+	credentialEnv     = "env"
+	credentialSecrets = "secrets"
+)
+
+// awsSignatureModule is the jslib module signing AWS requests.
+const awsSignatureModule = "https://jslib.k6.io/aws/0.14.0/signature.js"
+
+// credentialRole is a credential of a flow, read by the script from the
+// variable or secret Default unless renamed.
+type credentialRole struct {
+	Role    string
+	Default string
+	// Optional roles are only read when named in the credentials parameter.
+	Optional bool
+	// Variable is the JavaScript variable holding the credential.
+	Variable string
+}
+
+// authRoles lists the credentials of each flow.
+//
+//nolint:gochecknoglobals // Lookup table.
+var authRoles = map[string][]credentialRole{
+	authClientCredentials: {
+		{Role: "client_id", Default: "OAUTH_CLIENT_ID", Variable: "clientId"},
+		{Role: "client_secret", Default: "OAUTH_CLIENT_SECRET", Variable: "clientSecret"},
+	},
+	authPassword: {
+		{Role: "client_id", Default: "OAUTH_CLIENT_ID", Variable: "clientId"},
+		{Role: "client_secret", Default: "OAUTH_CLIENT_SECRET", Optional: true, Variable: "clientSecret"},
+		{Role: "username", Default: "OAUTH_USERNAME", Variable: "username"},
+		{Role: "password", Default: "OAUTH_PASSWORD", Variable: "password"},
+	},
+	authRefreshToken: {
+		{Role: "client_id", Default: "OAUTH_CLIENT_ID", Variable: "clientId"},
+		{Role: "client_secret", Default: "OAUTH_CLIENT_SECRET", Optional: true, Variable: "clientSecret"},
+		{Role: "refresh_token", Default: "OAUTH_REFRESH_TOKEN", Variable: "refreshToken"},
+	},
+	authSigV4: {
+		{Role: "access_key_id", Default: "AWS_ACCESS_KEY_ID", Variable: "accessKeyId"},
+		{Role: "secret_access_key", Default: "AWS_SECRET_ACCESS_KEY", Variable: "secretAccessKey"},
+		{Role: "session_token", Default: "AWS_SESSION_TOKEN", Optional: true, Variable: "sessionToken"},
+	},
+}
+
+// Valid names of environment variables and of k6 secrets.
+//
+//nolint:gochecknoglobals // Compiled once, read-only.
+var (
+	envVarName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	secretName = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+)
+
+// RegisterGenerateAuthSnippetTool registers the generate_auth_snippet tool
+// with the MCP server.
+func RegisterGenerateAuthSnippetTool(s *server.MCPServer) {
+	s.AddTool(GenerateAuthSnippetTool, withToolLogger("generate_auth_snippet", generateAuthSnippet))
+}
+
+// authOptions are the parameters of a generated authentication script.
+type authOptions struct {
+	Flow       string
+	TokenURL   string
+	Issuer     string
+	ClientAuth string
+	Scope      string
+	Audience   string
+	Source     string
+	// Credentials are the credentials the script reads, with their
+	// variable or secret names.
+	Credentials []authCredential
+	APIURL      string
+	Service     string
+	Region      string
+}
+
+// authCredential is a credential read by the script.
+type authCredential struct {
+	Role     string `json:"role"`
+	Name     string `json:"name"`
+	variable string
+}
+
+// generateAuthSnippetResponse is the JSON structure returned by the tool.
+type generateAuthSnippetResponse struct {
+	Script string `json:"script"`
+	// Credentials are the variables or secrets to provide when running the
+	// script.
+	Credentials []authCredential `json:"credentials"`
+	Source      string           `json:"credential_source"`
+	Warnings    []string         `json:"warnings,omitempty"`
+	NextSteps   []string         `json:"next_steps"`
+}
+
+func generateAuthSnippet(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger := logging.LoggerFromContext(ctx)
+
+	opts, warnings, err := authSnippetOptions(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	script := authScript(opts)
+
+	names := make([]string, 0, len(opts.Credentials))
+	for _, c := range opts.Credentials {
+		names = append(names, c.Name)
+	}
+	resp := generateAuthSnippetResponse{
+		Script:      script,
+		Credentials: opts.Credentials,
+		Source:      opts.Source,
+		Warnings:    warnings,
+	}
+	if opts.Source == credentialEnv {
+		resp.NextSteps = append(resp.NextSteps, fmt.Sprintf("Provide %s with the secrets parameter of "+
+			"run_script (secrets configured on the server), or in an env_file kept out of version control",
+			strings.Join(names, ", ")))
+	} else {
+		resp.NextSteps = append(resp.NextSteps, fmt.Sprintf("Provide the secrets %s with the secret_sources "+
+			"parameter of run_script, e.g. a file source outside version control", strings.Join(names, ", ")))
+	}
+	if opts.Flow != authSigV4 {
+		resp.NextSteps = append(resp.NextSteps,
+			"Move the Authorization header of the sample request to the requests of your script",
+			"setup() hands the token to the VUs in its data: keep the test shorter than the token lifetime, "+
+				"and leave setup_data out of handleSummary exports")
+	} else {
+		resp.NextSteps = append(resp.NextSteps,
+			"Sign the requests of your script with signedRequest(), which adds the signature headers")
+	}
+	resp.NextSteps = append(resp.NextSteps, "Use validate_script to run the flow once before adding load")
+
+	logger.InfoContext(ctx, "Auth snippet generated",
+		slog.String("flow", opts.Flow),
+		slog.String("credential_source", opts.Source),
+		slog.Int("script_size", len(script)))
+
+	return marshalResponse(ctx, logger, resp)
+}
+
+// authSnippetOptions reads and checks the parameters of request.
+func authSnippetOptions(request mcp.CallToolRequest) (authOptions, []string, error) {
+	opts := authOptions{
+		Flow:       request.GetString("flow", ""),
+		TokenURL:   strings.TrimSpace(request.GetString("token_url", "")),
+		Issuer:     strings.TrimRight(strings.TrimSpace(request.GetString("issuer", "")), "/"),
+		ClientAuth: request.GetString("client_auth", authBody),
+		Scope:      strings.TrimSpace(request.GetString("scope", "")),
+		Audience:   strings.TrimSpace(request.GetString("audience", "")),
+		Source:     request.GetString("credential_source", credentialEnv),
+		APIURL:     strings.TrimSpace(request.GetString("api_url", "https://api.example.com/")),
+		Service:    strings.TrimSpace(request.GetString("aws_service", "")),
+		Region:     strings.TrimSpace(request.GetString("aws_region", "")),
+	}
+	roles, ok := authRoles[opts.Flow]
+	if !ok {
+		return opts, nil, fmt.Errorf("invalid flow %q: use 'client_credentials', 'password', "+
+			"'refresh_token' or 'aws_sigv4'", opts.Flow)
+	}
+	if opts.Source != credentialEnv && opts.Source != credentialSecrets {
+		return opts, nil, fmt.Errorf("invalid credential_source %q: use 'env' or 'secrets'", opts.Source)
+	}
+	if !isHTTPURL(opts.APIURL) {
+		return opts, nil, fmt.Errorf("invalid api_url %q: expected an absolute http or https URL", opts.APIURL)
+	}
+
+	var warnings []string
+	if opts.Flow == authSigV4 {
+		if opts.Service == "" {
+			return opts, nil, errors.New("aws_service is required for aws_sigv4, e.g. 'execute-api'")
+		}
+		for _, param := range []string{"token_url", "issuer", "client_auth", "scope", "audience"} {
+			if _, ok := request.GetArguments()[param]; ok {
+				warnings = append(warnings, param+" is ignored: it only applies to OAuth2 flows")
+			}
+		}
+	} else {
+		switch {
+		case (opts.TokenURL == "") == (opts.Issuer == ""):
+			return opts, nil, errors.New("provide either token_url or issuer")
+		case opts.TokenURL != "" && !isHTTPURL(opts.TokenURL):
+			return opts, nil, fmt.Errorf("invalid token_url %q: expected an absolute http or https URL", opts.TokenURL)
+		case opts.Issuer != "" && !isHTTPURL(opts.Issuer):
+			return opts, nil, fmt.Errorf("invalid issuer %q: expected an absolute http or https URL", opts.Issuer)
+		case opts.ClientAuth != authBody && opts.ClientAuth != authBasic:
+			return opts, nil, fmt.Errorf("invalid client_auth %q: use 'body' or 'basic'", opts.ClientAuth)
+		}
+		if strings.HasPrefix(opts.TokenURL+opts.Issuer, "http://") {
+			warnings = append(warnings, "The token endpoint uses http://: credentials are sent in clear text")
+		}
+		if opts.Flow == authPassword {
+			warnings = append(warnings, "The password grant is deprecated by OAuth 2.1: prefer client "+
+				"credentials for service accounts when the provider allows it")
+		}
+	}
+
+	credentials, err := credentialsArgument(request, opts.Source, opts.Flow, roles)
+	if err != nil {
+		return opts, nil, err
+	}
+	opts.Credentials = credentials
+	return opts, warnings, nil
+}
+
+// credentialsArgument reads the credentials parameter, naming the variables
+// or secrets of the roles of flow.
+func credentialsArgument(
+	request mcp.CallToolRequest,
+	source, flow string,
+	roles []credentialRole,
+) ([]authCredential, error) {
+	names, err := stringMapArgument(request, "credentials")
+	if err != nil {
+		return nil, err
+	}
+	for role := range names {
+		if !slices.ContainsFunc(roles, func(r credentialRole) bool { return r.Role == role }) {
+			valid := make([]string, 0, len(roles))
+			for _, r := range roles {
+				valid = append(valid, r.Role)
+			}
+			slices.Sort(valid)
+			return nil, fmt.Errorf("unknown credential %q for %s: use %s", role, flow, strings.Join(valid, ", "))
+		}
+	}
+	var credentials []authCredential
+	for _, r := range roles {
+		name, named := names[r.Role]
+		if r.Optional && !named {
+			continue
+		}
+		if !named {
+			name = r.Default
+			if source == credentialSecrets {
+				name = strings.ToLower(name)
+			}
+		}
+		if source == credentialEnv && !envVarName.MatchString(name) ||
+			source == credentialSecrets && !secretName.MatchString(name) {
+			return nil, fmt.Errorf("invalid name %q of %s: credentials holds the names of variables "+
+				"or secrets, never their values", name, r.Role)
+		}
+		credentials = append(credentials, authCredential{Role: r.Role, Name: name, variable: r.Variable})
+	}
+	return credentials, nil
+}
+
+// credentialReads writes the reading of credentials from source, failing
+// when one is not set.
+func credentialReads(w *codeWriter, credentials []authCredential, source string) {
+	for _, c := range credentials {
+		if source == credentialSecrets {
+			w.line(fmt.Sprintf("const %s = await secrets.get(%s);", c.variable, jsString(c.Name)))
+		} else {
+			w.line(fmt.Sprintf("const %s = __ENV.%s;", c.variable, c.Name))
+		}
+	}
+	for _, c := range credentials {
+		w.open(fmt.Sprintf("if (!%s) {", c.variable))
+		if source == credentialSecrets {
+			w.line(fmt.Sprintf("fail('the secret %s is not set');", c.Name))
+		} else {
+			w.line(fmt.Sprintf("fail('%s is not set');", c.Name))
+		}
+		w.close("}")
+	}
+}
+
+// isHTTPURL reports whether s is an absolute http or https URL.
+func isHTTPURL(s string) bool {
+	u, err := url.ParseRequestURI(s)
+	return err == nil && u.Host != "" && (u.Scheme == "http" || u.Scheme == "https")
+}
+
+// authScript writes the script of opts.
+func authScript(opts authOptions) string {
+	secrets := opts.Source == credentialSecrets
+	basic := opts.Flow != authSigV4 && opts.ClientAuth == authBasic
+
+	w := &codeWriter{}
+	w.line("// Generated by mcp-k6 for the " + opts.Flow + " flow. Credentials are read when the test runs:")
+	w.line("// none is written here.")
+	w.line("import http from 'k6/http';")
+	w.line("import { check, fail } from 'k6';")
+	if basic {
+		w.line("import encoding from 'k6/encoding';")
+	}
+	if secrets {
+		w.line("import secrets from 'k6/secrets';")
+	}
+	if opts.Flow == authSigV4 {
+		w.line(fmt.Sprintf("import { Endpoint, SignatureV4 } from '%s';", awsSignatureModule))
+	}
+	w.line("")
+
+	w.open("export const options = {")
+	w.line("vus: 1,")
+	w.line("iterations: 1,")
+	w.open("thresholds: {")
+	w.line("checks: ['rate>0.99'],")
+	w.close("},")
+	w.close("};")
+	w.line("")
+
+	switch {
+	case opts.Flow == authSigV4:
+		// The signature covers the path and query, so the origin is kept apart.
+		u, _ := url.Parse(opts.APIURL)
+		query := map[string]string{}
+		for k, v := range u.Query() {
+			query[k] = v[0]
+		}
+		w.line(fmt.Sprintf("const API_ORIGIN = __ENV.API_ORIGIN || %s;", jsString(u.Scheme+"://"+u.Host)))
+		w.line(fmt.Sprintf("const API_PATH = %s;", jsString(u.EscapedPath())))
+		w.line(fmt.Sprintf("const API_QUERY = %s;", jsValue(query, "")))
+		w.line(fmt.Sprintf("const AWS_SERVICE = %s;", jsString(opts.Service)))
+		if opts.Region != "" {
+			w.line(fmt.Sprintf("const AWS_REGION = __ENV.AWS_REGION || %s;", jsString(opts.Region)))
+		} else {
+			w.line("const AWS_REGION = __ENV.AWS_REGION;")
+		}
+	case opts.Issuer != "":
+		w.line(fmt.Sprintf("const API_URL = __ENV.API_URL || %s;", jsString(opts.APIURL)))
+		w.line(fmt.Sprintf("const OIDC_ISSUER = __ENV.OIDC_ISSUER || %s;", jsString(opts.Issuer)))
+	default:
+		w.line(fmt.Sprintf("const API_URL = __ENV.API_URL || %s;", jsString(opts.APIURL)))
+		w.line(fmt.Sprintf("const TOKEN_URL = __ENV.TOKEN_URL || %s;", jsString(opts.TokenURL)))
+	}
+	w.line("")
+
+	if secrets {
+		w.open("export async function setup() {")
+	} else {
+		w.open("export function setup() {")
+	}
+	credentialReads(w, opts.Credentials, opts.Source)
+	if opts.Flow == authSigV4 {
+		authSigV4Setup(w, opts)
+	} else {
+		authTokenSetup(w, opts)
+	}
+	w.close("}")
+	w.line("")
+
+	w.open("export default function (data) {")
+	if opts.Flow == authSigV4 {
+		w.line("const request = signedRequest(data, 'GET', API_PATH, API_QUERY);")
+		w.line("const res = http.get(request.url, { headers: request.headers });")
+	} else {
+		w.line("const res = http.get(API_URL, { headers: { Authorization: `${data.tokenType} ${data.accessToken}` } });")
+	}
+	w.line("check(res, { 'request is authorized': (r) => r.status !== 401 && r.status !== 403 });")
+	w.close("}")
+
+	if opts.Flow == authSigV4 {
+		authSigV4Helper(w)
+	}
+	return w.String()
+}
+
+// authTokenSetup writes the token request of OAuth2 flows, ending setup().
+func authTokenSetup(w *codeWriter, opts authOptions) {
+	if opts.Issuer != "" {
+		w.line("")
+		w.line("const discovery = http.get(`${OIDC_ISSUER}/.well-known/openid-configuration`, {")
+		w.line("  tags: { name: 'oidc_discovery' },")
+		w.line("});")
+		w.open("if (discovery.status !== 200) {")
+		w.line("fail(`OIDC discovery failed with status ${discovery.status}`);")
+		w.close("}")
+		w.line("const tokenURL = discovery.json('token_endpoint');")
+	} else {
+		w.line("const tokenURL = TOKEN_URL;")
+	}
+	w.line("")
+
+	has := func(role string) bool {
+		return slices.ContainsFunc(opts.Credentials, func(c authCredential) bool { return c.Role == role })
+	}
+	w.open("const body = {")
+	w.line(fmt.Sprintf("grant_type: '%s',", opts.Flow))
+	switch opts.Flow {
+	case authPassword:
+		w.line("username,")
+		w.line("password,")
+	case authRefreshToken:
+		w.line("refresh_token: refreshToken,")
+	}
+	if opts.ClientAuth == authBody {
+		w.line("client_id: clientId,")
+		if has("client_secret") {
+			w.line("client_secret: clientSecret,")
+		}
+	}
+	if opts.Scope != "" {
+		w.line(fmt.Sprintf("scope: %s,", jsString(opts.Scope)))
+	}
+	if opts.Audience != "" {
+		w.line(fmt.Sprintf("audience: %s,", jsString(opts.Audience)))
+	}
+	w.close("};")
+	w.line("const params = { tags: { name: 'token' } };")
+	if opts.ClientAuth == authBasic {
+		secret := ""
+		if has("client_secret") {
+			secret = "${encodeURIComponent(clientSecret)}"
+		}
+		w.line(fmt.Sprintf("const basic = encoding.b64encode(`${encodeURIComponent(clientId)}:%s`);", secret))
+		w.line("params.headers = { Authorization: `Basic ${basic}` };")
+	}
+	w.line("// k6 sends objects form-encoded, as token endpoints expect.")
+	w.line("const res = http.post(tokenURL, body, params);")
+	w.open("if (res.status !== 200) {")
+	w.line("// The body is not logged: error responses may echo credentials.")
+	w.line("fail(`token request failed with status ${res.status}`);")
+	w.close("}")
+	w.line("const token = res.json();")
+	w.open("return {")
+	w.line("accessToken: token.access_token,")
+	w.line("tokenType: token.token_type || 'Bearer',")
+	w.line("expiresAt: Date.now() + (token.expires_in || 3600) * 1000,")
+	w.close("};")
+}
+
+// authSigV4Setup ends the setup() of aws_sigv4 scripts, handing the
+// credentials to the VUs.
+func authSigV4Setup(w *codeWriter, opts authOptions) {
+	w.open("if (!AWS_REGION) {")
+	w.line("fail('AWS_REGION is not set');")
+	w.close("}")
+	w.open("return {")
+	for _, c := range opts.Credentials {
+		w.line(c.variable + ",")
+	}
+	w.close("};")
+}
+
+// authSigV4Helper writes the signing helper of aws_sigv4 scripts.
+func authSigV4Helper(w *codeWriter) {
+	w.line("")
+	w.line("let signer;")
+	w.line("")
+	w.line("// signedRequest returns the URL and headers of a request to API_ORIGIN signed")
+	w.line("// with the credentials of data.")
+	w.open("function signedRequest(data, method, path, query = {}, body = '', headers = {}) {")
+	w.open("if (!signer) {")
+	w.open("signer = new SignatureV4({")
+	w.line("service: AWS_SERVICE,")
+	w.line("region: AWS_REGION,")
+	w.line("credentials: data,")
+	w.line("uriEscapePath: AWS_SERVICE !== 's3',")
+	w.line("applyChecksum: AWS_SERVICE === 's3',")
+	w.close("});")
+	w.close("}")
+	w.open("return signer.sign({")
+	w.line("method,")
+	w.line("endpoint: new Endpoint(API_ORIGIN),")
+	w.line("path,")
+	w.line("query,")
+	w.line("headers,")
+	w.line("body,")
+	w.close("});")
+	w.close("}")
+}
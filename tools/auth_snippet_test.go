@@ -0,0 +1,120 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateAuthSnippetClientCredentials(t *testing.T) {
+	t.Parallel()
+
+	result, err := generateAuthSnippet(t.Context(), newCallRequest(map[string]any{
+		"flow":        "client_credentials",
+		"token_url":   "https://auth.example.com/oauth/token",
+		"scope":       "orders:read",
+		"credentials": map[string]any{"client_id": "SHOP_CLIENT_ID"},
+	}))
+	require.NoError(t, err)
+	require.False(t, result.IsError, "unexpected tool error: %+v", result.Content)
+	var resp generateAuthSnippetResponse
+	decodeJSON(t, result, &resp)
+
+	assert.Equal(t, []authCredential{
+		{Role: "client_id", Name: "SHOP_CLIENT_ID"},
+		{Role: "client_secret", Name: "OAUTH_CLIENT_SECRET"},
+	}, resp.Credentials)
+	assert.Equal(t, "env", resp.Source)
+	assert.Contains(t, resp.Script, `const TOKEN_URL = __ENV.TOKEN_URL || "https://auth.example.com/oauth/token";`)
+	assert.Contains(t, resp.Script, "export function setup() {")
+	assert.Contains(t, resp.Script, "const clientId = __ENV.SHOP_CLIENT_ID;")
+	assert.Contains(t, resp.Script, "fail('OAUTH_CLIENT_SECRET is not set');")
+	assert.Contains(t, resp.Script, "grant_type: 'client_credentials',")
+	assert.Contains(t, resp.Script, "client_secret: clientSecret,")
+	assert.Contains(t, resp.Script, `scope: "orders:read",`)
+	assert.Contains(t, resp.Script, "Authorization: `${data.tokenType} ${data.accessToken}`")
+	assert.NotContains(t, resp.Script, "k6/encoding")
+}
+
+func TestGenerateAuthSnippetOIDCSecrets(t *testing.T) {
+	t.Parallel()
+
+	result, err := generateAuthSnippet(t.Context(), newCallRequest(map[string]any{
+		"flow":              "password",
+		"issuer":            "https://auth.example.com/realms/shop/",
+		"client_auth":       "basic",
+		"credential_source": "secrets",
+	}))
+	require.NoError(t, err)
+	require.False(t, result.IsError, "unexpected tool error: %+v", result.Content)
+	var resp generateAuthSnippetResponse
+	decodeJSON(t, result, &resp)
+
+	// client_secret is optional for the password grant.
+	assert.Len(t, resp.Credentials, 3)
+	assert.Contains(t, resp.Warnings[0], "deprecated")
+	assert.Contains(t, resp.Script, "import secrets from 'k6/secrets';")
+	assert.Contains(t, resp.Script, "import encoding from 'k6/encoding';")
+	assert.Contains(t, resp.Script, "export async function setup() {")
+	assert.Contains(t, resp.Script, `const password = await secrets.get("oauth_password");`)
+	assert.Contains(t, resp.Script, `const OIDC_ISSUER = __ENV.OIDC_ISSUER || "https://auth.example.com/realms/shop";`)
+	assert.Contains(t, resp.Script, "const tokenURL = discovery.json('token_endpoint');")
+	assert.Contains(t, resp.Script, "const basic = encoding.b64encode(`${encodeURIComponent(clientId)}:`);")
+	assert.NotContains(t, resp.Script, "client_id: clientId,")
+}
+
+func TestGenerateAuthSnippetSigV4(t *testing.T) {
+	t.Parallel()
+
+	result, err := generateAuthSnippet(t.Context(), newCallRequest(map[string]any{
+		"flow":        "aws_sigv4",
+		"aws_service": "execute-api",
+		"aws_region":  "eu-west-1",
+		"api_url":     "https://abc.execute-api.eu-west-1.amazonaws.com/prod/orders?limit=10",
+	}))
+	require.NoError(t, err)
+	require.False(t, result.IsError, "unexpected tool error: %+v", result.Content)
+	var resp generateAuthSnippetResponse
+	decodeJSON(t, result, &resp)
+
+	assert.Len(t, resp.Credentials, 2)
+	assert.Contains(t, resp.Script, "import { Endpoint, SignatureV4 } from '"+awsSignatureModule+"';")
+	assert.Contains(t, resp.Script,
+		`const API_ORIGIN = __ENV.API_ORIGIN || "https://abc.execute-api.eu-west-1.amazonaws.com";`)
+	assert.Contains(t, resp.Script, `const API_PATH = "/prod/orders";`)
+	assert.Contains(t, resp.Script, `"limit": "10"`)
+	assert.Contains(t, resp.Script, `const AWS_REGION = __ENV.AWS_REGION || "eu-west-1";`)
+	assert.Contains(t, resp.Script, "const accessKeyId = __ENV.AWS_ACCESS_KEY_ID;")
+	assert.NotContains(t, resp.Script, "AWS_SESSION_TOKEN")
+	assert.Contains(t, resp.Script, "const request = signedRequest(data, 'GET', API_PATH, API_QUERY);")
+}
+
+func TestGenerateAuthSnippetErrors(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		args map[string]any
+		want string
+	}{
+		{map[string]any{"flow": "implicit"}, `invalid flow "implicit"`},
+		{map[string]any{"flow": "client_credentials"}, "provide either token_url or issuer"},
+		{map[string]any{"flow": "aws_sigv4"}, "aws_service is required"},
+		{
+			map[string]any{"flow": "refresh_token", "token_url": "https://a.example.com/token",
+				"credentials": map[string]any{"username": "USER"}},
+			`unknown credential "username" for refresh_token`,
+		},
+		{
+			map[string]any{"flow": "client_credentials", "token_url": "https://a.example.com/token",
+				"credentials": map[string]any{"client_secret": "s3cr3t value!"}},
+			"never their values",
+		},
+	} {
+		result, err := generateAuthSnippet(t.Context(), newCallRequest(tc.args))
+		require.NoError(t, err)
+		require.True(t, result.IsError, tc.want)
+		assert.Contains(t, result.Content[0].(mcp.TextContent).Text, tc.want)
+	}
+}
@@ -0,0 +1,202 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+
+	"github.com/grafana/mcp-k6/internal/logging"
+	"github.com/grafana/mcp-k6/internal/scenario"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// BuildScenarioTool exposes a tool for building executor-correct k6 scenarios.
+//
+//nolint:gochecknoglobals // Shared tool definition registered at startup.
+var BuildScenarioTool = mcp.NewTool(
+	"build_scenario",
+	readOnlyTool(),
+	mcp.WithDescription(
+		"Build a k6 'scenarios' options block for one executor. Takes the executor and its shape "+
+			"(VUs, iterations, rate, duration, stages) and returns a validated options object ready to paste "+
+			"into a script. Rejects parameters the executor does not accept and reports missing required ones, "+
+			"so the result always matches what k6 expects. Executors: shared-iterations and per-vu-iterations "+
+			"(vus, iterations, max_duration); constant-vus (vus, duration); ramping-vus (start_vus, stages, "+
+			"graceful_ramp_down); constant-arrival-rate (rate, time_unit, duration, pre_allocated_vus, "+
+			"max_vus); ramping-arrival-rate (start_rate, time_unit, stages, pre_allocated_vus, max_vus).",
+	),
+	mcp.WithString(
+		"executor",
+		mcp.Required(),
+		mcp.Description("The k6 executor."),
+		mcp.Enum(scenario.Executors...),
+	),
+	mcp.WithString(
+		"name",
+		mcp.Description("Scenario name (default: 'default')."),
+	),
+	mcp.WithNumber("vus", mcp.Description("Number of VUs (iteration and constant-vus executors).")),
+	mcp.WithNumber("iterations", mcp.Description("Iterations: shared in total, or per VU for per-vu-iterations.")),
+	mcp.WithString("duration", mcp.Description("Duration of constant executors, e.g. '1m'.")),
+	mcp.WithString("max_duration", mcp.Description("Hard limit for iteration executors (k6 default: '10m').")),
+	mcp.WithNumber("rate", mcp.Description("Iterations started per time_unit (constant-arrival-rate).")),
+	mcp.WithString(
+		"time_unit",
+		mcp.Description("Period of rate and stage targets for arrival-rate executors (k6 default: '1s')."),
+	),
+	mcp.WithNumber("pre_allocated_vus", mcp.Description("VUs initialized before an arrival-rate test starts.")),
+	mcp.WithNumber("max_vus", mcp.Description("Upper bound of VUs an arrival-rate test may add while running.")),
+	mcp.WithNumber("start_vus", mcp.Description("VUs at the start of ramping-vus (k6 default: 1).")),
+	mcp.WithNumber("start_rate", mcp.Description("Rate at the start of ramping-arrival-rate (k6 default: 0).")),
+	mcp.WithArray(
+		"stages",
+		mcp.Description("Stages of ramping executors, e.g. [{\"duration\": \"30s\", \"target\": 10}]. "+
+			"Targets are VUs for ramping-vus and iterations per time_unit for ramping-arrival-rate."),
+		mcp.Items(map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"duration": map[string]any{"type": "string"},
+				"target":   map[string]any{"type": "number"},
+			},
+			"required": []string{"duration", "target"},
+		}),
+	),
+	mcp.WithString(
+		"graceful_ramp_down",
+		mcp.Description("Time running iterations get to finish when ramping-vus scales down."),
+	),
+	mcp.WithString("start_time", mcp.Description("Delay before the scenario starts, relative to the test start.")),
+	mcp.WithString(
+		"graceful_stop",
+		mcp.Description("Time running iterations get to finish when the scenario ends (k6 default: '30s')."),
+	),
+	mcp.WithString("exec", mcp.Description("Exported function the scenario runs (default: the default export).")),
+)
+
+// buildScenarioResponse is the build_scenario tool result.
+type buildScenarioResponse struct {
+	// Options is the k6 options object holding the scenario.
+	Options map[string]any `json:"options"`
+	// Script is Options as a JavaScript statement.
+	Script    string   `json:"script"`
+	PeakVUs   int      `json:"peak_vus"`
+	Warnings  []string `json:"warnings,omitempty"`
+	NextSteps []string `json:"next_steps"`
+}
+
+// RegisterBuildScenarioTool registers the build_scenario tool with the MCP server.
+func RegisterBuildScenarioTool(s *server.MCPServer) {
+	s.AddTool(BuildScenarioTool, withToolLogger("build_scenario", buildScenario))
+}
+
+func buildScenario(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger := logging.LoggerFromContext(ctx)
+
+	executor, err := request.RequireString("executor")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	name := request.GetString("name", "default")
+	if err := scenario.ValidateName(name); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	params, err := scenarioParams(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	sc, warnings, err := scenario.Build(executor, params)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if peak := sc.PeakVUs(); peak > MaxVUs {
+		warnings = append(warnings, fmt.Sprintf(
+			"the scenario may use %d VUs, more than the %d run_script allows", peak, MaxVUs))
+	}
+
+	options := map[string]any{"scenarios": map[string]any{name: sc}}
+	data, err := json.MarshalIndent(options, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal scenario: %w", err)
+	}
+
+	logger.InfoContext(ctx, "Scenario built",
+		slog.String("executor", executor),
+		slog.Int("warnings", len(warnings)))
+
+	return marshalResponse(ctx, logger, buildScenarioResponse{
+		Options:  options,
+		Script:   "export const options = " + string(data) + ";",
+		PeakVUs:  sc.PeakVUs(),
+		Warnings: warnings,
+		NextSteps: []string{
+			"Merge the scenarios block into the script's exported options with apply_patch or write_script",
+			"Use explain_options to confirm the scenario is the effective execution",
+			"Use plan_run before run_script: its vus, duration and iterations flags replace the script's scenarios",
+		},
+	})
+}
+
+// scenarioParams reads the shape parameters of a build_scenario request.
+func scenarioParams(request mcp.CallToolRequest) (scenario.Params, error) {
+	p := scenario.Params{
+		Duration:         request.GetString("duration", ""),
+		MaxDuration:      request.GetString("max_duration", ""),
+		TimeUnit:         request.GetString("time_unit", ""),
+		GracefulRampDown: request.GetString("graceful_ramp_down", ""),
+		StartTime:        request.GetString("start_time", ""),
+		GracefulStop:     request.GetString("graceful_stop", ""),
+		Exec:             request.GetString("exec", ""),
+	}
+
+	ints := map[string]**int{
+		"vus":               &p.VUs,
+		"iterations":        &p.Iterations,
+		"rate":              &p.Rate,
+		"pre_allocated_vus": &p.PreAllocatedVUs,
+		"max_vus":           &p.MaxVUs,
+		"start_vus":         &p.StartVUs,
+		"start_rate":        &p.StartRate,
+	}
+	args := request.GetArguments()
+	for name, dst := range ints {
+		v, err := optionalInt(args, name)
+		if err != nil {
+			return scenario.Params{}, err
+		}
+		*dst = v
+	}
+
+	if raw, ok := args["stages"]; ok && raw != nil {
+		data, err := json.Marshal(raw)
+		if err != nil {
+			return scenario.Params{}, fmt.Errorf("invalid stages: %w", err)
+		}
+		p.Stages = []scenario.Stage{}
+		if err := json.Unmarshal(data, &p.Stages); err != nil {
+			return scenario.Params{}, fmt.Errorf("stages must be a list of {duration, target}: %w", err)
+		}
+	}
+	return p, nil
+}
+
+// optionalInt returns the integer parameter name, or nil when it is absent.
+func optionalInt(args map[string]any, name string) (*int, error) {
+	raw, ok := args[name]
+	if !ok || raw == nil {
+		return nil, nil
+	}
+	switch v := raw.(type) {
+	case int:
+		return &v, nil
+	case float64:
+		if v == math.Trunc(v) {
+			n := int(v)
+			return &n, nil
+		}
+	}
+	return nil, fmt.Errorf("'%s' must be a whole number", name)
+}
@@ -0,0 +1,61 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildScenario(t *testing.T) {
+	t.Parallel()
+
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]any{
+		"executor":          "ramping-arrival-rate",
+		"name":              "spike",
+		"pre_allocated_vus": float64(10),
+		"max_vus":           float64(80),
+		"stages": []any{
+			map[string]any{"duration": "30s", "target": float64(100)},
+			map[string]any{"duration": "30s", "target": float64(0)},
+		},
+	}
+
+	result, err := buildScenario(context.Background(), req)
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+
+	var resp buildScenarioResponse
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &resp))
+	assert.Equal(t, 80, resp.PeakVUs)
+	assert.Contains(t, resp.Script, `"executor": "ramping-arrival-rate"`)
+	assert.Contains(t, resp.Script, `"preAllocatedVUs": 10`)
+	require.Len(t, resp.Warnings, 1)
+	assert.Contains(t, resp.Warnings[0], "more than the 50 run_script allows")
+}
+
+func TestBuildScenarioRejectsForeignParameters(t *testing.T) {
+	t.Parallel()
+
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]any{
+		"executor": "constant-vus",
+		"vus":      float64(10),
+		"duration": "1m",
+		"stages":   []any{map[string]any{"duration": "30s", "target": float64(10)}},
+	}
+
+	result, err := buildScenario(context.Background(), req)
+	require.NoError(t, err)
+	require.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "constant-vus does not accept stages")
+
+	req.Params.Arguments = map[string]any{"executor": "constant-vus", "vus": 2.5, "duration": "1m"}
+	result, err = buildScenario(context.Background(), req)
+	require.NoError(t, err)
+	require.True(t, result.IsError)
+}
@@ -0,0 +1,129 @@
+package tools
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+
+	"github.com/grafana/mcp-k6/internal/logging"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// CancelledNotification is the method of the notification a client sends to
+// cancel a request in progress.
+const CancelledNotification = "notifications/cancelled"
+
+// requestIDMeta is the _meta field BeforeCallTool passes the JSON-RPC request
+// ID of a tool call in, as mcp-go does not pass it to tool handlers.
+const requestIDMeta = "mcp-k6/request-id"
+
+// Cancellations cancels the context of the tool calls a client cancels with
+// notifications/cancelled, which kills the k6 or terraform process they
+// run. Background and scheduled runs outlive the call that started them and
+// are stopped with stop_run instead.
+type Cancellations struct {
+	mu    sync.Mutex
+	calls map[callKey]context.CancelFunc
+}
+
+// callKey identifies a tool call in progress. Request IDs are only unique
+// within a session.
+type callKey struct {
+	session string
+	id      string
+}
+
+// NewCancellations returns an empty set of cancellable tool calls.
+func NewCancellations() *Cancellations {
+	return &Cancellations{calls: make(map[callKey]context.CancelFunc)}
+}
+
+// HandleCancelled cancels the tool call a CancelledNotification names, if it
+// is still in progress.
+func (c *Cancellations) HandleCancelled(ctx context.Context, notification mcp.JSONRPCNotification) {
+	id, ok := notification.Params.AdditionalFields["requestId"]
+	if !ok {
+		return
+	}
+	key := callKey{session: sessionID(ctx), id: mcp.NewRequestId(id).String()}
+	c.mu.Lock()
+	cancel, ok := c.calls[key]
+	c.mu.Unlock()
+	if !ok {
+		return
+	}
+	reason, _ := notification.Params.AdditionalFields["reason"].(string)
+	logging.LoggerFromContext(ctx).InfoContext(ctx, "Tool call cancelled by the client",
+		slog.String("request_id", key.id), slog.String("reason", reason))
+	cancel()
+}
+
+// BeforeCallTool is the hook passing the request ID of a tool call on to the
+// CancelOnRequest middleware.
+func (*Cancellations) BeforeCallTool(_ context.Context, id any, request *mcp.CallToolRequest) {
+	key := requestIDString(id)
+	if key == "" {
+		return
+	}
+	if request.Params.Meta == nil {
+		request.Params.Meta = &mcp.Meta{}
+	}
+	if request.Params.Meta.AdditionalFields == nil {
+		request.Params.Meta.AdditionalFields = make(map[string]any)
+	}
+	request.Params.Meta.AdditionalFields[requestIDMeta] = key
+}
+
+// CancelOnRequest returns a middleware running each tool call with a context
+// HandleCancelled can cancel.
+func CancelOnRequest(c *Cancellations) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return c.track(next)
+	}
+}
+
+func (c *Cancellations) track(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		var id string
+		if request.Params.Meta != nil {
+			id, _ = request.Params.Meta.AdditionalFields[requestIDMeta].(string)
+		}
+		if id == "" {
+			return next(ctx, request)
+		}
+
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		key := callKey{session: sessionID(ctx), id: id}
+		c.mu.Lock()
+		c.calls[key] = cancel
+		c.mu.Unlock()
+		defer func() {
+			c.mu.Lock()
+			delete(c.calls, key)
+			c.mu.Unlock()
+		}()
+		return next(ctx, request)
+	}
+}
+
+// requestIDString returns the key of a JSON-RPC request ID, matching the
+// ID of the same request in a notification.
+func requestIDString(id any) string {
+	requestID, ok := id.(mcp.RequestId)
+	if !ok {
+		requestID = mcp.NewRequestId(id)
+	}
+	if requestID.IsNil() {
+		return ""
+	}
+	return requestID.String()
+}
+
+func sessionID(ctx context.Context) string {
+	if session := server.ClientSessionFromContext(ctx); session != nil {
+		return session.SessionID()
+	}
+	return ""
+}
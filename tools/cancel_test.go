@@ -0,0 +1,87 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCancelOnRequest(t *testing.T) {
+	t.Parallel()
+
+	calls := NewCancellations()
+	hooks := &server.Hooks{}
+	hooks.AddBeforeCallTool(calls.BeforeCallTool)
+	s := server.NewMCPServer("k6", "test",
+		server.WithHooks(hooks),
+		server.WithToolHandlerMiddleware(CancelOnRequest(calls)),
+	)
+	s.AddNotificationHandler(CancelledNotification, calls.HandleCancelled)
+
+	started := make(chan struct{})
+	s.AddTool(mcp.NewTool("wait"), func(ctx context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		close(started)
+		<-ctx.Done()
+		return mcp.NewToolResultError(ctx.Err().Error()), nil
+	})
+
+	ctx := context.Background()
+	done := make(chan mcp.JSONRPCMessage)
+	go func() {
+		done <- s.HandleMessage(ctx, json.RawMessage(
+			`{"jsonrpc": "2.0", "id": 7, "method": "tools/call", "params": {"name": "wait"}}`))
+	}()
+	<-started
+
+	// Other requests are left running
+	s.HandleMessage(ctx, json.RawMessage(
+		`{"jsonrpc": "2.0", "method": "notifications/cancelled", "params": {"requestId": 8}}`))
+	select {
+	case <-done:
+		t.Fatal("call cancelled by the notification of another request")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	s.HandleMessage(ctx, json.RawMessage(
+		`{"jsonrpc": "2.0", "method": "notifications/cancelled", "params": {"requestId": 7, "reason": "user"}}`))
+	var response mcp.JSONRPCMessage
+	select {
+	case response = <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("call not cancelled")
+	}
+	data, err := json.Marshal(response)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), context.Canceled.Error())
+
+	calls.mu.Lock()
+	defer calls.mu.Unlock()
+	assert.Empty(t, calls.calls)
+}
+
+func TestCancelOnRequestWithoutHook(t *testing.T) {
+	t.Parallel()
+
+	handler := CancelOnRequest(NewCancellations())(
+		func(ctx context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return mcp.NewToolResultText("ok"), ctx.Err()
+		})
+	result, err := handler(context.Background(), mcp.CallToolRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, "ok", result.Content[0].(mcp.TextContent).Text)
+}
+
+func TestRequestIDString(t *testing.T) {
+	t.Parallel()
+
+	// Request IDs decoded from a notification match those of the request
+	assert.Equal(t, requestIDString(mcp.NewRequestId(int64(7))), mcp.NewRequestId(float64(7)).String())
+	assert.Equal(t, mcp.NewRequestId("abc").String(), requestIDString("abc"))
+	assert.Empty(t, requestIDString(nil))
+}
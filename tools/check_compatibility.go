@@ -0,0 +1,142 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/grafana/mcp-k6/internal/compat"
+	"github.com/grafana/mcp-k6/internal/k6env"
+	"github.com/grafana/mcp-k6/internal/logging"
+	"github.com/grafana/mcp-k6/internal/workspace"
+	"github.com/grafana/xk6-docs/docs"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// CheckCompatibilityTool exposes a tool for finding the k6 version a script
+// needs.
+//
+//nolint:gochecknoglobals // Shared tool definition registered at startup.
+var CheckCompatibilityTool = mcp.NewTool(
+	"check_compatibility",
+	readOnlyTool(),
+	mcp.WithDescription(
+		"Report the minimum k6 version a script needs: the oldest documentation version documenting each "+
+			"built-in module, API symbol, option and executor it uses, and whether the installed k6 is older. "+
+			"Use it before running a generated script on a k6 you did not install.",
+	),
+	mcp.WithString(
+		"script",
+		mcp.Description("The k6 script content to check. Provide either script or script_path."),
+	),
+	mcp.WithString(
+		"script_path",
+		mcp.Description(scriptPathDescription),
+	),
+	mcp.WithString(
+		"k6_version",
+		mcp.Description("Optional: the k6 version to check against, e.g. '1.3.0' (default: the installed k6)."),
+	),
+)
+
+// RegisterCheckCompatibilityTool registers the check_compatibility tool,
+// reading the documentation versions of catalog.
+func RegisterCheckCompatibilityTool(s *server.MCPServer, ws *workspace.Workspace, catalog *docs.Catalog) {
+	s.AddTool(CheckCompatibilityTool,
+		withToolLogger("check_compatibility", newCheckCompatibilityHandlerFunc(ws, catalog)))
+}
+
+type checkCompatibilityResponse struct {
+	*compat.Report
+	// K6Version is the version checked against, empty when it is unknown.
+	K6Version string `json:"k6_version,omitempty"`
+	// Compatible is false when K6Version is older than the required one.
+	Compatible *bool    `json:"compatible,omitempty"`
+	Warnings   []string `json:"warnings,omitempty"`
+	NextSteps  []string `json:"next_steps,omitempty"`
+}
+
+func newCheckCompatibilityHandlerFunc(ws *workspace.Workspace, catalog *docs.Catalog) server.ToolHandlerFunc {
+	checker := compat.NewChecker(catalog)
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		logger := logging.LoggerFromContext(ctx)
+
+		script, _, err := readScriptArgument(ctx, ws, request)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		report, err := checker.Check(ctx, script)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		resp := checkCompatibilityResponse{Report: report, K6Version: request.GetString("k6_version", "")}
+		if resp.K6Version == "" {
+			resp.K6Version = installedK6Version(ctx)
+		}
+		if resp.K6Version != "" && report.Required != "" {
+			compatible := compat.Compare(resp.K6Version, report.Required) >= 0
+			resp.Compatible = &compatible
+		}
+		resp.Warnings = compatibilityWarnings(resp)
+		resp.NextSteps = compatibilityNextSteps(resp)
+
+		logger.InfoContext(ctx, "Script compatibility checked",
+			slog.String("required_version", report.Required),
+			slog.String("k6_version", resp.K6Version),
+			slog.Int("features", len(report.Features)))
+		return structuredResponse(ctx, logger, resp)
+	}
+}
+
+// installedK6Version returns the version of the k6 on the PATH, or "" when
+// there is none.
+func installedK6Version(ctx context.Context) string {
+	info, err := k6env.Locate(ctx)
+	if err != nil {
+		return ""
+	}
+	version, err := info.Version(ctx)
+	if err != nil {
+		logging.LoggerFromContext(ctx).WarnContext(ctx, "Failed to get k6 version", slog.String("error", err.Error()))
+		return ""
+	}
+	return version
+}
+
+func compatibilityWarnings(resp checkCompatibilityResponse) []string {
+	var warnings []string
+	if resp.Compatible != nil && !*resp.Compatible {
+		var newer []string
+		for _, f := range resp.Features {
+			if compat.Compare(f.Since, resp.K6Version) > 0 {
+				newer = append(newer, fmt.Sprintf("%s (%s)", f.Name, f.Since))
+			}
+		}
+		warnings = append(warnings, fmt.Sprintf("k6 %s is older than %s, which the script needs for %s",
+			resp.K6Version, resp.Required, strings.Join(newer, ", ")))
+	}
+	for _, f := range resp.Undocumented {
+		warnings = append(warnings, fmt.Sprintf("The %s %s on line %d is not documented in %s: it was removed, "+
+			"renamed or misspelled", f.Kind, f.Name, f.Line, resp.Latest))
+	}
+	return warnings
+}
+
+func compatibilityNextSteps(resp checkCompatibilityResponse) []string {
+	var steps []string
+	if resp.Compatible != nil && !*resp.Compatible {
+		steps = append(steps, fmt.Sprintf("Upgrade k6 to %s or later, or rewrite the features it lacks "+
+			"with the documentation of its version (get_documentation with version)", resp.Required))
+	}
+	if len(resp.Undocumented) > 0 {
+		steps = append(steps, "Find the replacement of undocumented modules and executors with lookup_symbol "+
+			"or list_sections on javascript-api")
+	}
+	if resp.K6Version == "" {
+		steps = append(steps, "k6 was not found; pass k6_version to check the script against a given version")
+	}
+	return steps
+}
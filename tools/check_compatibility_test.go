@@ -0,0 +1,60 @@
+package tools
+
+import (
+	"encoding/json"
+	"testing"
+	"testing/fstest"
+
+	"github.com/grafana/xk6-docs/docs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckCompatibility(t *testing.T) {
+	t.Parallel()
+
+	fsys := fstest.MapFS{}
+	for version, sections := range map[string][]docs.Section{
+		"v0.52.x": {
+			{Slug: "javascript-api/k6-http", Title: "k6/http"},
+			{Slug: "javascript-api/k6-http/get", Title: "get( url, [params] )"},
+		},
+		"v1.4.x": {
+			{Slug: "javascript-api/k6-http", Title: "k6/http"},
+			{Slug: "javascript-api/k6-http/get", Title: "get( url, [params] )"},
+			{Slug: "javascript-api/k6-secrets", Title: "k6/secrets"},
+		},
+	} {
+		index, err := json.Marshal(docs.Index{Version: version, Sections: sections})
+		require.NoError(t, err)
+		fsys[version+"/sections.json"] = &fstest.MapFile{Data: index}
+	}
+	handler := newCheckCompatibilityHandlerFunc(nil, docs.NewCatalog(docs.WithFS(fsys)))
+
+	script := `import http from 'k6/http';
+import secrets from 'k6/secrets';
+import ws from 'k6/experimental/websockets';
+
+export default function () {
+  http.get('https://test.k6.io');
+}
+`
+	result, err := handler(t.Context(), newCallRequest(map[string]any{"script": script, "k6_version": "0.57.0"}))
+	require.NoError(t, err)
+	require.False(t, result.IsError, "unexpected tool error: %+v", result.Content)
+	var resp checkCompatibilityResponse
+	decodeJSON(t, result, &resp)
+	assert.Equal(t, "v1.4.x", resp.Required)
+	require.NotNil(t, resp.Compatible)
+	assert.False(t, *resp.Compatible)
+	require.Len(t, resp.Warnings, 2)
+	assert.Equal(t, "k6 0.57.0 is older than v1.4.x, which the script needs for k6/secrets (v1.4.x)", resp.Warnings[0])
+	assert.Contains(t, resp.Warnings[1], "k6/experimental/websockets on line 3 is not documented in v1.4.x")
+
+	result, err = handler(t.Context(), newCallRequest(map[string]any{"script": script, "k6_version": "v1.4.1"}))
+	require.NoError(t, err)
+	resp = checkCompatibilityResponse{}
+	decodeJSON(t, result, &resp)
+	require.NotNil(t, resp.Compatible)
+	assert.True(t, *resp.Compatible)
+}
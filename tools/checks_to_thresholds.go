@@ -0,0 +1,195 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/mcp-k6/internal/logging"
+	"github.com/grafana/mcp-k6/internal/scriptinfo"
+	"github.com/grafana/mcp-k6/internal/workspace"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// ChecksToThresholdsTool exposes a tool for turning a script's checks into thresholds.
+//
+//nolint:gochecknoglobals // Shared tool definition registered at startup.
+var ChecksToThresholdsTool = mcp.NewTool(
+	"checks_to_thresholds",
+	readOnlyTool(),
+	mcp.WithDescription(
+		"Generate thresholds on the checks metric from the check() calls of a k6 script, so that failing "+
+			"checks fail the run (non-zero exit code) instead of only appearing in the summary. Returns a "+
+			"thresholds object with one entry for all checks plus one per check name (checks{check:...}) or "+
+			"per group (checks{group:...}), skipping metrics the script already has thresholds for.",
+	),
+	mcp.WithString(
+		"script",
+		mcp.Description("The k6 script content. Provide either script or script_path."),
+	),
+	mcp.WithString(
+		"script_path",
+		mcp.Description(scriptPathDescription),
+	),
+	mcp.WithNumber(
+		"rate",
+		mcp.Description("Minimum pass rate each threshold requires, from 0 to 1 (default: 1, every check must pass)."),
+	),
+	mcp.WithString(
+		"granularity",
+		mcp.Description("'check' for one threshold per check name (default), 'group' for one per group, "+
+			"'total' for a single threshold on all checks."),
+		mcp.Enum("check", "group", "total"),
+	),
+	mcp.WithBoolean(
+		"abort_on_fail",
+		mcp.Description("Stop the test as soon as a threshold is crossed (abortOnFail)."),
+	),
+)
+
+// checksToThresholdsResponse is the JSON structure returned by the tool.
+type checksToThresholdsResponse struct {
+	Checks []scriptinfo.Check `json:"checks"`
+	// Thresholds maps threshold keys to k6 threshold definitions.
+	Thresholds map[string][]any `json:"thresholds"`
+	// Snippet is Thresholds as an options property to merge into the script.
+	Snippet   string   `json:"snippet"`
+	Skipped   []string `json:"skipped,omitempty"`
+	NextSteps []string `json:"next_steps"`
+}
+
+// RegisterChecksToThresholdsTool registers the checks_to_thresholds tool with the MCP server.
+func RegisterChecksToThresholdsTool(s *server.MCPServer, ws *workspace.Workspace) {
+	s.AddTool(ChecksToThresholdsTool, withToolLogger("checks_to_thresholds", newChecksToThresholdsHandlerFunc(ws)))
+}
+
+// newChecksToThresholdsHandlerFunc returns an MCP tool handler bound to a workspace.
+func newChecksToThresholdsHandlerFunc(ws *workspace.Workspace) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		logger := logging.LoggerFromContext(ctx)
+
+		script, _, err := readScriptArgument(ctx, ws, request)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		rate := request.GetFloat("rate", 1)
+		if rate < 0 || rate > 1 {
+			return mcp.NewToolResultError(fmt.Sprintf("rate must be between 0 and 1, got %v", rate)), nil
+		}
+
+		resp, err := checksToThresholds(script, rate,
+			request.GetString("granularity", "check"), request.GetBool("abort_on_fail", false))
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		logger.InfoContext(ctx, "Thresholds generated from checks",
+			slog.Int("checks", len(resp.Checks)),
+			slog.Int("thresholds", len(resp.Thresholds)),
+			slog.Int("skipped", len(resp.Skipped)))
+
+		return marshalResponse(ctx, logger, resp)
+	}
+}
+
+// checksToThresholds builds the thresholds for the checks of script.
+func checksToThresholds(
+	script string,
+	rate float64,
+	granularity string,
+	abortOnFail bool,
+) (*checksToThresholdsResponse, error) {
+	info := scriptinfo.Analyze(script)
+	checks := scriptinfo.Checks(script)
+	if len(checks) == 0 {
+		return nil, errors.New("the script has no check() calls with literal check names")
+	}
+
+	expression := "rate==1"
+	if rate < 1 {
+		expression = "rate>=" + strconv.FormatFloat(rate, 'f', -1, 64)
+	}
+	var threshold any = expression
+	if abortOnFail {
+		threshold = map[string]any{"threshold": expression, "abortOnFail": true}
+	}
+
+	existing := make(map[string]bool, len(info.Thresholds))
+	for _, th := range info.Thresholds {
+		existing[th.Metric] = true
+	}
+
+	resp := &checksToThresholdsResponse{Checks: checks, Thresholds: map[string][]any{}}
+	seen := make(map[string]bool)
+	add := func(key string) {
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+		if existing[key] {
+			resp.Skipped = append(resp.Skipped, key+": the script already defines this threshold")
+			return
+		}
+		resp.Thresholds[key] = []any{threshold}
+	}
+
+	add("checks")
+	for _, c := range checks {
+		var tag, value string
+		switch granularity {
+		case "total":
+			continue
+		case "group":
+			tag, value = "group", c.Group
+			if value == "" {
+				continue
+			}
+		default:
+			tag, value = "check", c.Name
+			if c.Dynamic {
+				resp.Skipped = append(resp.Skipped, fmt.Sprintf(
+					"line %d: check name %s is computed at runtime; the total checks threshold covers it", c.Line, c.Name))
+				continue
+			}
+		}
+		if !validTagValue(value) {
+			resp.Skipped = append(resp.Skipped, fmt.Sprintf(
+				"line %d: %q cannot be used in a threshold tag filter (no commas, braces or surrounding spaces)",
+				c.Line, value))
+			continue
+		}
+		add("checks{" + tag + ":" + value + "}")
+	}
+
+	data, err := json.MarshalIndent(map[string]any{"thresholds": resp.Thresholds}, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal thresholds: %w", err)
+	}
+	resp.Snippet = strings.TrimSuffix(strings.TrimPrefix(string(data), "{\n"), "\n}")
+
+	switch {
+	case info.Options == nil && info.RawOptions() == nil:
+		resp.NextSteps = append(resp.NextSteps,
+			"Add `export const options = { ... }` with the snippet to the script")
+	case len(info.Thresholds) > 0:
+		resp.NextSteps = append(resp.NextSteps,
+			"Merge the snippet's entries into the existing thresholds of the script's options")
+	default:
+		resp.NextSteps = append(resp.NextSteps, "Add the snippet to the script's exported options")
+	}
+	resp.NextSteps = append(resp.NextSteps,
+		"Use apply_patch or write_script to save the change, then validate_script to confirm k6 accepts it",
+		"Failing checks now make run_script report success=false with exit code 99")
+	return resp, nil
+}
+
+// validTagValue reports whether value survives k6's threshold tag filter
+// parsing, which splits on commas and ends at the closing brace.
+func validTagValue(value string) bool {
+	return value != "" && value == strings.TrimSpace(value) && !strings.ContainsAny(value, ",{}")
+}
@@ -0,0 +1,51 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const checksScript = `import http from 'k6/http';
+import { check, group } from 'k6';
+
+export const options = { thresholds: { 'checks{check:home ok}': ['rate>0.9'] } };
+
+export default function () {
+  group('login', () => {
+    const res = http.post('https://test.k6.io/login');
+    check(res, { 'status is 200': (r) => r.status === 200, 'a, b': (r) => true });
+  });
+  check(http.get('https://test.k6.io'), { 'home ok': (r) => r.status === 200 });
+}
+`
+
+func TestChecksToThresholds(t *testing.T) {
+	t.Parallel()
+
+	resp, err := checksToThresholds(checksScript, 1, "check", false)
+	require.NoError(t, err)
+	assert.Len(t, resp.Checks, 3)
+	assert.Equal(t, map[string][]any{
+		"checks":                      {"rate==1"},
+		"checks{check:status is 200}": {"rate==1"},
+	}, resp.Thresholds)
+	require.Len(t, resp.Skipped, 2)
+	assert.Contains(t, resp.Skipped[0], `"a, b" cannot be used`)
+	assert.Contains(t, resp.Skipped[1], "checks{check:home ok}: the script already defines")
+	assert.Contains(t, resp.Snippet, `"checks{check:status is 200}": [`)
+	assert.Contains(t, resp.NextSteps[0], "existing thresholds")
+}
+
+func TestChecksToThresholdsByGroup(t *testing.T) {
+	t.Parallel()
+
+	resp, err := checksToThresholds(checksScript, 0.95, "group", true)
+	require.NoError(t, err)
+	want := []any{map[string]any{"threshold": "rate>=0.95", "abortOnFail": true}}
+	assert.Equal(t, map[string][]any{"checks": want, "checks{group:::login}": want}, resp.Thresholds)
+
+	_, err = checksToThresholds("export default function () {}", 1, "check", false)
+	require.Error(t, err)
+}
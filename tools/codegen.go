@@ -0,0 +1,75 @@
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// jsString renders s as a JavaScript string literal. JSON string escaping is a
+// strict subset of what JavaScript accepts, so the output is always valid.
+// HTML characters such as & and < are kept as is, as scripts are not HTML.
+func jsString(s string) string {
+	var b strings.Builder
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(s); err != nil {
+		return `""`
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// jsValue renders v as an indented JavaScript literal, prefixing every line
+// after the first with indent so it can be embedded in generated code.
+func jsValue(v any, indent string) string {
+	data, err := json.MarshalIndent(v, indent, "  ")
+	if err != nil {
+		return "null"
+	}
+	return string(data)
+}
+
+// codeWriter accumulates generated script source line by line.
+type codeWriter struct {
+	b     strings.Builder
+	depth int
+}
+
+// line writes a single line at the current indentation level. Empty input
+// produces a blank line without trailing whitespace.
+func (w *codeWriter) line(s string) {
+	if s != "" {
+		w.b.WriteString(strings.Repeat("  ", w.depth))
+		w.b.WriteString(s)
+	}
+	w.b.WriteByte('\n')
+}
+
+// open writes s and increases the indentation level.
+func (w *codeWriter) open(s string) {
+	w.line(s)
+	w.depth++
+}
+
+// close decreases the indentation level and writes s.
+func (w *codeWriter) close(s string) {
+	if w.depth > 0 {
+		w.depth--
+	}
+	w.line(s)
+}
+
+func (w *codeWriter) String() string {
+	return w.b.String()
+}
+
+// uniqueIdentifier returns name, suffixed with a number when it is among
+// taken.
+func uniqueIdentifier(name string, taken []string) string {
+	unique := name
+	for n := 2; slices.Contains(taken, unique); n++ {
+		unique = fmt.Sprintf("%s%d", name, n)
+	}
+	return unique
+}
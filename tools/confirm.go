@@ -0,0 +1,139 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"github.com/grafana/mcp-k6/internal/approval"
+	"github.com/grafana/mcp-k6/internal/loadprofile"
+	"github.com/grafana/mcp-k6/internal/logging"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// confirmationTokenDescription documents the confirmation_token parameter.
+const confirmationTokenDescription = "Optional: the confirmation_token returned when the server requires " +
+	"confirmation for a high-load run. Ask the user first, then call again with the same parameters plus " +
+	"this token. Tokens are single-use."
+
+// confirmationResponse is returned instead of starting a run that needs
+// confirmation.
+type confirmationResponse struct {
+	Status            string   `json:"status"`
+	Reasons           []string `json:"reasons"`
+	PeakVUs           int      `json:"peak_vus"`
+	Duration          string   `json:"duration,omitempty"`
+	ConfirmationToken string   `json:"confirmation_token"`
+	ExpiresAt         string   `json:"expires_at"`
+	NextSteps         []string `json:"next_steps"`
+}
+
+// confirmRun holds back a run above the limits of gate. It returns the
+// "requires confirmation" result to send instead of starting the run, or
+// nil when the run may go on: it is within the limits, or the request
+// carries a token issued for the same parameters.
+func confirmRun(
+	ctx context.Context,
+	gate *approval.Gate,
+	request mcp.CallToolRequest,
+	script string,
+	options *RunOptions,
+) *mcp.CallToolResult {
+	if gate == nil {
+		return nil
+	}
+	// The user's answers to elicitation are not in the arguments: bind the
+	// token to the variables the run is given too
+	return confirmEffective(ctx, gate, request, script, plannedLoad(script, options), options.Env)
+}
+
+// confirmLoad holds back a run of load above the limits of gate, like
+// confirmRun, for tools that add up the load of several scripts. script is
+// what the token is bound to along with the arguments of the request.
+func confirmLoad(
+	ctx context.Context,
+	gate *approval.Gate,
+	request mcp.CallToolRequest,
+	script string,
+	load approval.Load,
+) *mcp.CallToolResult {
+	return confirmEffective(ctx, gate, request, script, load, nil)
+}
+
+// confirmEffective holds back a run of load above the limits of gate, with
+// a token bound to the request, script, load and env the run is given.
+func confirmEffective(
+	ctx context.Context,
+	gate *approval.Gate,
+	request mcp.CallToolRequest,
+	script string,
+	load approval.Load,
+	env map[string]string,
+) *mcp.CallToolResult {
+	if gate == nil {
+		return nil
+	}
+	logger := logging.LoggerFromContext(ctx)
+	reasons := gate.Check(load)
+	if len(reasons) == 0 {
+		return nil
+	}
+
+	fingerprint := requestFingerprint(request, script, load, env)
+	if token := request.GetString("confirmation_token", ""); token != "" {
+		if err := gate.Redeem(token, fingerprint); err != nil {
+			return mcp.NewToolResultError(err.Error())
+		}
+		logger.InfoContext(ctx, "High-load run confirmed", slog.Any("reasons", reasons))
+		return nil
+	}
+
+	token, expires := gate.Issue(fingerprint)
+	logger.InfoContext(ctx, "High-load run requires confirmation", slog.Any("reasons", reasons))
+	resp := confirmationResponse{
+		Status:            "requires_confirmation",
+		Reasons:           reasons,
+		PeakVUs:           load.VUs,
+		ConfirmationToken: token,
+		ExpiresAt:         expires.Format(time.RFC3339),
+		NextSteps: []string{
+			"Nothing was run. The server requires confirmation for runs " + gate.String(),
+			"Ask the user to confirm this load, then call " + request.Params.Name +
+				" again with the same parameters plus confirmation_token",
+			"Or lower vus or duration to stay within the limits; plan_run shows the load profile",
+		},
+	}
+	if load.Duration > 0 {
+		resp.Duration = load.Duration.String()
+	}
+	result, _ := structuredResponse(ctx, logger, resp)
+	return result
+}
+
+// plannedLoad returns the peak load k6 applies for the run, after the
+// precedence rules between the script's options and the call's.
+func plannedLoad(script string, options *RunOptions) approval.Load {
+	res, info := effectiveOptions(script, buildK6Args(inlineScriptPlaceholder, options))
+	peak := loadprofile.PeakOf(res, info)
+	return approval.Load{VUs: peak.VUs, Duration: peak.Duration, Unknown: peak.Unknown}
+}
+
+// requestFingerprint identifies a call by its tool, its arguments other
+// than the token, the script it runs, and the load and env it runs with,
+// so a token confirms only the request it was issued for, and not a script
+// edited or answers given differently in between.
+func requestFingerprint(request mcp.CallToolRequest, script string, load approval.Load, env map[string]string) string {
+	args := make(map[string]any)
+	for k, v := range request.GetArguments() {
+		if k != "confirmation_token" {
+			args[k] = v
+		}
+	}
+	data, _ := json.Marshal(args)
+	effective, _ := json.Marshal(struct {
+		Load approval.Load
+		Env  map[string]string
+	}{load, env})
+	return approval.Fingerprint(request.Params.Name, string(data), script, string(effective))
+}
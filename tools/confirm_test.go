@@ -0,0 +1,74 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/grafana/mcp-k6/internal/approval"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func confirmRequest(args map[string]any) mcp.CallToolRequest {
+	req := mcp.CallToolRequest{}
+	req.Params.Name = "run_script"
+	req.Params.Arguments = args
+	return req
+}
+
+func TestConfirmRun(t *testing.T) {
+	t.Parallel()
+
+	gate, err := approval.New(20, 2*time.Minute)
+	require.NoError(t, err)
+	ctx := context.Background()
+	options := &RunOptions{VUs: 40, Duration: "1m"}
+	args := map[string]any{"script": testRunScript, "vus": float64(40), "duration": "1m"}
+
+	assert.Nil(t, confirmRun(ctx, nil, confirmRequest(args), testRunScript, options))
+	assert.Nil(t, confirmRun(ctx, gate, confirmRequest(args), testRunScript, &RunOptions{VUs: 10, Duration: "1m"}))
+
+	result := confirmRun(ctx, gate, confirmRequest(args), testRunScript, options)
+	require.NotNil(t, result)
+	require.False(t, result.IsError)
+	var resp confirmationResponse
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &resp))
+	assert.Equal(t, "requires_confirmation", resp.Status)
+	assert.Equal(t, []string{"the run starts up to 40 VUs (limit 20)"}, resp.Reasons)
+	assert.Equal(t, 40, resp.PeakVUs)
+	assert.Equal(t, "1m0s", resp.Duration)
+	assert.Contains(t, resp.NextSteps[0], "more than 20 VUs or longer than 2m0s")
+
+	// The token only confirms the same request, once
+	args["confirmation_token"] = resp.ConfirmationToken
+	assert.Nil(t, confirmRun(ctx, gate, confirmRequest(args), testRunScript, options))
+	result = confirmRun(ctx, gate, confirmRequest(args), testRunScript, options)
+	require.NotNil(t, result)
+	assert.True(t, result.IsError)
+
+	delete(args, "confirmation_token")
+	result = confirmRun(ctx, gate, confirmRequest(args), testRunScript, options)
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &resp))
+	args["confirmation_token"] = resp.ConfirmationToken
+	args["vus"] = float64(45)
+	result = confirmRun(ctx, gate, confirmRequest(args), testRunScript, &RunOptions{VUs: 45, Duration: "1m"})
+	require.NotNil(t, result)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "different parameters")
+}
+
+func TestPlannedLoad(t *testing.T) {
+	t.Parallel()
+
+	script := `
+export const options = { stages: [{ duration: '3m', target: 80 }, { duration: '1m', target: 0 }] };
+export default function () {}
+`
+	// The vus and duration flags run_script passes replace the script's stages
+	assert.Equal(t, approval.Load{VUs: DefaultVUs, Duration: 30 * time.Second}, plannedLoad(script, &RunOptions{}))
+	assert.Equal(t, approval.Load{VUs: 45, Duration: 4 * time.Minute},
+		plannedLoad(script, &RunOptions{VUs: 45, Duration: "4m"}))
+}
@@ -0,0 +1,342 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"mime"
+	"net/url"
+	"strings"
+
+	"github.com/grafana/mcp-k6/internal/contract"
+	"github.com/grafana/mcp-k6/internal/har"
+	"github.com/grafana/mcp-k6/internal/logging"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// GenerateContractTestsTool exposes a tool for turning recorded responses
+// into checks of their contract.
+//
+//nolint:gochecknoglobals // Shared tool definition registered at startup.
+var GenerateContractTestsTool = mcp.NewTool(
+	"generate_contract_tests",
+	readOnlyTool(),
+	mcp.WithDescription(
+		"Generate a k6 script replaying recorded requests and checking that each response keeps its recorded "+
+			"contract: its status, content type and JSON shape (field names and types, optional fields, array "+
+			"elements). Examples of the same endpoint and status are merged, so fields missing from some of "+
+			"them are optional. Run the script with 1 VU as a contract test, or reuse its checks in a load test.",
+	),
+	mcp.WithString(
+		"recording",
+		mcp.Description("A HAR 1.2 recording, as exported by k6 Studio or browsers. "+
+			"Provide either recording or examples."),
+	),
+	mcp.WithArray(
+		"examples",
+		mcp.Description("Request/response pairs, e.g. [{\"method\": \"GET\", \"url\": \"https://api.example.com/users\", "+
+			"\"status\": 200, \"response_body\": {\"users\": []}}]. response_body is the JSON body, "+
+			"or the body as a string."),
+		mcp.Items(map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"method":        map[string]any{"type": "string"},
+				"url":           map[string]any{"type": "string"},
+				"headers":       map[string]any{"type": "object"},
+				"request_body":  map[string]any{"type": "string"},
+				"status":        map[string]any{"type": "number"},
+				"content_type":  map[string]any{"type": "string"},
+				"response_body": map[string]any{},
+			},
+			"required": []string{"url"},
+		}),
+	),
+	mcp.WithArray(
+		"hosts",
+		mcp.WithStringItems(),
+		mcp.Description("Optional: only use requests to these hosts (default: every host)."),
+	),
+)
+
+// RegisterGenerateContractTestsTool registers the generate_contract_tests tool
+// with the MCP server.
+func RegisterGenerateContractTestsTool(s *server.MCPServer) {
+	s.AddTool(GenerateContractTestsTool, withToolLogger("generate_contract_tests", generateContractTests))
+}
+
+// contractExample is an example of the examples parameter.
+type contractExample struct {
+	Method       string            `json:"method"`
+	URL          string            `json:"url"`
+	Headers      map[string]string `json:"headers"`
+	RequestBody  string            `json:"request_body"`
+	Status       int               `json:"status"`
+	ContentType  string            `json:"content_type"`
+	ResponseBody json.RawMessage   `json:"response_body"`
+}
+
+// contractEndpoint is an endpoint and status the script checks.
+type contractEndpoint struct {
+	Method   string `json:"method"`
+	Path     string `json:"path"`
+	Status   int    `json:"status,omitempty"`
+	Examples int    `json:"examples"`
+	// ContentType is the recorded media type, without parameters.
+	ContentType string `json:"content_type,omitempty"`
+	// Shape is the shape of the JSON bodies, nil for other bodies.
+	Shape *contract.Shape `json:"shape,omitempty"`
+
+	entry har.Entry
+	label string
+}
+
+// generateContractTestsResponse is the JSON structure returned by the tool.
+type generateContractTestsResponse struct {
+	Script    string             `json:"script"`
+	Endpoints []contractEndpoint `json:"endpoints"`
+	Warnings  []string           `json:"warnings,omitempty"`
+	NextSteps []string           `json:"next_steps"`
+}
+
+func generateContractTests(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger := logging.LoggerFromContext(ctx)
+
+	entries, err := contractEntries(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	endpoints, warnings := contractEndpoints(entries, request.GetStringSlice("hosts", nil))
+	if len(endpoints) == 0 {
+		return mcp.NewToolResultError("No API request left after skipping static assets and filtering hosts. " +
+			"Check the 'hosts' filter."), nil
+	}
+
+	script := generateContractScript(endpoints)
+
+	logger.InfoContext(ctx, "Contract tests generated",
+		slog.Int("entries", len(entries)),
+		slog.Int("endpoints", len(endpoints)),
+		slog.Int("script_size", len(script)))
+
+	return marshalResponse(ctx, logger, generateContractTestsResponse{
+		Script:    script,
+		Endpoints: endpoints,
+		Warnings:  warnings,
+		NextSteps: []string{
+			"Use validate_script to run the contract tests once; the checks threshold fails the run on a broken contract",
+			"Replace recorded dynamic values (IDs, tokens) in the requests with values extracted from responses",
+			"Reuse the check() calls and hasShape in a load test to catch contract breaks under load",
+		},
+	})
+}
+
+// contractEntries returns the recording or the examples of request as HAR
+// entries.
+func contractEntries(request mcp.CallToolRequest) ([]har.Entry, error) {
+	recording := request.GetString("recording", "")
+	raw, hasExamples := request.GetArguments()["examples"]
+	switch {
+	case recording != "" && hasExamples:
+		return nil, errors.New("provide only one of 'recording' and 'examples'")
+	case recording != "":
+		archive, err := har.Parse([]byte(recording))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse recording: %w", err)
+		}
+		return archive.Log.Entries, nil
+	case !hasExamples:
+		return nil, errors.New("provide 'recording' or 'examples'")
+	}
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid examples: %w", err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	var examples []contractExample
+	if err := dec.Decode(&examples); err != nil {
+		return nil, fmt.Errorf("invalid examples: %w", err)
+	}
+	if len(examples) == 0 {
+		return nil, errors.New("examples is empty")
+	}
+
+	entries := make([]har.Entry, 0, len(examples))
+	for i, ex := range examples {
+		if _, err := url.ParseRequestURI(ex.URL); err != nil {
+			return nil, fmt.Errorf("examples[%d]: invalid url %q", i, ex.URL)
+		}
+		e := har.Entry{Request: har.Request{Method: ex.Method, URL: ex.URL}, Response: har.Response{Status: ex.Status}}
+		if e.Request.Method == "" {
+			e.Request.Method = "GET"
+		}
+		for name, value := range ex.Headers {
+			e.Request.Headers = append(e.Request.Headers, har.NameValue{Name: name, Value: value})
+		}
+		if ex.RequestBody != "" {
+			e.Request.PostData = &har.PostData{Text: ex.RequestBody}
+		}
+		e.Response.Content.MimeType = ex.ContentType
+		if body := bytes.TrimSpace(ex.ResponseBody); len(body) > 0 {
+			var text string
+			if json.Unmarshal(body, &text) == nil {
+				e.Response.Content.Text = text
+			} else {
+				e.Response.Content.Text = string(body)
+				if e.Response.Content.MimeType == "" {
+					e.Response.Content.MimeType = "application/json"
+				}
+			}
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// contractEndpoints groups entries by method, path and status, in recording
+// order, and infers the shape of their JSON bodies.
+func contractEndpoints(entries []har.Entry, hosts []string) ([]contractEndpoint, []string) {
+	allowed := make(map[string]bool, len(hosts))
+	for _, h := range hosts {
+		allowed[strings.ToLower(strings.TrimSpace(h))] = true
+	}
+
+	var endpoints []contractEndpoint
+	var warnings []string
+	index := make(map[string]int)
+	for _, e := range entries {
+		if (len(allowed) > 0 && !allowed[strings.ToLower(e.Host())]) || isStaticAsset(e) {
+			continue
+		}
+		method := strings.ToUpper(e.Request.Method)
+		path := e.Request.URL
+		if u, err := url.Parse(e.Request.URL); err == nil {
+			path = u.Path
+		}
+		key := fmt.Sprintf("%s %s %d", method, path, e.Response.Status)
+		i, ok := index[key]
+		if !ok {
+			i = len(endpoints)
+			index[key] = i
+			endpoints = append(endpoints, contractEndpoint{Method: method, Path: path, Status: e.Response.Status, entry: e})
+		}
+		ep := &endpoints[i]
+		ep.Examples++
+
+		mediaType, _, _ := mime.ParseMediaType(e.Response.Content.MimeType)
+		if ep.ContentType == "" {
+			ep.ContentType = mediaType
+		}
+		if !strings.Contains(mediaType, "json") {
+			continue
+		}
+		body := []byte(e.Response.Content.Text)
+		if e.Response.Content.Encoding == "base64" {
+			decoded, err := base64.StdEncoding.DecodeString(e.Response.Content.Text)
+			if err != nil {
+				continue
+			}
+			body = decoded
+		}
+		if len(bytes.TrimSpace(body)) == 0 {
+			continue
+		}
+		shape, err := contract.Infer(body)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("%s %s: the %s body is not valid JSON (%v); its shape is not checked",
+				method, e.Request.URL, mediaType, err))
+			continue
+		}
+		ep.Shape = contract.Merge(ep.Shape, shape)
+	}
+
+	labels := make(map[string]int)
+	for _, ep := range endpoints {
+		labels[ep.Method+" "+ep.Path]++
+	}
+	for i := range endpoints {
+		ep := &endpoints[i]
+		ep.label = ep.Method + " " + ep.Path
+		if labels[ep.label] > 1 {
+			ep.label += fmt.Sprintf(" (%d)", ep.Status)
+		}
+	}
+	return endpoints, warnings
+}
+
+// generateContractScript writes a script replaying the first example of each
+// endpoint and checking its contract.
+func generateContractScript(endpoints []contractEndpoint) string {
+	w := &codeWriter{}
+	w.line("// Generated by mcp-k6 from recorded responses. Review before running with load.")
+	w.line("import http from 'k6/http';")
+	w.line("import { check } from 'k6';")
+	w.line("")
+	w.open("export const options = {")
+	w.line("vus: 1,")
+	w.line("iterations: 1,")
+	w.open("thresholds: {")
+	w.line("// Any broken contract fails the test.")
+	w.line("checks: ['rate==1.0'],")
+	w.close("},")
+	w.close("};")
+
+	names := make([]string, len(endpoints))
+	var taken []string
+	for i, ep := range endpoints {
+		if ep.Shape == nil {
+			continue
+		}
+		if len(taken) == 0 {
+			w.line("")
+			w.line("// shapes are the JSON shapes of the recorded response bodies.")
+			w.open("const shapes = {")
+		}
+		names[i] = uniqueIdentifier(jsIdentifier(strings.ToLower(ep.Method)+" "+ep.Path), taken)
+		taken = append(taken, names[i])
+		w.line(fmt.Sprintf("%s: %s,", names[i], ep.Shape.JS()))
+	}
+	if len(taken) > 0 {
+		w.close("};")
+	}
+
+	w.line("")
+	w.open("export default function () {")
+	w.line("let res;")
+	for i, ep := range endpoints {
+		w.line("")
+		if ep.Examples == 1 {
+			w.line(fmt.Sprintf("// %s (1 example)", ep.label))
+		} else {
+			w.line(fmt.Sprintf("// %s (%d examples)", ep.label, ep.Examples))
+		}
+		writeRecordedCall(w, ep.entry)
+		w.open("check(res, {")
+		if ep.Status > 0 {
+			w.line(fmt.Sprintf("%s: (r) => r.status === %d,", jsString(ep.label+" status is "+fmt.Sprint(ep.Status)),
+				ep.Status))
+		}
+		if ep.ContentType != "" {
+			w.line(fmt.Sprintf("%s: (r) => (r.headers['Content-Type'] || '').startsWith(%s),",
+				jsString(ep.label+" content type is "+ep.ContentType), jsString(ep.ContentType)))
+		}
+		if names[i] != "" {
+			w.line(fmt.Sprintf("%s: (r) => hasShape(r, shapes.%s),",
+				jsString(ep.label+" body has the recorded shape"), names[i]))
+		}
+		w.close("});")
+	}
+	w.close("}")
+
+	if len(taken) > 0 {
+		w.line("")
+		w.b.WriteString(contract.Validator)
+	}
+	return w.String()
+}
@@ -0,0 +1,65 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateContractTests(t *testing.T) {
+	t.Parallel()
+
+	result, err := generateContractTests(t.Context(), newCallRequest(map[string]any{
+		"examples": []any{
+			map[string]any{
+				"url": "https://quickpizza.grafana.com/api/pizza/1", "status": 200,
+				"response_body": map[string]any{"id": 1, "name": "margherita", "tags": []any{"vegetarian"}},
+			},
+			map[string]any{
+				"url": "https://quickpizza.grafana.com/api/pizza/1", "status": 200,
+				"response_body": map[string]any{"id": 1, "name": "margherita", "tags": []any{}, "chef": "Luigi"},
+			},
+			map[string]any{
+				"method": "POST", "url": "https://quickpizza.grafana.com/api/login",
+				"headers": map[string]any{"Content-Type": "application/json"}, "request_body": `{"user":"a"}`,
+				"status": 401, "content_type": "text/plain; charset=utf-8", "response_body": "unauthorized",
+			},
+		},
+	}))
+	require.NoError(t, err)
+	require.False(t, result.IsError, "unexpected tool error: %+v", result.Content)
+	var resp generateContractTestsResponse
+	decodeJSON(t, result, &resp)
+
+	require.Len(t, resp.Endpoints, 2)
+	assert.Equal(t, 2, resp.Endpoints[0].Examples)
+	assert.True(t, resp.Endpoints[0].Shape.Fields["chef"].Optional)
+	assert.Equal(t, "text/plain", resp.Endpoints[1].ContentType)
+	assert.Nil(t, resp.Endpoints[1].Shape)
+
+	assert.Contains(t, resp.Script, "checks: ['rate==1.0'],")
+	assert.Contains(t, resp.Script, "getApiPizza1: { \"chef?\": 'string', id: 'number', name: 'string', tags: ['string'] },")
+	assert.Contains(t, resp.Script, `res = http.get("https://quickpizza.grafana.com/api/pizza/1");`)
+	assert.Contains(t, resp.Script, `"GET /api/pizza/1 status is 200": (r) => r.status === 200,`)
+	assert.Contains(t, resp.Script, `"GET /api/pizza/1 body has the recorded shape": (r) => hasShape(r, shapes.getApiPizza1),`)
+	assert.Contains(t, resp.Script, `"POST /api/login content type is text/plain": `+
+		`(r) => (r.headers['Content-Type'] || '').startsWith("text/plain"),`)
+	assert.Contains(t, resp.Script, "function matchesShape(value, shape) {")
+}
+
+func TestGenerateContractTestsArguments(t *testing.T) {
+	t.Parallel()
+
+	for name, args := range map[string]map[string]any{
+		"none":     {},
+		"both":     {"recording": "{}", "examples": []any{}},
+		"bad url":  {"examples": []any{map[string]any{"url": "pizza"}}},
+		"unknown":  {"examples": []any{map[string]any{"url": "https://a.test", "body": "x"}}},
+		"no entry": {"recording": `{"log": {"entries": []}}`},
+	} {
+		result, err := generateContractTests(t.Context(), newCallRequest(args))
+		require.NoError(t, err, name)
+		assert.True(t, result.IsError, name)
+	}
+}
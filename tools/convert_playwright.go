@@ -0,0 +1,324 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/grafana/mcp-k6/internal/logging"
+	"github.com/grafana/mcp-k6/internal/playwright"
+	"github.com/grafana/mcp-k6/internal/workspace"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// ConvertPlaywrightTool exposes a tool for turning Playwright tests into k6
+// browser scripts.
+//
+//nolint:gochecknoglobals // Shared tool definition registered at startup.
+var ConvertPlaywrightTool = mcp.NewTool(
+	"convert_playwright",
+	readOnlyTool(),
+	mcp.WithDescription(
+		"Convert a Playwright test file into a k6 browser script, deterministically. Each test runs on a new "+
+			"k6/browser page, beforeEach and afterEach hooks are inlined, and expect assertions use the "+
+			"k6-testing jslib, whose API mirrors Playwright's. Statements without a k6 equivalent are left as "+
+			"comments and listed; convert them with the convert_playwright_script prompt.",
+	),
+	mcp.WithString(
+		"script",
+		mcp.Description("The Playwright test file content (JavaScript or TypeScript). "+
+			"Provide either script or script_path."),
+	),
+	mcp.WithString(
+		"script_path",
+		mcp.Description(scriptPathDescription),
+	),
+)
+
+// k6TestingModule is the jslib providing expect to converted tests.
+const k6TestingModule = "https://jslib.k6.io/k6-testing/0.5.0/index.js"
+
+//nolint:gochecknoglobals // Read-only lookup tables.
+var (
+	// playwrightUnsupported maps the methods convert_playwright leaves as
+	// comments to the reason.
+	playwrightUnsupported = map[string]string{
+		"toHaveScreenshot": "visual comparisons have no k6 equivalent",
+		"toMatchSnapshot":  "snapshot comparisons have no k6 equivalent",
+		"route":            "request interception is not converted",
+		"unroute":          "request interception is not converted",
+		"waitForResponse":  "waits for network events are not converted",
+		"waitForRequest":   "waits for network events are not converted",
+		"waitForEvent":     "waits for events are not converted",
+		"pause":            "page.pause() is a debugging aid; remove it",
+		"newContext":       "k6 runs one browser context at a time; open contexts and pages by hand",
+		"newPage":          "k6 runs one browser context at a time; open contexts and pages by hand",
+	}
+	// playwrightFixtures are the fixtures besides page, which converted tests
+	// do not get.
+	playwrightFixtures = []string{"context", "browser", "browserName", "request"}
+	reMethodCall       = regexp.MustCompile(`\.(\w+)\s*\(`)
+	reTestCall         = regexp.MustCompile(`\btest\.(\w+)`)
+)
+
+// RegisterConvertPlaywrightTool registers the convert_playwright tool with
+// the MCP server.
+func RegisterConvertPlaywrightTool(s *server.MCPServer, ws *workspace.Workspace) {
+	s.AddTool(ConvertPlaywrightTool, withToolLogger("convert_playwright", newConvertPlaywrightHandlerFunc(ws)))
+}
+
+// unconvertedStatement is a statement left as a comment.
+type unconvertedStatement struct {
+	playwright.Statement
+	Reason string `json:"reason"`
+}
+
+// playwrightSummary describes what was converted.
+type playwrightSummary struct {
+	Tests int `json:"tests"`
+	// Skipped are the titles of the tests not converted for test.skip,
+	// test.fixme or another test's test.only.
+	Skipped     []string               `json:"skipped,omitempty"`
+	Statements  int                    `json:"statements"`
+	Converted   int                    `json:"converted"`
+	Unconverted []unconvertedStatement `json:"unconverted,omitempty"`
+	// LocalImports are the local modules the tests import, to convert too.
+	LocalImports []string `json:"local_imports,omitempty"`
+}
+
+// convertPlaywrightResponse is the JSON structure returned by the tool.
+type convertPlaywrightResponse struct {
+	Script    string            `json:"script"`
+	Summary   playwrightSummary `json:"summary"`
+	NextSteps []string          `json:"next_steps"`
+}
+
+func newConvertPlaywrightHandlerFunc(ws *workspace.Workspace) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		logger := logging.LoggerFromContext(ctx)
+
+		source, _, err := readScriptArgument(ctx, ws, request)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		suite, err := playwright.Parse(source)
+		if errors.Is(err, playwright.ErrNoTests) {
+			return mcp.NewToolResultError("No Playwright test() call found; " +
+				"use the convert_playwright_script prompt for other Playwright scripts"), nil
+		}
+		if err != nil {
+			return mcp.NewToolResultError("Failed to parse Playwright test: " + err.Error()), nil
+		}
+
+		script, summary := generateScriptFromPlaywright(suite)
+		if summary.Tests == 0 {
+			return mcp.NewToolResultError("Every test is skipped; remove test.skip or test.fixme to convert them"), nil
+		}
+
+		logger.InfoContext(ctx, "Playwright test converted",
+			slog.Int("tests", summary.Tests),
+			slog.Int("statements", summary.Statements),
+			slog.Int("unconverted", len(summary.Unconverted)))
+
+		return marshalResponse(ctx, logger, convertPlaywrightResponse{
+			Script:    script,
+			Summary:   summary,
+			NextSteps: playwrightNextSteps(summary),
+		})
+	}
+}
+
+// generateScriptFromPlaywright converts the tests of suite into a k6 browser
+// script running them in order.
+func generateScriptFromPlaywright(suite *playwright.Suite) (string, playwrightSummary) {
+	var summary playwrightSummary
+	tests := suite.Tests
+	if slices.ContainsFunc(tests, func(t playwright.Test) bool { return t.Mode == playwright.ModeOnly }) {
+		tests = nil
+		for _, t := range suite.Tests {
+			if t.Mode == playwright.ModeOnly {
+				tests = append(tests, t)
+			} else {
+				summary.Skipped = append(summary.Skipped, t.Title)
+			}
+		}
+	}
+
+	body := &codeWriter{}
+	var names []string
+	needsExpect := false
+	for _, t := range tests {
+		if t.Mode == playwright.ModeSkip || t.Mode == playwright.ModeFixme {
+			summary.Skipped = append(summary.Skipped, t.Title)
+			continue
+		}
+		summary.Tests++
+		name := uniqueIdentifier(jsIdentifier("test "+t.Title), names)
+		names = append(names, name)
+
+		convert := func(stmts []playwright.Statement) {
+			for _, s := range stmts {
+				needsExpect = writePlaywrightStatement(body, s, t.Fixtures, &summary) || needsExpect
+			}
+		}
+		body.line("")
+		body.line("// " + t.Title)
+		body.open(fmt.Sprintf("async function %s(page) {", name))
+		if len(t.After) > 0 {
+			body.open("try {")
+		}
+		convert(t.Before)
+		convert(t.Body)
+		if len(t.After) > 0 {
+			body.close("} finally {")
+			body.depth++
+			convert(t.After)
+			body.close("}")
+		}
+		body.close("}")
+	}
+
+	w := &codeWriter{}
+	w.line("// Generated by mcp-k6 from a Playwright test. Review before running with load.")
+	w.line("import { browser } from 'k6/browser';")
+	if needsExpect {
+		w.line(fmt.Sprintf("import { expect } from '%s';", k6TestingModule))
+	}
+	for _, s := range suite.Imports {
+		if strings.Contains(s.Code, "playwright") {
+			summary.Unconverted = append(summary.Unconverted,
+				unconvertedStatement{Statement: s, Reason: "Playwright modules have no k6 equivalent"})
+			writeLines(w, s.Code, "// ")
+			continue
+		}
+		if module := importedModule(s.Code); strings.HasPrefix(module, ".") {
+			summary.LocalImports = append(summary.LocalImports, module)
+		}
+		writeLines(w, s.Code, "")
+	}
+	w.line("")
+	w.open("export const options = {")
+	w.open("scenarios: {")
+	w.open("ui: {")
+	w.line("executor: 'shared-iterations',")
+	w.open("options: {")
+	w.open("browser: {")
+	w.line("type: 'chromium',")
+	w.close("},")
+	w.close("},")
+	w.close("},")
+	w.close("},")
+	w.close("};")
+	for _, s := range suite.Other {
+		w.line("")
+		if m := reTestCall.FindStringSubmatch(s.Code); m != nil {
+			summary.Unconverted = append(summary.Unconverted, unconvertedStatement{
+				Statement: s,
+				Reason:    fmt.Sprintf("test.%s is not converted; only tests and their beforeEach and afterEach hooks are", m[1]),
+			})
+			writeLines(w, s.Code, "// ")
+			continue
+		}
+		writeLines(w, s.Code, "")
+	}
+	w.line("")
+	w.open("export default async function () {")
+	for _, name := range names {
+		w.line(fmt.Sprintf("await run(%s);", name))
+	}
+	w.close("}")
+	w.line("")
+	w.line("// run runs test on a new page, as Playwright gives each test its own page.")
+	w.open("async function run(test) {")
+	w.line("const page = await browser.newPage();")
+	w.open("try {")
+	w.line("await test(page);")
+	w.close("} finally {")
+	w.depth++
+	w.line("await page.close();")
+	w.close("}")
+	w.close("}")
+
+	return w.String() + body.String(), summary
+}
+
+// writePlaywrightStatement writes s, or comments it out when it has no k6
+// equivalent, and reports whether it uses expect.
+func writePlaywrightStatement(
+	w *codeWriter, s playwright.Statement, fixtures []string, summary *playwrightSummary,
+) bool {
+	if strings.HasPrefix(s.Code, "//") {
+		writeLines(w, s.Code, "")
+		return false
+	}
+	summary.Statements++
+	if reason := playwrightUnconvertible(s.Code, fixtures); reason != "" {
+		summary.Unconverted = append(summary.Unconverted, unconvertedStatement{Statement: s, Reason: reason})
+		w.line("// Not converted: " + reason)
+		writeLines(w, s.Code, "// ")
+		return false
+	}
+	summary.Converted++
+	writeLines(w, s.Code, "")
+	return strings.Contains(s.Code, "expect(") || strings.Contains(s.Code, "expect.")
+}
+
+// playwrightUnconvertible returns why code has no k6 equivalent, or "" when
+// it runs as is with k6/browser and k6-testing.
+func playwrightUnconvertible(code string, fixtures []string) string {
+	for _, m := range reMethodCall.FindAllStringSubmatch(code, -1) {
+		if reason, ok := playwrightUnsupported[m[1]]; ok {
+			return reason
+		}
+	}
+	for _, f := range fixtures {
+		if f != "page" && slices.Contains(playwrightFixtures, f) &&
+			regexp.MustCompile(`\b`+regexp.QuoteMeta(f)+`\b`).MatchString(code) {
+			return fmt.Sprintf("the %s fixture has no k6 equivalent in a converted test", f)
+		}
+	}
+	if m := reTestCall.FindStringSubmatch(code); m != nil {
+		return fmt.Sprintf("test.%s has no k6 equivalent", m[1])
+	}
+	return ""
+}
+
+// importedModule returns the module an import statement imports.
+func importedModule(code string) string {
+	i := strings.LastIndexAny(code, `'"`)
+	if i < 0 {
+		return ""
+	}
+	j := strings.LastIndexAny(code[:i], `'"`)
+	if j < 0 {
+		return ""
+	}
+	return code[j+1 : i]
+}
+
+// writeLines writes the lines of code, each after prefix.
+func writeLines(w *codeWriter, code, prefix string) {
+	for _, line := range strings.Split(code, "\n") {
+		w.line(strings.TrimRight(prefix+line, " "))
+	}
+}
+
+func playwrightNextSteps(summary playwrightSummary) []string {
+	steps := []string{"Use validate_script to check the converted script"}
+	if n := len(summary.Unconverted); n > 0 {
+		steps = append(steps, fmt.Sprintf("Convert the %d statements left as comments with the "+
+			"convert_playwright_script prompt, or with the Playwright APIs in k6 documentation "+
+			"(get_documentation on using-k6-browser)", n))
+	}
+	if len(summary.LocalImports) > 0 {
+		steps = append(steps, "Convert the local modules the tests import too: "+
+			strings.Join(summary.LocalImports, ", "))
+	}
+	return append(steps, "Use check_compatibility to find the k6 version the locators and assertions need",
+		"Add thresholds, such as on browser_web_vital_lcp, before running with load via run_script")
+}
@@ -0,0 +1,111 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvertPlaywright(t *testing.T) {
+	t.Parallel()
+
+	source := `import { test, expect } from '@playwright/test';
+
+test.beforeEach(async ({ page }) => {
+  await page.goto('https://quickpizza.grafana.com');
+});
+
+test('has title', async ({ page }) => {
+  await expect(page).toHaveTitle(/QuickPizza/);
+});
+
+test('recommends a pizza', async ({ page, context }) => {
+  await context.clearCookies();
+  await page.getByRole('button', { name: 'Pizza, Please!' }).click();
+  await expect(page).toHaveScreenshot();
+});
+
+test.fixme('delivers', async ({ page }) => {});
+`
+	handler := newConvertPlaywrightHandlerFunc(nil)
+	result, err := handler(t.Context(), newCallRequest(map[string]any{"script": source}))
+	require.NoError(t, err)
+	require.False(t, result.IsError, "unexpected tool error: %+v", result.Content)
+	var resp convertPlaywrightResponse
+	decodeJSON(t, result, &resp)
+
+	assert.Equal(t, `// Generated by mcp-k6 from a Playwright test. Review before running with load.
+import { browser } from 'k6/browser';
+import { expect } from 'https://jslib.k6.io/k6-testing/0.5.0/index.js';
+
+export const options = {
+  scenarios: {
+    ui: {
+      executor: 'shared-iterations',
+      options: {
+        browser: {
+          type: 'chromium',
+        },
+      },
+    },
+  },
+};
+
+export default async function () {
+  await run(testHasTitle);
+  await run(testRecommendsAPizza);
+}
+
+// run runs test on a new page, as Playwright gives each test its own page.
+async function run(test) {
+  const page = await browser.newPage();
+  try {
+    await test(page);
+  } finally {
+    await page.close();
+  }
+}
+
+// has title
+async function testHasTitle(page) {
+  await page.goto('https://quickpizza.grafana.com');
+  await expect(page).toHaveTitle(/QuickPizza/);
+}
+
+// recommends a pizza
+async function testRecommendsAPizza(page) {
+  await page.goto('https://quickpizza.grafana.com');
+  // Not converted: the context fixture has no k6 equivalent in a converted test
+  // await context.clearCookies();
+  await page.getByRole('button', { name: 'Pizza, Please!' }).click();
+  // Not converted: visual comparisons have no k6 equivalent
+  // await expect(page).toHaveScreenshot();
+}
+`, resp.Script)
+	assert.Equal(t, 2, resp.Summary.Tests)
+	assert.Equal(t, []string{"delivers"}, resp.Summary.Skipped)
+	assert.Equal(t, 6, resp.Summary.Statements)
+	assert.Equal(t, 4, resp.Summary.Converted)
+	require.Len(t, resp.Summary.Unconverted, 2)
+	assert.Equal(t, 12, resp.Summary.Unconverted[0].Line)
+	assert.Contains(t, resp.NextSteps[1], "convert_playwright_script prompt")
+}
+
+func TestConvertPlaywrightNoTests(t *testing.T) {
+	t.Parallel()
+
+	handler := newConvertPlaywrightHandlerFunc(nil)
+	result, err := handler(t.Context(), newCallRequest(map[string]any{
+		"script": "const { chromium } = require('playwright');\n",
+	}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+}
+
+func TestUniqueIdentifier(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "testPizzaIsDelicious", uniqueIdentifier(jsIdentifier("test pizza > is delicious!"), nil))
+	assert.Equal(t, "testLogin2", uniqueIdentifier("testLogin", []string{"testLogin"}))
+}
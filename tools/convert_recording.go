@@ -0,0 +1,357 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/grafana/mcp-k6/internal/har"
+	"github.com/grafana/mcp-k6/internal/logging"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// ConvertRecordingTool exposes a tool for turning browser recordings into k6 scripts.
+//
+//nolint:gochecknoglobals // Shared tool definition registered at startup.
+var ConvertRecordingTool = mcp.NewTool(
+	"convert_recording",
+	readOnlyTool(),
+	mcp.WithDescription(
+		"Convert a k6 Studio recording or a browser recorder HAR export into a k6 HTTP script. "+
+			"Requests are grouped by page, static assets are skipped by default, and recorded pauses "+
+			"become sleep() calls. Use validate_script on the result, then refine it "+
+			"(correlation, parameterization, checks) before running it with load.",
+	),
+	mcp.WithString(
+		"recording",
+		mcp.Required(),
+		mcp.Description("The recording content in HAR 1.2 format (the format k6 Studio and the browser recorder export)."),
+	),
+	mcp.WithArray(
+		"hosts",
+		mcp.WithStringItems(),
+		mcp.Description(
+			"Optional: only convert requests to these hosts (e.g., ['test.k6.io']). "+
+				"Defaults to every host in the recording.",
+		),
+	),
+	mcp.WithBoolean(
+		"include_static",
+		mcp.Description("Optional: keep requests for images, stylesheets, scripts and fonts (default: false)."),
+	),
+	mcp.WithBoolean(
+		"think_time",
+		mcp.Description("Optional: convert recorded pauses between requests into sleep() calls (default: true)."),
+	),
+)
+
+const (
+	// minThinkTime is the shortest recorded pause that becomes a sleep() call.
+	minThinkTime = 500 * time.Millisecond
+	// maxThinkTime caps a single generated sleep() call.
+	maxThinkTime = 10 * time.Second
+)
+
+// RegisterConvertRecordingTool registers the convert_recording tool with the MCP server.
+func RegisterConvertRecordingTool(s *server.MCPServer) {
+	s.AddTool(ConvertRecordingTool, withToolLogger("convert_recording", convertRecording))
+}
+
+// recordingOptions controls how a recording is turned into a script.
+type recordingOptions struct {
+	Hosts         []string
+	IncludeStatic bool
+	ThinkTime     bool
+}
+
+// recordingSummary describes what was kept and dropped during conversion.
+type recordingSummary struct {
+	Creator        string   `json:"creator,omitempty"`
+	EntriesTotal   int      `json:"entries_total"`
+	RequestsKept   int      `json:"requests_kept"`
+	SkippedStatic  int      `json:"skipped_static"`
+	SkippedByHost  int      `json:"skipped_by_host"`
+	Pages          int      `json:"pages"`
+	Hosts          []string `json:"hosts"`
+	SleepsInserted int      `json:"sleeps_inserted"`
+}
+
+// convertRecordingResponse is the JSON structure returned by the tool.
+type convertRecordingResponse struct {
+	Script    string           `json:"script"`
+	Summary   recordingSummary `json:"summary"`
+	NextSteps []string         `json:"next_steps"`
+}
+
+func convertRecording(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger := logging.LoggerFromContext(ctx)
+	logger.DebugContext(ctx, "Starting recording conversion")
+
+	recording, err := request.RequireString("recording")
+	if err != nil {
+		return nil, err
+	}
+
+	archive, err := har.Parse([]byte(recording))
+	if err != nil {
+		logger.WarnContext(ctx, "Failed to parse recording", slog.String("error", err.Error()))
+		return mcp.NewToolResultError("Failed to parse recording: " + err.Error()), nil
+	}
+
+	opts := recordingOptions{
+		Hosts:         request.GetStringSlice("hosts", nil),
+		IncludeStatic: request.GetBool("include_static", false),
+		ThinkTime:     request.GetBool("think_time", true),
+	}
+
+	script, summary := generateScriptFromRecording(archive, opts)
+	if summary.RequestsKept == 0 {
+		logger.WarnContext(ctx, "No requests left after filtering",
+			slog.Int("entries_total", summary.EntriesTotal))
+		return mcp.NewToolResultError(
+			"No requests left after filtering the recording. " +
+				"Check the 'hosts' filter or set 'include_static' to true.",
+		), nil
+	}
+
+	logger.InfoContext(ctx, "Recording converted successfully",
+		slog.Int("entries_total", summary.EntriesTotal),
+		slog.Int("requests_kept", summary.RequestsKept),
+		slog.Int("script_size", len(script)))
+
+	return marshalResponse(ctx, logger, convertRecordingResponse{
+		Script:  script,
+		Summary: summary,
+		NextSteps: []string{
+			"Use validate_script to check the generated script",
+			"Replace recorded dynamic values (session IDs, CSRF tokens) with values extracted from responses",
+			"Move hard-coded test data into SharedArray-backed data files or environment variables",
+			"Add thresholds and scenarios before running with load via run_script",
+		},
+	})
+}
+
+// generateScriptFromRecording converts the recording entries into a k6 script.
+func generateScriptFromRecording(archive *har.Archive, opts recordingOptions) (string, recordingSummary) {
+	summary := recordingSummary{EntriesTotal: len(archive.Log.Entries)}
+	if archive.Log.Creator.Name != "" {
+		summary.Creator = strings.TrimSpace(archive.Log.Creator.Name + " " + archive.Log.Creator.Version)
+	}
+
+	allowedHosts := make(map[string]bool, len(opts.Hosts))
+	for _, h := range opts.Hosts {
+		allowedHosts[strings.ToLower(strings.TrimSpace(h))] = true
+	}
+
+	var kept []har.Entry
+	hosts := make(map[string]bool)
+	for _, e := range archive.Log.Entries {
+		host := strings.ToLower(e.Host())
+		if len(allowedHosts) > 0 && !allowedHosts[host] {
+			summary.SkippedByHost++
+			continue
+		}
+		if !opts.IncludeStatic && isStaticAsset(e) {
+			summary.SkippedStatic++
+			continue
+		}
+		hosts[host] = true
+		kept = append(kept, e)
+	}
+	summary.RequestsKept = len(kept)
+	for h := range hosts {
+		summary.Hosts = append(summary.Hosts, h)
+	}
+	sort.Strings(summary.Hosts)
+
+	w := &codeWriter{}
+	w.line("// Generated by mcp-k6 from a recording. Review before running with load.")
+	w.line("import http from 'k6/http';")
+	w.line("import { check, group, sleep } from 'k6';")
+	w.line("")
+	w.open("export const options = {")
+	w.line("vus: 1,")
+	w.line("iterations: 1,")
+	w.close("};")
+	w.line("")
+	w.open("export default function () {")
+	w.line("let res;")
+
+	var previous *har.Entry
+	currentPage := ""
+	groupOpen := false
+	for i := range kept {
+		entry := kept[i]
+
+		if entry.PageRef != "" && entry.PageRef != currentPage {
+			if groupOpen {
+				w.close("});")
+			}
+			currentPage = entry.PageRef
+			summary.Pages++
+			w.line("")
+			w.open(fmt.Sprintf("group(%s, function () {", jsString(archive.Log.PageTitle(entry.PageRef))))
+			groupOpen = true
+		}
+
+		if opts.ThinkTime && previous != nil {
+			if pause := recordedPause(*previous, entry); pause > 0 {
+				w.line(fmt.Sprintf("sleep(%s);", formatSeconds(pause)))
+				summary.SleepsInserted++
+			}
+		}
+
+		writeRecordedRequest(w, entry)
+		previous = &kept[i]
+	}
+	if groupOpen {
+		w.close("});")
+	}
+	w.close("}")
+
+	return w.String(), summary
+}
+
+// writeRecordedRequest emits the k6 call and status check for a single entry.
+func writeRecordedRequest(w *codeWriter, entry har.Entry) {
+	writeRecordedCall(w, entry)
+	if status := entry.Response.Status; status > 0 {
+		w.line(fmt.Sprintf("check(res, { %s: (r) => r.status === %d });",
+			jsString(fmt.Sprintf("status is %d", status)), status))
+	}
+}
+
+// writeRecordedCall emits the k6 call replaying entry into res.
+func writeRecordedCall(w *codeWriter, entry har.Entry) {
+	req := entry.Request
+	params := requestParams(entry)
+	paramsArg := "null"
+	if len(params) > 0 {
+		paramsArg = jsValue(params, strings.Repeat("  ", w.depth))
+	}
+
+	body := "null"
+	if req.PostData != nil {
+		switch {
+		case req.PostData.Text != "":
+			body = jsString(req.PostData.Text)
+		case len(req.PostData.Params) > 0:
+			form := make(map[string]string, len(req.PostData.Params))
+			for _, p := range req.PostData.Params {
+				form[p.Name] = p.Value
+			}
+			body = jsValue(form, strings.Repeat("  ", w.depth))
+		}
+	}
+
+	method := strings.ToUpper(req.Method)
+	switch method {
+	case "GET":
+		if paramsArg == "null" {
+			w.line(fmt.Sprintf("res = http.get(%s);", jsString(req.URL)))
+		} else {
+			w.line(fmt.Sprintf("res = http.get(%s, %s);", jsString(req.URL), paramsArg))
+		}
+	case "POST", "PUT", "PATCH":
+		w.line(fmt.Sprintf("res = http.%s(%s, %s, %s);",
+			strings.ToLower(method), jsString(req.URL), body, paramsArg))
+	case "DELETE":
+		w.line(fmt.Sprintf("res = http.del(%s, %s, %s);", jsString(req.URL), body, paramsArg))
+	default:
+		w.line(fmt.Sprintf("res = http.request(%s, %s, %s, %s);",
+			jsString(method), jsString(req.URL), body, paramsArg))
+	}
+}
+
+// requestParams builds the k6 request params object for an entry. Redirect
+// responses disable redirect following so the recorded follow-up request is
+// replayed explicitly, like in the browser.
+func requestParams(entry har.Entry) map[string]any {
+	params := make(map[string]any)
+
+	headers := make(map[string]string)
+	for _, h := range entry.Request.Headers {
+		if keepRecordedHeader(h.Name) {
+			headers[h.Name] = h.Value
+		}
+	}
+	if len(headers) > 0 {
+		params["headers"] = headers
+	}
+
+	if status := entry.Response.Status; status >= 300 && status < 400 {
+		params["redirects"] = 0
+	}
+
+	return params
+}
+
+// keepRecordedHeader reports whether a recorded request header should be
+// replayed. Headers managed by k6 itself (cookies, connection handling,
+// compression) and browser-only metadata are dropped.
+func keepRecordedHeader(name string) bool {
+	lower := strings.ToLower(name)
+	if strings.HasPrefix(lower, ":") || strings.HasPrefix(lower, "sec-") {
+		return false
+	}
+	switch lower {
+	case "cookie", "host", "content-length", "connection", "accept-encoding",
+		"user-agent", "upgrade-insecure-requests", "priority", "pragma", "cache-control":
+		return false
+	}
+	return true
+}
+
+// isStaticAsset reports whether an entry fetched an image, stylesheet,
+// script, font or media file.
+func isStaticAsset(entry har.Entry) bool {
+	mime := strings.ToLower(entry.Response.Content.MimeType)
+	for _, prefix := range []string{"image/", "font/", "audio/", "video/", "text/css", "text/javascript"} {
+		if strings.HasPrefix(mime, prefix) {
+			return true
+		}
+	}
+	if strings.Contains(mime, "javascript") {
+		return true
+	}
+
+	u, err := url.Parse(entry.Request.URL)
+	if err != nil {
+		return false
+	}
+	switch strings.ToLower(path.Ext(u.Path)) {
+	case ".png", ".jpg", ".jpeg", ".gif", ".svg", ".ico", ".webp", ".avif",
+		".css", ".js", ".mjs", ".map", ".woff", ".woff2", ".ttf", ".otf", ".eot",
+		".mp4", ".webm", ".mp3":
+		return true
+	}
+	return false
+}
+
+// recordedPause returns the idle time between the end of prev and the start of
+// next, or zero when the gap is too short to be user think time.
+func recordedPause(prev, next har.Entry) time.Duration {
+	prevStart, nextStart := prev.Started(), next.Started()
+	if prevStart.IsZero() || nextStart.IsZero() {
+		return 0
+	}
+	prevEnd := prevStart.Add(time.Duration(prev.Time * float64(time.Millisecond)))
+	gap := nextStart.Sub(prevEnd)
+	if gap < minThinkTime {
+		return 0
+	}
+	return min(gap, maxThinkTime)
+}
+
+// formatSeconds renders d as seconds with at most one decimal place.
+func formatSeconds(d time.Duration) string {
+	seconds := math.Round(d.Seconds()*10) / 10
+	return fmt.Sprintf("%g", seconds)
+}
@@ -0,0 +1,91 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/grafana/mcp-k6/internal/har"
+	"github.com/stretchr/testify/require"
+)
+
+const testRecording = `{
+  "log": {
+    "version": "1.2",
+    "creator": {"name": "k6 Studio", "version": "1.0.0"},
+    "pages": [{"id": "page_1", "title": "Home"}],
+    "entries": [
+      {
+        "pageref": "page_1",
+        "startedDateTime": "2025-01-01T10:00:00.000Z",
+        "time": 100,
+        "request": {
+          "method": "GET",
+          "url": "https://test.k6.io/",
+          "headers": [{"name": "Accept", "value": "text/html"}, {"name": "Cookie", "value": "sid=1"}]
+        },
+        "response": {"status": 200, "headers": [], "content": {"mimeType": "text/html"}}
+      },
+      {
+        "pageref": "page_1",
+        "startedDateTime": "2025-01-01T10:00:00.200Z",
+        "time": 10,
+        "request": {"method": "GET", "url": "https://test.k6.io/static/logo.png", "headers": []},
+        "response": {"status": 200, "headers": [], "content": {"mimeType": "image/png"}}
+      },
+      {
+        "pageref": "page_1",
+        "startedDateTime": "2025-01-01T10:00:03.100Z",
+        "time": 50,
+        "request": {
+          "method": "POST",
+          "url": "https://test.k6.io/login",
+          "headers": [{"name": "Content-Type", "value": "application/x-www-form-urlencoded"}],
+          "postData": {"mimeType": "application/x-www-form-urlencoded", "text": "user=admin"}
+        },
+        "response": {"status": 302, "headers": [], "content": {"mimeType": "text/html"}}
+      },
+      {
+        "startedDateTime": "2025-01-01T10:00:03.200Z",
+        "time": 10,
+        "request": {"method": "GET", "url": "https://analytics.example.com/collect", "headers": []},
+        "response": {"status": 204, "headers": [], "content": {}}
+      }
+    ]
+  }
+}`
+
+func TestGenerateScriptFromRecording(t *testing.T) {
+	t.Parallel()
+
+	archive, err := har.Parse([]byte(testRecording))
+	require.NoError(t, err)
+
+	script, summary := generateScriptFromRecording(archive, recordingOptions{
+		Hosts:     []string{"test.k6.io"},
+		ThinkTime: true,
+	})
+
+	require.Equal(t, 4, summary.EntriesTotal)
+	require.Equal(t, 2, summary.RequestsKept)
+	require.Equal(t, 1, summary.SkippedStatic)
+	require.Equal(t, 1, summary.SkippedByHost)
+	require.Equal(t, 1, summary.Pages)
+	require.Equal(t, 1, summary.SleepsInserted)
+	require.Equal(t, []string{"test.k6.io"}, summary.Hosts)
+
+	require.Contains(t, script, `group("Home", function () {`)
+	require.Contains(t, script, `res = http.get("https://test.k6.io/", {`)
+	require.Contains(t, script, `"Accept": "text/html"`)
+	require.NotContains(t, script, "Cookie")
+	require.NotContains(t, script, "logo.png")
+	require.Contains(t, script, "sleep(3);")
+	require.Contains(t, script, `res = http.post("https://test.k6.io/login", "user=admin", {`)
+	require.Contains(t, script, `"redirects": 0`)
+	require.Contains(t, script, "r.status === 302")
+}
+
+func TestParseRecordingRejectsEmptyArchive(t *testing.T) {
+	t.Parallel()
+
+	_, err := har.Parse([]byte(`{"log": {"entries": []}}`))
+	require.ErrorIs(t, err, har.ErrNoEntries)
+}
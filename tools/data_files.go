@@ -0,0 +1,198 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/grafana/mcp-k6/internal/logging"
+	"github.com/grafana/mcp-k6/internal/workspace"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// filesDescription documents the files parameter of the execution tools.
+const filesDescription = "Optional: data files (CSV, JSON, ...) staged next to an inline script in a " +
+	"per-run directory, so open('./users.csv') works. Each entry is {name, content} with the text inline, " +
+	"or {name, path} to copy a file from a workspace root. 'name' is the relative path the script opens, " +
+	"such as 'users.csv' or 'data/users.json'. The directory is removed when the run ends. Workspace " +
+	"scripts (script_path) open files next to them instead."
+
+const (
+	// MaxDataFiles is the maximum number of data files staged for a run.
+	MaxDataFiles = 20
+
+	// MaxDataFileSize is the maximum size of one data file in bytes (10MB).
+	MaxDataFileSize = 10 * 1024 * 1024
+
+	// MaxDataFilesSize is the maximum total size of a run's data files in bytes (50MB).
+	MaxDataFilesSize = 50 * 1024 * 1024
+
+	// runScriptName is the name the inline script is staged under.
+	runScriptName = "script.js"
+
+	// entryModuleName is the name the entry module is staged under.
+	entryModuleName = "entry.js"
+
+	// entryModulePattern names the entry module written next to a workspace
+	// or checked-out script.
+	entryModulePattern = ".mcp-k6-entry-*.js"
+
+	// runDirPlaceholder stands in for the per-run directory in plans.
+	runDirPlaceholder = "<run-dir>"
+)
+
+// DataFile is a file staged in the run directory.
+type DataFile struct {
+	// Name is the path of the file relative to the script.
+	Name string `json:"name"`
+	// Content is the file content. Files given by path are read into it.
+	Content string `json:"content,omitempty"`
+	// Path is a workspace file to copy instead of inline content.
+	Path string `json:"path,omitempty"`
+}
+
+// dataFilesArgument reads the files parameter, loading files given by path
+// from the workspace roots.
+func dataFilesArgument(ctx context.Context, ws *workspace.Workspace, request mcp.CallToolRequest) ([]DataFile, error) {
+	raw, ok := request.GetArguments()["files"]
+	if !ok || raw == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid files: %w", err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	var files []DataFile
+	if err := dec.Decode(&files); err != nil {
+		return nil, fmt.Errorf("invalid files: %w", err)
+	}
+
+	for i := range files {
+		switch {
+		case files[i].Path != "" && files[i].Content != "":
+			return nil, fmt.Errorf("files[%d]: provide either content or path, not both", i)
+		case files[i].Path == "":
+			continue
+		case ws == nil:
+			return nil, workspace.ErrNoRoots
+		}
+		content, _, err := ws.ReadFile(ctx, files[i].Path, MaxDataFileSize)
+		if err != nil {
+			return nil, fmt.Errorf("reading data file: %w", err)
+		}
+		files[i].Content, files[i].Path = string(content), ""
+	}
+	return files, nil
+}
+
+// validateDataFiles validates the names and sizes of a run's data files.
+func validateDataFiles(options *RunOptions) error {
+	if len(options.DataFiles) == 0 {
+		return nil
+	}
+	invalid := func(format string, args ...any) error {
+		return &RunError{Type: "PARAMETER_VALIDATION", Message: fmt.Sprintf(format, args...)}
+	}
+	if options.ScriptPath != "" {
+		return invalid("files can only be staged for inline scripts; place data files next to %s",
+			filepath.Base(options.ScriptPath))
+	}
+	if len(options.DataFiles) > MaxDataFiles {
+		return invalid("at most %d data files can be staged, got %d", MaxDataFiles, len(options.DataFiles))
+	}
+
+	seen := make(map[string]bool, len(options.DataFiles))
+	total := 0
+	for _, f := range options.DataFiles {
+		name := filepath.Clean(filepath.FromSlash(f.Name))
+		switch {
+		case f.Name == "":
+			return invalid("data files need a name")
+		case !filepath.IsLocal(name):
+			return invalid("data file name %q must be a relative path inside the run directory", f.Name)
+		case name == runScriptName || name == entryModuleName:
+			return invalid("data file name %q is reserved for the script", f.Name)
+		case seen[name]:
+			return invalid("data file name %q is used more than once", f.Name)
+		case len(f.Content) > MaxDataFileSize:
+			return invalid("data file %q exceeds the maximum size of %d bytes", f.Name, MaxDataFileSize)
+		}
+		seen[name] = true
+		total += len(f.Content)
+	}
+	if total > MaxDataFilesSize {
+		return invalid("data files exceed the maximum total size of %d bytes", MaxDataFilesSize)
+	}
+	return nil
+}
+
+// createRunDir stages script and its data files in a new private directory
+// and returns the script path and a function removing the directory.
+func createRunDir(script string, files []DataFile) (string, func(), error) {
+	//nolint:forbidigo // Temporary directory creation required for k6 execution
+	dir, err := os.MkdirTemp("", "k6-run-*")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create run directory: %w", err)
+	}
+	cleanup := func() {
+		//nolint:forbidigo // Cleanup of temporary directory required
+		if removeErr := os.RemoveAll(dir); removeErr != nil {
+			logging.WithComponent("runner").Warn("Failed to remove run directory",
+				slog.String("operation", "cleanup"),
+				slog.String("error", removeErr.Error()),
+			)
+		}
+	}
+
+	scriptPath := filepath.Join(dir, runScriptName)
+	write := func(path, content string) error {
+		const secureDirMode = 0o700
+		//nolint:forbidigo // Staging data files required for k6 execution
+		if err := os.MkdirAll(filepath.Dir(path), secureDirMode); err != nil {
+			return err
+		}
+		const secureFileMode = 0o600
+		//nolint:forbidigo // Staging data files required for k6 execution
+		return os.WriteFile(path, []byte(content), secureFileMode)
+	}
+	err = write(scriptPath, script)
+	for _, f := range files {
+		if err != nil {
+			break
+		}
+		err = write(filepath.Join(dir, filepath.FromSlash(f.Name)), f.Content)
+	}
+	if err != nil {
+		cleanup()
+		return "", nil, fmt.Errorf("failed to stage run files: %w", err)
+	}
+	return scriptPath, cleanup, nil
+}
+
+// createEntryFile writes the entry module of the run of scriptPath next to
+// it, so relative open() calls and imports keep resolving from the script's
+// directory: as a hidden file next to a workspace or checked-out script,
+// removed once the run ends, or as entry.js in the run directory of a staged
+// script. Other inline scripts get a temporary file.
+func createEntryFile(scriptPath, source string, options *RunOptions) (string, func(), error) {
+	switch {
+	case options.ScriptPath != "":
+		return createSecureTempFileIn(filepath.Dir(scriptPath), entryModulePattern, source)
+	case len(options.DataFiles) == 0:
+		return createSecureTempFile(source)
+	}
+	path := filepath.Join(filepath.Dir(scriptPath), entryModuleName)
+	const secureFileMode = 0o600
+	//nolint:forbidigo // Entry module staging required for k6 execution
+	if err := os.WriteFile(path, []byte(source), secureFileMode); err != nil {
+		return "", nil, fmt.Errorf("failed to write entry module: %w", err)
+	}
+	// Removed with the run directory
+	return path, func() {}, nil
+}
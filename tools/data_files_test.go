@@ -0,0 +1,129 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/grafana/mcp-k6/internal/workspace"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDataFilesArgument(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, "users.csv"), []byte("name\nalice\n"), 0o600))
+	ws := workspace.New(nil, root)
+
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]any{"files": []any{
+		map[string]any{"name": "users.csv", "path": "users.csv"},
+		map[string]any{"name": "data/config.json", "content": `{"base":"https://test.k6.io"}`},
+	}}
+	files, err := dataFilesArgument(context.Background(), ws, req)
+	require.NoError(t, err)
+	assert.Equal(t, []DataFile{
+		{Name: "users.csv", Content: "name\nalice\n"},
+		{Name: "data/config.json", Content: `{"base":"https://test.k6.io"}`},
+	}, files)
+
+	req.Params.Arguments = map[string]any{"files": []any{
+		map[string]any{"name": "users.csv", "path": "users.csv", "content": "x"},
+	}}
+	_, err = dataFilesArgument(context.Background(), ws, req)
+	require.Error(t, err)
+
+	req.Params.Arguments = map[string]any{"files": []any{map[string]any{"name": "a", "mode": "0644"}}}
+	_, err = dataFilesArgument(context.Background(), ws, req)
+	require.Error(t, err)
+}
+
+func TestValidateDataFiles(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]*RunOptions{
+		"workspace script": {ScriptPath: "/ws/test.js", DataFiles: []DataFile{{Name: "users.csv"}}},
+		"no name":          {DataFiles: []DataFile{{Content: "x"}}},
+		"absolute name":    {DataFiles: []DataFile{{Name: "/etc/passwd"}}},
+		"escaping name":    {DataFiles: []DataFile{{Name: "../users.csv"}}},
+		"reserved name":    {DataFiles: []DataFile{{Name: "script.js"}}},
+		"duplicate name":   {DataFiles: []DataFile{{Name: "a.csv"}, {Name: "./a.csv"}}},
+		"too large":        {DataFiles: []DataFile{{Name: "a.csv", Content: strings.Repeat("x", MaxDataFileSize+1)}}},
+		"too many":         {DataFiles: make([]DataFile, MaxDataFiles+1)},
+	}
+	for name, options := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			assert.Error(t, validateDataFiles(options))
+		})
+	}
+
+	assert.NoError(t, validateDataFiles(&RunOptions{DataFiles: []DataFile{{Name: "data/users.csv"}}}))
+}
+
+func TestCreateRunDir(t *testing.T) {
+	t.Parallel()
+
+	scriptPath, cleanup, err := createRunDir("export default function () {}", []DataFile{
+		{Name: "users.csv", Content: "name\nalice\n"},
+		{Name: "data/config.json", Content: "{}"},
+	})
+	require.NoError(t, err)
+	dir := filepath.Dir(scriptPath)
+	assert.Equal(t, runScriptName, filepath.Base(scriptPath))
+
+	data, err := os.ReadFile(filepath.Join(dir, "users.csv"))
+	require.NoError(t, err)
+	assert.Equal(t, "name\nalice\n", string(data))
+	info, err := os.Stat(filepath.Join(dir, "data", "config.json"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	entry, _, err := createEntryFile(scriptPath, "export * from './script.js';",
+		&RunOptions{DataFiles: []DataFile{{Name: "users.csv"}}})
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, entryModuleName), entry)
+
+	cleanup()
+	_, err = os.Stat(dir)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestCreateEntryFileNextToScript(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	scriptPath := filepath.Join(dir, "load.js")
+	script := "const users = JSON.parse(open('./users.json'));\nexport default function () {}\n"
+	//nolint:forbidigo // Test fixture.
+	require.NoError(t, os.WriteFile(scriptPath, []byte(script), 0o600))
+	options := &RunOptions{ScriptPath: scriptPath, Thresholds: map[string][]string{"http_req_failed": {"rate<0.01"}}}
+	require.True(t, needsEntryModule(options))
+	source, err := entryModule(scriptPath, script, options)
+	require.NoError(t, err)
+
+	// The entry module of a workspace script resolves open() from its directory
+	entry, cleanup, err := createEntryFile(scriptPath, source, options)
+	require.NoError(t, err)
+	assert.Equal(t, dir, filepath.Dir(entry))
+	assert.NotEqual(t, entryModuleName, filepath.Base(entry), "an entry.js of the workspace is left alone")
+	data, err := os.ReadFile(entry)
+	require.NoError(t, err)
+	assert.Equal(t, source, string(data))
+
+	cleanup()
+	_, err = os.Stat(entry)
+	assert.True(t, os.IsNotExist(err))
+	_, err = os.Stat(scriptPath)
+	require.NoError(t, err)
+
+	entry, cleanup, err = createEntryFile("", source, &RunOptions{})
+	require.NoError(t, err)
+	defer cleanup()
+	assert.Equal(t, filepath.Clean(os.TempDir()), filepath.Dir(entry))
+}
@@ -0,0 +1,163 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/grafana/mcp-k6/internal/diff"
+	"github.com/grafana/mcp-k6/internal/logging"
+	"github.com/grafana/mcp-k6/internal/workspace"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// DiffScriptsTool exposes a tool for comparing two versions of a script.
+//
+//nolint:gochecknoglobals // Shared tool definition registered at startup.
+var DiffScriptsTool = mcp.NewTool(
+	"diff_scripts",
+	readOnlyTool(),
+	mcp.WithDescription(
+		"Produce a unified diff between two versions of a k6 script. "+
+			"Use it to review changes, or to send a minimal patch to apply_patch instead of resending the whole script.",
+	),
+	mcp.WithString(
+		"original",
+		mcp.Required(),
+		mcp.Description("The original script content."),
+	),
+	mcp.WithString(
+		"modified",
+		mcp.Required(),
+		mcp.Description("The modified script content."),
+	),
+	mcp.WithNumber(
+		"context_lines",
+		mcp.Description("Optional: unchanged lines shown around each change (default: 3)."),
+	),
+)
+
+// ApplyPatchTool exposes a tool for applying a unified diff to a script.
+//
+//nolint:gochecknoglobals // Shared tool definition registered at startup.
+var ApplyPatchTool = mcp.NewTool(
+	"apply_patch",
+	readOnlyTool(),
+	mcp.WithDescription(
+		"Apply a unified diff to a k6 script and return the patched script. "+
+			"Hunks are matched at their recorded line first and then searched for nearby, "+
+			"tolerating small drifts and whitespace differences. "+
+			"Prefer small patches over resending whole scripts when iterating on a script.",
+	),
+	mcp.WithString(
+		"patch",
+		mcp.Required(),
+		mcp.Description("The unified diff to apply (as produced by diff_scripts, git diff or diff -u)."),
+	),
+	mcp.WithString(
+		"script",
+		mcp.Description("The script content to patch. Provide either script or script_path."),
+	),
+	mcp.WithString(
+		"script_path",
+		mcp.Description(scriptPathDescription),
+	),
+)
+
+// RegisterDiffScriptsTool registers the diff_scripts tool with the MCP server.
+func RegisterDiffScriptsTool(s *server.MCPServer) {
+	s.AddTool(DiffScriptsTool, withToolLogger("diff_scripts", diffScripts))
+}
+
+// RegisterApplyPatchTool registers the apply_patch tool with the MCP server.
+// canWrite tells whether write_script is registered too.
+func RegisterApplyPatchTool(s *server.MCPServer, ws *workspace.Workspace, canWrite bool) {
+	s.AddTool(ApplyPatchTool, withToolLogger("apply_patch", newApplyPatchHandlerFunc(ws, canWrite)))
+}
+
+// diffScriptsResponse is the JSON structure returned by diff_scripts.
+type diffScriptsResponse struct {
+	Diff      string     `json:"diff"`
+	Identical bool       `json:"identical"`
+	Stats     diff.Stats `json:"stats"`
+}
+
+// applyPatchResponse is the JSON structure returned by apply_patch.
+type applyPatchResponse struct {
+	Script    string         `json:"script"`
+	Applied   []diff.Applied `json:"applied"`
+	NextSteps []string       `json:"next_steps"`
+}
+
+func diffScripts(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger := logging.LoggerFromContext(ctx)
+
+	original, err := request.RequireString("original")
+	if err != nil {
+		return nil, err
+	}
+	modified, err := request.RequireString("modified")
+	if err != nil {
+		return nil, err
+	}
+	contextLines := request.GetInt("context_lines", diff.DefaultContext)
+
+	unified, stats := diff.Unified("a/script.js", "b/script.js", original, modified, contextLines)
+
+	logger.InfoContext(ctx, "Scripts compared",
+		slog.Int("added", stats.Added),
+		slog.Int("removed", stats.Removed),
+		slog.Int("hunks", stats.Hunks))
+
+	return marshalResponse(ctx, logger, diffScriptsResponse{
+		Diff:      unified,
+		Identical: stats.Hunks == 0,
+		Stats:     stats,
+	})
+}
+
+// newApplyPatchHandlerFunc returns an MCP tool handler bound to a workspace.
+func newApplyPatchHandlerFunc(ws *workspace.Workspace, canWrite bool) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		logger := logging.LoggerFromContext(ctx)
+
+		patch, err := request.RequireString("patch")
+		if err != nil {
+			return nil, err
+		}
+		script, scriptPath, err := readScriptArgument(ctx, ws, request)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		patched, applied, err := diff.Apply(script, patch)
+		if err != nil {
+			logger.WarnContext(ctx, "Failed to apply patch", slog.String("error", err.Error()))
+			message := "Failed to apply patch: " + err.Error()
+			if errors.Is(err, diff.ErrConflict) {
+				message += ". The script has changed too much; regenerate the patch with diff_scripts against the current script."
+			}
+			return mcp.NewToolResultError(message), nil
+		}
+
+		logger.InfoContext(ctx, "Patch applied",
+			slog.Int("hunks", len(applied)),
+			slog.Int("script_size", len(patched)))
+
+		nextSteps := []string{"Use validate_script to check the patched script"}
+		switch {
+		case scriptPath != "" && canWrite:
+			nextSteps = append(nextSteps, "Use write_script to save the patched script back to "+scriptPath)
+		case scriptPath != "":
+			nextSteps = append(nextSteps, "Saving the patched script back to "+scriptPath+
+				" needs a server started with -allow-write; return the script to the user to save instead")
+		}
+
+		return marshalResponse(ctx, logger, applyPatchResponse{
+			Script:    patched,
+			Applied:   applied,
+			NextSteps: nextSteps,
+		})
+	}
+}
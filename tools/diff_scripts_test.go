@@ -0,0 +1,39 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/grafana/mcp-k6/internal/workspace"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyPatchNextSteps(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	path := filepath.Join(root, "script.js")
+	require.NoError(t, os.WriteFile(path, []byte("export default function () {}\n"), 0o600))
+	patch := "--- a/script.js\n+++ b/script.js\n@@ -1 +1 @@\n-export default function () {}\n" +
+		"+export default function () { sleep(1); }\n"
+
+	for canWrite, want := range map[bool]string{
+		true:  "Use write_script to save the patched script back to " + path,
+		false: "needs a server started with -allow-write",
+	} {
+		handler := newApplyPatchHandlerFunc(workspace.New(nil, root), canWrite)
+		result, err := handler(t.Context(), newCallRequest(map[string]any{"patch": patch, "script_path": path}))
+		require.NoError(t, err)
+		require.False(t, result.IsError, "unexpected tool error: %+v", result.Content)
+		var resp applyPatchResponse
+		decodeJSON(t, result, &resp)
+		assert.Equal(t, "export default function () { sleep(1); }\n", resp.Script)
+		require.Len(t, resp.NextSteps, 2)
+		assert.Contains(t, resp.NextSteps[1], want)
+		if !canWrite {
+			assert.NotContains(t, resp.NextSteps[1], "write_script")
+		}
+	}
+}
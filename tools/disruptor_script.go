@@ -0,0 +1,512 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/grafana/mcp-k6/internal/logging"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// GenerateDisruptorScriptTool exposes a tool for writing resilience
+// experiments that inject faults into Kubernetes workloads with the
+// xk6-disruptor extension while k6 applies load.
+//
+//nolint:gochecknoglobals // Shared tool definition registered at startup.
+var GenerateDisruptorScriptTool = mcp.NewTool(
+	"generate_disruptor_script",
+	readOnlyTool(),
+	mcp.WithDescription(
+		"Generate a k6 resilience experiment with the xk6-disruptor extension (k6/x/disruptor): a "+
+			"constant-arrival-rate scenario loads base_url while a second scenario injects HTTP faults, "+
+			"latency and/or error responses, into a Kubernetes service or the pods matching labels for a "+
+			"fault window. Requests are tagged with their phase (baseline, fault or recovery) and each "+
+			"phase has its thresholds, so the summary shows how the system degrades under the fault and "+
+			"whether it recovers. The faulted workload is usually a dependency of the loaded one. It needs "+
+			"a k6 binary built with xk6-disruptor and access to the cluster: the response tells whether "+
+			"the installed k6 has the extension and gives the xk6 build command otherwise.",
+	),
+	mcp.WithString(
+		"base_url",
+		mcp.Required(),
+		mcp.Description("The URL the load is sent to, e.g. https://shop.example.com/api/products. "+
+			"The script reads it from BASE_URL first."),
+	),
+	mcp.WithString(
+		"target",
+		mcp.Description("What to fault: 'service' (default), the pods behind the Kubernetes service name, "+
+			"or 'pod', the pods matching labels."),
+		mcp.Enum(disruptService, disruptPod),
+	),
+	mcp.WithString(
+		"name",
+		mcp.Description("The service to fault, required for the service target."),
+	),
+	mcp.WithObject(
+		"labels",
+		mcp.Description("The labels of the pods to fault, required for the pod target, e.g. {\"app\": \"catalog\"}."),
+		mcp.AdditionalProperties(map[string]any{"type": "string"}),
+	),
+	mcp.WithString(
+		"namespace",
+		mcp.Description("The namespace of the workload (default: 'default')."),
+	),
+	mcp.WithNumber(
+		"port",
+		mcp.Description("Optional: the target port to fault (default: the port of the service, or 80 for pods)."),
+	),
+	mcp.WithString(
+		"average_delay",
+		mcp.Description("Optional: latency added to the responses, e.g. '200ms'."),
+	),
+	mcp.WithString(
+		"delay_variation",
+		mcp.Description("Optional: variation of the added latency, e.g. '50ms'."),
+	),
+	mcp.WithNumber(
+		"error_rate",
+		mcp.Description("Optional: the fraction of requests answered with error_code, from 0 to 1."),
+	),
+	mcp.WithNumber(
+		"error_code",
+		mcp.Description(fmt.Sprintf("Optional: the status of the injected errors (default: %d).",
+			defaultDisruptErrorCode)),
+	),
+	mcp.WithString(
+		"error_body",
+		mcp.Description("Optional: the body of the injected errors."),
+	),
+	mcp.WithArray(
+		"exclude",
+		mcp.WithStringItems(),
+		mcp.Description("Optional: paths left unfaulted, such as health checks, e.g. [\"/health\"]."),
+	),
+	mcp.WithNumber(
+		"rate",
+		mcp.Description(fmt.Sprintf("Optional: requests per second sent to base_url (default: %d).",
+			defaultDisruptRate)),
+	),
+	mcp.WithString(
+		"duration",
+		mcp.Description(fmt.Sprintf("Optional: duration of the load (default: %q).", defaultDisruptDuration)),
+	),
+	mcp.WithString(
+		"fault_start",
+		mcp.Description(fmt.Sprintf("Optional: when the fault starts after the load, leaving a baseline before "+
+			"it (default: %q).", defaultFaultStart)),
+	),
+	mcp.WithString(
+		"fault_duration",
+		mcp.Description(fmt.Sprintf("Optional: how long the fault lasts (default: %q); the load after it is "+
+			"the recovery phase.", defaultFaultDuration)),
+	),
+	mcp.WithString(
+		"latency_threshold",
+		mcp.Description(fmt.Sprintf("Optional: http_req_duration threshold of the baseline and recovery "+
+			"phases (default: %q).", defaultDisruptLatency)),
+	),
+	mcp.WithString(
+		"fault_latency_threshold",
+		mcp.Description(fmt.Sprintf("Optional: http_req_duration threshold of the fault phase, the degradation "+
+			"tolerated (default: %q).", defaultFaultLatency)),
+	),
+	mcp.WithString(
+		"fault_error_threshold",
+		mcp.Description(fmt.Sprintf("Optional: http_req_failed threshold of the fault phase (default: %q).",
+			defaultFaultErrors)),
+	),
+)
+
+// Targets of generate_disruptor_script.
+const (
+	disruptService = "service"
+	disruptPod     = "pod"
+)
+
+const (
+	defaultDisruptRate      = 10
+	defaultDisruptDuration  = "3m"
+	defaultFaultStart       = "1m"
+	defaultFaultDuration    = "1m"
+	defaultDisruptErrorCode = 500
+	defaultDisruptLatency   = "p(95)<500"
+	defaultFaultLatency     = "p(95)<2000"
+	defaultFaultErrors      = "rate<0.1"
+	// maxDisruptRate bounds the requests per second of the load.
+	maxDisruptRate = 1000
+	// disruptorModule is the module of the extension.
+	disruptorModule = "k6/x/disruptor"
+)
+
+// RegisterGenerateDisruptorScriptTool registers the generate_disruptor_script
+// tool with the MCP server.
+func RegisterGenerateDisruptorScriptTool(s *server.MCPServer) {
+	s.AddTool(GenerateDisruptorScriptTool, withToolLogger("generate_disruptor_script", generateDisruptorScript))
+}
+
+// disruptorScriptOptions are the parameters of a generated fault injection
+// experiment.
+type disruptorScriptOptions struct {
+	BaseURL   string
+	Target    string
+	Name      string
+	Labels    map[string]string
+	Namespace string
+	Port      int
+	// AverageDelay and DelayVariation are k6 durations, empty for no
+	// latency.
+	AverageDelay   string
+	DelayVariation string
+	ErrorRate      float64
+	ErrorCode      int
+	ErrorBody      string
+	Exclude        []string
+	Rate           int
+	Duration       time.Duration
+	FaultStart     time.Duration
+	FaultDuration  time.Duration
+	Latency        string
+	FaultLatency   string
+	FaultErrors    string
+}
+
+// faultWindow is the time of the fault relative to the start of the load.
+type faultWindow struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+// generateDisruptorScriptResponse is the JSON structure returned by the tool.
+type generateDisruptorScriptResponse struct {
+	Script string `json:"script"`
+	// Extension is the Go module of xk6-disruptor.
+	Extension    string `json:"extension"`
+	BuildCommand string `json:"build_command"`
+	// K6HasExtension tells whether the k6 on the PATH was built with
+	// xk6-disruptor, and is omitted when k6 was not found.
+	K6HasExtension *bool       `json:"k6_has_extension,omitempty"`
+	FaultWindow    faultWindow `json:"fault_window"`
+	// Phases are the values of the phase tag of the requests.
+	Phases    []string `json:"phases"`
+	Warnings  []string `json:"warnings,omitempty"`
+	NextSteps []string `json:"next_steps"`
+}
+
+func generateDisruptorScript(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger := logging.LoggerFromContext(ctx)
+
+	opts, warnings, err := disruptorOptions(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	script := disruptorScript(opts)
+
+	extension := extensionModule(disruptorModule)
+	resp := generateDisruptorScriptResponse{
+		Script:       script,
+		Extension:    extension,
+		BuildCommand: xk6BuildCommand([]string{extension}),
+		FaultWindow: faultWindow{
+			Start: formatSecs(opts.FaultStart.Seconds()),
+			End:   formatSecs((opts.FaultStart + opts.FaultDuration).Seconds()),
+		},
+		Phases:   disruptPhases(opts),
+		Warnings: warnings,
+	}
+	if extensions, ok := installedExtensions(ctx); ok {
+		has := providesModule(extensions, disruptorModule)
+		resp.K6HasExtension = &has
+	}
+	if resp.K6HasExtension == nil || !*resp.K6HasExtension {
+		resp.NextSteps = append(resp.NextSteps, fmt.Sprintf("Build k6 with xk6-disruptor: %s, and put the ./k6 "+
+			"binary it writes on the PATH in place of k6", resp.BuildCommand))
+	}
+	resp.NextSteps = append(resp.NextSteps,
+		fmt.Sprintf("Check that KUBECONFIG, or ~/.kube/config, points at the cluster and that its user may "+
+			"list the pods and services of the %s namespace and add ephemeral containers to its pods",
+			opts.Namespace),
+		"Run the experiment with run_script in a test environment, never against production traffic, "+
+			"and compare the http_req_duration and http_req_failed of the phases in the summary",
+		"If the recovery phase fails its thresholds, look for retries, timeouts and circuit breakers "+
+			"of the loaded service towards the faulted one",
+	)
+
+	logger.InfoContext(ctx, "Disruptor script generated",
+		slog.String("target", opts.Target),
+		slog.Bool("latency", opts.AverageDelay != ""),
+		slog.Float64("error_rate", opts.ErrorRate),
+		slog.Int("script_size", len(script)))
+
+	return marshalResponse(ctx, logger, resp)
+}
+
+// disruptorOptions reads and checks the parameters of request.
+func disruptorOptions(request mcp.CallToolRequest) (disruptorScriptOptions, []string, error) {
+	opts := disruptorScriptOptions{
+		BaseURL:        strings.TrimSpace(request.GetString("base_url", "")),
+		Target:         request.GetString("target", disruptService),
+		Name:           strings.TrimSpace(request.GetString("name", "")),
+		Namespace:      strings.TrimSpace(request.GetString("namespace", "default")),
+		Port:           request.GetInt("port", 0),
+		AverageDelay:   strings.TrimSpace(request.GetString("average_delay", "")),
+		DelayVariation: strings.TrimSpace(request.GetString("delay_variation", "")),
+		ErrorRate:      request.GetFloat("error_rate", 0),
+		ErrorCode:      request.GetInt("error_code", defaultDisruptErrorCode),
+		ErrorBody:      request.GetString("error_body", ""),
+		Rate:           request.GetInt("rate", defaultDisruptRate),
+		Latency:        strings.TrimSpace(request.GetString("latency_threshold", defaultDisruptLatency)),
+		FaultLatency:   strings.TrimSpace(request.GetString("fault_latency_threshold", defaultFaultLatency)),
+		FaultErrors:    strings.TrimSpace(request.GetString("fault_error_threshold", defaultFaultErrors)),
+	}
+	labels, err := stringMapArgument(request, "labels")
+	if err != nil {
+		return opts, nil, err
+	}
+	opts.Labels = labels
+	for _, p := range request.GetStringSlice("exclude", nil) {
+		if p = strings.TrimSpace(p); p != "" {
+			opts.Exclude = append(opts.Exclude, p)
+		}
+	}
+	if opts.Namespace == "" {
+		opts.Namespace = "default"
+	}
+
+	durations := []struct {
+		name string
+		dst  *time.Duration
+		def  string
+	}{
+		{"duration", &opts.Duration, defaultDisruptDuration},
+		{"fault_start", &opts.FaultStart, defaultFaultStart},
+		{"fault_duration", &opts.FaultDuration, defaultFaultDuration},
+	}
+	for _, d := range durations {
+		v := strings.TrimSpace(request.GetString(d.name, d.def))
+		if *d.dst, err = time.ParseDuration(v); err != nil || *d.dst < 0 {
+			return opts, nil, fmt.Errorf("invalid %s %q: expected a duration such as %s", d.name, v, d.def)
+		}
+	}
+	for _, d := range []struct{ name, value string }{
+		{"average_delay", opts.AverageDelay},
+		{"delay_variation", opts.DelayVariation},
+	} {
+		if v, err := time.ParseDuration(d.value); d.value != "" && (err != nil || v < 0) {
+			return opts, nil, fmt.Errorf("invalid %s %q: expected a duration such as 200ms", d.name, d.value)
+		}
+	}
+
+	switch {
+	case !isHTTPURL(opts.BaseURL):
+		return opts, nil, errors.New("base_url must be an http or https URL")
+	case opts.Target != disruptService && opts.Target != disruptPod:
+		return opts, nil, fmt.Errorf("invalid target %q: use 'service' or 'pod'", opts.Target)
+	case opts.Target == disruptService && opts.Name == "":
+		return opts, nil, errors.New("name is required for the service target")
+	case opts.Target == disruptPod && len(opts.Labels) == 0:
+		return opts, nil, errors.New("labels is required for the pod target, e.g. {\"app\": \"catalog\"}")
+	case opts.Port < 0 || opts.Port > math.MaxUint16:
+		return opts, nil, errors.New("port must be between 1 and 65535")
+	case opts.ErrorRate < 0 || opts.ErrorRate > 1:
+		return opts, nil, errors.New("error_rate must be between 0 and 1")
+	case opts.ErrorRate > 0 && (opts.ErrorCode < 100 || opts.ErrorCode > 599):
+		return opts, nil, errors.New("error_code must be an HTTP status between 100 and 599")
+	case opts.AverageDelay == "" && opts.ErrorRate == 0:
+		return opts, nil, errors.New("no fault: set average_delay, error_rate or both")
+	case opts.Rate < 1 || opts.Rate > maxDisruptRate:
+		return opts, nil, fmt.Errorf("rate must be between 1 and %d", maxDisruptRate)
+	case opts.FaultDuration == 0:
+		return opts, nil, errors.New("fault_duration must be positive")
+	case opts.FaultStart+opts.FaultDuration > opts.Duration:
+		return opts, nil, fmt.Errorf("the fault ends at %s, after the %s of load: lengthen duration",
+			formatSecs((opts.FaultStart + opts.FaultDuration).Seconds()), formatSecs(opts.Duration.Seconds()))
+	case opts.Latency == "" || opts.FaultLatency == "" || opts.FaultErrors == "":
+		return opts, nil, errors.New("thresholds must not be empty")
+	}
+
+	var warnings []string
+	if opts.Target == disruptService && len(opts.Labels) > 0 {
+		warnings = append(warnings, "labels is ignored: the service target faults the pods behind name")
+	}
+	if opts.Target == disruptPod && opts.Name != "" {
+		warnings = append(warnings, "name is ignored: the pod target faults the pods matching labels")
+	}
+	if opts.DelayVariation != "" && opts.AverageDelay == "" {
+		warnings = append(warnings, "delay_variation is ignored without average_delay")
+	}
+	if opts.FaultStart == 0 {
+		warnings = append(warnings, "fault_start is 0: without a baseline phase, the fault phase has nothing "+
+			"to be compared with")
+	}
+	if opts.FaultStart+opts.FaultDuration == opts.Duration {
+		warnings = append(warnings, "the fault lasts until the end of the load: without a recovery phase, "+
+			"the experiment does not tell whether the system recovers")
+	}
+	if opts.ErrorRate == 1 {
+		warnings = append(warnings, "error_rate is 1: every request to the target fails, which tests an "+
+			"outage rather than a degradation")
+	}
+	return opts, warnings, nil
+}
+
+// disruptPhases returns the phases of the load of opts.
+func disruptPhases(opts disruptorScriptOptions) []string {
+	var phases []string
+	if opts.FaultStart > 0 {
+		phases = append(phases, "baseline")
+	}
+	phases = append(phases, "fault")
+	if opts.FaultStart+opts.FaultDuration < opts.Duration {
+		phases = append(phases, "recovery")
+	}
+	return phases
+}
+
+// disruptorScript writes the experiment of opts.
+func disruptorScript(opts disruptorScriptOptions) string {
+	phases := disruptPhases(opts)
+	// Little's law sizes the VUs of the load, with room for the added latency.
+	responseTime := 0.5
+	if d, err := time.ParseDuration(opts.AverageDelay); err == nil {
+		responseTime += d.Seconds()
+	}
+	pre := max(1, int(math.Ceil(float64(opts.Rate)*responseTime)))
+	maxVUs := max(pre+1, 2*pre)
+
+	w := &codeWriter{}
+	w.line("// Generated by mcp-k6: a resilience experiment injecting faults with xk6-disruptor under load.")
+	w.line("// Run it with a k6 binary built with xk6-disruptor and access to the cluster:")
+	w.line("//   " + xk6BuildCommand([]string{extensionModule(disruptorModule)}))
+	w.line("import http from 'k6/http';")
+	w.line("import exec from 'k6/execution';")
+	w.line("import { check } from 'k6';")
+	if opts.Target == disruptPod {
+		w.line("import { PodDisruptor } from 'k6/x/disruptor';")
+	} else {
+		w.line("import { ServiceDisruptor } from 'k6/x/disruptor';")
+	}
+	w.line("")
+
+	w.open("export const options = {")
+	w.open("scenarios: {")
+	w.open("load: {")
+	w.line("executor: 'constant-arrival-rate',")
+	w.line(fmt.Sprintf("rate: %d,", opts.Rate))
+	w.line("timeUnit: '1s',")
+	w.line(fmt.Sprintf("duration: '%s',", formatSecs(opts.Duration.Seconds())))
+	w.line(fmt.Sprintf("preAllocatedVUs: %d,", pre))
+	w.line(fmt.Sprintf("maxVUs: %d,", maxVUs))
+	w.line("exec: 'load',")
+	w.close("},")
+	w.open("disrupt: {")
+	w.line("executor: 'shared-iterations',")
+	w.line("iterations: 1,")
+	w.line("vus: 1,")
+	w.line(fmt.Sprintf("startTime: '%s',", formatSecs(opts.FaultStart.Seconds())))
+	w.line("exec: 'disrupt',")
+	w.close("},")
+	w.close("},")
+	w.open("thresholds: {")
+	for _, phase := range phases {
+		latency, failed := opts.Latency, "rate<0.01"
+		if phase == "fault" {
+			latency, failed = opts.FaultLatency, opts.FaultErrors
+		}
+		w.line(fmt.Sprintf("'http_req_duration{phase:%s}': [%s],", phase, jsString(latency)))
+		w.line(fmt.Sprintf("'http_req_failed{phase:%s}': [%s],", phase, jsString(failed)))
+	}
+	w.close("},")
+	w.close("};")
+	w.line("")
+
+	w.line(fmt.Sprintf("const BASE_URL = __ENV.BASE_URL || %s;", jsString(opts.BaseURL)))
+	w.line(fmt.Sprintf("const NAMESPACE = __ENV.NAMESPACE || %s;", jsString(opts.Namespace)))
+	w.line("// The fault window, in seconds since the start of the load.")
+	w.line(fmt.Sprintf("const FAULT_START = %s;", jsNumber(opts.FaultStart.Seconds())))
+	w.line(fmt.Sprintf("const FAULT_END = %s;", jsNumber((opts.FaultStart + opts.FaultDuration).Seconds())))
+	w.line("")
+
+	w.line("// phase tells whether a request is sent before, during or after the fault. The disruptor")
+	w.line("// takes a few seconds to install its agents, so the fault lags the window: the first requests")
+	w.line("// of the fault phase may not be faulted yet, and the first of the recovery phase still may be.")
+	w.open("function phase() {")
+	w.line("const elapsed = (Date.now() - exec.scenario.startTime) / 1000;")
+	w.open("if (elapsed < FAULT_START) {")
+	w.line("return 'baseline';")
+	w.close("}")
+	w.line("return elapsed < FAULT_END ? 'fault' : 'recovery';")
+	w.close("}")
+	w.line("")
+
+	w.open("export function load() {")
+	w.line("const res = http.get(BASE_URL, { tags: { phase: phase() } });")
+	w.line("check(res, { 'status is 2xx or 3xx': (r) => r.status >= 200 && r.status < 400 });")
+	w.close("}")
+	w.line("")
+
+	w.open("export function disrupt() {")
+	if opts.Target == disruptPod {
+		w.open("const disruptor = new PodDisruptor({")
+		w.line("namespace: NAMESPACE,")
+		w.open("select: {")
+		w.open("labels: {")
+		for _, k := range slices.Sorted(maps.Keys(opts.Labels)) {
+			w.line(fmt.Sprintf("%s: %s,", jsString(k), jsString(opts.Labels[k])))
+		}
+		w.close("},")
+		w.close("},")
+		w.close("});")
+	} else {
+		w.line(fmt.Sprintf("const disruptor = new ServiceDisruptor(%s, NAMESPACE);", jsString(opts.Name)))
+	}
+	w.open("if (disruptor.targets().length === 0) {")
+	w.line(fmt.Sprintf("throw new Error(`no pods to fault in namespace ${NAMESPACE} for %s`);",
+		jsTemplateBody(disruptTargetName(opts))))
+	w.close("}")
+	w.line("")
+	w.open("const fault = {")
+	if opts.AverageDelay != "" {
+		w.line(fmt.Sprintf("averageDelay: %s,", jsString(opts.AverageDelay)))
+		if opts.DelayVariation != "" {
+			w.line(fmt.Sprintf("delayVariation: %s,", jsString(opts.DelayVariation)))
+		}
+	}
+	if opts.ErrorRate > 0 {
+		w.line(fmt.Sprintf("errorRate: %s,", jsNumber(opts.ErrorRate)))
+		w.line(fmt.Sprintf("errorCode: %d,", opts.ErrorCode))
+		if opts.ErrorBody != "" {
+			w.line(fmt.Sprintf("errorBody: %s,", jsString(opts.ErrorBody)))
+		}
+	}
+	if len(opts.Exclude) > 0 {
+		w.line(fmt.Sprintf("exclude: %s,", jsString(strings.Join(opts.Exclude, ","))))
+	}
+	if opts.Port > 0 {
+		w.line(fmt.Sprintf("port: %d,", opts.Port))
+	}
+	w.close("};")
+	w.line("// The faults last for the fault window, then the agents stop injecting them.")
+	w.line(fmt.Sprintf("disruptor.injectHTTPFaults(fault, '%s');", formatSecs(opts.FaultDuration.Seconds())))
+	w.close("}")
+	return w.String()
+}
+
+// disruptTargetName describes the faulted workload of opts in errors.
+func disruptTargetName(opts disruptorScriptOptions) string {
+	if opts.Target == disruptService {
+		return "service " + opts.Name
+	}
+	pairs := make([]string, 0, len(opts.Labels))
+	for _, k := range slices.Sorted(maps.Keys(opts.Labels)) {
+		pairs = append(pairs, k+"="+opts.Labels[k])
+	}
+	return "labels " + strings.Join(pairs, ",")
+}
@@ -0,0 +1,114 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateDisruptorScript(t *testing.T) {
+	t.Parallel()
+
+	result, err := generateDisruptorScript(t.Context(), newCallRequest(map[string]any{
+		"base_url":      "https://shop.example.com/api/products",
+		"name":          "catalog",
+		"namespace":     "shop",
+		"average_delay": "200ms",
+		"error_rate":    0.1,
+		"exclude":       []any{"/health", " "},
+	}))
+	require.NoError(t, err)
+	require.False(t, result.IsError, "unexpected tool error: %+v", result.Content)
+	var resp generateDisruptorScriptResponse
+	decodeJSON(t, result, &resp)
+
+	assert.Equal(t, "github.com/grafana/xk6-disruptor", resp.Extension)
+	assert.Equal(t, "xk6 build --with github.com/grafana/xk6-disruptor", resp.BuildCommand)
+	assert.Equal(t, faultWindow{Start: "60s", End: "120s"}, resp.FaultWindow)
+	assert.Equal(t, []string{"baseline", "fault", "recovery"}, resp.Phases)
+	assert.Empty(t, resp.Warnings)
+
+	assert.Contains(t, resp.Script, "import { ServiceDisruptor } from 'k6/x/disruptor';")
+	assert.Contains(t, resp.Script, `const NAMESPACE = __ENV.NAMESPACE || "shop";`)
+	assert.Contains(t, resp.Script, `const disruptor = new ServiceDisruptor("catalog", NAMESPACE);`)
+	assert.Contains(t, resp.Script, "duration: '180s',")
+	assert.Contains(t, resp.Script, "startTime: '60s',")
+	assert.Contains(t, resp.Script, "preAllocatedVUs: 7,")
+	assert.Contains(t, resp.Script, `averageDelay: "200ms",`)
+	assert.Contains(t, resp.Script, "errorRate: 0.1,")
+	assert.Contains(t, resp.Script, "errorCode: 500,")
+	assert.Contains(t, resp.Script, `exclude: "/health",`)
+	assert.Contains(t, resp.Script, "disruptor.injectHTTPFaults(fault, '60s');")
+	assert.Contains(t, resp.Script, `'http_req_duration{phase:baseline}': ["p(95)<500"],`)
+	assert.Contains(t, resp.Script, `'http_req_failed{phase:fault}': ["rate<0.1"],`)
+	assert.Contains(t, resp.Script, `'http_req_duration{phase:recovery}': ["p(95)<500"],`)
+	assert.Contains(t, resp.Script, "const res = http.get(BASE_URL, { tags: { phase: phase() } });")
+	assert.Contains(t, resp.Script, "in namespace ${NAMESPACE} for service catalog`);")
+	assert.Contains(t, resp.NextSteps[len(resp.NextSteps)-3], "shop namespace")
+}
+
+func TestDisruptorScriptPods(t *testing.T) {
+	t.Parallel()
+
+	opts, warnings, err := disruptorOptions(newCallRequest(map[string]any{
+		"base_url":       "http://localhost:8080",
+		"target":         "pod",
+		"labels":         map[string]any{"app": "catalog", "tier": "backend"},
+		"error_rate":     1,
+		"error_code":     503,
+		"error_body":     `{"error": "unavailable"}`,
+		"port":           8080,
+		"duration":       "2m",
+		"fault_start":    "0s",
+		"fault_duration": "2m",
+	}))
+	require.NoError(t, err)
+	require.Len(t, warnings, 3)
+	assert.Contains(t, warnings[0], "without a baseline phase")
+	assert.Contains(t, warnings[1], "without a recovery phase")
+	assert.Contains(t, warnings[2], "tests an outage")
+	assert.Equal(t, []string{"fault"}, disruptPhases(opts))
+
+	script := disruptorScript(opts)
+	assert.Contains(t, script, "import { PodDisruptor } from 'k6/x/disruptor';")
+	assert.Contains(t, script, "labels: {\n        \"app\": \"catalog\",\n        \"tier\": \"backend\",\n")
+	assert.Contains(t, script, `errorBody: "{\"error\": \"unavailable\"}",`)
+	assert.Contains(t, script, "port: 8080,")
+	assert.NotContains(t, script, "averageDelay")
+	assert.NotContains(t, script, "phase:baseline")
+	assert.Contains(t, script, "for labels app=catalog,tier=backend`);")
+}
+
+func TestGenerateDisruptorScriptErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		args map[string]any
+		want string
+	}{
+		"base_url": {map[string]any{"base_url": "shop", "name": "catalog", "error_rate": 0.1}, "base_url"},
+		"name":     {map[string]any{"base_url": "http://shop", "error_rate": 0.1}, "name is required"},
+		"labels":   {map[string]any{"base_url": "http://shop", "target": "pod", "error_rate": 0.1}, "labels is required"},
+		"no fault": {map[string]any{"base_url": "http://shop", "name": "catalog"}, "no fault"},
+		"delay":    {map[string]any{"base_url": "http://shop", "name": "catalog", "average_delay": "2"}, "average_delay"},
+		"rate":     {map[string]any{"base_url": "http://shop", "name": "catalog", "error_rate": 1.5}, "error_rate"},
+		"code": {
+			map[string]any{"base_url": "http://shop", "name": "catalog", "error_rate": 0.1, "error_code": 42},
+			"error_code",
+		},
+		"window": {
+			map[string]any{"base_url": "http://shop", "name": "catalog", "error_rate": 0.1, "duration": "1m"},
+			"the fault ends at 120s, after the 60s of load",
+		},
+	}
+	for name, tt := range tests {
+		_, _, err := disruptorOptions(newCallRequest(tt.args))
+		require.Error(t, err, name)
+		assert.Contains(t, err.Error(), tt.want, name)
+	}
+
+	result, err := generateDisruptorScript(t.Context(), newCallRequest(map[string]any{"base_url": "http://shop"}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+}
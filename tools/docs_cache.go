@@ -0,0 +1,161 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/grafana/mcp-k6/internal/logging"
+	"github.com/grafana/mcp-k6/internal/lru"
+	"github.com/grafana/xk6-docs/docs"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// DefaultDocsCacheSize is the default number of documentation tool responses
+// kept in memory.
+const DefaultDocsCacheSize = 256
+
+// cachedDocsTools are the tools whose responses depend only on their
+// arguments and on the docs index of their version.
+//
+//nolint:gochecknoglobals // Read-only lookup table.
+var cachedDocsTools = map[string]bool{
+	"list_sections":     true,
+	"get_documentation": true,
+	"get_category":      true,
+	"lookup_symbol":     true,
+	"search_docs":       true,
+}
+
+// foldedDocsArgs are the arguments of each documentation tool that it looks
+// up without case, surrounding spaces or slashes, and does not echo back.
+//
+//nolint:gochecknoglobals // Read-only lookup table.
+var foldedDocsArgs = map[string][]string{
+	"get_category": {"slug"},
+}
+
+// DocsCache holds the responses of the documentation tools, by tool and
+// normalized arguments. A nil DocsCache caches nothing.
+type DocsCache struct {
+	responses *lru.Cache[string, *mcp.CallToolResult]
+	// index returns the docs index of a version, nil when reloads are not
+	// tracked
+	index func(ctx context.Context, version string) (*docs.Index, error)
+
+	mu      sync.Mutex
+	indexes map[string]*docs.Index // the responses were built from, by version
+}
+
+// NewDocsCache returns a cache of the size most recently used documentation
+// tool responses of catalog, or nil when size is not positive. The cache is
+// flushed when catalog reloads the docs index of a version.
+func NewDocsCache(size int, catalog *docs.Catalog) *DocsCache {
+	if size <= 0 {
+		return nil
+	}
+	c := &DocsCache{
+		responses: lru.New[string, *mcp.CallToolResult](size),
+		indexes:   make(map[string]*docs.Index),
+	}
+	if catalog != nil {
+		c.index = catalog.Index
+	}
+	return c
+}
+
+// fresh reports whether the cached responses of version were built from its
+// current docs index. Otherwise, the index was reloaded since: the cache is
+// flushed, and the responses built from now on are fresh.
+func (c *DocsCache) fresh(ctx context.Context, version string) bool {
+	// "all" lists the versions, and names no index
+	if c.index == nil || version == "all" {
+		return true
+	}
+	idx, err := c.index(ctx, version)
+	if err != nil {
+		return true
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	prev, ok := c.indexes[version]
+	switch {
+	case !ok:
+		// First seen: the responses cached so far were built from idx
+		c.indexes[version] = idx
+		return true
+	case prev == idx:
+		return true
+	default:
+		c.responses.Purge()
+		clear(c.indexes)
+		c.indexes[version] = idx
+		return false
+	}
+}
+
+// CacheDocs returns a middleware that serves repeated calls of the
+// documentation tools from cache. Error results are not cached.
+func CacheDocs(cache *DocsCache) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		if cache == nil {
+			return next
+		}
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if !cachedDocsTools[request.Params.Name] {
+				return next(ctx, request)
+			}
+			key := docsCacheKey(request)
+			version := request.GetString("version", "")
+			if result, ok := cache.responses.Get(key); ok && cache.fresh(ctx, version) {
+				logging.LoggerFromContext(ctx).DebugContext(ctx, "Documentation served from cache",
+					slog.String("tool", request.Params.Name))
+				return copyResult(result), nil
+			}
+			result, err := next(ctx, request)
+			if err == nil && result != nil && !result.IsError && cache.fresh(ctx, version) {
+				cache.responses.Add(key, copyResult(result))
+			}
+			return result, err
+		}
+	}
+}
+
+// docsCacheKey identifies a documentation tool call by its tool and its
+// arguments, with unset ones left out. The arguments the tool folds, unless
+// they hold a docs URL, are trimmed of spaces and slashes and lowercased, as
+// the docs index looks slugs up; the others are kept as is, since the tools
+// echo them.
+func docsCacheKey(request mcp.CallToolRequest) string {
+	args := make(map[string]any)
+	for name, v := range request.GetArguments() {
+		if s, ok := v.(string); ok {
+			if slices.Contains(foldedDocsArgs[request.Params.Name], name) {
+				if _, _, isURL := parseDocURL(s); !isURL {
+					s = strings.ToLower(strings.Trim(strings.TrimSpace(s), "/"))
+				}
+			}
+			if v = s; s == "" {
+				continue
+			}
+		}
+		if v != nil {
+			args[name] = v
+		}
+	}
+	// Maps marshal with sorted keys
+	data, _ := json.Marshal(args)
+	return request.Params.Name + " " + string(data)
+}
+
+// copyResult returns a copy of result that the middlewares outside the
+// cache, which truncate or mask contents in place, can change.
+func copyResult(result *mcp.CallToolResult) *mcp.CallToolResult {
+	c := *result
+	c.Content = slices.Clone(result.Content)
+	return &c
+}
@@ -0,0 +1,134 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/grafana/xk6-docs/docs"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCacheDocs(t *testing.T) {
+	t.Parallel()
+
+	calls := 0
+	handler := CacheDocs(NewDocsCache(2, nil))(func(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		calls++
+		if req.GetString("slug", "") == "unknown" {
+			return mcp.NewToolResultError("section not found"), nil
+		}
+		return mcp.NewToolResultText("content"), nil
+	})
+	call := func(name string, args map[string]any) *mcp.CallToolResult {
+		req := newCallRequest(args)
+		req.Params.Name = name
+		result, err := handler(t.Context(), req)
+		require.NoError(t, err)
+		return result
+	}
+
+	first := call("get_category", map[string]any{"slug": "using-k6/scenarios", "version": ""})
+	// Outer middlewares change results in place
+	first.Content[0] = mcp.NewTextContent("truncated")
+	again := call("get_category", map[string]any{"slug": " Using-K6/Scenarios/ "})
+	assert.Equal(t, 1, calls, "normalized arguments hit the cache")
+	assert.Equal(t, "content", again.Content[0].(mcp.TextContent).Text)
+
+	call("get_category", map[string]any{"slug": "using-k6/scenarios", "version": "v1.4.x"})
+	call("get_category", map[string]any{"slug": "unknown"})
+	call("get_category", map[string]any{"slug": "unknown"})
+	assert.Equal(t, 4, calls, "other arguments and errors are not served from cache")
+
+	call("run_script", map[string]any{"script": "export default function () {}"})
+	call("run_script", map[string]any{"script": "export default function () {}"})
+	assert.Equal(t, 6, calls, "only documentation tools are cached")
+
+	call("lookup_symbol", map[string]any{"symbol": "http.get"})
+	call("get_category", map[string]any{"slug": "using-k6/scenarios"})
+	assert.Equal(t, 8, calls, "the least recently used response is evicted")
+}
+
+func TestCacheDocsEchoedArguments(t *testing.T) {
+	t.Parallel()
+
+	handler := CacheDocs(NewDocsCache(8, nil))(func(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText(req.GetString("symbol", "") + req.GetString("slug", "")), nil
+	})
+	call := func(name string, args map[string]any) string {
+		req := newCallRequest(args)
+		req.Params.Name = name
+		result, err := handler(t.Context(), req)
+		require.NoError(t, err)
+		return result.Content[0].(mcp.TextContent).Text
+	}
+
+	assert.Equal(t, "Check", call("lookup_symbol", map[string]any{"symbol": "Check"}))
+	assert.Equal(t, "check", call("lookup_symbol", map[string]any{"symbol": "check"}))
+	assert.Equal(t, "Using-K6", call("get_documentation", map[string]any{"slug": "Using-K6"}))
+	assert.Equal(t, "using-k6", call("get_documentation", map[string]any{"slug": "using-k6"}))
+
+	const docURL = "https://grafana.com/docs/k6/latest/Using-K6/"
+	assert.Equal(t, docURL, call("get_category", map[string]any{"slug": docURL}))
+	assert.Equal(t, docURL, call("get_category", map[string]any{"slug": docURL}), "docs URLs are not folded")
+}
+
+func TestCacheDocsReload(t *testing.T) {
+	t.Parallel()
+
+	calls := 0
+	cache := NewDocsCache(8, nil)
+	current := &docs.Index{}
+	cache.index = func(_ context.Context, version string) (*docs.Index, error) {
+		if version == "v0.0.x" {
+			return nil, errors.New("unknown version")
+		}
+		return current, nil
+	}
+	handler := CacheDocs(cache)(func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		calls++
+		return mcp.NewToolResultText("content"), nil
+	})
+	call := func(args map[string]any) {
+		req := newCallRequest(args)
+		req.Params.Name = "list_sections"
+		_, err := handler(t.Context(), req)
+		require.NoError(t, err)
+	}
+
+	call(map[string]any{})
+	call(map[string]any{})
+	assert.Equal(t, 1, calls, "the first lookup of a version is cached")
+	call(map[string]any{"version": "all"})
+	call(map[string]any{"version": "v0.0.x"})
+	call(map[string]any{})
+	call(map[string]any{"version": "all"})
+	call(map[string]any{"version": "v0.0.x"})
+	assert.Equal(t, 3, calls)
+
+	current = &docs.Index{}
+	call(map[string]any{})
+	assert.Equal(t, 4, calls, "a reloaded index is not served from cache")
+	call(map[string]any{"version": "all"})
+	assert.Equal(t, 5, calls, "a reload flushes the cache")
+	call(map[string]any{})
+	assert.Equal(t, 5, calls)
+}
+
+func TestCacheDocsDisabled(t *testing.T) {
+	t.Parallel()
+
+	assert.Nil(t, NewDocsCache(0, nil))
+	calls := 0
+	handler := CacheDocs(nil)(func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		calls++
+		return mcp.NewToolResultText("content"), nil
+	})
+	req := newCallRequest(map[string]any{"slug": "using-k6"})
+	req.Params.Name = "get_documentation"
+	_, _ = handler(t.Context(), req)
+	_, _ = handler(t.Context(), req)
+	assert.Equal(t, 2, calls)
+}
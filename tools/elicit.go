@@ -0,0 +1,283 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/grafana/mcp-k6/internal/k6opts"
+	"github.com/grafana/mcp-k6/internal/logging"
+	"github.com/grafana/mcp-k6/internal/scriptinfo"
+	"github.com/grafana/mcp-k6/internal/targetpolicy"
+	"github.com/grafana/mcp-k6/internal/workspace"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// elicitTimeout bounds each elicitation request, which waits for the user
+// to answer.
+const elicitTimeout = 5 * time.Minute
+
+// The fields of the run parameters form, besides the __ENV variables of
+// the targets.
+const (
+	vusField     = "vus"
+	confirmField = "confirm"
+)
+
+// errNoElicitation is returned by elicitors of clients that do not support
+// elicitation.
+var errNoElicitation = errors.New("the client does not support MCP elicitation")
+
+// elicitFunc asks the client's user for values.
+type elicitFunc func(ctx context.Context, request mcp.ElicitationRequest) (*mcp.ElicitationResult, error)
+
+// clientElicitor returns an elicitFunc sending elicitation requests to the
+// client of the call through s, when it declared the elicitation capability.
+func clientElicitor(s *server.MCPServer) elicitFunc {
+	return func(ctx context.Context, request mcp.ElicitationRequest) (*mcp.ElicitationResult, error) {
+		session, ok := server.ClientSessionFromContext(ctx).(server.SessionWithClientInfo)
+		if !ok || session.GetClientCapabilities().Elicitation == nil {
+			return nil, errNoElicitation
+		}
+		return s.RequestElicitation(ctx, request)
+	}
+}
+
+// runQuestions are the run parameters run_script would otherwise guess.
+type runQuestions struct {
+	// Targets are the __ENV variables request targets start with, which
+	// the run does not set.
+	Targets []string
+	// VUs is set when neither the call nor the script sets the load.
+	VUs bool
+	// Conflicts are the options of the call replacing the script's, and
+	// Execution the run k6 resolves from them.
+	Conflicts []string
+	Execution string
+}
+
+func (q *runQuestions) empty() bool {
+	return len(q.Targets) == 0 && !q.VUs && len(q.Conflicts) == 0
+}
+
+// missingRunParameters returns the questions to ask before running script
+// with options. modules resolves the local modules of the script.
+func missingRunParameters(
+	ctx context.Context,
+	modules *workspace.Workspace,
+	request mcp.CallToolRequest,
+	script string,
+	options *RunOptions,
+) *runQuestions {
+	q := &runQuestions{}
+	walkModules(ctx, modules, script, options.ScriptPath, options.DataFiles, func(_ string, info *scriptinfo.Info) {
+		for _, e := range info.Endpoints {
+			name, ok := targetpolicy.EnvVar(e.URL, e.Dynamic)
+			if !ok {
+				continue
+			}
+			if _, set := options.Env[name]; !set && options.Secrets[name] == "" {
+				q.Targets = append(q.Targets, name)
+			}
+		}
+	})
+	slices.Sort(q.Targets)
+	q.Targets = slices.Compact(q.Targets)
+	if options.Preview {
+		// Previews always run a single iteration with 1 VU
+		return q
+	}
+
+	res, info := effectiveOptions(script, buildK6Args(inlineScriptPlaceholder, options))
+	layer := k6opts.FromScript(info)
+	_, callVUs := request.GetArguments()["vus"]
+	q.VUs = !callVUs && layer["vus"] == "" && layer["duration"] == "" && layer["iterations"] == "" &&
+		layer["stages"] == "" && layer["scenarios"] == ""
+	q.Conflicts = res.Warnings
+	for _, o := range res.Options {
+		for _, prev := range o.Overridden {
+			if prev.Source == k6opts.SourceScript && prev.Value != o.Value {
+				q.Conflicts = append(q.Conflicts, fmt.Sprintf("%s=%s (%s) replaces %s=%s (script)",
+					o.Name, o.Value, o.Source, o.Name, prev.Value))
+			}
+		}
+	}
+	q.Execution = res.Execution.Description
+	return q
+}
+
+// askRunParameters asks the user of the call, through elicit, for the run
+// parameters of request that run_script would otherwise guess, and applies
+// the answers to options. modules resolves the local modules of the script.
+// It returns an error when the run must not go on: the user declined, or
+// the answers are invalid. Clients that do not support elicitation are not
+// asked, and the run goes on with the defaults.
+func askRunParameters(
+	ctx context.Context,
+	elicit elicitFunc,
+	modules *workspace.Workspace,
+	request mcp.CallToolRequest,
+	script string,
+	options *RunOptions,
+) error {
+	if elicit == nil {
+		return nil
+	}
+	q := missingRunParameters(ctx, modules, request, script, options)
+	if q.empty() {
+		return nil
+	}
+	logger := logging.LoggerFromContext(ctx)
+
+	elicitCtx, cancel := context.WithTimeout(ctx, elicitTimeout)
+	defer cancel()
+	result, err := elicit(elicitCtx, mcp.ElicitationRequest{Params: mcp.ElicitationParams{
+		Message:         runQuestionsMessage(q),
+		RequestedSchema: runQuestionsSchema(q),
+	}})
+	switch {
+	case errors.Is(err, errNoElicitation):
+		logger.DebugContext(ctx, "Running without asking for missing parameters", slog.String("reason", err.Error()))
+		return nil
+	case err != nil:
+		return fmt.Errorf("asking the user for the missing run parameters: %w", err)
+	case result.Action != mcp.ElicitationResponseActionAccept:
+		logger.InfoContext(ctx, "Run parameters not given", slog.String("action", string(result.Action)))
+		return fmt.Errorf("nothing was run: the user did not give the missing run parameters (%s); %s",
+			result.Action, runQuestionsHint(q))
+	}
+
+	answers, ok := result.Content.(map[string]any)
+	if !ok {
+		return errors.New("nothing was run: the reply to the run parameters form holds no values")
+	}
+	if err := applyRunAnswers(q, answers, options); err != nil {
+		return fmt.Errorf("nothing was run: %w", err)
+	}
+	logger.InfoContext(ctx, "Run parameters given by the user",
+		slog.Any("targets", q.Targets), slog.Int("vus", options.VUs), slog.Bool("confirmed", len(q.Conflicts) > 0))
+	return nil
+}
+
+// applyRunAnswers sets the answers to q in options.
+func applyRunAnswers(q *runQuestions, answers map[string]any, options *RunOptions) error {
+	if len(q.Conflicts) > 0 {
+		if confirmed, _ := answers[confirmField].(bool); !confirmed {
+			return errors.New("the user did not confirm the options of the run")
+		}
+	}
+
+	env := maps.Clone(options.Env)
+	if env == nil {
+		env = make(map[string]string, len(q.Targets))
+	}
+	for _, name := range q.Targets {
+		value, _ := answers[name].(string)
+		value = strings.TrimSpace(value)
+		if _, ok := targetpolicy.Host(value, false, nil); !ok {
+			return fmt.Errorf("%s must be a URL, such as https://staging.example.com, got %q", name, value)
+		}
+		env[name] = value
+	}
+	options.Env = env
+
+	if q.VUs {
+		vus, ok := answerInt(answers[vusField])
+		if !ok || vus < 1 || vus > MaxVUs {
+			return fmt.Errorf("vus must be a whole number between 1 and %d, got %v", MaxVUs, answers[vusField])
+		}
+		options.VUs = vus
+	}
+	return nil
+}
+
+// answerInt returns the integer of an answer, decoded from JSON as a
+// float64.
+func answerInt(v any) (int, bool) {
+	switch n := v.(type) {
+	case int:
+		return n, true
+	case float64:
+		if n != math.Trunc(n) || math.Abs(n) > math.MaxInt32 {
+			return 0, false
+		}
+		return int(n), true
+	}
+	return 0, false
+}
+
+// runQuestionsMessage tells the user why the run needs their answers.
+func runQuestionsMessage(q *runQuestions) string {
+	var b strings.Builder
+	b.WriteString("The k6 test needs more information before it starts:\n")
+	for _, name := range q.Targets {
+		fmt.Fprintf(&b, "- the URL the script reads from __ENV.%s to send its requests to\n", name)
+	}
+	if q.VUs {
+		fmt.Fprintf(&b, "- the number of virtual users, which neither the call nor the script sets (at most %d)\n",
+			MaxVUs)
+	}
+	if len(q.Conflicts) > 0 {
+		fmt.Fprintf(&b, "- a confirmation of the options of the run, which conflict with the script's: %s. "+
+			"k6 would run: %s.\n", strings.Join(q.Conflicts, "; "), q.Execution)
+	}
+	return b.String()
+}
+
+// runQuestionsSchema returns the form asking the questions of q.
+func runQuestionsSchema(q *runQuestions) map[string]any {
+	properties := make(map[string]any)
+	required := []string{}
+	for _, name := range q.Targets {
+		properties[name] = map[string]any{
+			"type":        "string",
+			"title":       name,
+			"description": fmt.Sprintf("The URL of the system under test, read from __ENV.%s", name),
+			"format":      "uri",
+		}
+		required = append(required, name)
+	}
+	if q.VUs {
+		properties[vusField] = map[string]any{
+			"type":        "integer",
+			"title":       "Virtual users",
+			"description": fmt.Sprintf("The number of virtual users to run, from 1 to %d", MaxVUs),
+			"minimum":     1,
+			"maximum":     MaxVUs,
+			"default":     DefaultVUs,
+		}
+		required = append(required, vusField)
+	}
+	if len(q.Conflicts) > 0 {
+		properties[confirmField] = map[string]any{
+			"type":        "boolean",
+			"title":       "Run with these options",
+			"description": "k6 would run: " + q.Execution,
+			"default":     false,
+		}
+		required = append(required, confirmField)
+	}
+	return map[string]any{"type": "object", "properties": properties, "required": required}
+}
+
+// runQuestionsHint tells the agent how to pass the parameters of q itself.
+func runQuestionsHint(q *runQuestions) string {
+	var hints []string
+	if len(q.Targets) > 0 {
+		hints = append(hints, "set "+strings.Join(q.Targets, ", ")+" in an env_file")
+	}
+	if q.VUs {
+		hints = append(hints, "pass vus")
+	}
+	if len(q.Conflicts) > 0 {
+		hints = append(hints, "check the effective options with plan_run")
+	}
+	return "ask the user, then " + strings.Join(hints, ", ") + " and call run_script again"
+}
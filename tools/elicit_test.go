@@ -0,0 +1,178 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/grafana/mcp-k6/internal/approval"
+	"github.com/grafana/mcp-k6/internal/targetpolicy"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const envTargetScript = "import http from 'k6/http';\nexport default () => http.get(`${__ENV.BASE_URL}/users`);\n"
+
+// fakeElicitor replies to elicitation requests with action and content, and
+// records the requests it was sent.
+func fakeElicitor(
+	requests *[]mcp.ElicitationRequest,
+	action mcp.ElicitationResponseAction,
+	content map[string]any,
+) elicitFunc {
+	return func(_ context.Context, request mcp.ElicitationRequest) (*mcp.ElicitationResult, error) {
+		*requests = append(*requests, request)
+		return &mcp.ElicitationResult{ElicitationResponse: mcp.ElicitationResponse{Action: action, Content: content}}, nil
+	}
+}
+
+// elicitedFields returns the required fields of the form of request.
+func elicitedFields(t *testing.T, request mcp.ElicitationRequest) []string {
+	t.Helper()
+
+	schema, ok := request.Params.RequestedSchema.(map[string]any)
+	require.True(t, ok)
+	return schema["required"].([]string)
+}
+
+func TestAskRunParameters(t *testing.T) {
+	t.Parallel()
+
+	var requests []mcp.ElicitationRequest
+	elicit := fakeElicitor(&requests, mcp.ElicitationResponseActionAccept,
+		map[string]any{"BASE_URL": " https://staging.example.com ", "vus": float64(5)})
+	deny, err := targetpolicy.New(nil, []string{"*.prod.example.com"})
+	require.NoError(t, err)
+	req := newCallRequest(map[string]any{"script": envTargetScript})
+	_, options, err := runRequest(t.Context(), nil, nil, nil, deny, elicit, req)
+	require.NoError(t, err)
+
+	require.Len(t, requests, 1)
+	assert.Equal(t, []string{"BASE_URL", "vus"}, elicitedFields(t, requests[0]))
+	assert.Contains(t, requests[0].Params.Message, "__ENV.BASE_URL")
+	assert.Equal(t, "https://staging.example.com", options.Env["BASE_URL"])
+	assert.Equal(t, 5, options.VUs)
+
+	// The answers go through the target policy
+	requests = nil
+	elicit = fakeElicitor(&requests, mcp.ElicitationResponseActionAccept,
+		map[string]any{"BASE_URL": "https://shop.prod.example.com", "vus": 1})
+	_, _, err = runRequest(t.Context(), nil, nil, nil, deny, elicit, req)
+	var policyErr *TargetPolicyError
+	require.ErrorAs(t, err, &policyErr)
+	assert.Equal(t, "shop.prod.example.com", policyErr.Violations[0].Host)
+}
+
+func TestAskRunParametersConfirmsConflicts(t *testing.T) {
+	t.Parallel()
+
+	script := "export const options = { vus: 10, duration: '1m' };\nexport default function () {}\n"
+	req := newCallRequest(map[string]any{"script": script})
+
+	var requests []mcp.ElicitationRequest
+	_, options, err := runRequest(t.Context(), nil, nil, nil, nil,
+		fakeElicitor(&requests, mcp.ElicitationResponseActionAccept, map[string]any{"confirm": true}), req)
+	require.NoError(t, err)
+	require.Len(t, requests, 1)
+	assert.Equal(t, []string{"confirm"}, elicitedFields(t, requests[0]), "the script sets the load")
+	assert.Contains(t, requests[0].Params.Message, "duration=30s (cli) replaces duration=1m (script)")
+	assert.Contains(t, requests[0].Params.Message, "vus=1 (cli) replaces vus=10 (script)")
+	assert.Equal(t, 1, options.VUs)
+
+	_, _, err = runRequest(t.Context(), nil, nil, nil, nil,
+		fakeElicitor(&requests, mcp.ElicitationResponseActionAccept, map[string]any{"confirm": false}), req)
+	require.ErrorContains(t, err, "did not confirm")
+
+	_, _, err = runRequest(t.Context(), nil, nil, nil, nil,
+		fakeElicitor(&requests, mcp.ElicitationResponseActionDecline, nil), req)
+	require.ErrorContains(t, err, "nothing was run")
+	require.ErrorContains(t, err, "plan_run")
+}
+
+func TestAskRunParametersNotNeeded(t *testing.T) {
+	t.Parallel()
+
+	var requests []mcp.ElicitationRequest
+	elicit := fakeElicitor(&requests, mcp.ElicitationResponseActionDecline, nil)
+	_, _, err := runRequest(t.Context(), nil, nil, nil, nil, elicit, newCallRequest(map[string]any{
+		"script": "import http from 'k6/http';\nexport default () => http.get('https://test.k6.io');\n",
+		"vus":    2,
+	}))
+	require.NoError(t, err)
+	_, _, err = runRequest(t.Context(), nil, nil, nil, nil, elicit, newCallRequest(map[string]any{
+		"script":  "export default function () {}\n",
+		"preview": true,
+	}))
+	require.NoError(t, err)
+	assert.Empty(t, requests)
+}
+
+func TestAskRunParametersInvalidAnswers(t *testing.T) {
+	t.Parallel()
+
+	req := newCallRequest(map[string]any{"script": envTargetScript})
+	for _, answers := range []map[string]any{
+		{"BASE_URL": "https://staging.example.com", "vus": float64(MaxVUs + 1)},
+		{"BASE_URL": "https://staging.example.com", "vus": 2.5},
+		{"BASE_URL": "", "vus": 1},
+	} {
+		var requests []mcp.ElicitationRequest
+		_, _, err := runRequest(t.Context(), nil, nil, nil, nil,
+			fakeElicitor(&requests, mcp.ElicitationResponseActionAccept, answers), req)
+		require.Error(t, err, answers)
+	}
+}
+
+func TestAskRunParametersWithoutElicitation(t *testing.T) {
+	t.Parallel()
+
+	s := server.NewMCPServer("test", "dev")
+	ctx := s.WithContext(t.Context(), testSession("no-elicitation"))
+	req := newCallRequest(map[string]any{"script": envTargetScript})
+	_, options, err := runRequest(ctx, nil, nil, nil, nil, clientElicitor(s), req)
+	require.NoError(t, err, "the run goes on with the defaults")
+	assert.Equal(t, DefaultVUs, options.VUs)
+
+	failing := func(context.Context, mcp.ElicitationRequest) (*mcp.ElicitationResult, error) {
+		return nil, errors.New("timeout")
+	}
+	_, _, err = runRequest(ctx, nil, nil, nil, nil, failing, req)
+	require.ErrorContains(t, err, "asking the user")
+}
+
+func TestAskRunParametersConfirmation(t *testing.T) {
+	t.Parallel()
+
+	gate, err := approval.New(20, 0)
+	require.NoError(t, err)
+	answers := map[string]any{"BASE_URL": "https://staging.example.com", "vus": float64(30)}
+	confirm := func(token string, vus float64) *mcp.CallToolResult {
+		args := map[string]any{"script": envTargetScript}
+		if token != "" {
+			args["confirmation_token"] = token
+		}
+		var requests []mcp.ElicitationRequest
+		answers["vus"] = vus
+		elicit := fakeElicitor(&requests, mcp.ElicitationResponseActionAccept, answers)
+		req := newCallRequest(args)
+		script, options, err := runRequest(t.Context(), nil, nil, nil, nil, elicit, req)
+		require.NoError(t, err)
+		return confirmRun(t.Context(), gate, req, script, options)
+	}
+	tokenOf := func(result *mcp.CallToolResult) string {
+		require.NotNil(t, result)
+		var resp confirmationResponse
+		decodeJSON(t, result, &resp)
+		require.Equal(t, "requires_confirmation", resp.Status)
+		return resp.ConfirmationToken
+	}
+
+	assert.Nil(t, confirm(tokenOf(confirm("", 30)), 30), "the token confirms the same answers")
+
+	result := confirm(tokenOf(confirm("", 30)), 45)
+	require.NotNil(t, result)
+	assert.True(t, result.IsError, "the token does not confirm more VUs given on the retry")
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "different parameters")
+}
@@ -0,0 +1,79 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/grafana/mcp-k6/internal/capacity"
+	"github.com/grafana/mcp-k6/internal/loadprofile"
+	"github.com/grafana/mcp-k6/internal/logging"
+	"github.com/grafana/mcp-k6/internal/slo"
+)
+
+// rampsLoad reports whether a scenario of the run ramps its VUs or arrival
+// rate, so the load at which it degrades can be estimated.
+func rampsLoad(script, file string, options *RunOptions) bool {
+	for _, p := range loadprofile.FromResult(effectiveOptions(profiledScript(script, options),
+		buildK6Args(file, options))) {
+		if p.Executor == "ramping-vus" || p.Executor == "ramping-arrival-rate" {
+			return true
+		}
+	}
+	return false
+}
+
+// capacityCriteria returns the criteria the load of a run is judged on: its
+// SLOs, or the default error rate and latency criteria without any.
+func capacityCriteria(slos []slo.SLO) []capacity.Criterion {
+	if len(slos) == 0 {
+		return capacity.DefaultCriteria()
+	}
+	criteria := make([]capacity.Criterion, 0, len(slos))
+	for _, s := range slos {
+		criteria = append(criteria, capacity.Criterion{
+			Name:       s.Name,
+			Latency:    s.Kind == slo.Latency,
+			Percentile: s.Objective,
+			Limit:      s.Limit(),
+			Tags:       s.Tags,
+		})
+	}
+	return criteria
+}
+
+// estimateCapacity returns the capacity of the system under test estimated
+// from the request log of a ramping run. A log that cannot be read leaves
+// the result without an estimate.
+func estimateCapacity(ctx context.Context, options *RunOptions) *capacity.Estimate {
+	logger := logging.LoggerFromContext(ctx)
+
+	//nolint:forbidigo // Reading the JSON output k6 has just written
+	f, err := os.Open(options.RequestLog)
+	if err != nil {
+		logger.WarnContext(ctx, "Failed to open request log", slog.String("error", err.Error()))
+		return nil
+	}
+	defer func() { _ = f.Close() }()
+
+	estimate, err := capacity.Read(f, capacityCriteria(options.SLOs))
+	if err != nil {
+		logger.WarnContext(ctx, "Failed to estimate capacity", slog.String("error", err.Error()))
+		return nil
+	}
+	return estimate
+}
+
+// capacityNextSteps points at the load the run degraded at.
+func capacityNextSteps(e *capacity.Estimate) []string {
+	if e == nil || !e.Degraded {
+		return nil
+	}
+	if e.Capacity == nil {
+		return []string{"The run degraded from its lowest load; start the ramp lower to find the capacity"}
+	}
+	return []string{fmt.Sprintf("The run degraded after %g VUs and %g requests/s; call find_capacity "+
+		"around this load to confirm it, or analyze_run on a run with output to see what degraded first",
+		e.Capacity.VUs, e.Capacity.RequestRate)}
+}
@@ -0,0 +1,43 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/grafana/mcp-k6/internal/capacity"
+	"github.com/grafana/mcp-k6/internal/slo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEstimatedCapacity(t *testing.T) {
+	t.Parallel()
+
+	script := "export default function () {}\n"
+	assert.True(t, rampsLoad(script, "script.js", &RunOptions{Scenarios: map[string]any{"ramp": map[string]any{
+		"executor": "ramping-arrival-rate", "preAllocatedVUs": 10,
+		"stages": []any{map[string]any{"duration": "5m", "target": 100}},
+	}}}))
+	assert.False(t, rampsLoad(script, "script.js", &RunOptions{VUs: 5, Duration: "1m"}))
+	// The --vus and --duration flags replace the ramping scenarios of the script
+	assert.False(t, rampsLoad(`export const options = {
+  scenarios: { ramp: { executor: 'ramping-vus', stages: [{ duration: '1m', target: 10 }] } },
+};
+export default function () {}
+`, "script.js", &RunOptions{}))
+
+	assert.Equal(t, capacity.DefaultCriteria(), capacityCriteria(nil))
+	criteria := capacityCriteria([]slo.SLO{
+		{Name: "checkout-latency", Kind: slo.Latency, Objective: 99, Latency: "300ms",
+			Tags: map[string]string{"name": "checkout"}},
+		{Name: "errors", Kind: slo.ErrorRate, Objective: 99.5},
+	})
+	require.Len(t, criteria, 2)
+	assert.Equal(t, capacity.Criterion{Name: "checkout-latency", Latency: true, Percentile: 99, Limit: 300,
+		Tags: map[string]string{"name": "checkout"}}, criteria[0])
+	assert.Equal(t, capacity.Criterion{Name: "errors", Percentile: 99.5, Limit: 0.005}, criteria[1])
+
+	assert.Nil(t, capacityNextSteps(&capacity.Estimate{Capacity: &capacity.Load{VUs: 50}}))
+	assert.Equal(t, []string{"The run degraded after 20 VUs and 41.5 requests/s; call find_capacity around this " +
+		"load to confirm it, or analyze_run on a run with output to see what degraded first"},
+		capacityNextSteps(&capacity.Estimate{Degraded: true, Capacity: &capacity.Load{VUs: 20, RequestRate: 41.5}}))
+}
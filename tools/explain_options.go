@@ -0,0 +1,103 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/grafana/mcp-k6/internal/k6opts"
+	"github.com/grafana/mcp-k6/internal/logging"
+	"github.com/grafana/mcp-k6/internal/scriptinfo"
+	"github.com/grafana/mcp-k6/internal/workspace"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// ExplainOptionsTool exposes a tool for explaining the effective k6 options of a run.
+//
+//nolint:gochecknoglobals // Shared tool definition registered at startup.
+var ExplainOptionsTool = mcp.NewTool(
+	"explain_options",
+	readOnlyTool(),
+	mcp.WithDescription(
+		"Explain which k6 options a run would end up with. Combines the script's exported options with "+
+			"proposed K6_* environment variables and k6 run command line flags using k6's precedence "+
+			"(defaults < script < env < CLI), and reports each effective value, where it came from, what it "+
+			"overrode, and the resulting scenario. Warns when overrides replace the script's scenarios.",
+	),
+	mcp.WithString(
+		"script",
+		mcp.Description("The k6 script content. Provide either script or script_path."),
+	),
+	mcp.WithString(
+		"script_path",
+		mcp.Description(scriptPathDescription),
+	),
+	mcp.WithObject(
+		"env",
+		mcp.Description("Proposed environment variables, e.g. {\"K6_VUS\": \"20\", \"K6_DURATION\": \"1m\"}."),
+		mcp.AdditionalProperties(map[string]any{"type": "string"}),
+	),
+	mcp.WithArray(
+		"cli_flags",
+		mcp.Description("Proposed k6 run arguments, e.g. [\"--vus\", \"10\", \"--stage\", \"30s:10\"]."),
+		mcp.WithStringItems(),
+	),
+)
+
+// RegisterExplainOptionsTool registers the explain_options tool with the MCP server.
+func RegisterExplainOptionsTool(s *server.MCPServer, ws *workspace.Workspace) {
+	s.AddTool(ExplainOptionsTool, withToolLogger("explain_options", newExplainOptionsHandlerFunc(ws)))
+}
+
+// newExplainOptionsHandlerFunc returns an MCP tool handler bound to a workspace.
+func newExplainOptionsHandlerFunc(ws *workspace.Workspace) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		logger := logging.LoggerFromContext(ctx)
+
+		script, _, err := readScriptArgument(ctx, ws, request)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		env, err := stringMapArgument(request, "env")
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		cli, warnings := k6opts.FromFlags(request.GetStringSlice("cli_flags", nil))
+		result := k6opts.Resolve(k6opts.FromScript(scriptinfo.Analyze(script)), k6opts.FromEnv(env), cli)
+		result.Warnings = append(warnings, result.Warnings...)
+
+		logger.InfoContext(ctx, "Options explained",
+			slog.Int("options", len(result.Options)),
+			slog.String("execution_source", string(result.Execution.Source)),
+			slog.Int("warnings", len(result.Warnings)))
+
+		return marshalResponse(ctx, logger, result)
+	}
+}
+
+// stringMapArgument returns an object parameter whose values must be strings.
+// Numbers and booleans are accepted and formatted, as clients often send them.
+func stringMapArgument(request mcp.CallToolRequest, name string) (map[string]string, error) {
+	raw, ok := request.GetArguments()[name]
+	if !ok || raw == nil {
+		return nil, nil
+	}
+	obj, ok := raw.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("'%s' must be an object of string values", name)
+	}
+	values := make(map[string]string, len(obj))
+	for k, v := range obj {
+		switch t := v.(type) {
+		case string:
+			values[k] = t
+		case float64, bool:
+			values[k] = fmt.Sprint(t)
+		default:
+			return nil, fmt.Errorf("'%s.%s' must be a string", name, k)
+		}
+	}
+	return values, nil
+}
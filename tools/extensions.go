@@ -0,0 +1,169 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os/exec"
+	"slices"
+	"strings"
+
+	"github.com/grafana/mcp-k6/internal/k6env"
+	"github.com/grafana/mcp-k6/internal/logging"
+	"github.com/grafana/mcp-k6/internal/scriptinfo"
+)
+
+// knownExtensions maps the JavaScript modules of popular extensions to the Go
+// module k6 is built with to provide them.
+//
+//nolint:gochecknoglobals // Lookup table.
+var knownExtensions = map[string]string{
+	"k6/x/kafka":     "github.com/mostafa/xk6-kafka",
+	"k6/x/sql":       "github.com/grafana/xk6-sql",
+	"k6/x/faker":     "github.com/grafana/xk6-faker",
+	"k6/x/amqp":      "github.com/grafana/xk6-amqp",
+	"k6/x/exec":      "github.com/grafana/xk6-exec",
+	"k6/x/mqtt":      "github.com/grafana/xk6-mqtt",
+	"k6/x/sse":       "github.com/phymbert/xk6-sse",
+	"k6/x/disruptor": "github.com/grafana/xk6-disruptor",
+}
+
+// extensionModule returns the Go module providing the JavaScript module, or
+// "" when it is unknown. xk6-sql drivers, such as k6/x/sql/driver/postgres,
+// are extensions of their own.
+func extensionModule(module string) string {
+	if driver, ok := strings.CutPrefix(module, "k6/x/sql/driver/"); ok {
+		return "github.com/grafana/xk6-sql-driver-" + driver
+	}
+	return knownExtensions[module]
+}
+
+// xk6BuildCommand returns the xk6 command building a k6 binary with the
+// extensions.
+func xk6BuildCommand(extensions []string) string {
+	var b strings.Builder
+	b.WriteString("xk6 build")
+	for _, ext := range extensions {
+		b.WriteString(" --with " + ext)
+	}
+	return b.String()
+}
+
+// missingExtension is an extension module a script imports that the k6
+// executable was built without.
+type missingExtension struct {
+	Module string `json:"module"`
+	Line   int    `json:"line,omitempty"`
+	// Extension is the Go module providing Module, empty when unknown.
+	Extension string `json:"extension,omitempty"`
+}
+
+// missingExtensions returns the k6/x modules script imports that the k6 on the
+// PATH lacks. It returns nil when k6 cannot be found or queried, as running it
+// reports that better.
+func missingExtensions(ctx context.Context, script string) []missingExtension {
+	var imports []scriptinfo.Import
+	for _, imp := range scriptinfo.Analyze(script).Imports {
+		if imp.Kind == "extension" {
+			imports = append(imports, imp)
+		}
+	}
+	if len(imports) == 0 {
+		return nil
+	}
+
+	extensions, ok := installedExtensions(ctx)
+	if !ok {
+		return nil
+	}
+
+	var missing []missingExtension
+	for _, imp := range imports {
+		if !providesModule(extensions, imp.Module) {
+			missing = append(missing, missingExtension{
+				Module:    imp.Module,
+				Line:      imp.Line,
+				Extension: extensionModule(imp.Module),
+			})
+		}
+	}
+	return missing
+}
+
+// installedExtensions returns the extensions of the k6 on the PATH, and false
+// when k6 cannot be found or queried.
+func installedExtensions(ctx context.Context) ([]k6env.Extension, bool) {
+	info, err := k6env.Locate(ctx)
+	if err != nil {
+		return nil, false
+	}
+	extensions, err := info.Extensions(ctx)
+	if err != nil {
+		logging.LoggerFromContext(ctx).WarnContext(ctx, "Failed to list k6 extensions",
+			slog.String("error", err.Error()))
+		return nil, false
+	}
+	return extensions, true
+}
+
+// providesModule reports whether one of extensions provides the JavaScript
+// module.
+func providesModule(extensions []k6env.Extension, module string) bool {
+	return slices.ContainsFunc(extensions, func(ext k6env.Extension) bool {
+		return slices.Contains(ext.Imports, module)
+	})
+}
+
+// missingExtensionsResponse reports extension modules the k6 executable lacks,
+// without running k6, with the xk6 command building one that has them.
+func missingExtensionsResponse(missing []missingExtension) *ValidationResponse {
+	resp := &ValidationResponse{
+		Valid: false,
+		Error: "the script imports extension modules the k6 executable was built without",
+		Summary: ValidationSummary{
+			Status:      "failed",
+			Description: "Script imports extension modules the k6 executable was built without",
+			IssueCount:  len(missing),
+			Severity:    "critical",
+			ReadyToRun:  false,
+		},
+		Recommendations: []string{
+			"Build a k6 binary with the extensions using xk6, and put it on the PATH in place of k6",
+		},
+	}
+
+	var build []string
+	for _, m := range missing {
+		suggestion := fmt.Sprintf("Find the extension providing %s in the k6 extensions registry: "+
+			"https://grafana.com/docs/k6/latest/extensions/explore/", m.Module)
+		if m.Extension != "" {
+			suggestion = "Build k6 with it: " + xk6BuildCommand([]string{m.Extension})
+			if !slices.Contains(build, m.Extension) {
+				build = append(build, m.Extension)
+			}
+		}
+		resp.Issues = append(resp.Issues, ValidationIssue{
+			Type:       "extension",
+			Severity:   "critical",
+			Message:    fmt.Sprintf("The k6 executable does not provide the extension module %s", m.Module),
+			Suggestion: suggestion,
+			LineNumber: m.Line,
+		})
+	}
+
+	if len(build) > 0 {
+		command := xk6BuildCommand(build)
+		if _, err := exec.LookPath("xk6"); err == nil {
+			resp.NextSteps = append(resp.NextSteps, fmt.Sprintf("Build k6 with the extensions: %s", command))
+		} else {
+			resp.NextSteps = append(resp.NextSteps,
+				fmt.Sprintf("Install xk6 (go install go.k6.io/xk6/cmd/xk6@latest), then build k6 with the "+
+					"extensions: %s", command),
+				fmt.Sprintf("Or build it with Docker: docker run --rm -u \"$(id -u):$(id -g)\" -v \"${PWD}:/xk6\" "+
+					"grafana/%s", command))
+		}
+	}
+	resp.NextSteps = append(resp.NextSteps,
+		"Put the ./k6 binary xk6 writes on the PATH in place of k6, then validate the script again")
+	return resp
+}
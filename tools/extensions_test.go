@@ -0,0 +1,37 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtensionModule(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "github.com/mostafa/xk6-kafka", extensionModule("k6/x/kafka"))
+	assert.Equal(t, "github.com/grafana/xk6-mqtt", extensionModule("k6/x/mqtt"))
+	assert.Equal(t, "github.com/phymbert/xk6-sse", extensionModule("k6/x/sse"))
+	assert.Equal(t, "github.com/grafana/xk6-sql-driver-postgres", extensionModule("k6/x/sql/driver/postgres"))
+	assert.Empty(t, extensionModule("k6/x/unknown"))
+}
+
+func TestMissingExtensionsResponse(t *testing.T) {
+	t.Parallel()
+
+	resp := missingExtensionsResponse([]missingExtension{
+		{Module: "k6/x/kafka", Line: 2, Extension: "github.com/mostafa/xk6-kafka"},
+		{Module: "k6/x/sql", Line: 3, Extension: "github.com/grafana/xk6-sql"},
+		{Module: "k6/x/unknown", Line: 4},
+	})
+	assert.False(t, resp.Valid)
+	assert.Equal(t, "critical", resp.Summary.Severity)
+	require.Len(t, resp.Issues, 3)
+	assert.Equal(t, "extension", resp.Issues[0].Type)
+	assert.Equal(t, 2, resp.Issues[0].LineNumber)
+	assert.Equal(t, "Build k6 with it: xk6 build --with github.com/mostafa/xk6-kafka", resp.Issues[0].Suggestion)
+	assert.Contains(t, resp.Issues[2].Suggestion, "extensions registry")
+	assert.Contains(t, resp.NextSteps[0],
+		"xk6 build --with github.com/mostafa/xk6-kafka --with github.com/grafana/xk6-sql")
+}
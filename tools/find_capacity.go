@@ -0,0 +1,471 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"time"
+
+	"github.com/grafana/mcp-k6/internal/approval"
+	"github.com/grafana/mcp-k6/internal/importpolicy"
+	"github.com/grafana/mcp-k6/internal/jslib"
+	"github.com/grafana/mcp-k6/internal/logging"
+	"github.com/grafana/mcp-k6/internal/ownership"
+	"github.com/grafana/mcp-k6/internal/redact"
+	"github.com/grafana/mcp-k6/internal/secrets"
+	"github.com/grafana/mcp-k6/internal/summary"
+	"github.com/grafana/mcp-k6/internal/targetpolicy"
+	"github.com/grafana/mcp-k6/internal/workspace"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	// DefaultProbeDuration is the default duration of a capacity probe.
+	DefaultProbeDuration = 20 * time.Second
+
+	// MaxProbeDuration is the maximum duration of a capacity probe.
+	MaxProbeDuration = time.Minute
+
+	// DefaultProbes is the default number of capacity probes.
+	DefaultProbes = 6
+
+	// MaxProbes is the maximum number of capacity probes.
+	MaxProbes = 8
+
+	// MaxProbeRate is the highest arrival rate a probe may target, in
+	// iterations per second.
+	MaxProbeRate = 10000
+
+	// probeScenario names the scenario of a capacity probe.
+	probeScenario = "capacity_probe"
+
+	// droppedTolerance is the share of a probe's iterations k6 may drop,
+	// for lack of a free VU, before the rate counts as not sustained.
+	droppedTolerance = 0.01
+)
+
+// FindCapacityTool exposes a tool for searching the highest arrival rate a system sustains.
+//
+//nolint:gochecknoglobals // Shared tool definition registered at startup.
+var FindCapacityTool = mcp.NewTool(
+	"find_capacity",
+	runTool(),
+	mcp.WithDescription(
+		"Find the maximum sustainable throughput of the system under test. Runs short constant-arrival-rate "+
+			"probes of the script, bisecting the rate between min_rate and max_rate until the SLO, given as "+
+			"thresholds, fails. A probe also fails when k6 drops more than 1% of its iterations because every "+
+			"VU is busy. Returns the highest passing rate and the evidence of each probe. A search above the "+
+			"server's confirmation limits, for max_vus or all probes together, returns status "+
+			"requires_confirmation first, as run_script does.",
+	),
+	mcp.WithString(
+		"script",
+		mcp.Description("The k6 script content; each probe iteration runs its default function. "+
+			"Provide either script or script_path."),
+	),
+	mcp.WithString(
+		"script_path",
+		mcp.Description(scriptPathDescription),
+	),
+	mcp.WithString(
+		"env_file",
+		mcp.Description(envFileDescription),
+	),
+	mcp.WithArray(
+		"secrets",
+		mcp.Description(secretsDescription),
+		mcp.WithStringItems(),
+	),
+	mcp.WithObject(
+		"thresholds",
+		mcp.Required(),
+		mcp.Description(
+			"The SLO each probe must meet, mapping metrics to expressions, e.g. "+
+				"{\"http_req_duration\": [\"p(95)<500\"], \"http_req_failed\": \"rate<0.01\"}.",
+		),
+	),
+	mcp.WithNumber(
+		"max_rate",
+		mcp.Required(),
+		mcp.Description(fmt.Sprintf("Highest rate to probe, in iterations per second (up to %d).", MaxProbeRate)),
+	),
+	mcp.WithNumber(
+		"min_rate",
+		mcp.Description("Lowest rate to probe, in iterations per second (default: 1)."),
+	),
+	mcp.WithNumber(
+		"resolution",
+		mcp.Description("Stop once the passing and failing rates are this close, in iterations per second (default: 1)."),
+	),
+	mcp.WithString(
+		"probe_duration",
+		mcp.Description(fmt.Sprintf("Duration of each probe, e.g. '30s' (default: %v, max: %v).",
+			DefaultProbeDuration, MaxProbeDuration)),
+	),
+	mcp.WithNumber(
+		"max_probes",
+		mcp.Description(fmt.Sprintf("Maximum number of probes to run (default: %d, max: %d).", DefaultProbes, MaxProbes)),
+	),
+	mcp.WithNumber(
+		"max_vus",
+		mcp.Description(fmt.Sprintf("VUs allocated to each probe (default and max: %d).", MaxVUs)),
+	),
+	mcp.WithString(
+		"exec",
+		mcp.Description("Optional: exported function the probes run instead of the default function."),
+	),
+	mcp.WithString(
+		"confirmation_token",
+		mcp.Description(confirmationTokenDescription),
+	),
+)
+
+// capacitySearch holds the parameters of a find_capacity call.
+type capacitySearch struct {
+	MinRate    int
+	MaxRate    int
+	Resolution int
+	Duration   time.Duration
+	MaxProbes  int
+	MaxVUs     int
+	Exec       string
+	Thresholds map[string][]string
+}
+
+// load returns the most the search may run: every probe, at max_vus.
+func (c *capacitySearch) load() approval.Load {
+	return approval.Load{VUs: c.MaxVUs, Duration: c.Duration * time.Duration(c.MaxProbes)}
+}
+
+// CapacityProbe is the evidence of one probe run.
+type CapacityProbe struct {
+	Rate   int  `json:"rate"`
+	Passed bool `json:"passed"`
+	// Reason tells why a probe failed: "slo" when a threshold of the SLO
+	// failed, "dropped_iterations" when k6 could not sustain the rate.
+	Reason     string              `json:"reason,omitempty"`
+	ExitCode   int                 `json:"exit_code"`
+	Elapsed    string              `json:"elapsed,omitempty"`
+	Thresholds []summary.Threshold `json:"thresholds,omitempty"`
+	Error      string              `json:"error,omitempty"`
+}
+
+// findCapacityResponse is the JSON structure returned by the tool.
+type findCapacityResponse struct {
+	// MaxSustainableRate is the highest passing rate, or 0 when even
+	// min_rate failed.
+	MaxSustainableRate int `json:"max_sustainable_rate"`
+	// FirstFailingRate is the lowest failing rate, or 0 when max_rate passed.
+	FirstFailingRate int             `json:"first_failing_rate,omitempty"`
+	ReachedMaxRate   bool            `json:"reached_max_rate"`
+	TimeUnit         string          `json:"time_unit"`
+	ProbeDuration    string          `json:"probe_duration"`
+	Probes           []CapacityProbe `json:"probes"`
+	NextSteps        []string        `json:"next_steps,omitempty"`
+}
+
+// probeFunc runs one probe; RunK6Test outside of tests.
+type probeFunc func(ctx context.Context, script string, options *RunOptions) (*RunResult, error)
+
+// RegisterFindCapacityTool registers the find_capacity tool with the MCP server.
+func RegisterFindCapacityTool(
+	s *server.MCPServer,
+	ws *workspace.Workspace,
+	rd *redact.Redactor,
+	reg *secrets.Registry,
+	ip *importpolicy.Policy,
+	tp *targetpolicy.Policy,
+	mirror *jslib.Mirror,
+	ov *ownership.Verifier,
+	gate *approval.Gate,
+) {
+	s.AddTool(FindCapacityTool,
+		withToolLogger("find_capacity", newFindCapacityHandlerFunc(ws, rd, reg, ip, tp, mirror, ov, gate)))
+}
+
+func newFindCapacityHandlerFunc(
+	ws *workspace.Workspace,
+	rd *redact.Redactor,
+	reg *secrets.Registry,
+	ip *importpolicy.Policy,
+	tp *targetpolicy.Policy,
+	mirror *jslib.Mirror,
+	ov *ownership.Verifier,
+	gate *approval.Gate,
+) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		logger := logging.LoggerFromContext(ctx)
+
+		script, scriptPath, err := readScriptArgument(ctx, ws, request)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		env, err := readEnvFileArgument(ctx, ws, request)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		secretValues, err := secretsArgument(reg, request)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		if err := checkImportPolicy(ctx, ws, ip, script, scriptPath, nil); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		if err := checkTargetPolicy(ctx, ws, tp, script, scriptPath, nil, env); err != nil {
+			return requestError(err), nil
+		}
+		search, err := capacityArguments(request)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		base := RunOptions{
+			ScriptPath: scriptPath,
+			Env:        env,
+			Secrets:    secretValues,
+			Redactor:   rd,
+			JSLib:      mirror,
+		}
+		if err := verifyOwnership(ctx, ov, ws, script, &base, search.MaxVUs); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		if result := confirmLoad(ctx, gate, request, script, search.load()); result != nil {
+			return result, nil
+		}
+		resp, err := findCapacity(ctx, RunK6Test, script, base, search)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		logger.InfoContext(ctx, "Capacity search completed",
+			slog.Int("max_sustainable_rate", resp.MaxSustainableRate),
+			slog.Int("probes", len(resp.Probes)))
+
+		return marshalResponse(ctx, logger, resp)
+	}
+}
+
+// capacityArguments reads and validates the search parameters.
+func capacityArguments(request mcp.CallToolRequest) (*capacitySearch, error) {
+	thresholds, err := thresholdsArgument(request)
+	if err != nil {
+		return nil, err
+	}
+	if len(thresholds) == 0 {
+		return nil, errors.New("'thresholds' is required: find_capacity searches for the rate where they fail")
+	}
+	if err := validateThresholdOptions(&RunOptions{Thresholds: thresholds}); err != nil {
+		return nil, err
+	}
+	maxRate, err := request.RequireInt("max_rate")
+	if err != nil {
+		return nil, err
+	}
+
+	search := &capacitySearch{
+		MinRate:    request.GetInt("min_rate", 1),
+		MaxRate:    maxRate,
+		Resolution: request.GetInt("resolution", 1),
+		Duration:   DefaultProbeDuration,
+		MaxProbes:  request.GetInt("max_probes", DefaultProbes),
+		MaxVUs:     request.GetInt("max_vus", MaxVUs),
+		Exec:       request.GetString("exec", ""),
+		Thresholds: thresholds,
+	}
+	if d := request.GetString("probe_duration", ""); d != "" {
+		if search.Duration, err = time.ParseDuration(d); err != nil {
+			return nil, fmt.Errorf("probe_duration must be a duration like '30s', got %q", d)
+		}
+	}
+
+	switch {
+	case search.MinRate < 1:
+		return nil, errors.New("min_rate must be at least 1")
+	case search.MaxRate > MaxProbeRate:
+		return nil, fmt.Errorf("max_rate cannot exceed %d", MaxProbeRate)
+	case search.MaxRate <= search.MinRate:
+		return nil, errors.New("max_rate must be greater than min_rate")
+	case search.Resolution < 1:
+		return nil, errors.New("resolution must be at least 1")
+	case search.Duration < time.Second || search.Duration > MaxProbeDuration:
+		return nil, fmt.Errorf("probe_duration must be between 1s and %v", MaxProbeDuration)
+	case search.MaxProbes < 2 || search.MaxProbes > MaxProbes:
+		return nil, fmt.Errorf("max_probes must be between 2 and %d", MaxProbes)
+	case search.MaxVUs < 1 || search.MaxVUs > MaxVUs:
+		return nil, fmt.Errorf("max_vus must be between 1 and %d", MaxVUs)
+	}
+	return search, nil
+}
+
+// findCapacity probes min_rate, then max_rate, then bisects between the
+// highest passing and lowest failing rates until they are within the
+// resolution or the probes run out.
+func findCapacity(
+	ctx context.Context,
+	probe probeFunc,
+	script string,
+	base RunOptions,
+	search *capacitySearch,
+) (*findCapacityResponse, error) {
+	resp := &findCapacityResponse{
+		TimeUnit:      "1s",
+		ProbeDuration: search.Duration.String(),
+		Probes:        []CapacityProbe{},
+	}
+	run := func(rate int) (bool, error) {
+		if err := ctx.Err(); err != nil {
+			return false, err
+		}
+		p, err := runProbe(ctx, probe, script, base, search, rate)
+		if err != nil {
+			return false, err
+		}
+		resp.Probes = append(resp.Probes, *p)
+		return p.Passed, nil
+	}
+
+	passed, err := run(search.MinRate)
+	if err != nil {
+		return nil, err
+	}
+	if !passed {
+		resp.FirstFailingRate = search.MinRate
+		resp.NextSteps = []string{
+			"Even min_rate failed the SLO; check the failed thresholds of the probe, lower min_rate, " +
+				"or validate the script with run_script preview=true",
+		}
+		return resp, nil
+	}
+	lo, hi := search.MinRate, search.MaxRate
+	if passed, err = run(hi); err != nil {
+		return nil, err
+	}
+	if passed {
+		resp.MaxSustainableRate, resp.ReachedMaxRate = hi, true
+		resp.NextSteps = []string{
+			"max_rate passed the SLO; raise max_rate to keep searching, or max_vus if probes were VU-bound",
+		}
+		return resp, nil
+	}
+
+	for len(resp.Probes) < search.MaxProbes && hi-lo > search.Resolution {
+		mid := lo + (hi-lo)/2
+		if passed, err = run(mid); err != nil {
+			return nil, err
+		}
+		if passed {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	resp.MaxSustainableRate, resp.FirstFailingRate = lo, hi
+
+	resp.NextSteps = append(resp.NextSteps, fmt.Sprintf(
+		"The system sustains %d iterations/s within the SLO and fails it at %d; "+
+			"confirm with a longer run_script at about %d using a constant-arrival-rate scenario",
+		lo, hi, int(math.Floor(float64(lo)*0.9))))
+	if hi-lo > search.Resolution {
+		resp.NextSteps = append(resp.NextSteps,
+			"The probes ran out before reaching the resolution; narrow min_rate and max_rate to refine")
+	}
+	for _, p := range resp.Probes {
+		if p.Reason == "dropped_iterations" {
+			resp.NextSteps = append(resp.NextSteps,
+				"Some probes dropped iterations: every VU was busy, so the limit may be max_vus rather than "+
+					"the system; check iteration_duration and sleep() calls in the script")
+			break
+		}
+	}
+	return resp, nil
+}
+
+// runProbe runs the script at rate for the probe duration and judges the
+// outcome from the exit code and the thresholds of the summary.
+func runProbe(
+	ctx context.Context,
+	probe probeFunc,
+	script string,
+	base RunOptions,
+	search *capacitySearch,
+	rate int,
+) (*CapacityProbe, error) {
+	options := base
+	options.Scenarios = map[string]any{probeScenario: probeScenarioOptions(search, rate)}
+	options.Thresholds = make(map[string][]string, len(search.Thresholds)+1)
+	for metric, exprs := range search.Thresholds {
+		options.Thresholds[metric] = exprs
+	}
+	if _, ok := options.Thresholds["dropped_iterations"]; !ok {
+		expected := float64(rate) * search.Duration.Seconds()
+		options.Thresholds["dropped_iterations"] = []string{
+			fmt.Sprintf("count<=%d", int(math.Floor(expected*droppedTolerance))),
+		}
+	}
+	options.AbortOnFail = true
+	options.DelayAbortEval = (search.Duration / 4).Round(time.Second).String()
+
+	result, err := probe(ctx, script, &options)
+	if err != nil {
+		return nil, fmt.Errorf("probe at %d iterations/s: %w", rate, err)
+	}
+	if result == nil {
+		return nil, fmt.Errorf("probe at %d iterations/s returned no result", rate)
+	}
+
+	p := &CapacityProbe{Rate: rate, ExitCode: result.ExitCode, Error: result.Error}
+	output := result.Stdout + "\n" + result.Stderr
+	p.Thresholds = summary.Thresholds(output)
+	if elapsed, ok := summary.Elapsed(output); ok {
+		p.Elapsed = elapsed.String()
+	}
+
+	switch {
+	case result.ExitCode == 0:
+		p.Passed, p.Error = true, ""
+	case result.ExitCode == ThresholdsExitCode:
+		p.Reason, p.Error = probeFailure(p.Thresholds), ""
+	default:
+		return nil, fmt.Errorf("probe at %d iterations/s did not complete (exit code %d): %s",
+			rate, result.ExitCode, result.Error)
+	}
+	return p, nil
+}
+
+// probeFailure returns "dropped_iterations" when only the dropped
+// iterations threshold failed, and "slo" otherwise.
+func probeFailure(thresholds []summary.Threshold) string {
+	dropped := false
+	for _, th := range thresholds {
+		switch {
+		case th.Passed:
+		case th.Metric == "dropped_iterations":
+			dropped = true
+		default:
+			return "slo"
+		}
+	}
+	if dropped {
+		return "dropped_iterations"
+	}
+	return "slo"
+}
+
+// probeScenarioOptions returns the constant-arrival-rate scenario of a probe.
+func probeScenarioOptions(search *capacitySearch, rate int) map[string]any {
+	sc := map[string]any{
+		"executor":        "constant-arrival-rate",
+		"rate":            rate,
+		"timeUnit":        "1s",
+		"duration":        search.Duration.String(),
+		"preAllocatedVUs": search.MaxVUs,
+		"maxVUs":          search.MaxVUs,
+	}
+	if search.Exec != "" {
+		sc["exec"] = search.Exec
+	}
+	return sc
+}
@@ -0,0 +1,191 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/grafana/mcp-k6/internal/approval"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeCapacity returns a probe function for a system that meets the SLO up
+// to capacity iterations per second, and records the probed rates.
+func fakeCapacity(capacity int, rates *[]int, options **RunOptions) probeFunc {
+	return func(_ context.Context, _ string, o *RunOptions) (*RunResult, error) {
+		rate, _ := o.Scenarios[probeScenario].(map[string]any)["rate"].(int)
+		*rates = append(*rates, rate)
+		*options = o
+		mark, code := "✓", 0
+		if rate > capacity {
+			mark, code = "✗", ThresholdsExitCode
+		}
+		stdout := fmt.Sprintf("  █ THRESHOLDS\n\n    http_req_duration\n    %s 'p(95)<500' p(95)=%dms\n\n"+
+			"  █ TOTAL RESULTS\n\nrunning (0m20.0s), 00/50 VUs\n", mark, rate*3)
+		return &RunResult{Success: code == 0, ExitCode: code, Stdout: stdout}, nil
+	}
+}
+
+func testCapacitySearch() *capacitySearch {
+	return &capacitySearch{
+		MinRate:    10,
+		MaxRate:    200,
+		Resolution: 5,
+		Duration:   20 * time.Second,
+		MaxProbes:  8,
+		MaxVUs:     MaxVUs,
+		Thresholds: map[string][]string{"http_req_duration": {"p(95)<500"}},
+	}
+}
+
+func TestFindCapacity(t *testing.T) {
+	t.Parallel()
+
+	var rates []int
+	var last *RunOptions
+	resp, err := findCapacity(context.Background(), fakeCapacity(137, &rates, &last), testRunScript,
+		RunOptions{ScriptPath: "/work/api.js"}, testCapacitySearch())
+	require.NoError(t, err)
+
+	assert.Equal(t, []int{10, 200, 105, 152, 128, 140, 134, 137}, rates)
+	assert.Equal(t, 137, resp.MaxSustainableRate)
+	assert.Equal(t, 140, resp.FirstFailingRate)
+	assert.False(t, resp.ReachedMaxRate)
+	require.Len(t, resp.Probes, 8)
+	assert.True(t, resp.Probes[0].Passed)
+	assert.False(t, resp.Probes[1].Passed)
+	assert.Equal(t, "slo", resp.Probes[1].Reason)
+	assert.Equal(t, "p(95)=600ms", resp.Probes[1].Thresholds[0].Value)
+	assert.Equal(t, "20s", resp.Probes[1].Elapsed)
+	assert.Contains(t, resp.NextSteps[0], "sustains 137 iterations/s")
+
+	assert.Equal(t, "/work/api.js", last.ScriptPath)
+	assert.True(t, last.AbortOnFail)
+	assert.Equal(t, "5s", last.DelayAbortEval)
+	assert.Equal(t, []string{"count<=27"}, last.Thresholds["dropped_iterations"])
+	assert.Equal(t, map[string]any{
+		"executor":        "constant-arrival-rate",
+		"rate":            137,
+		"timeUnit":        "1s",
+		"duration":        "20s",
+		"preAllocatedVUs": MaxVUs,
+		"maxVUs":          MaxVUs,
+	}, last.Scenarios[probeScenario])
+}
+
+func TestFindCapacityBounds(t *testing.T) {
+	t.Parallel()
+
+	var rates []int
+	var last *RunOptions
+	resp, err := findCapacity(context.Background(), fakeCapacity(5, &rates, &last), testRunScript,
+		RunOptions{}, testCapacitySearch())
+	require.NoError(t, err)
+	assert.Equal(t, []int{10}, rates)
+	assert.Equal(t, 0, resp.MaxSustainableRate)
+	assert.Equal(t, 10, resp.FirstFailingRate)
+
+	rates = nil
+	resp, err = findCapacity(context.Background(), fakeCapacity(500, &rates, &last), testRunScript,
+		RunOptions{}, testCapacitySearch())
+	require.NoError(t, err)
+	assert.Equal(t, []int{10, 200}, rates)
+	assert.Equal(t, 200, resp.MaxSustainableRate)
+	assert.True(t, resp.ReachedMaxRate)
+
+	failing := func(context.Context, string, *RunOptions) (*RunResult, error) {
+		return &RunResult{ExitCode: 107, Error: "k6 test failed with exit code 107"}, nil
+	}
+	_, err = findCapacity(context.Background(), failing, testRunScript, RunOptions{}, testCapacitySearch())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "did not complete (exit code 107)")
+}
+
+func TestProbeFailure(t *testing.T) {
+	t.Parallel()
+
+	stdout := "  █ THRESHOLDS\n\n    dropped_iterations\n    ✗ 'count<=4' count=31\n\n" +
+		"    http_req_duration\n    ✓ 'p(95)<500' p(95)=120ms\n\n  █ TOTAL RESULTS\n"
+	probe := func(context.Context, string, *RunOptions) (*RunResult, error) {
+		return &RunResult{ExitCode: ThresholdsExitCode, Stdout: stdout}, nil
+	}
+	p, err := runProbe(context.Background(), probe, testRunScript, RunOptions{}, testCapacitySearch(), 20)
+	require.NoError(t, err)
+	assert.False(t, p.Passed)
+	assert.Equal(t, "dropped_iterations", p.Reason)
+}
+
+func TestCapacityArguments(t *testing.T) {
+	t.Parallel()
+
+	slo := map[string]any{"http_req_duration": "p(95)<500"}
+	tests := map[string]struct {
+		args map[string]any
+		err  string
+	}{
+		"missing thresholds": {map[string]any{"max_rate": float64(100)}, "'thresholds' is required"},
+		"missing max_rate":   {map[string]any{"thresholds": slo}, "max_rate"},
+		"inverted rates": {
+			map[string]any{"thresholds": slo, "min_rate": float64(50), "max_rate": float64(10)},
+			"greater than min_rate",
+		},
+		"rate too high": {map[string]any{"thresholds": slo, "max_rate": float64(20000)}, "cannot exceed"},
+		"long probes": {
+			map[string]any{"thresholds": slo, "max_rate": float64(100), "probe_duration": "5m"},
+			"probe_duration must be between",
+		},
+		"too many probes": {
+			map[string]any{"thresholds": slo, "max_rate": float64(100), "max_probes": float64(20)},
+			"max_probes must be between",
+		},
+		"too many vus": {
+			map[string]any{"thresholds": slo, "max_rate": float64(100), "max_vus": float64(100)},
+			"max_vus must be between",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			req := mcp.CallToolRequest{}
+			req.Params.Arguments = tt.args
+			_, err := capacityArguments(req)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]any{"thresholds": slo, "max_rate": float64(100), "probe_duration": "30s"}
+	search, err := capacityArguments(req)
+	require.NoError(t, err)
+	assert.Equal(t, 1, search.MinRate)
+	assert.Equal(t, 30*time.Second, search.Duration)
+	assert.Equal(t, DefaultProbes, search.MaxProbes)
+}
+
+func TestFindCapacityConfirmation(t *testing.T) {
+	t.Parallel()
+
+	gate, err := approval.New(20, 0)
+	require.NoError(t, err)
+	handler := newFindCapacityHandlerFunc(nil, nil, nil, nil, nil, nil, nil, gate)
+	req := newCallRequest(map[string]any{
+		"script":     testRunScript,
+		"thresholds": map[string]any{"http_req_duration": "p(95)<500"},
+		"max_rate":   float64(MaxProbeRate),
+	})
+	req.Params.Name = "find_capacity"
+	result, err := handler(context.Background(), req)
+	require.NoError(t, err)
+	require.False(t, result.IsError, result.Content)
+
+	// No probe ran: the search allocates max_vus above the limit
+	var resp confirmationResponse
+	decodeJSON(t, result, &resp)
+	assert.Equal(t, "requires_confirmation", resp.Status)
+	assert.Equal(t, MaxVUs, resp.PeakVUs)
+	assert.Equal(t, "2m0s", resp.Duration)
+}
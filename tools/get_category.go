@@ -0,0 +1,168 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/grafana/mcp-k6/internal/logging"
+	"github.com/grafana/xk6-docs/docs"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// GetCategoryTool exposes a tool for retrieving a whole documentation subtree
+// in one call.
+//
+//nolint:gochecknoglobals // Shared tool definition registered at startup.
+var GetCategoryTool = mcp.NewTool(
+	"get_category",
+	readOnlyTool(),
+	mcp.WithDescription(
+		"Retrieves the markdown of a documentation section and every page under it, concatenated "+
+			"in reading order up to a size budget (e.g., the whole 'using-k6/scenarios' chapter). "+
+			"Use this instead of many get_documentation calls when the context allows; "+
+			"pass 'skip' with the returned next_skip to read the pages that did not fit.",
+	),
+	mcp.WithString(
+		"slug",
+		mcp.Required(),
+		mcp.Description(
+			"Slug of the section to retrieve with its subtree (e.g., 'using-k6/scenarios'). "+
+				"Supports aliases and full docs URLs.",
+		),
+	),
+	mcp.WithString(
+		"version",
+		mcp.Description("Optional: k6 version (e.g., 'v1.4.x'). Defaults to latest."),
+	),
+	mcp.WithNumber(
+		"max_bytes",
+		mcp.Description(fmt.Sprintf(
+			"Optional: size budget of the content in bytes (default: %d, max: %d). "+
+				"Pages are never split: a page that does not fit is left for the next call.",
+			defaultCategoryBytes, maxCategoryBytes)),
+	),
+	mcp.WithNumber(
+		"skip",
+		mcp.Description("Optional: number of pages of the subtree to skip, the next_skip of a previous call."),
+	),
+	outputSchema(getCategoryResponse{}),
+)
+
+const (
+	defaultCategoryBytes = 100_000
+	maxCategoryBytes     = 1_000_000
+	// categoryDepth bounds the subtrees get_category walks, deeper than the
+	// docs go.
+	categoryDepth = 32
+)
+
+// getCategoryResponse is the JSON structure returned by the tool.
+type getCategoryResponse struct {
+	Section           responseSection `json:"section"`
+	Content           string          `json:"content"`
+	Pages             []categoryPage  `json:"pages"`
+	TotalPages        int             `json:"total_pages"`
+	Remaining         int             `json:"remaining"`
+	Version           string          `json:"version"`
+	AvailableVersions []string        `json:"available_versions"`
+	// NextSkip is the skip to read on with, when pages remain.
+	NextSkip int `json:"next_skip,omitempty"`
+}
+
+// categoryPage is a page included in the content of a get_category response.
+type categoryPage struct {
+	Slug  string `json:"slug"`
+	Title string `json:"title"`
+	Bytes int    `json:"bytes"`
+}
+
+// RegisterGetCategoryTool registers the get_category tool with the MCP server.
+func RegisterGetCategoryTool(s *server.MCPServer, catalog *docs.Catalog) {
+	s.AddTool(GetCategoryTool, withToolLogger("get_category", newGetCategoryHandlerFunc(catalog)))
+}
+
+func newGetCategoryHandlerFunc(catalog *docs.Catalog) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		logger := logging.LoggerFromContext(ctx)
+
+		params, err := parseGetDocParams(request)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		budget := request.GetInt("max_bytes", defaultCategoryBytes)
+		if budget < 1 || budget > maxCategoryBytes {
+			return mcp.NewToolResultError(fmt.Sprintf(
+				"max_bytes must be between 1 and %d, got %d", maxCategoryBytes, budget)), nil
+		}
+		skip := request.GetInt("skip", 0)
+		if skip < 0 {
+			return mcp.NewToolResultError(fmt.Sprintf("skip must not be negative, got %d", skip)), nil
+		}
+
+		idx, err := catalog.Index(ctx, params.Version)
+		if err != nil {
+			return mcp.NewToolResultError(versionError(params.Version, catalog, err).Error()), nil
+		}
+		root, err := lookupSection(ctx, logger, idx, params.Slug)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		sections := []*docs.Section{root}
+		for _, t := range idx.Tree(root.Slug, categoryDepth) {
+			sections = append(sections, t.Section)
+		}
+		if skip >= len(sections) {
+			return mcp.NewToolResultError(fmt.Sprintf(
+				"skip %d is past the %d pages of %s", skip, len(sections), root.Slug)), nil
+		}
+
+		resp := getCategoryResponse{
+			Section:           toResponseSection(idx, root),
+			Pages:             []categoryPage{},
+			TotalPages:        len(sections),
+			Version:           idx.Version,
+			AvailableVersions: catalog.Versions(),
+		}
+		var content strings.Builder
+		next := skip
+		for ; next < len(sections); next++ {
+			sec := sections[next]
+			page, err := readMarkdownContent(ctx, logger, catalog, idx.Version, sec)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			text := categoryPageText(sec, page)
+			// The first page is always returned, so every call makes progress
+			if next > skip && content.Len()+len(text) > budget {
+				break
+			}
+			content.WriteString(text)
+			resp.Pages = append(resp.Pages, categoryPage{Slug: sec.Slug, Title: sec.Title, Bytes: len(text)})
+		}
+		resp.Content = content.String()
+		resp.Remaining = len(sections) - next
+		if resp.Remaining > 0 {
+			resp.NextSkip = next
+		}
+
+		logger.InfoContext(ctx, "Documentation category retrieved",
+			slog.String("slug", root.Slug),
+			slog.String("version", idx.Version),
+			slog.Int("pages", len(resp.Pages)),
+			slog.Int("remaining", resp.Remaining),
+			slog.Int("content_size", content.Len()))
+
+		return structuredResponse(ctx, logger, resp)
+	}
+}
+
+// categoryPageText returns a page of a category without its frontmatter,
+// after a comment naming it, as pages are concatenated.
+func categoryPageText(sec *docs.Section, content []byte) string {
+	_, body, _ := docs.SplitFrontmatter(string(content))
+	return fmt.Sprintf("<!-- page: %s (%s) -->\n\n%s\n\n", sec.Slug, sec.Title, strings.TrimSpace(body))
+}
@@ -0,0 +1,98 @@
+package tools
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/grafana/xk6-docs/docs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetCategory(t *testing.T) {
+	t.Parallel()
+
+	sections := []docs.Section{
+		{
+			Slug: "using-k6/scenarios", RelPath: "using-k6/scenarios/_index.md", Title: "Scenarios",
+			Children: []string{"using-k6/scenarios/executors", "using-k6/scenarios/concepts"},
+		},
+		{Slug: "using-k6/scenarios/concepts", RelPath: "using-k6/scenarios/concepts.md", Title: "Concepts", Weight: 1},
+		{
+			Slug: "using-k6/scenarios/executors", RelPath: "using-k6/scenarios/executors/_index.md", Title: "Executors",
+			Weight: 2, Children: []string{"using-k6/scenarios/executors/ramping-vus"},
+		},
+		{
+			Slug: "using-k6/scenarios/executors/ramping-vus", RelPath: "using-k6/scenarios/executors/ramping-vus.md",
+			Title: "Ramping VUs",
+		},
+		{Slug: "using-k6/thresholds", RelPath: "using-k6/thresholds.md", Title: "Thresholds"},
+	}
+	index, err := json.Marshal(docs.Index{Version: "v1.4.x", Sections: sections})
+	require.NoError(t, err)
+	fsys := fstest.MapFS{"v1.4.x/sections.json": {Data: index}}
+	for _, sec := range sections {
+		fsys["v1.4.x/markdown/"+sec.RelPath] = &fstest.MapFile{
+			Data: []byte("---\ntitle: " + sec.Title + "\n---\n" + strings.Repeat(sec.Title+" text. ", 20)),
+		}
+	}
+	handler := newGetCategoryHandlerFunc(docs.NewCatalog(docs.WithFS(fsys)))
+
+	call := func(args map[string]any) getCategoryResponse {
+		t.Helper()
+		result, err := handler(t.Context(), newCallRequest(args))
+		require.NoError(t, err)
+		require.False(t, result.IsError, "unexpected tool error: %+v", result.Content)
+		resp, ok := result.StructuredContent.(getCategoryResponse)
+		require.True(t, ok)
+		return resp
+	}
+
+	// The subtree in reading order, without frontmatter
+	resp := call(map[string]any{"slug": "https://grafana.com/docs/k6/latest/using-k6/scenarios/"})
+	assert.Equal(t, "using-k6/scenarios", resp.Section.Slug)
+	assert.Equal(t, 4, resp.TotalPages)
+	assert.Zero(t, resp.Remaining)
+	assert.Zero(t, resp.NextSkip)
+	slugs := make([]string, 0, len(resp.Pages))
+	for _, page := range resp.Pages {
+		slugs = append(slugs, page.Slug)
+	}
+	assert.Equal(t, []string{
+		"using-k6/scenarios", "using-k6/scenarios/concepts",
+		"using-k6/scenarios/executors", "using-k6/scenarios/executors/ramping-vus",
+	}, slugs)
+	assert.Contains(t, resp.Content, "<!-- page: using-k6/scenarios/executors/ramping-vus (Ramping VUs) -->")
+	assert.NotContains(t, resp.Content, "title:")
+	assert.NotContains(t, resp.Content, "Thresholds")
+
+	// A budget for about two pages leaves the rest for the next call
+	budget := 2*resp.Pages[0].Bytes + 10
+	resp = call(map[string]any{"slug": "using-k6/scenarios", "max_bytes": budget})
+	assert.Len(t, resp.Pages, 2)
+	assert.LessOrEqual(t, len(resp.Content), budget)
+	assert.Equal(t, 2, resp.Remaining)
+	assert.Equal(t, 2, resp.NextSkip)
+
+	resp = call(map[string]any{"slug": "using-k6/scenarios", "max_bytes": budget, "skip": resp.NextSkip})
+	assert.Equal(t, "using-k6/scenarios/executors", resp.Pages[0].Slug)
+	assert.Equal(t, 2-len(resp.Pages), resp.Remaining)
+
+	// The first page is returned even when it is over the budget
+	resp = call(map[string]any{"slug": "using-k6/scenarios", "max_bytes": 1})
+	assert.Len(t, resp.Pages, 1)
+	assert.Equal(t, 1, resp.NextSkip)
+
+	for _, args := range []map[string]any{
+		{"slug": "using-k6/scenarios", "skip": 4},
+		{"slug": "using-k6/scenarios", "max_bytes": 0},
+		{"slug": "using-k6/scenarios", "skip": -1},
+		{"slug": "using-k6/scenarois"},
+	} {
+		result, err := handler(t.Context(), newCallRequest(args))
+		require.NoError(t, err)
+		assert.True(t, result.IsError, "%v", args)
+	}
+}
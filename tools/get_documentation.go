@@ -4,6 +4,9 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"net/url"
+	"path"
+	"slices"
 	"strings"
 
 	"github.com/grafana/mcp-k6/internal/logging"
@@ -17,9 +20,11 @@ import (
 //nolint:gochecknoglobals // Shared tool definition registered at startup.
 var GetDocumentationTool = mcp.NewTool(
 	"get_documentation",
+	readOnlyTool(),
 	mcp.WithDescription(
 		"Retrieves the full markdown content of a specific k6 documentation section. "+
-			"Use the slug from list_sections output (e.g., 'using-k6/scenarios', 'javascript-api/k6-http/request'). "+
+			"Use the slug from list_sections output (e.g., 'using-k6/scenarios', 'javascript-api/k6-http/request'), "+
+			"or paste a grafana.com/docs/k6 URL. "+
 			"Returns the complete markdown content with frontmatter metadata. "+
 			"Supports multiple k6 versions - specify version parameter or defaults to latest. "+
 			"Use this when you need detailed documentation for a specific topic.",
@@ -29,7 +34,8 @@ var GetDocumentationTool = mcp.NewTool(
 		mcp.Required(),
 		mcp.Description(
 			"Section slug to retrieve (e.g., 'using-k6/scenarios', 'javascript-api/k6-http'). "+
-				"Get valid slugs from list_sections tool. Supports aliases.",
+				"Get valid slugs from list_sections tool. Supports aliases and full docs URLs "+
+				"(e.g., 'https://grafana.com/docs/k6/latest/using-k6/scenarios/').",
 		),
 	),
 	mcp.WithString(
@@ -39,12 +45,22 @@ var GetDocumentationTool = mcp.NewTool(
 				"Use list_sections with version='all' to see available versions.",
 		),
 	),
+	mcp.WithNumber(
+		"offset",
+		mcp.Description(
+			"Optional: byte offset of the content to start from, to read on after a truncated response.",
+		),
+	),
+	outputSchema(getDocResponse{}),
 )
 
 // getDocParams holds parsed request parameters.
 type getDocParams struct {
 	Slug    string
 	Version string
+	Offset  int
+	// Requested is the slug argument as given, before a URL is resolved.
+	Requested string
 }
 
 // responseSection mirrors the legacy MCP response shape for a section. The
@@ -60,6 +76,11 @@ type responseSection struct {
 	Category    string   `json:"category"`
 	Hierarchy   []string `json:"hierarchy"`
 	IsIndex     bool     `json:"is_index"`
+	// Parent is the slug of the section listing this one as a child.
+	Parent string `json:"parent,omitempty"`
+	// Breadcrumb holds the titles from the top-level category down to the
+	// section, such as ["Using k6", "Scenarios", "Executors"].
+	Breadcrumb []string `json:"breadcrumb,omitempty"`
 }
 
 // getDocResponse is the JSON structure returned by the tool.
@@ -68,6 +89,13 @@ type getDocResponse struct {
 	Content           string          `json:"content"`
 	Version           string          `json:"version"`
 	AvailableVersions []string        `json:"available_versions"`
+	// CanonicalSlug and RedirectedFrom are set when the requested slug is an
+	// alias or a URL of the section.
+	CanonicalSlug  string `json:"canonical_slug,omitempty"`
+	RedirectedFrom string `json:"redirected_from,omitempty"`
+	// Offset is where content starts in the section, when it does not start
+	// at the beginning.
+	Offset int `json:"offset,omitempty"`
 }
 
 // RegisterGetDocumentationTool registers the get documentation tool with the MCP server.
@@ -113,6 +141,11 @@ func newGetDocumentationHandlerFunc(
 		if err != nil {
 			return mcp.NewToolResultError(err.Error()), nil
 		}
+		if params.Offset > len(content) {
+			return mcp.NewToolResultError(fmt.Sprintf(
+				"offset %d is past the end of the section (%d bytes)", params.Offset, len(content))), nil
+		}
+		content = content[params.Offset:]
 
 		logger.InfoContext(ctx, "Documentation retrieved successfully",
 			slog.String("slug", params.Slug),
@@ -121,13 +154,21 @@ func newGetDocumentationHandlerFunc(
 			slog.Int("content_size", len(content)))
 
 		resp := getDocResponse{
-			Section:           toResponseSection(section),
+			Section:           toResponseSection(idx, section),
 			Content:           string(content),
+			Offset:            params.Offset,
 			Version:           idx.Version,
 			AvailableVersions: catalog.Versions(),
 		}
+		if params.Requested != section.Slug {
+			logger.DebugContext(ctx, "Slug redirected",
+				slog.String("requested", params.Requested),
+				slog.String("slug", section.Slug))
+			resp.CanonicalSlug = section.Slug
+			resp.RedirectedFrom = params.Requested
+		}
 
-		return marshalResponse(ctx, logger, resp)
+		return structuredResponse(ctx, logger, resp)
 	}
 }
 
@@ -137,10 +178,50 @@ func parseGetDocParams(request mcp.CallToolRequest) (*getDocParams, error) {
 		return nil, fmt.Errorf("missing or invalid slug parameter: %w", err)
 	}
 
-	return &getDocParams{
-		Slug:    slug,
-		Version: request.GetString("version", ""),
-	}, nil
+	offset := request.GetInt("offset", 0)
+	if offset < 0 {
+		return nil, fmt.Errorf("offset must not be negative, got %d", offset)
+	}
+
+	params := &getDocParams{
+		Slug:      strings.Trim(strings.TrimSpace(slug), "/"),
+		Version:   request.GetString("version", ""),
+		Offset:    offset,
+		Requested: slug,
+	}
+	if version, docSlug, ok := parseDocURL(slug); ok {
+		params.Slug = docSlug
+		// An explicit version wins over the one of the URL
+		if params.Version == "" {
+			params.Version = version
+		}
+	}
+	if params.Slug == "" {
+		return nil, fmt.Errorf("missing or invalid slug parameter: %q names no section", slug)
+	}
+	return params, nil
+}
+
+// parseDocURL returns the version and slug of a k6 docs URL, such as
+// https://grafana.com/docs/k6/v1.4.x/using-k6/scenarios/#concepts, or of its
+// path. The version is empty for "latest".
+func parseDocURL(s string) (version, slug string, ok bool) {
+	u, err := url.Parse(strings.TrimSpace(s))
+	if err != nil {
+		return "", "", false
+	}
+	if u.Host != "" && u.Hostname() != "grafana.com" && u.Hostname() != "www.grafana.com" {
+		return "", "", false
+	}
+	rest, ok := strings.CutPrefix(u.Path, "/docs/k6/")
+	if !ok {
+		return "", "", false
+	}
+	version, slug, _ = strings.Cut(rest, "/")
+	if version == "latest" || version == "next" {
+		version = ""
+	}
+	return version, strings.Trim(slug, "/"), true
 }
 
 func lookupSection(
@@ -160,8 +241,8 @@ func lookupSection(
 			slog.String("version", idx.Version))
 
 		return nil, fmt.Errorf(
-			"section not found: %s in version %s. Use list_sections tool to find valid slugs",
-			slug, idx.Version,
+			"section not found: %s in version %s.%s Use list_sections tool to find valid slugs",
+			slug, idx.Version, didYouMean(suggestSlugs(idx, slug)),
 		)
 	}
 
@@ -197,9 +278,10 @@ func readMarkdownContent(
 }
 
 // toResponseSection maps a docs.Section to the legacy MCP response shape,
-// deriving hierarchy from the relative path's directory components.
-func toResponseSection(sec *docs.Section) responseSection {
-	return responseSection{
+// deriving hierarchy from the relative path's directory components and the
+// parent and breadcrumb from the sections of idx.
+func toResponseSection(idx *docs.Index, sec *docs.Section) responseSection {
+	resp := responseSection{
 		Slug:        sec.Slug,
 		RelPath:     sec.RelPath,
 		Title:       sec.Title,
@@ -210,6 +292,39 @@ func toResponseSection(sec *docs.Section) responseSection {
 		Hierarchy:   hierarchyFromRelPath(sec.RelPath),
 		IsIndex:     sec.IsIndex,
 	}
+	if parent := parentSection(idx, sec); parent != nil {
+		resp.Parent = parent.Slug
+	}
+	resp.Breadcrumb = breadcrumb(idx, sec)
+	return resp
+}
+
+// parentSection returns the section listing sec as a child, or else the
+// section of the parent directory of its slug, nil for top-level sections.
+func parentSection(idx *docs.Index, sec *docs.Section) *docs.Section {
+	for i := range idx.Sections {
+		if slices.Contains(idx.Sections[i].Children, sec.Slug) {
+			return &idx.Sections[i]
+		}
+	}
+	for dir := path.Dir(sec.Slug); dir != "." && dir != "/"; dir = path.Dir(dir) {
+		if parent, ok := idx.Lookup(dir); ok && parent.Slug != sec.Slug {
+			return parent
+		}
+	}
+	return nil
+}
+
+// breadcrumb returns the titles of the ancestors of sec, then its own.
+func breadcrumb(idx *docs.Index, sec *docs.Section) []string {
+	titles := []string{sec.Title}
+	seen := map[string]bool{sec.Slug: true}
+	for parent := parentSection(idx, sec); parent != nil && !seen[parent.Slug]; parent = parentSection(idx, parent) {
+		seen[parent.Slug] = true
+		titles = append(titles, parent.Title)
+	}
+	slices.Reverse(titles)
+	return titles
 }
 
 // hierarchyFromRelPath returns the directory components of relPath, matching
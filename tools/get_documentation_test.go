@@ -0,0 +1,124 @@
+package tools
+
+import (
+	"encoding/json"
+	"testing"
+	"testing/fstest"
+
+	"github.com/grafana/xk6-docs/docs"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetDocumentationRedirects(t *testing.T) {
+	t.Parallel()
+
+	fsys := fstest.MapFS{}
+	for _, version := range []string{"v1.4.x", "v1.3.x"} {
+		index, err := json.Marshal(docs.Index{Version: version, Sections: []docs.Section{{
+			Slug:    "using-k6/scenarios",
+			RelPath: "using-k6/scenarios/_index.md",
+			Title:   "Scenarios",
+			Aliases: []string{"scenarios"},
+		}}})
+		require.NoError(t, err)
+		fsys[version+"/sections.json"] = &fstest.MapFile{Data: index}
+		fsys[version+"/markdown/using-k6/scenarios/_index.md"] = &fstest.MapFile{Data: []byte("# Scenarios " + version)}
+	}
+	handler := newGetDocumentationHandlerFunc(docs.NewCatalog(docs.WithFS(fsys)))
+
+	for _, tc := range []struct {
+		name, slug, version       string
+		wantVersion, wantRedirect string
+		wantCanonical             string
+	}{
+		{name: "slug", slug: "using-k6/scenarios", wantVersion: "v1.4.x"},
+		{
+			name: "alias", slug: "scenarios", wantVersion: "v1.4.x",
+			wantRedirect: "scenarios", wantCanonical: "using-k6/scenarios",
+		},
+		{
+			name: "latest url", slug: "https://grafana.com/docs/k6/latest/using-k6/scenarios/#concepts",
+			wantVersion:   "v1.4.x",
+			wantRedirect:  "https://grafana.com/docs/k6/latest/using-k6/scenarios/#concepts",
+			wantCanonical: "using-k6/scenarios",
+		},
+		{
+			name: "versioned path", slug: "/docs/k6/v1.3.x/scenarios/", wantVersion: "v1.3.x",
+			wantRedirect: "/docs/k6/v1.3.x/scenarios/", wantCanonical: "using-k6/scenarios",
+		},
+		{
+			name: "explicit version", slug: "https://grafana.com/docs/k6/v1.3.x/using-k6/scenarios/",
+			version: "v1.4.x", wantVersion: "v1.4.x",
+			wantRedirect:  "https://grafana.com/docs/k6/v1.3.x/using-k6/scenarios/",
+			wantCanonical: "using-k6/scenarios",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := handler(t.Context(), newCallRequest(map[string]any{"slug": tc.slug, "version": tc.version}))
+			require.NoError(t, err)
+			require.False(t, result.IsError, "unexpected tool error: %+v", result.Content)
+			resp, ok := result.StructuredContent.(getDocResponse)
+			require.True(t, ok)
+			assert.Equal(t, "using-k6/scenarios", resp.Section.Slug)
+			assert.Equal(t, tc.wantVersion, resp.Version)
+			assert.Equal(t, "# Scenarios "+tc.wantVersion, resp.Content)
+			assert.Equal(t, tc.wantCanonical, resp.CanonicalSlug)
+			assert.Equal(t, tc.wantRedirect, resp.RedirectedFrom)
+		})
+	}
+
+	// URLs of other sites are looked up as slugs, and not found
+	result, err := handler(t.Context(), newCallRequest(map[string]any{"slug": "https://k6.io/docs/using-k6/scenarios/"}))
+	require.NoError(t, err)
+	require.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "section not found")
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "Did you mean: using-k6/scenarios?")
+}
+
+func TestParseDocURL(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		in, version, slug string
+		ok                bool
+	}{
+		{in: "https://grafana.com/docs/k6/latest/javascript-api/k6-http/", slug: "javascript-api/k6-http", ok: true},
+		{in: "https://www.grafana.com/docs/k6/v0.57.x/using-k6/", version: "v0.57.x", slug: "using-k6", ok: true},
+		{in: "/docs/k6/next/using-k6/scenarios", slug: "using-k6/scenarios", ok: true},
+		{in: "https://example.com/docs/k6/latest/using-k6/"},
+		{in: "using-k6/scenarios"},
+	} {
+		version, slug, ok := parseDocURL(tc.in)
+		assert.Equal(t, tc.ok, ok, tc.in)
+		assert.Equal(t, tc.version, version, tc.in)
+		assert.Equal(t, tc.slug, slug, tc.in)
+	}
+}
+
+func TestToResponseSectionBreadcrumb(t *testing.T) {
+	t.Parallel()
+
+	idx := &docs.Index{Version: "v1.4.x", Sections: []docs.Section{
+		{Slug: "using-k6", Title: "Using k6", Children: []string{"using-k6/scenarios"}},
+		{Slug: "using-k6/scenarios", Title: "Scenarios", Children: []string{"using-k6/scenarios/executors"}},
+		{Slug: "using-k6/scenarios/executors", Title: "Executors"},
+		// Not listed as a child: its parent is found from its slug
+		{Slug: "using-k6/scenarios/executors/ramping-vus", Title: "Ramping VUs"},
+	}}
+
+	sec := toResponseSection(idx, &idx.Sections[2])
+	assert.Equal(t, "using-k6/scenarios", sec.Parent)
+	assert.Equal(t, []string{"Using k6", "Scenarios", "Executors"}, sec.Breadcrumb)
+
+	sec = toResponseSection(idx, &idx.Sections[3])
+	assert.Equal(t, "using-k6/scenarios/executors", sec.Parent)
+	assert.Equal(t, []string{"Using k6", "Scenarios", "Executors", "Ramping VUs"}, sec.Breadcrumb)
+
+	sec = toResponseSection(idx, &idx.Sections[0])
+	assert.Empty(t, sec.Parent)
+	assert.Equal(t, []string{"Using k6"}, sec.Breadcrumb)
+}
@@ -0,0 +1,124 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/grafana/mcp-k6/internal/gitsource"
+	"github.com/grafana/mcp-k6/internal/helpers"
+	"github.com/grafana/mcp-k6/internal/logging"
+	"github.com/grafana/mcp-k6/internal/workspace"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// gitRepoDescription documents the git_repo parameter of run_script.
+const gitRepoDescription = "Run the script from a Git repository instead of 'script' or 'script_path': an https " +
+	"or ssh URL, such as https://github.com/org/tests.git. The server checks out git_ref into a temporary " +
+	"directory with its own git credentials, and runs git_path from there, so that what runs is what was " +
+	"reviewed. Local modules and open() paths resolve inside the repository."
+
+// gitRefDescription documents the git_ref parameter of run_script.
+const gitRefDescription = "With git_repo: the commit to run. A full commit SHA pins the exact script; " +
+	"a branch or tag runs the commit it points to when called, which the result reports."
+
+// gitPathDescription documents the git_path parameter of run_script.
+const gitPathDescription = "With git_repo: the script to run, relative to the root of the repository."
+
+// gitSourceArgument returns the git source of a request, or nil when it
+// sets no git_repo.
+func gitSourceArgument(request mcp.CallToolRequest) (*gitsource.Source, error) {
+	src := &gitsource.Source{
+		Repo: request.GetString("git_repo", ""),
+		Ref:  request.GetString("git_ref", ""),
+		Path: request.GetString("git_path", ""),
+	}
+	switch {
+	case src.Repo == "" && (src.Ref != "" || src.Path != ""):
+		return nil, errors.New("git_ref and git_path apply with git_repo")
+	case src.Repo == "":
+		return nil, nil //nolint:nilnil // The script is inline or in the workspace.
+	case request.GetString("script", "") != "" || request.GetString("script_path", "") != "":
+		return nil, errors.New("provide only one of 'script', 'script_path' and 'git_repo'")
+	case src.Ref == "" || src.Path == "":
+		return nil, errors.New("git_repo takes the commit to run as git_ref and the script as git_path")
+	}
+	if err := src.Validate(); err != nil {
+		return nil, err
+	}
+	return src, nil
+}
+
+// readRunScript returns the script of a request and its path, checked out
+// of git_repo when set, along with the workspace its local modules are read
+// from. The checkout, if any, is the caller's to remove.
+func readRunScript(
+	ctx context.Context,
+	ws *workspace.Workspace,
+	request mcp.CallToolRequest,
+) (script, path string, modules *workspace.Workspace, co *gitsource.Checkout, err error) {
+	src, err := gitSourceArgument(request)
+	if err != nil {
+		return "", "", nil, nil, err
+	}
+	if src == nil {
+		script, path, err = readScriptArgument(ctx, ws, request)
+		return script, path, ws, nil, err
+	}
+
+	co, err = gitsource.Clone(ctx, *src)
+	if err != nil {
+		return "", "", nil, nil, fmt.Errorf("checking out git_repo: %w", err)
+	}
+	script, path, err = readCheckoutScript(ctx, co)
+	if err != nil {
+		removeCheckout(ctx, co)
+		return "", "", nil, nil, err
+	}
+	return script, path, checkoutWorkspace(ws, co), co, nil
+}
+
+// readCheckoutScript returns the content and path of the script of co,
+// which cannot be a link out of the repository.
+func readCheckoutScript(ctx context.Context, co *gitsource.Checkout) (string, string, error) {
+	data, resolved, err := checkoutWorkspace(nil, co).ReadFile(ctx, co.Path, MaxScriptSize)
+	if err != nil {
+		return "", "", fmt.Errorf("reading git_path %s at commit %s: %w", co.Path, co.Commit, err)
+	}
+
+	logging.LoggerFromContext(ctx).InfoContext(ctx, "Checked out script from git",
+		slog.String("repo", co.Repo),
+		slog.String("commit", co.Commit),
+		slog.String("script_path", helpers.GetPathType(resolved)),
+		slog.Int("script_size", len(data)))
+
+	return string(data), resolved, nil
+}
+
+// checkoutWorkspace returns the workspace of the working tree of co, or ws
+// when co is nil.
+func checkoutWorkspace(ws *workspace.Workspace, co *gitsource.Checkout) *workspace.Workspace {
+	if co == nil {
+		return ws
+	}
+	return workspace.New(nil, co.Dir)
+}
+
+// removeCheckout removes the working tree of co, if any, once its run ended.
+func removeCheckout(ctx context.Context, co *gitsource.Checkout) {
+	if err := co.Remove(); err != nil {
+		logging.LoggerFromContext(ctx).WarnContext(ctx, "Failed to remove git checkout",
+			slog.String("operation", "cleanup"),
+			slog.String("error", err.Error()))
+	}
+}
+
+// gitNextSteps suggests how to run the same commit of co again.
+func gitNextSteps(co *gitsource.Checkout) []string {
+	if co.Pinned() {
+		return nil
+	}
+	return []string{fmt.Sprintf("git_ref %s resolved to commit %s: pass the commit as git_ref to run this "+
+		"exact script again, as branches and tags can move", co.Ref, co.Commit)}
+}
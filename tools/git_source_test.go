@@ -0,0 +1,134 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/grafana/mcp-k6/internal/gitsource"
+	"github.com/grafana/mcp-k6/internal/targetpolicy"
+	"github.com/grafana/mcp-k6/internal/workspace"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGitSourceArgument(t *testing.T) {
+	t.Parallel()
+
+	src, err := gitSourceArgument(newCallRequest(map[string]any{"script": "export default function () {}"}))
+	require.NoError(t, err)
+	assert.Nil(t, src)
+
+	src, err = gitSourceArgument(newCallRequest(map[string]any{
+		"git_repo": "https://github.com/grafana/k6.git",
+		"git_ref":  "v1.0.0",
+		"git_path": "examples/http_get.js",
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, &gitsource.Source{
+		Repo: "https://github.com/grafana/k6.git", Ref: "v1.0.0", Path: "examples/http_get.js",
+	}, src)
+
+	for name, args := range map[string]map[string]any{
+		"no repo": {"git_ref": "main", "git_path": "load.js"},
+		"script":  {"git_repo": "https://example.com/t.git", "git_ref": "main", "git_path": "load.js", "script": "x"},
+		"no ref":  {"git_repo": "https://example.com/t.git", "git_path": "load.js"},
+		"no path": {"git_repo": "https://example.com/t.git", "git_ref": "main"},
+		"local":   {"git_repo": "/srv/tests", "git_ref": "main", "git_path": "load.js"},
+		"escape":  {"git_repo": "https://example.com/t.git", "git_ref": "main", "git_path": "../load.js"},
+	} {
+		_, err := gitSourceArgument(newCallRequest(args))
+		require.Error(t, err, name)
+	}
+}
+
+// testCheckout returns a checkout of files.
+func testCheckout(t *testing.T, src gitsource.Source, files map[string]string) *gitsource.Checkout {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		//nolint:forbidigo // Test fixture.
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+	}
+	return &gitsource.Checkout{Source: src, Dir: dir}
+}
+
+func TestReadCheckoutScript(t *testing.T) {
+	t.Parallel()
+
+	co := testCheckout(t, gitsource.Source{Repo: "https://example.com/t.git", Ref: "main", Path: "load.js"},
+		map[string]string{
+			"load.js": "import { get } from './lib.js';\nexport default function () { get(); }",
+			"lib.js":  "import http from 'k6/http';\nexport function get() { http.get('https://test.k6.io'); }",
+		})
+	script, path, err := readCheckoutScript(t.Context(), co)
+	require.NoError(t, err)
+	assert.Contains(t, script, "./lib.js")
+	assert.Equal(t, "load.js", filepath.Base(path))
+
+	// Local modules resolve in the checkout, not in the client's workspace
+	deny, err := targetpolicy.New(nil, []string{"test.k6.io"})
+	require.NoError(t, err)
+	err = checkTargetPolicy(t.Context(), checkoutWorkspace(workspace.New(nil, t.TempDir()), co), deny,
+		script, path, nil, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "test.k6.io")
+
+	co.Path = "missing.js"
+	_, _, err = readCheckoutScript(t.Context(), co)
+	require.Error(t, err)
+}
+
+func TestCheckoutEntryFile(t *testing.T) {
+	t.Parallel()
+
+	co := testCheckout(t, gitsource.Source{Repo: "https://example.com/t.git", Ref: "main", Path: "load.js"},
+		map[string]string{
+			"load.js":    "const users = JSON.parse(open('./users.json'));\nexport default function () {}",
+			"users.json": "[]",
+		})
+	script, path, err := readCheckoutScript(t.Context(), co)
+	require.NoError(t, err)
+	options := &RunOptions{ScriptPath: path, Checkout: co, AbortOnFail: true,
+		Thresholds: map[string][]string{"http_req_duration": {"p(95)<500"}}}
+	source, err := entryModule(path, script, options)
+	require.NoError(t, err)
+
+	// With thresholds, open() still resolves in the checkout
+	entry, cleanup, err := createEntryFile(path, source, options)
+	require.NoError(t, err)
+	defer cleanup()
+	dir, err := filepath.EvalSymlinks(co.Dir)
+	require.NoError(t, err)
+	entryDir, err := filepath.EvalSymlinks(filepath.Dir(entry))
+	require.NoError(t, err)
+	assert.Equal(t, dir, entryDir)
+}
+
+func TestReadCheckoutScriptRejectsLinksOut(t *testing.T) {
+	t.Parallel()
+
+	outside := filepath.Join(t.TempDir(), "secret.js")
+	//nolint:forbidigo // Test fixture.
+	require.NoError(t, os.WriteFile(outside, []byte("export default function () {}"), 0o600))
+	co := testCheckout(t, gitsource.Source{Path: "load.js"}, nil)
+	//nolint:forbidigo // Test fixture.
+	require.NoError(t, os.Symlink(outside, filepath.Join(co.Dir, "load.js")))
+
+	_, _, err := readCheckoutScript(t.Context(), co)
+	require.ErrorIs(t, err, workspace.ErrOutsideRoots)
+}
+
+func TestGitNextSteps(t *testing.T) {
+	t.Parallel()
+
+	commit := strings.Repeat("ab", 20)
+	co := &gitsource.Checkout{Source: gitsource.Source{Ref: "main", Commit: commit}}
+	steps := gitNextSteps(co)
+	require.Len(t, steps, 1)
+	assert.Contains(t, steps[0], "resolved to commit "+commit)
+
+	co.Ref = commit
+	assert.Empty(t, gitNextSteps(co))
+}
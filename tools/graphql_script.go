@@ -0,0 +1,283 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+
+	"github.com/grafana/mcp-k6/internal/graphql"
+	"github.com/grafana/mcp-k6/internal/logging"
+	"github.com/grafana/mcp-k6/internal/workspace"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// GenerateGraphQLScriptTool exposes a tool for writing GraphQL load tests from
+// a schema.
+//
+//nolint:gochecknoglobals // Shared tool definition registered at startup.
+var GenerateGraphQLScriptTool = mcp.NewTool(
+	"generate_graphql_script",
+	readOnlyTool(),
+	mcp.WithDescription(
+		"Generate a k6 load test for a GraphQL API from its schema (SDL or introspection JSON). Operations on "+
+			"root fields select their scalar fields down to 'depth' levels and turn required arguments into "+
+			"variables with sample values; operation documents are used as given. Each request is tagged with "+
+			"its operation name, with a latency and an error-free checks threshold per operation. Responses "+
+			"with GraphQL errors fail their checks even with status 200.",
+	),
+	mcp.WithString(
+		"schema",
+		mcp.Description("The schema as SDL, or the JSON result of an introspection query. "+
+			"Provide either schema or schema_path."),
+	),
+	mcp.WithString(
+		"schema_path",
+		mcp.Description("Path to the schema file (.graphql or introspection .json) inside a workspace root."),
+	),
+	mcp.WithString(
+		"url",
+		mcp.Required(),
+		mcp.Description("The GraphQL endpoint, e.g. https://api.example.com/graphql. "+
+			"The script reads it from the GRAPHQL_URL environment variable first."),
+	),
+	mcp.WithArray(
+		"operations",
+		mcp.WithStringItems(),
+		mcp.Description(fmt.Sprintf("Optional: operations to load test, as root fields ('pizza', "+
+			"'Mutation.createPizza') or operation documents ('query Menu { pizzas { name } }'). "+
+			"Default: the first %d fields of the query type.", maxGraphQLOperations)),
+	),
+	mcp.WithBoolean(
+		"batch",
+		mcp.Description("Optional: send all operations in one batched request, a JSON array of operations, "+
+			"for servers supporting query batching such as Apollo Server (default: false)."),
+	),
+	mcp.WithNumber(
+		"depth",
+		mcp.Description(fmt.Sprintf("Optional: levels of nested object fields selected by operations on "+
+			"root fields (default: %d, max: %d).", defaultGraphQLDepth, maxGraphQLDepth)),
+	),
+	mcp.WithString(
+		"latency_threshold",
+		mcp.Description(fmt.Sprintf("Optional: http_req_duration threshold of each operation "+
+			"(default: %q).", defaultGraphQLLatency)),
+	),
+)
+
+const (
+	// maxGraphQLOperations bounds the operations of a script.
+	maxGraphQLOperations = 20
+	defaultGraphQLDepth  = 2
+	maxGraphQLDepth      = 5
+	// defaultGraphQLLatency is the default latency threshold of operations.
+	defaultGraphQLLatency = "p(95)<500"
+)
+
+// RegisterGenerateGraphQLScriptTool registers the generate_graphql_script tool
+// with the MCP server.
+func RegisterGenerateGraphQLScriptTool(s *server.MCPServer, ws *workspace.Workspace) {
+	s.AddTool(GenerateGraphQLScriptTool,
+		withToolLogger("generate_graphql_script", newGenerateGraphQLScriptHandlerFunc(ws)))
+}
+
+// generateGraphQLScriptResponse is the JSON structure returned by the tool.
+type generateGraphQLScriptResponse struct {
+	Script     string              `json:"script"`
+	Operations []graphql.Operation `json:"operations"`
+	Warnings   []string            `json:"warnings,omitempty"`
+	NextSteps  []string            `json:"next_steps"`
+}
+
+// newGenerateGraphQLScriptHandlerFunc returns an MCP tool handler bound to a
+// workspace.
+func newGenerateGraphQLScriptHandlerFunc(ws *workspace.Workspace) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		logger := logging.LoggerFromContext(ctx)
+
+		endpoint := request.GetString("url", "")
+		if u, err := url.ParseRequestURI(endpoint); err != nil || u.Host == "" {
+			return mcp.NewToolResultError(fmt.Sprintf("invalid url %q: expected an absolute URL", endpoint)), nil
+		}
+		depth := request.GetInt("depth", defaultGraphQLDepth)
+		if depth < 1 || depth > maxGraphQLDepth {
+			return mcp.NewToolResultError(fmt.Sprintf("depth must be between 1 and %d", maxGraphQLDepth)), nil
+		}
+		latency := strings.TrimSpace(request.GetString("latency_threshold", defaultGraphQLLatency))
+		if latency == "" {
+			return mcp.NewToolResultError("latency_threshold is empty"), nil
+		}
+
+		data, err := readDocumentArgument(ctx, ws, request, "schema")
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		schema, err := graphql.Parse(data)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		operations, warnings, err := graphQLOperations(schema, request.GetStringSlice("operations", nil), depth)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		batch := request.GetBool("batch", false)
+		script := generateGraphQLScript(endpoint, operations, batch, latency)
+
+		logger.InfoContext(ctx, "GraphQL script generated",
+			slog.Int("operations", len(operations)),
+			slog.Bool("batch", batch),
+			slog.Int("script_size", len(script)))
+
+		return marshalResponse(ctx, logger, generateGraphQLScriptResponse{
+			Script:     script,
+			Operations: operations,
+			Warnings:   warnings,
+			NextSteps: []string{
+				"Replace the sample variables with values of your data, e.g. from a SharedArray of a data file",
+				"Use validate_script to check the operations against the server before adding load",
+				"Set the load profile in options, e.g. with build_scenario, then use run_script",
+			},
+		})
+	}
+}
+
+// graphQLOperations returns the operations refs select, or the first fields of
+// the query type, with unique names.
+func graphQLOperations(schema *graphql.Schema, refs []string, depth int) ([]graphql.Operation, []string, error) {
+	var warnings []string
+	if len(refs) == 0 {
+		for _, f := range schema.Root("query").Fields {
+			refs = append(refs, schema.Query+"."+f.Name)
+		}
+		if len(refs) > maxGraphQLOperations {
+			warnings = append(warnings, fmt.Sprintf("The query type has %d fields; only the first %d are tested. "+
+				"Select the others with 'operations'.", len(refs), maxGraphQLOperations))
+			refs = refs[:maxGraphQLOperations]
+		}
+	}
+	if len(refs) > maxGraphQLOperations {
+		return nil, nil, fmt.Errorf("too many operations: %d (max %d)", len(refs), maxGraphQLOperations)
+	}
+
+	operations := make([]graphql.Operation, 0, len(refs))
+	var names []string
+	for i, ref := range refs {
+		o, err := schema.Operation(ref, depth)
+		if err != nil {
+			return nil, nil, fmt.Errorf("operations[%d]: %w", i, err)
+		}
+		name := o.Name
+		if name == "" {
+			name = fmt.Sprintf("Operation%d", i+1)
+		}
+		if name = uniqueIdentifier(name, names); name != o.Name {
+			o.Rename(name)
+		}
+		names = append(names, name)
+		if o.Type == "mutation" {
+			warnings = append(warnings, fmt.Sprintf("%s is a mutation: every iteration changes data, "+
+				"so run the test against a test environment", name))
+		}
+		operations = append(operations, *o)
+	}
+	return operations, warnings, nil
+}
+
+// generateGraphQLScript writes the load test of operations. Batched operations
+// share a request tagged "batch", so their latency threshold applies to it,
+// while their checks keep their own tags.
+func generateGraphQLScript(endpoint string, operations []graphql.Operation, batch bool, latency string) string {
+	w := &codeWriter{}
+	w.line("// Generated by mcp-k6 from a GraphQL schema. Review before running with load.")
+	w.line("import http from 'k6/http';")
+	w.line("import { check } from 'k6';")
+	w.line("")
+	w.open("export const options = {")
+	w.line("vus: 1,")
+	w.line("iterations: 1,")
+	w.open("thresholds: {")
+	if batch {
+		w.line(fmt.Sprintf("'http_req_duration{operation:batch}': [%s],", jsString(latency)))
+		w.line("'checks{operation:batch}': ['rate>0.99'],")
+	}
+	for _, o := range operations {
+		if !batch {
+			w.line(fmt.Sprintf("'http_req_duration{operation:%s}': [%s],", o.Name, jsString(latency)))
+		}
+		w.line(fmt.Sprintf("'checks{operation:%s}': ['rate>0.99'],", o.Name))
+	}
+	w.close("},")
+	w.close("};")
+	w.line("")
+	w.line(fmt.Sprintf("const GRAPHQL_URL = __ENV.GRAPHQL_URL || %s;", jsString(endpoint)))
+	w.line("")
+	w.line("// operations are the operations to test. Replace the sample variables with")
+	w.line("// values of your data.")
+	w.open("const operations = [")
+	for _, o := range operations {
+		w.open("{")
+		w.line(fmt.Sprintf("operationName: %s,", jsString(o.Name)))
+		w.line(fmt.Sprintf("query: %s,", jsTemplate(o.Document)))
+		if len(o.Variables) > 0 {
+			w.line(fmt.Sprintf("variables: %s,", jsValue(o.Variables, strings.Repeat("  ", w.depth))))
+		}
+		w.close("},")
+	}
+	w.close("];")
+	w.line("")
+	w.line("const params = { headers: { 'Content-Type': 'application/json' } };")
+	w.line("")
+
+	w.open("export default function () {")
+	if batch {
+		w.line("const res = http.post(GRAPHQL_URL, JSON.stringify(operations), {")
+		w.line("  ...params,")
+		w.line("  tags: { operation: 'batch' },")
+		w.line("});")
+		w.line("check(res, { 'batch status is 200': (r) => r.status === 200 }, { operation: 'batch' });")
+		w.line("const results = parse(res);")
+		w.open("operations.forEach((operation, i) => {")
+		w.line("const result = Array.isArray(results) ? results[i] : undefined;")
+		w.open("check(result, {")
+		w.line("[`${operation.operationName} has data`]: (r) => r !== undefined && r.data != null,")
+		w.line("[`${operation.operationName} has no errors`]: (r) => r !== undefined && !r.errors,")
+		w.close("}, { operation: operation.operationName });")
+		w.close("});")
+	} else {
+		w.open("for (const operation of operations) {")
+		w.line("const res = http.post(GRAPHQL_URL, JSON.stringify(operation), {")
+		w.line("  ...params,")
+		w.line("  tags: { operation: operation.operationName },")
+		w.line("});")
+		w.line("const result = parse(res);")
+		w.open("check(res, {")
+		w.line("[`${operation.operationName} status is 200`]: (r) => r.status === 200,")
+		w.line("[`${operation.operationName} has no errors`]: () => result !== null && !result.errors,")
+		w.close("}, { operation: operation.operationName });")
+		w.close("}")
+	}
+	w.close("}")
+	w.line("")
+	w.line("// parse returns the JSON body of res, or null when it is not JSON.")
+	w.open("function parse(res) {")
+	w.open("try {")
+	w.line("return res.json();")
+	w.close("} catch (e) {")
+	w.depth++
+	w.line("return null;")
+	w.close("}")
+	w.close("}")
+	return w.String()
+}
+
+// jsTemplate renders s as a JavaScript template literal, keeping its line
+// breaks readable.
+func jsTemplate(s string) string {
+	s = strings.ReplaceAll(s, "\\", "\\\\")
+	s = strings.ReplaceAll(s, "`", "\\`")
+	s = strings.ReplaceAll(s, "${", "\\${")
+	return "`" + s + "`"
+}
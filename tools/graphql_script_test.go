@@ -0,0 +1,101 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const pizzaSchema = `
+type Query {
+  pizza(id: ID!): Pizza
+  pizzas(limit: Int): [Pizza!]!
+}
+
+type Mutation {
+  rate(pizzaId: ID!, stars: Int!): Rating
+}
+
+type Pizza {
+  id: ID!
+  name: String!
+  ingredients: [Ingredient!]!
+}
+
+type Ingredient { name: String! }
+
+type Rating { id: ID! stars: Int! }
+`
+
+func TestGenerateGraphQLScript(t *testing.T) {
+	t.Parallel()
+
+	handler := newGenerateGraphQLScriptHandlerFunc(nil)
+	result, err := handler(t.Context(), newCallRequest(map[string]any{
+		"schema":     pizzaSchema,
+		"url":        "https://quickpizza.grafana.com/graphql",
+		"operations": []any{"pizza", "Mutation.rate", "{ pizzas { name } }", "query Pizza { pizzas { id } }"},
+	}))
+	require.NoError(t, err)
+	require.False(t, result.IsError, "unexpected tool error: %+v", result.Content)
+	var resp generateGraphQLScriptResponse
+	decodeJSON(t, result, &resp)
+
+	require.Len(t, resp.Operations, 4)
+	assert.Equal(t, "query Operation3 { pizzas { name } }", resp.Operations[2].Document)
+	assert.Equal(t, "query Pizza2 { pizzas { id } }", resp.Operations[3].Document)
+	assert.Equal(t, map[string]any{"pizzaId": "1", "stars": float64(1)}, resp.Operations[1].Variables)
+	require.Len(t, resp.Warnings, 1)
+	assert.Contains(t, resp.Warnings[0], "Rate is a mutation")
+
+	assert.Contains(t, resp.Script, "'http_req_duration{operation:Pizza}': [\"p(95)<500\"],")
+	assert.Contains(t, resp.Script, "'checks{operation:Pizza2}': ['rate>0.99'],")
+	assert.Contains(t, resp.Script,
+		`const GRAPHQL_URL = __ENV.GRAPHQL_URL || "https://quickpizza.grafana.com/graphql";`)
+	assert.Contains(t, resp.Script, "    query: `query Pizza($id: ID!) {\n  pizza(id: $id) {\n    id\n    name\n"+
+		"    ingredients {\n      name\n    }\n  }\n}`,")
+	assert.Contains(t, resp.Script, "tags: { operation: operation.operationName },")
+	assert.Contains(t, resp.Script, "}, { operation: operation.operationName });")
+}
+
+func TestGenerateGraphQLScriptBatch(t *testing.T) {
+	t.Parallel()
+
+	handler := newGenerateGraphQLScriptHandlerFunc(nil)
+	result, err := handler(t.Context(), newCallRequest(map[string]any{
+		"schema":            pizzaSchema,
+		"url":               "https://quickpizza.grafana.com/graphql",
+		"batch":             true,
+		"latency_threshold": "p(99)<1000",
+	}))
+	require.NoError(t, err)
+	require.False(t, result.IsError, "unexpected tool error: %+v", result.Content)
+	var resp generateGraphQLScriptResponse
+	decodeJSON(t, result, &resp)
+
+	require.Len(t, resp.Operations, 2)
+	assert.Equal(t, "Pizzas", resp.Operations[1].Name)
+	assert.Contains(t, resp.Script, "'http_req_duration{operation:batch}': [\"p(99)<1000\"],")
+	assert.NotContains(t, resp.Script, "'http_req_duration{operation:Pizza}'")
+	assert.Contains(t, resp.Script, "'checks{operation:Pizzas}': ['rate>0.99'],")
+	assert.Contains(t, resp.Script, "const res = http.post(GRAPHQL_URL, JSON.stringify(operations), {")
+}
+
+func TestGenerateGraphQLScriptErrors(t *testing.T) {
+	t.Parallel()
+
+	handler := newGenerateGraphQLScriptHandlerFunc(nil)
+	for name, args := range map[string]map[string]any{
+		"no url":       {"schema": pizzaSchema},
+		"no schema":    {"url": "https://a.test/graphql"},
+		"bad schema":   {"schema": "type Pizza { id: ID! }", "url": "https://a.test/graphql"},
+		"bad depth":    {"schema": pizzaSchema, "url": "https://a.test/graphql", "depth": 9},
+		"bad field":    {"schema": pizzaSchema, "url": "https://a.test/graphql", "operations": []any{"menu"}},
+		"subscription": {"schema": pizzaSchema, "url": "https://a.test/graphql", "operations": []any{"subscription S { a }"}},
+	} {
+		result, err := handler(t.Context(), newCallRequest(args))
+		require.NoError(t, err, name)
+		assert.True(t, result.IsError, name)
+	}
+}
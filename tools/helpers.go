@@ -19,8 +19,15 @@ const (
 
 // createSecureTempFile creates a secure temporary file with the script content.
 func createSecureTempFile(script string) (string, func(), error) {
+	return createSecureTempFileIn("", "k6-run-*.js", script)
+}
+
+// createSecureTempFileIn creates a temporary file holding script in dir, the
+// default temporary directory when empty, named after pattern as
+// os.CreateTemp does.
+func createSecureTempFileIn(dir, pattern, script string) (string, func(), error) {
 	//nolint:forbidigo // Temporary file creation required for k6 execution
-	tmpFile, err := os.CreateTemp("", "k6-run-*.js")
+	tmpFile, err := os.CreateTemp(dir, pattern)
 	if err != nil {
 		return "", nil, fmt.Errorf("failed to create temporary file: %w", err)
 	}
@@ -44,6 +51,28 @@ func createSecureTempFile(script string) (string, func(), error) {
 	return filename, cleanup, nil
 }
 
+// createOutputFile creates an empty temporary file for k6 to write an
+// output of a run to, such as its samples or summary.
+func createOutputFile(pattern string) (string, func(), error) {
+	//nolint:forbidigo // Temporary file creation required for k6 execution
+	f, err := os.CreateTemp("", pattern)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create output file: %w", err)
+	}
+	path := f.Name()
+	_ = f.Close()
+	cleanup := func() {
+		//nolint:forbidigo // Cleanup of temporary file required
+		if err := os.Remove(path); err != nil {
+			logging.WithComponent("runner").Warn("Failed to remove output file",
+				slog.String("operation", "cleanup"),
+				slog.String("error", err.Error()),
+			)
+		}
+	}
+	return path, cleanup, nil
+}
+
 // setupTempFile configures and writes to the temporary file.
 //
 //nolint:forbidigo // Function parameter os.File required for temp file operations
@@ -0,0 +1,178 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/grafana/mcp-k6/internal/importpolicy"
+	"github.com/grafana/mcp-k6/internal/scriptinfo"
+	"github.com/grafana/mcp-k6/internal/workspace"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// remoteImportsDescription documents the remote_imports parameter of the execution tools.
+const remoteImportsDescription = "Optional: 'deny' rejects scripts that import remote modules " +
+	"(http:// or https:// URLs, including jslib.k6.io). It can only tighten the server's import policy."
+
+// importHostsDescription documents the import_hosts parameter of the execution tools.
+const importHostsDescription = "Optional: hosts remote modules may be imported from, such as " +
+	"'jslib.k6.io' or '*.corp.example'. It narrows the server's allowed hosts and cannot add to them."
+
+// maxPolicyModules bounds the local modules inspected for remote imports
+// and target hosts.
+const maxPolicyModules = 100
+
+// ImportViolation is a remote import the import policy rejects.
+type ImportViolation struct {
+	Module string `json:"module"`
+	// File is the local module the import appears in, empty for the script.
+	File   string `json:"file,omitempty"`
+	Line   int    `json:"line"`
+	Reason string `json:"reason"`
+}
+
+// importPolicyArgument returns the import policy of a request: the server
+// policy, narrowed by the remote_imports and import_hosts parameters.
+func importPolicyArgument(ip *importpolicy.Policy, request mcp.CallToolRequest) (*importpolicy.Policy, error) {
+	mode := request.GetString("remote_imports", "")
+	hosts := request.GetStringSlice("import_hosts", nil)
+	if mode == "" && len(hosts) == 0 {
+		return ip, nil
+	}
+	return ip.Restrict(mode, hosts)
+}
+
+// checkImportPolicy returns an error listing the remote imports of the
+// script, or of the local modules it imports, that policy rejects.
+func checkImportPolicy(
+	ctx context.Context,
+	ws *workspace.Workspace,
+	policy *importpolicy.Policy,
+	script, scriptPath string,
+	files []DataFile,
+) error {
+	violations := importViolations(ctx, ws, policy, script, scriptPath, files)
+	if len(violations) == 0 {
+		return nil
+	}
+	msgs := make([]string, 0, len(violations))
+	for _, v := range violations {
+		where := fmt.Sprintf("line %d", v.Line)
+		if v.File != "" {
+			where = fmt.Sprintf("%s:%d", v.File, v.Line)
+		}
+		msgs = append(msgs, fmt.Sprintf("%s (%s): %s", v.Module, where, v.Reason))
+	}
+	return fmt.Errorf("import policy: %s; %s", policy, strings.Join(msgs, "; "))
+}
+
+// importViolations walks the imports of script and of the local modules it
+// imports, read from the workspace next to scriptPath or from the staged
+// data files of an inline script. Modules that cannot be read are left to
+// k6 to report.
+func importViolations(
+	ctx context.Context,
+	ws *workspace.Workspace,
+	policy *importpolicy.Policy,
+	script, scriptPath string,
+	files []DataFile,
+) []ImportViolation {
+	if !policy.Restricted() {
+		return nil
+	}
+	var violations []ImportViolation
+	walkModules(ctx, ws, script, scriptPath, files, func(file string, info *scriptinfo.Info) {
+		for _, imp := range info.Imports {
+			if err := policy.Check(imp.Module); err != nil {
+				violations = append(violations, ImportViolation{
+					Module: imp.Module, File: file, Line: imp.Line, Reason: err.Error(),
+				})
+			}
+		}
+	})
+	return violations
+}
+
+// walkModules calls visit with the analysis of script and of each local
+// module it imports, directly or not, read from the workspace next to
+// scriptPath or from the staged data files of an inline script. file is the
+// module's path relative to the script, empty for the script itself.
+// Modules that cannot be read are skipped.
+func walkModules(
+	ctx context.Context,
+	ws *workspace.Workspace,
+	script, scriptPath string,
+	files []DataFile,
+	visit func(file string, info *scriptinfo.Info),
+) {
+	staged := make(map[string]string, len(files))
+	for _, f := range files {
+		staged[filepath.Clean(filepath.FromSlash(f.Name))] = f.Content
+	}
+	base := ""
+	if scriptPath != "" {
+		base = filepath.Dir(scriptPath)
+	}
+	read := func(path string) (string, bool) {
+		if scriptPath == "" {
+			content, ok := staged[path]
+			return content, ok
+		}
+		if ws == nil {
+			return "", false
+		}
+		data, _, err := ws.ReadFile(ctx, path, MaxScriptSize)
+		return string(data), err == nil
+	}
+
+	type module struct{ path, source string }
+	first := scriptPath
+	if first == "" {
+		first = runScriptName
+	}
+	queue := []module{{path: first, source: script}}
+	seen := map[string]bool{first: true}
+	for i := 0; i < len(queue) && i < maxPolicyModules; i++ {
+		m := queue[i]
+		file := ""
+		if i > 0 {
+			file = m.path
+			if rel, err := filepath.Rel(base, m.path); err == nil && base != "" {
+				file = rel
+			}
+		}
+		info := scriptinfo.Analyze(m.source)
+		visit(filepath.ToSlash(file), info)
+		for _, imp := range info.Imports {
+			path, ok := localModulePath(filepath.Dir(m.path), imp.Module)
+			if !ok || seen[path] {
+				continue
+			}
+			seen[path] = true
+			if source, ok := read(path); ok {
+				queue = append(queue, module{path: path, source: source})
+			}
+		}
+	}
+}
+
+// localModulePath resolves a local module specifier imported from dir.
+func localModulePath(dir, specifier string) (string, bool) {
+	switch {
+	case strings.HasPrefix(specifier, "file://"):
+		u, err := url.Parse(specifier)
+		if err != nil {
+			return "", false
+		}
+		return filepath.Clean(filepath.FromSlash(u.Path)), true
+	case strings.HasPrefix(specifier, "/"):
+		return filepath.Clean(specifier), true
+	case strings.HasPrefix(specifier, "./"), strings.HasPrefix(specifier, "../"):
+		return filepath.Join(dir, filepath.FromSlash(specifier)), true
+	default:
+		return "", false
+	}
+}
@@ -0,0 +1,92 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/grafana/mcp-k6/internal/importpolicy"
+	"github.com/grafana/mcp-k6/internal/workspace"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImportViolations(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "lib"), 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "lib", "helpers.js"),
+		[]byte("import { uuidv4 } from 'https://cdn.example.com/uuid.js';\nexport const id = uuidv4;\n"), 0o600))
+	scriptPath := filepath.Join(root, "test.js")
+	script := `import http from 'k6/http';
+import { randomItem } from 'https://jslib.k6.io/k6-utils/1.4.0/index.js';
+import { id } from './lib/helpers.js';
+export default function () {}
+`
+	ws := workspace.New(nil, root)
+
+	policy, err := importpolicy.New(importpolicy.Allow, []string{"jslib.k6.io"})
+	require.NoError(t, err)
+	violations := importViolations(context.Background(), ws, policy, script, scriptPath, nil)
+	require.Len(t, violations, 1)
+	assert.Equal(t, "https://cdn.example.com/uuid.js", violations[0].Module)
+	assert.Equal(t, "lib/helpers.js", violations[0].File)
+	assert.Equal(t, 1, violations[0].Line)
+
+	deny, err := importpolicy.New(importpolicy.Deny, nil)
+	require.NoError(t, err)
+	violations = importViolations(context.Background(), ws, deny, script, scriptPath, nil)
+	require.Len(t, violations, 2)
+	assert.Empty(t, violations[0].File)
+	assert.Equal(t, 2, violations[0].Line)
+
+	// Inline scripts import staged data files
+	violations = importViolations(context.Background(), nil, deny, script, "", []DataFile{
+		{Name: "lib/helpers.js", Content: "import 'https://cdn.example.com/uuid.js';\n"},
+	})
+	require.Len(t, violations, 2)
+	assert.Equal(t, "lib/helpers.js", violations[1].File)
+
+	assert.Empty(t, importViolations(context.Background(), ws, nil, script, scriptPath, nil))
+}
+
+func TestRunRequestEnforcesImportPolicy(t *testing.T) {
+	t.Parallel()
+
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]any{
+		"script":         "import { randomItem } from 'https://jslib.k6.io/k6-utils/1.4.0/index.js';\n",
+		"remote_imports": "deny",
+	}
+	_, _, err := runRequest(context.Background(), nil, nil, nil, nil, nil, req)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "remote imports are disabled")
+
+	server, err := importpolicy.New(importpolicy.Allow, []string{"jslib.k6.io"})
+	require.NoError(t, err)
+	req.Params.Arguments = map[string]any{
+		"script":       "export default function () {}\n",
+		"import_hosts": []any{"cdn.example.com"},
+	}
+	_, _, err = runRequest(context.Background(), nil, nil, server, nil, nil, req)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not allowed by the server policy")
+}
+
+func TestImportPolicyResponse(t *testing.T) {
+	t.Parallel()
+
+	deny, err := importpolicy.New(importpolicy.Deny, nil)
+	require.NoError(t, err)
+	resp := importPolicyResponse(deny, []ImportViolation{
+		{Module: "https://jslib.k6.io/k6-utils/1.4.0/index.js", Line: 2, Reason: "remote imports are disabled"},
+	})
+	assert.False(t, resp.Valid)
+	assert.False(t, resp.Summary.ReadyToRun)
+	require.Len(t, resp.Issues, 1)
+	assert.Equal(t, "import", resp.Issues[0].Type)
+	assert.Equal(t, 2, resp.Issues[0].LineNumber)
+}
@@ -18,7 +18,9 @@ import (
 //nolint:gochecknoglobals // Shared tool definition registered at startup.
 var InfoTool = mcp.NewTool(
 	"info",
+	readOnlyTool(),
 	mcp.WithDescription("Get details about the mcp-k6 server, the local k6 binary, and k6 Cloud login status."),
+	outputSchema(InfoResponse{}),
 )
 
 // RegisterInfoTool registers the info tool with the MCP server.
@@ -83,7 +85,7 @@ func info(ctx context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, erro
 		slog.String("k6_version", k6Version),
 		slog.Bool("logged_in", isLoggedIn))
 
-	return mcp.NewToolResultText(string(jsonResponse)), nil
+	return mcp.NewToolResultStructured(response, string(jsonResponse)), nil
 }
 
 // InfoResponse is the response to the info tool.
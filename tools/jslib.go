@@ -0,0 +1,49 @@
+package tools
+
+import (
+	"path"
+	"strings"
+
+	"github.com/grafana/mcp-k6/internal/jslib"
+)
+
+// mirrorSources points the jslib imports of an inline script, and of the
+// modules staged with it, to the offline jslib mirror. It returns the jslib
+// modules the mirror does not hold, which k6 still downloads.
+func mirrorSources(mirror *jslib.Mirror, script string, files []DataFile) (string, []DataFile, []string) {
+	if mirror == nil {
+		return script, files, nil
+	}
+	script, missing := mirror.Rewrite(script)
+	if len(files) == 0 {
+		return script, files, missing
+	}
+	mirrored := make([]DataFile, len(files))
+	for i, f := range files {
+		mirrored[i] = f
+		switch path.Ext(f.Name) {
+		case ".js", ".mjs", ".cjs":
+			var lacking []string
+			mirrored[i].Content, lacking = mirror.Rewrite(f.Content)
+			missing = append(missing, lacking...)
+		}
+	}
+	return script, mirrored, missing
+}
+
+// jslibNextSteps explains jslib imports the offline mirror could not serve.
+func jslibNextSteps(mirror *jslib.Mirror, script, scriptPath string, missing []string) []string {
+	if mirror == nil {
+		return nil
+	}
+	var steps []string
+	if scriptPath != "" && strings.Contains(script, jslib.Origin+"/") {
+		steps = append(steps, "The offline jslib mirror only serves inline scripts; k6 downloads the jslib "+
+			"imports of script_path from "+jslib.Origin+". Pass the script inline to use the mirror")
+	}
+	if len(missing) > 0 {
+		steps = append(steps, "These jslib modules are not in the offline mirror and are downloaded from "+
+			jslib.Origin+": "+strings.Join(missing, ", ")+". Vendor them with mcp-k6 -vendor-jslib")
+	}
+	return steps
+}
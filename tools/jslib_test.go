@@ -0,0 +1,46 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/grafana/mcp-k6/internal/jslib"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMirrorSources(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "k6-utils", "1.4.0"), 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "k6-utils", "1.4.0", "index.js"), []byte("export {};\n"), 0o600))
+	mirror, err := jslib.Serve(dir)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = mirror.Close() })
+
+	utils := "import { randomItem } from 'https://jslib.k6.io/k6-utils/1.4.0/index.js';\n"
+	papa := "import papa from 'https://jslib.k6.io/papaparse/5.1.1/index.js';\n"
+	script, files, missing := mirrorSources(mirror, utils, []DataFile{
+		{Name: "lib/parse.js", Content: papa + utils},
+		{Name: "users.csv", Content: "https://jslib.k6.io/k6-utils/1.4.0/index.js\n"},
+	})
+	assert.Contains(t, script, mirror.URL()+"/k6-utils/1.4.0/index.js")
+	assert.Contains(t, files[0].Content, mirror.URL()+"/k6-utils/1.4.0/index.js")
+	assert.Contains(t, files[0].Content, "https://jslib.k6.io/papaparse/5.1.1/index.js")
+	assert.Equal(t, "https://jslib.k6.io/k6-utils/1.4.0/index.js\n", files[1].Content)
+	assert.Equal(t, []string{"https://jslib.k6.io/papaparse/5.1.1/index.js"}, missing)
+
+	steps := jslibNextSteps(mirror, utils, "", missing)
+	require.Len(t, steps, 1)
+	assert.Contains(t, steps[0], "papaparse/5.1.1")
+	steps = jslibNextSteps(mirror, utils, "/ws/test.js", nil)
+	require.Len(t, steps, 1)
+	assert.Contains(t, steps[0], "only serves inline scripts")
+	assert.Empty(t, jslibNextSteps(nil, utils, "/ws/test.js", missing))
+
+	script, _, missing = mirrorSources(nil, utils, nil)
+	assert.Equal(t, utils, script)
+	assert.Empty(t, missing)
+}
@@ -0,0 +1,420 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/grafana/mcp-k6/internal/logging"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// GenerateKafkaScriptTool exposes a tool for writing Kafka tests with the
+// xk6-kafka extension.
+//
+//nolint:gochecknoglobals // Shared tool definition registered at startup.
+var GenerateKafkaScriptTool = mcp.NewTool(
+	"generate_kafka_script",
+	readOnlyTool(),
+	mcp.WithDescription(
+		"Generate a k6 script producing and/or consuming Kafka messages with the xk6-kafka extension "+
+			"(k6/x/kafka), serialized as strings, JSON or Avro with a schema registry. The script reads "+
+			"the brokers and topic from KAFKA_BROKERS and KAFKA_TOPIC first, and fails on producer or "+
+			"consumer errors. It needs a k6 binary built with xk6-kafka: the response tells whether the "+
+			"installed k6 has it and gives the xk6 build command otherwise.",
+	),
+	mcp.WithArray(
+		"brokers",
+		mcp.Required(),
+		mcp.WithStringItems(),
+		mcp.Description("Kafka bootstrap brokers, e.g. [\"localhost:9092\"]."),
+	),
+	mcp.WithString(
+		"topic",
+		mcp.Required(),
+		mcp.Description("The topic to produce to and consume from."),
+	),
+	mcp.WithString(
+		"mode",
+		mcp.Description("'both' to produce messages and consume them back (default), 'producer' or 'consumer'."),
+		mcp.Enum(kafkaBoth, kafkaProducer, kafkaConsumer),
+	),
+	mcp.WithString(
+		"serialization",
+		mcp.Description("Message value format: 'json' (default), 'string' or 'avro'. "+
+			"Keys are always strings."),
+		mcp.Enum(kafkaJSON, kafkaString, kafkaAvro),
+	),
+	mcp.WithString(
+		"schema_registry_url",
+		mcp.Description("The schema registry of Avro values, e.g. http://localhost:8081. Required for avro; "+
+			"the script reads it from SCHEMA_REGISTRY_URL first."),
+	),
+	mcp.WithString(
+		"avro_schema",
+		mcp.Description("Optional: the Avro record schema of values, as JSON (default: a record of the id, VU "+
+			"and iteration of each message)."),
+	),
+	mcp.WithString(
+		"group_id",
+		mcp.Description("Optional: consume as a member of this consumer group instead of reading partition 0."),
+	),
+	mcp.WithNumber(
+		"messages",
+		mcp.Description(fmt.Sprintf("Optional: messages produced and consumed by each iteration "+
+			"(default: %d, max: %d).", defaultKafkaMessages, maxKafkaMessages)),
+	),
+)
+
+// Modes and serializations of generate_kafka_script.
+const (
+	kafkaBoth     = "both"
+	kafkaProducer = "producer"
+	kafkaConsumer = "consumer"
+
+	kafkaJSON   = "json"
+	kafkaString = "string"
+	kafkaAvro   = "avro"
+)
+
+const (
+	defaultKafkaMessages = 10
+	maxKafkaMessages     = 1000
+	// kafkaModule is the module of the extension.
+	kafkaModule = "k6/x/kafka"
+	// defaultAvroSchema is the schema of the messages of the default value
+	// function.
+	defaultAvroSchema = `{"type": "record", "name": "Message", "fields": [` +
+		`{"name": "id", "type": "int"}, {"name": "vu", "type": "int"}, {"name": "iteration", "type": "int"}]}`
+)
+
+// RegisterGenerateKafkaScriptTool registers the generate_kafka_script tool
+// with the MCP server.
+func RegisterGenerateKafkaScriptTool(s *server.MCPServer) {
+	s.AddTool(GenerateKafkaScriptTool, withToolLogger("generate_kafka_script", generateKafkaScript))
+}
+
+// kafkaScriptOptions are the parameters of a generated Kafka script.
+type kafkaScriptOptions struct {
+	Brokers        []string
+	Topic          string
+	Mode           string
+	Serialization  string
+	SchemaRegistry string
+	// AvroSchema is the decoded Avro schema.
+	AvroSchema any
+	// AvroSample is the sample value of AvroSchema, nil for the default
+	// schema whose values are built from the message index.
+	AvroSample map[string]any
+	GroupID    string
+	Messages   int
+}
+
+// generateKafkaScriptResponse is the JSON structure returned by the tool.
+type generateKafkaScriptResponse struct {
+	Script string `json:"script"`
+	// Extension is the Go module of xk6-kafka.
+	Extension    string `json:"extension"`
+	BuildCommand string `json:"build_command"`
+	// K6HasExtension tells whether the k6 on the PATH was built with
+	// xk6-kafka, and is omitted when k6 was not found.
+	K6HasExtension *bool    `json:"k6_has_extension,omitempty"`
+	Warnings       []string `json:"warnings,omitempty"`
+	NextSteps      []string `json:"next_steps"`
+}
+
+func generateKafkaScript(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger := logging.LoggerFromContext(ctx)
+
+	opts, warnings, err := kafkaOptions(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	script := kafkaScript(opts)
+
+	extension := extensionModule(kafkaModule)
+	resp := generateKafkaScriptResponse{
+		Script:       script,
+		Extension:    extension,
+		BuildCommand: xk6BuildCommand([]string{extension}),
+		Warnings:     warnings,
+	}
+	if extensions, ok := installedExtensions(ctx); ok {
+		has := providesModule(extensions, kafkaModule)
+		resp.K6HasExtension = &has
+	}
+	if resp.K6HasExtension == nil || !*resp.K6HasExtension {
+		resp.NextSteps = append(resp.NextSteps, fmt.Sprintf("Build k6 with xk6-kafka: %s, and put the ./k6 "+
+			"binary it writes on the PATH in place of k6", resp.BuildCommand))
+	}
+	resp.NextSteps = append(resp.NextSteps,
+		"Use validate_script to run the script once against the brokers, "+
+			"setting KAFKA_BROKERS and KAFKA_TOPIC in env_file for other environments",
+		"Raise vus and duration in options, or use run_script parameters, to add load",
+	)
+
+	logger.InfoContext(ctx, "Kafka script generated",
+		slog.String("mode", opts.Mode),
+		slog.String("serialization", opts.Serialization),
+		slog.Int("script_size", len(script)))
+
+	return marshalResponse(ctx, logger, resp)
+}
+
+// kafkaOptions reads and checks the parameters of request.
+func kafkaOptions(request mcp.CallToolRequest) (kafkaScriptOptions, []string, error) {
+	opts := kafkaScriptOptions{
+		Topic:          strings.TrimSpace(request.GetString("topic", "")),
+		Mode:           request.GetString("mode", kafkaBoth),
+		Serialization:  request.GetString("serialization", kafkaJSON),
+		SchemaRegistry: strings.TrimSpace(request.GetString("schema_registry_url", "")),
+		GroupID:        strings.TrimSpace(request.GetString("group_id", "")),
+		Messages:       request.GetInt("messages", defaultKafkaMessages),
+	}
+	for _, b := range request.GetStringSlice("brokers", nil) {
+		if b = strings.TrimSpace(b); b != "" {
+			opts.Brokers = append(opts.Brokers, b)
+		}
+	}
+
+	switch {
+	case len(opts.Brokers) == 0:
+		return opts, nil, errors.New("brokers is required, e.g. [\"localhost:9092\"]")
+	case opts.Topic == "":
+		return opts, nil, errors.New("topic is required")
+	case opts.Mode != kafkaBoth && opts.Mode != kafkaProducer && opts.Mode != kafkaConsumer:
+		return opts, nil, fmt.Errorf("invalid mode %q: use 'both', 'producer' or 'consumer'", opts.Mode)
+	case opts.Serialization != kafkaJSON && opts.Serialization != kafkaString && opts.Serialization != kafkaAvro:
+		return opts, nil, fmt.Errorf("invalid serialization %q: use 'json', 'string' or 'avro'", opts.Serialization)
+	case opts.Messages < 1 || opts.Messages > maxKafkaMessages:
+		return opts, nil, fmt.Errorf("messages must be between 1 and %d", maxKafkaMessages)
+	case opts.Serialization == kafkaAvro && opts.SchemaRegistry == "":
+		return opts, nil, errors.New("schema_registry_url is required for avro serialization")
+	}
+
+	var warnings []string
+	if opts.Serialization == kafkaAvro {
+		schema := request.GetString("avro_schema", "")
+		if strings.TrimSpace(schema) == "" {
+			schema = defaultAvroSchema
+		}
+		var record any
+		if err := json.Unmarshal([]byte(schema), &record); err != nil {
+			return opts, nil, fmt.Errorf("invalid avro_schema: %w", err)
+		}
+		opts.AvroSchema = record
+		if schema != defaultAvroSchema {
+			opts.AvroSample, warnings = avroSample(record)
+		}
+	}
+	if opts.GroupID != "" && opts.Mode == kafkaProducer {
+		warnings = append(warnings, "group_id is ignored: producer scripts do not consume")
+	}
+	return opts, warnings, nil
+}
+
+// avroSample returns a placeholder value of an Avro record schema, with
+// warnings for the fields whose value must be written by hand.
+func avroSample(schema any) (map[string]any, []string) {
+	record, _ := schema.(map[string]any)
+	fields, _ := record["fields"].([]any)
+	sample := make(map[string]any, len(fields))
+	var warnings []string
+	for _, f := range fields {
+		field, _ := f.(map[string]any)
+		name, _ := field["name"].(string)
+		if name == "" {
+			continue
+		}
+		switch t := field["type"].(type) {
+		case string:
+			switch t {
+			case "int", "long":
+				sample[name] = 1
+			case "float", "double":
+				sample[name] = 1.5
+			case "boolean":
+				sample[name] = true
+			case "string":
+				sample[name] = "example"
+			default:
+				sample[name] = nil
+				warnings = append(warnings, fmt.Sprintf("Write the value of the %s field (%s) in value()", name, t))
+			}
+		case []any:
+			// Unions with null can be left empty; others need their branch.
+			sample[name] = nil
+			if !strings.Contains(fmt.Sprint(t), "null") {
+				warnings = append(warnings, fmt.Sprintf("Write the value of the %s union field in value()", name))
+			}
+		default:
+			sample[name] = nil
+			warnings = append(warnings, fmt.Sprintf("Write the value of the %s field in value()", name))
+		}
+	}
+	return sample, warnings
+}
+
+// kafkaScript writes the script of opts.
+func kafkaScript(opts kafkaScriptOptions) string {
+	produce := opts.Mode != kafkaConsumer
+	consume := opts.Mode != kafkaProducer
+	schemaType := map[string]string{
+		kafkaJSON:   "SCHEMA_TYPE_JSON",
+		kafkaString: "SCHEMA_TYPE_STRING",
+		kafkaAvro:   "SCHEMA_TYPE_AVRO",
+	}[opts.Serialization]
+
+	w := &codeWriter{}
+	w.line("// Generated by mcp-k6 for Kafka. Run it with a k6 binary built with xk6-kafka:")
+	w.line("//   " + xk6BuildCommand([]string{extensionModule(kafkaModule)}))
+	if consume {
+		w.line("import { check } from 'k6';")
+	}
+	var imports []string
+	if produce {
+		imports = append(imports, "Writer", "Connection")
+	}
+	if consume {
+		imports = append(imports, "Reader")
+	}
+	// Produced keys are strings, and consumed strings are not deserialized.
+	registry := produce || opts.Serialization != kafkaString
+	if registry {
+		imports = append(imports, "SchemaRegistry")
+	}
+	if produce {
+		imports = append(imports, "SCHEMA_TYPE_STRING")
+	}
+	if opts.Serialization != kafkaString {
+		imports = append(imports, schemaType)
+	}
+	w.open("import {")
+	for _, name := range imports {
+		w.line(name + ",")
+	}
+	w.close("} from 'k6/x/kafka';")
+	w.line("")
+
+	w.open("export const options = {")
+	w.line("vus: 1,")
+	w.line("iterations: 1,")
+	w.open("thresholds: {")
+	if produce {
+		w.line("kafka_writer_error_count: ['count == 0'],")
+	}
+	if consume {
+		w.line("kafka_reader_error_count: ['count == 0'],")
+	}
+	w.close("},")
+	w.close("};")
+	w.line("")
+
+	w.line(fmt.Sprintf("const brokers = (__ENV.KAFKA_BROKERS || %s).split(',');",
+		jsString(strings.Join(opts.Brokers, ","))))
+	w.line(fmt.Sprintf("const topic = __ENV.KAFKA_TOPIC || %s;", jsString(opts.Topic)))
+	w.line("// messages is the number of messages each iteration " + map[string]string{
+		kafkaBoth:     "produces and consumes.",
+		kafkaProducer: "produces.",
+		kafkaConsumer: "consumes.",
+	}[opts.Mode])
+	w.line(fmt.Sprintf("const messages = %d;", opts.Messages))
+	w.line("")
+
+	if produce {
+		w.line("const writer = new Writer({ brokers, topic });")
+		w.line("const connection = new Connection({ address: brokers[0] });")
+	}
+	if consume {
+		if opts.GroupID != "" {
+			w.line(fmt.Sprintf("const reader = new Reader({ brokers, groupID: %s, groupTopics: [topic] });",
+				jsString(opts.GroupID)))
+		} else {
+			w.line("const reader = new Reader({ brokers, topic });")
+		}
+	}
+	schema := "schemaType: " + schemaType
+	if opts.Serialization == kafkaAvro {
+		w.line(fmt.Sprintf("const schemaRegistry = new SchemaRegistry({ url: __ENV.SCHEMA_REGISTRY_URL || %s });",
+			jsString(opts.SchemaRegistry)))
+		w.open("const valueSchema = schemaRegistry.createSchema({")
+		w.line("subject: `${topic}-value`,")
+		w.line(fmt.Sprintf("schema: JSON.stringify(%s),", jsValue(opts.AvroSchema, strings.Repeat("  ", w.depth))))
+		w.line("schemaType: SCHEMA_TYPE_AVRO,")
+		w.close("});")
+		schema = "schema: valueSchema, " + schema
+	} else if registry {
+		w.line("const schemaRegistry = new SchemaRegistry();")
+	}
+	if produce {
+		w.line("")
+		w.line("// The first VU to initialize creates the topic when it does not exist.")
+		w.open("if (__VU === 0 && !connection.listTopics().includes(topic)) {")
+		w.line("connection.createTopic({ topic, numPartitions: 1, replicationFactor: 1 });")
+		w.close("}")
+	}
+	w.line("")
+
+	w.open("export default function () {")
+	if produce {
+		w.line("const batch = [];")
+		w.open("for (let i = 0; i < messages; i++) {")
+		w.open("batch.push({")
+		w.line("key: schemaRegistry.serialize({ data: `${__VU}-${__ITER}-${i}`, schemaType: SCHEMA_TYPE_STRING }),")
+		w.line(fmt.Sprintf("value: schemaRegistry.serialize({ data: value(i), %s }),", schema))
+		w.close("});")
+		w.close("}")
+		w.line("writer.produce({ messages: batch });")
+	}
+	if consume {
+		if produce {
+			w.line("")
+		}
+		w.line("const received = reader.consume({ limit: messages });")
+		w.open("check(received, {")
+		w.line("'all messages are consumed': (r) => r.length === messages,")
+		if opts.Serialization != kafkaString {
+			w.line(fmt.Sprintf("'values are %s': (r) => r.every((m) => {", map[string]string{
+				kafkaJSON: "JSON objects",
+				kafkaAvro: "Avro records",
+			}[opts.Serialization]))
+			w.line(fmt.Sprintf("  const data = schemaRegistry.deserialize({ data: m.value, %s });", schema))
+			w.line("  return data !== null && typeof data === 'object';")
+			w.line("}),")
+		}
+		w.close("});")
+	}
+	w.close("}")
+
+	w.line("")
+	w.open("export function teardown() {")
+	if produce {
+		w.line("writer.close();")
+		w.line("connection.close();")
+	}
+	if consume {
+		w.line("reader.close();")
+	}
+	w.close("}")
+
+	if produce {
+		w.line("")
+		w.line("// value returns the value of the ith message of an iteration.")
+		w.open("function value(i) {")
+		switch {
+		case opts.Serialization == kafkaString:
+			w.line("return `message ${i} of VU ${__VU}, iteration ${__ITER}`;")
+		case opts.AvroSample != nil:
+			w.line("// Replace the sample fields with the values to send.")
+			w.line(fmt.Sprintf("return %s;", jsValue(opts.AvroSample, strings.Repeat("  ", w.depth))))
+		default:
+			w.line("return { id: i, vu: __VU, iteration: __ITER };")
+		}
+		w.close("}")
+	}
+	return w.String()
+}
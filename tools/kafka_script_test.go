@@ -0,0 +1,94 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateKafkaScript(t *testing.T) {
+	t.Parallel()
+
+	result, err := generateKafkaScript(t.Context(), newCallRequest(map[string]any{
+		"brokers": []any{"kafka-1:9092", "kafka-2:9092"},
+		"topic":   "orders",
+	}))
+	require.NoError(t, err)
+	require.False(t, result.IsError, "unexpected tool error: %+v", result.Content)
+	var resp generateKafkaScriptResponse
+	decodeJSON(t, result, &resp)
+
+	assert.Equal(t, "github.com/mostafa/xk6-kafka", resp.Extension)
+	assert.Equal(t, "xk6 build --with github.com/mostafa/xk6-kafka", resp.BuildCommand)
+	assert.Contains(t, resp.Script, "} from 'k6/x/kafka';")
+	assert.Contains(t, resp.Script, `const brokers = (__ENV.KAFKA_BROKERS || "kafka-1:9092,kafka-2:9092").split(',');`)
+	assert.Contains(t, resp.Script, "kafka_writer_error_count: ['count == 0'],")
+	assert.Contains(t, resp.Script, "kafka_reader_error_count: ['count == 0'],")
+	assert.Contains(t, resp.Script, "value: schemaRegistry.serialize({ data: value(i), schemaType: SCHEMA_TYPE_JSON }),")
+	assert.Contains(t, resp.Script, "const received = reader.consume({ limit: messages });")
+	assert.Contains(t, resp.Script, "connection.createTopic({ topic, numPartitions: 1, replicationFactor: 1 });")
+}
+
+func TestKafkaScriptModes(t *testing.T) {
+	t.Parallel()
+
+	consumer := kafkaScript(kafkaScriptOptions{
+		Brokers: []string{"localhost:9092"}, Topic: "orders", Mode: kafkaConsumer,
+		Serialization: kafkaString, GroupID: "load-test", Messages: 5,
+	})
+	assert.Contains(t, consumer, `const reader = new Reader({ brokers, groupID: "load-test", groupTopics: [topic] });`)
+	assert.NotContains(t, consumer, "Writer")
+	assert.NotContains(t, consumer, "SchemaRegistry")
+	assert.NotContains(t, consumer, "function value(i)")
+
+	producer := kafkaScript(kafkaScriptOptions{
+		Brokers: []string{"localhost:9092"}, Topic: "orders", Mode: kafkaProducer,
+		Serialization: kafkaString, Messages: 5,
+	})
+	assert.NotContains(t, producer, "Reader")
+	assert.NotContains(t, producer, "import { check } from 'k6';")
+	assert.Contains(t, producer, "return `message ${i} of VU ${__VU}, iteration ${__ITER}`;")
+}
+
+func TestGenerateKafkaScriptAvro(t *testing.T) {
+	t.Parallel()
+
+	result, err := generateKafkaScript(t.Context(), newCallRequest(map[string]any{
+		"brokers":             []any{"localhost:9092"},
+		"topic":               "orders",
+		"serialization":       "avro",
+		"schema_registry_url": "http://localhost:8081",
+		"avro_schema": `{"type": "record", "name": "Order", "fields": [` +
+			`{"name": "id", "type": "long"}, {"name": "note", "type": ["null", "string"]},` +
+			`{"name": "items", "type": {"type": "array", "items": "string"}}]}`,
+	}))
+	require.NoError(t, err)
+	require.False(t, result.IsError, "unexpected tool error: %+v", result.Content)
+	var resp generateKafkaScriptResponse
+	decodeJSON(t, result, &resp)
+
+	assert.Contains(t, resp.Script,
+		`const schemaRegistry = new SchemaRegistry({ url: __ENV.SCHEMA_REGISTRY_URL || "http://localhost:8081" });`)
+	assert.Contains(t, resp.Script, "schema: JSON.stringify({\n")
+	assert.Contains(t, resp.Script, "schema: valueSchema, schemaType: SCHEMA_TYPE_AVRO")
+	assert.Contains(t, resp.Script, "\"id\": 1,")
+	assert.Equal(t, []string{"Write the value of the items field in value()"}, resp.Warnings)
+}
+
+func TestGenerateKafkaScriptErrors(t *testing.T) {
+	t.Parallel()
+
+	for name, args := range map[string]map[string]any{
+		"no brokers":    {"topic": "orders"},
+		"no topic":      {"brokers": []any{"localhost:9092"}},
+		"bad mode":      {"brokers": []any{"localhost:9092"}, "topic": "orders", "mode": "both ways"},
+		"no registry":   {"brokers": []any{"localhost:9092"}, "topic": "orders", "serialization": "avro"},
+		"bad schema":    {"brokers": []any{"localhost:9092"}, "topic": "orders", "serialization": "avro", "schema_registry_url": "http://r", "avro_schema": "{"},
+		"many messages": {"brokers": []any{"localhost:9092"}, "topic": "orders", "messages": 5000},
+	} {
+		result, err := generateKafkaScript(t.Context(), newCallRequest(args))
+		require.NoError(t, err, name)
+		assert.True(t, result.IsError, name)
+	}
+}
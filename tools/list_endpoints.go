@@ -0,0 +1,298 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/grafana/mcp-k6/internal/logging"
+	"github.com/grafana/mcp-k6/internal/scriptinfo"
+	"github.com/grafana/mcp-k6/internal/workspace"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// ListEndpointsTool exposes a tool for building an endpoint inventory from scripts.
+//
+//nolint:gochecknoglobals // Shared tool definition registered at startup.
+var ListEndpointsTool = mcp.NewTool(
+	"list_endpoints",
+	readOnlyTool(),
+	mcp.WithDescription(
+		"Scan k6 scripts for HTTP, WebSocket, gRPC and browser calls and produce an endpoint inventory: "+
+			"method, URL pattern (IDs collapsed to {id}), request tags, and whether responses are checked. "+
+			"Point it at a directory in the workspace to inventory a whole suite, e.g. to discuss coverage.",
+	),
+	mcp.WithString(
+		"path",
+		mcp.Description(
+			"A script file or directory inside a workspace root. Directories are scanned recursively "+
+				"for .js, .mjs, .cjs and .ts files (node_modules and hidden directories are skipped).",
+		),
+	),
+	mcp.WithString(
+		"script",
+		mcp.Description("Inline script content to inventory instead of a path."),
+	),
+)
+
+// maxInventoryFiles bounds how many files a single directory scan reads.
+const maxInventoryFiles = 500
+
+// RegisterListEndpointsTool registers the list_endpoints tool with the MCP server.
+func RegisterListEndpointsTool(s *server.MCPServer, ws *workspace.Workspace) {
+	s.AddTool(ListEndpointsTool, withToolLogger("list_endpoints", newListEndpointsHandlerFunc(ws)))
+}
+
+// inventoryEntry is one unique endpoint in the inventory.
+type inventoryEntry struct {
+	Protocol  string   `json:"protocol"`
+	Method    string   `json:"method,omitempty"`
+	Pattern   string   `json:"pattern"`
+	Host      string   `json:"host,omitempty"`
+	Calls     int      `json:"calls"`
+	Checked   int      `json:"checked_calls"`
+	Tags      []string `json:"tags,omitempty"`
+	Locations []string `json:"locations"`
+}
+
+// listEndpointsResponse is the JSON structure returned by the tool.
+type listEndpointsResponse struct {
+	Endpoints    []inventoryEntry `json:"endpoints"`
+	TotalCalls   int              `json:"total_calls"`
+	Unchecked    int              `json:"unchecked_endpoints"`
+	Hosts        []string         `json:"hosts"`
+	FilesScanned int              `json:"files_scanned"`
+	Skipped      []string         `json:"skipped,omitempty"`
+	Truncated    bool             `json:"truncated,omitempty"`
+}
+
+// scannedScript is a script read for the inventory.
+type scannedScript struct {
+	name    string
+	content string
+}
+
+// newListEndpointsHandlerFunc returns an MCP tool handler bound to a workspace.
+func newListEndpointsHandlerFunc(ws *workspace.Workspace) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		logger := logging.LoggerFromContext(ctx)
+
+		var resp listEndpointsResponse
+		scripts, skipped, truncated, err := readScriptsArgument(ctx, ws, request)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		resp.Skipped, resp.Truncated = skipped, truncated
+
+		resp.FilesScanned = len(scripts)
+		resp.Endpoints, resp.TotalCalls = buildInventory(scripts)
+
+		hosts := make(map[string]bool)
+		for _, e := range resp.Endpoints {
+			if e.Checked == 0 {
+				resp.Unchecked++
+			}
+			if e.Host != "" {
+				hosts[e.Host] = true
+			}
+		}
+		resp.Hosts = make([]string, 0, len(hosts))
+		for h := range hosts {
+			resp.Hosts = append(resp.Hosts, h)
+		}
+		sort.Strings(resp.Hosts)
+
+		logger.InfoContext(ctx, "Endpoint inventory built",
+			slog.Int("files", resp.FilesScanned),
+			slog.Int("endpoints", len(resp.Endpoints)),
+			slog.Int("calls", resp.TotalCalls))
+
+		return marshalResponse(ctx, logger, resp)
+	}
+}
+
+// readScriptsArgument returns the scripts selected by the path or script
+// parameters of the request.
+func readScriptsArgument(
+	ctx context.Context,
+	ws *workspace.Workspace,
+	request mcp.CallToolRequest,
+) (scripts []scannedScript, skipped []string, truncated bool, err error) {
+	switch path, script := request.GetString("path", ""), request.GetString("script", ""); {
+	case script != "":
+		return []scannedScript{{name: "script", content: script}}, nil, false, nil
+	case path != "":
+		return collectScripts(ctx, ws, path)
+	default:
+		return nil, nil, false, errors.New("provide either 'path' or 'script'")
+	}
+}
+
+// collectScripts reads the script at path, or every script below it when
+// path is a directory. Files that cannot be read are reported as skipped.
+func collectScripts(
+	ctx context.Context,
+	ws *workspace.Workspace,
+	path string,
+) (scripts []scannedScript, skipped []string, truncated bool, err error) {
+	if ws == nil {
+		return nil, nil, false, workspace.ErrNoRoots
+	}
+	root, err := ws.Resolve(ctx, path)
+	if err != nil {
+		return nil, nil, false, err
+	}
+
+	walkErr := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			skipped = append(skipped, fmt.Sprintf("%s: %v", p, err))
+			return nil
+		}
+		if d.IsDir() {
+			name := d.Name()
+			if p != root && (name == "node_modules" || strings.HasPrefix(name, ".")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !isScriptFile(p) {
+			return nil
+		}
+		if len(scripts) >= maxInventoryFiles {
+			truncated = true
+			return filepath.SkipAll
+		}
+
+		// ReadFile re-checks the roots, so symlinks cannot escape them.
+		data, _, readErr := ws.ReadFile(ctx, p, MaxScriptSize)
+		if readErr != nil {
+			skipped = append(skipped, fmt.Sprintf("%s: %v", p, readErr))
+			return nil
+		}
+		name, relErr := filepath.Rel(root, p)
+		if relErr != nil || name == "." {
+			name = filepath.Base(p)
+		}
+		scripts = append(scripts, scannedScript{name: filepath.ToSlash(name), content: string(data)})
+		return nil
+	})
+	if walkErr != nil && !errors.Is(walkErr, filepath.SkipAll) {
+		return nil, nil, false, walkErr
+	}
+	if len(scripts) == 0 && len(skipped) == 0 {
+		return nil, nil, false, fmt.Errorf("no .js, .mjs, .cjs or .ts files found at %s", path)
+	}
+	return scripts, skipped, truncated, nil
+}
+
+// buildInventory groups the calls of all scripts by protocol, method and URL pattern.
+func buildInventory(scripts []scannedScript) ([]inventoryEntry, int) {
+	index := make(map[string]*inventoryEntry)
+	var order []string
+	total := 0
+
+	for _, script := range scripts {
+		for _, e := range scriptinfo.Analyze(script.content).Endpoints {
+			total++
+			pattern, host := endpointPattern(e)
+			key := e.Protocol + " " + e.Method + " " + pattern
+			entry, ok := index[key]
+			if !ok {
+				entry = &inventoryEntry{Protocol: e.Protocol, Method: e.Method, Pattern: pattern, Host: host}
+				index[key] = entry
+				order = append(order, key)
+			}
+			entry.Calls++
+			if e.Checked {
+				entry.Checked++
+			}
+			entry.Locations = append(entry.Locations, fmt.Sprintf("%s:%d", script.name, e.Line))
+			for k, v := range e.Tags {
+				entry.Tags = append(entry.Tags, k+"="+v)
+			}
+		}
+	}
+
+	entries := make([]inventoryEntry, 0, len(order))
+	for _, key := range order {
+		e := index[key]
+		sort.Strings(e.Tags)
+		e.Tags = removeDuplicates(e.Tags)
+		entries = append(entries, *e)
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].Pattern != entries[j].Pattern {
+			return entries[i].Pattern < entries[j].Pattern
+		}
+		return entries[i].Method < entries[j].Method
+	})
+	return entries, total
+}
+
+//nolint:gochecknoglobals // Compiled once and reused.
+var (
+	templateExprRe = regexp.MustCompile(`\$\{\s*([^}]*?)\s*\}`)
+	concatTokenRe  = regexp.MustCompile(`'([^']*)'|"([^"]*)"|` + "`([^`]*)`" + `|([^+'"` + "`" + `]+)`)
+	idSegmentRe    = regexp.MustCompile(
+		`^(?:\d+|[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}|[0-9a-fA-F]{24,})$`)
+)
+
+// endpointPattern normalizes an endpoint URL to a pattern: runtime
+// expressions become {name}, ID-like path segments become {id} and the query
+// string is dropped. The host is returned for literal URLs.
+func endpointPattern(e scriptinfo.Endpoint) (string, string) {
+	raw := e.URL
+	if e.Dynamic {
+		raw = dynamicURLPattern(raw)
+	}
+
+	if i := strings.IndexAny(raw, "?#"); i >= 0 {
+		raw = raw[:i]
+	}
+
+	host := ""
+	prefix, rest := "", raw
+	if u, err := url.Parse(raw); err == nil && u.Host != "" && !strings.Contains(u.Host, "{") {
+		host = u.Host
+		prefix = u.Scheme + "://" + u.Host
+		rest = u.Path
+	}
+
+	segments := strings.Split(rest, "/")
+	for i, seg := range segments {
+		if idSegmentRe.MatchString(seg) {
+			segments[i] = "{id}"
+		}
+	}
+	return prefix + strings.Join(segments, "/"), host
+}
+
+// dynamicURLPattern converts a template literal or string concatenation
+// into a pattern with {expression} placeholders.
+func dynamicURLPattern(src string) string {
+	src = strings.TrimSpace(src)
+	if strings.HasPrefix(src, "`") && strings.HasSuffix(src, "`") {
+		return templateExprRe.ReplaceAllString(strings.Trim(src, "`"), "{$1}")
+	}
+
+	var b strings.Builder
+	for _, m := range concatTokenRe.FindAllStringSubmatch(src, -1) {
+		switch {
+		case m[1] != "" || m[2] != "":
+			b.WriteString(m[1] + m[2])
+		case m[3] != "":
+			b.WriteString(templateExprRe.ReplaceAllString(m[3], "{$1}"))
+		case strings.TrimSpace(m[4]) != "":
+			b.WriteString("{" + strings.TrimSpace(m[4]) + "}")
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,98 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/grafana/mcp-k6/internal/scriptinfo"
+	"github.com/grafana/mcp-k6/internal/workspace"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListEndpointsHandler(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	files := map[string]string{
+		"smoke.js": "import http from 'k6/http';\nimport { check } from 'k6';\n" +
+			"export default function () {\n" +
+			"  const res = http.get('https://api.example.com/users/42');\n" +
+			"  check(res, { ok: (r) => r.status === 200 });\n" +
+			"}\n",
+		"load/orders.js": "import http from 'k6/http';\n" +
+			"export default function () {\n" +
+			"  http.get('https://api.example.com/users/7?expand=1', { tags: { name: 'user' } });\n" +
+			"  http.post(`${BASE_URL}/orders/${id}`, '{}');\n" +
+			"}\n",
+		"node_modules/lib/index.js": "http.get('https://ignored.example.com');\n",
+		"README.md":                 "http.get('https://ignored.example.com');\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o750))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	}
+
+	handler := newListEndpointsHandlerFunc(workspace.New(nil, root))
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]any{"path": "."}
+	result, err := handler(context.Background(), req)
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+
+	var resp listEndpointsResponse
+	text, ok := result.Content[0].(mcp.TextContent)
+	require.True(t, ok)
+	require.NoError(t, json.Unmarshal([]byte(text.Text), &resp))
+
+	assert.Equal(t, 2, resp.FilesScanned)
+	assert.Equal(t, 3, resp.TotalCalls)
+	assert.Equal(t, []string{"api.example.com"}, resp.Hosts)
+	require.Len(t, resp.Endpoints, 2)
+	assert.Equal(t, inventoryEntry{
+		Protocol:  "http",
+		Method:    "GET",
+		Pattern:   "https://api.example.com/users/{id}",
+		Host:      "api.example.com",
+		Calls:     2,
+		Checked:   1,
+		Tags:      []string{"name=user"},
+		Locations: []string{"load/orders.js:3", "smoke.js:4"},
+	}, resp.Endpoints[0])
+	assert.Equal(t, "{BASE_URL}/orders/{id}", resp.Endpoints[1].Pattern)
+	assert.Equal(t, 1, resp.Unchecked)
+
+	req.Params.Arguments = map[string]any{}
+	result, err = handler(context.Background(), req)
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+}
+
+func TestEndpointPattern(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		endpoint scriptinfo.Endpoint
+		pattern  string
+		host     string
+	}{
+		{scriptinfo.Endpoint{URL: "https://test.k6.io/items/1"}, "https://test.k6.io/items/{id}", "test.k6.io"},
+		{
+			scriptinfo.Endpoint{URL: "https://x.io/o/3f2a1c9e-1b2c-4d5e-8f90-123456789abc/lines"},
+			"https://x.io/o/{id}/lines", "x.io",
+		},
+		{scriptinfo.Endpoint{URL: "BASE_URL + '/items/' + item.id", Dynamic: true}, "{BASE_URL}/items/{item.id}", ""},
+		{scriptinfo.Endpoint{URL: "`${BASE_URL}/login?next=/`", Dynamic: true}, "{BASE_URL}/login", ""},
+		{scriptinfo.Endpoint{URL: "/relative/10"}, "/relative/{id}", ""},
+	}
+	for _, tt := range tests {
+		pattern, host := endpointPattern(tt.endpoint)
+		assert.Equal(t, tt.pattern, pattern, tt.endpoint.URL)
+		assert.Equal(t, tt.host, host, tt.endpoint.URL)
+	}
+}
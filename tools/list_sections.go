@@ -7,11 +7,13 @@ import (
 	"iter"
 	"log/slog"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/grafana/mcp-k6/internal/logging"
 	"github.com/grafana/xk6-docs/docs"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
+	"gopkg.in/yaml.v3"
 )
 
 // ListSectionsTool exposes a tool for listing available k6 documentation sections.
@@ -19,6 +21,7 @@ import (
 //nolint:gochecknoglobals // Shared tool definition registered at startup.
 var ListSectionsTool = mcp.NewTool(
 	"list_sections",
+	readOnlyTool(),
 	mcp.WithDescription(
 		"Lists k6 documentation sections in a hierarchical tree structure. "+
 			"Use this to understand documentation organization and discover related topics. "+
@@ -51,14 +54,17 @@ var ListSectionsTool = mcp.NewTool(
 		"root_slug",
 		mcp.Description(
 			"Optional: List the contents under this slug (i.e., its children). "+
-				"Use the slug from a previous list_sections response.",
+				"Use the slug from a previous list_sections response, or a grafana.com/docs/k6 URL.",
 		),
 	),
+	outputSchema(listSectionsResponse{}, versionsResponse{}),
 )
 
 const (
 	defaultTreeDepth = 1
 	maxTreeDepth     = 5
+	// maxSummaryLength bounds the landing page summaries of categories.
+	maxSummaryLength = 300
 )
 
 // listSectionsParams holds parsed and validated request parameters.
@@ -72,12 +78,14 @@ type listSectionsParams struct {
 // treeItem is the MCP-facing representation of a section node in the response.
 // Its JSON shape is part of the public tool contract.
 type treeItem struct {
-	Slug        string      `json:"slug"`
-	Title       string      `json:"title"`
-	Description string      `json:"description,omitempty"`
-	ChildCount  int         `json:"child_count"`
-	HasMore     bool        `json:"has_more,omitempty"`
-	Children    []*treeItem `json:"children,omitempty"`
+	Slug        string `json:"slug"`
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	ChildCount  int    `json:"child_count"`
+	// SectionCount is the number of sections in a top-level category.
+	SectionCount int         `json:"section_count,omitempty"`
+	HasMore      bool        `json:"has_more,omitempty"`
+	Children     []*treeItem `json:"children,omitempty"`
 }
 
 // listSectionsResponse is the JSON structure returned by the tool.
@@ -141,10 +149,13 @@ func newListSectionsHandlerFunc(
 				slog.String("root_slug", params.RootSlug),
 				slog.String("version", idx.Version))
 			return mcp.NewToolResultError(
-				fmt.Sprintf("root slug not found: %s", params.RootSlug),
+				fmt.Sprintf("root slug not found: %s.%s", params.RootSlug, didYouMean(suggestSlugs(idx, params.RootSlug))),
 			), nil
 		}
 
+		if params.RootSlug == "" {
+			describeCategories(ctx, catalog, idx, tree)
+		}
 		resp := buildListSectionsResponse(idx.Version, catalog.Versions(), params, tree, total)
 
 		logger.InfoContext(ctx, "Sections listed successfully",
@@ -154,7 +165,7 @@ func newListSectionsHandlerFunc(
 			slog.Int("depth", params.Depth),
 			slog.String("root_slug", params.RootSlug))
 
-		return marshalResponse(ctx, logger, resp)
+		return structuredResponse(ctx, logger, resp)
 	}
 }
 
@@ -166,12 +177,19 @@ func parseListSectionsParams(request mcp.CallToolRequest) listSectionsParams {
 		depth = maxTreeDepth
 	}
 
-	return listSectionsParams{
+	params := listSectionsParams{
 		Version:  request.GetString("version", ""),
 		Category: request.GetString("category", ""),
 		RootSlug: request.GetString("root_slug", ""),
 		Depth:    depth,
 	}
+	if version, slug, ok := parseDocURL(params.RootSlug); ok {
+		params.RootSlug = slug
+		if params.Version == "" {
+			params.Version = version
+		}
+	}
+	return params
 }
 
 func logParams(ctx context.Context, logger *slog.Logger, params listSectionsParams) {
@@ -200,7 +218,7 @@ func handleVersionsRequest(
 		Message:  "Available k6 documentation versions. Use version parameter to filter sections.",
 	}
 
-	return marshalResponse(ctx, logger, resp)
+	return structuredResponse(ctx, logger, resp)
 }
 
 // buildResponseTree returns the response tree, the appropriate total count for
@@ -217,19 +235,23 @@ func buildResponseTree(idx *docs.Index, params listSectionsParams) ([]*treeItem,
 		return []*treeItem{root}, total, true
 	}
 
-	if params.RootSlug != "" {
-		if _, ok := idx.Lookup(params.RootSlug); !ok {
+	root := params.RootSlug
+	if root != "" {
+		sec, ok := idx.Lookup(root)
+		if !ok {
 			return nil, 0, false
 		}
+		// Tree only knows canonical slugs
+		root = sec.Slug
 	}
 
-	return collectRoots(idx.Tree(params.RootSlug, params.Depth)), len(idx.Sections), true
+	return collectRoots(idx.Tree(root, params.Depth)), len(idx.Sections), true
 }
 
 // collectRoots collects level-0 nodes from a docs.Tree iterator and maps
 // them into MCP response items. Tree already yields roots in weight order.
 func collectRoots(seq iter.Seq2[int, *docs.Tree]) []*treeItem {
-	var out []*treeItem
+	out := []*treeItem{}
 	for level, t := range seq {
 		if level == 0 {
 			out = append(out, mapTree(t))
@@ -238,6 +260,62 @@ func collectRoots(seq iter.Seq2[int, *docs.Tree]) []*treeItem {
 	return out
 }
 
+// describeCategories completes the top-level categories of tree, so the first
+// listing maps the docs without a call per category: it counts the sections of
+// each, and summarizes its landing page when the index has no description.
+func describeCategories(ctx context.Context, catalog *docs.Catalog, idx *docs.Index, tree []*treeItem) {
+	for _, item := range tree {
+		sec, ok := idx.Lookup(item.Slug)
+		if !ok || sec.Category != sec.Slug {
+			continue
+		}
+		item.SectionCount = len(idx.ByCategory(sec.Slug))
+		if item.Description != "" {
+			continue
+		}
+		if content, err := catalog.Read(ctx, idx.Version, sec.Slug); err == nil {
+			item.Description = landingSummary(string(content), idx.Version)
+		}
+	}
+}
+
+// landingSummary returns the description of the frontmatter of a landing
+// page, or else its first paragraph, cut to maxSummaryLength.
+func landingSummary(content, version string) string {
+	front, body, ok := docs.SplitFrontmatter(content)
+	if ok {
+		var meta struct {
+			Description string `yaml:"description"`
+		}
+		if yaml.Unmarshal([]byte(front), &meta) == nil && strings.TrimSpace(meta.Description) != "" {
+			return shorten(strings.TrimSpace(meta.Description))
+		}
+	}
+	for _, paragraph := range strings.Split(docs.Transform(body, version), "\n\n") {
+		paragraph = strings.TrimSpace(paragraph)
+		if paragraph == "" || strings.ContainsAny(paragraph[:1], "#>|`-*<") {
+			continue
+		}
+		return shorten(strings.Join(strings.Fields(paragraph), " "))
+	}
+	return ""
+}
+
+// shorten cuts s to maxSummaryLength at a word boundary.
+func shorten(s string) string {
+	if len(s) <= maxSummaryLength {
+		return s
+	}
+	cut := strings.LastIndexByte(s[:maxSummaryLength], ' ')
+	if cut <= 0 {
+		cut = maxSummaryLength
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+	}
+	return s[:cut] + "…"
+}
+
 // buildCategoryRoot returns a treeItem for the category root section. At
 // depth > 1 it populates the root's children using the docs index tree.
 // Returns nil if the category section does not exist.
@@ -188,6 +188,84 @@ func TestListSectionsHandlerMissingRootSlug(t *testing.T) {
 	require.True(t, result.IsError, "expected tool error result for unknown root_slug")
 }
 
+func TestListSectionsHandlerRootSlugURL(t *testing.T) {
+	t.Parallel()
+
+	fsys := fstest.MapFS{}
+	for _, version := range []string{"v1.4.x", "v1.3.x"} {
+		index, err := json.Marshal(docs.Index{Version: version, Sections: []docs.Section{
+			{Slug: "using-k6", Title: "Using k6", Children: []string{"using-k6/scenarios"}, Aliases: []string{"guides"}},
+			{Slug: "using-k6/scenarios", Title: "Scenarios " + version},
+		}})
+		require.NoError(t, err)
+		fsys[version+"/sections.json"] = &fstest.MapFile{Data: index}
+	}
+	handler := newListSectionsHandlerFunc(docs.NewCatalog(docs.WithFS(fsys)))
+
+	for rootSlug, wantVersion := range map[string]string{
+		"https://grafana.com/docs/k6/v1.3.x/using-k6/": "v1.3.x",
+		"https://grafana.com/docs/k6/latest/guides/":   "v1.4.x",
+		"guides": "v1.4.x",
+	} {
+		result, err := handler(t.Context(), newCallRequest(map[string]any{"root_slug": rootSlug}))
+		require.NoError(t, err)
+		require.False(t, result.IsError, "unexpected tool error for %s: %+v", rootSlug, result.Content)
+		resp := decodeListSectionsResponse(t, result)
+		require.Equal(t, wantVersion, resp.Version, rootSlug)
+		require.Len(t, resp.Tree, 1, rootSlug)
+		require.Equal(t, "Scenarios "+wantVersion, resp.Tree[0].Title, rootSlug)
+	}
+}
+
+func TestListSectionsHandlerDescribesCategories(t *testing.T) {
+	t.Parallel()
+
+	index, err := json.Marshal(docs.Index{Version: "v1.4.x", Sections: []docs.Section{
+		{
+			Slug: "using-k6", RelPath: "using-k6/_index.md", Title: "Using k6", Category: "using-k6",
+			Description: "Core concepts.", Children: []string{"using-k6/scenarios"},
+		},
+		{Slug: "using-k6/scenarios", Title: "Scenarios", Category: "using-k6", Children: []string{"using-k6/scenarios/a"}},
+		{Slug: "using-k6/scenarios/a", Title: "A", Category: "using-k6"},
+		{Slug: "examples", RelPath: "examples/_index.md", Title: "Examples", Category: "examples", Weight: 1},
+		{
+			Slug: "results-output", RelPath: "results-output/_index.md", Title: "Results",
+			Category: "results-output", Weight: 2,
+		},
+	}})
+	require.NoError(t, err)
+	fsys := fstest.MapFS{
+		"v1.4.x/sections.json": {Data: index},
+		"v1.4.x/markdown/examples/_index.md": {Data: []byte(
+			"---\ntitle: Examples\ndescription: 'Scripts for common cases.'\n---\n# Examples\n\nIgnored.\n")},
+		"v1.4.x/markdown/results-output/_index.md": {Data: []byte(
+			"---\ntitle: Results\n---\n# Results output\n\n{{< section >}}\n\nk6 emits\nmetrics to many outputs.\n")},
+	}
+	handler := newListSectionsHandlerFunc(docs.NewCatalog(docs.WithFS(fsys)))
+
+	result, err := handler(t.Context(), newCallRequest(nil))
+	require.NoError(t, err)
+	require.False(t, result.IsError, "unexpected tool error: %+v", result.Content)
+	resp := decodeListSectionsResponse(t, result)
+	require.Len(t, resp.Tree, 3)
+	byTitle := map[string]*treeItem{}
+	for _, item := range resp.Tree {
+		byTitle[item.Title] = item
+	}
+	require.Equal(t, "Core concepts.", byTitle["Using k6"].Description)
+	require.Equal(t, 3, byTitle["Using k6"].SectionCount)
+	require.Equal(t, 1, byTitle["Using k6"].ChildCount)
+	require.Equal(t, "Scripts for common cases.", byTitle["Examples"].Description)
+	require.Equal(t, "k6 emits metrics to many outputs.", byTitle["Results"].Description)
+
+	// Branches below the categories are listed as they are
+	result, err = handler(t.Context(), newCallRequest(map[string]any{"root_slug": "using-k6"}))
+	require.NoError(t, err)
+	resp = decodeListSectionsResponse(t, result)
+	require.Len(t, resp.Tree, 1)
+	require.Zero(t, resp.Tree[0].SectionCount)
+}
+
 func newCallRequest(args map[string]any) mcp.CallToolRequest {
 	if args == nil {
 		args = map[string]any{}
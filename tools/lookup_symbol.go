@@ -0,0 +1,208 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/grafana/mcp-k6/internal/fuzzy"
+	"github.com/grafana/mcp-k6/internal/logging"
+	"github.com/grafana/mcp-k6/internal/snippet"
+	"github.com/grafana/mcp-k6/internal/symbols"
+	"github.com/grafana/xk6-docs/docs"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// LookupSymbolTool exposes a tool for finding where a k6 API symbol or term is
+// documented.
+//
+//nolint:gochecknoglobals // Shared tool definition registered at startup.
+var LookupSymbolTool = mcp.NewTool(
+	"lookup_symbol",
+	readOnlyTool(),
+	mcp.WithDescription(
+		"Finds the documentation section of a k6 JavaScript API symbol or glossary term, such as "+
+			"'http.get', 'check', 'SharedArray', 'browser.newPage', 'k6/http' or 'VU'. "+
+			"Use this instead of browsing list_sections when you know the name. Each match comes with a "+
+			"snippet of its page around the symbol, which often answers the question; otherwise call "+
+			"get_documentation with the returned slug.",
+	),
+	mcp.WithString(
+		"symbol",
+		mcp.Required(),
+		mcp.Description("Symbol, module path or term to look up; case is ignored."),
+	),
+	mcp.WithString(
+		"version",
+		mcp.Description("Optional: k6 version (e.g., 'v1.4.x'). Defaults to latest."),
+	),
+)
+
+// symbolMatch is a match of the symbol with the sentences of its page
+// around the symbol.
+type symbolMatch struct {
+	symbols.Entry
+	Snippet string `json:"snippet,omitempty"`
+}
+
+// lookupSymbolResponse is the JSON structure returned by the tool.
+type lookupSymbolResponse struct {
+	Symbol  string        `json:"symbol"`
+	Matches []symbolMatch `json:"matches"`
+	Version string        `json:"version"`
+}
+
+// symbolIndexes builds the symbol index of each doc version once.
+type symbolIndexes struct {
+	mu      sync.Mutex
+	indexes map[string]*symbols.Index
+}
+
+func (s *symbolIndexes) get(ctx context.Context, catalog *docs.Catalog, idx *docs.Index) *symbols.Index {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if x, ok := s.indexes[idx.Version]; ok {
+		return x
+	}
+
+	var glossarySlug string
+	var glossary []byte
+	for i := range idx.Sections {
+		sec := &idx.Sections[i]
+		if sec.Slug == "glossary" || strings.HasSuffix(sec.Slug, "/glossary") {
+			glossarySlug = sec.Slug
+			// A glossary that cannot be read leaves the API symbols
+			glossary, _ = catalog.Read(ctx, idx.Version, sec.Slug)
+			break
+		}
+	}
+	x := symbols.Build(idx, glossarySlug, glossary)
+	s.indexes[idx.Version] = x
+	return x
+}
+
+// RegisterLookupSymbolTool registers the lookup_symbol tool with the MCP server.
+func RegisterLookupSymbolTool(s *server.MCPServer, catalog *docs.Catalog) {
+	s.AddTool(LookupSymbolTool, withToolLogger("lookup_symbol", newLookupSymbolHandlerFunc(catalog)))
+}
+
+func newLookupSymbolHandlerFunc(catalog *docs.Catalog) server.ToolHandlerFunc {
+	indexes := &symbolIndexes{indexes: make(map[string]*symbols.Index)}
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		logger := logging.LoggerFromContext(ctx)
+
+		symbol, err := request.RequireString("symbol")
+		if err != nil || strings.TrimSpace(symbol) == "" {
+			return mcp.NewToolResultError("missing or invalid symbol parameter"), nil
+		}
+		version := request.GetString("version", "")
+
+		idx, err := catalog.Index(ctx, version)
+		if err != nil {
+			return mcp.NewToolResultError(versionError(version, catalog, err).Error()), nil
+		}
+		x := indexes.get(ctx, catalog, idx)
+
+		matches := x.Lookup(symbol)
+		if len(matches) == 0 {
+			logger.InfoContext(ctx, "Symbol not found", slog.String("symbol", symbol))
+			return mcp.NewToolResultError(fmt.Sprintf(
+				"symbol not found: %s in version %s.%s Use list_sections on javascript-api to browse the API",
+				symbol, idx.Version, didYouMean(closestSymbols(x.Symbols(), symbol)))), nil
+		}
+
+		logger.InfoContext(ctx, "Symbol found",
+			slog.String("symbol", symbol),
+			slog.Int("matches", len(matches)))
+		return marshalResponse(ctx, logger, lookupSymbolResponse{
+			Symbol:  symbol,
+			Matches: symbolSnippets(ctx, catalog, idx.Version, symbol, matches),
+			Version: idx.Version,
+		})
+	}
+}
+
+// symbolSnippets returns the matches of symbol with the sentences of their
+// page around the symbol, or the first ones when the page does not mention
+// it. Glossary terms are searched under their heading. Pages that cannot be
+// read leave their matches without a snippet.
+func symbolSnippets(
+	ctx context.Context,
+	catalog *docs.Catalog,
+	version, symbol string,
+	entries []symbols.Entry,
+) []symbolMatch {
+	pages := make(map[string]string)
+	out := make([]symbolMatch, 0, len(entries))
+	for _, e := range entries {
+		text, ok := pages[e.Slug]
+		if !ok {
+			if content, err := catalog.Read(ctx, version, e.Slug); err == nil {
+				_, body, _ := docs.SplitFrontmatter(string(content))
+				text = docs.Transform(body, version)
+			}
+			pages[e.Slug] = text
+		}
+		if e.Anchor != "" {
+			text = termSection(text, e.Symbol)
+		}
+		name := e.Symbol[strings.LastIndexByte(e.Symbol, '.')+1:]
+		terms := []string{e.Symbol, strings.TrimSuffix(strings.TrimSpace(symbol), "()"), name}
+		out = append(out, symbolMatch{Entry: e, Snippet: snippet.Find(text, terms, snippet.DefaultContext)})
+	}
+	return out
+}
+
+// termSection returns the part of a glossary page under the heading of
+// term, or "" when it has none.
+func termSection(text, term string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if !strings.HasPrefix(line, "#") || !strings.EqualFold(strings.Trim(line, " #*`"), term) {
+			continue
+		}
+		end := i + 1
+		for end < len(lines) && !strings.HasPrefix(lines[end], "#") {
+			end++
+		}
+		return strings.Join(lines[i+1:end], "\n")
+	}
+	return ""
+}
+
+// closestSymbols returns the known symbols a few edits away from symbol, by
+// their qualified or last name, or containing it, closest first.
+func closestSymbols(known []string, symbol string) []string {
+	query := strings.ToLower(strings.TrimSpace(symbol))
+	name := query[strings.LastIndexByte(query, '.')+1:]
+	threshold := max(2, len(query)/4)
+	scores := make(map[string]int)
+	for _, candidate := range known {
+		score := min(fuzzy.Distance(query, candidate),
+			fuzzy.Distance(name, candidate[strings.LastIndexByte(candidate, '.')+1:])+1)
+		if len(query) >= minContainedLength && strings.Contains(candidate, query) {
+			score = min(score, 1)
+		}
+		if score <= threshold {
+			scores[candidate] = score
+		}
+	}
+	out := make([]string, 0, len(scores))
+	for candidate := range scores {
+		out = append(out, candidate)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if scores[out[i]] != scores[out[j]] {
+			return scores[out[i]] < scores[out[j]]
+		}
+		return out[i] < out[j]
+	})
+	if len(out) > maxSlugSuggestions {
+		out = out[:maxSlugSuggestions]
+	}
+	return out
+}
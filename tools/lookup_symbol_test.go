@@ -0,0 +1,55 @@
+package tools
+
+import (
+	"encoding/json"
+	"testing"
+	"testing/fstest"
+
+	"github.com/grafana/xk6-docs/docs"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLookupSymbol(t *testing.T) {
+	t.Parallel()
+
+	index, err := json.Marshal(docs.Index{Version: "v1.4.x", Sections: []docs.Section{
+		{Slug: "javascript-api/k6-http", Title: "k6/http"},
+		{Slug: "javascript-api/k6-http/get", RelPath: "javascript-api/k6-http/get.md", Title: "get( url, [params] )"},
+		{Slug: "javascript-api/k6-data/sharedarray", Title: "SharedArray"},
+		{Slug: "misc/glossary", RelPath: "misc/glossary.md", Title: "Glossary"},
+	}})
+	require.NoError(t, err)
+	fsys := fstest.MapFS{
+		"v1.4.x/sections.json": {Data: index},
+		"v1.4.x/markdown/misc/glossary.md": {Data: []byte("# Glossary\n\n## Iteration\n\nA virtual user runs " +
+			"the default function.\n\n## Virtual user\n\nA VU runs iterations. Virtual users run in parallel.\n")},
+		"v1.4.x/markdown/javascript-api/k6-http/get.md": {Data: []byte("---\ntitle: get\n---\n\n# get( url, " +
+			"[params] )\n\nMake a GET request. Use `http.get` for reads.\n\n```javascript\nhttp.get(url);\n```\n")},
+	}
+	handler := newLookupSymbolHandlerFunc(docs.NewCatalog(docs.WithFS(fsys)))
+
+	result, err := handler(t.Context(), newCallRequest(map[string]any{"symbol": "http.get"}))
+	require.NoError(t, err)
+	require.False(t, result.IsError, "unexpected tool error: %+v", result.Content)
+	var resp lookupSymbolResponse
+	decodeJSON(t, result, &resp)
+	require.Len(t, resp.Matches, 1)
+	assert.Equal(t, "javascript-api/k6-http/get", resp.Matches[0].Slug)
+	assert.Equal(t, "Make a GET request. Use `**http.get**` for reads.", resp.Matches[0].Snippet)
+	assert.Equal(t, "v1.4.x", resp.Version)
+
+	result, err = handler(t.Context(), newCallRequest(map[string]any{"symbol": "Virtual User"}))
+	require.NoError(t, err)
+	decodeJSON(t, result, &resp)
+	require.Len(t, resp.Matches, 1)
+	assert.Equal(t, "virtual-user", resp.Matches[0].Anchor)
+	assert.Equal(t, "A VU runs iterations. **Virtual users** run in parallel.", resp.Matches[0].Snippet,
+		"glossary terms are searched under their heading")
+
+	result, err = handler(t.Context(), newCallRequest(map[string]any{"symbol": "sharedaray"}))
+	require.NoError(t, err)
+	require.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "Did you mean: data.sharedarray?")
+}
@@ -0,0 +1,123 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/grafana/mcp-k6/internal/logging"
+	"github.com/grafana/mcp-k6/internal/migrate"
+	"github.com/grafana/mcp-k6/internal/releasenotes"
+	"github.com/grafana/mcp-k6/internal/workspace"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// MigrateScriptTool exposes a tool for rewriting the deprecated patterns of a
+// script.
+//
+//nolint:gochecknoglobals // Shared tool definition registered at startup.
+var MigrateScriptTool = mcp.NewTool(
+	"migrate_script",
+	readOnlyTool(),
+	mcp.WithDescription(
+		"Rewrite the deprecated patterns of a k6 script to their equivalent in a target k6 version: imports of "+
+			"graduated experimental modules, such as k6/experimental/browser to k6/browser, and removed modules "+
+			"and options. Returns the migrated script, a unified diff and notes on what to change by hand.",
+	),
+	mcp.WithString(
+		"script",
+		mcp.Description("The k6 script content to migrate. Provide either script or script_path."),
+	),
+	mcp.WithString(
+		"script_path",
+		mcp.Description(scriptPathDescription),
+	),
+	mcp.WithString(
+		"k6_version",
+		mcp.Description("Optional: the k6 version to migrate to, e.g. '1.0.0' "+
+			"(default: the installed k6, or the latest release when k6 is not found)."),
+	),
+)
+
+// RegisterMigrateScriptTool registers the migrate_script tool with the MCP
+// server.
+func RegisterMigrateScriptTool(s *server.MCPServer, ws *workspace.Workspace) {
+	s.AddTool(MigrateScriptTool, withToolLogger("migrate_script", newMigrateScriptHandlerFunc(ws)))
+}
+
+type migrateScriptResponse struct {
+	*migrate.Result
+	// K6Version is the version migrated to, empty for the latest release.
+	K6Version string   `json:"k6_version,omitempty"`
+	Notes     []string `json:"notes,omitempty"`
+	NextSteps []string `json:"next_steps,omitempty"`
+}
+
+func newMigrateScriptHandlerFunc(ws *workspace.Workspace) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		logger := logging.LoggerFromContext(ctx)
+
+		script, scriptPath, err := readScriptArgument(ctx, ws, request)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		raw := request.GetString("k6_version", "")
+		if raw == "" {
+			raw = installedK6Version(ctx)
+		}
+		var target releasenotes.Version
+		if raw != "" {
+			var ok bool
+			if target, ok = releasenotes.ParseVersion(raw); !ok {
+				return mcp.NewToolResultError(fmt.Sprintf("k6_version %q is not a k6 version such as '1.0.0'", raw)), nil
+			}
+		}
+
+		resp := migrateScriptResponse{Result: migrate.Migrate(script, target)}
+		if raw != "" {
+			resp.K6Version = target.String()
+		}
+		resp.Notes = migrationNotes(resp.Result)
+		resp.NextSteps = migrationNextSteps(resp, scriptPath)
+
+		logger.InfoContext(ctx, "Script migrated",
+			slog.String("k6_version", resp.K6Version),
+			slog.Int("changes", len(resp.Changes)),
+			slog.Int("manual", len(resp.Manual)),
+			slog.Int("pending", len(resp.Pending)))
+		return structuredResponse(ctx, logger, resp)
+	}
+}
+
+func migrationNotes(res *migrate.Result) []string {
+	var notes []string
+	for _, c := range res.Changes {
+		if c.Note != "" {
+			notes = append(notes, fmt.Sprintf("Line %d: %s", c.Line, c.Note))
+		}
+	}
+	for _, c := range res.Manual {
+		notes = append(notes, "Rewrite by hand, "+c.Describe())
+	}
+	for _, c := range res.Pending {
+		notes = append(notes, fmt.Sprintf("Not migrated, the target is older than %s: %s", c.Since, c.Describe()))
+	}
+	return notes
+}
+
+func migrationNextSteps(resp migrateScriptResponse, scriptPath string) []string {
+	if len(resp.Changes) == 0 && len(resp.Manual) == 0 {
+		return []string{"The script uses no deprecated pattern known to migrate_script; " +
+			"use check_compatibility to check the rest of it"}
+	}
+	steps := []string{"Use validate_script to check the migrated script"}
+	if scriptPath != "" && len(resp.Changes) > 0 {
+		steps = append(steps, "Use write_script to save the migrated script back to "+scriptPath+
+			", or apply_patch with the patch")
+	}
+	if len(resp.Pending) > 0 {
+		steps = append(steps, "Use whats_new to see what upgrading k6 past the target brings")
+	}
+	return steps
+}
@@ -0,0 +1,36 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrateScript(t *testing.T) {
+	t.Parallel()
+
+	handler := newMigrateScriptHandlerFunc(nil)
+	script := `import { browser } from 'k6/experimental/browser';
+import ws from 'k6/experimental/websockets';
+
+export default async function () {}
+`
+	result, err := handler(t.Context(), newCallRequest(map[string]any{"script": script, "k6_version": "0.57.0"}))
+	require.NoError(t, err)
+	require.False(t, result.IsError, "unexpected tool error: %+v", result.Content)
+	var resp migrateScriptResponse
+	decodeJSON(t, result, &resp)
+	assert.Equal(t, "v0.57.0", resp.K6Version)
+	assert.Contains(t, resp.Script, "from 'k6/browser'")
+	assert.Contains(t, resp.Script, "from 'k6/experimental/websockets'")
+	assert.Contains(t, resp.Patch, "+import { browser } from 'k6/browser';")
+	require.Len(t, resp.Notes, 2)
+	assert.Contains(t, resp.Notes[0], "Line 1: k6/browser methods return promises")
+	assert.Equal(t, "Not migrated, the target is older than v1.0.0: "+
+		"line 2: k6/experimental/websockets becomes k6/websockets in v1.0.0", resp.Notes[1])
+
+	result, err = handler(t.Context(), newCallRequest(map[string]any{"script": script, "k6_version": "latest"}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+}
@@ -0,0 +1,444 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/grafana/mcp-k6/internal/logging"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// GenerateMQTTScriptTool exposes a tool for writing MQTT device simulations
+// with the xk6-mqtt extension.
+//
+//nolint:gochecknoglobals // Shared tool definition registered at startup.
+var GenerateMQTTScriptTool = mcp.NewTool(
+	"generate_mqtt_script",
+	readOnlyTool(),
+	mcp.WithDescription(
+		"Generate a k6 script simulating MQTT devices with the xk6-mqtt extension (k6/x/mqtt). Each VU is "+
+			"a device holding its own connection, which publishes JSON messages, subscribes, or both, measuring "+
+			"the delivery latency of its messages in mqtt_message_latency; the fan_out pattern has one "+
+			"publisher and many subscribers on a shared topic. The script fails on client errors and lost "+
+			"messages. It needs a k6 binary built with xk6-mqtt: the response tells whether the installed k6 "+
+			"has it and gives the xk6 build command otherwise.",
+	),
+	mcp.WithString(
+		"broker",
+		mcp.Required(),
+		mcp.Description("The broker URL, e.g. mqtt://localhost:1883 or mqtts://broker.example.com:8883. "+
+			"The script reads it from MQTT_BROKER first."),
+	),
+	mcp.WithString(
+		"pattern",
+		mcp.Description("'roundtrip' (default): devices publish to their topic and receive their messages back; "+
+			"'publish': devices only publish; 'subscribe': devices only receive, e.g. commands sent by another "+
+			"system; 'fan_out': one publisher sends to a topic all devices subscribe to."),
+		mcp.Enum(mqttRoundtrip, mqttPublish, mqttSubscribe, mqttFanOut),
+	),
+	mcp.WithString(
+		"topic",
+		mcp.Description("Optional: the topic, where {device} stands for the device ID (default: "+
+			"devices/{device}/telemetry, devices/{device}/commands for subscribe, devices/broadcast for fan_out). "+
+			"The script reads it from MQTT_TOPIC first."),
+	),
+	mcp.WithNumber(
+		"devices",
+		mcp.Description(fmt.Sprintf("Optional: simulated devices, the subscribers of fan_out "+
+			"(default: %d, max: %d).", defaultMQTTDevices, maxMQTTDevices)),
+	),
+	mcp.WithNumber(
+		"messages",
+		mcp.Description(fmt.Sprintf("Optional: messages published by each device, or by the fan_out publisher "+
+			"(default: %d, max: %d).", defaultMQTTMessages, maxMQTTMessages)),
+	),
+	mcp.WithNumber(
+		"interval",
+		mcp.Description(fmt.Sprintf("Optional: milliseconds between the messages of a device "+
+			"(default: %d).", defaultMQTTInterval)),
+	),
+	mcp.WithNumber(
+		"qos",
+		mcp.Description("Optional: quality of service of messages and subscriptions, 0, 1 (default) or 2."),
+	),
+	mcp.WithString(
+		"latency_threshold",
+		mcp.Description(fmt.Sprintf("Optional: mqtt_message_latency threshold (default: %q).",
+			defaultMQTTLatency)),
+	),
+)
+
+// Patterns of generate_mqtt_script.
+const (
+	mqttRoundtrip = "roundtrip"
+	mqttPublish   = "publish"
+	mqttSubscribe = "subscribe"
+	mqttFanOut    = "fan_out"
+)
+
+const (
+	defaultMQTTDevices  = 10
+	maxMQTTDevices      = 10000
+	defaultMQTTMessages = 10
+	maxMQTTMessages     = 1000
+	defaultMQTTInterval = 1000
+	maxMQTTInterval     = 60000
+	// defaultMQTTLatency is the default threshold of mqtt_message_latency.
+	defaultMQTTLatency = "p(95)<500"
+	// mqttModule is the module of the extension.
+	mqttModule = "k6/x/mqtt"
+)
+
+//nolint:gochecknoglobals // Lookup table.
+var mqttSchemes = []string{"mqtt", "mqtts", "tcp", "ssl", "ws", "wss"}
+
+// RegisterGenerateMQTTScriptTool registers the generate_mqtt_script tool with
+// the MCP server.
+func RegisterGenerateMQTTScriptTool(s *server.MCPServer) {
+	s.AddTool(GenerateMQTTScriptTool, withToolLogger("generate_mqtt_script", generateMQTTScript))
+}
+
+// mqttScriptOptions are the parameters of a generated MQTT script.
+type mqttScriptOptions struct {
+	Broker  string
+	Pattern string
+	Topic   string
+	Devices int
+	// Messages is the number of messages of each publisher.
+	Messages int
+	// Interval is the time between messages, in milliseconds.
+	Interval int
+	QoS      int
+	Latency  string
+}
+
+// generateMQTTScriptResponse is the JSON structure returned by the tool.
+type generateMQTTScriptResponse struct {
+	Script string `json:"script"`
+	// Extension is the Go module of xk6-mqtt.
+	Extension    string `json:"extension"`
+	BuildCommand string `json:"build_command"`
+	// K6HasExtension tells whether the k6 on the PATH was built with
+	// xk6-mqtt, and is omitted when k6 was not found.
+	K6HasExtension *bool    `json:"k6_has_extension,omitempty"`
+	Warnings       []string `json:"warnings,omitempty"`
+	NextSteps      []string `json:"next_steps"`
+}
+
+func generateMQTTScript(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger := logging.LoggerFromContext(ctx)
+
+	opts, warnings, err := mqttOptions(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	script := mqttScript(opts)
+
+	extension := extensionModule(mqttModule)
+	resp := generateMQTTScriptResponse{
+		Script:       script,
+		Extension:    extension,
+		BuildCommand: xk6BuildCommand([]string{extension}),
+		Warnings:     warnings,
+	}
+	if extensions, ok := installedExtensions(ctx); ok {
+		has := providesModule(extensions, mqttModule)
+		resp.K6HasExtension = &has
+	}
+	if resp.K6HasExtension == nil || !*resp.K6HasExtension {
+		resp.NextSteps = append(resp.NextSteps, fmt.Sprintf("Build k6 with xk6-mqtt: %s, and put the ./k6 "+
+			"binary it writes on the PATH in place of k6", resp.BuildCommand))
+	}
+	resp.NextSteps = append(resp.NextSteps,
+		"Replace message() with the payload of your devices, keeping device and sentAt for the latency",
+		"Use validate_script to run the script once against the broker, setting MQTT_BROKER in env_file "+
+			"for other environments",
+		"Raise devices step by step: each one holds a connection, so check the broker's connection limits",
+	)
+
+	logger.InfoContext(ctx, "MQTT script generated",
+		slog.String("pattern", opts.Pattern),
+		slog.Int("devices", opts.Devices),
+		slog.Int("script_size", len(script)))
+
+	return marshalResponse(ctx, logger, resp)
+}
+
+// mqttOptions reads and checks the parameters of request.
+func mqttOptions(request mcp.CallToolRequest) (mqttScriptOptions, []string, error) {
+	opts := mqttScriptOptions{
+		Broker:   strings.TrimSpace(request.GetString("broker", "")),
+		Pattern:  request.GetString("pattern", mqttRoundtrip),
+		Topic:    strings.TrimSpace(request.GetString("topic", "")),
+		Devices:  request.GetInt("devices", defaultMQTTDevices),
+		Messages: request.GetInt("messages", defaultMQTTMessages),
+		Interval: request.GetInt("interval", defaultMQTTInterval),
+		QoS:      request.GetInt("qos", 1),
+		Latency:  strings.TrimSpace(request.GetString("latency_threshold", defaultMQTTLatency)),
+	}
+
+	u, err := url.Parse(opts.Broker)
+	switch {
+	case opts.Broker == "":
+		return opts, nil, errors.New("broker is required, e.g. mqtt://localhost:1883")
+	case err != nil || u.Host == "" || !slices.Contains(mqttSchemes, u.Scheme):
+		return opts, nil, fmt.Errorf("invalid broker %q: expected a URL with a scheme among %s",
+			opts.Broker, strings.Join(mqttSchemes, ", "))
+	case !slices.Contains([]string{mqttRoundtrip, mqttPublish, mqttSubscribe, mqttFanOut}, opts.Pattern):
+		return opts, nil, fmt.Errorf("invalid pattern %q: use 'roundtrip', 'publish', 'subscribe' or 'fan_out'",
+			opts.Pattern)
+	case opts.Devices < 1 || opts.Devices > maxMQTTDevices:
+		return opts, nil, fmt.Errorf("devices must be between 1 and %d", maxMQTTDevices)
+	case opts.Messages < 1 || opts.Messages > maxMQTTMessages:
+		return opts, nil, fmt.Errorf("messages must be between 1 and %d", maxMQTTMessages)
+	case opts.Interval < 1 || opts.Interval > maxMQTTInterval:
+		return opts, nil, fmt.Errorf("interval must be between 1 and %d milliseconds", maxMQTTInterval)
+	case opts.QoS < 0 || opts.QoS > 2:
+		return opts, nil, errors.New("qos must be 0, 1 or 2")
+	case opts.Latency == "":
+		return opts, nil, errors.New("latency_threshold is empty")
+	}
+
+	var warnings []string
+	if u.User != nil {
+		if _, ok := u.User.Password(); ok {
+			opts.Broker = strings.Replace(opts.Broker, u.User.String()+"@", "", 1)
+			warnings = append(warnings, "The broker URL contains credentials, so the script does not: "+
+				"set MQTT_BROKER in env_file with them")
+		}
+	}
+	if opts.Topic == "" {
+		opts.Topic = map[string]string{
+			mqttRoundtrip: "devices/{device}/telemetry",
+			mqttPublish:   "devices/{device}/telemetry",
+			mqttSubscribe: "devices/{device}/commands",
+			mqttFanOut:    "devices/broadcast",
+		}[opts.Pattern]
+	}
+	if opts.Pattern == mqttFanOut && strings.Contains(opts.Topic, "{device}") {
+		return opts, nil, errors.New("fan_out devices share one topic: remove {device} from topic")
+	}
+	if strings.ContainsAny(opts.Topic, "+#") && opts.Pattern != mqttSubscribe {
+		return opts, nil, errors.New("topic must not contain the + and # wildcards: devices publish to it")
+	}
+	if opts.Pattern == mqttSubscribe {
+		warnings = append(warnings, "Subscribing devices expect another system to publish to their topic "+
+			"during the test; the script fails when a device receives nothing")
+	}
+	return opts, warnings, nil
+}
+
+// mqttScript writes the script of opts.
+func mqttScript(opts mqttScriptOptions) string {
+	publish := opts.Pattern != mqttSubscribe
+	receive := opts.Pattern != mqttPublish
+	// Roundtrip and fan_out devices know the messages they must receive.
+	expect := opts.Pattern == mqttRoundtrip || opts.Pattern == mqttFanOut
+
+	w := &codeWriter{}
+	w.line("// Generated by mcp-k6 for MQTT devices. Run it with a k6 binary built with xk6-mqtt:")
+	w.line("//   " + xk6BuildCommand([]string{extensionModule(mqttModule)}))
+	w.line("import { Client } from 'k6/x/mqtt';")
+	var timers []string
+	if publish {
+		timers = append(timers, "setInterval", "clearInterval")
+	}
+	if receive {
+		timers = append(timers, "setTimeout")
+	}
+	w.line(fmt.Sprintf("import { %s } from 'k6/timers';", strings.Join(timers, ", ")))
+	var metrics []string
+	metrics = append(metrics, "Counter")
+	if receive {
+		metrics = append(metrics, "Trend")
+	}
+	w.line(fmt.Sprintf("import { %s } from 'k6/metrics';", strings.Join(metrics, ", ")))
+	w.line("")
+
+	w.open("export const options = {")
+	w.open("scenarios: {")
+	if opts.Pattern == mqttFanOut {
+		w.line(fmt.Sprintf("subscribers: { executor: 'per-vu-iterations', vus: %d, iterations: 1, "+
+			"exec: 'subscriber' },", opts.Devices))
+		w.line("// The publisher starts once the subscribers are connected.")
+		w.line("publisher: { executor: 'per-vu-iterations', vus: 1, iterations: 1, exec: 'publisher', " +
+			"startTime: '5s' },")
+	} else {
+		w.line(fmt.Sprintf("devices: { executor: 'per-vu-iterations', vus: %d, iterations: 1 },", opts.Devices))
+	}
+	w.close("},")
+	w.open("thresholds: {")
+	w.line("mqtt_errors: ['count == 0'],")
+	if receive {
+		w.line(fmt.Sprintf("mqtt_message_latency: [%s],", jsString(opts.Latency)))
+	}
+	if expect {
+		w.line("mqtt_messages_lost: ['count == 0'],")
+	} else if receive {
+		w.line("mqtt_messages_received: ['count > 0'],")
+	}
+	w.close("},")
+	w.close("};")
+	w.line("")
+
+	w.line(fmt.Sprintf("const BROKER = __ENV.MQTT_BROKER || %s;", jsString(opts.Broker)))
+	w.line(fmt.Sprintf("const TOPIC = __ENV.MQTT_TOPIC || %s;", jsString(opts.Topic)))
+	w.line(fmt.Sprintf("const QOS = %d;", opts.QoS))
+	w.line("// MESSAGES is the number of messages each publisher sends, INTERVAL milliseconds apart.")
+	w.line(fmt.Sprintf("const MESSAGES = %d;", opts.Messages))
+	w.line(fmt.Sprintf("const INTERVAL = %d;", opts.Interval))
+	if receive {
+		w.line("// WAIT is how long devices wait for the last messages, in milliseconds.")
+		w.line("const WAIT = 5000;")
+	}
+	w.line("")
+
+	w.line("const errors = new Counter('mqtt_errors');")
+	if publish {
+		w.line("const sent = new Counter('mqtt_messages_sent');")
+	}
+	if receive {
+		w.line("const received = new Counter('mqtt_messages_received');")
+		w.line("const latency = new Trend('mqtt_message_latency', true);")
+	}
+	if expect {
+		w.line("const lost = new Counter('mqtt_messages_lost');")
+	}
+	w.line("")
+
+	switch opts.Pattern {
+	case mqttPublish:
+		w.open("export default function () {")
+		mqttClient(w, "`device-${__VU}`")
+		w.open("client.on('connect', () => {")
+		w.line("send(client, device, topic, () => client.end());")
+		w.close("});")
+		w.line("client.connect(BROKER);")
+		w.close("}")
+	case mqttRoundtrip:
+		w.open("export default function () {")
+		mqttClient(w, "`device-${__VU}`")
+		mqttReceiver(w, "device")
+		w.open("client.on('connect', () => {")
+		w.line("client.subscribe(topic, { qos: QOS });")
+		w.line("send(client, device, topic, () => setTimeout(() => {")
+		w.line("  lost.add(MESSAGES - count);")
+		w.line("  client.end();")
+		w.line("}, WAIT));")
+		w.close("});")
+		w.line("client.connect(BROKER);")
+		w.close("}")
+	case mqttSubscribe:
+		w.open("export default function () {")
+		mqttClient(w, "`device-${__VU}`")
+		mqttReceiver(w, "")
+		w.open("client.on('connect', () => {")
+		w.line("client.subscribe(topic, { qos: QOS });")
+		w.line("setTimeout(() => client.end(), MESSAGES * INTERVAL + WAIT);")
+		w.close("});")
+		w.line("client.connect(BROKER);")
+		w.close("}")
+	case mqttFanOut:
+		w.open("export function subscriber() {")
+		mqttClient(w, "`device-${__VU}`")
+		mqttReceiver(w, "'publisher'")
+		w.open("client.on('connect', () => {")
+		w.line("client.subscribe(topic, { qos: QOS });")
+		w.line("// Wait for the publisher to start, send its messages and deliver the last ones.")
+		w.line("setTimeout(() => {")
+		w.line("  lost.add(MESSAGES - count);")
+		w.line("  client.end();")
+		w.line("}, 5000 + MESSAGES * INTERVAL + WAIT);")
+		w.close("});")
+		w.line("client.connect(BROKER);")
+		w.close("}")
+		w.line("")
+		w.open("export function publisher() {")
+		mqttClient(w, "'publisher'")
+		w.open("client.on('connect', () => {")
+		w.line("send(client, device, topic, () => client.end());")
+		w.close("});")
+		w.line("client.connect(BROKER);")
+		w.close("}")
+	}
+
+	if publish {
+		w.line("")
+		w.line("// send publishes MESSAGES messages of device to topic, then calls done.")
+		w.open("function send(client, device, topic, done) {")
+		w.line("let seq = 0;")
+		w.open("const timer = setInterval(() => {")
+		w.line("client.publish(topic, JSON.stringify(message(device, seq)), { qos: QOS });")
+		w.line("sent.add(1);")
+		w.open("if (++seq === MESSAGES) {")
+		w.line("clearInterval(timer);")
+		w.line("done();")
+		w.close("}")
+		w.close("}, INTERVAL);")
+		w.close("}")
+		w.line("")
+		w.line("// message returns the seqth message of device. Replace the telemetry with the")
+		w.line("// payload of your devices, keeping device and sentAt to measure the latency.")
+		w.open("function message(device, seq) {")
+		w.line("return { device, seq, sentAt: Date.now(), temperature: 20 + Math.random() * 5 };")
+		w.close("}")
+	}
+	if receive {
+		w.line("")
+		w.line("// decode returns the JSON object of a message payload, or null.")
+		w.open("function decode(payload) {")
+		w.open("try {")
+		w.line("return JSON.parse(String.fromCharCode.apply(null, new Uint8Array(payload)));")
+		w.close("} catch (e) {")
+		w.depth++
+		w.line("return null;")
+		w.close("}")
+		w.close("}")
+	}
+	return w.String()
+}
+
+// mqttClient writes the creation of the client of device, which counts and
+// logs errors.
+func mqttClient(w *codeWriter, device string) {
+	w.line("const device = " + device + ";")
+	w.line("const topic = TOPIC.replace('{device}', device);")
+	w.line("const client = new Client();")
+	w.open("client.on('error', (e) => {")
+	w.line("errors.add(1);")
+	w.line("console.error(`${device}: ${e}`);")
+	w.line("client.end();")
+	w.close("});")
+}
+
+// mqttReceiver writes the message handler of a client, which records the
+// messages of sender, counting them in count, or of any sender when it is
+// empty.
+func mqttReceiver(w *codeWriter, sender string) {
+	if sender != "" {
+		w.line("let count = 0;")
+	}
+	w.open("client.on('message', (_, payload) => {")
+	w.line("const body = decode(payload);")
+	if sender != "" {
+		w.open(fmt.Sprintf("if (body === null || body.device !== %s) {", sender))
+		w.line("return;")
+		w.close("}")
+	}
+	w.line("received.add(1);")
+	if sender != "" {
+		w.line("count++;")
+		w.line("latency.add(Date.now() - body.sentAt);")
+	} else {
+		w.open("if (body !== null && typeof body.sentAt === 'number') {")
+		w.line("latency.add(Date.now() - body.sentAt);")
+		w.close("}")
+	}
+	w.close("});")
+}
@@ -0,0 +1,94 @@
+package tools
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/grafana/mcp-k6/internal/summary"
+)
+
+// RunNotification is the JSON payload posted to the configured webhooks
+// when a background or scheduled run ends. Text makes it a valid Slack
+// incoming-webhook message.
+type RunNotification struct {
+	Text     string `json:"text"`
+	RunID    string `json:"run_id"`
+	Schedule string `json:"schedule_id,omitempty"`
+	// Script is the file name of a workspace script, or "inline".
+	Script   string `json:"script"`
+	State    string `json:"state"`
+	Success  bool   `json:"success"`
+	ExitCode int    `json:"exit_code"`
+	Elapsed  string `json:"elapsed"`
+	// ThresholdsPassed is nil when the script has no thresholds.
+	ThresholdsPassed *bool               `json:"thresholds_passed,omitempty"`
+	Thresholds       []summary.Threshold `json:"thresholds,omitempty"`
+	EarlyExit        *EarlyExit          `json:"early_exit,omitempty"`
+	Error            string              `json:"error,omitempty"`
+}
+
+// runNotification describes an ended run. It leaves out the output of the
+// run, which the webhook receivers should not see.
+func runNotification(run *BackgroundRun) RunNotification {
+	n := RunNotification{
+		RunID:    run.ID,
+		Schedule: run.Schedule,
+		Script:   run.Script,
+		State:    run.State(),
+		Elapsed:  summarizeRun(run).Elapsed,
+	}
+	if n.Script != "inline" {
+		n.Script = filepath.Base(n.Script)
+	}
+
+	result, err := run.Result()
+	switch {
+	case err != nil:
+		n.Error = err.Error()
+	case result != nil:
+		n.Success, n.ExitCode, n.Error = result.Success, result.ExitCode, result.Error
+		n.Thresholds = summary.Thresholds(result.Stdout)
+		n.EarlyExit = result.EarlyExit
+	}
+	if len(n.Thresholds) > 0 {
+		passed := true
+		for _, th := range n.Thresholds {
+			passed = passed && th.Passed
+		}
+		n.ThresholdsPassed = &passed
+	}
+	n.Text = notificationText(n)
+	return n
+}
+
+// notificationText summarizes a run in one line, such as
+// "k6 run run-3 (schedule-1) of api.js finished in 31s: thresholds passed".
+func notificationText(n RunNotification) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "k6 run %s", n.RunID)
+	if n.Schedule != "" {
+		fmt.Fprintf(&b, " (%s)", n.Schedule)
+	}
+	fmt.Fprintf(&b, " of %s %s in %s", n.Script, n.State, n.Elapsed)
+
+	switch {
+	case n.EarlyExit != nil:
+		fmt.Fprintf(&b, ": aborted, thresholds crossed on %s", strings.Join(n.EarlyExit.Metrics, ", "))
+	case n.ThresholdsPassed == nil:
+		if n.Error != "" {
+			fmt.Fprintf(&b, ": %s", n.Error)
+		}
+	case *n.ThresholdsPassed:
+		b.WriteString(": thresholds passed")
+	default:
+		var failed []string
+		for _, th := range n.Thresholds {
+			if !th.Passed {
+				failed = append(failed, strings.TrimSpace(th.Metric+" "+th.Expression))
+			}
+		}
+		fmt.Fprintf(&b, ": thresholds failed (%s)", strings.Join(failed, "; "))
+	}
+	return b.String()
+}
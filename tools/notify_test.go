@@ -0,0 +1,76 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/grafana/mcp-k6/internal/webhook"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunNotification(t *testing.T) {
+	t.Parallel()
+
+	received := make(chan RunNotification, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		var n RunNotification
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&n))
+		received <- n
+	}))
+	t.Cleanup(srv.Close)
+	notifier, err := webhook.New([]string{srv.URL})
+	require.NoError(t, err)
+
+	runs := NewRuns(notifier, nil, nil)
+	runs.execute = func(context.Context, string, *RunOptions) (*RunResult, error) {
+		return &RunResult{
+			ExitCode: ThresholdsExitCode,
+			Error:    "k6 test failed with exit code 99",
+			Stdout: "  █ THRESHOLDS\n\n    http_req_duration\n    ✗ 'p(95)<500' p(95)=612ms\n\n" +
+				"    checks\n    ✓ 'rate==1' rate=100.00%\n\n  █ TOTAL RESULTS\n",
+		}, nil
+	}
+	t.Cleanup(runs.Close)
+
+	_, err = runs.start(context.Background(), testRunScript, &RunOptions{ScriptPath: "/work/tests/api.js"}, "schedule-2")
+	require.NoError(t, err)
+
+	var n RunNotification
+	select {
+	case n = <-received:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no notification received")
+	}
+	assert.Equal(t, "run-1", n.RunID)
+	assert.Equal(t, "schedule-2", n.Schedule)
+	assert.Equal(t, "api.js", n.Script)
+	assert.Equal(t, "failed", n.State)
+	assert.Equal(t, ThresholdsExitCode, n.ExitCode)
+	require.NotNil(t, n.ThresholdsPassed)
+	assert.False(t, *n.ThresholdsPassed)
+	assert.Len(t, n.Thresholds, 2)
+	assert.Contains(t, n.Text, "k6 run run-1 (schedule-2) of api.js failed in ")
+	assert.Contains(t, n.Text, ": thresholds failed (http_req_duration p(95)<500)")
+}
+
+func TestNotificationText(t *testing.T) {
+	t.Parallel()
+
+	passed := true
+	assert.Equal(t, "k6 run run-4 of inline finished in 30s: thresholds passed", notificationText(RunNotification{
+		RunID: "run-4", Script: "inline", State: "finished", Elapsed: "30s", ThresholdsPassed: &passed,
+	}))
+	assert.Equal(t, "k6 run run-5 of inline failed in 2s: k6 test failed", notificationText(RunNotification{
+		RunID: "run-5", Script: "inline", State: "failed", Elapsed: "2s", Error: "k6 test failed",
+	}))
+	assert.Equal(t, "k6 run run-6 of a.js failed in 12s: aborted, thresholds crossed on http_req_failed",
+		notificationText(RunNotification{
+			RunID: "run-6", Script: "a.js", State: "failed", Elapsed: "12s",
+			EarlyExit: &EarlyExit{Metrics: []string{"http_req_failed"}},
+		}))
+}
@@ -0,0 +1,54 @@
+package tools
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/grafana/mcp-k6/internal/observe"
+)
+
+// observedRise is the ratio of the mean during a run to the baseline before
+// it from which a series is pointed out as reacting to the load.
+const observedRise = 1.5
+
+// observationNextSteps points at the server-side series that rose with the
+// load of the run, and at the queries that failed.
+func observationNextSteps(r *observe.Report) []string {
+	if r == nil {
+		return nil
+	}
+	var steps, rising []string
+	for _, s := range r.Series {
+		if s.Baseline == nil || s.During == nil || *s.During <= *s.Baseline*observedRise || *s.During == 0 {
+			continue
+		}
+		rising = append(rising, fmt.Sprintf("%s%s from %g to %g (peak %g at %gs)",
+			s.Query, seriesLabels(s.Labels), *s.Baseline, *s.During, s.Max, s.PeakAt))
+	}
+	if len(rising) > 0 {
+		steps = append(steps, "Server-side metrics rose with the load: "+strings.Join(rising, "; ")+
+			". Line their peaks up with the latency and errors of the run to find the bottleneck")
+	} else if len(r.Series) > 0 {
+		steps = append(steps, "Compare the baseline and during means of the observations with the results of "+
+			"the run: series that stay flat under load rule out their component as the bottleneck")
+	}
+	if len(r.Errors) > 0 {
+		steps = append(steps, fmt.Sprintf("%d observe queries failed or were cut (see observations.errors); "+
+			"check them against the datasource in Grafana Explore", len(r.Errors)))
+	}
+	return steps
+}
+
+// seriesLabels formats the labels of a series as a PromQL selector.
+func seriesLabels(labels map[string]string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	pairs := make([]string, 0, len(labels))
+	for k, v := range labels {
+		pairs = append(pairs, fmt.Sprintf("%s=%q", k, v))
+	}
+	slices.Sort(pairs)
+	return "{" + strings.Join(pairs, ",") + "}"
+}
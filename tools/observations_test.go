@@ -0,0 +1,29 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/grafana/mcp-k6/internal/observe"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestObservationNextSteps(t *testing.T) {
+	t.Parallel()
+
+	assert.Nil(t, observationNextSteps(nil))
+
+	baseline, during, flat := 0.2, 1.2, 0.25
+	steps := observationNextSteps(&observe.Report{
+		Series: []observe.Series{
+			{Query: "cpu", Labels: map[string]string{"job": "api"}, Baseline: &baseline, During: &during,
+				Max: 1.6, PeakAt: 15},
+			{Query: "db_cpu", Baseline: &baseline, During: &flat},
+		},
+		Errors: []string{"error_logs: loki: parse error"},
+	})
+	require.Len(t, steps, 2)
+	assert.Contains(t, steps[0], `cpu{job="api"} from 0.2 to 1.2 (peak 1.6 at 15s)`)
+	assert.NotContains(t, steps[0], "db_cpu")
+	assert.Contains(t, steps[1], "1 observe queries failed")
+}
@@ -0,0 +1,195 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"strings"
+
+	"github.com/grafana/mcp-k6/internal/logging"
+	"github.com/grafana/mcp-k6/internal/openapi"
+	"github.com/grafana/mcp-k6/internal/workspace"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// OpenAPICoverageTool exposes a tool for comparing script endpoints with an OpenAPI spec.
+//
+//nolint:gochecknoglobals // Shared tool definition registered at startup.
+var OpenAPICoverageTool = mcp.NewTool(
+	"openapi_coverage",
+	readOnlyTool(),
+	mcp.WithDescription(
+		"Compare the HTTP requests made by k6 scripts with the operations declared in an OpenAPI 3 or "+
+			"Swagger 2 spec (JSON or YAML) and return a coverage matrix: which operations are load tested, "+
+			"by which script lines, and which are not. Requests that match no operation are listed too.",
+	),
+	mcp.WithString(
+		"spec",
+		mcp.Description("The OpenAPI document content. Provide either spec or spec_path."),
+	),
+	mcp.WithString(
+		"spec_path",
+		mcp.Description("Path to the OpenAPI document inside a workspace root."),
+	),
+	mcp.WithString(
+		"path",
+		mcp.Description("A script file or directory of scripts inside a workspace root."),
+	),
+	mcp.WithString(
+		"script",
+		mcp.Description("Inline script content to check instead of a path."),
+	),
+)
+
+// maxSpecSize bounds the size of OpenAPI documents and GraphQL schemas read
+// from the workspace.
+const maxSpecSize = 10 * 1024 * 1024
+
+// RegisterOpenAPICoverageTool registers the openapi_coverage tool with the MCP server.
+func RegisterOpenAPICoverageTool(s *server.MCPServer, ws *workspace.Workspace) {
+	s.AddTool(OpenAPICoverageTool, withToolLogger("openapi_coverage", newOpenAPICoverageHandlerFunc(ws)))
+}
+
+// operationCoverage is one row of the coverage matrix.
+type operationCoverage struct {
+	openapi.Operation
+	Covered      bool     `json:"covered"`
+	Calls        int      `json:"calls"`
+	CheckedCalls int      `json:"checked_calls"`
+	Locations    []string `json:"locations,omitempty"`
+}
+
+// openAPICoverageResponse is the JSON structure returned by the tool.
+type openAPICoverageResponse struct {
+	Title             string              `json:"title,omitempty"`
+	Version           string              `json:"version,omitempty"`
+	TotalOperations   int                 `json:"total_operations"`
+	CoveredOperations int                 `json:"covered_operations"`
+	CoveragePercent   float64             `json:"coverage_percent"`
+	Uncovered         []string            `json:"uncovered"`
+	Operations        []operationCoverage `json:"operations"`
+	Unmatched         []inventoryEntry    `json:"unmatched_requests,omitempty"`
+	FilesScanned      int                 `json:"files_scanned"`
+	Skipped           []string            `json:"skipped,omitempty"`
+}
+
+// newOpenAPICoverageHandlerFunc returns an MCP tool handler bound to a workspace.
+func newOpenAPICoverageHandlerFunc(ws *workspace.Workspace) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		logger := logging.LoggerFromContext(ctx)
+
+		data, err := readDocumentArgument(ctx, ws, request, "spec")
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		spec, err := openapi.Parse(data)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		scripts, skipped, _, err := readScriptsArgument(ctx, ws, request)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		endpoints, _ := buildInventory(scripts)
+
+		resp := coverageMatrix(spec, endpoints)
+		resp.FilesScanned = len(scripts)
+		resp.Skipped = skipped
+
+		logger.InfoContext(ctx, "OpenAPI coverage computed",
+			slog.Int("operations", resp.TotalOperations),
+			slog.Int("covered", resp.CoveredOperations),
+			slog.Int("unmatched", len(resp.Unmatched)))
+
+		return marshalResponse(ctx, logger, resp)
+	}
+}
+
+// readDocumentArgument returns the document given by the param parameter, such
+// as spec, or read from the file at param_path.
+func readDocumentArgument(
+	ctx context.Context, ws *workspace.Workspace, request mcp.CallToolRequest, param string,
+) ([]byte, error) {
+	content := request.GetString(param, "")
+	path := request.GetString(param+"_path", "")
+	switch {
+	case content != "" && path != "":
+		return nil, fmt.Errorf("provide only one of '%s' and '%s_path'", param, param)
+	case content != "":
+		return []byte(content), nil
+	case path == "":
+		return nil, fmt.Errorf("provide either '%s' (document content) or '%s_path' (a file in the workspace)",
+			param, param)
+	case ws == nil:
+		return nil, workspace.ErrNoRoots
+	}
+
+	data, resolved, err := ws.ReadFile(ctx, path, maxSpecSize)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s_path: %w", param, err)
+	}
+	logging.FileOperation(ctx, "workspace", "read_"+param, resolved, nil)
+	return data, nil
+}
+
+// coverageMatrix matches the inventory against the operations of the spec.
+func coverageMatrix(spec *openapi.Spec, endpoints []inventoryEntry) openAPICoverageResponse {
+	resp := openAPICoverageResponse{
+		Title:           spec.Title,
+		Version:         spec.Version,
+		TotalOperations: len(spec.Operations),
+		Operations:      make([]operationCoverage, len(spec.Operations)),
+		Uncovered:       []string{},
+	}
+	for i, op := range spec.Operations {
+		resp.Operations[i].Operation = op
+	}
+
+	for _, e := range endpoints {
+		if e.Protocol != "http" {
+			continue
+		}
+		i := spec.Match(e.Method, requestPath(e.Pattern))
+		if i < 0 {
+			resp.Unmatched = append(resp.Unmatched, e)
+			continue
+		}
+		row := &resp.Operations[i]
+		row.Covered = true
+		row.Calls += e.Calls
+		row.CheckedCalls += e.Checked
+		row.Locations = append(row.Locations, e.Locations...)
+	}
+
+	for _, row := range resp.Operations {
+		if row.Covered {
+			resp.CoveredOperations++
+		} else {
+			resp.Uncovered = append(resp.Uncovered, row.Method+" "+row.Path)
+		}
+	}
+	resp.CoveragePercent = math.Round(float64(resp.CoveredOperations)/float64(resp.TotalOperations)*1000) / 10
+	return resp
+}
+
+// requestPath extracts the path from an endpoint pattern. Absolute URLs lose
+// their scheme and host, and a leading base URL expression such as
+// {BASE_URL} is dropped.
+func requestPath(pattern string) string {
+	if _, rest, ok := strings.Cut(pattern, "://"); ok {
+		if i := strings.IndexByte(rest, '/'); i >= 0 {
+			return rest[i:]
+		}
+		return "/"
+	}
+	if strings.HasPrefix(pattern, "{") {
+		if i := strings.IndexByte(pattern, '/'); i >= 0 {
+			return pattern[i:]
+		}
+		return "/"
+	}
+	return pattern
+}
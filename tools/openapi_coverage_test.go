@@ -0,0 +1,71 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenAPICoverageHandler(t *testing.T) {
+	t.Parallel()
+
+	spec := `{"openapi": "3.0.0", "info": {"title": "Shop", "version": "2"},
+  "servers": [{"url": "https://shop.example.com/api"}],
+  "paths": {
+    "/products": {"get": {"operationId": "listProducts"}},
+    "/products/{productId}": {"get": {"operationId": "getProduct"}, "delete": {"operationId": "deleteProduct"}},
+    "/orders": {"post": {"operationId": "createOrder"}}
+  }}`
+	script := "import http from 'k6/http';\nimport { check } from 'k6';\n" +
+		"export default function () {\n" +
+		"  const res = http.get('https://shop.example.com/api/products');\n" +
+		"  check(res, { ok: (r) => r.status === 200 });\n" +
+		"  http.get(`${BASE_URL}/products/${id}`);\n" +
+		"  http.get('https://shop.example.com/api/health');\n" +
+		"}\n"
+
+	handler := newOpenAPICoverageHandlerFunc(nil)
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]any{"spec": spec, "script": script}
+	result, err := handler(context.Background(), req)
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+
+	var resp openAPICoverageResponse
+	text, ok := result.Content[0].(mcp.TextContent)
+	require.True(t, ok)
+	require.NoError(t, json.Unmarshal([]byte(text.Text), &resp))
+
+	assert.Equal(t, "Shop", resp.Title)
+	assert.Equal(t, 4, resp.TotalOperations)
+	assert.Equal(t, 2, resp.CoveredOperations)
+	assert.InDelta(t, 50.0, resp.CoveragePercent, 0.01)
+	assert.Equal(t, []string{"POST /orders", "DELETE /products/{productId}"}, resp.Uncovered)
+	require.Len(t, resp.Unmatched, 1)
+	assert.Equal(t, "https://shop.example.com/api/health", resp.Unmatched[0].Pattern)
+
+	for _, row := range resp.Operations {
+		if row.OperationID == "listProducts" {
+			assert.Equal(t, 1, row.CheckedCalls)
+			assert.Equal(t, []string{"script:4"}, row.Locations)
+		}
+	}
+
+	req.Params.Arguments = map[string]any{"script": script}
+	result, err = handler(context.Background(), req)
+	require.NoError(t, err)
+	assert.True(t, result.IsError, "a spec is required")
+}
+
+func TestRequestPath(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "/v1/users/{id}", requestPath("https://api.example.com/v1/users/{id}"))
+	assert.Equal(t, "/", requestPath("https://api.example.com"))
+	assert.Equal(t, "/users", requestPath("{BASE_URL}/users"))
+	assert.Equal(t, "/users", requestPath("/users"))
+}
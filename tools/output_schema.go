@@ -0,0 +1,61 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+
+	"github.com/invopop/jsonschema"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// outputSchema returns a tool option declaring the JSON schema of the
+// structured content a tool returns: the schema of the type of the single
+// response, or any of the types of the responses, for tools whose result
+// depends on the call. Nested types are referenced from $defs, as the
+// documentation tree is recursive.
+func outputSchema(responses ...any) mcp.ToolOption {
+	reflector := jsonschema.Reflector{
+		ExpandedStruct:            true,
+		Anonymous:                 true,
+		AllowAdditionalProperties: true,
+	}
+	defs := jsonschema.Definitions{}
+	schemas := make([]*jsonschema.Schema, 0, len(responses))
+	for _, resp := range responses {
+		schema := reflector.Reflect(resp)
+		// References resolve against the root, so definitions are kept there
+		for name, def := range schema.Definitions {
+			defs[name] = def
+		}
+		schema.Definitions = nil
+		schema.Version = ""
+		schemas = append(schemas, schema)
+	}
+	schema := schemas[0]
+	if len(schemas) > 1 {
+		// MCP requires an object schema at the top level
+		schema = &jsonschema.Schema{Type: "object", AnyOf: schemas}
+	}
+	if len(defs) > 0 {
+		schema.Definitions = defs
+	}
+	data, err := json.Marshal(schema)
+	if err != nil {
+		// Declare no schema, as mcp.WithOutputSchema does
+		return func(*mcp.Tool) {}
+	}
+	return mcp.WithRawOutputSchema(data)
+}
+
+// structuredResponse returns v as the structured content of the result, for
+// tools declaring an outputSchema, along with its JSON as text for clients
+// that do not read structured content.
+func structuredResponse(ctx context.Context, logger *slog.Logger, v any) (*mcp.CallToolResult, error) {
+	result, err := marshalResponse(ctx, logger, v)
+	if err != nil {
+		return nil, err
+	}
+	result.StructuredContent = v
+	return result, nil
+}
@@ -0,0 +1,163 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"testing"
+
+	"github.com/grafana/xk6-docs/docs"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutputSchemas(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		tool      mcp.Tool
+		responses []any
+	}{
+		{tool: InfoTool, responses: []any{InfoResponse{Version: "v1.0.0", K6Version: "v1.4.0"}}},
+		{tool: ValidateTool, responses: []any{
+			ValidationResponse{Valid: true, Summary: ValidationSummary{Status: "success"}},
+			importPolicyResponse(nil, []ImportViolation{{Module: "https://cdn.example.com/lib.js", Line: 1}}),
+		}},
+		{tool: RunTool, responses: []any{
+			RunResult{Success: true, Metrics: map[string]any{"vus": 1}, EarlyExit: &EarlyExit{Metrics: []string{}}},
+			runResponse{runSummary: runSummary{RunID: "run-1", State: "running"}, NextSteps: []string{"get_run"}},
+			confirmationResponse{Status: "requires_confirmation", Reasons: []string{"100 VUs"}, NextSteps: []string{}},
+		}},
+		{tool: ListSectionsTool, responses: []any{
+			buildListSectionsResponse("v1.4.x", []string{"v1.4.x"}, listSectionsParams{Depth: 1},
+				collectRoots(func(func(int, *docs.Tree) bool) {}), 0),
+			buildListSectionsResponse("v1.4.x", []string{"v1.4.x"}, listSectionsParams{Depth: 2}, []*treeItem{
+				{Slug: "using-k6", Title: "Using k6", Children: []*treeItem{{Slug: "using-k6/scenarios"}}},
+			}, 2),
+			versionsResponse{Versions: []string{"v1.4.x"}, Latest: "v1.4.x"},
+		}},
+		{tool: GetCategoryTool, responses: []any{getCategoryResponse{
+			Section:           responseSection{Hierarchy: []string{}},
+			Pages:             []categoryPage{{Slug: "using-k6", Title: "Using k6", Bytes: 10}},
+			AvailableVersions: []string{"v1.4.x"},
+		}}},
+		{tool: GetDocumentationTool, responses: []any{getDocResponse{
+			Section:           responseSection{Hierarchy: hierarchyFromRelPath("index.md")},
+			AvailableVersions: []string{"v1.4.x"},
+		}}},
+	} {
+		t.Run(tc.tool.Name, func(t *testing.T) {
+			t.Parallel()
+
+			data, err := json.Marshal(tc.tool)
+			require.NoError(t, err)
+			var decoded struct {
+				OutputSchema map[string]any `json:"outputSchema"`
+			}
+			require.NoError(t, json.Unmarshal(data, &decoded))
+			schema := decoded.OutputSchema
+			require.NotNil(t, schema)
+			assert.Equal(t, "object", schema["type"])
+
+			for _, resp := range tc.responses {
+				require.NoError(t, conforms(schema, schema, roundTrip(t, resp)), "%+v", resp)
+			}
+			require.Error(t, conforms(schema, schema, map[string]any{"version": 1}))
+		})
+	}
+}
+
+func TestStructuredResponse(t *testing.T) {
+	t.Parallel()
+
+	resp := InfoResponse{Version: "v1.0.0", K6Version: "v1.4.0", LoggedIn: true}
+	result, err := structuredResponse(context.Background(), slog.Default(), resp)
+	require.NoError(t, err)
+	assert.Equal(t, resp, result.StructuredContent)
+	require.Len(t, result.Content, 1)
+	text, ok := result.Content[0].(mcp.TextContent)
+	require.True(t, ok)
+	assert.JSONEq(t, `{"version": "v1.0.0", "k6_version": "v1.4.0", "logged_in": true}`, text.Text)
+}
+
+func roundTrip(t *testing.T, v any) any {
+	t.Helper()
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+	var out any
+	require.NoError(t, json.Unmarshal(data, &out))
+	return out
+}
+
+// conforms checks value against the subset of JSON Schema the reflected
+// output schemas use.
+func conforms(root, schema map[string]any, value any) error {
+	if ref, ok := schema["$ref"].(string); ok {
+		defs, _ := root["$defs"].(map[string]any)
+		def, ok := defs[ref[len("#/$defs/"):]].(map[string]any)
+		if !ok {
+			return fmt.Errorf("unresolved %s", ref)
+		}
+		return conforms(root, def, value)
+	}
+	if anyOf, ok := schema["anyOf"].([]any); ok {
+		var errs []error
+		for _, alt := range anyOf {
+			err := conforms(root, alt.(map[string]any), value)
+			if err == nil {
+				return nil
+			}
+			errs = append(errs, err)
+		}
+		return fmt.Errorf("no alternative matches: %v", errs)
+	}
+
+	switch schema["type"] {
+	case "object":
+		obj, ok := value.(map[string]any)
+		if !ok {
+			return fmt.Errorf("%v is not an object", value)
+		}
+		required, _ := schema["required"].([]any)
+		for _, name := range required {
+			if _, ok := obj[name.(string)]; !ok {
+				return fmt.Errorf("missing %s", name)
+			}
+		}
+		props, _ := schema["properties"].(map[string]any)
+		for name, v := range obj {
+			if prop, ok := props[name].(map[string]any); ok {
+				if err := conforms(root, prop, v); err != nil {
+					return fmt.Errorf("%s: %w", name, err)
+				}
+			}
+		}
+	case "array":
+		items, ok := value.([]any)
+		if !ok {
+			return fmt.Errorf("%v is not an array", value)
+		}
+		if itemSchema, ok := schema["items"].(map[string]any); ok {
+			for _, item := range items {
+				if err := conforms(root, itemSchema, item); err != nil {
+					return err
+				}
+			}
+		}
+	case "string":
+		if _, ok := value.(string); !ok {
+			return fmt.Errorf("%v is not a string", value)
+		}
+	case "integer", "number":
+		if _, ok := value.(float64); !ok {
+			return fmt.Errorf("%v is not a number", value)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("%v is not a boolean", value)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/grafana/mcp-k6/internal/ownership"
+	"github.com/grafana/mcp-k6/internal/scriptinfo"
+	"github.com/grafana/mcp-k6/internal/targetpolicy"
+	"github.com/grafana/mcp-k6/internal/workspace"
+)
+
+// verifyOwnership returns an error unless every host the script, or a local
+// module it imports, sends requests to publishes the ownership token of ov.
+// Runs starting up to the VUs ov lets through are not checked.
+func verifyOwnership(
+	ctx context.Context,
+	ov *ownership.Verifier,
+	ws *workspace.Workspace,
+	script string,
+	options *RunOptions,
+	vus int,
+) error {
+	if !ov.Applies(vus) {
+		return nil
+	}
+
+	type origin struct{ scheme, host string }
+	var origins []origin
+	var problems []string
+	seen := make(map[string]bool)
+	modules := checkoutWorkspace(ws, options.Checkout)
+	walkModules(ctx, modules, script, options.ScriptPath, options.DataFiles, func(file string, info *scriptinfo.Info) {
+		for _, e := range info.Endpoints {
+			scheme, host, ok := targetpolicy.Origin(e.URL, e.Dynamic, options.Env)
+			if !ok {
+				where := fmt.Sprintf("line %d", e.Line)
+				if file != "" {
+					where = fmt.Sprintf("%s:%d", file, e.Line)
+				}
+				problems = append(problems, fmt.Sprintf("the host of %s (%s) cannot be read statically; "+
+					"use a literal URL or an __ENV variable set by env_file", e.URL, where))
+				continue
+			}
+			if !seen[host] {
+				seen[host] = true
+				origins = append(origins, origin{scheme: scheme, host: host})
+			}
+		}
+	})
+	for _, o := range origins {
+		if err := ov.Verify(ctx, o.scheme, o.host); err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	return fmt.Errorf("runs above %d VUs may only target hosts that verify their ownership: %s",
+		ov.AboveVUs(), strings.Join(problems, "; "))
+}
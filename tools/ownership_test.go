@@ -0,0 +1,55 @@
+package tools
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grafana/mcp-k6/internal/ownership"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyOwnership(t *testing.T) {
+	t.Parallel()
+
+	const token = "0123456789abcdef"
+	owned := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == ownership.WellKnownPath {
+			_, _ = w.Write([]byte("k6-verify=" + token + "\n"))
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	t.Cleanup(owned.Close)
+	other := httptest.NewServer(http.NotFoundHandler())
+	t.Cleanup(other.Close)
+
+	ov, err := ownership.New(token, 1)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	script := `import http from 'k6/http';
+export default function () {
+  http.get('` + owned.URL + `/health');
+  http.get(` + "`${__ENV.BASE_URL}/users`" + `);
+}
+`
+	options := &RunOptions{Env: map[string]string{"BASE_URL": owned.URL}}
+	require.NoError(t, verifyOwnership(ctx, ov, nil, script, options, 10))
+
+	options.Env["BASE_URL"] = other.URL
+	err = verifyOwnership(ctx, ov, nil, script, options, 10)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "runs above 1 VUs")
+	assert.Contains(t, err.Error(), other.URL+ownership.WellKnownPath)
+
+	err = verifyOwnership(ctx, ov, nil, script, &RunOptions{}, 10)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot be read statically")
+
+	// Trivial load, or no verifier, is never checked
+	require.NoError(t, verifyOwnership(ctx, ov, nil, script, options, 1))
+	require.NoError(t, verifyOwnership(ctx, nil, nil, script, options, 10))
+}
@@ -0,0 +1,502 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/mcp-k6/internal/logging"
+	"github.com/grafana/mcp-k6/internal/scenario"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// ModelPacingTool exposes a tool for turning a per-user pacing model into
+// executor settings and think-time code.
+//
+//nolint:gochecknoglobals // Shared tool definition registered at startup.
+var ModelPacingTool = mcp.NewTool(
+	"model_pacing",
+	readOnlyTool(),
+	mcp.WithDescription(
+		"Turn a per-user pacing model, such as 30 iterations per hour for each of 200 users with lognormal "+
+			"think times, into k6 executor settings and sleep code. Chooses between the closed model "+
+			"(constant-vus: a fixed population of users, each pacing its iterations) and the open model "+
+			"(constant-arrival-rate: iterations start at the target rate whatever the response times), "+
+			"explaining the choice, estimates the VUs the open model needs with Little's law, and reports "+
+			"targets the iterations are too long to reach.",
+	),
+	mcp.WithNumber(
+		"users",
+		mcp.Required(),
+		mcp.Description("Concurrent users to simulate."),
+	),
+	mcp.WithNumber(
+		"iterations",
+		mcp.Required(),
+		mcp.Description("Iterations each user runs per 'per', e.g. 30 for 30 iterations per hour."),
+	),
+	mcp.WithString(
+		"per",
+		mcp.Description("Optional: the period of 'iterations', e.g. '1h' or '1m' (default: '1h')."),
+	),
+	mcp.WithNumber(
+		"iteration_time",
+		mcp.Description("Optional: seconds an iteration spends in requests, without think time (default: 1)."),
+	),
+	mcp.WithNumber(
+		"think_steps",
+		mcp.Description("Optional: pauses for think time in each iteration, e.g. one per page (default: 1)."),
+	),
+	mcp.WithString(
+		"think_distribution",
+		mcp.Description("Optional: distribution of each think time (default: lognormal)."),
+		mcp.Enum(thinkConstant, thinkUniform, thinkNormal, thinkLognormal, thinkExponential),
+	),
+	mcp.WithNumber(
+		"think_mean",
+		mcp.Description("Optional: mean think time in seconds (default: 5)."),
+	),
+	mcp.WithNumber(
+		"think_stddev",
+		mcp.Description("Optional, normal and lognormal: standard deviation in seconds (default: half the mean)."),
+	),
+	mcp.WithNumber(
+		"think_min",
+		mcp.Description("Optional: lower bound of think times in seconds (default: 0, half the mean for uniform)."),
+	),
+	mcp.WithNumber(
+		"think_max",
+		mcp.Description("Optional: upper bound of think times in seconds (default: 4 times the mean, "+
+			"1.5 times for uniform)."),
+	),
+	mcp.WithString(
+		"model",
+		mcp.Description("Optional: 'open', 'closed', or 'auto' (default) to choose from the model."),
+		mcp.Enum(pacingAuto, pacingOpen, pacingClosed),
+	),
+	mcp.WithString(
+		"duration",
+		mcp.Description("Optional: test duration (default: '30m')."),
+	),
+)
+
+// Think time distributions and workload models of model_pacing.
+const (
+	thinkConstant    = "constant"
+	thinkUniform     = "uniform"
+	thinkNormal      = "normal"
+	thinkLognormal   = "lognormal"
+	thinkExponential = "exponential"
+
+	pacingAuto   = "auto"
+	pacingOpen   = "open"
+	pacingClosed = "closed"
+)
+
+const (
+	// closedModelBusy is the share of the pacing interval an iteration must
+	// fill for auto to choose the closed model: users busy most of the time
+	// behave as a fixed population, while users idle between visits arrive
+	// as an open stream.
+	closedModelBusy = 0.5
+	// z95 is the 95th percentile of the standard normal distribution.
+	z95 = 1.6449
+)
+
+// RegisterModelPacingTool registers the model_pacing tool with the MCP server.
+func RegisterModelPacingTool(s *server.MCPServer) {
+	s.AddTool(ModelPacingTool, withToolLogger("model_pacing", modelPacing))
+}
+
+// thinkTime is a think time distribution, in seconds.
+type thinkTime struct {
+	Distribution string  `json:"distribution"`
+	Mean         float64 `json:"mean"`
+	StdDev       float64 `json:"stddev,omitempty"`
+	Min          float64 `json:"min"`
+	Max          float64 `json:"max"`
+	// P50 and P95 are the median and 95th percentile before the bounds.
+	P50 float64 `json:"p50"`
+	P95 float64 `json:"p95"`
+}
+
+// modelPacingResponse is the JSON structure returned by the tool.
+type modelPacingResponse struct {
+	Model       string `json:"model"`
+	ModelReason string `json:"model_reason"`
+	// PacingSeconds is the time between the iteration starts of a user.
+	PacingSeconds float64 `json:"pacing_seconds"`
+	// IterationSeconds is the expected length of an iteration, think time
+	// included, and Utilization its share of the pacing interval.
+	IterationSeconds float64 `json:"iteration_seconds"`
+	Utilization      float64 `json:"utilization"`
+	// ArrivalRate is the iterations all users start per second.
+	ArrivalRate float64 `json:"arrival_rate"`
+	// Concurrency is the expected number of running iterations.
+	Concurrency float64        `json:"concurrency"`
+	ThinkTime   thinkTime      `json:"think_time"`
+	Options     map[string]any `json:"options"`
+	Script      string         `json:"script"`
+	PeakVUs     int            `json:"peak_vus"`
+	Warnings    []string       `json:"warnings,omitempty"`
+	NextSteps   []string       `json:"next_steps"`
+}
+
+// pacingModel is the workload a pacing script simulates.
+type pacingModel struct {
+	Users      int
+	Iterations float64
+	Per        string
+	PerSeconds float64
+	// Active is the time an iteration spends in requests, in seconds.
+	Active   float64
+	Steps    int
+	Think    thinkTime
+	Model    string
+	Duration string
+}
+
+func modelPacing(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger := logging.LoggerFromContext(ctx)
+
+	m, err := pacingArguments(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	pacing := m.PerSeconds / m.Iterations
+	busy := m.Active + float64(m.Steps)*m.Think.Mean
+	utilization := busy / pacing
+	arrival := float64(m.Users) * m.Iterations / m.PerSeconds
+	concurrency := arrival * busy
+
+	var warnings []string
+	reason := fmt.Sprintf("The %s model was requested; iterations fill %.0f%% of the %s pacing interval.",
+		m.Model, utilization*100, formatSecs(pacing))
+	if m.Model == pacingAuto {
+		if utilization >= closedModelBusy {
+			m.Model = pacingClosed
+			reason = fmt.Sprintf("Users are busy %.0f%% of the time, like a fixed population of concurrent "+
+				"sessions: the closed model keeps %d users, each pacing its iterations.", utilization*100, m.Users)
+		} else {
+			m.Model = pacingOpen
+			reason = fmt.Sprintf("Users are idle %.0f%% of the time between iterations, so they arrive as an "+
+				"open stream: the open model starts %s iterations per second whatever the response times, "+
+				"and does not slow down with the system under test.", (1-utilization)*100, formatRate(arrival))
+		}
+	}
+	if m.Model == pacingClosed && utilization > 1 {
+		warnings = append(warnings, fmt.Sprintf("An iteration takes about %s, longer than the %s pacing "+
+			"interval: the closed model falls short of the target rate. Shorten the think time, or use the open "+
+			"model with more VUs.", formatSecs(busy), formatSecs(pacing)))
+	}
+
+	sc, scWarnings, err := pacingScenario(m, arrival, concurrency)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	warnings = append(warnings, scWarnings...)
+	if peak := sc.PeakVUs(); peak > MaxVUs {
+		warnings = append(warnings, fmt.Sprintf(
+			"the scenario may use %d VUs, more than the %d run_script allows", peak, MaxVUs))
+	}
+	options := map[string]any{"scenarios": map[string]any{"users": sc}}
+	script, err := pacingScript(m, pacing, options)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.InfoContext(ctx, "Pacing modeled",
+		slog.String("model", m.Model),
+		slog.Float64("utilization", utilization),
+		slog.Int("peak_vus", sc.PeakVUs()))
+
+	return marshalResponse(ctx, logger, modelPacingResponse{
+		Model:            m.Model,
+		ModelReason:      reason,
+		PacingSeconds:    round3(pacing),
+		IterationSeconds: round3(busy),
+		Utilization:      round3(utilization),
+		ArrivalRate:      round3(arrival),
+		Concurrency:      round3(concurrency),
+		ThinkTime:        m.Think,
+		Options:          options,
+		Script:           script,
+		PeakVUs:          sc.PeakVUs(),
+		Warnings:         warnings,
+		NextSteps: []string{
+			"Replace the placeholder steps of the default function with the requests of your script, " +
+				"keeping a sleep(thinkTime()) after each step",
+			"Measure the real iteration_time with validate_script and model again if it differs",
+			"See docs://k6/best_practices for think time, pacing and scenario guidance",
+		},
+	})
+}
+
+// pacingArguments reads and checks the parameters of request.
+func pacingArguments(request mcp.CallToolRequest) (pacingModel, error) {
+	m := pacingModel{
+		Users:      request.GetInt("users", 0),
+		Iterations: request.GetFloat("iterations", 0),
+		Per:        strings.TrimSpace(request.GetString("per", "1h")),
+		Active:     request.GetFloat("iteration_time", 1),
+		Steps:      request.GetInt("think_steps", 1),
+		Model:      request.GetString("model", pacingAuto),
+		Duration:   strings.TrimSpace(request.GetString("duration", "30m")),
+	}
+	per, err := time.ParseDuration(m.Per)
+	switch {
+	case m.Users < 1:
+		return m, errors.New("users must be at least 1")
+	case m.Iterations <= 0:
+		return m, errors.New("iterations must be positive")
+	case err != nil || per <= 0:
+		return m, fmt.Errorf("invalid per %q: expected a duration such as 1h", m.Per)
+	case m.Active < 0:
+		return m, errors.New("iteration_time must not be negative")
+	case m.Steps < 0:
+		return m, errors.New("think_steps must not be negative")
+	case m.Model != pacingAuto && m.Model != pacingOpen && m.Model != pacingClosed:
+		return m, fmt.Errorf("invalid model %q: use 'auto', 'open' or 'closed'", m.Model)
+	}
+	m.PerSeconds = per.Seconds()
+
+	think, err := thinkArguments(request)
+	if err != nil {
+		return m, err
+	}
+	m.Think = think
+	return m, nil
+}
+
+// thinkArguments reads the think time distribution of request.
+func thinkArguments(request mcp.CallToolRequest) (thinkTime, error) {
+	t := thinkTime{
+		Distribution: request.GetString("think_distribution", thinkLognormal),
+		Mean:         request.GetFloat("think_mean", 5),
+	}
+	if t.Mean < 0 {
+		return t, errors.New("think_mean must not be negative")
+	}
+	minDefault, maxDefault := 0.0, 4*t.Mean
+	switch t.Distribution {
+	case thinkConstant:
+		minDefault, maxDefault = t.Mean, t.Mean
+	case thinkUniform:
+		minDefault, maxDefault = t.Mean/2, 1.5*t.Mean
+	case thinkNormal, thinkLognormal:
+		t.StdDev = request.GetFloat("think_stddev", t.Mean/2)
+		if t.StdDev < 0 {
+			return t, errors.New("think_stddev must not be negative")
+		}
+	case thinkExponential:
+	default:
+		return t, fmt.Errorf("invalid think_distribution %q: use 'constant', 'uniform', 'normal', "+
+			"'lognormal' or 'exponential'", t.Distribution)
+	}
+	t.Min = request.GetFloat("think_min", minDefault)
+	t.Max = request.GetFloat("think_max", maxDefault)
+	if t.Min < 0 || t.Max < t.Min {
+		return t, errors.New("think_min and think_max must satisfy 0 <= think_min <= think_max")
+	}
+	if t.Distribution == thinkUniform {
+		t.Mean = (t.Min + t.Max) / 2
+	}
+	if t.Distribution == thinkLognormal && t.Mean == 0 {
+		return t, errors.New("think_mean must be positive for the lognormal distribution")
+	}
+
+	switch t.Distribution {
+	case thinkConstant:
+		t.P50, t.P95 = t.Mean, t.Mean
+	case thinkUniform:
+		t.P50, t.P95 = t.Min+0.5*(t.Max-t.Min), t.Min+0.95*(t.Max-t.Min)
+	case thinkNormal:
+		t.P50, t.P95 = t.Mean, t.Mean+z95*t.StdDev
+	case thinkLognormal:
+		mu, sigma := lognormalParams(t.Mean, t.StdDev)
+		t.P50, t.P95 = math.Exp(mu), math.Exp(mu+z95*sigma)
+	case thinkExponential:
+		t.P50, t.P95 = t.Mean*math.Ln2, t.Mean*math.Log(20)
+	}
+	t.P50, t.P95 = round3(t.P50), round3(t.P95)
+	return t, nil
+}
+
+// lognormalParams returns the parameters of the normal distribution whose
+// exponential has the mean and standard deviation.
+func lognormalParams(mean, stddev float64) (mu, sigma float64) {
+	variance := math.Log(1 + stddev*stddev/(mean*mean))
+	return math.Log(mean) - variance/2, math.Sqrt(variance)
+}
+
+// pacingScenario builds the scenario of m.
+func pacingScenario(m pacingModel, arrival, concurrency float64) (scenario.Scenario, []string, error) {
+	p := scenario.Params{Duration: m.Duration}
+	executor := "constant-vus"
+	if m.Model == pacingClosed {
+		p.VUs = &m.Users
+	} else {
+		executor = "constant-arrival-rate"
+		rate, unit := arrivalRate(arrival, m)
+		// Little's law gives the mean; VUs absorb its variance and slower responses.
+		pre := max(1, int(math.Ceil(concurrency*1.2)))
+		maxVUs := max(pre+1, int(math.Ceil(concurrency*2)))
+		p.Rate, p.TimeUnit, p.PreAllocatedVUs, p.MaxVUs = &rate, unit, &pre, &maxVUs
+	}
+	sc, warnings, err := scenario.Build(executor, p)
+	if err != nil {
+		return sc, nil, fmt.Errorf("invalid scenario: %w", err)
+	}
+	return sc, warnings, nil
+}
+
+// arrivalRate returns the whole rate and time unit closest to arrival, in
+// iterations per second, preferring the period of the model.
+func arrivalRate(arrival float64, m pacingModel) (int, string) {
+	total := float64(m.Users) * m.Iterations
+	if total == math.Trunc(total) {
+		return int(total), m.Per
+	}
+	return wholeRate(arrival)
+}
+
+// wholeRate returns a whole rate and the shortest time unit keeping it
+// within 1% of perSecond, falling back to hours.
+func wholeRate(perSecond float64) (int, string) {
+	for _, unit := range []struct {
+		name    string
+		seconds float64
+	}{{"1s", 1}, {"1m", 60}, {"1h", 3600}} {
+		rate := math.Round(perSecond * unit.seconds)
+		if rate >= 10 && math.Abs(rate-perSecond*unit.seconds)/(perSecond*unit.seconds) < 0.01 {
+			return int(rate), unit.name
+		}
+	}
+	return max(1, int(math.Round(perSecond*3600))), "1h"
+}
+
+// pacingScript writes a script skeleton running the model.
+func pacingScript(m pacingModel, pacing float64, options map[string]any) (string, error) {
+	data, err := json.MarshalIndent(options, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal scenario: %w", err)
+	}
+	closed := m.Model == pacingClosed
+
+	w := &codeWriter{}
+	w.line(fmt.Sprintf("// Generated by mcp-k6 for %d users running %s iterations per %s (%s model).",
+		m.Users, strconv.FormatFloat(m.Iterations, 'f', -1, 64), m.Per, m.Model))
+	w.line("import { sleep } from 'k6';")
+	if closed {
+		w.line("import { Counter } from 'k6/metrics';")
+	}
+	w.line("")
+	w.line("export const options = " + string(data) + ";")
+	w.line("")
+	if closed {
+		w.line(fmt.Sprintf("// PACING is the seconds between the iteration starts of a user: %s / %s.",
+			m.Per, strconv.FormatFloat(m.Iterations, 'f', -1, 64)))
+		w.line(fmt.Sprintf("const PACING = %s;", jsNumber(pacing)))
+		w.line("")
+		w.line("// pacingMissed counts the iterations that took longer than PACING.")
+		w.line("const pacingMissed = new Counter('pacing_missed');")
+		w.line("")
+	}
+	thinkFunction(w, m.Think)
+	w.line("")
+
+	w.open("export default function () {")
+	if closed {
+		w.line("const start = Date.now();")
+		w.line("")
+	}
+	for i := 1; i <= max(1, m.Steps); i++ {
+		w.line(fmt.Sprintf("// Step %d: the requests of the step.", i))
+		if i <= m.Steps {
+			w.line("sleep(thinkTime());")
+		}
+	}
+	if closed {
+		w.line("")
+		w.line("// Wait out the rest of the pacing interval, so each user keeps its rate.")
+		w.line("const elapsed = (Date.now() - start) / 1000;")
+		w.open("if (elapsed > PACING) {")
+		w.line("pacingMissed.add(1);")
+		w.close("}")
+		w.line("sleep(Math.max(0, PACING - elapsed));")
+	} else {
+		w.line("// No pacing sleep: the arrival-rate executor starts the iterations on time.")
+	}
+	w.close("}")
+	return w.String(), nil
+}
+
+// thinkFunction writes thinkTime(), sampling t.
+func thinkFunction(w *codeWriter, t thinkTime) {
+	bounds := fmt.Sprintf("between %ss and %ss", jsNumber(t.Min), jsNumber(t.Max))
+	switch t.Distribution {
+	case thinkConstant:
+		w.line(fmt.Sprintf("// thinkTime returns the think time, %ss.", jsNumber(t.Mean)))
+	case thinkUniform:
+		w.line(fmt.Sprintf("// thinkTime returns a think time uniform %s.", bounds))
+	case thinkExponential:
+		w.line(fmt.Sprintf("// thinkTime returns an exponential think time with mean %ss, %s.",
+			jsNumber(t.Mean), bounds))
+	default:
+		w.line(fmt.Sprintf("// thinkTime returns a %s think time with mean %ss and standard deviation %ss,",
+			t.Distribution, jsNumber(t.Mean), jsNumber(t.StdDev)))
+		w.line("// " + bounds + ".")
+	}
+	w.open("function thinkTime() {")
+	switch t.Distribution {
+	case thinkConstant:
+		w.line(fmt.Sprintf("return %s;", jsNumber(t.Mean)))
+		w.close("}")
+		return
+	case thinkUniform:
+		w.line(fmt.Sprintf("return %s + Math.random() * %s;", jsNumber(t.Min), jsNumber(t.Max-t.Min)))
+		w.close("}")
+		return
+	case thinkExponential:
+		w.line(fmt.Sprintf("const t = -%s * Math.log(1 - Math.random());", jsNumber(t.Mean)))
+	case thinkNormal:
+		w.line("// Box-Muller transform of two uniform samples.")
+		w.line("const z = Math.sqrt(-2 * Math.log(1 - Math.random())) * Math.cos(2 * Math.PI * Math.random());")
+		w.line(fmt.Sprintf("const t = %s + %s * z;", jsNumber(t.Mean), jsNumber(t.StdDev)))
+	case thinkLognormal:
+		mu, sigma := lognormalParams(t.Mean, t.StdDev)
+		w.line("// Box-Muller transform of two uniform samples.")
+		w.line("const z = Math.sqrt(-2 * Math.log(1 - Math.random())) * Math.cos(2 * Math.PI * Math.random());")
+		w.line(fmt.Sprintf("const t = Math.exp(%s + %s * z);", jsNumber(mu), jsNumber(sigma)))
+	}
+	w.line(fmt.Sprintf("return Math.min(%s, Math.max(%s, t));", jsNumber(t.Max), jsNumber(t.Min)))
+	w.close("}")
+}
+
+// jsNumber formats f with at most 4 decimals.
+func jsNumber(f float64) string {
+	return strconv.FormatFloat(math.Round(f*10000)/10000, 'f', -1, 64)
+}
+
+// round3 rounds f to 3 decimals.
+func round3(f float64) float64 {
+	return math.Round(f*1000) / 1000
+}
+
+// formatSecs formats a number of seconds for messages.
+func formatSecs(s float64) string {
+	return jsNumber(math.Round(s*10)/10) + "s"
+}
+
+// formatRate formats an arrival rate for messages.
+func formatRate(r float64) string {
+	return strconv.FormatFloat(r, 'g', 3, 64)
+}
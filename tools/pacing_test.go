@@ -0,0 +1,133 @@
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModelPacingOpen(t *testing.T) {
+	t.Parallel()
+
+	result, err := modelPacing(context.Background(), newCallRequest(map[string]any{
+		"users":          float64(200),
+		"iterations":     float64(30),
+		"iteration_time": float64(2),
+		"think_steps":    float64(3),
+	}))
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+
+	var resp modelPacingResponse
+	decodeJSON(t, result, &resp)
+	assert.Equal(t, pacingOpen, resp.Model)
+	assert.InDelta(t, 120, resp.PacingSeconds, 0.001)
+	assert.InDelta(t, 17, resp.IterationSeconds, 0.001)
+	assert.InDelta(t, 6000.0/3600, resp.ArrivalRate, 0.001)
+	assert.Contains(t, resp.Script, `"executor": "constant-arrival-rate"`)
+	assert.Contains(t, resp.Script, `"rate": 6000`)
+	assert.Contains(t, resp.Script, `"timeUnit": "1h"`)
+	// Little's law: 1.67 iterations per second of 17s each keep 28.3 VUs busy.
+	assert.Contains(t, resp.Script, `"preAllocatedVUs": 34`)
+	assert.Contains(t, resp.Script, `"maxVUs": 57`)
+	assert.Equal(t, 57, resp.PeakVUs)
+	assert.Contains(t, resp.Script, "Math.exp(")
+	assert.Equal(t, 3, strings.Count(resp.Script, "sleep(thinkTime());"))
+	assert.NotContains(t, resp.Script, "PACING")
+	assert.InDelta(t, 4.472, resp.ThinkTime.P50, 0.001)
+	require.Len(t, resp.Warnings, 1)
+	assert.Contains(t, resp.Warnings[0], "more than the 50 run_script allows")
+}
+
+func TestModelPacingClosed(t *testing.T) {
+	t.Parallel()
+
+	result, err := modelPacing(context.Background(), newCallRequest(map[string]any{
+		"users":              float64(20),
+		"iterations":         float64(6),
+		"per":                "1m",
+		"iteration_time":     float64(4),
+		"think_distribution": "uniform",
+		"think_min":          float64(2),
+		"think_max":          float64(4),
+	}))
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+
+	var resp modelPacingResponse
+	decodeJSON(t, result, &resp)
+	assert.Equal(t, pacingClosed, resp.Model)
+	assert.InDelta(t, 0.7, resp.Utilization, 0.001)
+	assert.InDelta(t, 3, resp.ThinkTime.Mean, 0.001)
+	assert.Contains(t, resp.Script, `"executor": "constant-vus"`)
+	assert.Contains(t, resp.Script, `"vus": 20`)
+	assert.Contains(t, resp.Script, "const PACING = 10;")
+	assert.Contains(t, resp.Script, "sleep(Math.max(0, PACING - elapsed));")
+	assert.Contains(t, resp.Script, "return 2 + Math.random() * 2;")
+	assert.Empty(t, resp.Warnings)
+}
+
+func TestModelPacingOverbooked(t *testing.T) {
+	t.Parallel()
+
+	result, err := modelPacing(context.Background(), newCallRequest(map[string]any{
+		"users":      float64(10),
+		"iterations": float64(10),
+		"per":        "1m",
+		"think_mean": float64(8),
+		"model":      "closed",
+	}))
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+
+	var resp modelPacingResponse
+	decodeJSON(t, result, &resp)
+	assert.Contains(t, resp.ModelReason, "closed model was requested")
+	require.Len(t, resp.Warnings, 1)
+	assert.Contains(t, resp.Warnings[0], "longer than the 6s pacing interval")
+}
+
+func TestModelPacingErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		args map[string]any
+		want string
+	}{
+		{map[string]any{"iterations": float64(1)}, "users must be at least 1"},
+		{map[string]any{"users": float64(1)}, "iterations must be positive"},
+		{map[string]any{"users": float64(1), "iterations": float64(1), "per": "hourly"}, "invalid per"},
+		{
+			map[string]any{"users": float64(1), "iterations": float64(1), "think_distribution": "pareto"},
+			"invalid think_distribution",
+		},
+		{
+			map[string]any{"users": float64(1), "iterations": float64(1), "think_max": float64(-1)},
+			"think_min and think_max",
+		},
+	}
+	for _, tt := range tests {
+		result, err := modelPacing(context.Background(), newCallRequest(tt.args))
+		require.NoError(t, err)
+		require.True(t, result.IsError)
+		assert.Contains(t, result.Content[0].(mcp.TextContent).Text, tt.want)
+	}
+}
+
+func TestThinkArgumentsLognormal(t *testing.T) {
+	t.Parallel()
+
+	think, err := thinkArguments(newCallRequest(map[string]any{
+		"think_mean":   float64(5),
+		"think_stddev": float64(5),
+	}))
+	require.NoError(t, err)
+	// A lognormal with a coefficient of variation of 1 has its median at mean/sqrt(2).
+	assert.InDelta(t, 3.536, think.P50, 0.001)
+	assert.Greater(t, think.P95, think.Mean*2)
+	assert.InDelta(t, 20, think.Max, 0.001)
+}
@@ -0,0 +1,190 @@
+package tools
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"strings"
+
+	"github.com/grafana/mcp-k6/internal/audit"
+	"github.com/grafana/mcp-k6/internal/importpolicy"
+	"github.com/grafana/mcp-k6/internal/k6opts"
+	"github.com/grafana/mcp-k6/internal/loadprofile"
+	"github.com/grafana/mcp-k6/internal/logging"
+	"github.com/grafana/mcp-k6/internal/scriptinfo"
+	"github.com/grafana/mcp-k6/internal/secrets"
+	"github.com/grafana/mcp-k6/internal/security"
+	"github.com/grafana/mcp-k6/internal/targetpolicy"
+	"github.com/grafana/mcp-k6/internal/workspace"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// PlanRunTool exposes a tool for auditing a run_script request without executing it.
+//
+//nolint:gochecknoglobals // Shared tool definition registered at startup.
+var PlanRunTool = mcp.NewTool(
+	"plan_run",
+	append([]mcp.ToolOption{
+		readOnlyTool(),
+		mcp.WithDescription(
+			"Show exactly what run_script would execute for the same parameters, without running k6: " +
+				"the command line, the environment k6 sees, the script variables passed with --env, " +
+				"the effective options after k6's precedence rules, and whether validation would reject the request.",
+		),
+	}, runParameters()...)...,
+)
+
+// inlineScriptPlaceholder stands in for the temporary file inline scripts are written to.
+const inlineScriptPlaceholder = "<temporary-file>.js"
+
+// RegisterPlanRunTool registers the plan_run tool with the MCP server.
+func RegisterPlanRunTool(
+	s *server.MCPServer,
+	ws *workspace.Workspace,
+	reg *secrets.Registry,
+	ip *importpolicy.Policy,
+	tp *targetpolicy.Policy,
+) {
+	s.AddTool(PlanRunTool, withToolLogger("plan_run", newPlanRunHandlerFunc(ws, reg, ip, tp)))
+}
+
+// runPlan is the JSON structure returned by the tool.
+type runPlan struct {
+	Command string   `json:"command"`
+	Args    []string `json:"args"`
+	Script  string   `json:"script"`
+	// ProcessEnv lists the environment variables the k6 process inherits.
+	ProcessEnv []string `json:"process_env"`
+	// ScriptEnv lists the variables exposed to the script as __ENV. Values
+	// are not echoed back.
+	ScriptEnv []string `json:"script_env,omitempty"`
+	// DataFiles lists the files staged next to the script.
+	DataFiles []string `json:"data_files,omitempty"`
+	// Secrets lists the server secrets exported to the k6 process.
+	Secrets   []string       `json:"secrets,omitempty"`
+	Timeout   string         `json:"timeout"`
+	Valid     bool           `json:"valid"`
+	Error     string         `json:"error,omitempty"`
+	Effective *k6opts.Result `json:"effective"`
+	// LoadProfile charts the VUs or arrival rate the run would apply.
+	LoadProfile []string `json:"load_profile,omitempty"`
+	// EntryModule is the generated script k6 runs instead of Script when
+	// the call sets thresholds or abort_on_fail.
+	EntryModule string `json:"entry_module,omitempty"`
+}
+
+// newPlanRunHandlerFunc returns an MCP tool handler bound to a workspace.
+func newPlanRunHandlerFunc(
+	ws *workspace.Workspace,
+	reg *secrets.Registry,
+	ip *importpolicy.Policy,
+	tp *targetpolicy.Policy,
+) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		logger := logging.LoggerFromContext(ctx)
+
+		script, options, err := runRequest(ctx, ws, reg, ip, tp, nil, request)
+		if err != nil {
+			return requestError(err), nil
+		}
+		defer removeCheckout(ctx, options.Checkout)
+
+		plan := planRun(ctx, script, options)
+
+		logger.InfoContext(ctx, "Run planned",
+			slog.Bool("valid", plan.Valid),
+			slog.Int("args", len(plan.Args)))
+
+		return marshalResponse(ctx, logger, plan)
+	}
+}
+
+// planRun describes the k6 invocation RunK6Test would make.
+func planRun(ctx context.Context, script string, options *RunOptions) runPlan {
+	plan := runPlan{
+		Script:  options.ScriptPath,
+		Timeout: DefaultTimeout.String(),
+		Valid:   true,
+	}
+	switch {
+	case plan.Script != "":
+	case len(options.DataFiles) > 0:
+		plan.Script = runDirPlaceholder + "/" + runScriptName
+		for _, f := range options.DataFiles {
+			plan.DataFiles = append(plan.DataFiles, runDirPlaceholder+"/"+f.Name)
+		}
+	default:
+		plan.Script = inlineScriptPlaceholder
+	}
+	if err := validateRunInput(ctx, script, options); err != nil {
+		plan.Valid = false
+		plan.Error = err.Error()
+	}
+
+	target := plan.Script
+	if plan.Valid && needsEntryModule(options) {
+		entry, err := entryModule(plan.Script, script, options)
+		if err != nil {
+			plan.Valid = false
+			plan.Error = err.Error()
+		} else {
+			plan.EntryModule = entry
+			target = entryModulePlaceholder
+			if len(plan.DataFiles) > 0 {
+				target = runDirPlaceholder + "/" + entryModuleName
+			}
+		}
+	}
+
+	args := buildK6Args(target, options)
+	plan.Args = audit.RedactArgs(args)
+	plan.Command = "k6 " + strings.Join(quoteArgs(plan.Args), " ")
+
+	for _, kv := range security.SecureEnvironment() {
+		name, _, _ := strings.Cut(kv, "=")
+		plan.ProcessEnv = append(plan.ProcessEnv, name)
+	}
+	for name := range options.Env {
+		plan.ScriptEnv = append(plan.ScriptEnv, name)
+	}
+	sort.Strings(plan.ScriptEnv)
+	for name := range options.Secrets {
+		plan.Secrets = append(plan.Secrets, name)
+	}
+	sort.Strings(plan.Secrets)
+	plan.ProcessEnv = append(plan.ProcessEnv, plan.Secrets...)
+
+	var info *scriptinfo.Info
+	plan.Effective, info = effectiveOptions(script, args)
+	plan.LoadProfile = loadProfile(plan.Effective, info)
+
+	return plan
+}
+
+// effectiveOptions resolves the options k6 applies when run with args.
+// k6 only sees PATH and HOME, so no K6_* variables take part.
+func effectiveOptions(script string, args []string) (*k6opts.Result, *scriptinfo.Info) {
+	info := scriptinfo.Analyze(script)
+	cli, warnings := k6opts.FromFlags(args)
+	result := k6opts.Resolve(k6opts.FromScript(info), nil, cli)
+	result.Warnings = append(warnings, result.Warnings...)
+	return result, info
+}
+
+// loadProfile charts the load of the effective execution.
+func loadProfile(result *k6opts.Result, info *scriptinfo.Info) []string {
+	return loadprofile.Render(loadprofile.FromResult(result, info))
+}
+
+// quoteArgs single-quotes arguments that a POSIX shell would split or expand.
+func quoteArgs(args []string) []string {
+	out := make([]string, len(args))
+	for i, arg := range args {
+		if arg == "" || strings.ContainsAny(arg, " \t\n'\"$`\\*?[]{}()<>|&;#~!") {
+			arg = "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+		}
+		out[i] = arg
+	}
+	return out
+}
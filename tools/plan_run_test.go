@@ -0,0 +1,107 @@
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanRun(t *testing.T) {
+	t.Parallel()
+
+	script := `import http from 'k6/http';
+export const options = { scenarios: { api: { executor: 'constant-vus', vus: 5, duration: '1m' } } };
+export default function () { http.get('https://test.k6.io'); }
+`
+	plan := planRun(context.Background(), script, &RunOptions{
+		VUs:      2,
+		Duration: "10s",
+		Env:      map[string]string{"API_TOKEN": "secret", "BASE_URL": "https://test.k6.io"},
+	})
+
+	assert.True(t, plan.Valid)
+	assert.Equal(t, []string{
+		"run", "--vus", "2", "--duration", "10s",
+		"--env", "API_TOKEN=<redacted>", "--env", "BASE_URL=<redacted>",
+		inlineScriptPlaceholder,
+	}, plan.Args)
+	assert.Equal(t,
+		"k6 run --vus 2 --duration 10s --env 'API_TOKEN=<redacted>' --env 'BASE_URL=<redacted>' '<temporary-file>.js'",
+		plan.Command)
+	assert.NotContains(t, plan.Command, "secret")
+	assert.Equal(t, []string{"API_TOKEN", "BASE_URL"}, plan.ScriptEnv)
+	assert.Contains(t, plan.ProcessEnv, "PATH")
+
+	require.NotNil(t, plan.Effective)
+	assert.Equal(t, "constant-vus", plan.Effective.Execution.Executor)
+	require.Len(t, plan.Effective.Warnings, 1)
+	assert.Contains(t, plan.Effective.Warnings[0], "dropped scenarios=api (constant-vus) (script)")
+	require.NotEmpty(t, plan.LoadProfile)
+	assert.Equal(t, "default (constant-vus), VUs:", plan.LoadProfile[0])
+	assert.True(t, strings.HasSuffix(plan.LoadProfile[len(plan.LoadProfile)-1], "10s"))
+
+	plan = planRun(context.Background(), script, &RunOptions{VUs: 500, Duration: "10s"})
+	assert.False(t, plan.Valid)
+	assert.Contains(t, plan.Error, "vus cannot exceed")
+}
+
+func TestPlanRunPreview(t *testing.T) {
+	t.Parallel()
+
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]any{
+		"script":  "export default function () {}",
+		"vus":     20,
+		"preview": true,
+	}
+	script, options, err := runRequest(context.Background(), nil, nil, nil, nil, nil, req)
+	require.NoError(t, err)
+	assert.True(t, options.Preview)
+
+	plan := planRun(context.Background(), script, options)
+	assert.Equal(t, []string{"run", "--vus", "1", "--iterations", "1", "--http-debug=full", inlineScriptPlaceholder}, plan.Args)
+	assert.Equal(t, "shared-iterations", plan.Effective.Execution.Executor)
+	assert.Equal(t, []string{"default (shared-iterations): 1 VU(s) share 1 iteration(s)"}, plan.LoadProfile)
+}
+
+func TestPlanRunThresholds(t *testing.T) {
+	t.Parallel()
+
+	script := "export default function () {}\n"
+	plan := planRun(context.Background(), script, &RunOptions{
+		VUs:         1,
+		Duration:    "10s",
+		Thresholds:  map[string][]string{"checks": {"rate==1"}},
+		AbortOnFail: true,
+	})
+
+	assert.True(t, plan.Valid)
+	assert.Equal(t, inlineScriptPlaceholder, plan.Script)
+	assert.Equal(t, entryModulePlaceholder, plan.Args[len(plan.Args)-1])
+	assert.Contains(t, plan.EntryModule, `export * from "<temporary-file>.js";`)
+	assert.Contains(t, plan.EntryModule, `const overrides = {"checks":["rate==1"]};`)
+
+	plan = planRun(context.Background(), script, &RunOptions{VUs: 1, Duration: "10s", DelayAbortEval: "5s"})
+	assert.False(t, plan.Valid)
+	assert.Contains(t, plan.Error, "delay_abort_eval requires abort_on_fail")
+	assert.Empty(t, plan.EntryModule)
+}
+
+func TestPlanRunDataFiles(t *testing.T) {
+	t.Parallel()
+
+	plan := planRun(context.Background(), "export default function () {}\n", &RunOptions{
+		VUs:       1,
+		Duration:  "10s",
+		DataFiles: []DataFile{{Name: "users.csv", Content: "name\n"}},
+	})
+
+	assert.True(t, plan.Valid)
+	assert.Equal(t, "<run-dir>/script.js", plan.Script)
+	assert.Equal(t, []string{"<run-dir>/users.csv"}, plan.DataFiles)
+	assert.Equal(t, "<run-dir>/script.js", plan.Args[len(plan.Args)-1])
+}
@@ -0,0 +1,282 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/grafana/mcp-k6/internal/diff"
+	"github.com/grafana/mcp-k6/internal/logging"
+	"github.com/grafana/mcp-k6/internal/scriptinfo"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	// MaxRepairAttempts bounds the fixes validate_script asks the client's
+	// model for.
+	MaxRepairAttempts = 3
+
+	// repairTimeout bounds each sampling request, which the client may hold
+	// for its user to review.
+	repairTimeout = 5 * time.Minute
+	// repairMaxTokens bounds the length of a proposed fix.
+	repairMaxTokens = 16384
+	// maxRepairOutput bounds the k6 output quoted in a repair prompt.
+	maxRepairOutput = 4096
+)
+
+const repairAttemptsDescription = "Optional: when the script fails validation, ask the client's model for a " +
+	"fix through MCP sampling and validate it, up to this many times (default: 0, at most 3). Needs a client " +
+	"supporting sampling; the repaired script is returned, and workspace files are left unchanged."
+
+const repairSystemPrompt = "You fix k6 load test scripts that fail validation. Make the smallest change that " +
+	"fixes the reported errors, keeping the requests, checks and options of the script. Reply with the " +
+	"complete corrected script in a single ```javascript code block, and nothing else."
+
+// errNoSampling is returned by samplers of clients that do not support
+// sampling.
+var errNoSampling = errors.New("the client does not support MCP sampling")
+
+// sampleFunc asks the client's model for a completion.
+type sampleFunc func(ctx context.Context, request mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error)
+
+// clientSampler returns a sampleFunc sending sampling requests to the client
+// of the call through s, when it declared the sampling capability.
+func clientSampler(s *server.MCPServer) sampleFunc {
+	return func(ctx context.Context, request mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
+		session, ok := server.ClientSessionFromContext(ctx).(server.SessionWithClientInfo)
+		if !ok || session.GetClientCapabilities().Sampling == nil {
+			return nil, errNoSampling
+		}
+		return s.RequestSampling(ctx, request)
+	}
+}
+
+// RepairAttempt is a fix proposed by the client's model and its validation.
+type RepairAttempt struct {
+	Attempt int    `json:"attempt"`
+	Model   string `json:"model,omitempty"`
+	Valid   bool   `json:"valid"`
+	Error   string `json:"error,omitempty"`
+}
+
+// RepairReport tells how validate_script repaired a script, or tried to.
+type RepairReport struct {
+	Repaired bool            `json:"repaired"`
+	Attempts []RepairAttempt `json:"attempts"`
+	// Script is the repaired script and Patch the unified diff from the
+	// original, to pass to write_script or apply_patch.
+	Script string `json:"script,omitempty"`
+	Patch  string `json:"patch,omitempty"`
+}
+
+// repairAttemptsArgument returns the repair_attempts of a request.
+func repairAttemptsArgument(request mcp.CallToolRequest) (int, error) {
+	attempts := request.GetInt("repair_attempts", 0)
+	if attempts < 0 || attempts > MaxRepairAttempts {
+		return 0, fmt.Errorf("repair_attempts must be between 0 and %d, got %d", MaxRepairAttempts, attempts)
+	}
+	return attempts, nil
+}
+
+// repairScript asks sample for fixes of script, which failed validation with
+// result, and validates each with check, up to attempts times. It returns
+// the validation of the repaired script, or result when no fix passed, with
+// the repair report.
+func repairScript(
+	ctx context.Context,
+	sample sampleFunc,
+	check func(ctx context.Context, script string) (*ValidationResponse, error),
+	script, scriptPath string,
+	result *ValidationResponse,
+	attempts int,
+) *ValidationResponse {
+	logger := logging.LoggerFromContext(ctx)
+	report := &RepairReport{Attempts: []RepairAttempt{}}
+	result.Repair = report
+	if scriptPath != "" && importsLocalModules(script) {
+		result.NextSteps = append(result.NextSteps, "The script imports local modules, which a repaired copy "+
+			"validated inline cannot resolve: pass its content as script, with its modules inlined, to repair it")
+		return result
+	}
+
+	candidate, last := script, result
+	noSampling := false
+	for i := 1; i <= attempts && !noSampling && ctx.Err() == nil; i++ {
+		attempt := RepairAttempt{Attempt: i}
+		fixed, model, err := proposeFix(ctx, sample, candidate, last)
+		attempt.Model = model
+		if err != nil {
+			logger.WarnContext(ctx, "Script repair failed", slog.Int("attempt", i), slog.String("error", err.Error()))
+			attempt.Error = err.Error()
+			report.Attempts = append(report.Attempts, attempt)
+			noSampling = errors.Is(err, errNoSampling)
+			continue
+		}
+		validation, err := check(ctx, fixed)
+		if err != nil {
+			attempt.Error = err.Error()
+			report.Attempts = append(report.Attempts, attempt)
+			continue
+		}
+		attempt.Valid, attempt.Error = validation.Valid, validation.Error
+		report.Attempts = append(report.Attempts, attempt)
+		logger.InfoContext(ctx, "Script repair attempted", slog.Int("attempt", i), slog.Bool("valid", attempt.Valid))
+		candidate, last = fixed, validation
+		if validation.Valid {
+			report.Repaired, report.Script = true, fixed
+			report.Patch, _ = diff.Unified("a/script.js", "b/script.js", script, fixed, diff.DefaultContext)
+			validation.Repair = report
+			validation.NextSteps = append(validation.NextSteps, repairedNextStep(scriptPath, i))
+			return validation
+		}
+	}
+
+	switch {
+	case ctx.Err() != nil:
+	case noSampling:
+		result.NextSteps = append(result.NextSteps, "The client does not support MCP sampling: fix the "+
+			"script from the issues above and validate it again")
+	default:
+		result.NextSteps = append(result.NextSteps, fmt.Sprintf("No fix passed validation in %d attempts: "+
+			"see the errors of each in repair.attempts", len(report.Attempts)))
+	}
+	return result
+}
+
+// repairedNextStep suggests saving a script repaired in n attempts.
+func repairedNextStep(scriptPath string, n int) string {
+	if scriptPath != "" {
+		return fmt.Sprintf("The script was repaired in %d attempt(s): review repair.patch, then save "+
+			"repair.script to %s with write_script", n, scriptPath)
+	}
+	return fmt.Sprintf("The script was repaired in %d attempt(s): review repair.patch and use repair.script "+
+		"from now on", n)
+}
+
+// importsLocalModules reports whether script imports modules by path.
+func importsLocalModules(script string) bool {
+	for _, imp := range scriptinfo.Analyze(script).Imports {
+		if _, ok := localModulePath(".", imp.Module); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// proposeFix asks sample for a fix of script, which failed validation with
+// result, and returns it with the model that wrote it.
+func proposeFix(
+	ctx context.Context,
+	sample sampleFunc,
+	script string,
+	result *ValidationResponse,
+) (string, string, error) {
+	ctx, cancel := context.WithTimeout(ctx, repairTimeout)
+	defer cancel()
+	request := mcp.CreateMessageRequest{CreateMessageParams: mcp.CreateMessageParams{
+		Messages: []mcp.SamplingMessage{{
+			Role:    mcp.RoleUser,
+			Content: mcp.NewTextContent(repairPrompt(script, result)),
+		}},
+		SystemPrompt:   repairSystemPrompt,
+		IncludeContext: "none",
+		MaxTokens:      repairMaxTokens,
+	}}
+	reply, err := sample(ctx, request)
+	if err != nil {
+		return "", "", err
+	}
+	fixed := extractScript(samplingText(reply.Content))
+	switch {
+	case fixed == "":
+		return "", reply.Model, errors.New("the reply held no script")
+	case len(fixed) > MaxScriptSize:
+		return "", reply.Model, fmt.Errorf("the proposed script exceeds %d bytes", MaxScriptSize)
+	case strings.TrimSpace(fixed) == strings.TrimSpace(script):
+		return "", reply.Model, errors.New("the proposed script is unchanged")
+	}
+	return fixed, reply.Model, nil
+}
+
+// repairPrompt describes the failed validation of script to the model.
+func repairPrompt(script string, result *ValidationResponse) string {
+	var b strings.Builder
+	b.WriteString("This k6 script failed validation, a run with 1 VU for 1 iteration.\n\n")
+	if result.Error != "" {
+		fmt.Fprintf(&b, "Error: %s\n", result.Error)
+	}
+	for _, d := range result.Diagnostics {
+		kind := d.Kind
+		if kind == "" {
+			kind = "error"
+		}
+		fmt.Fprintf(&b, "- %s at %s: %s\n", kind, d.Location(), d.Message)
+	}
+	for _, issue := range result.Issues {
+		fmt.Fprintf(&b, "- %s issue: %s", issue.Type, issue.Message)
+		if issue.Suggestion != "" {
+			fmt.Fprintf(&b, " (%s)", issue.Suggestion)
+		}
+		b.WriteString("\n")
+	}
+	for _, fix := range result.Fixes {
+		fmt.Fprintf(&b, "- suggested fix at line %d: %s\n", fix.Line, fix.Description)
+	}
+	if out := strings.TrimSpace(result.Stderr); out != "" {
+		if len(out) > maxRepairOutput {
+			out = out[len(out)-maxRepairOutput:]
+		}
+		fmt.Fprintf(&b, "\nk6 output:\n```\n%s\n```\n", out)
+	}
+	fmt.Fprintf(&b, "\nScript:\n```javascript\n%s\n```\n", strings.TrimRight(script, "\n"))
+	return b.String()
+}
+
+// samplingText returns the text of the content of a sampling reply, which
+// arrives decoded as a map from the transports.
+func samplingText(content any) string {
+	switch c := content.(type) {
+	case mcp.TextContent:
+		return c.Text
+	case *mcp.TextContent:
+		return c.Text
+	case map[string]any:
+		if text, ok := c["text"].(string); ok && c["type"] == "text" {
+			return text
+		}
+	case string:
+		return c
+	}
+	return ""
+}
+
+// extractScript returns the first fenced code block of text, or text
+// itself when it has none.
+func extractScript(text string) string {
+	start := strings.Index(text, "```")
+	if start < 0 {
+		if text = strings.TrimSpace(text); text == "" {
+			return ""
+		}
+		return text + "\n"
+	}
+	body := text[start+3:]
+	// Skip the info string, such as javascript
+	if nl := strings.IndexByte(body, '\n'); nl >= 0 {
+		body = body[nl+1:]
+	} else {
+		return ""
+	}
+	if end := strings.Index(body, "```"); end >= 0 {
+		body = body[:end]
+	}
+	if body = strings.TrimSpace(body); body == "" {
+		return ""
+	}
+	return body + "\n"
+}
@@ -0,0 +1,136 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/grafana/mcp-k6/internal/diagnostics"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const brokenScript = "import http from 'k6/http';\nexport default function () { http.get('https://test.k6.io') \n"
+
+// fakeSampler returns a sampler replying with replies in turn, and records
+// the prompts it was sent.
+func fakeSampler(prompts *[]string, replies ...any) sampleFunc {
+	return func(_ context.Context, request mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
+		*prompts = append(*prompts, request.Messages[0].Content.(mcp.TextContent).Text)
+		if len(replies) == 0 {
+			return nil, errors.New("no more replies")
+		}
+		reply := replies[0]
+		replies = replies[1:]
+		if err, ok := reply.(error); ok {
+			return nil, err
+		}
+		return &mcp.CreateMessageResult{
+			SamplingMessage: mcp.SamplingMessage{Role: mcp.RoleAssistant, Content: reply},
+			Model:           "test-model",
+		}, nil
+	}
+}
+
+// fakeCheck passes scripts holding "valid".
+func fakeCheck(_ context.Context, script string) (*ValidationResponse, error) {
+	if strings.Contains(script, "valid") {
+		return &ValidationResponse{Valid: true}, nil
+	}
+	return &ValidationResponse{Error: "SyntaxError: Unexpected token"}, nil
+}
+
+func failedValidation() *ValidationResponse {
+	return &ValidationResponse{
+		Error:  "exit status 107",
+		Stderr: "SyntaxError: Unexpected end of input",
+		Diagnostics: []diagnostics.Diagnostic{
+			{File: "inline", Line: 3, Column: 1, Kind: "SyntaxError", Message: "Unexpected end of input"},
+		},
+	}
+}
+
+func TestRepairScript(t *testing.T) {
+	t.Parallel()
+
+	var prompts []string
+	sample := fakeSampler(&prompts,
+		mcp.NewTextContent("```javascript\nexport default function () { still broken\n```"),
+		// Replies decoded from the transports are maps
+		map[string]any{"type": "text", "text": "Here it is:\n```js\n// valid\nexport default function () {}\n```\n"},
+	)
+	result := repairScript(t.Context(), sample, fakeCheck, brokenScript, "", failedValidation(), MaxRepairAttempts)
+
+	assert.True(t, result.Valid)
+	require.NotNil(t, result.Repair)
+	assert.True(t, result.Repair.Repaired)
+	assert.Equal(t, "// valid\nexport default function () {}\n", result.Repair.Script)
+	assert.Contains(t, result.Repair.Patch, "+// valid")
+	require.Len(t, result.Repair.Attempts, 2)
+	assert.Equal(t, RepairAttempt{Attempt: 1, Model: "test-model", Error: "SyntaxError: Unexpected token"},
+		result.Repair.Attempts[0])
+	assert.True(t, result.Repair.Attempts[1].Valid)
+	assert.Contains(t, result.NextSteps[len(result.NextSteps)-1], "repaired in 2 attempt(s)")
+
+	require.Len(t, prompts, 2)
+	assert.Contains(t, prompts[0], "SyntaxError at inline:3:1: Unexpected end of input")
+	assert.Contains(t, prompts[0], brokenScript)
+	assert.Contains(t, prompts[1], "still broken", "each attempt fixes the previous one")
+}
+
+func TestRepairScriptGivesUp(t *testing.T) {
+	t.Parallel()
+
+	var prompts []string
+	original := failedValidation()
+	sample := fakeSampler(&prompts, mcp.NewTextContent(brokenScript), mcp.NewTextContent("no code"))
+	result := repairScript(t.Context(), sample, fakeCheck, brokenScript, "", original, 2)
+
+	assert.Same(t, original, result, "the original validation is returned")
+	assert.False(t, result.Repair.Repaired)
+	require.Len(t, result.Repair.Attempts, 2)
+	assert.Equal(t, "the proposed script is unchanged", result.Repair.Attempts[0].Error)
+	assert.Equal(t, "SyntaxError: Unexpected token", result.Repair.Attempts[1].Error)
+	assert.Contains(t, result.NextSteps[0], "No fix passed validation in 2 attempts")
+}
+
+func TestRepairScriptWithoutSampling(t *testing.T) {
+	t.Parallel()
+
+	s := server.NewMCPServer("test", "dev")
+	ctx := s.WithContext(t.Context(), testSession("no-sampling"))
+	result := repairScript(ctx, clientSampler(s), fakeCheck, brokenScript, "", failedValidation(), 3)
+	require.Len(t, result.Repair.Attempts, 1, "no retry without sampling")
+	assert.Contains(t, result.NextSteps[0], "does not support MCP sampling")
+
+	var prompts []string
+	result = repairScript(t.Context(), fakeSampler(&prompts), fakeCheck,
+		"import { login } from './lib.js';\n"+brokenScript, "/ws/test.js", failedValidation(), 3)
+	assert.Empty(t, prompts)
+	assert.Contains(t, result.NextSteps[0], "imports local modules")
+}
+
+func TestRepairAttemptsArgument(t *testing.T) {
+	t.Parallel()
+
+	n, err := repairAttemptsArgument(newCallRequest(map[string]any{}))
+	require.NoError(t, err)
+	assert.Zero(t, n)
+	n, err = repairAttemptsArgument(newCallRequest(map[string]any{"repair_attempts": 2}))
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	_, err = repairAttemptsArgument(newCallRequest(map[string]any{"repair_attempts": 10}))
+	require.Error(t, err)
+}
+
+func TestExtractScript(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "a();\n", extractScript("```\na();\n```"))
+	assert.Equal(t, "a();\n", extractScript("  a();  "))
+	assert.Empty(t, extractScript("```javascript"))
+	assert.Empty(t, extractScript("```js\n```"))
+}
@@ -0,0 +1,104 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/grafana/mcp-k6/internal/logging"
+	"github.com/grafana/mcp-k6/internal/requestlog"
+)
+
+const (
+	// DefaultSlowestRequests is the number of slowest requests a run with
+	// capture_requests returns by default.
+	DefaultSlowestRequests = 10
+	// MaxSlowestRequests bounds slowest_requests.
+	MaxSlowestRequests = 100
+	// maxFailedRequests bounds the failed requests a run returns; the others
+	// are only counted.
+	maxFailedRequests = 100
+	// requestLogPlaceholder stands for the JSON output file in run plans.
+	requestLogPlaceholder = "<request-log>.json"
+)
+
+// validateCaptureOptions checks the request capture options of a run.
+func validateCaptureOptions(options *RunOptions) error {
+	if options.CaptureRequests && (options.SlowestRequests < 0 || options.SlowestRequests > MaxSlowestRequests) {
+		return &RunError{
+			Type:    "PARAMETER_VALIDATION",
+			Message: fmt.Sprintf("slowest_requests must be between 0 and %d", MaxSlowestRequests),
+		}
+	}
+	return nil
+}
+
+// capturesSamples reports whether a run records its samples, for its
+// requests, groups, web vitals, capacity or metrics without warm-up.
+func capturesSamples(options *RunOptions) bool {
+	return options.CaptureRequests || options.GroupWaterfall || options.WebVitals || options.EstimateCapacity ||
+		options.DiscardWarmup != ""
+}
+
+// requestLogArgs returns the flags making k6 write the samples of the run,
+// tagged with their VU and iteration, to the request log.
+func requestLogArgs(options *RunOptions) []string {
+	if !capturesSamples(options) {
+		return nil
+	}
+	path := options.RequestLog
+	if path == "" {
+		path = requestLogPlaceholder
+	}
+	return []string{"--out", "json=" + path, "--system-tags", requestlog.SystemTags}
+}
+
+// readRequestLog returns the slowest and failed requests of the request log
+// of a run, with credentials in URLs and errors redacted. A log that cannot
+// be read leaves the result without requests.
+func readRequestLog(ctx context.Context, options *RunOptions) *requestlog.Summary {
+	logger := logging.LoggerFromContext(ctx)
+
+	//nolint:forbidigo // Reading the JSON output k6 has just written
+	f, err := os.Open(options.RequestLog)
+	if err != nil {
+		logger.WarnContext(ctx, "Failed to open request log", slog.String("error", err.Error()))
+		return nil
+	}
+	defer func() { _ = f.Close() }()
+
+	requests, err := requestlog.Read(f, options.SlowestRequests, maxFailedRequests)
+	if err != nil {
+		logger.WarnContext(ctx, "Failed to read request log", slog.String("error", err.Error()))
+	}
+	for _, list := range [][]requestlog.Request{requests.Slowest, requests.Failed} {
+		for i := range list {
+			list[i].URL = options.Redactor.String(list[i].URL)
+			list[i].Name = options.Redactor.String(list[i].Name)
+			list[i].Error = options.Redactor.String(list[i].Error)
+		}
+	}
+	logger.DebugContext(ctx, "Requests captured",
+		slog.Int("total", requests.Total),
+		slog.Int("failed", requests.FailedTotal))
+	return requests
+}
+
+// requestNextSteps points at the failed and slow requests of a run.
+func requestNextSteps(requests *requestlog.Summary) []string {
+	if requests == nil {
+		return nil
+	}
+	var steps []string
+	if requests.FailedTotal > 0 {
+		steps = append(steps, fmt.Sprintf(
+			"Inspect requests.failed: %d of %d requests failed; their status, error_code and tags show which ones",
+			requests.FailedTotal, requests.Total))
+	}
+	if len(requests.Slowest) > 0 {
+		steps = append(steps, "Compare the timings of requests.slowest: high blocked, connecting or tls_handshaking "+
+			"point at the network or client, high waiting at the server")
+	}
+	return steps
+}
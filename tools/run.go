@@ -7,13 +7,37 @@ import (
 	"fmt"
 	"log/slog"
 	"os/exec"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/grafana/mcp-k6/internal/annotate"
+	"github.com/grafana/mcp-k6/internal/approval"
+	"github.com/grafana/mcp-k6/internal/artifacts"
+	"github.com/grafana/mcp-k6/internal/audit"
+	"github.com/grafana/mcp-k6/internal/capacity"
+	"github.com/grafana/mcp-k6/internal/gitsource"
 	"github.com/grafana/mcp-k6/internal/helpers"
+	"github.com/grafana/mcp-k6/internal/httpdebug"
+	"github.com/grafana/mcp-k6/internal/importpolicy"
+	"github.com/grafana/mcp-k6/internal/jslib"
 	"github.com/grafana/mcp-k6/internal/logging"
+	"github.com/grafana/mcp-k6/internal/observe"
+	"github.com/grafana/mcp-k6/internal/ownership"
+	"github.com/grafana/mcp-k6/internal/redact"
+	"github.com/grafana/mcp-k6/internal/requestlog"
+	"github.com/grafana/mcp-k6/internal/samples"
+	"github.com/grafana/mcp-k6/internal/secrets"
 	"github.com/grafana/mcp-k6/internal/security"
+	"github.com/grafana/mcp-k6/internal/slo"
+	"github.com/grafana/mcp-k6/internal/summary"
+	"github.com/grafana/mcp-k6/internal/targetpolicy"
+	"github.com/grafana/mcp-k6/internal/telemetry"
+	"github.com/grafana/mcp-k6/internal/warmup"
+	"github.com/grafana/mcp-k6/internal/waterfall"
+	"github.com/grafana/mcp-k6/internal/webvitals"
+	"github.com/grafana/mcp-k6/internal/workspace"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 )
@@ -23,61 +47,301 @@ import (
 //nolint:gochecknoglobals // Shared tool definition registered at startup.
 var RunTool = mcp.NewTool(
 	"run_script",
-	mcp.WithDescription(
-		"Run a k6 test script with configurable parameters. "+
-			"Returns execution results including stdout, stderr, exit code, and raw metrics from k6.",
-	),
-	mcp.WithString(
-		"script",
-		mcp.Required(),
-		mcp.Description(
-			"The k6 script content to run (JavaScript/TypeScript). "+
-				"Should be a valid k6 script with proper imports and default function.",
+	append(append([]mcp.ToolOption{
+		runTool(),
+		mcp.WithDescription(
+			"Run a k6 test script with configurable parameters. " +
+				"Returns execution results including stdout, stderr, exit code, and raw metrics from k6. " +
+				"When the client supports elicitation, the user is asked for the target URLs the script reads " +
+				"from unset __ENV variables, for vus when neither the call nor the script sets the load, and to " +
+				"confirm call options that replace the script's.",
 		),
-	),
-	mcp.WithNumber(
-		"vus",
-		mcp.Description(
-			"Number of virtual users (default: 1, max: 50). "+
-				"Examples: 1 for basic test, 10 for moderate load, 50 for stress test.",
+	}, runParameters()...),
+		mcp.WithString(
+			"git_repo",
+			mcp.Description(gitRepoDescription),
 		),
-	),
-	mcp.WithString(
-		"duration",
-		mcp.Description(
-			"Test duration (default: '30s', max: '5m'). "+
-				"Examples: '30s', '2m', '5m'. Overridden by iterations if specified.",
+		mcp.WithString(
+			"git_ref",
+			mcp.Description(gitRefDescription),
 		),
-	),
-	mcp.WithNumber(
-		"iterations",
-		mcp.Description(
-			"Number of iterations per VU (overrides duration). "+
-				"Examples: 1 for single run, 100 for throughput test.",
+		mcp.WithString(
+			"git_path",
+			mcp.Description(gitPathDescription),
 		),
-	),
+		mcp.WithBoolean(
+			"background",
+			mcp.Description(
+				"Start the test and return a run_id right away instead of waiting for it to end. "+
+					"Watch live metrics with get_run and stop it early with stop_run.",
+			),
+		),
+		mcp.WithArray(
+			"slos",
+			mcp.Description(slosDescription),
+			mcp.WithStringItems(),
+		),
+		mcp.WithString(
+			"confirmation_token",
+			mcp.Description(confirmationTokenDescription),
+		),
+		outputSchema(RunResult{}, runResponse{}, confirmationResponse{}),
+	)...,
 )
 
-// RegisterRunTool registers the run tool with the MCP server.
-func RegisterRunTool(s *server.MCPServer) {
-	s.AddTool(RunTool, withToolLogger("run_script", run))
+// runParameters returns the parameters shared by run_script and plan_run.
+func runParameters() []mcp.ToolOption {
+	return []mcp.ToolOption{
+		mcp.WithString(
+			"script",
+			mcp.Description(
+				"The k6 script content to run (JavaScript/TypeScript). "+
+					"Should be a valid k6 script with proper imports and default function.",
+			),
+		),
+		mcp.WithString(
+			"script_path",
+			mcp.Description(scriptPathDescription),
+		),
+		mcp.WithString(
+			"env_file",
+			mcp.Description(envFileDescription),
+		),
+		mcp.WithArray(
+			"secrets",
+			mcp.Description(secretsDescription),
+			mcp.WithStringItems(),
+		),
+		mcp.WithArray(
+			"secret_sources",
+			mcp.Description(secretSourcesDescription),
+			mcp.Items(map[string]any{"type": "object"}),
+		),
+		mcp.WithArray(
+			"files",
+			mcp.Description(filesDescription),
+			mcp.Items(map[string]any{"type": "object"}),
+		),
+		mcp.WithString(
+			"remote_imports",
+			mcp.Description(remoteImportsDescription),
+			mcp.Enum(importpolicy.Allow, importpolicy.Deny),
+		),
+		mcp.WithArray(
+			"import_hosts",
+			mcp.Description(importHostsDescription),
+			mcp.WithStringItems(),
+		),
+		mcp.WithNumber(
+			"vus",
+			mcp.Description(
+				"Number of virtual users (default: 1, max: 50). "+
+					"Examples: 1 for basic test, 10 for moderate load, 50 for stress test.",
+			),
+		),
+		mcp.WithString(
+			"duration",
+			mcp.Description(
+				"Test duration (default: '30s', max: '5m'). "+
+					"Examples: '30s', '2m', '5m'. Overridden by iterations if specified.",
+			),
+		),
+		mcp.WithNumber(
+			"iterations",
+			mcp.Description(
+				"Number of iterations per VU (overrides duration). "+
+					"Examples: 1 for single run, 100 for throughput test.",
+			),
+		),
+		mcp.WithString(
+			"http_debug",
+			mcp.Description(
+				"Capture HTTP traffic with k6 --http-debug: 'headers' for request/response headers, "+
+					"'full' to include bodies. Traces are returned in http_traces with credentials redacted.",
+			),
+			mcp.Enum("headers", "full"),
+		),
+		mcp.WithObject(
+			"thresholds",
+			mcp.Description(
+				"Thresholds to apply to this run, mapping metrics to expressions, e.g. "+
+					"{\"http_req_duration\": [\"p(95)<500\"], \"checks\": \"rate==1\"}. They replace the script's "+
+					"thresholds for the same metric and keep the others.",
+			),
+		),
+		mcp.WithBoolean(
+			"abort_on_fail",
+			mcp.Description(
+				"Stop the test as soon as any threshold (the script's or the ones passed here) is crossed. "+
+					"The result then reports which thresholds triggered the abort and when, in early_exit.",
+			),
+		),
+		mcp.WithString(
+			"delay_abort_eval",
+			mcp.Description(
+				"With abort_on_fail, how long to collect samples before thresholds can abort the test, e.g. '10s'.",
+			),
+		),
+		mcp.WithBoolean(
+			"preview",
+			mcp.Description(
+				"Run a single iteration with 1 VU and full HTTP debug capture, regardless of the script's "+
+					"options, and return the request/response traces. Use it to check a script is correct "+
+					"before applying load.",
+			),
+		),
+		mcp.WithBoolean(
+			"capture_requests",
+			mcp.Description(
+				"Record every HTTP request of the run, tagged with its VU and iteration, and return the "+
+					"slowest ones and all failed ones in requests, with their status, tags and timings "+
+					"(blocked, connecting, tls_handshaking, sending, waiting, receiving).",
+			),
+		),
+		mcp.WithBoolean(
+			"group_waterfall",
+			mcp.Description(
+				"Return the duration of each group() of the script in groups, as a tree mirroring their nesting "+
+					"in the order they run, to analyze multi-step journeys step by step.",
+			),
+		),
+		mcp.WithString(
+			"discard_warmup",
+			mcp.Description(
+				"Leave the samples of the first part of the run, such as '30s', out of the metrics and threshold "+
+					"results returned in warmup, and of the SLO verdicts, so cold starts do not skew them. "+
+					"k6's own summary and exit code still include them.",
+			),
+		),
+		mcp.WithBoolean(
+			"summary_handler",
+			mcp.Description(
+				"Read the end-of-test summary from a handleSummary injected into the run instead of "+
+					"--summary-export, so thresholds and metrics come in the same schema whatever the k6 "+
+					"version; their results are returned in thresholds. The script's own handleSummary still runs.",
+			),
+		),
+		mcp.WithObject(
+			"web_vital_budgets",
+			mcp.Description(
+				"Budgets for the web vitals browser scripts record, mapping ttfb, fcp, lcp, fid and inp (ms) "+
+					"and cls to the value their 75th percentile must stay within on each page, e.g. {\"lcp\": 2000}. "+
+					"Vitals without a budget are checked against the thresholds of a good rating.",
+			),
+		),
+		mcp.WithObject(
+			"performance_budget",
+			mcp.Description(
+				"A performance budget for browser scripts, checked once the run ends: the bytes and requests each "+
+					"iteration may load, and the 75th percentile of web vitals across pages, e.g. "+
+					"{\"bytes\": 2000000, \"requests\": 80, \"vitals\": {\"lcp\": 2500, \"cls\": 0.1}}. "+
+					"Violations are returned in performance_budget.",
+			),
+		),
+		mcp.WithString(
+			"output",
+			mcp.Description(
+				"Keep the raw metric samples of the run in its artifact directory, written by k6 with "+
+					"--out json or --out csv. The result names the artifact_id to read them with get_run_samples.",
+			),
+			mcp.Enum(samples.Formats...),
+		),
+		mcp.WithNumber(
+			"slowest_requests",
+			mcp.Description(fmt.Sprintf(
+				"With capture_requests, the number of slowest requests to return (default: %d, max: %d).",
+				DefaultSlowestRequests, MaxSlowestRequests)),
+		),
+	}
+}
+
+// RegisterRunTool registers the run tool with the MCP server. Captured HTTP
+// traffic is passed through rd before it is returned, and secrets named in
+// a call are looked up in reg.
+func RegisterRunTool(
+	s *server.MCPServer,
+	ws *workspace.Workspace,
+	rd *redact.Redactor,
+	reg *secrets.Registry,
+	ip *importpolicy.Policy,
+	tp *targetpolicy.Policy,
+	mirror *jslib.Mirror,
+	runs *Runs,
+	objectives *slo.Registry,
+	gate *approval.Gate,
+	ov *ownership.Verifier,
+) {
+	s.AddTool(RunTool, withToolLogger("run_script",
+		newRunHandlerFunc(ws, rd, reg, ip, tp, mirror, runs, objectives, gate, ov, clientElicitor(s))))
 }
 
-func run(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	script, err := request.RequireString("script")
-	if err != nil {
-		return nil, err
+// newRunHandlerFunc returns an MCP tool handler bound to a workspace.
+func newRunHandlerFunc(
+	ws *workspace.Workspace,
+	rd *redact.Redactor,
+	reg *secrets.Registry,
+	ip *importpolicy.Policy,
+	tp *targetpolicy.Policy,
+	mirror *jslib.Mirror,
+	runs *Runs,
+	objectives *slo.Registry,
+	gate *approval.Gate,
+	ov *ownership.Verifier,
+	elicit elicitFunc,
+) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return run(ctx, ws, rd, reg, ip, tp, mirror, runs, objectives, gate, ov, elicit, request)
 	}
+}
 
-	vus := request.GetInt("vus", 1)
-	duration := request.GetString("duration", "30s")
-	iterations := request.GetInt("iterations", 0)
+func run(
+	ctx context.Context,
+	ws *workspace.Workspace,
+	rd *redact.Redactor,
+	reg *secrets.Registry,
+	ip *importpolicy.Policy,
+	tp *targetpolicy.Policy,
+	mirror *jslib.Mirror,
+	runs *Runs,
+	objectives *slo.Registry,
+	gate *approval.Gate,
+	ov *ownership.Verifier,
+	elicit elicitFunc,
+	request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	script, options, err := runRequest(ctx, ws, reg, ip, tp, elicit, request)
+	if err != nil {
+		return requestError(err), nil
+	}
+	// Background runs remove their git checkout once they end
+	started := false
+	defer func() {
+		if !started {
+			removeCheckout(ctx, options.Checkout)
+		}
+	}()
+	if err := applySLOs(objectives, request, options); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if err := verifyOwnership(ctx, ov, ws, script, options, plannedLoad(script, options).VUs); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if result := confirmRun(ctx, gate, request, script, options); result != nil {
+		return result, nil
+	}
+	options.Redactor = rd
+	options.JSLib = mirror
+	options.Artifacts = runs.Artifacts()
+	options.Observer = runs.Observer()
+	options.Annotator = runs.Annotator()
+
+	if request.GetBool("background", false) {
+		result, err := startBackgroundRun(ctx, runs, script, options)
+		started = err == nil && !result.IsError
+		return result, err
+	}
 
-	result, err := RunK6Test(ctx, script, &RunOptions{
-		VUs:        vus,
-		Duration:   duration,
-		Iterations: iterations,
-	})
+	result, err := RunK6Test(ctx, script, options)
 	if err != nil {
 		return nil, err
 	}
@@ -87,7 +351,108 @@ func run(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult,
 		return nil, err
 	}
 
-	return mcp.NewToolResultText(string(resultJSON)), nil
+	return mcp.NewToolResultStructured(result, string(resultJSON)), nil
+}
+
+// runRequest reads the script and run options of a run_script request and
+// checks the script's imports against the import policy, and its request
+// targets against the target policy. With elicit, the user is asked for
+// the parameters the request leaves to defaults first. The git checkout of
+// the options, if any, is the caller's to remove.
+func runRequest(
+	ctx context.Context,
+	ws *workspace.Workspace,
+	reg *secrets.Registry,
+	ip *importpolicy.Policy,
+	tp *targetpolicy.Policy,
+	elicit elicitFunc,
+	request mcp.CallToolRequest,
+) (_ string, _ *RunOptions, err error) {
+	script, scriptPath, modules, checkout, err := readRunScript(ctx, ws, request)
+	if err != nil {
+		return "", nil, err
+	}
+	defer func() {
+		if err != nil {
+			removeCheckout(ctx, checkout)
+		}
+	}()
+	env, err := readEnvFileArgument(ctx, ws, request)
+	if err != nil {
+		return "", nil, err
+	}
+	secretValues, err := secretsArgument(reg, request)
+	if err != nil {
+		return "", nil, err
+	}
+	secretSources, err := secretSourcesArgument(ctx, ws, request)
+	if err != nil {
+		return "", nil, err
+	}
+	thresholds, err := thresholdsArgument(request)
+	if err != nil {
+		return "", nil, err
+	}
+	dataFiles, err := dataFilesArgument(ctx, ws, request)
+	if err != nil {
+		return "", nil, err
+	}
+	budgets, err := webVitalBudgetsArgument(request)
+	if err != nil {
+		return "", nil, err
+	}
+	budget, err := performanceBudgetArgument(request)
+	if err != nil {
+		return "", nil, err
+	}
+	if budget != nil && !usesBrowser(script) {
+		return "", nil, errors.New("performance_budget applies to browser scripts, which import k6/browser")
+	}
+	policy, err := importPolicyArgument(ip, request)
+	if err != nil {
+		return "", nil, err
+	}
+	if err := checkImportPolicy(ctx, modules, policy, script, scriptPath, dataFiles); err != nil {
+		return "", nil, err
+	}
+
+	options := &RunOptions{
+		VUs:            request.GetInt("vus", 1),
+		Duration:       request.GetString("duration", "30s"),
+		Iterations:     request.GetInt("iterations", 0),
+		HTTPDebug:      request.GetString("http_debug", ""),
+		Thresholds:     thresholds,
+		AbortOnFail:    request.GetBool("abort_on_fail", false),
+		DelayAbortEval: request.GetString("delay_abort_eval", ""),
+		ScriptPath:     scriptPath,
+		Checkout:       checkout,
+		Env:            env,
+		Secrets:        secretValues,
+		SecretSources:  secretSources,
+		DataFiles:      dataFiles,
+	}
+	options.GroupWaterfall = request.GetBool("group_waterfall", false)
+	options.DiscardWarmup = request.GetString("discard_warmup", "")
+	options.SummaryHandler = request.GetBool("summary_handler", false)
+	options.WebVitalBudgets = budgets
+	options.PerformanceBudget = budget
+	options.Output = request.GetString("output", "")
+	if request.GetBool("capture_requests", false) {
+		options.CaptureRequests = true
+		options.SlowestRequests = request.GetInt("slowest_requests", DefaultSlowestRequests)
+	}
+	if request.GetBool("preview", false) {
+		options.Preview = true
+		options.VUs, options.Iterations, options.Duration = 1, 1, ""
+		options.HTTPDebug = "full"
+	}
+	if err := askRunParameters(ctx, elicit, modules, request, script, options); err != nil {
+		return "", nil, err
+	}
+	if err := checkTargetPolicy(ctx, modules, tp, script, scriptPath, dataFiles, options.Env); err != nil {
+		return "", nil, err
+	}
+	return script, options, nil
 }
 
 const (
@@ -109,18 +474,147 @@ type RunOptions struct {
 	VUs        int    `json:"vus,omitempty"`
 	Duration   string `json:"duration,omitempty"`
 	Iterations int    `json:"iterations,omitempty"`
+	// Preview marks a single-iteration correctness check.
+	Preview bool `json:"preview,omitempty"`
+	// HTTPDebug is passed to k6 as --http-debug ("headers" or "full").
+	HTTPDebug string `json:"http_debug,omitempty"`
+	// Thresholds replace the script's thresholds for the same metrics.
+	Thresholds map[string][]string `json:"thresholds,omitempty"`
+	// AbortOnFail and DelayAbortEval are applied to every threshold.
+	AbortOnFail    bool   `json:"abort_on_fail,omitempty"`
+	DelayAbortEval string `json:"delay_abort_eval,omitempty"`
+	// Scenarios replace the script's scenarios; the --vus, --duration and
+	// --iterations flags are then left out.
+	Scenarios map[string]any `json:"scenarios,omitempty"`
+	// SLOs the run is checked against; their thresholds are already merged
+	// into Thresholds.
+	SLOs []slo.SLO `json:"slos,omitempty"`
+	// CaptureRequests records the requests of the run, returning the
+	// SlowestRequests slowest ones and the failed ones.
+	CaptureRequests bool `json:"capture_requests,omitempty"`
+	SlowestRequests int  `json:"slowest_requests,omitempty"`
+	// GroupWaterfall records the durations of the groups of the run.
+	GroupWaterfall bool `json:"group_waterfall,omitempty"`
+	// DiscardWarmup is the first part of the run, such as "30s", left out
+	// of the metrics and thresholds of the result.
+	DiscardWarmup string `json:"discard_warmup,omitempty"`
+	// SummaryHandler has a handleSummary of the entry module write the
+	// summary export, instead of --summary-export.
+	SummaryHandler bool `json:"summary_handler,omitempty"`
+	// WebVitalBudgets overrides the default budgets of the web vitals of
+	// browser scripts.
+	WebVitalBudgets map[string]float64 `json:"web_vital_budgets,omitempty"`
+	// PerformanceBudget is checked against the samples of browser scripts.
+	PerformanceBudget *webvitals.Budget `json:"performance_budget,omitempty"`
+	// Output is the format of the sample file kept with the artifacts of
+	// the run, if any.
+	Output string `json:"output,omitempty"`
+
+	// ScriptPath is the resolved workspace path of the script. When set, k6
+	// runs the file in place instead of a temporary copy.
+	ScriptPath string `json:"-"`
+	// Checkout is the git checkout ScriptPath is in, with git_repo.
+	Checkout *gitsource.Checkout `json:"-"`
+	// Env holds variables passed to the script with --env.
+	Env map[string]string `json:"-"`
+	// Secrets are exported to the k6 process environment, keeping their
+	// values off the command line.
+	Secrets map[string]string `json:"-"`
+	// SecretSources are passed to k6 as --secret-source flags.
+	SecretSources []SecretSource `json:"-"`
+	// DataFiles are staged next to an inline script in a per-run directory.
+	DataFiles []DataFile `json:"-"`
+	// Redactor masks credentials in captured HTTP traffic. Nil applies the
+	// built-in rules.
+	Redactor *redact.Redactor `json:"-"`
+	// JSLib serves the jslib imports of inline scripts from an offline
+	// mirror when set.
+	JSLib *jslib.Mirror `json:"-"`
+	// APIAddress is where k6 serves its REST API (--address) during the run.
+	APIAddress string `json:"-"`
+	// RequestLog is where k6 writes the samples of the run (--out json)
+	// when requests or groups are captured.
+	RequestLog string `json:"-"`
+	// WebVitals records the web vitals of the pages of a browser script.
+	WebVitals bool `json:"-"`
+	// EstimateCapacity records the samples of a run ramping its load, to
+	// find the load at which it degraded.
+	EstimateCapacity bool `json:"-"`
+	// Artifacts keeps the sample file of the run with Output.
+	Artifacts *artifacts.Store `json:"-"`
+	// Observer queries the server-side metrics of the window of the run.
+	Observer *observe.Observer `json:"-"`
+	// Annotator marks the run on Grafana dashboards, as RunID when it runs
+	// in the background.
+	Annotator *annotate.Annotator `json:"-"`
+	RunID     string              `json:"-"`
+	// ArtifactID and SamplesFile are the artifacts of the run and the
+	// sample file k6 writes with Output.
+	ArtifactID  string `json:"-"`
+	SamplesFile string `json:"-"`
+	// SummaryExport is where k6 exports the end-of-test summary
+	// (--summary-export).
+	SummaryExport string `json:"-"`
 }
 
 // RunResult contains the result of a k6 test execution.
 type RunResult struct {
-	Success   bool                   `json:"success"`
-	ExitCode  int                    `json:"exit_code"`
-	Stdout    string                 `json:"stdout"`
-	Stderr    string                 `json:"stderr"`
-	Error     string                 `json:"error,omitempty"`
-	Duration  string                 `json:"duration"`
-	Metrics   map[string]interface{} `json:"metrics,omitempty"`
-	NextSteps []string               `json:"next_steps,omitempty"`
+	Success  bool                   `json:"success"`
+	ExitCode int                    `json:"exit_code"`
+	Stdout   string                 `json:"stdout"`
+	Stderr   string                 `json:"stderr"`
+	Error    string                 `json:"error,omitempty"`
+	Duration string                 `json:"duration"`
+	Metrics  map[string]interface{} `json:"metrics,omitempty"`
+	// Summary holds the thresholds, checks and metrics of the end-of-test
+	// summary, read according to the format the k6 binary printed.
+	Summary *summary.Summary `json:"summary,omitempty"`
+	// HTTPTraces holds the requests and responses captured with --http-debug.
+	HTTPTraces []httpdebug.Exchange `json:"http_traces,omitempty"`
+	// EarlyExit is set when an abortOnFail threshold stopped the test.
+	EarlyExit *EarlyExit `json:"early_exit,omitempty"`
+	// LoadProfile charts the load the run was configured to apply.
+	LoadProfile []string `json:"load_profile,omitempty"`
+	// SLOs judges the run against the SLOs passed in slos.
+	SLOs []slo.Verdict `json:"slos,omitempty"`
+	// Thresholds holds the outcome of each threshold, read from the summary
+	// export with summary_handler.
+	Thresholds []summary.Threshold `json:"thresholds,omitempty"`
+	// Network breaks the HTTP request timings of the run down into its
+	// phases.
+	Network *summary.Network `json:"network,omitempty"`
+	// Protocols summarizes the WebSocket and gRPC metrics of the run.
+	Protocols *summary.Protocols `json:"protocols,omitempty"`
+	// CustomMetrics holds the metrics the script defined, with their type
+	// and values.
+	CustomMetrics []summary.CustomMetric `json:"custom_metrics,omitempty"`
+	// Requests holds the slowest and failed requests, with capture_requests.
+	Requests *requestlog.Summary `json:"requests,omitempty"`
+	// WebVitals checks the web vitals of each page of browser scripts
+	// against their budgets.
+	WebVitals []webvitals.Page `json:"web_vitals,omitempty"`
+	// Budget is the outcome of the performance_budget of the run.
+	Budget *webvitals.BudgetResult `json:"performance_budget,omitempty"`
+	// Artifacts names the sample file the run kept, with output.
+	Artifacts *RunArtifacts `json:"artifacts,omitempty"`
+	// Groups charts the durations of the groups, with group_waterfall.
+	Groups *waterfall.Waterfall `json:"groups,omitempty"`
+	// Warmup holds the metrics and thresholds of the run without the
+	// discard_warmup part.
+	Warmup *warmup.Result `json:"warmup,omitempty"`
+	// EstimatedCapacity is the load at which a ramping run first broke its
+	// SLOs, or the default error rate and latency criteria.
+	EstimatedCapacity *capacity.Estimate `json:"estimated_capacity,omitempty"`
+	// Observations holds the server-side metrics of the window of the run,
+	// queried from the datasources of the observe configuration.
+	Observations *observe.Report `json:"observations,omitempty"`
+	// Git names the repository and commit the script ran from, with
+	// git_repo.
+	Git       *gitsource.Source `json:"git,omitempty"`
+	NextSteps []string          `json:"next_steps,omitempty"`
+	// Export is the summary export of the run, which workers return to
+	// their coordinator.
+	Export *summary.Export `json:"-"`
 }
 
 // RunError represents errors that occur during k6 test execution.
@@ -166,8 +660,23 @@ func RunK6Test(ctx context.Context, script string, options *RunOptions) (*RunRes
 
 	logger.DebugContext(ctx, "Test input validation passed")
 
-	// Create secure temporary file
-	tempFile, cleanup, err := createSecureTempFile(script)
+	// Run workspace scripts in place; inline scripts go to a secure temporary
+	// file, or to a per-run directory along with their data files
+	tempFile, cleanup := "", func() {}
+	staged := options != nil && len(options.DataFiles) > 0
+	source, files, missing := script, []DataFile(nil), []string(nil)
+	if options != nil {
+		tempFile = options.ScriptPath
+		source, files, missing = mirrorSources(options.JSLib, script, options.DataFiles)
+	}
+	var err error
+	switch {
+	case tempFile != "":
+	case staged:
+		tempFile, cleanup, err = createRunDir(source, files)
+	default:
+		tempFile, cleanup, err = createSecureTempFile(source)
+	}
 	if err != nil {
 		logging.FileOperation(ctx, "runner", "create_temp_file", tempFile, err)
 		return &RunResult{
@@ -180,17 +689,135 @@ func RunK6Test(ctx context.Context, script string, options *RunOptions) (*RunRes
 
 	logging.FileOperation(ctx, "runner", "create_temp_file", tempFile, nil)
 
+	// k6 writes the summary, and with capture_requests the samples, of the
+	// run to temporary files read once it ends
+	if options != nil {
+		options.WebVitals = usesBrowser(script)
+		options.EstimateCapacity = rampsLoad(script, tempFile, options)
+		var cleanupOutputs func()
+		cleanupOutputs, err = createRunOutputs(options)
+		if err != nil {
+			logging.FileOperation(ctx, "runner", "create_output_file", "", err)
+			return &RunResult{
+				Success:  false,
+				Error:    err.Error(),
+				Duration: time.Since(startTime).String(),
+			}, err
+		}
+		defer cleanupOutputs()
+	}
+
+	// Thresholds are only read from the exported options, so overriding them
+	// takes an entry module that re-exports the script with merged options,
+	// and with summary_handler a handleSummary writing the summary export
+	entryFile := tempFile
+	if needsEntryModule(options) {
+		source, err := entryModule(tempFile, script, options)
+		if err != nil {
+			return nil, fmt.Errorf("generating entry module failed; reason: %w", err)
+		}
+		if options.JSLib != nil {
+			source, _ = options.JSLib.Rewrite(source)
+		}
+		var cleanupEntry func()
+		entryFile, cleanupEntry, err = createEntryFile(tempFile, source, options)
+		if err != nil {
+			logging.FileOperation(ctx, "runner", "create_entry_module", entryFile, err)
+			return &RunResult{
+				Success:  false,
+				Error:    fmt.Sprintf("failed to create entry module: %v", err),
+				Duration: time.Since(startTime).String(),
+			}, err
+		}
+		defer cleanupEntry()
+	}
+
 	// Execute k6 test
 	logger.DebugContext(ctx, "Starting k6 test execution",
 		slog.String("script_path", helpers.GetPathType(tempFile)),
 		slog.Any("options", sanitizeRunOptions(options)))
-	result, err := executeK6Test(ctx, tempFile, options)
+	runStart := time.Now()
+	endAnnotation := annotateRun(ctx, options)
+	result, err := executeK6Test(ctx, entryFile, options)
+	endAnnotation(result)
 	if err != nil {
 		return nil, fmt.Errorf("executing k6 script failed; reason: %w", err)
 	}
+	runEnd := time.Now()
 
 	result.Duration = time.Since(startTime).String()
+	telemetry.FromContext(ctx).Run(runOutcome(result), time.Since(startTime))
+	result.EarlyExit = earlyExit(result)
+	result.LoadProfile = loadProfile(effectiveOptions(profiledScript(script, options), buildK6Args(tempFile, options)))
 	result.NextSteps = generateRunNextSteps(result, options)
+	if options != nil && options.SummaryExport != "" {
+		if export := readSummaryExport(ctx, options.SummaryExport); export != nil {
+			result.Export = export
+			result.Network = export.Network()
+			result.Protocols = export.Protocols()
+			result.CustomMetrics = export.CustomMetrics()
+			if options.SummaryHandler {
+				result.Thresholds = export.Thresholds()
+			}
+		}
+		result.NextSteps = append(result.NextSteps, networkNextSteps(result.Network)...)
+	}
+	if options != nil && options.SamplesFile != "" {
+		result.Artifacts = runArtifacts(options)
+		result.NextSteps = append(result.NextSteps, fmt.Sprintf(
+			"Call get_run_samples with artifact_id %s to read the samples of the run by metric, tag or time range, "+
+				"or analyze_run to flag its latency spikes, error bursts and throughput plateaus",
+			options.ArtifactID))
+	}
+	if options != nil && options.RequestLog != "" && options.CaptureRequests {
+		result.Requests = readRequestLog(ctx, options)
+		result.NextSteps = append(result.NextSteps, requestNextSteps(result.Requests)...)
+	}
+	if options != nil && options.RequestLog != "" && options.WebVitals {
+		result.WebVitals = readWebVitals(ctx, options)
+		result.NextSteps = append(result.NextSteps, webVitalNextSteps(result.WebVitals)...)
+	}
+	if options != nil && options.RequestLog != "" && options.WebVitals && options.PerformanceBudget != nil {
+		result.Budget = checkPerformanceBudget(ctx, options)
+		result.NextSteps = append(result.NextSteps, budgetNextSteps(result.Budget)...)
+	}
+	if options != nil && options.RequestLog != "" && options.GroupWaterfall {
+		result.Groups = readWaterfall(ctx, options.RequestLog)
+		result.NextSteps = append(result.NextSteps, waterfallNextSteps(result.Groups)...)
+	}
+	if options != nil && options.RequestLog != "" && options.EstimateCapacity {
+		result.EstimatedCapacity = estimateCapacity(ctx, options)
+		result.NextSteps = append(result.NextSteps, capacityNextSteps(result.EstimatedCapacity)...)
+	}
+	var thresholds []summary.Threshold
+	if result.Summary != nil {
+		thresholds = result.Summary.Thresholds
+		result.NextSteps = append(result.NextSteps, summaryNextSteps(result.Summary, options)...)
+	}
+	if len(result.Thresholds) > 0 {
+		thresholds = result.Thresholds
+	}
+	if options != nil && options.RequestLog != "" && options.DiscardWarmup != "" {
+		result.Warmup = readWarmup(ctx, options, thresholds)
+		result.NextSteps = append(result.NextSteps, warmupNextSteps(thresholds, result.Warmup)...)
+		thresholds = warmupThresholds(thresholds, result.Warmup)
+	}
+	if options != nil && len(options.SLOs) > 0 {
+		result.SLOs = slo.Evaluate(options.SLOs, thresholds)
+		result.NextSteps = append(result.NextSteps, sloNextSteps(result.SLOs)...)
+	}
+	if options != nil && options.Observer != nil && !options.Preview {
+		result.Observations = options.Observer.Observe(ctx, runStart, runEnd)
+		result.NextSteps = append(result.NextSteps, observationNextSteps(result.Observations)...)
+	}
+	if options != nil {
+		result.NextSteps = append(result.NextSteps,
+			jslibNextSteps(options.JSLib, script, options.ScriptPath, missing)...)
+	}
+	if options != nil && options.Checkout != nil {
+		result.Git = &options.Checkout.Source
+		result.NextSteps = append(result.NextSteps, gitNextSteps(options.Checkout)...)
+	}
 
 	logger.InfoContext(ctx, "k6 test execution completed",
 		slog.Bool("success", result.Success),
@@ -241,6 +868,30 @@ func validateRunOptions(options *RunOptions) error {
 	if err := validateVUsAndIterations(options); err != nil {
 		return err
 	}
+	if err := validateThresholdOptions(options); err != nil {
+		return err
+	}
+	if err := validateDataFiles(options); err != nil {
+		return err
+	}
+	if err := validateCaptureOptions(options); err != nil {
+		return err
+	}
+	if err := validateOutputOptions(options); err != nil {
+		return err
+	}
+	if err := validateWarmupOptions(options); err != nil {
+		return err
+	}
+
+	switch options.HTTPDebug {
+	case "", "headers", "full":
+	default:
+		return &RunError{
+			Type:    "PARAMETER_VALIDATION",
+			Message: fmt.Sprintf("http_debug must be 'headers' or 'full', got %q", options.HTTPDebug),
+		}
+	}
 
 	return validateDuration(options)
 }
@@ -335,11 +986,16 @@ func executeK6Test(ctx context.Context, scriptPath string, options *RunOptions)
 	// #nosec G204 - k6 binary is validated to exist, args are sanitized
 	cmd := exec.CommandContext(cmdCtx, "k6", args...)
 
-	// Set secure environment
+	// Set secure environment, plus any secrets requested for this run
 	cmd.Env = security.SecureEnvironment()
+	if options != nil {
+		cmd.Env = append(cmd.Env, secretEnv(options.Secrets)...)
+	}
 
 	// Execute command and capture output
+	execStart := time.Now()
 	stdout, stderr, exitCode, err := executeCommand(cmd)
+	audit.Command(ctx, cmd, execStart, err)
 
 	// Log execution results
 	logging.ExecutionEvent(ctx, "runner", "k6 run", time.Since(startTime), exitCode, err)
@@ -355,6 +1011,16 @@ func executeK6Test(ctx context.Context, scriptPath string, options *RunOptions)
 		Stderr:   stderr,
 	}
 
+	// Move HTTP debug dumps out of stdout into structured, redacted traces
+	if options != nil && options.HTTPDebug != "" {
+		result.HTTPTraces, result.Stdout = httpdebug.Parse(stdout)
+		redactTraces(options.Redactor, result.HTTPTraces)
+		result.Stdout = options.Redactor.String(result.Stdout)
+		result.Stderr = options.Redactor.String(result.Stderr)
+		logger.DebugContext(ctx, "HTTP debug traces captured",
+			slog.Int("exchange_count", len(result.HTTPTraces)))
+	}
+
 	// Parse metrics from output
 	if result.Success {
 		logger.DebugContext(ctx, "Parsing k6 output for metrics")
@@ -363,9 +1029,20 @@ func executeK6Test(ctx context.Context, scriptPath string, options *RunOptions)
 			slog.Int("metric_count", len(result.Metrics)))
 	}
 
+	result.Summary = summary.Parse(result.Stdout)
+
 	// Handle different types of errors
 	if err != nil {
 		switch {
+		case errors.Is(ctx.Err(), context.Canceled):
+			// The client cancelled the call, and the k6 process was killed
+			logger.InfoContext(ctx, "k6 test cancelled by the client")
+			result.Error = "k6 test cancelled by the client"
+			return result, &RunError{
+				Type:    "CANCELLED",
+				Message: "k6 test cancelled by the client",
+				Cause:   ctx.Err(),
+			}
 		case errors.Is(err, context.DeadlineExceeded):
 			// Command timed out
 			logger.WarnContext(ctx, "k6 test timed out",
@@ -405,6 +1082,64 @@ func executeK6Test(ctx context.Context, scriptPath string, options *RunOptions)
 	return result, nil
 }
 
+// createRunOutputs creates the files k6 writes the outputs of a run to, and
+// returns the cleanup removing them.
+func createRunOutputs(options *RunOptions) (func(), error) {
+	var cleanups []func()
+	cleanup := func() {
+		for _, c := range cleanups {
+			c()
+		}
+	}
+
+	path, c, err := createOutputFile("k6-summary-*.json")
+	if err != nil {
+		return nil, err
+	}
+	options.SummaryExport, cleanups = path, append(cleanups, c)
+	if capturesSamples(options) {
+		if path, c, err = createOutputFile("k6-samples-*.json"); err != nil {
+			cleanup()
+			return nil, err
+		}
+		options.RequestLog, cleanups = path, append(cleanups, c)
+	}
+	// The sample file outlives the run, in the artifacts of the run
+	if options.Output != "" && options.Artifacts != nil {
+		if err := createSamplesFile(options); err != nil {
+			cleanup()
+			return nil, err
+		}
+	}
+	return cleanup, nil
+}
+
+// secretEnv renders secrets as sorted NAME=VALUE environment entries.
+func secretEnv(values map[string]string) []string {
+	env := make([]string, 0, len(values))
+	for name, value := range values {
+		env = append(env, name+"="+value)
+	}
+	sort.Strings(env)
+	return env
+}
+
+// redactTraces masks credentials in the captured requests and responses.
+func redactTraces(rd *redact.Redactor, exchanges []httpdebug.Exchange) {
+	for _, ex := range exchanges {
+		for _, msg := range []*httpdebug.Message{ex.Request, ex.Response} {
+			if msg == nil {
+				continue
+			}
+			msg.StartLine = rd.String(msg.StartLine)
+			for i, h := range msg.Headers {
+				msg.Headers[i].Value = rd.Header(h.Name, h.Value)
+			}
+			msg.Body = rd.String(msg.Body)
+		}
+	}
+}
+
 // buildK6Args builds the command line arguments for k6 based on the provided options.
 func buildK6Args(scriptPath string, options *RunOptions) []string {
 	args := []string{"run"}
@@ -417,23 +1152,38 @@ func buildK6Args(scriptPath string, options *RunOptions) []string {
 		}
 	}
 
-	// Set VUs (default to 1 if not specified)
+	// Set VUs (default to 1 if not specified); the flags would replace the
+	// scenarios of the run
 	vus := options.VUs
 	if vus == 0 {
 		vus = DefaultVUs
 	}
-	args = append(args, "--vus", strconv.Itoa(vus))
-
-	// Handle duration vs iterations
-	if options.Iterations > 0 {
-		args = append(args, "--iterations", strconv.Itoa(options.Iterations))
-	} else {
+	switch {
+	case len(options.Scenarios) > 0:
+	case options.Iterations > 0:
+		args = append(args, "--vus", strconv.Itoa(vus), "--iterations", strconv.Itoa(options.Iterations))
+	default:
 		duration := options.Duration
 		if duration == "" {
 			duration = DefaultDuration
 		}
-		args = append(args, "--duration", duration)
+		args = append(args, "--vus", strconv.Itoa(vus), "--duration", duration)
+	}
+
+	if options.HTTPDebug != "" {
+		args = append(args, "--http-debug="+options.HTTPDebug)
 	}
+	if options.APIAddress != "" {
+		args = append(args, "--address", options.APIAddress)
+	}
+	if options.SummaryExport != "" && !options.SummaryHandler {
+		args = append(args, "--summary-export", options.SummaryExport)
+	}
+
+	args = append(args, requestLogArgs(options)...)
+	args = append(args, outputArgs(options)...)
+	args = append(args, envArgs(options.Env)...)
+	args = append(args, secretSourceArgs(options.SecretSources)...)
 
 	// Add script path
 	args = append(args, scriptPath)
@@ -478,9 +1228,24 @@ func sanitizeRunOptions(options *RunOptions) interface{} {
 	}
 
 	return map[string]interface{}{
-		"vus":        options.VUs,
-		"duration":   options.Duration,
-		"iterations": options.Iterations,
+		"vus":            options.VUs,
+		"duration":       options.Duration,
+		"iterations":     options.Iterations,
+		"preview":        options.Preview,
+		"http_debug":     options.HTTPDebug,
+		"env_vars":       len(options.Env),
+		"secrets":        len(options.Secrets),
+		"secret_sources": len(options.SecretSources),
+		"thresholds":     len(options.Thresholds),
+		"abort_on_fail":  options.AbortOnFail,
+		"data_files":     len(options.DataFiles),
+		"scenarios":      len(options.Scenarios),
+		"slos":           len(options.SLOs),
+		"capture":        options.CaptureRequests,
+		"waterfall":      options.GroupWaterfall,
+		"output":         options.Output,
+		"warmup":         options.DiscardWarmup,
+		"handler":        options.SummaryHandler,
 	}
 }
 
@@ -492,6 +1257,14 @@ func generateRunNextSteps(result *RunResult, options *RunOptions) []string {
 
 	var steps []string
 
+	// A threshold stopped the test early
+	if result.EarlyExit != nil {
+		steps = append(steps, "Review early_exit for the thresholds that stopped the test and when")
+		steps = append(steps, "Compare the elapsed time with the load_profile to see which load level crossed them")
+		steps = append(steps, "Use delay_abort_eval to give thresholds more samples before they can abort")
+		return steps
+	}
+
 	// Handle test failures
 	if !result.Success || result.ExitCode != 0 {
 		steps = append(steps, "Use validate_k6_script to check for syntax errors and script validity")
@@ -508,6 +1281,13 @@ func generateRunNextSteps(result *RunResult, options *RunOptions) []string {
 		return steps
 	}
 
+	// Successful preview: verify the traffic before applying load
+	if options != nil && options.Preview {
+		steps = append(steps, "Review http_traces to confirm each request and response is what the test expects")
+		steps = append(steps, "Run run_script again without preview to apply load")
+		return steps
+	}
+
 	// Successful execution
 	steps = append(steps, "Use the metrics data above to analyze test performance and results")
 
@@ -0,0 +1,490 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/grafana/mcp-k6/internal/approval"
+	"github.com/grafana/mcp-k6/internal/k6api"
+	"github.com/grafana/mcp-k6/internal/logging"
+	"github.com/grafana/mcp-k6/internal/ownership"
+	"github.com/grafana/mcp-k6/internal/workspace"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	// runIDDescription documents the run_id parameter of the run control tools.
+	runIDDescription = "The run_id returned by run_script with background=true."
+
+	// apiStartingMessage explains a REST API that does not answer yet.
+	apiStartingMessage = "k6 is not serving its REST API yet (initializing VUs or running setup); retry in a few seconds"
+)
+
+// GetRunTool exposes a tool for watching a background run.
+//
+//nolint:gochecknoglobals // Shared tool definition registered at startup.
+var GetRunTool = mcp.NewTool(
+	"get_run",
+	readOnlyTool(),
+	mcp.WithDescription(
+		"Get the state of a background run. While the test runs, returns its live status from the k6 "+
+			"REST API (execution stage, VUs, whether a threshold failed) and the current value of its "+
+			"metrics; once it ended, returns the full run_script result, or a report of its thresholds and "+
+			"checks in the requested format.",
+	),
+	mcp.WithString(
+		"run_id",
+		mcp.Required(),
+		mcp.Description(runIDDescription),
+	),
+	mcp.WithArray(
+		"metrics",
+		mcp.Description("Optional: metric names to return, such as 'http_req_duration' (default: all)."),
+		mcp.WithStringItems(),
+	),
+	mcp.WithString(
+		"format",
+		mcp.Description("Optional: format of the result of an ended run. 'json' (default) returns the "+
+			"run_script result; 'junit' returns JUnit XML with a test case per threshold and check, for CI "+
+			"systems to publish as a test report; 'markdown' returns a summary of key metrics, thresholds "+
+			"and failed checks to paste into a pull-request comment."),
+		mcp.Enum(formatJSON, formatJUnit, formatMarkdown),
+	),
+	mcp.WithString(
+		"baseline_run_id",
+		mcp.Description("Optional, with format 'markdown': an ended run, such as the previous run of the "+
+			"same schedule, to compare the key metrics with."),
+	),
+)
+
+// ListRunsTool exposes a tool for listing background runs.
+//
+//nolint:gochecknoglobals // Shared tool definition registered at startup.
+var ListRunsTool = mcp.NewTool(
+	"list_runs",
+	readOnlyTool(),
+	mcp.WithDescription("List the background runs started with run_script, in progress and recently ended."),
+)
+
+// StopRunTool exposes a tool for stopping a background run.
+//
+//nolint:gochecknoglobals // Shared tool definition registered at startup.
+var StopRunTool = mcp.NewTool(
+	"stop_run",
+	controlTool(true),
+	mcp.WithDescription(
+		"Stop a background run early. k6 still runs teardown and prints the end-of-test summary, "+
+			"which get_run returns once the run ended.",
+	),
+	mcp.WithString(
+		"run_id",
+		mcp.Required(),
+		mcp.Description(runIDDescription),
+	),
+)
+
+// PauseRunTool exposes a tool for pausing a background run.
+//
+//nolint:gochecknoglobals // Shared tool definition registered at startup.
+var PauseRunTool = mcp.NewTool(
+	"pause_run",
+	controlTool(false),
+	mcp.WithDescription(
+		"Pause a background run: VUs stop starting iterations until resume_run, giving the target system "+
+			"a breather. Time spent paused counts towards the run_script timeout.",
+	),
+	mcp.WithString(
+		"run_id",
+		mcp.Required(),
+		mcp.Description(runIDDescription),
+	),
+)
+
+// ResumeRunTool exposes a tool for resuming a paused background run.
+//
+//nolint:gochecknoglobals // Shared tool definition registered at startup.
+var ResumeRunTool = mcp.NewTool(
+	"resume_run",
+	controlTool(false),
+	mcp.WithDescription("Resume a background run paused with pause_run."),
+	mcp.WithString(
+		"run_id",
+		mcp.Required(),
+		mcp.Description(runIDDescription),
+	),
+)
+
+// ScaleRunTool exposes a tool for scaling the VUs of a background run.
+//
+//nolint:gochecknoglobals // Shared tool definition registered at startup.
+var ScaleRunTool = mcp.NewTool(
+	"scale_run",
+	controlTool(false),
+	mcp.WithDescription(
+		"Change the number of active VUs of a background run, to ramp load up or down based on what get_run "+
+			"reports. Only tests using the externally-controlled executor can be scaled, for example "+
+			"options: {scenarios: {main: {executor: 'externally-controlled', vus: 5, maxVUs: 50, duration: '5m'}}}. "+
+			"Scaling up past the server's confirmation limits returns status requires_confirmation first, as "+
+			"run_script does.",
+	),
+	mcp.WithString(
+		"run_id",
+		mcp.Required(),
+		mcp.Description(runIDDescription),
+	),
+	mcp.WithNumber(
+		"vus",
+		mcp.Required(),
+		mcp.Description(fmt.Sprintf("Number of active VUs to run (0-%d); at most the run's maximum VUs.", MaxVUs)),
+	),
+	mcp.WithNumber(
+		"vus_max",
+		mcp.Description(fmt.Sprintf(
+			"Optional: new maximum VUs (1-%d). k6 can raise it while the test runs but not lower it.", MaxVUs)),
+	),
+	mcp.WithString(
+		"confirmation_token",
+		mcp.Description(confirmationTokenDescription),
+	),
+)
+
+// runSummary describes a background run.
+type runSummary struct {
+	RunID     string `json:"run_id"`
+	State     string `json:"state"`
+	Script    string `json:"script"`
+	Schedule  string `json:"schedule_id,omitempty"`
+	StartedAt string `json:"started_at"`
+	Elapsed   string `json:"elapsed"`
+}
+
+// runResponse is the JSON structure returned by the run control tools.
+type runResponse struct {
+	runSummary
+	// Live is the status reported by the k6 REST API while the test runs.
+	Live    *k6api.Status  `json:"live,omitempty"`
+	Metrics []k6api.Metric `json:"metrics,omitempty"`
+	// Result is the run_script result once the run ended.
+	Result    *RunResult `json:"result,omitempty"`
+	Error     string     `json:"error,omitempty"`
+	NextSteps []string   `json:"next_steps,omitempty"`
+}
+
+// RegisterRunControlTools registers the get_run, list_runs, stop_run, pause_run, resume_run and
+// scale_run tools with the MCP server.
+func RegisterRunControlTools(
+	s *server.MCPServer,
+	ws *workspace.Workspace,
+	runs *Runs,
+	gate *approval.Gate,
+	ov *ownership.Verifier,
+) {
+	s.AddTool(GetRunTool, withToolLogger("get_run", newGetRunHandlerFunc(runs)))
+	s.AddTool(ListRunsTool, withToolLogger("list_runs", newListRunsHandlerFunc(runs)))
+	s.AddTool(StopRunTool, withToolLogger("stop_run", newStopRunHandlerFunc(runs)))
+	s.AddTool(PauseRunTool, withToolLogger("pause_run", newSetPausedHandlerFunc(runs, true)))
+	s.AddTool(ResumeRunTool, withToolLogger("resume_run", newSetPausedHandlerFunc(runs, false)))
+	s.AddTool(ScaleRunTool, withToolLogger("scale_run", newScaleRunHandlerFunc(ws, runs, gate, ov)))
+}
+
+// startBackgroundRun starts a run_script request in the background.
+func startBackgroundRun(
+	ctx context.Context,
+	runs *Runs,
+	script string,
+	options *RunOptions,
+) (*mcp.CallToolResult, error) {
+	logger := logging.LoggerFromContext(ctx)
+	if runs == nil {
+		return mcp.NewToolResultError("background runs are not available"), nil
+	}
+	run, err := runs.Start(ctx, script, options)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	logger.InfoContext(ctx, "Background run started",
+		slog.String("run_id", run.ID),
+		slog.Any("options", sanitizeRunOptions(options)))
+
+	return structuredResponse(ctx, logger, runResponse{
+		runSummary: summarizeRun(run),
+		NextSteps: []string{
+			"Call get_run with this run_id to watch live metrics while the test runs",
+			"Call stop_run to stop the test early",
+		},
+	})
+}
+
+func newGetRunHandlerFunc(runs *Runs) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		logger := logging.LoggerFromContext(ctx)
+		run, err := requestRun(runs, request)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		format := request.GetString("format", formatJSON)
+		if format != formatJSON {
+			if !run.Done() {
+				return mcp.NewToolResultError(fmt.Sprintf(
+					"run %s is still %s; the %s report is available once it ended", run.ID, run.State(), format)), nil
+			}
+			baseline, err := requestBaseline(runs, request)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			out, err := renderRun(run, format, baseline)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			return mcp.NewToolResultText(out), nil
+		}
+
+		resp := runResponse{runSummary: summarizeRun(run)}
+		if run.Done() {
+			resp.Result, err = run.Result()
+			if err != nil {
+				resp.Error = err.Error()
+			}
+			return marshalResponse(ctx, logger, resp)
+		}
+
+		resp.Live, resp.Metrics, err = liveRun(ctx, run, request.GetStringSlice("metrics", nil))
+		switch {
+		case errors.Is(err, k6api.ErrUnavailable):
+			resp.NextSteps = append(resp.NextSteps, apiStartingMessage)
+		case err != nil:
+			resp.Error = err.Error()
+		case resp.Live.Paused:
+			resp.NextSteps = append(resp.NextSteps, "The test is paused; call resume_run to continue it")
+		case resp.Live.Tainted:
+			resp.NextSteps = append(resp.NextSteps,
+				"A threshold has already failed; consider stop_run and review the result")
+		}
+		return marshalResponse(ctx, logger, resp)
+	}
+}
+
+func newListRunsHandlerFunc(runs *Runs) server.ToolHandlerFunc {
+	return func(ctx context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		logger := logging.LoggerFromContext(ctx)
+		list := []runSummary{}
+		if runs != nil {
+			for _, run := range runs.List() {
+				list = append(list, summarizeRun(run))
+			}
+		}
+		return marshalResponse(ctx, logger, map[string]any{"runs": list})
+	}
+}
+
+func newStopRunHandlerFunc(runs *Runs) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		logger := logging.LoggerFromContext(ctx)
+		run, err := requestRun(runs, request)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		status, killed, err := run.Stop(ctx)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		logger.InfoContext(ctx, "Background run stopped",
+			slog.String("run_id", run.ID), slog.Bool("killed", killed))
+
+		resp := runResponse{runSummary: summarizeRun(run), Live: status}
+		if killed {
+			resp.NextSteps = append(resp.NextSteps,
+				"k6 did not answer on its REST API, so the process was killed without an end-of-test summary")
+		}
+		resp.NextSteps = append(resp.NextSteps, "Call get_run to read the result once the run ended")
+		return marshalResponse(ctx, logger, resp)
+	}
+}
+
+func newSetPausedHandlerFunc(runs *Runs, paused bool) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		logger := logging.LoggerFromContext(ctx)
+		run, err := requestRun(runs, request)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		status, err := run.SetPaused(ctx, paused)
+		if errors.Is(err, k6api.ErrUnavailable) {
+			return mcp.NewToolResultError(apiStartingMessage), nil
+		}
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		logger.InfoContext(ctx, "Background run paused state changed",
+			slog.String("run_id", run.ID), slog.Bool("paused", status.Paused))
+
+		resp := runResponse{runSummary: summarizeRun(run), Live: status}
+		if status.Paused {
+			resp.NextSteps = []string{
+				"Call resume_run to continue the test once the target system has recovered",
+				fmt.Sprintf("Time spent paused counts towards the %v run_script timeout", DefaultTimeout),
+			}
+		} else {
+			resp.NextSteps = []string{"Call get_run to watch how the target system handles the load again"}
+		}
+		return marshalResponse(ctx, logger, resp)
+	}
+}
+
+func newScaleRunHandlerFunc(
+	ws *workspace.Workspace,
+	runs *Runs,
+	gate *approval.Gate,
+	ov *ownership.Verifier,
+) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		logger := logging.LoggerFromContext(ctx)
+		run, err := requestRun(runs, request)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		vus, vusMax, err := scaleArguments(request)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		load := scaledLoad(vus, vusMax)
+		if err := verifyOwnership(ctx, ov, ws, run.source, &run.options, load.VUs); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		if result := confirmLoad(ctx, gate, request, run.Script, load); result != nil {
+			return result, nil
+		}
+
+		status, err := run.Scale(ctx, vus, vusMax)
+		switch {
+		case errors.Is(err, k6api.ErrUnavailable):
+			return mcp.NewToolResultError(apiStartingMessage), nil
+		case err != nil && run.Done():
+			return mcp.NewToolResultError(err.Error()), nil
+		case err != nil:
+			return mcp.NewToolResultError(err.Error() +
+				"; only tests using the externally-controlled executor can be scaled, up to their maximum VUs"), nil
+		}
+		logger.InfoContext(ctx, "Background run scaled",
+			slog.String("run_id", run.ID),
+			slog.Int64("vus", status.VUs),
+			slog.Int64("vus_max", status.VUsMax))
+
+		return marshalResponse(ctx, logger, runResponse{
+			runSummary: summarizeRun(run),
+			Live:       status,
+			NextSteps: []string{
+				"Call get_run after a few seconds to see how latency and errors respond to the new load",
+			},
+		})
+	}
+}
+
+// scaleArguments reads and bounds the vus and vus_max parameters of scale_run.
+func scaleArguments(request mcp.CallToolRequest) (int64, *int64, error) {
+	vus, err := request.RequireInt("vus")
+	if err != nil {
+		return 0, nil, err
+	}
+	if vus < 0 || vus > MaxVUs {
+		return 0, nil, fmt.Errorf("vus must be between 0 and %d", MaxVUs)
+	}
+	if _, ok := request.GetArguments()["vus_max"]; !ok {
+		return int64(vus), nil, nil
+	}
+	vusMax, err := request.RequireInt("vus_max")
+	if err != nil {
+		return 0, nil, err
+	}
+	if vusMax < 1 || vusMax > MaxVUs {
+		return 0, nil, fmt.Errorf("vus_max must be between 1 and %d", MaxVUs)
+	}
+	if vus > vusMax {
+		return 0, nil, fmt.Errorf("vus (%d) exceeds vus_max (%d)", vus, vusMax)
+	}
+	limit := int64(vusMax)
+	return int64(vus), &limit, nil
+}
+
+// scaledLoad returns the load a run scaled to vus and vusMax may reach.
+func scaledLoad(vus int64, vusMax *int64) approval.Load {
+	if vusMax != nil {
+		vus = max(vus, *vusMax)
+	}
+	return approval.Load{VUs: int(vus)}
+}
+
+// requestRun returns the run named by the run_id parameter.
+func requestRun(runs *Runs, request mcp.CallToolRequest) (*BackgroundRun, error) {
+	id, err := request.RequireString("run_id")
+	if err != nil {
+		return nil, err
+	}
+	if runs == nil {
+		return nil, errors.New("background runs are not available")
+	}
+	return runs.Get(id)
+}
+
+// requestBaseline returns the ended run named by the baseline_run_id
+// parameter, or nil when it is not set.
+func requestBaseline(runs *Runs, request mcp.CallToolRequest) (*BackgroundRun, error) {
+	id := request.GetString("baseline_run_id", "")
+	if id == "" {
+		return nil, nil
+	}
+	baseline, err := runs.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if !baseline.Done() {
+		return nil, fmt.Errorf("baseline run %s is still %s; compare with an ended run", id, baseline.State())
+	}
+	return baseline, nil
+}
+
+// liveRun queries the REST API of a running test, keeping only the named
+// metrics when any are given.
+func liveRun(ctx context.Context, run *BackgroundRun, names []string) (*k6api.Status, []k6api.Metric, error) {
+	status, err := run.API.Status(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	metrics, err := run.API.Metrics(ctx)
+	if err != nil {
+		return status, nil, err
+	}
+	if len(names) == 0 {
+		return status, metrics, nil
+	}
+	wanted := make(map[string]bool, len(names))
+	for _, name := range names {
+		wanted[name] = true
+	}
+	filtered := metrics[:0]
+	for _, m := range metrics {
+		if wanted[m.Name] {
+			filtered = append(filtered, m)
+		}
+	}
+	return status, filtered, nil
+}
+
+func summarizeRun(run *BackgroundRun) runSummary {
+	return runSummary{
+		RunID:     run.ID,
+		State:     run.State(),
+		Script:    run.Script,
+		Schedule:  run.Schedule,
+		StartedAt: run.StartedAt.UTC().Format(time.RFC3339),
+		Elapsed:   run.Elapsed().Round(100 * time.Millisecond).String(),
+	}
+}
@@ -0,0 +1,209 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grafana/mcp-k6/internal/approval"
+	"github.com/grafana/mcp-k6/internal/ownership"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func callRunControl(
+	t *testing.T,
+	handler func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error),
+	args map[string]any,
+) *mcp.CallToolResult {
+	t.Helper()
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = args
+	result, err := handler(context.Background(), req)
+	require.NoError(t, err)
+	return result
+}
+
+func decodeRunResponse(t *testing.T, result *mcp.CallToolResult) runResponse {
+	t.Helper()
+	require.False(t, result.IsError, result.Content)
+	var resp runResponse
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &resp))
+	return resp
+}
+
+func TestRunControlTools(t *testing.T) {
+	t.Parallel()
+
+	runs := newTestRuns(t)
+	result, err := startBackgroundRun(context.Background(), runs, testRunScript, &RunOptions{VUs: 2})
+	require.NoError(t, err)
+	started := decodeRunResponse(t, result)
+	assert.Equal(t, "run-1", started.RunID)
+	assert.Equal(t, "running", started.State)
+
+	run, err := runs.Get(started.RunID)
+	require.NoError(t, err)
+	waitForAPI(t, run)
+
+	live := decodeRunResponse(t, callRunControl(t, newGetRunHandlerFunc(runs),
+		map[string]any{"run_id": "run-1", "metrics": []any{"vus"}}))
+	require.NotNil(t, live.Live)
+	assert.Equal(t, "running", live.Live.Execution)
+	assert.Equal(t, int64(2), live.Live.VUs)
+	require.Len(t, live.Metrics, 1)
+	assert.Equal(t, "vus", live.Metrics[0].Name)
+	assert.Contains(t, live.NextSteps[0], "A threshold has already failed")
+
+	paused := decodeRunResponse(t, callRunControl(t, newSetPausedHandlerFunc(runs, true),
+		map[string]any{"run_id": "run-1"}))
+	assert.True(t, paused.Live.Paused)
+	assert.Contains(t, paused.NextSteps[0], "resume_run")
+	resumed := decodeRunResponse(t, callRunControl(t, newSetPausedHandlerFunc(runs, false),
+		map[string]any{"run_id": "run-1"}))
+	assert.False(t, resumed.Live.Paused)
+
+	scaled := decodeRunResponse(t, callRunControl(t, newScaleRunHandlerFunc(nil, runs, nil, nil),
+		map[string]any{"run_id": "run-1", "vus": float64(8)}))
+	assert.Equal(t, int64(8), scaled.Live.VUs)
+	result = callRunControl(t, newScaleRunHandlerFunc(nil, runs, nil, nil), map[string]any{"run_id": "run-1", "vus": float64(20)})
+	require.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "can't exceed vus-max")
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "externally-controlled")
+	scaled = decodeRunResponse(t, callRunControl(t, newScaleRunHandlerFunc(nil, runs, nil, nil),
+		map[string]any{"run_id": "run-1", "vus": float64(20), "vus_max": float64(30)}))
+	assert.Equal(t, int64(20), scaled.Live.VUs)
+	assert.Equal(t, int64(30), scaled.Live.VUsMax)
+
+	result = callRunControl(t, newGetRunHandlerFunc(runs), map[string]any{"run_id": "run-1", "format": "junit"})
+	require.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "still running")
+
+	listed := callRunControl(t, newListRunsHandlerFunc(runs), nil)
+	assert.Contains(t, listed.Content[0].(mcp.TextContent).Text, `"run_id": "run-1"`)
+
+	stopped := decodeRunResponse(t, callRunControl(t, newStopRunHandlerFunc(runs), map[string]any{"run_id": "run-1"}))
+	assert.True(t, stopped.Live.Stopped)
+	require.NoError(t, run.Wait(context.Background()))
+
+	result = callRunControl(t, newSetPausedHandlerFunc(runs, true), map[string]any{"run_id": "run-1"})
+	require.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "already ended")
+
+	ended := decodeRunResponse(t, callRunControl(t, newGetRunHandlerFunc(runs), map[string]any{"run_id": "run-1"}))
+	assert.Equal(t, "stopped", ended.State)
+	require.NotNil(t, ended.Result)
+	assert.True(t, ended.Result.Success)
+	assert.Nil(t, ended.Live)
+}
+
+func TestRunControlErrors(t *testing.T) {
+	t.Parallel()
+
+	runs := newTestRuns(t)
+	result := callRunControl(t, newGetRunHandlerFunc(runs), map[string]any{"run_id": "run-7"})
+	require.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "unknown run_id")
+
+	result = callRunControl(t, newStopRunHandlerFunc(runs), map[string]any{})
+	require.True(t, result.IsError)
+
+	result, err := startBackgroundRun(context.Background(), nil, testRunScript, nil)
+	require.NoError(t, err)
+	require.True(t, result.IsError)
+}
+
+func TestScaleArguments(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		args map[string]any
+		err  string
+	}{
+		"missing vus":      {map[string]any{}, "vus"},
+		"negative vus":     {map[string]any{"vus": float64(-1)}, "between 0 and 50"},
+		"too many vus":     {map[string]any{"vus": float64(51)}, "between 0 and 50"},
+		"too high vus_max": {map[string]any{"vus": float64(5), "vus_max": float64(100)}, "between 1 and 50"},
+		"vus over vus_max": {map[string]any{"vus": float64(20), "vus_max": float64(10)}, "exceeds vus_max"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			req := mcp.CallToolRequest{}
+			req.Params.Arguments = tt.args
+			_, _, err := scaleArguments(req)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]any{"vus": float64(0)}
+	vus, vusMax, err := scaleArguments(req)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), vus)
+	assert.Nil(t, vusMax)
+}
+
+func TestScaleRunConfirmation(t *testing.T) {
+	t.Parallel()
+
+	gate, err := approval.New(20, 0)
+	require.NoError(t, err)
+	runs := newTestRuns(t)
+	result, err := startBackgroundRun(context.Background(), runs, testRunScript, &RunOptions{VUs: 2})
+	require.NoError(t, err)
+	run, err := runs.Get(decodeRunResponse(t, result).RunID)
+	require.NoError(t, err)
+	waitForAPI(t, run)
+
+	handler := newScaleRunHandlerFunc(nil, runs, gate, nil)
+	args := map[string]any{"run_id": run.ID, "vus": float64(10), "vus_max": float64(MaxVUs)}
+	result = callRunControl(t, handler, args)
+	require.False(t, result.IsError, result.Content)
+	var resp confirmationResponse
+	decodeJSON(t, result, &resp)
+	assert.Equal(t, "requires_confirmation", resp.Status)
+	assert.Equal(t, MaxVUs, resp.PeakVUs)
+	status, err := run.API.Status(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), status.VUs, "the run is not scaled before confirmation")
+
+	args["confirmation_token"] = resp.ConfirmationToken
+	scaled := decodeRunResponse(t, callRunControl(t, handler, args))
+	assert.Equal(t, int64(10), scaled.Live.VUs)
+
+	scaled = decodeRunResponse(t, callRunControl(t, handler, map[string]any{"run_id": run.ID, "vus": float64(5)}))
+	assert.Equal(t, int64(5), scaled.Live.VUs, "scaling within the limits needs no confirmation")
+}
+
+func TestScaleRunOwnership(t *testing.T) {
+	t.Parallel()
+
+	target := httptest.NewServer(http.NotFoundHandler())
+	t.Cleanup(target.Close)
+	ov, err := ownership.New("0123456789abcdef", 5)
+	require.NoError(t, err)
+	runs := newTestRuns(t)
+	script := "import http from 'k6/http';\nexport default function () { http.get('" + target.URL + "'); }\n"
+	result, err := startBackgroundRun(context.Background(), runs, script, &RunOptions{VUs: 2})
+	require.NoError(t, err)
+	run, err := runs.Get(decodeRunResponse(t, result).RunID)
+	require.NoError(t, err)
+	waitForAPI(t, run)
+
+	handler := newScaleRunHandlerFunc(nil, runs, nil, ov)
+	scaled := decodeRunResponse(t, callRunControl(t, handler, map[string]any{"run_id": run.ID, "vus": float64(5)}))
+	assert.Equal(t, int64(5), scaled.Live.VUs)
+
+	// Scaling past the threshold verifies the targets the run started without
+	result = callRunControl(t, handler, map[string]any{"run_id": run.ID, "vus": float64(8), "vus_max": float64(10)})
+	require.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "runs above 5 VUs")
+	status, err := run.API.Status(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), status.VUs)
+}
@@ -0,0 +1,557 @@
+package tools
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/mcp-k6/internal/approval"
+	"github.com/grafana/mcp-k6/internal/importpolicy"
+	"github.com/grafana/mcp-k6/internal/k6operator"
+	"github.com/grafana/mcp-k6/internal/logging"
+	"github.com/grafana/mcp-k6/internal/ownership"
+	"github.com/grafana/mcp-k6/internal/scriptinfo"
+	"github.com/grafana/mcp-k6/internal/summary"
+	"github.com/grafana/mcp-k6/internal/targetpolicy"
+	"github.com/grafana/mcp-k6/internal/workspace"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// RunDistributedTool exposes a tool for running a test across the pods of a
+// Kubernetes cluster with the k6-operator.
+//
+//nolint:gochecknoglobals // Shared tool definition registered at startup.
+var RunDistributedTool = mcp.NewTool(
+	"run_distributed",
+	runTool(),
+	mcp.WithDescription(
+		"Run a k6 test distributed across the pods of a Kubernetes cluster with the k6-operator, for load "+
+			"beyond a single machine. Renders a TestRun resource splitting the VUs across parallelism runners, "+
+			"applies it with kubectl, follows the stage of the test and the status of its runner pods until it "+
+			"ends, and merges the end-of-test summaries of the runners. Needs kubectl and a cluster with the "+
+			"k6-operator installed; dry_run returns the manifests without applying them. Scripts must be a single "+
+			"file: bundle local modules first.",
+	),
+	mcp.WithString(
+		"script",
+		mcp.Description("The k6 script content to run."),
+	),
+	mcp.WithString(
+		"script_path",
+		mcp.Description(scriptPathDescription),
+	),
+	mcp.WithString(
+		"env_file",
+		mcp.Description(envFileDescription+" The variables are set in the environment of the runner pods, "+
+			"in the TestRun resource."),
+	),
+	mcp.WithNumber(
+		"vus",
+		mcp.Required(),
+		mcp.Description(fmt.Sprintf("The total VUs of the test, split across the runners (max: %d).",
+			MaxDistributedVUs)),
+	),
+	mcp.WithString(
+		"duration",
+		mcp.Description(fmt.Sprintf("Optional: test duration (default: %q, max: %s).",
+			DefaultDuration, MaxDistributedDuration)),
+	),
+	mcp.WithNumber(
+		"iterations",
+		mcp.Description("Optional: total iterations shared by the VUs, instead of duration."),
+	),
+	mcp.WithNumber(
+		"parallelism",
+		mcp.Description(fmt.Sprintf("Optional: the runner pods the load is split across (default: %d, max: %d).",
+			defaultParallelism, maxParallelism)),
+	),
+	mcp.WithString(
+		"namespace",
+		mcp.Description("Optional: the namespace of the TestRun (default: 'default')."),
+	),
+	mcp.WithString(
+		"kubeconfig",
+		mcp.Description("Optional: the kubeconfig file of the cluster (default: KUBECONFIG or ~/.kube/config)."),
+	),
+	mcp.WithString(
+		"context",
+		mcp.Description("Optional: the kubeconfig context of the cluster (default: the current context)."),
+	),
+	mcp.WithString(
+		"name",
+		mcp.Description("Optional: the name of the TestRun and of the ConfigMap of the script "+
+			"(default: mcp-k6- and a random suffix)."),
+	),
+	mcp.WithString(
+		"image",
+		mcp.Description(fmt.Sprintf("Optional: the k6 image of the runners, such as one built with extensions "+
+			"(default: %q).", k6operator.DefaultImage)),
+	),
+	mcp.WithString(
+		"timeout",
+		mcp.Description("Optional: how long to wait for the test to end before stopping it "+
+			"(default: the duration plus 10m, or 30m for iterations)."),
+	),
+	mcp.WithBoolean(
+		"cleanup",
+		mcp.Description("Optional: delete the TestRun and the ConfigMap once the test ended (default: true)."),
+	),
+	mcp.WithBoolean(
+		"dry_run",
+		mcp.Description("Optional: return the manifests and k6 arguments without applying them."),
+	),
+	mcp.WithString(
+		"confirmation_token",
+		mcp.Description(confirmationTokenDescription),
+	),
+)
+
+const (
+	// MaxDistributedVUs is the maximum number of VUs of a distributed run.
+	MaxDistributedVUs = 10000
+
+	// MaxDistributedDuration is the maximum duration of a distributed run.
+	MaxDistributedDuration = time.Hour
+
+	defaultParallelism = 2
+	maxParallelism     = 50
+	// distributedStartup is the time allowed to schedule the runners and
+	// pull their image, on top of the duration of the test.
+	distributedStartup = 10 * time.Minute
+	// distributedIterationsTimeout is the default timeout of runs bounded by
+	// iterations.
+	distributedIterationsTimeout = 30 * time.Minute
+	// distributedPollInterval is the time between two status checks.
+	distributedPollInterval = 5 * time.Second
+	// distributedEntry and distributedScript are the files of the ConfigMap.
+	distributedEntry  = "entry.js"
+	distributedScript = "script.js"
+	// distributedSummaryMarker starts the log line holding the summary
+	// export of a runner.
+	distributedSummaryMarker = "mcp-k6-summary: "
+)
+
+// distributedSummaryHandlerSource is the handleSummary of distributed entry
+// modules: runner pods keep no files, so the summary export goes to the log
+// after the marker, on a line of its own, next to the text summary.
+const distributedSummaryHandlerSource = summaryExportSource + `export function handleSummary(data) {
+  const outputs = typeof script.handleSummary === 'function'
+    ? Object.assign({}, script.handleSummary(data))
+    : { stdout: mcpTextSummary(data, { indent: ' ', enableColors: false }) };
+  outputs.stdout = (outputs.stdout || '') + '\n' + summaryMarker + mcpExportSummary(data) + '\n';
+  return outputs;
+}
+`
+
+// RegisterRunDistributedTool registers the run_distributed tool with the MCP
+// server.
+func RegisterRunDistributedTool(
+	s *server.MCPServer,
+	ws *workspace.Workspace,
+	ip *importpolicy.Policy,
+	tp *targetpolicy.Policy,
+	gate *approval.Gate,
+	ov *ownership.Verifier,
+) {
+	s.AddTool(RunDistributedTool, withToolLogger("run_distributed",
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return runDistributed(ctx, ws, ip, tp, gate, ov, request)
+		}))
+}
+
+// distributedRun is a distributed run read from a request.
+type distributedRun struct {
+	Spec       k6operator.Spec
+	Kubeconfig string
+	Context    string
+	Timeout    time.Duration
+	Cleanup    bool
+}
+
+// distributedEvent is a change of the status of a distributed run.
+type distributedEvent struct {
+	// At is the time since the TestRun was applied.
+	At    string `json:"at"`
+	Stage string `json:"stage,omitempty"`
+	// Pods counts the runner pods by phase.
+	Pods map[string]int `json:"pods,omitempty"`
+}
+
+// distributedRunner is a runner pod of an ended distributed run.
+type distributedRunner struct {
+	k6operator.Pod
+	// Summary tells whether the summary export of the runner was found in
+	// its log.
+	Summary bool `json:"summary"`
+}
+
+// runDistributedResponse is the JSON structure returned by the tool.
+type runDistributedResponse struct {
+	Name        string `json:"name"`
+	Namespace   string `json:"namespace"`
+	Parallelism int    `json:"parallelism"`
+	Arguments   string `json:"arguments"`
+	// Manifests are returned by dry runs.
+	Manifests string `json:"manifests,omitempty"`
+	Stage     string `json:"stage,omitempty"`
+	Success   bool   `json:"success"`
+	Duration  string `json:"duration,omitempty"`
+	Error     string `json:"error,omitempty"`
+	// Timeline lists the changes of the stage of the test and of the phases
+	// of its runners.
+	Timeline []distributedEvent  `json:"timeline,omitempty"`
+	Runners  []distributedRunner `json:"runners,omitempty"`
+	// Metrics and Thresholds are those of the merged summaries of the
+	// runners.
+	Metrics    map[string]map[string]float64 `json:"metrics,omitempty"`
+	Thresholds []summary.Threshold           `json:"thresholds,omitempty"`
+	Warnings   []string                      `json:"warnings,omitempty"`
+	NextSteps  []string                      `json:"next_steps"`
+}
+
+func runDistributed(
+	ctx context.Context,
+	ws *workspace.Workspace,
+	ip *importpolicy.Policy,
+	tp *targetpolicy.Policy,
+	gate *approval.Gate,
+	ov *ownership.Verifier,
+	request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	logger := logging.LoggerFromContext(ctx)
+
+	script, options, err := distributedRequest(ctx, ws, ip, tp, request,
+		"run_distributed", MaxDistributedVUs, MaxDistributedDuration)
+	if err != nil {
+		return requestError(err), nil
+	}
+	if err := verifyOwnership(ctx, ov, ws, script, options, options.VUs); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if result := confirmRun(ctx, gate, request, script, options); result != nil {
+		return result, nil
+	}
+	run, err := distributedArguments(request, script, options)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	manifests, err := k6operator.Manifests(run.Spec)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &runDistributedResponse{
+		Name:        run.Spec.Name,
+		Namespace:   run.Spec.Namespace,
+		Parallelism: run.Spec.Parallelism,
+		Arguments:   strings.Join(run.Spec.Arguments, " "),
+	}
+	if len(options.Env) > 0 {
+		resp.Warnings = append(resp.Warnings, "The env_file variables are written in the TestRun resource, "+
+			"readable by whoever may read it in the namespace: keep credentials in Kubernetes secrets")
+	}
+	if request.GetBool("dry_run", false) {
+		resp.Manifests = manifests
+		resp.Success = true
+		resp.NextSteps = []string{
+			"Apply the manifests with kubectl apply -f - in a cluster running the k6-operator, " +
+				"or call run_distributed without dry_run to apply and follow them",
+		}
+		return marshalResponse(ctx, logger, resp)
+	}
+
+	kubectl, err := k6operator.NewKubectl(run.Kubeconfig, run.Context, run.Spec.Namespace)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if err := kubectl.Apply(ctx, manifests); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("%v; check that the k6-operator is installed "+
+			"(kubectl get crd testruns.k6.io) and that the namespace exists", err)), nil
+	}
+	logger.InfoContext(ctx, "Distributed run applied",
+		slog.String("name", run.Spec.Name),
+		slog.String("namespace", run.Spec.Namespace),
+		slog.Int("parallelism", run.Spec.Parallelism),
+		slog.Int("vus", options.VUs))
+
+	followDistributedRun(ctx, kubectl, run, resp)
+
+	if run.Cleanup {
+		if err := kubectl.Delete(context.WithoutCancel(ctx), run.Spec.Name); err != nil {
+			resp.Warnings = append(resp.Warnings, fmt.Sprintf("The TestRun was not deleted: %v", err))
+		}
+	}
+	resp.NextSteps = append([]string{}, distributedNextSteps(resp, run.Cleanup)...)
+
+	logger.InfoContext(ctx, "Distributed run ended",
+		slog.String("name", run.Spec.Name),
+		slog.String("stage", resp.Stage),
+		slog.Bool("success", resp.Success),
+		slog.String("duration", resp.Duration))
+
+	return marshalResponse(ctx, logger, resp)
+}
+
+// distributedRequest reads the script and load of a request of the tool
+// splitting runs across machines, up to maxVUs and maxDuration, and checks
+// the script against the import and target policies.
+func distributedRequest(
+	ctx context.Context,
+	ws *workspace.Workspace,
+	ip *importpolicy.Policy,
+	tp *targetpolicy.Policy,
+	request mcp.CallToolRequest,
+	tool string,
+	maxVUs int,
+	maxDuration time.Duration,
+) (string, *RunOptions, error) {
+	script, scriptPath, err := readScriptArgument(ctx, ws, request)
+	if err != nil {
+		return "", nil, err
+	}
+	for _, imp := range scriptinfo.Analyze(script).Imports {
+		if imp.Kind == "local" {
+			return "", nil, fmt.Errorf("the script imports the local module %s (line %d): %s runs "+
+				"single-file scripts, bundle it first, e.g. with esbuild", imp.Module, imp.Line, tool)
+		}
+	}
+	env, err := readEnvFileArgument(ctx, ws, request)
+	if err != nil {
+		return "", nil, err
+	}
+	if err := checkImportPolicy(ctx, ws, ip, script, scriptPath, nil); err != nil {
+		return "", nil, err
+	}
+	if err := checkTargetPolicy(ctx, ws, tp, script, scriptPath, nil, env); err != nil {
+		return "", nil, err
+	}
+
+	options := &RunOptions{
+		VUs:        request.GetInt("vus", 0),
+		Duration:   strings.TrimSpace(request.GetString("duration", DefaultDuration)),
+		Iterations: request.GetInt("iterations", 0),
+		ScriptPath: scriptPath,
+		Env:        env,
+	}
+	switch {
+	case options.VUs < 1 || options.VUs > maxVUs:
+		return "", nil, fmt.Errorf("vus must be between 1 and %d", maxVUs)
+	case options.Iterations < 0:
+		return "", nil, errors.New("iterations must not be negative")
+	case options.Iterations > 0:
+		options.Duration = ""
+	default:
+		d, err := time.ParseDuration(options.Duration)
+		if err != nil || d <= 0 || d > maxDuration {
+			return "", nil, fmt.Errorf("duration must be a duration up to %s, such as 1m", maxDuration)
+		}
+	}
+	return script, options, nil
+}
+
+// distributedArguments reads the cluster, runners and waiting parameters of
+// request, and builds the spec of the TestRun of script.
+func distributedArguments(request mcp.CallToolRequest, script string, options *RunOptions) (distributedRun, error) {
+	run := distributedRun{
+		Kubeconfig: strings.TrimSpace(request.GetString("kubeconfig", "")),
+		Context:    strings.TrimSpace(request.GetString("context", "")),
+		Cleanup:    request.GetBool("cleanup", true),
+		Spec: k6operator.Spec{
+			Name:        strings.TrimSpace(request.GetString("name", "")),
+			Namespace:   strings.TrimSpace(request.GetString("namespace", "default")),
+			Entry:       distributedEntry,
+			Parallelism: request.GetInt("parallelism", defaultParallelism),
+			Image:       strings.TrimSpace(request.GetString("image", "")),
+			Env:         options.Env,
+		},
+	}
+	if run.Spec.Name == "" {
+		run.Spec.Name = "mcp-k6-" + randomSuffix()
+	}
+	if run.Spec.Namespace == "" {
+		run.Spec.Namespace = "default"
+	}
+	if err := k6operator.CheckName(run.Spec.Name); err != nil {
+		return run, err
+	}
+	if err := k6operator.CheckName(run.Spec.Namespace); err != nil {
+		return run, fmt.Errorf("namespace: %w", err)
+	}
+	switch p := run.Spec.Parallelism; {
+	case p < 1 || p > maxParallelism:
+		return run, fmt.Errorf("parallelism must be between 1 and %d", maxParallelism)
+	case p > options.VUs:
+		return run, fmt.Errorf("parallelism %d exceeds the %d VUs: every runner needs at least one VU",
+			p, options.VUs)
+	}
+
+	switch timeout := strings.TrimSpace(request.GetString("timeout", "")); {
+	case timeout != "":
+		d, err := time.ParseDuration(timeout)
+		if err != nil || d <= 0 {
+			return run, fmt.Errorf("invalid timeout %q: expected a duration such as 30m", timeout)
+		}
+		run.Timeout = d
+	case options.Iterations > 0:
+		run.Timeout = distributedIterationsTimeout
+	default:
+		d, _ := time.ParseDuration(options.Duration)
+		run.Timeout = d + distributedStartup
+	}
+
+	entry, err := entryModule("./"+distributedScript, script, &RunOptions{})
+	if err != nil {
+		return run, fmt.Errorf("generating entry module failed; reason: %w", err)
+	}
+	entry += fmt.Sprintf("const summaryMarker = %s;\n", jsString(distributedSummaryMarker)) +
+		distributedSummaryHandlerSource
+	run.Spec.Files = map[string]string{distributedScript: script, distributedEntry: entry}
+
+	run.Spec.Arguments = []string{"--vus", strconv.Itoa(options.VUs)}
+	if options.Iterations > 0 {
+		run.Spec.Arguments = append(run.Spec.Arguments, "--iterations", strconv.Itoa(options.Iterations))
+	} else {
+		run.Spec.Arguments = append(run.Spec.Arguments, "--duration", options.Duration)
+	}
+	return run, nil
+}
+
+// followDistributedRun follows the TestRun of run until it ends or times
+// out, then reads the summaries of its runners into resp.
+func followDistributedRun(
+	ctx context.Context, kubectl *k6operator.Kubectl, run distributedRun, resp *runDistributedResponse,
+) {
+	logger := logging.LoggerFromContext(ctx)
+	start := time.Now()
+	waitCtx, cancel := context.WithTimeout(ctx, run.Timeout)
+	defer cancel()
+
+	status, err := kubectl.Follow(waitCtx, run.Spec.Name, run.Spec.Parallelism, distributedPollInterval,
+		func(s k6operator.Status) {
+			event := distributedEvent{
+				At:    time.Since(start).Round(time.Second).String(),
+				Stage: s.Stage,
+				Pods:  make(map[string]int),
+			}
+			for _, p := range s.Pods {
+				event.Pods[p.Phase]++
+			}
+			resp.Timeline = append(resp.Timeline, event)
+			logger.InfoContext(ctx, "Distributed run status",
+				slog.String("name", run.Spec.Name),
+				slog.String("stage", s.Stage),
+				slog.Any("pods", event.Pods))
+		})
+	resp.Stage = status.Stage
+	resp.Duration = time.Since(start).Round(time.Second).String()
+	switch {
+	case errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil:
+		resp.Error = fmt.Sprintf("the test did not end within the %s timeout", run.Timeout)
+	case err != nil:
+		resp.Error = err.Error()
+	}
+
+	// Logs outlive the pods for as long as the TestRun is kept.
+	readCtx := context.WithoutCancel(ctx)
+	var exports []*summary.Export
+	passed := resp.Error == "" && status.Stage != k6operator.StageError && len(status.Pods) > 0
+	for _, p := range status.Pods {
+		runner := distributedRunner{Pod: p}
+		if logs, err := kubectl.Logs(readCtx, p.Name); err == nil {
+			if export := runnerExport(logs); export != nil {
+				exports = append(exports, export)
+				runner.Summary = true
+			}
+		} else {
+			logger.WarnContext(ctx, "Failed to read runner logs",
+				slog.String("pod", p.Name),
+				slog.String("error", err.Error()))
+		}
+		if p.ExitCode == nil || *p.ExitCode != 0 {
+			passed = false
+		}
+		resp.Runners = append(resp.Runners, runner)
+	}
+	if merged := summary.Merge(exports); merged != nil {
+		resp.Metrics = merged.Values()
+		resp.Thresholds = merged.Thresholds()
+	}
+	for _, th := range resp.Thresholds {
+		passed = passed && th.Passed
+	}
+	resp.Success = passed
+}
+
+// runnerExport returns the summary export a runner wrote to its log, or nil
+// when there is none.
+func runnerExport(logs string) *summary.Export {
+	lines := strings.Split(logs, "\n")
+	for _, line := range slices.Backward(lines) {
+		if _, data, ok := strings.Cut(line, distributedSummaryMarker); ok {
+			export, err := summary.ReadExport(strings.NewReader(data))
+			if err != nil {
+				return nil
+			}
+			return export
+		}
+	}
+	return nil
+}
+
+// distributedNextSteps suggests what to do after a distributed run.
+func distributedNextSteps(resp *runDistributedResponse, cleanup bool) []string {
+	var steps []string
+	missing := 0
+	for _, r := range resp.Runners {
+		if !r.Summary {
+			missing++
+		}
+		if r.Reason != "" && r.Reason != "Completed" && r.Reason != "Error" {
+			steps = append(steps, fmt.Sprintf("Runner %s is %s: check it with kubectl describe pod %s -n %s",
+				r.Name, r.Reason, r.Name, resp.Namespace))
+		}
+	}
+	switch {
+	case resp.Error != "" && !cleanup:
+		steps = append(steps, fmt.Sprintf("Check the TestRun with kubectl get testrun %s -n %s -o yaml and "+
+			"the logs of its pods, then delete it with kubectl delete testrun %s -n %s",
+			resp.Name, resp.Namespace, resp.Name, resp.Namespace))
+	case resp.Error != "":
+		steps = append(steps, "Rerun with cleanup=false to keep the TestRun and its pods for inspection")
+	}
+	if len(resp.Runners) > 0 && missing > 0 {
+		steps = append(steps, fmt.Sprintf("%d of %d runners logged no summary, so the metrics leave them out: "+
+			"k6 may have failed to start, see the logs of their pods", missing, len(resp.Runners)))
+	}
+	for _, th := range resp.Thresholds {
+		if !th.Passed {
+			steps = append(steps, "Thresholds were evaluated by each runner on its share of the load, and fail "+
+				"when they failed on one: compare the runners before raising the load further")
+			break
+		}
+	}
+	if resp.Metrics != nil {
+		steps = append(steps, "Percentiles of the merged metrics are the highest of the runners, an upper bound; "+
+			"stream the results to one output, such as Prometheus remote write, for exact percentiles")
+	}
+	if resp.Success {
+		steps = append(steps, "Raise vus and parallelism together to add load, keeping the VUs of each runner "+
+			"within what one pod drives without saturating its CPU")
+	}
+	return steps
+}
+
+// randomSuffix returns 6 random hexadecimal characters.
+func randomSuffix() string {
+	b := make([]byte, 3)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,110 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/grafana/mcp-k6/internal/k6operator"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const distributedTestScript = `import http from 'k6/http';
+export default function () {
+  http.get('https://shop.example.com/');
+}
+`
+
+func TestRunDistributedDryRun(t *testing.T) {
+	t.Parallel()
+
+	result, err := runDistributed(t.Context(), nil, nil, nil, nil, nil, newCallRequest(map[string]any{
+		"script":      distributedTestScript,
+		"vus":         400,
+		"duration":    "10m",
+		"parallelism": 4,
+		"namespace":   "load",
+		"name":        "checkout",
+		"dry_run":     true,
+	}))
+	require.NoError(t, err)
+	require.False(t, result.IsError, "unexpected tool error: %+v", result.Content)
+	var resp runDistributedResponse
+	decodeJSON(t, result, &resp)
+
+	assert.True(t, resp.Success)
+	assert.Equal(t, "checkout", resp.Name)
+	assert.Equal(t, "--vus 400 --duration 10m", resp.Arguments)
+	assert.Contains(t, resp.Manifests, "kind: TestRun")
+	assert.Contains(t, resp.Manifests, "parallelism: 4")
+	assert.Contains(t, resp.Manifests, "file: entry.js")
+	assert.Contains(t, resp.Manifests, "import * as script from \"./script.js\";")
+	assert.Contains(t, resp.Manifests, `const summaryMarker = "mcp-k6-summary: ";`)
+	assert.Contains(t, resp.Manifests, "http.get('https://shop.example.com/');")
+	assert.Empty(t, resp.Runners)
+}
+
+func TestDistributedArguments(t *testing.T) {
+	t.Parallel()
+
+	options := &RunOptions{VUs: 10, Iterations: 1000, Env: map[string]string{"BASE_URL": "https://shop"}}
+	run, err := distributedArguments(newCallRequest(map[string]any{}), distributedTestScript, options)
+	require.NoError(t, err)
+	assert.Regexp(t, `^mcp-k6-[0-9a-f]{6}$`, run.Spec.Name)
+	assert.Equal(t, "default", run.Spec.Namespace)
+	assert.Equal(t, []string{"--vus", "10", "--iterations", "1000"}, run.Spec.Arguments)
+	assert.Equal(t, distributedIterationsTimeout, run.Timeout)
+	assert.Equal(t, options.Env, run.Spec.Env)
+	assert.True(t, run.Cleanup)
+	assert.Equal(t, distributedTestScript, run.Spec.Files[distributedScript])
+
+	for name, args := range map[string]map[string]any{
+		"name":        {"name": "Checkout"},
+		"parallelism": {"parallelism": 20},
+		"timeout":     {"timeout": "soon"},
+	} {
+		_, err := distributedArguments(newCallRequest(args), distributedTestScript, options)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestRunDistributedErrors(t *testing.T) {
+	t.Parallel()
+
+	for name, args := range map[string]map[string]any{
+		"no vus":       {"script": distributedTestScript},
+		"many vus":     {"script": distributedTestScript, "vus": MaxDistributedVUs + 1},
+		"long":         {"script": distributedTestScript, "vus": 10, "duration": "2h"},
+		"local import": {"script": "import { login } from './auth.js';\nexport default function () { login(); }", "vus": 10},
+	} {
+		result, err := runDistributed(t.Context(), nil, nil, nil, nil, nil, newCallRequest(args))
+		require.NoError(t, err, name)
+		assert.True(t, result.IsError, name)
+	}
+}
+
+func TestRunnerExport(t *testing.T) {
+	t.Parallel()
+
+	export := runnerExport("running (10m00.0s), 000/100 VUs\n" +
+		`mcp-k6-summary: {"metrics": {"http_reqs": {"count": 12, "rate": 1.2}}}` + "\n")
+	require.NotNil(t, export)
+	assert.Equal(t, map[string]float64{"count": 12, "rate": 1.2}, export.Values()["http_reqs"])
+	assert.Nil(t, runnerExport("level=error msg=\"could not initialize\"\n"))
+}
+
+func TestDistributedNextSteps(t *testing.T) {
+	t.Parallel()
+
+	failed := 99
+	steps := distributedNextSteps(&runDistributedResponse{
+		Name: "checkout", Namespace: "load", Error: "the test did not end within the 20m0s timeout",
+		Runners: []distributedRunner{
+			{Pod: k6operator.Pod{Name: "checkout-1", Phase: "Pending", Reason: "ImagePullBackOff"}},
+			{Pod: k6operator.Pod{Name: "checkout-2", Phase: "Failed", ExitCode: &failed, Reason: "Error"}, Summary: true},
+		},
+	}, false)
+	require.Len(t, steps, 3)
+	assert.Contains(t, steps[0], "checkout-1 is ImagePullBackOff")
+	assert.Contains(t, steps[1], "kubectl delete testrun checkout -n load")
+	assert.Contains(t, steps[2], "1 of 2 runners logged no summary")
+}
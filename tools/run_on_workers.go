@@ -0,0 +1,330 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/mcp-k6/internal/approval"
+	"github.com/grafana/mcp-k6/internal/importpolicy"
+	"github.com/grafana/mcp-k6/internal/jslib"
+	"github.com/grafana/mcp-k6/internal/logging"
+	"github.com/grafana/mcp-k6/internal/ownership"
+	"github.com/grafana/mcp-k6/internal/summary"
+	"github.com/grafana/mcp-k6/internal/targetpolicy"
+	"github.com/grafana/mcp-k6/internal/worker"
+	"github.com/grafana/mcp-k6/internal/workspace"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// RunOnWorkersTool exposes a tool for running a test across the mcp-k6
+// workers registered with the server.
+//
+//nolint:gochecknoglobals // Shared tool definition registered at startup.
+var RunOnWorkersTool = mcp.NewTool(
+	"run_on_workers",
+	runTool(),
+	mcp.WithDescription(
+		"Run a k6 test split across the mcp-k6 workers registered with this server, for load beyond one machine "+
+			"without Kubernetes. Checks that every worker is reachable and idle, posts each its share of the VUs "+
+			"and iterations, waits for all of them and merges their end-of-test summaries. Each worker runs at most "+
+			fmt.Sprintf("%d VUs for up to %s, and checks the script against its own import and target policies. ",
+				MaxVUs, MaxDuration)+
+			"Workers are mcp-k6 instances started with -worker-addr, registered with -worker. Scripts must be a "+
+			"single file: bundle local modules first.",
+	),
+	mcp.WithString(
+		"script",
+		mcp.Description("The k6 script content to run."),
+	),
+	mcp.WithString(
+		"script_path",
+		mcp.Description(scriptPathDescription),
+	),
+	mcp.WithString(
+		"env_file",
+		mcp.Description(envFileDescription+" The variables are sent to every worker with the script."),
+	),
+	mcp.WithNumber(
+		"vus",
+		mcp.Required(),
+		mcp.Description(fmt.Sprintf("The total VUs of the test, split across the workers (max: %d per worker).",
+			MaxVUs)),
+	),
+	mcp.WithString(
+		"duration",
+		mcp.Description(fmt.Sprintf("Optional: test duration (default: %q, max: %s).", DefaultDuration, MaxDuration)),
+	),
+	mcp.WithNumber(
+		"iterations",
+		mcp.Description("Optional: total iterations shared by the VUs, instead of duration; at least vus."),
+	),
+	mcp.WithString(
+		"confirmation_token",
+		mcp.Description(confirmationTokenDescription),
+	),
+)
+
+// RegisterRunOnWorkersTool registers the run_on_workers tool with the MCP
+// server.
+func RegisterRunOnWorkersTool(
+	s *server.MCPServer,
+	ws *workspace.Workspace,
+	ip *importpolicy.Policy,
+	tp *targetpolicy.Policy,
+	gate *approval.Gate,
+	ov *ownership.Verifier,
+	pool *worker.Pool,
+) {
+	s.AddTool(RunOnWorkersTool, withToolLogger("run_on_workers",
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return runOnWorkers(ctx, ws, ip, tp, gate, ov, pool, request)
+		}))
+}
+
+// WorkerRunFunc returns the function a worker runs the shares of runs its
+// coordinators post with, after checking them against ip and tp. Inline
+// scripts import jslib from mirror, when set.
+func WorkerRunFunc(ip *importpolicy.Policy, tp *targetpolicy.Policy, mirror *jslib.Mirror) worker.RunFunc {
+	return func(ctx context.Context, run worker.Run) (*worker.Result, error) {
+		if err := checkImportPolicy(ctx, nil, ip, run.Script, "", nil); err != nil {
+			return nil, err
+		}
+		if err := checkTargetPolicy(ctx, nil, tp, run.Script, "", nil, run.Env); err != nil {
+			return nil, err
+		}
+		options := &RunOptions{
+			VUs:            run.VUs,
+			Duration:       run.Duration,
+			Iterations:     run.Iterations,
+			Env:            run.Env,
+			SummaryHandler: true,
+			JSLib:          mirror,
+		}
+		result, err := RunK6Test(ctx, run.Script, options)
+		if err != nil {
+			return nil, err
+		}
+		return &worker.Result{
+			Success:  result.Success,
+			ExitCode: result.ExitCode,
+			Error:    result.Error,
+			Duration: result.Duration,
+			Export:   result.Export,
+		}, nil
+	}
+}
+
+// workerShare is the share of a run of one worker, and its outcome.
+type workerShare struct {
+	Host       string `json:"host"`
+	VUs        int    `json:"vus"`
+	Iterations int    `json:"iterations,omitempty"`
+	Success    bool   `json:"success"`
+	ExitCode   *int   `json:"exit_code,omitempty"`
+	Error      string `json:"error,omitempty"`
+	Duration   string `json:"duration,omitempty"`
+	// Summary tells whether the worker returned its summary export.
+	Summary bool `json:"summary"`
+}
+
+// runOnWorkersResponse is the JSON structure returned by the tool.
+type runOnWorkersResponse struct {
+	Success  bool          `json:"success"`
+	VUs      int           `json:"vus"`
+	Duration string        `json:"duration"`
+	Workers  []workerShare `json:"workers"`
+	// Metrics and Thresholds are those of the merged summaries of the
+	// workers.
+	Metrics    map[string]map[string]float64 `json:"metrics,omitempty"`
+	Thresholds []summary.Threshold           `json:"thresholds,omitempty"`
+	Warnings   []string                      `json:"warnings,omitempty"`
+	NextSteps  []string                      `json:"next_steps"`
+}
+
+func runOnWorkers(
+	ctx context.Context,
+	ws *workspace.Workspace,
+	ip *importpolicy.Policy,
+	tp *targetpolicy.Policy,
+	gate *approval.Gate,
+	ov *ownership.Verifier,
+	pool *worker.Pool,
+	request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	logger := logging.LoggerFromContext(ctx)
+
+	workers := pool.Workers()
+	if len(workers) == 0 {
+		return mcp.NewToolResultError("no workers are registered: start mcp-k6 with -worker-addr and " +
+			worker.EnvToken + " on each machine, then pass their URLs to this server with -worker"), nil
+	}
+	script, options, err := distributedRequest(ctx, ws, ip, tp, request,
+		"run_on_workers", MaxVUs*len(workers), MaxDuration)
+	if err != nil {
+		return requestError(err), nil
+	}
+	if options.Iterations > 0 && options.Iterations < options.VUs {
+		return mcp.NewToolResultError(fmt.Sprintf("iterations must be at least the %d vus", options.VUs)), nil
+	}
+	if err := verifyOwnership(ctx, ov, ws, script, options, options.VUs); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if result := confirmRun(ctx, gate, request, script, options); result != nil {
+		return result, nil
+	}
+
+	// Every VU needs a worker, and every worker at least one VU
+	workers = workers[:min(len(workers), options.VUs)]
+	if err := checkWorkers(ctx, workers); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	resp := &runOnWorkersResponse{VUs: options.VUs}
+	for _, w := range workers {
+		if !w.Encrypted() {
+			resp.Warnings = append(resp.Warnings, fmt.Sprintf("Worker %s is reached over http: the script, "+
+				"its env_file variables and the worker token travel unencrypted, serve it behind TLS", w.Host()))
+		}
+	}
+	vus := worker.Split(options.VUs, len(workers))
+	iterations := worker.Split(options.Iterations, len(workers))
+	resp.Workers = make([]workerShare, len(workers))
+	exports := make([]*summary.Export, len(workers))
+
+	logger.InfoContext(ctx, "Run on workers started",
+		slog.Int("workers", len(workers)),
+		slog.Int("vus", options.VUs))
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i, w := range workers {
+		share := &resp.Workers[i]
+		share.Host, share.VUs, share.Iterations = w.Host(), vus[i], iterations[i]
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			exports[i] = runShare(ctx, w, worker.Run{
+				Script:     script,
+				VUs:        share.VUs,
+				Duration:   options.Duration,
+				Iterations: share.Iterations,
+				Env:        options.Env,
+			}, share)
+		}()
+	}
+	wg.Wait()
+	resp.Duration = time.Since(start).Round(time.Second).String()
+
+	passed := true
+	for _, share := range resp.Workers {
+		passed = passed && share.Success
+	}
+	if merged := summary.Merge(exports); merged != nil {
+		resp.Metrics = merged.Values()
+		resp.Thresholds = merged.Thresholds()
+	}
+	for _, th := range resp.Thresholds {
+		passed = passed && th.Passed
+	}
+	resp.Success = passed
+	resp.NextSteps = append([]string{}, workerNextSteps(resp, len(pool.Workers()))...)
+
+	logger.InfoContext(ctx, "Run on workers ended",
+		slog.Int("workers", len(workers)),
+		slog.Bool("success", resp.Success),
+		slog.String("duration", resp.Duration))
+
+	return marshalResponse(ctx, logger, resp)
+}
+
+// checkWorkers returns an error naming the workers that cannot take a run,
+// so that none starts unless all of them can.
+func checkWorkers(ctx context.Context, workers []*worker.Worker) error {
+	problems := make([]string, len(workers))
+	var wg sync.WaitGroup
+	for i, w := range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			status, err := w.Status(ctx)
+			switch {
+			case err != nil:
+				problems[i] = err.Error()
+			case status.Busy:
+				problems[i] = fmt.Sprintf("worker %s: %v", w.Host(), worker.ErrBusy)
+			}
+		}()
+	}
+	wg.Wait()
+
+	var failed []string
+	for _, p := range problems {
+		if p != "" {
+			failed = append(failed, p)
+		}
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+	return fmt.Errorf("no run started, %d of %d workers cannot take it: %s",
+		len(failed), len(workers), strings.Join(failed, "; "))
+}
+
+// runShare runs run on w, recording its outcome in share, and returns its
+// summary export.
+func runShare(ctx context.Context, w *worker.Worker, run worker.Run, share *workerShare) *summary.Export {
+	logger := logging.LoggerFromContext(ctx)
+	result, err := w.Run(ctx, run)
+	if err != nil {
+		logger.WarnContext(ctx, "Worker run failed",
+			slog.String("worker", w.Host()),
+			slog.String("error", err.Error()))
+		share.Error = err.Error()
+		if errors.Is(ctx.Err(), context.Canceled) {
+			share.Error = "cancelled by the client"
+		}
+		return nil
+	}
+	share.Success = result.Success
+	share.ExitCode = &result.ExitCode
+	share.Error = result.Error
+	share.Duration = result.Duration
+	share.Summary = result.Export != nil
+	return result.Export
+}
+
+// workerNextSteps suggests what to do after a run on workers.
+func workerNextSteps(resp *runOnWorkersResponse, registered int) []string {
+	var steps []string
+	missing := 0
+	for _, share := range resp.Workers {
+		if !share.Summary {
+			missing++
+		}
+	}
+	if missing > 0 {
+		steps = append(steps, fmt.Sprintf("%d of %d workers returned no summary, so the metrics leave them out: "+
+			"see their errors, and the logs of the workers", missing, len(resp.Workers)))
+	}
+	for _, th := range resp.Thresholds {
+		if !th.Passed {
+			steps = append(steps, "Thresholds were evaluated by each worker on its share of the load, and fail "+
+				"when they failed on one: compare the workers before raising the load further")
+			break
+		}
+	}
+	if resp.Metrics != nil {
+		steps = append(steps, "Percentiles of the merged metrics are the highest of the workers, an upper bound; "+
+			"stream the results to one output, such as Prometheus remote write, for exact percentiles")
+	}
+	if resp.Success {
+		steps = append(steps, fmt.Sprintf("The %d registered workers take up to %d VUs: register more workers "+
+			"to raise the load further, or use run_distributed on Kubernetes", registered, registered*MaxVUs))
+	}
+	return steps
+}
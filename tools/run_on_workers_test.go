@@ -0,0 +1,175 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/grafana/mcp-k6/internal/importpolicy"
+	"github.com/grafana/mcp-k6/internal/summary"
+	"github.com/grafana/mcp-k6/internal/worker"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testWorkerEnv = []string{worker.EnvToken + "=0123456789abcdef"}
+
+// testWorkerURL returns the URL of a worker running runs with run.
+func testWorkerURL(t *testing.T, run worker.RunFunc) string {
+	t.Helper()
+	h, err := worker.NewHandler(testWorkerEnv, "test", run)
+	require.NoError(t, err)
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+	return srv.URL
+}
+
+// testPool returns a pool of n workers running runs with run.
+func testPool(t *testing.T, n int, run worker.RunFunc) *worker.Pool {
+	t.Helper()
+	urls := make([]string, 0, n)
+	for range n {
+		urls = append(urls, testWorkerURL(t, run))
+	}
+	pool, err := worker.New(urls, testWorkerEnv)
+	require.NoError(t, err)
+	return pool
+}
+
+func TestRunOnWorkers(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu   sync.Mutex
+		runs []worker.Run
+	)
+	pool := testPool(t, 3, func(_ context.Context, run worker.Run) (*worker.Result, error) {
+		mu.Lock()
+		runs = append(runs, run)
+		mu.Unlock()
+		return &worker.Result{Success: true, Export: &summary.Export{Metrics: map[string]map[string]json.RawMessage{
+			"iterations":        {"count": json.RawMessage("100")},
+			"http_req_duration": {"p(95)": json.RawMessage("120"), "thresholds": json.RawMessage(`{"p(95)<500":false}`)},
+		}}}, nil
+	})
+
+	result, err := runOnWorkers(t.Context(), nil, nil, nil, nil, nil, pool, newCallRequest(map[string]any{
+		"script":   distributedTestScript,
+		"vus":      100,
+		"duration": "1m",
+	}))
+	require.NoError(t, err)
+	require.False(t, result.IsError, "unexpected tool error: %+v", result.Content)
+	var resp runOnWorkersResponse
+	decodeJSON(t, result, &resp)
+
+	assert.True(t, resp.Success)
+	require.Len(t, resp.Workers, 3)
+	assert.Equal(t, []int{34, 33, 33}, []int{resp.Workers[0].VUs, resp.Workers[1].VUs, resp.Workers[2].VUs})
+	for _, share := range resp.Workers {
+		assert.True(t, share.Summary)
+	}
+	assert.InDelta(t, 300.0, resp.Metrics["iterations"]["count"], 0)
+	require.Len(t, resp.Thresholds, 1)
+	assert.True(t, resp.Thresholds[0].Passed)
+	assert.Len(t, resp.Warnings, 3, "the test workers are reached over http")
+
+	require.Len(t, runs, 3)
+	total := 0
+	for _, run := range runs {
+		assert.Equal(t, "1m", run.Duration)
+		assert.Equal(t, distributedTestScript, run.Script)
+		total += run.VUs
+	}
+	assert.Equal(t, 100, total)
+}
+
+func TestRunOnWorkersSplitsIterations(t *testing.T) {
+	t.Parallel()
+
+	pool := testPool(t, 3, func(_ context.Context, run worker.Run) (*worker.Result, error) {
+		return &worker.Result{Success: run.Iterations != 3}, nil
+	})
+	result, err := runOnWorkers(t.Context(), nil, nil, nil, nil, nil, pool, newCallRequest(map[string]any{
+		"script":     distributedTestScript,
+		"vus":        2,
+		"iterations": 7,
+	}))
+	require.NoError(t, err)
+	var resp runOnWorkersResponse
+	decodeJSON(t, result, &resp)
+
+	// Two VUs take two of the three workers
+	require.Len(t, resp.Workers, 2)
+	assert.Equal(t, 4, resp.Workers[0].Iterations)
+	assert.Equal(t, 3, resp.Workers[1].Iterations)
+	assert.False(t, resp.Success)
+	assert.Contains(t, resp.NextSteps[0], "2 of 2 workers returned no summary")
+}
+
+func TestRunOnWorkersErrors(t *testing.T) {
+	t.Parallel()
+
+	pool := testPool(t, 2, func(context.Context, worker.Run) (*worker.Result, error) {
+		return &worker.Result{Success: true}, nil
+	})
+	for name, args := range map[string]map[string]any{
+		"no vus":     {"script": distributedTestScript},
+		"many vus":   {"script": distributedTestScript, "vus": 2*MaxVUs + 1},
+		"long":       {"script": distributedTestScript, "vus": 10, "duration": "10m"},
+		"iterations": {"script": distributedTestScript, "vus": 10, "iterations": 5},
+	} {
+		result, err := runOnWorkers(t.Context(), nil, nil, nil, nil, nil, pool, newCallRequest(args))
+		require.NoError(t, err, name)
+		assert.True(t, result.IsError, name)
+	}
+
+	result, err := runOnWorkers(t.Context(), nil, nil, nil, nil, nil, nil, newCallRequest(map[string]any{
+		"script": distributedTestScript, "vus": 10,
+	}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "no workers are registered")
+}
+
+func TestRunOnWorkersChecksWorkersFirst(t *testing.T) {
+	t.Parallel()
+
+	var started atomic.Bool
+	up := testWorkerURL(t, func(context.Context, worker.Run) (*worker.Result, error) {
+		started.Store(true)
+		return &worker.Result{Success: true}, nil
+	})
+	down := httptest.NewServer(nil)
+	down.Close()
+	pool, err := worker.New([]string{up, down.URL}, testWorkerEnv)
+	require.NoError(t, err)
+
+	result, err := runOnWorkers(t.Context(), nil, nil, nil, nil, nil, pool, newCallRequest(map[string]any{
+		"script": distributedTestScript, "vus": 10,
+	}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "no run started, 1 of 2 workers cannot take it")
+	assert.False(t, started.Load())
+}
+
+func TestWorkerRunFuncChecksPolicies(t *testing.T) {
+	t.Parallel()
+
+	ip, err := importpolicy.New("deny", nil)
+	require.NoError(t, err)
+	run := WorkerRunFunc(ip, nil, nil)
+	_, err = run(t.Context(), worker.Run{
+		Script: "import { uuidv4 } from 'https://jslib.k6.io/k6-utils/1.4.0/index.js';\n" +
+			"export default function () { uuidv4(); }",
+		VUs:      1,
+		Duration: "1s",
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "import policy")
+}
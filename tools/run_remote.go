@@ -0,0 +1,382 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/mcp-k6/internal/approval"
+	"github.com/grafana/mcp-k6/internal/importpolicy"
+	"github.com/grafana/mcp-k6/internal/logging"
+	"github.com/grafana/mcp-k6/internal/ownership"
+	"github.com/grafana/mcp-k6/internal/security"
+	"github.com/grafana/mcp-k6/internal/sshexec"
+	"github.com/grafana/mcp-k6/internal/summary"
+	"github.com/grafana/mcp-k6/internal/targetpolicy"
+	"github.com/grafana/mcp-k6/internal/workspace"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// RunRemoteTool exposes a tool for running a test on a remote host over SSH.
+//
+//nolint:gochecknoglobals // Shared tool definition registered at startup.
+var RunRemoteTool = mcp.NewTool(
+	"run_remote",
+	runTool(),
+	mcp.WithDescription(
+		"Run a k6 test on a remote host over SSH, such as a load generator in the datacenter of the target. "+
+			"Stages the script in a temporary directory of the host, runs the k6 installed there, reads back "+
+			"the end-of-test summary and removes the directory. Needs the ssh client on the server and k6 on the "+
+			"host; logs in with key, or the ssh agent and configuration of the server, and never prompts: the "+
+			"host key must already be known. Only the remote hosts the server is configured with can be used. "+
+			"Scripts must be a single file: bundle local modules first.",
+	),
+	mcp.WithString(
+		"host",
+		mcp.Required(),
+		mcp.Description("The host name or IP address of the remote host, one of the server's remote hosts."),
+	),
+	mcp.WithString(
+		"user",
+		mcp.Description("Optional: the user to log in as (default: that of the ssh configuration)."),
+	),
+	mcp.WithNumber(
+		"port",
+		mcp.Description("Optional: the SSH port (default: 22, or that of the ssh configuration)."),
+	),
+	mcp.WithString(
+		"key",
+		mcp.Description("Optional: the name of the private key file, in the server's key directory, to log in "+
+			"with (default: the keys of the ssh agent and configuration)."),
+	),
+	mcp.WithString(
+		"script",
+		mcp.Description("The k6 script content to run."),
+	),
+	mcp.WithString(
+		"script_path",
+		mcp.Description(scriptPathDescription),
+	),
+	mcp.WithString(
+		"env_file",
+		mcp.Description(envFileDescription+" The variables are staged with the script on the host; K6_ variables "+
+			"are rejected."),
+	),
+	mcp.WithNumber(
+		"vus",
+		mcp.Required(),
+		mcp.Description(fmt.Sprintf("The VUs of the test (max: %d).", MaxRemoteVUs)),
+	),
+	mcp.WithString(
+		"duration",
+		mcp.Description(fmt.Sprintf("Optional: test duration (default: %q, max: %s).",
+			DefaultDuration, MaxRemoteDuration)),
+	),
+	mcp.WithNumber(
+		"iterations",
+		mcp.Description("Optional: total iterations shared by the VUs, instead of duration."),
+	),
+	mcp.WithString(
+		"timeout",
+		mcp.Description("Optional: how long to wait for the test to end before stopping it "+
+			"(default: the duration plus 5m, or 30m for iterations)."),
+	),
+	mcp.WithBoolean(
+		"cleanup",
+		mcp.Description("Optional: remove the staged directory from the host once the test ended (default: true)."),
+	),
+	mcp.WithString(
+		"confirmation_token",
+		mcp.Description(confirmationTokenDescription),
+	),
+)
+
+const (
+	// MaxRemoteVUs is the maximum number of VUs of a remote run.
+	MaxRemoteVUs = 1000
+
+	// MaxRemoteDuration is the maximum duration of a remote run.
+	MaxRemoteDuration = 30 * time.Minute
+
+	// remoteStartup is the time allowed to stage the files of a run and
+	// start k6, on top of the duration of the test.
+	remoteStartup = 5 * time.Minute
+	// remoteIterationsTimeout is the default timeout of runs bounded by
+	// iterations.
+	remoteIterationsTimeout = 30 * time.Minute
+	// remoteEntry, remoteScript, remoteEnv and remoteSummary are the files
+	// of the staged directory.
+	remoteEntry   = "entry.js"
+	remoteScript  = "script.js"
+	remoteEnv     = "env.sh"
+	remoteSummary = "summary.json"
+)
+
+// RegisterRunRemoteTool registers the run_remote tool with the MCP server,
+// when the operator configured the remote hosts it may run tests on.
+func RegisterRunRemoteTool(
+	s *server.MCPServer,
+	ws *workspace.Workspace,
+	ip *importpolicy.Policy,
+	tp *targetpolicy.Policy,
+	gate *approval.Gate,
+	ov *ownership.Verifier,
+	hosts *sshexec.Allowlist,
+) {
+	if hosts == nil {
+		return
+	}
+	s.AddTool(RunRemoteTool, withToolLogger("run_remote",
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return runRemote(ctx, ws, ip, tp, gate, ov, hosts, request)
+		}))
+}
+
+// remoteRun is a remote run read from a request.
+type remoteRun struct {
+	Target  sshexec.Target
+	K6      string
+	Files   map[string]string
+	Args    []string
+	Timeout time.Duration
+	Cleanup bool
+}
+
+// runRemoteResponse is the JSON structure returned by the tool.
+type runRemoteResponse struct {
+	Host      string `json:"host"`
+	Success   bool   `json:"success"`
+	ExitCode  *int   `json:"exit_code,omitempty"`
+	Duration  string `json:"duration"`
+	Error     string `json:"error,omitempty"`
+	Arguments string `json:"arguments"`
+	Stdout    string `json:"stdout,omitempty"`
+	Stderr    string `json:"stderr,omitempty"`
+	// Metrics, Thresholds and Network are read from the summary k6
+	// exported on the host.
+	Metrics    map[string]map[string]float64 `json:"metrics,omitempty"`
+	Thresholds []summary.Threshold           `json:"thresholds,omitempty"`
+	Network    *summary.Network              `json:"network,omitempty"`
+	Warnings   []string                      `json:"warnings,omitempty"`
+	NextSteps  []string                      `json:"next_steps"`
+}
+
+func runRemote(
+	ctx context.Context,
+	ws *workspace.Workspace,
+	ip *importpolicy.Policy,
+	tp *targetpolicy.Policy,
+	gate *approval.Gate,
+	ov *ownership.Verifier,
+	hosts *sshexec.Allowlist,
+	request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	logger := logging.LoggerFromContext(ctx)
+
+	script, options, err := distributedRequest(ctx, ws, ip, tp, request,
+		"run_remote", MaxRemoteVUs, MaxRemoteDuration)
+	if err != nil {
+		return requestError(err), nil
+	}
+	run, err := remoteArguments(request, script, options)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if run.Target, err = hosts.Resolve(run.Target); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	run.K6 = hosts.K6()
+	// The host runs the load rather than receiving it: only denied hosts are
+	// rejected
+	if err := tp.CheckDenied(run.Target.Host); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("remote host: %v", err)), nil
+	}
+	if err := verifyOwnership(ctx, ov, ws, script, options, options.VUs); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if result := confirmRun(ctx, gate, request, script, options); result != nil {
+		return result, nil
+	}
+	client, err := sshexec.New(run.Target)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	resp := &runRemoteResponse{Host: run.Target.Host, Arguments: strings.Join(run.Args, " ")}
+	start := time.Now()
+	dir, err := client.Stage(ctx, run.Files)
+	if err != nil {
+		if dir != "" {
+			_ = client.Remove(context.WithoutCancel(ctx), dir)
+		}
+		return mcp.NewToolResultError(fmt.Sprintf("%v; check that the host is reachable with "+
+			"ssh -o BatchMode=yes and that its host key is in known_hosts (ssh-keyscan)", err)), nil
+	}
+	logger.InfoContext(ctx, "Remote run started",
+		slog.String("host", run.Target.Host),
+		slog.Int("vus", options.VUs))
+
+	runRemoteTest(ctx, client, dir, run, resp)
+	resp.Duration = time.Since(start).Round(time.Second).String()
+
+	if run.Cleanup {
+		if err := client.Remove(context.WithoutCancel(ctx), dir); err != nil {
+			resp.Warnings = append(resp.Warnings, fmt.Sprintf("The staged directory was not removed: %v", err))
+		}
+	} else {
+		resp.Warnings = append(resp.Warnings, fmt.Sprintf("The script, its env_file variables and the summary "+
+			"are kept in %s on the host", dir))
+	}
+	resp.NextSteps = append([]string{}, remoteNextSteps(resp, run)...)
+
+	logger.InfoContext(ctx, "Remote run ended",
+		slog.String("host", run.Target.Host),
+		slog.Bool("success", resp.Success),
+		slog.String("duration", resp.Duration))
+
+	return marshalResponse(ctx, logger, resp)
+}
+
+// remoteArguments reads the host and waiting parameters of request, and
+// builds the files and k6 arguments of the run of script. The k6 executable
+// is the operator's, set from the allowlist of the hosts.
+func remoteArguments(request mcp.CallToolRequest, script string, options *RunOptions) (remoteRun, error) {
+	run := remoteRun{
+		Target: sshexec.Target{
+			Host: strings.TrimSpace(request.GetString("host", "")),
+			User: strings.TrimSpace(request.GetString("user", "")),
+			Port: request.GetInt("port", 0),
+			Key:  strings.TrimSpace(request.GetString("key", "")),
+		},
+		K6:      sshexec.DefaultK6,
+		Cleanup: request.GetBool("cleanup", true),
+	}
+	if run.Target.Host == "" {
+		return run, errors.New("host is required")
+	}
+
+	switch timeout := strings.TrimSpace(request.GetString("timeout", "")); {
+	case timeout != "":
+		d, err := time.ParseDuration(timeout)
+		if err != nil || d <= 0 {
+			return run, fmt.Errorf("invalid timeout %q: expected a duration such as 30m", timeout)
+		}
+		run.Timeout = d
+	case options.Iterations > 0:
+		run.Timeout = remoteIterationsTimeout
+	default:
+		d, _ := time.ParseDuration(options.Duration)
+		run.Timeout = d + remoteStartup
+	}
+
+	// The variables are exported to k6 on the host, where K6_ ones would
+	// override the options of the run, such as its outputs or thresholds
+	for name := range options.Env {
+		if strings.HasPrefix(strings.ToUpper(name), "K6_") {
+			return run, fmt.Errorf("env_file variable %s: K6_ variables configure k6 itself, and are not "+
+				"staged on the host; pass the options of the run as parameters", name)
+		}
+	}
+
+	entry, err := entryModule("./"+remoteScript, script, &RunOptions{SummaryHandler: true, SummaryExport: remoteSummary})
+	if err != nil {
+		return run, fmt.Errorf("generating entry module failed; reason: %w", err)
+	}
+	run.Files = map[string]string{remoteScript: script, remoteEntry: entry, remoteEnv: remoteEnvFile(options.Env)}
+
+	run.Args = []string{"run", "--vus", strconv.Itoa(options.VUs)}
+	if options.Iterations > 0 {
+		run.Args = append(run.Args, "--iterations", strconv.Itoa(options.Iterations))
+	} else {
+		run.Args = append(run.Args, "--duration", options.Duration)
+	}
+	run.Args = append(run.Args, remoteEntry)
+	return run, nil
+}
+
+// remoteEnvFile renders env as a shell file exporting each variable, sourced
+// before k6 starts, which reads it from __ENV: the values stay off the command
+// lines of the server and of the host. env holds no K6_ variables, which
+// remoteArguments rejects.
+func remoteEnvFile(env map[string]string) string {
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	for _, k := range keys {
+		fmt.Fprintf(&b, "export %s=%s\n", k, sshexec.Quote(env[k]))
+	}
+	return b.String()
+}
+
+// runRemoteTest runs k6 in the staged directory dir until it ends or times
+// out, then reads its summary into resp.
+func runRemoteTest(ctx context.Context, client *sshexec.Client, dir string, run remoteRun, resp *runRemoteResponse) {
+	logger := logging.LoggerFromContext(ctx)
+	runCtx, cancel := context.WithTimeout(ctx, run.Timeout)
+	defer cancel()
+
+	out, err := client.Run(runCtx, dir, append([]string{".", "./" + remoteEnv, "&&", run.K6}, run.Args...)...)
+	resp.Stdout = security.SanitizeOutput(out.Stdout)
+	resp.Stderr = security.SanitizeOutput(out.Stderr)
+	switch {
+	case errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil:
+		// Without a terminal, the host may keep k6 running once ssh is gone
+		resp.Error = fmt.Sprintf("the test did not end within the %s timeout; check that k6 no longer runs "+
+			"on the host", run.Timeout)
+		return
+	case err != nil:
+		resp.Error = err.Error()
+		return
+	}
+	resp.ExitCode = &out.ExitCode
+	if out.ExitCode == 127 {
+		resp.Error = fmt.Sprintf("%s was not found on the host: install k6 there, or start the server with -remote-k6", run.K6)
+		return
+	}
+
+	// Read even when thresholds failed, which k6 reports with exit code 99
+	data, err := client.ReadFile(context.WithoutCancel(ctx), dir, remoteSummary)
+	if err != nil {
+		logger.WarnContext(ctx, "Failed to read remote summary", slog.String("error", err.Error()))
+	} else if export, err := summary.ReadExport(bytes.NewReader(data)); err == nil {
+		resp.Metrics = export.Values()
+		resp.Thresholds = export.Thresholds()
+		resp.Network = export.Network()
+	}
+	passed := out.ExitCode == 0
+	for _, th := range resp.Thresholds {
+		passed = passed && th.Passed
+	}
+	resp.Success = passed
+	if !passed && out.ExitCode != 0 {
+		resp.Error = fmt.Sprintf("k6 test failed with exit code %d", out.ExitCode)
+	}
+}
+
+// remoteNextSteps suggests what to do after a remote run.
+func remoteNextSteps(resp *runRemoteResponse, run remoteRun) []string {
+	var steps []string
+	if resp.ExitCode != nil && resp.Metrics == nil {
+		steps = append(steps, "k6 exported no summary on the host: it may have failed to start, see stderr, "+
+			"and check that the k6 of the host is recent enough for handleSummary (v0.30+)")
+	}
+	if resp.Error != "" && run.Cleanup {
+		steps = append(steps, "Rerun with cleanup=false to keep the staged directory on the host for inspection")
+	}
+	steps = append(steps, networkNextSteps(resp.Network)...)
+	if resp.Success {
+		steps = append(steps, "Raise vus while the host keeps up, then split the load across hosts with "+
+			"run_on_workers or run_distributed beyond what one machine drives")
+	}
+	return steps
+}
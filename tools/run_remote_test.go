@@ -0,0 +1,167 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/grafana/mcp-k6/internal/sshexec"
+	"github.com/grafana/mcp-k6/internal/targetpolicy"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRemoteArguments(t *testing.T) {
+	t.Parallel()
+
+	options := &RunOptions{VUs: 200, Duration: "10m", Env: map[string]string{
+		"TOKEN":    "it's secret",
+		"BASE_URL": "https://shop",
+	}}
+	run, err := remoteArguments(newCallRequest(map[string]any{
+		"host": "loadgen-1.dc.example.com",
+		"user": "k6",
+		"port": 2222,
+		"key":  "~/.ssh/loadgen",
+	}), distributedTestScript, options)
+	require.NoError(t, err)
+	assert.Equal(t, "loadgen-1.dc.example.com", run.Target.Host)
+	assert.Equal(t, 2222, run.Target.Port)
+	assert.Equal(t, "k6", run.K6)
+	assert.Equal(t, []string{"run", "--vus", "200", "--duration", "10m", "entry.js"}, run.Args)
+	assert.Equal(t, 15*time.Minute, run.Timeout)
+	assert.True(t, run.Cleanup)
+	assert.Equal(t, distributedTestScript, run.Files[remoteScript])
+	assert.Contains(t, run.Files[remoteEntry], `import * as script from "./script.js";`)
+	assert.Contains(t, run.Files[remoteEntry], `const summaryExport = "summary.json";`)
+	assert.Equal(t, "export BASE_URL=https://shop\nexport TOKEN='it'\\''s secret'\n", run.Files[remoteEnv])
+
+	for name, args := range map[string]map[string]any{
+		"host":    {},
+		"timeout": {"host": "loadgen", "timeout": "soon"},
+	} {
+		_, err := remoteArguments(newCallRequest(args), distributedTestScript, options)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestRemoteArgumentsK6Env(t *testing.T) {
+	t.Parallel()
+
+	for _, name := range []string{"K6_OUT", "K6_NO_THRESHOLDS", "k6_iterations"} {
+		options := &RunOptions{VUs: 10, Duration: "1m", Env: map[string]string{"BASE_URL": "https://shop", name: "1"}}
+		_, err := remoteArguments(newCallRequest(map[string]any{"host": "loadgen"}), distributedTestScript, options)
+		assert.ErrorContains(t, err, name)
+	}
+}
+
+func TestRunRemoteErrors(t *testing.T) {
+	t.Parallel()
+
+	for name, args := range map[string]map[string]any{
+		"no vus":       {"host": "loadgen", "script": distributedTestScript},
+		"many vus":     {"host": "loadgen", "script": distributedTestScript, "vus": MaxRemoteVUs + 1},
+		"long":         {"host": "loadgen", "script": distributedTestScript, "vus": 10, "duration": "1h"},
+		"bad host":     {"host": "-oProxyCommand=sh", "script": distributedTestScript, "vus": 10},
+		"local import": {"host": "loadgen", "script": "import './auth.js';\nexport default function () {}", "vus": 1},
+	} {
+		result, err := runRemote(t.Context(), nil, nil, nil, nil, nil, testRemoteHosts(t), newCallRequest(args))
+		require.NoError(t, err, name)
+		assert.True(t, result.IsError, name)
+	}
+}
+
+// testRemoteHosts returns an allowlist of the host loadgen.
+func testRemoteHosts(t *testing.T) *sshexec.Allowlist {
+	t.Helper()
+	hosts, err := sshexec.NewAllowlist([]string{"loadgen"}, t.TempDir(), "")
+	require.NoError(t, err)
+	return hosts
+}
+
+func TestRunRemoteHosts(t *testing.T) {
+	t.Parallel()
+
+	deny, err := targetpolicy.New(nil, []string{"loadgen"})
+	require.NoError(t, err)
+	for name, tt := range map[string]struct {
+		hosts *sshexec.Allowlist
+		tp    *targetpolicy.Policy
+		args  map[string]any
+		err   string
+	}{
+		"not configured": {nil, nil, map[string]any{"host": "loadgen"}, "no remote hosts are configured"},
+		"other host":     {testRemoteHosts(t), nil, map[string]any{"host": "db.prod"}, "not in the remote hosts"},
+		"key path": {
+			testRemoteHosts(t), nil, map[string]any{"host": "loadgen", "key": "/root/.ssh/id_rsa"},
+			"key file of the server's key directory",
+		},
+		"denied target": {testRemoteHosts(t), deny, map[string]any{"host": "loadgen"}, "denied by the target policy"},
+	} {
+		tt.args["script"] = distributedTestScript
+		tt.args["vus"] = 10
+		result, err := runRemote(t.Context(), nil, nil, tt.tp, nil, nil, tt.hosts, newCallRequest(tt.args))
+		require.NoError(t, err, name)
+		require.True(t, result.IsError, name)
+		assert.Contains(t, result.Content[0].(mcp.TextContent).Text, tt.err, name)
+	}
+}
+
+// TestRunRemote runs through an ssh stub on the PATH, which runs the remote
+// command in a local shell, and the k6 stub of the allowlist, which writes a
+// summary export.
+func TestRunRemote(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the ssh and k6 stubs are shell scripts")
+	}
+	bin := t.TempDir()
+	stubs := map[string]string{
+		"ssh": `#!/bin/sh
+while [ "$1" != "--" ]; do shift; done
+shift 2
+exec sh -c "$1"
+`,
+		"k6": `#!/bin/sh
+echo "k6 $*"
+test -f entry.js && test -f script.js || exit 1
+echo '{"metrics": {"http_req_duration": {"avg": 12, "p(95)": 30, "thresholds": {"p(95)<20": true}}}}' > summary.json
+exit 99
+`,
+	}
+	for name, script := range stubs {
+		//nolint:forbidigo // Writing the ssh and k6 stubs
+		// #nosec G306 -- Stub executables must be runnable during tests
+		require.NoError(t, os.WriteFile(filepath.Join(bin, name), []byte(script), 0o700))
+	}
+	//nolint:forbidigo // Reading the PATH the stubs are put in front of
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	// The k6 of the operator runs, whatever the call asks for
+	hosts, err := sshexec.NewAllowlist([]string{"loadgen"}, "", filepath.Join(bin, "k6"))
+	require.NoError(t, err)
+	result, err := runRemote(t.Context(), nil, nil, nil, nil, nil, hosts, newCallRequest(map[string]any{
+		"host":     "loadgen",
+		"script":   distributedTestScript,
+		"vus":      20,
+		"duration": "30s",
+		"k6_path":  "/bin/false",
+	}))
+	require.NoError(t, err)
+	require.False(t, result.IsError, "unexpected tool error: %+v", result.Content)
+	var resp runRemoteResponse
+	decodeJSON(t, result, &resp)
+
+	assert.False(t, resp.Success)
+	require.NotNil(t, resp.ExitCode)
+	assert.Equal(t, 99, *resp.ExitCode)
+	assert.Equal(t, "run --vus 20 --duration 30s entry.js", resp.Arguments)
+	assert.Contains(t, resp.Stdout, "k6 run --vus 20 --duration 30s entry.js")
+	assert.InDelta(t, 30.0, resp.Metrics["http_req_duration"]["p(95)"], 0)
+	require.Len(t, resp.Thresholds, 1)
+	assert.False(t, resp.Thresholds[0].Passed)
+	assert.Equal(t, "k6 test failed with exit code 99", resp.Error)
+	assert.Empty(t, resp.Warnings)
+}
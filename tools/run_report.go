@@ -0,0 +1,61 @@
+package tools
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/grafana/mcp-k6/internal/report"
+	"github.com/grafana/mcp-k6/internal/summary"
+)
+
+// Result formats of get_run.
+const (
+	formatJSON     = "json"
+	formatJUnit    = "junit"
+	formatMarkdown = "markdown"
+)
+
+// runReport describes the outcome of an ended run for the report renderers.
+func runReport(run *BackgroundRun) report.Report {
+	r := report.Report{Name: run.ID, Duration: run.Elapsed()}
+	if run.Script != "inline" {
+		r.Name += " (" + filepath.Base(run.Script) + ")"
+	}
+
+	result, err := run.Result()
+	switch {
+	case err != nil:
+		r.Error = err.Error()
+	case result != nil:
+		if s := summary.Parse(result.Stdout); s != nil {
+			r.Thresholds, r.Checks, r.Metrics = s.Thresholds, s.Checks, s.Metrics
+		}
+		// Crossed thresholds are reported as failed test cases instead
+		if !result.Success && result.ExitCode != ThresholdsExitCode {
+			r.Error = result.Error
+			if r.Error == "" {
+				r.Error = fmt.Sprintf("k6 exited with code %d", result.ExitCode)
+			}
+		}
+	}
+	return r
+}
+
+// renderRun renders the outcome of an ended run in a report format. The
+// Markdown report compares the run's metrics with those of baseline, if set.
+func renderRun(run *BackgroundRun, format string, baseline *BackgroundRun) (string, error) {
+	switch format {
+	case formatJUnit:
+		out, err := runReport(run).JUnit()
+		return string(out), err
+	case formatMarkdown:
+		var base *report.Report
+		if baseline != nil {
+			r := runReport(baseline)
+			base = &r
+		}
+		return runReport(run).Markdown(base), nil
+	default:
+		return "", fmt.Errorf("unknown format %q; use %s, %s or %s", format, formatJSON, formatJUnit, formatMarkdown)
+	}
+}
@@ -0,0 +1,80 @@
+package tools
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunReport(t *testing.T) {
+	t.Parallel()
+
+	runs := NewRuns(nil, nil, nil)
+	runs.execute = func(context.Context, string, *RunOptions) (*RunResult, error) {
+		return &RunResult{
+			ExitCode: ThresholdsExitCode,
+			Error:    "k6 test failed with exit code 99",
+			Stdout: "  █ THRESHOLDS\n\n    http_req_duration\n    ✗ 'p(95)<500' p(95)=612ms\n\n" +
+				"  █ TOTAL RESULTS\n\n    http_req_duration..: avg=300ms p(95)=612ms\n" +
+				"    ✓ status is 200\n    ✗ has items\n      ↳  50% — ✓ 1 / ✗ 1\n",
+		}, nil
+	}
+	t.Cleanup(runs.Close)
+
+	run, err := runs.Start(context.Background(), testRunScript, &RunOptions{ScriptPath: "/work/tests/api.js"})
+	require.NoError(t, err)
+	require.NoError(t, run.Wait(context.Background()))
+
+	r := runReport(run)
+	assert.Equal(t, "run-1 (api.js)", r.Name)
+	assert.Empty(t, r.Error, "crossed thresholds are not a run error")
+	assert.Len(t, r.Thresholds, 1)
+	assert.Len(t, r.Checks, 2)
+
+	result := callRunControl(t, newGetRunHandlerFunc(runs), map[string]any{"run_id": "run-1", "format": "junit"})
+	require.False(t, result.IsError)
+	out := result.Content[0].(mcp.TextContent).Text
+	assert.Contains(t, out, `<testsuites name="k6 run-1 (api.js)" tests="4" failures="2" errors="0"`)
+	assert.Contains(t, out, `<failure message="1 of 2 failed" type="check">`)
+
+	second, err := runs.Start(context.Background(), testRunScript, &RunOptions{ScriptPath: "/work/tests/api.js"})
+	require.NoError(t, err)
+	require.NoError(t, second.Wait(context.Background()))
+	result = callRunControl(t, newGetRunHandlerFunc(runs),
+		map[string]any{"run_id": "run-2", "format": "markdown", "baseline_run_id": "run-1"})
+	require.False(t, result.IsError)
+	out = result.Content[0].(mcp.TextContent).Text
+	assert.Contains(t, out, "### k6 run-2 (api.js): ✗ 1 of 1 thresholds failed")
+	assert.Contains(t, out, "compared to run-1 (api.js)")
+	assert.Contains(t, out, "| http_req_duration p(95) | 612ms | 612ms | 0% |")
+
+	result = callRunControl(t, newGetRunHandlerFunc(runs),
+		map[string]any{"run_id": "run-2", "format": "markdown", "baseline_run_id": "run-9"})
+	require.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "unknown run_id")
+
+	result = callRunControl(t, newGetRunHandlerFunc(runs), map[string]any{"run_id": "run-1", "format": "yaml"})
+	require.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, `unknown format "yaml"`)
+}
+
+func TestRunReportError(t *testing.T) {
+	t.Parallel()
+
+	runs := NewRuns(nil, nil, nil)
+	runs.execute = func(context.Context, string, *RunOptions) (*RunResult, error) {
+		return &RunResult{ExitCode: 107, Error: "k6 test failed with exit code 107"}, nil
+	}
+	t.Cleanup(runs.Close)
+
+	run, err := runs.Start(context.Background(), testRunScript, nil)
+	require.NoError(t, err)
+	require.NoError(t, run.Wait(context.Background()))
+
+	r := runReport(run)
+	assert.Equal(t, "run-1", r.Name)
+	assert.Equal(t, "k6 test failed with exit code 107", r.Error)
+}
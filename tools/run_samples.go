@@ -0,0 +1,260 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/grafana/mcp-k6/internal/logging"
+	"github.com/grafana/mcp-k6/internal/samples"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	// DefaultSampleLimit is the number of samples get_run_samples returns by
+	// default.
+	DefaultSampleLimit = 100
+	// MaxSampleLimit bounds the limit of get_run_samples.
+	MaxSampleLimit = 1000
+	// MaxBuckets bounds the buckets of the series of get_run_samples.
+	MaxBuckets = 500
+	// samplesName names the sample file in the artifact directory of a run.
+	samplesName = "samples"
+	// samplesPlaceholder stands for the artifact directory in run plans.
+	samplesPlaceholder = "<artifacts>"
+)
+
+// GetRunSamplesTool exposes a tool for reading the raw samples of a run.
+//
+//nolint:gochecknoglobals // Shared tool definition registered at startup.
+var GetRunSamplesTool = mcp.NewTool(
+	"get_run_samples",
+	readOnlyTool(),
+	mcp.WithDescription(
+		"Read the raw metric samples a run_script call with output kept: the samples selected by metric, "+
+			"tag and time range, and their count, sum, min, max, avg and p95 by metric, or by metric and "+
+			"the value of a tag.",
+	),
+	mcp.WithString(
+		"artifact_id",
+		mcp.Required(),
+		mcp.Description("The artifact_id of the run, from the artifacts of its run_script result."),
+	),
+	mcp.WithArray(
+		"metrics",
+		mcp.Description("Optional: the metrics to select, such as 'http_req_duration' (default: all)."),
+		mcp.WithStringItems(),
+	),
+	mcp.WithObject(
+		"tags",
+		mcp.Description("Optional: tag values samples must have, e.g. {\"status\": \"500\", \"scenario\": \"api\"}."),
+	),
+	mcp.WithString(
+		"from",
+		mcp.Description("Optional: select samples from this time since the start of the run, e.g. '30s'."),
+	),
+	mcp.WithString(
+		"to",
+		mcp.Description("Optional: select samples up to this time since the start of the run, e.g. '2m'."),
+	),
+	mcp.WithString(
+		"group_by",
+		mcp.Description("Optional: a tag to aggregate the selected samples by, such as 'name' or 'status'."),
+	),
+	mcp.WithNumber(
+		"limit",
+		mcp.Description(fmt.Sprintf(
+			"Optional: the number of selected samples to return; all of them are aggregated (default: %d, max: %d).",
+			DefaultSampleLimit, MaxSampleLimit)),
+	),
+	mcp.WithNumber(
+		"buckets",
+		mcp.Description(fmt.Sprintf(
+			"Optional: downsample the selected samples of each aggregate into a series of this many buckets of "+
+				"equal duration, each with its count, sum, min, max and avg, so the shape of a long run fits in "+
+				"the response; set limit to 0 to only get the series (max: %d).", MaxBuckets)),
+	),
+)
+
+// RunArtifacts names the sample file a run kept.
+type RunArtifacts struct {
+	ID      string `json:"artifact_id"`
+	Format  string `json:"format"`
+	Samples string `json:"samples"`
+}
+
+// validateOutputOptions checks the output format of a run.
+func validateOutputOptions(options *RunOptions) error {
+	if options.Output != "" && !slices.Contains(samples.Formats, options.Output) {
+		return &RunError{
+			Type:    "PARAMETER_VALIDATION",
+			Message: fmt.Sprintf("output must be %s", strings.Join(samples.Formats, " or ")),
+		}
+	}
+	return nil
+}
+
+// outputArgs returns the flags making k6 write the samples of the run to its
+// sample file.
+func outputArgs(options *RunOptions) []string {
+	if options.Output == "" {
+		return nil
+	}
+	path := options.SamplesFile
+	if path == "" {
+		path = samplesPlaceholder + "/" + samplesName + "." + options.Output
+	}
+	return []string{"--out", options.Output + "=" + path}
+}
+
+// createSamplesFile creates the artifact directory of a run keeping its
+// samples.
+func createSamplesFile(options *RunOptions) error {
+	id, dir, err := options.Artifacts.Create()
+	if err != nil {
+		return err
+	}
+	options.ArtifactID = id
+	options.SamplesFile = filepath.Join(dir, samplesName+"."+options.Output)
+	return nil
+}
+
+func runArtifacts(options *RunOptions) *RunArtifacts {
+	return &RunArtifacts{ID: options.ArtifactID, Format: options.Output, Samples: options.SamplesFile}
+}
+
+// RegisterGetRunSamplesTool registers the get_run_samples tool, reading the
+// artifacts of runs.
+func RegisterGetRunSamplesTool(s *server.MCPServer, runs *Runs) {
+	s.AddTool(GetRunSamplesTool, withToolLogger("get_run_samples", newGetRunSamplesHandlerFunc(runs)))
+}
+
+type runSamplesResponse struct {
+	ArtifactID string `json:"artifact_id"`
+	Format     string `json:"format"`
+	*samples.Result
+	NextSteps []string `json:"next_steps,omitempty"`
+}
+
+func newGetRunSamplesHandlerFunc(runs *Runs) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		logger := logging.LoggerFromContext(ctx)
+		id, err := request.RequireString("artifact_id")
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		query, err := samplesQuery(request)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		result, format, err := selectRunSamples(runs, id, query)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		resp := runSamplesResponse{ArtifactID: id, Format: format, Result: result}
+		if result.Matched > len(result.Samples) {
+			resp.NextSteps = append(resp.NextSteps, fmt.Sprintf(
+				"%d of %d selected samples are returned; narrow metrics, tags or the time range, "+
+					"read the aggregates, or set buckets for their time series", len(result.Samples), result.Matched))
+		}
+		if result.Matched == 0 {
+			resp.NextSteps = append(resp.NextSteps, "No sample matches; check the metric and tag names, "+
+				"and that the time range is within the run")
+		}
+		return structuredResponse(ctx, logger, resp)
+	}
+}
+
+// selectRunSamples runs q on the samples the run with the given artifact ID
+// kept, and returns their format.
+func selectRunSamples(runs *Runs, id string, q samples.Query) (*samples.Result, string, error) {
+	store := runs.Artifacts()
+	if store == nil {
+		return nil, "", errors.New("run artifacts are not available")
+	}
+	dir, err := store.Dir(id)
+	if err != nil {
+		return nil, "", err
+	}
+	path, format, err := samplesFile(dir)
+	if err != nil {
+		return nil, "", fmt.Errorf("%s: %w", id, err)
+	}
+
+	//nolint:forbidigo // Reading the samples k6 wrote to the artifacts of the run
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to open the samples of %s: %w", id, err)
+	}
+	defer func() { _ = f.Close() }()
+	result, err := samples.Select(f, format, q)
+	if err != nil {
+		return nil, "", err
+	}
+	return result, format, nil
+}
+
+// samplesQuery reads the query of a get_run_samples request.
+func samplesQuery(request mcp.CallToolRequest) (samples.Query, error) {
+	q := samples.Query{
+		Metrics: request.GetStringSlice("metrics", nil),
+		GroupBy: request.GetString("group_by", ""),
+		Limit:   request.GetInt("limit", DefaultSampleLimit),
+		Buckets: request.GetInt("buckets", 0),
+	}
+	if q.Limit < 0 || q.Limit > MaxSampleLimit {
+		return q, fmt.Errorf("limit must be between 0 and %d", MaxSampleLimit)
+	}
+	if q.Buckets < 0 || q.Buckets > MaxBuckets {
+		return q, fmt.Errorf("buckets must be between 0 and %d", MaxBuckets)
+	}
+	if raw, ok := request.GetArguments()["tags"]; ok && raw != nil {
+		obj, ok := raw.(map[string]any)
+		if !ok {
+			return q, errors.New("'tags' must be an object mapping tag names to values")
+		}
+		q.Tags = make(map[string]string, len(obj))
+		for name, v := range obj {
+			value, ok := v.(string)
+			if !ok {
+				return q, fmt.Errorf("'tags.%s' must be a string", name)
+			}
+			q.Tags[name] = value
+		}
+	}
+	var err error
+	for _, r := range []struct {
+		param string
+		dst   *time.Duration
+	}{{"from", &q.From}, {"to", &q.To}} {
+		if v := request.GetString(r.param, ""); v != "" {
+			if *r.dst, err = time.ParseDuration(v); err != nil || *r.dst < 0 {
+				return q, fmt.Errorf("'%s' must be a duration since the start of the run, such as '30s'", r.param)
+			}
+		}
+	}
+	if q.To != 0 && q.To < q.From {
+		return q, errors.New("'to' must not be before 'from'")
+	}
+	return q, nil
+}
+
+// samplesFile returns the sample file in an artifact directory, and its
+// format.
+func samplesFile(dir string) (string, string, error) {
+	for _, format := range samples.Formats {
+		path := filepath.Join(dir, samplesName+"."+format)
+		//nolint:forbidigo // Looking up the sample file of the artifacts of a run
+		if _, err := os.Stat(path); err == nil {
+			return path, format, nil
+		}
+	}
+	return "", "", errors.New("the run kept no samples")
+}
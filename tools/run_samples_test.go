@@ -0,0 +1,73 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/grafana/mcp-k6/internal/artifacts"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetRunSamples(t *testing.T) {
+	t.Parallel()
+
+	runs := NewRuns(nil, nil, nil)
+	runs.artifacts = artifacts.New(t.TempDir(), artifacts.DefaultMaxRuns)
+	t.Cleanup(runs.Close)
+
+	options := &RunOptions{Output: "json", Artifacts: runs.Artifacts()}
+	require.NoError(t, createSamplesFile(options))
+	assert.Equal(t, []string{"--out", "json=" + options.SamplesFile}, outputArgs(options))
+	require.NoError(t, os.WriteFile(options.SamplesFile, []byte(
+		`{"metric":"http_req_duration","type":"Point","data":{"time":"2026-01-02T10:00:00Z","value":100,`+
+			`"tags":{"status":"200"}}}`+"\n"+
+			`{"metric":"http_req_duration","type":"Point","data":{"time":"2026-01-02T10:00:20Z","value":900,`+
+			`"tags":{"status":"500"}}}`+"\n"), 0o600))
+
+	handler := newGetRunSamplesHandlerFunc(runs)
+	result, err := handler(context.Background(), newCallRequest(map[string]any{
+		"artifact_id": options.ArtifactID,
+		"tags":        map[string]any{"status": "500"},
+		"limit":       0.0,
+	}))
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	var resp runSamplesResponse
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &resp))
+	assert.Equal(t, "json", resp.Format)
+	assert.Equal(t, 1, resp.Matched)
+	require.Len(t, resp.Aggregates, 1)
+	assert.InDelta(t, 900, resp.Aggregates[0].Max, 0)
+	assert.Contains(t, resp.NextSteps[0], "0 of 1 selected samples are returned")
+
+	result, err = handler(context.Background(), newCallRequest(map[string]any{
+		"artifact_id": options.ArtifactID,
+		"buckets":     2.0,
+	}))
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	resp = runSamplesResponse{}
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &resp))
+	require.Len(t, resp.Aggregates[0].Series, 2)
+	assert.InDelta(t, 100, resp.Aggregates[0].Series[0].Max, 0)
+	assert.InDelta(t, 900, resp.Aggregates[0].Series[1].Min, 0)
+
+	for _, args := range []map[string]any{
+		{"artifact_id": "artifact-9"},
+		{"artifact_id": options.ArtifactID, "from": "soon"},
+		{"artifact_id": options.ArtifactID, "from": "2m", "to": "1m"},
+		{"artifact_id": options.ArtifactID, "limit": 5000.0},
+		{"artifact_id": options.ArtifactID, "buckets": 1000.0},
+	} {
+		result, err = handler(context.Background(), newCallRequest(args))
+		require.NoError(t, err)
+		assert.True(t, result.IsError, args)
+	}
+
+	require.Error(t, validateOutputOptions(&RunOptions{Output: "xml"}))
+	assert.Equal(t, []string{"--out", "csv=<artifacts>/samples.csv"}, outputArgs(&RunOptions{Output: "csv"}))
+}
@@ -0,0 +1,655 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/mcp-k6/internal/approval"
+	"github.com/grafana/mcp-k6/internal/importpolicy"
+	"github.com/grafana/mcp-k6/internal/jslib"
+	"github.com/grafana/mcp-k6/internal/logging"
+	"github.com/grafana/mcp-k6/internal/ownership"
+	"github.com/grafana/mcp-k6/internal/redact"
+	"github.com/grafana/mcp-k6/internal/suite"
+	"github.com/grafana/mcp-k6/internal/summary"
+	"github.com/grafana/mcp-k6/internal/targetpolicy"
+	"github.com/grafana/mcp-k6/internal/workspace"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	// MaxParallelScripts is the number of scripts of a parallel suite that
+	// run at the same time.
+	MaxParallelScripts = 4
+
+	// maxManifestSize bounds the size of suite manifests.
+	maxManifestSize = 64 * 1024
+)
+
+// Outcomes of the scripts of a suite.
+const (
+	suitePassed  = "passed"
+	suiteFailed  = "failed"
+	suiteError   = "error"
+	suiteSkipped = "skipped"
+)
+
+// RunSuiteTool exposes a tool for running the scripts of a suite manifest
+// and reporting on them together.
+//
+//nolint:gochecknoglobals // Shared tool definition registered at startup.
+var RunSuiteTool = mcp.NewTool(
+	"run_suite",
+	runTool(),
+	mcp.WithDescription(
+		"Run the k6 scripts listed in a suite manifest as one suite, one after the other or in parallel, and "+
+			"return an aggregated pass/fail report. The manifest is YAML: an optional name, parallel and "+
+			"fail_fast flags, shared thresholds and env, and the scripts, each with its path (relative to the "+
+			"manifest) and optional name, vus, duration, iterations, env and thresholds, the latter replacing "+
+			"the shared ones for the same metric, and depends_on, the names of the scripts that must pass "+
+			"before it runs. An optional setup script (setup: {path, env}) runs first with 1 VU for 1 "+
+			"iteration; the fields of the object its setup() returns, such as tokens or seeded IDs, are "+
+			"passed to every script as env vars, and no script runs unless it passes. "+
+			fmt.Sprintf("Up to %d scripts, %d at a time in parallel suites, each within the limits of run_script. ",
+				suite.MaxScripts, MaxParallelScripts)+
+			"A script passes when k6 exits cleanly, with its thresholds met.",
+	),
+	mcp.WithString(
+		"suite_path",
+		mcp.Description("Path to the suite manifest inside a workspace root. Script paths resolve next to it."),
+	),
+	mcp.WithString(
+		"suite",
+		mcp.Description("The suite manifest content, instead of suite_path. Script paths resolve against "+
+			"the workspace roots."),
+	),
+	mcp.WithString(
+		"env_file",
+		mcp.Description(envFileDescription+" The variables are passed to every script, under those of the manifest."),
+	),
+	mcp.WithString(
+		"confirmation_token",
+		mcp.Description(confirmationTokenDescription),
+	),
+)
+
+// RegisterRunSuiteTool registers the run_suite tool with the MCP server.
+func RegisterRunSuiteTool(
+	s *server.MCPServer,
+	ws *workspace.Workspace,
+	rd *redact.Redactor,
+	ip *importpolicy.Policy,
+	tp *targetpolicy.Policy,
+	mirror *jslib.Mirror,
+	gate *approval.Gate,
+	ov *ownership.Verifier,
+) {
+	s.AddTool(RunSuiteTool, withToolLogger("run_suite",
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return runSuiteTool(ctx, RunK6Test, ws, rd, ip, tp, mirror, gate, ov, request)
+		}))
+}
+
+// suiteScript is a script of a suite ready to run.
+type suiteScript struct {
+	name    string
+	script  string
+	options *RunOptions
+}
+
+// suiteScriptResult is the outcome of a script of a suite.
+type suiteScriptResult struct {
+	Name string `json:"name"`
+	Path string `json:"path"`
+	// Status is "passed", "failed" (k6 exited with an error, thresholds
+	// included), "error" (k6 could not run it) or "skipped".
+	Status   string `json:"status"`
+	ExitCode *int   `json:"exit_code,omitempty"`
+	Duration string `json:"duration,omitempty"`
+	Error    string `json:"error,omitempty"`
+	// Thresholds holds the thresholds of the script with their observed
+	// values, and FailedChecks the checks that failed at least once.
+	Thresholds   []summary.Threshold `json:"thresholds,omitempty"`
+	FailedChecks []summary.Check     `json:"failed_checks,omitempty"`
+	// Reason tells why a script was skipped.
+	Reason string `json:"reason,omitempty"`
+	// Outputs names the env vars the setup script passed to the scripts;
+	// their values are not returned.
+	Outputs []string `json:"outputs,omitempty"`
+}
+
+// suiteCounts counts the scripts of a suite by outcome.
+type suiteCounts struct {
+	Total   int `json:"total"`
+	Passed  int `json:"passed"`
+	Failed  int `json:"failed"`
+	Errors  int `json:"errors"`
+	Skipped int `json:"skipped"`
+}
+
+// runSuiteResponse is the JSON structure returned by the tool.
+type runSuiteResponse struct {
+	Name      string              `json:"name,omitempty"`
+	Passed    bool                `json:"passed"`
+	Parallel  bool                `json:"parallel"`
+	Duration  string              `json:"duration"`
+	Counts    suiteCounts         `json:"counts"`
+	Setup     *suiteScriptResult  `json:"setup,omitempty"`
+	Scripts   []suiteScriptResult `json:"scripts"`
+	NextSteps []string            `json:"next_steps"`
+}
+
+func runSuiteTool(
+	ctx context.Context,
+	execute probeFunc,
+	ws *workspace.Workspace,
+	rd *redact.Redactor,
+	ip *importpolicy.Policy,
+	tp *targetpolicy.Policy,
+	mirror *jslib.Mirror,
+	gate *approval.Gate,
+	ov *ownership.Verifier,
+	request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	logger := logging.LoggerFromContext(ctx)
+
+	manifest, dir, err := readManifestArgument(ctx, ws, request)
+	if err != nil {
+		return requestError(err), nil
+	}
+	env, err := readEnvFileArgument(ctx, ws, request)
+	if err != nil {
+		return requestError(err), nil
+	}
+	setup, err := suiteSetup(ctx, ws, ip, tp, manifest, dir, env)
+	if err != nil {
+		return requestError(err), nil
+	}
+	scripts, err := suiteScripts(ctx, ws, ip, tp, manifest, dir, env)
+	if err != nil {
+		return requestError(err), nil
+	}
+
+	load := suiteLoad(manifest, setup, scripts)
+	all := scripts
+	if setup != nil {
+		all = append([]suiteScript{*setup}, scripts...)
+	}
+	for _, s := range all {
+		if err := verifyOwnership(ctx, ov, ws, s.script, s.options, load.VUs); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("script %s: %v", s.name, err)), nil
+		}
+	}
+	sources := make([]string, len(all))
+	for i, s := range all {
+		sources[i] = s.script
+	}
+	if result := confirmLoad(ctx, gate, request, strings.Join(sources, "\n"), load); result != nil {
+		return result, nil
+	}
+	for _, s := range all {
+		s.options.Redactor = rd
+		s.options.JSLib = mirror
+	}
+
+	logger.InfoContext(ctx, "Suite started",
+		slog.String("suite", manifest.Name),
+		slog.Int("scripts", len(scripts)),
+		slog.Bool("parallel", manifest.Parallel))
+	resp := runSuite(ctx, execute, ws, tp, manifest, env, setup, scripts)
+	logger.InfoContext(ctx, "Suite ended",
+		slog.String("suite", manifest.Name),
+		slog.Bool("passed", resp.Passed),
+		slog.Int("failed", resp.Counts.Failed+resp.Counts.Errors),
+		slog.String("duration", resp.Duration))
+
+	return marshalResponse(ctx, logger, resp)
+}
+
+// readManifestArgument returns the suite manifest of a request, and the
+// directory its script paths are relative to: that of suite_path, or none
+// for inline manifests.
+func readManifestArgument(
+	ctx context.Context,
+	ws *workspace.Workspace,
+	request mcp.CallToolRequest,
+) (*suite.Manifest, string, error) {
+	content := request.GetString("suite", "")
+	manifestPath := request.GetString("suite_path", "")
+	switch {
+	case content != "" && manifestPath != "":
+		return nil, "", errors.New("provide only one of 'suite' and 'suite_path'")
+	case content != "":
+		manifest, err := suite.Parse([]byte(content))
+		return manifest, "", err
+	case manifestPath == "":
+		return nil, "", errors.New("provide either 'suite' (the manifest content) or 'suite_path' " +
+			"(a manifest file in the workspace)")
+	case ws == nil:
+		return nil, "", workspace.ErrNoRoots
+	}
+
+	data, resolved, err := ws.ReadFile(ctx, manifestPath, maxManifestSize)
+	if err != nil {
+		return nil, "", fmt.Errorf("reading suite_path: %w", err)
+	}
+	logging.FileOperation(ctx, "workspace", "read_suite", resolved, nil)
+	manifest, err := suite.Parse(data)
+	return manifest, filepath.Dir(resolved), err
+}
+
+// suiteScripts reads the scripts of manifest, with their paths relative to
+// dir when set, and checks each against the run limits and the import and
+// target policies before any of them runs.
+func suiteScripts(
+	ctx context.Context,
+	ws *workspace.Workspace,
+	ip *importpolicy.Policy,
+	tp *targetpolicy.Policy,
+	manifest *suite.Manifest,
+	dir string,
+	env map[string]string,
+) ([]suiteScript, error) {
+	if ws == nil {
+		return nil, workspace.ErrNoRoots
+	}
+	scripts := make([]suiteScript, 0, len(manifest.Scripts))
+	for _, s := range manifest.Scripts {
+		options := &RunOptions{
+			VUs:        max(s.VUs, DefaultVUs),
+			Duration:   s.Duration,
+			Iterations: s.Iterations,
+			Thresholds: manifest.ScriptThresholds(s),
+			Env:        manifest.ScriptEnv(s, env, nil),
+		}
+		if options.Duration == "" && options.Iterations == 0 {
+			options.Duration = DefaultDuration
+		}
+		script, err := readSuiteScript(ctx, ws, ip, tp, dir, s.Name, s.Path, options)
+		if err != nil {
+			return nil, err
+		}
+		scripts = append(scripts, script)
+	}
+	return scripts, nil
+}
+
+// suiteSetup reads the setup script of manifest, if any, to run once with
+// one VU and the env of the suite.
+func suiteSetup(
+	ctx context.Context,
+	ws *workspace.Workspace,
+	ip *importpolicy.Policy,
+	tp *targetpolicy.Policy,
+	manifest *suite.Manifest,
+	dir string,
+	env map[string]string,
+) (*suiteScript, error) {
+	if manifest.Setup == nil {
+		return nil, nil //nolint:nilnil // No setup script.
+	}
+	if ws == nil {
+		return nil, workspace.ErrNoRoots
+	}
+	options := &RunOptions{
+		VUs:            1,
+		Iterations:     1,
+		Env:            manifest.ScriptEnv(suite.Script{Env: manifest.Setup.Env}, env, nil),
+		SummaryHandler: true,
+	}
+	script, err := readSuiteScript(ctx, ws, ip, tp, dir, "setup", manifest.Setup.Path, options)
+	if err != nil {
+		return nil, err
+	}
+	return &script, nil
+}
+
+// readSuiteScript reads the script of a suite at path, relative to dir when
+// set, and checks it and its options against the run limits and the import
+// and target policies.
+func readSuiteScript(
+	ctx context.Context,
+	ws *workspace.Workspace,
+	ip *importpolicy.Policy,
+	tp *targetpolicy.Policy,
+	dir, name, path string,
+	options *RunOptions,
+) (suiteScript, error) {
+	p := filepath.FromSlash(path)
+	if dir != "" && !filepath.IsAbs(p) {
+		p = filepath.Join(dir, p)
+	}
+	data, resolved, err := ws.ReadFile(ctx, p, MaxScriptSize)
+	if err != nil {
+		return suiteScript{}, fmt.Errorf("script %s: reading %s: %w", name, path, err)
+	}
+	script := string(data)
+	options.ScriptPath = resolved
+
+	if err := validateRunOptions(options); err != nil {
+		return suiteScript{}, fmt.Errorf("script %s: %w", name, err)
+	}
+	if err := checkImportPolicy(ctx, ws, ip, script, resolved, nil); err != nil {
+		return suiteScript{}, fmt.Errorf("script %s: %w", name, err)
+	}
+	if err := checkTargetPolicy(ctx, ws, tp, script, resolved, nil, options.Env); err != nil {
+		return suiteScript{}, fmt.Errorf("script %s: %w", name, err)
+	}
+	return suiteScript{name: name, script: script, options: options}, nil
+}
+
+// suiteLoad returns an upper bound of the peak load of a suite. In
+// sequence, that is the largest VUs of its scripts for all their durations;
+// in parallel, the VUs of the largest scripts that fit in the
+// MaxParallelScripts slots, for the longest script, or all of them when
+// some have to wait for a slot. The setup script runs alone, before them.
+func suiteLoad(manifest *suite.Manifest, setup *suiteScript, scripts []suiteScript) approval.Load {
+	load := scriptsLoad(manifest, scripts)
+	if setup != nil {
+		l := plannedLoad(setup.script, setup.options)
+		load.VUs, load.Duration = max(load.VUs, l.VUs), load.Duration+l.Duration
+		load.Unknown = load.Unknown || l.Unknown
+	}
+	return load
+}
+
+// scriptsLoad returns an upper bound of the peak load of the scripts of a
+// suite, as suiteLoad.
+func scriptsLoad(manifest *suite.Manifest, scripts []suiteScript) approval.Load {
+	var load approval.Load
+	vus := make([]int, 0, len(scripts))
+	var longest, total time.Duration
+	for _, s := range scripts {
+		l := plannedLoad(s.script, s.options)
+		load.Unknown = load.Unknown || l.Unknown
+		vus = append(vus, l.VUs)
+		longest, total = max(longest, l.Duration), total+l.Duration
+	}
+	if !manifest.Parallel {
+		load.VUs, load.Duration = slices.Max(vus), total
+		return load
+	}
+	slices.SortFunc(vus, func(a, b int) int { return b - a })
+	for _, v := range vus[:min(len(vus), MaxParallelScripts)] {
+		load.VUs += v
+	}
+	load.Duration = longest
+	if len(scripts) > MaxParallelScripts {
+		load.Duration = total
+	}
+	return load
+}
+
+// runSuite runs the setup script, then scripts as manifest says, and
+// reports on them.
+func runSuite(
+	ctx context.Context,
+	execute probeFunc,
+	ws *workspace.Workspace,
+	tp *targetpolicy.Policy,
+	manifest *suite.Manifest,
+	env map[string]string,
+	setup *suiteScript,
+	scripts []suiteScript,
+) *runSuiteResponse {
+	resp := &runSuiteResponse{
+		Name:     manifest.Name,
+		Parallel: manifest.Parallel,
+		Scripts:  make([]suiteScriptResult, len(scripts)),
+	}
+	for i, s := range scripts {
+		resp.Scripts[i] = suiteScriptResult{Name: s.name, Path: manifest.Scripts[i].Path, Status: suiteSkipped}
+	}
+
+	start := time.Now()
+	ready := true
+	if setup != nil {
+		resp.Setup = &suiteScriptResult{Name: setup.name, Path: manifest.Setup.Path, Status: suiteSkipped}
+		ready = runSuiteSetup(ctx, execute, ws, tp, manifest, env, *setup, scripts, resp.Setup)
+		if !ready {
+			for i := range resp.Scripts {
+				resp.Scripts[i].Reason = "the setup script did not pass"
+			}
+		}
+	}
+	switch {
+	case !ready:
+	case manifest.Parallel:
+		runSuiteParallel(ctx, execute, manifest, scripts, resp.Scripts)
+	default:
+		runSuiteSequence(ctx, execute, manifest, scripts, resp.Scripts)
+	}
+	resp.Duration = time.Since(start).Round(time.Second).String()
+
+	resp.Counts.Total = len(resp.Scripts)
+	for _, r := range resp.Scripts {
+		switch r.Status {
+		case suitePassed:
+			resp.Counts.Passed++
+		case suiteFailed:
+			resp.Counts.Failed++
+		case suiteError:
+			resp.Counts.Errors++
+		default:
+			resp.Counts.Skipped++
+		}
+	}
+	resp.Passed = resp.Counts.Passed == resp.Counts.Total
+	resp.NextSteps = append([]string{}, suiteNextSteps(manifest, resp)...)
+	return resp
+}
+
+// runSuiteSetup runs the setup script and passes the data its setup()
+// returned to the env of scripts, checking them again against the target
+// policy. It reports whether the scripts can run.
+func runSuiteSetup(
+	ctx context.Context,
+	execute probeFunc,
+	ws *workspace.Workspace,
+	tp *targetpolicy.Policy,
+	manifest *suite.Manifest,
+	env map[string]string,
+	setup suiteScript,
+	scripts []suiteScript,
+	result *suiteScriptResult,
+) bool {
+	var data json.RawMessage
+	runSuiteScript(ctx, func(ctx context.Context, script string, options *RunOptions) (*RunResult, error) {
+		run, err := execute(ctx, script, options)
+		if run != nil && run.Export != nil {
+			data = run.Export.SetupData
+		}
+		return run, err
+	}, setup, result)
+	if result.Status != suitePassed {
+		return false
+	}
+
+	outputs, err := suite.Outputs(data)
+	if err == nil {
+		for i, s := range scripts {
+			s.options.Env = manifest.ScriptEnv(manifest.Scripts[i], env, outputs)
+			err = checkTargetPolicy(ctx, ws, tp, s.script, s.options.ScriptPath, nil, s.options.Env)
+			if err != nil {
+				err = fmt.Errorf("with its outputs, script %s: %w", s.name, err)
+				break
+			}
+		}
+	}
+	if err != nil {
+		result.Status, result.Error = suiteError, err.Error()
+		return false
+	}
+	result.Outputs = slices.Sorted(maps.Keys(outputs))
+	return true
+}
+
+// runSuiteSequence runs scripts one after the other, each after those it
+// depends on.
+func runSuiteSequence(
+	ctx context.Context,
+	execute probeFunc,
+	manifest *suite.Manifest,
+	scripts []suiteScript,
+	results []suiteScriptResult,
+) {
+	for _, i := range manifest.Order() {
+		if ctx.Err() != nil {
+			break
+		}
+		if results[i].Reason = blockedBy(manifest, manifest.Scripts[i], results); results[i].Reason != "" {
+			continue
+		}
+		runSuiteScript(ctx, execute, scripts[i], &results[i])
+		if manifest.FailFast && results[i].Status != suitePassed {
+			break
+		}
+	}
+}
+
+// runSuiteParallel runs scripts side by side, MaxParallelScripts at a time,
+// each once those it depends on ended.
+func runSuiteParallel(
+	ctx context.Context,
+	execute probeFunc,
+	manifest *suite.Manifest,
+	scripts []suiteScript,
+	results []suiteScriptResult,
+) {
+	index := make(map[string]int, len(scripts))
+	done := make([]chan struct{}, len(scripts))
+	for i, s := range scripts {
+		index[s.name] = i
+		done[i] = make(chan struct{})
+	}
+	sem := make(chan struct{}, MaxParallelScripts)
+	var wg sync.WaitGroup
+	for i, s := range scripts {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer close(done[i])
+			for _, dep := range manifest.Scripts[i].DependsOn {
+				select {
+				case <-done[index[dep]]:
+				case <-ctx.Done():
+					return
+				}
+			}
+			if results[i].Reason = blockedBy(manifest, manifest.Scripts[i], results); results[i].Reason != "" {
+				return
+			}
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			defer func() { <-sem }()
+			runSuiteScript(ctx, execute, s, &results[i])
+		}()
+	}
+	wg.Wait()
+}
+
+// blockedBy returns why s cannot run: the first script it depends on that
+// did not pass, or "" when it can run.
+func blockedBy(manifest *suite.Manifest, s suite.Script, results []suiteScriptResult) string {
+	for _, dep := range s.DependsOn {
+		for i, d := range manifest.Scripts {
+			if d.Name != dep || results[i].Status == suitePassed {
+				continue
+			}
+			if results[i].Status == suiteSkipped {
+				return fmt.Sprintf("depends on %s, which was skipped", dep)
+			}
+			return fmt.Sprintf("depends on %s, which %s", dep, results[i].Status)
+		}
+	}
+	return ""
+}
+
+// runSuiteScript runs s with execute, recording its outcome in result.
+func runSuiteScript(ctx context.Context, execute probeFunc, s suiteScript, result *suiteScriptResult) {
+	start := time.Now()
+	run, err := execute(ctx, s.script, s.options)
+	result.Duration = time.Since(start).Round(time.Millisecond).String()
+	switch {
+	case err != nil:
+		result.Status, result.Error = suiteError, err.Error()
+		if errors.Is(ctx.Err(), context.Canceled) {
+			result.Error = "cancelled by the client"
+		}
+		return
+	case run == nil:
+		result.Status, result.Error = suiteError, "the run returned no result"
+		return
+	case run.Success:
+		result.Status = suitePassed
+	default:
+		result.Status, result.Error = suiteFailed, run.Error
+	}
+	result.ExitCode = &run.ExitCode
+	result.Thresholds = run.Thresholds
+	if run.Summary != nil {
+		if len(result.Thresholds) == 0 {
+			result.Thresholds = run.Summary.Thresholds
+		}
+		for _, c := range run.Summary.Checks {
+			if !c.Passed {
+				result.FailedChecks = append(result.FailedChecks, c)
+			}
+		}
+	}
+}
+
+// suiteNextSteps suggests what to do after a suite ran.
+func suiteNextSteps(manifest *suite.Manifest, resp *runSuiteResponse) []string {
+	var steps []string
+	var failed []string
+	thresholds := false
+	blocked := 0
+	for _, r := range resp.Scripts {
+		if r.Status == suiteFailed || r.Status == suiteError {
+			failed = append(failed, r.Name)
+		}
+		if r.Status == suiteSkipped && strings.HasPrefix(r.Reason, "depends on") {
+			blocked++
+		}
+		thresholds = thresholds || len(r.Thresholds) > 0
+	}
+	if len(failed) > 0 {
+		steps = append(steps, fmt.Sprintf("%s failed: run it alone with run_script on its path to see its "+
+			"full output, or with preview=true to check one iteration", strings.Join(failed, ", ")))
+	}
+	if resp.Setup != nil && resp.Setup.Status != suitePassed {
+		steps = append(steps, "The setup script did not pass, so no script ran: run it alone with run_script "+
+			"and preview=true to see why, and have its setup() return an object of env var names to values")
+	}
+	if blocked > 0 {
+		steps = append(steps, fmt.Sprintf("%d scripts were skipped as scripts they depend on did not pass: "+
+			"fix those first, their reason field names which", blocked))
+	}
+	if skipped := resp.Counts.Skipped - blocked; skipped > 0 && manifest.FailFast && !manifest.Parallel {
+		steps = append(steps, fmt.Sprintf("%d scripts were skipped as the manifest sets fail_fast; "+
+			"unset it to run every script whatever the outcome of the others", skipped))
+	}
+	if resp.Passed && !thresholds {
+		steps = append(steps, "No script had thresholds, so passing only means k6 exited cleanly: add shared "+
+			"thresholds to the manifest, such as http_req_failed: rate<0.01")
+	}
+	if resp.Parallel && resp.Counts.Failed > 0 {
+		steps = append(steps, "The scripts shared the system under test in parallel: run the suite with "+
+			"parallel: false to tell whether a script fails on its own or under the load of the others")
+	}
+	return steps
+}
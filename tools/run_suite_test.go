@@ -0,0 +1,305 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/grafana/mcp-k6/internal/approval"
+	"github.com/grafana/mcp-k6/internal/suite"
+	"github.com/grafana/mcp-k6/internal/summary"
+	"github.com/grafana/mcp-k6/internal/workspace"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testSuiteWorkspace returns a workspace holding files.
+func testSuiteWorkspace(t *testing.T, files map[string]string) *workspace.Workspace {
+	t.Helper()
+	root := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		//nolint:forbidigo // Test fixture.
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o700))
+		//nolint:forbidigo // Test fixture.
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	}
+	return workspace.New(nil, root)
+}
+
+// fakeSuiteRun returns an execute function failing the scripts whose path
+// holds fail, and records the options of each run.
+func fakeSuiteRun(mu *sync.Mutex, runs map[string]*RunOptions) probeFunc {
+	return func(_ context.Context, _ string, o *RunOptions) (*RunResult, error) {
+		mu.Lock()
+		runs[filepath.Base(o.ScriptPath)] = o
+		mu.Unlock()
+		if strings.Contains(o.ScriptPath, "fail") {
+			return &RunResult{ExitCode: ThresholdsExitCode, Error: "thresholds crossed", Summary: &summary.Summary{
+				Thresholds: []summary.Threshold{{Metric: "http_req_failed", Expression: "rate<0.01", Passed: false}},
+				Checks:     []summary.Check{{Name: "status is 200", Fails: 3, Passed: false}, {Name: "ok", Passed: true}},
+			}}, nil
+		}
+		return &RunResult{Success: true, Thresholds: []summary.Threshold{
+			{Metric: "http_req_failed", Expression: "rate<0.01", Value: "rate=0.00%", Passed: true},
+		}}, nil
+	}
+}
+
+func TestRunSuite(t *testing.T) {
+	t.Parallel()
+
+	ws := testSuiteWorkspace(t, map[string]string{
+		"suites/smoke.yaml": "name: smoke\nfail_fast: true\nthresholds:\n  http_req_failed: rate<0.01\n" +
+			"env:\n  BASE_URL: https://staging.example.com\nscripts:\n" +
+			"  - path: ../tests/login.js\n    vus: 5\n    duration: 10s\n" +
+			"  - path: ../tests/fail.js\n    iterations: 3\n" +
+			"  - path: ../tests/cart.js\n",
+		"tests/login.js": testRunScript,
+		"tests/fail.js":  testRunScript,
+		"tests/cart.js":  testRunScript,
+		".env":           "TOKEN=abc\nBASE_URL=http://localhost\n",
+	})
+	var mu sync.Mutex
+	runs := make(map[string]*RunOptions)
+	result, err := runSuiteTool(t.Context(), fakeSuiteRun(&mu, runs), ws, nil, nil, nil, nil, nil, nil,
+		newCallRequest(map[string]any{"suite_path": "suites/smoke.yaml", "env_file": ".env"}))
+	require.NoError(t, err)
+	require.False(t, result.IsError, "unexpected tool error: %+v", result.Content)
+	var resp runSuiteResponse
+	decodeJSON(t, result, &resp)
+
+	assert.Equal(t, "smoke", resp.Name)
+	assert.False(t, resp.Passed)
+	assert.Equal(t, suiteCounts{Total: 3, Passed: 1, Failed: 1, Skipped: 1}, resp.Counts)
+	require.Len(t, resp.Scripts, 3)
+	assert.Equal(t, suitePassed, resp.Scripts[0].Status)
+	assert.Equal(t, "../tests/login.js", resp.Scripts[0].Path)
+	assert.Equal(t, suiteFailed, resp.Scripts[1].Status)
+	assert.Equal(t, ThresholdsExitCode, *resp.Scripts[1].ExitCode)
+	assert.Len(t, resp.Scripts[1].FailedChecks, 1)
+	assert.False(t, resp.Scripts[1].Thresholds[0].Passed)
+	assert.Equal(t, suiteSkipped, resp.Scripts[2].Status)
+	assert.Contains(t, resp.NextSteps[0], "fail failed")
+	assert.Contains(t, resp.NextSteps[1], "1 scripts were skipped")
+
+	require.Len(t, runs, 2)
+	login := runs["login.js"]
+	assert.Equal(t, 5, login.VUs)
+	assert.Equal(t, "10s", login.Duration)
+	assert.Equal(t, map[string][]string{"http_req_failed": {"rate<0.01"}}, login.Thresholds)
+	assert.Equal(t, map[string]string{"TOKEN": "abc", "BASE_URL": "https://staging.example.com"}, login.Env)
+	assert.Equal(t, 3, runs["fail.js"].Iterations)
+	assert.Empty(t, runs["fail.js"].Duration)
+}
+
+func TestRunSuiteParallel(t *testing.T) {
+	t.Parallel()
+
+	ws := testSuiteWorkspace(t, map[string]string{
+		"a.js": testRunScript, "b.js": testRunScript, "c.js": testRunScript,
+	})
+	var running, peak atomic.Int32
+	execute := func(context.Context, string, *RunOptions) (*RunResult, error) {
+		n := running.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		running.Add(-1)
+		return &RunResult{Success: true}, nil
+	}
+	result, err := runSuiteTool(t.Context(), execute, ws, nil, nil, nil, nil, nil, nil,
+		newCallRequest(map[string]any{
+			"suite": "parallel: true\nscripts:\n  - path: a.js\n  - path: b.js\n  - path: c.js\n",
+		}))
+	require.NoError(t, err)
+	var resp runSuiteResponse
+	decodeJSON(t, result, &resp)
+
+	assert.True(t, resp.Passed)
+	assert.True(t, resp.Parallel)
+	assert.Equal(t, 3, resp.Counts.Passed)
+	assert.Equal(t, int32(3), peak.Load())
+	require.Len(t, resp.NextSteps, 1)
+	assert.Contains(t, resp.NextSteps[0], "No script had thresholds")
+}
+
+func TestRunSuiteChecksScriptsFirst(t *testing.T) {
+	t.Parallel()
+
+	ws := testSuiteWorkspace(t, map[string]string{"a.js": testRunScript})
+	var ran atomic.Bool
+	execute := func(context.Context, string, *RunOptions) (*RunResult, error) {
+		ran.Store(true)
+		return &RunResult{Success: true}, nil
+	}
+	for name, tt := range map[string]struct {
+		args map[string]any
+		err  string
+	}{
+		"missing": {map[string]any{"suite": "scripts:\n  - path: a.js\n  - path: b.js\n"}, "script b: reading b.js"},
+		"vus":     {map[string]any{"suite": "scripts:\n  - path: a.js\n    vus: 500\n"}, "script a:"},
+		"none":    {map[string]any{}, "provide either 'suite'"},
+		"both":    {map[string]any{"suite": "x", "suite_path": "s.yaml"}, "only one of"},
+	} {
+		result, err := runSuiteTool(t.Context(), execute, ws, nil, nil, nil, nil, nil, nil, newCallRequest(tt.args))
+		require.NoError(t, err, name)
+		require.True(t, result.IsError, name)
+		assert.Contains(t, result.Content[0].(mcp.TextContent).Text, tt.err, name)
+	}
+	assert.False(t, ran.Load())
+}
+
+func TestRunSuiteRequiresConfirmation(t *testing.T) {
+	t.Parallel()
+
+	ws := testSuiteWorkspace(t, map[string]string{"a.js": testRunScript, "b.js": testRunScript})
+	gate, err := approval.New(40, 0)
+	require.NoError(t, err)
+	var ran atomic.Bool
+	execute := func(context.Context, string, *RunOptions) (*RunResult, error) {
+		ran.Store(true)
+		return &RunResult{Success: true}, nil
+	}
+	args := map[string]any{
+		"suite": "parallel: true\nscripts:\n  - path: a.js\n    vus: 30\n  - path: b.js\n    vus: 30\n",
+	}
+	result, err := runSuiteTool(t.Context(), execute, ws, nil, nil, nil, nil, gate, nil, newCallRequest(args))
+	require.NoError(t, err)
+	var resp confirmationResponse
+	decodeJSON(t, result, &resp)
+	assert.Equal(t, "requires_confirmation", resp.Status)
+	assert.Equal(t, 60, resp.PeakVUs)
+	assert.False(t, ran.Load())
+}
+
+func TestSuiteLoad(t *testing.T) {
+	t.Parallel()
+
+	scripts := make([]suiteScript, 0, 5)
+	for _, vus := range []int{10, 40, 20, 30, 5} {
+		scripts = append(scripts, suiteScript{
+			script:  testRunScript,
+			options: &RunOptions{VUs: vus, Duration: "1m"},
+		})
+	}
+	m := &suite.Manifest{}
+	assert.Equal(t, 40, suiteLoad(m, nil, scripts).VUs)
+	assert.Equal(t, 5*time.Minute, suiteLoad(m, nil, scripts).Duration)
+
+	m = &suite.Manifest{Parallel: true}
+	assert.Equal(t, 100, suiteLoad(m, nil, scripts).VUs, "the four largest scripts")
+	assert.Equal(t, 5*time.Minute, suiteLoad(m, nil, scripts).Duration, "one script waits for a slot")
+	assert.Equal(t, time.Minute, suiteLoad(m, nil, scripts[:4]).Duration)
+
+	setup := &suiteScript{script: testRunScript, options: &RunOptions{VUs: 1, Duration: "30s"}}
+	assert.Equal(t, 100, suiteLoad(m, setup, scripts).VUs)
+	assert.Equal(t, 5*time.Minute+30*time.Second, suiteLoad(m, setup, scripts).Duration, "the setup runs first")
+}
+
+func TestRunSuiteSetup(t *testing.T) {
+	t.Parallel()
+
+	ws := testSuiteWorkspace(t, map[string]string{
+		"seed.js": testRunScript, "login.js": testRunScript, "cart.js": testRunScript,
+	})
+	var mu sync.Mutex
+	runs := make(map[string]*RunOptions)
+	var order []string
+	execute := func(_ context.Context, _ string, o *RunOptions) (*RunResult, error) {
+		name := filepath.Base(o.ScriptPath)
+		mu.Lock()
+		runs[name], order = o, append(order, name)
+		mu.Unlock()
+		if name == "seed.js" {
+			return &RunResult{Success: true, Export: &summary.Export{
+				SetupData: []byte(`{"TOKEN":"abc","ORDER_ID":42}`),
+			}}, nil
+		}
+		return &RunResult{Success: true}, nil
+	}
+	result, err := runSuiteTool(t.Context(), execute, ws, nil, nil, nil, nil, nil, nil,
+		newCallRequest(map[string]any{
+			"suite": "setup:\n  path: seed.js\n  env:\n    USERS: \"3\"\nenv:\n  TOKEN: unset\nscripts:\n" +
+				"  - path: cart.js\n    depends_on: [login]\n    env:\n      ORDER_ID: \"7\"\n  - path: login.js\n",
+		}))
+	require.NoError(t, err)
+	require.False(t, result.IsError, "unexpected tool error: %+v", result.Content)
+	var resp runSuiteResponse
+	decodeJSON(t, result, &resp)
+
+	assert.True(t, resp.Passed)
+	require.NotNil(t, resp.Setup)
+	assert.Equal(t, suitePassed, resp.Setup.Status)
+	assert.Equal(t, []string{"ORDER_ID", "TOKEN"}, resp.Setup.Outputs)
+	assert.NotContains(t, result.Content[0].(mcp.TextContent).Text, "abc", "output values are not returned")
+	assert.Equal(t, []string{"seed.js", "login.js", "cart.js"}, order)
+
+	seed := runs["seed.js"]
+	assert.Equal(t, 1, seed.VUs)
+	assert.Equal(t, 1, seed.Iterations)
+	assert.True(t, seed.SummaryHandler)
+	assert.Equal(t, map[string]string{"TOKEN": "unset", "USERS": "3"}, seed.Env)
+	assert.Equal(t, map[string]string{"TOKEN": "abc", "ORDER_ID": "42"}, runs["login.js"].Env)
+	assert.Equal(t, map[string]string{"TOKEN": "abc", "ORDER_ID": "7"}, runs["cart.js"].Env)
+}
+
+func TestRunSuiteSetupFails(t *testing.T) {
+	t.Parallel()
+
+	ws := testSuiteWorkspace(t, map[string]string{"fail.js": testRunScript, "a.js": testRunScript})
+	var mu sync.Mutex
+	runs := make(map[string]*RunOptions)
+	result, err := runSuiteTool(t.Context(), fakeSuiteRun(&mu, runs), ws, nil, nil, nil, nil, nil, nil,
+		newCallRequest(map[string]any{"suite": "setup:\n  path: fail.js\nscripts:\n  - path: a.js\n"}))
+	require.NoError(t, err)
+	var resp runSuiteResponse
+	decodeJSON(t, result, &resp)
+
+	assert.False(t, resp.Passed)
+	assert.Equal(t, suiteFailed, resp.Setup.Status)
+	assert.Equal(t, suiteSkipped, resp.Scripts[0].Status)
+	assert.Equal(t, "the setup script did not pass", resp.Scripts[0].Reason)
+	assert.Len(t, runs, 1)
+	assert.Contains(t, resp.NextSteps[len(resp.NextSteps)-1], "The setup script did not pass")
+}
+
+func TestRunSuiteDependencies(t *testing.T) {
+	t.Parallel()
+
+	manifest := "scripts:\n  - path: fail.js\n  - path: a.js\n    depends_on: [fail]\n" +
+		"  - path: b.js\n    depends_on: [a]\n  - path: c.js\n"
+	for _, parallel := range []bool{false, true} {
+		ws := testSuiteWorkspace(t, map[string]string{
+			"fail.js": testRunScript, "a.js": testRunScript, "b.js": testRunScript, "c.js": testRunScript,
+		})
+		var mu sync.Mutex
+		runs := make(map[string]*RunOptions)
+		m := manifest
+		if parallel {
+			m = "parallel: true\n" + m
+		}
+		result, err := runSuiteTool(t.Context(), fakeSuiteRun(&mu, runs), ws, nil, nil, nil, nil, nil, nil,
+			newCallRequest(map[string]any{"suite": m}))
+		require.NoError(t, err)
+		var resp runSuiteResponse
+		decodeJSON(t, result, &resp)
+
+		assert.Equal(t, suiteCounts{Total: 4, Passed: 1, Failed: 1, Skipped: 2}, resp.Counts, "parallel=%v", parallel)
+		assert.Equal(t, "depends on fail, which failed", resp.Scripts[1].Reason)
+		assert.Equal(t, "depends on a, which was skipped", resp.Scripts[2].Reason)
+		assert.Equal(t, suitePassed, resp.Scripts[3].Status)
+		assert.Len(t, runs, 2)
+		assert.Contains(t, strings.Join(resp.NextSteps, "\n"), "2 scripts were skipped as scripts they depend on")
+	}
+}
@@ -0,0 +1,110 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/grafana/mcp-k6/internal/httpdebug"
+	"github.com/grafana/mcp-k6/internal/requestlog"
+	"github.com/grafana/mcp-k6/internal/summary"
+	"github.com/grafana/mcp-k6/internal/waterfall"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedactTraces(t *testing.T) {
+	t.Parallel()
+
+	exchanges, _ := httpdebug.Parse("Request:\n" +
+		"POST /login?token=abc HTTP/1.1\r\n" +
+		"Authorization: Bearer secret\r\n" +
+		"Content-Type: application/json\r\n" +
+		"\r\n" +
+		`{"user":"admin","password":"hunter2"}` + "\n" +
+		"Response:\n" +
+		"HTTP/1.1 200 OK\r\n" +
+		"Set-Cookie: sid=xyz; HttpOnly\r\n" +
+		"\r\n\n")
+	require.Len(t, exchanges, 1)
+
+	redactTraces(nil, exchanges)
+
+	req, res := exchanges[0].Request, exchanges[0].Response
+	assert.Equal(t, "POST /login?token=[REDACTED] HTTP/1.1", req.StartLine)
+	auth, _ := req.Get("Authorization")
+	assert.Equal(t, "Bearer [REDACTED]", auth)
+	contentType, _ := req.Get("Content-Type")
+	assert.Equal(t, "application/json", contentType)
+	assert.Equal(t, `{"user":"admin","password":"[REDACTED]"}`, req.Body)
+	cookie, _ := res.Get("Set-Cookie")
+	assert.Equal(t, "sid=[REDACTED]; HttpOnly", cookie)
+}
+
+func TestValidateRunOptionsHTTPDebug(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, validateRunOptions(&RunOptions{VUs: 1, HTTPDebug: "headers"}))
+	require.Error(t, validateRunOptions(&RunOptions{VUs: 1, HTTPDebug: "verbose"}))
+}
+
+func TestRequestCapture(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, validateRunOptions(&RunOptions{VUs: 1, CaptureRequests: true, SlowestRequests: 10}))
+	require.Error(t, validateRunOptions(&RunOptions{VUs: 1, CaptureRequests: true, SlowestRequests: 1000}))
+
+	assert.Empty(t, requestLogArgs(&RunOptions{}))
+	assert.Equal(t, []string{"--out", "json=" + requestLogPlaceholder, "--system-tags", requestlog.SystemTags},
+		requestLogArgs(&RunOptions{CaptureRequests: true}))
+
+	steps := requestNextSteps(&requestlog.Summary{Total: 10, FailedTotal: 2})
+	require.Len(t, steps, 1)
+	assert.Contains(t, steps[0], "2 of 10 requests failed")
+}
+
+func TestNetworkNextSteps(t *testing.T) {
+	t.Parallel()
+
+	assert.Empty(t, networkNextSteps(nil))
+	assert.Empty(t, networkNextSteps(&summary.Network{Bottleneck: summary.BottleneckServer}))
+
+	steps := networkNextSteps(&summary.Network{
+		Blocked:    summary.Timing{Avg: 150},
+		Connecting: summary.Timing{Avg: 40},
+		Waiting:    summary.Timing{Avg: 20},
+		Bottleneck: summary.BottleneckConnection,
+	})
+	require.Len(t, steps, 1)
+	assert.Contains(t, steps[0], "blocked 150.00ms, connecting 40.00ms")
+}
+
+func TestSummaryNextSteps(t *testing.T) {
+	t.Parallel()
+
+	crossed := []summary.Threshold{{Metric: "http_req_failed", Passed: false}}
+	assert.Empty(t, summaryNextSteps(&summary.Summary{Format: summary.FormatV1, Thresholds: crossed}, nil))
+	assert.Empty(t, summaryNextSteps(&summary.Summary{Format: summary.FormatLegacy}, nil))
+	assert.Empty(t, summaryNextSteps(&summary.Summary{Format: summary.FormatLegacy, Thresholds: crossed},
+		&RunOptions{SummaryHandler: true}))
+
+	steps := summaryNextSteps(&summary.Summary{Format: summary.FormatLegacy, Thresholds: crossed}, nil)
+	require.Len(t, steps, 1)
+	assert.Contains(t, steps[0], "summary_handler")
+}
+
+func TestWaterfallNextSteps(t *testing.T) {
+	t.Parallel()
+
+	assert.Contains(t, waterfallNextSteps(nil)[0], "group()")
+	assert.Equal(t, []string{"--out", "json=" + requestLogPlaceholder, "--system-tags", requestlog.SystemTags},
+		requestLogArgs(&RunOptions{GroupWaterfall: true}))
+
+	steps := waterfallNextSteps(&waterfall.Waterfall{Groups: []*waterfall.Group{
+		{Name: "login", Path: "::login", Avg: 900, Groups: []*waterfall.Group{
+			{Name: "form", Path: "::login::form", Avg: 100, Count: 2},
+			{Name: "submit", Path: "::login::submit", Avg: 800, P95: 1200, Count: 2},
+		}},
+		{Name: "browse", Path: "::browse", Avg: 500, Count: 2},
+	}})
+	require.Len(t, steps, 1)
+	assert.Contains(t, steps[0], "group ::login::submit (avg 800.00ms, p95 1200.00ms over 2 runs)")
+}
@@ -0,0 +1,341 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/grafana/mcp-k6/internal/annotate"
+	"github.com/grafana/mcp-k6/internal/artifacts"
+	"github.com/grafana/mcp-k6/internal/k6api"
+	"github.com/grafana/mcp-k6/internal/logging"
+	"github.com/grafana/mcp-k6/internal/observe"
+	"github.com/grafana/mcp-k6/internal/webhook"
+)
+
+const (
+	// MaxBackgroundRuns is the maximum number of background runs in progress at once.
+	MaxBackgroundRuns = 3
+
+	// maxFinishedRuns bounds the finished background runs kept for get_run.
+	maxFinishedRuns = 20
+)
+
+// Runs tracks the background runs started with run_script.
+type Runs struct {
+	mu    sync.Mutex
+	runs  map[string]*BackgroundRun
+	order []string
+	seq   int
+	// notifier is told when a run ends; nil sends nothing.
+	notifier *webhook.Notifier
+	// execute runs the test; RunK6Test outside of tests.
+	execute func(ctx context.Context, script string, options *RunOptions) (*RunResult, error)
+	// artifacts keeps the sample files of runs, background or not.
+	artifacts *artifacts.Store
+	// observer queries the server-side metrics of runs; nil queries none.
+	observer *observe.Observer
+	// annotator marks runs on Grafana dashboards; nil marks none.
+	annotator *annotate.Annotator
+}
+
+// NewRuns returns an empty background run registry, posting a notification
+// to notifier whenever a run ends, attaching the observations of observer to
+// the results of runs and marking them with annotator.
+func NewRuns(notifier *webhook.Notifier, observer *observe.Observer, annotator *annotate.Annotator) *Runs {
+	return &Runs{
+		runs:      make(map[string]*BackgroundRun),
+		notifier:  notifier,
+		execute:   RunK6Test,
+		artifacts: artifacts.New("", artifacts.DefaultMaxRuns),
+		observer:  observer,
+		annotator: annotator,
+	}
+}
+
+// Artifacts returns the store of the files runs leave behind.
+func (r *Runs) Artifacts() *artifacts.Store {
+	if r == nil {
+		return nil
+	}
+	return r.artifacts
+}
+
+// Observer returns the observer of the server-side metrics of runs.
+func (r *Runs) Observer() *observe.Observer {
+	if r == nil {
+		return nil
+	}
+	return r.observer
+}
+
+// Annotator returns the annotator marking runs on Grafana dashboards.
+func (r *Runs) Annotator() *annotate.Annotator {
+	if r == nil {
+		return nil
+	}
+	return r.annotator
+}
+
+// BackgroundRun is a k6 test running, or run, in the background. k6 serves
+// its REST API on Address while the test runs.
+type BackgroundRun struct {
+	ID     string
+	Script string
+	// Schedule is the ID of the schedule that started the run, if any.
+	Schedule  string
+	StartedAt time.Time
+	Address   string
+	API       *k6api.Client
+
+	// source and options are what the run was started with, to verify the
+	// targets again when it is scaled up.
+	source  string
+	options RunOptions
+
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	mu         sync.Mutex
+	stopped    bool
+	finishedAt time.Time
+	result     *RunResult
+	err        error
+}
+
+// Start validates the run and starts it in the background. The run outlives
+// ctx but keeps its values, such as the request logger.
+func (r *Runs) Start(ctx context.Context, script string, options *RunOptions) (*BackgroundRun, error) {
+	return r.start(ctx, script, options, "")
+}
+
+// start starts a run on behalf of schedule, empty for runs started by hand.
+func (r *Runs) start(ctx context.Context, script string, options *RunOptions, schedule string) (*BackgroundRun, error) {
+	if err := validateRunInput(ctx, script, options); err != nil {
+		return nil, err
+	}
+	if options == nil {
+		options = &RunOptions{}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if n := r.active(); n >= MaxBackgroundRuns {
+		return nil, fmt.Errorf("%d background runs are in progress (limit %d); stop one with stop_run first",
+			n, MaxBackgroundRuns)
+	}
+
+	addr, err := k6api.FreeAddress(ctx)
+	if err != nil {
+		return nil, err
+	}
+	opts := *options
+	opts.APIAddress = addr
+	if opts.Observer == nil {
+		opts.Observer = r.observer
+	}
+	if opts.Annotator == nil {
+		opts.Annotator = r.annotator
+	}
+
+	r.seq++
+	runCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	run := &BackgroundRun{
+		ID:        "run-" + strconv.Itoa(r.seq),
+		Script:    "inline",
+		Schedule:  schedule,
+		StartedAt: time.Now(),
+		Address:   addr,
+		API:       k6api.New(addr),
+		cancel:    cancel,
+		done:      make(chan struct{}),
+	}
+	if opts.ScriptPath != "" {
+		run.Script = opts.ScriptPath
+	}
+	if opts.Checkout != nil {
+		run.Script = opts.Checkout.String()
+	}
+	opts.RunID = run.ID
+	run.source, run.options = script, opts
+	r.runs[run.ID] = run
+	r.order = append(r.order, run.ID)
+	r.prune()
+
+	go func() {
+		defer cancel()
+		defer removeCheckout(runCtx, opts.Checkout)
+		result, err := r.execute(runCtx, script, &opts)
+		run.finish(result, err)
+		logger := logging.LoggerFromContext(runCtx)
+		logger.InfoContext(runCtx, "Background run finished",
+			slog.String("run_id", run.ID),
+			slog.String("state", run.State()))
+		if err := r.notifier.Notify(context.WithoutCancel(runCtx), runNotification(run)); err != nil {
+			logger.WarnContext(runCtx, "Run notification failed",
+				slog.String("run_id", run.ID),
+				slog.String("error", err.Error()))
+		}
+	}()
+	return run, nil
+}
+
+// Get returns the run with the given ID.
+func (r *Runs) Get(id string) (*BackgroundRun, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	run, ok := r.runs[id]
+	if !ok {
+		return nil, fmt.Errorf("unknown run_id %q; list_runs shows the known runs", id)
+	}
+	return run, nil
+}
+
+// List returns the known runs, oldest first.
+func (r *Runs) List() []*BackgroundRun {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	runs := make([]*BackgroundRun, 0, len(r.order))
+	for _, id := range r.order {
+		runs = append(runs, r.runs[id])
+	}
+	return runs
+}
+
+// Close stops every run in progress and waits for them to end.
+func (r *Runs) Close() {
+	for _, run := range r.List() {
+		run.cancel()
+		<-run.done
+	}
+}
+
+// active returns the number of runs in progress. r.mu must be held.
+func (r *Runs) active() int {
+	n := 0
+	for _, run := range r.runs {
+		if !run.Done() {
+			n++
+		}
+	}
+	return n
+}
+
+// prune forgets the oldest finished runs beyond maxFinishedRuns. r.mu must
+// be held.
+func (r *Runs) prune() {
+	finished := 0
+	for i := len(r.order) - 1; i >= 0; i-- {
+		id := r.order[i]
+		if !r.runs[id].Done() {
+			continue
+		}
+		if finished++; finished > maxFinishedRuns {
+			delete(r.runs, id)
+			r.order = append(r.order[:i], r.order[i+1:]...)
+		}
+	}
+}
+
+// Done reports whether the run has ended.
+func (b *BackgroundRun) Done() bool {
+	select {
+	case <-b.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// Wait blocks until the run ends or ctx is done.
+func (b *BackgroundRun) Wait(ctx context.Context) error {
+	select {
+	case <-b.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Result returns the result of an ended run.
+func (b *BackgroundRun) Result() (*RunResult, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.result, b.err
+}
+
+// Elapsed returns how long the run has been, or was, running.
+func (b *BackgroundRun) Elapsed() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.finishedAt.IsZero() {
+		return b.finishedAt.Sub(b.StartedAt)
+	}
+	return time.Since(b.StartedAt)
+}
+
+// State returns "running" while the test runs, and "stopped", "finished" or
+// "failed" once it ended.
+func (b *BackgroundRun) State() string {
+	if !b.Done() {
+		return "running"
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch {
+	case b.stopped:
+		return "stopped"
+	case b.err == nil && b.result != nil && b.result.Success:
+		return "finished"
+	default:
+		return "failed"
+	}
+}
+
+// Stop asks k6 to stop the test, running teardown and the end-of-test
+// summary. When the REST API does not answer, the k6 process is killed and
+// killed is true.
+func (b *BackgroundRun) Stop(ctx context.Context) (status *k6api.Status, killed bool, err error) {
+	if b.Done() {
+		return nil, false, fmt.Errorf("run %s already ended (%s)", b.ID, b.State())
+	}
+	b.mu.Lock()
+	b.stopped = true
+	b.mu.Unlock()
+
+	stop := true
+	status, err = b.API.UpdateStatus(ctx, k6api.StatusUpdate{Stopped: &stop})
+	if err != nil {
+		b.cancel()
+		return nil, true, nil
+	}
+	return status, false, nil
+}
+
+// SetPaused pauses or resumes the test and returns the resulting status.
+func (b *BackgroundRun) SetPaused(ctx context.Context, paused bool) (*k6api.Status, error) {
+	if b.Done() {
+		return nil, fmt.Errorf("run %s already ended (%s)", b.ID, b.State())
+	}
+	return b.API.UpdateStatus(ctx, k6api.StatusUpdate{Paused: &paused})
+}
+
+// Scale sets the active VUs of the test, and its maximum VUs when vusMax is
+// not nil, and returns the resulting status.
+func (b *BackgroundRun) Scale(ctx context.Context, vus int64, vusMax *int64) (*k6api.Status, error) {
+	if b.Done() {
+		return nil, fmt.Errorf("run %s already ended (%s)", b.ID, b.State())
+	}
+	return b.API.UpdateStatus(ctx, k6api.StatusUpdate{VUs: &vus, VUsMax: vusMax})
+}
+
+func (b *BackgroundRun) finish(result *RunResult, err error) {
+	b.mu.Lock()
+	b.finishedAt = time.Now()
+	b.result, b.err = result, err
+	b.mu.Unlock()
+	close(b.done)
+}
@@ -0,0 +1,188 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testRunScript = "export default function () {}"
+
+// fakeK6 returns an execute function that serves a minimal k6 REST API on
+// the run's address until the test is stopped through it or cancelled.
+func fakeK6(t *testing.T) func(ctx context.Context, script string, options *RunOptions) (*RunResult, error) {
+	t.Helper()
+	return func(ctx context.Context, _ string, options *RunOptions) (*RunResult, error) {
+		var lc net.ListenConfig
+		ln, err := lc.Listen(ctx, "tcp", options.APIAddress)
+		if err != nil {
+			return nil, err
+		}
+		stopped := make(chan struct{})
+		var mu sync.Mutex
+		paused := false
+		vus, vusMax := int64(2), int64(10)
+		status := func(w http.ResponseWriter, stop bool) {
+			mu.Lock()
+			defer mu.Unlock()
+			_, _ = fmt.Fprintf(w, `{"data":{"type":"status","id":"default","attributes":`+
+				`{"status":7,"vus":%d,"vus-max":%d,"running":true,"tainted":true,"paused":%t,"stopped":%t}}}`,
+				vus, vusMax, paused, stop)
+		}
+		mux := http.NewServeMux()
+		mux.HandleFunc("GET /v1/status", func(w http.ResponseWriter, _ *http.Request) {
+			status(w, false)
+		})
+		mux.HandleFunc("PATCH /v1/status", func(w http.ResponseWriter, r *http.Request) {
+			var body struct {
+				Data struct {
+					Attributes struct {
+						Paused  *bool  `json:"paused"`
+						Stopped *bool  `json:"stopped"`
+						VUs     *int64 `json:"vus"`
+						VUsMax  *int64 `json:"vus-max"`
+					} `json:"attributes"`
+				} `json:"data"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			attrs := body.Data.Attributes
+			mu.Lock()
+			if attrs.VUsMax != nil {
+				vusMax = *attrs.VUsMax
+			}
+			if attrs.VUs != nil && *attrs.VUs > vusMax {
+				mu.Unlock()
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = io.WriteString(w, `{"errors":[{"title":"Couldn't update status","detail":"can't exceed vus-max"}]}`)
+				return
+			}
+			if attrs.VUs != nil {
+				vus = *attrs.VUs
+			}
+			if attrs.Paused != nil {
+				paused = *attrs.Paused
+			}
+			mu.Unlock()
+			stop := attrs.Stopped != nil && *attrs.Stopped
+			status(w, stop)
+			if stop {
+				close(stopped)
+			}
+		})
+		mux.HandleFunc("GET /v1/metrics", func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = io.WriteString(w, `{"data":[`+
+				`{"type":"metrics","id":"vus","attributes":{"type":"gauge","sample":{"value":2}}},`+
+				`{"type":"metrics","id":"iterations","attributes":{"type":"counter","sample":{"count":40}}}]}`)
+		})
+		srv := &http.Server{Handler: mux, ReadHeaderTimeout: time.Second}
+		go func() { _ = srv.Serve(ln) }()
+		defer func() { _ = srv.Close() }()
+
+		select {
+		case <-stopped:
+			return &RunResult{Success: true, ExitCode: 0}, nil
+		case <-ctx.Done():
+			return nil, errors.New("k6 process killed")
+		}
+	}
+}
+
+func newTestRuns(t *testing.T) *Runs {
+	t.Helper()
+	runs := NewRuns(nil, nil, nil)
+	runs.execute = fakeK6(t)
+	t.Cleanup(runs.Close)
+	return runs
+}
+
+// waitForAPI waits until the run serves its REST API.
+func waitForAPI(t *testing.T, run *BackgroundRun) {
+	t.Helper()
+	require.Eventually(t, func() bool {
+		_, err := run.API.Status(context.Background())
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestRunsStartAndStop(t *testing.T) {
+	t.Parallel()
+
+	runs := newTestRuns(t)
+	run, err := runs.Start(context.Background(), testRunScript, &RunOptions{VUs: 2})
+	require.NoError(t, err)
+	assert.Equal(t, "run-1", run.ID)
+	assert.Equal(t, "inline", run.Script)
+	assert.Equal(t, "running", run.State())
+	waitForAPI(t, run)
+
+	got, err := runs.Get("run-1")
+	require.NoError(t, err)
+	assert.Same(t, run, got)
+
+	status, killed, err := run.Stop(context.Background())
+	require.NoError(t, err)
+	assert.False(t, killed)
+	assert.True(t, status.Stopped)
+
+	require.NoError(t, run.Wait(context.Background()))
+	assert.Equal(t, "stopped", run.State())
+	result, err := run.Result()
+	require.NoError(t, err)
+	assert.True(t, result.Success)
+
+	_, _, err = run.Stop(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "already ended")
+}
+
+func TestRunsStopKillsUnresponsiveRun(t *testing.T) {
+	t.Parallel()
+
+	runs := NewRuns(nil, nil, nil)
+	runs.execute = func(ctx context.Context, _ string, _ *RunOptions) (*RunResult, error) {
+		<-ctx.Done()
+		return nil, errors.New("k6 process killed")
+	}
+	t.Cleanup(runs.Close)
+
+	run, err := runs.Start(context.Background(), testRunScript, nil)
+	require.NoError(t, err)
+	_, killed, err := run.Stop(context.Background())
+	require.NoError(t, err)
+	assert.True(t, killed)
+	require.NoError(t, run.Wait(context.Background()))
+	assert.Equal(t, "stopped", run.State())
+}
+
+func TestRunsLimits(t *testing.T) {
+	t.Parallel()
+
+	runs := newTestRuns(t)
+	for range MaxBackgroundRuns {
+		_, err := runs.Start(context.Background(), testRunScript, nil)
+		require.NoError(t, err)
+	}
+	_, err := runs.Start(context.Background(), testRunScript, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "background runs are in progress")
+
+	_, err = runs.Start(context.Background(), testRunScript, &RunOptions{VUs: MaxVUs + 1})
+	require.Error(t, err)
+
+	_, err = runs.Get("run-99")
+	require.Error(t, err)
+	assert.Len(t, runs.List(), MaxBackgroundRuns)
+}
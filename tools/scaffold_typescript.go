@@ -0,0 +1,92 @@
+package tools
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/grafana/mcp-k6/internal/logging"
+	"github.com/grafana/mcp-k6/internal/tsproject"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// ScaffoldTypeScriptTool exposes a tool for generating a TypeScript workspace
+// for k6 tests.
+//
+//nolint:gochecknoglobals // Shared tool definition registered at startup.
+var ScaffoldTypeScriptTool = mcp.NewTool(
+	"scaffold_typescript_project",
+	readOnlyTool(),
+	mcp.WithDescription(
+		"Generate the files of a working TypeScript workspace for k6 tests: package.json with @types/k6 "+
+			"and build scripts, tsconfig.json, a typed sample test in src/test.ts and, for webpack, "+
+			"its configuration. Bundlers: esbuild (default) bundles src/*.ts into dist; webpack follows "+
+			"the k6 TypeScript template; none runs the .ts files with k6's own TypeScript support and "+
+			"keeps tsc for type checking. Returns the files to create; nothing is written.",
+	),
+	mcp.WithString(
+		"name",
+		mcp.Description("Optional: npm package name (default: 'k6-tests')."),
+	),
+	mcp.WithString(
+		"bundler",
+		mcp.Description("Optional: how tests are built before k6 runs them (default: 'esbuild')."),
+		mcp.Enum(tsproject.Bundlers...),
+	),
+	mcp.WithString(
+		"types_version",
+		mcp.Description("Optional: @types/k6 version range matching the k6 version used (default: '"+
+			tsproject.DefaultTypesVersion+"')."),
+	),
+	mcp.WithString(
+		"url",
+		mcp.Description("Optional: URL the sample test requests (default: '"+tsproject.DefaultURL+"')."),
+	),
+)
+
+// scaffoldTypeScriptResponse is the JSON structure returned by the tool.
+type scaffoldTypeScriptResponse struct {
+	*tsproject.Project
+	Bundler   string   `json:"bundler"`
+	NextSteps []string `json:"next_steps"`
+}
+
+// RegisterScaffoldTypeScriptTool registers the scaffold_typescript_project tool
+// with the MCP server.
+func RegisterScaffoldTypeScriptTool(s *server.MCPServer) {
+	s.AddTool(ScaffoldTypeScriptTool, withToolLogger("scaffold_typescript_project", scaffoldTypeScript))
+}
+
+func scaffoldTypeScript(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger := logging.LoggerFromContext(ctx)
+
+	bundler := request.GetString("bundler", tsproject.BundlerEsbuild)
+	project, err := tsproject.Generate(tsproject.Options{
+		Name:         request.GetString("name", ""),
+		Bundler:      bundler,
+		TypesVersion: request.GetString("types_version", ""),
+		URL:          request.GetString("url", ""),
+	})
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	nextSteps := []string{"Create the files in the project directory, then run 'npm install'"}
+	if project.Build != "" {
+		nextSteps = append(nextSteps, "Run '"+project.Build+"' to bundle src/*.ts into dist")
+	}
+	nextSteps = append(nextSteps,
+		"Run '"+project.Run+"' to run the sample test, or 'npm run typecheck' to check types",
+		"Use validate_script on the sample test before adding more tests to src",
+	)
+
+	logger.InfoContext(ctx, "TypeScript project scaffolded",
+		slog.String("bundler", bundler),
+		slog.Int("files", len(project.Files)))
+
+	return marshalResponse(ctx, logger, scaffoldTypeScriptResponse{
+		Project:   project,
+		Bundler:   bundler,
+		NextSteps: nextSteps,
+	})
+}
@@ -0,0 +1,32 @@
+package tools
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScaffoldTypeScript(t *testing.T) {
+	t.Parallel()
+
+	result, err := scaffoldTypeScript(t.Context(), newCallRequest(map[string]any{"bundler": "webpack"}))
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+
+	var resp scaffoldTypeScriptResponse
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &resp))
+	assert.Equal(t, "webpack", resp.Bundler)
+	paths := make([]string, 0, len(resp.Files))
+	for _, f := range resp.Files {
+		paths = append(paths, f.Path)
+	}
+	assert.Equal(t, []string{"package.json", "tsconfig.json", "src/test.ts", "webpack.config.js", ".gitignore"}, paths)
+	assert.Contains(t, resp.NextSteps[1], "npm run build")
+
+	result, err = scaffoldTypeScript(t.Context(), newCallRequest(map[string]any{"bundler": "rollup"}))
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+}
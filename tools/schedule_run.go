@@ -0,0 +1,243 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/grafana/mcp-k6/internal/approval"
+	"github.com/grafana/mcp-k6/internal/importpolicy"
+	"github.com/grafana/mcp-k6/internal/jslib"
+	"github.com/grafana/mcp-k6/internal/logging"
+	"github.com/grafana/mcp-k6/internal/ownership"
+	"github.com/grafana/mcp-k6/internal/redact"
+	"github.com/grafana/mcp-k6/internal/secrets"
+	"github.com/grafana/mcp-k6/internal/slo"
+	"github.com/grafana/mcp-k6/internal/targetpolicy"
+	"github.com/grafana/mcp-k6/internal/workspace"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// errNoSchedules is returned when the server has no scheduler.
+var errNoSchedules = errors.New("schedules are not available")
+
+// ScheduleRunTool exposes a tool for running a script periodically.
+//
+//nolint:gochecknoglobals // Shared tool definition registered at startup.
+var ScheduleRunTool = mcp.NewTool(
+	"schedule_run",
+	append([]mcp.ToolOption{
+		runTool(),
+		mcp.WithDescription(
+			"Run a k6 script periodically on a cron schedule, for continuous baseline tracking. Takes the " +
+				"run_script parameters plus a cron expression. Each run starts in the background and appears " +
+				"in list_runs and get_run; a run is skipped while the previous one of the schedule goes on. " +
+				"Schedules live as long as the server process.",
+		),
+		mcp.WithString(
+			"cron",
+			mcp.Required(),
+			mcp.Description("Five-field cron expression in server local time (minute hour day-of-month month "+
+				"day-of-week), e.g. '*/30 * * * *' or '0 2 * * mon-fri', or a descriptor such as '@hourly'."),
+		),
+		mcp.WithString(
+			"name",
+			mcp.Description("Optional: label shown by list_schedules."),
+		),
+		mcp.WithArray(
+			"slos",
+			mcp.Description(slosDescription),
+			mcp.WithStringItems(),
+		),
+		mcp.WithString(
+			"confirmation_token",
+			mcp.Description(confirmationTokenDescription),
+		),
+	}, runParameters()...)...,
+)
+
+// ListSchedulesTool exposes a tool for listing schedules.
+//
+//nolint:gochecknoglobals // Shared tool definition registered at startup.
+var ListSchedulesTool = mcp.NewTool(
+	"list_schedules",
+	readOnlyTool(),
+	mcp.WithDescription("List the active schedules created with schedule_run, with their next and recent runs."),
+)
+
+// CancelScheduleTool exposes a tool for cancelling a schedule.
+//
+//nolint:gochecknoglobals // Shared tool definition registered at startup.
+var CancelScheduleTool = mcp.NewTool(
+	"cancel_schedule",
+	controlTool(true),
+	mcp.WithDescription("Cancel a schedule so it starts no more runs. A run in progress goes on; stop it with stop_run."),
+	mcp.WithString(
+		"schedule_id",
+		mcp.Required(),
+		mcp.Description("The schedule_id returned by schedule_run."),
+	),
+)
+
+// scheduleResponse describes a schedule.
+type scheduleResponse struct {
+	ScheduleID string `json:"schedule_id"`
+	Name       string `json:"name,omitempty"`
+	Cron       string `json:"cron"`
+	Script     string `json:"script"`
+	CreatedAt  string `json:"created_at"`
+	NextRun    string `json:"next_run,omitempty"`
+	// Runs are the schedule's most recent runs, oldest first; runs dropped
+	// from the run history are left out.
+	Runs      []runSummary `json:"runs"`
+	Skipped   int          `json:"skipped,omitempty"`
+	LastSkip  string       `json:"last_skip_reason,omitempty"`
+	NextSteps []string     `json:"next_steps,omitempty"`
+}
+
+// RegisterScheduleTools registers the schedule_run, list_schedules and cancel_schedule tools with the MCP
+// server.
+func RegisterScheduleTools(
+	s *server.MCPServer,
+	ws *workspace.Workspace,
+	rd *redact.Redactor,
+	reg *secrets.Registry,
+	ip *importpolicy.Policy,
+	tp *targetpolicy.Policy,
+	mirror *jslib.Mirror,
+	schedules *Schedules,
+	objectives *slo.Registry,
+	gate *approval.Gate,
+	ov *ownership.Verifier,
+) {
+	s.AddTool(ScheduleRunTool, withToolLogger("schedule_run",
+		newScheduleRunHandlerFunc(ws, rd, reg, ip, tp, mirror, schedules, objectives, gate, ov)))
+	s.AddTool(ListSchedulesTool, withToolLogger("list_schedules", newListSchedulesHandlerFunc(schedules)))
+	s.AddTool(CancelScheduleTool, withToolLogger("cancel_schedule", newCancelScheduleHandlerFunc(schedules)))
+}
+
+func newScheduleRunHandlerFunc(
+	ws *workspace.Workspace,
+	rd *redact.Redactor,
+	reg *secrets.Registry,
+	ip *importpolicy.Policy,
+	tp *targetpolicy.Policy,
+	mirror *jslib.Mirror,
+	schedules *Schedules,
+	objectives *slo.Registry,
+	gate *approval.Gate,
+	ov *ownership.Verifier,
+) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		logger := logging.LoggerFromContext(ctx)
+		if schedules == nil {
+			return mcp.NewToolResultError(errNoSchedules.Error()), nil
+		}
+		expr, err := request.RequireString("cron")
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		script, options, err := runRequest(ctx, ws, reg, ip, tp, nil, request)
+		if err != nil {
+			return requestError(err), nil
+		}
+		if options.Checkout != nil {
+			removeCheckout(ctx, options.Checkout)
+			return mcp.NewToolResultError("git_repo runs cannot be scheduled: pass script_path instead"), nil
+		}
+		if err := applySLOs(objectives, request, options); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		if err := verifyOwnership(ctx, ov, ws, script, options, plannedLoad(script, options).VUs); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		if result := confirmRun(ctx, gate, request, script, options); result != nil {
+			return result, nil
+		}
+		options.Redactor = rd
+		options.JSLib = mirror
+
+		job, err := schedules.Add(ctx, request.GetString("name", ""), expr, script, options)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		logger.InfoContext(ctx, "Schedule created",
+			slog.String("schedule_id", job.ID),
+			slog.String("cron", job.Cron),
+			slog.Any("options", sanitizeRunOptions(options)))
+
+		resp := describeSchedule(schedules, job)
+		resp.NextSteps = []string{
+			"Call list_schedules to follow the schedule's runs, and get_run to read the result of each",
+			"Call cancel_schedule to stop it",
+		}
+		if options.ScriptPath != "" {
+			resp.NextSteps = append(resp.NextSteps,
+				"Each run reads the script file as it is then, so edits apply to the next run")
+		}
+		return marshalResponse(ctx, logger, resp)
+	}
+}
+
+func newListSchedulesHandlerFunc(schedules *Schedules) server.ToolHandlerFunc {
+	return func(ctx context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		logger := logging.LoggerFromContext(ctx)
+		list := []scheduleResponse{}
+		if schedules != nil {
+			for _, job := range schedules.List() {
+				list = append(list, describeSchedule(schedules, job))
+			}
+		}
+		return marshalResponse(ctx, logger, map[string]any{"schedules": list})
+	}
+}
+
+func newCancelScheduleHandlerFunc(schedules *Schedules) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		logger := logging.LoggerFromContext(ctx)
+		id, err := request.RequireString("schedule_id")
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		if schedules == nil {
+			return mcp.NewToolResultError(errNoSchedules.Error()), nil
+		}
+		job, err := schedules.Cancel(id)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		logger.InfoContext(ctx, "Schedule cancelled", slog.String("schedule_id", job.ID))
+
+		resp := describeSchedule(schedules, job)
+		resp.NextRun = ""
+		for _, run := range resp.Runs {
+			if run.State == "running" {
+				resp.NextSteps = append(resp.NextSteps, "Run "+run.RunID+" is still in progress; stop it with stop_run")
+			}
+		}
+		return marshalResponse(ctx, logger, resp)
+	}
+}
+
+func describeSchedule(schedules *Schedules, job *Schedule) scheduleResponse {
+	resp := scheduleResponse{
+		ScheduleID: job.ID,
+		Name:       job.Name,
+		Cron:       job.Cron,
+		Script:     job.Script,
+		CreatedAt:  job.CreatedAt.Format(time.RFC3339),
+		Runs:       []runSummary{},
+	}
+	if next := job.Next(); !next.IsZero() {
+		resp.NextRun = next.Format(time.RFC3339)
+	}
+	for _, id := range job.RunIDs() {
+		if run, err := schedules.runs.Get(id); err == nil {
+			resp.Runs = append(resp.Runs, summarizeRun(run))
+		}
+	}
+	resp.Skipped, resp.LastSkip = job.Skipped()
+	return resp
+}
@@ -0,0 +1,244 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/grafana/mcp-k6/internal/cron"
+	"github.com/grafana/mcp-k6/internal/logging"
+)
+
+const (
+	// MaxSchedules is the maximum number of active schedules.
+	MaxSchedules = 10
+
+	// maxScheduleRuns bounds the run IDs kept per schedule.
+	maxScheduleRuns = 10
+)
+
+// Schedules runs scripts periodically, on cron schedules, as background
+// runs, so their results land in the run history of list_runs and get_run.
+type Schedules struct {
+	runs *Runs
+
+	mu    sync.Mutex
+	jobs  map[string]*Schedule
+	order []string
+	seq   int
+
+	// now and after read the clock; replaced in tests.
+	now   func() time.Time
+	after func(d time.Duration) <-chan time.Time
+}
+
+// NewSchedules returns a scheduler starting its runs in runs.
+func NewSchedules(runs *Runs) *Schedules {
+	return &Schedules{
+		runs:  runs,
+		jobs:  make(map[string]*Schedule),
+		now:   time.Now,
+		after: time.After,
+	}
+}
+
+// Schedule is a script run on a cron schedule.
+type Schedule struct {
+	ID        string
+	Name      string
+	Cron      string
+	Script    string
+	CreatedAt time.Time
+
+	spec    *cron.Schedule
+	script  string
+	options *RunOptions
+	cancel  context.CancelFunc
+	done    chan struct{}
+
+	mu        sync.Mutex
+	next      time.Time
+	runIDs    []string
+	skipped   int
+	lastError string
+}
+
+// Add validates the run and schedules it. Schedules outlive ctx but keep its
+// values, such as the request logger.
+func (s *Schedules) Add(ctx context.Context, name, expr, script string, options *RunOptions) (*Schedule, error) {
+	spec, err := cron.Parse(expr)
+	if err != nil {
+		return nil, err
+	}
+	if spec.Next(s.now()).IsZero() {
+		return nil, fmt.Errorf("cron expression %q never fires", expr)
+	}
+	if err := validateRunInput(ctx, script, options); err != nil {
+		return nil, err
+	}
+	if options == nil {
+		options = &RunOptions{}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.jobs) >= MaxSchedules {
+		return nil, fmt.Errorf("%d schedules are active (limit %d); cancel one with cancel_schedule first",
+			len(s.jobs), MaxSchedules)
+	}
+
+	s.seq++
+	jobCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	job := &Schedule{
+		ID:        "schedule-" + strconv.Itoa(s.seq),
+		Name:      name,
+		Cron:      spec.String(),
+		Script:    "inline",
+		CreatedAt: s.now(),
+		spec:      spec,
+		script:    script,
+		options:   options,
+		cancel:    cancel,
+		done:      make(chan struct{}),
+	}
+	if options.ScriptPath != "" {
+		job.Script = options.ScriptPath
+	}
+	job.next = spec.Next(job.CreatedAt)
+	s.jobs[job.ID] = job
+	s.order = append(s.order, job.ID)
+
+	go s.loop(jobCtx, job)
+	return job, nil
+}
+
+// Cancel stops a schedule from starting new runs. Runs in progress go on.
+func (s *Schedules) Cancel(id string) (*Schedule, error) {
+	s.mu.Lock()
+	job, ok := s.jobs[id]
+	if ok {
+		delete(s.jobs, id)
+		for i, jobID := range s.order {
+			if jobID == id {
+				s.order = append(s.order[:i], s.order[i+1:]...)
+				break
+			}
+		}
+	}
+	s.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown schedule_id %q; list_schedules shows the active schedules", id)
+	}
+	job.cancel()
+	<-job.done
+	return job, nil
+}
+
+// List returns the active schedules, oldest first.
+func (s *Schedules) List() []*Schedule {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	jobs := make([]*Schedule, 0, len(s.order))
+	for _, id := range s.order {
+		jobs = append(jobs, s.jobs[id])
+	}
+	return jobs
+}
+
+// Close cancels every schedule.
+func (s *Schedules) Close() {
+	for _, job := range s.List() {
+		_, _ = s.Cancel(job.ID)
+	}
+}
+
+// loop starts the job's runs until it is cancelled or stops firing.
+func (s *Schedules) loop(ctx context.Context, job *Schedule) {
+	defer close(job.done)
+	logger := logging.LoggerFromContext(ctx)
+	for {
+		job.mu.Lock()
+		next := job.next
+		job.mu.Unlock()
+		if next.IsZero() {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.after(next.Sub(s.now())):
+		}
+
+		run, err := s.fire(ctx, job)
+		if err != nil {
+			logger.WarnContext(ctx, "Scheduled run skipped",
+				slog.String("schedule_id", job.ID),
+				slog.String("reason", err.Error()))
+		} else {
+			logger.InfoContext(ctx, "Scheduled run started",
+				slog.String("schedule_id", job.ID),
+				slog.String("run_id", run.ID))
+		}
+
+		// Fire once after a missed activation, for example after a suspend,
+		// instead of catching up on every one
+		from := s.now()
+		if from.Before(next) {
+			from = next
+		}
+		job.mu.Lock()
+		job.next = job.spec.Next(from)
+		job.mu.Unlock()
+	}
+}
+
+// fire starts one run of job, skipping it while the previous one goes on.
+func (s *Schedules) fire(ctx context.Context, job *Schedule) (*BackgroundRun, error) {
+	job.mu.Lock()
+	defer job.mu.Unlock()
+
+	if n := len(job.runIDs); n > 0 {
+		if prev, err := s.runs.Get(job.runIDs[n-1]); err == nil && !prev.Done() {
+			job.skipped++
+			job.lastError = "previous run " + prev.ID + " still in progress"
+			return nil, errors.New(job.lastError)
+		}
+	}
+	run, err := s.runs.start(ctx, job.script, job.options, job.ID)
+	if err != nil {
+		job.skipped++
+		job.lastError = err.Error()
+		return nil, err
+	}
+	job.runIDs = append(job.runIDs, run.ID)
+	if len(job.runIDs) > maxScheduleRuns {
+		job.runIDs = job.runIDs[len(job.runIDs)-maxScheduleRuns:]
+	}
+	return run, nil
+}
+
+// Next returns when the schedule fires next, or the zero time if never.
+func (j *Schedule) Next() time.Time {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.next
+}
+
+// RunIDs returns the IDs of the schedule's most recent runs, oldest first.
+func (j *Schedule) RunIDs() []string {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return append([]string(nil), j.runIDs...)
+}
+
+// Skipped returns how many runs were skipped, and why the last one was.
+func (j *Schedule) Skipped() (int, string) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.skipped, j.lastError
+}
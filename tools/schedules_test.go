@@ -0,0 +1,112 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestSchedules returns a scheduler whose activations fire when the test
+// sends on the returned channel.
+func newTestSchedules(t *testing.T) (*Schedules, chan time.Time) {
+	t.Helper()
+	tick := make(chan time.Time)
+	now := time.Date(2026, time.March, 11, 10, 7, 0, 0, time.UTC)
+	schedules := NewSchedules(newTestRuns(t))
+	schedules.now = func() time.Time { return now }
+	schedules.after = func(time.Duration) <-chan time.Time { return tick }
+	t.Cleanup(schedules.Close)
+	return schedules, tick
+}
+
+func TestSchedules(t *testing.T) {
+	t.Parallel()
+
+	schedules, tick := newTestSchedules(t)
+	job, err := schedules.Add(context.Background(), "baseline", "*/15 * * * *", testRunScript, &RunOptions{VUs: 2})
+	require.NoError(t, err)
+	assert.Equal(t, "schedule-1", job.ID)
+	assert.Equal(t, time.Date(2026, time.March, 11, 10, 15, 0, 0, time.UTC), job.Next())
+
+	tick <- time.Time{}
+	require.Eventually(t, func() bool { return len(job.RunIDs()) == 1 }, 5*time.Second, 10*time.Millisecond)
+	run, err := schedules.runs.Get(job.RunIDs()[0])
+	require.NoError(t, err)
+	assert.Equal(t, "schedule-1", run.Schedule)
+	assert.Equal(t, time.Date(2026, time.March, 11, 10, 30, 0, 0, time.UTC), job.Next())
+
+	// The previous run is still in progress
+	tick <- time.Time{}
+	require.Eventually(t, func() bool { n, _ := job.Skipped(); return n == 1 }, 5*time.Second, 10*time.Millisecond)
+	_, reason := job.Skipped()
+	assert.Contains(t, reason, "still in progress")
+
+	waitForAPI(t, run)
+	_, _, err = run.Stop(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, run.Wait(context.Background()))
+	tick <- time.Time{}
+	require.Eventually(t, func() bool { return len(job.RunIDs()) == 2 }, 5*time.Second, 10*time.Millisecond)
+
+	assert.Len(t, schedules.List(), 1)
+	_, err = schedules.Cancel(job.ID)
+	require.NoError(t, err)
+	assert.Empty(t, schedules.List())
+	_, err = schedules.Cancel(job.ID)
+	require.Error(t, err)
+}
+
+func TestSchedulesRejectInvalidJobs(t *testing.T) {
+	t.Parallel()
+
+	schedules, _ := newTestSchedules(t)
+	_, err := schedules.Add(context.Background(), "", "every hour", testRunScript, nil)
+	require.Error(t, err)
+	_, err = schedules.Add(context.Background(), "", "0 0 30 2 *", testRunScript, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "never fires")
+	_, err = schedules.Add(context.Background(), "", "@hourly", testRunScript, &RunOptions{VUs: MaxVUs + 1})
+	require.Error(t, err)
+
+	for range MaxSchedules {
+		_, err = schedules.Add(context.Background(), "", "@hourly", testRunScript, nil)
+		require.NoError(t, err)
+	}
+	_, err = schedules.Add(context.Background(), "", "@hourly", testRunScript, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "schedules are active")
+}
+
+func TestScheduleTools(t *testing.T) {
+	t.Parallel()
+
+	schedules, _ := newTestSchedules(t)
+	handler := newScheduleRunHandlerFunc(nil, nil, nil, nil, nil, nil, schedules, nil, nil, nil)
+
+	result := callRunControl(t, handler, map[string]any{"script": testRunScript, "cron": "0 2 * * *", "name": "nightly"})
+	require.False(t, result.IsError, result.Content)
+	var created scheduleResponse
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &created))
+	assert.Equal(t, "schedule-1", created.ScheduleID)
+	assert.Equal(t, "nightly", created.Name)
+	assert.Equal(t, "inline", created.Script)
+	assert.Equal(t, "2026-03-12T02:00:00Z", created.NextRun)
+
+	result = callRunControl(t, handler, map[string]any{"script": testRunScript, "cron": "0 25 * * *"})
+	require.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "invalid cron expression")
+
+	listed := callRunControl(t, newListSchedulesHandlerFunc(schedules), nil)
+	assert.Contains(t, listed.Content[0].(mcp.TextContent).Text, `"schedule_id": "schedule-1"`)
+
+	result = callRunControl(t, newCancelScheduleHandlerFunc(schedules), map[string]any{"schedule_id": "schedule-1"})
+	require.False(t, result.IsError, result.Content)
+	result = callRunControl(t, newCancelScheduleHandlerFunc(schedules), map[string]any{"schedule_id": "schedule-1"})
+	require.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "unknown schedule_id")
+}
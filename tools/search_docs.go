@@ -0,0 +1,148 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/grafana/mcp-k6/internal/docsearch"
+	"github.com/grafana/mcp-k6/internal/logging"
+	"github.com/grafana/mcp-k6/internal/symbols"
+	"github.com/grafana/xk6-docs/docs"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	// DefaultSearchResults is the default number of sections search_docs
+	// returns.
+	DefaultSearchResults = 10
+	// MaxSearchResults is the maximum number of sections search_docs returns.
+	MaxSearchResults = 50
+)
+
+// SearchDocsTool exposes a tool for searching the k6 documentation by free
+// text.
+//
+//nolint:gochecknoglobals // Shared tool definition registered at startup.
+var SearchDocsTool = mcp.NewTool(
+	"search_docs",
+	readOnlyTool(),
+	mcp.WithDescription(
+		"Searches the titles, descriptions and slugs of the k6 documentation sections for a free-text query, "+
+			"such as 'how do I ramp up users', 'http.post' or 'thresholds'. Code-like queries rank the "+
+			"JavaScript API reference first, conceptual ones the guides; the query_kind of the response tells "+
+			"which. Use lookup_symbol when you know the exact API name, then get_documentation with a "+
+			"returned slug.",
+	),
+	mcp.WithString(
+		"query",
+		mcp.Required(),
+		mcp.Description("Words, question or API name to search for; case is ignored."),
+	),
+	mcp.WithString(
+		"version",
+		mcp.Description("Optional: k6 version (e.g., 'v1.4.x'). Defaults to latest."),
+	),
+	mcp.WithNumber(
+		"limit",
+		mcp.Description(fmt.Sprintf("Optional: the number of sections to return (default: %d, max: %d).",
+			DefaultSearchResults, MaxSearchResults)),
+	),
+)
+
+// searchDocsResult is a section matching the query.
+type searchDocsResult struct {
+	Slug        string `json:"slug"`
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Score       int    `json:"score"`
+	// Boosted is set for the sections of the categories favored for the
+	// kind of query.
+	Boosted bool `json:"boosted,omitempty"`
+}
+
+// searchDocsResponse is the JSON structure returned by the tool.
+type searchDocsResponse struct {
+	Query string `json:"query"`
+	// QueryKind is "code" or "concept", and BoostedCategories the top-level
+	// sections it ranks first among equal matches.
+	QueryKind         docsearch.Kind     `json:"query_kind"`
+	BoostedCategories []string           `json:"boosted_categories"`
+	Results           []searchDocsResult `json:"results"`
+	Total             int                `json:"total"`
+	Version           string             `json:"version"`
+}
+
+// RegisterSearchDocsTool registers the search_docs tool with the MCP server.
+func RegisterSearchDocsTool(s *server.MCPServer, catalog *docs.Catalog) {
+	s.AddTool(SearchDocsTool, withToolLogger("search_docs", newSearchDocsHandlerFunc(catalog)))
+}
+
+func newSearchDocsHandlerFunc(catalog *docs.Catalog) server.ToolHandlerFunc {
+	indexes := &symbolIndexes{indexes: make(map[string]*symbols.Index)}
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		logger := logging.LoggerFromContext(ctx)
+
+		query, err := request.RequireString("query")
+		if err != nil || strings.TrimSpace(query) == "" {
+			return mcp.NewToolResultError("missing or invalid query parameter"), nil
+		}
+		limit := request.GetInt("limit", DefaultSearchResults)
+		if limit < 1 || limit > MaxSearchResults {
+			return mcp.NewToolResultError(fmt.Sprintf("limit must be between 1 and %d, got %d",
+				MaxSearchResults, limit)), nil
+		}
+		version := request.GetString("version", "")
+
+		idx, err := catalog.Index(ctx, version)
+		if err != nil {
+			return mcp.NewToolResultError(versionError(version, catalog, err).Error()), nil
+		}
+		x := indexes.get(ctx, catalog, idx)
+
+		kind := docsearch.Classify(query, func(name string) bool { return isAPISymbol(x, name) })
+		matches := docsearch.Search(idx, query, kind)
+		if len(matches) == 0 {
+			logger.InfoContext(ctx, "No documentation found", slog.String("query", query))
+			return mcp.NewToolResultError(fmt.Sprintf(
+				"no section of version %s matches %q. Search fewer or other words, or browse list_sections",
+				idx.Version, query)), nil
+		}
+
+		resp := searchDocsResponse{
+			Query:             query,
+			QueryKind:         kind,
+			BoostedCategories: docsearch.Boosted(kind),
+			Results:           make([]searchDocsResult, 0, min(limit, len(matches))),
+			Total:             len(matches),
+			Version:           idx.Version,
+		}
+		for _, m := range matches[:min(limit, len(matches))] {
+			resp.Results = append(resp.Results, searchDocsResult{
+				Slug:        m.Slug,
+				Title:       m.Title,
+				Description: m.Description,
+				Score:       m.Score,
+				Boosted:     m.Boosted,
+			})
+		}
+		logger.InfoContext(ctx, "Documentation searched",
+			slog.String("query", query),
+			slog.String("kind", string(kind)),
+			slog.Int("matches", len(matches)))
+		return marshalResponse(ctx, logger, resp)
+	}
+}
+
+// isAPISymbol reports whether name is a JavaScript API symbol of x, rather
+// than a glossary term.
+func isAPISymbol(x *symbols.Index, name string) bool {
+	for _, e := range x.Lookup(name) {
+		if e.Anchor == "" {
+			return true
+		}
+	}
+	return false
+}
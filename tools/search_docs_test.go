@@ -0,0 +1,86 @@
+package tools
+
+import (
+	"encoding/json"
+	"testing"
+	"testing/fstest"
+
+	"github.com/grafana/mcp-k6/internal/docsearch"
+	"github.com/grafana/xk6-docs/docs"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchDocs(t *testing.T) {
+	t.Parallel()
+
+	sections := []docs.Section{
+		{Slug: "javascript-api", RelPath: "javascript-api/_index.md", Title: "JavaScript API", IsIndex: true},
+		{
+			Slug: "javascript-api/k6/check", RelPath: "javascript-api/k6/check.md", Title: "check( val, sets, [tags] )",
+			Description: "Runs checks on a value.",
+		},
+		{
+			Slug: "using-k6/checks", RelPath: "using-k6/checks.md", Title: "Checks",
+			Description: "Checks validate boolean conditions in your test.",
+		},
+		{
+			Slug: "testing-guides/api-load-testing", RelPath: "testing-guides/api-load-testing.md",
+			Title: "API load testing", Description: "How to ramp up users and check the responses.",
+		},
+	}
+	index, err := json.Marshal(docs.Index{Version: "v1.4.x", Sections: sections})
+	require.NoError(t, err)
+	fsys := fstest.MapFS{"v1.4.x/sections.json": {Data: index}}
+	for _, sec := range sections {
+		fsys["v1.4.x/markdown/"+sec.RelPath] = &fstest.MapFile{Data: []byte("# " + sec.Title)}
+	}
+	handler := newSearchDocsHandlerFunc(docs.NewCatalog(docs.WithFS(fsys)))
+
+	call := func(args map[string]any) searchDocsResponse {
+		t.Helper()
+		result, err := handler(t.Context(), newCallRequest(args))
+		require.NoError(t, err)
+		require.False(t, result.IsError, "unexpected tool error: %+v", result.Content)
+		var resp searchDocsResponse
+		decodeJSON(t, result, &resp)
+		return resp
+	}
+	slugs := func(resp searchDocsResponse) []string {
+		out := make([]string, len(resp.Results))
+		for i, r := range resp.Results {
+			out[i] = r.Slug
+		}
+		return out
+	}
+
+	// check is an API symbol: the API reference comes first
+	resp := call(map[string]any{"query": "check"})
+	assert.Equal(t, docsearch.Code, resp.QueryKind)
+	assert.Equal(t, []string{"javascript-api"}, resp.BoostedCategories)
+	assert.Equal(t, "javascript-api/k6/check", resp.Results[0].Slug)
+	assert.True(t, resp.Results[0].Boosted)
+	assert.Equal(t, "v1.4.x", resp.Version)
+
+	// The same match, asked about in prose: the guides come first
+	resp = call(map[string]any{"query": "how do I check responses", "limit": 2})
+	assert.Equal(t, docsearch.Concept, resp.QueryKind)
+	assert.Equal(t, []string{"using-k6/checks", "javascript-api/k6/check"}, slugs(resp))
+	assert.Equal(t, 3, resp.Total)
+
+	for name, args := range map[string]map[string]any{
+		"no query": {},
+		"blank":    {"query": "  "},
+		"limit":    {"query": "check", "limit": MaxSearchResults + 1},
+		"no match": {"query": "websockets"},
+		"version":  {"query": "check", "version": "v0.1.x"},
+	} {
+		result, err := handler(t.Context(), newCallRequest(args))
+		require.NoError(t, err, name)
+		assert.True(t, result.IsError, name)
+	}
+	result, err := handler(t.Context(), newCallRequest(map[string]any{"query": "websockets"}))
+	require.NoError(t, err)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "list_sections")
+}
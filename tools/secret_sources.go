@@ -0,0 +1,187 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/grafana/mcp-k6/internal/workspace"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// secretSourcesDescription documents the secret_sources parameter of the execution tools.
+const secretSourcesDescription = "Optional: k6 secret sources (--secret-source) for scripts that use the " +
+	"k6/secrets module. Each entry is {type, name, path, url, response_path, default}: type 'file' reads " +
+	"KEY=VALUE lines from 'path' inside a workspace root; type 'url' fetches each secret from 'url', a " +
+	"template containing {key} (for example a Vault agent or secrets proxy), optionally extracting " +
+	"'response_path' from a JSON response. Name every source when passing more than one and mark one " +
+	"as default. k6 masks secret values in its own output."
+
+//nolint:gochecknoglobals // Compiled once and reused.
+var secretSourceNameRe = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// SecretSource configures one k6 secret source.
+type SecretSource struct {
+	// Type is the k6 source type: "file" or "url".
+	Type string `json:"type"`
+	// Name identifies the source for secrets.source(name) in the script.
+	Name string `json:"name,omitempty"`
+	// Path is the secrets file of a file source, resolved inside the workspace.
+	Path string `json:"path,omitempty"`
+	// URL is the request template of a url source; {key} is replaced by the secret name.
+	URL string `json:"url,omitempty"`
+	// ResponsePath selects the secret in a JSON response of a url source.
+	ResponsePath string `json:"response_path,omitempty"`
+	// Default makes the source the one used by secrets.get.
+	Default bool `json:"default,omitempty"`
+}
+
+// secretSourcesArgument reads and validates the secret_sources parameter.
+// File paths are resolved inside the workspace roots.
+func secretSourcesArgument(
+	ctx context.Context,
+	ws *workspace.Workspace,
+	request mcp.CallToolRequest,
+) ([]SecretSource, error) {
+	raw, ok := request.GetArguments()["secret_sources"]
+	if !ok || raw == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid secret_sources: %w", err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	var sources []SecretSource
+	if err := dec.Decode(&sources); err != nil {
+		return nil, fmt.Errorf("invalid secret_sources: %w", err)
+	}
+
+	if err := validateSecretSources(sources); err != nil {
+		return nil, err
+	}
+
+	for i := range sources {
+		if sources[i].Type != "file" {
+			continue
+		}
+		if ws == nil {
+			return nil, workspace.ErrNoRoots
+		}
+		resolved, err := ws.Resolve(ctx, sources[i].Path)
+		if err != nil {
+			return nil, fmt.Errorf("resolving secret source path: %w", err)
+		}
+		sources[i].Path = resolved
+	}
+	return sources, nil
+}
+
+// validateSecretSources checks the sources can be expressed as k6
+// --secret-source flags.
+func validateSecretSources(sources []SecretSource) error {
+	names := make(map[string]bool, len(sources))
+	defaults := 0
+	for i, src := range sources {
+		field := fmt.Sprintf("secret_sources[%d]", i)
+
+		switch src.Type {
+		case "file":
+			if src.Path == "" {
+				return fmt.Errorf("%s: file sources need a 'path'", field)
+			}
+			if src.URL != "" || src.ResponsePath != "" {
+				return fmt.Errorf("%s: 'url' and 'response_path' only apply to url sources", field)
+			}
+		case "url":
+			if err := validateSecretSourceURL(src.URL); err != nil {
+				return fmt.Errorf("%s: %w", field, err)
+			}
+			if src.Path != "" {
+				return fmt.Errorf("%s: 'path' only applies to file sources", field)
+			}
+		default:
+			return fmt.Errorf("%s: type must be 'file' or 'url', got %q", field, src.Type)
+		}
+		if strings.ContainsAny(src.Path+src.ResponsePath, ",") {
+			return fmt.Errorf("%s: values cannot contain commas", field)
+		}
+
+		if src.Name == "" {
+			if len(sources) > 1 {
+				return fmt.Errorf("%s: name every source when passing more than one", field)
+			}
+		} else {
+			if !secretSourceNameRe.MatchString(src.Name) {
+				return fmt.Errorf("%s: invalid name %q", field, src.Name)
+			}
+			if names[src.Name] {
+				return fmt.Errorf("%s: duplicate name %q", field, src.Name)
+			}
+			names[src.Name] = true
+		}
+		if src.Default {
+			defaults++
+		}
+	}
+	if defaults > 1 {
+		return errors.New("secret_sources: only one source can be the default")
+	}
+	return nil
+}
+
+func validateSecretSourceURL(raw string) error {
+	if raw == "" {
+		return errors.New("url sources need a 'url'")
+	}
+	if !strings.Contains(raw, "{key}") {
+		return errors.New("'url' must contain the {key} placeholder")
+	}
+	if strings.Contains(raw, ",") {
+		return errors.New("'url' cannot contain commas")
+	}
+	u, err := url.Parse(strings.ReplaceAll(raw, "{key}", "key"))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("'url' must be an http or https URL, got %q", raw)
+	}
+	if u.User != nil {
+		return errors.New("'url' cannot embed credentials")
+	}
+	return nil
+}
+
+// secretSourceArgs converts secret sources into k6 --secret-source flags.
+func secretSourceArgs(sources []SecretSource) []string {
+	args := make([]string, 0, len(sources))
+	for _, src := range sources {
+		var params []string
+		if src.Name != "" {
+			params = append(params, "name="+src.Name)
+		}
+		switch src.Type {
+		case "file":
+			if len(params) == 0 && !src.Default {
+				// A single unnamed file source takes the short form.
+				args = append(args, "--secret-source=file="+src.Path)
+				continue
+			}
+			params = append(params, "filename="+src.Path)
+		case "url":
+			params = append(params, "urlTemplate="+src.URL)
+			if src.ResponsePath != "" {
+				params = append(params, "responsePath="+src.ResponsePath)
+			}
+		}
+		if src.Default {
+			params = append(params, "default")
+		}
+		args = append(args, "--secret-source="+src.Type+"="+strings.Join(params, ","))
+	}
+	return args
+}
@@ -0,0 +1,106 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/grafana/mcp-k6/internal/secrets"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// secretsDescription documents the secrets parameter of the execution tools.
+const secretsDescription = "Optional: names of secrets configured on the server to expose to the script " +
+	"as __ENV variables of the same name. Values are never returned; they appear as [SECRET:NAME] in output."
+
+// ScrubSecrets returns a middleware that removes secret values from every
+// tool result and error before it reaches the client.
+func ScrubSecrets(reg *secrets.Registry) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		if reg.Empty() {
+			return next
+		}
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			result, err := next(ctx, request)
+			if err != nil {
+				err = errors.New(reg.Scrub(err.Error()))
+			}
+			if result == nil {
+				return result, err
+			}
+
+			for i, content := range result.Content {
+				switch c := content.(type) {
+				case mcp.TextContent:
+					c.Text = reg.Scrub(c.Text)
+					result.Content[i] = c
+				case mcp.EmbeddedResource:
+					if text, ok := c.Resource.(mcp.TextResourceContents); ok {
+						text.Text = reg.Scrub(text.Text)
+						c.Resource = text
+						result.Content[i] = c
+					}
+				}
+			}
+			if result.StructuredContent != nil {
+				result.StructuredContent = scrubStructured(reg, result.StructuredContent)
+			}
+			return result, err
+		}
+	}
+}
+
+// scrubStructured returns the structured content v with the secrets of reg
+// removed from its strings. It works on the decoded values: in the encoded
+// JSON, a secret holding quotes or HTML characters is escaped and would not
+// match.
+func scrubStructured(reg *secrets.Registry, v any) any {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	var decoded any
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return v
+	}
+	scrubbed, changed := scrubValue(reg, decoded)
+	if !changed {
+		return v
+	}
+	return scrubbed
+}
+
+// scrubValue scrubs the strings of a decoded JSON value, keys included, and
+// reports whether any changed.
+func scrubValue(reg *secrets.Registry, v any) (any, bool) {
+	switch v := v.(type) {
+	case string:
+		s := reg.Scrub(v)
+		return s, s != v
+	case []any:
+		changed := false
+		for i, e := range v {
+			var c bool
+			v[i], c = scrubValue(reg, e)
+			changed = changed || c
+		}
+		return v, changed
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		changed := false
+		for k, e := range v {
+			e, c := scrubValue(reg, e)
+			key := reg.Scrub(k)
+			out[key] = e
+			changed = changed || c || key != k
+		}
+		return out, changed
+	}
+	return v, false
+}
+
+// secretsArgument resolves the secrets parameter of the request.
+func secretsArgument(reg *secrets.Registry, request mcp.CallToolRequest) (map[string]string, error) {
+	return reg.Resolve(request.GetStringSlice("secrets", nil))
+}
@@ -0,0 +1,79 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/grafana/mcp-k6/internal/logging"
+	"github.com/grafana/mcp-k6/internal/secrets"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScrubSecrets(t *testing.T) {
+	t.Parallel()
+
+	reg, err := secrets.New(map[string]string{"API_TOKEN": "tok-12345"})
+	require.NoError(t, err)
+
+	handler := ScrubSecrets(reg)(func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		result := mcp.NewToolResultText(`{"stdout":"Authorization: Bearer tok-12345"}`)
+		result.StructuredContent = map[string]any{"stderr": "token=tok-12345"}
+		return result, errors.New("failed with tok-12345")
+	})
+
+	result, err := handler(context.Background(), mcp.CallToolRequest{})
+	require.EqualError(t, err, "failed with [SECRET:API_TOKEN]")
+	text, ok := result.Content[0].(mcp.TextContent)
+	require.True(t, ok)
+	assert.Equal(t, `{"stdout":"Authorization: Bearer [SECRET:API_TOKEN]"}`, text.Text)
+	structured, err := json.Marshal(result.StructuredContent)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"stderr":"token=[SECRET:API_TOKEN]"}`, string(structured))
+}
+
+func TestScrubSecretsEscaped(t *testing.T) {
+	t.Parallel()
+
+	const secret = `pa"ss<word>`
+	reg, err := secrets.New(map[string]string{"PASSWORD": secret})
+	require.NoError(t, err)
+
+	handler := ScrubSecrets(reg)(func(ctx context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		resp := map[string]any{"stdout": "login " + secret, "lines": []any{"password=" + secret}}
+		result, err := marshalResponse(ctx, logging.LoggerFromContext(ctx), resp)
+		result.StructuredContent = resp
+		return result, err
+	})
+
+	result, err := handler(context.Background(), mcp.CallToolRequest{})
+	require.NoError(t, err)
+	structured, err := json.Marshal(result.StructuredContent)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"stdout":"login [SECRET:PASSWORD]","lines":["password=[SECRET:PASSWORD]"]}`, string(structured))
+	text := result.Content[0].(mcp.TextContent).Text
+	assert.Contains(t, text, "login [SECRET:PASSWORD]")
+	assert.NotContains(t, text, `pa\"ss`)
+}
+
+func TestSecretsArgument(t *testing.T) {
+	t.Parallel()
+
+	reg, err := secrets.New(map[string]string{"API_TOKEN": "tok-12345"})
+	require.NoError(t, err)
+
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]any{"secrets": []any{"API_TOKEN"}}
+	values, err := secretsArgument(reg, req)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"API_TOKEN": "tok-12345"}, values)
+
+	req.Params.Arguments = map[string]any{"secrets": []any{"OTHER"}}
+	_, err = secretsArgument(reg, req)
+	require.ErrorIs(t, err, secrets.ErrUnknownSecret)
+
+	assert.Equal(t, []string{"API_TOKEN=tok-12345"}, secretEnv(values))
+}
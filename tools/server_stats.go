@@ -0,0 +1,168 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/grafana/mcp-k6/internal/logging"
+	"github.com/grafana/mcp-k6/internal/usage"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	// failingToolCalls is the number of calls from which a tool failing
+	// often is pointed out.
+	failingToolCalls = 5
+	// failingToolRate is the error rate from which it is.
+	failingToolRate = 0.25
+)
+
+// ServerStatsTool exposes a tool returning the tool call statistics of the
+// server since startup.
+//
+//nolint:gochecknoglobals // Shared tool definition registered at startup.
+var ServerStatsTool = mcp.NewTool(
+	"server_stats",
+	readOnlyTool(),
+	mcp.WithDescription(
+		"Get the tool call statistics of the mcp-k6 server since it started: call counts, error rates and "+
+			"average and longest durations per tool, for the whole server and for the calling session, and "+
+			"the call counts of the other sessions, identified by a digest of their ID. Calls in progress, "+
+			"this one included, are not counted yet.",
+	),
+)
+
+// RecordUsage returns a middleware that counts every tool call in stats, by
+// tool and by session.
+func RecordUsage(stats *usage.Stats) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		if stats == nil {
+			return next
+		}
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			start := time.Now()
+			result, err := next(ctx, request)
+			stats.Record(sessionID(ctx), request.Params.Name, err != nil || (result != nil && result.IsError),
+				time.Since(start))
+			return result, err
+		}
+	}
+}
+
+// RegisterServerStatsTool registers the server_stats tool with the MCP
+// server.
+func RegisterServerStatsTool(s *server.MCPServer, stats *usage.Stats) {
+	s.AddTool(ServerStatsTool, withToolLogger("server_stats",
+		func(ctx context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return serverStats(ctx, stats)
+		}))
+}
+
+// toolStats is the statistics of a tool.
+type toolStats struct {
+	Tool        string  `json:"tool"`
+	Calls       int64   `json:"calls"`
+	Errors      int64   `json:"errors"`
+	ErrorRate   float64 `json:"error_rate"`
+	AvgDuration string  `json:"avg_duration"`
+	MaxDuration string  `json:"max_duration"`
+}
+
+// sessionStats is the statistics of a session. Only the calling session
+// lists its tools.
+type sessionStats struct {
+	ID        string      `json:"id"`
+	Current   bool        `json:"current,omitempty"`
+	FirstCall string      `json:"first_call"`
+	LastCall  string      `json:"last_call"`
+	Calls     int64       `json:"calls"`
+	Errors    int64       `json:"errors"`
+	Tools     []toolStats `json:"tools,omitempty"`
+}
+
+// serverStatsResponse is the JSON structure returned by the tool.
+type serverStatsResponse struct {
+	Since     string         `json:"since"`
+	Uptime    string         `json:"uptime"`
+	Calls     int64          `json:"calls"`
+	Errors    int64          `json:"errors"`
+	ErrorRate float64        `json:"error_rate"`
+	Tools     []toolStats    `json:"tools"`
+	Session   *sessionStats  `json:"session,omitempty"`
+	Sessions  []sessionStats `json:"sessions"`
+	NextSteps []string       `json:"next_steps"`
+}
+
+func serverStats(ctx context.Context, stats *usage.Stats) (*mcp.CallToolResult, error) {
+	logger := logging.LoggerFromContext(ctx)
+
+	snap := stats.Snapshot(sessionID(ctx))
+	resp := serverStatsResponse{
+		Since:     snap.Since.UTC().Format(time.RFC3339),
+		Uptime:    snap.Uptime.Round(time.Second).String(),
+		Calls:     snap.Calls,
+		Errors:    snap.Errors,
+		ErrorRate: errorRate(snap.Errors, snap.Calls),
+		Tools:     toolsStats(snap.Tools),
+		Sessions:  make([]sessionStats, 0, len(snap.Sessions)),
+	}
+	for _, s := range snap.Sessions {
+		session := sessionStats{
+			ID:        s.ID,
+			Current:   s.Current,
+			FirstCall: s.First.UTC().Format(time.RFC3339),
+			LastCall:  s.Last.UTC().Format(time.RFC3339),
+			Calls:     s.Calls,
+			Errors:    s.Errors,
+		}
+		resp.Sessions = append(resp.Sessions, session)
+		if s.Current {
+			session.Tools = toolsStats(s.Tools)
+			resp.Session = &session
+		}
+	}
+	resp.NextSteps = append([]string{}, serverStatsNextSteps(&resp)...)
+
+	return marshalResponse(ctx, logger, resp)
+}
+
+func toolsStats(tools []usage.Tool) []toolStats {
+	stats := make([]toolStats, 0, len(tools))
+	for _, t := range tools {
+		stats = append(stats, toolStats{
+			Tool:        t.Name,
+			Calls:       t.Calls,
+			Errors:      t.Errors,
+			ErrorRate:   errorRate(t.Errors, t.Calls),
+			AvgDuration: t.Average().Round(time.Millisecond).String(),
+			MaxDuration: t.Max.Round(time.Millisecond).String(),
+		})
+	}
+	return stats
+}
+
+// errorRate returns the share of calls that failed, to three decimals.
+func errorRate(failed, calls int64) float64 {
+	if calls == 0 {
+		return 0
+	}
+	return math.Round(float64(failed)/float64(calls)*1000) / 1000
+}
+
+// serverStatsNextSteps points out the tools failing often.
+func serverStatsNextSteps(resp *serverStatsResponse) []string {
+	var steps []string
+	for _, t := range resp.Tools {
+		if t.Calls >= failingToolCalls && t.ErrorRate >= failingToolRate {
+			steps = append(steps, fmt.Sprintf("%s failed %d of %d calls: check the arguments it is called "+
+				"with against its description, or the server logs for the errors", t.Tool, t.Errors, t.Calls))
+		}
+	}
+	if resp.Calls == 0 {
+		steps = append(steps, "No tool call has ended since the server started")
+	}
+	return steps
+}
@@ -0,0 +1,87 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/grafana/mcp-k6/internal/usage"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testSession is a client session of ID.
+type testSession string
+
+func (testSession) Initialize()                                         {}
+func (testSession) Initialized() bool                                   { return true }
+func (testSession) NotificationChannel() chan<- mcp.JSONRPCNotification { return nil }
+func (s testSession) SessionID() string                                 { return string(s) }
+
+func TestServerStats(t *testing.T) {
+	t.Parallel()
+
+	stats := usage.New()
+	handler := RecordUsage(stats)(func(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		switch req.Params.Name {
+		case "get_run":
+			return mcp.NewToolResultError("unknown run_id"), nil
+		case "info":
+			return nil, errors.New("boom")
+		}
+		return mcp.NewToolResultText("ok"), nil
+	})
+	s := server.NewMCPServer("test", "dev")
+	mine := s.WithContext(t.Context(), testSession("mine"))
+	other := s.WithContext(t.Context(), testSession("other"))
+	call := func(ctx context.Context, name string) {
+		req := mcp.CallToolRequest{}
+		req.Params.Name = name
+		_, _ = handler(ctx, req)
+	}
+	for range failingToolCalls {
+		call(mine, "get_run")
+	}
+	call(mine, "run_script")
+	call(other, "info")
+
+	result, err := serverStats(mine, stats)
+	require.NoError(t, err)
+	var resp serverStatsResponse
+	decodeJSON(t, result, &resp)
+
+	assert.Equal(t, int64(7), resp.Calls)
+	assert.Equal(t, int64(6), resp.Errors)
+	assert.InDelta(t, 0.857, resp.ErrorRate, 1e-9)
+	require.Len(t, resp.Tools, 3)
+	assert.Equal(t, "get_run", resp.Tools[0].Tool)
+	assert.InDelta(t, 1.0, resp.Tools[0].ErrorRate, 1e-9)
+
+	require.NotNil(t, resp.Session)
+	assert.True(t, resp.Session.Current)
+	assert.Equal(t, int64(6), resp.Session.Calls)
+	assert.Len(t, resp.Session.Tools, 2)
+	require.Len(t, resp.Sessions, 2)
+	for _, session := range resp.Sessions {
+		assert.NotContains(t, []string{"mine", "other"}, session.ID)
+		assert.Empty(t, session.Tools, "only the calling session lists its tools")
+	}
+
+	require.Len(t, resp.NextSteps, 1)
+	assert.Contains(t, resp.NextSteps[0], "get_run failed 5 of 5 calls")
+}
+
+func TestServerStatsEmpty(t *testing.T) {
+	t.Parallel()
+
+	result, err := serverStats(t.Context(), usage.New())
+	require.NoError(t, err)
+	var resp serverStatsResponse
+	decodeJSON(t, result, &resp)
+	assert.Zero(t, resp.Calls)
+	assert.Nil(t, resp.Session)
+	assert.Empty(t, resp.Sessions)
+	assert.Contains(t, resp.NextSteps[0], "No tool call")
+}
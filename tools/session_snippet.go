@@ -0,0 +1,458 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/grafana/mcp-k6/internal/logging"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// GenerateSessionSnippetTool exposes a tool for writing the session handling
+// of scripts logging in through a web form.
+//
+//nolint:gochecknoglobals // Shared tool definition registered at startup.
+var GenerateSessionSnippetTool = mcp.NewTool(
+	"generate_session_snippet",
+	readOnlyTool(),
+	mcp.WithDescription(
+		"Generate k6 code logging in through a described login flow and keeping the session in the VU's cookie "+
+			"jar: it loads the login page, extracts the CSRF token (hidden input, meta tag, cookie, header or JSON "+
+			"field), posts the credentials with the token, checks the session cookie, and logs in again when the "+
+			"session expires. Use it when a recorded login breaks on replay; analyze_correlation finds the other "+
+			"dynamic values. Credentials are read from environment variables or k6/secrets, never written in "+
+			"the script.",
+	),
+	mcp.WithString(
+		"base_url",
+		mcp.Required(),
+		mcp.Description("The origin of the application, e.g. https://shop.example.com. "+
+			"The script reads it from BASE_URL first."),
+	),
+	mcp.WithString(
+		"login_page",
+		mcp.Description("Optional: path of the page or endpoint serving the CSRF token (default: /login)."),
+	),
+	mcp.WithString(
+		"login_path",
+		mcp.Description("Optional: path the credentials are posted to (default: login_page)."),
+	),
+	mcp.WithString(
+		"login_format",
+		mcp.Description("Optional: 'form' (default) to post an urlencoded form, or 'json'."),
+		mcp.Enum(loginForm, loginJSON),
+	),
+	mcp.WithString(
+		"username_field",
+		mcp.Description("Optional: the field of the username (default: username)."),
+	),
+	mcp.WithString(
+		"password_field",
+		mcp.Description("Optional: the field of the password (default: password)."),
+	),
+	mcp.WithString(
+		"csrf_source",
+		mcp.Description("Optional: where the login page gives the CSRF token: 'html_input' (default), "+
+			"'html_meta', 'cookie', 'header', 'json', or 'none'."),
+		mcp.Enum(csrfInput, csrfMeta, csrfCookie, csrfHeader, csrfJSON, csrfNone),
+	),
+	mcp.WithString(
+		"csrf_name",
+		mcp.Description("Optional: the name of the token input, meta tag, cookie or header, or its JSON path "+
+			"(default by source: csrf_token, csrf-token, XSRF-TOKEN, X-CSRF-Token, csrfToken)."),
+	),
+	mcp.WithString(
+		"csrf_send",
+		mcp.Description("Optional: how the login request sends the token back, 'field' or 'header' "+
+			"(default: field for html_input, header otherwise)."),
+		mcp.Enum(csrfSendField, csrfSendHeader),
+	),
+	mcp.WithString(
+		"csrf_send_name",
+		mcp.Description("Optional: the field or header sending the token (default: csrf_name for fields, "+
+			"X-XSRF-TOKEN for the XSRF-TOKEN cookie, X-CSRF-Token otherwise)."),
+	),
+	mcp.WithString(
+		"session_cookie",
+		mcp.Description("Optional: the session cookie the login sets, e.g. 'sessionid', checked after logging in."),
+	),
+	mcp.WithString(
+		"session_scope",
+		mcp.Description("Optional: 'vu' (default) logs in once per VU and keeps the session across iterations; "+
+			"'iteration' clears the cookie jar and logs in on every iteration."),
+		mcp.Enum(sessionVU, sessionIteration),
+	),
+	mcp.WithString(
+		"protected_path",
+		mcp.Description("Optional: a page only logged-in users see, requested by each iteration (default: /)."),
+	),
+	mcp.WithString(
+		"credential_source",
+		mcp.Description("Where the script reads credentials: 'env' (default) or 'secrets' (k6/secrets)."),
+		mcp.Enum(credentialEnv, credentialSecrets),
+	),
+	mcp.WithObject(
+		"credentials",
+		mcp.Description("Optional: names of the variables or secrets holding the username and password, e.g. "+
+			"{\"username\": \"SHOP_USER\"} (default: LOGIN_USERNAME and LOGIN_PASSWORD)."),
+		mcp.AdditionalProperties(map[string]any{"type": "string"}),
+	),
+)
+
+// Login formats, CSRF token sources and session scopes of
+// generate_session_snippet.
+const (
+	loginForm = "form"
+	loginJSON = "json"
+
+	csrfInput  = "html_input"
+	csrfMeta   = "html_meta"
+	csrfCookie = "cookie"
+	csrfHeader = "header"
+	csrfJSON   = "json"
+	csrfNone   = "none"
+
+	csrfSendField  = "field"
+	csrfSendHeader = "header"
+
+	sessionVU        = "vu"
+	sessionIteration = "iteration"
+)
+
+// csrfDefaultNames are the default token names of each source.
+//
+//nolint:gochecknoglobals // Lookup table.
+var csrfDefaultNames = map[string]string{
+	csrfInput:  "csrf_token",
+	csrfMeta:   "csrf-token",
+	csrfCookie: "XSRF-TOKEN",
+	csrfHeader: "X-CSRF-Token",
+	csrfJSON:   "csrfToken",
+	csrfNone:   "",
+}
+
+// loginRoles are the credentials of a login flow.
+//
+//nolint:gochecknoglobals // Lookup table.
+var loginRoles = []credentialRole{
+	{Role: "username", Default: "LOGIN_USERNAME", Variable: "username"},
+	{Role: "password", Default: "LOGIN_PASSWORD", Variable: "password"},
+}
+
+// RegisterGenerateSessionSnippetTool registers the generate_session_snippet
+// tool with the MCP server.
+func RegisterGenerateSessionSnippetTool(s *server.MCPServer) {
+	s.AddTool(GenerateSessionSnippetTool, withToolLogger("generate_session_snippet", generateSessionSnippet))
+}
+
+// sessionOptions are the parameters of a generated login flow.
+type sessionOptions struct {
+	BaseURL       string
+	LoginPage     string
+	LoginPath     string
+	Format        string
+	UsernameField string
+	PasswordField string
+	CSRF          csrfToken
+	SessionCookie string
+	Scope         string
+	ProtectedPath string
+	Source        string
+	Credentials   []authCredential
+}
+
+// csrfToken describes how the login flow carries its CSRF token.
+type csrfToken struct {
+	Source string `json:"source"`
+	Name   string `json:"name,omitempty"`
+	// Send is "field" or "header", and SendName the field or header name.
+	Send     string `json:"send,omitempty"`
+	SendName string `json:"send_name,omitempty"`
+}
+
+// generateSessionSnippetResponse is the JSON structure returned by the tool.
+type generateSessionSnippetResponse struct {
+	Script      string           `json:"script"`
+	CSRF        csrfToken        `json:"csrf"`
+	Credentials []authCredential `json:"credentials"`
+	Warnings    []string         `json:"warnings,omitempty"`
+	NextSteps   []string         `json:"next_steps"`
+}
+
+func generateSessionSnippet(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger := logging.LoggerFromContext(ctx)
+
+	opts, warnings, err := sessionSnippetOptions(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	script := sessionScript(opts)
+
+	names := make([]string, 0, len(opts.Credentials))
+	for _, c := range opts.Credentials {
+		names = append(names, c.Name)
+	}
+	provide := fmt.Sprintf("Provide %s with the secrets parameter of run_script, or in an env_file kept out "+
+		"of version control", strings.Join(names, " and "))
+	if opts.Source == credentialSecrets {
+		provide = fmt.Sprintf("Provide the secrets %s with the secret_sources parameter of run_script",
+			strings.Join(names, " and "))
+	}
+
+	logger.InfoContext(ctx, "Session snippet generated",
+		slog.String("csrf_source", opts.CSRF.Source),
+		slog.String("session_scope", opts.Scope),
+		slog.Int("script_size", len(script)))
+
+	return marshalResponse(ctx, logger, generateSessionSnippetResponse{
+		Script:      script,
+		CSRF:        opts.CSRF,
+		Credentials: opts.Credentials,
+		Warnings:    warnings,
+		NextSteps: []string{
+			provide,
+			"Use validate_script to run the login once, and check that 'login succeeded' passes",
+			"Replace the recorded login requests of your script with login(), and remove their recorded " +
+				"Cookie headers: the cookie jar sends the session",
+			"Use analyze_correlation on the recording to find the other dynamic values to extract",
+			"For many users, read the credentials from a SharedArray of a data file instead, one row per VU",
+		},
+	})
+}
+
+// sessionSnippetOptions reads and checks the parameters of request.
+func sessionSnippetOptions(request mcp.CallToolRequest) (sessionOptions, []string, error) {
+	opts := sessionOptions{
+		BaseURL:       strings.TrimRight(strings.TrimSpace(request.GetString("base_url", "")), "/"),
+		LoginPage:     strings.TrimSpace(request.GetString("login_page", "/login")),
+		Format:        request.GetString("login_format", loginForm),
+		UsernameField: strings.TrimSpace(request.GetString("username_field", "username")),
+		PasswordField: strings.TrimSpace(request.GetString("password_field", "password")),
+		SessionCookie: strings.TrimSpace(request.GetString("session_cookie", "")),
+		Scope:         request.GetString("session_scope", sessionVU),
+		ProtectedPath: strings.TrimSpace(request.GetString("protected_path", "/")),
+		Source:        request.GetString("credential_source", credentialEnv),
+		CSRF:          csrfToken{Source: request.GetString("csrf_source", csrfInput)},
+	}
+	opts.LoginPath = strings.TrimSpace(request.GetString("login_path", opts.LoginPage))
+
+	defaultName, ok := csrfDefaultNames[opts.CSRF.Source]
+	switch {
+	case !isHTTPURL(opts.BaseURL):
+		return opts, nil, fmt.Errorf("invalid base_url %q: expected an absolute http or https URL", opts.BaseURL)
+	case !ok:
+		return opts, nil, fmt.Errorf("invalid csrf_source %q: use 'html_input', 'html_meta', 'cookie', "+
+			"'header', 'json' or 'none'", opts.CSRF.Source)
+	case opts.Format != loginForm && opts.Format != loginJSON:
+		return opts, nil, fmt.Errorf("invalid login_format %q: use 'form' or 'json'", opts.Format)
+	case opts.Scope != sessionVU && opts.Scope != sessionIteration:
+		return opts, nil, fmt.Errorf("invalid session_scope %q: use 'vu' or 'iteration'", opts.Scope)
+	case opts.Source != credentialEnv && opts.Source != credentialSecrets:
+		return opts, nil, fmt.Errorf("invalid credential_source %q: use 'env' or 'secrets'", opts.Source)
+	case opts.UsernameField == "" || opts.PasswordField == "":
+		return opts, nil, errors.New("username_field and password_field must not be empty")
+	}
+	// login_path defaults to login_page, so the page is checked first
+	for _, p := range []struct{ param, path string }{
+		{"login_page", opts.LoginPage}, {"login_path", opts.LoginPath}, {"protected_path", opts.ProtectedPath},
+	} {
+		if !strings.HasPrefix(p.path, "/") {
+			return opts, nil, fmt.Errorf("invalid %s %q: expected a path starting with /", p.param, p.path)
+		}
+	}
+
+	var warnings []string
+	if opts.CSRF.Source != csrfNone {
+		opts.CSRF.Name = strings.TrimSpace(request.GetString("csrf_name", defaultName))
+		opts.CSRF.Send = request.GetString("csrf_send", "")
+		if opts.CSRF.Send == "" {
+			opts.CSRF.Send = csrfSendHeader
+			if opts.CSRF.Source == csrfInput {
+				opts.CSRF.Send = csrfSendField
+			}
+		}
+		if opts.CSRF.Send != csrfSendField && opts.CSRF.Send != csrfSendHeader {
+			return opts, nil, fmt.Errorf("invalid csrf_send %q: use 'field' or 'header'", opts.CSRF.Send)
+		}
+		opts.CSRF.SendName = strings.TrimSpace(request.GetString("csrf_send_name", ""))
+		if opts.CSRF.SendName == "" {
+			switch {
+			case opts.CSRF.Send == csrfSendField:
+				opts.CSRF.SendName = opts.CSRF.Name
+			case opts.CSRF.Source == csrfCookie && strings.EqualFold(opts.CSRF.Name, "XSRF-TOKEN"):
+				opts.CSRF.SendName = "X-XSRF-TOKEN"
+			default:
+				opts.CSRF.SendName = "X-CSRF-Token"
+			}
+		}
+		if opts.CSRF.Name == "" || opts.CSRF.SendName == "" {
+			return opts, nil, errors.New("csrf_name and csrf_send_name must not be empty")
+		}
+	} else if opts.SessionCookie == "" {
+		warnings = append(warnings, "Without a CSRF token or session_cookie, only the protected page "+
+			"tells whether the login worked")
+	}
+	if strings.HasPrefix(opts.BaseURL, "http://") {
+		warnings = append(warnings, "base_url uses http://: the credentials and session cookie are sent in "+
+			"clear text, and cookies marked Secure are not sent back")
+	}
+
+	credentials, err := credentialsArgument(request, opts.Source, "the login flow", loginRoles)
+	if err != nil {
+		return opts, nil, err
+	}
+	opts.Credentials = credentials
+	return opts, warnings, nil
+}
+
+// sessionScript writes the script of opts.
+func sessionScript(opts sessionOptions) string {
+	secrets := opts.Source == credentialSecrets
+	async := ""
+	await := ""
+	if secrets {
+		async = "async "
+		await = "await "
+	}
+
+	w := &codeWriter{}
+	w.line("// Generated by mcp-k6 for a login flow. Credentials are read when the test runs:")
+	w.line("// none is written here.")
+	w.line("import http from 'k6/http';")
+	w.line("import { check, fail } from 'k6';")
+	if secrets {
+		w.line("import secrets from 'k6/secrets';")
+	}
+	w.line("")
+
+	w.open("export const options = {")
+	w.line("vus: 1,")
+	w.line("iterations: 1,")
+	w.open("thresholds: {")
+	w.line("checks: ['rate>0.99'],")
+	w.close("},")
+	w.close("};")
+	w.line("")
+
+	w.line(fmt.Sprintf("const BASE_URL = __ENV.BASE_URL || %s;", jsString(opts.BaseURL)))
+	w.line(fmt.Sprintf("const LOGIN_PAGE = %s;", jsString(opts.LoginPage)))
+	if opts.Scope == sessionVU {
+		w.line("")
+		w.line("// loggedIn tells whether the cookie jar of this VU holds a session.")
+		w.line("let loggedIn = false;")
+	}
+	w.line("")
+
+	w.open(fmt.Sprintf("export default %sfunction () {", async))
+	if opts.Scope == sessionIteration {
+		w.line("// Each iteration is a new user session.")
+		w.line("http.cookieJar().clear(BASE_URL);")
+		w.line(await + "login();")
+	} else {
+		w.open("if (!loggedIn) {")
+		w.line(await + "login();")
+		w.line("loggedIn = true;")
+		w.close("}")
+	}
+	w.line("")
+	w.line(fmt.Sprintf("const res = http.get(`${BASE_URL}%s`, { tags: { name: 'protected' } });",
+		jsTemplateBody(opts.ProtectedPath)))
+	w.line("const valid = res.status === 200 && !res.url.includes(LOGIN_PAGE);")
+	w.line("check(res, { 'session is valid': () => valid });")
+	if opts.Scope == sessionVU {
+		w.open("if (!valid) {")
+		w.line("// The session expired: log in again on the next iteration.")
+		w.line("http.cookieJar().clear(BASE_URL);")
+		w.line("loggedIn = false;")
+		w.close("}")
+	}
+	w.close("}")
+	w.line("")
+
+	w.line("// login logs in, leaving the session cookie in the cookie jar of the VU.")
+	w.open(fmt.Sprintf("%sfunction login() {", async))
+	credentialReads(w, opts.Credentials, opts.Source)
+	w.line("")
+	w.line("const page = http.get(`${BASE_URL}${LOGIN_PAGE}`, { tags: { name: 'login_page' } });")
+	if opts.CSRF.Source != csrfNone {
+		w.line("const csrf = " + csrfExtraction(opts.CSRF) + ";")
+		w.open("if (!csrf) {")
+		w.line(fmt.Sprintf("fail(%s);", jsString(fmt.Sprintf("no CSRF token (%s %s) in %s",
+			strings.ReplaceAll(opts.CSRF.Source, "_", " "), opts.CSRF.Name, opts.LoginPage))))
+		w.close("}")
+	}
+	w.line("")
+
+	w.open("const body = {")
+	w.line(fmt.Sprintf("%s: username,", jsKey(opts.UsernameField)))
+	w.line(fmt.Sprintf("%s: password,", jsKey(opts.PasswordField)))
+	if opts.CSRF.Send == csrfSendField {
+		w.line(fmt.Sprintf("%s: csrf,", jsKey(opts.CSRF.SendName)))
+	}
+	w.close("};")
+	var headers []string
+	if opts.Format == loginJSON {
+		headers = append(headers, "'Content-Type': 'application/json'")
+	}
+	if opts.CSRF.Send == csrfSendHeader {
+		headers = append(headers, jsString(opts.CSRF.SendName)+": csrf")
+	}
+	params := "{ tags: { name: 'login' } }"
+	if len(headers) > 0 {
+		params = fmt.Sprintf("{ headers: { %s }, tags: { name: 'login' } }", strings.Join(headers, ", "))
+	}
+	payload := "body"
+	if opts.Format == loginJSON {
+		payload = "JSON.stringify(body)"
+	}
+	w.line(fmt.Sprintf("const res = http.post(`${BASE_URL}%s`, %s, %s);",
+		jsTemplateBody(opts.LoginPath), payload, params))
+	w.line("// Failed logins often render the login page again with status 200.")
+	w.open("check(res, {")
+	w.line("'login succeeded': (r) => r.status < 400 && !r.url.includes(LOGIN_PAGE),")
+	if opts.SessionCookie != "" {
+		w.line(fmt.Sprintf("'session cookie is set': () => %s in http.cookieJar().cookiesForURL(BASE_URL),",
+			jsString(opts.SessionCookie)))
+	}
+	w.close("});")
+	w.close("}")
+	return w.String()
+}
+
+// csrfExtraction returns the expression reading token from the login page
+// response, named page.
+func csrfExtraction(token csrfToken) string {
+	switch token.Source {
+	case csrfMeta:
+		return fmt.Sprintf("page.html().find(%s).first().attr('content')",
+			jsString(fmt.Sprintf(`meta[name="%s"]`, token.Name)))
+	case csrfCookie:
+		return fmt.Sprintf("(http.cookieJar().cookiesForURL(BASE_URL)[%s] || [])[0]", jsString(token.Name))
+	case csrfHeader:
+		return fmt.Sprintf("page.headers[%s]", jsString(canonicalHeader(token.Name)))
+	case csrfJSON:
+		return fmt.Sprintf("page.json(%s)", jsString(token.Name))
+	default:
+		return fmt.Sprintf("page.html().find(%s).first().attr('value')",
+			jsString(fmt.Sprintf(`input[name="%s"]`, token.Name)))
+	}
+}
+
+// jsKey returns name as a JavaScript object key, quoted unless it is an
+// identifier.
+func jsKey(name string) string {
+	if jsIdentifier(name) == name {
+		return name
+	}
+	return jsString(name)
+}
+
+// jsTemplateBody escapes s for the inside of a JavaScript template literal.
+func jsTemplateBody(s string) string {
+	t := jsTemplate(s)
+	return t[1 : len(t)-1]
+}
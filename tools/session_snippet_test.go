@@ -0,0 +1,101 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateSessionSnippet(t *testing.T) {
+	t.Parallel()
+
+	result, err := generateSessionSnippet(t.Context(), newCallRequest(map[string]any{
+		"base_url":       "https://shop.example.com/",
+		"session_cookie": "sessionid",
+		"protected_path": "/account",
+	}))
+	require.NoError(t, err)
+	require.False(t, result.IsError, "unexpected tool error: %+v", result.Content)
+	var resp generateSessionSnippetResponse
+	decodeJSON(t, result, &resp)
+
+	assert.Equal(t, csrfToken{Source: "html_input", Name: "csrf_token", Send: "field", SendName: "csrf_token"}, resp.CSRF)
+	assert.Equal(t, []authCredential{
+		{Role: "username", Name: "LOGIN_USERNAME"},
+		{Role: "password", Name: "LOGIN_PASSWORD"},
+	}, resp.Credentials)
+	assert.Empty(t, resp.Warnings)
+
+	assert.Contains(t, resp.Script, `const BASE_URL = __ENV.BASE_URL || "https://shop.example.com";`)
+	assert.Contains(t, resp.Script, "let loggedIn = false;")
+	assert.Contains(t, resp.Script, "const username = __ENV.LOGIN_USERNAME;")
+	assert.Contains(t, resp.Script,
+		`const csrf = page.html().find("input[name=\"csrf_token\"]").first().attr('value');`)
+	assert.Contains(t, resp.Script, "csrf_token: csrf,")
+	assert.Contains(t, resp.Script, "const res = http.post(`${BASE_URL}/login`, body, { tags: { name: 'login' } });")
+	assert.Contains(t, resp.Script, `'session cookie is set': () => "sessionid" in http.cookieJar().cookiesForURL(BASE_URL),`)
+	assert.Contains(t, resp.Script, "const res = http.get(`${BASE_URL}/account`, { tags: { name: 'protected' } });")
+	assert.NotContains(t, resp.Script, "k6/secrets")
+}
+
+func TestSessionScriptCSRFSources(t *testing.T) {
+	t.Parallel()
+
+	result, err := generateSessionSnippet(t.Context(), newCallRequest(map[string]any{
+		"base_url":          "https://shop.example.com",
+		"login_page":        "/api/session",
+		"login_format":      "json",
+		"username_field":    "e-mail",
+		"csrf_source":       "cookie",
+		"session_scope":     "iteration",
+		"credential_source": "secrets",
+	}))
+	require.NoError(t, err)
+	require.False(t, result.IsError, "unexpected tool error: %+v", result.Content)
+	var resp generateSessionSnippetResponse
+	decodeJSON(t, result, &resp)
+
+	assert.Equal(t, "X-XSRF-TOKEN", resp.CSRF.SendName)
+	assert.Contains(t, resp.Script, "import secrets from 'k6/secrets';")
+	assert.Contains(t, resp.Script, "export default async function () {")
+	assert.Contains(t, resp.Script, "http.cookieJar().clear(BASE_URL);\n  await login();")
+	assert.Contains(t, resp.Script, `const username = await secrets.get("login_username");`)
+	assert.Contains(t, resp.Script, `const csrf = (http.cookieJar().cookiesForURL(BASE_URL)["XSRF-TOKEN"] || [])[0];`)
+	assert.Contains(t, resp.Script, `"e-mail": username,`)
+	assert.Contains(t, resp.Script, "JSON.stringify(body), "+
+		`{ headers: { 'Content-Type': 'application/json', "X-XSRF-TOKEN": csrf }, tags: { name: 'login' } });`)
+	assert.NotContains(t, resp.Script, "loggedIn")
+
+	for token, want := range map[csrfToken]string{
+		{Source: csrfMeta, Name: "csrf-token"}:     `page.html().find("meta[name=\"csrf-token\"]").first().attr('content')`,
+		{Source: csrfHeader, Name: "x-csrf-token"}: `page.headers["X-Csrf-Token"]`,
+		{Source: csrfJSON, Name: "data.csrf"}:      `page.json("data.csrf")`,
+	} {
+		assert.Equal(t, want, csrfExtraction(token))
+	}
+}
+
+func TestGenerateSessionSnippetErrors(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		args map[string]any
+		want string
+	}{
+		{map[string]any{"base_url": "shop.example.com"}, "invalid base_url"},
+		{map[string]any{"base_url": "https://shop.example.com", "csrf_source": "url"}, `invalid csrf_source "url"`},
+		{map[string]any{"base_url": "https://shop.example.com", "login_page": "login"}, "invalid login_page"},
+		{map[string]any{"base_url": "https://shop.example.com", "csrf_send": "query"}, `invalid csrf_send "query"`},
+		{
+			map[string]any{"base_url": "https://shop.example.com", "credentials": map[string]any{"otp": "OTP"}},
+			`unknown credential "otp" for the login flow`,
+		},
+	} {
+		result, err := generateSessionSnippet(t.Context(), newCallRequest(tc.args))
+		require.NoError(t, err)
+		require.True(t, result.IsError, tc.want)
+		assert.Contains(t, result.Content[0].(mcp.TextContent).Text, tc.want)
+	}
+}
@@ -0,0 +1,342 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+
+	"github.com/grafana/mcp-k6/internal/logging"
+	"github.com/grafana/mcp-k6/internal/slo"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// slosDescription documents the slos parameter of run_script and schedule_run.
+const slosDescription = "Optional: names of SLOs defined with define_slo to check the run against. Each is " +
+	"added as a threshold, and the result judges the run against it in slos: met, breached or fast_burn, " +
+	"with the error budget burn rate of error-rate objectives."
+
+// DefineSLOTool exposes a tool for defining a service level objective.
+//
+//nolint:gochecknoglobals // Shared tool definition registered at startup.
+var DefineSLOTool = mcp.NewTool(
+	"define_slo",
+	controlTool(false),
+	mcp.WithDescription(
+		"Define a service level objective (SLO) for the requests of an endpoint or of a whole test, "+
+			"such as '99% of checkout requests under 500ms' or '99.9% of requests succeed'. Runs of any "+
+			"script can then be checked against it with the slos parameter of run_script and schedule_run, "+
+			"so what good looks like is kept apart from the scripts. Defining an existing name replaces it.",
+	),
+	mcp.WithString(
+		"name",
+		mcp.Required(),
+		mcp.Description("Name to refer to the SLO by, e.g. 'checkout-latency'."),
+	),
+	mcp.WithString(
+		"kind",
+		mcp.Required(),
+		mcp.Description("'latency' bounds the share of requests slower than latency; 'error_rate' the "+
+			"share of failed requests (http_req_failed)."),
+		mcp.Enum(string(slo.Latency), string(slo.ErrorRate)),
+	),
+	mcp.WithNumber(
+		"objective",
+		mcp.Required(),
+		mcp.Description("Percentage of requests that must be good, e.g. 99 or 99.9."),
+	),
+	mcp.WithString(
+		"latency",
+		mcp.Description("For latency objectives: the duration good requests stay under, e.g. '500ms'."),
+	),
+	mcp.WithObject(
+		"tags",
+		mcp.Description("Optional: request tags selecting the endpoint, e.g. {\"name\": \"checkout\"} for "+
+			"requests made with tags: {name: 'checkout'}. Without tags the SLO applies to every request."),
+	),
+	mcp.WithString(
+		"description",
+		mcp.Description("Optional: what the SLO protects, shown by list_slos."),
+	),
+)
+
+// ListSLOsTool exposes a tool for listing the defined SLOs.
+//
+//nolint:gochecknoglobals // Shared tool definition registered at startup.
+var ListSLOsTool = mcp.NewTool(
+	"list_slos",
+	readOnlyTool(),
+	mcp.WithDescription("List the SLOs defined with define_slo or the server configuration, with their k6 thresholds."),
+)
+
+// DeleteSLOTool exposes a tool for deleting an SLO.
+//
+//nolint:gochecknoglobals // Shared tool definition registered at startup.
+var DeleteSLOTool = mcp.NewTool(
+	"delete_slo",
+	controlTool(true),
+	mcp.WithDescription("Delete an SLO. Schedules created with it keep checking their runs against it."),
+	mcp.WithString(
+		"name",
+		mcp.Required(),
+		mcp.Description("The name of the SLO."),
+	),
+)
+
+// GetErrorBudgetTool exposes a tool for tracking the error budget of an SLO across runs.
+//
+//nolint:gochecknoglobals // Shared tool definition registered at startup.
+var GetErrorBudgetTool = mcp.NewTool(
+	"get_error_budget",
+	readOnlyTool(),
+	mcp.WithDescription(
+		"Track the error budget of an SLO across the run history: the background and scheduled runs "+
+			"checked against it with the slos parameter. Returns each run's verdict, how much of the "+
+			"budget the runs consumed on average, whether burn is trending better or worse, and the "+
+			"resulting risk, to see trend-based risk rather than single-run pass/fail.",
+	),
+	mcp.WithString(
+		"slo",
+		mcp.Required(),
+		mcp.Description("The name of the SLO."),
+	),
+	mcp.WithString(
+		"schedule_id",
+		mcp.Description("Optional: only count the runs of this schedule."),
+	),
+)
+
+// errorBudgetResponse is the JSON structure returned by get_error_budget.
+type errorBudgetResponse struct {
+	SLO sloResponse `json:"slo"`
+	slo.Budget
+	// Observations are the runs' verdicts, oldest first.
+	Observations []slo.Observation `json:"observations"`
+	NextSteps    []string          `json:"next_steps,omitempty"`
+}
+
+// sloResponse describes an SLO.
+type sloResponse struct {
+	slo.SLO
+	// Threshold is the k6 threshold the SLO is checked with.
+	Threshold map[string][]string `json:"threshold"`
+}
+
+// RegisterSLOTools registers the define_slo, list_slos, delete_slo and get_error_budget tools with the
+// MCP server.
+func RegisterSLOTools(s *server.MCPServer, objectives *slo.Registry, runs *Runs) {
+	s.AddTool(DefineSLOTool, withToolLogger("define_slo", newDefineSLOHandlerFunc(objectives)))
+	s.AddTool(ListSLOsTool, withToolLogger("list_slos", newListSLOsHandlerFunc(objectives)))
+	s.AddTool(DeleteSLOTool, withToolLogger("delete_slo", newDeleteSLOHandlerFunc(objectives)))
+	s.AddTool(GetErrorBudgetTool, withToolLogger("get_error_budget", newGetErrorBudgetHandlerFunc(objectives, runs)))
+}
+
+func newDefineSLOHandlerFunc(objectives *slo.Registry) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		logger := logging.LoggerFromContext(ctx)
+		s, err := sloDefinition(request)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		replaced, err := objectives.Define(s)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		logger.InfoContext(ctx, "SLO defined",
+			slog.String("slo", s.Name),
+			slog.String("kind", string(s.Kind)),
+			slog.Bool("replaced", replaced))
+
+		next := []string{
+			fmt.Sprintf("Pass slos: [%q] to run_script or schedule_run to check runs against it", s.Name),
+		}
+		if len(s.Tags) > 0 {
+			next = append(next, "Only requests carrying all the tags count; tag them in the script, e.g. "+
+				"http.get(url, {tags: {name: 'checkout'}})")
+		}
+		return marshalResponse(ctx, logger, map[string]any{
+			"slo":        describeSLO(s),
+			"replaced":   replaced,
+			"next_steps": next,
+		})
+	}
+}
+
+func newListSLOsHandlerFunc(objectives *slo.Registry) server.ToolHandlerFunc {
+	return func(ctx context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		logger := logging.LoggerFromContext(ctx)
+		list := []sloResponse{}
+		for _, s := range objectives.List() {
+			list = append(list, describeSLO(s))
+		}
+		return marshalResponse(ctx, logger, map[string]any{"slos": list})
+	}
+}
+
+func newDeleteSLOHandlerFunc(objectives *slo.Registry) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		logger := logging.LoggerFromContext(ctx)
+		name, err := request.RequireString("name")
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		s, err := objectives.Delete(name)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		logger.InfoContext(ctx, "SLO deleted", slog.String("slo", s.Name))
+		return marshalResponse(ctx, logger, map[string]any{"deleted": describeSLO(s)})
+	}
+}
+
+func newGetErrorBudgetHandlerFunc(objectives *slo.Registry, runs *Runs) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		logger := logging.LoggerFromContext(ctx)
+		name, err := request.RequireString("slo")
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		slos, err := objectives.Get(name)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		observations := sloObservations(runs, name, request.GetString("schedule_id", ""))
+
+		resp := errorBudgetResponse{
+			SLO:          describeSLO(slos[0]),
+			Budget:       slo.ErrorBudget(observations),
+			Observations: observations,
+		}
+		switch {
+		case len(observations) == 0:
+			resp.NextSteps = append(resp.NextSteps, fmt.Sprintf("No run in the history was checked against %s; "+
+				"pass slos: [%q] to run_script with background=true or to schedule_run", name, name))
+		case resp.Risk == slo.RiskExhausted:
+			resp.NextSteps = append(resp.NextSteps,
+				"The runs spent more than the whole error budget; look into the failing requests before adding load")
+		case resp.Risk == slo.RiskAtRisk:
+			resp.NextSteps = append(resp.NextSteps,
+				"The error budget is at risk; compare a recent run with an earlier one using get_run "+
+					"format=markdown and baseline_run_id")
+		}
+		return marshalResponse(ctx, logger, resp)
+	}
+}
+
+// sloObservations returns the verdicts of the ended runs checked against
+// the SLO called name, oldest first, optionally only those of a schedule.
+func sloObservations(runs *Runs, name, schedule string) []slo.Observation {
+	observations := []slo.Observation{}
+	if runs == nil {
+		return observations
+	}
+	for _, run := range runs.List() {
+		if schedule != "" && run.Schedule != schedule {
+			continue
+		}
+		result, err := run.Result()
+		if err != nil || result == nil {
+			continue
+		}
+		for _, v := range result.SLOs {
+			if v.SLO == name {
+				observations = append(observations, slo.Observation{
+					RunID:     run.ID,
+					Schedule:  run.Schedule,
+					StartedAt: run.StartedAt,
+					Verdict:   v,
+				})
+			}
+		}
+	}
+	return observations
+}
+
+func describeSLO(s slo.SLO) sloResponse {
+	return sloResponse{SLO: s, Threshold: map[string][]string{s.Metric(): {s.Expression()}}}
+}
+
+// sloDefinition reads the SLO of a define_slo request.
+func sloDefinition(request mcp.CallToolRequest) (slo.SLO, error) {
+	name, err := request.RequireString("name")
+	if err != nil {
+		return slo.SLO{}, err
+	}
+	kind, err := request.RequireString("kind")
+	if err != nil {
+		return slo.SLO{}, err
+	}
+	objective, err := request.RequireFloat("objective")
+	if err != nil {
+		return slo.SLO{}, err
+	}
+	s := slo.SLO{
+		Name:        name,
+		Kind:        slo.Kind(kind),
+		Objective:   objective,
+		Latency:     request.GetString("latency", ""),
+		Description: request.GetString("description", ""),
+	}
+	if raw, ok := request.GetArguments()["tags"]; ok && raw != nil {
+		obj, ok := raw.(map[string]any)
+		if !ok {
+			return slo.SLO{}, errors.New("'tags' must be an object mapping tag names to values")
+		}
+		s.Tags = make(map[string]string, len(obj))
+		for key, v := range obj {
+			value, ok := v.(string)
+			if !ok {
+				return slo.SLO{}, fmt.Errorf("'tags.%s' must be a string", key)
+			}
+			s.Tags[key] = value
+		}
+	}
+	return s, s.Validate()
+}
+
+// applySLOs sets the SLOs named by the slos parameter on options and adds
+// their thresholds.
+func applySLOs(objectives *slo.Registry, request mcp.CallToolRequest, options *RunOptions) error {
+	names := request.GetStringSlice("slos", nil)
+	if len(names) == 0 {
+		return nil
+	}
+	if objectives == nil {
+		return errors.New("SLOs are not available")
+	}
+	slos, err := objectives.Get(names...)
+	if err != nil {
+		return err
+	}
+	if options.Thresholds == nil {
+		options.Thresholds = make(map[string][]string, len(slos))
+	}
+	for _, s := range slos {
+		metric, expr := s.Metric(), s.Expression()
+		if !slices.Contains(options.Thresholds[metric], expr) {
+			options.Thresholds[metric] = append(options.Thresholds[metric], expr)
+		}
+	}
+	options.SLOs = slos
+	return nil
+}
+
+// sloNextSteps points out the SLOs a run did not meet.
+func sloNextSteps(verdicts []slo.Verdict) []string {
+	var steps []string
+	for _, v := range verdicts {
+		switch {
+		case v.Verdict == slo.NoData:
+			steps = append(steps, fmt.Sprintf("SLO %s has no result in the summary; check that the run "+
+				"completed and that requests carry the SLO's tags", v.SLO))
+		case v.BurnRate != nil && v.Verdict != slo.Met:
+			steps = append(steps, fmt.Sprintf("SLO %s %s: errors spent the budget %.4gx faster than allowed (%s)",
+				v.SLO, v.Verdict, *v.BurnRate, v.Observed))
+		case v.Verdict == slo.Breached:
+			steps = append(steps, fmt.Sprintf("SLO %s breached: %s against %s", v.SLO, v.Observed, v.Threshold))
+		}
+	}
+	return steps
+}
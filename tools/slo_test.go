@@ -0,0 +1,150 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/grafana/mcp-k6/internal/slo"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSLOTools(t *testing.T) {
+	t.Parallel()
+
+	objectives, err := slo.NewRegistry(nil)
+	require.NoError(t, err)
+
+	result := callRunControl(t, newDefineSLOHandlerFunc(objectives), map[string]any{
+		"name":      "checkout-latency",
+		"kind":      "latency",
+		"objective": float64(99),
+		"latency":   "500ms",
+		"tags":      map[string]any{"name": "checkout"},
+	})
+	require.False(t, result.IsError, result.Content)
+	var defined struct {
+		SLO       sloResponse `json:"slo"`
+		Replaced  bool        `json:"replaced"`
+		NextSteps []string    `json:"next_steps"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &defined))
+	assert.False(t, defined.Replaced)
+	assert.Equal(t, map[string][]string{"http_req_duration{name:checkout}": {"p(99)<500"}}, defined.SLO.Threshold)
+	assert.Contains(t, defined.NextSteps[1], "tags: {name: 'checkout'}")
+
+	result = callRunControl(t, newDefineSLOHandlerFunc(objectives), map[string]any{
+		"name": "errors", "kind": "error_rate", "objective": float64(120),
+	})
+	require.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "objective must be a percentage")
+
+	listed := callRunControl(t, newListSLOsHandlerFunc(objectives), nil)
+	assert.Contains(t, listed.Content[0].(mcp.TextContent).Text, `"name": "checkout-latency"`)
+
+	result = callRunControl(t, newDeleteSLOHandlerFunc(objectives), map[string]any{"name": "checkout-latency"})
+	require.False(t, result.IsError, result.Content)
+	assert.Empty(t, objectives.List())
+	result = callRunControl(t, newDeleteSLOHandlerFunc(objectives), map[string]any{"name": "checkout-latency"})
+	require.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "unknown SLO")
+}
+
+func TestApplySLOs(t *testing.T) {
+	t.Parallel()
+
+	objectives, err := slo.NewRegistry([]slo.SLO{
+		{Name: "errors", Kind: slo.ErrorRate, Objective: 99.5},
+		{Name: "latency", Kind: slo.Latency, Objective: 95, Latency: "300ms"},
+	})
+	require.NoError(t, err)
+
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]any{"slos": []any{"errors", "latency"}}
+	options := &RunOptions{Thresholds: map[string][]string{"http_req_duration": {"p(95)<300", "max<2000"}}}
+	require.NoError(t, applySLOs(objectives, req, options))
+	assert.Equal(t, map[string][]string{
+		"http_req_duration": {"p(95)<300", "max<2000"},
+		"http_req_failed":   {"rate<=0.005"},
+	}, options.Thresholds)
+	require.Len(t, options.SLOs, 2)
+
+	req.Params.Arguments = map[string]any{"slos": []any{"throughput"}}
+	err = applySLOs(objectives, req, &RunOptions{})
+	require.ErrorIs(t, err, slo.ErrUnknown)
+
+	empty := &RunOptions{}
+	require.NoError(t, applySLOs(nil, mcp.CallToolRequest{}, empty))
+	assert.Nil(t, empty.Thresholds)
+}
+
+func TestSLONextSteps(t *testing.T) {
+	t.Parallel()
+
+	burn := 3.2
+	steps := sloNextSteps([]slo.Verdict{
+		{SLO: "errors", Verdict: slo.Breached, BurnRate: &burn, Observed: "rate=1.60%"},
+		{SLO: "latency", Verdict: slo.Breached, Observed: "p(95)=412ms", Threshold: "http_req_duration: p(95)<300"},
+		{SLO: "search", Verdict: slo.NoData},
+		{SLO: "ok", Verdict: slo.Met},
+	})
+	require.Len(t, steps, 3)
+	assert.Equal(t, "SLO errors breached: errors spent the budget 3.2x faster than allowed (rate=1.60%)", steps[0])
+	assert.Equal(t, "SLO latency breached: p(95)=412ms against http_req_duration: p(95)<300", steps[1])
+	assert.Contains(t, steps[2], "SLO search has no result")
+}
+
+func TestGetErrorBudget(t *testing.T) {
+	t.Parallel()
+
+	objectives, err := slo.NewRegistry([]slo.SLO{{Name: "errors", Kind: slo.ErrorRate, Objective: 99}})
+	require.NoError(t, err)
+
+	burns := []float64{0.2, 0.4, 0.9}
+	runs := NewRuns(nil, nil, nil)
+	calls := 0
+	runs.execute = func(context.Context, string, *RunOptions) (*RunResult, error) {
+		burn := burns[calls]
+		calls++
+		return &RunResult{Success: true, SLOs: []slo.Verdict{{SLO: "errors", Verdict: slo.Met, BurnRate: &burn}}}, nil
+	}
+	t.Cleanup(runs.Close)
+	for i := range burns {
+		schedule := ""
+		if i > 0 {
+			schedule = "schedule-1"
+		}
+		run, err := runs.start(context.Background(), testRunScript, nil, schedule)
+		require.NoError(t, err)
+		require.NoError(t, run.Wait(context.Background()))
+	}
+
+	handler := newGetErrorBudgetHandlerFunc(objectives, runs)
+	result := callRunControl(t, handler, map[string]any{"slo": "errors"})
+	require.False(t, result.IsError, result.Content)
+	var resp errorBudgetResponse
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &resp))
+	assert.Equal(t, 3, resp.Runs)
+	require.NotNil(t, resp.Consumed)
+	assert.InDelta(t, 50.0, *resp.Consumed, 0)
+	assert.Equal(t, slo.Worsening, resp.Trend)
+	assert.Equal(t, slo.RiskAtRisk, resp.Risk)
+	require.Len(t, resp.Observations, 3)
+	assert.Equal(t, "run-1", resp.Observations[0].RunID)
+
+	result = callRunControl(t, handler, map[string]any{"slo": "errors", "schedule_id": "schedule-1"})
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &resp))
+	assert.Equal(t, 2, resp.Runs)
+	assert.Equal(t, "schedule-1", resp.Observations[0].Schedule)
+
+	result = callRunControl(t, handler, map[string]any{"slo": "latency"})
+	require.True(t, result.IsError)
+
+	empty, err := slo.NewRegistry([]slo.SLO{{Name: "latency", Kind: slo.Latency, Objective: 95, Latency: "1s"}})
+	require.NoError(t, err)
+	result = callRunControl(t, newGetErrorBudgetHandlerFunc(empty, runs), map[string]any{"slo": "latency"})
+	require.False(t, result.IsError, result.Content)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "No run in the history was checked against latency")
+}
@@ -0,0 +1,87 @@
+package tools
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/grafana/mcp-k6/internal/fuzzy"
+	"github.com/grafana/xk6-docs/docs"
+)
+
+const (
+	// maxSlugSuggestions is the number of slugs suggested for an unknown one.
+	maxSlugSuggestions = 5
+	// minContainedLength is the shortest last segment matched inside slugs,
+	// as short ones like "k6" are in most of them.
+	minContainedLength = 4
+)
+
+// suggestSlugs returns the slugs of idx closest to an unknown slug, closest
+// first: slugs or aliases a few edits away, and slugs containing its last
+// segment, such as "using-k6/scenarios/executors" for "executors".
+func suggestSlugs(idx *docs.Index, slug string) []string {
+	query := normalizeSlug(slug)
+	if query == "" {
+		return nil
+	}
+	last := lastSegment(query)
+	threshold := max(2, len(last)/3)
+
+	best := make(map[string]int)
+	consider := func(candidate, target string) {
+		c := normalizeSlug(candidate)
+		score := min(fuzzy.Distance(query, c), fuzzy.Distance(last, lastSegment(c))+1)
+		if len(last) >= minContainedLength && strings.Contains(c, last) {
+			score = min(score, 2)
+		}
+		if score > threshold {
+			return
+		}
+		if prev, ok := best[target]; !ok || score < prev {
+			best[target] = score
+		}
+	}
+	for i := range idx.Sections {
+		sec := &idx.Sections[i]
+		consider(sec.Slug, sec.Slug)
+		for _, alias := range sec.Aliases {
+			consider(alias, sec.Slug)
+		}
+	}
+
+	out := make([]string, 0, len(best))
+	for s := range best {
+		out = append(out, s)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if best[out[i]] != best[out[j]] {
+			return best[out[i]] < best[out[j]]
+		}
+		if len(out[i]) != len(out[j]) {
+			return len(out[i]) < len(out[j])
+		}
+		return out[i] < out[j]
+	})
+	if len(out) > maxSlugSuggestions {
+		out = out[:maxSlugSuggestions]
+	}
+	return out
+}
+
+// didYouMean renders suggestions as a sentence to append to an error, empty
+// when there are none.
+func didYouMean(suggestions []string) string {
+	if len(suggestions) == 0 {
+		return ""
+	}
+	return " Did you mean: " + strings.Join(suggestions, ", ") + "?"
+}
+
+func normalizeSlug(s string) string {
+	s = strings.ToLower(strings.Trim(strings.TrimSpace(s), "/"))
+	return strings.NewReplacer("_", "-", " ", "-").Replace(s)
+}
+
+func lastSegment(s string) string {
+	return s[strings.LastIndexByte(s, '/')+1:]
+}
@@ -0,0 +1,43 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/grafana/xk6-docs/docs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSuggestSlugs(t *testing.T) {
+	t.Parallel()
+
+	idx := &docs.Index{Version: "v1.4.x", Sections: []docs.Section{
+		{Slug: "using-k6"},
+		{Slug: "using-k6/scenarios"},
+		{Slug: "using-k6/scenarios/executors"},
+		{Slug: "using-k6/scenarios/executors/ramping-vus", Aliases: []string{"ramping"}},
+		{Slug: "using-k6/thresholds"},
+		{Slug: "javascript-api/k6-http/request"},
+	}}
+
+	for _, tc := range []struct {
+		slug string
+		want []string
+	}{
+		{slug: "using-k6/scenarois", want: []string{"using-k6/scenarios"}},
+		{slug: "executors", want: []string{"using-k6/scenarios/executors", "using-k6/scenarios/executors/ramping-vus"}},
+		{slug: "Using_K6/Thresholds/", want: []string{"using-k6/thresholds"}},
+		{slug: "rampin", want: []string{"using-k6/scenarios/executors/ramping-vus"}},
+		{slug: "k6-http/requests", want: []string{"javascript-api/k6-http/request"}},
+		{slug: "grpc", want: []string{}},
+		{slug: "", want: nil},
+	} {
+		assert.Equal(t, tc.want, suggestSlugs(idx, tc.slug), tc.slug)
+	}
+}
+
+func TestDidYouMean(t *testing.T) {
+	t.Parallel()
+
+	assert.Empty(t, didYouMean(nil))
+	assert.Equal(t, " Did you mean: a, b?", didYouMean([]string{"a", "b"}))
+}
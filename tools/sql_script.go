@@ -0,0 +1,361 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/grafana/mcp-k6/internal/logging"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// GenerateSQLTestTool exposes a tool for writing database load tests with the
+// xk6-sql extension.
+//
+//nolint:gochecknoglobals // Shared tool definition registered at startup.
+var GenerateSQLTestTool = mcp.NewTool(
+	"generate_sql_test",
+	readOnlyTool(),
+	mcp.WithDescription(
+		"Generate a k6 database load test with the xk6-sql extension (k6/x/sql) and a driver extension. "+
+			"Each iteration runs one query of a weighted workload mix, timed in the sql_query_duration "+
+			"trend tagged with the query name, with a latency threshold per query and a threshold on "+
+			"failed queries. The response gives the xk6 build command and guidance on connection pooling.",
+	),
+	mcp.WithString(
+		"driver",
+		mcp.Required(),
+		mcp.Description("The database driver."),
+		mcp.Enum(sqlDrivers...),
+	),
+	mcp.WithString(
+		"dsn",
+		mcp.Required(),
+		mcp.Description("The connection string, e.g. postgres://user@localhost:5432/shop?sslmode=disable. "+
+			"The script reads it from SQL_DSN first; a DSN with a password is left out of the script."),
+	),
+	mcp.WithArray(
+		"queries",
+		mcp.Required(),
+		mcp.Description("The workload mix, e.g. [{\"name\": \"orders\", \"sql\": \"SELECT * FROM orders WHERE "+
+			"id = $1\", \"args\": [42], \"weight\": 8}]. Placeholders follow the driver: $1 for postgres, ? "+
+			"for mysql and sqlite3, @p1 for sqlserver. weight (default 1) is the relative share of iterations."),
+		mcp.Items(map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"name":   map[string]any{"type": "string"},
+				"sql":    map[string]any{"type": "string"},
+				"args":   map[string]any{"type": "array"},
+				"weight": map[string]any{"type": "number"},
+			},
+			"required": []string{"sql"},
+		}),
+	),
+	mcp.WithArray(
+		"setup",
+		mcp.WithStringItems(),
+		mcp.Description("Optional: statements run once before the test, e.g. to create and fill tables."),
+	),
+	mcp.WithString(
+		"latency_threshold",
+		mcp.Description(fmt.Sprintf("Optional: sql_query_duration threshold of each query "+
+			"(default: %q).", defaultSQLLatency)),
+	),
+)
+
+//nolint:gochecknoglobals // Lookup table.
+var sqlDrivers = []string{"postgres", "mysql", "sqlite3", "sqlserver", "clickhouse"}
+
+const (
+	// maxSQLQueries bounds the queries of a workload mix.
+	maxSQLQueries = 20
+	// defaultSQLLatency is the default latency threshold of queries.
+	defaultSQLLatency = "p(95)<200"
+)
+
+// sqlPassword matches the password of key=value and MySQL DSNs, such as
+// "password=secret" or "user:secret@tcp(localhost:3306)/shop".
+//
+//nolint:gochecknoglobals // Compiled once, read-only.
+var sqlPassword = regexp.MustCompile(`(?i)(^|[\s;])(password|pwd)\s*=|^[^:/@]+:[^@]+@`)
+
+// RegisterGenerateSQLTestTool registers the generate_sql_test tool with the
+// MCP server.
+func RegisterGenerateSQLTestTool(s *server.MCPServer) {
+	s.AddTool(GenerateSQLTestTool, withToolLogger("generate_sql_test", generateSQLTest))
+}
+
+// sqlQuery is a query of the workload mix.
+type sqlQuery struct {
+	Name   string  `json:"name"`
+	SQL    string  `json:"sql"`
+	Args   []any   `json:"args,omitempty"`
+	Weight float64 `json:"weight,omitempty"`
+	// Exec is set on statements returning no rows, run with db.exec.
+	Exec bool `json:"exec"`
+}
+
+// generateSQLTestResponse is the JSON structure returned by the tool.
+type generateSQLTestResponse struct {
+	Script string `json:"script"`
+	// Extensions are the Go modules of xk6-sql and of the driver.
+	Extensions   []string   `json:"extensions"`
+	BuildCommand string     `json:"build_command"`
+	Queries      []sqlQuery `json:"queries"`
+	// K6HasExtensions tells whether the k6 on the PATH was built with both
+	// extensions, and is omitted when k6 was not found.
+	K6HasExtensions *bool    `json:"k6_has_extensions,omitempty"`
+	Guidance        []string `json:"guidance"`
+	Warnings        []string `json:"warnings,omitempty"`
+	NextSteps       []string `json:"next_steps"`
+}
+
+func generateSQLTest(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger := logging.LoggerFromContext(ctx)
+
+	driver := request.GetString("driver", "")
+	if !slices.Contains(sqlDrivers, driver) {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid driver %q: use one of %s",
+			driver, strings.Join(sqlDrivers, ", "))), nil
+	}
+	dsn := strings.TrimSpace(request.GetString("dsn", ""))
+	if dsn == "" {
+		return mcp.NewToolResultError("dsn is required"), nil
+	}
+	latency := strings.TrimSpace(request.GetString("latency_threshold", defaultSQLLatency))
+	if latency == "" {
+		return mcp.NewToolResultError("latency_threshold is empty"), nil
+	}
+	queries, err := sqlQueries(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	var warnings []string
+	if dsnHasPassword(dsn) {
+		dsn = ""
+		warnings = append(warnings, "The DSN contains a password, so the script does not: "+
+			"set SQL_DSN in env_file, or read the password from a secret")
+	}
+	for _, q := range queries {
+		if q.Exec {
+			warnings = append(warnings, fmt.Sprintf("%s changes data on every iteration it runs: "+
+				"run the test against a test database", q.Name))
+		}
+	}
+	setup := request.GetStringSlice("setup", nil)
+	script := sqlScript(driver, dsn, queries, setup, latency)
+
+	modules := []string{"k6/x/sql", "k6/x/sql/driver/" + driver}
+	resp := generateSQLTestResponse{
+		Script:   script,
+		Queries:  queries,
+		Warnings: warnings,
+		Guidance: []string{
+			"Each VU opens its own connection pool in the init context, and runs one query at a time: " +
+				"the test holds about one connection per VU, so keep VUs below the server's connection " +
+				"limit (max_connections) minus the connections of the applications sharing it",
+			"Open the database in the init context, never in the default function: a connection per " +
+				"iteration measures connection setup, not queries",
+			"Compare sql_query_duration with the server's own statistics (pg_stat_statements, the slow " +
+				"query log) to tell query time from network and pool waits",
+			"Start at a low arrival rate and ramp up, e.g. with build_scenario and ramping-arrival-rate, " +
+				"watching the p(95) of each query rather than the average",
+		},
+	}
+	for _, m := range modules {
+		resp.Extensions = append(resp.Extensions, extensionModule(m))
+	}
+	resp.BuildCommand = xk6BuildCommand(resp.Extensions)
+	if extensions, ok := installedExtensions(ctx); ok {
+		has := providesModule(extensions, modules[0]) && providesModule(extensions, modules[1])
+		resp.K6HasExtensions = &has
+	}
+	if resp.K6HasExtensions == nil || !*resp.K6HasExtensions {
+		resp.NextSteps = append(resp.NextSteps, fmt.Sprintf("Build k6 with xk6-sql and the %s driver: %s, and "+
+			"put the ./k6 binary it writes on the PATH in place of k6", driver, resp.BuildCommand))
+	}
+	resp.NextSteps = append(resp.NextSteps,
+		"Use validate_script to run the script once against the database, with SQL_DSN in env_file",
+		"Set the load profile in options, e.g. with build_scenario, then use run_script",
+	)
+
+	logger.InfoContext(ctx, "SQL test generated",
+		slog.String("driver", driver),
+		slog.Int("queries", len(queries)),
+		slog.Int("script_size", len(script)))
+
+	return marshalResponse(ctx, logger, resp)
+}
+
+// sqlQueries reads the queries parameter, naming queries and setting the
+// default weight.
+func sqlQueries(request mcp.CallToolRequest) ([]sqlQuery, error) {
+	raw, ok := request.GetArguments()["queries"]
+	if !ok {
+		return nil, errors.New("queries is required")
+	}
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid queries: %w", err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	var queries []sqlQuery
+	if err := dec.Decode(&queries); err != nil {
+		return nil, fmt.Errorf("invalid queries: %w", err)
+	}
+	switch {
+	case len(queries) == 0:
+		return nil, errors.New("queries is empty")
+	case len(queries) > maxSQLQueries:
+		return nil, fmt.Errorf("too many queries: %d (max %d)", len(queries), maxSQLQueries)
+	}
+
+	var names []string
+	for i := range queries {
+		q := &queries[i]
+		q.SQL = strings.TrimSpace(q.SQL)
+		if q.SQL == "" {
+			return nil, fmt.Errorf("queries[%d]: sql is empty", i)
+		}
+		if q.Weight < 0 {
+			return nil, fmt.Errorf("queries[%d]: weight must not be negative", i)
+		}
+		if q.Weight == 0 {
+			q.Weight = 1
+		}
+		if q.Name == "" {
+			q.Name = fmt.Sprintf("query%d", i+1)
+		}
+		q.Name = uniqueIdentifier(q.Name, names)
+		names = append(names, q.Name)
+		q.Exec = !returnsRows(q.SQL)
+	}
+	return queries, nil
+}
+
+// returnsRows reports whether the statement returns rows, from its first
+// keyword.
+func returnsRows(statement string) bool {
+	keyword, _, _ := strings.Cut(strings.ToUpper(strings.TrimLeft(statement, "( \t\n")), " ")
+	switch strings.TrimSpace(keyword) {
+	case "SELECT", "WITH", "SHOW", "EXPLAIN", "VALUES", "DESCRIBE", "PRAGMA":
+		return true
+	}
+	return strings.Contains(strings.ToUpper(statement), " RETURNING ")
+}
+
+// dsnHasPassword reports whether the connection string holds a password.
+func dsnHasPassword(dsn string) bool {
+	if u, err := url.Parse(dsn); err == nil && u.User != nil {
+		if _, ok := u.User.Password(); ok {
+			return true
+		}
+	}
+	return !strings.Contains(dsn, "://") && sqlPassword.MatchString(dsn)
+}
+
+// sqlScript writes the load test of queries.
+func sqlScript(driver, dsn string, queries []sqlQuery, setup []string, latency string) string {
+	w := &codeWriter{}
+	w.line("// Generated by mcp-k6 for " + driver + ". Run it with a k6 binary built with xk6-sql and its driver:")
+	w.line("//   " + xk6BuildCommand([]string{
+		extensionModule("k6/x/sql"), extensionModule("k6/x/sql/driver/" + driver),
+	}))
+	w.line("import sql from 'k6/x/sql';")
+	w.line(fmt.Sprintf("import driver from 'k6/x/sql/driver/%s';", driver))
+	w.line("import { Counter, Trend } from 'k6/metrics';")
+	w.line("")
+
+	w.open("export const options = {")
+	w.line("vus: 1,")
+	w.line("iterations: 1,")
+	w.open("thresholds: {")
+	for _, q := range queries {
+		w.line(fmt.Sprintf("'sql_query_duration{query:%s}': [%s],", q.Name, jsString(latency)))
+	}
+	w.line("sql_query_errors: ['count == 0'],")
+	w.close("},")
+	w.close("};")
+	w.line("")
+
+	w.line("// Each VU opens its own connection pool; see the connection pooling guidance.")
+	w.line(fmt.Sprintf("const db = sql.open(driver, __ENV.SQL_DSN || %s);", jsString(dsn)))
+	w.line("")
+	w.line("const queryDuration = new Trend('sql_query_duration', true);")
+	w.line("const queryErrors = new Counter('sql_query_errors');")
+	w.line("")
+
+	w.line("// queries is the workload mix: each iteration runs one query, picked by weight.")
+	w.open("const queries = [")
+	for _, q := range queries {
+		args := "[]"
+		if len(q.Args) > 0 {
+			data, err := json.Marshal(q.Args)
+			if err == nil {
+				args = string(data)
+			}
+		}
+		w.line(fmt.Sprintf("{ name: %s, sql: %s, args: %s, weight: %v, exec: %t },",
+			jsString(q.Name), jsString(q.SQL), args, q.Weight, q.Exec))
+	}
+	w.close("];")
+	w.line("const totalWeight = queries.reduce((sum, q) => sum + q.weight, 0);")
+	w.line("")
+
+	if len(setup) > 0 {
+		w.open("export function setup() {")
+		for _, statement := range setup {
+			w.line(fmt.Sprintf("db.exec(%s);", jsString(strings.TrimSpace(statement))))
+		}
+		w.close("}")
+		w.line("")
+	}
+
+	w.open("export default function () {")
+	w.line("const query = pick();")
+	w.line("const tags = { query: query.name };")
+	w.line("const start = Date.now();")
+	w.open("try {")
+	w.open("if (query.exec) {")
+	w.line("db.exec(query.sql, ...query.args);")
+	w.close("} else {")
+	w.depth++
+	w.line("db.query(query.sql, ...query.args);")
+	w.close("}")
+	w.line("queryDuration.add(Date.now() - start, tags);")
+	w.close("} catch (e) {")
+	w.depth++
+	w.line("queryErrors.add(1, tags);")
+	w.line("console.error(`${query.name} failed: ${e}`);")
+	w.close("}")
+	w.close("}")
+	w.line("")
+
+	w.open("export function teardown() {")
+	w.line("db.close();")
+	w.close("}")
+	w.line("")
+
+	w.line("// pick returns a query of the mix, with a probability proportional to its weight.")
+	w.open("function pick() {")
+	w.line("let r = Math.random() * totalWeight;")
+	w.open("for (const query of queries) {")
+	w.line("r -= query.weight;")
+	w.open("if (r < 0) {")
+	w.line("return query;")
+	w.close("}")
+	w.close("}")
+	w.line("return queries[queries.length - 1];")
+	w.close("}")
+	return w.String()
+}
@@ -0,0 +1,427 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/grafana/mcp-k6/internal/logging"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// GenerateStreamingScriptTool exposes a tool for writing tests of
+// Server-Sent Events and long-polling endpoints.
+//
+//nolint:gochecknoglobals // Shared tool definition registered at startup.
+var GenerateStreamingScriptTool = mcp.NewTool(
+	"generate_streaming_script",
+	readOnlyTool(),
+	mcp.WithDescription(
+		"Generate a k6 script for an endpoint streaming events: Server-Sent Events, read with the xk6-sse "+
+			"extension (k6/x/sse), or long polling with k6/http. Each iteration waits for a number of events, "+
+			"recording the time to the first one, and reconnects when the stream drops or a poll fails, "+
+			"resuming after the last event (Last-Event-ID for SSE). SSE scripts need a k6 binary built with "+
+			"xk6-sse: the response tells whether the installed k6 has it and gives the xk6 build command otherwise.",
+	),
+	mcp.WithString(
+		"url",
+		mcp.Required(),
+		mcp.Description("The endpoint, e.g. https://api.example.com/events. "+
+			"The script reads it from STREAM_URL first."),
+	),
+	mcp.WithString(
+		"mode",
+		mcp.Description("'sse' (default) for a text/event-stream endpoint, or 'long_poll' for an endpoint "+
+			"holding each request open until events are available."),
+		mcp.Enum(streamSSE, streamLongPoll),
+	),
+	mcp.WithNumber(
+		"events",
+		mcp.Description(fmt.Sprintf("Optional: events each iteration waits for; for long_poll, the polls "+
+			"returning events (default: %d, max: %d).", defaultStreamEvents, maxStreamEvents)),
+	),
+	mcp.WithNumber(
+		"max_reconnects",
+		mcp.Description(fmt.Sprintf("Optional: reconnections an iteration makes after the stream drops or a "+
+			"poll fails (default: %d, max: %d).", defaultStreamReconnects, maxStreamReconnects)),
+	),
+	mcp.WithNumber(
+		"retry",
+		mcp.Description("Optional: seconds to wait before reconnecting (default: 1)."),
+	),
+	mcp.WithObject(
+		"headers",
+		mcp.Description("Optional: request headers, e.g. {\"Authorization\": \"Bearer ...\"}."),
+		mcp.AdditionalProperties(map[string]any{"type": "string"}),
+	),
+	mcp.WithString(
+		"poll_timeout",
+		mcp.Description(fmt.Sprintf("Optional, long_poll: the request timeout, longer than the time the server "+
+			"holds a poll open (default: %q).", defaultPollTimeout)),
+	),
+	mcp.WithString(
+		"max_wait",
+		mcp.Description(fmt.Sprintf("Optional, long_poll: how long an iteration polls for its events "+
+			"(default: %q).", defaultPollMaxWait)),
+	),
+	mcp.WithString(
+		"cursor_param",
+		mcp.Description("Optional, long_poll: the query parameter passing the position of the last event, "+
+			"e.g. 'since'. The script takes it from the ETag or Last-Event-ID header of responses."),
+	),
+	mcp.WithString(
+		"first_event_threshold",
+		mcp.Description(fmt.Sprintf("Optional: time-to-first-event threshold (default: %q).",
+			defaultFirstEventLatency)),
+	),
+)
+
+// Modes of generate_streaming_script.
+const (
+	streamSSE      = "sse"
+	streamLongPoll = "long_poll"
+)
+
+const (
+	defaultStreamEvents     = 10
+	maxStreamEvents         = 10000
+	defaultStreamReconnects = 3
+	maxStreamReconnects     = 100
+	defaultPollTimeout      = "60s"
+	defaultPollMaxWait      = "2m"
+	// defaultFirstEventLatency is the default time-to-first-event threshold.
+	defaultFirstEventLatency = "p(95)<1000"
+	// sseModule is the module of the xk6-sse extension.
+	sseModule = "k6/x/sse"
+)
+
+// queryParam matches a valid query parameter name.
+//
+//nolint:gochecknoglobals // Compiled once, read-only.
+var queryParam = regexp.MustCompile(`^[A-Za-z0-9_.~-]+$`)
+
+// RegisterGenerateStreamingScriptTool registers the generate_streaming_script
+// tool with the MCP server.
+func RegisterGenerateStreamingScriptTool(s *server.MCPServer) {
+	s.AddTool(GenerateStreamingScriptTool, withToolLogger("generate_streaming_script", generateStreamingScript))
+}
+
+// streamScriptOptions are the parameters of a generated streaming script.
+type streamScriptOptions struct {
+	URL           string
+	Mode          string
+	Events        int
+	MaxReconnects int
+	// Retry is the delay before reconnecting, in seconds.
+	Retry       float64
+	Headers     map[string]string
+	PollTimeout string
+	MaxWait     time.Duration
+	CursorParam string
+	FirstEvent  string
+}
+
+// generateStreamingScriptResponse is the JSON structure returned by the tool.
+type generateStreamingScriptResponse struct {
+	Script string `json:"script"`
+	// Metrics are the custom metrics of the script.
+	Metrics []string `json:"metrics"`
+	// Extension is the Go module of xk6-sse, for SSE scripts.
+	Extension    string `json:"extension,omitempty"`
+	BuildCommand string `json:"build_command,omitempty"`
+	// K6HasExtension tells whether the k6 on the PATH was built with xk6-sse,
+	// and is omitted for long polling or when k6 was not found.
+	K6HasExtension *bool    `json:"k6_has_extension,omitempty"`
+	Warnings       []string `json:"warnings,omitempty"`
+	NextSteps      []string `json:"next_steps"`
+}
+
+func generateStreamingScript(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger := logging.LoggerFromContext(ctx)
+
+	opts, warnings, err := streamOptions(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	script := streamScript(opts)
+
+	prefix := streamMetricPrefix(opts.Mode)
+	resp := generateStreamingScriptResponse{
+		Script: script,
+		Metrics: []string{
+			prefix + "time_to_first_event", prefix + "events", prefix + "reconnects",
+		},
+		Warnings: warnings,
+	}
+	if opts.Mode == streamSSE {
+		resp.Metrics = append(resp.Metrics, prefix+"errors")
+		resp.Extension = extensionModule(sseModule)
+		resp.BuildCommand = xk6BuildCommand([]string{resp.Extension})
+		if extensions, ok := installedExtensions(ctx); ok {
+			has := providesModule(extensions, sseModule)
+			resp.K6HasExtension = &has
+		}
+		if resp.K6HasExtension == nil || !*resp.K6HasExtension {
+			resp.NextSteps = append(resp.NextSteps, fmt.Sprintf("Build k6 with xk6-sse: %s, and put the ./k6 "+
+				"binary it writes on the PATH in place of k6", resp.BuildCommand))
+		}
+	} else {
+		resp.Metrics = append(resp.Metrics, prefix+"empty")
+	}
+	resp.NextSteps = append(resp.NextSteps,
+		"Use validate_script to run the script once against the endpoint, "+
+			"setting STREAM_URL in env_file for other environments",
+		"Raise vus to the number of concurrent clients to simulate: each VU holds one stream or poll open",
+	)
+
+	logger.InfoContext(ctx, "Streaming script generated",
+		slog.String("mode", opts.Mode),
+		slog.Int("events", opts.Events),
+		slog.Int("script_size", len(script)))
+
+	return marshalResponse(ctx, logger, resp)
+}
+
+// streamOptions reads and checks the parameters of request.
+func streamOptions(request mcp.CallToolRequest) (streamScriptOptions, []string, error) {
+	opts := streamScriptOptions{
+		URL:           strings.TrimSpace(request.GetString("url", "")),
+		Mode:          request.GetString("mode", streamSSE),
+		Events:        request.GetInt("events", defaultStreamEvents),
+		MaxReconnects: request.GetInt("max_reconnects", defaultStreamReconnects),
+		Retry:         request.GetFloat("retry", 1),
+		PollTimeout:   strings.TrimSpace(request.GetString("poll_timeout", defaultPollTimeout)),
+		CursorParam:   strings.TrimSpace(request.GetString("cursor_param", "")),
+		FirstEvent:    strings.TrimSpace(request.GetString("first_event_threshold", defaultFirstEventLatency)),
+	}
+	headers, err := stringMapArgument(request, "headers")
+	if err != nil {
+		return opts, nil, err
+	}
+	opts.Headers = headers
+	if opts.Headers == nil {
+		opts.Headers = map[string]string{}
+	}
+
+	if u, err := url.ParseRequestURI(opts.URL); err != nil || u.Host == "" ||
+		(u.Scheme != "http" && u.Scheme != "https") {
+		return opts, nil, fmt.Errorf("invalid url %q: expected an absolute http or https URL", opts.URL)
+	}
+	switch {
+	case opts.Mode != streamSSE && opts.Mode != streamLongPoll:
+		return opts, nil, fmt.Errorf("invalid mode %q: use 'sse' or 'long_poll'", opts.Mode)
+	case opts.Events < 1 || opts.Events > maxStreamEvents:
+		return opts, nil, fmt.Errorf("events must be between 1 and %d", maxStreamEvents)
+	case opts.MaxReconnects < 0 || opts.MaxReconnects > maxStreamReconnects:
+		return opts, nil, fmt.Errorf("max_reconnects must be between 0 and %d", maxStreamReconnects)
+	case opts.Retry < 0:
+		return opts, nil, errors.New("retry must not be negative")
+	case opts.FirstEvent == "":
+		return opts, nil, errors.New("first_event_threshold is empty")
+	case opts.CursorParam != "" && !queryParam.MatchString(opts.CursorParam):
+		return opts, nil, fmt.Errorf("invalid cursor_param %q", opts.CursorParam)
+	}
+	timeout, err := time.ParseDuration(opts.PollTimeout)
+	if err != nil || timeout <= 0 {
+		return opts, nil, fmt.Errorf("invalid poll_timeout %q: expected a duration such as 60s", opts.PollTimeout)
+	}
+	opts.MaxWait, err = time.ParseDuration(request.GetString("max_wait", defaultPollMaxWait))
+	if err != nil || opts.MaxWait <= 0 {
+		return opts, nil, errors.New("invalid max_wait: expected a duration such as 2m")
+	}
+
+	var warnings []string
+	if opts.Mode == streamSSE {
+		for _, param := range []string{"poll_timeout", "max_wait", "cursor_param"} {
+			if _, ok := request.GetArguments()[param]; ok {
+				warnings = append(warnings, param+" is ignored: it only applies to long_poll")
+			}
+		}
+	}
+	for name := range opts.Headers {
+		if strings.EqualFold(name, "Authorization") || strings.EqualFold(name, "Cookie") {
+			warnings = append(warnings, fmt.Sprintf("The %s header is written in the script: "+
+				"read it from __ENV or a secret before sharing the script", name))
+		}
+	}
+	return opts, warnings, nil
+}
+
+// streamMetricPrefix returns the prefix of the custom metrics of mode.
+func streamMetricPrefix(mode string) string {
+	if mode == streamLongPoll {
+		return "long_poll_"
+	}
+	return "sse_"
+}
+
+// streamScript writes the script of opts.
+func streamScript(opts streamScriptOptions) string {
+	prefix := streamMetricPrefix(opts.Mode)
+
+	w := &codeWriter{}
+	if opts.Mode == streamSSE {
+		w.line("// Generated by mcp-k6 for a Server-Sent Events endpoint. Run it with a k6 binary built with xk6-sse:")
+		w.line("//   " + xk6BuildCommand([]string{extensionModule(sseModule)}))
+		w.line("import sse from 'k6/x/sse';")
+	} else {
+		w.line("// Generated by mcp-k6 for a long-polling endpoint. Review before running with load.")
+		w.line("import http from 'k6/http';")
+	}
+	w.line("import { check, sleep } from 'k6';")
+	w.line("import { Counter, Trend } from 'k6/metrics';")
+	w.line("")
+
+	w.open("export const options = {")
+	w.line("vus: 1,")
+	w.line("iterations: 1,")
+	w.open("thresholds: {")
+	w.line(fmt.Sprintf("%stime_to_first_event: [%s],", prefix, jsString(opts.FirstEvent)))
+	w.line("checks: ['rate>0.99'],")
+	w.close("},")
+	w.close("};")
+	w.line("")
+
+	w.line(fmt.Sprintf("const URL = __ENV.STREAM_URL || %s;", jsString(opts.URL)))
+	if opts.Mode == streamSSE {
+		w.line("// EVENTS is the number of events each iteration waits for.")
+	} else {
+		w.line("// EVENTS is the number of polls returning events each iteration waits for.")
+	}
+	w.line(fmt.Sprintf("const EVENTS = %d;", opts.Events))
+	w.line("// MAX_RECONNECTS bounds the reconnections of an iteration, RETRY seconds apart.")
+	w.line(fmt.Sprintf("const MAX_RECONNECTS = %d;", opts.MaxReconnects))
+	w.line(fmt.Sprintf("const RETRY = %v;", opts.Retry))
+	if opts.Mode == streamLongPoll {
+		w.line("// MAX_WAIT bounds the time an iteration polls, in milliseconds.")
+		w.line(fmt.Sprintf("const MAX_WAIT = %d;", opts.MaxWait.Milliseconds()))
+	}
+	w.line(fmt.Sprintf("const headers = %s;", jsValue(opts.Headers, "")))
+	w.line("")
+
+	w.line(fmt.Sprintf("const timeToFirstEvent = new Trend('%stime_to_first_event', true);", prefix))
+	w.line(fmt.Sprintf("const events = new Counter('%sevents');", prefix))
+	w.line(fmt.Sprintf("const reconnects = new Counter('%sreconnects');", prefix))
+	if opts.Mode == streamSSE {
+		w.line("const errors = new Counter('sse_errors');")
+		w.line("")
+		sseDefault(w)
+	} else {
+		w.line("const emptyPolls = new Counter('long_poll_empty');")
+		w.line("")
+		w.line("// The timeout is longer than the time the server holds a poll open.")
+		w.line(fmt.Sprintf("const params = { headers, timeout: %s, tags: { name: 'long_poll' } };",
+			jsString(opts.PollTimeout)))
+		w.line("")
+		longPollDefault(w, opts.CursorParam)
+	}
+	return w.String()
+}
+
+// sseDefault writes the default function of an SSE script.
+func sseDefault(w *codeWriter) {
+	w.open("export default function () {")
+	w.line("let lastEventId = '';")
+	w.line("let received = 0;")
+	w.open("for (let attempt = 0; attempt <= MAX_RECONNECTS && received < EVENTS; attempt++) {")
+	w.open("if (attempt > 0) {")
+	w.line("// The stream dropped: reconnect like a browser, resuming after the last event.")
+	w.line("reconnects.add(1);")
+	w.line("sleep(RETRY);")
+	w.close("}")
+	w.line("const params = { headers: { ...headers, Accept: 'text/event-stream' }, tags: { name: 'sse' } };")
+	w.open("if (lastEventId !== '') {")
+	w.line("params.headers['Last-Event-ID'] = lastEventId;")
+	w.close("}")
+	w.line("const start = Date.now();")
+	w.line("let first = true;")
+	w.open("const res = sse.open(URL, params, (client) => {")
+	w.open("client.on('event', (event) => {")
+	w.open("if (first) {")
+	w.line("timeToFirstEvent.add(Date.now() - start, { reconnect: String(attempt > 0) });")
+	w.line("first = false;")
+	w.close("}")
+	w.open("if (event.id) {")
+	w.line("lastEventId = event.id;")
+	w.close("}")
+	w.line("events.add(1);")
+	w.open("if (++received >= EVENTS) {")
+	w.line("client.close();")
+	w.close("}")
+	w.close("});")
+	w.open("client.on('error', (e) => {")
+	w.line("errors.add(1);")
+	w.line("console.error(`stream error: ${e.error()}`);")
+	w.close("});")
+	w.close("});")
+	w.line("check(res, { 'stream status is 200': (r) => r && r.status === 200 });")
+	w.close("}")
+	w.line("check(received, { 'all events are received': (n) => n >= EVENTS });")
+	w.close("}")
+}
+
+// longPollDefault writes the default function of a long-polling script, and
+// its helpers passing the position of the last event in cursorParam.
+func longPollDefault(w *codeWriter, cursorParam string) {
+	w.open("export default function () {")
+	w.line("const start = Date.now();")
+	if cursorParam != "" {
+		w.line("let cursor = '';")
+	}
+	w.line("let received = 0;")
+	w.line("let failures = 0;")
+	w.open("while (received < EVENTS && failures <= MAX_RECONNECTS && Date.now() - start < MAX_WAIT) {")
+	if cursorParam != "" {
+		w.line("const res = http.get(pollURL(cursor), params);")
+	} else {
+		w.line("const res = http.get(URL, params);")
+	}
+	w.open("if (res.status === 204 || res.status === 304) {")
+	w.line("// The server held the poll open without events: poll again.")
+	w.line("emptyPolls.add(1);")
+	w.line("continue;")
+	w.close("}")
+	w.open("if (res.status !== 200) {")
+	w.line("// The poll dropped or failed: reconnect after RETRY seconds.")
+	w.line("failures++;")
+	w.line("reconnects.add(1);")
+	w.line("sleep(RETRY);")
+	w.line("continue;")
+	w.close("}")
+	w.open("if (received === 0) {")
+	w.line("timeToFirstEvent.add(Date.now() - start);")
+	w.close("}")
+	w.line("events.add(1);")
+	w.line("received++;")
+	if cursorParam != "" {
+		w.line("cursor = nextCursor(res, cursor);")
+	}
+	w.close("}")
+	w.line("check(received, { 'all events are received': (n) => n >= EVENTS });")
+	w.close("}")
+
+	if cursorParam == "" {
+		return
+	}
+	w.line("")
+	w.line("// pollURL returns the URL of the poll following the event at cursor.")
+	w.open("function pollURL(cursor) {")
+	w.open("if (cursor === '') {")
+	w.line("return URL;")
+	w.close("}")
+	w.line(fmt.Sprintf("return `${URL}${URL.includes('?') ? '&' : '?'}%s=${encodeURIComponent(cursor)}`;",
+		cursorParam))
+	w.close("}")
+	w.line("")
+	w.line("// nextCursor returns the position of the last event of res. Adjust it when the")
+	w.line("// API returns the position in the body, e.g. res.json('cursor').")
+	w.open("function nextCursor(res, cursor) {")
+	w.line("return res.headers['Etag'] || res.headers['Last-Event-Id'] || cursor;")
+	w.close("}")
+}
@@ -0,0 +1,92 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateStreamingScriptSSE(t *testing.T) {
+	t.Parallel()
+
+	result, err := generateStreamingScript(t.Context(), newCallRequest(map[string]any{
+		"url":     "https://api.example.com/events",
+		"events":  5,
+		"headers": map[string]any{"Authorization": "Bearer token"},
+	}))
+	require.NoError(t, err)
+	require.False(t, result.IsError, "unexpected tool error: %+v", result.Content)
+	var resp generateStreamingScriptResponse
+	decodeJSON(t, result, &resp)
+
+	assert.Equal(t, "github.com/phymbert/xk6-sse", resp.Extension)
+	assert.Equal(t, "xk6 build --with github.com/phymbert/xk6-sse", resp.BuildCommand)
+	assert.Equal(t, []string{"sse_time_to_first_event", "sse_events", "sse_reconnects", "sse_errors"}, resp.Metrics)
+	require.Len(t, resp.Warnings, 1)
+	assert.Contains(t, resp.Warnings[0], "Authorization")
+
+	assert.Contains(t, resp.Script, "import sse from 'k6/x/sse';")
+	assert.Contains(t, resp.Script, `const URL = __ENV.STREAM_URL || "https://api.example.com/events";`)
+	assert.Contains(t, resp.Script, "const EVENTS = 5;")
+	assert.Contains(t, resp.Script, `sse_time_to_first_event: ["p(95)<1000"],`)
+	assert.Contains(t, resp.Script, "params.headers['Last-Event-ID'] = lastEventId;")
+	assert.Contains(t, resp.Script, "const res = sse.open(URL, params, (client) => {")
+	assert.Contains(t, resp.Script, `"Authorization": "Bearer token"`)
+}
+
+func TestGenerateStreamingScriptLongPoll(t *testing.T) {
+	t.Parallel()
+
+	result, err := generateStreamingScript(t.Context(), newCallRequest(map[string]any{
+		"url":          "https://api.example.com/poll?channel=orders",
+		"mode":         "long_poll",
+		"poll_timeout": "45s",
+		"cursor_param": "since",
+	}))
+	require.NoError(t, err)
+	require.False(t, result.IsError, "unexpected tool error: %+v", result.Content)
+	var resp generateStreamingScriptResponse
+	decodeJSON(t, result, &resp)
+
+	assert.Empty(t, resp.Extension)
+	assert.Nil(t, resp.K6HasExtension)
+	assert.Contains(t, resp.Metrics, "long_poll_empty")
+	assert.Contains(t, resp.Script, "import http from 'k6/http';")
+	assert.NotContains(t, resp.Script, "k6/x/sse")
+	assert.Contains(t, resp.Script, "const MAX_WAIT = 120000;")
+	assert.Contains(t, resp.Script, `const params = { headers, timeout: "45s", tags: { name: 'long_poll' } };`)
+	assert.Contains(t, resp.Script, "const headers = {};")
+	assert.Contains(t, resp.Script, "const res = http.get(pollURL(cursor), params);")
+	assert.Contains(t, resp.Script, "since=${encodeURIComponent(cursor)}")
+	assert.Contains(t, resp.Script, "if (res.status === 204 || res.status === 304) {")
+
+	plain := streamScript(streamScriptOptions{
+		URL: "https://api.example.com/poll", Mode: streamLongPoll, Events: 1, PollTimeout: "60s",
+		FirstEvent: defaultFirstEventLatency, Headers: map[string]string{},
+	})
+	assert.Contains(t, plain, "const res = http.get(URL, params);")
+	assert.NotContains(t, plain, "cursor")
+}
+
+func TestGenerateStreamingScriptErrors(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		args map[string]any
+		want string
+	}{
+		{map[string]any{"url": "ws://api.example.com/events"}, "invalid url"},
+		{map[string]any{"url": "https://api.example.com", "mode": "websocket"}, `invalid mode "websocket"`},
+		{map[string]any{"url": "https://api.example.com", "events": 0}, "events must be between"},
+		{map[string]any{"url": "https://api.example.com", "poll_timeout": "soon"}, "invalid poll_timeout"},
+		{map[string]any{"url": "https://api.example.com", "cursor_param": "a b"}, "invalid cursor_param"},
+		{map[string]any{"url": "https://api.example.com", "headers": map[string]any{"X": []any{}}}, "'headers.X'"},
+	} {
+		result, err := generateStreamingScript(t.Context(), newCallRequest(tc.args))
+		require.NoError(t, err)
+		require.True(t, result.IsError, tc.want)
+		assert.Contains(t, result.Content[0].(mcp.TextContent).Text, tc.want)
+	}
+}
@@ -0,0 +1,114 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/grafana/mcp-k6/internal/logging"
+	"github.com/grafana/mcp-k6/internal/summary"
+)
+
+// summaryExportPlaceholder stands for the summary file in run plans.
+const summaryExportPlaceholder = "<summary-export>.json"
+
+// summaryExportSource defines mcpExportSummary, which renders the summary in
+// the schema of --summary-export with the type and contents of each metric,
+// so summary.ReadExport reads it whatever the k6 version.
+const summaryExportSource = `import { textSummary as mcpTextSummary }
+  from 'https://jslib.k6.io/k6-summary/0.1.0/index.js';
+function mcpExportSummary(data) {
+  const metrics = {};
+  for (const [name, metric] of Object.entries(data.metrics)) {
+    const values = Object.assign({}, metric.values);
+    if (metric.type === 'rate') {
+      values.value = values.rate;
+      delete values.rate;
+    }
+    values.type = metric.type;
+    values.contains = metric.contains;
+    if (metric.thresholds) {
+      values.thresholds = {};
+      for (const [expression, result] of Object.entries(metric.thresholds)) {
+        values.thresholds[expression] = !result.ok;
+      }
+    }
+    metrics[name] = values;
+  }
+  return JSON.stringify({ root_group: data.root_group, metrics, setup_data: data.setup_data });
+}
+`
+
+// summaryHandlerSource is the handleSummary an entry module adds with
+// summary_handler: it writes the summary export to summaryExport. The
+// script's own handleSummary still runs; without one, the text summary is
+// printed with the k6-summary jslib.
+const summaryHandlerSource = summaryExportSource + `export function handleSummary(data) {
+  const outputs = typeof script.handleSummary === 'function'
+    ? Object.assign({}, script.handleSummary(data))
+    : { stdout: mcpTextSummary(data, { indent: ' ', enableColors: false }) };
+  outputs[summaryExport] = mcpExportSummary(data);
+  return outputs;
+}
+`
+
+// readSummaryExport returns the summary k6 exported to path. k6 exports no
+// summary when it fails to start the test, which leaves the result without
+// network timings and custom metrics.
+func readSummaryExport(ctx context.Context, path string) *summary.Export {
+	logger := logging.LoggerFromContext(ctx)
+
+	//nolint:forbidigo // Reading the summary k6 has just exported
+	f, err := os.Open(path)
+	if err != nil {
+		logger.WarnContext(ctx, "Failed to open summary export", slog.String("error", err.Error()))
+		return nil
+	}
+	defer func() { _ = f.Close() }()
+
+	export, err := summary.ReadExport(f)
+	if err != nil {
+		logger.DebugContext(ctx, "No summary exported", slog.String("error", err.Error()))
+		return nil
+	}
+	return export
+}
+
+// networkNextSteps points at the phase the HTTP requests of a run spent the
+// most time in.
+func networkNextSteps(network *summary.Network) []string {
+	if network == nil {
+		return nil
+	}
+	switch network.Bottleneck {
+	case summary.BottleneckConnection:
+		return []string{fmt.Sprintf(
+			"Getting connections takes longer than the server to respond (blocked %.2fms, connecting %.2fms, "+
+				"tls_handshaking %.2fms vs waiting %.2fms on average): check DNS, the network path and connection "+
+				"reuse (noConnectionReuse, noVUConnectionReuse)",
+			network.Blocked.Avg, network.Connecting.Avg, network.TLSHandshaking.Avg, network.Waiting.Avg)}
+	case summary.BottleneckTransfer:
+		return []string{fmt.Sprintf(
+			"Sending and receiving take longer than the server to respond (%.2fms vs waiting %.2fms on average): "+
+				"check the size of request and response bodies and the bandwidth to the target",
+			network.Sending.Avg+network.Receiving.Avg, network.Waiting.Avg)}
+	default:
+		return nil
+	}
+}
+
+// summaryNextSteps points runs whose legacy summary only marks the metrics
+// with crossed thresholds at summary_handler, which reports each expression.
+func summaryNextSteps(s *summary.Summary, options *RunOptions) []string {
+	if s.Format != summary.FormatLegacy || (options != nil && options.SummaryHandler) {
+		return nil
+	}
+	for _, th := range s.Thresholds {
+		if !th.Passed {
+			return []string{"The legacy summary of this k6 only marks the metrics whose thresholds were crossed; " +
+				"rerun with summary_handler to get each expression with its observed value"}
+		}
+	}
+	return nil
+}
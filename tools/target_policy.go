@@ -0,0 +1,94 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/grafana/mcp-k6/internal/scriptinfo"
+	"github.com/grafana/mcp-k6/internal/targetpolicy"
+	"github.com/grafana/mcp-k6/internal/workspace"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// TargetViolation is a request target the target policy rejects.
+type TargetViolation struct {
+	URL string `json:"url"`
+	// Host is empty when it cannot be read statically.
+	Host string `json:"host,omitempty"`
+	// File is the local module the request appears in, empty for the script.
+	File   string `json:"file,omitempty"`
+	Line   int    `json:"line"`
+	Reason string `json:"reason"`
+}
+
+// TargetPolicyError is returned for a script sending requests to hosts the
+// target policy rejects.
+type TargetPolicyError struct {
+	Policy     string            `json:"policy"`
+	Violations []TargetViolation `json:"violations"`
+}
+
+func (e *TargetPolicyError) Error() string {
+	msgs := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		where := fmt.Sprintf("line %d", v.Line)
+		if v.File != "" {
+			where = fmt.Sprintf("%s:%d", v.File, v.Line)
+		}
+		msgs = append(msgs, fmt.Sprintf("%s (%s): %s", v.URL, where, v.Reason))
+	}
+	return fmt.Sprintf("target policy: %s; %s", e.Policy, strings.Join(msgs, "; "))
+}
+
+// checkTargetPolicy returns a *TargetPolicyError listing the requests of the
+// script, or of the local modules it imports, that policy rejects. The
+// hosts of URLs built from __ENV variables are read from env.
+func checkTargetPolicy(
+	ctx context.Context,
+	ws *workspace.Workspace,
+	policy *targetpolicy.Policy,
+	script, scriptPath string,
+	files []DataFile,
+	env map[string]string,
+) error {
+	if !policy.Restricted() {
+		return nil
+	}
+	var violations []TargetViolation
+	walkModules(ctx, ws, script, scriptPath, files, func(file string, info *scriptinfo.Info) {
+		for _, e := range info.Endpoints {
+			host, _ := targetpolicy.Host(e.URL, e.Dynamic, env)
+			if err := policy.Check(host); err != nil {
+				violations = append(violations, TargetViolation{
+					URL: e.URL, Host: host, File: file, Line: e.Line, Reason: err.Error(),
+				})
+			}
+		}
+	})
+	if len(violations) == 0 {
+		return nil
+	}
+	return &TargetPolicyError{Policy: policy.String(), Violations: violations}
+}
+
+// requestError returns the error result of a request rejected before
+// running k6. Target policy errors are returned as JSON, listing the
+// offending URLs.
+func requestError(err error) *mcp.CallToolResult {
+	var policyErr *TargetPolicyError
+	if !errors.As(err, &policyErr) {
+		return mcp.NewToolResultError(err.Error())
+	}
+	data, jsonErr := json.MarshalIndent(map[string]any{
+		"error":      "the script sends requests to hosts the target policy rejects",
+		"policy":     policyErr.Policy,
+		"violations": policyErr.Violations,
+	}, "", "  ")
+	if jsonErr != nil {
+		return mcp.NewToolResultError(err.Error())
+	}
+	return mcp.NewToolResultError(string(data))
+}
@@ -0,0 +1,108 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/grafana/mcp-k6/internal/targetpolicy"
+	"github.com/grafana/mcp-k6/internal/workspace"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckTargetPolicy(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "lib"), 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "lib", "api.js"), []byte(`import http from 'k6/http';
+export const login = () => http.post('https://auth.prod.example.com/login');
+`), 0o600))
+	scriptPath := filepath.Join(root, "test.js")
+	script := `import http from 'k6/http';
+import { login } from './lib/api.js';
+export default function () {
+  http.get('https://api.staging.example.com/health');
+  http.get(` + "`${__ENV.BASE_URL}/users`" + `);
+  login();
+}
+`
+	ws := workspace.New(nil, root)
+	env := map[string]string{"BASE_URL": "https://api.prod.example.com"}
+
+	deny, err := targetpolicy.New(nil, []string{"*.prod.example.com"})
+	require.NoError(t, err)
+	err = checkTargetPolicy(context.Background(), ws, deny, script, scriptPath, nil, env)
+	var policyErr *TargetPolicyError
+	require.True(t, errors.As(err, &policyErr))
+	require.Len(t, policyErr.Violations, 2)
+	assert.Equal(t, "api.prod.example.com", policyErr.Violations[0].Host)
+	assert.Equal(t, 5, policyErr.Violations[0].Line)
+	assert.Equal(t, "https://auth.prod.example.com/login", policyErr.Violations[1].URL)
+	assert.Equal(t, "lib/api.js", policyErr.Violations[1].File)
+	assert.Contains(t, err.Error(), "targets *.prod.example.com are denied")
+
+	// Without the env file, the host of the dynamic URL is unknown, which
+	// only an allow list rejects
+	err = checkTargetPolicy(context.Background(), ws, deny, script, scriptPath, nil, nil)
+	require.True(t, errors.As(err, &policyErr))
+	require.Len(t, policyErr.Violations, 1)
+
+	allow, err := targetpolicy.New([]string{"*.staging.example.com"}, nil)
+	require.NoError(t, err)
+	err = checkTargetPolicy(context.Background(), ws, allow, script, scriptPath, nil, nil)
+	require.True(t, errors.As(err, &policyErr))
+	require.Len(t, policyErr.Violations, 2)
+	assert.Empty(t, policyErr.Violations[0].Host)
+	assert.Contains(t, policyErr.Violations[0].Reason, "cannot be read statically")
+
+	require.NoError(t, checkTargetPolicy(context.Background(), ws, nil, script, scriptPath, nil, env))
+}
+
+func TestRunRequestEnforcesTargetPolicy(t *testing.T) {
+	t.Parallel()
+
+	deny, err := targetpolicy.New(nil, []string{"*.prod.example.com"})
+	require.NoError(t, err)
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]any{
+		"script": "import http from 'k6/http';\nexport default () => http.get('https://shop.prod.example.com/');\n",
+	}
+	_, _, err = runRequest(context.Background(), nil, nil, nil, deny, nil, req)
+	require.Error(t, err)
+
+	result := requestError(err)
+	require.True(t, result.IsError)
+	var body struct {
+		Policy     string            `json:"policy"`
+		Violations []TargetViolation `json:"violations"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &body))
+	assert.Equal(t, "targets *.prod.example.com are denied", body.Policy)
+	require.Len(t, body.Violations, 1)
+	assert.Equal(t, "https://shop.prod.example.com/", body.Violations[0].URL)
+	assert.Equal(t, 2, body.Violations[0].Line)
+
+	assert.Equal(t, "boom", requestError(errors.New("boom")).Content[0].(mcp.TextContent).Text)
+}
+
+func TestTargetPolicyResponse(t *testing.T) {
+	t.Parallel()
+
+	resp := targetPolicyResponse(&TargetPolicyError{
+		Policy: "targets *.prod.example.com are denied",
+		Violations: []TargetViolation{
+			{URL: "https://auth.prod.example.com/login", File: "lib/api.js", Line: 2, Reason: "denied"},
+		},
+	})
+	assert.False(t, resp.Valid)
+	assert.False(t, resp.Summary.ReadyToRun)
+	require.Len(t, resp.Issues, 1)
+	assert.Equal(t, "target", resp.Issues[0].Type)
+	assert.Contains(t, resp.Issues[0].Message, "in lib/api.js")
+}
@@ -0,0 +1,39 @@
+package tools
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/mcp-k6/internal/telemetry"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// RecordTelemetry returns a middleware that counts every tool call in rec,
+// and passes rec on in the context so the runs a call starts are counted
+// too, including the background and scheduled runs that outlive it.
+func RecordTelemetry(rec *telemetry.Recorder) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		if rec == nil {
+			return next
+		}
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			start := time.Now()
+			result, err := next(telemetry.ContextWithRecorder(ctx, rec), request)
+			rec.ToolCall(request.Params.Name, err != nil || (result != nil && result.IsError), time.Since(start))
+			return result, err
+		}
+	}
+}
+
+// runOutcome classifies a run for telemetry.
+func runOutcome(result *RunResult) string {
+	switch {
+	case result.Success:
+		return "passed"
+	case result.ExitCode == ThresholdsExitCode:
+		return "thresholds_failed"
+	default:
+		return "error"
+	}
+}
@@ -0,0 +1,60 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grafana/mcp-k6/internal/telemetry"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordTelemetry(t *testing.T) {
+	t.Parallel()
+
+	body := make(chan string, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		body <- string(data)
+	}))
+	t.Cleanup(srv.Close)
+	rec, err := telemetry.New([]string{telemetry.EnvURL + "=" + srv.URL, telemetry.EnvInstance + "=test"}, "dev")
+	require.NoError(t, err)
+
+	var seen *telemetry.Recorder
+	handler := RecordTelemetry(rec)(func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		seen = telemetry.FromContext(ctx)
+		switch req.Params.Name {
+		case "get_run":
+			return mcp.NewToolResultError("unknown run_id"), nil
+		case "info":
+			return nil, errors.New("boom")
+		}
+		return mcp.NewToolResultText("ok"), nil
+	})
+	for _, name := range []string{"run_script", "get_run", "info"} {
+		req := mcp.CallToolRequest{}
+		req.Params.Name = name
+		_, _ = handler(context.Background(), req)
+	}
+	assert.Same(t, rec, seen)
+
+	require.NoError(t, rec.Push(context.Background()))
+	lines := <-body
+	assert.Contains(t, lines, "outcome=ok,tool=run_script,version=dev calls_total=1")
+	assert.Contains(t, lines, "outcome=error,tool=get_run,version=dev calls_total=1")
+	assert.Contains(t, lines, "outcome=error,tool=info,version=dev calls_total=1")
+}
+
+func TestRunOutcome(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "passed", runOutcome(&RunResult{Success: true}))
+	assert.Equal(t, "thresholds_failed", runOutcome(&RunResult{ExitCode: ThresholdsExitCode}))
+	assert.Equal(t, "error", runOutcome(&RunResult{ExitCode: 107}))
+}
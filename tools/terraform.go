@@ -3,11 +3,14 @@ package tools
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os/exec"
 	"strings"
+	"time"
 
+	"github.com/grafana/mcp-k6/internal/audit"
 	"github.com/grafana/mcp-k6/internal/logging"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
@@ -20,6 +23,7 @@ const grafanaProviderKey = "registry.terraform.io/grafana/grafana"
 //nolint:gochecknoglobals // Shared tool definition registered at startup.
 var SearchTerraformTool = mcp.NewTool(
 	"search_terraform",
+	readOnlyTool(),
 	mcp.WithDescription(
 		"Search for k6 Cloud-related resources in the Grafana Terraform provider. "+
 			"Queries the installed provider schema and filters resources by name.",
@@ -108,7 +112,12 @@ func runTerraformSchema(ctx context.Context, logger *slog.Logger, tfPath, root s
 	cmd := exec.CommandContext(ctx, tfPath, "providers", "schema", "-json")
 	cmd.Dir = root
 
+	start := time.Now()
 	output, err := cmd.CombinedOutput()
+	audit.Command(ctx, cmd, start, err)
+	if errors.Is(ctx.Err(), context.Canceled) {
+		return nil, fmt.Errorf("terraform cancelled by the client: %w", ctx.Err())
+	}
 	if err != nil {
 		outputStr := strings.TrimSpace(string(output))
 		logger.ErrorContext(ctx, "Failed to run terraform command",
@@ -0,0 +1,238 @@
+package tools
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/grafana/mcp-k6/internal/loadprofile"
+	"github.com/grafana/mcp-k6/internal/scriptinfo"
+	"github.com/grafana/mcp-k6/internal/summary"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// ThresholdsExitCode is the exit code k6 uses when thresholds fail,
+// including when an abortOnFail threshold stops the test.
+const ThresholdsExitCode = 99
+
+// entryModulePlaceholder stands in for the generated entry module in plans.
+const entryModulePlaceholder = "<entry-module>.js"
+
+// EarlyExit reports a test that an abortOnFail threshold stopped.
+type EarlyExit struct {
+	Reason string `json:"reason"`
+	// Metrics are the metrics whose thresholds were crossed.
+	Metrics []string `json:"metrics"`
+	// Thresholds are the failed expressions from the end-of-test summary.
+	Thresholds []summary.Threshold `json:"thresholds,omitempty"`
+	// Elapsed is the test time at which k6 stopped.
+	Elapsed string `json:"elapsed,omitempty"`
+}
+
+// thresholdsArgument reads the thresholds parameter: a map of metric names
+// to one expression or a list of them.
+func thresholdsArgument(request mcp.CallToolRequest) (map[string][]string, error) {
+	raw, ok := request.GetArguments()["thresholds"]
+	if !ok || raw == nil {
+		return nil, nil
+	}
+	obj, ok := raw.(map[string]any)
+	if !ok {
+		return nil, errors.New("'thresholds' must be an object mapping metrics to expressions")
+	}
+	thresholds := make(map[string][]string, len(obj))
+	for metric, v := range obj {
+		switch t := v.(type) {
+		case string:
+			thresholds[metric] = []string{t}
+		case []any:
+			for _, item := range t {
+				expr, ok := item.(string)
+				if !ok {
+					return nil, fmt.Errorf("'thresholds.%s' must hold expression strings", metric)
+				}
+				thresholds[metric] = append(thresholds[metric], expr)
+			}
+		default:
+			return nil, fmt.Errorf("'thresholds.%s' must be an expression or a list of expressions", metric)
+		}
+	}
+	return thresholds, nil
+}
+
+// validateThresholdOptions validates the threshold parameters of a run.
+func validateThresholdOptions(options *RunOptions) error {
+	for metric, exprs := range options.Thresholds {
+		if strings.TrimSpace(metric) == "" {
+			return &RunError{Type: "PARAMETER_VALIDATION", Message: "thresholds cannot have an empty metric name"}
+		}
+		if len(exprs) == 0 {
+			return &RunError{
+				Type:    "PARAMETER_VALIDATION",
+				Message: fmt.Sprintf("thresholds.%s needs at least one expression", metric),
+			}
+		}
+		for _, expr := range exprs {
+			if strings.TrimSpace(expr) == "" {
+				return &RunError{
+					Type:    "PARAMETER_VALIDATION",
+					Message: fmt.Sprintf("thresholds.%s has an empty expression", metric),
+				}
+			}
+		}
+	}
+
+	if options.DelayAbortEval == "" {
+		return nil
+	}
+	if !options.AbortOnFail {
+		return &RunError{Type: "PARAMETER_VALIDATION", Message: "delay_abort_eval requires abort_on_fail"}
+	}
+	if d, err := time.ParseDuration(options.DelayAbortEval); err != nil || d < 0 {
+		return &RunError{
+			Type:    "PARAMETER_VALIDATION",
+			Message: fmt.Sprintf("delay_abort_eval must be a duration like '10s', got %q", options.DelayAbortEval),
+		}
+	}
+	return nil
+}
+
+// needsEntryModule reports whether the run changes the script's thresholds
+// or scenarios, which k6 only reads from the exported options, or its
+// handleSummary.
+func needsEntryModule(options *RunOptions) bool {
+	return options != nil && (len(options.Thresholds) > 0 || options.AbortOnFail || len(options.Scenarios) > 0 ||
+		options.SummaryHandler)
+}
+
+// entryModule returns a k6 entry script that re-exports the script at
+// scriptPath with the run's thresholds merged into its options: thresholds
+// given for a metric replace the script's, and abortOnFail/delayAbortEval
+// are applied to every threshold. Scenarios of the run replace the script's.
+// With summary_handler, its handleSummary also writes the summary export.
+func entryModule(scriptPath, script string, options *RunOptions) (string, error) {
+	target := scriptPath
+	if filepath.IsAbs(scriptPath) {
+		target = (&url.URL{Scheme: "file", Path: filepath.ToSlash(scriptPath)}).String()
+	}
+	quoted, err := marshalJS(target)
+	if err != nil {
+		return "", err
+	}
+	overrides, err := marshalJS(options.Thresholds)
+	if err != nil {
+		return "", err
+	}
+	scenarios := []byte("null")
+	if len(options.Scenarios) > 0 {
+		if scenarios, err = marshalJS(options.Scenarios); err != nil {
+			return "", err
+		}
+	}
+	abort := []byte("null")
+	if options.AbortOnFail {
+		settings := map[string]any{"abortOnFail": true}
+		if options.DelayAbortEval != "" {
+			settings["delayAbortEval"] = options.DelayAbortEval
+		}
+		if abort, err = marshalJS(settings); err != nil {
+			return "", err
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("// Generated by mcp-k6: runs the script with the options of the tool call.\n")
+	fmt.Fprintf(&b, "import * as script from %s;\n", quoted)
+	fmt.Fprintf(&b, "export * from %s;\n", quoted)
+	if hasDefaultExport(script) {
+		fmt.Fprintf(&b, "export { default } from %s;\n", quoted)
+	}
+	fmt.Fprintf(&b, "const overrides = %s;\n", overrides)
+	fmt.Fprintf(&b, "const abort = %s;\n", abort)
+	fmt.Fprintf(&b, "const scenarios = %s;\n", scenarios)
+	b.WriteString(`const thresholds = Object.assign({}, (script.options || {}).thresholds, overrides || {});
+if (abort) {
+  for (const metric of Object.keys(thresholds)) {
+    thresholds[metric] = [].concat(thresholds[metric]).map((t) =>
+      Object.assign(typeof t === 'string' ? { threshold: t } : Object.assign({}, t), abort));
+  }
+}
+export const options = Object.assign({}, script.options, { thresholds }, scenarios ? { scenarios } : {});
+`)
+	if options.SummaryHandler {
+		path := options.SummaryExport
+		if path == "" {
+			path = summaryExportPlaceholder
+		}
+		quotedPath, err := marshalJS(path)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&b, "const summaryExport = %s;\n", quotedPath)
+		b.WriteString(summaryHandlerSource)
+	}
+	return b.String(), nil
+}
+
+// profiledScript returns the script whose options chart the load of the
+// run: a stand-in exporting the run's scenarios when it replaces them.
+func profiledScript(script string, options *RunOptions) string {
+	if options == nil || len(options.Scenarios) == 0 {
+		return script
+	}
+	scenarios, err := marshalJS(map[string]any{"scenarios": options.Scenarios})
+	if err != nil {
+		return script
+	}
+	return "export const options = " + string(scenarios) + ";\n"
+}
+
+// marshalJS encodes v as a JavaScript literal, leaving '<' and '>' of
+// expressions such as "p(95)<500" readable.
+func marshalJS(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+func hasDefaultExport(script string) bool {
+	for _, name := range scriptinfo.Analyze(script).Exports {
+		if name == "default" {
+			return true
+		}
+	}
+	return false
+}
+
+// earlyExit returns the early-exit report of a run an abortOnFail
+// threshold stopped, or nil.
+func earlyExit(result *RunResult) *EarlyExit {
+	if result.ExitCode != ThresholdsExitCode {
+		return nil
+	}
+	output := result.Stdout + "\n" + result.Stderr
+	metrics, aborted := summary.AbortedMetrics(output)
+	if !aborted {
+		return nil
+	}
+
+	exit := &EarlyExit{Reason: "threshold_abort", Metrics: metrics}
+	for _, th := range summary.Thresholds(output) {
+		if !th.Passed {
+			exit.Thresholds = append(exit.Thresholds, th)
+		}
+	}
+	if elapsed, ok := summary.Elapsed(output); ok {
+		exit.Elapsed = loadprofile.FormatDuration(elapsed)
+	}
+	return exit
+}
@@ -0,0 +1,135 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/grafana/mcp-k6/internal/summary"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestThresholdsArgument(t *testing.T) {
+	t.Parallel()
+
+	req := mcp.CallToolRequest{}
+	req.Params.Arguments = map[string]any{"thresholds": map[string]any{
+		"checks":            "rate==1",
+		"http_req_duration": []any{"p(95)<500", "avg<200"},
+	}}
+	thresholds, err := thresholdsArgument(req)
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{
+		"checks":            {"rate==1"},
+		"http_req_duration": {"p(95)<500", "avg<200"},
+	}, thresholds)
+
+	req.Params.Arguments = map[string]any{"thresholds": map[string]any{"checks": 1.0}}
+	_, err = thresholdsArgument(req)
+	require.Error(t, err)
+
+	req.Params.Arguments = map[string]any{"thresholds": "rate==1"}
+	_, err = thresholdsArgument(req)
+	require.Error(t, err)
+}
+
+func TestValidateThresholdOptions(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]*RunOptions{
+		"empty metric":       {Thresholds: map[string][]string{" ": {"rate==1"}}},
+		"no expressions":     {Thresholds: map[string][]string{"checks": {}}},
+		"empty expression":   {Thresholds: map[string][]string{"checks": {""}}},
+		"delay without fail": {DelayAbortEval: "10s"},
+		"bad delay":          {AbortOnFail: true, DelayAbortEval: "soon"},
+	}
+	for name, options := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			assert.Error(t, validateThresholdOptions(options))
+		})
+	}
+
+	assert.NoError(t, validateThresholdOptions(&RunOptions{
+		Thresholds:     map[string][]string{"checks": {"rate==1"}},
+		AbortOnFail:    true,
+		DelayAbortEval: "10s",
+	}))
+}
+
+func TestEntryModule(t *testing.T) {
+	t.Parallel()
+
+	script := `export const options = { thresholds: { checks: ['rate>0.9'] } };
+export function setup() {}
+export default function () {}
+`
+	entry, err := entryModule("/tmp/script.js", script, &RunOptions{
+		Thresholds:     map[string][]string{"http_req_failed": {"rate<0.01"}},
+		AbortOnFail:    true,
+		DelayAbortEval: "10s",
+	})
+	require.NoError(t, err)
+	assert.Contains(t, entry, `import * as script from "file:///tmp/script.js";`)
+	assert.Contains(t, entry, `export * from "file:///tmp/script.js";`)
+	assert.Contains(t, entry, `export { default } from "file:///tmp/script.js";`)
+	assert.Contains(t, entry, `const overrides = {"http_req_failed":["rate<0.01"]};`)
+	assert.Contains(t, entry, `const abort = {"abortOnFail":true,"delayAbortEval":"10s"};`)
+
+	entry, err = entryModule("script.js", "export function scenario() {}", &RunOptions{AbortOnFail: true})
+	require.NoError(t, err)
+	assert.Contains(t, entry, `import * as script from "script.js";`)
+	assert.NotContains(t, entry, "export { default }")
+	assert.Contains(t, entry, `const overrides = null;`)
+	assert.Contains(t, entry, `const scenarios = null;`)
+
+	entry, err = entryModule("script.js", script, &RunOptions{
+		Scenarios: map[string]any{"probe": map[string]any{"executor": "constant-arrival-rate", "rate": 10}},
+	})
+	require.NoError(t, err)
+	assert.Contains(t, entry, `const scenarios = {"probe":{"executor":"constant-arrival-rate","rate":10}};`)
+	assert.Equal(t, []string{"run", "script.js"}, buildK6Args("script.js", &RunOptions{
+		VUs: 5, Scenarios: map[string]any{"probe": map[string]any{}},
+	}))
+
+	entry, err = entryModule("script.js", script, &RunOptions{SummaryHandler: true, SummaryExport: "/tmp/summary.json"})
+	require.NoError(t, err)
+	assert.Contains(t, entry, `const summaryExport = "/tmp/summary.json";`)
+	assert.Contains(t, entry, "export function handleSummary(data)")
+	assert.Contains(t, entry, "https://jslib.k6.io/k6-summary/0.1.0/index.js")
+
+	entry, err = entryModule("script.js", script, &RunOptions{SummaryHandler: true})
+	require.NoError(t, err)
+	assert.Contains(t, entry, `const summaryExport = "`+summaryExportPlaceholder+`";`)
+	assert.NotContains(t, buildK6Args("script.js", &RunOptions{SummaryHandler: true, SummaryExport: "/tmp/s.json"}),
+		"--summary-export")
+}
+
+func TestEarlyExit(t *testing.T) {
+	t.Parallel()
+
+	output := `running (0m12.4s), 10/10 VUs, 130 complete and 10 interrupted iterations
+
+  █ THRESHOLDS
+
+    http_req_duration
+    ✗ 'p(95)<200' p(95)=512.3ms
+    ✓ 'avg<1000' avg=201.2ms
+`
+	stderr := `level=error msg="thresholds on metrics 'http_req_duration' were crossed; ` +
+		`at least one has abortOnFail enabled, stopping test prematurely"`
+
+	exit := earlyExit(&RunResult{ExitCode: ThresholdsExitCode, Stdout: output, Stderr: stderr})
+	require.NotNil(t, exit)
+	assert.Equal(t, &EarlyExit{
+		Reason:  "threshold_abort",
+		Metrics: []string{"http_req_duration"},
+		Thresholds: []summary.Threshold{
+			{Metric: "http_req_duration", Expression: "p(95)<200", Value: "p(95)=512.3ms"},
+		},
+		Elapsed: "12.4s",
+	}, exit)
+
+	assert.Nil(t, earlyExit(&RunResult{ExitCode: ThresholdsExitCode, Stdout: output}))
+	assert.Nil(t, earlyExit(&RunResult{ExitCode: 0, Stdout: output, Stderr: stderr}))
+}
@@ -33,3 +33,32 @@ func withToolLogger(toolName string, handler server.ToolHandlerFunc) server.Tool
 		return handler(ctx, request)
 	}
 }
+
+// readOnlyTool annotates tools that only read and compute: documentation
+// lookups, generators, analyses, and the state of runs, schedules and SLOs.
+func readOnlyTool() mcp.ToolOption {
+	return toolAnnotation(true, false, true, false)
+}
+
+// runTool annotates tools that run k6 scripts, or schedule them: the
+// scripts send whatever requests they make to the systems they target, and
+// each call runs them again.
+func runTool() mcp.ToolOption {
+	return toolAnnotation(false, true, false, true)
+}
+
+// controlTool annotates tools that change the state of the server or of the
+// workspace, such as stopping a run or writing a file, with no further
+// effect when repeated. Destructive ones discard state.
+func controlTool(destructive bool) mcp.ToolOption {
+	return toolAnnotation(false, destructive, true, false)
+}
+
+func toolAnnotation(readOnly, destructive, idempotent, openWorld bool) mcp.ToolOption {
+	return func(t *mcp.Tool) {
+		t.Annotations.ReadOnlyHint = mcp.ToBoolPtr(readOnly)
+		t.Annotations.DestructiveHint = mcp.ToBoolPtr(destructive)
+		t.Annotations.IdempotentHint = mcp.ToBoolPtr(idempotent)
+		t.Annotations.OpenWorldHint = mcp.ToBoolPtr(openWorld)
+	}
+}
@@ -0,0 +1,38 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToolAnnotations(t *testing.T) {
+	t.Parallel()
+
+	type hints struct{ readOnly, destructive, idempotent, openWorld bool }
+	readOnly := hints{readOnly: true, idempotent: true}
+	run := hints{destructive: true, openWorld: true}
+	tests := map[string]struct {
+		tool mcp.Tool
+		want hints
+	}{
+		"docs":      {GetDocumentationTool, readOnly},
+		"generator": {GenerateKafkaScriptTool, readOnly},
+		"terraform": {SearchTerraformTool, readOnly},
+		"plan":      {PlanRunTool, readOnly},
+		"run":       {RunTool, run},
+		"validate":  {ValidateTool, run},
+		"schedule":  {ScheduleRunTool, run},
+		"remote":    {RunRemoteTool, run},
+		"pause":     {PauseRunTool, hints{idempotent: true}},
+		"stop":      {StopRunTool, hints{destructive: true, idempotent: true}},
+		"write":     {WriteScriptTool, hints{destructive: true, idempotent: true}},
+	}
+	for name, tt := range tests {
+		a := tt.tool.Annotations
+		require.NotNil(t, a.ReadOnlyHint, name)
+		assert.Equal(t, tt.want, hints{*a.ReadOnlyHint, *a.DestructiveHint, *a.IdempotentHint, *a.OpenWorldHint}, name)
+	}
+}
@@ -0,0 +1,144 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	"github.com/grafana/mcp-k6/internal/logging"
+	"github.com/grafana/mcp-k6/internal/truncate"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// truncationHints tell how to get what a truncated response left out, by
+// tool. get_documentation continues at an offset instead.
+//
+//nolint:gochecknoglobals // Read-only lookup table.
+var truncationHints = map[string]string{
+	"list_sections": "Lower depth, or pass root_slug to list one branch at a time.",
+	"run_script": "Long k6 output was shortened: use http_debug 'headers' instead of 'full', fewer iterations, " +
+		"or a background run and get_run with 'metrics' to select metrics.",
+	"get_run":         "Pass 'metrics' to select the metrics to return.",
+	"validate_script": "Long k6 output was shortened; fix the first reported issue and validate again.",
+	"list_endpoints":  "Pass a narrower path to scan fewer scripts.",
+	"analyze_script":  "Split the script into modules and analyze them one at a time.",
+	"get_category":    "Lower max_bytes below the response limit, and pass skip to read the next pages.",
+	"search_docs":     "Lower limit, or search more specific words.",
+}
+
+// TruncateResponses returns a middleware cutting the results of tool calls
+// to the response limit of their tool, with a note telling how much was
+// left out and how to get the rest. JSON results keep their shape: their
+// longest strings are shortened, and their structured content with them.
+func TruncateResponses(limits *truncate.Limits) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		if limits == nil {
+			return next
+		}
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			result, err := next(ctx, request)
+			limit := limits.For(request.Params.Name)
+			if err != nil || result == nil || limit == 0 {
+				return result, err
+			}
+			truncateResult(ctx, request, result, limit)
+			return result, nil
+		}
+	}
+}
+
+// truncateResult cuts the text of result to limit bytes.
+func truncateResult(ctx context.Context, request mcp.CallToolRequest, result *mcp.CallToolResult, limit int) {
+	size := 0
+	for _, content := range result.Content {
+		if text, ok := content.(mcp.TextContent); ok {
+			size += len(text.Text)
+		}
+	}
+	if size <= limit {
+		return
+	}
+
+	var cuts []truncate.Cut
+	if text, ok := singleText(result); ok {
+		var v any
+		if json.Unmarshal([]byte(text), &v) == nil {
+			var fits bool
+			v, cuts, fits = truncate.Value(v, limit)
+			if data, err := json.MarshalIndent(v, "", "  "); err == nil {
+				text = string(data)
+			}
+			if result.StructuredContent != nil {
+				result.StructuredContent = v
+			}
+			if !fits {
+				text = truncate.Text(text, limit)
+			}
+		} else {
+			text = truncate.Text(text, limit)
+		}
+		result.Content = []mcp.Content{mcp.NewTextContent(text)}
+	} else {
+		result.Content = truncateContents(result.Content, limit)
+	}
+
+	kept := 0
+	for _, content := range result.Content {
+		if text, ok := content.(mcp.TextContent); ok {
+			kept += len(text.Text)
+		}
+	}
+	logging.LoggerFromContext(ctx).InfoContext(ctx, "Response truncated",
+		slog.Int("size", size), slog.Int("limit", limit))
+	result.Content = append(result.Content, mcp.NewTextContent(fmt.Sprintf(
+		"Response truncated to %d of %d bytes, the response limit of %s. %s",
+		kept, size, request.Params.Name, truncationHint(request, cuts))))
+}
+
+// singleText returns the text of a result made of a single text content.
+func singleText(result *mcp.CallToolResult) (string, bool) {
+	if len(result.Content) != 1 {
+		return "", false
+	}
+	text, ok := result.Content[0].(mcp.TextContent)
+	return text.Text, ok
+}
+
+// truncateContents keeps the text contents in order until limit bytes, and
+// every other content.
+func truncateContents(contents []mcp.Content, limit int) []mcp.Content {
+	out := make([]mcp.Content, 0, len(contents))
+	left := limit
+	for _, content := range contents {
+		text, ok := content.(mcp.TextContent)
+		if !ok {
+			out = append(out, content)
+			continue
+		}
+		if left == 0 {
+			continue
+		}
+		text.Text = truncate.Text(text.Text, left)
+		left -= len(text.Text)
+		out = append(out, text)
+	}
+	return out
+}
+
+// truncationHint tells how to get what the response left out.
+func truncationHint(request mcp.CallToolRequest, cuts []truncate.Cut) string {
+	if request.Params.Name == "get_documentation" {
+		for _, cut := range cuts {
+			if cut.Path == "content" {
+				offset := request.GetInt("offset", 0) + cut.Kept
+				return fmt.Sprintf("Call get_documentation again with offset=%d to read on.", offset)
+			}
+		}
+	}
+	if hint, ok := truncationHints[request.Params.Name]; ok {
+		return hint
+	}
+	return "Narrow the request to get less at once."
+}
@@ -0,0 +1,85 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/grafana/mcp-k6/internal/truncate"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTruncateResponses(t *testing.T) {
+	t.Parallel()
+
+	limits, err := truncate.New(4096, []string{"get_documentation=2048"})
+	require.NoError(t, err)
+	doc := getDocResponse{
+		Section:           responseSection{Slug: "using-k6/scenarios", Title: "Scenarios", Hierarchy: []string{}},
+		Content:           strings.Repeat("Scenarios configure how VUs and iterations are scheduled.\n", 200),
+		Offset:            100,
+		Version:           "v1.4.x",
+		AvailableVersions: []string{"v1.4.x"},
+	}
+	handler := TruncateResponses(limits)(func(ctx context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return structuredResponse(ctx, slog.Default(), doc)
+	})
+	req := mcp.CallToolRequest{}
+	req.Params.Name = "get_documentation"
+	req.Params.Arguments = map[string]any{"slug": "using-k6/scenarios", "offset": 100}
+
+	result, err := handler(context.Background(), req)
+	require.NoError(t, err)
+	require.Len(t, result.Content, 2)
+	text := result.Content[0].(mcp.TextContent).Text
+	assert.LessOrEqual(t, len(text), 2048)
+
+	// The text is still a response of the tool, and the structured content
+	// is shortened with it
+	var got getDocResponse
+	require.NoError(t, json.Unmarshal([]byte(text), &got))
+	assert.Equal(t, "Scenarios", got.Section.Title)
+	assert.Contains(t, got.Content, "bytes truncated]")
+	assert.Equal(t, roundTrip(t, got), result.StructuredContent)
+
+	kept := len(strings.SplitN(got.Content, "… [", 2)[0])
+	note := result.Content[1].(mcp.TextContent).Text
+	assert.Contains(t, note, "the response limit of get_documentation")
+	assert.Contains(t, note, "offset="+strconv.Itoa(100+kept))
+
+	// Results within the limit, or of tools without one, are left alone
+	req.Params.Name = "info"
+	small := TruncateResponses(limits)(func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText(`{"version": "v1.0.0"}`), nil
+	})
+	result, err = small(context.Background(), req)
+	require.NoError(t, err)
+	assert.Len(t, result.Content, 1)
+}
+
+func TestTruncateResponsesText(t *testing.T) {
+	t.Parallel()
+
+	limits, err := truncate.New(1024, nil)
+	require.NoError(t, err)
+	output := strings.Repeat("running (0m01.0s), 10/10 VUs, 42 complete and 0 interrupted iterations\n", 100)
+	handler := TruncateResponses(limits)(func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultError(output), nil
+	})
+	req := mcp.CallToolRequest{}
+	req.Params.Name = "run_script"
+
+	result, err := handler(context.Background(), req)
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	require.Len(t, result.Content, 2)
+	text := result.Content[0].(mcp.TextContent).Text
+	assert.LessOrEqual(t, len(text), 1024)
+	assert.True(t, strings.HasSuffix(text, "iterations"), "cut at a line break")
+	assert.Contains(t, result.Content[1].(mcp.TextContent).Text, "get_run with 'metrics'")
+}
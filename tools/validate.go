@@ -8,11 +8,19 @@ import (
 	"log/slog"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 	"time"
 
+	"github.com/grafana/mcp-k6/internal/audit"
+	"github.com/grafana/mcp-k6/internal/diagnostics"
 	"github.com/grafana/mcp-k6/internal/helpers"
+	"github.com/grafana/mcp-k6/internal/importpolicy"
+	"github.com/grafana/mcp-k6/internal/jslib"
 	"github.com/grafana/mcp-k6/internal/logging"
+	"github.com/grafana/mcp-k6/internal/quickfix"
+	"github.com/grafana/mcp-k6/internal/targetpolicy"
+	"github.com/grafana/mcp-k6/internal/workspace"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 )
@@ -22,58 +30,164 @@ import (
 //nolint:gochecknoglobals // Shared tool definition registered at startup.
 var ValidateTool = mcp.NewTool(
 	"validate_script",
+	runTool(),
 	mcp.WithDescription(
 		"Validate a k6 script by running it with minimal configuration (1 VU, 1 iteration). "+
 			"Returns detailed validation results with syntax errors, runtime issues, "+
-			"and actionable recommendations for fixing problems.",
+			"and actionable recommendations for fixing problems. Extension modules (k6/x/...) the k6 "+
+			"binary lacks are reported without running it, with the xk6 command building one that has them.",
 	),
 	mcp.WithString(
 		"script",
-		mcp.Required(),
 		mcp.Description(
 			"The k6 script content to validate (either JavaScript or TypeScript code). "+
 				"Example: 'import http from \"k6/http\"; export default function() { http.get(\"https://httpbin.org/get\"); }'",
 		),
 	),
+	mcp.WithString(
+		"script_path",
+		mcp.Description(scriptPathDescription),
+	),
+	mcp.WithString(
+		"env_file",
+		mcp.Description(envFileDescription),
+	),
+	mcp.WithString(
+		"remote_imports",
+		mcp.Description(remoteImportsDescription),
+		mcp.Enum(importpolicy.Allow, importpolicy.Deny),
+	),
+	mcp.WithArray(
+		"import_hosts",
+		mcp.Description(importHostsDescription),
+		mcp.WithStringItems(),
+	),
+	mcp.WithNumber(
+		"repair_attempts",
+		mcp.Description(repairAttemptsDescription),
+	),
+	outputSchema(ValidationResponse{}),
 )
 
 // RegisterValidateTool registers the validate tool with the MCP server.
-func RegisterValidateTool(s *server.MCPServer) {
-	s.AddTool(ValidateTool, withToolLogger("validate_script", validate))
+func RegisterValidateTool(
+	s *server.MCPServer,
+	ws *workspace.Workspace,
+	ip *importpolicy.Policy,
+	tp *targetpolicy.Policy,
+	mirror *jslib.Mirror,
+) {
+	s.AddTool(ValidateTool, withToolLogger("validate_script",
+		newValidateHandlerFunc(ws, ip, tp, mirror, clientSampler(s))))
+}
+
+// newValidateHandlerFunc returns an MCP tool handler bound to a workspace.
+func newValidateHandlerFunc(
+	ws *workspace.Workspace,
+	ip *importpolicy.Policy,
+	tp *targetpolicy.Policy,
+	mirror *jslib.Mirror,
+	sample sampleFunc,
+) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return validate(ctx, ws, ip, tp, mirror, sample, request)
+	}
 }
 
-func validate(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	script, err := request.RequireString("script")
+func validate(
+	ctx context.Context,
+	ws *workspace.Workspace,
+	ip *importpolicy.Policy,
+	tp *targetpolicy.Policy,
+	mirror *jslib.Mirror,
+	sample sampleFunc,
+	request mcp.CallToolRequest,
+) (*mcp.CallToolResult, error) {
+	script, scriptPath, err := readScriptArgument(ctx, ws, request)
 	if err != nil {
-		return nil, err
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	env, err := readEnvFileArgument(ctx, ws, request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	policy, err := importPolicyArgument(ip, request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	attempts, err := repairAttemptsArgument(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
 	}
 
-	result, err := validateK6Script(ctx, script)
+	result, err := checkScript(ctx, ws, policy, tp, mirror, script, scriptPath, env)
 	if err != nil {
 		return nil, err
 	}
+	if !result.Valid && attempts > 0 {
+		result = repairScript(ctx, sample, func(ctx context.Context, candidate string) (*ValidationResponse, error) {
+			return checkScript(ctx, ws, policy, tp, mirror, candidate, "", env)
+		}, script, scriptPath, result, attempts)
+	}
 
 	resultJSON, err := json.MarshalIndent(result, "", "  ")
 	if err != nil {
 		return nil, err
 	}
 
-	return mcp.NewToolResultText(string(resultJSON)), nil
+	return mcp.NewToolResultStructured(result, string(resultJSON)), nil
+}
+
+// checkScript validates script, at scriptPath in the workspace or inline:
+// against the import and target policies and the extensions of the k6
+// binary first, then by running it.
+func checkScript(
+	ctx context.Context,
+	ws *workspace.Workspace,
+	policy *importpolicy.Policy,
+	tp *targetpolicy.Policy,
+	mirror *jslib.Mirror,
+	script, scriptPath string,
+	env map[string]string,
+) (*ValidationResponse, error) {
+	var targetErr *TargetPolicyError
+	if violations := importViolations(ctx, ws, policy, script, scriptPath, nil); len(violations) > 0 {
+		return importPolicyResponse(policy, violations), nil
+	} else if errors.As(checkTargetPolicy(ctx, ws, tp, script, scriptPath, nil, env), &targetErr) {
+		return targetPolicyResponse(targetErr), nil
+	} else if missing := missingExtensions(ctx, script); len(missing) > 0 {
+		return missingExtensionsResponse(missing), nil
+	}
+
+	// Inline scripts import jslib from the offline mirror, if any
+	source, missing := script, []string(nil)
+	if scriptPath == "" {
+		source, missing = mirror.Rewrite(script)
+	}
+	result, err := validateK6Script(ctx, source, validateOptions{ScriptPath: scriptPath, Env: env})
+	if err != nil {
+		return nil, err
+	}
+	result.NextSteps = append(result.NextSteps, jslibNextSteps(mirror, script, scriptPath, missing)...)
+	return result, nil
 }
 
 // ValidationResponse contains the result of a k6 script validation.
 type ValidationResponse struct {
-	Valid           bool              `json:"valid"`
-	ExitCode        int               `json:"exit_code"`
-	Stdout          string            `json:"stdout"`
-	Stderr          string            `json:"stderr"`
-	Error           string            `json:"error,omitempty"`
-	Duration        string            `json:"duration"`
-	ScriptURL       string            `json:"script_url,omitempty"`
-	Summary         ValidationSummary `json:"summary"`
-	Issues          []ValidationIssue `json:"issues,omitempty"`
-	Recommendations []string          `json:"recommendations,omitempty"`
-	NextSteps       []string          `json:"next_steps,omitempty"`
+	Valid           bool                     `json:"valid"`
+	ExitCode        int                      `json:"exit_code"`
+	Stdout          string                   `json:"stdout"`
+	Stderr          string                   `json:"stderr"`
+	Error           string                   `json:"error,omitempty"`
+	Duration        string                   `json:"duration"`
+	ScriptURL       string                   `json:"script_url,omitempty"`
+	Summary         ValidationSummary        `json:"summary"`
+	Issues          []ValidationIssue        `json:"issues,omitempty"`
+	Diagnostics     []diagnostics.Diagnostic `json:"diagnostics,omitempty"`
+	Fixes           []quickfix.Fix           `json:"fixes,omitempty"`
+	Recommendations []string                 `json:"recommendations,omitempty"`
+	NextSteps       []string                 `json:"next_steps,omitempty"`
+	Repair          *RepairReport            `json:"repair,omitempty"`
 }
 
 // ValidationSummary provides a high-level overview of the validation results.
@@ -87,13 +201,83 @@ type ValidationSummary struct {
 
 // ValidationIssue represents a specific issue found during validation.
 type ValidationIssue struct {
-	Type       string `json:"type"`                  // "syntax", "import", "function"
+	Type       string `json:"type"`                  // "syntax", "import", "function", "target"
 	Severity   string `json:"severity"`              // "critical", "high", "medium", "low"
 	Message    string `json:"message"`               // Description of the issue
 	Suggestion string `json:"suggestion"`            // Specific fix recommendation
 	LineNumber int    `json:"line_number,omitempty"` // Line where issue occurs (if available)
 }
 
+// importPolicyResponse reports remote imports the import policy rejects,
+// without running k6.
+func importPolicyResponse(policy *importpolicy.Policy, violations []ImportViolation) *ValidationResponse {
+	resp := &ValidationResponse{
+		Valid: false,
+		Error: "the script imports remote modules the import policy rejects: " + policy.String(),
+		Summary: ValidationSummary{
+			Status:      "failed",
+			Description: "Script imports remote modules the import policy rejects",
+			IssueCount:  len(violations),
+			Severity:    "critical",
+			ReadyToRun:  false,
+		},
+		Recommendations: []string{
+			"Vendor the module into the workspace and import it with a relative path",
+			"Import the module from a host the policy allows",
+		},
+		NextSteps: []string{"Replace the rejected imports and validate the script again"},
+	}
+	for _, v := range violations {
+		message := fmt.Sprintf("Remote import %s is not allowed: %s", v.Module, v.Reason)
+		if v.File != "" {
+			message = fmt.Sprintf("Remote import %s in %s is not allowed: %s", v.Module, v.File, v.Reason)
+		}
+		resp.Issues = append(resp.Issues, ValidationIssue{
+			Type:       "import",
+			Severity:   "critical",
+			Message:    message,
+			Suggestion: "Use a local copy of the module or an allowed host",
+			LineNumber: v.Line,
+		})
+	}
+	return resp
+}
+
+// targetPolicyResponse reports requests to hosts the target policy rejects,
+// without running k6.
+func targetPolicyResponse(err *TargetPolicyError) *ValidationResponse {
+	resp := &ValidationResponse{
+		Valid: false,
+		Error: "the script sends requests to hosts the target policy rejects: " + err.Policy,
+		Summary: ValidationSummary{
+			Status:      "failed",
+			Description: "Script sends requests to hosts the target policy rejects",
+			IssueCount:  len(err.Violations),
+			Severity:    "critical",
+			ReadyToRun:  false,
+		},
+		Recommendations: []string{
+			"Point the script at a test or staging environment the policy allows",
+			"Read the base URL from an __ENV variable set in env_file, so one script serves each environment",
+		},
+		NextSteps: []string{"Change the rejected targets and validate the script again"},
+	}
+	for _, v := range err.Violations {
+		message := fmt.Sprintf("Request to %s is not allowed: %s", v.URL, v.Reason)
+		if v.File != "" {
+			message = fmt.Sprintf("Request to %s in %s is not allowed: %s", v.URL, v.File, v.Reason)
+		}
+		resp.Issues = append(resp.Issues, ValidationIssue{
+			Type:       "target",
+			Severity:   "critical",
+			Message:    message,
+			Suggestion: "Use a host the target policy allows",
+			LineNumber: v.Line,
+		})
+	}
+	return resp
+}
+
 // ValidationError represents errors that occur during validation.
 type ValidationError struct {
 	Type    string
@@ -112,10 +296,19 @@ func (e *ValidationError) Unwrap() error {
 	return e.Cause
 }
 
+// validateOptions controls where a script is executed from during validation.
+type validateOptions struct {
+	// ScriptPath is the resolved workspace path of the script. When set, k6 runs
+	// the file in place instead of a temporary copy.
+	ScriptPath string
+	// Env holds variables passed to the script with --env.
+	Env map[string]string
+}
+
 // validateK6Script validates a k6 script by executing it with minimal configuration.
 //
 //nolint:funlen // Function length slightly exceeds limit due to comprehensive logging
-func validateK6Script(ctx context.Context, script string) (*ValidationResponse, error) {
+func validateK6Script(ctx context.Context, script string, opts validateOptions) (*ValidationResponse, error) {
 	startTime := time.Now()
 	logger := logging.LoggerFromContext(ctx)
 
@@ -145,7 +338,7 @@ func validateK6Script(ctx context.Context, script string) (*ValidationResponse,
 			},
 			Issues:          []ValidationIssue{issue},
 			Recommendations: getRecommendationsForIssue(issue),
-			NextSteps:       []string{"Fix the validation issue and try again", "Use the 'search' tool for k6 documentation"},
+			NextSteps:       []string{"Fix the validation issue and try again", "Use the 'search_docs' tool for k6 documentation"},
 		}, err
 	}
 
@@ -153,8 +346,12 @@ func validateK6Script(ctx context.Context, script string) (*ValidationResponse,
 		"script_size": len(script),
 	})
 
-	// Create secure temporary file
-	tempFile, cleanup, err := createSecureTempFile(script)
+	// Run workspace scripts in place; inline scripts go to a secure temporary file
+	tempFile, cleanup := opts.ScriptPath, func() {}
+	var err error
+	if tempFile == "" {
+		tempFile, cleanup, err = createSecureTempFile(script)
+	}
 	if err != nil {
 		logging.FileOperation(ctx, "validator", "create_temp_file", tempFile, err)
 		return &ValidationResponse{
@@ -184,13 +381,16 @@ func validateK6Script(ctx context.Context, script string) (*ValidationResponse,
 	// Execute k6 validation
 	logger.DebugContext(ctx, "Starting k6 validation execution",
 		slog.String("script_path", helpers.GetPathType(tempFile)))
-	result, err := executeK6Validation(ctx, tempFile)
+	result, err := executeK6Validation(ctx, tempFile, opts.Env)
 	if err != nil {
 		return nil, fmt.Errorf("validating k6 script failed; reason: %w", err)
 	}
 
 	// Enhance result with analysis if validation completed
 	result.Duration = time.Since(startTime).String()
+	output := result.Stderr + "\n" + result.Stdout
+	result.Diagnostics = validationDiagnostics(output, script, tempFile, opts.ScriptPath)
+	result.Fixes = quickfix.Suggest(script, output, scriptDiagnostics(result.Diagnostics, tempFile))
 	logger.DebugContext(ctx, "Enhancing validation result with analysis",
 		slog.Int("initial_issues", len(result.Issues)))
 	enhanceValidationResult(result, script)
@@ -233,7 +433,7 @@ const (
 // executeK6Validation executes k6 with the given script file.
 //
 //nolint:funlen // Function length slightly exceeds limit due to comprehensive logging
-func executeK6Validation(ctx context.Context, scriptPath string) (*ValidationResponse, error) {
+func executeK6Validation(ctx context.Context, scriptPath string, env map[string]string) (*ValidationResponse, error) {
 	logger := logging.LoggerFromContext(ctx)
 	startTime := time.Now()
 
@@ -257,14 +457,17 @@ func executeK6Validation(ctx context.Context, scriptPath string) (*ValidationRes
 	}
 
 	// Prepare k6 command with minimal configuration and additional validation flags
-	cmd := exec.CommandContext(cmdCtx, "k6", "run", // #nosec G204
+	args := []string{"run",
 		"--vus", "1",
 		"--iterations", "1",
 		"--quiet",
 		"--insecure-skip-tls-verify",
 		"--log-format=json",
 		"--no-usage-report",
-		scriptPath)
+	}
+	args = append(args, envArgs(env)...)
+	args = append(args, scriptPath)
+	cmd := exec.CommandContext(cmdCtx, "k6", args...) // #nosec G204
 
 	// Set minimal environment
 	//nolint:forbidigo // Environment variables required for k6 execution
@@ -279,7 +482,9 @@ func executeK6Validation(ctx context.Context, scriptPath string) (*ValidationRes
 	)
 
 	// Execute command and capture output
+	execStart := time.Now()
 	stdout, stderr, exitCode, err := executeCommand(cmd)
+	audit.Command(ctx, cmd, execStart, err)
 
 	// Log execution results
 	logging.ExecutionEvent(ctx, "validator", "k6 run", time.Since(startTime), exitCode, err)
@@ -296,6 +501,15 @@ func executeK6Validation(ctx context.Context, scriptPath string) (*ValidationRes
 	}
 
 	// Handle different types of errors
+	if errors.Is(ctx.Err(), context.Canceled) {
+		logger.InfoContext(ctx, "k6 validation cancelled by the client")
+		result.Error = "k6 validation cancelled by the client"
+		return result, &ValidationError{
+			Type:    "CANCELLED",
+			Message: "k6 validation cancelled by the client",
+			Cause:   ctx.Err(),
+		}
+	}
 	if errors.Is(err, context.DeadlineExceeded) {
 		logger.WarnContext(ctx, "k6 validation timed out",
 			slog.Duration("timeout", ValidationTimeout))
@@ -455,6 +669,8 @@ func getSuggestionForErrorType(errorType, message string) string {
 	case "TIMEOUT":
 		return "Your script may have infinite loops or very slow operations. " +
 			"Check for blocking code and optimize performance."
+	case "CANCELLED":
+		return "The client cancelled the validation before k6 finished; validate again to get a result."
 	default:
 		return "Review your script and ensure it follows k6 best practices"
 	}
@@ -465,18 +681,18 @@ func getRecommendationsForIssue(issue ValidationIssue) []string {
 	switch issue.Type {
 	case "syntax":
 		return []string{
-			"Use the 'search' tool with query 'getting started' for basic k6 syntax",
+			"Use the 'search_docs' tool with query 'getting started' for basic k6 syntax",
 			"Ensure your script has proper import statements and a default function",
 			"Check for missing semicolons, brackets, or quotes",
 		}
 	case "environment":
 		return []string{
 			"Ensure k6 is installed and available in your PATH",
-			"Use the 'search' tool with query 'installation' for setup help",
+			"Use the 'search_docs' tool with query 'installation' for setup help",
 		}
 	default:
 		return []string{
-			"Use the 'search' tool to find relevant k6 documentation",
+			"Use the 'search_docs' tool to find relevant k6 documentation",
 			"Start with a simple script and gradually add complexity",
 		}
 	}
@@ -525,6 +741,63 @@ func enhanceValidationResult(result *ValidationResponse, script string) {
 	addWorkflowIntegrationSuggestions(result)
 }
 
+// inlineScriptName names the temporary copy of an inline script in diagnostics.
+const inlineScriptName = "inline"
+
+// validationDiagnostics locates the errors of k6 output, for the script run
+// from runPath. Locations in the script, or in modules next to a workspace
+// script, get a code frame; an inline script is named inlineScriptName.
+func validationDiagnostics(output, script, runPath, scriptPath string) []diagnostics.Diagnostic {
+	found := diagnostics.Parse(output)
+	for i := range found {
+		d := &found[i]
+		switch {
+		case sameFile(d.File, runPath):
+			if scriptPath == "" {
+				d.File = inlineScriptName
+			}
+			d.CodeFrame = diagnostics.CodeFrame(script, d.Line, d.Column)
+		case scriptPath != "" && isWithin(filepath.Dir(scriptPath), d.File):
+			//nolint:forbidigo // Modules next to a workspace script, which k6 has just read
+			if source, err := os.ReadFile(d.File); err == nil {
+				d.CodeFrame = diagnostics.CodeFrame(string(source), d.Line, d.Column)
+			}
+		}
+	}
+	return found
+}
+
+// sameFile reports whether the paths a and b name the same file, as k6 may
+// print the temporary directory through a symbolic link.
+func sameFile(a, b string) bool {
+	if a == b {
+		return true
+	}
+	//nolint:forbidigo // Comparing files k6 has just run
+	infoA, errA := os.Stat(a)
+	//nolint:forbidigo // Comparing files k6 has just run
+	infoB, errB := os.Stat(b)
+	return errA == nil && errB == nil && os.SameFile(infoA, infoB)
+}
+
+// scriptDiagnostics returns the diagnostics located in the script run from
+// runPath, rather than in its modules.
+func scriptDiagnostics(found []diagnostics.Diagnostic, runPath string) []diagnostics.Diagnostic {
+	var out []diagnostics.Diagnostic
+	for _, d := range found {
+		if d.File == inlineScriptName || sameFile(d.File, runPath) {
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+// isWithin reports whether path is inside dir.
+func isWithin(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && filepath.IsAbs(path) && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
 // analyzeScriptContent performs static analysis of the script content
 func analyzeScriptContent(script string) []ValidationIssue {
 	var issues []ValidationIssue
@@ -643,7 +916,7 @@ func analyzeK6Output(stderr, stdout string) []ValidationIssue {
 			Type:       "import",
 			Severity:   "high",
 			Message:    "Module import error",
-			Suggestion: "Check your import statements. Use 'search' tool with query 'k6 modules' to see available modules.",
+			Suggestion: "Check your import statements. Use the 'search_docs' tool with query 'k6 modules' to see available modules.",
 		})
 	}
 
@@ -715,10 +988,13 @@ func generateValidationNextSteps(result *ValidationResponse) []string {
 		if len(result.Issues) > 0 {
 			steps = append(steps, "Consider addressing the minor issues found for better script quality")
 		}
+		if len(result.Fixes) > 0 {
+			steps = append(steps, "Review the suggested fixes: k6 ignores some of the script's options")
+		}
 
 		steps = append(steps,
 			"Use the 'run' tool to execute your script with desired parameters",
-			"Use the 'search' tool to find examples for advanced testing scenarios",
+			"Use the 'search_docs' tool to find examples for advanced testing scenarios",
 		)
 
 		return steps
@@ -726,6 +1002,19 @@ func generateValidationNextSteps(result *ValidationResponse) []string {
 
 	// Script has issues
 	steps := []string{"Fix the validation errors before running the script"}
+	for _, d := range result.Diagnostics {
+		kind := d.Kind
+		if kind == "" {
+			kind = "error"
+		}
+		steps = append(steps, fmt.Sprintf("Fix the %s at %s: %s", kind, d.Location(), d.Message))
+	}
+	for _, fix := range result.Fixes {
+		if fix.Patch != "" {
+			steps = append(steps, "Apply the patch of each suggested fix with apply_patch, then validate again")
+			break
+		}
+	}
 
 	// Add specific steps based on issue types
 	hasSyntaxIssues := false
@@ -749,7 +1038,7 @@ func generateValidationNextSteps(result *ValidationResponse) []string {
 	}
 
 	steps = append(steps,
-		"Use the 'search' tool for k6 documentation and examples",
+		"Use the 'search_docs' tool for k6 documentation and examples",
 		"Start with a simple script template if needed",
 	)
 
@@ -817,7 +1106,7 @@ func addWorkflowIntegrationSuggestions(result *ValidationResponse) {
 	// Add general workflow recommendations
 	generalWorkflow := []string{
 		"Recommended testing workflow: validate → run (small load) → analyze → scale up",
-		"Use the 'search' tool for examples of advanced k6 patterns and configurations",
+		"Use the 'search_docs' tool for examples of advanced k6 patterns and configurations",
 	}
 	result.Recommendations = append(result.Recommendations, generalWorkflow...)
 
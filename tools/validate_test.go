@@ -0,0 +1,63 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/grafana/mcp-k6/internal/diagnostics"
+	"github.com/grafana/mcp-k6/internal/quickfix"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidationDiagnostics(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	script := "import http from 'k6/http';\nexport default function () {\n  foo();\n}\n"
+	runPath := filepath.Join(dir, "k6-run-1.js")
+	require.NoError(t, os.WriteFile(runPath, []byte(script), 0o600))
+	module := filepath.Join(dir, "lib", "helpers.js")
+	require.NoError(t, os.MkdirAll(filepath.Dir(module), 0o700))
+	require.NoError(t, os.WriteFile(module, []byte("export const x = ;\n"), 0o600))
+
+	output := `{"level":"error","msg":"ReferenceError: foo is not defined\n\tat default (file://` + runPath +
+		`:3:3(2))\n"}` + "\n" + `{"level":"error","msg":"SyntaxError: file://` + module + `: Line 1:18 Unexpected token ;"}`
+
+	// An inline script is named as such, with a frame of its content
+	found := validationDiagnostics(output, script, runPath, "")
+	require.Len(t, found, 2)
+	assert.Equal(t, inlineScriptName, found[0].File)
+	assert.Equal(t, "ReferenceError", found[0].Kind)
+	assert.Contains(t, found[0].CodeFrame, "> 3 |   foo();")
+	// Modules of inline scripts are not read
+	assert.Equal(t, module, found[1].File)
+	assert.Empty(t, found[1].CodeFrame)
+
+	// Modules next to a workspace script are framed too
+	found = validationDiagnostics(output, script, runPath, runPath)
+	require.Len(t, found, 2)
+	assert.Equal(t, runPath, found[0].File)
+	assert.Contains(t, found[1].CodeFrame, "> 1 | export const x = ;")
+
+	steps := generateValidationNextSteps(&ValidationResponse{ExitCode: 1, Diagnostics: found})
+	assert.Contains(t, steps, "Fix the ReferenceError at "+runPath+":3:3: foo is not defined")
+}
+
+func TestValidationFixes(t *testing.T) {
+	t.Parallel()
+
+	found := []diagnostics.Diagnostic{{File: inlineScriptName, Line: 1}, {File: "/work/lib.js", Line: 2}}
+	assert.Equal(t, found[:1], scriptDiagnostics(found, "/tmp/k6-run-1.js"))
+
+	script := "export const options = { VUs: 2 };\nexport default function () {}\n"
+	result := &ValidationResponse{Valid: true, Fixes: quickfix.Suggest(script, "", nil)}
+	require.Len(t, result.Fixes, 1)
+	assert.Contains(t, generateValidationNextSteps(result),
+		"Review the suggested fixes: k6 ignores some of the script's options")
+
+	result = &ValidationResponse{ExitCode: 1, Fixes: quickfix.Suggest("import 'k6/htp';\n", "unknown module: k6/htp", nil)}
+	assert.Contains(t, generateValidationNextSteps(result),
+		"Apply the patch of each suggested fix with apply_patch, then validate again")
+}
@@ -0,0 +1,100 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/grafana/mcp-k6/internal/logging"
+	"github.com/grafana/mcp-k6/internal/summary"
+	"github.com/grafana/mcp-k6/internal/warmup"
+)
+
+// validateWarmupOptions checks the warm-up a run discards.
+func validateWarmupOptions(options *RunOptions) error {
+	if options.DiscardWarmup == "" {
+		return nil
+	}
+	discard, err := time.ParseDuration(options.DiscardWarmup)
+	if err != nil || discard <= 0 {
+		return &RunError{
+			Type:    "PARAMETER_VALIDATION",
+			Message: fmt.Sprintf("discard_warmup must be a positive duration like '30s', got %q", options.DiscardWarmup),
+		}
+	}
+	if d, err := time.ParseDuration(options.Duration); err == nil && len(options.Scenarios) == 0 && discard >= d {
+		return &RunError{
+			Type:    "PARAMETER_VALIDATION",
+			Message: fmt.Sprintf("discard_warmup %s must be shorter than the duration %s", discard, d),
+		}
+	}
+	return nil
+}
+
+// readWarmup returns the metrics of the request log of a run without its
+// warm-up, and thresholds evaluated on them. A log that cannot be read
+// leaves the result without them.
+func readWarmup(ctx context.Context, options *RunOptions, thresholds []summary.Threshold) *warmup.Result {
+	logger := logging.LoggerFromContext(ctx)
+	discard, _ := time.ParseDuration(options.DiscardWarmup)
+
+	//nolint:forbidigo // Reading the JSON output k6 has just written
+	f, err := os.Open(options.RequestLog)
+	if err != nil {
+		logger.WarnContext(ctx, "Failed to open request log", slog.String("error", err.Error()))
+		return nil
+	}
+	defer func() { _ = f.Close() }()
+
+	res, err := warmup.Read(f, discard, thresholds)
+	if err != nil {
+		logger.WarnContext(ctx, "Failed to read metrics without warm-up", slog.String("error", err.Error()))
+		return nil
+	}
+	return res
+}
+
+// warmupNextSteps points at the thresholds whose outcome the warm-up
+// changed.
+func warmupNextSteps(thresholds []summary.Threshold, res *warmup.Result) []string {
+	if res == nil {
+		return nil
+	}
+	var steps []string
+	for _, th := range res.Thresholds {
+		for _, k6 := range thresholds {
+			if k6.Metric != th.Metric || k6.Expression != th.Expression || k6.Passed == th.Passed {
+				continue
+			}
+			if th.Passed {
+				steps = append(steps, fmt.Sprintf("Threshold %s '%s' only failed during the %s warm-up (%s after it); "+
+					"k6 still counts the warm-up, so the run's exit code reports it as failed",
+					th.Metric, th.Expression, res.Discarded, th.Value))
+			} else {
+				steps = append(steps, fmt.Sprintf("Threshold %s '%s' fails once the %s warm-up is discarded (%s)",
+					th.Metric, th.Expression, res.Discarded, th.Value))
+			}
+		}
+	}
+	return steps
+}
+
+// warmupThresholds returns thresholds with the results k6 printed replaced
+// by their results without the warm-up, where those could be evaluated.
+func warmupThresholds(thresholds []summary.Threshold, res *warmup.Result) []summary.Threshold {
+	if res == nil {
+		return thresholds
+	}
+	merged := make([]summary.Threshold, len(thresholds))
+	for i, k6 := range thresholds {
+		merged[i] = k6
+		for _, th := range res.Thresholds {
+			if th.Metric == k6.Metric && th.Expression == k6.Expression {
+				merged[i] = th
+			}
+		}
+	}
+	return merged
+}
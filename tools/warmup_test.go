@@ -0,0 +1,46 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/grafana/mcp-k6/internal/summary"
+	"github.com/grafana/mcp-k6/internal/warmup"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateWarmupOptions(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, validateWarmupOptions(&RunOptions{Duration: "1m"}))
+	require.NoError(t, validateWarmupOptions(&RunOptions{Duration: "1m", DiscardWarmup: "15s"}))
+	require.NoError(t, validateWarmupOptions(&RunOptions{Duration: "10s", DiscardWarmup: "15s",
+		Scenarios: map[string]any{"ramp": map[string]any{"executor": "ramping-vus"}}}))
+	require.ErrorContains(t, validateWarmupOptions(&RunOptions{Duration: "1m", DiscardWarmup: "soon"}),
+		"discard_warmup must be a positive duration")
+	require.ErrorContains(t, validateWarmupOptions(&RunOptions{Duration: "1m", DiscardWarmup: "2m"}),
+		"discard_warmup 2m0s must be shorter than the duration 1m0s")
+
+	assert.Contains(t, requestLogArgs(&RunOptions{DiscardWarmup: "15s"}), "json="+requestLogPlaceholder)
+}
+
+func TestWarmupThresholds(t *testing.T) {
+	t.Parallel()
+
+	k6 := []summary.Threshold{
+		{Metric: "http_req_duration", Expression: "p(95)<500", Value: "p(95)=812ms"},
+		{Metric: "http_req_failed", Expression: "rate<0.01", Value: "rate=0.00%", Passed: true},
+		{Metric: "checks"},
+	}
+	res := &warmup.Result{Discarded: "30s", Thresholds: []summary.Threshold{
+		{Metric: "http_req_duration", Expression: "p(95)<500", Value: "p(95)=210ms", Passed: true},
+		{Metric: "http_req_failed", Expression: "rate<0.01", Value: "rate=0.00%", Passed: true},
+	}}
+
+	assert.Equal(t, []summary.Threshold{res.Thresholds[0], res.Thresholds[1], k6[2]}, warmupThresholds(k6, res))
+	assert.Equal(t, k6, warmupThresholds(k6, nil))
+	assert.Equal(t, []string{"Threshold http_req_duration 'p(95)<500' only failed during the 30s warm-up " +
+		"(p(95)=210ms after it); k6 still counts the warm-up, so the run's exit code reports it as failed"},
+		warmupNextSteps(k6, res))
+	assert.Nil(t, warmupNextSteps(k6, nil))
+}
@@ -0,0 +1,59 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/grafana/mcp-k6/internal/logging"
+	"github.com/grafana/mcp-k6/internal/waterfall"
+)
+
+// readWaterfall returns the group durations of the request log of a run. A
+// log that cannot be read leaves the result without groups.
+func readWaterfall(ctx context.Context, path string) *waterfall.Waterfall {
+	logger := logging.LoggerFromContext(ctx)
+
+	//nolint:forbidigo // Reading the JSON output k6 has just written
+	f, err := os.Open(path)
+	if err != nil {
+		logger.WarnContext(ctx, "Failed to open request log", slog.String("error", err.Error()))
+		return nil
+	}
+	defer func() { _ = f.Close() }()
+
+	groups, err := waterfall.Read(f)
+	if err != nil {
+		logger.WarnContext(ctx, "Failed to read group durations", slog.String("error", err.Error()))
+		return nil
+	}
+	return groups
+}
+
+// waterfallNextSteps points at the slowest step of a journey.
+func waterfallNextSteps(w *waterfall.Waterfall) []string {
+	if w == nil {
+		return []string{"No group durations were recorded: wrap the steps of the journey in group() calls"}
+	}
+	var slowest *waterfall.Group
+	var visit func(groups []*waterfall.Group)
+	visit = func(groups []*waterfall.Group) {
+		for _, g := range groups {
+			// The innermost groups tell which step is slow
+			if len(g.Groups) > 0 {
+				visit(g.Groups)
+				continue
+			}
+			if slowest == nil || g.Avg > slowest.Avg {
+				slowest = g
+			}
+		}
+	}
+	visit(w.Groups)
+	if slowest == nil {
+		return nil
+	}
+	return []string{fmt.Sprintf("The slowest step is group %s (avg %.2fms, p95 %.2fms over %d runs): "+
+		"capture_requests shows its slowest requests", slowest.Path, slowest.Avg, slowest.P95, slowest.Count)}
+}
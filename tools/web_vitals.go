@@ -0,0 +1,172 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/grafana/mcp-k6/internal/logging"
+	"github.com/grafana/mcp-k6/internal/scriptinfo"
+	"github.com/grafana/mcp-k6/internal/webvitals"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// webVitalBudgetsArgument reads the web_vital_budgets argument, mapping web
+// vitals to the value their 75th percentile must stay within.
+func webVitalBudgetsArgument(request mcp.CallToolRequest) (map[string]float64, error) {
+	raw, ok := request.GetArguments()["web_vital_budgets"]
+	if !ok || raw == nil {
+		return nil, nil
+	}
+	return vitalBudgets("web_vital_budgets", raw)
+}
+
+// vitalBudgets reads the budgets of web vitals of the param argument.
+func vitalBudgets(param string, raw any) (map[string]float64, error) {
+	obj, ok := raw.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("'%s' must be an object mapping web vitals (%s) to budgets",
+			param, strings.Join(webvitals.Names, ", "))
+	}
+	budgets := make(map[string]float64, len(obj))
+	for name, v := range obj {
+		if !slices.Contains(webvitals.Names, name) {
+			return nil, fmt.Errorf("unknown web vital %q in %s; use %s",
+				name, param, strings.Join(webvitals.Names, ", "))
+		}
+		budget, ok := v.(float64)
+		if !ok || budget < 0 {
+			return nil, fmt.Errorf("'%s.%s' must be a non-negative number", param, name)
+		}
+		budgets[name] = budget
+	}
+	return budgets, nil
+}
+
+// usesBrowser reports whether script drives a browser, and so records web
+// vitals.
+func usesBrowser(script string) bool {
+	for _, imp := range scriptinfo.Analyze(script).Imports {
+		if imp.Module == "k6/browser" || imp.Module == "k6/experimental/browser" {
+			return true
+		}
+	}
+	return false
+}
+
+// readWebVitals returns the web vitals of each page of the request log of a
+// run. A log that cannot be read leaves the result without web vitals.
+func readWebVitals(ctx context.Context, options *RunOptions) []webvitals.Page {
+	logger := logging.LoggerFromContext(ctx)
+
+	//nolint:forbidigo // Reading the JSON output k6 has just written
+	f, err := os.Open(options.RequestLog)
+	if err != nil {
+		logger.WarnContext(ctx, "Failed to open request log", slog.String("error", err.Error()))
+		return nil
+	}
+	defer func() { _ = f.Close() }()
+
+	pages, err := webvitals.Read(f, options.WebVitalBudgets)
+	if err != nil {
+		logger.WarnContext(ctx, "Failed to read web vitals", slog.String("error", err.Error()))
+		return nil
+	}
+	for i := range pages {
+		pages[i].URL = options.Redactor.String(pages[i].URL)
+	}
+	return pages
+}
+
+// webVitalNextSteps points at the web vitals of pages over budget.
+func webVitalNextSteps(pages []webvitals.Page) []string {
+	var steps []string
+	for _, page := range pages {
+		var over []string
+		for _, v := range page.Vitals {
+			if !v.Passed {
+				over = append(over, fmt.Sprintf("%s p75 %g > %g", v.Name, v.P75, v.Budget))
+			}
+		}
+		if len(over) > 0 {
+			steps = append(steps, fmt.Sprintf("Page %s is over its web vital budgets (%s)",
+				page.URL, strings.Join(over, ", ")))
+		}
+	}
+	return steps
+}
+
+// performanceBudgetArgument reads the performance_budget argument.
+func performanceBudgetArgument(request mcp.CallToolRequest) (*webvitals.Budget, error) {
+	raw, ok := request.GetArguments()["performance_budget"]
+	if !ok || raw == nil {
+		return nil, nil //nolint:nilnil // No budget to check.
+	}
+	obj, ok := raw.(map[string]any)
+	if !ok {
+		return nil, errors.New("'performance_budget' must be an object with bytes, requests and vitals")
+	}
+	budget := &webvitals.Budget{}
+	for key, v := range obj {
+		switch key {
+		case "bytes", "requests":
+			n, ok := v.(float64)
+			if !ok || n < 0 {
+				return nil, fmt.Errorf("'performance_budget.%s' must be a non-negative number", key)
+			}
+			if key == "bytes" {
+				budget.Bytes = n
+			} else {
+				budget.Requests = n
+			}
+		case "vitals":
+			vitals, err := vitalBudgets("performance_budget.vitals", v)
+			if err != nil {
+				return nil, err
+			}
+			budget.Vitals = vitals
+		default:
+			return nil, fmt.Errorf("unknown performance_budget field %q; use bytes, requests or vitals", key)
+		}
+	}
+	return budget, nil
+}
+
+// checkPerformanceBudget checks the request log of a browser run against its
+// performance budget. A log that cannot be read leaves the result without
+// it.
+func checkPerformanceBudget(ctx context.Context, options *RunOptions) *webvitals.BudgetResult {
+	logger := logging.LoggerFromContext(ctx)
+
+	//nolint:forbidigo // Reading the JSON output k6 has just written
+	f, err := os.Open(options.RequestLog)
+	if err != nil {
+		logger.WarnContext(ctx, "Failed to open request log", slog.String("error", err.Error()))
+		return nil
+	}
+	defer func() { _ = f.Close() }()
+
+	result, err := webvitals.CheckBudget(f, *options.PerformanceBudget)
+	if err != nil {
+		logger.WarnContext(ctx, "Failed to check performance budget", slog.String("error", err.Error()))
+		return nil
+	}
+	return result
+}
+
+// budgetNextSteps points at the violations of a performance budget.
+func budgetNextSteps(result *webvitals.BudgetResult) []string {
+	if result == nil || result.Passed {
+		return nil
+	}
+	over := make([]string, 0, len(result.Violations))
+	for _, v := range result.Violations {
+		over = append(over, fmt.Sprintf("%s %g > %g", v.Budget, v.Actual, v.Limit))
+	}
+	return []string{fmt.Sprintf("The run is over its performance budget (%s): "+
+		"review performance_budget.violations and web_vitals for the pages to optimize", strings.Join(over, ", "))}
+}
@@ -0,0 +1,74 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/grafana/mcp-k6/internal/webvitals"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebVitalBudgetsArgument(t *testing.T) {
+	t.Parallel()
+
+	budgets, err := webVitalBudgetsArgument(newCallRequest(map[string]any{}))
+	require.NoError(t, err)
+	assert.Nil(t, budgets)
+
+	budgets, err = webVitalBudgetsArgument(newCallRequest(map[string]any{
+		"web_vital_budgets": map[string]any{"lcp": 2000.0, "cls": 0.2},
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]float64{"lcp": 2000, "cls": 0.2}, budgets)
+
+	_, err = webVitalBudgetsArgument(newCallRequest(map[string]any{"web_vital_budgets": map[string]any{"lcd": 1.0}}))
+	require.ErrorContains(t, err, `unknown web vital "lcd"`)
+	_, err = webVitalBudgetsArgument(newCallRequest(map[string]any{"web_vital_budgets": map[string]any{"lcp": "2s"}}))
+	require.Error(t, err)
+}
+
+func TestWebVitals(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, usesBrowser("import { browser } from 'k6/browser';\nexport default async function () {}\n"))
+	assert.False(t, usesBrowser("import http from 'k6/http';\nexport default function () {}\n"))
+
+	steps := webVitalNextSteps([]webvitals.Page{
+		{URL: "https://shop.test/", Passed: true},
+		{URL: "https://shop.test/cart", Vitals: []webvitals.Vital{
+			{Name: "lcp", P75: 3200, Budget: 2500},
+			{Name: "cls", P75: 0.05, Budget: 0.1, Passed: true},
+		}},
+	})
+	assert.Equal(t, []string{"Page https://shop.test/cart is over its web vital budgets (lcp p75 3200 > 2500)"}, steps)
+}
+
+func TestPerformanceBudgetArgument(t *testing.T) {
+	t.Parallel()
+
+	budget, err := performanceBudgetArgument(newCallRequest(map[string]any{}))
+	require.NoError(t, err)
+	assert.Nil(t, budget)
+
+	budget, err = performanceBudgetArgument(newCallRequest(map[string]any{"performance_budget": map[string]any{
+		"bytes": 2000000.0, "requests": 80.0, "vitals": map[string]any{"lcp": 2500.0},
+	}}))
+	require.NoError(t, err)
+	assert.Equal(t, &webvitals.Budget{Bytes: 2000000, Requests: 80, Vitals: map[string]float64{"lcp": 2500}}, budget)
+
+	for _, bad := range []map[string]any{
+		{"size": 1.0},
+		{"bytes": -1.0},
+		{"vitals": map[string]any{"speed": 1.0}},
+	} {
+		_, err = performanceBudgetArgument(newCallRequest(map[string]any{"performance_budget": bad}))
+		require.Error(t, err, bad)
+	}
+
+	steps := budgetNextSteps(&webvitals.BudgetResult{Violations: []webvitals.Violation{
+		{Budget: "bytes", Limit: 500000, Actual: 920000},
+	}})
+	require.Len(t, steps, 1)
+	assert.Contains(t, steps[0], "bytes 920000 > 500000")
+	assert.Empty(t, budgetNextSteps(&webvitals.BudgetResult{Passed: true}))
+}
@@ -0,0 +1,124 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/grafana/mcp-k6/internal/logging"
+	"github.com/grafana/mcp-k6/internal/releasenotes"
+	"github.com/grafana/xk6-docs/docs"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// WhatsNewTool exposes a tool for summarizing the k6 releases after a
+// version.
+//
+//nolint:gochecknoglobals // Shared tool definition registered at startup.
+var WhatsNewTool = mcp.NewTool(
+	"whats_new",
+	readOnlyTool(),
+	mcp.WithDescription(
+		"Summarize the new features, breaking changes and deprecations of the k6 releases between the "+
+			"installed k6, or a given version, and the latest release, from the release notes of the "+
+			"documentation. Use it before upgrading k6, or to explain why a script behaves differently.",
+	),
+	mcp.WithString(
+		"from_version",
+		mcp.Description("Optional: the k6 version to start after, e.g. '0.57.0' (default: the installed k6)."),
+	),
+	mcp.WithString(
+		"to_version",
+		mcp.Description("Optional: the last k6 version to include, e.g. '1.2.0' (default: the latest release)."),
+	),
+)
+
+// RegisterWhatsNewTool registers the whats_new tool, reading the release
+// notes of the latest documentation version of catalog.
+func RegisterWhatsNewTool(s *server.MCPServer, catalog *docs.Catalog) {
+	s.AddTool(WhatsNewTool, withToolLogger("whats_new", newWhatsNewHandlerFunc(catalog)))
+}
+
+type whatsNewResponse struct {
+	From string `json:"from_version"`
+	To   string `json:"to_version"`
+	// Releases are the releases after From up to To, oldest first.
+	Releases []releasenotes.Release `json:"releases"`
+	// Features, BreakingChanges and Deprecations count the entries of
+	// Releases.
+	Features        int      `json:"features"`
+	BreakingChanges int      `json:"breaking_changes"`
+	Deprecations    int      `json:"deprecations"`
+	NextSteps       []string `json:"next_steps,omitempty"`
+}
+
+func newWhatsNewHandlerFunc(catalog *docs.Catalog) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		logger := logging.LoggerFromContext(ctx)
+
+		raw := request.GetString("from_version", "")
+		if raw == "" {
+			raw = installedK6Version(ctx)
+		}
+		if raw == "" {
+			return mcp.NewToolResultError("k6 was not found; pass from_version, such as '0.57.0'"), nil
+		}
+		from, ok := releasenotes.ParseVersion(raw)
+		if !ok {
+			return mcp.NewToolResultError(fmt.Sprintf("from_version %q is not a k6 version such as '0.57.0'", raw)), nil
+		}
+
+		idx, err := catalog.Index(ctx, "")
+		if err != nil {
+			return mcp.NewToolResultError(versionError("", catalog, err).Error()), nil
+		}
+		notes := releasenotes.Notes(idx)
+		if len(notes) == 0 {
+			return mcp.NewToolResultError(fmt.Sprintf("the documentation %s has no release notes", idx.Version)), nil
+		}
+		to := notes[len(notes)-1].Version
+		if last := request.GetString("to_version", ""); last != "" {
+			if to, ok = releasenotes.ParseVersion(last); !ok {
+				return mcp.NewToolResultError(fmt.Sprintf("to_version %q is not a k6 version such as '1.2.0'", last)), nil
+			}
+		}
+
+		resp := whatsNewResponse{From: from.String(), To: to.String(), Releases: []releasenotes.Release{}}
+		for _, note := range notes {
+			if note.Version.Compare(from) <= 0 || note.Version.Compare(to) > 0 {
+				continue
+			}
+			content, err := catalog.Read(ctx, idx.Version, note.Slug)
+			if err != nil {
+				logger.WarnContext(ctx, "Failed to read release notes",
+					slog.String("slug", note.Slug), slog.String("error", err.Error()))
+				continue
+			}
+			r := releasenotes.Parse(note, string(content))
+			resp.Features += len(r.Features)
+			resp.BreakingChanges += len(r.BreakingChanges)
+			resp.Deprecations += len(r.Deprecations)
+			resp.Releases = append(resp.Releases, r)
+		}
+		resp.NextSteps = whatsNewNextSteps(resp)
+
+		logger.InfoContext(ctx, "Release notes summarized",
+			slog.String("from_version", resp.From),
+			slog.String("to_version", resp.To),
+			slog.Int("releases", len(resp.Releases)))
+		return structuredResponse(ctx, logger, resp)
+	}
+}
+
+func whatsNewNextSteps(resp whatsNewResponse) []string {
+	if len(resp.Releases) == 0 {
+		return []string{fmt.Sprintf("No release after %s up to %s has release notes", resp.From, resp.To)}
+	}
+	steps := []string{"Read the full notes of a release with get_documentation on its slug"}
+	if resp.BreakingChanges > 0 || resp.Deprecations > 0 {
+		steps = append(steps, fmt.Sprintf("Check scripts against the breaking changes and deprecations with "+
+			"check_compatibility and k6_version %s", resp.To))
+	}
+	return steps
+}
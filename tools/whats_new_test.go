@@ -0,0 +1,58 @@
+package tools
+
+import (
+	"encoding/json"
+	"testing"
+	"testing/fstest"
+
+	"github.com/grafana/xk6-docs/docs"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWhatsNew(t *testing.T) {
+	t.Parallel()
+
+	index, err := json.Marshal(docs.Index{Version: "v1.2.x", Sections: []docs.Section{
+		{Slug: "release-notes", Title: "Release notes", IsIndex: true},
+		{Slug: "release-notes/v1-0-0", RelPath: "release-notes/v1-0-0.md", Title: "Version 1.0.0 release notes"},
+		{Slug: "release-notes/v1-1-0", RelPath: "release-notes/v1-1-0.md", Title: "Version 1.1.0 release notes"},
+		{Slug: "release-notes/v1-2-0", RelPath: "release-notes/v1-2-0.md", Title: "Version 1.2.0 release notes"},
+	}})
+	require.NoError(t, err)
+	fsys := fstest.MapFS{
+		"v1.2.x/sections.json":                    {Data: index},
+		"v1.2.x/markdown/release-notes/v1-0-0.md": {Data: []byte("## Breaking changes\n\n- Removes k6/experimental/browser\n")},
+		"v1.2.x/markdown/release-notes/v1-1-0.md": {Data: []byte("## New features\n\n### Summary modes\n")},
+		"v1.2.x/markdown/release-notes/v1-2-0.md": {Data: []byte("## New features\n\n### Secret sources\n\n" +
+			"## Deprecations\n\n- The externally-controlled executor\n")},
+	}
+	handler := newWhatsNewHandlerFunc(docs.NewCatalog(docs.WithFS(fsys)))
+
+	result, err := handler(t.Context(), newCallRequest(map[string]any{"from_version": "1.0.2"}))
+	require.NoError(t, err)
+	require.False(t, result.IsError, "unexpected tool error: %+v", result.Content)
+	var resp whatsNewResponse
+	decodeJSON(t, result, &resp)
+	assert.Equal(t, "v1.0.2", resp.From)
+	assert.Equal(t, "v1.2.0", resp.To)
+	require.Len(t, resp.Releases, 2)
+	assert.Equal(t, []string{"Summary modes"}, resp.Releases[0].Features)
+	assert.Equal(t, "release-notes/v1-2-0", resp.Releases[1].Slug)
+	assert.Equal(t, 2, resp.Features)
+	assert.Equal(t, 1, resp.Deprecations)
+	assert.Len(t, resp.NextSteps, 2)
+
+	result, err = handler(t.Context(), newCallRequest(map[string]any{"from_version": "0.57.0", "to_version": "v1.0.0"}))
+	require.NoError(t, err)
+	resp = whatsNewResponse{}
+	decodeJSON(t, result, &resp)
+	require.Len(t, resp.Releases, 1)
+	assert.Equal(t, []string{"Removes k6/experimental/browser"}, resp.Releases[0].BreakingChanges)
+
+	result, err = handler(t.Context(), newCallRequest(map[string]any{"from_version": "devel"}))
+	require.NoError(t, err)
+	require.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "is not a k6 version")
+}
@@ -0,0 +1,559 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/mcp-k6/internal/logging"
+	"github.com/grafana/mcp-k6/internal/scenario"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// BuildWorkloadMixTool exposes a tool for reproducing a production traffic
+// mix with one arrival-rate scenario per endpoint.
+//
+//nolint:gochecknoglobals // Shared tool definition registered at startup.
+var BuildWorkloadMixTool = mcp.NewTool(
+	"build_workload_mix",
+	readOnlyTool(),
+	mcp.WithDescription(
+		"Generate a multi-scenario k6 script reproducing a production traffic mix. Takes the endpoints with "+
+			"their observed share of requests, as a list or as a CSV table exported from analytics, and the "+
+			"total production rate, and gives each endpoint a constant-arrival-rate scenario whose rate is its "+
+			"share of the production rate times a scale factor, so the mix holds whatever the response times. "+
+			"VUs are sized from the expected response time. Path parameters such as /products/{id} are read "+
+			"from environment variables.",
+	),
+	mcp.WithString(
+		"base_url",
+		mcp.Required(),
+		mcp.Description("The base URL of the endpoints, e.g. https://shop.example.com. "+
+			"The script reads it from BASE_URL first."),
+	),
+	mcp.WithArray(
+		"endpoints",
+		mcp.Description("The endpoints, e.g. [{\"method\": \"GET\", \"path\": \"/products/{id}\", "+
+			"\"share\": 62.5}]. share is a percentage, a fraction or a request count: shares are normalized "+
+			"to their total. method defaults to GET; body is sent as is."),
+		mcp.Items(map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"name":   map[string]any{"type": "string"},
+				"method": map[string]any{"type": "string"},
+				"path":   map[string]any{"type": "string"},
+				"share":  map[string]any{"type": "number"},
+				"body":   map[string]any{"type": "string"},
+			},
+			"required": []string{"path", "share"},
+		}),
+	),
+	mcp.WithString(
+		"table",
+		mcp.Description("Alternative to endpoints: a CSV, TSV or semicolon-separated table with a header row. "+
+			"Recognized columns: method, path (or endpoint, route, url) and share (or percent, count, requests, "+
+			"hits). A path cell may hold the method, as in 'GET /products'."),
+	),
+	mcp.WithNumber(
+		"production_rate",
+		mcp.Required(),
+		mcp.Description("The total production rate of the listed endpoints, in requests per second."),
+	),
+	mcp.WithNumber(
+		"scale",
+		mcp.Description("Optional: the share of the production rate to generate, e.g. 0.1 for 10% or 2 "+
+			"for twice the production load (default: 1)."),
+	),
+	mcp.WithNumber(
+		"response_time",
+		mcp.Description("Optional: the expected response time in seconds, used to size VUs (default: 0.5)."),
+	),
+	mcp.WithString(
+		"duration",
+		mcp.Description("Optional: test duration (default: '10m')."),
+	),
+	mcp.WithString(
+		"latency_threshold",
+		mcp.Description(fmt.Sprintf("Optional: http_req_duration threshold of each endpoint "+
+			"(default: %q).", defaultMixLatency)),
+	),
+)
+
+const (
+	// maxMixEndpoints bounds the scenarios of a workload mix.
+	maxMixEndpoints = 50
+	// defaultMixLatency is the default latency threshold of endpoints.
+	defaultMixLatency = "p(95)<500"
+	// mixRateTolerance is the relative error of a rounded rate worth a warning.
+	mixRateTolerance = 0.05
+)
+
+//nolint:gochecknoglobals // Lookup table.
+var (
+	mixMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS"}
+	// mixColumns maps the table headers to endpoint fields.
+	mixColumns = map[string]string{
+		"name":     "name",
+		"method":   "method",
+		"verb":     "method",
+		"path":     "path",
+		"endpoint": "path",
+		"route":    "path",
+		"url":      "path",
+		"uri":      "path",
+		"share":    "share",
+		"percent":  "share",
+		"%":        "share",
+		"count":    "share",
+		"requests": "share",
+		"hits":     "share",
+		"calls":    "share",
+	}
+)
+
+// mixPathParam matches the {id} and :id parameters of a path.
+//
+//nolint:gochecknoglobals // Compiled once, read-only.
+var mixPathParam = regexp.MustCompile(`\{([A-Za-z_][A-Za-z0-9_]*)\}|(?:^|/):([A-Za-z_][A-Za-z0-9_]*)`)
+
+// RegisterBuildWorkloadMixTool registers the build_workload_mix tool with the
+// MCP server.
+func RegisterBuildWorkloadMixTool(s *server.MCPServer) {
+	s.AddTool(BuildWorkloadMixTool, withToolLogger("build_workload_mix", buildWorkloadMix))
+}
+
+// mixEndpoint is an endpoint of the workload mix.
+type mixEndpoint struct {
+	Name   string  `json:"name"`
+	Method string  `json:"method"`
+	Path   string  `json:"path"`
+	Share  float64 `json:"share"`
+	Body   string  `json:"body,omitempty"`
+}
+
+// mixScenario is the scenario generated for an endpoint.
+type mixScenario struct {
+	Name     string `json:"name"`
+	Endpoint string `json:"endpoint"`
+	// Share is the normalized share of the endpoint, between 0 and 1.
+	Share float64 `json:"share"`
+	// PerSecond is the target rate; Rate per TimeUnit is what k6 runs.
+	PerSecond float64 `json:"per_second"`
+	Rate      int     `json:"rate"`
+	TimeUnit  string  `json:"time_unit"`
+	MaxVUs    int     `json:"max_vus"`
+	// PathParams are the environment variables read for path parameters.
+	PathParams []string `json:"path_params,omitempty"`
+}
+
+// buildWorkloadMixResponse is the JSON structure returned by the tool.
+type buildWorkloadMixResponse struct {
+	Script    string        `json:"script"`
+	Scenarios []mixScenario `json:"scenarios"`
+	// TotalRate is the generated requests per second of all scenarios.
+	TotalRate float64  `json:"total_rate"`
+	PeakVUs   int      `json:"peak_vus"`
+	Warnings  []string `json:"warnings,omitempty"`
+	NextSteps []string `json:"next_steps"`
+}
+
+func buildWorkloadMix(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logger := logging.LoggerFromContext(ctx)
+
+	baseURL := strings.TrimRight(strings.TrimSpace(request.GetString("base_url", "")), "/")
+	production := request.GetFloat("production_rate", 0)
+	scale := request.GetFloat("scale", 1)
+	responseTime := request.GetFloat("response_time", 0.5)
+	duration := strings.TrimSpace(request.GetString("duration", "10m"))
+	latency := strings.TrimSpace(request.GetString("latency_threshold", defaultMixLatency))
+	switch {
+	case !isHTTPURL(baseURL):
+		return mcp.NewToolResultError("base_url must be an http or https URL"), nil
+	case production <= 0:
+		return mcp.NewToolResultError("production_rate must be positive"), nil
+	case scale <= 0:
+		return mcp.NewToolResultError("scale must be positive"), nil
+	case responseTime <= 0:
+		return mcp.NewToolResultError("response_time must be positive"), nil
+	}
+
+	endpoints, warnings, err := mixEndpoints(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	total := production * scale
+	scenarios := make(map[string]any, len(endpoints))
+	thresholds := map[string]any{"http_req_failed": []string{"rate<0.01"}}
+	var (
+		mix       []mixScenario
+		generated float64
+		peak      int
+	)
+	for _, e := range endpoints {
+		perSecond := total * e.Share
+		rate, unit := wholeRate(perSecond)
+		runs := float64(rate) / unitSeconds(unit)
+		if math.Abs(runs-perSecond)/perSecond > mixRateTolerance {
+			warnings = append(warnings, fmt.Sprintf("%s %s runs %d per %s, off its target of %.4g per second: "+
+				"k6 runs whole iterations per time unit", e.Method, e.Path, rate, unit, perSecond))
+		}
+		// Little's law gives the mean; VUs absorb its variance and slower responses.
+		pre := max(1, int(math.Ceil(runs*responseTime*1.2)))
+		maxVUs := max(pre+1, int(math.Ceil(runs*responseTime*2)))
+		sc, _, err := scenario.Build("constant-arrival-rate", scenario.Params{
+			Rate: &rate, TimeUnit: unit, Duration: duration, PreAllocatedVUs: &pre, MaxVUs: &maxVUs, Exec: e.Name,
+		})
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("invalid scenario: %v", err)), nil
+		}
+		scenarios[e.Name] = sc
+		thresholds["http_req_duration{scenario:"+e.Name+"}"] = []string{latency}
+		generated += runs
+		peak += sc.PeakVUs()
+		mix = append(mix, mixScenario{
+			Name:       e.Name,
+			Endpoint:   e.Method + " " + e.Path,
+			Share:      round3(e.Share),
+			PerSecond:  round3(perSecond),
+			Rate:       rate,
+			TimeUnit:   unit,
+			MaxVUs:     sc.PeakVUs(),
+			PathParams: pathParamVariables(e.Path),
+		})
+	}
+	if peak > MaxVUs {
+		warnings = append(warnings, fmt.Sprintf(
+			"the scenarios may use %d VUs, more than the %d run_script allows: lower scale for a local run",
+			peak, MaxVUs))
+	}
+
+	options := map[string]any{"scenarios": scenarios, "thresholds": thresholds}
+	script, err := mixScript(baseURL, endpoints, options, total)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.InfoContext(ctx, "Workload mix generated",
+		slog.Int("endpoints", len(endpoints)),
+		slog.Float64("total_rate", generated),
+		slog.Int("peak_vus", peak))
+
+	return marshalResponse(ctx, logger, buildWorkloadMixResponse{
+		Script:    script,
+		Scenarios: mix,
+		TotalRate: round3(generated),
+		PeakVUs:   peak,
+		Warnings:  warnings,
+		NextSteps: []string{
+			"Set the path parameter variables, or replace them with test data from a SharedArray",
+			"Validate the script with validate_script, then run it at a small scale first",
+			"Compare the per-scenario http_reqs of the summary with the production mix",
+		},
+	})
+}
+
+// mixEndpoints reads the endpoints or table parameter, merging duplicate
+// endpoints and normalizing shares to a total of 1.
+func mixEndpoints(request mcp.CallToolRequest) ([]mixEndpoint, []string, error) {
+	var (
+		endpoints []mixEndpoint
+		err       error
+	)
+	raw, hasList := request.GetArguments()["endpoints"]
+	table := strings.TrimSpace(request.GetString("table", ""))
+	switch {
+	case hasList && table != "":
+		return nil, nil, errors.New("use either endpoints or table, not both")
+	case hasList:
+		endpoints, err = decodeMixEndpoints(raw)
+	case table != "":
+		endpoints, err = parseMixTable(table)
+	default:
+		return nil, nil, errors.New("endpoints or table is required")
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		warnings []string
+		merged   []mixEndpoint
+		names    []string
+		sum      float64
+	)
+	for i, e := range endpoints {
+		e.Method = strings.ToUpper(strings.TrimSpace(e.Method))
+		if e.Method == "" {
+			e.Method = "GET"
+		}
+		e.Path = strings.TrimSpace(e.Path)
+		switch {
+		case !slices.Contains(mixMethods, e.Method):
+			return nil, nil, fmt.Errorf("endpoints[%d]: unsupported method %q", i, e.Method)
+		case !strings.HasPrefix(e.Path, "/"):
+			return nil, nil, fmt.Errorf("endpoints[%d]: path %q must start with /", i, e.Path)
+		case e.Share < 0 || math.IsNaN(e.Share):
+			return nil, nil, fmt.Errorf("endpoints[%d]: share must not be negative", i)
+		case e.Share == 0:
+			warnings = append(warnings, fmt.Sprintf("%s %s has no traffic and is left out", e.Method, e.Path))
+			continue
+		}
+		sum += e.Share
+		if j := slices.IndexFunc(merged, func(m mixEndpoint) bool {
+			return m.Method == e.Method && m.Path == e.Path
+		}); j >= 0 {
+			merged[j].Share += e.Share
+			warnings = append(warnings, fmt.Sprintf("%s %s is listed more than once: shares are added up",
+				e.Method, e.Path))
+			continue
+		}
+		switch {
+		case e.Name != "":
+		case e.Path == "/":
+			e.Name = strings.ToLower(e.Method) + " root"
+		default:
+			e.Name = strings.ToLower(e.Method) + " " + mixPathParam.ReplaceAllString(e.Path, "/by $1$2")
+		}
+		e.Name = jsIdentifier(e.Name)
+		if e.Name == "" {
+			e.Name = "endpoint"
+		}
+		e.Name = uniqueIdentifier(e.Name, names)
+		names = append(names, e.Name)
+		merged = append(merged, e)
+	}
+	switch {
+	case len(merged) == 0:
+		return nil, nil, errors.New("no endpoint has traffic")
+	case len(merged) > maxMixEndpoints:
+		return nil, nil, fmt.Errorf("too many endpoints: %d (max %d)", len(merged), maxMixEndpoints)
+	}
+	if math.Abs(sum-1) > 0.01 && math.Abs(sum-100) > 1 && sum < 100 {
+		warnings = append(warnings, fmt.Sprintf("the shares add up to %.4g, neither 1 nor 100: they are "+
+			"normalized to the listed endpoints", sum))
+	}
+	for i := range merged {
+		merged[i].Share /= sum
+	}
+	return merged, warnings, nil
+}
+
+// decodeMixEndpoints decodes the endpoints parameter.
+func decodeMixEndpoints(raw any) ([]mixEndpoint, error) {
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid endpoints: %w", err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	var endpoints []mixEndpoint
+	if err := dec.Decode(&endpoints); err != nil {
+		return nil, fmt.Errorf("invalid endpoints: %w", err)
+	}
+	return endpoints, nil
+}
+
+// parseMixTable reads the endpoints of an exported table, detecting its
+// delimiter from the header row.
+func parseMixTable(table string) ([]mixEndpoint, error) {
+	header, _, _ := strings.Cut(table, "\n")
+	r := csv.NewReader(strings.NewReader(table))
+	r.TrimLeadingSpace = true
+	r.FieldsPerRecord = -1
+	switch {
+	case strings.Contains(header, "\t"):
+		r.Comma = '\t'
+	case strings.Count(header, ";") > strings.Count(header, ","):
+		r.Comma = ';'
+	}
+
+	columns := map[string]int{}
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid table: %w", err)
+	}
+	if len(records) < 2 {
+		return nil, errors.New("table needs a header row and at least one endpoint")
+	}
+	for i, h := range records[0] {
+		h = strings.ToLower(strings.Trim(strings.TrimSpace(h), `"`))
+		h = strings.TrimSuffix(strings.TrimSuffix(h, " (%)"), " %")
+		if field, ok := mixColumns[h]; ok {
+			if _, seen := columns[field]; !seen {
+				columns[field] = i
+			}
+		}
+	}
+	if _, ok := columns["path"]; !ok {
+		return nil, errors.New("table has no path column (path, endpoint, route or url)")
+	}
+	if _, ok := columns["share"]; !ok {
+		return nil, errors.New("table has no share column (share, percent, count, requests or hits)")
+	}
+
+	cell := func(record []string, field string) string {
+		i, ok := columns[field]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+	// Semicolon-separated exports use the decimal comma.
+	thousands, decimal := ",", ","
+	if r.Comma == ';' {
+		thousands, decimal = ".", "."
+	}
+	share := strings.NewReplacer("%", "", thousands, "", decimal, ".", "_", "", " ", "")
+	var endpoints []mixEndpoint
+	for n, record := range records[1:] {
+		pathCell := cell(record, "path")
+		if pathCell == "" {
+			continue
+		}
+		e := mixEndpoint{Name: cell(record, "name"), Method: cell(record, "method"), Path: pathCell}
+		if method, path, ok := strings.Cut(pathCell, " "); ok && slices.Contains(mixMethods, strings.ToUpper(method)) {
+			e.Method, e.Path = method, strings.TrimSpace(path)
+		}
+		e.Path = tablePath(e.Path)
+		e.Share, err = strconv.ParseFloat(share.Replace(cell(record, "share")), 64)
+		if err != nil {
+			return nil, fmt.Errorf("table row %d: invalid share %q", n+2, cell(record, "share"))
+		}
+		endpoints = append(endpoints, e)
+	}
+	return endpoints, nil
+}
+
+// tablePath strips the scheme, host and query of a URL exported in a table.
+func tablePath(s string) string {
+	if i := strings.Index(s, "://"); i >= 0 {
+		rest := s[i+3:]
+		if j := strings.Index(rest, "/"); j >= 0 {
+			s = rest[j:]
+		} else {
+			s = "/"
+		}
+	}
+	s, _, _ = strings.Cut(s, "?")
+	return s
+}
+
+// pathParamVariables returns the environment variables read for the
+// parameters of path.
+func pathParamVariables(path string) []string {
+	var vars []string
+	for _, m := range mixPathParam.FindAllStringSubmatch(path, -1) {
+		vars = append(vars, pathParamVariable(m[1]+m[2]))
+	}
+	return vars
+}
+
+// pathParamVariable returns the environment variable of a path parameter,
+// such as PRODUCT_ID for productId.
+func pathParamVariable(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		if i > 0 && r >= 'A' && r <= 'Z' {
+			b.WriteByte('_')
+		}
+		b.WriteRune(r)
+	}
+	return strings.ToUpper(b.String())
+}
+
+// mixURL returns the template literal of the URL of path, reading its
+// parameters from the environment.
+func mixURL(path string) string {
+	var b strings.Builder
+	b.WriteString("`${BASE_URL}")
+	last := 0
+	for _, loc := range mixPathParam.FindAllStringSubmatchIndex(path, -1) {
+		start := loc[0]
+		if path[start] == '/' {
+			start++
+		}
+		var name string
+		if loc[2] >= 0 {
+			name = path[loc[2]:loc[3]]
+		} else {
+			name = path[loc[4]:loc[5]]
+		}
+		b.WriteString(jsTemplateBody(path[last:start]))
+		fmt.Fprintf(&b, "${__ENV.%s || '1'}", pathParamVariable(name))
+		last = loc[1]
+	}
+	b.WriteString(jsTemplateBody(path[last:]))
+	b.WriteString("`")
+	return b.String()
+}
+
+// mixScript writes the workload mix script, with one exported function per
+// endpoint.
+func mixScript(baseURL string, endpoints []mixEndpoint, options map[string]any, total float64) (string, error) {
+	// Thresholds hold < and >, which HTML escaping would turn into \u003c.
+	var data bytes.Buffer
+	enc := json.NewEncoder(&data)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(options); err != nil {
+		return "", fmt.Errorf("failed to marshal options: %w", err)
+	}
+
+	w := &codeWriter{}
+	w.line(fmt.Sprintf("// Generated by mcp-k6 to reproduce a production traffic mix of %s requests per second.",
+		formatRate(total)))
+	w.line("// Each endpoint runs in its own constant-arrival-rate scenario at its share of the total.")
+	w.line("import http from 'k6/http';")
+	w.line("import { check } from 'k6';")
+	w.line("")
+	w.line("export const options = " + strings.TrimSuffix(data.String(), "\n") + ";")
+	w.line("")
+	w.line(fmt.Sprintf("const BASE_URL = __ENV.BASE_URL || %s;", jsString(baseURL)))
+	for _, e := range endpoints {
+		w.line("")
+		w.line(fmt.Sprintf("// %s %s: %.1f%% of the requests.", e.Method, e.Path, e.Share*100))
+		w.open(fmt.Sprintf("export function %s() {", e.Name))
+		params := fmt.Sprintf("{ tags: { name: %s } }", jsString(e.Method+" "+e.Path))
+		body := "null"
+		if e.Body != "" {
+			body = jsString(e.Body)
+		}
+		url := mixURL(e.Path)
+		switch e.Method {
+		case "GET":
+			w.line(fmt.Sprintf("const res = http.get(%s, %s);", url, params))
+		case "POST", "PUT", "PATCH":
+			w.line(fmt.Sprintf("const res = http.%s(%s, %s, %s);", strings.ToLower(e.Method), url, body, params))
+		case "DELETE":
+			w.line(fmt.Sprintf("const res = http.del(%s, %s, %s);", url, body, params))
+		default:
+			w.line(fmt.Sprintf("const res = http.request(%s, %s, %s, %s);", jsString(e.Method), url, body, params))
+		}
+		w.line("check(res, { 'status is 2xx or 3xx': (r) => r.status >= 200 && r.status < 400 });")
+		w.close("}")
+	}
+	return w.String(), nil
+}
+
+// unitSeconds returns the seconds of a time unit returned by wholeRate.
+func unitSeconds(unit string) float64 {
+	switch unit {
+	case "1m":
+		return 60
+	case "1h":
+		return 3600
+	default:
+		return 1
+	}
+}
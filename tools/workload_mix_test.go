@@ -0,0 +1,116 @@
+package tools
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildWorkloadMix(t *testing.T) {
+	t.Parallel()
+
+	result, err := buildWorkloadMix(context.Background(), newCallRequest(map[string]any{
+		"base_url":        "https://shop.example.com/",
+		"production_rate": float64(200),
+		"scale":           0.1,
+		"endpoints": []any{
+			map[string]any{"path": "/products/{productId}", "share": float64(60)},
+			map[string]any{"method": "post", "path": "/cart", "share": float64(30), "body": `{"sku":1}`},
+			map[string]any{"path": "/", "share": float64(10)},
+		},
+	}))
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+
+	var resp buildWorkloadMixResponse
+	decodeJSON(t, result, &resp)
+	require.Len(t, resp.Scenarios, 3)
+	assert.Equal(t, "getProductsByProductId", resp.Scenarios[0].Name)
+	assert.Equal(t, []string{"PRODUCT_ID"}, resp.Scenarios[0].PathParams)
+	assert.Equal(t, 12, resp.Scenarios[0].Rate)
+	assert.Equal(t, "1s", resp.Scenarios[0].TimeUnit)
+	assert.Equal(t, 360, resp.Scenarios[1].Rate)
+	assert.Equal(t, "1m", resp.Scenarios[1].TimeUnit)
+	assert.InDelta(t, 20, resp.TotalRate, 0.001)
+	assert.Empty(t, resp.Warnings)
+
+	assert.Contains(t, resp.Script, `const BASE_URL = __ENV.BASE_URL || "https://shop.example.com";`)
+	assert.Contains(t, resp.Script, "export function getProductsByProductId() {")
+	assert.Contains(t, resp.Script, "http.get(`${BASE_URL}/products/${__ENV.PRODUCT_ID || '1'}`")
+	assert.Contains(t, resp.Script, `http.post(`+"`${BASE_URL}/cart`"+`, "{\"sku\":1}"`)
+	assert.Contains(t, resp.Script, `"exec": "postCart"`)
+	assert.Contains(t, resp.Script, `"http_req_duration{scenario:postCart}": [`)
+	assert.Contains(t, resp.Script, `"p(95)<500"`)
+	assert.Contains(t, resp.Script, "export function getRoot() {")
+}
+
+func TestBuildWorkloadMixTable(t *testing.T) {
+	t.Parallel()
+
+	table := "Endpoint;Requests\n" +
+		"GET /search?q=x;1.500\n" +
+		"https://shop.example.com/users/:id;500\n" +
+		"GET /search;2\n" +
+		"GET /health;1\n"
+	result, err := buildWorkloadMix(context.Background(), newCallRequest(map[string]any{
+		"base_url":        "https://shop.example.com",
+		"production_rate": float64(1),
+		"table":           table,
+	}))
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+
+	var resp buildWorkloadMixResponse
+	decodeJSON(t, result, &resp)
+	require.Len(t, resp.Scenarios, 3)
+	assert.Equal(t, "GET /search", resp.Scenarios[0].Endpoint)
+	assert.InDelta(t, 0.75, resp.Scenarios[0].Share, 0.001)
+	assert.Equal(t, "GET /users/:id", resp.Scenarios[1].Endpoint)
+	assert.Equal(t, "getUsersById", resp.Scenarios[1].Name)
+	require.Len(t, resp.Warnings, 2)
+	assert.Contains(t, resp.Warnings[0], "GET /search is listed more than once")
+	assert.Contains(t, resp.Warnings[1], "GET /health runs 2 per 1h")
+	assert.Contains(t, resp.Script, "`${BASE_URL}/users/${__ENV.ID || '1'}`")
+}
+
+func TestBuildWorkloadMixErrors(t *testing.T) {
+	t.Parallel()
+
+	endpoints := []any{map[string]any{"path": "/", "share": float64(1)}}
+	tests := []struct {
+		args map[string]any
+		want string
+	}{
+		{map[string]any{"base_url": "shop", "production_rate": float64(1), "endpoints": endpoints}, "base_url"},
+		{map[string]any{"base_url": "https://a.test", "endpoints": endpoints}, "production_rate must be positive"},
+		{map[string]any{"base_url": "https://a.test", "production_rate": float64(1)}, "endpoints or table"},
+		{
+			map[string]any{"base_url": "https://a.test", "production_rate": float64(1), "table": "route,hits\n/,1"},
+			"",
+		},
+		{
+			map[string]any{"base_url": "https://a.test", "production_rate": float64(1), "table": "page,views\n/,1"},
+			"no path column",
+		},
+		{
+			map[string]any{
+				"base_url": "https://a.test", "production_rate": float64(1),
+				"endpoints": []any{map[string]any{"path": "cart", "share": float64(1)}},
+			},
+			"must start with /",
+		},
+	}
+	for _, tt := range tests {
+		result, err := buildWorkloadMix(context.Background(), newCallRequest(tt.args))
+		require.NoError(t, err)
+		if tt.want == "" {
+			assert.False(t, result.IsError)
+			continue
+		}
+		require.True(t, result.IsError)
+		assert.Contains(t, result.Content[0].(mcp.TextContent).Text, tt.want)
+	}
+}
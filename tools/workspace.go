@@ -0,0 +1,112 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+
+	"github.com/grafana/mcp-k6/internal/helpers"
+	"github.com/grafana/mcp-k6/internal/logging"
+	"github.com/grafana/mcp-k6/internal/workspace"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// maxEnvFileSize bounds the size of .env files read from the workspace.
+const maxEnvFileSize = 64 * 1024
+
+// scriptPathDescription documents the script_path parameter shared by the execution tools.
+const scriptPathDescription = "Path to a k6 script inside a workspace root the client has shared " +
+	"(absolute, or relative to a root). Use instead of 'script' so that relative open() and import " +
+	"paths (CSV datasets, modules) resolve next to the script."
+
+// envFileDescription documents the env_file parameter shared by the execution tools.
+const envFileDescription = "Optional: path to a .env file inside a workspace root. " +
+	"Its KEY=VALUE pairs are exposed to the script as __ENV variables."
+
+// errNoScript is returned when neither inline content nor a path was provided.
+var errNoScript = errors.New("provide either 'script' (inline content) or 'script_path' (a file in the workspace)")
+
+// readScriptArgument returns the script content from the request, reading it
+// from the workspace when script_path is set. The resolved path is empty for
+// inline scripts.
+func readScriptArgument(
+	ctx context.Context,
+	ws *workspace.Workspace,
+	request mcp.CallToolRequest,
+) (script, path string, err error) {
+	script = request.GetString("script", "")
+	scriptPath := request.GetString("script_path", "")
+
+	switch {
+	case script != "" && scriptPath != "":
+		return "", "", errors.New("provide only one of 'script' and 'script_path'")
+	case script != "":
+		return script, "", nil
+	case scriptPath == "":
+		return "", "", errNoScript
+	}
+
+	if ws == nil {
+		return "", "", workspace.ErrNoRoots
+	}
+	data, resolved, err := ws.ReadFile(ctx, scriptPath, MaxScriptSize)
+	if err != nil {
+		return "", "", fmt.Errorf("reading script_path: %w", err)
+	}
+
+	logging.FileOperation(ctx, "workspace", "read_script", resolved, nil)
+	logging.LoggerFromContext(ctx).DebugContext(ctx, "Loaded script from workspace",
+		slog.String("script_path", helpers.GetPathType(resolved)),
+		slog.Int("script_size", len(data)))
+
+	return string(data), resolved, nil
+}
+
+// readEnvFileArgument parses the env_file parameter, if any.
+func readEnvFileArgument(
+	ctx context.Context,
+	ws *workspace.Workspace,
+	request mcp.CallToolRequest,
+) (map[string]string, error) {
+	envFile := request.GetString("env_file", "")
+	if envFile == "" {
+		return nil, nil
+	}
+	if ws == nil {
+		return nil, workspace.ErrNoRoots
+	}
+
+	data, resolved, err := ws.ReadFile(ctx, envFile, maxEnvFileSize)
+	if err != nil {
+		return nil, fmt.Errorf("reading env_file: %w", err)
+	}
+	env, err := workspace.ParseEnv(data)
+	if err != nil {
+		return nil, fmt.Errorf("parsing env_file: %w", err)
+	}
+
+	logging.FileOperation(ctx, "workspace", "read_env_file", resolved, nil)
+	logging.LoggerFromContext(ctx).DebugContext(ctx, "Loaded env file from workspace",
+		slog.Int("variables", len(env)))
+
+	return env, nil
+}
+
+// envArgs converts environment variables into k6 --env flags, sorted by key
+// for reproducible command lines. Values never reach the process environment,
+// so K6_* variables cannot override the server's execution limits.
+func envArgs(env map[string]string) []string {
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	args := make([]string, 0, len(keys)*2)
+	for _, k := range keys {
+		args = append(args, "--env", k+"="+env[k])
+	}
+	return args
+}
@@ -0,0 +1,111 @@
+package tools
+
+import (
+	"context"
+	"log/slog"
+	"path/filepath"
+	"strings"
+
+	"github.com/grafana/mcp-k6/internal/helpers"
+	"github.com/grafana/mcp-k6/internal/logging"
+	"github.com/grafana/mcp-k6/internal/security"
+	"github.com/grafana/mcp-k6/internal/workspace"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// WriteScriptTool exposes a tool for saving k6 scripts into the workspace.
+//
+//nolint:gochecknoglobals // Shared tool definition registered at startup.
+var WriteScriptTool = mcp.NewTool(
+	"write_script",
+	controlTool(true),
+	mcp.WithDescription(
+		"Write a k6 script to a file inside a workspace root the client has shared, "+
+			"so generated or modified scripts land in the project instead of only in the conversation. "+
+			"Only .js, .mjs, .cjs and .ts files can be written. Existing files are kept unless overwrite is true. "+
+			"Use validate_script with script_path afterwards to check the saved file.",
+	),
+	mcp.WithString(
+		"path",
+		mcp.Required(),
+		mcp.Description("Destination path inside a workspace root (absolute, or relative to a root). Example: 'tests/load/checkout.js'"),
+	),
+	mcp.WithString(
+		"script",
+		mcp.Required(),
+		mcp.Description("The k6 script content to write."),
+	),
+	mcp.WithBoolean(
+		"overwrite",
+		mcp.Description("Optional: replace the file if it already exists (default: false)."),
+	),
+)
+
+// RegisterWriteScriptTool registers the write_script tool with the MCP server.
+func RegisterWriteScriptTool(s *server.MCPServer, ws *workspace.Workspace) {
+	s.AddTool(WriteScriptTool, withToolLogger("write_script", newWriteScriptHandlerFunc(ws)))
+}
+
+// writeScriptResponse is the JSON structure returned by the tool.
+type writeScriptResponse struct {
+	Path      string   `json:"path"`
+	Bytes     int      `json:"bytes"`
+	Created   bool     `json:"created"`
+	NextSteps []string `json:"next_steps"`
+}
+
+// newWriteScriptHandlerFunc returns an MCP tool handler bound to a workspace.
+func newWriteScriptHandlerFunc(ws *workspace.Workspace) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		logger := logging.LoggerFromContext(ctx)
+
+		path, err := request.RequireString("path")
+		if err != nil {
+			return nil, err
+		}
+		script, err := request.RequireString("script")
+		if err != nil {
+			return nil, err
+		}
+		overwrite := request.GetBool("overwrite", false)
+
+		if !isScriptFile(path) {
+			return mcp.NewToolResultError("Only .js, .mjs, .cjs and .ts files can be written"), nil
+		}
+		if err := security.ValidateScriptContent(ctx, script); err != nil {
+			logger.WarnContext(ctx, "Refusing to write script", slog.String("error", err.Error()))
+			return mcp.NewToolResultError("Script content validation failed: " + err.Error()), nil
+		}
+
+		resolved, created, err := ws.WriteFile(ctx, path, []byte(script), overwrite)
+		logging.FileOperation(ctx, "workspace", "write_script", resolved, err)
+		if err != nil {
+			return mcp.NewToolResultError("Failed to write script: " + err.Error()), nil
+		}
+
+		logger.InfoContext(ctx, "Script written to workspace",
+			slog.String("script_path", helpers.GetPathType(resolved)),
+			slog.Int("script_size", len(script)),
+			slog.Bool("created", created))
+
+		return marshalResponse(ctx, logger, writeScriptResponse{
+			Path:    resolved,
+			Bytes:   len(script),
+			Created: created,
+			NextSteps: []string{
+				"Use validate_script with script_path to check the saved script",
+				"Use run_script with script_path to execute it in place",
+			},
+		})
+	}
+}
+
+// isScriptFile reports whether path has a JavaScript or TypeScript extension.
+func isScriptFile(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".js", ".mjs", ".cjs", ".ts":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,46 @@
+package tools
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/grafana/mcp-k6/internal/workspace"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteScriptHandler(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	handler := newWriteScriptHandlerFunc(workspace.New(nil, root))
+
+	call := func(args map[string]any) *mcp.CallToolResult {
+		t.Helper()
+		req := mcp.CallToolRequest{}
+		req.Params.Arguments = args
+		result, err := handler(context.Background(), req)
+		require.NoError(t, err)
+		return result
+	}
+
+	script := "import http from 'k6/http';\nexport default function () { http.get('https://test.k6.io'); }\n"
+
+	result := call(map[string]any{"path": "load/smoke.js", "script": script})
+	require.False(t, result.IsError)
+	assert.FileExists(t, filepath.Join(root, "load", "smoke.js"))
+
+	result = call(map[string]any{"path": "load/smoke.js", "script": script})
+	assert.True(t, result.IsError, "existing file must not be overwritten by default")
+
+	result = call(map[string]any{"path": "load/smoke.js", "script": script, "overwrite": true})
+	assert.False(t, result.IsError)
+
+	result = call(map[string]any{"path": "notes.txt", "script": script})
+	assert.True(t, result.IsError, "non-script extensions are rejected")
+
+	result = call(map[string]any{"path": "../escape.js", "script": script})
+	assert.True(t, result.IsError, "paths outside the roots are rejected")
+}